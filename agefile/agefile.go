@@ -0,0 +1,301 @@
+// Package agefile implements the age v1 file encryption format
+// (age-encryption.org/v1) with X25519 and scrypt recipients, so that files
+// encrypted here open with the reference age tool and the other way round.
+//
+// A random 16 bytes file key is wrapped once per recipient in the stanzas
+// of a text header, which is authenticated with an HMAC keyed from the
+// file key. The payload is encrypted in 64 KiB chunks of ChaCha20-Poly1305
+// under a key derived with HKDF from the file key and a random nonce.
+package agefile
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jvehent/badcrypto/kdf"
+)
+
+const (
+	intro      = "age-encryption.org/v1\n"
+	stanzaTag  = "->"
+	footerTag  = "---"
+	columns    = 64
+	fileKeyLen = 16
+)
+
+var b64 = base64.RawStdEncoding.Strict()
+
+// ErrIncorrectIdentity is returned by an Identity that cannot unwrap any
+// of the stanzas, so the next identity can be tried
+var ErrIncorrectIdentity = errors.New("agefile: incorrect identity for recipient stanza")
+
+var (
+	errNoMatch   = errors.New("agefile: no identity matched any of the recipients")
+	errHeaderMAC = errors.New("agefile: bad header MAC")
+)
+
+// Stanza is a recipient block of the header: a type, a list of
+// arguments and a body holding the wrapped file key
+type Stanza struct {
+	Type string
+	Args []string
+	Body []byte
+}
+
+// Recipient wraps a file key for one reader
+type Recipient interface {
+	Wrap(fileKey []byte) ([]*Stanza, error)
+}
+
+// Identity unwraps a file key from the stanzas of a header. It returns
+// ErrIncorrectIdentity when none of the stanzas is addressed to it.
+type Identity interface {
+	Unwrap(stanzas []*Stanza) ([]byte, error)
+}
+
+// marshal writes a stanza: the arguments line then the body in base64,
+// wrapped at 64 columns and always ending with a short line
+func (s *Stanza) marshal(w io.Writer) error {
+	line := stanzaTag + " " + s.Type
+	for _, arg := range s.Args {
+		line += " " + arg
+	}
+	if _, err := io.WriteString(w, line+"\n"); err != nil {
+		return err
+	}
+	body := b64.EncodeToString(s.Body)
+	for {
+		n := len(body)
+		if n > columns {
+			n = columns
+		}
+		if _, err := io.WriteString(w, body[:n]+"\n"); err != nil {
+			return err
+		}
+		if n < columns {
+			return nil
+		}
+		body = body[n:]
+	}
+}
+
+type header struct {
+	recipients []*Stanza
+	mac        []byte
+}
+
+// marshalWithoutMAC returns the header up to and including "---", which
+// is what the header MAC covers
+func (h *header) marshalWithoutMAC() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(intro)
+	for _, s := range h.recipients {
+		if err := s.marshal(&buf); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteString(footerTag)
+	return buf.Bytes(), nil
+}
+
+func headerMAC(fileKey, hdr []byte) ([]byte, error) {
+	key, err := kdf.HKDF(sha256.New, fileKey, nil, []byte("header"), 32)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(hdr)
+	return mac.Sum(nil), nil
+}
+
+// isArg checks that an argument is a non empty string of visible ASCII
+func isArg(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 33 || s[i] > 126 {
+			return false
+		}
+	}
+	return true
+}
+
+// parseHeader reads the header from r and returns it along with its
+// serialization without the MAC, leaving r at the start of the payload
+func parseHeader(r *bufio.Reader) (*header, []byte, error) {
+	var raw bytes.Buffer
+	readLine := func() (string, error) {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return "", fmt.Errorf("agefile: failed to read header: %v", err)
+		}
+		raw.WriteString(line)
+		return strings.TrimSuffix(line, "\n"), nil
+	}
+	line, err := readLine()
+	if err != nil {
+		return nil, nil, err
+	}
+	if line+"\n" != intro {
+		return nil, nil, fmt.Errorf("agefile: unexpected intro line %q", line)
+	}
+	h := new(header)
+	for {
+		line, err = readLine()
+		if err != nil {
+			return nil, nil, err
+		}
+		if strings.HasPrefix(line, footerTag+" ") {
+			mac, err := b64.DecodeString(strings.TrimPrefix(line, footerTag+" "))
+			if err != nil || len(mac) != sha256.Size {
+				return nil, nil, errors.New("agefile: malformed header MAC")
+			}
+			h.mac = mac
+			// the MAC covers the header up to "---", without the space
+			macLine := len(line) + 1 - len(footerTag)
+			return h, raw.Bytes()[:raw.Len()-macLine], nil
+		}
+		fields := strings.Split(line, " ")
+		if len(fields) < 2 || fields[0] != stanzaTag {
+			return nil, nil, fmt.Errorf("agefile: malformed stanza line %q", line)
+		}
+		for _, f := range fields[1:] {
+			if !isArg(f) {
+				return nil, nil, fmt.Errorf("agefile: malformed stanza argument in %q", line)
+			}
+		}
+		s := &Stanza{Type: fields[1], Args: fields[2:]}
+		var body []byte
+		for {
+			line, err = readLine()
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(line) > columns {
+				return nil, nil, errors.New("agefile: stanza body line too long")
+			}
+			chunk, err := b64.DecodeString(line)
+			if err != nil {
+				return nil, nil, fmt.Errorf("agefile: malformed stanza body: %v", err)
+			}
+			body = append(body, chunk...)
+			if len(line) < columns {
+				break
+			}
+		}
+		s.Body = body
+		h.recipients = append(h.recipients, s)
+	}
+}
+
+// Encrypt writes a header for the given recipients to dst and returns a
+// writer that encrypts the payload. The writer must be closed to flush
+// the final chunk.
+func Encrypt(dst io.Writer, recipients ...Recipient) (io.WriteCloser, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("agefile: no recipients")
+	}
+	fileKey := make([]byte, fileKeyLen)
+	if _, err := rand.Read(fileKey); err != nil {
+		return nil, err
+	}
+	h := new(header)
+	for _, r := range recipients {
+		stanzas, err := r.Wrap(fileKey)
+		if err != nil {
+			return nil, fmt.Errorf("agefile: failed to wrap key for recipient: %v", err)
+		}
+		h.recipients = append(h.recipients, stanzas...)
+	}
+	for _, s := range h.recipients {
+		if s.Type == "scrypt" && len(h.recipients) != 1 {
+			return nil, errors.New("agefile: an scrypt recipient must be the only recipient")
+		}
+	}
+	hdr, err := h.marshalWithoutMAC()
+	if err != nil {
+		return nil, err
+	}
+	mac, err := headerMAC(fileKey, hdr)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Fprintf(dst, "%s %s\n", hdr, b64.EncodeToString(mac)); err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, payloadNonceLen)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	if _, err := dst.Write(nonce); err != nil {
+		return nil, err
+	}
+	key, err := payloadKey(fileKey, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return newWriter(key, dst)
+}
+
+// Decrypt reads the header from src, unwraps the file key with the first
+// identity that accepts one of the stanzas, and returns a reader of the
+// decrypted payload. The payload is authenticated chunk by chunk, so
+// errors may surface while reading.
+func Decrypt(src io.Reader, identities ...Identity) (io.Reader, error) {
+	if len(identities) == 0 {
+		return nil, errors.New("agefile: no identities")
+	}
+	br := bufio.NewReader(src)
+	h, hdr, err := parseHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range h.recipients {
+		if s.Type == "scrypt" && len(h.recipients) != 1 {
+			return nil, errors.New("agefile: an scrypt stanza must be the only one in the header")
+		}
+	}
+	var fileKey []byte
+	for _, id := range identities {
+		fileKey, err = id.Unwrap(h.recipients)
+		if err == ErrIncorrectIdentity {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		break
+	}
+	if fileKey == nil {
+		return nil, errNoMatch
+	}
+	mac, err := headerMAC(fileKey, hdr)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(mac, h.mac) {
+		return nil, errHeaderMAC
+	}
+	nonce := make([]byte, payloadNonceLen)
+	if _, err := io.ReadFull(br, nonce); err != nil {
+		return nil, fmt.Errorf("agefile: failed to read payload nonce: %v", err)
+	}
+	key, err := payloadKey(fileKey, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return newReader(key, br)
+}
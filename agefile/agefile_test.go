@@ -0,0 +1,253 @@
+package agefile
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func encrypt(t *testing.T, plaintext []byte, recipients ...Recipient) []byte {
+	var buf bytes.Buffer
+	w, err := Encrypt(&buf, recipients...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func decrypt(ciphertext []byte, identities ...Identity) ([]byte, error) {
+	r, err := Decrypt(bytes.NewReader(ciphertext), identities...)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+func TestX25519RoundTrip(t *testing.T) {
+	t.Parallel()
+	alice, err := GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var testcases = []int{0, 1, 1000, chunkSize - 1, chunkSize, chunkSize + 1, 2 * chunkSize, 3*chunkSize + 5}
+	for i, size := range testcases {
+		plaintext := make([]byte, size)
+		rand.Read(plaintext)
+		ciphertext := encrypt(t, plaintext, alice.Recipient(), bob.Recipient())
+		for _, id := range []Identity{alice, bob} {
+			out, err := decrypt(ciphertext, id)
+			if err != nil {
+				t.Fatalf("testcase %d decryption failed: %v", i, err)
+			}
+			if !bytes.Equal(out, plaintext) {
+				t.Fatalf("testcase %d decrypted payload does not match", i)
+			}
+		}
+	}
+}
+
+func TestHeaderFormat(t *testing.T) {
+	t.Parallel()
+	id, err := GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext := encrypt(t, []byte("hello"), id.Recipient())
+	lines := strings.SplitN(string(ciphertext), "\n", 5)
+	if lines[0] != "age-encryption.org/v1" {
+		t.Fatalf("unexpected intro line %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "-> X25519 ") || len(lines[1]) != len("-> X25519 ")+43 {
+		t.Fatalf("unexpected stanza line %q", lines[1])
+	}
+	if len(lines[2]) != 43 {
+		t.Fatalf("unexpected stanza body %q", lines[2])
+	}
+	if !strings.HasPrefix(lines[3], "--- ") || len(lines[3]) != len("--- ")+43 {
+		t.Fatalf("unexpected footer %q", lines[3])
+	}
+	// nonce, then a single chunk of 5 bytes and its tag
+	if len(lines[4]) != payloadNonceLen+5+16 {
+		t.Fatalf("unexpected payload length %d", len(lines[4]))
+	}
+}
+
+func TestDecryptFailures(t *testing.T) {
+	t.Parallel()
+	id, _ := GenerateX25519Identity()
+	other, _ := GenerateX25519Identity()
+	plaintext := make([]byte, chunkSize+10)
+	ciphertext := encrypt(t, plaintext, id.Recipient())
+
+	if _, err := decrypt(ciphertext, other); err == nil {
+		t.Fatal("expected decryption with the wrong identity to fail")
+	}
+	// the second identity is tried when the first one does not match
+	if _, err := decrypt(ciphertext, other, id); err != nil {
+		t.Fatalf("expected the second identity to decrypt: %v", err)
+	}
+
+	tampered := append([]byte{}, ciphertext...)
+	idx := bytes.Index(tampered, []byte("\n--- ")) - 1
+	tampered[idx] ^= 1
+	if _, err := decrypt(tampered, id); err == nil {
+		t.Fatal("expected a modified header to fail")
+	}
+
+	hdrLen := bytes.Index(ciphertext, []byte("\n--- ")) + len("\n--- ") + 44
+	payload := ciphertext[hdrLen+payloadNonceLen:]
+	// dropping the last chunk leaves a full chunk that is not flagged as last
+	truncated := ciphertext[:hdrLen+payloadNonceLen+encChunkSize]
+	if len(payload) <= encChunkSize {
+		t.Fatal("expected two chunks")
+	}
+	if _, err := decrypt(truncated, id); err == nil {
+		t.Fatal("expected a truncated payload to fail")
+	}
+
+	flipped := append([]byte{}, ciphertext...)
+	flipped[len(flipped)-1] ^= 1
+	if _, err := decrypt(flipped, id); err == nil {
+		t.Fatal("expected a modified payload to fail")
+	}
+}
+
+func TestKeyEncoding(t *testing.T) {
+	t.Parallel()
+	id, err := GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := id.String()
+	if !strings.HasPrefix(s, "AGE-SECRET-KEY-1") {
+		t.Fatalf("unexpected identity encoding %s", s)
+	}
+	parsed, err := ParseX25519Identity(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Recipient().String() != id.Recipient().String() {
+		t.Fatal("parsed identity does not match")
+	}
+	r := id.Recipient().String()
+	if !strings.HasPrefix(r, "age1") {
+		t.Fatalf("unexpected recipient encoding %s", r)
+	}
+	if _, err := ParseX25519Recipient(r); err != nil {
+		t.Fatal(err)
+	}
+	// the recipient from the age README
+	if _, err := ParseX25519Recipient("age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseX25519Recipient(s); err == nil {
+		t.Fatal("expected an identity to be rejected as a recipient")
+	}
+}
+
+// TestTestkit decrypts the files of the C2SP age test kit
+// (c2sp.org/CCTV/age) in testdata/testkit, which are produced by the
+// reference implementation. The armored and hybrid ones are left out since
+// this package implements neither. Each file starts with a few "key:
+// value" lines describing the expected result and the identities to use.
+func TestTestkit(t *testing.T) {
+	t.Parallel()
+	files, err := ioutil.ReadDir("testdata/testkit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		data, err := ioutil.ReadFile(filepath.Join("testdata/testkit", f.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var expect, payload, compressed string
+		var identities []Identity
+		for {
+			i := bytes.IndexByte(data, '\n')
+			line := string(data[:i])
+			data = data[i+1:]
+			if line == "" {
+				break
+			}
+			kv := strings.SplitN(line, ": ", 2)
+			switch kv[0] {
+			case "expect":
+				expect = kv[1]
+			case "payload":
+				payload = kv[1]
+			case "compressed":
+				compressed = kv[1]
+			case "identity":
+				id, err := ParseX25519Identity(kv[1])
+				if err != nil {
+					t.Fatalf("%s: %v", f.Name(), err)
+				}
+				identities = append(identities, id)
+			case "passphrase":
+				id, err := NewScryptIdentity(kv[1])
+				if err != nil {
+					t.Fatalf("%s: %v", f.Name(), err)
+				}
+				identities = append(identities, id)
+			}
+		}
+		if compressed == "zlib" {
+			zr, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data, err = ioutil.ReadAll(zr); err != nil {
+				t.Fatal(err)
+			}
+		}
+		r, err := Decrypt(bytes.NewReader(data), identities...)
+		switch expect {
+		case "no match":
+			if err != errNoMatch {
+				t.Fatalf("%s: expected no identity to match but got %v", f.Name(), err)
+			}
+			continue
+		case "HMAC failure":
+			if err != errHeaderMAC {
+				t.Fatalf("%s: expected a bad header MAC but got %v", f.Name(), err)
+			}
+			continue
+		case "header failure":
+			if err == nil || err == errNoMatch || err == errHeaderMAC {
+				t.Fatalf("%s: expected a malformed header but got %v", f.Name(), err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: expected the header to decrypt but got %v", f.Name(), err)
+		}
+		// whatever is released before a payload failure must be genuine
+		out, err := ioutil.ReadAll(r)
+		switch {
+		case expect == "success" && err != nil:
+			t.Fatalf("%s: expected the payload to decrypt but got %v", f.Name(), err)
+		case expect == "payload failure" && err == nil:
+			t.Fatalf("%s: expected the payload to fail to decrypt", f.Name())
+		}
+		if h := sha256.Sum256(out); hex.EncodeToString(h[:]) != payload {
+			t.Fatalf("%s: expected a payload of hash %s but got %x", f.Name(), payload, h)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package agefile
+
+import (
+	"crypto/rand"
+	"errors"
+	"strconv"
+
+	"github.com/jvehent/badcrypto/chacha20poly1305"
+	"github.com/jvehent/badcrypto/kdf"
+)
+
+const (
+	scryptLabel = "age-encryption.org/v1/scrypt"
+	saltLen     = 16
+	// defaultWorkFactor is the log2 of the scrypt N parameter used by age
+	defaultWorkFactor = 18
+	// defaultMaxWorkFactor bounds the work a file can impose on a reader
+	defaultMaxWorkFactor = 22
+)
+
+// ScryptRecipient wraps the file key with a passphrase. It must be the
+// only recipient of a file.
+type ScryptRecipient struct {
+	password   []byte
+	workFactor int
+}
+
+// NewScryptRecipient returns a recipient for passphrase with the default
+// work factor of 18, which takes about a second with a fast scrypt
+func NewScryptRecipient(passphrase string) (*ScryptRecipient, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("agefile: empty passphrase")
+	}
+	return &ScryptRecipient{password: []byte(passphrase), workFactor: defaultWorkFactor}, nil
+}
+
+// SetWorkFactor sets the log2 of the scrypt N parameter, between 1 and 30
+func (r *ScryptRecipient) SetWorkFactor(logN int) {
+	if logN < 1 || logN > 30 {
+		panic("agefile: invalid scrypt work factor")
+	}
+	r.workFactor = logN
+}
+
+// Wrap encrypts the file key under a key derived from the passphrase
+// with scrypt, r = 8 and p = 1, salted with a label and a random salt
+func (r *ScryptRecipient) Wrap(fileKey []byte) ([]*Stanza, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	key, err := scryptKey(r.password, salt, r.workFactor)
+	if err != nil {
+		return nil, err
+	}
+	body, err := aeadSeal(key, fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return []*Stanza{{
+		Type: "scrypt",
+		Args: []string{b64.EncodeToString(salt), strconv.Itoa(r.workFactor)},
+		Body: body,
+	}}, nil
+}
+
+func scryptKey(password, salt []byte, logN int) ([]byte, error) {
+	labeled := append([]byte(scryptLabel), salt...)
+	return kdf.Scrypt(password, labeled, 1<<uint(logN), 8, 1, chacha20poly1305.KeySize)
+}
+
+// ScryptIdentity unwraps a file key encrypted with a passphrase
+type ScryptIdentity struct {
+	password      []byte
+	maxWorkFactor int
+}
+
+// NewScryptIdentity returns an identity for passphrase that accepts work
+// factors up to 22
+func NewScryptIdentity(passphrase string) (*ScryptIdentity, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("agefile: empty passphrase")
+	}
+	return &ScryptIdentity{password: []byte(passphrase), maxWorkFactor: defaultMaxWorkFactor}, nil
+}
+
+// SetMaxWorkFactor sets the largest work factor the identity accepts
+func (i *ScryptIdentity) SetMaxWorkFactor(logN int) {
+	if logN < 1 || logN > 30 {
+		panic("agefile: invalid scrypt work factor")
+	}
+	i.maxWorkFactor = logN
+}
+
+// Unwrap decrypts the file key of an scrypt stanza
+func (i *ScryptIdentity) Unwrap(stanzas []*Stanza) ([]byte, error) {
+	for _, s := range stanzas {
+		if s.Type != "scrypt" {
+			continue
+		}
+		if len(s.Args) != 2 {
+			return nil, errors.New("agefile: invalid scrypt stanza")
+		}
+		salt, err := b64.DecodeString(s.Args[0])
+		if err != nil || len(salt) != saltLen {
+			return nil, errors.New("agefile: invalid scrypt stanza salt")
+		}
+		// the work factor is a decimal without leading zeroes
+		logN, err := strconv.Atoi(s.Args[1])
+		if err != nil || logN <= 0 || strconv.Itoa(logN) != s.Args[1] {
+			return nil, errors.New("agefile: invalid scrypt work factor")
+		}
+		if logN > i.maxWorkFactor {
+			return nil, errors.New("agefile: scrypt work factor is too large")
+		}
+		if len(s.Body) != fileKeyLen+chacha20poly1305.Overhead {
+			return nil, errors.New("agefile: invalid scrypt stanza body")
+		}
+		key, err := scryptKey(i.password, salt, logN)
+		if err != nil {
+			return nil, err
+		}
+		fileKey, err := aeadOpen(key, s.Body)
+		if err != nil {
+			// a wrong passphrase is indistinguishable from a stanza
+			// for someone else, so let the next identity try
+			return nil, ErrIncorrectIdentity
+		}
+		return fileKey, nil
+	}
+	return nil, ErrIncorrectIdentity
+}
@@ -0,0 +1,52 @@
+package agefile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestScryptRoundTrip(t *testing.T) {
+	t.Parallel()
+	r, err := NewScryptRecipient("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.SetWorkFactor(10)
+	ciphertext := encrypt(t, []byte("passphrase protected"), r)
+	if !bytes.Contains(ciphertext, []byte("\n-> scrypt ")) || !bytes.Contains(ciphertext, []byte(" 10\n")) {
+		t.Fatalf("unexpected header %q", ciphertext[:bytes.Index(ciphertext, []byte("---"))])
+	}
+
+	id, err := NewScryptIdentity("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := decrypt(ciphertext, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "passphrase protected" {
+		t.Fatalf("unexpected plaintext %q", out)
+	}
+
+	wrong, _ := NewScryptIdentity("wrong")
+	if _, err := decrypt(ciphertext, wrong); err == nil {
+		t.Fatal("expected the wrong passphrase to fail")
+	}
+
+	id.SetMaxWorkFactor(9)
+	if _, err := decrypt(ciphertext, id); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Fatalf("expected the work factor to be refused but got %v", err)
+	}
+}
+
+func TestScryptMustBeAlone(t *testing.T) {
+	t.Parallel()
+	r, _ := NewScryptRecipient("passphrase")
+	r.SetWorkFactor(1)
+	id, _ := GenerateX25519Identity()
+	if _, err := Encrypt(&bytes.Buffer{}, r, id.Recipient()); err == nil {
+		t.Fatal("expected an scrypt recipient to be refused along with another one")
+	}
+}
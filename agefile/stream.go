@@ -0,0 +1,183 @@
+package agefile
+
+import (
+	"crypto/cipher"
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"github.com/jvehent/badcrypto/chacha20poly1305"
+	"github.com/jvehent/badcrypto/kdf"
+)
+
+const (
+	payloadNonceLen = 16
+	chunkSize       = 64 * 1024
+	encChunkSize    = chunkSize + chacha20poly1305.Overhead
+	lastChunkFlag   = 0x01
+)
+
+func payloadKey(fileKey, nonce []byte) ([]byte, error) {
+	return kdf.HKDF(sha256.New, fileKey, nonce, []byte("payload"), chacha20poly1305.KeySize)
+}
+
+// chunkNonce is the STREAM nonce: an 11 bytes big endian chunk counter
+// followed by a flag set on the last chunk
+func chunkNonce(counter uint64, last bool) []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	for i := 0; i < 8; i++ {
+		nonce[10-i] = byte(counter >> (8 * i))
+	}
+	if last {
+		nonce[11] = lastChunkFlag
+	}
+	return nonce
+}
+
+// writer encrypts the payload in chunks. A chunk is only sealed once
+// more data arrives or the writer is closed, because the last chunk
+// must be flagged as such.
+type writer struct {
+	aead    cipher.AEAD
+	dst     io.Writer
+	buf     []byte
+	counter uint64
+	closed  bool
+}
+
+func newWriter(key []byte, dst io.Writer) (*writer, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return &writer{aead: aead, dst: dst, buf: make([]byte, 0, chunkSize)}, nil
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("agefile: write on closed writer")
+	}
+	total := len(p)
+	for len(p) > 0 {
+		if len(w.buf) == chunkSize {
+			if err := w.flush(false); err != nil {
+				return 0, err
+			}
+		}
+		n := copy(w.buf[len(w.buf):chunkSize], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+	}
+	return total, nil
+}
+
+func (w *writer) flush(last bool) error {
+	out := w.aead.Seal(nil, chunkNonce(w.counter, last), w.buf, nil)
+	if _, err := w.dst.Write(out); err != nil {
+		return err
+	}
+	w.counter++
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// Close seals the last chunk. It does not close the destination.
+func (w *writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.flush(true)
+}
+
+// reader decrypts the payload one chunk at a time. A chunk is released
+// as soon as it authenticates, so an error past it, like trailing data
+// after the last chunk, only surfaces on the next read.
+type reader struct {
+	aead    cipher.AEAD
+	src     io.Reader
+	buf     []byte
+	enc     []byte
+	counter uint64
+	err     error
+}
+
+func newReader(key []byte, src io.Reader) (*reader, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return &reader{aead: aead, src: src, enc: make([]byte, encChunkSize)}, nil
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if len(p) == 0 {
+			return 0, nil
+		}
+		last, err := r.readChunk()
+		if err != nil {
+			r.err = err
+			return 0, err
+		}
+		if last {
+			r.err = r.checkEOF()
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// checkEOF makes sure nothing follows the last chunk
+func (r *reader) checkEOF() error {
+	_, err := r.src.Read(make([]byte, 1))
+	switch err {
+	case nil:
+		return errors.New("agefile: trailing data after the last chunk")
+	case io.EOF:
+		return io.EOF
+	default:
+		return err
+	}
+}
+
+// readChunk decrypts the next chunk into r.buf and reports whether it was
+// flagged as the last one. A full chunk may be the last one too, which
+// only its tag tells.
+func (r *reader) readChunk() (bool, error) {
+	n, err := io.ReadFull(r.src, r.enc)
+	last := false
+	switch err {
+	case nil:
+	case io.EOF:
+		// the payload must end with a flagged chunk
+		return false, errors.New("agefile: truncated payload")
+	case io.ErrUnexpectedEOF:
+		last = true
+	default:
+		return false, err
+	}
+	chunk := r.enc[:n]
+	if len(chunk) < chacha20poly1305.Overhead {
+		return false, errors.New("agefile: truncated payload")
+	}
+	// only an empty payload may end with an empty chunk
+	if last && len(chunk) == chacha20poly1305.Overhead && r.counter > 0 {
+		return false, errors.New("agefile: last chunk is empty")
+	}
+	out, err := r.aead.Open(nil, chunkNonce(r.counter, last), chunk, nil)
+	if err != nil && !last {
+		last = true
+		out, err = r.aead.Open(nil, chunkNonce(r.counter, last), chunk, nil)
+	}
+	if err != nil {
+		return false, errors.New("agefile: failed to decrypt and authenticate payload chunk")
+	}
+	r.counter++
+	r.buf = out
+	return last, nil
+}
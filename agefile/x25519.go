@@ -0,0 +1,163 @@
+package agefile
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jvehent/badcrypto/chacha20poly1305"
+	"github.com/jvehent/badcrypto/encoding/bech32"
+	"github.com/jvehent/badcrypto/kdf"
+	"github.com/jvehent/badcrypto/x25519"
+)
+
+const x25519Label = "age-encryption.org/v1/X25519"
+
+// X25519Recipient is the public key of an X25519 identity, encoded as
+// an "age1..." Bech32 string
+type X25519Recipient struct {
+	theirPublicKey []byte
+}
+
+// ParseX25519Recipient parses an "age1..." recipient string
+func ParseX25519Recipient(s string) (*X25519Recipient, error) {
+	hrp, key, err := bech32.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("agefile: malformed recipient %q: %v", s, err)
+	}
+	if hrp != "age" || len(key) != x25519.Size {
+		return nil, fmt.Errorf("agefile: malformed recipient %q", s)
+	}
+	return &X25519Recipient{theirPublicKey: key}, nil
+}
+
+// String returns the Bech32 encoding of the recipient
+func (r *X25519Recipient) String() string {
+	s, _ := bech32.Encode("age", r.theirPublicKey)
+	return s
+}
+
+// Wrap encrypts the file key to the recipient with an ephemeral X25519
+// share, whose Diffie-Hellman output is the input of HKDF
+func (r *X25519Recipient) Wrap(fileKey []byte) ([]*Stanza, error) {
+	ephemeral, share, err := x25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := x25519.SharedSecret(ephemeral[:], r.theirPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	wrapKey, err := x25519WrapKey(shared, share[:], r.theirPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	body, err := aeadSeal(wrapKey, fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return []*Stanza{{
+		Type: "X25519",
+		Args: []string{b64.EncodeToString(share[:])},
+		Body: body,
+	}}, nil
+}
+
+// x25519WrapKey derives the key wrapping the file key, salted with the
+// ephemeral share followed by the recipient public key
+func x25519WrapKey(shared, share, recipient []byte) ([]byte, error) {
+	salt := append(append([]byte{}, share...), recipient...)
+	return kdf.HKDF(sha256.New, shared, salt, []byte(x25519Label), chacha20poly1305.KeySize)
+}
+
+// X25519Identity is an X25519 private key, encoded as an
+// "AGE-SECRET-KEY-1..." Bech32 string
+type X25519Identity struct {
+	secretKey, ourPublicKey []byte
+}
+
+// GenerateX25519Identity returns a new random identity
+func GenerateX25519Identity() (*X25519Identity, error) {
+	priv, pub, err := x25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &X25519Identity{secretKey: priv[:], ourPublicKey: pub[:]}, nil
+}
+
+// ParseX25519Identity parses an "AGE-SECRET-KEY-1..." identity string
+func ParseX25519Identity(s string) (*X25519Identity, error) {
+	hrp, key, err := bech32.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("agefile: malformed secret key: %v", err)
+	}
+	if hrp != "age-secret-key-" || len(key) != x25519.Size {
+		return nil, errors.New("agefile: malformed secret key")
+	}
+	var priv [x25519.Size]byte
+	copy(priv[:], key)
+	pub := x25519.ScalarBaseMult(priv)
+	return &X25519Identity{secretKey: key, ourPublicKey: pub[:]}, nil
+}
+
+// String returns the Bech32 encoding of the identity
+func (i *X25519Identity) String() string {
+	s, _ := bech32.Encode(strings.ToUpper("age-secret-key-"), i.secretKey)
+	return s
+}
+
+// Recipient returns the public recipient of the identity
+func (i *X25519Identity) Recipient() *X25519Recipient {
+	return &X25519Recipient{theirPublicKey: i.ourPublicKey}
+}
+
+// Unwrap tries each X25519 stanza until one decrypts with the identity
+func (i *X25519Identity) Unwrap(stanzas []*Stanza) ([]byte, error) {
+	for _, s := range stanzas {
+		if s.Type != "X25519" {
+			continue
+		}
+		if len(s.Args) != 1 {
+			return nil, errors.New("agefile: invalid X25519 stanza")
+		}
+		share, err := b64.DecodeString(s.Args[0])
+		if err != nil || len(share) != x25519.Size {
+			return nil, errors.New("agefile: invalid X25519 stanza")
+		}
+		if len(s.Body) != fileKeyLen+chacha20poly1305.Overhead {
+			return nil, errors.New("agefile: invalid X25519 stanza body")
+		}
+		shared, err := x25519.SharedSecret(i.secretKey, share)
+		if err != nil {
+			return nil, fmt.Errorf("agefile: invalid X25519 stanza: %v", err)
+		}
+		wrapKey, err := x25519WrapKey(shared, share, i.ourPublicKey)
+		if err != nil {
+			return nil, err
+		}
+		if fileKey, err := aeadOpen(wrapKey, s.Body); err == nil {
+			return fileKey, nil
+		}
+	}
+	return nil, ErrIncorrectIdentity
+}
+
+// aeadSeal and aeadOpen wrap file keys with ChaCha20-Poly1305 and a zero
+// nonce, which is safe since each wrapping key is used only once
+func aeadSeal(key, plaintext []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nil, make([]byte, chacha20poly1305.NonceSize), plaintext, nil), nil
+}
+
+func aeadOpen(key, ciphertext []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Open(nil, make([]byte, chacha20poly1305.NonceSize), ciphertext, nil)
+}
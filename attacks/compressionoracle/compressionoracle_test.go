@@ -0,0 +1,27 @@
+package compressionoracle
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+)
+
+var base64Alphabet = []byte("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/=")
+
+func TestRecover(t *testing.T) {
+	t.Parallel()
+	for _, mode := range []Mode{CTR, CBC} {
+		raw := make([]byte, 32)
+		rand.Read(raw)
+		secret := []byte(base64.StdEncoding.EncodeToString(raw))
+		s := NewServer(rand.Reader, secret, mode)
+		found, err := Recover(s, []byte("sessionid="), base64Alphabet, len(secret))
+		if err != nil {
+			t.Fatalf("mode %d failed after %q with %v", mode, found, err)
+		}
+		if !bytes.Equal(found, secret) {
+			t.Fatalf("mode %d expected %s but got %s", mode, secret, found)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package compressionoracle
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"io"
+)
+
+// Mode is the encryption mode of a Server
+type Mode int
+
+const (
+	// CTR is a stream cipher mode, the ciphertext has the exact size of
+	// the compressed request
+	CTR Mode = iota
+	// CBC pads the compressed request to a multiple of 16 bytes
+	CBC
+)
+
+// Server is a deliberately vulnerable service that compresses HTTP
+// requests carrying a secret session cookie, then encrypts them under a
+// fresh key, for exercises
+type Server struct {
+	mode   Mode
+	rand   io.Reader
+	secret []byte
+}
+
+// NewServer returns a server whose requests carry the cookie
+// sessionid=secret
+func NewServer(rand io.Reader, secret []byte, mode Mode) *Server {
+	return &Server{mode: mode, rand: rand, secret: secret}
+}
+
+// Request returns the request carrying body
+func (s *Server) Request(body []byte) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "POST / HTTP/1.1\r\nHost: hapless.com\r\nCookie: sessionid=%s\r\nContent-Length: %d\r\n\r\n", s.secret, len(body))
+	b.Write(body)
+	return b.Bytes()
+}
+
+// Encrypt compresses the request carrying body with DEFLATE and encrypts
+// it with AES-128 under a random key and IV
+func (s *Server) Encrypt(body []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	w, err := flate.NewWriter(&compressed, flate.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	w.Write(s.Request(body))
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	key := make([]byte, 16)
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(s.rand, key); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(s.rand, iv); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	msg := compressed.Bytes()
+	if s.mode == CTR {
+		cipher.NewCTR(block, iv).XORKeyStream(msg, msg)
+		return append(iv, msg...), nil
+	}
+	n := aes.BlockSize - len(msg)%aes.BlockSize
+	for i := 0; i < n; i++ {
+		msg = append(msg, byte(n))
+	}
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(msg, msg)
+	return append(iv, msg...), nil
+}
+
+// Length returns the length of the encryption of the request carrying
+// body, which is all the attacker sees on the wire
+func (s *Server) Length(body []byte) int {
+	ct, err := s.Encrypt(body)
+	if err != nil {
+		panic(err)
+	}
+	return len(ct)
+}
@@ -0,0 +1,280 @@
+// Package coppersmith implements Coppersmith's method to find the small
+// roots of a polynomial modulo an integer of unknown factorization, in the
+// formulation of Howgrave-Graham (1997) generalized by May.
+//
+// Given a monic polynomial f of degree d and a modulus N with a divisor b
+// >= N^beta, possibly N itself, every root x0 of f mod b with |x0| <=
+// N^(beta^2/d) / 2 can be found in polynomial time. Polynomials that share
+// the root modulo b^m, such as N^(m-i) f(x)^i, are combined by LLL into one
+// with small coefficients, for which the root holds over the integers and
+// is found with ordinary root finding.
+//
+// With beta = 1 this recovers the unknown part of an RSA message of which
+// most is known, such as a fixed template with a short password, when e
+// is small. With beta = 1/2 and f(x) = a + x it factors N from half of the
+// bits of p, which a side channel or a weak prime generator may leak.
+package coppersmith
+
+import (
+	"errors"
+	"math"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/lattice"
+	"github.com/jvehent/badcrypto/poly"
+)
+
+// MaxDimension bounds the size of the lattice. A bound close to the
+// theoretical limit needs a large one, and the exact LLL of the lattice
+// package slows down quickly with the dimension: a dozen takes seconds
+// with a 1024 bits modulus and the time grows much faster than the
+// dimension.
+const MaxDimension = 60
+
+// ErrBoundTooLarge is returned when the bound on the roots is too close to
+// or beyond N^(beta^2/d) for a lattice of MaxDimension
+var ErrBoundTooLarge = errors.New("coppersmith: bound on the roots too large for the lattice")
+
+// SmallRoots returns the roots x0 of f mod b with |x0| <= bound, for a
+// divisor b >= n^beta of n. f must be invertible modulo n once made monic,
+// its degree at least one, and beta in (0, 1]. Negative roots are
+// returned as negative integers.
+//
+// The lattice parameters follow May: epsilon is the gap between the bound
+// and N^(beta^2/d), m = ceil(beta^2 / (d epsilon)) and t = floor(d m (1/beta
+// - 1)). The lattice is spanned by the coefficients of
+//
+// g_ij(xX) = (xX)^j n^(m-i) f(xX)^i for i < m and j < d
+// h_i(xX) = (xX)^i f(xX)^m for i < t
+//
+// where X is the bound, in dimension d m + t.
+func SmallRoots(f poly.Poly, n, bound *big.Int, beta float64) ([]*big.Int, error) {
+	if beta <= 0 || beta > 1 {
+		return nil, errors.New("coppersmith: beta must be in (0, 1]")
+	}
+	if bound.Sign() <= 0 {
+		return nil, errors.New("coppersmith: the bound must be positive")
+	}
+	r := poly.Ring{N: n}
+	f, err := r.Monic(f)
+	if err != nil {
+		return nil, err
+	}
+	d := f.Degree()
+	if d < 1 {
+		return nil, errors.New("coppersmith: the polynomial must have a degree of at least one")
+	}
+	// bound <= n^(beta^2/d - epsilon) / 2
+	logN := float64(n.BitLen())
+	epsilon := beta*beta/float64(d) - float64(bound.BitLen()+1)/logN
+	if epsilon <= 0 {
+		return nil, ErrBoundTooLarge
+	}
+	m := int(math.Ceil(beta * beta / (float64(d) * epsilon)))
+	t := int(math.Floor(float64(d*m) * (1/beta - 1)))
+	dim := d*m + t
+	if dim > MaxDimension {
+		return nil, ErrBoundTooLarge
+	}
+
+	// powers of f, n and X
+	fPow := []poly.Poly{{big.NewInt(1)}}
+	for i := 1; i <= m; i++ {
+		fPow = append(fPow, mul(fPow[i-1], f))
+	}
+	xPow := []*big.Int{big.NewInt(1)}
+	for i := 1; i < dim; i++ {
+		xPow = append(xPow, new(big.Int).Mul(xPow[i-1], bound))
+	}
+	var rows []poly.Poly
+	for i := 0; i < m; i++ {
+		ni := new(big.Int).Exp(n, big.NewInt(int64(m-i)), nil)
+		for j := 0; j < d; j++ {
+			rows = append(rows, shift(scale(fPow[i], ni), j))
+		}
+	}
+	for i := 0; i < t; i++ {
+		rows = append(rows, shift(fPow[m], i))
+	}
+	basis := make([][]*big.Int, dim)
+	for i, g := range rows {
+		basis[i] = make([]*big.Int, dim)
+		for k := range basis[i] {
+			basis[i][k] = new(big.Int)
+			if k < len(g) {
+				basis[i][k].Mul(g[k], xPow[k])
+			}
+		}
+	}
+	reduced, err := lattice.LLL(basis, lattice.DefaultDelta)
+	if err != nil {
+		return nil, err
+	}
+
+	// the short vectors are polynomials with the root over the integers,
+	// the first one is usually enough
+	minBits := int(math.Floor(beta*logN)) - 1
+	lo := new(big.Int).Neg(bound)
+	for _, v := range reduced {
+		h := make(poly.Poly, dim)
+		for k := range h {
+			h[k] = new(big.Int).Quo(v[k], xPow[k])
+		}
+		h = trim(h)
+		if len(h) < 2 {
+			continue
+		}
+		var roots []*big.Int
+		for _, x := range integerRoots(h, lo, bound) {
+			// f(x0) must share a large enough factor with n
+			y := r.Eval(f, new(big.Int).Mod(x, n))
+			g := new(big.Int).GCD(nil, nil, y, n)
+			if y.Sign() == 0 || g.BitLen() >= minBits {
+				roots = append(roots, x)
+			}
+		}
+		if len(roots) > 0 {
+			return roots, nil
+		}
+	}
+	return nil, nil
+}
+
+// mul multiplies two polynomials over the integers
+func mul(a, b poly.Poly) poly.Poly {
+	p := make(poly.Poly, len(a)+len(b)-1)
+	for i := range p {
+		p[i] = new(big.Int)
+	}
+	t := new(big.Int)
+	for i := range a {
+		for j := range b {
+			p[i+j].Add(p[i+j], t.Mul(a[i], b[j]))
+		}
+	}
+	return p
+}
+
+// scale returns c * a over the integers
+func scale(a poly.Poly, c *big.Int) poly.Poly {
+	p := make(poly.Poly, len(a))
+	for i := range a {
+		p[i] = new(big.Int).Mul(a[i], c)
+	}
+	return p
+}
+
+// shift returns x^k * a
+func shift(a poly.Poly, k int) poly.Poly {
+	p := make(poly.Poly, k, k+len(a))
+	for i := range p {
+		p[i] = new(big.Int)
+	}
+	return append(p, a...)
+}
+
+// trim drops the leading zero coefficients
+func trim(a poly.Poly) poly.Poly {
+	for len(a) > 0 && a[len(a)-1].Sign() == 0 {
+		a = a[:len(a)-1]
+	}
+	return a
+}
+
+// eval returns a(x) over the integers
+func eval(a poly.Poly, x *big.Int) *big.Int {
+	y := new(big.Int)
+	for i := len(a) - 1; i >= 0; i-- {
+		y.Mul(y, x)
+		y.Add(y, a[i])
+	}
+	return y
+}
+
+// derivative returns a'
+func derivative(a poly.Poly) poly.Poly {
+	if len(a) < 2 {
+		return nil
+	}
+	p := make(poly.Poly, len(a)-1)
+	for i := range p {
+		p[i] = new(big.Int).Mul(a[i+1], big.NewInt(int64(i+1)))
+	}
+	return p
+}
+
+// integerRoots returns the integer roots of a in [lo, hi]
+func integerRoots(a poly.Poly, lo, hi *big.Int) []*big.Int {
+	var roots []*big.Int
+	for _, x := range crossings(a, lo, hi) {
+		if eval(a, x).Sign() == 0 {
+			roots = append(roots, x)
+		}
+	}
+	return roots
+}
+
+// crossings returns, in increasing order, the integers x in [lo, hi] such
+// that a(x) = 0 or a changes sign between x and x + 1.
+//
+// Between two consecutive real roots of a', a is monotone and has at most
+// one crossing, found by bisection. The roots of a' are located to the
+// nearest integers by the same function, recursively.
+func crossings(a poly.Poly, lo, hi *big.Int) []*big.Int {
+	a = trim(a)
+	if len(a) < 2 {
+		return nil
+	}
+	// the segments where a is monotone, up to one unit
+	points := []*big.Int{lo}
+	last := lo
+	for _, c := range crossings(derivative(a), lo, hi) {
+		for _, p := range []*big.Int{c, new(big.Int).Add(c, big.NewInt(1))} {
+			if p.Cmp(last) > 0 && p.Cmp(hi) < 0 {
+				points = append(points, p)
+				last = p
+			}
+		}
+	}
+	if hi.Cmp(last) > 0 {
+		points = append(points, hi)
+	}
+	var result []*big.Int
+	for i := 0; i+1 < len(points); i++ {
+		x, y := points[i], points[i+1]
+		sx := eval(a, x).Sign()
+		if sx == 0 {
+			result = append(result, x)
+			continue
+		}
+		if sy := eval(a, y).Sign(); sy == 0 || sy == sx {
+			continue
+		}
+		result = append(result, bisect(a, x, y, sx))
+	}
+	if eval(a, hi).Sign() == 0 {
+		result = append(result, hi)
+	}
+	return result
+}
+
+// bisect returns the crossing of a between lo and hi, where a(lo) has sign
+// s and a(hi) the opposite sign
+func bisect(a poly.Poly, lo, hi *big.Int, s int) *big.Int {
+	lo, hi = new(big.Int).Set(lo), new(big.Int).Set(hi)
+	one := big.NewInt(1)
+	for new(big.Int).Sub(hi, lo).Cmp(one) > 0 {
+		mid := new(big.Int).Add(lo, hi)
+		mid.Rsh(mid, 1)
+		sm := eval(a, mid).Sign()
+		if sm == 0 {
+			return mid
+		}
+		if sm == s {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
@@ -0,0 +1,79 @@
+package coppersmith
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/poly"
+)
+
+func TestIntegerRoots(t *testing.T) {
+	t.Parallel()
+	// (x - 3)(x + 5)(x - 1000)(2x - 1) = 2x^4 - 1997x^3 - 3032x^2 + 32015x - 15000
+	a := poly.Poly{big.NewInt(-15000), big.NewInt(32015), big.NewInt(-3032), big.NewInt(-1997), big.NewInt(2)}
+	var testcases = []struct {
+		lo, hi int64
+		roots  []int64
+	}{
+		{-10000, 10000, []int64{-5, 3, 1000}},
+		{-5, 1000, []int64{-5, 3, 1000}},
+		{0, 999, []int64{3}},
+		{4, 999, nil},
+	}
+	for i, tc := range testcases {
+		roots := integerRoots(a, big.NewInt(tc.lo), big.NewInt(tc.hi))
+		if len(roots) != len(tc.roots) {
+			t.Fatalf("testcase %d expected roots %v but got %v", i, tc.roots, roots)
+		}
+		for j := range roots {
+			if roots[j].Int64() != tc.roots[j] {
+				t.Fatalf("testcase %d expected roots %v but got %v", i, tc.roots, roots)
+			}
+		}
+	}
+}
+
+func TestSmallRoots(t *testing.T) {
+	t.Parallel()
+	p, err := rand.Prime(rand.Reader, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := rand.Prime(rand.Reader, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := new(big.Int).Mul(p, q)
+	r := poly.Ring{N: n}
+	// f(x) = (x - x0)(x^2 + ax + b) mod n with a root of 64 bits, well below
+	// n^(1/3) of 170 bits to keep the lattice small
+	x0, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := rand.Int(rand.Reader, n)
+	b, _ := rand.Int(rand.Reader, n)
+	f := r.Mul(r.New(new(big.Int).Neg(x0), big.NewInt(1)), r.New(b, a, big.NewInt(1)))
+	bound := new(big.Int).Lsh(big.NewInt(1), 64)
+	roots, err := SmallRoots(f, n, bound, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roots) != 1 || roots[0].Cmp(x0) != 0 {
+		t.Fatalf("expected the root %s but got %v", x0, roots)
+	}
+	// and a negative root
+	f = r.Mul(r.New(x0, big.NewInt(1)), r.New(b, a, big.NewInt(1)))
+	roots, err = SmallRoots(f, n, bound, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roots) != 1 || roots[0].Cmp(new(big.Int).Neg(x0)) != 0 {
+		t.Fatalf("expected the root -%s but got %v", x0, roots)
+	}
+	// n^(1/3) is out of reach
+	if _, err := SmallRoots(f, n, new(big.Int).Lsh(big.NewInt(1), 171), 1); err != ErrBoundTooLarge {
+		t.Fatalf("expected the bound to be too large but got %v", err)
+	}
+}
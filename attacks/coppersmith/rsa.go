@@ -0,0 +1,87 @@
+package coppersmith
+
+import (
+	"crypto/rsa"
+	"errors"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/poly"
+)
+
+// ErrNotFound is returned when no small root was found, the unknown part
+// is too large or the known part is wrong
+var ErrNotFound = errors.New("coppersmith: no small root found")
+
+// StereotypedMessage recovers an unpadded RSA message of the form prefix
+// || unknown || suffix, where unknown is unknownLen bytes long, from its
+// ciphertext. The unknown part must be smaller than about a fraction 1/e
+// of the modulus, a third of it for e = 3. The whole message is returned.
+//
+// The polynomial is f(x) = (a + 2^(8 len(suffix)) x)^e - c mod n, where a
+// holds the known bytes, and the unknown part is its small root.
+func StereotypedMessage(pub *rsa.PublicKey, ciphertext, prefix []byte, unknownLen int, suffix []byte) ([]byte, error) {
+	n := pub.N
+	k := (n.BitLen() + 7) / 8
+	if unknownLen < 1 || len(prefix)+unknownLen+len(suffix) > k {
+		return nil, errors.New("coppersmith: message larger than the modulus")
+	}
+	c := new(big.Int).SetBytes(ciphertext)
+	if c.Cmp(n) >= 0 {
+		return nil, errors.New("coppersmith: ciphertext out of range")
+	}
+	a := new(big.Int).SetBytes(prefix)
+	a.Lsh(a, uint(8*(unknownLen+len(suffix))))
+	a.Add(a, new(big.Int).SetBytes(suffix))
+	step := new(big.Int).Lsh(big.NewInt(1), uint(8*len(suffix)))
+	r := poly.Ring{N: n}
+	f := r.Sub(r.Pow(r.New(a, step), pub.E), r.New(c))
+	bound := new(big.Int).Lsh(big.NewInt(1), uint(8*unknownLen))
+	roots, err := SmallRoots(f, n, bound, 1)
+	if err != nil {
+		return nil, err
+	}
+	for _, x := range roots {
+		if x.Sign() < 0 || x.Cmp(bound) >= 0 {
+			continue
+		}
+		m := make([]byte, 0, len(prefix)+unknownLen+len(suffix))
+		m = append(m, prefix...)
+		m = append(m, x.FillBytes(make([]byte, unknownLen))...)
+		return append(m, suffix...), nil
+	}
+	return nil, ErrNotFound
+}
+
+// FactorWithHighBits factors n from the high bits of one of its prime
+// factors, p = high * 2^unknownBits + x with 0 <= x < 2^unknownBits. For a
+// balanced modulus, a bit more than half of the bits of p must be known.
+//
+// The polynomial is f(x) = high * 2^unknownBits + x, which has the small
+// root x modulo p >= n^beta, with beta the size of p relative to n.
+func FactorWithHighBits(n, high *big.Int, unknownBits int) (p, q *big.Int, err error) {
+	if high.Sign() <= 0 || unknownBits < 1 {
+		return nil, nil, errors.New("coppersmith: invalid known bits")
+	}
+	a := new(big.Int).Lsh(high, uint(unknownBits))
+	beta := float64(a.BitLen()-1) / float64(n.BitLen())
+	if beta > 1 {
+		return nil, nil, errors.New("coppersmith: the known bits are larger than n")
+	}
+	r := poly.Ring{N: n}
+	bound := new(big.Int).Lsh(big.NewInt(1), uint(unknownBits))
+	roots, err := SmallRoots(r.New(a, big.NewInt(1)), n, bound, beta)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, x := range roots {
+		if x.Sign() < 0 {
+			continue
+		}
+		p := new(big.Int).Add(a, x)
+		q, m := new(big.Int).QuoRem(n, p, new(big.Int))
+		if m.Sign() == 0 && q.Cmp(big.NewInt(1)) > 0 {
+			return p, q, nil
+		}
+	}
+	return nil, nil, ErrNotFound
+}
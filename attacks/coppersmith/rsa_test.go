@@ -0,0 +1,83 @@
+package coppersmith
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/attacks/rsalowe"
+)
+
+func TestStereotypedMessage(t *testing.T) {
+	t.Parallel()
+	priv, err := rsalowe.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := &priv.PublicKey
+	var testcases = []struct {
+		prefix, secret, suffix string
+	}{
+		{"your one time password for today is: ", "Tr0ub4dor&3", ""},
+		{"the key is ", "0123456789abcdef", ", keep it safe"},
+		{"", "only the end", " is unknown, the rest of the message is known and fixed"},
+	}
+	for i, tc := range testcases {
+		m := []byte(tc.prefix + tc.secret + tc.suffix)
+		c, err := rsalowe.EncryptRaw(pub, m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recovered, err := StereotypedMessage(pub, c, []byte(tc.prefix), len(tc.secret), []byte(tc.suffix))
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if !bytes.Equal(recovered, m) {
+			t.Fatalf("testcase %d expected %q but got %q", i, m, recovered)
+		}
+	}
+	// a wrong prefix finds nothing
+	m := []byte("the key is 0123456789abcdef, keep it safe")
+	c, _ := rsalowe.EncryptRaw(pub, m)
+	if _, err := StereotypedMessage(pub, c, []byte("the key was "), 16, []byte(", keep it safe")); err != ErrNotFound {
+		t.Fatalf("expected no root for a wrong prefix but got %v", err)
+	}
+	// more than a third of the modulus is unknown
+	if _, err := StereotypedMessage(pub, c, nil, 48, nil); err != ErrBoundTooLarge {
+		t.Fatalf("expected the unknown part to be too large but got %v", err)
+	}
+}
+
+func TestFactorWithHighBits(t *testing.T) {
+	t.Parallel()
+	p, err := rand.Prime(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := rand.Prime(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := new(big.Int).Mul(p, q)
+	for _, unknown := range []int{64, 150} {
+		high := new(big.Int).Rsh(p, uint(unknown))
+		fp, fq, err := FactorWithHighBits(n, high, unknown)
+		if err != nil {
+			t.Fatalf("%d unknown bits failed with %v", unknown, err)
+		}
+		if fp.Cmp(p) != 0 || fq.Cmp(q) != 0 {
+			t.Fatalf("%d unknown bits expected %x but got %x", unknown, p, fp)
+		}
+	}
+	// the known bits of the wrong prime
+	high := new(big.Int).Rsh(p, 100)
+	high.Add(high, big.NewInt(1<<20))
+	if _, _, err := FactorWithHighBits(n, high, 100); err != ErrNotFound {
+		t.Fatalf("expected wrong bits to fail but got %v", err)
+	}
+	// a quarter of n is the limit
+	if _, _, err := FactorWithHighBits(n, new(big.Int).Rsh(p, 260), 260); err != ErrBoundTooLarge {
+		t.Fatalf("expected 260 unknown bits to be too many but got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package dlog
+
+import (
+	"errors"
+	"math/big"
+)
+
+// BSGS returns x in [lo, hi) such that base^x = target, using Shanks'
+// baby-step giant-step algorithm.
+//
+// The baby steps base^j for j < m are stored in a table of m entries, then
+// the giant steps target * base^-(lo + i m) are looked up in it, so that
+// x = lo + i m + j. With m = sqrt(hi - lo) both phases take sqrt(hi - lo)
+// operations. memory caps m, and the giant steps grow to (hi - lo) /
+// memory to compensate. A memory of zero or less uses the square root.
+func BSGS(g Group, base, target Element, lo, hi *big.Int, memory int) (*big.Int, error) {
+	width := new(big.Int).Sub(hi, lo)
+	if lo.Sign() < 0 || width.Sign() <= 0 {
+		return nil, errors.New("dlog: invalid range")
+	}
+	// m = ceil(sqrt(width))
+	m := new(big.Int).Sub(width, big.NewInt(1))
+	m.Sqrt(m)
+	m.Add(m, big.NewInt(1))
+	if memory > 0 && m.Cmp(big.NewInt(int64(memory))) > 0 {
+		m.SetInt64(int64(memory))
+	}
+	if !m.IsInt64() || m.Int64() > 1<<32 {
+		return nil, errors.New("dlog: range too large for the baby steps table")
+	}
+	steps := int(m.Int64())
+	table := make(map[string]int, steps)
+	e := g.Identity()
+	for j := 0; j < steps; j++ {
+		key := g.Key(e)
+		if _, ok := table[key]; ok {
+			// base has an order lower than m, the table is complete
+			break
+		}
+		table[key] = j
+		e = g.Op(e, base)
+	}
+	// giant steps of base^-m from target * base^-lo
+	giant := g.Inverse(g.Exp(base, m))
+	gamma := g.Op(target, g.Inverse(g.Exp(base, lo)))
+	x := new(big.Int).Set(lo)
+	for x.Cmp(hi) < 0 {
+		if j, ok := table[g.Key(gamma)]; ok {
+			x.Add(x, big.NewInt(int64(j)))
+			if x.Cmp(hi) >= 0 {
+				break
+			}
+			return x, nil
+		}
+		gamma = g.Op(gamma, giant)
+		x.Add(x, m)
+	}
+	return nil, ErrNotFound
+}
@@ -0,0 +1,49 @@
+package dlog
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestBSGS(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		g      Group
+		base   Element
+		lo, hi *big.Int
+		memory int
+	}{
+		{modP, modPBase, big.NewInt(0), modPOrder, 0},
+		{modP, modPBase, big.NewInt(0), modPOrder, 1000},
+		{modP, modPBase, big.NewInt(1 << 20), big.NewInt(1<<20 + 12345), 0},
+		{curve, toyCurve.Generator(), big.NewInt(0), toyCurve.N, 0},
+		{curve, toyCurve.Generator(), big.NewInt(1 << 30), big.NewInt(1<<30 + 1<<24), 256},
+	}
+	for i, tc := range testcases {
+		width := new(big.Int).Sub(tc.hi, tc.lo)
+		x, _ := rand.Int(rand.Reader, width)
+		x.Add(x, tc.lo)
+		found, err := BSGS(tc.g, tc.base, tc.g.Exp(tc.base, x), tc.lo, tc.hi, tc.memory)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if found.Cmp(x) != 0 {
+			t.Fatalf("testcase %d expected %s but got %s", i, x, found)
+		}
+	}
+	// both ends of the range
+	lo, hi := big.NewInt(1000), big.NewInt(2000)
+	for _, x := range []int64{1000, 1999} {
+		found, err := BSGS(modP, modPBase, modP.Exp(modPBase, big.NewInt(x)), lo, hi, 7)
+		if err != nil || found.Int64() != x {
+			t.Fatalf("expected %d but got %v, %v", x, found, err)
+		}
+	}
+	if _, err := BSGS(modP, modPBase, modP.Exp(modPBase, big.NewInt(2000)), lo, hi, 0); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound but got %v", err)
+	}
+	if _, err := BSGS(modP, modPBase, modPBase, hi, lo, 0); err == nil {
+		t.Fatal("expected an empty range to be rejected")
+	}
+}
@@ -0,0 +1,122 @@
+// Package dlog implements generic algorithms for the discrete logarithm
+// problem: given g and h = g^x in a cyclic group, find x.
+//
+// Generic algorithms only use the group operation, so they work the same
+// in Zp* and on elliptic curves, and all need about sqrt(n) operations
+// for a group of order n. Baby-step giant-step gets there
+// deterministically with a table of sqrt(n) elements, or trades memory for
+// time with a smaller table. Pollard's rho needs no memory at all, and
+// Pollard's kangaroos find logarithms known to lie in an interval in the
+// square root of its width. 2^128 operations is out of reach, which is why
+// groups of 256 bits are secure, but a subgroup of order 2^40 or an
+// exponent of 40 bits is not, which the Pohlig-Hellman and small subgroup
+// attacks exploit.
+package dlog
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+// ErrNotFound is returned when the logarithm does not exist in the
+// searched range
+var ErrNotFound = errors.New("dlog: logarithm not found")
+
+// Element is an element of a Group, a *big.Int for ModP and an *ec.Point
+// for Curve
+type Element interface{}
+
+// Group is a group written multiplicatively
+type Group interface {
+	Identity() Element
+	// Op returns a * b
+	Op(a, b Element) Element
+	// Inverse returns a^-1
+	Inverse(a Element) Element
+	// Exp returns a^k for a non negative k
+	Exp(a Element, k *big.Int) Element
+	// Key returns a canonical encoding of a, equal elements have equal
+	// keys
+	Key(a Element) string
+}
+
+// ModP is the multiplicative group of integers modulo the prime P
+type ModP struct {
+	P *big.Int
+}
+
+// Identity returns 1
+func (g ModP) Identity() Element {
+	return big.NewInt(1)
+}
+
+// Op returns a * b mod p
+func (g ModP) Op(a, b Element) Element {
+	r := new(big.Int).Mul(a.(*big.Int), b.(*big.Int))
+	return r.Mod(r, g.P)
+}
+
+// Inverse returns a^-1 mod p
+func (g ModP) Inverse(a Element) Element {
+	return new(big.Int).ModInverse(a.(*big.Int), g.P)
+}
+
+// Exp returns a^k mod p
+func (g ModP) Exp(a Element, k *big.Int) Element {
+	return new(big.Int).Exp(a.(*big.Int), k, g.P)
+}
+
+// Key returns the bytes of a
+func (g ModP) Key(a Element) string {
+	return string(a.(*big.Int).Bytes())
+}
+
+// Curve is the group of points of an elliptic curve, written additively
+// elsewhere: Op is the point addition and Exp the scalar multiplication.
+//
+// Unlike ec.Curve.ScalarMult, Exp does not reduce the scalar modulo the
+// order of the base point, so it works for points of any order, even on a
+// curve with a different b coefficient since the addition formulas do not
+// use it.
+type Curve struct {
+	Curve *ec.Curve
+}
+
+// Identity returns the point at infinity
+func (g Curve) Identity() Element {
+	return ec.Infinity()
+}
+
+// Op returns a + b
+func (g Curve) Op(a, b Element) Element {
+	return g.Curve.Add(a.(*ec.Point), b.(*ec.Point))
+}
+
+// Inverse returns -a
+func (g Curve) Inverse(a Element) Element {
+	return g.Curve.Neg(a.(*ec.Point))
+}
+
+// Exp returns k*a with double and add
+func (g Curve) Exp(a Element, k *big.Int) Element {
+	p := a.(*ec.Point)
+	acc := ec.Infinity()
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		acc = g.Curve.Double(acc)
+		if k.Bit(i) == 1 {
+			acc = g.Curve.Add(acc, p)
+		}
+	}
+	return acc
+}
+
+// Key returns the coordinates of a
+func (g Curve) Key(a Element) string {
+	p := a.(*ec.Point)
+	if p.IsInfinity() {
+		return ""
+	}
+	return string(p.X.Bytes()) + "," + string(p.Y.Bytes())
+}
@@ -0,0 +1,68 @@
+package dlog
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+// toy groups of prime order q = 2^32 - 5: the squares modulo the safe
+// prime 2q + 1, and a subgroup of the supersingular curve y^2 = x^3 + 7
+// over a prime p = 6q' - 1 with q' = 4294967387, which has p + 1 points
+var (
+	modP      = ModP{P: big.NewInt(8589934583)}
+	modPBase  = big.NewInt(4)
+	modPOrder = big.NewInt(4294967291)
+
+	toyCurve = &ec.Curve{
+		Name: "toy",
+		P:    big.NewInt(25769804321),
+		N:    big.NewInt(4294967387),
+		A:    big.NewInt(0),
+		B:    big.NewInt(7),
+		Gx:   big.NewInt(4216250098),
+		Gy:   big.NewInt(4139770262),
+	}
+	curve = Curve{Curve: toyCurve}
+)
+
+func TestGroups(t *testing.T) {
+	t.Parallel()
+	if !modPOrder.ProbablyPrime(20) || !toyCurve.N.ProbablyPrime(20) {
+		t.Fatal("expected prime group orders")
+	}
+	var testcases = []struct {
+		g     Group
+		base  Element
+		order *big.Int
+	}{
+		{modP, modPBase, modPOrder},
+		{curve, toyCurve.Generator(), toyCurve.N},
+	}
+	for i, tc := range testcases {
+		id := tc.g.Key(tc.g.Identity())
+		if tc.g.Key(tc.g.Exp(tc.base, tc.order)) != id {
+			t.Fatalf("testcase %d expected the base to have order %s", i, tc.order)
+		}
+		x, _ := rand.Int(rand.Reader, tc.order)
+		y, _ := rand.Int(rand.Reader, tc.order)
+		a, b := tc.g.Exp(tc.base, x), tc.g.Exp(tc.base, y)
+		sum := new(big.Int).Add(x, y)
+		if tc.g.Key(tc.g.Op(a, b)) != tc.g.Key(tc.g.Exp(tc.base, sum)) {
+			t.Fatalf("testcase %d expected g^x g^y = g^(x+y)", i)
+		}
+		if tc.g.Key(tc.g.Op(a, tc.g.Inverse(a))) != id {
+			t.Fatalf("testcase %d expected a a^-1 = 1", i)
+		}
+		if tc.g.Key(tc.g.Op(a, tc.g.Identity())) != tc.g.Key(a) {
+			t.Fatalf("testcase %d expected a 1 = a", i)
+		}
+	}
+	// Exp agrees with the scalar multiplication of ec
+	k := big.NewInt(123456789)
+	if !curve.Exp(toyCurve.Generator(), k).(*ec.Point).Equal(toyCurve.ScalarBaseMult(k)) {
+		t.Fatal("expected Exp to match ScalarBaseMult")
+	}
+}
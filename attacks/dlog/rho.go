@@ -0,0 +1,161 @@
+package dlog
+
+import (
+	"errors"
+	"hash/fnv"
+	"io"
+	"math/big"
+)
+
+// partitions is the number of multipliers of the random walks, 20 behaves
+// like a truly random walk according to Teske
+const partitions = 20
+
+// partition returns the index of the multiplier applied to a
+func partition(g Group, a Element) int {
+	h := fnv.New32a()
+	h.Write([]byte(g.Key(a)))
+	return int(h.Sum32() % partitions)
+}
+
+// randomBelow returns a uniform integer in [0, n)
+func randomBelow(rand io.Reader, n *big.Int) (*big.Int, error) {
+	buf := make([]byte, (n.BitLen()+7)/8+8)
+	if _, err := io.ReadFull(rand, buf); err != nil {
+		return nil, err
+	}
+	x := new(big.Int).SetBytes(buf)
+	return x.Mod(x, n), nil
+}
+
+// Rho returns x such that base^x = target, where base has the prime order
+// order, with Pollard's rho algorithm.
+//
+// Each element of the walk is known as base^a target^b. The next one is
+// the element times one of partitions multipliers base^a_i target^b_i,
+// chosen by the hash of the element, which makes the walk look random
+// and eventually cycle after about sqrt(order) steps. Floyd's algorithm
+// detects the cycle with a tortoise and a hare running at twice its speed,
+// without storing anything, and the two representations of the same
+// element give a linear equation in x.
+func Rho(rand io.Reader, g Group, base, target Element, order *big.Int) (*big.Int, error) {
+	if order.Cmp(big.NewInt(2)) < 0 || !order.ProbablyPrime(20) {
+		return nil, errors.New("dlog: the order must be a prime")
+	}
+	if g.Key(g.Exp(target, order)) != g.Key(g.Identity()) {
+		return nil, ErrNotFound
+	}
+	type state struct {
+		e    Element
+		a, b *big.Int
+	}
+	var ma, mb [partitions]*big.Int
+	var mult [partitions]Element
+	step := func(s *state) {
+		i := partition(g, s.e)
+		s.e = g.Op(s.e, mult[i])
+		s.a.Add(s.a, ma[i])
+		s.a.Mod(s.a, order)
+		s.b.Add(s.b, mb[i])
+		s.b.Mod(s.b, order)
+	}
+	for attempt := 0; attempt < 10; attempt++ {
+		var err error
+		for i := range mult {
+			if ma[i], err = randomBelow(rand, order); err != nil {
+				return nil, err
+			}
+			if mb[i], err = randomBelow(rand, order); err != nil {
+				return nil, err
+			}
+			mult[i] = g.Op(g.Exp(base, ma[i]), g.Exp(target, mb[i]))
+		}
+		a0, err := randomBelow(rand, order)
+		if err != nil {
+			return nil, err
+		}
+		tortoise := &state{g.Exp(base, a0), a0, new(big.Int)}
+		hare := &state{tortoise.e, new(big.Int).Set(a0), new(big.Int)}
+		for {
+			step(tortoise)
+			step(hare)
+			step(hare)
+			if g.Key(tortoise.e) == g.Key(hare.e) {
+				break
+			}
+		}
+		// base^a1 target^b1 = base^a2 target^b2, so
+		// (b1 - b2) x = a2 - a1 mod order
+		db := new(big.Int).Sub(tortoise.b, hare.b)
+		db.Mod(db, order)
+		if db.Sign() == 0 {
+			// useless collision, start over
+			continue
+		}
+		x := new(big.Int).Sub(hare.a, tortoise.a)
+		x.Mul(x, db.ModInverse(db, order))
+		x.Mod(x, order)
+		if g.Key(g.Exp(base, x)) == g.Key(target) {
+			return x, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Kangaroo returns x in [lo, hi] such that base^x = target, with Pollard's
+// kangaroo, or lambda, algorithm.
+//
+// A tame kangaroo starts at base^hi and jumps forward by base^s for jump
+// sizes s that depend on where it lands, powers of two averaging about
+// sqrt(hi - lo) / 2. After about sqrt(hi - lo) jumps it stops and sets a
+// trap. A wild kangaroo starts at target and follows the same rule: once
+// it lands on a spot the tame one visited, it follows its path and falls
+// into the trap, and the distances they travelled give x. If the wild
+// kangaroo travels past the trap the search failed, which happens with a
+// small probability even when x is in the interval.
+func Kangaroo(g Group, base, target Element, lo, hi *big.Int) (*big.Int, error) {
+	width := new(big.Int).Sub(hi, lo)
+	if lo.Sign() < 0 || width.Sign() < 0 {
+		return nil, errors.New("dlog: invalid range")
+	}
+	// jump sizes 2^i for i < k, with a mean of about sqrt(width) / 2
+	k := (width.BitLen()+1)/2 + 1
+	jumps := make([]Element, k)
+	sizes := make([]*big.Int, k)
+	for i := range jumps {
+		sizes[i] = new(big.Int).Lsh(big.NewInt(1), uint(i))
+		jumps[i] = g.Exp(base, sizes[i])
+	}
+	jump := func(e Element) int {
+		h := fnv.New32a()
+		h.Write([]byte(g.Key(e)))
+		return int(h.Sum32() % uint32(k))
+	}
+	// the tame kangaroo makes 4 sqrt(width) jumps
+	n := new(big.Int).Sqrt(width)
+	n.Lsh(n, 2)
+	n.Add(n, big.NewInt(4))
+	tame := g.Exp(base, hi)
+	dTame := new(big.Int)
+	for i := new(big.Int); i.Cmp(n) < 0; i.Add(i, big.NewInt(1)) {
+		j := jump(tame)
+		tame = g.Op(tame, jumps[j])
+		dTame.Add(dTame, sizes[j])
+	}
+	trap := g.Key(tame)
+	// the wild kangaroo gives up once it has passed the trap
+	wild := target
+	dWild := new(big.Int)
+	limit := new(big.Int).Add(width, dTame)
+	for dWild.Cmp(limit) <= 0 {
+		if g.Key(wild) == trap {
+			// x + dWild = hi + dTame
+			x := new(big.Int).Add(hi, dTame)
+			return x.Sub(x, dWild), nil
+		}
+		j := jump(wild)
+		wild = g.Op(wild, jumps[j])
+		dWild.Add(dWild, sizes[j])
+	}
+	return nil, ErrNotFound
+}
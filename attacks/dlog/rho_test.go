@@ -0,0 +1,80 @@
+package dlog
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestRho(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		g     Group
+		base  Element
+		order *big.Int
+	}{
+		{modP, modPBase, modPOrder},
+		{curve, toyCurve.Generator(), toyCurve.N},
+	}
+	for i, tc := range testcases {
+		x, _ := rand.Int(rand.Reader, tc.order)
+		found, err := Rho(rand.Reader, tc.g, tc.base, tc.g.Exp(tc.base, x), tc.order)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if found.Cmp(x) != 0 {
+			t.Fatalf("testcase %d expected %s but got %s", i, x, found)
+		}
+	}
+	// -1 is not a square modulo a prime p = 3 mod 4, it is not in the
+	// subgroup generated by 4
+	minusOne := new(big.Int).Sub(modP.P, big.NewInt(1))
+	if _, err := Rho(rand.Reader, modP, modPBase, minusOne, modPOrder); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound but got %v", err)
+	}
+	if _, err := Rho(rand.Reader, modP, modPBase, modPBase, big.NewInt(4294967290)); err == nil {
+		t.Fatal("expected a composite order to be rejected")
+	}
+}
+
+func TestKangaroo(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		g      Group
+		base   Element
+		lo, hi *big.Int
+	}{
+		{modP, modPBase, big.NewInt(0), big.NewInt(1 << 24)},
+		{modP, modPBase, big.NewInt(1 << 31), big.NewInt(1<<31 + 1<<28)},
+		{curve, toyCurve.Generator(), big.NewInt(1 << 30), big.NewInt(1<<30 + 1<<24)},
+		{curve, toyCurve.Generator(), big.NewInt(5), big.NewInt(5)},
+	}
+	for i, tc := range testcases {
+		// x in [lo, hi], both ends included
+		width := new(big.Int).Sub(tc.hi, tc.lo)
+		width.Add(width, big.NewInt(1))
+		// the kangaroos fail with a small probability, retry with other
+		// logarithms rather than flaking
+		var err error
+		for attempt := 0; attempt < 3; attempt++ {
+			x, _ := rand.Int(rand.Reader, width)
+			x.Add(x, tc.lo)
+			var found *big.Int
+			found, err = Kangaroo(tc.g, tc.base, tc.g.Exp(tc.base, x), tc.lo, tc.hi)
+			if err == nil {
+				if found.Cmp(x) != 0 {
+					t.Fatalf("testcase %d expected %s but got %s", i, x, found)
+				}
+				break
+			}
+		}
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+	}
+	// far outside of the interval
+	_, err := Kangaroo(modP, modPBase, modP.Exp(modPBase, big.NewInt(1<<30)), big.NewInt(0), big.NewInt(1<<16))
+	if err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound but got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+// Command dsak recovers a DSA or ECDSA private key from signatures with a
+// known or repeated nonce.
+//
+//	dsak -curve secp256k1 [-pub 02...] [-k 1f...] [signatures.txt]
+//	dsak -q f4e3... [-k 1f...] [signatures.txt]
+//
+// Signatures are read from the file or standard input, one per line as
+// the hex encoded hash followed by the hex encoded DER or r || s
+// signature. Without -k, dsak looks for two signatures sharing a nonce.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"os"
+
+	"github.com/jvehent/badcrypto/attacks/dsak"
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/ecdsa"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("dsak: ")
+	curveName := flag.String("curve", "", "ECDSA curve, P-256 or secp256k1")
+	order := flag.String("q", "", "hex order of the DSA subgroup, instead of -curve")
+	pubHex := flag.String("pub", "", "hex SEC 1 public key to check candidates against, with -curve")
+	nonce := flag.String("k", "", "hex nonce of the signatures, if known")
+	flag.Parse()
+
+	pub, err := publicKey(*curveName, *order, *pubHex)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var in io.Reader = os.Stdin
+	if flag.NArg() > 0 {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		in = f
+	}
+	sigs, err := dsak.ReadSignatures(in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *nonce != "" {
+		raw, err := dsak.ParseHex(*nonce)
+		if err != nil {
+			log.Fatalf("invalid nonce: %v", err)
+		}
+		k := new(big.Int).SetBytes(raw)
+		for i, sig := range sigs {
+			if x, err := dsak.KnownNonce(pub, sig, k); err == nil {
+				fmt.Printf("signature %d was made with k\nprivate key: %x\n", i+1, x)
+				return
+			}
+		}
+		log.Fatal("no signature matches the nonce")
+	}
+	x, i, j, err := dsak.Scan(pub, sigs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("signatures %d and %d share a nonce\nprivate key: %x\n", i+1, j+1, x)
+}
+
+// publicKey returns the key candidates are checked against
+func publicKey(curveName, order, pubHex string) (dsak.PublicKey, error) {
+	if order != "" {
+		if curveName != "" || pubHex != "" {
+			return nil, fmt.Errorf("-q excludes -curve and -pub")
+		}
+		raw, err := dsak.ParseHex(order)
+		if err != nil {
+			return nil, fmt.Errorf("invalid order: %v", err)
+		}
+		return dsak.Order(new(big.Int).SetBytes(raw)), nil
+	}
+	var c *ec.Curve
+	switch curveName {
+	case "P-256", "p256", "prime256v1":
+		c = ec.P256()
+	case "secp256k1":
+		c = ec.Secp256k1()
+	default:
+		return nil, fmt.Errorf("unknown curve %q, use -curve or -q", curveName)
+	}
+	if pubHex == "" {
+		return dsak.Order(c.N), nil
+	}
+	raw, err := dsak.ParseHex(pubHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %v", err)
+	}
+	p, err := c.Unmarshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	return dsak.ECDSA(&ecdsa.PublicKey{Curve: c, Point: p}), nil
+}
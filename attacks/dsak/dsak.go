@@ -0,0 +1,156 @@
+// Package dsak recovers DSA and ECDSA private keys from signatures whose
+// nonce k is known or was used twice.
+//
+// Both schemes compute s = k^-1 (e + r x) mod q for the hash e, the
+// private key x and r derived from k alone. Knowing k gives x = (s k - e) /
+// r directly, and two signatures with the same k, which have the same r,
+// give k = (e1 - e2) / (s1 - s2) first. Signatures are accepted in their
+// DER or raw r || s encodings, in hex, so that artifacts found in the wild
+// or in a CTF can be fed in as they are. See the ecdsanonce package for
+// nonces that are only partially known.
+package dsak
+
+import (
+	"crypto/dsa"
+	"errors"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ecdsa"
+)
+
+// ErrNotFound is returned when no candidate private key matches the
+// public key
+var ErrNotFound = errors.New("dsak: private key not found")
+
+// Signature is a DSA or ECDSA signature (R, S) of Hash
+type Signature struct {
+	Hash []byte
+	R, S *big.Int
+}
+
+// PublicKey is what the recovery needs from a public key: the order of the
+// group, and a check of candidate private keys
+type PublicKey interface {
+	Order() *big.Int
+	Matches(x *big.Int) bool
+}
+
+type dsaKey struct {
+	pub *dsa.PublicKey
+}
+
+func (k dsaKey) Order() *big.Int { return k.pub.Q }
+
+func (k dsaKey) Matches(x *big.Int) bool {
+	return new(big.Int).Exp(k.pub.G, x, k.pub.P).Cmp(k.pub.Y) == 0
+}
+
+// DSA wraps a DSA public key
+func DSA(pub *dsa.PublicKey) PublicKey {
+	return dsaKey{pub}
+}
+
+type ecdsaKey struct {
+	pub *ecdsa.PublicKey
+}
+
+func (k ecdsaKey) Order() *big.Int { return k.pub.Curve.N }
+
+func (k ecdsaKey) Matches(x *big.Int) bool {
+	return k.pub.Curve.ScalarBaseMult(x).Equal(k.pub.Point)
+}
+
+// ECDSA wraps an ECDSA public key
+func ECDSA(pub *ecdsa.PublicKey) PublicKey {
+	return ecdsaKey{pub}
+}
+
+type orderOnly struct {
+	q *big.Int
+}
+
+func (k orderOnly) Order() *big.Int { return k.q }
+
+func (k orderOnly) Matches(x *big.Int) bool { return true }
+
+// Order stands for an unknown public key in a group of order q. Nothing
+// can be checked, and the first candidate is returned.
+func Order(q *big.Int) PublicKey {
+	return orderOnly{q}
+}
+
+// hashToInt keeps the leftmost bits of hash when it is longer than q, as
+// FIPS 186-4 specifies for both DSA and ECDSA
+func hashToInt(q *big.Int, hash []byte) *big.Int {
+	orderBits := q.BitLen()
+	orderBytes := (orderBits + 7) / 8
+	if len(hash) > orderBytes {
+		hash = hash[:orderBytes]
+	}
+	e := new(big.Int).SetBytes(hash)
+	if excess := len(hash)*8 - orderBits; excess > 0 {
+		e.Rsh(e, uint(excess))
+	}
+	return e
+}
+
+// KnownNonce returns the private key of pub from a signature made with the
+// nonce k
+func KnownNonce(pub PublicKey, sig Signature, k *big.Int) (*big.Int, error) {
+	q := pub.Order()
+	rInv := new(big.Int).ModInverse(sig.R, q)
+	if rInv == nil {
+		return nil, errors.New("dsak: invalid signature")
+	}
+	// x = (s k - e) / r
+	x := new(big.Int).Mul(sig.S, k)
+	x.Sub(x, hashToInt(q, sig.Hash))
+	x.Mul(x, rInv)
+	x.Mod(x, q)
+	if x.Sign() == 0 || !pub.Matches(x) {
+		return nil, ErrNotFound
+	}
+	return x, nil
+}
+
+// RepeatedNonce returns the private key of pub and the nonce from two
+// signatures of different hashes made with the same nonce. ECDSA
+// signatures (r, s) and (r, -s) are both valid, so the second one is also
+// tried with -s, which pub tells apart.
+func RepeatedNonce(pub PublicKey, sig1, sig2 Signature) (x, k *big.Int, err error) {
+	q := pub.Order()
+	if sig1.R.Cmp(sig2.R) != 0 {
+		return nil, nil, errors.New("dsak: the signatures do not share a nonce")
+	}
+	de := new(big.Int).Sub(hashToInt(q, sig1.Hash), hashToInt(q, sig2.Hash))
+	for _, s2 := range []*big.Int{sig2.S, new(big.Int).Sub(q, sig2.S)} {
+		// k = (e1 - e2) / (s1 - s2)
+		ds := new(big.Int).Sub(sig1.S, s2)
+		ds.Mod(ds, q)
+		if ds.ModInverse(ds, q) == nil {
+			continue
+		}
+		k := ds.Mul(ds, de)
+		k.Mod(k, q)
+		if x, err := KnownNonce(pub, sig1, k); err == nil {
+			return x, k, nil
+		}
+	}
+	return nil, nil, ErrNotFound
+}
+
+// Scan looks for two signatures sharing a nonce in sigs, and returns the
+// private key of pub and the indexes of the signatures
+func Scan(pub PublicKey, sigs []Signature) (x *big.Int, i, j int, err error) {
+	seen := make(map[string]int)
+	for j, sig := range sigs {
+		if i, ok := seen[string(sig.R.Bytes())]; ok {
+			if x, _, err := RepeatedNonce(pub, sigs[i], sig); err == nil {
+				return x, i, j, nil
+			}
+			continue
+		}
+		seen[string(sig.R.Bytes())] = j
+	}
+	return nil, 0, 0, ErrNotFound
+}
@@ -0,0 +1,95 @@
+package dsak
+
+import (
+	"crypto/dsa"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/attacks/ecdsanonce"
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/ecdsa"
+)
+
+func newDSAKey(t *testing.T) *dsa.PrivateKey {
+	priv := new(dsa.PrivateKey)
+	if err := dsa.GenerateParameters(&priv.Parameters, rand.Reader, dsa.L1024N160); err != nil {
+		t.Fatal(err)
+	}
+	if err := dsa.GenerateKey(priv, rand.Reader); err != nil {
+		t.Fatal(err)
+	}
+	return priv
+}
+
+func TestDSA(t *testing.T) {
+	t.Parallel()
+	priv := newDSAKey(t)
+	pub := DSA(&priv.PublicKey)
+	k, _ := rand.Int(rand.Reader, priv.Q)
+	h1, h2 := sha1.Sum([]byte("first")), sha1.Sum([]byte("second"))
+	sig1, err := SignDSAWithNonce(priv, h1[:], k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig2, err := SignDSAWithNonce(priv, h2[:], k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the fixture signs like crypto/dsa
+	if !dsa.Verify(&priv.PublicKey, h1[:], sig1.R, sig1.S) {
+		t.Fatal("expected a valid signature")
+	}
+	x, err := KnownNonce(pub, sig1, k)
+	if err != nil || x.Cmp(priv.X) != 0 {
+		t.Fatalf("expected %x but got %x, %v", priv.X, x, err)
+	}
+	x, found, err := RepeatedNonce(pub, sig1, sig2)
+	if err != nil || x.Cmp(priv.X) != 0 || found.Cmp(k) != 0 {
+		t.Fatalf("expected %x but got %x, %v", priv.X, x, err)
+	}
+	if _, err := KnownNonce(pub, sig1, new(big.Int).Add(k, big.NewInt(1))); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound but got %v", err)
+	}
+}
+
+func TestECDSA(t *testing.T) {
+	t.Parallel()
+	for _, c := range []*ec.Curve{ec.P256(), ec.Secp256k1()} {
+		priv, err := ecdsa.GenerateKey(rand.Reader, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub := ECDSA(&priv.PublicKey)
+		k, _ := c.RandomScalar(rand.Reader)
+		var sigs []Signature
+		for _, msg := range []string{"a", "b", "c"} {
+			h := sha256.Sum256([]byte(msg))
+			nonce := k
+			if msg == "a" {
+				nonce, _ = c.RandomScalar(rand.Reader)
+			}
+			s, err := ecdsanonce.SignWithNonce(priv, h[:], nonce)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sigs = append(sigs, Signature(s))
+		}
+		// low s normalization negates s
+		sigs[2].S.Sub(c.N, sigs[2].S)
+		x, i, j, err := Scan(pub, sigs)
+		if err != nil || x.Cmp(priv.D) != 0 || i != 1 || j != 2 {
+			t.Fatalf("%s expected %x from 1 and 2 but got %x from %d and %d, %v", c.Name, priv.D, x, i, j, err)
+		}
+		if _, _, _, err := Scan(pub, sigs[:2]); err != ErrNotFound {
+			t.Fatalf("%s expected ErrNotFound but got %v", c.Name, err)
+		}
+		// without the public key, the sign of s must be right
+		x, _, err = RepeatedNonce(Order(c.N), sigs[1], Signature{sigs[2].Hash, sigs[2].R, new(big.Int).Sub(c.N, sigs[2].S)})
+		if err != nil || x.Cmp(priv.D) != 0 {
+			t.Fatalf("%s expected %x but got %x, %v", c.Name, priv.D, x, err)
+		}
+	}
+}
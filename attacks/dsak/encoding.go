@@ -0,0 +1,76 @@
+package dsak
+
+import (
+	"bufio"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+)
+
+// ParseSignature decodes a signature of hash in the ASN.1 DER form of
+// X.509 and TLS, or as the concatenation of r and s of the same size
+func ParseSignature(hash, sig []byte) (Signature, error) {
+	var parsed struct {
+		R, S *big.Int
+	}
+	if rest, err := asn1.Unmarshal(sig, &parsed); err == nil && len(rest) == 0 {
+		if parsed.R.Sign() <= 0 || parsed.S.Sign() <= 0 {
+			return Signature{}, errors.New("dsak: invalid signature")
+		}
+		return Signature{Hash: hash, R: parsed.R, S: parsed.S}, nil
+	}
+	if len(sig) == 0 || len(sig)%2 != 0 {
+		return Signature{}, errors.New("dsak: signature is neither DER nor r || s")
+	}
+	half := len(sig) / 2
+	return Signature{Hash: hash, R: new(big.Int).SetBytes(sig[:half]), S: new(big.Int).SetBytes(sig[half:])}, nil
+}
+
+// ParseHex decodes hex, ignoring an optional 0x prefix, whitespace and
+// colons as printed by openssl
+func ParseHex(s string) ([]byte, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "0x")
+	s = strings.Map(func(r rune) rune {
+		if r == ':' || r == ' ' || r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, s)
+	return hex.DecodeString(s)
+}
+
+// ReadSignatures reads one signature per line, as the hex encoded hash and
+// signature separated by whitespace. Empty lines and lines starting with #
+// are ignored.
+func ReadSignatures(r io.Reader) ([]Signature, error) {
+	var sigs []Signature
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("dsak: line %d: expected a hash and a signature", line)
+		}
+		hash, err := ParseHex(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("dsak: line %d: invalid hash: %v", line, err)
+		}
+		raw, err := ParseHex(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("dsak: line %d: invalid signature: %v", line, err)
+		}
+		sig, err := ParseSignature(hash, raw)
+		if err != nil {
+			return nil, fmt.Errorf("dsak: line %d: %v", line, err)
+		}
+		sigs = append(sigs, sig)
+	}
+	return sigs, scanner.Err()
+}
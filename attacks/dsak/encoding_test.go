@@ -0,0 +1,72 @@
+package dsak
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/ecdsa"
+)
+
+func TestParseSignature(t *testing.T) {
+	t.Parallel()
+	priv, _ := ecdsa.GenerateKey(rand.Reader, ec.P256())
+	h := sha256.Sum256([]byte("hello"))
+	der, err := ecdsa.SignASN1(rand.Reader, priv, h[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := ParseSignature(h[:], der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ecdsa.Verify(&priv.PublicKey, h[:], sig.R, sig.S) {
+		t.Fatal("expected the DER signature to verify")
+	}
+	raw := append(sig.R.FillBytes(make([]byte, 32)), sig.S.FillBytes(make([]byte, 32))...)
+	sig2, err := ParseSignature(h[:], raw)
+	if err != nil || sig2.R.Cmp(sig.R) != 0 || sig2.S.Cmp(sig.S) != 0 {
+		t.Fatalf("expected the raw signature to match, got %v", err)
+	}
+	if _, err := ParseSignature(h[:], raw[:63]); err == nil {
+		t.Fatal("expected an odd length signature to be rejected")
+	}
+}
+
+func TestParseHex(t *testing.T) {
+	t.Parallel()
+	var testcases = []string{"0a1b2c", "0x0a1b2c", " 0a:1b:2c\n", "0A 1B 2C"}
+	for i, s := range testcases {
+		b, err := ParseHex(s)
+		if err != nil || fmt.Sprintf("%x", b) != "0a1b2c" {
+			t.Fatalf("testcase %d expected 0a1b2c but got %x, %v", i, b, err)
+		}
+	}
+	if _, err := ParseHex("0g"); err == nil {
+		t.Fatal("expected invalid hex to be rejected")
+	}
+}
+
+func TestReadSignatures(t *testing.T) {
+	t.Parallel()
+	input := `# hash signature
+00ff 0102
+
+0x0a 3006020101020102
+`
+	sigs, err := ReadSignatures(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sigs) != 2 || sigs[0].R.Int64() != 1 || sigs[0].S.Int64() != 2 || sigs[1].R.Int64() != 1 || sigs[1].S.Int64() != 2 || sigs[1].Hash[0] != 10 {
+		t.Fatalf("unexpected signatures %+v", sigs)
+	}
+	for i, bad := range []string{"00ff", "zz 0102", "00 zz", "00 010203"} {
+		if _, err := ReadSignatures(strings.NewReader(bad)); err == nil {
+			t.Fatalf("testcase %d expected %q to be rejected", i, bad)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package dsak
+
+import (
+	"crypto/dsa"
+	"errors"
+	"math/big"
+)
+
+// SignDSAWithNonce signs hash with the nonce k chosen by the caller, the
+// way a broken implementation does with a repeated or predictable k
+func SignDSAWithNonce(priv *dsa.PrivateKey, hash []byte, k *big.Int) (Signature, error) {
+	q := priv.Q
+	if k.Sign() <= 0 || k.Cmp(q) >= 0 {
+		return Signature{}, errors.New("dsak: nonce out of range")
+	}
+	// r = (g^k mod p) mod q
+	r := new(big.Int).Exp(priv.G, k, priv.P)
+	r.Mod(r, q)
+	// s = k^-1 (e + x r) mod q
+	s := new(big.Int).Mul(priv.X, r)
+	s.Add(s, hashToInt(q, hash))
+	s.Mul(s, new(big.Int).ModInverse(k, q))
+	s.Mod(s, q)
+	if r.Sign() == 0 || s.Sign() == 0 {
+		return Signature{}, errors.New("dsak: invalid nonce")
+	}
+	return Signature{Hash: append([]byte{}, hash...), R: r, S: s}, nil
+}
@@ -0,0 +1,131 @@
+// Package ecb implements the classic attacks on the electronic codebook
+// mode, which encrypts equal plaintext blocks to equal ciphertext blocks.
+//
+// Repeated blocks give ECB away. Against an oracle that encrypts attacker
+// input between a fixed prefix and a secret suffix, the attacker first
+// finds the block size and the prefix length, then aligns the suffix so a
+// single unknown byte ends a block, and matches that block against the
+// encryptions of all 256 candidates. The suffix is recovered one byte at a
+// time, in at most 256 queries per byte.
+package ecb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// maxBlockSize bounds the block size search
+const maxBlockSize = 64
+
+// Oracle encrypts attacker input, usually surrounded by data the attacker
+// does not know, in ECB mode under a key the attacker does not know
+type Oracle interface {
+	Encrypt(input []byte) []byte
+}
+
+// OracleFunc adapts a function to the Oracle interface
+type OracleFunc func(input []byte) []byte
+
+// Encrypt calls f(input)
+func (f OracleFunc) Encrypt(input []byte) []byte {
+	return f(input)
+}
+
+// Detect returns true if ct has two identical blocks, which in practice
+// only happens in ECB mode
+func Detect(ct []byte, blockSize int) bool {
+	seen := make(map[string]bool)
+	for i := 0; i+blockSize <= len(ct); i += blockSize {
+		block := string(ct[i : i+blockSize])
+		if seen[block] {
+			return true
+		}
+		seen[block] = true
+	}
+	return false
+}
+
+// IsECB feeds three identical blocks to the oracle, at least two of them
+// are aligned on block boundaries whatever the prefix, and looks for
+// repeated blocks
+func IsECB(o Oracle, blockSize int) bool {
+	return Detect(o.Encrypt(make([]byte, 3*blockSize)), blockSize)
+}
+
+// BlockSize grows the input until the ciphertext grows by a block. It
+// returns the block size and the combined length of the data the oracle
+// adds around the input.
+func BlockSize(o Oracle) (blockSize, extra int, err error) {
+	initial := len(o.Encrypt(nil))
+	for i := 1; i <= maxBlockSize; i++ {
+		if n := len(o.Encrypt(make([]byte, i))); n > initial {
+			return n - initial, initial - i, nil
+		}
+	}
+	return 0, 0, errors.New("ecb: ciphertext size never changed, not a block cipher?")
+}
+
+// PrefixLength finds the length of the data the oracle puts before the
+// input, by padding the input until two blocks of attacker bytes line up
+func PrefixLength(o Oracle, blockSize int) (int, error) {
+	for pad := 0; pad < blockSize; pad++ {
+		// two different fill bytes rule out a prefix ending with the fill
+		// byte and repeated blocks in the prefix itself
+		a := o.Encrypt(bytes.Repeat([]byte{0}, pad+2*blockSize))
+		b := o.Encrypt(bytes.Repeat([]byte{1}, pad+2*blockSize))
+		for i := 0; i+2*blockSize <= len(a) && i+2*blockSize <= len(b); i += blockSize {
+			if bytes.Equal(a[i:i+blockSize], a[i+blockSize:i+2*blockSize]) &&
+				bytes.Equal(b[i:i+blockSize], b[i+blockSize:i+2*blockSize]) &&
+				!bytes.Equal(a[i:i+blockSize], b[i:i+blockSize]) {
+				return i - pad, nil
+			}
+		}
+	}
+	return 0, errors.New("ecb: no aligned repeated blocks, not ECB?")
+}
+
+// RecoverSuffix recovers the secret the oracle appends to the input, one
+// byte at a time
+func RecoverSuffix(o Oracle) ([]byte, error) {
+	blockSize, extra, err := BlockSize(o)
+	if err != nil {
+		return nil, err
+	}
+	if !IsECB(o, blockSize) {
+		return nil, errors.New("ecb: oracle does not encrypt in ECB mode")
+	}
+	prefix, err := PrefixLength(o, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	// align pads the prefix to a block boundary, first is the index of the
+	// first block entirely under control
+	align := (blockSize - prefix%blockSize) % blockSize
+	first := (prefix + align) / blockSize
+	suffix := make([]byte, 0, extra-prefix)
+	for i := 0; i < extra-prefix; i++ {
+		// shift the suffix so byte i is the last one of block
+		fill := bytes.Repeat([]byte{'A'}, align+blockSize-1-i%blockSize)
+		block := first + i/blockSize
+		target := o.Encrypt(fill)[block*blockSize : (block+1)*blockSize]
+		// the blockSize-1 bytes preceding byte i are known
+		known := append(append([]byte{}, fill[align:]...), suffix...)
+		input := append(make([]byte, align), known[len(known)-blockSize+1:]...)
+		input = append(input, 0)
+		found := false
+		for guess := 0; guess < 256; guess++ {
+			input[len(input)-1] = byte(guess)
+			ct := o.Encrypt(input)
+			if bytes.Equal(ct[first*blockSize:(first+1)*blockSize], target) {
+				suffix = append(suffix, byte(guess))
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("ecb: no candidate matches byte %d of the suffix", i)
+		}
+	}
+	return suffix, nil
+}
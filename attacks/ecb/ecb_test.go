@@ -0,0 +1,79 @@
+package ecb
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"testing"
+)
+
+func TestRecoverSuffix(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		prefix, secret []byte
+	}{
+		{nil, []byte("Rollin' in my 5.0\nWith my rag-top down so my hair can blow\n")},
+		{[]byte("comment1=cooking%20MCs;userdata="), []byte(";comment2=%20like%20a%20pound%20of%20bacon")},
+		{[]byte("xyz"), []byte("A")},
+		{bytes.Repeat([]byte{0}, 37), []byte("secret after a prefix of zeros")},
+		// the prefix contains repeated blocks of its own
+		{bytes.Repeat([]byte{1}, 40), bytes.Repeat([]byte{'A'}, 33)},
+		{[]byte("prefix"), nil},
+	}
+	for i, tc := range testcases {
+		s, err := NewServer(rand.Reader, tc.prefix, tc.secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		blockSize, extra, err := BlockSize(s)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if blockSize != aes.BlockSize || extra != len(tc.prefix)+len(tc.secret) {
+			t.Fatalf("testcase %d expected block size 16 and %d extra bytes but got %d and %d",
+				i, len(tc.prefix)+len(tc.secret), blockSize, extra)
+		}
+		prefix, err := PrefixLength(s, blockSize)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if prefix != len(tc.prefix) {
+			t.Fatalf("testcase %d expected a prefix of %d bytes but got %d", i, len(tc.prefix), prefix)
+		}
+		secret, err := RecoverSuffix(s)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if !bytes.Equal(secret, tc.secret) {
+			t.Fatalf("testcase %d expected %q but got %q", i, tc.secret, secret)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+	key := make([]byte, 16)
+	rand.Read(key)
+	block, _ := aes.NewCipher(key)
+	s := &Server{block: block, secret: []byte("secret")}
+	if !IsECB(s, aes.BlockSize) {
+		t.Fatal("expected ECB to be detected")
+	}
+	cbc := OracleFunc(func(input []byte) []byte {
+		msg := append(append([]byte{}, input...), make([]byte, 16-len(input)%16)...)
+		iv := make([]byte, aes.BlockSize)
+		rand.Read(iv)
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(msg, msg)
+		return msg
+	})
+	if IsECB(cbc, aes.BlockSize) {
+		t.Fatal("expected CBC not to be detected as ECB")
+	}
+	if _, err := RecoverSuffix(cbc); err == nil {
+		t.Fatal("expected the attack to refuse a CBC oracle")
+	}
+	if Detect([]byte("0123456789abcdef"), 16) {
+		t.Fatal("expected a single block not to be detected as ECB")
+	}
+}
@@ -0,0 +1,42 @@
+package ecb
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"io"
+)
+
+// Server is a deliberately vulnerable service encrypting a prefix, the
+// input and a secret suffix with AES-128 in ECB mode, for exercises
+type Server struct {
+	block  cipher.Block
+	prefix []byte
+	secret []byte
+}
+
+// NewServer returns a server with a random key
+func NewServer(rand io.Reader, prefix, secret []byte) (*Server, error) {
+	key := make([]byte, 16)
+	if _, err := io.ReadFull(rand, key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{block: block, prefix: prefix, secret: secret}, nil
+}
+
+// Encrypt returns the ECB encryption of prefix || input || secret with
+// PKCS#7 padding
+func (s *Server) Encrypt(input []byte) []byte {
+	msg := append(append(append([]byte{}, s.prefix...), input...), s.secret...)
+	n := aes.BlockSize - len(msg)%aes.BlockSize
+	for i := 0; i < n; i++ {
+		msg = append(msg, byte(n))
+	}
+	for i := 0; i < len(msg); i += aes.BlockSize {
+		s.block.Encrypt(msg[i:], msg[i:])
+	}
+	return msg
+}
@@ -0,0 +1,96 @@
+// Package ecdsanonce implements private key recovery from ECDSA
+// signatures with bad nonces.
+//
+// A signature is s = k^-1 (e + r d) mod n, where k is the nonce, e the hash
+// and d the private key. Two signatures with the same k have the same r,
+// and subtracting them gives k = (e1 - e2) / (s1 - s2), then d = (s k -
+// e) / r. This broke the PlayStation 3 in 2010 and many Bitcoin wallets
+// since.
+//
+// Nonces that are merely not uniform are fatal too. Each signature gives
+// k = s^-1 r d + s^-1 e mod n, a linear relation between d and k. If k is
+// known to be small, because a few of its top bits are zero or known, the
+// hidden number problem of Boneh and Venkatesan finds d from enough such
+// relations: d is encoded in an unusually short vector of a lattice that
+// LLL recovers. Leaking 4 bits per signature through timing was enough
+// for the Minerva and TPM-Fail attacks in 2019. Deterministic nonces of
+// RFC 6979, or hedged ones like in the ecdsa package, prevent both.
+package ecdsanonce
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/ecdsa"
+)
+
+// Signature is an ECDSA signature (R, S) of Hash
+type Signature struct {
+	Hash []byte
+	R, S *big.Int
+}
+
+// ErrNotFound is returned when the private key could not be recovered
+var ErrNotFound = errors.New("ecdsanonce: private key not found")
+
+// hashToInt converts a hash to an integer like ecdsa does, keeping its
+// leftmost bits when it is longer than the order
+func hashToInt(c *ec.Curve, hash []byte) *big.Int {
+	orderBits := c.N.BitLen()
+	orderBytes := (orderBits + 7) / 8
+	if len(hash) > orderBytes {
+		hash = hash[:orderBytes]
+	}
+	e := new(big.Int).SetBytes(hash)
+	if excess := len(hash)*8 - orderBits; excess > 0 {
+		e.Rsh(e, uint(excess))
+	}
+	return e
+}
+
+// check returns the private key if d matches pub
+func check(pub *ecdsa.PublicKey, d *big.Int) *ecdsa.PrivateKey {
+	c := pub.Curve
+	d = new(big.Int).Mod(d, c.N)
+	if d.Sign() == 0 || !c.ScalarBaseMult(d).Equal(pub.Point) {
+		return nil
+	}
+	return &ecdsa.PrivateKey{PublicKey: *pub, D: d}
+}
+
+// RecoverFromReuse returns the private key of pub from two signatures of
+// different hashes made with the same nonce
+func RecoverFromReuse(pub *ecdsa.PublicKey, sig1, sig2 Signature) (*ecdsa.PrivateKey, error) {
+	c := pub.Curve
+	n := c.N
+	if sig1.R.Cmp(sig2.R) != 0 {
+		return nil, errors.New("ecdsanonce: the signatures do not share a nonce")
+	}
+	r := sig1.R
+	rInv := new(big.Int).ModInverse(r, n)
+	if rInv == nil {
+		return nil, errors.New("ecdsanonce: invalid signature")
+	}
+	e1, e2 := hashToInt(c, sig1.Hash), hashToInt(c, sig2.Hash)
+	de := new(big.Int).Sub(e1, e2)
+	// (r, s) and (r, -s) are both valid, so s2 is tried with both signs
+	for _, s2 := range []*big.Int{sig2.S, new(big.Int).Sub(n, sig2.S)} {
+		// k = (e1 - e2) / (s1 - s2)
+		ds := new(big.Int).Sub(sig1.S, s2)
+		ds.Mod(ds, n)
+		if ds.ModInverse(ds, n) == nil {
+			continue
+		}
+		k := ds.Mul(ds, de)
+		k.Mod(k, n)
+		// d = (s1 k - e1) / r
+		d := k.Mul(k, sig1.S)
+		d.Sub(d, e1)
+		d.Mul(d, rInv)
+		if priv := check(pub, d); priv != nil {
+			return priv, nil
+		}
+	}
+	return nil, ErrNotFound
+}
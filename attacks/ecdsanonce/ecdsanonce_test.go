@@ -0,0 +1,78 @@
+package ecdsanonce
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/ecdsa"
+)
+
+func TestSignWithNonce(t *testing.T) {
+	t.Parallel()
+	priv, err := ecdsa.GenerateKey(rand.Reader, ec.P256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte("hello"))
+	sig, err := SignWithNonce(priv, hash[:], big.NewInt(12345))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ecdsa.Verify(&priv.PublicKey, hash[:], sig.R, sig.S) {
+		t.Fatal("expected the signature to verify")
+	}
+	if _, err := SignWithNonce(priv, hash[:], new(big.Int)); err == nil {
+		t.Fatal("expected a zero nonce to be rejected")
+	}
+}
+
+func TestRecoverFromReuse(t *testing.T) {
+	t.Parallel()
+	for i, c := range []*ec.Curve{ec.P256(), ec.Secp256k1()} {
+		priv, err := ecdsa.GenerateKey(rand.Reader, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k, err := c.RandomScalar(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h1 := sha256.Sum256([]byte("first message"))
+		h2 := sha256.Sum256([]byte("second message"))
+		sig1, err := SignWithNonce(priv, h1[:], k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig2, err := SignWithNonce(priv, h2[:], k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recovered, err := RecoverFromReuse(&priv.PublicKey, sig1, sig2)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if recovered.D.Cmp(priv.D) != 0 {
+			t.Fatalf("testcase %d expected %x but got %x", i, priv.D, recovered.D)
+		}
+		// the malleated signature (r, n - s) is valid too
+		sig2.S.Sub(c.N, sig2.S)
+		recovered, err = RecoverFromReuse(&priv.PublicKey, sig1, sig2)
+		if err != nil {
+			t.Fatalf("testcase %d failed with a negated s with %v", i, err)
+		}
+		if recovered.D.Cmp(priv.D) != 0 {
+			t.Fatalf("testcase %d expected %x but got %x", i, priv.D, recovered.D)
+		}
+	}
+	// proper signatures do not share a nonce
+	priv, _ := ecdsa.GenerateKey(rand.Reader, ec.P256())
+	hash := sha256.Sum256([]byte("message"))
+	r1, s1, _ := ecdsa.Sign(rand.Reader, priv, hash[:])
+	r2, s2, _ := ecdsa.Sign(rand.Reader, priv, hash[:])
+	if _, err := RecoverFromReuse(&priv.PublicKey, Signature{hash[:], r1, s1}, Signature{hash[:], r2, s2}); err == nil {
+		t.Fatal("expected signatures with different nonces to be rejected")
+	}
+}
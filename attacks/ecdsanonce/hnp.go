@@ -0,0 +1,108 @@
+package ecdsanonce
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ecdsa"
+	"github.com/jvehent/badcrypto/lattice"
+)
+
+// PartialSignature is a signature whose nonce is known up to its low bits,
+// k = KnownNonce + x with 0 <= x < 2^unknownBits. KnownNonce holds the
+// leaked high bits of the nonce, shifted in place, and is zero or nil for
+// nonces that are just short.
+type PartialSignature struct {
+	Signature
+	KnownNonce *big.Int
+}
+
+// RecoverFromShortNonces returns the private key of pub from signatures
+// whose nonces are all lower than 2^nonceBits, that is with their top bits
+// set to zero by a biased generator. About 1.3 * n.BitLen() / bias
+// signatures are needed, with bias = n.BitLen() - nonceBits.
+func RecoverFromShortNonces(pub *ecdsa.PublicKey, sigs []Signature, nonceBits int) (*ecdsa.PrivateKey, error) {
+	partial := make([]PartialSignature, len(sigs))
+	for i, sig := range sigs {
+		partial[i] = PartialSignature{Signature: sig}
+	}
+	return RecoverFromPartialNonces(pub, partial, nonceBits)
+}
+
+// RecoverFromPartialNonces returns the private key of pub from signatures
+// whose nonces are known except for their low unknownBits bits, by solving
+// the hidden number problem with LLL.
+//
+// Each signature gives x_i = t_i d + u_i mod n with t_i = r_i / s_i, u_i =
+// e_i / s_i - KnownNonce_i, and x_i lower than B = 2^unknownBits. The
+// lattice spanned by the rows of
+//
+//	n^2  0   ...  0  0
+//	0    n^2 ...  0  0
+//	...
+//	n t1 n t2 ... B  0
+//	n u1 n u2 ... 0  n B
+//
+// holds the short vector (n x_1, ..., n x_m, d B, n B), from which d is
+// read. The x_i are centered around zero to make that vector shorter.
+func RecoverFromPartialNonces(pub *ecdsa.PublicKey, sigs []PartialSignature, unknownBits int) (*ecdsa.PrivateKey, error) {
+	c := pub.Curve
+	n := c.N
+	if unknownBits < 1 || unknownBits >= n.BitLen() {
+		return nil, errors.New("ecdsanonce: invalid number of unknown bits")
+	}
+	m := len(sigs)
+	if m < 2 {
+		return nil, errors.New("ecdsanonce: at least two signatures are needed")
+	}
+	b := new(big.Int).Lsh(big.NewInt(1), uint(unknownBits))
+	halfB := new(big.Int).Rsh(b, 1)
+	n2 := new(big.Int).Mul(n, n)
+	basis := make([][]*big.Int, m+2)
+	for i := range basis {
+		basis[i] = make([]*big.Int, m+2)
+		for j := range basis[i] {
+			basis[i][j] = new(big.Int)
+		}
+	}
+	t, u := basis[m], basis[m+1]
+	for i, sig := range sigs {
+		if sig.R.Sign() <= 0 || sig.R.Cmp(n) >= 0 || sig.S.Sign() <= 0 || sig.S.Cmp(n) >= 0 {
+			return nil, errors.New("ecdsanonce: invalid signature")
+		}
+		sInv := new(big.Int).ModInverse(sig.S, n)
+		basis[i][i].Set(n2)
+		// t_i = r / s
+		ti := new(big.Int).Mul(sig.R, sInv)
+		t[i].Mul(ti.Mod(ti, n), n)
+		// u_i = e / s - known - B/2, so that x_i - B/2 is centered
+		ui := new(big.Int).Mul(hashToInt(c, sig.Hash), sInv)
+		if sig.KnownNonce != nil {
+			ui.Sub(ui, sig.KnownNonce)
+		}
+		ui.Sub(ui, halfB)
+		u[i].Mul(ui.Mod(ui, n), n)
+	}
+	t[m].Set(b)
+	u[m+1].Mul(n, b)
+
+	reduced, err := lattice.LLL(basis, lattice.DefaultDelta)
+	if err != nil {
+		return nil, err
+	}
+	nb := u[m+1]
+	for _, v := range reduced {
+		if v[m+1].CmpAbs(nb) != 0 {
+			continue
+		}
+		// v = +-(..., d B, n B)
+		d := new(big.Int).Quo(v[m], b)
+		if v[m+1].Sign() < 0 {
+			d.Neg(d)
+		}
+		if priv := check(pub, d); priv != nil {
+			return priv, nil
+		}
+	}
+	return nil, ErrNotFound
+}
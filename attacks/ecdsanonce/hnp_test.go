@@ -0,0 +1,98 @@
+package ecdsanonce
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/ecdsa"
+)
+
+// signatures returns count signatures by priv with nonces of nonceBits
+// bits, and the nonces
+func signatures(t *testing.T, priv *ecdsa.PrivateKey, count, nonceBits int) ([]Signature, []*big.Int) {
+	var sigs []Signature
+	var nonces []*big.Int
+	for i := 0; i < count; i++ {
+		k, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), uint(nonceBits)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nonceBits == priv.Curve.N.BitLen() {
+			k.Mod(k, priv.Curve.N)
+		}
+		hash := sha256.Sum256([]byte(fmt.Sprintf("message %d", i)))
+		sig, err := SignWithNonce(priv, hash[:], k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigs = append(sigs, sig)
+		nonces = append(nonces, k)
+	}
+	return sigs, nonces
+}
+
+func TestRecoverFromShortNonces(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		curve           *ec.Curve
+		nonceBits, sigs int
+	}{
+		{ec.P256(), 128, 3},
+		{ec.Secp256k1(), 192, 6},
+		{ec.P256(), 224, 12},
+	}
+	for i, tc := range testcases {
+		priv, err := ecdsa.GenerateKey(rand.Reader, tc.curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigs, _ := signatures(t, priv, tc.sigs, tc.nonceBits)
+		recovered, err := RecoverFromShortNonces(&priv.PublicKey, sigs, tc.nonceBits)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if recovered.D.Cmp(priv.D) != 0 {
+			t.Fatalf("testcase %d expected %x but got %x", i, priv.D, recovered.D)
+		}
+	}
+}
+
+func TestRecoverFromPartialNonces(t *testing.T) {
+	t.Parallel()
+	priv, err := ecdsa.GenerateKey(rand.Reader, ec.P256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// full size nonces, of which the top 64 bits leaked
+	const unknownBits = 192
+	sigs, nonces := signatures(t, priv, 6, 256)
+	partial := make([]PartialSignature, len(sigs))
+	for i := range sigs {
+		known := new(big.Int).Rsh(nonces[i], unknownBits)
+		partial[i] = PartialSignature{Signature: sigs[i], KnownNonce: known.Lsh(known, unknownBits)}
+	}
+	recovered, err := RecoverFromPartialNonces(&priv.PublicKey, partial, unknownBits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recovered.D.Cmp(priv.D) != 0 {
+		t.Fatalf("expected %x but got %x", priv.D, recovered.D)
+	}
+}
+
+func TestRecoverFromUniformNonces(t *testing.T) {
+	t.Parallel()
+	// uniform nonces do not leak anything
+	priv, err := ecdsa.GenerateKey(rand.Reader, ec.P256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs, _ := signatures(t, priv, 6, 256)
+	if _, err := RecoverFromShortNonces(&priv.PublicKey, sigs, 192); err != ErrNotFound {
+		t.Fatalf("expected the key not to be found but got %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+package ecdsanonce
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ecdsa"
+)
+
+// SignWithNonce signs hash with the nonce k chosen by the caller, the
+// way a broken implementation does with a repeated, predictable or biased
+// k
+func SignWithNonce(priv *ecdsa.PrivateKey, hash []byte, k *big.Int) (Signature, error) {
+	c := priv.Curve
+	n := c.N
+	if k.Sign() <= 0 || k.Cmp(n) >= 0 {
+		return Signature{}, errors.New("ecdsanonce: nonce out of range")
+	}
+	r := new(big.Int).Mod(c.ScalarBaseMult(k).X, n)
+	// s = k^-1 * (e + r*d) mod n
+	s := new(big.Int).Mul(r, priv.D)
+	s.Add(s, hashToInt(c, hash))
+	s.Mul(s, new(big.Int).ModInverse(k, n))
+	s.Mod(s, n)
+	if r.Sign() == 0 || s.Sign() == 0 {
+		return Signature{}, errors.New("ecdsanonce: invalid nonce")
+	}
+	return Signature{Hash: append([]byte{}, hash...), R: r, S: s}, nil
+}
@@ -0,0 +1,232 @@
+// Package ecm factors integers with Lenstra's elliptic curve method, which
+// finds a prime factor p in a time that depends on the size of p rather
+// than on the size of n.
+//
+// Pollard's p - 1 method finds p when p - 1 is smooth: a^k = 1 mod p for
+// any k made of all the small prime powers, and gcd(a^k - 1, n) reveals
+// p. ECM replaces the group of integers modulo p, whose order p - 1 is
+// fixed, by the points of a random curve modulo p, whose order is random
+// in [p + 1 - 2 sqrt(p), p + 1 + 2 sqrt(p)]: when it is smooth, kP is the
+// point at infinity modulo p but not modulo n, and its Z coordinate
+// shares p with n. Each curve is another chance, which is why the strong
+// primes of X9.31 do not protect RSA against ECM.
+//
+// Stage 1 multiplies a point by all the prime powers up to B1, stage 2
+// then tries each prime q between B1 and B2 at the cost of a
+// multiplication modulo n, catching orders with one larger prime. The
+// curves use Suyama's parametrization in Montgomery form and only the x
+// coordinate of points. ECM is the method of choice for factors of 20 to
+// 40 digits, and holds the record for the largest factor found, 83 digits;
+// RSA moduli with two 300 digits primes are far out of its reach.
+package ecm
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/primes"
+)
+
+var one = big.NewInt(1)
+
+var (
+	// ErrPrime is returned when n is prime, or too likely so to be
+	// factored
+	ErrPrime = errors.New("ecm: n is prime")
+	// ErrNotFound is returned when no curve found a factor
+	ErrNotFound = errors.New("ecm: no factor found")
+)
+
+// Params bound the search for a factor
+type Params struct {
+	// B1 bounds the prime powers of stage 1
+	B1 uint64
+	// B2 bounds the primes of stage 2, 100 B1 when zero
+	B2 uint64
+	// Curves is the number of curves tried
+	Curves int
+}
+
+// levels are the parameters Factor tries one after the other, each
+// expected to find a factor of that many digits
+var levels = []struct {
+	digits int
+	Params
+}{
+	{15, Params{B1: 2000, Curves: 40}},
+	{20, Params{B1: 11000, Curves: 150}},
+	{25, Params{B1: 50000, Curves: 500}},
+	{30, Params{B1: 250000, Curves: 1200}},
+	{35, Params{B1: 1000000, Curves: 3000}},
+}
+
+// stage2Width is D, stage 2 reaches each prime q as mD ± j for j coprime
+// with D and below D/2: 2310 = 2 * 3 * 5 * 7 * 11 leaves 240 values of j
+const stage2Width = 2310
+
+// Factor returns a factor of n other than 1 and n, looking for factors of
+// 15 digits, then 20 and so on up to 35 digits. Small factors are found
+// first, but not necessarily the smallest one.
+func Factor(random io.Reader, n *big.Int) (*big.Int, error) {
+	for _, level := range levels {
+		f, err := FactorParams(random, n, level.Params)
+		if err != ErrNotFound {
+			return f, err
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// FactorParams returns a factor of n other than 1 and n, trying
+// params.Curves random curves
+func FactorParams(random io.Reader, n *big.Int, params Params) (*big.Int, error) {
+	if n.Cmp(big.NewInt(4)) < 0 {
+		return nil, errors.New("ecm: n must be at least 4")
+	}
+	if params.B1 < 2 || params.Curves < 1 {
+		return nil, errors.New("ecm: B1 and the number of curves must be positive")
+	}
+	if n.ProbablyPrime(20) {
+		return nil, ErrPrime
+	}
+	if n.Bit(0) == 0 {
+		return big.NewInt(2), nil
+	}
+	b2 := params.B2
+	if b2 == 0 {
+		b2 = 100 * params.B1
+	}
+	k := stage1Multiplier(params.B1)
+	var isPrime []bool
+	if b2 > params.B1 {
+		isPrime = primeTable(b2 + stage2Width/2)
+	}
+	// sigma in [6, n-1], 0 to 5 give singular curves
+	bound := new(big.Int).Sub(n, big.NewInt(6))
+	for i := 0; i < params.Curves; i++ {
+		sigma, err := rand.Int(random, bound)
+		if err != nil {
+			return nil, err
+		}
+		sigma.Add(sigma, big.NewInt(6))
+		c, p, g := suyama(n, sigma)
+		if g != nil {
+			return g, nil
+		}
+		if c == nil {
+			continue
+		}
+		q := c.mul(p, k)
+		g = new(big.Int).GCD(nil, nil, q.z, n)
+		if g.Cmp(one) != 0 {
+			if g.Cmp(n) != 0 {
+				return g, nil
+			}
+			// the order was smooth modulo every factor at once
+			continue
+		}
+		if isPrime == nil {
+			continue
+		}
+		if g := c.stage2(q, params.B1, b2, isPrime); g != nil {
+			return g, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// stage1Multiplier returns the product of the largest powers of each
+// prime that are at most b1
+func stage1Multiplier(b1 uint64) *big.Int {
+	k := big.NewInt(1)
+	for _, p := range primes.Below(int(b1)) {
+		q := uint64(p)
+		for q*uint64(p) <= b1 {
+			q *= uint64(p)
+		}
+		k.Mul(k, new(big.Int).SetUint64(q))
+	}
+	return k
+}
+
+// primeTable returns a table of the primality of the integers up to n
+func primeTable(n uint64) []bool {
+	table := make([]bool, n+1)
+	for _, p := range primes.Below(int(n)) {
+		table[p] = true
+	}
+	return table
+}
+
+// stage2 returns a factor of n if qQ is the point at infinity modulo it
+// for some prime q in (b1, b2]. The x-coordinates of mDQ and jQ are the
+// same when (mD ± j)Q is the point at infinity, so the product of their
+// cross differences X_m Z_j - X_j Z_m over all the pairs with a prime
+// mD ± j shares that factor with n.
+func (c *curve) stage2(q *point, b1, b2 uint64, isPrime []bool) *big.Int {
+	const d = stage2Width
+	// jQ for odd j below D/2, and 2Q to step from one to the next
+	var js []uint64
+	var baby []*point
+	q2 := c.double(q)
+	var prev *point
+	cur := q
+	for j := uint64(1); j < d/2; j += 2 {
+		if gcdWord(j, d) == 1 {
+			js = append(js, j)
+			baby = append(baby, cur)
+		}
+		// (j+2)Q = jQ + 2Q given their difference (j-2)Q, and 3Q = 2Q + Q
+		// given Q
+		var next *point
+		if j == 1 {
+			next = c.add(q2, q, q)
+		} else {
+			next = c.add(cur, q2, prev)
+		}
+		prev, cur = cur, next
+	}
+
+	// giant steps mDQ, each from the previous two
+	dq := c.mul(q, big.NewInt(d))
+	m := b1 / d
+	r := &point{big.NewInt(1), big.NewInt(0)}
+	if m > 0 {
+		r = c.mul(q, new(big.Int).SetUint64(m*d))
+	}
+	rNext := c.mul(q, new(big.Int).SetUint64((m+1)*d))
+	acc := big.NewInt(1)
+	t := new(big.Int)
+	for ; m*d <= b2+d/2; m++ {
+		for i, j := range js {
+			lo, hi := m*d-j, m*d+j
+			if !(m*d >= j && lo > b1 && lo <= b2 && isPrime[lo]) && !(hi > b1 && hi <= b2 && isPrime[hi]) {
+				continue
+			}
+			t.Mul(r.x, baby[i].z)
+			acc.Mul(acc, t.Sub(t, new(big.Int).Mul(baby[i].x, r.z))).Mod(acc, c.n)
+		}
+		next := c.add(rNext, dq, r)
+		if r.z.Sign() == 0 {
+			// the difference is the point at infinity, which add does
+			// not handle: 2DQ is a doubling
+			next = c.double(rNext)
+		}
+		r, rNext = rNext, next
+	}
+	g := acc.GCD(nil, nil, acc, c.n)
+	if g.Cmp(one) == 0 || g.Cmp(c.n) == 0 {
+		return nil
+	}
+	return g
+}
+
+// gcdWord returns the greatest common divisor of a and b
+func gcdWord(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,116 @@
+package ecm
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func fromString(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("bad number " + s)
+	}
+	return n
+}
+
+func TestFactor(t *testing.T) {
+	t.Parallel()
+	// a medium factor of a larger composite, the other factor is 2^127 - 1
+	// or 2^89 - 1
+	var testcases = []struct {
+		p, q string
+	}{
+		{"1000003", "170141183460469231731687303715884105727"},
+		{"100000000000031", "618970019642690137449562111"},
+		{"10000000000000061", "170141183460469231731687303715884105727"},
+	}
+	for i, tc := range testcases {
+		p, q := fromString(tc.p), fromString(tc.q)
+		n := new(big.Int).Mul(p, q)
+		f, err := Factor(rand.Reader, n)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if f.Cmp(p) != 0 && f.Cmp(q) != 0 {
+			t.Fatalf("testcase %d expected %s or %s but got %s", i, p, q, f)
+		}
+	}
+}
+
+func TestFactorErrors(t *testing.T) {
+	t.Parallel()
+	prime := fromString("170141183460469231731687303715884105727")
+	if _, err := Factor(rand.Reader, prime); err != ErrPrime {
+		t.Fatalf("expected ErrPrime but got %v", err)
+	}
+	if f, err := Factor(rand.Reader, new(big.Int).Lsh(prime, 1)); err != nil || f.Int64() != 2 {
+		t.Fatalf("expected 2 but got %v, %v", f, err)
+	}
+	if _, err := Factor(rand.Reader, big.NewInt(3)); err == nil {
+		t.Fatal("expected 3 to be rejected")
+	}
+	// two 20 digits primes are out of reach of a single small curve
+	n := new(big.Int).Mul(fromString("10000000000000000051"), fromString("100000000000000000039"))
+	if _, err := FactorParams(rand.Reader, n, Params{B1: 100, B2: 100, Curves: 1}); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound but got %v", err)
+	}
+	if _, err := FactorParams(rand.Reader, n, Params{}); err == nil {
+		t.Fatal("expected empty parameters to be rejected")
+	}
+}
+
+func TestStage2(t *testing.T) {
+	t.Parallel()
+	// find a curve modulo p whose order is B1-smooth but for one prime in
+	// (B1, B2]: stage 1 must miss p and stage 2 find it
+	const p, b1, b2 = 100003, 50, 5000
+	q := fromString("170141183460469231731687303715884105727")
+	n := new(big.Int).Mul(big.NewInt(p), q)
+	isPrime := primeTable(b2 + stage2Width/2)
+	k := stage1Multiplier(b1)
+	found := false
+	for sigma := int64(6); sigma < 10000 && !found; sigma++ {
+		cp, point, g := suyama(big.NewInt(p), big.NewInt(sigma))
+		if cp == nil || g != nil {
+			continue
+		}
+		order := curveOrder(cp, point, p)
+		large := largestFactor(order)
+		if large <= b1 || large > b2 || !divides(k, order/large) {
+			continue
+		}
+		found = true
+		c, start, _ := suyama(n, big.NewInt(sigma))
+		r := c.mul(start, k)
+		if new(big.Int).GCD(nil, nil, r.z, n).Cmp(one) != 0 {
+			t.Fatalf("sigma %d expected stage 1 to find no factor", sigma)
+		}
+		f := c.stage2(r, b1, b2, isPrime)
+		if f == nil || f.Int64() != p {
+			t.Fatalf("sigma %d with order %d expected %d but got %v", sigma, order, p, f)
+		}
+	}
+	if !found {
+		t.Fatal("expected a curve with a stage 2 order")
+	}
+}
+
+// largestFactor returns the largest prime factor of n
+func largestFactor(n int64) int64 {
+	largest := int64(1)
+	for d := int64(2); d*d <= n; d++ {
+		for n%d == 0 {
+			largest, n = d, n/d
+		}
+	}
+	if n > largest {
+		largest = n
+	}
+	return largest
+}
+
+// divides returns true if d divides k
+func divides(k *big.Int, d int64) bool {
+	return new(big.Int).Mod(k, big.NewInt(d)).Sign() == 0
+}
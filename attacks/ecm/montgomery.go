@@ -0,0 +1,96 @@
+package ecm
+
+import "math/big"
+
+// curve is a Montgomery curve By^2 = x^3 + Ax^2 + x modulo n. Only the
+// x-coordinate of points is used, in projective coordinates X:Z, which
+// needs no inversion and no B: a24 = (A + 2) / 4 is all the arithmetic
+// needs.
+type curve struct {
+	n   *big.Int
+	a24 *big.Int
+}
+
+// point is the projective x-coordinate X/Z of a point, Z = 0 being the
+// point at infinity
+type point struct {
+	x, z *big.Int
+}
+
+// suyama returns the curve and starting point of Suyama's parametrization
+// for sigma, whose group order is a multiple of 12: one prime factor
+// fewer for the order to be smooth. It returns a factor of n instead if
+// an inversion fails, or nil for both if sigma is degenerate.
+func suyama(n, sigma *big.Int) (*curve, *point, *big.Int) {
+	// u = sigma^2 - 5, v = 4 sigma, x0 = u^3, z0 = v^3
+	u := new(big.Int).Mul(sigma, sigma)
+	u.Sub(u, big.NewInt(5)).Mod(u, n)
+	v := new(big.Int).Lsh(sigma, 2)
+	v.Mod(v, n)
+	x0 := new(big.Int).Exp(u, big.NewInt(3), n)
+	z0 := new(big.Int).Exp(v, big.NewInt(3), n)
+	// a24 = (v - u)^3 (3u + v) / (16 u^3 v)
+	num := new(big.Int).Sub(v, u)
+	num.Exp(num.Mod(num, n), big.NewInt(3), n)
+	t := new(big.Int).Mul(u, big.NewInt(3))
+	t.Add(t, v)
+	num.Mul(num, t).Mod(num, n)
+	den := new(big.Int).Mul(x0, v)
+	den.Lsh(den, 4).Mod(den, n)
+	g := new(big.Int).GCD(nil, nil, den, n)
+	if g.Cmp(n) == 0 {
+		return nil, nil, nil
+	}
+	if g.Cmp(one) != 0 {
+		return nil, nil, g
+	}
+	den.ModInverse(den, n)
+	a24 := num.Mul(num, den)
+	return &curve{n: n, a24: a24.Mod(a24, n)}, &point{x0, z0}, nil
+}
+
+// double returns 2P
+func (c *curve) double(p *point) *point {
+	// (X+Z)^2 (X-Z)^2 : 4XZ ((X-Z)^2 + a24 4XZ)
+	s := new(big.Int).Add(p.x, p.z)
+	s.Mul(s, s).Mod(s, c.n)
+	d := new(big.Int).Sub(p.x, p.z)
+	d.Mul(d, d).Mod(d, c.n)
+	e := new(big.Int).Sub(s, d)
+	x := new(big.Int).Mul(s, d)
+	z := new(big.Int).Mul(c.a24, e)
+	z.Add(z, d).Mul(z, e)
+	return &point{x.Mod(x, c.n), z.Mod(z, c.n)}
+}
+
+// add returns P + Q given their difference P - Q, which the x-coordinate
+// alone cannot tell from P + Q
+func (c *curve) add(p, q, diff *point) *point {
+	// u = (Xp - Zp)(Xq + Zq), v = (Xp + Zp)(Xq - Zq)
+	u := new(big.Int).Sub(p.x, p.z)
+	u.Mul(u, new(big.Int).Add(q.x, q.z))
+	v := new(big.Int).Add(p.x, p.z)
+	v.Mul(v, new(big.Int).Sub(q.x, q.z))
+	// Zd (u + v)^2 : Xd (u - v)^2
+	x := new(big.Int).Add(u, v)
+	x.Mod(x, c.n)
+	x.Mul(x, x).Mul(x, diff.z)
+	z := u.Sub(u, v)
+	z.Mod(z, c.n)
+	z.Mul(z, z).Mul(z, diff.x)
+	return &point{x.Mod(x, c.n), z.Mod(z, c.n)}
+}
+
+// mul returns kP for k >= 1 with the Montgomery ladder, which keeps R0 =
+// mP and R1 = (m+1)P so that their difference is always P
+func (c *curve) mul(p *point, k *big.Int) *point {
+	r0, r1 := p, c.double(p)
+	for i := k.BitLen() - 2; i >= 0; i-- {
+		if k.Bit(i) == 1 {
+			r0, r1 = c.add(r1, r0, p), c.double(r1)
+		} else {
+			r0, r1 = c.double(r0), c.add(r1, r0, p)
+		}
+	}
+	return r0
+}
@@ -0,0 +1,84 @@
+package ecm
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestLadder(t *testing.T) {
+	t.Parallel()
+	// modulo a prime, the affine x of kP computed by repeated additions
+	// must match the ladder
+	n := big.NewInt(1000003)
+	c, p, g := suyama(n, big.NewInt(11))
+	if c == nil || g != nil {
+		t.Fatal("expected a curve")
+	}
+	affine := func(q *point) *big.Int {
+		z := new(big.Int).ModInverse(q.z, n)
+		return z.Mul(z, q.x).Mod(z, n)
+	}
+	// kP from (k-1)P + P given (k-2)P
+	prev, cur := p, c.double(p)
+	for k := int64(3); k < 200; k++ {
+		prev, cur = cur, c.add(cur, p, prev)
+		if got := affine(c.mul(p, big.NewInt(k))); got.Cmp(affine(cur)) != 0 {
+			t.Fatalf("%dP expected x = %s but got %s", k, affine(cur), got)
+		}
+	}
+	if got := affine(c.mul(p, big.NewInt(2))); got.Cmp(affine(c.double(p))) != 0 {
+		t.Fatalf("2P expected x = %s but got %s", affine(c.double(p)), got)
+	}
+}
+
+func TestSuyamaOrder(t *testing.T) {
+	t.Parallel()
+	// Suyama's curves have an order divisible by 12, so 12 times any
+	// point is the point at infinity on the subgroup of order 12
+	p := int64(10007)
+	n := big.NewInt(p)
+	for sigma := int64(6); sigma < 40; sigma++ {
+		c, q, g := suyama(n, big.NewInt(sigma))
+		if c == nil || g != nil {
+			continue
+		}
+		order := curveOrder(c, q, p)
+		if order%12 != 0 {
+			t.Fatalf("sigma %d expected an order divisible by 12 but got %d", sigma, order)
+		}
+		if r := c.mul(q, big.NewInt(order)); r.z.Sign() != 0 {
+			t.Fatalf("sigma %d expected %d P to be the point at infinity", sigma, order)
+		}
+	}
+}
+
+// curveOrder counts the points of the curve of q modulo the prime p: the
+// curve By^2 = f(x) has p + 1 + χ(B) Σ χ(f(x)) points, with χ the
+// Legendre symbol and χ(B) = χ(f(x_q)) since q is on it
+func curveOrder(c *curve, q *point, p int64) int64 {
+	square := make([]bool, p)
+	for y := int64(1); y < p; y++ {
+		square[y*y%p] = true
+	}
+	chi := func(v int64) int64 {
+		switch {
+		case v == 0:
+			return 0
+		case square[v]:
+			return 1
+		}
+		return -1
+	}
+	// A = 4 a24 - 2
+	a := (4*c.a24.Int64() - 2) % p
+	f := func(x int64) int64 {
+		return ((x*x%p*x+a*x%p*x+x)%p + p) % p
+	}
+	sum := int64(0)
+	for x := int64(0); x < p; x++ {
+		sum += chi(f(x))
+	}
+	zinv := new(big.Int).ModInverse(q.z, big.NewInt(p)).Int64()
+	xq := q.x.Int64() * zinv % p
+	return p + 1 + chi(f(xq))*sum
+}
@@ -0,0 +1,62 @@
+// Package franklinreiter implements the related message attack of
+// Franklin and Reiter (1995) on RSA with a small public exponent.
+//
+// When two unpadded messages satisfy a known affine relation m2 = a*m1 +
+// b mod n, such as a message resent with an incremented counter, and both
+// are encrypted to the same key, m1 is a root of x^e - c1 and of (a*x +
+// b)^e - c2. Both polynomials are usually divisible by x - m1 and nothing
+// else in common, so their GCD over Z/nZ gives m1 directly. The cost is
+// quadratic in e, which is trivial for e = 3 and out of reach for 65537.
+// Coppersmith's short pad attack extends this to random padding that is
+// too short. Proper randomized padding such as OAEP breaks the relation.
+package franklinreiter
+
+import (
+	"crypto/rsa"
+	"errors"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/poly"
+)
+
+// ErrNoLinearFactor is returned when the GCD of the two polynomials is not
+// linear, the messages are not related as described
+var ErrNoLinearFactor = errors.New("franklinreiter: the polynomials have no common linear factor")
+
+// Attack recovers m1 and m2 = a*m1 + b mod n from their unpadded
+// encryptions c1 and c2 to pub. The messages are returned without leading
+// zeros.
+func Attack(pub *rsa.PublicKey, c1, c2 []byte, a, b *big.Int) (m1, m2 []byte, err error) {
+	if pub.E < 2 {
+		return nil, nil, errors.New("franklinreiter: invalid public exponent")
+	}
+	n := pub.N
+	x1, x2 := new(big.Int).SetBytes(c1), new(big.Int).SetBytes(c2)
+	if x1.Cmp(n) >= 0 || x2.Cmp(n) >= 0 {
+		return nil, nil, errors.New("franklinreiter: ciphertext out of range")
+	}
+	if new(big.Int).Mod(a, n).Sign() == 0 {
+		return nil, nil, errors.New("franklinreiter: the relation does not depend on m1")
+	}
+	r := poly.Ring{N: n}
+	// g1 = x^e - c1 and g2 = (a*x + b)^e - c2
+	g1 := r.Sub(r.Pow(r.NewInt64(0, 1), pub.E), r.New(x1))
+	g2 := r.Sub(r.Pow(r.New(b, a), pub.E), r.New(x2))
+	g, err := r.GCD(g1, g2)
+	if err != nil {
+		return nil, nil, err
+	}
+	if g.Degree() != 1 {
+		return nil, nil, ErrNoLinearFactor
+	}
+	// g is monic, x + g[0], so m1 = -g[0]
+	root := r.Neg(r.New(g[0]))
+	x := new(big.Int)
+	if len(root) > 0 {
+		x.Set(root[0])
+	}
+	y := new(big.Int).Mul(a, x)
+	y.Add(y, b)
+	y.Mod(y, n)
+	return x.Bytes(), y.Bytes(), nil
+}
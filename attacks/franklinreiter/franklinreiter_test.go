@@ -0,0 +1,90 @@
+package franklinreiter
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/attacks/rsalowe"
+)
+
+func TestAttack(t *testing.T) {
+	t.Parallel()
+	priv, err := rsalowe.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := &priv.PublicKey
+	var testcases = []struct {
+		m1   string
+		a, b int64
+	}{
+		// the same order sent twice with a different sequence number
+		{"order 0001: transfer 1000000 dollars to the account of Mallory, signed Alice", 1, 1},
+		{"a message close to the size of the modulus is recovered as well, the attack does not need it to be small", 1, 1 << 40},
+		{"m2 is three times m1 minus seven", 3, -7},
+	}
+	for i, tc := range testcases {
+		m1 := []byte(tc.m1)
+		a, b := big.NewInt(tc.a), big.NewInt(tc.b)
+		x := new(big.Int).SetBytes(m1)
+		x.Mul(x, a)
+		x.Add(x, b)
+		m2 := x.Mod(x, pub.N).Bytes()
+		c1, err := rsalowe.EncryptRaw(pub, m1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c2, err := rsalowe.EncryptRaw(pub, m2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r1, r2, err := Attack(pub, c1, c2, a, b)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if !bytes.Equal(r1, m1) || !bytes.Equal(r2, m2) {
+			t.Fatalf("testcase %d expected %q and %x but got %q and %x", i, m1, m2, r1, r2)
+		}
+	}
+}
+
+func TestAttackLargerExponent(t *testing.T) {
+	t.Parallel()
+	// e = 17 needs polynomials of degree 17, the cost grows with e^2
+	priv, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := &rsa.PublicKey{N: priv.N, E: 17}
+	m1 := []byte("yet another message")
+	m2 := append([]byte{}, m1...)
+	m2[len(m2)-1]++
+	encrypt := func(m []byte) []byte {
+		x := new(big.Int).SetBytes(m)
+		return x.Exp(x, big.NewInt(17), pub.N).Bytes()
+	}
+	r1, r2, err := Attack(pub, encrypt(m1), encrypt(m2), big.NewInt(1), big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(r1, m1) || !bytes.Equal(r2, m2) {
+		t.Fatalf("expected %q and %q but got %q and %q", m1, m2, r1, r2)
+	}
+}
+
+func TestAttackWrongRelation(t *testing.T) {
+	t.Parallel()
+	priv, err := rsalowe.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := &priv.PublicKey
+	c1, _ := rsalowe.EncryptRaw(pub, []byte("first message"))
+	c2, _ := rsalowe.EncryptRaw(pub, []byte("unrelated message"))
+	if _, _, err := Attack(pub, c1, c2, big.NewInt(1), big.NewInt(1)); err != ErrNoLinearFactor {
+		t.Fatalf("expected no linear factor but got %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+// Package hashcollide finds collisions of hash functions truncated to a
+// few dozen bits with Pollard's rho, to verify the birthday bound.
+//
+// A hash of n bits has collisions after about sqrt(pi/2 * 2^n) random
+// inputs, the birthday bound, and storing them all takes as much memory.
+// Iterating the hash on its own output instead gives a walk that falls
+// into a cycle after about as many steps: the two points leading to the
+// entry of the cycle are a collision, found with Floyd's algorithm without
+// memory. The distinguished points method of van Oorschot and Wiener runs
+// many such walks in parallel, each one stopping at rare points that are
+// shared in a table, and two walks that reach the same point collide
+// somewhere on the way. This is why a 128 bits hash, or a 64 bits MAC,
+// only gives 64 and 32 bits of security against collisions.
+package hashcollide
+
+import (
+	"bytes"
+	"errors"
+	"hash"
+	"io"
+	"math"
+)
+
+// Truncated is a hash function whose output is truncated to Bits bits,
+// the first ones
+type Truncated struct {
+	New  func() hash.Hash
+	Bits int
+}
+
+// Size returns the size of the truncated output in bytes
+func (t Truncated) Size() int {
+	return (t.Bits + 7) / 8
+}
+
+// Sum returns the hash of in truncated to t.Bits bits, with the extra bits
+// of the last byte set to zero
+func (t Truncated) Sum(in []byte) []byte {
+	h := t.New()
+	h.Write(in)
+	out := h.Sum(nil)[:t.Size()]
+	if extra := uint(8*t.Size() - t.Bits); extra > 0 {
+		out[len(out)-1] &= 0xff << extra
+	}
+	return out
+}
+
+func (t Truncated) check() error {
+	if t.Bits < 1 || t.Bits > 8*t.New().Size() {
+		return errors.New("hashcollide: invalid truncation")
+	}
+	return nil
+}
+
+// Collision holds two different inputs with the same truncated hash, and
+// the number of hash evaluations it took to find them
+type Collision struct {
+	A, B        []byte
+	Evaluations uint64
+}
+
+// BirthdayBound returns sqrt(pi/2 * 2^bits), the expected number of
+// random inputs before two hashes of bits bits collide
+func BirthdayBound(bits int) float64 {
+	return math.Sqrt(math.Pi / 2 * math.Exp2(float64(bits)))
+}
+
+// random returns a random starting point of the walks
+func (t Truncated) random(rand io.Reader) ([]byte, error) {
+	x := make([]byte, t.Size())
+	if _, err := io.ReadFull(rand, x); err != nil {
+		return nil, err
+	}
+	// inputs are outputs of the walk, with the same zero bits
+	if extra := uint(8*t.Size() - t.Bits); extra > 0 {
+		x[len(x)-1] &= 0xff << extra
+	}
+	return x, nil
+}
+
+// Rho finds a collision of t with Floyd's cycle detection, in constant
+// memory and about 3 times the birthday bound evaluations.
+//
+// A tortoise walks x -> t(x) and a hare twice as fast until they meet in
+// the cycle, after a multiple of its length. Starting the tortoise over,
+// both then walk at the same speed and meet at the entry of the cycle,
+// which they reach from different points.
+func Rho(rand io.Reader, t Truncated) (*Collision, error) {
+	if err := t.check(); err != nil {
+		return nil, err
+	}
+	c := new(Collision)
+	f := func(x []byte) []byte {
+		c.Evaluations++
+		return t.Sum(x)
+	}
+	for {
+		x0, err := t.random(rand)
+		if err != nil {
+			return nil, err
+		}
+		tortoise, hare := f(x0), f(f(x0))
+		for !bytes.Equal(tortoise, hare) {
+			tortoise, hare = f(tortoise), f(f(hare))
+		}
+		tortoise = x0
+		if bytes.Equal(tortoise, hare) {
+			// x0 is on the cycle, no point enters it from outside
+			continue
+		}
+		for {
+			ft, fh := f(tortoise), f(hare)
+			if bytes.Equal(ft, fh) {
+				c.A, c.B = tortoise, hare
+				return c, nil
+			}
+			tortoise, hare = ft, fh
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package hashcollide
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestTruncated(t *testing.T) {
+	t.Parallel()
+	full := sha256.Sum256([]byte("abc"))
+	var testcases = []struct {
+		bits     int
+		expected []byte
+	}{
+		{8, full[:1]},
+		{12, []byte{full[0], full[1] & 0xf0}},
+		{33, []byte{full[0], full[1], full[2], full[3], full[4] & 0x80}},
+		{256, full[:]},
+	}
+	for i, tc := range testcases {
+		tr := Truncated{New: sha256.New, Bits: tc.bits}
+		if out := tr.Sum([]byte("abc")); !bytes.Equal(out, tc.expected) {
+			t.Fatalf("testcase %d expected %x but got %x", i, tc.expected, out)
+		}
+	}
+	if _, err := Rho(rand.Reader, Truncated{New: sha256.New, Bits: 257}); err == nil {
+		t.Fatal("expected a truncation longer than the hash to be rejected")
+	}
+}
+
+func checkCollision(t *testing.T, tr Truncated, c *Collision) {
+	if bytes.Equal(c.A, c.B) {
+		t.Fatalf("expected different inputs but got %x twice", c.A)
+	}
+	if !bytes.Equal(tr.Sum(c.A), tr.Sum(c.B)) {
+		t.Fatalf("expected %x and %x to collide", c.A, c.B)
+	}
+}
+
+func TestRho(t *testing.T) {
+	t.Parallel()
+	for _, bits := range []int{16, 24, 33} {
+		tr := Truncated{New: sha256.New, Bits: bits}
+		c, err := Rho(rand.Reader, tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkCollision(t, tr, c)
+	}
+}
+
+func TestParallel(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		bits, workers, distinguished int
+	}{
+		{24, 1, 4},
+		{36, 4, 8},
+		{40, 8, 10},
+	}
+	for i, tc := range testcases {
+		tr := Truncated{New: md5.New, Bits: tc.bits}
+		c, err := Parallel(rand.Reader, tr, tc.workers, tc.distinguished)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		checkCollision(t, tr, c)
+	}
+	tr := Truncated{New: md5.New, Bits: 24}
+	if _, err := Parallel(rand.Reader, tr, 0, 4); err == nil {
+		t.Fatal("expected zero workers to be rejected")
+	}
+	if _, err := Parallel(rand.Reader, tr, 2, 24); err == nil {
+		t.Fatal("expected all bits distinguished to be rejected")
+	}
+}
+
+func TestBirthdayBound(t *testing.T) {
+	t.Parallel()
+	// the average over a few runs is within a small factor of the bound,
+	// Floyd's algorithm evaluates the hash about 3 times per step
+	const bits, runs = 20, 20
+	var total uint64
+	for i := 0; i < runs; i++ {
+		c, err := Rho(rand.Reader, Truncated{New: sha256.New, Bits: bits})
+		if err != nil {
+			t.Fatal(err)
+		}
+		total += c.Evaluations
+	}
+	ratio := float64(total) / runs / BirthdayBound(bits)
+	if ratio < 1 || ratio > 8 {
+		t.Fatalf("expected about 3 times the birthday bound but got %.2f", ratio)
+	}
+}
@@ -0,0 +1,165 @@
+package hashcollide
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// trail is a walk from start to a distinguished point in length steps
+type trail struct {
+	start  []byte
+	length uint64
+}
+
+// search is the state shared by the workers of Parallel
+type search struct {
+	t                 Truncated
+	distinguishedBits int
+	evaluations       uint64
+
+	mu     sync.Mutex
+	rand   io.Reader
+	trails map[string]trail
+	result *Collision
+	err    error
+	done   chan struct{}
+}
+
+func (s *search) f(x []byte) []byte {
+	atomic.AddUint64(&s.evaluations, 1)
+	return s.t.Sum(x)
+}
+
+// distinguished reports whether the first distinguishedBits of x are zero
+func (s *search) distinguished(x []byte) bool {
+	for i := 0; i < s.distinguishedBits; i++ {
+		if x[i/8]>>(7-uint(i%8))&1 != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// finish records the result or error of the search, and stops it
+func (s *search) finish(c *Collision, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+	s.result, s.err = c, err
+	close(s.done)
+}
+
+func (s *search) worker() {
+	// abandon trails 20 times longer than expected, stuck in a cycle
+	// without distinguished points
+	maxLength := uint64(20) << uint(s.distinguishedBits)
+	for {
+		select {
+		case <-s.done:
+			return
+		default:
+		}
+		s.mu.Lock()
+		start, err := s.t.random(s.rand)
+		s.mu.Unlock()
+		if err != nil {
+			s.finish(nil, err)
+			return
+		}
+		x := start
+		var length uint64
+		for !s.distinguished(x) && length < maxLength {
+			if length%1024 == 0 {
+				select {
+				case <-s.done:
+					return
+				default:
+				}
+			}
+			x = s.f(x)
+			length++
+		}
+		if length == maxLength {
+			continue
+		}
+		s.mu.Lock()
+		other, ok := s.trails[string(x)]
+		if !ok {
+			s.trails[string(x)] = trail{start, length}
+		}
+		s.mu.Unlock()
+		if !ok {
+			continue
+		}
+		if c := s.locate(trail{start, length}, other); c != nil {
+			s.finish(c, nil)
+			return
+		}
+	}
+}
+
+// locate walks two trails that end at the same distinguished point to
+// where they merge, or returns nil if one started on the other
+func (s *search) locate(a, b trail) *Collision {
+	if a.length < b.length {
+		a, b = b, a
+	}
+	x, y := a.start, b.start
+	for i := b.length; i < a.length; i++ {
+		x = s.f(x)
+	}
+	for !bytes.Equal(x, y) {
+		fx, fy := s.f(x), s.f(y)
+		if bytes.Equal(fx, fy) {
+			return &Collision{A: x, B: y}
+		}
+		x, y = fx, fy
+	}
+	return nil
+}
+
+// Parallel finds a collision of t with the distinguished points method of
+// van Oorschot and Wiener, running workers walks at a time. Walks stop at
+// points whose first distinguishedBits bits are zero, which takes about
+// 2^distinguishedBits evaluations, and the search stores one entry per
+// walk: more bits save memory but waste more evaluations after the
+// collision happened.
+func Parallel(rand io.Reader, t Truncated, workers, distinguishedBits int) (*Collision, error) {
+	if err := t.check(); err != nil {
+		return nil, err
+	}
+	if workers < 1 {
+		return nil, errors.New("hashcollide: invalid number of workers")
+	}
+	if distinguishedBits < 1 || distinguishedBits >= t.Bits {
+		return nil, errors.New("hashcollide: invalid number of distinguished bits")
+	}
+	s := &search{
+		t:                 t,
+		distinguishedBits: distinguishedBits,
+		rand:              rand,
+		trails:            make(map[string]trail),
+		done:              make(chan struct{}),
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.worker()
+		}()
+	}
+	wg.Wait()
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.result.Evaluations = atomic.LoadUint64(&s.evaluations)
+	return s.result, nil
+}
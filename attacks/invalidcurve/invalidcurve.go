@@ -0,0 +1,127 @@
+// Package invalidcurve implements the invalid curve attack of Biehl,
+// Meyer and Müller (CRYPTO 2000) against elliptic curve Diffie-Hellman
+// implementations that do not check that the peer public key is on the
+// curve.
+//
+// The addition formulas of a short Weierstrass curve y^2 = x^3 + ax + b
+// only use a, so a point off the curve is silently computed on with the
+// curve of the same a that it lies on instead. Most such curves have an
+// order with small factors, and the attacker sends a point R of small
+// prime order r on one of them: the shared secret x(kR) takes one of r
+// values, which anything keyed with it reveals, and gives k up to its
+// sign modulo r. Repeating with other curves and primes until their
+// product exceeds the order of the real curve, the Chinese remainder
+// theorem gives k, with the victim public key to sort out the signs. It
+// broke TLS libraries and Bluetooth pairing. The fix is to check that
+// points are on the curve, or to use x-only ladders such as X25519 whose
+// twist is secure as well.
+package invalidcurve
+
+import (
+	"crypto/hmac"
+	"errors"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+// maxProbes bounds the number of probes, the signs of the residues are
+// searched exhaustively
+const maxProbes = 24
+
+// Probe is a point of small prime order Order on the invalid curve
+// y^2 = x^3 + ax + B sharing the a coefficient of the target curve
+type Probe struct {
+	Point    *ec.Point
+	B, Order *big.Int
+}
+
+// Oracle returns the tag of Message under the secret shared with the
+// public key peer, such as Responder.Respond
+type Oracle func(peer *ec.Point) ([]byte, error)
+
+// Attack recovers the private key of pub on curve c behind oracle with the
+// invalid curve points of probes, see FindProbes. The product of the
+// orders of the probes must exceed the order of c.
+func Attack(c *ec.Curve, pub *ec.Point, probes []Probe, oracle Oracle) (*big.Int, error) {
+	if len(probes) > maxProbes {
+		return nil, errors.New("invalidcurve: too many probes")
+	}
+	product := big.NewInt(1)
+	for _, p := range probes {
+		product.Mul(product, p.Order)
+	}
+	if product.Cmp(c.N) <= 0 {
+		return nil, errors.New("invalidcurve: the probes do not cover the curve order")
+	}
+	residues := make([]*big.Int, len(probes))
+	for i, p := range probes {
+		t, err := oracle(p.Point)
+		if err != nil {
+			return nil, err
+		}
+		// u R and -u R have the same x, only search up to r / 2
+		invalid := &ec.Curve{Name: "invalid", P: c.P, N: p.Order, A: c.A, B: p.B}
+		half := new(big.Int).Rsh(p.Order, 1)
+		acc := ec.Infinity()
+		for u := new(big.Int); u.Cmp(half) <= 0; u.Add(u, big.NewInt(1)) {
+			if hmac.Equal(t, tag(secret(c, acc))) {
+				residues[i] = new(big.Int).Set(u)
+				break
+			}
+			acc = invalid.Add(acc, p.Point)
+		}
+		if residues[i] == nil {
+			return nil, errors.New("invalidcurve: no shared secret matches the tag")
+		}
+	}
+	// try every combination of signs against the public key
+	moduli := make([]*big.Int, len(probes))
+	signed := make([]*big.Int, len(probes))
+	for i, p := range probes {
+		moduli[i] = p.Order
+		signed[i] = new(big.Int)
+	}
+signs:
+	for mask := 0; mask < 1<<uint(len(probes)); mask++ {
+		for i, u := range residues {
+			signed[i].Set(u)
+			if mask>>uint(i)&1 == 1 {
+				if u.Sign() == 0 {
+					// the same combination as with a positive sign
+					continue signs
+				}
+				signed[i].Sub(moduli[i], u)
+			}
+		}
+		k := crt(signed, moduli)
+		if k.Cmp(c.N) < 0 && c.ScalarBaseMult(k).Equal(pub) {
+			return k, nil
+		}
+	}
+	return nil, errors.New("invalidcurve: no combination of residues matches the public key")
+}
+
+// secret returns the shared secret of the point s, as ec.ECDHUnchecked
+func secret(c *ec.Curve, s *ec.Point) []byte {
+	out := make([]byte, c.ByteLen())
+	if s.IsInfinity() {
+		return out
+	}
+	return s.X.FillBytes(out)
+}
+
+// crt returns x modulo the product of the coprime moduli such that x is
+// residues[i] modulo moduli[i]
+func crt(residues, moduli []*big.Int) *big.Int {
+	x, n := new(big.Int), big.NewInt(1)
+	for i, m := range moduli {
+		// x + n t = residues[i] mod m
+		t := new(big.Int).Sub(residues[i], x)
+		t.Mul(t, new(big.Int).ModInverse(n, m))
+		t.Mod(t, m)
+		x.Add(x, t.Mul(t, n))
+		n.Mul(n, m)
+	}
+	return x
+}
@@ -0,0 +1,59 @@
+package invalidcurve
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+// toyCurve is a curve of prime order with a = -3 over a 40 bits field
+var toyCurve = &ec.Curve{
+	Name: "toy",
+	P:    big.NewInt(554282362747),
+	N:    big.NewInt(554282762821),
+	A:    big.NewInt(554282362744),
+	B:    big.NewInt(83553316860),
+	Gx:   big.NewInt(416945004117),
+	Gy:   big.NewInt(517811250309),
+}
+
+func TestAttack(t *testing.T) {
+	t.Parallel()
+	r, err := NewResponder(rand.Reader, toyCurve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	probes, err := FindProbes(rand.Reader, toyCurve, 1<<12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := Attack(toyCurve, r.PublicKey, probes, r.Respond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Cmp(r.d) != 0 {
+		t.Fatalf("expected %s but got %s", r.d, d)
+	}
+	if _, err := Attack(toyCurve, r.PublicKey, probes[:1], r.Respond); err == nil {
+		t.Fatal("expected too few probes to be rejected")
+	}
+	// checking the peer point stops the attack, and still accepts valid
+	// points
+	r.Validate = true
+	if _, err := Attack(toyCurve, r.PublicKey, probes, r.Respond); err == nil {
+		t.Fatal("expected the validating responder to resist")
+	}
+	if _, err := r.Respond(toyCurve.Generator()); err != nil {
+		t.Fatalf("expected a valid point to be accepted but got %v", err)
+	}
+}
+
+func TestCRT(t *testing.T) {
+	t.Parallel()
+	x := crt([]*big.Int{big.NewInt(2), big.NewInt(3), big.NewInt(2)}, []*big.Int{big.NewInt(3), big.NewInt(5), big.NewInt(7)})
+	if x.Int64() != 23 {
+		t.Fatalf("expected 23 but got %s", x)
+	}
+}
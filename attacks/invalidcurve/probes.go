@@ -0,0 +1,134 @@
+package invalidcurve
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/attacks/dlog"
+	"github.com/jvehent/badcrypto/attacks/pohlighellman"
+	"github.com/jvehent/badcrypto/ec"
+)
+
+// maxCurves bounds the number of invalid curves FindProbes tries
+const maxCurves = 1000
+
+// randomBelow returns a uniform integer in [0, n)
+func randomBelow(rand io.Reader, n *big.Int) (*big.Int, error) {
+	buf := make([]byte, (n.BitLen()+7)/8+8)
+	if _, err := io.ReadFull(rand, buf); err != nil {
+		return nil, err
+	}
+	x := new(big.Int).SetBytes(buf)
+	return x.Mod(x, n), nil
+}
+
+// randomPoint returns a random point of the curve c, whose field modulus
+// must be prime
+func randomPoint(rand io.Reader, c *ec.Curve) (*ec.Point, error) {
+	for {
+		x, err := randomBelow(rand, c.P)
+		if err != nil {
+			return nil, err
+		}
+		// y^2 = x^3 + ax + b
+		rhs := new(big.Int).Mul(x, x)
+		rhs.Add(rhs, c.A)
+		rhs.Mul(rhs, x)
+		rhs.Add(rhs, c.B)
+		rhs.Mod(rhs, c.P)
+		if y := new(big.Int).ModSqrt(rhs, c.P); y != nil {
+			return &ec.Point{X: x, Y: y}, nil
+		}
+	}
+}
+
+// Order returns the number of points of the curve c, ignoring c.N.
+//
+// The order is in the Hasse interval p + 1 ± 2 sqrt(p), and baby-step
+// giant-step finds the multiple of the order of a random point in it in
+// p^(1/4) operations, which is only practical for toy curves. Real attacks
+// count points once with Schoof's algorithm. The result is checked with
+// other random points, but could still be a multiple of the order of the
+// first one rather than the order of the curve if both are small.
+func Order(rand io.Reader, c *ec.Curve) (*big.Int, error) {
+	s := new(big.Int).Sqrt(c.P)
+	s.Lsh(s, 1)
+	s.Add(s, big.NewInt(1))
+	lo := new(big.Int).Add(c.P, big.NewInt(1))
+	hi := new(big.Int).Add(lo, s)
+	lo.Sub(lo, s)
+	g := dlog.Curve{Curve: c}
+	var n *big.Int
+	for i := 0; i < 3; i++ {
+		p, err := randomPoint(rand, c)
+		if err != nil {
+			return nil, err
+		}
+		if n != nil {
+			if !g.Exp(p, n).(*ec.Point).IsInfinity() {
+				return nil, errors.New("invalidcurve: ambiguous curve order")
+			}
+			continue
+		}
+		if n, err = dlog.BSGS(g, p, g.Identity(), lo, new(big.Int).Add(hi, big.NewInt(1)), 0); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// FindProbes searches curves y^2 = x^3 + ax + b' with the a of c for
+// points of prime order below bound, until the product of their orders
+// exceeds the order of c. Each prime is used once.
+func FindProbes(rand io.Reader, c *ec.Curve, bound int64) ([]Probe, error) {
+	var probes []Probe
+	used := make(map[int64]bool)
+	product := big.NewInt(1)
+	four, twentySeven := big.NewInt(4), big.NewInt(27)
+	for i := 0; i < maxCurves; i++ {
+		b, err := randomBelow(rand, c.P)
+		if err != nil {
+			return nil, err
+		}
+		// skip the real curve and singular ones, 4a^3 + 27b^2 = 0
+		disc := new(big.Int).Exp(c.A, big.NewInt(3), c.P)
+		disc.Mul(disc, four)
+		disc.Add(disc, new(big.Int).Mul(twentySeven, new(big.Int).Mul(b, b)))
+		if b.Cmp(c.B) == 0 || disc.Mod(disc, c.P).Sign() == 0 {
+			continue
+		}
+		invalid := &ec.Curve{Name: "invalid", P: c.P, A: c.A, B: b}
+		n, err := Order(rand, invalid)
+		if err != nil {
+			continue
+		}
+		g := dlog.Curve{Curve: invalid}
+		for _, r := range pohlighellman.SmallFactors(n, bound) {
+			if used[r.Int64()] {
+				continue
+			}
+			// (n / r) Q has order r unless it is the point at infinity
+			cofactor := new(big.Int).Quo(n, r)
+			var point *ec.Point
+			for tries := 0; tries < 10 && point == nil; tries++ {
+				q, err := randomPoint(rand, invalid)
+				if err != nil {
+					return nil, err
+				}
+				if p := g.Exp(q, cofactor).(*ec.Point); !p.IsInfinity() {
+					point = p
+				}
+			}
+			if point == nil || !g.Exp(point, r).(*ec.Point).IsInfinity() {
+				continue
+			}
+			used[r.Int64()] = true
+			probes = append(probes, Probe{Point: point, B: b, Order: r})
+			if product.Mul(product, r).Cmp(c.N) > 0 {
+				return probes, nil
+			}
+		}
+	}
+	return nil, errors.New("invalidcurve: not enough small subgroups found")
+}
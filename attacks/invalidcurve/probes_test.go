@@ -0,0 +1,59 @@
+package invalidcurve
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/attacks/dlog"
+	"github.com/jvehent/badcrypto/ec"
+)
+
+func TestOrder(t *testing.T) {
+	t.Parallel()
+	n, err := Order(rand.Reader, toyCurve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Cmp(toyCurve.N) != 0 {
+		t.Fatalf("expected %s but got %s", toyCurve.N, n)
+	}
+	// y^2 = x^3 + 7 over p = 2 mod 3 is supersingular with p + 1 points
+	c := &ec.Curve{P: big.NewInt(25769804321), A: big.NewInt(0), B: big.NewInt(7)}
+	if n, err := Order(rand.Reader, c); err != nil || n.Int64() != 25769804322 {
+		t.Fatalf("expected 25769804322 but got %v, %v", n, err)
+	}
+}
+
+func TestFindProbes(t *testing.T) {
+	t.Parallel()
+	const bound = 1 << 10
+	probes, err := FindProbes(rand.Reader, toyCurve, bound)
+	if err != nil {
+		t.Fatal(err)
+	}
+	product := big.NewInt(1)
+	seen := make(map[int64]bool)
+	for i, p := range probes {
+		r := p.Order
+		if !r.ProbablyPrime(20) || r.Int64() >= bound || seen[r.Int64()] {
+			t.Fatalf("testcase %d unexpected order %s", i, r)
+		}
+		seen[r.Int64()] = true
+		product.Mul(product, r)
+		if toyCurve.IsOnCurve(p.Point) {
+			t.Fatalf("testcase %d expected a point off the curve", i)
+		}
+		invalid := &ec.Curve{P: toyCurve.P, A: toyCurve.A, B: p.B}
+		if !invalid.IsOnCurve(p.Point) {
+			t.Fatalf("testcase %d expected the point on the invalid curve", i)
+		}
+		g := dlog.Curve{Curve: invalid}
+		if p.Point.IsInfinity() || !g.Exp(p.Point, r).(*ec.Point).IsInfinity() {
+			t.Fatalf("testcase %d expected a point of order %s", i, r)
+		}
+	}
+	if product.Cmp(toyCurve.N) <= 0 {
+		t.Fatalf("expected the orders to cover N but got %s", product)
+	}
+}
@@ -0,0 +1,57 @@
+package invalidcurve
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+// Message is the message a Responder authenticates under the shared
+// secret
+var Message = []byte("crazy flamboyant for the rap enjoyment")
+
+// Responder is a deliberately vulnerable ECDH party with a static key, for
+// exercises. It answers any public key with a MAC of Message under the
+// shared secret, computed with ec.ECDHUnchecked unless Validate is set.
+type Responder struct {
+	Curve *ec.Curve
+	// PublicKey is d*G for the private key d
+	PublicKey *ec.Point
+	// Validate uses ec.ECDH, which checks the peer point, and stops the
+	// attack
+	Validate bool
+	d        *big.Int
+}
+
+// NewResponder returns a responder with a random private key on c
+func NewResponder(rand io.Reader, c *ec.Curve) (*Responder, error) {
+	d, err := c.RandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	return &Responder{Curve: c, PublicKey: c.ScalarBaseMult(d), d: d}, nil
+}
+
+// Respond returns the tag of Message under the secret shared with peer
+func (r *Responder) Respond(peer *ec.Point) ([]byte, error) {
+	if r.Validate {
+		s, err := r.Curve.ECDH(r.d, peer)
+		if err != nil {
+			return nil, err
+		}
+		return tag(s), nil
+	}
+	return tag(r.Curve.ECDHUnchecked(r.d, peer)), nil
+}
+
+// tag returns HMAC-SHA256 of Message keyed with the hash of the shared
+// secret s
+func tag(s []byte) []byte {
+	key := sha256.Sum256(s)
+	mac := hmac.New(sha256.New, key[:])
+	mac.Write(Message)
+	return mac.Sum(nil)
+}
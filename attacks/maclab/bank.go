@@ -0,0 +1,96 @@
+package maclab
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidMAC is returned for a message whose tag does not verify
+var ErrInvalidMAC = errors.New("maclab: invalid MAC")
+
+// Transfer moves Amount to the account To
+type Transfer struct {
+	To     string
+	Amount int
+}
+
+// Bank is a deliberately vulnerable service, for exercises. Its web client
+// authenticates transfer messages of the form
+// from=alice&tx_list=bob:10;carol:20 with CBC-MAC under a zero IV, and
+// its backend executes the valid transfers of a message with a valid tag,
+// skipping the malformed ones.
+type Bank struct {
+	block cipher.Block
+	// Balances of the accounts
+	Balances map[string]int
+}
+
+// NewBank returns a bank with a random key
+func NewBank(rand io.Reader) (*Bank, error) {
+	key := make([]byte, 16)
+	if _, err := io.ReadFull(rand, key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Bank{block: block, Balances: make(map[string]int)}, nil
+}
+
+var account = regexp.MustCompile(`^[a-z0-9]+$`)
+
+// Sign returns the message of the transfers from the account of a logged
+// in customer, and its tag
+func (b *Bank) Sign(from string, transfers []Transfer) (msg, tag []byte, err error) {
+	if !account.MatchString(from) {
+		return nil, nil, fmt.Errorf("maclab: invalid account %q", from)
+	}
+	list := make([]string, len(transfers))
+	for i, t := range transfers {
+		if !account.MatchString(t.To) || t.Amount <= 0 {
+			return nil, nil, fmt.Errorf("maclab: invalid transfer %v", t)
+		}
+		list[i] = fmt.Sprintf("%s:%d", t.To, t.Amount)
+	}
+	msg = []byte("from=" + from + "&tx_list=" + strings.Join(list, ";"))
+	return msg, CBCMAC(b.block, make([]byte, aes.BlockSize), msg), nil
+}
+
+// Process verifies the tag of msg and executes its transfers
+func (b *Bank) Process(msg, tag []byte) ([]Transfer, error) {
+	if subtle.ConstantTimeCompare(tag, CBCMAC(b.block, make([]byte, aes.BlockSize), msg)) != 1 {
+		return nil, ErrInvalidMAC
+	}
+	if !bytes.HasPrefix(msg, []byte("from=")) {
+		return nil, errors.New("maclab: missing sender")
+	}
+	fields := strings.SplitN(string(msg[len("from="):]), "&", 2)
+	from := fields[0]
+	if len(fields) != 2 || !account.MatchString(from) || !strings.HasPrefix(fields[1], "tx_list=") {
+		return nil, errors.New("maclab: malformed message")
+	}
+	var done []Transfer
+	for _, entry := range strings.Split(fields[1][len("tx_list="):], ";") {
+		parts := strings.Split(entry, ":")
+		if len(parts) != 2 || !account.MatchString(parts[0]) {
+			continue
+		}
+		amount, err := strconv.Atoi(parts[1])
+		if err != nil || amount <= 0 {
+			continue
+		}
+		b.Balances[from] -= amount
+		b.Balances[parts[0]] += amount
+		done = append(done, Transfer{To: parts[0], Amount: amount})
+	}
+	return done, nil
+}
@@ -0,0 +1,40 @@
+package maclab
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+)
+
+// pad appends PKCS#7 padding to a copy of msg
+func pad(msg []byte, blockSize int) []byte {
+	n := blockSize - len(msg)%blockSize
+	out := make([]byte, len(msg), len(msg)+n)
+	copy(out, msg)
+	for i := 0; i < n; i++ {
+		out = append(out, byte(n))
+	}
+	return out
+}
+
+// CBCMAC returns the last block of the CBC encryption of msg, with PKCS#7
+// padding, under iv
+func CBCMAC(block cipher.Block, iv, msg []byte) []byte {
+	padded := pad(msg, block.BlockSize())
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(padded, padded)
+	return padded[len(padded)-block.BlockSize():]
+}
+
+// Splice returns a message starting with m1 whose CBC-MAC under a zero IV
+// is the tag of m2, given the tag t1 of m1. The first block of m2 is
+// replaced by garbage, and the padding of m1 stays in the middle.
+func Splice(m1, t1, m2 []byte) ([]byte, error) {
+	if len(t1) != aes.BlockSize || len(m2) < aes.BlockSize {
+		return nil, errors.New("maclab: m2 must be at least a block long")
+	}
+	forged := pad(m1, aes.BlockSize)
+	for i := 0; i < aes.BlockSize; i++ {
+		forged = append(forged, m2[i]^t1[i])
+	}
+	return append(forged, m2[aes.BlockSize:]...), nil
+}
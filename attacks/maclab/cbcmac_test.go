@@ -0,0 +1,91 @@
+package maclab
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestCBCMAC(t *testing.T) {
+	t.Parallel()
+	block, _ := aes.NewCipher(make([]byte, 16))
+	iv := make([]byte, 16)
+	// a full block gets a whole block of padding
+	msg := []byte("YELLOW SUBMARINE")
+	expected := make([]byte, 16)
+	block.Encrypt(expected, msg)
+	for i := range expected {
+		expected[i] ^= 16
+	}
+	block.Encrypt(expected, expected)
+	if tag := CBCMAC(block, iv, msg); !bytes.Equal(tag, expected) {
+		t.Fatalf("expected %x but got %x", expected, tag)
+	}
+}
+
+func TestSplice(t *testing.T) {
+	t.Parallel()
+	key := make([]byte, 16)
+	rand.Read(key)
+	block, _ := aes.NewCipher(key)
+	iv := make([]byte, 16)
+	for _, sizes := range [][2]int{{0, 16}, {5, 16}, {16, 40}, {33, 17}} {
+		m1, m2 := make([]byte, sizes[0]), make([]byte, sizes[1])
+		rand.Read(m1)
+		rand.Read(m2)
+		forged, err := Splice(m1, CBCMAC(block, iv, m1), m2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(forged, m1) || !bytes.Equal(CBCMAC(block, iv, forged), CBCMAC(block, iv, m2)) {
+			t.Fatalf("sizes %v expected the forged message to have the tag of m2", sizes)
+		}
+	}
+	if _, err := Splice(nil, make([]byte, 16), make([]byte, 15)); err == nil {
+		t.Fatal("expected a short m2 to be rejected")
+	}
+}
+
+func TestBank(t *testing.T) {
+	t.Parallel()
+	bank, err := NewBank(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// alice pays bob, mallory sees the message on the wire
+	m1, t1, err := bank.Sign("alice", []Transfer{{"bob", 10}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bank.Process(m1, t1); err != nil {
+		t.Fatal(err)
+	}
+	// mallory gets a message of her own authenticated, the first block is
+	// sacrificed and the rest ends up in the transfer list of alice
+	m2, t2, err := bank.Sign("mallory", []Transfer{{"mallory", 1}, {"mallory", 1000000}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged, err := Splice(m1, t1, m2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done, err := bank.Process(forged, t2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 1 || done[0] != (Transfer{"mallory", 1000000}) {
+		t.Fatalf("expected the forged transfer but got %v", done)
+	}
+	if bank.Balances["alice"] != -1000010 || bank.Balances["mallory"] != 1000000 {
+		t.Fatalf("unexpected balances %v", bank.Balances)
+	}
+	forged[0] ^= 1
+	if _, err := bank.Process(forged, t2); err != ErrInvalidMAC {
+		t.Fatalf("expected ErrInvalidMAC but got %v", err)
+	}
+	if _, _, err := bank.Sign("alice&tx_list=mallory", nil); err == nil {
+		t.Fatal("expected an invalid account to be rejected")
+	}
+}
@@ -0,0 +1,142 @@
+package maclab
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// ErrNotFound is returned when the recovered tag does not verify
+var ErrNotFound = errors.New("maclab: no valid tag found")
+
+// HMACServer is a deliberately vulnerable service, for exercises. It
+// checks HMAC-SHA1 tags of file names byte by byte, stopping at the first
+// difference and spending Delay on each matching byte.
+type HMACServer struct {
+	key []byte
+	// Delay exaggerates the time each matching byte takes
+	Delay time.Duration
+}
+
+// NewHMACServer returns a server with a random key
+func NewHMACServer(rand io.Reader, delay time.Duration) (*HMACServer, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand, key); err != nil {
+		return nil, err
+	}
+	return &HMACServer{key: key, Delay: delay}, nil
+}
+
+func (s *HMACServer) tag(msg []byte) []byte {
+	mac := hmac.New(sha1.New, s.key)
+	mac.Write(msg)
+	return mac.Sum(nil)
+}
+
+// Verify reports whether tag is the HMAC of msg, in a time proportional
+// to the length of their common prefix
+func (s *HMACServer) Verify(msg, tag []byte) bool {
+	expected := s.tag(msg)
+	if len(tag) != len(expected) {
+		return false
+	}
+	for i := range expected {
+		if tag[i] != expected[i] {
+			return false
+		}
+		// busy wait, sleeping is far less precise
+		start := time.Now()
+		for time.Since(start) < s.Delay {
+		}
+	}
+	return true
+}
+
+// ServeHTTP checks the hex encoded "signature" query parameter against the
+// "file" parameter, and answers 200 or 500
+func (s *HMACServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	tag, err := hex.DecodeString(q.Get("signature"))
+	if err != nil || !s.Verify([]byte(q.Get("file")), tag) {
+		http.Error(w, "invalid signature", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Verifier reports whether tag is valid for msg
+type Verifier func(msg, tag []byte) bool
+
+// RecoverHMAC finds a tag of size bytes for msg from the time verify
+// takes. Each byte is the value whose verification takes the longest,
+// timing each of them samples times and keeping the fastest, since noise
+// only ever adds time. The few slowest values are then timed again with
+// more samples, to rule out a value that was unlucky every time. The last
+// byte is the one verify accepts.
+func RecoverHMAC(verify Verifier, msg []byte, size, samples int) ([]byte, error) {
+	if size < 1 || samples < 1 {
+		return nil, errors.New("maclab: invalid parameters")
+	}
+	tag := make([]byte, size)
+	candidates := make([]int, 256)
+	for i := 0; i < size-1; i++ {
+		for b := range candidates {
+			candidates[b] = b
+		}
+		timings := timeCandidates(verify, msg, tag, i, candidates, samples)
+		sort.Slice(candidates, func(a, b int) bool {
+			return timings[candidates[a]] > timings[candidates[b]]
+		})
+		candidates = candidates[:refined]
+		timings = timeCandidates(verify, msg, tag, i, candidates, refinedSamples*samples)
+		best := candidates[0]
+		for _, b := range candidates {
+			if timings[b] > timings[best] {
+				best = b
+			}
+		}
+		tag[i] = byte(best)
+		candidates = candidates[:256]
+	}
+	for b := 0; b < 256; b++ {
+		tag[size-1] = byte(b)
+		if verify(msg, tag) {
+			return tag, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// number of candidates timed again, and the factor applied to their
+// samples
+const (
+	refined        = 4
+	refinedSamples = 4
+)
+
+// timeCandidates returns the fastest of samples verifications of tag with each of
+// the candidate values at position i
+func timeCandidates(verify Verifier, msg, tag []byte, i int, candidates []int, samples int) *[256]time.Duration {
+	var timings [256]time.Duration
+	for _, b := range candidates {
+		timings[b] = 1<<63 - 1
+	}
+	// interleave the samples of the candidates so that a slow period does
+	// not favor one of them
+	for j := 0; j < samples; j++ {
+		for _, b := range candidates {
+			tag[i] = byte(b)
+			start := time.Now()
+			verify(msg, tag)
+			if d := time.Since(start); d < timings[b] {
+				timings[b] = d
+			}
+		}
+	}
+	return &timings
+}
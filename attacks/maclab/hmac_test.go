@@ -0,0 +1,61 @@
+package maclab
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRecoverHMAC(t *testing.T) {
+	s, err := NewHMACServer(rand.Reader, 20*time.Microsecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("foo")
+	tag, err := RecoverHMAC(s.Verify, msg, sha1.Size, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(tag, s.tag(msg)) {
+		t.Fatalf("expected %x but got %x", s.tag(msg), tag)
+	}
+	if _, err := RecoverHMAC(s.Verify, msg, 0, 3); err == nil {
+		t.Fatal("expected an empty tag to be rejected")
+	}
+}
+
+func TestHMACServer(t *testing.T) {
+	t.Parallel()
+	s, err := NewHMACServer(rand.Reader, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(s)
+	defer server.Close()
+	valid := hex.EncodeToString(s.tag([]byte("foo")))
+	var testcases = []struct {
+		file, signature string
+		status          int
+	}{
+		{"foo", valid, http.StatusOK},
+		{"bar", valid, http.StatusInternalServerError},
+		{"foo", valid[:38], http.StatusInternalServerError},
+		{"foo", "zz", http.StatusInternalServerError},
+	}
+	for i, tc := range testcases {
+		resp, err := http.Get(server.URL + "/test?" + url.Values{"file": {tc.file}, "signature": {tc.signature}}.Encode())
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.status {
+			t.Fatalf("testcase %d expected status %d but got %d", i, tc.status, resp.StatusCode)
+		}
+	}
+}
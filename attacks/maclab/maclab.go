@@ -0,0 +1,17 @@
+// Package maclab implements two forgeries of message authentication codes
+// that are secure on paper and broken by how they are used.
+//
+// CBC-MAC is the last block of the CBC encryption of a message under a
+// zero IV, which is secure for messages of a fixed length only. Given the
+// tags t1 of m1 and t2 of m2, the tag of m1 || (m2[0] xor t1) || m2[1:]
+// is t2: the xor cancels the state left by m1, and the rest computes as
+// for m2 alone. An attacker who can get their own messages authenticated
+// appends them to the ones of others. CMAC fixes this.
+//
+// HMAC tags checked by comparing bytes until the first difference take
+// longer the more leading bytes are right. Timing the verification of all
+// 256 values of the first byte reveals it, then the next one, and a valid
+// tag for any message comes after 256 guesses per byte instead of 2^160.
+// Network jitter only means more samples per guess. The fix is a constant
+// time comparison such as hmac.Equal.
+package maclab
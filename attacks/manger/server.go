@@ -0,0 +1,47 @@
+package manger
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// ErrFirstByte is returned by Server.Decrypt when the first byte of the
+// encoded message is not zero. Telling it apart from other decoding errors
+// is the vulnerability.
+var ErrFirstByte = errors.New("manger: decryption error, first byte is not zero")
+
+// Server is a deliberately vulnerable RSA-OAEP decryptor with SHA-256 and
+// an empty label, for exercises. It checks the first byte of the encoded
+// message before anything else and reports that failure separately, like
+// several real implementations did.
+type Server struct {
+	priv *rsa.PrivateKey
+}
+
+// NewServer returns a server decrypting with priv
+func NewServer(priv *rsa.PrivateKey) *Server {
+	return &Server{priv: priv}
+}
+
+// Decrypt decrypts an OAEP ciphertext
+func (s *Server) Decrypt(ciphertext []byte) ([]byte, error) {
+	k := s.priv.Size()
+	c := new(big.Int).SetBytes(ciphertext)
+	if len(ciphertext) != k || c.Cmp(s.priv.N) >= 0 {
+		return nil, errors.New("manger: invalid ciphertext")
+	}
+	em := c.Exp(c, s.priv.D, s.priv.N).FillBytes(make([]byte, k))
+	if em[0] != 0 {
+		return nil, ErrFirstByte
+	}
+	return unpadOAEP(sha256.New(), em, nil)
+}
+
+// Oracle reports whether the first byte of the decryption of ciphertext
+// is zero, it can be passed to DecryptOAEP as is
+func (s *Server) Oracle(ciphertext []byte) bool {
+	_, err := s.Decrypt(ciphertext)
+	return err != ErrFirstByte
+}
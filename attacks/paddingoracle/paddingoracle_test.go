@@ -0,0 +1,94 @@
+package paddingoracle
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDecrypt(t *testing.T) {
+	t.Parallel()
+	s, err := NewServer(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var testcases = [][]byte{
+		{},
+		[]byte("A"),
+		[]byte("YELLOW SUBMARINE"),
+		[]byte("attack at dawn, bring the padding"),
+		bytes.Repeat([]byte{1}, 15),
+		bytes.Repeat([]byte{2}, 31),
+	}
+	for i, plaintext := range testcases {
+		iv, ct, err := s.Encrypt(rand.Reader, plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		queries := 0
+		oracle := func(msg []byte) bool {
+			queries++
+			return s.Oracle(msg)
+		}
+		recovered, err := Decrypt(oracle, iv, ct)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if !bytes.Equal(recovered, plaintext) {
+			t.Fatalf("testcase %d expected %q but got %q", i, plaintext, recovered)
+		}
+		// at most 256 queries per byte, plus one check per block
+		if max := len(ct) * 257; queries > max {
+			t.Fatalf("testcase %d expected at most %d queries but used %d", i, max, queries)
+		}
+	}
+}
+
+func TestDecryptHTTP(t *testing.T) {
+	t.Parallel()
+	s, err := NewServer(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(s)
+	defer server.Close()
+	plaintext := []byte("user=alice;role=admin")
+	iv, ct, err := s.Encrypt(rand.Reader, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the attacker only sees status codes
+	oracle := func(msg []byte) bool {
+		resp, err := http.Get(server.URL + "/?msg=" + hex.EncodeToString(msg))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode != http.StatusForbidden
+	}
+	recovered, err := Decrypt(oracle, iv, ct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(recovered, plaintext) {
+		t.Fatalf("expected %q but got %q", plaintext, recovered)
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	t.Parallel()
+	iv := make([]byte, 16)
+	never := func([]byte) bool { return false }
+	if _, err := Decrypt(never, iv, make([]byte, 16)); err == nil {
+		t.Fatal("expected an oracle rejecting everything to fail")
+	}
+	if _, err := Decrypt(never, iv, make([]byte, 20)); err == nil {
+		t.Fatal("expected a truncated ciphertext to be rejected")
+	}
+	if _, err := Decrypt(never, nil, make([]byte, 16)); err == nil {
+		t.Fatal("expected an empty IV to be rejected")
+	}
+}
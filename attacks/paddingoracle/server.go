@@ -0,0 +1,95 @@
+package paddingoracle
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// ErrPadding is returned by Server.Decrypt for an invalid padding. Telling
+// it apart from other errors is the vulnerability.
+var ErrPadding = errors.New("paddingoracle: invalid padding")
+
+// Server is a deliberately vulnerable service decrypting AES-CBC messages
+// under a secret key, for exercises
+type Server struct {
+	block cipher.Block
+}
+
+// NewServer returns a server with a random AES-128 key
+func NewServer(rand io.Reader) (*Server, error) {
+	key := make([]byte, 16)
+	if _, err := io.ReadFull(rand, key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{block: block}, nil
+}
+
+// Encrypt pads and encrypts plaintext under a random IV
+func (s *Server) Encrypt(rand io.Reader, plaintext []byte) (iv, ct []byte, err error) {
+	iv = make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand, iv); err != nil {
+		return nil, nil, err
+	}
+	n := aes.BlockSize - len(plaintext)%aes.BlockSize
+	ct = make([]byte, len(plaintext), len(plaintext)+n)
+	copy(ct, plaintext)
+	for i := 0; i < n; i++ {
+		ct = append(ct, byte(n))
+	}
+	cipher.NewCBCEncrypter(s.block, iv).CryptBlocks(ct, ct)
+	return iv, ct, nil
+}
+
+// Decrypt decrypts msg, an IV followed by the ciphertext, and returns
+// ErrPadding when the padding is invalid
+func (s *Server) Decrypt(msg []byte) ([]byte, error) {
+	if len(msg) < 2*aes.BlockSize || len(msg)%aes.BlockSize != 0 {
+		return nil, errors.New("paddingoracle: invalid message size")
+	}
+	plaintext := make([]byte, len(msg)-aes.BlockSize)
+	cipher.NewCBCDecrypter(s.block, msg[:aes.BlockSize]).CryptBlocks(plaintext, msg[aes.BlockSize:])
+	n := int(plaintext[len(plaintext)-1])
+	if n == 0 || n > aes.BlockSize {
+		return nil, ErrPadding
+	}
+	for _, b := range plaintext[len(plaintext)-n:] {
+		if int(b) != n {
+			return nil, ErrPadding
+		}
+	}
+	return plaintext[:len(plaintext)-n], nil
+}
+
+// Oracle reports whether msg decrypts with a valid padding, it can be
+// passed to Decrypt as is
+func (s *Server) Oracle(msg []byte) bool {
+	_, err := s.Decrypt(msg)
+	return err == nil
+}
+
+// ServeHTTP decrypts the hex encoded message of the "msg" query parameter
+// and, like many real services, answers 403 on a padding error and 200
+// otherwise
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	msg, err := hex.DecodeString(r.URL.Query().Get("msg"))
+	if err != nil {
+		http.Error(w, "invalid encoding", http.StatusBadRequest)
+		return
+	}
+	if _, err := s.Decrypt(msg); err == ErrPadding {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
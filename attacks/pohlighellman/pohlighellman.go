@@ -0,0 +1,108 @@
+// Package pohlighellman implements the Pohlig-Hellman algorithm, which
+// splits a discrete logarithm in a group of composite order n into
+// logarithms in its subgroups of prime order, and the small subgroup
+// confinement attack of Lim and Lee (CRYPTO 1997) built on it.
+//
+// If n = p1^e1 ... pk^ek, raising both sides of g^x = h to n / pi^ei gives
+// x mod pi^ei, which is found one base pi digit at a time with a generic
+// algorithm in the subgroup of order pi. The Chinese remainder theorem
+// then gives x mod n. The cost is that of the largest prime factor, not
+// of n, so a group is only as strong as the largest prime dividing its
+// order.
+//
+// Diffie-Hellman in Zp* uses a subgroup of large prime order q, but p - 1
+// has other, small, factors. A party that does not check that the public
+// key it receives is in the subgroup of order q computes h^x for an h of
+// small order r chosen by the attacker, and anything it sends keyed with
+// the shared secret reveals x mod r after r guesses. Enough small factors
+// give the whole private key. The fix is to check that public keys are in
+// the right subgroup, y^q = 1, or to use a safe prime p = 2q + 1.
+package pohlighellman
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/attacks/dlog"
+)
+
+// Factor is the prime power Prime^Exponent
+type Factor struct {
+	Prime    *big.Int
+	Exponent int
+}
+
+// Solve returns x such that base^x = target, where factors is the
+// factorization of the order of base. The logarithm modulo each prime
+// power is found with baby-step giant-step, so the primes must be small
+// enough for a table of their square root.
+func Solve(g dlog.Group, base, target dlog.Element, factors []Factor) (*big.Int, error) {
+	order := big.NewInt(1)
+	for _, f := range factors {
+		if f.Exponent < 1 || f.Prime.Cmp(big.NewInt(2)) < 0 {
+			return nil, errors.New("pohlighellman: invalid factor")
+		}
+		order.Mul(order, new(big.Int).Exp(f.Prime, big.NewInt(int64(f.Exponent)), nil))
+	}
+	residues := make([]*big.Int, len(factors))
+	moduli := make([]*big.Int, len(factors))
+	for i, f := range factors {
+		x, pe, err := solvePrimePower(g, base, target, order, f)
+		if err != nil {
+			return nil, err
+		}
+		residues[i], moduli[i] = x, pe
+	}
+	x, _, err := crt(residues, moduli)
+	if err != nil {
+		return nil, err
+	}
+	if g.Key(g.Exp(base, x)) != g.Key(target) {
+		// target is not in the group generated by base, or the
+		// factorization is wrong
+		return nil, dlog.ErrNotFound
+	}
+	return x, nil
+}
+
+// solvePrimePower returns x mod p^e and p^e for the factor p^e of order
+func solvePrimePower(g dlog.Group, base, target dlog.Element, order *big.Int, f Factor) (x, pe *big.Int, err error) {
+	p := f.Prime
+	pe = new(big.Int).Exp(p, big.NewInt(int64(f.Exponent)), nil)
+	// gamma has order p
+	cofactor := new(big.Int).Quo(order, p)
+	gamma := g.Exp(base, cofactor)
+	x = new(big.Int)
+	pk := big.NewInt(1)
+	for k := 0; k < f.Exponent; k++ {
+		// (base^-x target)^(order / p^(k+1)) = gamma^d_k, the k-th digit
+		h := g.Op(g.Inverse(g.Exp(base, x)), target)
+		e := new(big.Int).Quo(order, new(big.Int).Mul(pk, p))
+		d, err := dlog.BSGS(g, gamma, g.Exp(h, e), new(big.Int), p, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		x.Add(x, d.Mul(d, pk))
+		pk.Mul(pk, p)
+	}
+	return x, pe, nil
+}
+
+// crt returns x modulo the product of the coprime moduli such that x is
+// residues[i] modulo moduli[i], and that product
+func crt(residues, moduli []*big.Int) (x, n *big.Int, err error) {
+	x, n = new(big.Int), big.NewInt(1)
+	for i, m := range moduli {
+		// x + n t = residues[i] mod m
+		inv := new(big.Int).ModInverse(n, m)
+		if inv == nil {
+			return nil, nil, errors.New("pohlighellman: moduli are not coprime")
+		}
+		t := new(big.Int).Sub(residues[i], x)
+		t.Mul(t, inv)
+		t.Mod(t, m)
+		x.Add(x, t.Mul(t, n))
+		n.Mul(n, m)
+	}
+	return x, n, nil
+}
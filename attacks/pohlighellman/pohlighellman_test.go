@@ -0,0 +1,70 @@
+package pohlighellman
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/attacks/dlog"
+)
+
+// smooth is a prime p such that p - 1 = 2^6 3^4 5^3 7^2 727 2039 2339
+// 3061 3709, and 19 generates Zp*
+var (
+	smooth        = dlog.ModP{P: mustInt("1249893781264334923416001")}
+	smoothFactors = []Factor{
+		{big.NewInt(2), 6}, {big.NewInt(3), 4}, {big.NewInt(5), 3}, {big.NewInt(7), 2},
+		{big.NewInt(727), 1}, {big.NewInt(2039), 1}, {big.NewInt(2339), 1}, {big.NewInt(3061), 1}, {big.NewInt(3709), 1},
+	}
+)
+
+func mustInt(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("invalid integer " + s)
+	}
+	return n
+}
+
+func TestSolve(t *testing.T) {
+	t.Parallel()
+	base := big.NewInt(19)
+	order := new(big.Int).Sub(smooth.P, big.NewInt(1))
+	for i := 0; i < 5; i++ {
+		x, _ := rand.Int(rand.Reader, order)
+		found, err := Solve(smooth, base, smooth.Exp(base, x), smoothFactors)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if found.Cmp(x) != 0 {
+			t.Fatalf("testcase %d expected %s but got %s", i, x, found)
+		}
+	}
+	// a subgroup of order 2^6 3^4 7^2 727
+	sub := []Factor{smoothFactors[0], smoothFactors[1], smoothFactors[3], smoothFactors[4]}
+	cofactor := big.NewInt(5 * 5 * 5 * 2039 * 2339 * 3061 * 3709)
+	subBase := smooth.Exp(base, cofactor)
+	x := big.NewInt(123456789)
+	found, err := Solve(smooth, subBase, smooth.Exp(subBase, x), sub)
+	if err != nil || found.Cmp(x) != 0 {
+		t.Fatalf("expected %s but got %v, %v", x, found, err)
+	}
+	// 19 is not in that subgroup
+	if _, err := Solve(smooth, subBase, base, sub); err != dlog.ErrNotFound {
+		t.Fatalf("expected ErrNotFound but got %v", err)
+	}
+	if _, err := Solve(smooth, base, base, []Factor{{big.NewInt(2), 0}}); err == nil {
+		t.Fatal("expected an invalid factor to be rejected")
+	}
+}
+
+func TestCRT(t *testing.T) {
+	t.Parallel()
+	x, n, err := crt([]*big.Int{big.NewInt(2), big.NewInt(3), big.NewInt(2)}, []*big.Int{big.NewInt(3), big.NewInt(5), big.NewInt(7)})
+	if err != nil || x.Int64() != 23 || n.Int64() != 105 {
+		t.Fatalf("expected 23 mod 105 but got %v mod %v, %v", x, n, err)
+	}
+	if _, _, err := crt([]*big.Int{big.NewInt(1), big.NewInt(2)}, []*big.Int{big.NewInt(4), big.NewInt(6)}); err == nil {
+		t.Fatal("expected moduli that are not coprime to be rejected")
+	}
+}
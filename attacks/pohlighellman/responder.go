@@ -0,0 +1,80 @@
+package pohlighellman
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// ErrInvalidKey is returned by a validating Responder for a public key
+// outside of the subgroup of order Q
+var ErrInvalidKey = errors.New("pohlighellman: public key is not in the subgroup")
+
+// Message is the message a Responder authenticates under the shared
+// secret
+var Message = []byte("crazy flamboyant for the rap enjoyment")
+
+// Params are Diffie-Hellman parameters: G generates a subgroup of prime
+// order Q of Zp*
+type Params struct {
+	P, G, Q *big.Int
+}
+
+// Responder is a deliberately vulnerable Diffie-Hellman party, for
+// exercises. It answers any public key with a MAC of Message under the
+// shared secret, without checking the public key unless Validate is set.
+type Responder struct {
+	Params
+	// PublicKey is G^x for the private key x
+	PublicKey *big.Int
+	// Validate enables the subgroup check, which stops the attack
+	Validate bool
+	x        *big.Int
+}
+
+// NewResponder returns a responder with a random private key in [1, Q)
+func NewResponder(rand io.Reader, params Params) (*Responder, error) {
+	x, err := randomBelow(rand, new(big.Int).Sub(params.Q, big.NewInt(1)))
+	if err != nil {
+		return nil, err
+	}
+	x.Add(x, big.NewInt(1))
+	return &Responder{
+		Params:    params,
+		PublicKey: new(big.Int).Exp(params.G, x, params.P),
+		x:         x,
+	}, nil
+}
+
+// Respond returns the tag of Message under the secret shared with the
+// public key pub
+func (r *Responder) Respond(pub *big.Int) ([]byte, error) {
+	if r.Validate {
+		pm1 := new(big.Int).Sub(r.P, big.NewInt(1))
+		if pub.Cmp(big.NewInt(1)) <= 0 || pub.Cmp(pm1) >= 0 || new(big.Int).Exp(pub, r.Q, r.P).Cmp(big.NewInt(1)) != 0 {
+			return nil, ErrInvalidKey
+		}
+	}
+	return tag(new(big.Int).Exp(pub, r.x, r.P)), nil
+}
+
+// tag returns HMAC-SHA256 of Message keyed with the hash of the shared
+// secret k
+func tag(k *big.Int) []byte {
+	key := sha256.Sum256(k.Bytes())
+	mac := hmac.New(sha256.New, key[:])
+	mac.Write(Message)
+	return mac.Sum(nil)
+}
+
+// randomBelow returns a uniform integer in [0, n)
+func randomBelow(rand io.Reader, n *big.Int) (*big.Int, error) {
+	buf := make([]byte, (n.BitLen()+7)/8+8)
+	if _, err := io.ReadFull(rand, buf); err != nil {
+		return nil, err
+	}
+	x := new(big.Int).SetBytes(buf)
+	return x.Mod(x, n), nil
+}
@@ -0,0 +1,112 @@
+package pohlighellman
+
+import (
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/attacks/dlog"
+)
+
+// Oracle returns the tag of Message under the secret shared with the
+// public key pub, such as Responder.Respond
+type Oracle func(pub *big.Int) ([]byte, error)
+
+// SmallFactors returns the distinct primes below bound dividing n, by
+// trial division
+func SmallFactors(n *big.Int, bound int64) []*big.Int {
+	var factors []*big.Int
+	n = new(big.Int).Set(n)
+	m := new(big.Int)
+	for p := int64(2); p < bound; p++ {
+		bp := big.NewInt(p)
+		if m.Mod(n, bp).Sign() != 0 {
+			continue
+		}
+		// p is prime, smaller primes were divided out
+		factors = append(factors, bp)
+		for m.Mod(n, bp).Sign() == 0 {
+			n.Quo(n, bp)
+		}
+	}
+	return factors
+}
+
+// SmallSubgroup recovers the private key behind oracle with the small
+// subgroup confinement attack. factors are small primes dividing
+// (P - 1) / Q, see SmallFactors.
+//
+// For each factor r, it sends a public key h of order r and finds x mod r
+// by comparing the tag with those of the r possible shared secrets h^i.
+// Once the product of the factors exceeds Q, the Chinese remainder theorem
+// gives x. Otherwise x = n + m R for the known n = x mod R, and if pub,
+// the public key of the victim, is given, m < Q / R is found with Pollard's
+// kangaroos from pub G^-n = (G^R)^m.
+func SmallSubgroup(rand io.Reader, params Params, pub *big.Int, factors []*big.Int, oracle Oracle) (*big.Int, error) {
+	pm1 := new(big.Int).Sub(params.P, big.NewInt(1))
+	j, m := new(big.Int).QuoRem(pm1, params.Q, new(big.Int))
+	if m.Sign() != 0 {
+		return nil, errors.New("pohlighellman: Q does not divide P - 1")
+	}
+	var residues, moduli []*big.Int
+	product := big.NewInt(1)
+	for _, r := range factors {
+		if product.Cmp(params.Q) > 0 {
+			break
+		}
+		if m.Mod(j, r).Sign() != 0 || m.Mod(params.Q, r).Sign() == 0 {
+			return nil, fmt.Errorf("pohlighellman: %s does not divide (P - 1) / Q", r)
+		}
+		// h = a^((P-1)/r) has order r unless it is 1
+		cofactor := new(big.Int).Quo(pm1, r)
+		h := big.NewInt(1)
+		for h.Cmp(big.NewInt(1)) == 0 {
+			a, err := randomBelow(rand, pm1)
+			if err != nil {
+				return nil, err
+			}
+			h.Exp(a.Add(a, big.NewInt(1)), cofactor, params.P)
+		}
+		t, err := oracle(h)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		k := big.NewInt(1)
+		for i := int64(0); i < r.Int64(); i++ {
+			if hmac.Equal(t, tag(k)) {
+				residues = append(residues, big.NewInt(i))
+				moduli = append(moduli, r)
+				found = true
+				break
+			}
+			k.Mul(k, h)
+			k.Mod(k, params.P)
+		}
+		if !found {
+			return nil, errors.New("pohlighellman: no shared secret matches the tag")
+		}
+		product.Mul(product, r)
+	}
+	n, product, err := crt(residues, moduli)
+	if err != nil {
+		return nil, err
+	}
+	if product.Cmp(params.Q) > 0 {
+		return n, nil
+	}
+	if pub == nil {
+		return nil, errors.New("pohlighellman: the factors do not cover Q and the public key is unknown")
+	}
+	g := dlog.ModP{P: params.P}
+	base := g.Exp(params.G, product)
+	target := g.Op(pub, g.Inverse(g.Exp(params.G, n)))
+	hi := new(big.Int).Quo(params.Q, product)
+	mm, err := dlog.Kangaroo(g, base, target, new(big.Int), hi)
+	if err != nil {
+		return nil, err
+	}
+	return n.Add(n, mm.Mul(mm, product)), nil
+}
@@ -0,0 +1,72 @@
+package pohlighellman
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+// toy parameters with a 64 bits Q, and P - 1 = 2 Q 241 349 587 661 761 1297
+// 1327 1567
+var params = Params{
+	P: mustInt("2334123037383030691350505506063600279562727"),
+	G: mustInt("1635842423042528218801843552219148269965511"),
+	Q: mustInt("17423972097016212017"),
+}
+
+func TestSmallFactors(t *testing.T) {
+	t.Parallel()
+	j := new(big.Int).Sub(params.P, big.NewInt(1))
+	j.Quo(j, params.Q)
+	factors := SmallFactors(j, 1000)
+	expected := []int64{2, 241, 349, 587, 661, 761}
+	if len(factors) != len(expected) {
+		t.Fatalf("expected %v but got %v", expected, factors)
+	}
+	for i, f := range factors {
+		if f.Int64() != expected[i] {
+			t.Fatalf("testcase %d expected %d but got %s", i, expected[i], f)
+		}
+	}
+	if f := SmallFactors(big.NewInt(2*2*2*9*7), 100); len(f) != 3 || f[2].Int64() != 7 {
+		t.Fatalf("expected [2 3 7] but got %v", f)
+	}
+}
+
+func TestSmallSubgroup(t *testing.T) {
+	t.Parallel()
+	r, err := NewResponder(rand.Reader, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j := new(big.Int).Sub(params.P, big.NewInt(1))
+	j.Quo(j, params.Q)
+	// the small factors cover Q, the tags alone give the key
+	x, err := SmallSubgroup(rand.Reader, params, nil, SmallFactors(j, 2000), r.Respond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.Cmp(r.x) != 0 {
+		t.Fatalf("expected %s but got %s", r.x, x)
+	}
+	// about 2^45 from the factors, the kangaroos find the remaining 19 bits
+	x, err = SmallSubgroup(rand.Reader, params, r.PublicKey, SmallFactors(j, 1000), r.Respond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.Cmp(r.x) != 0 {
+		t.Fatalf("expected %s but got %s", r.x, x)
+	}
+	if _, err := SmallSubgroup(rand.Reader, params, nil, SmallFactors(j, 1000), r.Respond); err == nil {
+		t.Fatal("expected an error without the public key")
+	}
+	// validating the public key stops the attack, and still accepts
+	// honest keys
+	r.Validate = true
+	if _, err := SmallSubgroup(rand.Reader, params, nil, SmallFactors(j, 2000), r.Respond); err != ErrInvalidKey {
+		t.Fatalf("expected ErrInvalidKey but got %v", err)
+	}
+	if _, err := r.Respond(params.G); err != nil {
+		t.Fatalf("expected a valid key to be accepted but got %v", err)
+	}
+}
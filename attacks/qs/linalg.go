@@ -0,0 +1,145 @@
+package qs
+
+import "math/big"
+
+// solve removes the relations that cannot be part of a dependency, then
+// finds the dependencies over GF(2) and tries each of them
+func (s *sieve) solve() *big.Int {
+	rels := removeSingletons(s.full, len(s.fb)+1)
+	for _, dep := range dependencies(rels, len(s.fb)+1) {
+		if f := s.squareRoot(rels, dep); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// removeSingletons drops the relations holding a prime with an odd
+// exponent that no other relation has, over and over, since they cannot
+// appear in a product with only even exponents
+func removeSingletons(rels []relation, columns int) []relation {
+	for {
+		count := make([]int, columns)
+		odd := make([][]int, len(rels))
+		for i, r := range rels {
+			odd[i] = oddFactors(r.factors)
+			for _, f := range odd[i] {
+				count[f]++
+			}
+		}
+		var kept []relation
+		for i, r := range rels {
+			single := false
+			for _, f := range odd[i] {
+				if count[f] == 1 {
+					single = true
+					break
+				}
+			}
+			if !single {
+				kept = append(kept, r)
+			}
+		}
+		if len(kept) == len(rels) {
+			return kept
+		}
+		rels = kept
+	}
+}
+
+// oddFactors returns the factors appearing an odd number of times
+func oddFactors(factors []int) []int {
+	parity := make(map[int]bool)
+	for _, f := range factors {
+		parity[f] = !parity[f]
+	}
+	var odd []int
+	for f, o := range parity {
+		if o {
+			odd = append(odd, f)
+		}
+	}
+	return odd
+}
+
+// dependencies returns sets of relations whose exponent vectors sum to
+// zero modulo 2, by Gaussian elimination of the matrix of the relations
+// augmented with the identity, which tracks the combinations
+func dependencies(rels []relation, columns int) [][]int {
+	mw := (columns + 63) / 64
+	width := mw + (len(rels)+63)/64
+	rows := make([][]uint64, len(rels))
+	for i, r := range rels {
+		rows[i] = make([]uint64, width)
+		for _, f := range oddFactors(r.factors) {
+			rows[i][f/64] |= 1 << uint(f%64)
+		}
+		rows[i][mw+i/64] |= 1 << uint(i%64)
+	}
+	pivoted := make([]bool, len(rows))
+	for col := 0; col < columns; col++ {
+		w, bit := col/64, uint64(1)<<uint(col%64)
+		pivot := -1
+		for i, row := range rows {
+			if !pivoted[i] && row[w]&bit != 0 {
+				pivot = i
+				break
+			}
+		}
+		if pivot < 0 {
+			continue
+		}
+		pivoted[pivot] = true
+		for i, row := range rows {
+			if i != pivot && row[w]&bit != 0 {
+				for k := w; k < width; k++ {
+					row[k] ^= rows[pivot][k]
+				}
+			}
+		}
+	}
+	// the rows left without a pivot are zero in the matrix part
+	var deps [][]int
+	for i, row := range rows {
+		if pivoted[i] {
+			continue
+		}
+		var dep []int
+		for j := range rels {
+			if row[mw+j/64]&(1<<uint(j%64)) != 0 {
+				dep = append(dep, j)
+			}
+		}
+		deps = append(deps, dep)
+	}
+	return deps
+}
+
+// squareRoot builds X^2 = Y^2 mod n from a dependency and returns
+// gcd(X - Y, n) if it is a proper factor
+func (s *sieve) squareRoot(rels []relation, dep []int) *big.Int {
+	x := big.NewInt(1)
+	y := big.NewInt(1)
+	exponents := make([]int, len(s.fb)+1)
+	for _, i := range dep {
+		x.Mul(x, rels[i].x).Mod(x, s.n)
+		y.Mul(y, rels[i].q).Mod(y, s.n)
+		for _, f := range rels[i].factors {
+			exponents[f]++
+		}
+	}
+	// the exponents are even, -1 contributes a square of 1
+	for k, e := range exponents[1:] {
+		if e%2 != 0 {
+			return nil
+		}
+		p := big.NewInt(int64(s.fb[k].p))
+		y.Mul(y, p.Exp(p, big.NewInt(int64(e/2)), s.n)).Mod(y, s.n)
+	}
+	f := new(big.Int).Sub(x, y)
+	f.GCD(nil, nil, f.Abs(f), s.n)
+	if f.Cmp(big.NewInt(1)) == 0 || f.Cmp(s.n) == 0 {
+		return nil
+	}
+	return f
+}
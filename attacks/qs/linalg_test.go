@@ -0,0 +1,75 @@
+package qs
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestDependencies(t *testing.T) {
+	t.Parallel()
+	// columns 0 to 4, the relations as lists of factors with repetitions
+	rels := []relation{
+		{factors: []int{1, 2}},
+		{factors: []int{2, 3, 3}},
+		{factors: []int{1, 4}},
+		{factors: []int{0, 4, 4, 4}},
+		{factors: []int{0, 1}},
+		{factors: []int{3, 3}},
+	}
+	deps := dependencies(rels, 5)
+	// 6 relations of rank 4 over GF(2), counting the empty vector
+	if len(deps) != 2 {
+		t.Fatalf("expected 2 dependencies but got %d", len(deps))
+	}
+	for i, dep := range deps {
+		parity := make([]int, 5)
+		for _, r := range dep {
+			for _, f := range rels[r].factors {
+				parity[f]++
+			}
+		}
+		for f, c := range parity {
+			if c%2 != 0 {
+				t.Fatalf("dependency %d %v has an odd exponent of %d", i, dep, f)
+			}
+		}
+	}
+}
+
+func TestRemoveSingletons(t *testing.T) {
+	t.Parallel()
+	rels := []relation{
+		{factors: []int{1, 2}},
+		{factors: []int{2, 3}},
+		{factors: []int{1, 3}},
+		// 4 is alone, then 5 is once it goes
+		{factors: []int{4, 5}},
+		{factors: []int{5, 5, 1, 2}},
+		{factors: []int{5, 6, 6}},
+	}
+	kept := removeSingletons(rels, 7)
+	if len(kept) != 4 {
+		t.Fatalf("expected 4 relations but got %d", len(kept))
+	}
+}
+
+func TestSquareRoot(t *testing.T) {
+	t.Parallel()
+	// the classic example of n = 1649 = 17 * 97: 41^2 = 32 = 2^5 and
+	// 43^2 = 200 = 2^3 5^2 mod n, so (41 * 43)^2 = (2^4 * 5)^2
+	s := &sieve{
+		n:  big.NewInt(1649),
+		fb: []fbPrime{{p: 2}, {p: 5}},
+	}
+	rels := []relation{
+		{x: big.NewInt(41), q: big.NewInt(1), factors: []int{1, 1, 1, 1, 1}},
+		{x: big.NewInt(43), q: big.NewInt(1), factors: []int{1, 1, 1, 2, 2}},
+	}
+	f := s.squareRoot(rels, []int{0, 1})
+	if f == nil || (f.Int64() != 17 && f.Int64() != 97) {
+		t.Fatalf("expected 17 or 97 but got %v", f)
+	}
+	if s.squareRoot(rels, []int{0}) != nil {
+		t.Fatal("expected an odd exponent to be rejected")
+	}
+}
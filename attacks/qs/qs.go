@@ -0,0 +1,142 @@
+// Package qs factors integers with the quadratic sieve, the fastest method
+// for numbers of up to a hundred digits or so, and the one that factored
+// RSA-129 in 1994.
+//
+// The sieve looks for many x whose square modulo n is a product of small
+// primes, the factor base. Each such relation gives a vector of the
+// exponents of the primes modulo 2, and once there are more relations than
+// primes, some of them combine into a product whose exponents are all
+// even: a congruence of squares X^2 = Y^2 mod n, where gcd(X - Y, n) is a
+// factor of n half of the time.
+//
+// Finding relations is the expensive part. The values Q(x) = x^2 - n
+// taken near the square root of n are small, and which of them a prime p
+// divides only depends on x mod p, so a sieve finds the smooth ones
+// without dividing every value: the logarithm of p is added to every
+// position x = r + kp for the two square roots r of n modulo p, and the
+// positions whose sum gets close to the logarithm of Q(x) are the smooth
+// ones. This package implements the self-initializing multiple polynomial
+// variant, which sieves Q(x) = (Ax + B)^2 - n for many A and B to keep the
+// values small, with A the product of a few primes of the factor base so
+// that each A gives many B for the cost of one, and keeps relations with
+// one prime above the factor base to combine them in pairs.
+//
+// The cost grows as exp(sqrt(ln n ln ln n)): 40 digits take a tenth of a
+// second, 60 digits half a minute, 70 digits a few minutes and 80 digits
+// about an hour on one core. A 512 bits RSA modulus, 155 digits, is out of
+// reach of this code but was factored with the number field sieve in 1999,
+// and 829 bits in 2020: RSA keys must be 2048 bits or more.
+package qs
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrPrime is returned when n is prime, or too likely so to be factored
+var ErrPrime = errors.New("qs: n is prime")
+
+// Params are the sizes of the sieve, picked from the size of n when left
+// to zero
+type Params struct {
+	// FactorBase is the number of primes in the factor base
+	FactorBase int
+	// Interval is M, each polynomial is sieved over [-M, M)
+	Interval int
+}
+
+// defaultParams are tuned for the number of decimal digits of n
+var defaultParams = []struct {
+	digits int
+	Params
+}{
+	{20, Params{FactorBase: 100, Interval: 1 << 14}},
+	{30, Params{FactorBase: 200, Interval: 1 << 15}},
+	{40, Params{FactorBase: 500, Interval: 1 << 16}},
+	{50, Params{FactorBase: 1200, Interval: 1 << 16}},
+	{60, Params{FactorBase: 2500, Interval: 1 << 16}},
+	{70, Params{FactorBase: 5000, Interval: 1 << 17}},
+	{80, Params{FactorBase: 9000, Interval: 1 << 17}},
+}
+
+// extraRelations are collected beyond the size of the factor base, each
+// gives another dependency and so another chance at a factor
+const extraRelations = 32
+
+// largePrimeMultiplier bounds the large prime of partial relations by that
+// multiple of the largest prime of the factor base
+const largePrimeMultiplier = 64
+
+// Factor returns a factor of n other than 1 and n, with the parameters
+// picked from the size of n
+func Factor(n *big.Int) (*big.Int, error) {
+	return FactorParams(n, Params{})
+}
+
+// FactorParams returns a factor of n other than 1 and n. Small factors and
+// perfect powers are found before sieving.
+func FactorParams(n *big.Int, params Params) (*big.Int, error) {
+	if n.Cmp(big.NewInt(4)) < 0 {
+		return nil, errors.New("qs: n must be at least 4")
+	}
+	if n.ProbablyPrime(20) {
+		return nil, ErrPrime
+	}
+	if n.Bit(0) == 0 {
+		return big.NewInt(2), nil
+	}
+	if r := perfectPower(n); r != nil {
+		return r, nil
+	}
+	digits := len(n.String())
+	if params.FactorBase == 0 || params.Interval == 0 {
+		d := defaultParams[len(defaultParams)-1].Params
+		for _, dp := range defaultParams {
+			if digits <= dp.digits {
+				d = dp.Params
+				break
+			}
+		}
+		if params.FactorBase == 0 {
+			params.FactorBase = d.FactorBase
+		}
+		if params.Interval == 0 {
+			params.Interval = d.Interval
+		}
+	}
+	s, factor := newSieve(n, params)
+	if factor != nil {
+		return factor, nil
+	}
+	return s.run()
+}
+
+// perfectPower returns r if n = r^k for some k >= 2
+func perfectPower(n *big.Int) *big.Int {
+	for k := 2; k <= n.BitLen(); k++ {
+		r := root(n, k)
+		if new(big.Int).Exp(r, big.NewInt(int64(k)), nil).Cmp(n) == 0 {
+			return r
+		}
+	}
+	return nil
+}
+
+// root returns the integer k-th root of n, with Newton's method
+func root(n *big.Int, k int) *big.Int {
+	bk := big.NewInt(int64(k))
+	bk1 := big.NewInt(int64(k - 1))
+	// 2^ceil(bitlen/k) is larger than the root
+	r := new(big.Int).Lsh(big.NewInt(1), uint((n.BitLen()+k-1)/k))
+	for {
+		// r' = ((k-1) r + n / r^(k-1)) / k
+		t := new(big.Int).Exp(r, bk1, nil)
+		t.Quo(n, t)
+		t.Add(t, new(big.Int).Mul(bk1, r))
+		t.Quo(t, bk)
+		if t.Cmp(r) >= 0 {
+			return r
+		}
+		r = t
+	}
+}
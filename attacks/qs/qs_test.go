@@ -0,0 +1,103 @@
+package qs
+
+import (
+	"math/big"
+	"testing"
+)
+
+func fromString(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("bad number " + s)
+	}
+	return n
+}
+
+func TestFactor(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		p, q string
+	}{
+		{"10007", "10009"},
+		{"1000000007", "1000000009"},
+		{"100000000000000003", "1000000000000000003"},
+		// 40 digits
+		{"10000000000000000051", "100000000000000000039"},
+		// 45 digits
+		{"1000000000000000000117", "100000000000000000000117"},
+	}
+	for i, tc := range testcases {
+		p, q := fromString(tc.p), fromString(tc.q)
+		n := new(big.Int).Mul(p, q)
+		f, err := Factor(n)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if f.Cmp(p) != 0 && f.Cmp(q) != 0 {
+			t.Fatalf("testcase %d expected %s or %s but got %s", i, p, q, f)
+		}
+	}
+}
+
+func TestFactorSpecial(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		n, factor string
+	}{
+		{"4", "2"},
+		{"1000000000000000000000000000000000000002", "2"},
+		// small factors turn up while building the factor base
+		{"300000000000000000117", "3"},
+		// 10007^3
+		{"1002101470343", "10007"},
+		// (10^20 + 39)^2
+		{"10000000000000000007800000000000000001521", "100000000000000000039"},
+	}
+	for i, tc := range testcases {
+		f, err := Factor(fromString(tc.n))
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if f.String() != tc.factor {
+			t.Fatalf("testcase %d expected %s but got %s", i, tc.factor, f)
+		}
+	}
+	if _, err := Factor(fromString("100000000000000000039")); err != ErrPrime {
+		t.Fatalf("expected ErrPrime but got %v", err)
+	}
+	if _, err := Factor(big.NewInt(3)); err == nil {
+		t.Fatal("expected 3 to be rejected")
+	}
+}
+
+func TestFactorParams(t *testing.T) {
+	t.Parallel()
+	p, q := fromString("1000000007"), fromString("1000000000039")
+	n := new(big.Int).Mul(p, q)
+	// a tiny factor base and interval still get there
+	f, err := FactorParams(n, Params{FactorBase: 60, Interval: 1 << 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Cmp(p) != 0 && f.Cmp(q) != 0 {
+		t.Fatalf("expected %s or %s but got %s", p, q, f)
+	}
+}
+
+func TestRoot(t *testing.T) {
+	t.Parallel()
+	for k := 2; k < 10; k++ {
+		for _, s := range []string{"2", "12345", "99999999999999999999"} {
+			r := fromString(s)
+			n := new(big.Int).Exp(r, big.NewInt(int64(k)), nil)
+			if got := root(n, k); got.Cmp(r) != 0 {
+				t.Fatalf("root %d of %s^%d expected %s but got %s", k, s, k, r, got)
+			}
+			n.Sub(n, big.NewInt(1))
+			r.Sub(r, big.NewInt(1))
+			if got := root(n, k); got.Cmp(r) != 0 {
+				t.Fatalf("root %d of %s^%d-1 expected %s but got %s", k, s, k, r, got)
+			}
+		}
+	}
+}
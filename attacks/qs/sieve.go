@@ -0,0 +1,417 @@
+package qs
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"math/bits"
+	"math/rand"
+
+	"github.com/jvehent/badcrypto/primes"
+)
+
+// fbPrime is a prime of the factor base
+type fbPrime struct {
+	p    int
+	logp byte
+	// sqrt is a square root of n modulo p
+	sqrt int
+	// root1 and root2 are the positions of the sieve array divisible by
+	// p for the current polynomial, or -1 when p divides A
+	root1, root2 int
+}
+
+// relation records that x^2 = q^2 * (-1)^e0 * prod(p_i^e_i) mod n, with
+// the primes listed in factors as indices of the factor base plus one,
+// and 0 standing for -1
+type relation struct {
+	x, q    *big.Int
+	factors []int
+}
+
+type sieve struct {
+	n     *big.Int
+	m     int
+	fb    []fbPrime
+	large int // bound of the large primes of partial relations
+	full  []relation
+	// partials are the first partial relation seen for each large prime
+	partials map[int]relation
+	// target is the ideal A, sqrt(2n) / M, which keeps |Q(x)/A| below
+	// M sqrt(n/2) over the interval
+	target *big.Int
+	usedA  map[string]bool
+	rand   *rand.Rand
+	values []byte
+}
+
+// newSieve builds the factor base, or returns a factor of n if one of its
+// primes divides n
+func newSieve(n *big.Int, params Params) (*sieve, *big.Int) {
+	s := &sieve{
+		n:        n,
+		m:        params.Interval,
+		partials: make(map[int]relation),
+		usedA:    make(map[string]bool),
+		// the choice of A needs no secret randomness, a fixed seed makes
+		// runs reproducible
+		rand:   rand.New(rand.NewSource(1)),
+		values: make([]byte, 2*params.Interval),
+	}
+	// a prime is in the factor base if n is a square modulo it, which is
+	// the case of about half of them
+	limit := 16 * params.FactorBase
+	for len(s.fb) < params.FactorBase {
+		s.fb = s.fb[:0]
+		for _, p := range primes.Below(limit) {
+			np := smallMod(n, p)
+			if np == 0 {
+				return nil, big.NewInt(int64(p))
+			}
+			if p != 2 && big.Jacobi(big.NewInt(int64(np)), big.NewInt(int64(p))) != 1 {
+				continue
+			}
+			// n is odd, 1 is its square root modulo 2
+			t := np
+			if p != 2 {
+				t = int(new(big.Int).ModSqrt(big.NewInt(int64(np)), big.NewInt(int64(p))).Int64())
+			}
+			s.fb = append(s.fb, fbPrime{p: p, logp: byte(math.Round(math.Log2(float64(p)))), sqrt: t})
+			if len(s.fb) == params.FactorBase {
+				break
+			}
+		}
+		limit *= 2
+	}
+	s.large = s.fb[len(s.fb)-1].p * largePrimeMultiplier
+	s.target = new(big.Int).Lsh(n, 1)
+	s.target.Sqrt(s.target).Quo(s.target, big.NewInt(int64(s.m)))
+	return s, nil
+}
+
+// run collects relations until the linear algebra finds a factor
+func (s *sieve) run() (*big.Int, error) {
+	want := len(s.fb) + 1 + extraRelations
+	for {
+		for len(s.full) < want {
+			s.sieveA()
+		}
+		if f := s.solve(); f != nil {
+			return f, nil
+		}
+		// every dependency gave a trivial factor, look for more
+		want += want / 10
+	}
+}
+
+// chooseA returns the indices in the factor base of primes whose product
+// A is close to the target. All but the last are picked at random among
+// primes of the right size, the last one brings the product closest to
+// the target.
+func (s *sieve) chooseA() (*big.Int, []int) {
+	// the primes of A are not sieved with, they should be neither the
+	// small ones that cost little to lose nor the large ones that make
+	// most relations: around 2000, or in the upper middle of small
+	// factor bases
+	ideal := 2000.0
+	if p := float64(s.fb[len(s.fb)*2/3].p); p < ideal {
+		ideal = p
+	}
+	logTarget := float64(s.target.BitLen())
+	count := int(math.Round(logTarget / math.Log2(ideal)))
+	if count < 1 {
+		count = 1
+	}
+	size := math.Exp2(logTarget / float64(count))
+	// the window of primes of about that size
+	lo, hi := len(s.fb), 0
+	for i, f := range s.fb {
+		if f.p > 2 && float64(f.p) > size/2 && float64(f.p) < size*2 {
+			if i < lo {
+				lo = i
+			}
+			hi = i
+		}
+	}
+	if lo > hi {
+		lo, hi = 1, len(s.fb)-1
+	}
+	for tries := 0; ; tries++ {
+		chosen := make(map[int]bool)
+		var indices []int
+		a := big.NewInt(1)
+		for len(indices) < count-1 && len(chosen) < hi-lo+1 {
+			i := lo + s.rand.Intn(hi-lo+1)
+			if !chosen[i] {
+				chosen[i] = true
+				indices = append(indices, i)
+				a.Mul(a, big.NewInt(int64(s.fb[i].p)))
+			}
+		}
+		// the last prime is the one closest to target / a
+		rest := new(big.Int).Quo(s.target, a)
+		best := -1
+		var bestDiff *big.Int
+		for i := 1; i < len(s.fb); i++ {
+			if chosen[i] {
+				continue
+			}
+			d := new(big.Int).Sub(rest, big.NewInt(int64(s.fb[i].p)))
+			d.Abs(d)
+			if best < 0 || d.Cmp(bestDiff) < 0 {
+				best, bestDiff = i, d
+			}
+		}
+		indices = append(indices, best)
+		a.Mul(a, big.NewInt(int64(s.fb[best].p)))
+		// small n run out of new values of A, repeating one is wasteful
+		// but correct
+		if key := a.String(); !s.usedA[key] || tries > 100 {
+			s.usedA[key] = true
+			return a, indices
+		}
+	}
+}
+
+// sieveA sieves all the polynomials of a new A. A is the product of s
+// primes q_j of the factor base, and B^2 = n mod A for the 2^s values
+// B = ±B_1 ± ... ± B_s where B_j is 0 modulo every prime of A but q_j.
+// B and -B give the same values, so 2^(s-1) polynomials are sieved, each
+// differing from the previous one by the sign of one B_j only as in a
+// Gray code: updating their roots only takes an addition per prime.
+func (s *sieve) sieveA() {
+	a, qs := s.chooseA()
+	inA := make(map[int]bool)
+	bs := make([]*big.Int, len(qs))
+	b := new(big.Int)
+	for j, k := range qs {
+		inA[k] = true
+		q := s.fb[k].p
+		// B_j = (A/q_j) * gamma, gamma = sqrt(n) * (A/q_j)^-1 mod q_j
+		aq := new(big.Int).Quo(a, big.NewInt(int64(q)))
+		gamma := mulMod(s.fb[k].sqrt, modInverse(smallMod(aq, q), q), q)
+		if gamma > q/2 {
+			gamma = q - gamma
+		}
+		bs[j] = aq.Mul(aq, big.NewInt(int64(gamma)))
+		b.Add(b, bs[j])
+	}
+
+	// the roots of the first polynomial, and 2 B_j / A modulo each prime
+	// to move to the next ones
+	deltas := make([][]int, len(qs))
+	for j := range deltas {
+		deltas[j] = make([]int, len(s.fb))
+	}
+	for k := range s.fb {
+		f := &s.fb[k]
+		if inA[k] {
+			f.root1, f.root2 = -1, -1
+			continue
+		}
+		ainv := modInverse(smallMod(a, f.p), f.p)
+		bm := smallMod(b, f.p)
+		// (Ax + B)^2 = n mod p for x = (±sqrt - B) / A, shifted by M
+		f.root1 = (mulMod(f.sqrt-bm+f.p, ainv, f.p) + s.m) % f.p
+		f.root2 = (mulMod(2*f.p-f.sqrt-bm, ainv, f.p) + s.m) % f.p
+		for j, bj := range bs {
+			deltas[j][k] = mulMod(2*smallMod(bj, f.p), ainv, f.p)
+		}
+	}
+
+	for i := 0; i < 1<<uint(len(qs)-1); i++ {
+		if i > 0 {
+			// B changes by 2 e B_j with j the lowest set bit of i, and the
+			// roots (±sqrt - B) / A by -2 e B_j / A
+			j := bits.TrailingZeros(uint(i))
+			e := 1
+			if ((i>>uint(j+1))+1)%2 == 1 {
+				e = -1
+			}
+			if e == 1 {
+				b.Add(b, new(big.Int).Lsh(bs[j], 1))
+			} else {
+				b.Sub(b, new(big.Int).Lsh(bs[j], 1))
+			}
+			for k := range s.fb {
+				f := &s.fb[k]
+				if f.root1 < 0 {
+					continue
+				}
+				d := deltas[j][k]
+				if e == 1 {
+					d = f.p - d
+				}
+				f.root1 = (f.root1 + d) % f.p
+				f.root2 = (f.root2 + d) % f.p
+			}
+		}
+		s.sievePolynomial(a, b, qs)
+	}
+}
+
+// sievePolynomial sieves Q(x) = (Ax + B)^2 - n = A (Ax^2 + 2Bx + C) over
+// [-M, M) and records its relations
+func (s *sieve) sievePolynomial(a, b *big.Int, qs []int) {
+	c := new(big.Int).Mul(b, b)
+	c.Sub(c, s.n).Quo(c, a)
+
+	// |Q(x) / A| is at most about M sqrt(n/2), relations with a large
+	// prime are missing up to its logarithm, and the unsieved small
+	// primes a few bits. Positions start at 128 - threshold so that those
+	// over the threshold have their top bit set.
+	maxLog := math.Log2(float64(s.m)) + float64(s.n.BitLen()-1)/2
+	threshold := int(maxLog - math.Log2(float64(s.large)) - 4)
+	if threshold < 1 {
+		threshold = 1
+	}
+	if threshold > 127 {
+		threshold = 127
+	}
+	values := s.values
+	for i := range values {
+		values[i] = byte(128 - threshold)
+	}
+	for _, f := range s.fb {
+		// the smallest primes hit most positions for little, they are
+		// left to the slack of the threshold
+		if f.p < 20 || f.root1 < 0 {
+			continue
+		}
+		for j := f.root1; j < len(values); j += f.p {
+			values[j] += f.logp
+		}
+		if f.root2 != f.root1 {
+			for j := f.root2; j < len(values); j += f.p {
+				values[j] += f.logp
+			}
+		}
+	}
+
+	v := new(big.Int)
+	x := new(big.Int)
+	tmp := new(big.Int)
+	for w := 0; w < len(values); w += 8 {
+		if w+8 <= len(values) && binary.LittleEndian.Uint64(values[w:])&0x8080808080808080 == 0 {
+			continue
+		}
+		for i := w; i < w+8 && i < len(values); i++ {
+			if values[i] < 128 {
+				continue
+			}
+			// v = A x^2 + 2 B x + C
+			x.SetInt64(int64(i - s.m))
+			v.Mul(a, x)
+			v.Add(v, tmp.Lsh(b, 1))
+			v.Mul(v, x)
+			v.Add(v, c)
+			factors, cofactor := s.trialDivide(v, i)
+			if cofactor == 0 {
+				continue
+			}
+			// Ax + B, whose square is A v modulo n
+			r := relation{x: new(big.Int).Mul(a, x), q: big.NewInt(1)}
+			r.x.Add(r.x, b).Mod(r.x, s.n)
+			for _, k := range qs {
+				r.factors = append(r.factors, k+1)
+			}
+			r.factors = append(r.factors, factors...)
+			s.addRelation(r, cofactor)
+		}
+	}
+}
+
+// addRelation records a full relation, or a partial one with a large
+// prime cofactor
+func (s *sieve) addRelation(r relation, cofactor int) {
+	if cofactor == 1 {
+		s.full = append(s.full, r)
+		return
+	}
+	// a pair of partial relations with the same large prime L multiplies
+	// into a full one with L^2 as an extra square
+	other, ok := s.partials[cofactor]
+	if !ok {
+		s.partials[cofactor] = r
+		return
+	}
+	if other.x.Cmp(r.x) == 0 {
+		return
+	}
+	combined := relation{
+		x:       new(big.Int).Mul(r.x, other.x),
+		q:       new(big.Int).Mul(r.q, other.q),
+		factors: append(append([]int{}, r.factors...), other.factors...),
+	}
+	combined.x.Mod(combined.x, s.n)
+	combined.q.Mul(combined.q, big.NewInt(int64(cofactor))).Mod(combined.q, s.n)
+	s.full = append(s.full, combined)
+}
+
+// trialDivide factors v, the value at position i of the sieve, over the
+// factor base. It returns the factors and the cofactor left, or a cofactor
+// of 0 if it is too large to be a large prime.
+func (s *sieve) trialDivide(v *big.Int, i int) ([]int, int) {
+	var factors []int
+	if v.Sign() < 0 {
+		factors = append(factors, 0)
+		v.Neg(v)
+	}
+	if v.Sign() == 0 {
+		return nil, 0
+	}
+	bigP := new(big.Int)
+	for k, f := range s.fb {
+		// the sieve positions tell which primes divide v, except for
+		// the primes of A which are tried anyway
+		if f.root1 >= 0 && i%f.p != f.root1 && i%f.p != f.root2 {
+			continue
+		}
+		if smallMod(v, f.p) != 0 {
+			continue
+		}
+		bigP.SetInt64(int64(f.p))
+		for smallMod(v, f.p) == 0 {
+			v.Quo(v, bigP)
+			factors = append(factors, k+1)
+		}
+	}
+	if v.IsInt64() && v.Int64() < int64(s.large) {
+		return factors, int(v.Int64())
+	}
+	return nil, 0
+}
+
+// smallMod returns x mod p, with the sign of p, one word at a time
+func smallMod(x *big.Int, p int) int {
+	var r uint
+	words := x.Bits()
+	for i := len(words) - 1; i >= 0; i-- {
+		r = bits.Rem(r, uint(words[i]), uint(p))
+	}
+	if x.Sign() < 0 && r != 0 {
+		return p - int(r)
+	}
+	return int(r)
+}
+
+// modInverse returns the inverse of a modulo the prime p
+func modInverse(a, p int) int {
+	// extended Euclid on small integers
+	t, newT := 0, 1
+	r, newR := p, a%p
+	for newR != 0 {
+		q := r / newR
+		t, newT = newT, t-q*newT
+		r, newR = newR, r-q*newR
+	}
+	if t < 0 {
+		t += p
+	}
+	return t
+}
+
+// mulMod returns a * b mod p for a, b and p below 2^31
+func mulMod(a, b, p int) int {
+	return int(uint64(a%p) * uint64(b) % uint64(p))
+}
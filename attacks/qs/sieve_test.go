@@ -0,0 +1,66 @@
+package qs
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestSmallMod(t *testing.T) {
+	t.Parallel()
+	var testcases = []string{"0", "1", "-1", "123456789012345678901234567890", "-98765432109876543210987654321"}
+	for i, tc := range testcases {
+		x := fromString(tc)
+		for _, p := range []int{2, 3, 65521, 2147483647} {
+			expected := new(big.Int).Mod(x, big.NewInt(int64(p))).Int64()
+			if got := smallMod(x, p); int64(got) != expected {
+				t.Fatalf("testcase %d expected %d mod %d but got %d", i, expected, p, got)
+			}
+		}
+	}
+}
+
+func TestModInverse(t *testing.T) {
+	t.Parallel()
+	for _, p := range []int{3, 101, 65521, 2147483647} {
+		for _, a := range []int{1, 2, 77, p - 1} {
+			if a%p == 0 {
+				continue
+			}
+			inv := modInverse(a, p)
+			if mulMod(a, inv, p) != 1 {
+				t.Fatalf("expected %d^-1 mod %d but got %d", a, p, inv)
+			}
+		}
+	}
+}
+
+func TestPolynomials(t *testing.T) {
+	t.Parallel()
+	n := new(big.Int).Mul(fromString("1000000000000000000117"), fromString("100000000000000000000117"))
+	s, f := newSieve(n, Params{FactorBase: 300, Interval: 1 << 14})
+	if f != nil {
+		t.Fatalf("unexpected factor %s", f)
+	}
+	// every relation found from the polynomials of many A must hold
+	for i := 0; i < 100 && len(s.full) < 20; i++ {
+		s.sieveA()
+	}
+	if len(s.full) < 20 {
+		t.Fatalf("expected 20 relations but got %d", len(s.full))
+	}
+	for i, r := range s.full {
+		rhs := new(big.Int).Mul(r.q, r.q)
+		for _, k := range r.factors {
+			if k == 0 {
+				rhs.Neg(rhs)
+			} else {
+				rhs.Mul(rhs, big.NewInt(int64(s.fb[k-1].p)))
+			}
+		}
+		rhs.Mod(rhs, n)
+		lhs := new(big.Int).Mul(r.x, r.x)
+		if lhs.Mod(lhs, n).Cmp(rhs) != 0 {
+			t.Fatalf("relation %d does not hold", i)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package rsacommon
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+)
+
+// productTree returns the levels of the product tree of leaves, from the
+// leaves up to the root. Each node is the product of its two children, a
+// lone node is carried up as is.
+func productTree(leaves []*big.Int) [][]*big.Int {
+	tree := [][]*big.Int{leaves}
+	for level := leaves; len(level) > 1; {
+		next := make([]*big.Int, (len(level)+1)/2)
+		for i := range next {
+			if 2*i+1 < len(level) {
+				next[i] = new(big.Int).Mul(level[2*i], level[2*i+1])
+			} else {
+				next[i] = level[2*i]
+			}
+		}
+		tree = append(tree, next)
+		level = next
+	}
+	return tree
+}
+
+// remainders returns x mod leaf for each leaf of tree, reducing x down the
+// tree so each step works on numbers the size of the node. With squared
+// set, it returns x mod leaf^2 instead.
+func remainders(tree [][]*big.Int, x *big.Int, squared bool) []*big.Int {
+	mod := func(x, node *big.Int) *big.Int {
+		if squared {
+			node = new(big.Int).Mul(node, node)
+		}
+		return new(big.Int).Mod(x, node)
+	}
+	level := []*big.Int{mod(x, tree[len(tree)-1][0])}
+	for i := len(tree) - 2; i >= 0; i-- {
+		next := make([]*big.Int, len(tree[i]))
+		for j := range next {
+			next[j] = mod(level[j/2], tree[i][j])
+		}
+		level = next
+	}
+	return level
+}
+
+// BatchGCD returns, for each modulus, its greatest common divisor with
+// the product of all the other moduli. A result of 1 means the modulus
+// shares no factor with the others, a prime means that prime is shared,
+// and the modulus itself means both of its primes are shared, which
+// happens when it is repeated.
+//
+// The product P of all moduli is computed with a product tree, then P mod
+// n^2 for each modulus n with a remainder tree, and the result is
+// gcd(n, (P mod n^2) / n), since P/n mod n = (P mod n^2) / n.
+func BatchGCD(moduli []*big.Int) []*big.Int {
+	if len(moduli) == 0 {
+		return nil
+	}
+	tree := productTree(moduli)
+	rems := remainders(tree, tree[len(tree)-1][0], true)
+	gcds := make([]*big.Int, len(moduli))
+	for i, n := range moduli {
+		r := new(big.Int).Quo(rems[i], n)
+		gcds[i] = r.GCD(nil, nil, r, n)
+	}
+	return gcds
+}
+
+// Shared is a modulus found to share a factor with another one
+type Shared struct {
+	// Index is the position of the modulus in the stream, starting at 0
+	Index int
+	N     *big.Int
+	// GCD is a prime factor of N, or N itself when both primes are shared
+	GCD *big.Int
+}
+
+// Scanner runs batch GCD on a stream of moduli too large to keep in a
+// single product tree, such as all the keys of a certificate scan.
+//
+// Moduli are processed in batches. Each new batch goes through BatchGCD,
+// then its product tree is used to reduce the product of every previous
+// batch modulo each of its moduli, which finds the factors shared with
+// older moduli. Only the moduli and one product per batch are kept, and
+// the work for N moduli is about N^2 / batch size multiplications of
+// modulus sized numbers.
+type Scanner struct {
+	batchSize int
+	moduli    []*big.Int
+	// products holds the product of each complete batch
+	products []*big.Int
+	// gcds holds the last reported GCD of each shared modulus
+	gcds map[int]*big.Int
+}
+
+// NewScanner returns a Scanner processing moduli in batches of batchSize,
+// a few hundreds being a good tradeoff
+func NewScanner(batchSize int) (*Scanner, error) {
+	if batchSize < 1 {
+		return nil, errors.New("rsacommon: batch size must be at least 1")
+	}
+	return &Scanner{batchSize: batchSize, gcds: make(map[int]*big.Int)}, nil
+}
+
+// Add appends n to the stream, and returns the moduli found to share a
+// factor when it completes a batch
+func (s *Scanner) Add(n *big.Int) []Shared {
+	s.moduli = append(s.moduli, new(big.Int).Set(n))
+	if len(s.moduli)-len(s.products)*s.batchSize < s.batchSize {
+		return nil
+	}
+	return s.Flush()
+}
+
+// Flush processes the moduli added since the last complete batch, even if
+// there are fewer than the batch size, and returns the moduli found to
+// share a factor. It must be called at the end of the stream.
+//
+// Moduli of older batches are returned as well when they turn out to
+// share a factor with the new ones. A modulus is only returned again if
+// its GCD changed, from one of its primes to itself when its second prime
+// is found to be shared too.
+func (s *Scanner) Flush() []Shared {
+	start := len(s.products) * s.batchSize
+	batch := s.moduli[start:]
+	if len(batch) == 0 {
+		return nil
+	}
+	found := make(map[int]*big.Int)
+	for i, g := range BatchGCD(batch) {
+		found[start+i] = g
+	}
+	tree := productTree(batch)
+	for j, product := range s.products {
+		older := s.moduli[j*s.batchSize : (j+1)*s.batchSize]
+		for i, r := range remainders(tree, product, false) {
+			n := batch[i]
+			g := new(big.Int).GCD(nil, nil, r, n)
+			if g.Cmp(one) == 0 {
+				continue
+			}
+			found[start+i] = lcm(found[start+i], g)
+			// find the partners in the older batch, hits are rare
+			for k, m := range older {
+				if h := new(big.Int).GCD(nil, nil, m, n); h.Cmp(one) != 0 {
+					index := j*s.batchSize + k
+					found[index] = lcm(s.gcd(index), lcm(found[index], h))
+				}
+			}
+		}
+	}
+	var shared []Shared
+	for index, g := range found {
+		if g == nil || g.Cmp(one) == 0 || g.Cmp(s.gcd(index)) == 0 {
+			continue
+		}
+		g = lcm(s.gcd(index), g)
+		s.gcds[index] = g
+		shared = append(shared, Shared{Index: index, N: s.moduli[index], GCD: g})
+	}
+	sort.Slice(shared, func(i, j int) bool { return shared[i].Index < shared[j].Index })
+	if len(batch) == s.batchSize {
+		s.products = append(s.products, tree[len(tree)-1][0])
+	}
+	return shared
+}
+
+// gcd returns the last reported GCD of the modulus at index, or 1
+func (s *Scanner) gcd(index int) *big.Int {
+	if g, ok := s.gcds[index]; ok {
+		return g
+	}
+	return one
+}
+
+// lcm returns the least common multiple of two divisors of a modulus,
+// where nil stands for 1
+func lcm(a, b *big.Int) *big.Int {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	g := new(big.Int).GCD(nil, nil, a, b)
+	l := new(big.Int).Mul(a, b)
+	return l.Quo(l, g)
+}
@@ -0,0 +1,137 @@
+package rsacommon
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+// weakModuli returns count moduli of 256 bits, some of which share primes
+// the way keys generated with little entropy do
+func weakModuli(t *testing.T, count int) []*big.Int {
+	primes := make([]*big.Int, 2*count)
+	for i := range primes {
+		p, err := rand.Prime(rand.Reader, 128)
+		if err != nil {
+			t.Fatal(err)
+		}
+		primes[i] = p
+	}
+	moduli := make([]*big.Int, count)
+	for i := range moduli {
+		moduli[i] = new(big.Int).Mul(primes[2*i], primes[2*i+1])
+	}
+	share := func(i, j int) {
+		moduli[i] = new(big.Int).Mul(primes[2*j], primes[2*i+1])
+	}
+	share(5, 40)
+	share(11, 10)
+	share(count-1, 2)
+	// repeated modulus
+	moduli[70] = moduli[20]
+	// both primes shared with two different moduli
+	moduli[80] = new(big.Int).Mul(primes[2*90], primes[2*3+1])
+	return moduli
+}
+
+// pairwiseGCD is the quadratic reference
+func pairwiseGCD(moduli []*big.Int) []*big.Int {
+	gcds := make([]*big.Int, len(moduli))
+	for i := range moduli {
+		gcds[i] = big.NewInt(1)
+		for j := range moduli {
+			if i != j {
+				gcds[i] = lcm(gcds[i], new(big.Int).GCD(nil, nil, moduli[i], moduli[j]))
+			}
+		}
+	}
+	return gcds
+}
+
+func TestBatchGCD(t *testing.T) {
+	t.Parallel()
+	moduli := weakModuli(t, 150)
+	expected := pairwiseGCD(moduli)
+	shared := 0
+	for i, g := range BatchGCD(moduli) {
+		if g.Cmp(expected[i]) != 0 {
+			t.Fatalf("modulus %d expected gcd %x but got %x", i, expected[i], g)
+		}
+		if g.Cmp(one) != 0 {
+			shared++
+		}
+	}
+	// 5 and 40, 10 and 11, 149 and 2, 20 and 70, 80 with 90 and 3
+	if shared != 11 {
+		t.Fatalf("expected 11 moduli sharing a factor but got %d", shared)
+	}
+	if BatchGCD(nil) != nil {
+		t.Fatal("expected no result for no moduli")
+	}
+	if g := BatchGCD(moduli[:1]); g[0].Cmp(one) != 0 {
+		t.Fatalf("expected a single modulus to share nothing but got %x", g[0])
+	}
+}
+
+func TestScanner(t *testing.T) {
+	t.Parallel()
+	moduli := weakModuli(t, 150)
+	expected := pairwiseGCD(moduli)
+	for _, batchSize := range []int{1, 7, 16, 64, 200} {
+		s, err := NewScanner(batchSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := make(map[int]*big.Int)
+		record := func(shared []Shared) {
+			for _, sh := range shared {
+				if sh.N.Cmp(moduli[sh.Index]) != 0 {
+					t.Fatalf("batch size %d reported modulus %d with the wrong value", batchSize, sh.Index)
+				}
+				if old, ok := found[sh.Index]; ok && old.Cmp(sh.GCD) == 0 {
+					t.Fatalf("batch size %d reported modulus %d twice with the same gcd", batchSize, sh.Index)
+				}
+				found[sh.Index] = sh.GCD
+			}
+		}
+		for i, n := range moduli {
+			record(s.Add(n))
+			// an early flush does not change the results
+			if i == 100 {
+				record(s.Flush())
+			}
+		}
+		record(s.Flush())
+		for i, g := range expected {
+			if g.Cmp(one) == 0 {
+				if _, ok := found[i]; ok {
+					t.Fatalf("batch size %d reported modulus %d which shares nothing", batchSize, i)
+				}
+				continue
+			}
+			if found[i] == nil || found[i].Cmp(g) != 0 {
+				t.Fatalf("batch size %d expected modulus %d to have gcd %x but got %x", batchSize, i, g, found[i])
+			}
+		}
+	}
+	if _, err := NewScanner(0); err == nil {
+		t.Fatal("expected a batch size of 0 to be rejected")
+	}
+}
+
+func BenchmarkScanner1000(b *testing.B) {
+	moduli := make([]*big.Int, 1000)
+	for i := range moduli {
+		p, _ := rand.Prime(rand.Reader, 512)
+		q, _ := rand.Prime(rand.Reader, 512)
+		moduli[i] = new(big.Int).Mul(p, q)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s, _ := NewScanner(256)
+		for _, n := range moduli {
+			s.Add(n)
+		}
+		s.Flush()
+	}
+}
@@ -0,0 +1,61 @@
+// Package rsacommon implements two attacks on RSA keys that share a
+// secret they should not.
+//
+// When a message is encrypted without padding to two keys with the same
+// modulus n and coprime exponents e1 and e2, Bezout's identity a*e1 +
+// b*e2 = 1 gives m = c1^a * c2^b mod n, without factoring n. Sharing a
+// modulus between users is never safe: any of them can factor it with
+// their own d.
+//
+// Keys generated with a poor random number generator, such as embedded
+// devices right after boot, end up sharing one of their primes. The
+// greatest common divisor of two such moduli is that prime, and batch GCD
+// finds all of them among many keys in quasi-linear time using product
+// and remainder trees, as Heninger et al. did on the keys of the whole
+// internet in 2012.
+package rsacommon
+
+import (
+	"crypto/rsa"
+	"errors"
+	"math/big"
+)
+
+var one = big.NewInt(1)
+
+// CommonModulus recovers the plaintext of two unpadded ciphertexts of the
+// same message, c1 encrypted to pub1 and c2 to pub2, where both keys have
+// the same modulus and coprime exponents. The plaintext is returned
+// without leading zeros.
+func CommonModulus(pub1, pub2 *rsa.PublicKey, c1, c2 []byte) ([]byte, error) {
+	n := pub1.N
+	if n.Cmp(pub2.N) != 0 {
+		return nil, errors.New("rsacommon: the keys do not share their modulus")
+	}
+	e1, e2 := big.NewInt(int64(pub1.E)), big.NewInt(int64(pub2.E))
+	// a*e1 + b*e2 = gcd(e1, e2), one of a and b is negative
+	a, b := new(big.Int), new(big.Int)
+	if new(big.Int).GCD(a, b, e1, e2).Cmp(one) != 0 {
+		return nil, errors.New("rsacommon: the exponents are not coprime")
+	}
+	x1, x2 := new(big.Int).SetBytes(c1), new(big.Int).SetBytes(c2)
+	if x1.Cmp(n) >= 0 || x2.Cmp(n) >= 0 {
+		return nil, errors.New("rsacommon: ciphertext out of range")
+	}
+	// c^-a = (c^-1)^a for the negative coefficient
+	if a.Sign() < 0 {
+		if x1.ModInverse(x1, n) == nil {
+			return nil, errors.New("rsacommon: ciphertext is not invertible, it shares a factor with n")
+		}
+		a.Neg(a)
+	}
+	if b.Sign() < 0 {
+		if x2.ModInverse(x2, n) == nil {
+			return nil, errors.New("rsacommon: ciphertext is not invertible, it shares a factor with n")
+		}
+		b.Neg(b)
+	}
+	m := x1.Exp(x1, a, n)
+	m.Mul(m, x2.Exp(x2, b, n))
+	return m.Mod(m, n).Bytes(), nil
+}
@@ -0,0 +1,58 @@
+package rsacommon
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"math/big"
+	"testing"
+)
+
+func encrypt(pub *rsa.PublicKey, m []byte) []byte {
+	x := new(big.Int).SetBytes(m)
+	return x.Exp(x, big.NewInt(int64(pub.E)), pub.N).Bytes()
+}
+
+func TestCommonModulus(t *testing.T) {
+	t.Parallel()
+	priv, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := []byte("the same message sent to two users of the same modulus")
+	var testcases = []struct {
+		e1, e2 int
+		ok     bool
+	}{
+		{65537, 3, true},
+		{3, 65537, true},
+		{17, 65537, true},
+		{65537, 65539, true},
+		{3, 9, false},
+		{65537, 65537, false},
+	}
+	for i, tc := range testcases {
+		pub1 := &rsa.PublicKey{N: priv.N, E: tc.e1}
+		pub2 := &rsa.PublicKey{N: priv.N, E: tc.e2}
+		recovered, err := CommonModulus(pub1, pub2, encrypt(pub1, m), encrypt(pub2, m))
+		if !tc.ok {
+			if err == nil {
+				t.Fatalf("testcase %d expected an error but got none", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if !bytes.Equal(recovered, m) {
+			t.Fatalf("testcase %d expected %q but got %q", i, m, recovered)
+		}
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CommonModulus(&priv.PublicKey, &other.PublicKey, encrypt(&priv.PublicKey, m), encrypt(&other.PublicKey, m)); err == nil {
+		t.Fatal("expected different moduli to be rejected")
+	}
+}
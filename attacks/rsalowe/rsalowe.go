@@ -0,0 +1,111 @@
+// Package rsalowe implements two attacks on textbook RSA with a small
+// public exponent such as e = 3.
+//
+// Without padding, a message m with m^e < n is never reduced modulo n,
+// and the plaintext is simply the integer e-th root of the ciphertext. If
+// m^e only wraps around the modulus a few times, trying c + k*n for small
+// k finds it as well. When the same message is sent to e recipients or
+// more, each with its own modulus but the same exponent, the Chinese
+// remainder theorem rebuilds m^e modulo the product of the moduli, which
+// is larger than m^e, and the e-th root gives m back whatever its size:
+// this is Hastad's broadcast attack. Randomized padding such as OAEP
+// defeats both, and so does a larger exponent like 65537.
+package rsalowe
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+
+	"github.com/jvehent/badcrypto/bignum"
+)
+
+// ErrNoRoot is returned when no exact e-th root was found
+var ErrNoRoot = errors.New("rsalowe: no exact root found, the message is too large or padded")
+
+// toInt converts big endian bytes to a bignum Int
+func toInt(b []byte) *bignum.Int {
+	x := new(bignum.Int)
+	x.SetBytes(b)
+	return x
+}
+
+// SmallMessage recovers the plaintext of an unpadded ciphertext by taking
+// the e-th root of c + k*n for k from 0 to maxWraps, which succeeds if
+// m^e is lower than (maxWraps + 1) * n. The plaintext is returned without
+// leading zeros.
+func SmallMessage(pub *rsa.PublicKey, ciphertext []byte, maxWraps int) ([]byte, error) {
+	if pub.E < 2 {
+		return nil, errors.New("rsalowe: invalid public exponent")
+	}
+	if len(ciphertext) > (pub.N.BitLen()+7)/8 {
+		return nil, errors.New("rsalowe: ciphertext larger than the modulus")
+	}
+	n := toInt(pub.N.Bytes())
+	c := toInt(ciphertext)
+	for k := 0; k <= maxWraps; k++ {
+		root := new(bignum.Int)
+		root.Set(c)
+		if root.Root(pub.E) {
+			return root.Bytes(), nil
+		}
+		c.Add(n)
+	}
+	return nil, ErrNoRoot
+}
+
+// Hastad recovers a message sent unpadded to several recipients that share
+// the same public exponent e, from at least e ciphertexts. pubs[i] is the
+// key ciphertexts[i] was encrypted to, and the moduli must be pairwise
+// coprime, which distinct RSA keys are unless they share a prime. The
+// plaintext is returned without leading zeros.
+func Hastad(pubs []*rsa.PublicKey, ciphertexts [][]byte) ([]byte, error) {
+	if len(pubs) != len(ciphertexts) {
+		return nil, errors.New("rsalowe: need as many public keys as ciphertexts")
+	}
+	if len(pubs) == 0 {
+		return nil, errors.New("rsalowe: no ciphertext")
+	}
+	e := pubs[0].E
+	if e < 2 {
+		return nil, errors.New("rsalowe: invalid public exponent")
+	}
+	if len(pubs) < e {
+		return nil, fmt.Errorf("rsalowe: need %d ciphertexts for e = %d but got %d", e, e, len(pubs))
+	}
+	// e ciphertexts are enough, m^e is lower than any product of e moduli
+	var residues, moduli []*bignum.Int
+	for i, pub := range pubs[:e] {
+		if pub.E != e {
+			return nil, fmt.Errorf("rsalowe: key %d has exponent %d instead of %d", i, pub.E, e)
+		}
+		if len(ciphertexts[i]) > (pub.N.BitLen()+7)/8 {
+			return nil, fmt.Errorf("rsalowe: ciphertext %d larger than its modulus", i)
+		}
+		for _, other := range pubs[:i] {
+			if other.N.Cmp(pub.N) == 0 {
+				return nil, fmt.Errorf("rsalowe: key %d is repeated", i)
+			}
+		}
+		residues = append(residues, toInt(ciphertexts[i]))
+		moduli = append(moduli, toInt(pub.N.Bytes()))
+	}
+	var me *bignum.Int
+	err := func() (err error) {
+		// CRT panics if two moduli share a factor
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("rsalowe: %v", r)
+			}
+		}()
+		me = bignum.CRT(residues, moduli)
+		return nil
+	}()
+	if err != nil {
+		return nil, err
+	}
+	if !me.Root(e) {
+		return nil, ErrNoRoot
+	}
+	return me.Bytes(), nil
+}
@@ -0,0 +1,152 @@
+package rsalowe
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"math/big"
+	"testing"
+)
+
+func TestGenerateKey(t *testing.T) {
+	t.Parallel()
+	priv, err := GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if priv.E != 3 || priv.N.BitLen() != 1024 {
+		t.Fatalf("expected a 1024 bits key with e = 3 but got %d bits and e = %d", priv.N.BitLen(), priv.E)
+	}
+	if err := priv.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	m := []byte("a message larger than the cube root of the modulus, which wraps around it")
+	c, err := EncryptRaw(&priv.PublicKey, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pt := new(big.Int).Exp(new(big.Int).SetBytes(c), priv.D, priv.N)
+	if !bytes.Equal(pt.Bytes(), m) {
+		t.Fatalf("expected %q but got %q", m, pt.Bytes())
+	}
+}
+
+func TestSmallMessage(t *testing.T) {
+	t.Parallel()
+	priv, err := GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := &priv.PublicKey
+	// the largest message that does not wrap is 341 bits, 42 bytes
+	small := []byte("attack at dawn, bring the cheese")
+	c, err := EncryptRaw(pub, small)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := SmallMessage(pub, c, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(m, small) {
+		t.Fatalf("expected %q but got %q", small, m)
+	}
+
+	// a message of 343 bits whose cube wraps around the modulus 4 to 64
+	// times, retried until it is less than 50
+	var wrapping []byte
+	var wraps int64
+	for {
+		wrapping = make([]byte, 43)
+		if _, err := rand.Read(wrapping); err != nil {
+			t.Fatal(err)
+		}
+		wrapping[0] = 0x40 | wrapping[0]&0x3f
+		x := new(big.Int).SetBytes(wrapping)
+		x.Exp(x, big.NewInt(3), nil)
+		wraps = x.Div(x, pub.N).Int64()
+		if wraps > 0 && wraps < 50 {
+			break
+		}
+	}
+	c, err = EncryptRaw(pub, wrapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SmallMessage(pub, c, int(wraps)-1); err != ErrNoRoot {
+		t.Fatalf("expected no root with %d wraps but got %v", wraps-1, err)
+	}
+	m, err = SmallMessage(pub, c, int(wraps))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(m, wrapping) {
+		t.Fatalf("expected %x but got %x", wrapping, m)
+	}
+
+	// a full size message is out of reach
+	large := make([]byte, 120)
+	large[0] = 1
+	c, err = EncryptRaw(pub, large)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SmallMessage(pub, c, 10); err != ErrNoRoot {
+		t.Fatalf("expected no root for a large message but got %v", err)
+	}
+}
+
+func TestHastad(t *testing.T) {
+	t.Parallel()
+	var pubs []*rsa.PublicKey
+	for i := 0; i < 4; i++ {
+		priv, err := GenerateKey(rand.Reader, 1024)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubs = append(pubs, &priv.PublicKey)
+	}
+	// almost as large as the modulus, SmallMessage cannot recover it
+	m := make([]byte, 120)
+	if _, err := rand.Read(m); err != nil {
+		t.Fatal(err)
+	}
+	m[0] |= 0x80
+	var cts [][]byte
+	for _, pub := range pubs {
+		c, err := EncryptRaw(pub, m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cts = append(cts, c)
+	}
+	recovered, err := Hastad(pubs[:3], cts[:3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(recovered, m) {
+		t.Fatalf("expected %x but got %x", m, recovered)
+	}
+	// extra recipients are ignored
+	recovered, err = Hastad(pubs[1:], cts[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(recovered, m) {
+		t.Fatalf("expected %x but got %x", m, recovered)
+	}
+
+	if _, err := Hastad(pubs[:2], cts[:2]); err == nil {
+		t.Fatal("expected two ciphertexts to be rejected for e = 3")
+	}
+	if _, err := Hastad(pubs[:3], cts[1:4]); err != ErrNoRoot {
+		t.Fatalf("expected mismatched keys and ciphertexts to find no root but got %v", err)
+	}
+	if _, err := Hastad([]*rsa.PublicKey{pubs[0], pubs[1], pubs[0]}, cts[:3]); err == nil {
+		t.Fatal("expected a repeated key to be rejected")
+	}
+	e17 := &rsa.PublicKey{N: pubs[2].N, E: 17}
+	if _, err := Hastad([]*rsa.PublicKey{pubs[0], pubs[1], e17}, cts[:3]); err == nil {
+		t.Fatal("expected mixed exponents to be rejected")
+	}
+}
@@ -0,0 +1,64 @@
+package rsalowe
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// GenerateKey returns an RSA key of bits bits with the public exponent 3,
+// which crypto/rsa refuses to generate
+func GenerateKey(random io.Reader, bits int) (*rsa.PrivateKey, error) {
+	if bits < 64 {
+		return nil, errors.New("rsalowe: key too small")
+	}
+	e := big.NewInt(3)
+	one := big.NewInt(1)
+	for {
+		var primes [2]*big.Int
+		for i := range primes {
+			size := bits / 2
+			if i == 0 {
+				size = bits - size
+			}
+			for {
+				p, err := rand.Prime(random, size)
+				if err != nil {
+					return nil, err
+				}
+				// e must be invertible modulo p - 1
+				if new(big.Int).Mod(p, e).Cmp(one) != 0 {
+					primes[i] = p
+					break
+				}
+			}
+		}
+		p, q := primes[0], primes[1]
+		n := new(big.Int).Mul(p, q)
+		if p.Cmp(q) == 0 || n.BitLen() != bits {
+			continue
+		}
+		phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+		d := new(big.Int).ModInverse(e, phi)
+		priv := &rsa.PrivateKey{
+			PublicKey: rsa.PublicKey{N: n, E: 3},
+			D:         d,
+			Primes:    []*big.Int{p, q},
+		}
+		priv.Precompute()
+		return priv, nil
+	}
+}
+
+// EncryptRaw encrypts m with textbook RSA, m^e mod n, without any padding.
+// This is what the attacks of this package exploit.
+func EncryptRaw(pub *rsa.PublicKey, m []byte) ([]byte, error) {
+	x := new(big.Int).SetBytes(m)
+	if x.Cmp(pub.N) >= 0 {
+		return nil, errors.New("rsalowe: message larger than the modulus")
+	}
+	c := x.Exp(x, big.NewInt(int64(pub.E)), pub.N)
+	return c.FillBytes(make([]byte, (pub.N.BitLen()+7)/8)), nil
+}
@@ -0,0 +1,162 @@
+// Package wiener implements Wiener's attack (1990) on RSA keys with a
+// small private exponent.
+//
+// Since e*d = 1 + k*phi(n) and phi(n) is close to n, e/n is a very good
+// approximation of k/d. When d < n^(1/4)/3 and q < p < 2q, it is so good
+// that k/d is one of the convergents of the continued fraction of e/n,
+// of which there are only O(log n). Each candidate gives phi(n) =
+// (e*d - 1)/k, and p and q are the roots of x^2 - (n - phi(n) + 1)x + n,
+// which are integers only for the right guess. Picking a small d to speed
+// up decryption is therefore fatal. Boneh and Durfee extend the bound to
+// d < n^0.292 with lattices, so d should simply be the inverse of a small
+// e such as 65537.
+package wiener
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/bignum"
+)
+
+// ErrNotVulnerable is returned when no convergent of e/n yields the
+// factors of n, d is too large for the attack
+var ErrNotVulnerable = errors.New("wiener: private exponent not found, the key is not vulnerable")
+
+// PublicKey is an RSA public key. Unlike rsa.PublicKey, whose exponent is
+// an int, E can be as large as n, which it is when d is small.
+type PublicKey struct {
+	N, E *big.Int
+}
+
+// PrivateKey is an RSA private key with its two prime factors
+type PrivateKey struct {
+	PublicKey
+	D, P, Q *big.Int
+}
+
+func toInt(x *big.Int) *bignum.Int {
+	y := new(bignum.Int)
+	y.SetBytes(x.Bytes())
+	return y
+}
+
+func toBig(x *bignum.Int) *big.Int {
+	return new(big.Int).SetBytes(x.Bytes())
+}
+
+// Attack recovers the private key of pub if its private exponent is small
+// enough, by walking the convergents of the continued fraction of e/n
+func Attack(pub *PublicKey) (*PrivateKey, error) {
+	if pub.E.Sign() <= 0 || pub.E.Cmp(pub.N) >= 0 {
+		return nil, errors.New("wiener: public exponent out of range")
+	}
+	n := toInt(pub.N)
+	e := toInt(pub.E)
+	zero, one := bignum.ZeroValue, bignum.OneValue
+	two := bignum.NewInt(2)
+	for _, c := range bignum.Convergents(bignum.NewRat(e, n).ContinuedFraction()) {
+		k, d := c.Num(), c.Denom()
+		if k.Compare(zero) == 0 {
+			continue
+		}
+		// phi = (e*d - 1) / k must be an integer
+		phi := new(bignum.Int)
+		phi.Set(e)
+		phi.Mul(d)
+		phi.Sub(one)
+		if phi.Div(k).Compare(zero) != 0 || phi.Compare(n) >= 0 {
+			continue
+		}
+		// p + q = n - phi + 1 and p - q = sqrt((p + q)^2 - 4n)
+		sum := new(bignum.Int)
+		sum.Set(n)
+		sum.Sub(phi)
+		sum.Add(one)
+		diff := new(bignum.Int)
+		diff.Set(sum)
+		diff.Mul(sum)
+		fourN := bignum.NewInt(4)
+		fourN.Mul(n)
+		if diff.Compare(fourN) < 0 {
+			continue
+		}
+		diff.Sub(fourN)
+		if !diff.Root(2) || diff.Compare(sum) >= 0 {
+			continue
+		}
+		p := new(bignum.Int)
+		p.Set(sum)
+		p.Add(diff)
+		if p.Div(two).Compare(zero) != 0 {
+			continue
+		}
+		q := new(bignum.Int)
+		q.Set(sum)
+		q.Sub(diff)
+		q.Div(two)
+		check := new(bignum.Int)
+		check.Set(p)
+		check.Mul(q)
+		if check.Compare(n) != 0 {
+			continue
+		}
+		return &PrivateKey{
+			PublicKey: PublicKey{N: new(big.Int).Set(pub.N), E: new(big.Int).Set(pub.E)},
+			D:         toBig(d),
+			P:         toBig(p),
+			Q:         toBig(q),
+		}, nil
+	}
+	return nil, ErrNotVulnerable
+}
+
+// GenerateWeakKey returns an RSA key of bits bits with a private exponent
+// of dBits bits, and the public exponent its inverse modulo phi(n). The
+// key falls to Attack if dBits is lower than about bits/4 - 2.
+func GenerateWeakKey(random io.Reader, bits, dBits int) (*PrivateKey, error) {
+	if bits < 64 || bits%2 != 0 {
+		return nil, errors.New("wiener: key size must be an even number of at least 64 bits")
+	}
+	if dBits < 2 || dBits >= bits {
+		return nil, errors.New("wiener: invalid private exponent size")
+	}
+	one := big.NewInt(1)
+	for {
+		// primes of the same size have q < p < 2q
+		p, err := rand.Prime(random, bits/2)
+		if err != nil {
+			return nil, err
+		}
+		q, err := rand.Prime(random, bits/2)
+		if err != nil {
+			return nil, err
+		}
+		if p.Cmp(q) == 0 {
+			continue
+		}
+		if p.Cmp(q) < 0 {
+			p, q = q, p
+		}
+		phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+		d, err := rand.Int(random, new(big.Int).Lsh(one, uint(dBits-1)))
+		if err != nil {
+			return nil, err
+		}
+		// exactly dBits bits, and odd since phi is even
+		d.SetBit(d, dBits-1, 1)
+		d.SetBit(d, 0, 1)
+		e := new(big.Int).ModInverse(d, phi)
+		if e == nil {
+			continue
+		}
+		return &PrivateKey{
+			PublicKey: PublicKey{N: new(big.Int).Mul(p, q), E: e},
+			D:         d,
+			P:         p,
+			Q:         q,
+		}, nil
+	}
+}
@@ -0,0 +1,78 @@
+package wiener
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"math/big"
+	"testing"
+)
+
+func TestAttackWikipedia(t *testing.T) {
+	t.Parallel()
+	// the toy example of wikipedia, n = 379 * 239 and d = 5
+	priv, err := Attack(&PublicKey{N: big.NewInt(90581), E: big.NewInt(17993)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if priv.D.Int64() != 5 || priv.P.Int64() != 379 || priv.Q.Int64() != 239 {
+		t.Fatalf("expected d = 5, p = 379 and q = 239 but got %s, %s and %s", priv.D, priv.P, priv.Q)
+	}
+}
+
+func TestAttack(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		bits, dBits int
+		vulnerable  bool
+	}{
+		{512, 100, true},
+		{1024, 200, true},
+		{1024, 254, true},
+		{2048, 500, true},
+		// well above n^(1/4)
+		{1024, 300, false},
+		{1024, 1000, false},
+	}
+	for i, tc := range testcases {
+		weak, err := GenerateWeakKey(rand.Reader, tc.bits, tc.dBits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if weak.N.BitLen() != tc.bits || weak.D.BitLen() != tc.dBits {
+			t.Fatalf("testcase %d expected %d bits n and %d bits d but got %d and %d",
+				i, tc.bits, tc.dBits, weak.N.BitLen(), weak.D.BitLen())
+		}
+		// e and d are inverses
+		m := big.NewInt(42)
+		c := new(big.Int).Exp(m, weak.E, weak.N)
+		if c.Exp(c, weak.D, weak.N).Cmp(m) != 0 {
+			t.Fatalf("testcase %d generated an invalid key", i)
+		}
+		priv, err := Attack(&weak.PublicKey)
+		if !tc.vulnerable {
+			if err != ErrNotVulnerable {
+				t.Fatalf("testcase %d expected the key not to be vulnerable but got %v", i, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if priv.D.Cmp(weak.D) != 0 || priv.P.Cmp(weak.P) != 0 || priv.Q.Cmp(weak.Q) != 0 {
+			t.Fatalf("testcase %d expected d = %x but got %x", i, weak.D, priv.D)
+		}
+	}
+}
+
+func TestAttackStandardKey(t *testing.T) {
+	t.Parallel()
+	// keys of crypto/rsa have e = 65537 and a full size d
+	priv, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := &PublicKey{N: priv.N, E: big.NewInt(int64(priv.E))}
+	if _, err := Attack(pub); err != ErrNotVulnerable {
+		t.Fatalf("expected a standard key not to be vulnerable but got %v", err)
+	}
+}
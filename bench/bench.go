@@ -0,0 +1,145 @@
+// Package bench compares the arithmetic of bignum with math/big, checking
+// that both libraries agree while timing them, and calibrates the bignum
+// Karatsuba, Toom-Cook and Montgomery thresholds for the machine it runs
+// on.
+//
+// The comparison is not flattering: both libraries work on machine words,
+// but math/big uses assembly and allocation free algorithms where bignum
+// uses plain Go loops and allocates at every step. The point is to see
+// how the gap grows with the size of the operands, and what Karatsuba and
+// Toom-Cook do to it.
+package bench
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/jvehent/badcrypto/bignum"
+)
+
+// minDuration is how long each operation is repeated to be timed
+const minDuration = 20 * time.Millisecond
+
+// op is an operation implemented by both libraries. The bignum version
+// overwrites its receiver, so z gets a copy of a first.
+type op struct {
+	bignum func(z, a, b *bignum.Int)
+	big    func(z, a, b *big.Int)
+}
+
+var ops = map[string]op{
+	"add": {
+		func(z, a, b *bignum.Int) { z.Set(a); z.Add(b) },
+		func(z, a, b *big.Int) { z.Add(a, b) },
+	},
+	"sub": {
+		func(z, a, b *bignum.Int) { z.Set(a); z.Sub(b) },
+		func(z, a, b *big.Int) { z.Sub(a, b) },
+	},
+	"mul": {
+		func(z, a, b *bignum.Int) { z.Set(a); z.Mul(b) },
+		func(z, a, b *big.Int) { z.Mul(a, b) },
+	},
+	"div": {
+		func(z, a, b *bignum.Int) { z.Set(a); z.Div(b) },
+		func(z, a, b *big.Int) { z.Quo(a, b) },
+	},
+}
+
+// Ops lists the operations Compare accepts
+var Ops = []string{"add", "sub", "mul", "div"}
+
+// Result compares the two libraries on one operation and operand size
+type Result struct {
+	Op   string
+	Bits int
+	// Bignum and Big are the average durations of one operation
+	Bignum, Big time.Duration
+}
+
+// Ratio returns how many times slower bignum is than math/big
+func (r Result) Ratio() float64 {
+	return float64(r.Bignum) / float64(r.Big)
+}
+
+// String formats r as a line of a table
+func (r Result) String() string {
+	return fmt.Sprintf("%-4s %6d bits  bignum %12v  math/big %10v  x%.1f", r.Op, r.Bits, r.Bignum, r.Big, r.Ratio())
+}
+
+// operands returns two random numbers of the given size, the second one
+// half as long so that subtraction and division stay meaningful
+func operands(random io.Reader, bits int) (a, b *big.Int, err error) {
+	if a, err = rand.Int(random, new(big.Int).Lsh(big.NewInt(1), uint(bits))); err != nil {
+		return nil, nil, err
+	}
+	if b, err = rand.Int(random, new(big.Int).Lsh(big.NewInt(1), uint(bits/2))); err != nil {
+		return nil, nil, err
+	}
+	// the top bit makes a of the requested size, b must not be zero
+	a.SetBit(a, bits-1, 1)
+	b.SetBit(b, 0, 1)
+	return a, b, nil
+}
+
+func toBignum(x *big.Int) *bignum.Int {
+	z := new(bignum.Int)
+	z.SetBytes(x.Bytes())
+	return z
+}
+
+// measure returns the average duration of f, repeated for at least
+// minDuration
+func measure(f func()) time.Duration {
+	n := 0
+	start := time.Now()
+	for time.Since(start) < minDuration {
+		f()
+		n++
+	}
+	return time.Since(start) / time.Duration(n)
+}
+
+// Compare times every operation of names on operands of each size in
+// bits, drawn from random, which defaults to crypto/rand. It returns an
+// error as soon as the two libraries disagree on a result.
+func Compare(random io.Reader, names []string, sizes []int) ([]Result, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	var results []Result
+	for _, name := range names {
+		o, ok := ops[name]
+		if !ok {
+			return nil, fmt.Errorf("bench: unknown operation %q", name)
+		}
+		for _, bits := range sizes {
+			if bits < 2 {
+				return nil, errors.New("bench: operands need at least 2 bits")
+			}
+			a, b, err := operands(random, bits)
+			if err != nil {
+				return nil, err
+			}
+			ba, bb := toBignum(a), toBignum(b)
+			bz, z := new(bignum.Int), new(big.Int)
+			o.bignum(bz, ba, bb)
+			o.big(z, a, b)
+			if !bytes.Equal(bz.Bytes(), z.Bytes()) {
+				return nil, fmt.Errorf("bench: %s of %x and %x gives %x with bignum but %x with math/big", name, a, b, bz.Bytes(), z)
+			}
+			results = append(results, Result{
+				Op:     name,
+				Bits:   bits,
+				Bignum: measure(func() { o.bignum(bz, ba, bb) }),
+				Big:    measure(func() { o.big(z, a, b) }),
+			})
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,89 @@
+package bench
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/bignum"
+)
+
+func TestCompare(t *testing.T) {
+	results, err := Compare(nil, Ops, []int{64, 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2*len(Ops) {
+		t.Fatalf("expected %d results but got %d", 2*len(Ops), len(results))
+	}
+	for _, r := range results {
+		if r.Bignum <= 0 || r.Big <= 0 {
+			t.Fatalf("unexpected timing %s", r)
+		}
+		t.Log(r)
+	}
+	if _, err := Compare(nil, []string{"pow"}, []int{64}); err == nil {
+		t.Fatal("expected an unknown operation to be rejected")
+	}
+}
+
+func TestCalibrate(t *testing.T) {
+	before := bignum.GetThresholds()
+	th, err := Calibrate(nil, 1024)
+	if err != nil {
+		// a busy machine may not show a clear crossover
+		t.Skip(err)
+	}
+	t.Logf("thresholds: karatsuba %d, toom %d, montgomery %d limbs", th.Karatsuba, th.Toom, th.Montgomery)
+	if th.Karatsuba < 4 || th.Toom < th.Karatsuba || th.Montgomery < th.Karatsuba || th.Toom > 1024 || th.Montgomery > 1024 {
+		t.Fatalf("thresholds %+v out of the calibrated range", th)
+	}
+	if bignum.GetThresholds() != before {
+		t.Fatal("expected Calibrate to restore the thresholds")
+	}
+	path := t.TempDir() + "/thresholds.json"
+	if err := Save(path, th); err != nil {
+		t.Fatal(err)
+	}
+	defer bignum.SetThresholds(before)
+	if err := bignum.LoadThresholds(path); err != nil {
+		t.Fatal(err)
+	}
+	if bignum.GetThresholds() != th {
+		t.Fatalf("expected the saved thresholds %+v but loaded %+v", th, bignum.GetThresholds())
+	}
+}
+
+// benchmark sizes in bits, from a curve scalar to an RSA-4096 product
+var sizes = []int{256, 1024, 2048, 4096}
+
+func benchmarkOp(b *testing.B, name string) {
+	o := ops[name]
+	for _, bits := range sizes {
+		a, c, err := operands(rand.Reader, bits)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(fmt.Sprintf("bignum/%d", bits), func(b *testing.B) {
+			ba, bc, z := toBignum(a), toBignum(c), new(bignum.Int)
+			for i := 0; i < b.N; i++ {
+				o.bignum(z, ba, bc)
+			}
+		})
+		b.Run(fmt.Sprintf("big/%d", bits), func(b *testing.B) {
+			z := new(big.Int)
+			for i := 0; i < b.N; i++ {
+				o.big(z, a, c)
+			}
+		})
+	}
+}
+
+func BenchmarkAdd(b *testing.B) { benchmarkOp(b, "add") }
+
+func BenchmarkSub(b *testing.B) { benchmarkOp(b, "sub") }
+
+func BenchmarkMul(b *testing.B) { benchmarkOp(b, "mul") }
+
+func BenchmarkDiv(b *testing.B) { benchmarkOp(b, "div") }
@@ -0,0 +1,129 @@
+package bench
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/jvehent/badcrypto/bignum"
+)
+
+// confirmations is the number of consecutive sizes the faster algorithm
+// must win to set a threshold, so that a single noisy measurement does
+// not
+const confirmations = 3
+
+// Calibrate finds the Karatsuba, Toom and Montgomery thresholds of
+// bignum for this machine, each the smallest operand size, in limbs of
+// bignum.LimbBits bits and up to maxLimbs, from which the faster
+// algorithm wins. Karatsuba is raced against the naive convolution, Toom
+// against Karatsuba with its threshold, and the Montgomery reduction by
+// multiplications against the limb loop with both. It returns the
+// thresholds to pass to bignum.SetThresholds or Save, the thresholds in
+// use are restored when it returns. It must not run while other
+// goroutines use bignum.
+func Calibrate(random io.Reader, maxLimbs int) (bignum.Thresholds, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	saved := bignum.GetThresholds()
+	defer bignum.SetThresholds(saved)
+	// until they are found, the thresholds keep the faster algorithms off
+	t := bignum.Thresholds{Karatsuba: math.MaxInt32, Toom: math.MaxInt32, Montgomery: math.MaxInt32}
+	mul := func(a, b *big.Int) func() {
+		ba, bb := toBignum(a), toBignum(b)
+		z := new(bignum.Int)
+		return func() { z.Set(ba); z.Mul(bb) }
+	}
+	var err error
+	t.Karatsuba, err = crossover(random, 4, maxLimbs, mul, func(n int) bignum.Thresholds {
+		u := t
+		u.Karatsuba = n
+		return u
+	})
+	if err != nil {
+		return saved, fmt.Errorf("bench: Karatsuba %v", err)
+	}
+	t.Toom, err = crossover(random, t.Karatsuba, maxLimbs, mul, func(n int) bignum.Thresholds {
+		u := t
+		u.Toom = n
+		return u
+	})
+	if err != nil {
+		return saved, fmt.Errorf("bench: Toom-Cook %v", err)
+	}
+	// a chain of products modulo a of n limbs, as in exponentiation
+	montgomery := func(a, b *big.Int) func() {
+		a.SetBit(a, 0, 1)
+		m, err := bignum.NewMontgomeryContext(toBignum(a))
+		if err != nil {
+			panic(err) // a is odd and above one
+		}
+		x, y := m.ToMontgomery(toBignum(b)), m.ToMontgomery(toBignum(b))
+		return func() { x = m.MontgomeryMul(x, y) }
+	}
+	t.Montgomery, err = crossover(random, t.Karatsuba, maxLimbs, montgomery, func(n int) bignum.Thresholds {
+		u := t
+		u.Montgomery = n
+		return u
+	})
+	if err != nil {
+		return saved, fmt.Errorf("bench: Montgomery reduction by multiplications %v", err)
+	}
+	return t, nil
+}
+
+// crossover returns the smallest size n, from start up to maxLimbs limbs,
+// from which the thresholds with(n) make op faster than with(MaxInt32),
+// for confirmations sizes in a row. op returns the operation to time on
+// operands a and b of n limbs.
+func crossover(random io.Reader, start, maxLimbs int, op func(a, b *big.Int) func(), with func(n int) bignum.Thresholds) (int, error) {
+	found, wins := 0, 0
+	for n := start; n <= maxLimbs; n += 1 + n/16 {
+		a, b, err := operands(random, bignum.LimbBits*n)
+		if err != nil {
+			return 0, err
+		}
+		// b of the same size as a, as in the multiplications of RSA
+		b.SetBit(b, bignum.LimbBits*n-1, 1)
+		f := op(a, b)
+		slow := timeWith(with(math.MaxInt32), f)
+		// one level of the faster algorithm, the parts of n are below n
+		fast := timeWith(with(n), f)
+
+		if fast >= slow {
+			wins = 0
+			continue
+		}
+		if wins++; wins == 1 {
+			found = n
+		}
+		if wins == confirmations {
+			return found, nil
+		}
+	}
+	return 0, fmt.Errorf("is not consistently faster below %d limbs", maxLimbs)
+}
+
+// timeWith measures f with the thresholds t
+func timeWith(t bignum.Thresholds, f func()) time.Duration {
+	if err := bignum.SetThresholds(t); err != nil {
+		panic(err)
+	}
+	return measure(f)
+}
+
+// Save writes t to a JSON file that bignum loads at init when the
+// environment variable bignum.ThresholdsEnv names it
+func Save(path string, t bignum.Thresholds) error {
+	data, err := json.MarshalIndent(t, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
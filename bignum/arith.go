@@ -0,0 +1,133 @@
+package bignum
+
+import "math/bits"
+
+// word is a limb, a digit of an Int in base 2^_W. Limbs are machine
+// words so that every multiplication of the inner loops is a single
+// hardware instruction producing a double word.
+type word = uint
+
+// _W is the number of bits of a limb, 32 or 64 depending on the platform
+const _W = bits.UintSize
+
+// LimbBits is the size in bits of the limbs of an Int, the unit of
+// Thresholds
+const LimbBits = _W
+
+// halfMask keeps the low half of a word
+const halfMask = 1<<(_W/2) - 1
+
+// The inner loops of Add, Sub and Mul work on vectors of limbs:
+//
+//	addVV(z, x, y)     z = x + y, returns the carry
+//	subVV(z, x, y)     z = x - y, returns the borrow
+//	addMulVVW(z, x, y) z = z + x*y for a single limb y, returns the carry limb
+//
+// where z, x and y have the same length and z may be x. Each has two
+// implementations. The generic one works the way it is done by hand: it
+// detects carries by comparison and multiplies half words, whose product
+// fits in a word. The bits one relies on the add, subtract and multiply
+// with carry of math/bits, which the compiler turns into single
+// instructions on amd64 and arm64. Elsewhere, or with the purego build
+// tag, the generic one is used.
+
+// addVVGeneric sets z to x + y + c, with c a carry of 0 or 1
+func addVVGeneric(z, x, y []word, c word) word {
+	for i := range z {
+		xi := x[i]
+		s := xi + y[i] + c
+		// the sum wrapped around if it is below x, or equal to it with
+		// a carry in
+		if c == 0 {
+			c = b2w(s < xi)
+		} else {
+			c = b2w(s <= xi)
+		}
+		z[i] = s
+	}
+	return c
+}
+
+// subVVGeneric sets z to x - y - b, with b a borrow of 0 or 1
+func subVVGeneric(z, x, y []word, b word) word {
+	for i := range z {
+		xi, yi := x[i], y[i]
+		d := xi - yi - b
+		// the difference wrapped around if y + b exceeds x
+		if b == 0 {
+			b = b2w(yi > xi)
+		} else {
+			b = b2w(yi >= xi)
+		}
+		z[i] = d
+	}
+	return b
+}
+
+// mulWWGeneric returns the double word product x*y as hi, lo, from the
+// four products of the half words of x and y
+func mulWWGeneric(x, y word) (hi, lo word) {
+	x0, x1 := x&halfMask, x>>(_W/2)
+	y0, y1 := y&halfMask, y>>(_W/2)
+	w0 := x0 * y0
+	t := x1*y0 + w0>>(_W/2)
+	w1, w2 := t&halfMask, t>>(_W/2)
+	w1 += x0 * y1
+	return x1*y1 + w2 + w1>>(_W/2), x * y
+}
+
+// addMulVVWGeneric sets z to z + x*y + c
+func addMulVVWGeneric(z, x []word, y, c word) word {
+	for i := range z {
+		// at most (2^W-1)^2 + 2*(2^W-1) = 2^2W-1, it cannot overflow
+		hi, lo := mulWWGeneric(x[i], y)
+		lo += z[i]
+		hi += b2w(lo < z[i])
+		lo += c
+		hi += b2w(lo < c)
+		z[i] = lo
+		c = hi
+	}
+	return c
+}
+
+// b2w converts a boolean to a word
+func b2w(b bool) word {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// addVVBits is addVVGeneric with the add with carry of math/bits
+func addVVBits(z, x, y []word) word {
+	var c word
+	for i := range z {
+		z[i], c = bits.Add(x[i], y[i], c)
+	}
+	return c
+}
+
+// subVVBits is subVVGeneric with the subtract with borrow of math/bits
+func subVVBits(z, x, y []word) word {
+	var b word
+	for i := range z {
+		z[i], b = bits.Sub(x[i], y[i], b)
+	}
+	return b
+}
+
+// addMulVVWBits is addMulVVWGeneric with the double word multiply of
+// math/bits
+func addMulVVWBits(z, x []word, y word) word {
+	var c word
+	for i := range z {
+		hi, lo := bits.Mul(x[i], y)
+		var cc word
+		lo, cc = bits.Add(lo, z[i], 0)
+		hi += cc
+		z[i], cc = bits.Add(lo, c, 0)
+		c = hi + cc
+	}
+	return c
+}
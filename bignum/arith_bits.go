@@ -0,0 +1,11 @@
+//go:build (amd64 || arm64) && !purego
+// +build amd64 arm64
+// +build !purego
+
+package bignum
+
+func addVV(z, x, y []word) word { return addVVBits(z, x, y) }
+
+func subVV(z, x, y []word) word { return subVVBits(z, x, y) }
+
+func addMulVVW(z, x []word, y word) word { return addMulVVWBits(z, x, y) }
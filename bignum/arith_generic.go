@@ -0,0 +1,10 @@
+//go:build (!amd64 && !arm64) || purego
+// +build !amd64,!arm64 purego
+
+package bignum
+
+func addVV(z, x, y []word) word { return addVVGeneric(z, x, y, 0) }
+
+func subVV(z, x, y []word) word { return subVVGeneric(z, x, y, 0) }
+
+func addMulVVW(z, x []word, y word) word { return addMulVVWGeneric(z, x, y, 0) }
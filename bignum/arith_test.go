@@ -0,0 +1,95 @@
+package bignum
+
+import (
+	"crypto/rand"
+	"testing"
+)
+
+// randomLimbs returns n limbs that are random, all ones or all zeros, to
+// exercise long carry and borrow chains
+func randomLimbs(n, kind int) []word {
+	z := make([]word, n)
+	switch kind {
+	case 0:
+		buf := make([]byte, _W/8)
+		for i := range z {
+			rand.Read(buf)
+			for _, b := range buf {
+				z[i] = z[i]<<8 | word(b)
+			}
+		}
+	case 1:
+		for i := range z {
+			z[i] = ^word(0)
+		}
+	}
+	return z
+}
+
+// TestArith checks the bits kernels against the generic ones, whichever
+// the build selected
+func TestArith(t *testing.T) {
+	t.Parallel()
+	for n := 0; n < 40; n++ {
+		for kind := 0; kind < 9; kind++ {
+			x, y := randomLimbs(n, kind%3), randomLimbs(n, kind/3)
+			var w word
+			if n > 0 {
+				w = x[n-1] | 1
+			}
+			z1, z2 := make([]word, n), make([]word, n)
+			if c1, c2 := addVVGeneric(z1, x, y, 0), addVVBits(z2, x, y); c1 != c2 || !equalLimbs(z1, z2) {
+				t.Fatalf("n=%d kind=%d add expected %x carry %d but got %x carry %d", n, kind, z1, c1, z2, c2)
+			}
+			if c1, c2 := subVVGeneric(z1, x, y, 0), subVVBits(z2, x, y); c1 != c2 || !equalLimbs(z1, z2) {
+				t.Fatalf("n=%d kind=%d sub expected %x borrow %d but got %x borrow %d", n, kind, z1, c1, z2, c2)
+			}
+			copy(z1, y)
+			copy(z2, y)
+			if c1, c2 := addMulVVWGeneric(z1, x, w, 0), addMulVVWBits(z2, x, w); c1 != c2 || !equalLimbs(z1, z2) {
+				t.Fatalf("n=%d kind=%d addmul expected %x carry %x but got %x carry %x", n, kind, z1, c1, z2, c2)
+			}
+			// the selected kernels work in place
+			copy(z1, x)
+			addVV(z1, z1, y)
+			addVVGeneric(z2, x, y, 0)
+			if !equalLimbs(z1, z2) {
+				t.Fatalf("n=%d kind=%d expected in place addition to work", n, kind)
+			}
+		}
+	}
+	// all ones plus all ones times all ones has the largest carry
+	x := randomLimbs(9, 1)
+	z := randomLimbs(9, 1)
+	if c := addMulVVWBits(z, x, ^word(0)); c != ^word(0) {
+		t.Fatalf("expected a carry of %x but got %x", ^word(0), c)
+	}
+}
+
+func equalLimbs(a, b []word) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func benchmarkAddMul(b *testing.B, f func(z, x []word, y word) word) {
+	x, z := randomLimbs(256, 0), make([]word, 256)
+	b.SetBytes(256 * _W / 8)
+	for i := 0; i < b.N; i++ {
+		f(z, x, 0xbeef)
+	}
+}
+
+func BenchmarkAddMulVVWGeneric(b *testing.B) {
+	benchmarkAddMul(b, func(z, x []word, y word) word { return addMulVVWGeneric(z, x, y, 0) })
+}
+
+func BenchmarkAddMulVVWBits(b *testing.B) {
+	benchmarkAddMul(b, addMulVVWBits)
+}
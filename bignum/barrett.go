@@ -0,0 +1,84 @@
+package bignum
+
+import "errors"
+
+// BarrettContext reduces numbers modulo a fixed m without long division,
+// as in the Handbook of Applied Cryptography, algorithm 14.42. It works
+// for any modulus, where Montgomery needs an odd one.
+//
+// With b = 2^_W the limb base and k the number of limbs of m, the
+// quotient of x < b^2k by m is close to
+//
+//	q = floor(floor(x / b^(k-1)) * mu / b^(k+1))
+//
+// where mu = floor(b^2k / m) is computed once. The divisions by powers
+// of b are shifts by whole limbs, so q costs two multiplications, and it
+// is short of the true quotient by at most two: x - q*m, which only
+// needs its low k+1 limbs, is brought below m by at most two
+// subtractions.
+type BarrettContext struct {
+	m  *Int
+	k  int
+	mu *Int
+	// bk1 is b^(k+1), added when the low limbs of x - q*m wrap around
+	bk1 *Int
+}
+
+// NewBarrettContext precomputes the context of the modulus m, which must
+// not be zero
+func NewBarrettContext(m *Int) (*BarrettContext, error) {
+	if m.len() == 0 {
+		return nil, errors.New("bignum: Barrett modulus must not be zero")
+	}
+	b := &BarrettContext{m: new(Int), k: m.len()}
+	b.m.Set(m)
+	b.m.norm()
+	b.mu = new(Int)
+	b.mu.Set(OneValue)
+	b.mu.shiftLimbs(2 * b.k)
+	b.mu.Div(b.m)
+	b.bk1 = new(Int)
+	b.bk1.Set(OneValue)
+	b.bk1.shiftLimbs(b.k + 1)
+	return b, nil
+}
+
+// window returns the limbs lo to hi of bi, floor(bi / b^lo) mod b^(hi-lo),
+// with hi clamped to the length of bi
+func (bi *Int) window(lo, hi int) *Int {
+	if hi > len(bi.nat) {
+		hi = len(bi.nat)
+	}
+	if lo >= hi {
+		return new(Int)
+	}
+	return bi.limbs(lo, hi)
+}
+
+// Reduce sets x to x mod m. Numbers of more than 2k limbs are out of
+// reach of the approximate quotient and go through long division.
+func (b *BarrettContext) Reduce(x *Int) {
+	x.mutate()
+	if x.Compare(b.m) < 0 {
+		return
+	}
+	k := b.k
+	if x.len() > 2*k {
+		x.reduce(b.m)
+		return
+	}
+	q := x.window(k-1, x.len())
+	q.Mul(b.mu)
+	q = q.window(k+1, q.len())
+	q.Mul(b.m)
+	r := x.window(0, k+1)
+	qm := q.window(0, k+1)
+	if r.Compare(qm) < 0 {
+		r.Add(b.bk1)
+	}
+	r.Sub(qm)
+	for r.Compare(b.m) >= 0 {
+		r.Sub(b.m)
+	}
+	x.nat = r.nat
+}
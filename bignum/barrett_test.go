@@ -0,0 +1,101 @@
+package bignum
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestBarrettReduce(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		x, m     int
+		expected int
+	}{
+		{100, 7, 2},
+		{6, 7, 6},
+		{7, 7, 0},
+		{1 << 40, 1 << 20, 0},
+		{(1 << 40) + 5, 1 << 20, 5},
+		{123456789, 1, 0},
+		{123456789, 65536, 123456789 % 65536},
+	}
+	for i, tc := range testcases {
+		b, err := NewBarrettContext(NewInt(tc.m))
+		if err != nil {
+			t.Fatal(err)
+		}
+		x := NewInt(tc.x)
+		b.Reduce(x)
+		if x.ToInt() != tc.expected {
+			t.Fatalf("testcase %d expected %d but got %d", i, tc.expected, x.ToInt())
+		}
+	}
+	// more than 2k limbs, through long division
+	b, err := NewBarrettContext(NewInt(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	std := new(big.Int).Lsh(big.NewInt(1), 4*_W)
+	std.Add(std, big.NewInt(12345))
+	x := fromBig(std)
+	b.Reduce(x)
+	if expected := new(big.Int).Mod(std, big.NewInt(1000)).Int64(); x.ToInt() != int(expected) {
+		t.Fatalf("expected %d but got %d", expected, x.ToInt())
+	}
+	if _, err := NewBarrettContext(new(Int)); err == nil {
+		t.Fatal("expected a zero modulus to be rejected")
+	}
+}
+
+func TestBarrettReduceRandoms(t *testing.T) {
+	t.Parallel()
+	for _, bits := range []int{16, 17, 64, 256, 1024, 2048} {
+		m, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+		// even moduli, where Montgomery does not apply
+		m.SetBit(m, 0, 0)
+		m.SetBit(m, bits-1, 1)
+		b, err := NewBarrettContext(fromBig(m))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 20; i++ {
+			x, _ := rand.Int(rand.Reader, new(big.Int).Mul(m, m))
+			got := fromBig(x)
+			b.Reduce(got)
+			if ref := new(big.Int).Mod(x, m); got.Compare(fromBig(ref)) != 0 {
+				t.Fatalf("%d bits: expected %x mod %x = %x but got %s", bits, x, m, ref, got.hex())
+			}
+		}
+	}
+}
+
+// BenchmarkBarrettReduce compares Reduce to the long division of Div on
+// a product of two numbers below an even modulus
+func BenchmarkBarrettReduce(b *testing.B) {
+	for _, bits := range []int{512, 1024, 2048} {
+		m, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+		m.SetBit(m, 0, 0)
+		x, _ := rand.Int(rand.Reader, new(big.Int).Mul(m, m))
+		bm, bx := fromBig(m), fromBig(x)
+		ctx, err := NewBarrettContext(bm)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(fmt.Sprintf("div/%d", bits), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				z := new(Int)
+				z.Set(bx)
+				z.reduce(bm)
+			}
+		})
+		b.Run(fmt.Sprintf("barrett/%d", bits), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				z := new(Int)
+				z.Set(bx)
+				ctx.Reduce(z)
+			}
+		})
+	}
+}
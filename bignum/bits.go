@@ -0,0 +1,89 @@
+package bignum
+
+import "math/bits"
+
+// Lsh shifts bi to the left by n bits, such as bi = bi * 2^n. Whole limbs
+// move by n/_W positions, and each limb sends its top n%_W bits to the
+// next one.
+func (bi *Int) Lsh(n uint) {
+	bi.mutate()
+	words, shift := int(n/_W), n%_W
+	nat := make([]word, len(bi.nat)+words+1)
+	for i, limb := range bi.nat {
+		nat[i+words] |= limb << shift
+		if shift != 0 {
+			nat[i+words+1] = limb >> (_W - shift)
+		}
+	}
+	bi.nat = nat
+	bi.norm()
+}
+
+// Rsh shifts bi to the right by n bits, such as bi = bi / 2^n rounded
+// down
+func (bi *Int) Rsh(n uint) {
+	bi.mutate()
+	words, shift := int(n/_W), n%_W
+	if words >= len(bi.nat) {
+		bi.nat = bi.nat[:0]
+		return
+	}
+	nat := make([]word, len(bi.nat)-words)
+	for i := range nat {
+		nat[i] = bi.nat[i+words] >> shift
+		if shift != 0 && i+words+1 < len(bi.nat) {
+			nat[i] |= bi.nat[i+words+1] << (_W - shift)
+		}
+	}
+	bi.nat = nat
+	bi.norm()
+}
+
+// Bit returns the value of the i-th bit of bi, 0 or 1. It panics if i is
+// negative.
+func (bi *Int) Bit(i int) uint {
+	if i < 0 {
+		panic("bignum: negative bit index")
+	}
+	return bi.bit(i)
+}
+
+// SetBit sets the i-th bit of bi to b, which must be 0 or 1
+func (bi *Int) SetBit(i int, b uint) {
+	bi.mutate()
+	if i < 0 {
+		panic("bignum: negative bit index")
+	}
+	switch b {
+	case 0:
+		if i/_W < len(bi.nat) {
+			bi.nat[i/_W] &^= 1 << uint(i%_W)
+			bi.norm()
+		}
+	case 1:
+		for len(bi.nat) <= i/_W {
+			bi.nat = append(bi.nat, 0)
+		}
+		bi.nat[i/_W] |= 1 << uint(i%_W)
+	default:
+		panic("bignum: bit value must be 0 or 1")
+	}
+}
+
+// BitLen returns the number of bits of bi, without leading zeroes. The
+// bit length of zero is 0.
+func (bi *Int) BitLen() int {
+	return bi.bitLen()
+}
+
+// TrailingZeroBits returns the number of consecutive zero bits at the
+// bottom of bi, the largest k such that 2^k divides bi. It returns 0 for
+// zero.
+func (bi *Int) TrailingZeroBits() uint {
+	for i, limb := range bi.nat {
+		if limb != 0 {
+			return uint(_W*i + bits.TrailingZeros(limb))
+		}
+	}
+	return 0
+}
@@ -0,0 +1,138 @@
+package bignum
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestShifts(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		x        int
+		n        uint
+		lsh, rsh int
+	}{
+		{0, 5, 0, 0},
+		{1, 0, 1, 1},
+		{1, 1, 2, 0},
+		{0x8001, 1, 0x10002, 0x4000},
+		{0xabcd, 16, 0xabcd0000, 0},
+		{0x12345678, 4, 0x123456780, 0x1234567},
+		{0x12345678, 20, 0x123456780 << 16, 0x123},
+		{0x12345678, 64, 0, 0},
+	}
+	for i, tc := range testcases {
+		x := NewInt(tc.x)
+		x.Rsh(tc.n)
+		if x.ToInt() != tc.rsh {
+			t.Fatalf("testcase %d expected %#x >> %d = %#x but got %#x", i, tc.x, tc.n, tc.rsh, x.ToInt())
+		}
+		if tc.n >= 32 {
+			continue
+		}
+		x = NewInt(tc.x)
+		x.Lsh(tc.n)
+		if x.ToInt() != tc.lsh {
+			t.Fatalf("testcase %d expected %#x << %d = %#x but got %#x", i, tc.x, tc.n, tc.lsh, x.ToInt())
+		}
+	}
+}
+
+func TestBigIntShiftsRandoms(t *testing.T) {
+	t.Parallel()
+	for i := 0; i < 100; i++ {
+		std, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 300))
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := uint(i * 7 % 200)
+		x := new(Int)
+		x.SetBytes(std.Bytes())
+		x.Lsh(n)
+		if expected := new(big.Int).Lsh(std, n); !bytes.Equal(x.Bytes(), expected.Bytes()) {
+			t.Fatalf("testcase %d expected %x << %d = %x but got %x", i, std, n, expected, x.Bytes())
+		}
+		x.SetBytes(std.Bytes())
+		x.Rsh(n)
+		if expected := new(big.Int).Rsh(std, n); !bytes.Equal(x.Bytes(), expected.Bytes()) {
+			t.Fatalf("testcase %d expected %x >> %d = %x but got %x", i, std, n, expected, x.Bytes())
+		}
+		if x.SetBytes(std.Bytes()); x.BitLen() != std.BitLen() {
+			t.Fatalf("testcase %d expected %d bits but got %d", i, std.BitLen(), x.BitLen())
+		}
+		if x.TrailingZeroBits() != std.TrailingZeroBits() {
+			t.Fatalf("testcase %d expected %d trailing zero bits but got %d", i, std.TrailingZeroBits(), x.TrailingZeroBits())
+		}
+		for b := 0; b < 310; b += 3 {
+			if x.Bit(b) != std.Bit(b) {
+				t.Fatalf("testcase %d expected bit %d to be %d", i, b, std.Bit(b))
+			}
+		}
+	}
+}
+
+func TestSetBit(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		x, i     int
+		b        uint
+		expected int
+	}{
+		{0, 0, 1, 1},
+		{0, 17, 1, 1 << 17},
+		{1 << 17, 17, 0, 0},
+		{0xffff, 3, 0, 0xfff7},
+		{0xffff, 3, 1, 0xffff},
+		{5, 40, 0, 5},
+	}
+	for i, tc := range testcases {
+		x := NewInt(tc.x)
+		x.SetBit(tc.i, tc.b)
+		if x.ToInt() != tc.expected {
+			t.Fatalf("testcase %d expected %#x but got %#x", i, tc.expected, x.ToInt())
+		}
+		if x.BitLen() != new(big.Int).SetInt64(int64(tc.expected)).BitLen() {
+			t.Fatalf("testcase %d expected bit length %d but got %d", i, new(big.Int).SetInt64(int64(tc.expected)).BitLen(), x.BitLen())
+		}
+	}
+}
+
+func TestTrailingZeroBits(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		x        int
+		expected uint
+	}{
+		{0, 0},
+		{1, 0},
+		{8, 3},
+		{1 << 16, 16},
+		{3 << 35, 35},
+	}
+	for i, tc := range testcases {
+		if z := NewInt(tc.x).TrailingZeroBits(); z != tc.expected {
+			t.Fatalf("testcase %d expected %d but got %d", i, tc.expected, z)
+		}
+	}
+}
+
+func TestBitPanics(t *testing.T) {
+	t.Parallel()
+	for i, f := range []func(){
+		func() { NewInt(1).Bit(-1) },
+		func() { NewInt(1).SetBit(-1, 1) },
+		func() { NewInt(1).SetBit(0, 2) },
+		func() { OneValue.Lsh(1) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("testcase %d expected a panic", i)
+				}
+			}()
+			f()
+		}()
+	}
+}
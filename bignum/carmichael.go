@@ -0,0 +1,121 @@
+package bignum
+
+// Factor is a prime power P^K of a factorization
+type Factor struct {
+	P *Int
+	K int
+}
+
+// Factorization is the decomposition of an integer into powers of
+// distinct primes, in increasing order of the primes
+type Factorization []Factor
+
+// Int returns the integer that f is the factorization of
+func (f Factorization) Int() *Int {
+	n := NewInt(1)
+	for _, pk := range f {
+		n.Mul(pow(pk.P, pk.K))
+	}
+	return n
+}
+
+// Factorize returns the prime factorization of n, in increasing order of
+// the primes, by trial division by every integer up to the square root of
+// n. It takes time exponential in the size of n and is meant for the
+// small numbers of examples, up to 40 bits or so.
+func Factorize(n *Int) Factorization {
+	if n.len() == 0 {
+		panic("factorization of zero")
+	}
+	m := new(Int)
+	m.Set(n)
+	var factors Factorization
+	d := NewInt(2)
+	for {
+		sq := new(Int)
+		sq.Set(d)
+		sq.Mul(d)
+		if sq.Compare(m) > 0 {
+			break
+		}
+		k := 0
+		for mod(m, d).len() == 0 {
+			m.Div(d)
+			k++
+		}
+		if k > 0 {
+			p := new(Int)
+			p.Set(d)
+			factors = append(factors, Factor{P: p, K: k})
+		}
+		d.Increment()
+	}
+	// what remains has no factor below its square root
+	if m.Compare(OneValue) > 0 {
+		factors = append(factors, Factor{P: m, K: 1})
+	}
+	return factors
+}
+
+// Carmichael returns λ(n), the Carmichael function of n: the smallest m
+// such that a^m = 1 mod n for every a coprime with n. It divides Euler's
+// totient φ(n), and is computed from the factorization of n as the least
+// common multiple of λ(p^k) for its prime powers, where
+//
+//	λ(p^k) = p^(k-1) * (p-1)    for an odd prime p, or 2 and 4
+//	λ(2^k) = 2^(k-2)            for k >= 3
+func Carmichael(n *Int) *Int {
+	l := NewInt(1)
+	for _, f := range Factorize(n) {
+		lp := pow(f.P, f.K-1)
+		if f.P.Compare(NewInt(2)) == 0 && f.K >= 3 {
+			lp.rsh1()
+		} else {
+			pm1 := new(Int)
+			pm1.Set(f.P)
+			pm1.Decrement()
+			lp.Mul(pm1)
+		}
+		l = lcm(l, lp)
+	}
+	return l
+}
+
+// IsCarmichaelNumber returns true if bi is a Carmichael number, a
+// composite that passes Fermat's test for every base coprime with it,
+// like 561 = 3 * 11 * 17. It uses Korselt's criterion: bi must be square
+// free, and p - 1 must divide bi - 1 for every prime factor p of bi,
+// which is the same as λ(bi) dividing bi - 1. bi is factored by trial
+// division, see Factorize.
+func (bi *Int) IsCarmichaelNumber() bool {
+	if bi.Compare(OneValue) <= 0 {
+		return false
+	}
+	factors := Factorize(bi)
+	if len(factors) < 2 {
+		// prime, or a prime power that is not square free
+		return false
+	}
+	nm1 := new(Int)
+	nm1.Set(bi)
+	nm1.Decrement()
+	for _, f := range factors {
+		pm1 := new(Int)
+		pm1.Set(f.P)
+		pm1.Decrement()
+		if f.K > 1 || mod(nm1, pm1).len() != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// lcm returns the least common multiple of a and b, which must not be
+// zero
+func lcm(a, b *Int) *Int {
+	l := new(Int)
+	l.Set(a)
+	l.Div(Gcd(a, b))
+	l.Mul(b)
+	return l
+}
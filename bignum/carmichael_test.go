@@ -0,0 +1,87 @@
+package bignum
+
+import "testing"
+
+func TestFactorize(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		n        int
+		expected []Factor
+	}{
+		{1, nil},
+		{2, []Factor{{NewInt(2), 1}}},
+		{720, []Factor{{NewInt(2), 4}, {NewInt(3), 2}, {NewInt(5), 1}}},
+		{561, []Factor{{NewInt(3), 1}, {NewInt(11), 1}, {NewInt(17), 1}}},
+		{7919, []Factor{{NewInt(7919), 1}}},
+		{2 * 1048573, []Factor{{NewInt(2), 1}, {NewInt(1048573), 1}}},
+	}
+	for i, tc := range testcases {
+		factors := Factorize(NewInt(tc.n))
+		if len(factors) != len(tc.expected) {
+			t.Fatalf("testcase %d expected %d factors but got %d", i, len(tc.expected), len(factors))
+		}
+		for k, f := range factors {
+			if f.P.Compare(tc.expected[k].P) != 0 || f.K != tc.expected[k].K {
+				t.Fatalf("testcase %d expected factor %d^%d but got %d^%d",
+					i, tc.expected[k].P.ToInt(), tc.expected[k].K, f.P.ToInt(), f.K)
+			}
+		}
+	}
+}
+
+func TestCarmichael(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		n, lambda int
+	}{
+		{1, 1},
+		{2, 1},
+		{4, 2},
+		{8, 2},
+		{16, 4},
+		{9, 6},
+		{15, 4},
+		{561, 80},
+		{720, 12},
+		{1105, 48},
+		{1729, 36},
+	}
+	for i, tc := range testcases {
+		l := Carmichael(NewInt(tc.n))
+		if l.ToInt() != tc.lambda {
+			t.Fatalf("testcase %d expected λ(%d) = %d but got %d", i, tc.n, tc.lambda, l.ToInt())
+		}
+	}
+}
+
+func TestIsCarmichaelNumber(t *testing.T) {
+	t.Parallel()
+	// the Carmichael numbers below 3000
+	expected := map[int]bool{561: true, 1105: true, 1729: true, 2465: true, 2821: true}
+	for n := 1; n < 3000; n++ {
+		if r := NewInt(n).IsCarmichaelNumber(); r != expected[n] {
+			t.Fatalf("expected IsCarmichaelNumber=%t for %d but got %t", expected[n], n, r)
+		}
+	}
+}
+
+// TestFermatLiar runs the example of Carmichael numbers fooling Fermat's
+// test: every base coprime with 561 passes it, although 561 = 3 * 11 * 17
+func TestFermatLiar(t *testing.T) {
+	t.Parallel()
+	n := NewInt(561)
+	nm1 := NewInt(560)
+	for a := 2; a < 561; a++ {
+		base := NewInt(a)
+		if Gcd(base, n).Compare(OneValue) != 0 {
+			continue
+		}
+		if x := modExp(base, nm1, n); x.Compare(OneValue) != 0 {
+			t.Fatalf("expected %d^560 = 1 mod 561 but got %d", a, x.ToInt())
+		}
+	}
+	// Solovay-Strassen is not fooled
+	if n.IsSolovayStrassenPrime(20) {
+		t.Fatal("expected 561 to fail the Solovay-Strassen test")
+	}
+}
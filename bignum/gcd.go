@@ -0,0 +1,95 @@
+package bignum
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Gcd returns the greatest common divisor of x and y with Euclid's
+// algorithm. Gcd(x, 0) is x.
+func Gcd(x, y *Int) *Int {
+	a := new(Int)
+	a.Set(x)
+	b := new(Int)
+	b.Set(y)
+	for b.len() != 0 {
+		a, b = b, mod(a, b)
+	}
+	return a
+}
+
+// ExtendedGcd returns g = gcd(a, b) and the Bezout coefficients x and y
+// such that a*x - b*y = g. Int has no sign, so the identity is written
+// with a subtraction and both coefficients are positive: x is at most
+// b/g and y lower than a/g. a must not be zero, ExtendedGcd panics
+// otherwise, since g = b is then no such difference.
+//
+// The extended Euclidean algorithm keeps the remainders r(i) = a*x(i) +
+// b*y(i), where the signs of x(i) and y(i) alternate with i, so only their
+// magnitudes are stored: |x(i+1)| = |x(i-1)| + q(i)*|x(i)|. When the last
+// coefficient of a is negative, adding b/g to it and a/g to that of b
+// gives the positive pair.
+func ExtendedGcd(a, b *Int) (g, x, y *Int) {
+	if a.len() == 0 {
+		panic("bignum: extended gcd with a zero first argument")
+	}
+	r0, r1 := new(Int), new(Int)
+	r0.Set(a)
+	r1.Set(b)
+	x0, x1 := NewInt(1), new(Int)
+	y0, y1 := new(Int), NewInt(1)
+	odd := false
+	for r1.len() != 0 {
+		q := new(Int)
+		q.Set(r0)
+		rem := q.Div(r1)
+		r0, r1 = r1, rem
+		// |x(i+1)| = |x(i-1)| + q(i)*|x(i)|, and the same for y
+		x2 := new(Int)
+		x2.Set(x1)
+		x2.Mul(q)
+		x2.Add(x0)
+		y2 := new(Int)
+		y2.Set(y1)
+		y2.Mul(q)
+		y2.Add(y0)
+		x0, x1 = x1, x2
+		y0, y1 = y1, y2
+		odd = !odd
+	}
+	g, x, y = r0, x0, y0
+	if odd {
+		// -a*|x| + b*|y| = g, so a*(b/g - |x|) - b*(a/g - |y|) = g
+		bg := new(Int)
+		bg.Set(b)
+		bg.Div(g)
+		bg.Sub(x)
+		ag := new(Int)
+		ag.Set(a)
+		ag.Div(g)
+		ag.Sub(y)
+		x, y = bg, ag
+	}
+	return g, x, y
+}
+
+// ModInverse returns the inverse of a modulo n, the x lower than n such
+// that a*x = 1 mod n, or an error when a and n are not coprime and a has
+// no inverse
+func ModInverse(a, n *Int) (*Int, error) {
+	if n.len() == 0 {
+		return nil, errors.New("bignum: no inverse modulo zero")
+	}
+	r := mod(a, n)
+	if r.len() == 0 {
+		if n.Compare(OneValue) == 0 {
+			return new(Int), nil
+		}
+		return nil, fmt.Errorf("bignum: %s has no inverse modulo %s", a.hex(), n.hex())
+	}
+	g, x, _ := ExtendedGcd(r, n)
+	if g.Compare(OneValue) != 0 {
+		return nil, fmt.Errorf("bignum: %s has no inverse modulo %s, they share the factor %s", a.hex(), n.hex(), g.hex())
+	}
+	return mod(x, n), nil
+}
@@ -0,0 +1,150 @@
+package bignum
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestGcd(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		x, y, gcd int
+	}{
+		{12, 18, 6},
+		{18, 12, 6},
+		{17, 5, 1},
+		{0, 7, 7},
+		{7, 0, 7},
+		{0, 0, 0},
+		{1 << 40, 3 << 20, 1 << 20},
+	}
+	for i, tc := range testcases {
+		if g := Gcd(NewInt(tc.x), NewInt(tc.y)); g.ToInt() != tc.gcd {
+			t.Fatalf("testcase %d expected gcd %d but got %d", i, tc.gcd, g.ToInt())
+		}
+	}
+}
+
+func TestExtendedGcd(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		a, b, g, x, y int
+	}{
+		// 240*(-9) + 46*47 = 2, or 240*14 - 46*73 = 2
+		{240, 46, 2, 14, 73},
+		{46, 240, 2, 47, 9},
+		{3, 11, 1, 4, 1},
+		{12, 4, 4, 1, 2},
+		{4, 12, 4, 1, 0},
+		{7, 0, 7, 1, 0},
+		{1, 1, 1, 1, 0},
+	}
+	for i, tc := range testcases {
+		g, x, y := ExtendedGcd(NewInt(tc.a), NewInt(tc.b))
+		if g.ToInt() != tc.g || x.ToInt() != tc.x || y.ToInt() != tc.y {
+			t.Fatalf("testcase %d expected (%d, %d, %d) but got (%d, %d, %d)",
+				i, tc.g, tc.x, tc.y, g.ToInt(), x.ToInt(), y.ToInt())
+		}
+	}
+}
+
+func TestExtendedGcdRandoms(t *testing.T) {
+	t.Parallel()
+	limit := new(big.Int).Lsh(big.NewInt(1), 512)
+	for i := 0; i < 50; i++ {
+		stda, err := rand.Int(rand.Reader, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdb, err := rand.Int(rand.Reader, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// a common factor, so that g is not always 1
+		stda.Mul(stda, big.NewInt(6))
+		stdb.Mul(stdb, big.NewInt(10))
+		stda.Add(stda, big.NewInt(6))
+		a, b := new(Int), new(Int)
+		a.SetBytes(stda.Bytes())
+		b.SetBytes(stdb.Bytes())
+		g, x, y := ExtendedGcd(a, b)
+		stdg := new(big.Int).GCD(nil, nil, stda, stdb)
+		if g.Compare(fromBig(stdg)) != 0 {
+			t.Fatalf("testcase %d expected gcd %x but got %s", i, stdg, g.hex())
+		}
+		// a*x - b*y = g
+		ax := new(Int)
+		ax.Set(a)
+		ax.Mul(x)
+		by := new(Int)
+		by.Set(b)
+		by.Mul(y)
+		by.Add(g)
+		if ax.Compare(by) != 0 {
+			t.Fatalf("testcase %d expected a*x - b*y = g for a=%s b=%s x=%s y=%s", i, a.hex(), b.hex(), x.hex(), y.hex())
+		}
+	}
+}
+
+func fromBig(x *big.Int) *Int {
+	bi := new(Int)
+	bi.SetBytes(x.Bytes())
+	return bi
+}
+
+func TestModInverse(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		a, m, inv int
+	}{
+		{3, 11, 4},
+		{10, 17, 12},
+		{1, 7, 1},
+		{65537, 4294967311, 268431361},
+		{6, 9, 0},
+		{18, 7, 2},
+		{9, 3, 0},
+		{5, 0, 0},
+	}
+	for i, testcase := range testcases {
+		inv, err := ModInverse(NewInt(testcase.a), NewInt(testcase.m))
+		if testcase.inv == 0 {
+			if err == nil {
+				t.Fatalf("testcase %d expected no inverse but got %d", i, inv.ToInt())
+			}
+			continue
+		}
+		if err != nil || inv.ToInt() != testcase.inv {
+			t.Fatalf("testcase %d expected %d", i, testcase.inv)
+		}
+	}
+	if inv, err := ModInverse(NewInt(5), OneValue); err != nil || inv.len() != 0 {
+		t.Fatalf("expected 0 as the inverse modulo 1 but got %v, %v", inv, err)
+	}
+}
+
+func TestModInverseRandoms(t *testing.T) {
+	t.Parallel()
+	for i := 0; i < 20; i++ {
+		stdm, err := rand.Prime(rand.Reader, 512)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stda, err := rand.Int(rand.Reader, stdm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stda.Sign() == 0 {
+			continue
+		}
+		inv, err := ModInverse(fromBig(stda), fromBig(stdm))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := new(big.Int).ModInverse(stda, stdm)
+		if inv.Compare(fromBig(expected)) != 0 {
+			t.Fatalf("testcase %d expected %x but got %s", i, expected, inv.hex())
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package bignum
+
+import (
+	"errors"
+	"io"
+)
+
+// primeRounds is the number of Miller-Rabin rounds of generated primes,
+// a composite passes them with a probability below 2^-40
+const primeRounds = 20
+
+// maxSieveDelta bounds the search from a random start, after which a new
+// start is drawn
+const maxSieveDelta = 1 << 20
+
+// GeneratePrime returns a random prime of exactly bits bits, read from r.
+//
+// It draws an odd start with the top bit set and searches the odd numbers
+// above it. The residues of the start modulo the primes below 256 are
+// computed once, and give those of every candidate with a small addition,
+// which rules out four candidates out of five without touching the Int.
+// Survivors go through Fermat's test to base 2, a single modular
+// exponentiation that fails almost every composite, then Miller-Rabin.
+//
+// Searching from a random start favors the primes that follow long gaps,
+// which is harmless, and what most libraries do.
+func GeneratePrime(r io.Reader, bits int) (*Int, error) {
+	if bits < 2 {
+		return nil, errors.New("bignum: prime size must be at least 2 bits")
+	}
+	for {
+		start, err := randomBits(r, bits)
+		if err != nil {
+			return nil, err
+		}
+		if p := searchPrime(start, bits, false, nil); p != nil {
+			return p, nil
+		}
+	}
+}
+
+// GenerateSafePrime returns a random safe prime p of exactly bits bits,
+// such that q = (p-1)/2 is also prime, read from r. The multiplicative
+// group modulo p then has order 2q, and its only subgroups have 1, 2, q
+// and 2q elements, which leaves no small subgroup for Pohlig-Hellman to
+// break Diffie-Hellman into.
+//
+// It searches q like GeneratePrime does, also sieving out the q for which
+// a small prime divides 2q + 1. Safe primes are rarer than primes by a
+// factor of about the bit size, so expect a 1024 bits one to take tens of
+// minutes with the arithmetic of this package.
+func GenerateSafePrime(r io.Reader, bits int) (*Int, error) {
+	if bits < 3 {
+		return nil, errors.New("bignum: safe prime size must be at least 3 bits")
+	}
+	for {
+		start, err := randomBits(r, bits-1)
+		if err != nil {
+			return nil, err
+		}
+		if q := searchPrime(start, bits-1, true, nil); q != nil {
+			p := new(Int)
+			p.Set(q)
+			p.Add(q)
+			p.Increment()
+			return p, nil
+		}
+	}
+}
+
+// SearchPrime returns the first prime from start upwards that has as many
+// bits as start. It returns nil if there is none within 2^20 of start, or
+// once done is closed, which lets goroutines searching from different
+// starts stop when one of them succeeds. A nil done never stops it.
+func SearchPrime(start *Int, done <-chan struct{}) *Int {
+	n := new(Int)
+	n.Set(start)
+	n.norm()
+	n.SetBit(0, 1)
+	return searchPrime(n, n.bitLen(), false, done)
+}
+
+// searchPrime returns the first odd n above start, of bits bits, that is
+// prime, and for which 2n + 1 is also prime if safe is set. It returns nil
+// if there is none within maxSieveDelta of start, or once done is closed.
+func searchPrime(start *Int, bits int, safe bool, done <-chan struct{}) *Int {
+	residues := make([]uint32, len(smallPrimes))
+	for i, p := range smallPrimes {
+		residues[i] = start.modWord(p)
+	}
+	// candidates below 256 could be one of the small primes themselves
+	sieve := bits > 8
+	for delta := uint32(0); delta < maxSieveDelta; delta += 2 {
+		if sieve && sieved(residues, delta, safe) {
+			continue
+		}
+		select {
+		case <-done:
+			return nil
+		default:
+		}
+		n := new(Int)
+		n.Set(start)
+		n.Add(NewInt(int(delta)))
+		if n.bitLen() != bits {
+			return nil
+		}
+		if !isPrimeCandidate(n) {
+			continue
+		}
+		if !safe {
+			return n
+		}
+		p := new(Int)
+		p.Set(n)
+		p.Add(n)
+		p.Increment()
+		if isPrimeCandidate(p) {
+			return n
+		}
+	}
+	return nil
+}
+
+// sieved returns true if a small prime divides n = start + delta, or
+// 2n + 1 when safe is set, from the residues of start
+func sieved(residues []uint32, delta uint32, safe bool) bool {
+	for i, p := range smallPrimes {
+		r := (residues[i] + delta) % p
+		if r == 0 || safe && (2*r+1)%p == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// isPrimeCandidate runs Fermat's test to base 2, then Miller-Rabin
+func isPrimeCandidate(n *Int) bool {
+	if n.Compare(NewInt(3)) > 0 {
+		nm1 := new(Int)
+		nm1.Set(n)
+		nm1.Decrement()
+		if modExp(NewInt(2), nm1, n).Compare(OneValue) != 0 {
+			return false
+		}
+	}
+	return n.IsProbablyPrime(primeRounds)
+}
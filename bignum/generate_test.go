@@ -0,0 +1,107 @@
+package bignum
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestGeneratePrime(t *testing.T) {
+	t.Parallel()
+	for _, bits := range []int{2, 3, 8, 9, 16, 17, 64, 256} {
+		p, err := GeneratePrime(rand.Reader, bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.bitLen() != bits {
+			t.Fatalf("expected a prime of %d bits but got %d bits", bits, p.bitLen())
+		}
+		if !new(big.Int).SetBytes(p.Bytes()).ProbablyPrime(20) {
+			t.Fatalf("expected %s to be prime", p.hex())
+		}
+	}
+	if _, err := GeneratePrime(rand.Reader, 1); err == nil {
+		t.Fatal("expected an error for a 1 bit prime")
+	}
+	if _, err := GeneratePrime(bytes.NewReader([]byte{1, 2}), 64); err == nil {
+		t.Fatal("expected an error when the reader runs out")
+	}
+}
+
+func TestSearchPrime(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		start, expected int
+	}{
+		// an even start is searched from the next odd number
+		{1000, 1009},
+		{1009, 1009},
+		{1010, 1013},
+		// the next prime after 1021 has 11 bits
+		{1022, 0},
+	}
+	for i, tc := range testcases {
+		p := SearchPrime(NewInt(tc.start), nil)
+		switch {
+		case tc.expected == 0 && p != nil:
+			t.Fatalf("testcase %d expected no prime but got %s", i, p.hex())
+		case tc.expected != 0 && (p == nil || p.Compare(NewInt(tc.expected)) != 0):
+			t.Fatalf("testcase %d expected %d but got %v", i, tc.expected, p)
+		}
+	}
+	// a closed done channel stops the search before the first candidate
+	done := make(chan struct{})
+	close(done)
+	if p := SearchPrime(NewInt(1000), done); p != nil {
+		t.Fatalf("expected a cancelled search to return nil but got %s", p.hex())
+	}
+}
+
+func TestGenerateSafePrime(t *testing.T) {
+	t.Parallel()
+	for _, bits := range []int{3, 4, 9, 10, 32, 64} {
+		p, err := GenerateSafePrime(rand.Reader, bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.bitLen() != bits {
+			t.Fatalf("expected a safe prime of %d bits but got %d bits", bits, p.bitLen())
+		}
+		std := new(big.Int).SetBytes(p.Bytes())
+		q := new(big.Int).Rsh(std, 1)
+		if !std.ProbablyPrime(20) || !q.ProbablyPrime(20) {
+			t.Fatalf("expected %s to be a safe prime", p.hex())
+		}
+	}
+	if _, err := GenerateSafePrime(rand.Reader, 2); err == nil {
+		t.Fatal("expected an error for a 2 bits safe prime")
+	}
+}
+
+func TestSieved(t *testing.T) {
+	t.Parallel()
+	// 1001 = 7 * 11 * 13, 1003 = 17 * 59, 1009 is prime and 2*1009 + 1 =
+	// 2019 = 3 * 673, 1013 and 2*1013 + 1 = 2027 are both primes
+	start := NewInt(1001)
+	residues := make([]uint32, len(smallPrimes))
+	for i, p := range smallPrimes {
+		residues[i] = start.modWord(p)
+	}
+	var testcases = []struct {
+		delta    uint32
+		safe     bool
+		expected bool
+	}{
+		{0, false, true},
+		{2, false, true},
+		{8, false, false},
+		{8, true, true},
+		{12, true, false},
+	}
+	for i, tc := range testcases {
+		if s := sieved(residues, tc.delta, tc.safe); s != tc.expected {
+			t.Fatalf("testcase %d expected sieved=%t but got %t", i, tc.expected, s)
+		}
+	}
+}
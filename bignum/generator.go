@@ -0,0 +1,40 @@
+package bignum
+
+import (
+	"errors"
+	"io"
+)
+
+// FindGenerator returns a generator of the subgroup of order q of the
+// multiplicative group modulo the prime p, where the prime q divides
+// p - 1, as DSA, Schnorr signatures over Zp and Diffie-Hellman groups
+// use. Random values are read from rand.
+//
+// For any h in Zp*, g = h^((p-1)/q) satisfies g^q = h^(p-1) = 1, so the
+// order of g divides q and, q being prime, is q unless g is 1. Random h
+// are tried until g is not 1, which happens with probability 1/q.
+func FindGenerator(p, q *Int, rand io.Reader) (*Int, error) {
+	if p.Compare(NewInt(3)) < 0 || q.Compare(NewInt(2)) < 0 {
+		return nil, errors.New("bignum: p and q must be primes")
+	}
+	e := new(Int)
+	e.Set(p)
+	e.Decrement()
+	if e.Div(q).len() != 0 {
+		return nil, errors.New("bignum: q does not divide p - 1")
+	}
+	// h is picked in [2, p-1]
+	span := new(Int)
+	span.Set(p)
+	span.Sub(NewInt(2))
+	for {
+		h, err := randomBelow(rand, span)
+		if err != nil {
+			return nil, err
+		}
+		h.Add(NewInt(2))
+		if g := modExp(h, e, p); g.Compare(OneValue) != 0 {
+			return g, nil
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package bignum
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestFindGenerator(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		p, q int
+	}{
+		{3, 2},
+		{7, 3},
+		{2097779, 1048889},
+		{2097779, 2},
+	}
+	for i, tc := range testcases {
+		p, q := NewInt(tc.p), NewInt(tc.q)
+		g, err := FindGenerator(p, q, rand.Reader)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if o := MultiplicativeOrder(g, p); o.Compare(q) != 0 {
+			t.Fatalf("testcase %d expected a generator of order %d but %d has order %d", i, tc.q, g.ToInt(), o.ToInt())
+		}
+	}
+	if _, err := FindGenerator(NewInt(2097779), NewInt(5), rand.Reader); err == nil {
+		t.Fatal("expected q not dividing p - 1 to be refused")
+	}
+	if _, err := FindGenerator(NewInt(2097779), NewInt(1048889), bytes.NewReader(nil)); err == nil {
+		t.Fatal("expected a failing reader to be an error")
+	}
+}
+
+func TestBigIntFindGeneratorRandoms(t *testing.T) {
+	t.Parallel()
+	for i := 0; i < 3; i++ {
+		// DSA style parameters, a 64 bits q dividing a 160 bits p
+		stdq, err := rand.Prime(rand.Reader, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stdp *big.Int
+		for {
+			k, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 96))
+			if err != nil {
+				t.Fatal(err)
+			}
+			stdp = new(big.Int).Mul(k, stdq)
+			stdp.Add(stdp, big.NewInt(1))
+			if stdp.ProbablyPrime(20) {
+				break
+			}
+		}
+		p := new(Int)
+		p.SetBytes(stdp.Bytes())
+		q := new(Int)
+		q.SetBytes(stdq.Bytes())
+		g, err := FindGenerator(p, q, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdg := new(big.Int).SetBytes(g.Bytes())
+		if stdg.Cmp(big.NewInt(1)) == 0 || new(big.Int).Exp(stdg, stdq, stdp).Cmp(big.NewInt(1)) != 0 {
+			t.Fatalf("testcase %d expected %x to have order %x modulo %x", i, g.Bytes(), stdq.Bytes(), stdp.Bytes())
+		}
+	}
+}
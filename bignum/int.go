@@ -1,22 +1,47 @@
 package bignum
 
+import "math/bits"
+
 // Int is a positive big integer of arbitrary size.
 //
-// Internally, an Int is stored as an array of uint16
-// where the first index contains the lower 16 bits, the
-// second index the next 16 bits, and so on...
+// Internally, an Int is stored as an array of machine words, its limbs,
+// where the first index contains the lower _W bits, the second index the
+// next _W bits, and so on. _W is 64 on 64 bits platforms and 32 on the
+// others.
 //
-// For example, the 64 bits integer 4611686018427387901 is
-// stored as follows: [65533, 65535, 65535, 16383]
+// For example, with 32 bits limbs, the 64 bits integer
+// 4611686018427387901 is stored as follows: [4294967293, 1073741823]
 //
 // The original integer can be retrieved by shifting each
-// limb to the left by 16 bits * index.
+// limb to the left by _W bits * index.
 //
-// 65533 + (65535<<16) + (65535<<32) + (16383<<48)
+// 4294967293 + (1073741823<<32)
 // which is equivalent to
-// 65533 + (65535 * 2^16) + (65535 * 2^32) + (16383 * 2^48)
+// 4294967293 + (1073741823 * 2^32)
+//
+// An Int is not safe for concurrent use when one goroutine modifies
+// it. Methods never modify their arguments though, nor keep or share
+// their limbs: the same Int can be read, or passed as argument, by any
+// number of goroutines at once as long as none of them changes it.
 type Int struct {
-	nat []uint16 // natural number stored as 16 bits words
+	nat      []word // natural number stored as machine words
+	constant bool   // modifying methods panic, see ZeroValue
+}
+
+// ZeroValue and OneValue are shared constants, to compare with or pass
+// as arguments without allocating a new Int each time. They cannot be
+// modified, methods that would change them panic instead.
+var (
+	ZeroValue = &Int{nat: []word{}, constant: true}
+	OneValue  = &Int{nat: []word{1}, constant: true}
+)
+
+// mutate panics if bi is one of the shared constants. Every exported
+// method that changes bi calls it first.
+func (bi *Int) mutate() {
+	if bi.constant {
+		panic("bignum: cannot modify a constant Int")
+	}
 }
 
 // NewInt initializes a big integer using an integer value
@@ -26,17 +51,13 @@ func NewInt(v int) *Int {
 	return bi
 }
 
-func storeInt(v int) []uint16 {
-	nat := make([]uint16, 0)
-	if v == 0 {
-		nat = append(nat, uint16(0))
-		return nat
+// storeInt returns the limbs of v, a single one since an int fits in a
+// word. Negative values are stored as zero.
+func storeInt(v int) []word {
+	if v < 0 {
+		return []word{}
 	}
-	for i := v; i > 0; i = i >> 16 {
-		limb := uint16(i & 0xFFFF)
-		nat = append(nat, limb)
-	}
-	return nat
+	return []word{word(v)}
 }
 
 // ToInt returns the unsigned integer representation of a big integer.
@@ -46,14 +67,7 @@ func (bi *Int) ToInt() int {
 	if len(bi.nat) == 0 {
 		return 0
 	}
-	v := int(bi.nat[0])
-	if len(bi.nat) == 1 {
-		return v
-	}
-	for i := 1; i < 4 && i < len(bi.nat); i++ {
-		v = int(bi.nat[i])<<(16*i) | v
-	}
-	return v
+	return int(bi.nat[0])
 }
 
 // SetBytes sets the value of a big integer to the provided byte buffer.
@@ -63,49 +77,53 @@ func (bi *Int) ToInt() int {
 // would be []byte{0xD3, 0x4D, 0xB3, 0x3F}.
 //
 // When stored in the Int nat slice, the order of the bytes is reversed,
-// such that the lower 16 bits of the number, the last two bytes of the buf
-// slice, is stored in the first index of the Int nat slice. And the upper
-// 16 bits of the number are stored in the last index entry of the Int nat slice.
+// such that the lower _W bits of the number, the last _W/8 bytes of the
+// buf slice, are stored in the first index of the Int nat slice. And the
+// upper bits of the number are stored in the last index entry of the Int
+// nat slice.
 //
-// If the provided buf is of an odd length, then the last uint16 is only 8 bits
-// long. It is still stored as a uint16, with the upper 8 bits set to zero.
+// If the length of buf is not a multiple of _W/8, then the last limb is
+// shorter. It is still stored as a word, with its upper bits set to zero.
 func (bi *Int) SetBytes(buf []byte) {
-	bi.nat = bi.nat[:0] // clear the buffer
-	for i := len(buf) - 1; i >= 0; i -= 2 {
-		// bound check if we're at the last byte of an odd slice.
-		// if so, the upper 8 bits of the last limbs are set to zero
-		if i == 0 {
-			bi.nat = append(bi.nat, uint16(buf[i]))
-			break
+	bi.mutate()
+	nat := make([]word, 0, (len(buf)+_W/8-1)/(_W/8))
+	for end := len(buf); end > 0; end -= _W / 8 {
+		start := end - _W/8
+		if start < 0 {
+			start = 0
+		}
+		// convert up to _W/8 bytes, most significant first, into a limb
+		var limb word
+		for _, b := range buf[start:end] {
+			limb = limb<<8 | word(b)
 		}
-		// convert two bytes into a uint16 and append them
-		// to the nat slice
-		bi.nat = append(bi.nat, uint16(buf[i-1])<<8|uint16(buf[i]))
+		nat = append(nat, limb)
 	}
+	bi.nat = nat
 }
 
-// Set sets bi to the value of x
+// Set sets bi to a copy of the value of x
 func (bi *Int) Set(x *Int) {
-	bi.nat = make([]uint16, len(x.nat))
-	copy(bi.nat, x.nat)
+	bi.mutate()
+	// copy before assigning, x may be bi
+	nat := make([]word, len(x.nat))
+	copy(nat, x.nat)
+	bi.nat = nat
 }
 
 // Bytes returns the big endian unsigned byte slice representation
 // of the big integer
 func (bi *Int) Bytes() []byte {
-	i := 0
 	if len(bi.nat) == 0 {
 		return []byte{}
 	}
-	var buf []byte
-	for _, limb16 := range bi.nat {
-		buf = append([]byte{byte(limb16)}, buf...)
-		i += 8
-		limb16 >>= 8
-		buf = append([]byte{byte(limb16)}, buf...)
-		i += 8
-		if i >= len(bi.nat)*16 {
-			break
+	buf := make([]byte, len(bi.nat)*_W/8)
+	i := len(buf)
+	for _, limb := range bi.nat {
+		for j := 0; j < _W/8; j++ {
+			i--
+			buf[i] = byte(limb)
+			limb >>= 8
 		}
 	}
 	// strip leading zeroes
@@ -118,46 +136,47 @@ func (bi *Int) Bytes() []byte {
 // as argument and adds its value to the bi.
 //
 // This algorithm isn't particularly smart. It simply adds
-// each uint16 from bi and x at the same index to each other
-// into an uint32, then keep the upper 16 bits as a carry for
-// the next index, and store the lower 16 bits at the index.
+// the limbs from bi and x at the same index to each other,
+// with addVV, carrying into the next index.
 //
 // If the carry is not zero after the last addition, it is
 // appended to the nat slice of bi.
 func (bi *Int) Add(x *Int) {
+	bi.mutate()
 	switch {
 	case len(bi.nat) < len(x.nat):
-		x.Add(bi)
-		*bi = *x
+		// add bi to a copy of x, to leave x untouched
+		y := new(Int)
+		y.Set(x)
+		y.Add(bi)
+		bi.nat = y.nat
 		return
 	case len(x.nat) == 0:
 		return
 	case len(bi.nat) == 0:
-		*bi = *x
+		bi.Set(x)
 		return
 	}
-	carry := uint32(0)
 	// add all limbs from x, the smallest number, to bi
-	for i := 0; i < len(x.nat); i++ {
-		limbsum32 := uint32(bi.nat[i]) + uint32(x.nat[i]) + carry
-		//fmt.Printf("%x + %x + %x = %x\n", bi.nat[i], x.nat[i], carry, limbsum32)
-		carry = uint32(limbsum32 >> 16)
-		//fmt.Printf("carry=%x;\n", carry)
-		bi.nat[i] = uint16(limbsum32 & 0xFFFF)
-	}
-	// if there's a remaining carry, either add it to an upper limb of bi
-	// or allocate a new limb if needed
-	if carry == 1 {
-		if len(bi.nat) == len(x.nat) {
-			bi.nat = append(bi.nat, uint16(1))
-		} else {
-			bi.nat[len(x.nat)] = uint16(bi.nat[len(x.nat)] + 1)
+	n := len(x.nat)
+	carry := addVV(bi.nat[:n], bi.nat[:n], x.nat)
+	// if there's a remaining carry, propagate it to the upper limbs of bi
+	// and allocate a new limb if needed
+	for i := len(x.nat); carry == 1; i++ {
+		if i == len(bi.nat) {
+			bi.nat = append(bi.nat, 1)
+			break
+		}
+		bi.nat[i]++
+		if bi.nat[i] != 0 {
+			carry = 0
 		}
 	}
 }
 
 // Sub substracts x from bi. If x is greater than bi, it panics.
 func (bi *Int) Sub(x *Int) {
+	bi.mutate()
 	switch bi.Compare(x) {
 	case -1:
 		panic("x is larger than bi, which would result in a negative number, that are not yet supported")
@@ -165,102 +184,113 @@ func (bi *Int) Sub(x *Int) {
 		bi.Zero()
 		return
 	}
-	carry := int(0)
-	var i int
-	for i = 0; i < len(x.nat); i++ {
-		limbdiff32 := int(bi.nat[i]) - (int(x.nat[i]) + carry)
-		//fmt.Printf("%x - (%x + %x) = %x;\n", bi.nat[i], x.nat[i], carry, limbdiff32)
-		if limbdiff32 < 0 {
-			// x.nat[i] was greater than bi.nat[i] so the diff is a negative
-			// number. we store a carry of one and set the value of bi.nat[i]
-			// to the inverse of the difference
-			carry = 1
-			bi.nat[i] = 0xFFFF - uint16(-limbdiff32) + 1 // surely there's a better way...
-			//fmt.Printf("storing bi.nat[%x]=%x\n", i, 0xFFFF-uint16(-limbdiff32))
-		} else {
-			carry = 0
-			bi.nat[i] = uint16(limbdiff32)
-		}
-	}
-	if carry == 1 {
-		if len(bi.nat) == len(x.nat) {
+	// subtract the limbs of x, borrowing from the next limb of bi
+	// whenever a limb of x is larger
+	i := x.len()
+	carry := subVV(bi.nat[:i], bi.nat[:i], x.nat[:i])
+	// propagate the remaining carry to the upper limbs, bi is larger
+	// than x so it stops before running out of limbs
+	for ; carry == 1; i++ {
+		if i == len(bi.nat) {
 			panic("remaining carry implies x is larger than bi and negative numbers are not supported")
 		}
-		//fmt.Printf("i=%d; len(bi.nat)=%d; len(x.nat)=%d\n", i, len(bi.nat), len(x.nat))
+		if bi.nat[i] != 0 {
+			carry = 0
+		}
 		bi.nat[i]--
 	}
+	bi.norm()
 }
 
 // Mul implements multiplication of the provided Int x with bi
 //
-// It uses a naive linear convolution algorithm that multiplies
-// uint16 words one by one, starting with the lower ones at the
-// beginning of the nat slices.
+// It uses the naive schoolbook algorithm: bi is multiplied by
+// each limb of x, starting with the lower one, and added with
+// addMulVVW to the product shifted by the position of that
+// limb. When both factors have at least Thresholds.Karatsuba
+// limbs, it uses Karatsuba instead, and from Thresholds.Toom
+// limbs Toom-Cook 3-way.
 func (bi *Int) Mul(x *Int) {
+	bi.mutate()
 	switch {
 	case len(bi.nat) < len(x.nat):
 		y := new(Int)
 		y.Set(x)
 		y.Mul(bi)
-		*bi = *y
+		bi.nat = y.nat
 		return
 	case len(x.nat) == 0, len(bi.nat) == 0:
 		// multiplication by zero just sets bi to zero
-		bi.nat[0] = 0
+		bi.Zero()
+		return
+	}
+	switch {
+	case x.len() >= thresholds.Toom:
+		bi.toom3(x)
+		return
+	case x.len() >= thresholds.Karatsuba:
+		bi.karatsuba(x)
 		return
 	}
 
-	var product = new(Int)
-	for i := 0; i < len(bi.nat); i++ {
-		var inter = new(Int)
-		for j := 0; j < len(x.nat); j++ {
-			p := NewInt(int(bi.nat[i]) * int(x.nat[j]))
-			// raise p by 2^16 for each word already processed
-			p.shift16(j)
-			//fmt.Printf("p=%d*%d=%+v\n", bi.nat[i], x.nat[j], p)
-			inter.Add(p)
-			//fmt.Printf("inter=%+v\n", inter)
-		}
-		// raise inter by 2^16 for each word already processed
-		inter.shift16(i)
-		//fmt.Printf("inter=%+v\n", inter)
-		product.Add(inter)
+	m := len(bi.nat)
+	product := make([]word, m+len(x.nat))
+	for j, limb := range x.nat {
+		// the limbs above j+m are still zero, the carry goes there
+		product[j+m] = addMulVVW(product[j:j+m], bi.nat, limb)
 	}
-	*bi = *product
-	//fmt.Printf("bi=%+v\n", bi)
+	bi.nat = product
+	bi.norm()
 }
 
 // Div implements integer division of bi by x and returns
 // the remainder n.
+//
+// It uses binary long division, the way it's done by hand but
+// in base 2: the bits of bi are shifted one at a time into the
+// remainder, starting with the most significant one, and x is
+// subtracted from the remainder every time it is large enough,
+// which sets the matching bit of the quotient. KnuthDiv brings down
+// a whole limb at a time instead.
 func (bi *Int) Div(x *Int) (n *Int) {
+	bi.mutate()
+	return bi.div(x, tracing())
+}
+
+// div is Div, writing its steps only if trace is set
+func (bi *Int) div(x *Int, trace bool) (n *Int) {
 	n = new(Int)
-	if x.Compare(NewInt(0)) == 0 {
+	if x.len() == 0 {
 		panic("division by zero")
 	}
-	switch bi.Compare(x) {
-	case 0:
-		// if bi and x are equal, quotient is one, remainder is zero
-		n.Zero()
-		bi.Set(NewInt(1))
-		return
-	case -1:
-		// if x is greater than bi,
-		// then quotient is zero and remainder is bi
-		n.Set(bi)
-		bi.Zero()
-		return
+	if trace {
+		tracef("div", "%s / %s, bringing down one bit of the dividend at a time", bi.hex(), x.hex())
 	}
-
-	// substract x from bi until bi is lower than x,
-	// then the value of bi is the remainder stored in n,
-	// and the number of iteration is the quotient stored in bi
-	q := NewInt(0)
-	for q.Zero(); bi.Compare(x) > 0; q.Increment() {
-		//fmt.Printf("%x-%x\n", bi.Bytes(), x.Bytes())
-		bi.Sub(x)
+	q := new(Int)
+	q.nat = make([]word, bi.len())
+	for i := bi.bitLen() - 1; i >= 0; i-- {
+		n.lsh1()
+		if bi.bit(i) == 1 {
+			if len(n.nat) == 0 {
+				n.nat = append(n.nat, 0)
+			}
+			n.nat[0] |= 1
+		}
+		if n.Compare(x) >= 0 {
+			if trace {
+				tracef("div", "  bit %d is %d: remainder %s >= %s, subtract, quotient bit %d is 1", i, bi.bit(i), n.hex(), x.hex(), i)
+			}
+			n.Sub(x)
+			q.nat[i/_W] |= 1 << uint(i%_W)
+		} else if trace {
+			tracef("div", "  bit %d is %d: remainder %s < %s, quotient bit %d is 0", i, bi.bit(i), n.hex(), x.hex(), i)
+		}
+	}
+	q.norm()
+	bi.nat = q.nat
+	if trace {
+		tracef("div", "quotient %s, remainder %s", bi.hex(), n.hex())
 	}
-	n.Set(bi)
-	bi.Set(q)
 	return
 }
 
@@ -270,15 +300,16 @@ func (bi *Int) Div(x *Int) (n *Int) {
 // It does so by iteratively substracting x from bi, which is
 // pretty much how a child would do it.
 func (bi *Int) ChildishDiv(x *Int) (n *Int) {
+	bi.mutate()
 	n = new(Int)
-	if x.Compare(NewInt(0)) == 0 {
+	if x.Compare(ZeroValue) == 0 {
 		panic("division by zero")
 	}
 	switch bi.Compare(x) {
 	case 0:
 		// if bi and x are equal, quotient is one, remainder is zero
 		n.Zero()
-		bi.Set(NewInt(1))
+		bi.Set(OneValue)
 		return
 	case -1:
 		// if x is greater than bi,
@@ -292,8 +323,6 @@ func (bi *Int) ChildishDiv(x *Int) (n *Int) {
 	// and the number of iteration is the quotient stored in bi
 	q := NewInt(0)
 	for q.Zero(); bi.Compare(x) > 0; q.Increment() {
-		//fmt.Printf("q=%x\n", q.Bytes())
-		//fmt.Printf("%x-%x\n", bi.Bytes(), x.Bytes())
 		bi.Sub(x)
 	}
 	n.Set(bi)
@@ -301,34 +330,81 @@ func (bi *Int) ChildishDiv(x *Int) (n *Int) {
 	return
 }
 
-// shift bi by x count of 16 bits words
-func (bi *Int) shift16(count int) {
-	for shift := 0; shift < count; shift++ {
-		bi.nat = append([]uint16{0}, bi.nat...)
+// reduce sets bi to bi mod m, without tracing the division
+func (bi *Int) reduce(m *Int) {
+	bi.Set(bi.div(m, false))
+}
+
+// shiftLimbs shifts bi to the left by count limbs
+func (bi *Int) shiftLimbs(count int) {
+	bi.nat = append(make([]word, count, count+len(bi.nat)), bi.nat...)
+}
+
+// lsh1 shifts bi to the left by one bit
+func (bi *Int) lsh1() {
+	carry := word(0)
+	for i, limb := range bi.nat {
+		bi.nat[i] = limb<<1 | carry
+		carry = limb >> (_W - 1)
+	}
+	if carry == 1 {
+		bi.nat = append(bi.nat, 1)
+	}
+}
+
+// bit returns the value of the i-th bit of bi
+func (bi *Int) bit(i int) uint {
+	if i/_W >= len(bi.nat) {
+		return 0
+	}
+	return uint(bi.nat[i/_W]>>uint(i%_W)) & 1
+}
+
+// bitLen returns the number of bits of bi, without leading zeroes
+func (bi *Int) bitLen() int {
+	n := bi.len()
+	if n == 0 {
+		return 0
+	}
+	return _W*(n-1) + bits.Len(bi.nat[n-1])
+}
+
+// len returns the number of limbs of bi, without leading zero limbs
+func (bi *Int) len() int {
+	n := len(bi.nat)
+	for n > 0 && bi.nat[n-1] == 0 {
+		n--
 	}
+	return n
+}
+
+// norm removes the leading zero limbs of bi
+func (bi *Int) norm() {
+	bi.nat = bi.nat[:bi.len()]
 }
 
 // Zero resets a big integer to zero
 func (bi *Int) Zero() {
-	bi.nat = make([]uint16, 0)
+	bi.mutate()
+	bi.nat = make([]word, 0)
 }
 
 // Increment adds one to big integer
 func (bi *Int) Increment() {
-	bi.Add(NewInt(1))
+	bi.Add(OneValue)
 }
 
 // Decrement substracts one from big integer
 func (bi *Int) Decrement() {
-	bi.Sub(NewInt(1))
+	bi.Sub(OneValue)
 }
 
 // Compare returns 1 if bi is greater than x, 0 if they
 // are equal, and -1 if bi is smaller than x.
 func (bi *Int) Compare(x *Int) (r int) {
-	m := len(bi.nat)
-	n := len(x.nat)
-	if m != n || m == 0 {
+	m := bi.len()
+	n := x.len()
+	if m != n {
 		// compare the the length of the nat slices
 		// to get a quick answer on which number is larger
 		if m < n {
@@ -337,6 +413,10 @@ func (bi *Int) Compare(x *Int) (r int) {
 			return 1
 		}
 	}
+	if m == 0 {
+		// both are zero
+		return 0
+	}
 
 	// if the nat len are equal, iterate over the nat limb
 	// on bi until we find one that isn't identical to the
@@ -356,32 +436,55 @@ func (bi *Int) Compare(x *Int) (r int) {
 
 // ModularExponentiation raises a big integer bi to the exponent x
 // and reduces it modulo n, such as bi = bi^x mod n
+//
+// It uses square and multiply: the bits of the exponent are read from
+// the most significant one, the result is squared for each bit and
+// multiplied by bi for those that are set, and reduced modulo n after
+// every product. That is at most two multiplications per bit of the
+// exponent, where multiplying by bi once per unit of the exponent never
+// finishes for exponents of cryptographic size.
 func (bi *Int) ModularExponentiation(x *Int, modulus *Int) {
-	/* from https://en.wikipedia.org/wiki/Modular_exponentiation#Memory-efficient_method
-		if modulus = 1 then
-	        return 0
-	    c := 1
-	    for e_prime = 0 to exponent-1 do
-	        c := (c * base) mod modulus
-	    return c
-	*/
-	if modulus.Compare(NewInt(1)) == 0 {
+	bi.mutate()
+	if modulus.Compare(OneValue) == 0 {
 		bi.Zero()
 		return
 	}
 
+	if tracing() {
+		tracef("modexp", "%s ^ %s mod %s, square and multiply over the %d bits of the exponent", bi.hex(), x.hex(), modulus.hex(), x.bitLen())
+	}
+	b := new(Int)
+	b.Set(bi)
+	b.reduce(modulus)
 	c := NewInt(1)
-	//fmt.Printf("computing %x ^ %x mod %x\n", bi.Bytes(), x.Bytes(), modulus.Bytes())
-	for e := NewInt(0); e.Compare(x) < 0; e.Increment() {
-		//fmt.Printf("%x: %x * %x mod %x\n", e.Bytes(), c.Bytes(), base.Bytes(), modulus.Bytes())
-		c.Mul(bi)
-		c.Set(c.ChildishDiv(modulus))
+	for i := x.bitLen() - 1; i >= 0; i-- {
+		var before string
+		if tracing() {
+			before = c.hex()
+		}
+		sq := new(Int)
+		sq.Set(c)
+		c.Mul(sq)
+		c.reduce(modulus)
+		if x.bit(i) == 1 {
+			c.Mul(b)
+			c.reduce(modulus)
+			if tracing() {
+				tracef("modexp", "  bit %d is 1: c = %s^2 * %s mod %s = %s", i, before, b.hex(), modulus.hex(), c.hex())
+			}
+		} else if tracing() {
+			tracef("modexp", "  bit %d is 0: c = %s^2 mod %s = %s", i, before, modulus.hex(), c.hex())
+		}
 	}
 	bi.Set(c)
+	if tracing() {
+		tracef("modexp", "result %s", bi.hex())
+	}
 }
 
 // IsFermatPrime returns true if a given big integer is considered
-// prime using Fermat's primality test
+// prime using Fermat's primality test. Carmichael numbers such as 561
+// pass it for every base coprime with them, see IsCarmichaelNumber.
 func (bi *Int) IsFermatPrime() bool {
 	p := new(Int)
 	p.Set(bi)
@@ -391,7 +494,7 @@ func (bi *Int) IsFermatPrime() bool {
 	for _, step := range []int{2, 3, 5, 7} {
 		a := NewInt(step)
 		a.ModularExponentiation(pmin, p)
-		if a.Compare(NewInt(1)) != 0 {
+		if a.Compare(OneValue) != 0 {
 			return false
 		}
 	}
@@ -399,6 +502,7 @@ func (bi *Int) IsFermatPrime() bool {
 }
 
 // IsRabinMillerPrime implements primality test of bi using the
-// Rabin-Miller algorithm
-//func (bi *Int) IsRabinMillerPrime() bool {
-//}
+// Rabin-Miller algorithm, with 20 random bases, see IsProbablyPrime
+func (bi *Int) IsRabinMillerPrime() bool {
+	return bi.IsProbablyPrime(20)
+}
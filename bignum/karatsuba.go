@@ -0,0 +1,108 @@
+package bignum
+
+import "fmt"
+
+// Thresholds are the operand sizes, in limbs of _W bits, from which the
+// arithmetic switches to an asymptotically faster algorithm. The best
+// values depend on the machine, the bench package measures them.
+type Thresholds struct {
+	// Karatsuba is the size of the smaller factor of a multiplication
+	// from which Mul uses Karatsuba rather than the naive convolution
+	Karatsuba int `json:"karatsuba"`
+	// Toom is the size of the smaller factor of a multiplication from
+	// which Mul uses Toom-Cook 3-way rather than Karatsuba
+	Toom int `json:"toom"`
+	// Montgomery is the size of the modulus from which Montgomery
+	// multiplication reduces the product with two more multiplications,
+	// through Mul, rather than one limb at a time
+	Montgomery int `json:"montgomery"`
+}
+
+// DefaultThresholds are the thresholds used until SetThresholds is called,
+// as calibrated on amd64 with the limb vector kernels of arith.go
+var DefaultThresholds = Thresholds{Karatsuba: 64, Toom: 256, Montgomery: 320}
+
+// ThresholdsEnv is the environment variable naming a JSON file of
+// Thresholds, as written by bench.Save, that is loaded at init. TinyGo
+// builds, made for the browser, ignore it.
+const ThresholdsEnv = "BADCRYPTO_BIGNUM_THRESHOLDS"
+
+var thresholds = DefaultThresholds
+
+// GetThresholds returns the thresholds in use
+func GetThresholds() Thresholds {
+	return thresholds
+}
+
+// SetThresholds replaces the thresholds in use. It must not be called
+// while other goroutines do arithmetic.
+func SetThresholds(t Thresholds) error {
+	// Karatsuba splits both factors in two, below 2 limbs it cannot
+	if t.Karatsuba < 2 {
+		return fmt.Errorf("bignum: karatsuba threshold %d is below 2 limbs", t.Karatsuba)
+	}
+	// Toom-Cook 3-way splits them in three
+	if t.Toom < 3 {
+		return fmt.Errorf("bignum: toom threshold %d is below 3 limbs", t.Toom)
+	}
+	if t.Montgomery < 1 {
+		return fmt.Errorf("bignum: montgomery threshold %d is below 1 limb", t.Montgomery)
+	}
+	thresholds = t
+	return nil
+}
+
+// karatsuba sets bi to bi * x where bi has at least as many limbs as x.
+//
+// Both factors are split at m limbs, bi = b1*B^m + b0 and x = x1*B^m + x0
+// with B = 2^_W, and the product
+//
+//	b1*x1*B^2m + ((b0+b1)(x0+x1) - b0*x0 - b1*x1)*B^m + b0*x0
+//
+// needs three half size multiplications instead of four. The halves go
+// back through Mul, which recurses until they fall below the threshold.
+func (bi *Int) karatsuba(x *Int) {
+	m := bi.len() / 2
+	b0, b1 := bi.limbs(0, m), bi.limbs(m, bi.len())
+	if x.len() <= m {
+		// x is too short to split, multiply each half of bi by it
+		b1.Mul(x)
+		b1.shiftLimbs(m)
+		b0.Mul(x)
+		b1.Add(b0)
+		b1.norm()
+		bi.nat = b1.nat
+		return
+	}
+	x0, x1 := x.limbs(0, m), x.limbs(m, x.len())
+
+	z0 := new(Int)
+	z0.Set(b0)
+	z0.Mul(x0)
+	z2 := new(Int)
+	z2.Set(b1)
+	z2.Mul(x1)
+	// z1 = (b0+b1)(x0+x1) - z0 - z2, which cannot be negative
+	z1 := b0
+	z1.Add(b1)
+	x0.Add(x1)
+	z1.Mul(x0)
+	z1.Sub(z0)
+	z1.Sub(z2)
+
+	z2.shiftLimbs(2 * m)
+	z1.shiftLimbs(m)
+	z2.Add(z1)
+	z2.Add(z0)
+	z2.norm()
+	bi.nat = z2.nat
+}
+
+// limbs returns a copy of the limbs of bi from lo up to hi
+func (bi *Int) limbs(lo, hi int) *Int {
+	r := new(Int)
+	r.nat = make([]word, hi-lo)
+	copy(r.nat, bi.nat[lo:hi])
+	r.norm()
+	return r
+}
@@ -0,0 +1,91 @@
+package bignum
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestKaratsuba(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		aBits, bBits int
+	}{
+		{64, 32},
+		{512, 512},
+		{1024, 1000},
+		{2048, 2048},
+		// x shorter than half of bi
+		{4096, 700},
+		{3000, 64},
+	}
+	for i, tc := range testcases {
+		stda, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), uint(tc.aBits)))
+		stdb, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), uint(tc.bBits)))
+		ref := new(big.Int).Mul(stda, stdb)
+		a := new(Int)
+		a.SetBytes(stda.Bytes())
+		b := new(Int)
+		b.SetBytes(stdb.Bytes())
+		a.karatsuba(b)
+		if !bytes.Equal(a.Bytes(), ref.Bytes()) {
+			t.Fatalf("testcase %d expected product\n%x\nbut got\n%x", i, ref.Bytes(), a.Bytes())
+		}
+		if !bytes.Equal(b.Bytes(), stdb.Bytes()) {
+			t.Fatalf("testcase %d modified the second factor", i)
+		}
+	}
+}
+
+func TestThresholds(t *testing.T) {
+	// not parallel, the thresholds are global
+	current := GetThresholds()
+	var testcases = []Thresholds{
+		{Karatsuba: 1, Toom: 256, Montgomery: 320},
+		{Karatsuba: 64, Toom: 2, Montgomery: 320},
+		{Karatsuba: 64, Toom: 256, Montgomery: 0},
+	}
+	for i, tc := range testcases {
+		if err := SetThresholds(tc); err == nil {
+			t.Fatalf("testcase %d expected thresholds %+v to be rejected", i, tc)
+		}
+		if GetThresholds() != current {
+			t.Fatalf("testcase %d expected a rejected threshold to leave the current ones", i)
+		}
+	}
+}
+
+// BenchmarkMulCrossover times Mul on operands around the default
+// threshold, with the naive convolution and with one level of Karatsuba
+// over it, to show where one overtakes the other. The bench package
+// calibrates the threshold from the same measurement.
+func BenchmarkMulCrossover(b *testing.B) {
+	saved := GetThresholds()
+	defer SetThresholds(saved)
+	for _, limbs := range []int{32, 64, 96, 128, 192, 256} {
+		stda, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), uint(_W*limbs)))
+		stdb, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), uint(_W*limbs)))
+		x, y := new(Int), new(Int)
+		x.SetBytes(stda.Bytes())
+		y.SetBytes(stdb.Bytes())
+		for _, tc := range []struct {
+			name      string
+			threshold int
+		}{
+			{"schoolbook", math.MaxInt32},
+			{"karatsuba", limbs},
+		} {
+			b.Run(fmt.Sprintf("%s/%d", tc.name, limbs), func(b *testing.B) {
+				SetThresholds(Thresholds{Karatsuba: tc.threshold, Toom: math.MaxInt32, Montgomery: saved.Montgomery})
+				z := new(Int)
+				for i := 0; i < b.N; i++ {
+					z.Set(x)
+					z.Mul(y)
+				}
+			})
+		}
+	}
+}
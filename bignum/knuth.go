@@ -0,0 +1,131 @@
+package bignum
+
+import "math/bits"
+
+// KnuthDiv divides bi by x like Div, and returns the remainder n, but
+// with Knuth's algorithm D (The Art of Computer Programming, volume 2,
+// section 4.3.1), which brings down a whole limb at a time where Div
+// brings down a bit.
+//
+// Each quotient limb is guessed from the two top limbs of the remainder
+// and the top limb of x, with a double word division. Once x is shifted
+// so that its top bit is set, the guess is never below the true limb and
+// at most two above, and checking it against the next limb of x leaves
+// it off by one in rare cases, caught when subtracting its product with
+// x makes the remainder negative, and fixed by adding x back.
+func (bi *Int) KnuthDiv(x *Int) (n *Int) {
+	bi.mutate()
+	v := x.nat[:x.len()]
+	if len(v) == 0 {
+		panic("division by zero")
+	}
+	if bi.Compare(x) < 0 {
+		n = new(Int)
+		n.Set(bi)
+		bi.Zero()
+		return n
+	}
+	if len(v) == 1 {
+		q, r := divWord(bi.nat[:bi.len()], v[0])
+		bi.nat = q
+		return &Int{nat: storeWord(r)}
+	}
+	q, r := divLimbs(bi.nat[:bi.len()], v)
+	bi.nat = q
+	bi.norm()
+	n = &Int{nat: r}
+	n.norm()
+	return n
+}
+
+// storeWord returns the limbs of w, none for zero
+func storeWord(w word) []word {
+	if w == 0 {
+		return []word{}
+	}
+	return []word{w}
+}
+
+// divLimbs returns the quotient and the remainder of u by v, where v has
+// at least two limbs, the top one not zero, and u at least as many
+func divLimbs(u, v []word) (q, r []word) {
+	n, m := len(v), len(u)-len(v)
+	// normalize: shift both so that the top bit of v is set, which
+	// changes the remainder but not the quotient
+	s := uint(bits.LeadingZeros(v[n-1]))
+	vn := make([]word, n)
+	shlLimbs(vn, v, s)
+	un := make([]word, len(u)+1)
+	un[len(u)] = shlLimbs(un[:len(u)], u, s)
+
+	q = make([]word, m+1)
+	qv := make([]word, n+1)
+	vn1, vn2 := vn[n-1], vn[n-2]
+	for j := m; j >= 0; j-- {
+		// guess the quotient limb from the top two limbs of the
+		// remainder, the guess is the largest limb when they would
+		// overflow a limb
+		qhat := ^word(0)
+		if ujn := un[j+n]; ujn != vn1 {
+			var rhat word
+			qhat, rhat = bits.Div(ujn, un[j+n-1], vn1)
+			// lower the guess while qhat*vn2 > rhat*B + u[j+n-2]
+			for {
+				hi, lo := bits.Mul(qhat, vn2)
+				if hi < rhat || hi == rhat && lo <= un[j+n-2] {
+					break
+				}
+				qhat--
+				prev := rhat
+				if rhat += vn1; rhat < prev {
+					// rhat no longer fits a limb, the test passes
+					break
+				}
+			}
+		}
+		// subtract qhat*v from the top n+1 limbs of the remainder
+		for i := range qv {
+			qv[i] = 0
+		}
+		qv[n] = addMulVVW(qv[:n], vn, qhat)
+		if subVV(un[j:j+n+1], un[j:j+n+1], qv) != 0 {
+			// the guess was one too many, add v back
+			c := addVV(un[j:j+n], un[j:j+n], vn)
+			un[j+n] += c
+			qhat--
+		}
+		q[j] = qhat
+	}
+	r = make([]word, n)
+	shrLimbs(r, un[:n], s)
+	return q, r
+}
+
+// shlLimbs sets z to x shifted left by s < _W bits and returns the bits
+// shifted out of the top limb
+func shlLimbs(z, x []word, s uint) word {
+	if s == 0 {
+		copy(z, x)
+		return 0
+	}
+	var carry word
+	for i, limb := range x {
+		z[i] = limb<<s | carry
+		carry = limb >> (_W - s)
+	}
+	return carry
+}
+
+// shrLimbs sets z to x shifted right by s < _W bits
+func shrLimbs(z, x []word, s uint) {
+	if s == 0 {
+		copy(z, x)
+		return
+	}
+	for i := range x {
+		z[i] = x[i] >> s
+		if i+1 < len(x) {
+			z[i] |= x[i+1] << (_W - s)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package bignum
+
+import (
+	"crypto/rand"
+	"math/big"
+	mrand "math/rand"
+	"testing"
+)
+
+func TestKnuthDiv(t *testing.T) {
+	t.Parallel()
+	ops := diffOperands(t)
+	for _, stda := range ops {
+		for _, stdb := range ops {
+			if stdb.Sign() == 0 {
+				continue
+			}
+			a, b := fromStd(stda), fromStd(stdb)
+			n := a.KnuthDiv(b)
+			refq, refn := new(big.Int).QuoRem(stda, stdb, new(big.Int))
+			if a.Compare(fromStd(refq)) != 0 || n.Compare(fromStd(refn)) != 0 {
+				t.Fatalf("expected %x / %x = %x remainder %x but got %x remainder %x",
+					stda, stdb, refq, refn, a.Bytes(), n.Bytes())
+			}
+		}
+	}
+}
+
+// specialLimbs returns n limbs picked among the values where the quotient
+// guesses of algorithm D go wrong most often, and random ones
+func specialLimbs(r *mrand.Rand, n int) []byte {
+	buf := make([]byte, n*_W/8)
+	for i := 0; i < len(buf); i += _W / 8 {
+		var limb word
+		switch r.Intn(6) {
+		case 0:
+		case 1:
+			limb = 1
+		case 2:
+			limb = ^word(0)
+		case 3:
+			limb = 1 << (_W - 1)
+		case 4:
+			limb = 1<<(_W-1) - 1
+		default:
+			limb = word(r.Uint64())
+		}
+		for j := _W/8 - 1; j >= 0; j-- {
+			buf[i+j] = byte(limb)
+			limb >>= 8
+		}
+	}
+	return buf
+}
+
+// TestKnuthDivCorrections compares KnuthDiv with math/big on operands
+// made of extreme limbs, which need the guessed quotient limbs to be
+// corrected, including by adding the divisor back
+func TestKnuthDivCorrections(t *testing.T) {
+	t.Parallel()
+	var seed [8]byte
+	if _, err := rand.Read(seed[:]); err != nil {
+		t.Fatal(err)
+	}
+	r := mrand.New(mrand.NewSource(int64(new(big.Int).SetBytes(seed[:]).Uint64())))
+	for i := 0; i < 20000; i++ {
+		stda := new(big.Int).SetBytes(specialLimbs(r, 2+r.Intn(6)))
+		stdb := new(big.Int).SetBytes(specialLimbs(r, 1+r.Intn(4)))
+		if stdb.Sign() == 0 {
+			continue
+		}
+		a, b := fromStd(stda), fromStd(stdb)
+		n := a.KnuthDiv(b)
+		refq, refn := new(big.Int).QuoRem(stda, stdb, new(big.Int))
+		if a.Compare(fromStd(refq)) != 0 || n.Compare(fromStd(refn)) != 0 {
+			t.Fatalf("iteration %d expected %x / %x = %x remainder %x but got %x remainder %x",
+				i, stda, stdb, refq, refn, a.Bytes(), n.Bytes())
+		}
+	}
+}
+
+func BenchmarkKnuthDiv(b *testing.B) {
+	x, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 4096))
+	m, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 2048))
+	bx, bm := fromStd(x), fromStd(m)
+	for _, div := range []struct {
+		name string
+		f    func(a, m *Int) *Int
+	}{
+		{"Div", (*Int).Div},
+		{"KnuthDiv", (*Int).KnuthDiv},
+	} {
+		b.Run(div.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				a := new(Int)
+				a.Set(bx)
+				div.f(a, bm)
+			}
+		})
+	}
+}
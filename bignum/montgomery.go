@@ -0,0 +1,211 @@
+package bignum
+
+import "errors"
+
+// MontgomeryContext holds what multiplication modulo an odd n needs in
+// the Montgomery representation, where x stands for x*R mod n with
+// R = 2^(_W*k) and k the number of limbs of n.
+//
+// The product of two numbers in that representation is x*y*R^2, which
+// MontgomeryMul brings back to x*y*R mod n by dividing by R rather than
+// by n. Dividing by R is a shift, once the right multiple of n has been
+// added to clear the low limbs: for each limb from the bottom, adding
+// u*n with u = -t[i] * n^-1 mod 2^_W zeroes limb i. That multiple costs
+// one limb multiplication per limb instead of the long division Div
+// does bit by bit, and one final subtraction at most brings the result
+// below n.
+//
+// Interleaved with the product, that is two naive convolutions. From
+// Thresholds.Montgomery limbs, the product goes through Mul instead, and
+// the multiple of n is computed at once as u = -t * n^-1 mod R, with two
+// more multiplications through Mul, which Karatsuba and Toom-Cook speed
+// up where the limb loop cannot.
+//
+// Converting into the representation and back costs a multiplication
+// each, so it pays for a chain of products modulo the same n, as in
+// modular exponentiation.
+type MontgomeryContext struct {
+	n    *Int
+	k    int
+	nInv word // -n^-1 mod 2^_W
+	rr   *Int // R^2 mod n, to convert into the representation
+	// nPrime is -n^-1 mod R, to reduce with multiplications
+	nPrime *Int
+}
+
+// NewMontgomeryContext precomputes the context of the odd modulus n,
+// which must be greater than one
+func NewMontgomeryContext(n *Int) (*MontgomeryContext, error) {
+	if n.Compare(OneValue) <= 0 || n.bit(0) == 0 {
+		return nil, errors.New("bignum: Montgomery modulus must be odd and greater than one")
+	}
+	m := &MontgomeryContext{n: new(Int), k: n.len()}
+	m.n.Set(n)
+	m.n.norm()
+	// Newton iteration for n0^-1 mod 2^_W: n0*n0 = 1 mod 8 for odd n0,
+	// and every step doubles the number of correct bits
+	n0 := m.n.nat[0]
+	inv := n0
+	for correct := 3; correct < _W; correct *= 2 {
+		inv *= 2 - n0*inv
+	}
+	m.nInv = -inv
+	m.rr = new(Int)
+	m.rr.Set(OneValue)
+	m.rr.shiftLimbs(2 * m.k)
+	m.rr.reduce(m.n)
+	m.nPrime = m.negInverse(-m.nInv)
+	return m, nil
+}
+
+// negInverse returns -n^-1 mod R from inv = n^-1 mod 2^_W. Newton's
+// iteration inv = inv*(2 - n*inv) doubles the number of correct limbs
+// at every step, the same way it does bits for the inverse of a limb.
+func (m *MontgomeryContext) negInverse(inv word) *Int {
+	r := fromLimbs([]word{inv})
+	for correct := 1; correct < m.k; correct *= 2 {
+		l := 2 * correct
+		if l > m.k {
+			l = m.k
+		}
+		// 2 - n*inv mod B^l, where n*inv is 1 modulo B^correct
+		t := m.n.window(0, l)
+		t.Mul(r)
+		t = t.window(0, l)
+		d := NewInt(2)
+		d.Add(limbPower(l))
+		d.Sub(t)
+		r.Mul(d)
+		r = r.window(0, l)
+	}
+	neg := limbPower(m.k)
+	neg.Sub(r)
+	return neg
+}
+
+// limbPower returns B^l, one shifted by l limbs
+func limbPower(l int) *Int {
+	r := NewInt(1)
+	r.shiftLimbs(l)
+	return r
+}
+
+// limbs returns x as exactly k limbs, x must be below n
+func (m *MontgomeryContext) limbs(x *Int) []word {
+	z := make([]word, m.k)
+	copy(z, x.nat[:x.len()])
+	return z
+}
+
+// mul returns x*y/R mod n for x and y of k limbs below n
+func (m *MontgomeryContext) mul(x, y []word) []word {
+	k := m.k
+	if k >= thresholds.Montgomery {
+		return m.mulReduce(x, y)
+	}
+	n := m.n.nat
+	// t stays below 2*n*R, which fits in 2k+1 limbs
+	t := make([]word, 2*k+1)
+	for i := 0; i < k; i++ {
+		addCarry(t[i+k:], addMulVVW(t[i:i+k], x, y[i]))
+		u := t[i] * m.nInv
+		addCarry(t[i+k:], addMulVVW(t[i:i+k], n, u))
+	}
+	// the low k limbs are zero, the result is the rest and below 2n
+	z := t[k:]
+	if z[k] != 0 || compareLimbs(z[:k], n) >= 0 {
+		subVV(z[:k], z[:k], n)
+	}
+	return z[:k]
+}
+
+// mulReduce returns x*y/R mod n like mul, with the product and the
+// multiple of n to add computed by Mul
+func (m *MontgomeryContext) mulReduce(x, y []word) []word {
+	t := fromLimbs(append([]word{}, x...))
+	t.Mul(fromLimbs(y))
+	// u = -t * n^-1 mod R makes t + u*n a multiple of R, below 2*n*R
+	u := t.window(0, m.k)
+	u.Mul(m.nPrime)
+	u = u.window(0, m.k)
+	u.Mul(m.n)
+	t.Add(u)
+	z := t.window(m.k, t.len())
+	if z.Compare(m.n) >= 0 {
+		z.Sub(m.n)
+	}
+	return m.limbs(z)
+}
+
+// addCarry adds the limb c to z, which must be large enough to hold the
+// sum
+func addCarry(z []word, c word) {
+	for i := 0; c != 0; i++ {
+		z[i] += c
+		c = b2w(z[i] < c)
+	}
+}
+
+// compareLimbs compares x and y of the same length
+func compareLimbs(x, y []word) int {
+	for i := len(x) - 1; i >= 0; i-- {
+		switch {
+		case x[i] < y[i]:
+			return -1
+		case x[i] > y[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// fromLimbs wraps limbs in a normalized Int
+func fromLimbs(z []word) *Int {
+	r := &Int{nat: z}
+	r.norm()
+	return r
+}
+
+// ToMontgomery returns x*R mod n, the Montgomery representation of x
+func (m *MontgomeryContext) ToMontgomery(x *Int) *Int {
+	r := new(Int)
+	r.Set(x)
+	if r.Compare(m.n) >= 0 {
+		r.reduce(m.n)
+	}
+	return fromLimbs(m.mul(m.limbs(r), m.limbs(m.rr)))
+}
+
+// FromMontgomery returns x/R mod n, the number that x represents
+func (m *MontgomeryContext) FromMontgomery(x *Int) *Int {
+	return fromLimbs(m.mul(m.limbs(x), m.limbs(OneValue)))
+}
+
+// MontgomeryMul returns x*y/R mod n, which represents the product of the
+// numbers that x and y represent. Both must be below n.
+func (m *MontgomeryContext) MontgomeryMul(x, y *Int) *Int {
+	return fromLimbs(m.mul(m.limbs(x), m.limbs(y)))
+}
+
+// ModExpMontgomery sets bi to bi^x mod modulus, like
+// ModularExponentiation, but multiplies in the Montgomery representation
+// so that no step needs a division. Even moduli have no Montgomery
+// representation and go through ModularExponentiation.
+func (bi *Int) ModExpMontgomery(x *Int, modulus *Int) {
+	bi.mutate()
+	m, err := NewMontgomeryContext(modulus)
+	if err != nil {
+		bi.ModularExponentiation(x, modulus)
+		return
+	}
+	b := m.limbs(m.ToMontgomery(bi))
+	// R mod n represents one
+	c := m.limbs(m.ToMontgomery(OneValue))
+	for i := x.bitLen() - 1; i >= 0; i-- {
+		c = m.mul(c, c)
+		if x.bit(i) == 1 {
+			c = m.mul(c, b)
+		}
+	}
+	bi.nat = m.FromMontgomery(fromLimbs(c)).nat
+}
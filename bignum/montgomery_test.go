@@ -0,0 +1,141 @@
+package bignum
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestModExpMontgomery(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		base, exp, modulus int
+		expected           int
+	}{
+		{4, 13, 497, 445},
+		{2, 10, 1023, 1},
+		{3, 0, 7, 1},
+		{0, 5, 7, 0},
+		{10, 3, 3, 1},
+		// even moduli go through ModularExponentiation
+		{3, 5, 10, 3},
+		{5, 3, 1, 0},
+	}
+	for i, tc := range testcases {
+		b := NewInt(tc.base)
+		b.ModExpMontgomery(NewInt(tc.exp), NewInt(tc.modulus))
+		if b.ToInt() != tc.expected {
+			t.Fatalf("testcase %d expected %d but got %d", i, tc.expected, b.ToInt())
+		}
+	}
+}
+
+func TestModExpMontgomeryRandoms(t *testing.T) {
+	t.Parallel()
+	for _, bits := range []int{16, 17, 64, 255, 256, 1024} {
+		for i := 0; i < 5; i++ {
+			n, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+			n.SetBit(n, 0, 1)
+			n.SetBit(n, bits-1, 1)
+			base, _ := rand.Int(rand.Reader, new(big.Int).Lsh(n, 8))
+			exp, _ := rand.Int(rand.Reader, n)
+			ref := new(big.Int).Exp(base, exp, n)
+			got := fromBig(base)
+			got.ModExpMontgomery(fromBig(exp), fromBig(n))
+			if got.Compare(fromBig(ref)) != 0 {
+				t.Fatalf("%d bits: expected %x^%x mod %x = %x but got %s", bits, base, exp, n, ref, got.hex())
+			}
+		}
+	}
+}
+
+func TestMontgomeryMul(t *testing.T) {
+	t.Parallel()
+	if _, err := NewMontgomeryContext(NewInt(10)); err == nil {
+		t.Fatal("expected an even modulus to be rejected")
+	}
+	if _, err := NewMontgomeryContext(NewInt(1)); err == nil {
+		t.Fatal("expected a modulus of one to be rejected")
+	}
+	n, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 521))
+	n.SetBit(n, 0, 1)
+	m, err := NewMontgomeryContext(fromBig(n))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		x, _ := rand.Int(rand.Reader, n)
+		y, _ := rand.Int(rand.Reader, n)
+		xm, ym := m.ToMontgomery(fromBig(x)), m.ToMontgomery(fromBig(y))
+		if back := m.FromMontgomery(xm); back.Compare(fromBig(x)) != 0 {
+			t.Fatalf("testcase %d expected %x back from the Montgomery form but got %s", i, x, back.hex())
+		}
+		ref := new(big.Int).Mul(x, y)
+		ref.Mod(ref, n)
+		if got := m.FromMontgomery(m.MontgomeryMul(xm, ym)); got.Compare(fromBig(ref)) != 0 {
+			t.Fatalf("testcase %d expected %x * %x = %x but got %s", i, x, y, ref, got.hex())
+		}
+	}
+}
+
+func TestMontgomeryMulReduce(t *testing.T) {
+	t.Parallel()
+	// both reductions of the product must agree, called directly as the
+	// threshold is global
+	for _, limbs := range []int{1, 2, 3, 5, 8, 17, 100} {
+		n, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), uint(_W*limbs)))
+		n.SetBit(n, 0, 1)
+		n.SetBit(n, _W*limbs-1, 1)
+		m, err := NewMontgomeryContext(fromBig(n))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// n * nPrime = -1 mod R
+		check := new(big.Int).Mul(n, new(big.Int).SetBytes(m.nPrime.Bytes()))
+		check.Add(check, big.NewInt(1))
+		if check.Mod(check, new(big.Int).Lsh(big.NewInt(1), uint(_W*limbs))).Sign() != 0 {
+			t.Fatalf("%d limbs: expected nPrime to be -n^-1 mod R but got %s", limbs, m.nPrime.hex())
+		}
+		for i := 0; i < 10; i++ {
+			x, _ := rand.Int(rand.Reader, n)
+			y, _ := rand.Int(rand.Reader, n)
+			if i == 0 {
+				// the largest operands
+				x.Sub(n, big.NewInt(1))
+				y.Set(x)
+			}
+			expected := fromLimbs(m.mul(m.limbs(fromBig(x)), m.limbs(fromBig(y))))
+			got := fromLimbs(m.mulReduce(m.limbs(fromBig(x)), m.limbs(fromBig(y))))
+			if got.Compare(expected) != 0 {
+				t.Fatalf("%d limbs: testcase %d expected %s but got %s", limbs, i, expected.hex(), got.hex())
+			}
+		}
+	}
+}
+
+// BenchmarkModExp compares square and multiply reducing with Div at every
+// step to the Montgomery form, on RSA sized operands
+func BenchmarkModExp(b *testing.B) {
+	for _, bits := range []int{512, 1024, 2048} {
+		n, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+		n.SetBit(n, 0, 1)
+		base, _ := rand.Int(rand.Reader, n)
+		exp, _ := rand.Int(rand.Reader, n)
+		bn, bb, be := fromBig(n), fromBig(base), fromBig(exp)
+		b.Run(fmt.Sprintf("div/%d", bits), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				z := new(Int)
+				z.Set(bb)
+				z.ModularExponentiation(be, bn)
+			}
+		})
+		b.Run(fmt.Sprintf("montgomery/%d", bits), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				z := new(Int)
+				z.Set(bb)
+				z.ModExpMontgomery(be, bn)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package bignum
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PrattCertificate proves that N is prime by exhibiting A, a generator of
+// the multiplicative group modulo N: A^(N-1) = 1 mod N, and A^((N-1)/p)
+// is not 1 for any prime factor p of N - 1. A then has order N - 1, so the
+// group has N - 1 elements and every integer in [1, N-1] is coprime with
+// N. Factors is the factorization of N - 1, each of its primes proven by
+// its own certificate, down to 2 which needs none.
+//
+// A certificate has a size polynomial in the size of N, but finding one
+// takes factoring N - 1 and then the N - 1 of each of its factors.
+type PrattCertificate struct {
+	N       *Int
+	A       *Int
+	Factors []PrattFactor
+}
+
+// PrattFactor is a prime power P^K of N - 1 with the certificate of P,
+// which is nil for 2
+type PrattFactor struct {
+	P    *Int
+	K    int
+	Cert *PrattCertificate
+}
+
+// VerifyPrattCertificate returns nil if cert proves that cert.N is prime
+func VerifyPrattCertificate(cert *PrattCertificate) error {
+	if cert == nil || cert.N == nil {
+		return errors.New("bignum: pratt certificate has no number")
+	}
+	two := NewInt(2)
+	if cert.N.Compare(two) == 0 {
+		return nil
+	}
+	if cert.N.Compare(two) < 0 || cert.A == nil {
+		return fmt.Errorf("bignum: no pratt proof for %s", cert.N.hex())
+	}
+	nm1 := new(Int)
+	nm1.Set(cert.N)
+	nm1.Decrement()
+	product := NewInt(1)
+	for _, f := range cert.Factors {
+		if f.K < 1 {
+			return errors.New("bignum: pratt factor with a zero exponent")
+		}
+		if f.P == nil {
+			return errors.New("bignum: pratt factor has no prime")
+		}
+		if f.P.Compare(two) != 0 {
+			// P divides N - 1 so is smaller than N, the recursion ends
+			if f.Cert == nil || f.Cert.N == nil || f.Cert.N.Compare(f.P) != 0 {
+				return fmt.Errorf("bignum: no certificate for factor %s", f.P.hex())
+			}
+			if err := VerifyPrattCertificate(f.Cert); err != nil {
+				return err
+			}
+		}
+		product.Mul(pow(f.P, f.K))
+		if product.Compare(nm1) > 0 {
+			break
+		}
+	}
+	if product.Compare(nm1) != 0 {
+		return fmt.Errorf("bignum: factors do not multiply to %s - 1", cert.N.hex())
+	}
+	if modExp(cert.A, nm1, cert.N).Compare(OneValue) != 0 {
+		return fmt.Errorf("bignum: %s^(n-1) is not 1 mod %s", cert.A.hex(), cert.N.hex())
+	}
+	for _, f := range cert.Factors {
+		e := new(Int)
+		e.Set(nm1)
+		e.Div(f.P)
+		if modExp(cert.A, e, cert.N).Compare(OneValue) == 0 {
+			return fmt.Errorf("bignum: %s does not generate the group modulo %s", cert.A.hex(), cert.N.hex())
+		}
+	}
+	return nil
+}
+
+// NewPrattCertificate returns a certificate of the primality of n, or an
+// error if n is not prime. It factors n - 1 by trial division, see
+// Factorize, and is meant for small n.
+func NewPrattCertificate(n *Int) (*PrattCertificate, error) {
+	if f := Factorize(n); len(f) != 1 || f[0].K != 1 {
+		return nil, fmt.Errorf("bignum: %s is not prime", n.hex())
+	}
+	cert := &PrattCertificate{N: new(Int)}
+	cert.N.Set(n)
+	if n.Compare(NewInt(2)) == 0 {
+		return cert, nil
+	}
+	nm1 := new(Int)
+	nm1.Set(n)
+	nm1.Decrement()
+	for _, f := range Factorize(nm1) {
+		pf := PrattFactor{P: f.P, K: f.K}
+		if f.P.Compare(NewInt(2)) != 0 {
+			c, err := NewPrattCertificate(f.P)
+			if err != nil {
+				return nil, err
+			}
+			pf.Cert = c
+		}
+		cert.Factors = append(cert.Factors, pf)
+	}
+	// the smallest generator, which is small: there are φ(n-1) of them
+	for a := NewInt(2); ; a.Increment() {
+		generates := true
+		for _, f := range cert.Factors {
+			e := new(Int)
+			e.Set(nm1)
+			e.Div(f.P)
+			if modExp(a, e, n).Compare(OneValue) == 0 {
+				generates = false
+				break
+			}
+		}
+		if generates {
+			cert.A = a
+			return cert, nil
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package bignum
+
+import "testing"
+
+func TestPrattCertificate(t *testing.T) {
+	t.Parallel()
+	for i, p := range []int{2, 3, 5, 7919, 1048573, 2097779, 2147483647} {
+		cert, err := NewPrattCertificate(NewInt(p))
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if err := VerifyPrattCertificate(cert); err != nil {
+			t.Fatalf("testcase %d certificate of %d failed to verify: %v", i, p, err)
+		}
+	}
+	for i, n := range []int{1, 4, 561, 7909, 1048575} {
+		if _, err := NewPrattCertificate(NewInt(n)); err == nil {
+			t.Fatalf("testcase %d expected no certificate for composite %d", i, n)
+		}
+	}
+}
+
+func TestPrattCertificateForgeries(t *testing.T) {
+	t.Parallel()
+	// 7919 - 1 = 2 * 37 * 107
+	cert, err := NewPrattCertificate(NewInt(7919))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var testcases = []struct {
+		desc   string
+		forged func(c PrattCertificate) *PrattCertificate
+	}{
+		{"another number", func(c PrattCertificate) *PrattCertificate {
+			c.N = NewInt(7921)
+			return &c
+		}},
+		{"a witness of small order", func(c PrattCertificate) *PrattCertificate {
+			// -1 has order 2
+			c.A = NewInt(7918)
+			return &c
+		}},
+		{"a missing factor", func(c PrattCertificate) *PrattCertificate {
+			c.Factors = c.Factors[:2]
+			return &c
+		}},
+		{"a factor proven by another certificate", func(c PrattCertificate) *PrattCertificate {
+			c.Factors = append([]PrattFactor{}, c.Factors...)
+			c.Factors[1].Cert = c.Factors[2].Cert
+			return &c
+		}},
+		{"a composite factor", func(c PrattCertificate) *PrattCertificate {
+			// 7921 - 1 = 2^4 * 3^2 * 5 * 11, with 55 passed off as prime
+			c.N = NewInt(7921)
+			c.Factors = []PrattFactor{
+				{NewInt(2), 4, nil},
+				{NewInt(3), 2, &PrattCertificate{N: NewInt(3), A: NewInt(2), Factors: []PrattFactor{{NewInt(2), 1, nil}}}},
+				{NewInt(55), 1, &PrattCertificate{N: NewInt(55), A: NewInt(2)}},
+			}
+			return &c
+		}},
+		{"no certificate", func(c PrattCertificate) *PrattCertificate {
+			return nil
+		}},
+		{"a factor with no prime", func(c PrattCertificate) *PrattCertificate {
+			c.Factors = append([]PrattFactor{}, c.Factors...)
+			c.Factors[1].P = nil
+			return &c
+		}},
+		{"a factor certificate with no number", func(c PrattCertificate) *PrattCertificate {
+			c.Factors = append([]PrattFactor{}, c.Factors...)
+			c.Factors[1].Cert = &PrattCertificate{A: NewInt(2)}
+			return &c
+		}},
+	}
+	for i, tc := range testcases {
+		if err := VerifyPrattCertificate(tc.forged(*cert)); err == nil {
+			t.Fatalf("testcase %d expected %s to fail", i, tc.desc)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package bignum
+
+import "crypto/rand"
+
+// IsSolovayStrassenPrime returns true if bi is probably prime according
+// to the Solovay-Strassen test, repeated rounds times.
+//
+// Euler's criterion says that for an odd prime n, every a coprime with n
+// satisfies a^((n-1)/2) = (a/n) mod n, where (a/n) is the Jacobi symbol.
+// Each round picks a random a and checks the criterion: a composite n
+// fails it for at least half of the bases, so a composite passes all
+// rounds with a probability below 2^-rounds. Unlike Fermat's test, no
+// composite passes for every base, Carmichael numbers included. The test
+// came in 1977, after Miller's deterministic test of 1976 and before
+// Rabin randomized it in 1980: Miller-Rabin fails composites for three
+// quarters of the bases, and replaced Solovay-Strassen.
+func (bi *Int) IsSolovayStrassenPrime(rounds int) bool {
+	n := new(Int)
+	n.Set(bi)
+	n.norm()
+	switch {
+	case n.Compare(NewInt(3)) <= 0:
+		return n.Compare(OneValue) > 0
+	case n.bit(0) == 0:
+		return false
+	}
+	nMinusOne := new(Int)
+	nMinusOne.Set(n)
+	nMinusOne.Decrement()
+	e := new(Int)
+	e.Set(nMinusOne)
+	e.rsh1()
+	for i := 0; i < rounds; i++ {
+		a := randomBase(n)
+		j := jacobi(a, n)
+		if j == 0 {
+			// a shares a factor with n
+			return false
+		}
+		x := modExp(a, e, n)
+		if (j == 1 && x.Compare(OneValue) != 0) || (j == -1 && x.Compare(nMinusOne) != 0) {
+			if tracing() {
+				tracef("solovay-strassen", "%s is composite: %s^((n-1)/2) = %s but (a/n) = %d", n.hex(), a.hex(), x.hex(), j)
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// IsProbablyPrime returns true if bi is probably prime according to the
+// Miller-Rabin test, repeated rounds times with random bases.
+//
+// Write n - 1 as 2^s d with d odd. Modulo a prime the only square roots
+// of 1 are 1 and -1, so squaring a^d s times either starts from 1 or
+// passes through -1 before reaching a^(n-1) = 1. A base a for which it
+// does neither is a witness that n is composite, and at least three
+// quarters of the bases are witnesses for any odd composite, Carmichael
+// numbers included: a composite passes all rounds with a probability
+// below 4^-rounds.
+//
+// With rounds below one nothing is tested, so it returns false rather than
+// calling every odd number prime.
+func (bi *Int) IsProbablyPrime(rounds int) bool {
+	if rounds < 1 {
+		return false
+	}
+	n := new(Int)
+	n.Set(bi)
+	n.norm()
+	switch {
+	case n.Compare(NewInt(3)) <= 0:
+		return n.Compare(OneValue) > 0
+	case n.bit(0) == 0:
+		return false
+	}
+	nMinusOne := new(Int)
+	nMinusOne.Set(n)
+	nMinusOne.Decrement()
+	d := new(Int)
+	d.Set(nMinusOne)
+	s := int(d.TrailingZeroBits())
+	d.Rsh(uint(s))
+	for i := 0; i < rounds; i++ {
+		a := randomBase(n)
+		if !millerRabinRound(n, nMinusOne, a, d, s) {
+			if tracing() {
+				tracef("miller-rabin", "%s is composite: %s is a witness", n.hex(), a.hex())
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// millerRabinRound returns false if a is a witness that n = 2^s d + 1 is
+// composite
+func millerRabinRound(n, nMinusOne, a, d *Int, s int) bool {
+	x := modExp(a, d, n)
+	if x.Compare(OneValue) == 0 || x.Compare(nMinusOne) == 0 {
+		return true
+	}
+	for r := 1; r < s; r++ {
+		sq := new(Int)
+		sq.Set(x)
+		sq.Mul(x)
+		x = mod(sq, n)
+		switch {
+		case x.Compare(nMinusOne) == 0:
+			return true
+		case x.Compare(OneValue) == 0:
+			// a square root of 1 other than -1
+			return false
+		}
+	}
+	return false
+}
+
+// randomBase returns a random integer in [2, n-2], for n > 3
+func randomBase(n *Int) *Int {
+	span := new(Int)
+	span.Set(n)
+	span.Sub(NewInt(3))
+	a, err := randomBelow(rand.Reader, span)
+	if err != nil {
+		panic(err)
+	}
+	a.Add(NewInt(2))
+	return a
+}
+
+// jacobi returns the Jacobi symbol (a/n) of a and an odd n, which is 0
+// when they share a factor and otherwise 1 or -1. For a prime n, it is
+// the Legendre symbol: 1 if a is a square modulo n and -1 if it is not.
+//
+// It applies the rules of the symbol until a reaches zero: factors of
+// two come out of a with (2/n) = -1 when n = 3 or 5 mod 8, and quadratic
+// reciprocity swaps a and n, flipping the sign when both are 3 mod 4.
+func jacobi(a, n *Int) int {
+	if n.bit(0) == 0 {
+		panic("jacobi symbol of an even number")
+	}
+	a = mod(a, n)
+	m := new(Int)
+	m.Set(n)
+	n = m
+	j := 1
+	for a.len() != 0 {
+		for a.bit(0) == 0 {
+			a.rsh1()
+			if r := n.nat[0] & 7; r == 3 || r == 5 {
+				j = -j
+			}
+		}
+		a, n = n, a
+		if a.nat[0]&3 == 3 && n.nat[0]&3 == 3 {
+			j = -j
+		}
+		a = mod(a, n)
+	}
+	if n.Compare(OneValue) != 0 {
+		return 0
+	}
+	return j
+}
+
+// modExp returns base^e mod m, leaving base untouched. The moduli of the
+// primality tests are odd, so it multiplies in Montgomery form.
+func modExp(base, e, m *Int) *Int {
+	r := new(Int)
+	r.Set(base)
+	r.ModExpMontgomery(e, m)
+	return r
+}
+
+// rsh1 shifts bi to the right by one bit
+func (bi *Int) rsh1() {
+	for i := range bi.nat {
+		bi.nat[i] >>= 1
+		if i+1 < len(bi.nat) {
+			bi.nat[i] |= bi.nat[i+1] << (_W - 1)
+		}
+	}
+	bi.norm()
+}
@@ -0,0 +1,195 @@
+package bignum
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestIsSolovayStrassenPrime(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		p       int
+		isprime bool
+	}{
+		{0, false},
+		{1, false},
+		{2, true},
+		{3, true},
+		{4, false},
+		{13, true},
+		{17, true},
+		{21, false},
+		// Carmichael numbers, which pass Fermat's test for every
+		// coprime base
+		{561, false},
+		{1105, false},
+		{41041, false},
+		{7909, false},
+		{7919, true},
+		{1048129, true},
+		{1048130, false},
+		{2147483647, true},
+	}
+	for i, tc := range testcases {
+		p := NewInt(tc.p)
+		r := p.IsSolovayStrassenPrime(20)
+		if r != tc.isprime {
+			t.Fatalf("testcase %d expected isprime=%t for %d but got %t", i, tc.isprime, tc.p, r)
+		}
+	}
+}
+
+func TestIsProbablyPrime(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		p       string
+		isprime bool
+	}{
+		{"0", false},
+		{"1", false},
+		{"2", true},
+		{"3", true},
+		{"4", false},
+		{"7919", true},
+		{"1048130", false},
+		// Carmichael numbers
+		{"561", false},
+		{"41041", false},
+		// strong pseudoprimes to base 2, and to bases 2, 3, 5 and 7
+		{"2047", false},
+		{"3277", false},
+		{"3215031751", false},
+		// 2^61 - 1 and 2^127 - 1, and the product of two 64 bits primes
+		{"2305843009213693951", true},
+		{"170141183460469231731687303715884105727", true},
+		{"340282366920938460843936948965011886881", false},
+	}
+	for i, tc := range testcases {
+		p, _ := new(Int).SetString(tc.p, 10)
+		if r := p.IsProbablyPrime(20); r != tc.isprime {
+			t.Fatalf("testcase %d expected isprime=%t for %s but got %t", i, tc.isprime, tc.p, r)
+		}
+	}
+}
+
+// TestIsProbablyPrimeNoRounds checks that no rounds do not let the
+// Carmichael number 561 through, nor any prime
+func TestIsProbablyPrimeNoRounds(t *testing.T) {
+	t.Parallel()
+	for _, rounds := range []int{0, -1} {
+		for _, n := range []int{561, 7919} {
+			if NewInt(n).IsProbablyPrime(rounds) {
+				t.Fatalf("expected %d not to be reported prime with %d rounds", n, rounds)
+			}
+		}
+	}
+	if !NewInt(7919).IsProbablyPrime(1) {
+		t.Fatal("expected 7919 to be reported prime with one round")
+	}
+}
+
+// TestMillerRabinWitness checks that 2047 = 23 * 89 passes the round of
+// base 2 but not that of base 3
+func TestMillerRabinWitness(t *testing.T) {
+	t.Parallel()
+	n, nMinusOne := NewInt(2047), NewInt(2046)
+	// 2046 = 2 * 1023
+	d := NewInt(1023)
+	if !millerRabinRound(n, nMinusOne, NewInt(2), d, 1) {
+		t.Fatal("expected 2 not to be a witness for 2047")
+	}
+	if millerRabinRound(n, nMinusOne, NewInt(3), d, 1) {
+		t.Fatal("expected 3 to be a witness for 2047")
+	}
+}
+
+func TestIsProbablyPrimeRandoms(t *testing.T) {
+	t.Parallel()
+	for i := 0; i < 100; i++ {
+		std, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 96))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i%2 == 0 {
+			std, err = rand.Prime(rand.Reader, 96)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		n := new(Int)
+		n.SetBytes(std.Bytes())
+		if r, ref := n.IsProbablyPrime(20), std.ProbablyPrime(20); r != ref {
+			t.Fatalf("testcase %d expected isprime=%t for %s but got %t", i, ref, std, r)
+		}
+	}
+}
+
+func TestJacobi(t *testing.T) {
+	t.Parallel()
+	for i := 0; i < 50; i++ {
+		stdn, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdn.SetBit(stdn, 0, 1)
+		stda, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 160))
+		if err != nil {
+			t.Fatal(err)
+		}
+		a := new(Int)
+		a.SetBytes(stda.Bytes())
+		n := new(Int)
+		n.SetBytes(stdn.Bytes())
+		if j, ref := jacobi(a, n), big.Jacobi(stda, stdn); j != ref {
+			t.Fatalf("testcase %d expected (%x/%x) = %d but got %d", i, stda.Bytes(), stdn.Bytes(), ref, j)
+		}
+	}
+}
+
+func TestModExp(t *testing.T) {
+	t.Parallel()
+	for i := 0; i < 10; i++ {
+		var std [3]*big.Int
+		var ints [3]*Int
+		for k := range std {
+			var err error
+			std[k], err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 256))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ints[k] = new(Int)
+			ints[k].SetBytes(std[k].Bytes())
+		}
+		ref := new(big.Int).Exp(std[0], std[1], std[2])
+		if r := modExp(ints[0], ints[1], ints[2]); !bytes.Equal(r.Bytes(), ref.Bytes()) {
+			t.Fatalf("testcase %d expected %x but got %x", i, ref.Bytes(), r.Bytes())
+		}
+	}
+}
+
+// TestBigIntSolovayStrassenRandoms cross-checks the test against the
+// Miller-Rabin rounds of math/big on random odd numbers and on primes,
+// which would be far too rare among random numbers of that size
+func TestBigIntSolovayStrassenRandoms(t *testing.T) {
+	t.Parallel()
+	for i := 0; i < 40; i++ {
+		var stdn *big.Int
+		var err error
+		if i%4 == 0 {
+			stdn, err = rand.Prime(rand.Reader, 96)
+		} else {
+			stdn, err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 96))
+			stdn.SetBit(stdn, 0, 1)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := new(Int)
+		n.SetBytes(stdn.Bytes())
+		if r, ref := n.IsSolovayStrassenPrime(20), stdn.ProbablyPrime(20); r != ref {
+			t.Fatalf("testcase %d expected isprime=%t for %x but got %t", i, ref, stdn.Bytes(), r)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package bignum
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+// certificateSmallBits is the size up to which primes are proven by
+// trial division rather than by Pocklington's criterion
+const certificateSmallBits = 24
+
+// PrimalityCertificate proves that N is prime, and can be checked with
+// Verify by someone who does not trust how N was generated.
+//
+// Up to 24 bits, N carries no certificate and is proven by trial
+// division. Above, QCert proves the primality of Q = QCert.N, which
+// divides N - 1 and is larger than the square root of N, and A is a
+// witness of Pocklington's criterion:
+//
+//	A^(N-1) = 1 mod N    and    gcd(A^((N-1)/Q) - 1, N) = 1
+//
+// Any prime factor r of N then has A^((N-1)/Q) of an order modulo r that
+// divides N - 1 but not (N-1)/Q, which makes Q divide r - 1: r is larger
+// than Q and so than the square root of N, and N has no other factor
+// than itself.
+type PrimalityCertificate struct {
+	N     *Int
+	A     *Int
+	QCert *PrimalityCertificate
+}
+
+// Verify returns nil if the certificate proves that N is prime, checking
+// the certificates of the factors down to trial division
+func (c *PrimalityCertificate) Verify() error {
+	if c.N == nil {
+		return errors.New("bignum: certificate has no number")
+	}
+	if c.QCert == nil {
+		if c.N.bitLen() > certificateSmallBits || !isSmallPrime(c.N) {
+			return fmt.Errorf("bignum: %s is not a small prime", c.N.hex())
+		}
+		return nil
+	}
+	if err := c.QCert.Verify(); err != nil {
+		return err
+	}
+	q := c.QCert.N
+	if c.A == nil || !pocklington(c.N, q, c.A) {
+		return fmt.Errorf("bignum: no pocklington proof for %s", c.N.hex())
+	}
+	return nil
+}
+
+// pocklington checks the criterion of PrimalityCertificate for n, q and
+// the witness a
+func pocklington(n, q, a *Int) bool {
+	// q^2 > n
+	sq := new(Int)
+	sq.Set(q)
+	sq.Mul(q)
+	if sq.Compare(n) <= 0 || n.bit(0) == 0 {
+		return false
+	}
+	nm1 := new(Int)
+	nm1.Set(n)
+	nm1.Decrement()
+	e := new(Int)
+	e.Set(nm1)
+	if e.Div(q).len() != 0 {
+		return false
+	}
+	if modExp(a, nm1, n).Compare(OneValue) != 0 {
+		return false
+	}
+	x := modExp(a, e, n)
+	if x.len() == 0 {
+		return false
+	}
+	x.Decrement()
+	return Gcd(x, n).Compare(OneValue) == 0
+}
+
+// isSmallPrime tests bi by trial division, for bi of at most 32 bits
+func isSmallPrime(bi *Int) bool {
+	n := uint32(bi.ToInt())
+	if n < 2 {
+		return false
+	}
+	for d := uint32(2); d*d <= n; d++ {
+		if n%d == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// GenerateProvablePrime returns a random prime of exactly bits bits and
+// its certificate, with Maurer's recursive construction: it proves a
+// prime q of about half the size first, then searches n = 2Rq + 1 for
+// random R until n is prime, which Pocklington's criterion proves from q.
+// Unlike Miller-Rabin, whose answer is only likely, the certificate is a
+// proof. Random values are read from rand.
+//
+// This is the simplified form of the construction, where q always has
+// half the size of n plus one bit, as FIPS 186-4 does with
+// Shawe-Taylor's algorithm. Maurer picks the size of q at random so that
+// n is drawn from all primes of bits bits almost uniformly.
+func GenerateProvablePrime(rand io.Reader, bits int) (*Int, *PrimalityCertificate, error) {
+	if bits < 2 {
+		return nil, nil, errors.New("bignum: prime size must be at least 2 bits")
+	}
+	if bits <= certificateSmallBits {
+		for {
+			n, err := randomBits(rand, bits)
+			if err != nil {
+				return nil, nil, err
+			}
+			if isSmallPrime(n) {
+				return n, &PrimalityCertificate{N: n}, nil
+			}
+		}
+	}
+	q, qCert, err := GenerateProvablePrime(rand, (bits+1)/2+1)
+	if err != nil {
+		return nil, nil, err
+	}
+	// R in [I+1, 2I] with I = 2^(bits-1) / 2q, so that n has bits bits
+	// or, rarely, one more
+	i := new(Int)
+	i.nat = make([]word, (bits-1)/_W+1)
+	i.nat[(bits-1)/_W] = 1 << uint((bits-1)%_W)
+	twoQ := new(Int)
+	twoQ.Set(q)
+	twoQ.Add(q)
+	i.Div(twoQ)
+	for {
+		r, err := randomBelow(rand, i)
+		if err != nil {
+			return nil, nil, err
+		}
+		r.Add(i)
+		r.Increment()
+		n := new(Int)
+		n.Set(r)
+		n.Mul(twoQ)
+		n.Increment()
+		if n.bitLen() != bits || hasSmallFactor(n) {
+			continue
+		}
+		// a is 2 or, when 2 fails the gcd but not Fermat's test, a
+		// few small bases
+		nm1 := new(Int)
+		nm1.Set(n)
+		nm1.Decrement()
+		for a := 2; a < 8; a++ {
+			witness := NewInt(a)
+			if modExp(witness, nm1, n).Compare(OneValue) != 0 {
+				// composite
+				break
+			}
+			if pocklington(n, q, witness) {
+				return n, &PrimalityCertificate{N: n, A: witness, QCert: qCert}, nil
+			}
+		}
+	}
+}
+
+// randomBits returns a random odd integer of exactly bits bits
+func randomBits(rand io.Reader, bits int) (*Int, error) {
+	buf := make([]byte, (bits+7)/8)
+	if _, err := io.ReadFull(rand, buf); err != nil {
+		return nil, err
+	}
+	buf[0] &= byte(0xff >> uint(len(buf)*8-bits))
+	buf[0] |= byte(0x80 >> uint(len(buf)*8-bits))
+	buf[len(buf)-1] |= 1
+	n := new(Int)
+	n.SetBytes(buf)
+	return n, nil
+}
+
+// smallPrimes are the odd primes below 256, to sieve candidates
+var smallPrimes = []uint32{3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37, 41, 43, 47, 53, 59, 61,
+	67, 71, 73, 79, 83, 89, 97, 101, 103, 107, 109, 113, 127, 131, 137, 139, 149, 151, 157,
+	163, 167, 173, 179, 181, 191, 193, 197, 199, 211, 223, 227, 229, 233, 239, 241, 251}
+
+// hasSmallFactor returns true if bi, larger than 256, is divisible by one
+// of smallPrimes, which rules it out much faster than Fermat's test
+func hasSmallFactor(bi *Int) bool {
+	for _, p := range smallPrimes {
+		if bi.modWord(p) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// modWord returns bi mod d, one limb at a time from the most significant
+func (bi *Int) modWord(d uint32) uint32 {
+	var r word
+	for i := bi.len() - 1; i >= 0; i-- {
+		r = bits.Rem(r, bi.nat[i], word(d))
+	}
+	return uint32(r)
+}
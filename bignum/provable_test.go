@@ -0,0 +1,71 @@
+package bignum
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestGenerateProvablePrime(t *testing.T) {
+	t.Parallel()
+	for i, bits := range []int{2, 8, 24, 25, 64, 127, 256} {
+		p, cert, err := GenerateProvablePrime(rand.Reader, bits)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if p.bitLen() != bits || cert.N != p {
+			t.Fatalf("testcase %d expected a certified %d bits prime but got %d bits", i, bits, p.bitLen())
+		}
+		if !new(big.Int).SetBytes(p.Bytes()).ProbablyPrime(20) {
+			t.Fatalf("testcase %d returned composite %x", i, p.Bytes())
+		}
+		if err := cert.Verify(); err != nil {
+			t.Fatalf("testcase %d certificate failed to verify: %v", i, err)
+		}
+	}
+	if _, _, err := GenerateProvablePrime(rand.Reader, 1); err == nil {
+		t.Fatal("expected a 1 bit prime to be refused")
+	}
+	if _, _, err := GenerateProvablePrime(bytes.NewReader(nil), 64); err == nil {
+		t.Fatal("expected an error from an exhausted reader")
+	}
+}
+
+func TestPrimalityCertificateVerify(t *testing.T) {
+	t.Parallel()
+	_, cert, err := GenerateProvablePrime(rand.Reader, 96)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a composite with the same proof
+	forged := *cert
+	forged.N = new(Int)
+	forged.N.Set(cert.N)
+	forged.N.Add(NewInt(2))
+	if forged.Verify() == nil {
+		t.Fatal("expected a certificate for another number to fail")
+	}
+	// the criterion does not hold for a witness of 1
+	forged = *cert
+	forged.A = NewInt(1)
+	if forged.Verify() == nil {
+		t.Fatal("expected a witness of 1 to fail")
+	}
+	// a prime factor too small for the square root bound
+	forged = *cert
+	forged.QCert = &PrimalityCertificate{N: NewInt(2)}
+	if forged.Verify() == nil {
+		t.Fatal("expected a small factor to fail")
+	}
+	// a composite leaf
+	if (&PrimalityCertificate{N: NewInt(561)}).Verify() == nil {
+		t.Fatal("expected 561 to fail trial division")
+	}
+	if (&PrimalityCertificate{N: NewInt(1 << 25)}).Verify() == nil {
+		t.Fatal("expected a large leaf to be refused")
+	}
+	if (&PrimalityCertificate{}).Verify() == nil {
+		t.Fatal("expected an empty certificate to be refused")
+	}
+}
@@ -0,0 +1,69 @@
+package bignum
+
+import (
+	"fmt"
+	"io"
+)
+
+// Rand returns a uniformly random integer in [0, max) read from r, such
+// as crypto/rand.Reader. It panics if max is zero, or if reading from r
+// fails, which crypto/rand.Reader never does.
+//
+// Reducing random bytes modulo max would favor the small values when max
+// is not a power of two. Instead, Rand draws as many bits as max has and
+// starts over while the draw is not below max, which happens less than
+// half of the time.
+func Rand(r io.Reader, max *Int) *Int {
+	if max.len() == 0 {
+		panic("bignum: random integer below zero")
+	}
+	n, err := randomBelow(r, max)
+	if err != nil {
+		panic(fmt.Sprintf("bignum: reading randomness failed: %v", err))
+	}
+	return n
+}
+
+// RandBits returns a uniformly random integer in [0, 2^bits) read from r,
+// which may have less than bits bits. It panics if bits is negative, or
+// if reading from r fails.
+func RandBits(r io.Reader, bits int) *Int {
+	if bits < 0 {
+		panic("bignum: negative number of random bits")
+	}
+	n, err := randomUniformBits(r, bits)
+	if err != nil {
+		panic(fmt.Sprintf("bignum: reading randomness failed: %v", err))
+	}
+	return n
+}
+
+// randomUniformBits returns a random integer of at most bits bits
+func randomUniformBits(r io.Reader, bits int) (*Int, error) {
+	buf := make([]byte, (bits+7)/8)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	if len(buf) > 0 {
+		buf[0] &= byte(0xff >> uint(len(buf)*8-bits))
+	}
+	n := new(Int)
+	n.SetBytes(buf)
+	n.norm()
+	return n, nil
+}
+
+// randomBelow returns a uniformly random integer in [0, n) read from
+// random, by rejection of the draws of n.bitLen() bits that are not
+// below n
+func randomBelow(random io.Reader, n *Int) (*Int, error) {
+	for {
+		a, err := randomUniformBits(random, n.bitLen())
+		if err != nil {
+			return nil, err
+		}
+		if a.Compare(n) < 0 {
+			return a, nil
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package bignum
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestRand(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		random []byte
+		max    int
+		n      int
+	}{
+		// 5 has 3 bits, 0xff is masked to 7 and rejected, then 0x0a to 2
+		{[]byte{0xff, 0x0a}, 5, 2},
+		{[]byte{0x04}, 5, 4},
+		{[]byte{0x00}, 1, 0},
+		// 0x1234 masked to 13 bits is 0x1234, and 0xffff is rejected
+		{[]byte{0xff, 0xff, 0x12, 0x34}, 0x1235, 0x1234},
+	}
+	for i, tc := range testcases {
+		n := Rand(bytes.NewReader(tc.random), NewInt(tc.max))
+		if n.ToInt() != tc.n {
+			t.Fatalf("testcase %d expected %d but got %d", i, tc.n, n.ToInt())
+		}
+	}
+}
+
+func TestRandUniform(t *testing.T) {
+	t.Parallel()
+	// with modular reduction of a byte, 0 to 15 would come out once more
+	// often than 16 to 19 out of 256 draws
+	var counts [20]int
+	for i := 0; i < 20000; i++ {
+		counts[Rand(rand.Reader, NewInt(20)).ToInt()]++
+	}
+	for v, c := range counts {
+		if c < 800 || c > 1200 {
+			t.Fatalf("expected about 1000 draws of %d but got %d", v, c)
+		}
+	}
+	max := new(Int)
+	max.SetBytes(bytes.Repeat([]byte{0xab}, 100))
+	for i := 0; i < 100; i++ {
+		if Rand(rand.Reader, max).Compare(max) >= 0 {
+			t.Fatal("expected a random integer below max")
+		}
+	}
+}
+
+func TestRandBits(t *testing.T) {
+	t.Parallel()
+	for _, bits := range []int{0, 1, 7, 8, 9, 16, 17, 1024} {
+		longest := 0
+		for i := 0; i < 50; i++ {
+			n := RandBits(rand.Reader, bits)
+			if n.bitLen() > bits {
+				t.Fatalf("expected at most %d bits but got %d", bits, n.bitLen())
+			}
+			if n.bitLen() > longest {
+				longest = n.bitLen()
+			}
+		}
+		if longest != bits {
+			t.Fatalf("expected a draw of %d bits in 50 but the longest has %d", bits, longest)
+		}
+	}
+	if n := RandBits(bytes.NewReader([]byte{0xff, 0xff}), 12); n.ToInt() != 0xfff {
+		t.Fatalf("expected 0xfff but got %#x", n.ToInt())
+	}
+}
+
+func TestRandPanics(t *testing.T) {
+	t.Parallel()
+	for i, f := range []func(){
+		func() { Rand(rand.Reader, new(Int)) },
+		func() { Rand(bytes.NewReader(nil), NewInt(10)) },
+		func() { RandBits(rand.Reader, -1) },
+		func() { RandBits(bytes.NewReader([]byte{1}), 16) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("testcase %d expected a panic", i)
+				}
+			}()
+			f()
+		}()
+	}
+}
@@ -0,0 +1,93 @@
+package bignum
+
+// Rat is a positive rational number a/b, stored as two Int.
+//
+// A Rat is not reduced to lowest terms, 2/4 stays 2/4, but the
+// convergents of a continued fraction always are.
+type Rat struct {
+	a, b Int
+}
+
+// NewRat initializes a rational number a/b from copies of a and b.
+// It panics if b is zero.
+func NewRat(a, b *Int) *Rat {
+	if b.len() == 0 {
+		panic("rational with a zero denominator")
+	}
+	r := new(Rat)
+	r.a.Set(a)
+	r.b.Set(b)
+	return r
+}
+
+// Num returns a copy of the numerator of r
+func (r *Rat) Num() *Int {
+	a := new(Int)
+	a.Set(&r.a)
+	return a
+}
+
+// Denom returns a copy of the denominator of r
+func (r *Rat) Denom() *Int {
+	b := new(Int)
+	b.Set(&r.b)
+	return b
+}
+
+// Compare returns -1 if r < x, 0 if r == x and 1 if r > x. Both
+// fractions are brought to the same denominator, a/b is compared to
+// c/d as a*d to c*b.
+func (r *Rat) Compare(x *Rat) int {
+	ad := r.Num()
+	ad.Mul(&x.b)
+	cb := x.Num()
+	cb.Mul(&r.b)
+	return ad.Compare(cb)
+}
+
+// ContinuedFraction returns the expansion of r as a continued
+// fraction [a0; a1, a2, ...], such that
+//
+// r = a0 + 1/(a1 + 1/(a2 + ...))
+//
+// The terms are the successive quotients of Euclid's algorithm on the
+// numerator and the denominator, the expansion of a rational is
+// therefore finite.
+func (r *Rat) ContinuedFraction() (cf []*Int) {
+	a := r.Num()
+	b := r.Denom()
+	for b.len() != 0 {
+		rem := a.Div(b)
+		cf = append(cf, a)
+		a, b = b, rem
+	}
+	return
+}
+
+// Convergents returns the successive approximations of the continued
+// fraction cf, [a0], [a0; a1], [a0; a1, a2], and so on. The last one
+// is the value of cf itself.
+//
+// The i-th convergent h_i/k_i is computed from the previous two with
+//
+// h_i = a_i * h_i-1 + h_i-2 and k_i = a_i * k_i-1 + k_i-2
+//
+// starting from h_-1/k_-1 = 1/0 and h_-2/k_-2 = 0/1.
+func Convergents(cf []*Int) (convergents []*Rat) {
+	h1, h2 := NewInt(1), new(Int)
+	k1, k2 := new(Int), NewInt(1)
+	for _, term := range cf {
+		h := new(Int)
+		h.Set(term)
+		h.Mul(h1)
+		h.Add(h2)
+		k := new(Int)
+		k.Set(term)
+		k.Mul(k1)
+		k.Add(k2)
+		convergents = append(convergents, NewRat(h, k))
+		h1, h2 = h, h1
+		k1, k2 = k, k1
+	}
+	return
+}
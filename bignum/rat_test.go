@@ -0,0 +1,93 @@
+package bignum
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestRatCompare(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		a, b, c, d, r int
+	}{
+		{1, 2, 2, 4, 0},
+		{1, 3, 1, 2, -1},
+		{5, 3, 3, 2, 1},
+		{0, 7, 0, 1, 0},
+		{65537, 65536, 1, 1, 1},
+	}
+	for i, testcase := range testcases {
+		x := NewRat(NewInt(testcase.a), NewInt(testcase.b))
+		y := NewRat(NewInt(testcase.c), NewInt(testcase.d))
+		if r := x.Compare(y); r != testcase.r {
+			t.Fatalf("testcase %d expected %d/%d compared to %d/%d to be %d but got %d",
+				i, testcase.a, testcase.b, testcase.c, testcase.d, testcase.r, r)
+		}
+	}
+}
+
+func TestContinuedFraction(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		a, b int
+		cf   []int
+		h, k []int
+	}{
+		// the example of Wiener's attack on wikipedia, e/n with
+		// e = 17993 and n = 90581
+		{17993, 90581, []int{0, 5, 29, 4, 1, 3, 2, 4, 3}, []int{0, 1, 29, 117, 146, 555, 1256, 5579, 17993}, []int{1, 5, 146, 589, 735, 2794, 6323, 28086, 90581}},
+		{415, 93, []int{4, 2, 6, 7}, []int{4, 9, 58, 415}, []int{1, 2, 13, 93}},
+		{7, 1, []int{7}, []int{7}, []int{1}},
+		// 6/4 is not reduced but its convergents are
+		{6, 4, []int{1, 2}, []int{1, 3}, []int{1, 2}},
+	}
+	for i, testcase := range testcases {
+		cf := NewRat(NewInt(testcase.a), NewInt(testcase.b)).ContinuedFraction()
+		if len(cf) != len(testcase.cf) {
+			t.Fatalf("testcase %d expected %d terms but got %d", i, len(testcase.cf), len(cf))
+		}
+		for j := range cf {
+			if cf[j].ToInt() != testcase.cf[j] {
+				t.Fatalf("testcase %d expected term %d to be %d but got %d", i, j, testcase.cf[j], cf[j].ToInt())
+			}
+		}
+		convergents := Convergents(cf)
+		for j, c := range convergents {
+			if c.Num().ToInt() != testcase.h[j] || c.Denom().ToInt() != testcase.k[j] {
+				t.Fatalf("testcase %d expected convergent %d to be %d/%d but got %d/%d",
+					i, j, testcase.h[j], testcase.k[j], c.Num().ToInt(), c.Denom().ToInt())
+			}
+		}
+	}
+}
+
+func TestBigIntContinuedFractionRandoms(t *testing.T) {
+	t.Parallel()
+	for i := 0; i < 5; i++ {
+		// the last convergent of a random fraction is the fraction
+		// reduced to lowest terms, which the stdlib does as well
+		stda, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 512))
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdb, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 512))
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdb.Add(stdb, big.NewInt(1))
+		ref := new(big.Rat).SetFrac(stda, stdb)
+
+		a := new(Int)
+		a.SetBytes(stda.Bytes())
+		b := new(Int)
+		b.SetBytes(stdb.Bytes())
+		convergents := Convergents(NewRat(a, b).ContinuedFraction())
+		last := convergents[len(convergents)-1]
+		num := new(big.Int).SetBytes(last.Num().Bytes())
+		denom := new(big.Int).SetBytes(last.Denom().Bytes())
+		if num.Cmp(ref.Num()) != 0 || denom.Cmp(ref.Denom()) != 0 {
+			t.Fatalf("in iteration %d, expected %s but got %s/%s", i, ref, num, denom)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package bignum
+
+// Root sets bi to its integer k-th root, the largest r such that
+// r^k <= bi, and returns true if the root is exact, that is if
+// r^k == bi.
+//
+// It uses Newton's method on integers. Starting from a power of two
+// larger than the root, each iteration computes
+//
+// r' = ((k-1) * r + bi / r^(k-1)) / k
+//
+// which decreases toward the root and stops decreasing once it is
+// reached.
+func (bi *Int) Root(k int) (exact bool) {
+	if k < 1 {
+		panic("root of order lower than one")
+	}
+	bi.mutate()
+	if k == 1 || bi.len() == 0 {
+		return true
+	}
+	n := new(Int)
+	n.Set(bi)
+	// 2^ceil(bitlen/k) is larger than the root
+	r := new(Int)
+	e := (n.bitLen() + k - 1) / k
+	r.nat = make([]word, e/_W+1)
+	r.nat[e/_W] = 1 << uint(e%_W)
+	kInt := NewInt(k)
+	kMinusOne := NewInt(k - 1)
+	for {
+		t := new(Int)
+		t.Set(n)
+		t.Div(pow(r, k-1))
+		u := new(Int)
+		u.Set(r)
+		u.Mul(kMinusOne)
+		t.Add(u)
+		t.Div(kInt)
+		if t.Compare(r) >= 0 {
+			break
+		}
+		r = t
+	}
+	exact = pow(r, k).Compare(n) == 0
+	bi.Set(r)
+	return
+}
+
+// pow returns x^e for a small exponent e
+func pow(x *Int, e int) *Int {
+	p := NewInt(1)
+	for i := 0; i < e; i++ {
+		p.Mul(x)
+	}
+	return p
+}
+
+// mod returns x mod m, leaving x untouched
+func mod(x, m *Int) *Int {
+	q := new(Int)
+	q.Set(x)
+	return q.Div(m)
+}
+
+// CRT returns the solution of a system of congruences using the
+// Chinese remainder theorem: the unique x lower than the product of
+// the moduli such that x = residues[i] mod moduli[i] for all i.
+//
+// The moduli must be pairwise coprime, CRT panics otherwise. The
+// solution is the sum of residues[i] * Mi * (Mi^-1 mod moduli[i]),
+// where Mi is the product of all moduli but moduli[i], reduced modulo
+// the product of all moduli.
+func CRT(residues, moduli []*Int) *Int {
+	if len(residues) != len(moduli) || len(moduli) == 0 {
+		panic("CRT needs as many residues as moduli, and at least one of each")
+	}
+	product := NewInt(1)
+	for _, m := range moduli {
+		product.Mul(m)
+	}
+	x := new(Int)
+	for i, m := range moduli {
+		mi := new(Int)
+		mi.Set(product)
+		mi.Div(m)
+		inv, err := ModInverse(mi, m)
+		if err != nil {
+			panic("CRT moduli are not pairwise coprime")
+		}
+		term := mod(residues[i], m)
+		term.Mul(inv)
+		term = mod(term, m)
+		term.Mul(mi)
+		x.Add(term)
+	}
+	return mod(x, product)
+}
@@ -0,0 +1,120 @@
+package bignum
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestRoot(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		a, k, root int
+		exact      bool
+	}{
+		{0, 3, 0, true},
+		{1, 3, 1, true},
+		{7, 3, 1, false},
+		{8, 3, 2, true},
+		{9, 2, 3, true},
+		{10, 2, 3, false},
+		{65536, 2, 256, true},
+		{1000000, 3, 100, true},
+		{999999, 3, 99, false},
+		{4611686018427387903, 2, 2147483647, false},
+		{123456789, 1, 123456789, true},
+	}
+	for i, testcase := range testcases {
+		a := NewInt(testcase.a)
+		exact := a.Root(testcase.k)
+		if a.ToInt() != testcase.root || exact != testcase.exact {
+			t.Fatalf("testcase %d expected root %d exact %v but got %d exact %v",
+				i, testcase.root, testcase.exact, a.ToInt(), exact)
+		}
+	}
+}
+
+func TestBigIntRootRandoms(t *testing.T) {
+	t.Parallel()
+	for i, k := range []int{2, 3, 5, 17} {
+		// a random cube, square, etc. of 512 bits, and the same
+		// number plus one which has no exact root
+		stdr, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 512))
+		if err != nil {
+			t.Fatal(err)
+		}
+		stda := new(big.Int).Exp(stdr, big.NewInt(int64(k)), nil)
+		a := new(Int)
+		a.SetBytes(stda.Bytes())
+		if !a.Root(k) || !bytes.Equal(a.Bytes(), stdr.Bytes()) {
+			t.Fatalf("testcase %d expected exact %d-th root %x but got %x", i, k, stdr.Bytes(), a.Bytes())
+		}
+		a.SetBytes(stda.Add(stda, big.NewInt(1)).Bytes())
+		if a.Root(k) || !bytes.Equal(a.Bytes(), stdr.Bytes()) {
+			t.Fatalf("testcase %d expected inexact %d-th root %x but got %x", i, k, stdr.Bytes(), a.Bytes())
+		}
+	}
+}
+
+func TestCRT(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		residues, moduli []int
+		x                int
+	}{
+		{[]int{2, 3, 2}, []int{3, 5, 7}, 23},
+		{[]int{0, 0}, []int{4, 9}, 0},
+		{[]int{1}, []int{10}, 1},
+		{[]int{12, 7}, []int{5, 11}, 7},
+		{[]int{65535, 65536}, []int{65537, 65539}, 2147647488},
+	}
+	for i, testcase := range testcases {
+		var residues, moduli []*Int
+		for j := range testcase.moduli {
+			residues = append(residues, NewInt(testcase.residues[j]))
+			moduli = append(moduli, NewInt(testcase.moduli[j]))
+		}
+		x := CRT(residues, moduli)
+		if x.ToInt() != testcase.x {
+			t.Fatalf("testcase %d expected %d but got %d", i, testcase.x, x.ToInt())
+		}
+		// the inputs are left untouched
+		for j := range moduli {
+			if moduli[j].ToInt() != testcase.moduli[j] || residues[j].ToInt() != testcase.residues[j] {
+				t.Fatalf("testcase %d modified its inputs", i)
+			}
+		}
+	}
+}
+
+func TestBigIntCRTRandoms(t *testing.T) {
+	t.Parallel()
+	var residues, moduli []*Int
+	var stdmoduli []*big.Int
+	for i := 0; i < 3; i++ {
+		p, err := rand.Prime(rand.Reader, 256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdmoduli = append(stdmoduli, p)
+		m := new(Int)
+		m.SetBytes(p.Bytes())
+		moduli = append(moduli, m)
+	}
+	product := new(big.Int).Mul(stdmoduli[0], stdmoduli[1])
+	product.Mul(product, stdmoduli[2])
+	stdx, err := rand.Int(rand.Reader, product)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range stdmoduli {
+		r := new(Int)
+		r.SetBytes(new(big.Int).Mod(stdx, m).Bytes())
+		residues = append(residues, r)
+	}
+	x := CRT(residues, moduli)
+	if !bytes.Equal(x.Bytes(), stdx.Bytes()) {
+		t.Fatalf("expected %x but got %x", stdx.Bytes(), x.Bytes())
+	}
+}
@@ -0,0 +1,199 @@
+package bignum
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+const digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// wordBase returns the largest power of base that fits in a limb, and its
+// exponent: that many digits are converted with a single limb operation
+func wordBase(base int) (power word, n int) {
+	b := word(base)
+	power = b
+	for n = 1; power <= ^word(0)/b; n++ {
+		power *= b
+	}
+	return power, n
+}
+
+// SetString sets bi to the value of s in the given base, from 2 to 36,
+// and returns bi and true. Letters of either case are digits above 9.
+// With base 0 the base is taken from the prefix of s, 0x for 16, 0o for
+// 8 and 0b for 2, and is 10 without prefix. If s is not a number, bi is
+// left unchanged and SetString returns nil and false.
+func (bi *Int) SetString(s string, base int) (*Int, bool) {
+	bi.mutate()
+	if base == 0 {
+		base = 10
+		if len(s) > 2 && s[0] == '0' {
+			switch s[1] {
+			case 'x', 'X':
+				base, s = 16, s[2:]
+			case 'o', 'O':
+				base, s = 8, s[2:]
+			case 'b', 'B':
+				base, s = 2, s[2:]
+			}
+		}
+	}
+	if base < 2 || base > len(digits) || len(s) == 0 {
+		return nil, false
+	}
+	power, n := wordBase(base)
+	x := new(Int)
+	x.nat = []word{}
+	// accumulate n digits in a limb, then x = x * base^n + limb
+	var limb word
+	var count int
+	for i := 0; i < len(s); i++ {
+		d := strings.IndexByte(digits, lower(s[i]))
+		if d < 0 || d >= base {
+			return nil, false
+		}
+		limb = limb*word(base) + word(d)
+		count++
+		if count == n || i == len(s)-1 {
+			m := power
+			if count < n {
+				m = 1
+				for j := 0; j < count; j++ {
+					m *= word(base)
+				}
+			}
+			x.mulAddWord(m, limb)
+			limb, count = 0, 0
+		}
+	}
+	bi.nat = x.nat
+	return bi, true
+}
+
+func lower(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+// mulAddWord sets bi to bi * m + a
+func (bi *Int) mulAddWord(m, a word) {
+	n := len(bi.nat)
+	product := make([]word, n+1)
+	product[n] = addMulVVW(product[:n], bi.nat, m)
+	// bi * m + a < (bi + 1) 2^_W, the carry stops within the limbs
+	addCarry(product, a)
+	bi.nat = product
+	bi.norm()
+}
+
+// divWord divides nat by d and returns the quotient, normalized, and the
+// remainder, one limb at a time from the most significant
+func divWord(nat []word, d word) ([]word, word) {
+	q := make([]word, len(nat))
+	var r word
+	for i := len(nat) - 1; i >= 0; i-- {
+		// the remainder is below d, so the quotient fits in a limb
+		q[i], r = bits.Div(r, nat[i], d)
+	}
+	for len(q) > 0 && q[len(q)-1] == 0 {
+		q = q[:len(q)-1]
+	}
+	return q, r
+}
+
+// Text returns bi in the given base, from 2 to 36, with lower case
+// letters for the digits above 9 and no prefix. It panics if base is out
+// of range.
+func (bi *Int) Text(base int) string {
+	if base < 2 || base > len(digits) {
+		panic("bignum: base out of range")
+	}
+	if bi == nil {
+		return "<nil>"
+	}
+	nat := bi.nat[:bi.len()]
+	if len(nat) == 0 {
+		return "0"
+	}
+	power, n := wordBase(base)
+	// divide by base^n and convert each remainder into n digits, from
+	// the least significant
+	var out []byte
+	for len(nat) > 0 {
+		var r word
+		nat, r = divWord(nat, power)
+		for i := 0; i < n && (len(nat) > 0 || r > 0); i++ {
+			out = append(out, digits[r%word(base)])
+			r /= word(base)
+		}
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// String returns bi in decimal
+func (bi *Int) String() string {
+	return bi.Text(10)
+}
+
+// Format implements fmt.Formatter for the verbs b, o, d, x, X, and v and s
+// which are d. The # flag adds the prefix 0b, 0, 0x or 0X, a precision sets
+// the minimum number of digits, a width pads with spaces on the left, or on
+// the right with the - flag, or with zeros after the prefix with the 0
+// flag.
+func (bi *Int) Format(s fmt.State, ch rune) {
+	var base int
+	var prefix string
+	switch ch {
+	case 'b':
+		base, prefix = 2, "0b"
+	case 'o':
+		base, prefix = 8, "0"
+	case 'd', 's', 'v':
+		base = 10
+	case 'x':
+		base, prefix = 16, "0x"
+	case 'X':
+		base, prefix = 16, "0X"
+	default:
+		fmt.Fprintf(s, "%%!%c(bignum.Int=%s)", ch, bi.String())
+		return
+	}
+	if bi == nil {
+		fmt.Fprint(s, "<nil>")
+		return
+	}
+	text := bi.Text(base)
+	if ch == 'X' {
+		text = strings.ToUpper(text)
+	}
+	if !s.Flag('#') {
+		prefix = ""
+	}
+	if precision, ok := s.Precision(); ok {
+		if precision == 0 && text == "0" {
+			text = ""
+		}
+		if len(text) < precision {
+			text = strings.Repeat("0", precision-len(text)) + text
+		}
+	}
+	padding := 0
+	if width, ok := s.Width(); ok && width > len(prefix)+len(text) {
+		padding = width - len(prefix) - len(text)
+	}
+	_, hasPrecision := s.Precision()
+	switch {
+	case s.Flag('-'):
+		fmt.Fprint(s, prefix, text, strings.Repeat(" ", padding))
+	case s.Flag('0') && !hasPrecision:
+		fmt.Fprint(s, prefix, strings.Repeat("0", padding), text)
+	default:
+		fmt.Fprint(s, strings.Repeat(" ", padding), prefix, text)
+	}
+}
@@ -0,0 +1,114 @@
+package bignum
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestSetString(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		s    string
+		base int
+		hex  string
+	}{
+		{"0", 10, "0x0"},
+		{"65535", 10, "0xffff"},
+		{"65536", 10, "0x010000"},
+		{"18446744073709551617", 10, "0x010000000000000001"},
+		{"DEADbeef", 16, "0xdeadbeef"},
+		{"0xDEADBEEF", 0, "0xdeadbeef"},
+		{"0b101", 0, "0x05"},
+		{"0o777", 0, "0x01ff"},
+		{"0777", 0, "0x0309"},
+		{"zz", 36, "0x050f"},
+		{"00000000000000000000012", 10, "0x0c"},
+	}
+	for i, tc := range testcases {
+		bi, ok := new(Int).SetString(tc.s, tc.base)
+		if !ok {
+			t.Fatalf("testcase %d: %q in base %d was rejected", i, tc.s, tc.base)
+		}
+		if bi.hex() != tc.hex {
+			t.Fatalf("testcase %d expected %s but got %s", i, tc.hex, bi.hex())
+		}
+	}
+	var invalid = []struct {
+		s    string
+		base int
+	}{
+		{"", 10},
+		{"12a", 10},
+		{"-1", 10},
+		{"+1", 10},
+		{"0x", 0},
+		{"102", 2},
+		{"1", 1},
+		{"1", 37},
+		{"1 000", 10},
+	}
+	for i, tc := range invalid {
+		bi := NewInt(7)
+		if _, ok := bi.SetString(tc.s, tc.base); ok {
+			t.Fatalf("testcase %d: %q in base %d was accepted", i, tc.s, tc.base)
+		}
+		if bi.ToInt() != 7 {
+			t.Fatalf("testcase %d: a rejected string changed the value to %d", i, bi.ToInt())
+		}
+	}
+}
+
+// TestTextRandoms converts random numbers back and forth in every base
+// and compares with math/big
+func TestTextRandoms(t *testing.T) {
+	t.Parallel()
+	for i := 0; i < 200; i++ {
+		buf := make([]byte, i)
+		rand.Read(buf)
+		bi := new(Int)
+		bi.SetBytes(buf)
+		expected := new(big.Int).SetBytes(buf)
+		for base := 2; base <= 36; base++ {
+			text := bi.Text(base)
+			if text != expected.Text(base) {
+				t.Fatalf("testcase %d expected %s in base %d but got %s", i, expected.Text(base), base, text)
+			}
+			back, ok := new(Int).SetString(text, base)
+			if !ok || back.Compare(bi) != 0 {
+				t.Fatalf("testcase %d: %s in base %d does not parse back", i, text, base)
+			}
+		}
+	}
+	if NewInt(1234567890).String() != "1234567890" {
+		t.Fatalf("expected 1234567890 but got %s", NewInt(1234567890))
+	}
+}
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+	var formats = []string{
+		"%d", "%v", "%s", "%x", "%X", "%b", "%o",
+		"%#x", "%#X", "%#b", "%#o",
+		"%30d", "%-30d|", "%030d", "%#030x", "%.40x", "%#.40x", "%45.40d", "%.0d",
+	}
+	var values = []string{"0", "1", "255", "340282366920938463463374607431768211457"}
+	for _, v := range values {
+		bi, _ := new(Int).SetString(v, 10)
+		expected, _ := new(big.Int).SetString(v, 10)
+		for _, format := range formats {
+			got, want := fmt.Sprintf(format, bi), fmt.Sprintf(format, expected)
+			if got != want {
+				t.Fatalf("%s of %s: expected %q but got %q", format, v, want, got)
+			}
+		}
+	}
+	if got := fmt.Sprintf("%q", NewInt(3)); got != "%!q(bignum.Int=3)" {
+		t.Fatalf("expected a bad verb error but got %q", got)
+	}
+	var nilInt *Int
+	if got := fmt.Sprintf("%d", nilInt); got != "<nil>" {
+		t.Fatalf("expected <nil> but got %q", got)
+	}
+}
@@ -0,0 +1,36 @@
+//go:build !tinygo
+// +build !tinygo
+
+package bignum
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+func init() {
+	path := os.Getenv(ThresholdsEnv)
+	if path == "" {
+		return
+	}
+	if err := LoadThresholds(path); err != nil {
+		// keep the defaults, a bad tuning file must not stop programs
+		fmt.Fprintf(os.Stderr, "bignum: ignoring $%s: %v\n", ThresholdsEnv, err)
+	}
+}
+
+// LoadThresholds reads thresholds from a JSON file and uses them
+func LoadThresholds(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	// thresholds missing from the file keep their default
+	t := DefaultThresholds
+	if err := json.Unmarshal(data, &t); err != nil {
+		return fmt.Errorf("bignum: invalid thresholds in %s: %v", path, err)
+	}
+	return SetThresholds(t)
+}
@@ -0,0 +1,46 @@
+//go:build !tinygo
+// +build !tinygo
+
+package bignum
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadThresholds(t *testing.T) {
+	// not parallel, the thresholds are global
+	dir := t.TempDir()
+	current := GetThresholds()
+	var testcases = []struct {
+		data  string
+		valid bool
+	}{
+		{`{"karatsuba": 0}`, false},
+		{`{"karatsuba": `, false},
+		{`{"toom": 2}`, false},
+		{`{"montgomery": 0}`, false},
+		// the other thresholds keep their default
+		{fmt.Sprintf(`{"karatsuba": %d}`, current.Karatsuba), true},
+		{fmt.Sprintf(`{"karatsuba": %d, "toom": %d, "montgomery": %d}`, current.Karatsuba, current.Toom, current.Montgomery), true},
+	}
+	for i, tc := range testcases {
+		path := filepath.Join(dir, "thresholds.json")
+		if err := ioutil.WriteFile(path, []byte(tc.data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		// the valid files hold the current thresholds, other tests see no
+		// change
+		if err := LoadThresholds(path); (err == nil) != tc.valid {
+			t.Fatalf("testcase %d expected validity %v but got %v", i, tc.valid, err)
+		}
+		if GetThresholds() != current {
+			t.Fatalf("testcase %d changed the thresholds to %+v", i, GetThresholds())
+		}
+	}
+	if err := LoadThresholds(filepath.Join(dir, "missing.json")); err == nil {
+		t.Fatal("expected a missing file to be an error")
+	}
+}
@@ -0,0 +1,13 @@
+//go:build tinygo
+// +build tinygo
+
+package bignum
+
+import "errors"
+
+// LoadThresholds is not available under TinyGo, which has no filesystem
+// in the browser and limited reflection for encoding/json. Use
+// SetThresholds.
+func LoadThresholds(path string) error {
+	return errors.New("bignum: loading thresholds is not supported with tinygo")
+}
@@ -0,0 +1,115 @@
+package bignum
+
+// toom3 sets bi to bi * x where bi has at least as many limbs as x, with
+// Toom-Cook 3-way multiplication.
+//
+// Both factors are split in three parts of k limbs and read as
+// polynomials of degree 2 in B^k, a(t) = a2*t^2 + a1*t + a0 with
+// B = 2^_W. Their product c(t) has degree 4, so five values determine
+// it: it is evaluated at 0, 1, -1, 2 and infinity, where each value is a
+// product of third size, and interpolated back. That is five third size
+// multiplications instead of the nine of the naive convolution. The
+// parts go back through Mul, which recurses until they fall below the
+// thresholds.
+//
+// The coefficients of a and x are natural numbers, so those of c are as
+// well, and so are the intermediate sums of the interpolation below. Only
+// the value at -1 has a sign, which is kept aside.
+func (bi *Int) toom3(x *Int) {
+	k := (bi.len() + 2) / 3
+	if x.len() <= k {
+		// x is too short to split in three, Karatsuba multiplies each
+		// half of bi by it
+		bi.karatsuba(x)
+		return
+	}
+	a0, a1, a2 := bi.window(0, k), bi.window(k, 2*k), bi.window(2*k, bi.len())
+	b0, b1, b2 := x.window(0, k), x.window(k, 2*k), x.window(2*k, x.len())
+
+	c0 := new(Int)
+	c0.Set(a0)
+	c0.Mul(b0)
+	c4 := new(Int)
+	c4.Set(a2)
+	c4.Mul(b2)
+	v1, vm1, negative, v2 := toomEval(a0, a1, a2)
+	w1, wm1, wNegative, w2 := toomEval(b0, b1, b2)
+	// c(1), c(-1) and c(2)
+	v1.Mul(w1)
+	vm1.Mul(wm1)
+	negative = negative != wNegative
+	v2.Mul(w2)
+
+	// c(1) + c(-1) = 2(c0 + c2 + c4) and c(1) - c(-1) = 2(c1 + c3)
+	even, odd := new(Int), new(Int)
+	even.Set(v1)
+	odd.Set(v1)
+	if negative {
+		even.Sub(vm1)
+		odd.Add(vm1)
+	} else {
+		even.Add(vm1)
+		odd.Sub(vm1)
+	}
+	even.Rsh(1)
+	odd.Rsh(1)
+	c2 := even
+	c2.Sub(c0)
+	c2.Sub(c4)
+	// c(2) = c0 + 2c1 + 4c2 + 8c3 + 16c4, less c0, 4c2 and 16c4, halved,
+	// is c1 + 4c3, and c1 + c3 less gives 3c3
+	c3 := v2
+	c3.Sub(c0)
+	t := new(Int)
+	t.Set(c2)
+	t.Lsh(2)
+	c3.Sub(t)
+	t.Set(c4)
+	t.Lsh(4)
+	c3.Sub(t)
+	c3.Rsh(1)
+	c3.Sub(odd)
+	c3.nat, _ = divWord(c3.nat[:c3.len()], 3)
+	c1 := odd
+	c1.Sub(c3)
+
+	c4.shiftLimbs(4 * k)
+	c3.shiftLimbs(3 * k)
+	c2.shiftLimbs(2 * k)
+	c1.shiftLimbs(k)
+	c4.Add(c3)
+	c4.Add(c2)
+	c4.Add(c1)
+	c4.Add(c0)
+	c4.norm()
+	bi.nat = c4.nat
+}
+
+// toomEval returns the values at 1, -1 and 2 of the polynomial
+// p2*t^2 + p1*t + p0, the value at -1 as a magnitude and a sign
+func toomEval(p0, p1, p2 *Int) (v1, vm1 *Int, negative bool, v2 *Int) {
+	// p0 + p2, shared by the values at 1 and -1
+	even := new(Int)
+	even.Set(p0)
+	even.Add(p2)
+	v1 = new(Int)
+	v1.Set(even)
+	v1.Add(p1)
+	vm1 = new(Int)
+	if even.Compare(p1) < 0 {
+		vm1.Set(p1)
+		vm1.Sub(even)
+		negative = true
+	} else {
+		vm1.Set(even)
+		vm1.Sub(p1)
+	}
+	// (2p2 + p1)*2 + p0
+	v2 = new(Int)
+	v2.Set(p2)
+	v2.Lsh(1)
+	v2.Add(p1)
+	v2.Lsh(1)
+	v2.Add(p0)
+	return v1, vm1, negative, v2
+}
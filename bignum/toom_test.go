@@ -0,0 +1,48 @@
+package bignum
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestToom(t *testing.T) {
+	t.Parallel()
+	one := big.NewInt(1)
+	// 30 limbs of ones, and parts 1, 2^(10*_W) - 1 and 1 of 10 limbs,
+	// whose middle one is larger than the sum of the others, so that the
+	// values at -1 take both signs
+	ones := new(big.Int).Sub(new(big.Int).Lsh(one, 30*_W), one)
+	middle := new(big.Int).Sub(new(big.Int).Lsh(one, 10*_W), one)
+	middle.Lsh(middle, 10*_W).Add(middle, new(big.Int).Lsh(one, 20*_W)).Add(middle, one)
+	random := func(bits int) *big.Int {
+		r, _ := rand.Int(rand.Reader, new(big.Int).Lsh(one, uint(bits)))
+		return r
+	}
+	var testcases = []struct {
+		a, b *big.Int
+	}{
+		{random(3 * _W), random(3 * _W)},
+		{random(512), random(512)},
+		{random(1024), random(1000)},
+		{random(8192), random(8192)},
+		{ones, ones},
+		{middle, ones},
+		{middle, middle},
+		// x too short to split in three
+		{random(4096), random(700)},
+		{random(3000), random(64)},
+	}
+	for i, tc := range testcases {
+		ref := new(big.Int).Mul(tc.a, tc.b)
+		a, b := fromBig(tc.a), fromBig(tc.b)
+		if a.len() < b.len() {
+			a, b = b, a
+		}
+		a.toom3(b)
+		if !bytes.Equal(a.Bytes(), ref.Bytes()) {
+			t.Fatalf("testcase %d expected product\n%x\nbut got\n%x", i, ref.Bytes(), a.Bytes())
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package bignum
+
+// Totient returns φ(n), Euler's totient of the integer n factored into
+// f: the number of integers in [1, n] coprime with n, which is the order
+// of the multiplicative group modulo n. It is the product of
+// p^(k-1) * (p-1) over the prime powers p^k of f. Taking the
+// factorization rather than n lets large n of known factorization, like
+// the p-1 of Diffie-Hellman groups, be used without factoring them.
+func Totient(f Factorization) *Int {
+	phi := NewInt(1)
+	for _, pk := range f {
+		phi.Mul(pow(pk.P, pk.K-1))
+		pm1 := new(Int)
+		pm1.Set(pk.P)
+		pm1.Decrement()
+		phi.Mul(pm1)
+	}
+	return phi
+}
+
+// MultiplicativeOrder returns the order of a modulo n, the smallest k > 0
+// such that a^k = 1 mod n, or nil if a and n are not coprime and no such
+// k exists. n is factored by trial division, see Factorize.
+//
+// The order divides λ(n), so it is found by starting from λ(n) and
+// removing its prime factors one at a time for as long as a raised to
+// the quotient is still 1. A Diffie-Hellman generator must not have a
+// small order, and the factors of the order of a group are what the
+// Pohlig-Hellman attack needs.
+func MultiplicativeOrder(a, n *Int) *Int {
+	if n.Compare(OneValue) == 0 {
+		return NewInt(1)
+	}
+	if Gcd(a, n).Compare(OneValue) != 0 {
+		return nil
+	}
+	return order(a, n, Factorize(Carmichael(n)))
+}
+
+// order returns the order of a modulo n, given the factorization of a
+// multiple m of it
+func order(a, n *Int, m Factorization) *Int {
+	o := m.Int()
+	for _, pk := range m {
+		for k := 0; k < pk.K; k++ {
+			q := new(Int)
+			q.Set(o)
+			q.Div(pk.P)
+			if modExp(a, q, n).Compare(OneValue) != 0 {
+				break
+			}
+			o = q
+		}
+	}
+	return o
+}
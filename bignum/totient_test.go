@@ -0,0 +1,65 @@
+package bignum
+
+import "testing"
+
+func TestTotient(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		n, phi int
+	}{
+		{1, 1},
+		{2, 1},
+		{9, 6},
+		{10, 4},
+		{36, 12},
+		{561, 320},
+		{720, 192},
+		{7919, 7918},
+	}
+	for i, tc := range testcases {
+		f := Factorize(NewInt(tc.n))
+		if f.Int().ToInt() != tc.n {
+			t.Fatalf("testcase %d expected the factorization of %d but got one of %d", i, tc.n, f.Int().ToInt())
+		}
+		if phi := Totient(f); phi.ToInt() != tc.phi {
+			t.Fatalf("testcase %d expected φ(%d) = %d but got %d", i, tc.n, tc.phi, phi.ToInt())
+		}
+	}
+}
+
+func TestMultiplicativeOrder(t *testing.T) {
+	t.Parallel()
+	// compare with the order found by raising a until it reaches 1
+	for n := 1; n < 150; n++ {
+		for a := 0; a < n; a++ {
+			expected := 0
+			if Gcd(NewInt(a), NewInt(n)).ToInt() == 1 {
+				x := 1 % n
+				for expected = 1; (x*a)%n != 1%n; expected++ {
+					x = x * a % n
+				}
+			}
+			o := MultiplicativeOrder(NewInt(a), NewInt(n))
+			if expected == 0 && o != nil {
+				t.Fatalf("expected no order for %d mod %d but got %d", a, n, o.ToInt())
+			}
+			if expected != 0 && (o == nil || o.ToInt() != expected) {
+				t.Fatalf("expected the order of %d mod %d to be %d but got %v", a, n, expected, o)
+			}
+		}
+	}
+	// modulo the safe prime p = 2q + 1, with q = 1048889, 2 generates
+	// the whole group of order 2q and 3 the subgroup of order q
+	var testcases = []struct {
+		a, order int
+	}{
+		{2, 2097778},
+		{3, 1048889},
+		{2097778, 2},
+	}
+	for i, tc := range testcases {
+		if o := MultiplicativeOrder(NewInt(tc.a), NewInt(2097779)); o.ToInt() != tc.order {
+			t.Fatalf("testcase %d expected the order of %d to be %d but got %d", i, tc.a, tc.order, o.ToInt())
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package bignum
+
+import (
+	"fmt"
+	"io"
+)
+
+// traceWriter receives the steps of the traced algorithms, nil disables
+// tracing
+var traceWriter io.Writer
+
+// SetTraceWriter makes long division and modular exponentiation write
+// each of their steps to w, one line per step prefixed with the name of
+// the algorithm, so a worked example can be followed by hand. A nil w
+// turns tracing off, which is the default. Tracing is global and meant
+// for single threaded examples, it must not be switched while other
+// goroutines do arithmetic.
+func SetTraceWriter(w io.Writer) {
+	traceWriter = w
+}
+
+// tracing returns true when steps should be written. Callers check it
+// before formatting arguments, which costs allocations.
+func tracing() bool {
+	return traceWriter != nil
+}
+
+// tracef writes a step of algorithm alg
+func tracef(alg, format string, args ...interface{}) {
+	fmt.Fprintf(traceWriter, alg+": "+format+"\n", args...)
+}
+
+// hex formats bi in hexadecimal for traces
+func (bi *Int) hex() string {
+	if bi.len() == 0 {
+		return "0x0"
+	}
+	return fmt.Sprintf("0x%x", bi.Bytes())
+}
@@ -0,0 +1,57 @@
+package bignum
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// not parallel, the trace writer is global
+func TestTrace(t *testing.T) {
+	var buf bytes.Buffer
+	SetTraceWriter(&buf)
+	defer SetTraceWriter(nil)
+
+	a := NewInt(100)
+	r := a.Div(NewInt(7))
+	if a.ToInt() != 14 || r.ToInt() != 2 {
+		t.Fatalf("expected 100 / 7 = 14 remainder 2 but got %d remainder %d", a.ToInt(), r.ToInt())
+	}
+	b := NewInt(3)
+	b.ModularExponentiation(NewInt(5), NewInt(7))
+	if b.ToInt() != 5 {
+		t.Fatalf("expected 3^5 mod 7 = 5 but got %d", b.ToInt())
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	var testcases = []struct {
+		line int
+		want string
+	}{
+		{0, "div: 0x64 / 0x07, bringing down one bit of the dividend at a time"},
+		// 100 is 0b1100100, the first remainder above 7 is 0b1100
+		{4, "div:   bit 3 is 0: remainder 0x0c >= 0x07, subtract, quotient bit 3 is 1"},
+		{8, "div: quotient 0x0e, remainder 0x02"},
+		{9, "modexp: 0x03 ^ 0x05 mod 0x07, square and multiply over the 3 bits of the exponent"},
+		// 5 is 0b101
+		{10, "modexp:   bit 2 is 1: c = 0x01^2 * 0x03 mod 0x07 = 0x03"},
+		{11, "modexp:   bit 1 is 0: c = 0x03^2 mod 0x07 = 0x02"},
+		{12, "modexp:   bit 0 is 1: c = 0x02^2 * 0x03 mod 0x07 = 0x05"},
+		{13, "modexp: result 0x05"},
+	}
+	if len(lines) != 14 {
+		t.Fatalf("expected 14 trace lines but got %d:\n%s", len(lines), buf.String())
+	}
+	for i, tc := range testcases {
+		if lines[tc.line] != tc.want {
+			t.Fatalf("testcase %d expected line %d to be %q but got %q", i, tc.line, tc.want, lines[tc.line])
+		}
+	}
+
+	buf.Reset()
+	SetTraceWriter(nil)
+	NewInt(100).Div(NewInt(7))
+	if buf.Len() != 0 {
+		t.Fatalf("expected no trace once disabled but got %q", buf.String())
+	}
+}
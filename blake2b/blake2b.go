@@ -0,0 +1,198 @@
+// Package blake2b implements the BLAKE2b hash function of RFC 7693,
+// unkeyed or keyed as a MAC, with digests of 1 to 64 bytes. Argon2 is
+// built on it.
+package blake2b
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+	"math/bits"
+)
+
+const (
+	// Size is the size of a BLAKE2b-512 digest in bytes
+	Size = 64
+	// Size256 is the size of a BLAKE2b-256 digest in bytes
+	Size256 = 32
+	// Size384 is the size of a BLAKE2b-384 digest in bytes
+	Size384 = 48
+	// BlockSize is the block size of BLAKE2b in bytes
+	BlockSize = 128
+)
+
+// iv is the initialization vector, the same as SHA-512's
+var iv = [8]uint64{
+	0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+	0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+}
+
+// sigma is the message word permutation of each round, the last two
+// rounds reuse the first two
+var sigma = [12][16]byte{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+}
+
+type digest struct {
+	h    [8]uint64
+	t    [2]uint64 // bytes compressed so far, 128 bits
+	buf  [BlockSize]byte
+	n    int
+	size int
+	key  [BlockSize]byte
+	kLen int
+}
+
+// New returns a hash.Hash computing BLAKE2b with digests of size bytes,
+// from 1 to 64. A non empty key, of up to 64 bytes, makes it a MAC.
+func New(size int, key []byte) (hash.Hash, error) {
+	if size < 1 || size > Size {
+		return nil, errors.New("blake2b: digest size must be between 1 and 64 bytes")
+	}
+	if len(key) > Size {
+		return nil, errors.New("blake2b: key longer than 64 bytes")
+	}
+	d := &digest{size: size, kLen: len(key)}
+	copy(d.key[:], key)
+	d.Reset()
+	return d, nil
+}
+
+// New512 returns a hash.Hash computing BLAKE2b-512
+func New512() hash.Hash {
+	d, _ := New(Size, nil)
+	return d
+}
+
+// New384 returns a hash.Hash computing BLAKE2b-384
+func New384() hash.Hash {
+	d, _ := New(Size384, nil)
+	return d
+}
+
+// New256 returns a hash.Hash computing BLAKE2b-256
+func New256() hash.Hash {
+	d, _ := New(Size256, nil)
+	return d
+}
+
+// Sum512 returns the BLAKE2b-512 digest of data
+func Sum512(data []byte) [Size]byte {
+	d := New512()
+	d.Write(data)
+	var out [Size]byte
+	d.Sum(out[:0])
+	return out
+}
+
+// Sum256 returns the BLAKE2b-256 digest of data
+func Sum256(data []byte) [Size256]byte {
+	d := New256()
+	d.Write(data)
+	var out [Size256]byte
+	d.Sum(out[:0])
+	return out
+}
+
+func (d *digest) Size() int { return d.size }
+
+func (d *digest) BlockSize() int { return BlockSize }
+
+// Reset loads the parameter block, which only has the digest and key
+// sizes set, and the padded key as first block when there is one
+func (d *digest) Reset() {
+	d.h = iv
+	d.h[0] ^= 0x01010000 ^ uint64(d.kLen)<<8 ^ uint64(d.size)
+	d.t = [2]uint64{}
+	d.n = 0
+	if d.kLen > 0 {
+		d.buf = d.key
+		d.n = BlockSize
+	}
+}
+
+func (d *digest) Write(p []byte) (int, error) {
+	written := len(p)
+	for len(p) > 0 {
+		// the last block is compressed differently, so a full buffer is
+		// only compressed once more data shows up
+		if d.n == BlockSize {
+			d.compress(false)
+			d.n = 0
+		}
+		c := copy(d.buf[d.n:], p)
+		d.n += c
+		p = p[c:]
+	}
+	return written, nil
+}
+
+func (d *digest) Sum(in []byte) []byte {
+	// work on a copy, so the caller can keep writing
+	d0 := *d
+	for i := d0.n; i < BlockSize; i++ {
+		d0.buf[i] = 0
+	}
+	d0.compress(true)
+	var out [Size]byte
+	for i, h := range d0.h {
+		binary.LittleEndian.PutUint64(out[8*i:], h)
+	}
+	return append(in, out[:d.size]...)
+}
+
+// compress mixes the buffer, holding d.n bytes, into the state
+func (d *digest) compress(last bool) {
+	d.t[0] += uint64(d.n)
+	if d.t[0] < uint64(d.n) {
+		d.t[1]++
+	}
+	var m [16]uint64
+	for i := range m {
+		m[i] = binary.LittleEndian.Uint64(d.buf[8*i:])
+	}
+	var v [16]uint64
+	copy(v[:8], d.h[:])
+	copy(v[8:], iv[:])
+	v[12] ^= d.t[0]
+	v[13] ^= d.t[1]
+	if last {
+		v[14] = ^v[14]
+	}
+	for _, s := range sigma {
+		g(&v, 0, 4, 8, 12, m[s[0]], m[s[1]])
+		g(&v, 1, 5, 9, 13, m[s[2]], m[s[3]])
+		g(&v, 2, 6, 10, 14, m[s[4]], m[s[5]])
+		g(&v, 3, 7, 11, 15, m[s[6]], m[s[7]])
+		g(&v, 0, 5, 10, 15, m[s[8]], m[s[9]])
+		g(&v, 1, 6, 11, 12, m[s[10]], m[s[11]])
+		g(&v, 2, 7, 8, 13, m[s[12]], m[s[13]])
+		g(&v, 3, 4, 9, 14, m[s[14]], m[s[15]])
+	}
+	for i := range d.h {
+		d.h[i] ^= v[i] ^ v[i+8]
+	}
+}
+
+// g is the mixing function of RFC 7693 section 3.1
+func g(v *[16]uint64, a, b, c, d int, x, y uint64) {
+	v[a] += v[b] + x
+	v[d] = bits.RotateLeft64(v[d]^v[a], -32)
+	v[c] += v[d]
+	v[b] = bits.RotateLeft64(v[b]^v[c], -24)
+	v[a] += v[b] + y
+	v[d] = bits.RotateLeft64(v[d]^v[a], -16)
+	v[c] += v[d]
+	v[b] = bits.RotateLeft64(v[b]^v[c], -63)
+}
@@ -0,0 +1,112 @@
+package blake2b
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// sequence returns the bytes 0, 1, 2, ... wrapping at 256
+func sequence(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return b
+}
+
+func TestVectors(t *testing.T) {
+	t.Parallel()
+	// RFC 7693 appendix A for "abc", the others from the reference
+	// implementation
+	var testcases = []struct {
+		input       string
+		expected512 string
+		expected256 string
+	}{
+		{"", "786a02f742015903c6c6fd852552d272912f4740e15847618a86e217f71f5419d25e1031afee585313896444934eb04b903a685b1448b755d56f701afe9be2ce", "0e5751c026e543b2e8ab2eb06099daa1d1e5df47778f7787faab45cdf12fe3a8"},
+		{"abc", "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923", "bddd813c634239723171ef3fee98579b94964e3bb1cb3e427262c8c068d52319"},
+		// exactly one block, and one byte more
+		{strings.Repeat("a", 128), "fc6c71f688f43ea7d60817478808f3cac753e61571865c95adbc2d9122c943a76b92c2cb1047ef3fe7bf6e436ec1d0a99a9e5b216780bf7fed9d7ca91d3a8f3b", "ae2aa48507885c4c950fb809b2076f959cde9f8ea6da260d9a3587df33dac450"},
+		{strings.Repeat("a", 129), "55e6e0eb418149a8af92fd9ddc99254781b2f522a131b4f4d984404b71a00e1167b8124d5dcddd4c6977b299392335d6edd303da6d344d74bbef2d38101b232b", "2f64744a6de0d2c0b56e64cf6e29a5aaa255010d415d51c75ccc82f73dccd865"},
+		{string(sequence(1280)), "a86b784c748f990b998e6d30d71e20cc95228d2b08dd85e29f63e4de8d8839bdf935f4291537af5014fe44c0b578a073e4c9217c7b05542d0c450784c30bac8a", "82628cbfc9689e234b0923a531f4578fe2e7138a03e2f81ed6cde97517336650"},
+	}
+	for i, tc := range testcases {
+		sum := Sum512([]byte(tc.input))
+		if hex.EncodeToString(sum[:]) != tc.expected512 {
+			t.Fatalf("testcase %d expected %s but got %x", i, tc.expected512, sum)
+		}
+		sum256 := Sum256([]byte(tc.input))
+		if hex.EncodeToString(sum256[:]) != tc.expected256 {
+			t.Fatalf("testcase %d expected %s but got %x", i, tc.expected256, sum256)
+		}
+		// writing in odd sized pieces gives the same digest
+		h := New512()
+		for in := []byte(tc.input); len(in) > 0; {
+			n := 7
+			if n > len(in) {
+				n = len(in)
+			}
+			h.Write(in[:n])
+			in = in[n:]
+		}
+		if hex.EncodeToString(h.Sum(nil)) != tc.expected512 {
+			t.Fatalf("testcase %d expected %s in pieces but got %x", i, tc.expected512, h.Sum(nil))
+		}
+	}
+}
+
+func TestKeyed(t *testing.T) {
+	t.Parallel()
+	// from blake2b-kat.txt of the reference implementation, with the key
+	// 00 01 .. 3f
+	var testcases = []struct {
+		size     int
+		input    []byte
+		expected string
+	}{
+		{64, nil, "10ebb67700b1868efb4417987acf4690ae9d972fb7a590c2f02871799aaa4786b5e996e8f0f4eb981fc214b005f42d2ff4233499391653df7aefcbc13fc51568"},
+		{64, sequence(255), "142709d62e28fcccd0af97fad0f8465b971e82201dc51070faa0372aa43e92484be1c1e73ba10906d5d1853db6a4106e0a7bf9800d373d6dee2d46d62ef2a461"},
+	}
+	for i, tc := range testcases {
+		h, err := New(tc.size, sequence(64))
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.Write(tc.input)
+		if hex.EncodeToString(h.Sum(nil)) != tc.expected {
+			t.Fatalf("testcase %d expected %s but got %x", i, tc.expected, h.Sum(nil))
+		}
+		// Reset starts over with the key
+		h.Reset()
+		h.Write(tc.input)
+		if hex.EncodeToString(h.Sum(nil)) != tc.expected {
+			t.Fatalf("testcase %d expected %s after Reset but got %x", i, tc.expected, h.Sum(nil))
+		}
+	}
+}
+
+func TestSizes(t *testing.T) {
+	t.Parallel()
+	h, err := New(20, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Write([]byte("abc"))
+	if expected := "384264f676f39536840523f284921cdc68b6846b"; hex.EncodeToString(h.Sum(nil)) != expected {
+		t.Fatalf("expected %s but got %x", expected, h.Sum(nil))
+	}
+	h = New384()
+	h.Write([]byte(strings.Repeat("a", 129)))
+	if expected := "d7a9fb931381834c12884bca2b81e183dc6a2e3f8633c9f0c9622cee4c7c725b688f30a62189129f2329bf9d11172360"; hex.EncodeToString(h.Sum(nil)) != expected {
+		t.Fatalf("expected %s but got %x", expected, h.Sum(nil))
+	}
+	for _, size := range []int{0, 65} {
+		if _, err := New(size, nil); err == nil {
+			t.Fatalf("expected a %d bytes digest to be rejected", size)
+		}
+	}
+	if _, err := New(64, make([]byte, 65)); err == nil {
+		t.Fatal("expected a 65 bytes key to be rejected")
+	}
+}
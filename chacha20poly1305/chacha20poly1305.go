@@ -0,0 +1,239 @@
+// Package chacha20poly1305 implements the ChaCha20-Poly1305 AEAD of
+// RFC 8439 behind the standard cipher.AEAD interface. Poly1305 is
+// computed with math/big to stay close to the math of the RFC, which
+// makes it slow and not constant time. NewX returns the XChaCha20-Poly1305
+// variant, whose 24 bytes nonces can be picked at random.
+//
+// Under TinyGo, where every math/big allocation weighs on a small garbage
+// collector, Poly1305 is computed on 64 bits limbs instead, without
+// allocating. The tinygo build tag, which TinyGo sets, selects it.
+package chacha20poly1305
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"math/bits"
+)
+
+const (
+	// KeySize is the size of the key used by this AEAD, in bytes
+	KeySize = 32
+	// NonceSize is the size of the nonce used with this AEAD, in bytes
+	NonceSize = 12
+	// Overhead is the size of the Poly1305 authentication tag, in bytes
+	Overhead = 16
+)
+
+type chacha20poly1305 struct {
+	key [KeySize]byte
+}
+
+// New returns a ChaCha20-Poly1305 AEAD that uses the given 256-bit key
+func New(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, errors.New("chacha20poly1305: bad key length")
+	}
+	c := new(chacha20poly1305)
+	copy(c.key[:], key)
+	return c, nil
+}
+
+func (c *chacha20poly1305) NonceSize() int { return NonceSize }
+
+func (c *chacha20poly1305) Overhead() int { return Overhead }
+
+// Seal encrypts and authenticates plaintext, authenticates the
+// additional data and appends the result to dst
+func (c *chacha20poly1305) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != NonceSize {
+		panic("chacha20poly1305: bad nonce length passed to Seal")
+	}
+	// the one time poly1305 key is the first 32 bytes of block zero
+	var polyKey [64]byte
+	block(&polyKey, &c.key, 0, nonce)
+
+	ret, out := sliceForAppend(dst, len(plaintext)+Overhead)
+	XORKeyStream(out[:len(plaintext)], plaintext, c.key[:], nonce, 1)
+	tag := poly1305(polyKey[:32], macData(additionalData, out[:len(plaintext)]))
+	copy(out[len(plaintext):], tag[:])
+	return ret
+}
+
+// Open authenticates and decrypts ciphertext, authenticates the
+// additional data and, if successful, appends the plaintext to dst
+func (c *chacha20poly1305) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != NonceSize {
+		panic("chacha20poly1305: bad nonce length passed to Open")
+	}
+	if len(ciphertext) < Overhead {
+		return nil, errors.New("chacha20poly1305: message authentication failed")
+	}
+	var polyKey [64]byte
+	block(&polyKey, &c.key, 0, nonce)
+
+	ct, tag := ciphertext[:len(ciphertext)-Overhead], ciphertext[len(ciphertext)-Overhead:]
+	expected := poly1305(polyKey[:32], macData(additionalData, ct))
+	if subtle.ConstantTimeCompare(expected[:], tag) != 1 {
+		return nil, errors.New("chacha20poly1305: message authentication failed")
+	}
+	ret, out := sliceForAppend(dst, len(ct))
+	XORKeyStream(out, ct, c.key[:], nonce, 1)
+	return ret, nil
+}
+
+// macData builds the input of poly1305: the additional data and the
+// ciphertext, each padded to 16 bytes, followed by their lengths
+func macData(ad, ct []byte) []byte {
+	pad := func(n int) int { return (16 - n%16) % 16 }
+	buf := make([]byte, 0, len(ad)+pad(len(ad))+len(ct)+pad(len(ct))+16)
+	buf = append(buf, ad...)
+	buf = append(buf, make([]byte, pad(len(ad)))...)
+	buf = append(buf, ct...)
+	buf = append(buf, make([]byte, pad(len(ct)))...)
+	var lens [16]byte
+	binary.LittleEndian.PutUint64(lens[:8], uint64(len(ad)))
+	binary.LittleEndian.PutUint64(lens[8:], uint64(len(ct)))
+	return append(buf, lens[:]...)
+}
+
+// sliceForAppend extends in by n bytes, reusing its capacity if possible
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
+
+// quarterRound is the ChaCha quarter round of RFC 8439 section 2.1
+func quarterRound(a, b, c, d uint32) (uint32, uint32, uint32, uint32) {
+	a += b
+	d = bits.RotateLeft32(d^a, 16)
+	c += d
+	b = bits.RotateLeft32(b^c, 12)
+	a += b
+	d = bits.RotateLeft32(d^a, 8)
+	c += d
+	b = bits.RotateLeft32(b^c, 7)
+	return a, b, c, d
+}
+
+// block computes the ChaCha20 block function of RFC 8439 section 2.3
+// for the given key, counter and 12 bytes nonce
+func block(out *[64]byte, key *[KeySize]byte, counter uint32, nonce []byte) {
+	var s [16]uint32
+	// "expand 32-byte k"
+	s[0], s[1], s[2], s[3] = 0x61707865, 0x3320646e, 0x79622d32, 0x6b206574
+	for i := 0; i < 8; i++ {
+		s[4+i] = binary.LittleEndian.Uint32(key[i*4:])
+	}
+	s[12] = counter
+	s[13] = binary.LittleEndian.Uint32(nonce[0:])
+	s[14] = binary.LittleEndian.Uint32(nonce[4:])
+	s[15] = binary.LittleEndian.Uint32(nonce[8:])
+
+	x := s
+	rounds(&x)
+	for i := range x {
+		binary.LittleEndian.PutUint32(out[i*4:], x[i]+s[i])
+	}
+}
+
+// rounds applies the 20 rounds of ChaCha20 to the state x
+func rounds(x *[16]uint32) {
+	for i := 0; i < 10; i++ {
+		// column rounds
+		x[0], x[4], x[8], x[12] = quarterRound(x[0], x[4], x[8], x[12])
+		x[1], x[5], x[9], x[13] = quarterRound(x[1], x[5], x[9], x[13])
+		x[2], x[6], x[10], x[14] = quarterRound(x[2], x[6], x[10], x[14])
+		x[3], x[7], x[11], x[15] = quarterRound(x[3], x[7], x[11], x[15])
+		// diagonal rounds
+		x[0], x[5], x[10], x[15] = quarterRound(x[0], x[5], x[10], x[15])
+		x[1], x[6], x[11], x[12] = quarterRound(x[1], x[6], x[11], x[12])
+		x[2], x[7], x[8], x[13] = quarterRound(x[2], x[7], x[8], x[13])
+		x[3], x[4], x[9], x[14] = quarterRound(x[3], x[4], x[9], x[14])
+	}
+}
+
+// XORKeyStream encrypts src into dst with the raw ChaCha20 stream cipher,
+// starting at block counter. dst and src may overlap entirely.
+func XORKeyStream(dst, src, key, nonce []byte, counter uint32) {
+	if len(key) != KeySize || len(nonce) != NonceSize {
+		panic("chacha20poly1305: bad key or nonce length")
+	}
+	var (
+		k  [KeySize]byte
+		ks [64]byte
+	)
+	copy(k[:], key)
+	for len(src) > 0 {
+		block(&ks, &k, counter, nonce)
+		counter++
+		n := len(src)
+		if n > 64 {
+			n = 64
+		}
+		for i := 0; i < n; i++ {
+			dst[i] = src[i] ^ ks[i]
+		}
+		dst, src = dst[n:], src[n:]
+	}
+}
+
+// p1305 is the poly1305 prime 2^130 - 5
+var p1305 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 130), big.NewInt(5))
+
+// poly1305Big computes the one time authenticator of RFC 8439 section
+// 2.5. Each 16 bytes block of msg, with a one byte appended, is added to
+// the accumulator which is then multiplied by r, modulo 2^130 - 5. The key
+// half s is added at the end.
+func poly1305Big(key, msg []byte) (tag [16]byte) {
+	rb := make([]byte, 16)
+	copy(rb, key[:16])
+	// clamp r
+	rb[3] &= 15
+	rb[7] &= 15
+	rb[11] &= 15
+	rb[15] &= 15
+	rb[4] &= 252
+	rb[8] &= 252
+	rb[12] &= 252
+	r := leInt(rb)
+	s := leInt(key[16:32])
+
+	acc := new(big.Int)
+	n := new(big.Int)
+	for len(msg) > 0 {
+		l := 16
+		if len(msg) < 16 {
+			l = len(msg)
+		}
+		chunk := append(append([]byte{}, msg[:l]...), 1)
+		n.Set(leInt(chunk))
+		acc.Add(acc, n)
+		acc.Mul(acc, r)
+		acc.Mod(acc, p1305)
+		msg = msg[l:]
+	}
+	acc.Add(acc, s)
+	out := acc.Bytes()
+	// keep the lower 128 bits, little endian
+	for i := 0; i < 16 && i < len(out); i++ {
+		tag[i] = out[len(out)-1-i]
+	}
+	return
+}
+
+func leInt(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	return new(big.Int).SetBytes(be)
+}
@@ -0,0 +1,162 @@
+package chacha20poly1305
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/jvehent/badcrypto/testvectors"
+)
+
+func TestBlockRFC8439(t *testing.T) {
+	t.Parallel()
+	// test vector from RFC 8439 section 2.3.2
+	var key [KeySize]byte
+	copy(key[:], mustHex("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"))
+	var out [64]byte
+	block(&out, &key, 1, mustHex("000000090000004a00000000"))
+	expected := "10f1e7e4d13b5915500fdd1fa32071c4c7d1f4c733c068030422aa9ac3d46c4e" +
+		"d2826446079faa0914c2d705d98b02a2b5129cd1de164eb9cbd083e8a2503c4e"
+	if hex.EncodeToString(out[:]) != expected {
+		t.Fatalf("unexpected block\nexp %s\ngot %x", expected, out)
+	}
+}
+
+func TestPoly1305RFC8439(t *testing.T) {
+	t.Parallel()
+	// test vector from RFC 8439 section 2.5.2
+	key := mustHex("85d6be7857556d337f4452fe42d506a80103808afb0db2fd4abff6af4149f51b")
+	tag := poly1305(key, []byte("Cryptographic Forum Research Group"))
+	if hex.EncodeToString(tag[:]) != "a8061dc1305136c6c22b8baf0c0127a9" {
+		t.Fatalf("unexpected tag %x", tag)
+	}
+}
+
+func TestPoly1305Limbs(t *testing.T) {
+	t.Parallel()
+	key := mustHex("85d6be7857556d337f4452fe42d506a80103808afb0db2fd4abff6af4149f51b")
+	tag := poly1305Limbs(key, []byte("Cryptographic Forum Research Group"))
+	if hex.EncodeToString(tag[:]) != "a8061dc1305136c6c22b8baf0c0127a9" {
+		t.Fatalf("unexpected tag %x", tag)
+	}
+	// all ones keys and messages keep the accumulator close to 2^130
+	ones := bytes.Repeat([]byte{0xff}, 1000)
+	for i := 0; i < 200; i++ {
+		key := make([]byte, 32)
+		msg := make([]byte, i%70)
+		if i%2 == 0 {
+			rand.Read(key)
+			rand.Read(msg)
+		} else {
+			copy(key, ones)
+			copy(msg, ones)
+		}
+		if i >= 190 {
+			msg = ones[:i*5]
+		}
+		if big, limbs := poly1305Big(key, msg), poly1305Limbs(key, msg); big != limbs {
+			t.Fatalf("testcase %d expected %x but got %x", i, big, limbs)
+		}
+	}
+}
+
+func TestAEADRFC8439(t *testing.T) {
+	t.Parallel()
+	// test vector from RFC 8439 section 2.8.2
+	key := mustHex("808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f")
+	nonce := mustHex("070000004041424344454647")
+	aad := mustHex("50515253c0c1c2c3c4c5c6c7")
+	plaintext := []byte("Ladies and Gentlemen of the class of '99: If I could offer you only one tip for the future, sunscreen would be it.")
+	expected := "d31a8d34648e60db7b86afbc53ef7ec2a4aded51296e08fea9e2b5a736ee62d6" +
+		"3dbea45e8ca9671282fafb69da92728b1a71de0a9e060b2905d6a5b67ecd3b36" +
+		"92ddbd7f2d778b8c9803aee328091b58fab324e4fad675945585808b4831d7bc" +
+		"3ff4def08e4b7a9de576d26586cec64b6116" +
+		"1ae10b594f09e26a7e902ecbd0600691"
+	aead, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext := aead.Seal(nil, nonce, plaintext, aad)
+	if hex.EncodeToString(ciphertext) != expected {
+		t.Fatalf("unexpected ciphertext\nexp %s\ngot %x", expected, ciphertext)
+	}
+	decrypted, err := aead.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Fatalf("decrypted %q", decrypted)
+	}
+	ciphertext[0] ^= 1
+	if _, err := aead.Open(nil, nonce, ciphertext, aad); err == nil {
+		t.Fatal("expected a modified ciphertext to fail authentication")
+	}
+}
+
+func TestSealInPlace(t *testing.T) {
+	t.Parallel()
+	aead, err := New(make([]byte, KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, NonceSize)
+	for _, size := range []int{0, 1, 63, 64, 65, 1000} {
+		plaintext := bytes.Repeat([]byte{0x42}, size)
+		buf := make([]byte, size, size+Overhead)
+		copy(buf, plaintext)
+		sealed := aead.Seal(buf[:0], nonce, buf, nil)
+		opened, err := aead.Open(sealed[:0], nonce, sealed, nil)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		if !bytes.Equal(opened, plaintext) {
+			t.Fatalf("size %d: in place round trip failed", size)
+		}
+	}
+}
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestWycheproof(t *testing.T) {
+	t.Parallel()
+	var f testvectors.AEADFile
+	if err := testvectors.LoadWycheproof("testdata/chacha20_poly1305_test.json", &f); err != nil {
+		t.Fatal(err)
+	}
+	err := f.ForEach(func(g *testvectors.AEADGroup, tc *testvectors.AEADTest) error {
+		if g.IVSize != 8*NonceSize || g.TagSize != 8*Overhead {
+			// Seal and Open panic on other nonce sizes, skip those groups
+			return nil
+		}
+		aead, err := New(tc.Key)
+		if err != nil {
+			return fmt.Errorf("%s: %v", tc, err)
+		}
+		ciphertext := append(append([]byte{}, tc.Ct...), tc.Tag...)
+		plaintext, err := aead.Open(nil, tc.IV, ciphertext, tc.AAD)
+		if valid := tc.Result != testvectors.Invalid; valid != (err == nil) {
+			return fmt.Errorf("%s: unexpected Open result %v", tc, err)
+		}
+		if tc.Result == testvectors.Invalid {
+			return nil
+		}
+		if !bytes.Equal(plaintext, tc.Msg) {
+			return fmt.Errorf("%s: decrypted %x", tc, plaintext)
+		}
+		if sealed := aead.Seal(nil, tc.IV, tc.Msg, tc.AAD); !bytes.Equal(sealed, ciphertext) {
+			return fmt.Errorf("%s: sealed %x", tc, sealed)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,86 @@
+package chacha20poly1305
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// uint128 is the result of a 64x64 bits multiplication
+type uint128 struct {
+	lo, hi uint64
+}
+
+func mul64(a, b uint64) uint128 {
+	hi, lo := bits.Mul64(a, b)
+	return uint128{lo, hi}
+}
+
+func add128(a, b uint128) uint128 {
+	lo, c := bits.Add64(a.lo, b.lo, 0)
+	hi, _ := bits.Add64(a.hi, b.hi, c)
+	return uint128{lo, hi}
+}
+
+// poly1305Limbs computes the same authenticator as poly1305Big, with
+// the accumulator h in three 64 bits limbs h2:h1:h0 and r in two. h is
+// only partially reduced between blocks, h2 stays below 8, and fully
+// reduced at the end.
+func poly1305Limbs(key, msg []byte) (tag [16]byte) {
+	// clamp r, as poly1305Big does byte by byte
+	r0 := binary.LittleEndian.Uint64(key[0:8]) & 0x0ffffffc0fffffff
+	r1 := binary.LittleEndian.Uint64(key[8:16]) & 0x0ffffffc0ffffffc
+	var h0, h1, h2, c uint64
+	for len(msg) > 0 {
+		if len(msg) >= 16 {
+			h0, c = bits.Add64(h0, binary.LittleEndian.Uint64(msg[0:8]), 0)
+			h1, c = bits.Add64(h1, binary.LittleEndian.Uint64(msg[8:16]), c)
+			// the one byte appended to a full block is bit 128
+			h2 += c + 1
+			msg = msg[16:]
+		} else {
+			var buf [16]byte
+			copy(buf[:], msg)
+			buf[len(msg)] = 1
+			h0, c = bits.Add64(h0, binary.LittleEndian.Uint64(buf[0:8]), 0)
+			h1, c = bits.Add64(h1, binary.LittleEndian.Uint64(buf[8:16]), c)
+			h2 += c
+			msg = nil
+		}
+
+		// m = h * r, in four limbs m3:m2:m1:m0. h2 is small and r is
+		// clamped below 2^124, so h2*r0 and h2*r1 fit in 64 bits.
+		m0 := mul64(h0, r0)
+		m1 := add128(mul64(h1, r0), mul64(h0, r1))
+		m2 := add128(mul64(h1, r1), uint128{h2 * r0, 0})
+		m3 := h2 * r1
+		t0 := m0.lo
+		t1, c := bits.Add64(m1.lo, m0.hi, 0)
+		t2, c := bits.Add64(m2.lo, m1.hi, c)
+		t3, _ := bits.Add64(m3, m2.hi, c)
+
+		// 2^130 = 5 mod p, so h = (m mod 2^130) + 5 * (m >> 130), added
+		// as 4 * (m >> 130) then m >> 130
+		h0, h1, h2 = t0, t1, t2&3
+		cc := uint128{t2 &^ 3, t3}
+		h0, c = bits.Add64(h0, cc.lo, 0)
+		h1, c = bits.Add64(h1, cc.hi, c)
+		h2 += c
+		cc = uint128{cc.lo>>2 | cc.hi<<62, cc.hi >> 2}
+		h0, c = bits.Add64(h0, cc.lo, 0)
+		h1, c = bits.Add64(h1, cc.hi, c)
+		h2 += c
+	}
+
+	// h is below 2p, subtract p unless that borrows
+	t0, b := bits.Sub64(h0, 0xfffffffffffffffb, 0)
+	t1, b := bits.Sub64(h1, 0xffffffffffffffff, b)
+	_, b = bits.Sub64(h2, 3, b)
+	if b == 0 {
+		h0, h1 = t0, t1
+	}
+	h0, c = bits.Add64(h0, binary.LittleEndian.Uint64(key[16:24]), 0)
+	h1, _ = bits.Add64(h1, binary.LittleEndian.Uint64(key[24:32]), c)
+	binary.LittleEndian.PutUint64(tag[0:8], h0)
+	binary.LittleEndian.PutUint64(tag[8:16], h1)
+	return
+}
@@ -0,0 +1,82 @@
+package chacha20poly1305
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+)
+
+// NonceSizeX is the size of the nonce used with XChaCha20-Poly1305, in
+// bytes. It is large enough to be picked at random for every message.
+const NonceSizeX = 24
+
+type xchacha20poly1305 struct {
+	key [KeySize]byte
+}
+
+// NewX returns an XChaCha20-Poly1305 AEAD that uses the given 256-bit
+// key. It is ChaCha20-Poly1305 with a 24 bytes nonce, following
+// draft-irtf-cfrg-xchacha: the first 16 bytes of the nonce derive a
+// subkey with HChaCha20, and the last 8 form the nonce used with it.
+func NewX(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, errors.New("chacha20poly1305: bad key length")
+	}
+	c := new(xchacha20poly1305)
+	copy(c.key[:], key)
+	return c, nil
+}
+
+func (c *xchacha20poly1305) NonceSize() int { return NonceSizeX }
+
+func (c *xchacha20poly1305) Overhead() int { return Overhead }
+
+// inner returns the ChaCha20-Poly1305 AEAD and nonce for a 24 bytes nonce
+func (c *xchacha20poly1305) inner(nonce []byte) (*chacha20poly1305, []byte) {
+	subkey := HChaCha20(c.key[:], nonce[:16])
+	inner := &chacha20poly1305{key: subkey}
+	n := make([]byte, NonceSize)
+	copy(n[4:], nonce[16:])
+	return inner, n
+}
+
+func (c *xchacha20poly1305) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != NonceSizeX {
+		panic("chacha20poly1305: bad nonce length passed to Seal")
+	}
+	inner, n := c.inner(nonce)
+	return inner.Seal(dst, n, plaintext, additionalData)
+}
+
+func (c *xchacha20poly1305) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != NonceSizeX {
+		panic("chacha20poly1305: bad nonce length passed to Open")
+	}
+	inner, n := c.inner(nonce)
+	return inner.Open(dst, n, ciphertext, additionalData)
+}
+
+// HChaCha20 derives a subkey from a key and a 16 bytes nonce: the
+// ChaCha20 rounds run on a state made of the key and the nonce, and the
+// first and last rows of the result, without the final addition of the
+// input, are the subkey
+func HChaCha20(key, nonce []byte) [KeySize]byte {
+	if len(key) != KeySize || len(nonce) != 16 {
+		panic("chacha20poly1305: bad key or nonce length")
+	}
+	var x [16]uint32
+	x[0], x[1], x[2], x[3] = 0x61707865, 0x3320646e, 0x79622d32, 0x6b206574
+	for i := 0; i < 8; i++ {
+		x[4+i] = binary.LittleEndian.Uint32(key[i*4:])
+	}
+	for i := 0; i < 4; i++ {
+		x[12+i] = binary.LittleEndian.Uint32(nonce[i*4:])
+	}
+	rounds(&x)
+	var out [KeySize]byte
+	for i := 0; i < 4; i++ {
+		binary.LittleEndian.PutUint32(out[i*4:], x[i])
+		binary.LittleEndian.PutUint32(out[16+i*4:], x[12+i])
+	}
+	return out
+}
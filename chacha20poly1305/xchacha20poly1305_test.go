@@ -0,0 +1,58 @@
+package chacha20poly1305
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestHChaCha20(t *testing.T) {
+	t.Parallel()
+	// test vector from draft-irtf-cfrg-xchacha-03 section 2.2.1
+	key := mustHex("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	nonce := mustHex("000000090000004a0000000031415927")
+	expected := "82413b4227b27bfed30e42508a877d73a0f9e4d58a74a853c12ec41326d3ecdc"
+	if subkey := HChaCha20(key, nonce); hex.EncodeToString(subkey[:]) != expected {
+		t.Fatalf("expected subkey %s but got %x", expected, subkey)
+	}
+}
+
+func TestAEADXChaCha(t *testing.T) {
+	t.Parallel()
+	// test vector from draft-irtf-cfrg-xchacha-03 appendix A.3.1
+	key := mustHex("808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f")
+	nonce := mustHex("404142434445464748494a4b4c4d4e4f5051525354555657")
+	aad := mustHex("50515253c0c1c2c3c4c5c6c7")
+	plaintext := []byte("Ladies and Gentlemen of the class of '99: If I could offer you only one tip for the future, sunscreen would be it.")
+	expected := "bd6d179d3e83d43b9576579493c0e939572a1700252bfaccbed2902c21396cbb" +
+		"731c7f1b0b4aa6440bf3a82f4eda7e39ae64c6708c54c216cb96b72e1213b452" +
+		"2f8c9ba40db5d945b11b69b982c1bb9e3f3fac2bc369488f76b2383565d3fff9" +
+		"21f9664c97637da9768812f615c68b13b52e" +
+		"c0875924c1c7987947deafd8780acf49"
+	aead, err := NewX(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aead.NonceSize() != NonceSizeX || aead.Overhead() != Overhead {
+		t.Fatalf("unexpected nonce size %d or overhead %d", aead.NonceSize(), aead.Overhead())
+	}
+	ciphertext := aead.Seal(nil, nonce, plaintext, aad)
+	if hex.EncodeToString(ciphertext) != expected {
+		t.Fatalf("unexpected ciphertext\nexp %s\ngot %x", expected, ciphertext)
+	}
+	decrypted, err := aead.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Fatalf("decrypted %q", decrypted)
+	}
+	// the first 16 bytes of the nonce select the subkey
+	nonce[0] ^= 1
+	if _, err := aead.Open(nil, nonce, ciphertext, aad); err == nil {
+		t.Fatal("expected a modified nonce to fail authentication")
+	}
+	if _, err := NewX(key[:16]); err == nil {
+		t.Fatal("expected a short key to be rejected")
+	}
+}
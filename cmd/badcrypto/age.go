@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jvehent/badcrypto/agefile"
+)
+
+// stringList is a repeatable string flag
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+func encrypt(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("encrypt", "(-r age1... | -p) [-o file.age] [file]")
+	var recipients stringList
+	fs.Var(&recipients, "r", "age recipient, can be repeated")
+	withPassphrase := fs.Bool("p", false, "encrypt with the passphrase in $"+passphraseEnv)
+	workFactor := fs.Int("work", 18, "log2 of the scrypt cost with -p")
+	out := fs.String("o", "", "file to write the ciphertext to, instead of standard output")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	var rs []agefile.Recipient
+	switch {
+	case *withPassphrase && len(recipients) > 0:
+		return errors.New("-p cannot be combined with -r")
+	case *withPassphrase:
+		p, err := passphrase()
+		if err != nil {
+			return err
+		}
+		r, err := agefile.NewScryptRecipient(p)
+		if err != nil {
+			return err
+		}
+		if *workFactor < 1 || *workFactor > 22 {
+			return fmt.Errorf("work factor %d is not between 1 and 22", *workFactor)
+		}
+		r.SetWorkFactor(*workFactor)
+		rs = append(rs, r)
+	case len(recipients) == 0:
+		return errors.New("missing -r or -p")
+	}
+	for _, s := range recipients {
+		r, err := agefile.ParseX25519Recipient(s)
+		if err != nil {
+			return err
+		}
+		rs = append(rs, r)
+	}
+	in, err := input(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	dst, err := output(*out, stdout)
+	if err != nil {
+		return err
+	}
+	w, err := agefile.Encrypt(dst, rs...)
+	if err != nil {
+		dst.Close()
+		return err
+	}
+	if _, err := io.Copy(w, in); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+func decrypt(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("decrypt", "(-i key.txt | -p) [-o file] [file.age]")
+	identityPath := fs.String("i", "", "file of age identities written by keygen -type age")
+	withPassphrase := fs.Bool("p", false, "decrypt with the passphrase in $"+passphraseEnv)
+	out := fs.String("o", "", "file to write the plaintext to, instead of standard output")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	var ids []agefile.Identity
+	switch {
+	case *withPassphrase && *identityPath != "":
+		return errors.New("-p cannot be combined with -i")
+	case *withPassphrase:
+		p, err := passphrase()
+		if err != nil {
+			return err
+		}
+		id, err := agefile.NewScryptIdentity(p)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	case *identityPath != "":
+		var err error
+		if ids, err = readIdentities(*identityPath); err != nil {
+			return err
+		}
+	default:
+		return errors.New("missing -i or -p")
+	}
+	in, err := input(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	r, err := agefile.Decrypt(in, ids...)
+	if err != nil {
+		return err
+	}
+	dst, err := output(*out, stdout)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, r); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// readIdentities parses an identity file in the age-keygen format, one
+// AGE-SECRET-KEY-1 per line with # comments
+func readIdentities(path string) ([]agefile.Identity, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var ids []agefile.Identity
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, err := agefile.ParseX25519Identity(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %v", path, n, err)
+		}
+		ids = append(ids, id)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no identities in %s", path)
+	}
+	return ids, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jvehent/badcrypto/bench"
+	"github.com/jvehent/badcrypto/bignum"
+)
+
+func calibrate(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("calibrate", "[-max limbs] [-o thresholds.json] [-compare]")
+	maxLimbs := fs.Int("max", 1024, fmt.Sprintf("largest operand size to try, in %d bits limbs", bignum.LimbBits))
+	out := fs.String("o", "", "file to save the thresholds to, for $"+bignum.ThresholdsEnv)
+	compare := fs.Bool("compare", false, "also compare bignum with math/big before and after")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments: %q", fs.Args())
+	}
+	if *maxLimbs < 4 {
+		return errors.New("-max must be at least 4 limbs")
+	}
+	sizes := []int{256, 1024, 2048, 4096}
+	if *compare {
+		if err := printComparison(stdout, "current thresholds", sizes); err != nil {
+			return err
+		}
+	}
+	t, err := bench.Calibrate(nil, *maxLimbs)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "karatsuba threshold: %d limbs (%d bits)\n", t.Karatsuba, bignum.LimbBits*t.Karatsuba)
+	fmt.Fprintf(stdout, "toom threshold: %d limbs (%d bits)\n", t.Toom, bignum.LimbBits*t.Toom)
+	fmt.Fprintf(stdout, "montgomery threshold: %d limbs (%d bits)\n", t.Montgomery, bignum.LimbBits*t.Montgomery)
+	if *compare {
+		if err := bignum.SetThresholds(t); err != nil {
+			return err
+		}
+		if err := printComparison(stdout, "calibrated thresholds", sizes); err != nil {
+			return err
+		}
+	}
+	if *out == "" {
+		return nil
+	}
+	if err := bench.Save(*out, t); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(stdout, "saved to %s, set %s=%s to use them\n", *out, bignum.ThresholdsEnv, *out)
+	return err
+}
+
+func printComparison(w io.Writer, title string, sizes []int) error {
+	results, err := bench.Compare(nil, []string{"mul"}, sizes)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%s:\n", title)
+	for _, r := range results {
+		fmt.Fprintf(w, "\t%s\n", r)
+	}
+	return nil
+}
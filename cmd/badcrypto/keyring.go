@@ -0,0 +1,239 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jvehent/badcrypto/encoding/keyenc"
+	"github.com/jvehent/badcrypto/keyringfile"
+)
+
+const defaultKeyring = "keyring.bkr"
+
+var keyringCommands = map[string]command{
+	"init":   keyringInit,
+	"add":    keyringAdd,
+	"list":   keyringList,
+	"export": keyringExport,
+	"rotate": keyringRotate,
+}
+
+// keyring dispatches to the keyring subcommands, which all work on the
+// keyring file named by their -f flag
+func keyring(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 || keyringCommands[args[0]] == nil {
+		names := make([]string, 0, len(keyringCommands))
+		for name := range keyringCommands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(os.Stderr, "usage: badcrypto keyring %s [flags] [args]\n", strings.Join(names, "|"))
+		return errUsage
+	}
+	return keyringCommands[args[0]](args[1:], stdin, stdout)
+}
+
+// keyringFlags returns the flag set of a keyring subcommand, with its -f
+// flag
+func keyringFlags(name, args string) (*flag.FlagSet, *string) {
+	fs := newFlagSet("keyring "+name, "[-f keyring] "+args)
+	file := fs.String("f", defaultKeyring, "keyring file")
+	return fs, file
+}
+
+// openKeyring decrypts the keyring file at path
+func openKeyring(path string) (*keyringfile.Keyring, []byte, error) {
+	p, err := passphrase()
+	if err != nil {
+		return nil, nil, err
+	}
+	kr, err := keyringfile.Load(path, []byte(p))
+	if err != nil {
+		return nil, nil, err
+	}
+	return kr, []byte(p), nil
+}
+
+func keyringInit(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs, file := keyringFlags("init", "[-time n] [-memory MiB] [-threads n]")
+	params := keyringfile.DefaultParams
+	fs.IntVar(&params.Time, "time", params.Time, "argon2id passes")
+	memory := fs.Int("memory", params.Memory/1024, "argon2id memory in MiB")
+	fs.IntVar(&params.Threads, "threads", params.Threads, "argon2id lanes")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments: %q", fs.Args())
+	}
+	if _, err := os.Stat(*file); err == nil {
+		return fmt.Errorf("%s already exists", *file)
+	}
+	p, err := passphrase()
+	if err != nil {
+		return err
+	}
+	kr := keyringfile.New()
+	params.Memory = *memory * 1024
+	kr.Params = params
+	return kr.Save(*file, []byte(p))
+}
+
+func keyringAdd(args []string, stdin io.Reader, stdout io.Writer) error {
+	types := make([]string, len(keyringfile.KeyTypes))
+	for i, t := range keyringfile.KeyTypes {
+		types[i] = string(t)
+	}
+	fs, file := keyringFlags("add", "(-type t | -import key.pb) name")
+	keyType := fs.String("type", "", "type of the key to generate, one of "+strings.Join(types, ", "))
+	imported := fs.String("import", "", "file of a private key in the protobuf encoding of keyenc, - for standard input")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || (*keyType == "") == (*imported == "") {
+		fs.Usage()
+		return errUsage
+	}
+	kr, p, err := openKeyring(*file)
+	if err != nil {
+		return err
+	}
+	var e *keyringfile.Entry
+	if *keyType != "" {
+		e, err = kr.Generate(fs.Arg(0), keyringfile.KeyType(*keyType))
+	} else {
+		var key interface{}
+		key, err = readKey(*imported, stdin)
+		if err != nil {
+			return err
+		}
+		e, err = kr.Add(fs.Arg(0), key)
+	}
+	if err != nil {
+		return err
+	}
+	if err := kr.Save(*file, p); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%s %d %s\n", e.Name, e.Version, e.Type())
+	return nil
+}
+
+// readKey reads a keyenc protobuf key from a file or stdin
+func readKey(path string, stdin io.Reader) (interface{}, error) {
+	in, err := input([]string{path}, stdin)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	return keyenc.UnmarshalProto(data)
+}
+
+func keyringList(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs, file := keyringFlags("list", "")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments: %q", fs.Args())
+	}
+	kr, _, err := openKeyring(*file)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tTYPE\tCREATED")
+	for _, e := range kr.List() {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", e.Name, e.Version, e.Type(), e.Created.Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+func keyringExport(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs, file := keyringFlags("export", "[-version n] [-public] [-format proto|cbor] [-o file] name")
+	version := fs.Int("version", 0, "version to export, the latest by default")
+	public := fs.Bool("public", false, "export the public key only")
+	format := fs.String("format", "proto", "encoding of the key, proto or cbor as in the keyenc package")
+	out := fs.String("o", "", "file to write the key to, instead of standard output")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	marshal := keyenc.MarshalProto
+	switch *format {
+	case "proto":
+	case "cbor":
+		marshal = keyenc.MarshalCBOR
+	default:
+		return fmt.Errorf("unknown format %q, use proto or cbor", *format)
+	}
+	kr, _, err := openKeyring(*file)
+	if err != nil {
+		return err
+	}
+	e, err := kr.Export(fs.Arg(0), *version)
+	if err != nil {
+		return err
+	}
+	var key interface{} = e.Key
+	if *public {
+		if key, err = keyringfile.Public(e.Key); err != nil {
+			return err
+		}
+	}
+	data, err := marshal(key)
+	if err != nil {
+		return err
+	}
+	w, err := output(*out, stdout)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func keyringRotate(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs, file := keyringFlags("rotate", "name")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	kr, p, err := openKeyring(*file)
+	if err != nil {
+		return err
+	}
+	e, err := kr.Rotate(fs.Arg(0))
+	if errors.Is(err, keyringfile.ErrNotFound) {
+		return fmt.Errorf("no key %q in %s", fs.Arg(0), *file)
+	}
+	if err != nil {
+		return err
+	}
+	if err := kr.Save(*file, p); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%s %d %s\n", e.Name, e.Version, e.Type())
+	return nil
+}
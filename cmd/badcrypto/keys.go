@@ -0,0 +1,184 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+
+	"github.com/jvehent/badcrypto/agefile"
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/ecdsa"
+)
+
+// PEM types of the ECDSA key files. The public block can be copied out
+// of a key file on its own for verify.
+const (
+	privatePEMType = "BADCRYPTO ECDSA PRIVATE KEY"
+	publicPEMType  = "BADCRYPTO ECDSA PUBLIC KEY"
+	curveHeader    = "Curve"
+)
+
+// newFlagSet returns a flag set that reports errors instead of exiting
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: badcrypto %s %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses args into fs and turns errors into errUsage, the flag
+// set has already printed them
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	return nil
+}
+
+func curveByName(name string) (*ec.Curve, error) {
+	switch name {
+	case "P-256", "p256", "prime256v1":
+		return ec.P256(), nil
+	case "secp256k1":
+		return ec.Secp256k1(), nil
+	}
+	return nil, fmt.Errorf("unknown curve %q, use P-256 or secp256k1", name)
+}
+
+func curveName(c *ec.Curve) string {
+	if c == ec.Secp256k1() {
+		return "secp256k1"
+	}
+	return "P-256"
+}
+
+func keygen(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("keygen", "[-type ecdsa|age] [-curve P-256|secp256k1] [-o key]")
+	keyType := fs.String("type", "ecdsa", "key type, ecdsa for sign and verify or age for encrypt and decrypt")
+	curve := fs.String("curve", "P-256", "ECDSA curve, P-256 or secp256k1")
+	out := fs.String("o", "", "file to write the key to, instead of standard output")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments: %q", fs.Args())
+	}
+	var key []byte
+	switch *keyType {
+	case "ecdsa":
+		c, err := curveByName(*curve)
+		if err != nil {
+			return err
+		}
+		priv, err := ecdsa.GenerateKey(rand.Reader, c)
+		if err != nil {
+			return err
+		}
+		key = marshalPrivateKey(priv)
+	case "age":
+		id, err := agefile.GenerateX25519Identity()
+		if err != nil {
+			return err
+		}
+		key = []byte(fmt.Sprintf("# public key: %s\n%s\n", id.Recipient(), id))
+	default:
+		return fmt.Errorf("unknown key type %q, use ecdsa or age", *keyType)
+	}
+	w, err := output(*out, stdout)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(key); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// marshalPrivateKey encodes the private scalar then the compressed public
+// point of priv as two PEM blocks
+func marshalPrivateKey(priv *ecdsa.PrivateKey) []byte {
+	c := priv.Curve
+	headers := map[string]string{curveHeader: curveName(c)}
+	out := pem.EncodeToMemory(&pem.Block{
+		Type:    privatePEMType,
+		Headers: headers,
+		Bytes:   priv.D.FillBytes(make([]byte, (c.N.BitLen()+7)/8)),
+	})
+	return append(out, marshalPublicKey(&priv.PublicKey)...)
+}
+
+func marshalPublicKey(pub *ecdsa.PublicKey) []byte {
+	return pem.EncodeToMemory(&pem.Block{
+		Type:    publicPEMType,
+		Headers: map[string]string{curveHeader: curveName(pub.Curve)},
+		Bytes:   pub.Curve.Marshal(pub.Point),
+	})
+}
+
+// readPEM returns the first block of type typ in the file at path
+func readPEM(path, typ string) (*pem.Block, *ec.Curve, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, nil, os.ErrNotExist
+		}
+		if block.Type != typ {
+			continue
+		}
+		c, err := curveByName(block.Headers[curveHeader])
+		if err != nil {
+			return nil, nil, err
+		}
+		return block, c, nil
+	}
+}
+
+// readPrivateKey loads the ECDSA private key of a keygen file
+func readPrivateKey(path string) (*ecdsa.PrivateKey, error) {
+	block, c, err := readPEM(path, privatePEMType)
+	if err == os.ErrNotExist {
+		return nil, fmt.Errorf("no %s block in %s", privatePEMType, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	d := new(big.Int).SetBytes(block.Bytes)
+	if d.Sign() == 0 || d.Cmp(c.N) >= 0 {
+		return nil, errors.New("private key out of range")
+	}
+	return &ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: c, Point: c.ScalarBaseMult(d)}, D: d}, nil
+}
+
+// readPublicKey loads the ECDSA public key of a keygen file, which may
+// hold the public block alone
+func readPublicKey(path string) (*ecdsa.PublicKey, error) {
+	block, c, err := readPEM(path, publicPEMType)
+	if err == os.ErrNotExist {
+		return nil, fmt.Errorf("no %s block in %s", publicPEMType, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	p, err := c.Unmarshal(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if p.IsInfinity() {
+		return nil, errors.New("public key is the point at infinity")
+	}
+	return &ecdsa.PublicKey{Curve: c, Point: p}, nil
+}
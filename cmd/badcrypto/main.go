@@ -0,0 +1,124 @@
+// Command badcrypto exercises the library from a terminal.
+//
+//	badcrypto keygen [-type ecdsa|age] [-curve P-256|secp256k1] [-o key.pem]
+//	badcrypto encrypt (-r age1... | -p) [-o file.age] [file]
+//	badcrypto decrypt (-i key.txt | -p) [-o file] [file.age]
+//	badcrypto sign -key key.pem [file]
+//	badcrypto verify -key key.pem -sig 3045... [file]
+//	badcrypto hash [-a sha256|sha512|ripemd160|hash160] [file...]
+//	badcrypto prime (-bits n | number...)
+//	badcrypto rand [-f hex|base64|raw|bip39] n
+//	badcrypto calibrate [-max limbs] [-o thresholds.json] [-compare]
+//	badcrypto keyring init [-f keyring] [-time n] [-memory MiB] [-threads n]
+//	badcrypto keyring add [-f keyring] (-type t | -import key.pb) name
+//	badcrypto keyring list [-f keyring]
+//	badcrypto keyring export [-f keyring] [-version n] [-public] [-format proto|cbor] [-o file] name
+//	badcrypto keyring rotate [-f keyring] name
+//
+// Files default to standard input and output. Passphrases are read from
+// the BADCRYPTO_PASSPHRASE environment variable rather than the command
+// line, where other users could see them. The keyring commands keep keys
+// in a keyring file encrypted under that passphrase.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+)
+
+// command runs a subcommand with its arguments
+type command func(args []string, stdin io.Reader, stdout io.Writer) error
+
+var commands = map[string]command{
+	"keygen":    keygen,
+	"encrypt":   encrypt,
+	"decrypt":   decrypt,
+	"sign":      sign,
+	"verify":    verify,
+	"hash":      hash,
+	"prime":     prime,
+	"rand":      random,
+	"calibrate": calibrate,
+	"keyring":   keyring,
+}
+
+// errUsage is returned by a subcommand after its flag set printed the
+// usage, it only sets the exit status
+var errUsage = errors.New("usage")
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("badcrypto: ")
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+	}
+	log.SetPrefix("badcrypto " + os.Args[1] + ": ")
+	if err := cmd(os.Args[2:], os.Stdin, os.Stdout); err != nil {
+		if err == errUsage {
+			os.Exit(2)
+		}
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(os.Stderr, "usage: badcrypto <command> [flags] [args]\n\ncommands:\n")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "\t%s\n", name)
+	}
+	fmt.Fprintf(os.Stderr, "\nrun badcrypto <command> -h for the flags of a command\n")
+	os.Exit(2)
+}
+
+// passphraseEnv is the environment variable holding the passphrase
+const passphraseEnv = "BADCRYPTO_PASSPHRASE"
+
+func passphrase() (string, error) {
+	p := os.Getenv(passphraseEnv)
+	if p == "" {
+		return "", fmt.Errorf("no passphrase in $%s", passphraseEnv)
+	}
+	return p, nil
+}
+
+// input opens the file named by the first argument, or returns stdin when
+// there is none or it is "-"
+func input(args []string, stdin io.Reader) (io.ReadCloser, error) {
+	if len(args) > 1 {
+		return nil, fmt.Errorf("too many arguments: %q", args)
+	}
+	if len(args) == 0 || args[0] == "-" {
+		return ioutil.NopCloser(stdin), nil
+	}
+	return os.Open(args[0])
+}
+
+// nopWriteCloser keeps stdout open when an output is closed
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// output creates the file at path, or returns stdout when path is empty
+// or "-"
+func output(path string, stdout io.Writer) (io.WriteCloser, error) {
+	if path == "" || path == "-" {
+		return nopWriteCloser{stdout}, nil
+	}
+	return os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jvehent/badcrypto/encoding/keyenc"
+)
+
+// run calls a subcommand and returns its standard output
+func run(stdin, name string, args ...string) (string, error) {
+	var out bytes.Buffer
+	err := commands[name](args, strings.NewReader(stdin), &out)
+	return out.String(), err
+}
+
+func TestSignVerify(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	for _, curve := range []string{"P-256", "secp256k1"} {
+		key := filepath.Join(dir, curve+".pem")
+		if _, err := run("", "keygen", "-curve", curve, "-o", key); err != nil {
+			t.Fatal(err)
+		}
+		sig, err := run("hello", "sign", "-key", key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := run("hello", "verify", "-key", key, "-sig", sig); err != nil {
+			t.Fatalf("%s signature failed to verify: %v", curve, err)
+		}
+		if _, err := run("hello!", "verify", "-key", key, "-sig", sig); err != errBadSignature {
+			t.Fatalf("%s expected a modified message to be rejected but got %v", curve, err)
+		}
+		// the public block alone is enough to verify
+		data, _ := ioutil.ReadFile(key)
+		pub := filepath.Join(dir, curve+".pub")
+		ioutil.WriteFile(pub, data[bytes.Index(data, []byte("-----BEGIN "+publicPEMType)):], 0600)
+		if _, err := run("hello", "verify", "-key", pub, "-sig", sig); err != nil {
+			t.Fatalf("%s signature failed to verify with the public key: %v", curve, err)
+		}
+		if _, err := run("hello", "sign", "-key", pub); err == nil {
+			t.Fatalf("%s expected signing with a public key to fail", curve)
+		}
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	dir := t.TempDir()
+	ids := filepath.Join(dir, "key.txt")
+	if _, err := run("", "keygen", "-type", "age", "-o", ids); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := ioutil.ReadFile(ids)
+	recipient := strings.Fields(string(data))[3]
+	ciphertext, err := run("attack at dawn", "encrypt", "-r", recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err := run(ciphertext, "decrypt", "-i", ids)
+	if err != nil || plaintext != "attack at dawn" {
+		t.Fatalf("expected the plaintext back but got %q and %v", plaintext, err)
+	}
+
+	// not parallel because of the environment
+	os.Setenv(passphraseEnv, "correct horse battery staple")
+	defer os.Unsetenv(passphraseEnv)
+	ciphertext, err = run("attack at dusk", "encrypt", "-p", "-work", "10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err = run(ciphertext, "decrypt", "-p")
+	if err != nil || plaintext != "attack at dusk" {
+		t.Fatalf("expected the plaintext back but got %q and %v", plaintext, err)
+	}
+	if _, err := run(ciphertext, "decrypt", "-i", ids); err == nil {
+		t.Fatal("expected a passphrase file to be rejected by an X25519 identity")
+	}
+}
+
+func TestTools(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		stdin    string
+		args     []string
+		expected string
+	}{
+		{"abc", []string{"hash"}, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad  -\n"},
+		{"abc", []string{"hash", "-a", "ripemd160"}, "8eb208f7e05d987a9b044a8e98c6b087f15a0bfc  -\n"},
+		{"", []string{"prime", "561", "0x7fffffff", "2"}, "561 is not prime\n0x7fffffff is prime\n2 is prime\n"},
+	}
+	for i, tc := range testcases {
+		out, err := run(tc.stdin, tc.args[0], tc.args[1:]...)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if out != tc.expected {
+			t.Fatalf("testcase %d expected %q but got %q", i, tc.expected, out)
+		}
+	}
+	out, err := run("", "rand", "-f", "bip39", "16")
+	if err != nil || len(strings.Fields(out)) != 12 {
+		t.Fatalf("expected a 12 words mnemonic but got %q and %v", out, err)
+	}
+	out, err = run("", "rand", "32")
+	if err != nil || len(out) != 65 {
+		t.Fatalf("expected 32 hex bytes but got %q and %v", out, err)
+	}
+	if _, err := run("", "rand", "-f", "bip39", "15"); err == nil {
+		t.Fatal("expected 15 bytes of entropy to be rejected")
+	}
+}
+
+func TestKeyring(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "keys.bkr")
+	if _, err := run("", "keyring", "list", "-f", file); err == nil {
+		t.Fatal("expected a missing passphrase to be an error")
+	}
+	// not parallel because of the environment
+	os.Setenv(passphraseEnv, "correct horse battery staple")
+	defer os.Unsetenv(passphraseEnv)
+	var testcases = []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"init", "-f", file, "-time", "1", "-memory", "1", "-threads", "1"}, ""},
+		{[]string{"add", "-f", file, "-type", "ed25519", "signing"}, "signing 1 ed25519\n"},
+		{[]string{"add", "-f", file, "-type", "x25519", "dh"}, "dh 1 x25519\n"},
+		{[]string{"rotate", "-f", file, "signing"}, "signing 2 ed25519\n"},
+	}
+	for i, tc := range testcases {
+		out, err := run("", "keyring", tc.args...)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if out != tc.expected {
+			t.Fatalf("testcase %d expected %q but got %q", i, tc.expected, out)
+		}
+	}
+	out, err := run("", "keyring", "list", "-f", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "dh ") || !strings.Contains(lines[2], " 2 ") {
+		t.Fatalf("expected dh then signing version 2 but got %q", out)
+	}
+
+	// an exported key imports back under another name
+	v1, err := run("", "keyring", "export", "-f", file, "-version", "1", "signing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2, _ := run("", "keyring", "export", "-f", file, "signing")
+	if v1 == v2 {
+		t.Fatal("expected rotation to change the key")
+	}
+	if out, err := run(v1, "keyring", "add", "-f", file, "-import", "-", "old"); err != nil || out != "old 1 ed25519\n" {
+		t.Fatalf("expected the key to be imported but got %q and %v", out, err)
+	}
+	if old, _ := run("", "keyring", "export", "-f", file, "old"); old != v1 {
+		t.Fatal("expected the imported key to export unchanged")
+	}
+	pub, err := run("", "keyring", "export", "-f", file, "-public", "-format", "cbor", "signing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key, err := keyenc.UnmarshalCBOR([]byte(pub)); err != nil {
+		t.Fatal(err)
+	} else if _, ok := key.(ed25519.PublicKey); !ok {
+		t.Fatalf("expected an ed25519 public key but got %T", key)
+	}
+	if _, err := run("", "keyring", "init", "-f", file); err == nil {
+		t.Fatal("expected init to refuse to overwrite a keyring")
+	}
+	os.Setenv(passphraseEnv, "wrong")
+	if _, err := run("", "keyring", "list", "-f", file); err == nil {
+		t.Fatal("expected a wrong passphrase to be rejected")
+	}
+}
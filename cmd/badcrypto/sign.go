@@ -0,0 +1,84 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jvehent/badcrypto/ecdsa"
+)
+
+// errBadSignature makes verify exit with a non zero status
+var errBadSignature = errors.New("signature is invalid")
+
+// digest returns the SHA-256 of the input named by args
+func digest(args []string, stdin io.Reader) ([]byte, error) {
+	in, err := input(args, stdin)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, in); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+func sign(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("sign", "-key key.pem [file]")
+	keyPath := fs.String("key", "", "ECDSA key file written by keygen")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *keyPath == "" {
+		return errors.New("missing -key")
+	}
+	priv, err := readPrivateKey(*keyPath)
+	if err != nil {
+		return err
+	}
+	h, err := digest(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	sig, err := ecdsa.SignASN1(rand.Reader, priv, h)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(stdout, "%x\n", sig)
+	return err
+}
+
+func verify(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("verify", "-key key.pem -sig hex [file]")
+	keyPath := fs.String("key", "", "ECDSA key file, or its public block alone")
+	sigHex := fs.String("sig", "", "hex DER signature printed by sign")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *keyPath == "" || *sigHex == "" {
+		return errors.New("missing -key or -sig")
+	}
+	pub, err := readPublicKey(*keyPath)
+	if err != nil {
+		return err
+	}
+	sig, err := hex.DecodeString(strings.TrimSpace(*sigHex))
+	if err != nil {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+	h, err := digest(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	if !ecdsa.VerifyASN1(pub, h, sig) {
+		return errBadSignature
+	}
+	_, err = fmt.Fprintln(stdout, "signature is valid")
+	return err
+}
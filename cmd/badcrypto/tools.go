@@ -0,0 +1,152 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	gohash "hash"
+	"io"
+	"math/big"
+	"strconv"
+
+	"github.com/jvehent/badcrypto/mnemonic"
+	"github.com/jvehent/badcrypto/ripemd160"
+)
+
+// hash160 is RIPEMD-160 of SHA-256, the Bitcoin public key hash
+type hash160 struct {
+	gohash.Hash
+}
+
+func newHash160() gohash.Hash { return hash160{sha256.New()} }
+
+func (h hash160) Size() int { return ripemd160.Size }
+
+func (h hash160) Sum(in []byte) []byte {
+	sum := ripemd160.Sum(h.Hash.Sum(nil))
+	return append(in, sum[:]...)
+}
+
+var hashes = map[string]func() gohash.Hash{
+	"sha256":    sha256.New,
+	"sha512":    sha512.New,
+	"ripemd160": ripemd160.New,
+	"hash160":   newHash160,
+}
+
+func hash(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("hash", "[-a sha256|sha512|ripemd160|hash160] [file...]")
+	alg := fs.String("a", "sha256", "hash algorithm, sha256, sha512, ripemd160 or hash160")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	newHash, ok := hashes[*alg]
+	if !ok {
+		return fmt.Errorf("unknown hash algorithm %q", *alg)
+	}
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	for _, name := range files {
+		in, err := input([]string{name}, stdin)
+		if err != nil {
+			return err
+		}
+		h := newHash()
+		_, err = io.Copy(h, in)
+		in.Close()
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(stdout, "%x  %s\n", h.Sum(nil), name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// primeRounds is the number of Miller-Rabin rounds of math/big, on top of
+// its Baillie-PSW test
+const primeRounds = 20
+
+func prime(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("prime", "(-bits n | number...)")
+	bits := fs.Int("bits", 0, "generate a random prime of this size instead of testing numbers")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *bits != 0 {
+		if fs.NArg() != 0 {
+			return errors.New("-bits cannot be combined with numbers")
+		}
+		if *bits < 2 {
+			return fmt.Errorf("cannot generate a prime of %d bits", *bits)
+		}
+		p, err := rand.Prime(rand.Reader, *bits)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(stdout, p)
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("missing -bits or numbers to test")
+	}
+	for _, arg := range fs.Args() {
+		// base 0 accepts 0x hexadecimal as well as decimal
+		n, ok := new(big.Int).SetString(arg, 0)
+		if !ok || n.Sign() < 0 {
+			return fmt.Errorf("%q is not a non negative integer", arg)
+		}
+		verdict := "is not prime"
+		if n.ProbablyPrime(primeRounds) {
+			verdict = "is prime"
+		}
+		if _, err := fmt.Fprintln(stdout, arg, verdict); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func random(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("rand", "[-f hex|base64|raw|bip39] n")
+	format := fs.String("f", "hex", "output format, hex, base64, raw or bip39 for a mnemonic of n bytes of entropy")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("expected the number of bytes as the only argument")
+	}
+	n, err := strconv.Atoi(fs.Arg(0))
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid number of bytes %q", fs.Arg(0))
+	}
+	if *format == "bip39" {
+		m, err := mnemonic.Generate(rand.Reader, 8*n)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(stdout, m)
+		return err
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, buf); err != nil {
+		return err
+	}
+	switch *format {
+	case "hex":
+		_, err = fmt.Fprintf(stdout, "%x\n", buf)
+	case "base64":
+		_, err = fmt.Fprintln(stdout, base64.StdEncoding.EncodeToString(buf))
+	case "raw":
+		_, err = stdout.Write(buf)
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	return err
+}
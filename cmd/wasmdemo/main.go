@@ -0,0 +1,171 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Command wasmdemo runs badcrypto in a web page. It exposes a badcrypto
+// object to JavaScript with
+//
+//	badcrypto.ripemd160(text)                   hex digest
+//	badcrypto.seal(keyHex, nonceHex, text)      hex ChaCha20-Poly1305 ciphertext
+//	badcrypto.open(keyHex, nonceHex, ctHex)     text
+//	badcrypto.modexp(baseHex, expHex, modHex)   {result, trace}
+//
+// where errors are returned as Error objects rather than thrown. The
+// modexp trace is the step by step output of bignum.SetTraceWriter.
+//
+// Build it with Go or TinyGo, which is much smaller, next to index.html
+// and the wasm_exec.js of the compiler that built it:
+//
+//	GOOS=js GOARCH=wasm go build -o cmd/wasmdemo/badcrypto.wasm ./cmd/wasmdemo
+//	cp "$(go env GOROOT)/lib/wasm/wasm_exec.js" cmd/wasmdemo/
+//
+//	tinygo build -o cmd/wasmdemo/badcrypto.wasm -target wasm ./cmd/wasmdemo
+//	cp "$(tinygo env TINYGOROOT)/targets/wasm_exec.js" cmd/wasmdemo/
+//
+// Go releases before 1.24 keep wasm_exec.js in misc/wasm. Then serve the
+// directory with any static web server.
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"syscall/js"
+
+	"github.com/jvehent/badcrypto/bignum"
+	"github.com/jvehent/badcrypto/chacha20poly1305"
+	"github.com/jvehent/badcrypto/ripemd160"
+)
+
+// maxExponent bounds modexp, which multiplies once per unit of the
+// exponent
+const maxExponent = 1 << 16
+
+// export wraps f for JavaScript, turning errors into Error objects
+func export(f func(args []js.Value) (interface{}, error)) js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		v, err := f(args)
+		if err != nil {
+			return js.Global().Get("Error").New(err.Error())
+		}
+		return v
+	})
+}
+
+// stringArgs returns the n string arguments of a call
+func stringArgs(args []js.Value, n int) ([]string, error) {
+	if len(args) != n {
+		return nil, errors.New("badcrypto: wrong number of arguments")
+	}
+	s := make([]string, n)
+	for i, a := range args {
+		if a.Type() != js.TypeString {
+			return nil, errors.New("badcrypto: arguments must be strings")
+		}
+		s[i] = a.String()
+	}
+	return s, nil
+}
+
+// hexArgs decodes hex arguments
+func hexArgs(s ...string) ([][]byte, error) {
+	out := make([][]byte, len(s))
+	for i := range s {
+		b, err := hex.DecodeString(s[i])
+		if err != nil {
+			return nil, err
+		}
+		out[i] = b
+	}
+	return out, nil
+}
+
+func hashRIPEMD160(args []js.Value) (interface{}, error) {
+	s, err := stringArgs(args, 1)
+	if err != nil {
+		return nil, err
+	}
+	sum := ripemd160.Sum([]byte(s[0]))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func seal(args []js.Value) (interface{}, error) {
+	s, err := stringArgs(args, 3)
+	if err != nil {
+		return nil, err
+	}
+	b, err := hexArgs(s[0], s[1])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.New(b[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(b[1]) != aead.NonceSize() {
+		return nil, errors.New("badcrypto: nonces are 12 bytes")
+	}
+	return hex.EncodeToString(aead.Seal(nil, b[1], []byte(s[2]), nil)), nil
+}
+
+func open(args []js.Value) (interface{}, error) {
+	s, err := stringArgs(args, 3)
+	if err != nil {
+		return nil, err
+	}
+	b, err := hexArgs(s...)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.New(b[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(b[1]) != aead.NonceSize() {
+		return nil, errors.New("badcrypto: nonces are 12 bytes")
+	}
+	pt, err := aead.Open(nil, b[1], b[2], nil)
+	if err != nil {
+		return nil, err
+	}
+	return string(pt), nil
+}
+
+func modexp(args []js.Value) (interface{}, error) {
+	s, err := stringArgs(args, 3)
+	if err != nil {
+		return nil, err
+	}
+	b, err := hexArgs(s...)
+	if err != nil {
+		return nil, err
+	}
+	base, exp, mod := new(bignum.Int), new(bignum.Int), new(bignum.Int)
+	base.SetBytes(b[0])
+	exp.SetBytes(b[1])
+	mod.SetBytes(b[2])
+	if exp.Compare(bignum.NewInt(maxExponent)) > 0 {
+		return nil, errors.New("badcrypto: exponents above 0x10000 take too long")
+	}
+	if mod.Compare(bignum.ZeroValue) == 0 {
+		return nil, errors.New("badcrypto: modulus must not be zero")
+	}
+	var trace bytes.Buffer
+	bignum.SetTraceWriter(&trace)
+	base.ModularExponentiation(exp, mod)
+	bignum.SetTraceWriter(nil)
+	return map[string]interface{}{
+		"result": hex.EncodeToString(base.Bytes()),
+		"trace":  trace.String(),
+	}, nil
+}
+
+func main() {
+	js.Global().Set("badcrypto", js.ValueOf(map[string]interface{}{
+		"ripemd160": export(hashRIPEMD160),
+		"seal":      export(seal),
+		"open":      export(open),
+		"modexp":    export(modexp),
+	}))
+	// keep the exported functions alive
+	select {}
+}
@@ -0,0 +1,40 @@
+package commit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"io"
+)
+
+// NonceSize is the size of the random opening of hash commitments
+const NonceSize = 32
+
+// HashCommit commits to msg with SHA-256(domain || nonce || msg). The
+// nonce hides low entropy messages and must be revealed to open.
+func HashCommit(rand io.Reader, msg []byte) (commitment, nonce []byte, err error) {
+	nonce = make([]byte, NonceSize)
+	if _, err := io.ReadFull(rand, nonce); err != nil {
+		return nil, nil, err
+	}
+	return hashCommitment(nonce, msg), nonce, nil
+}
+
+func hashCommitment(nonce, msg []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte("badcrypto hash commitment"))
+	h.Write(nonce)
+	h.Write(msg)
+	return h.Sum(nil)
+}
+
+// HashOpen checks that commitment was made to msg with nonce
+func HashOpen(commitment, msg, nonce []byte) error {
+	if len(nonce) != NonceSize {
+		return errors.New("commit: invalid nonce size")
+	}
+	if !hmac.Equal(commitment, hashCommitment(nonce, msg)) {
+		return errors.New("commit: commitment does not open to the message")
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package commit
+
+import (
+	"crypto/rand"
+	"testing"
+)
+
+func TestHashCommit(t *testing.T) {
+	t.Parallel()
+	cm, nonce, err := HashCommit(rand.Reader, []byte("heads"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := HashOpen(cm, []byte("heads"), nonce); err != nil {
+		t.Fatal(err)
+	}
+	if err := HashOpen(cm, []byte("tails"), nonce); err == nil {
+		t.Fatal("expected commitment to not open to another message")
+	}
+	cm2, _, _ := HashCommit(rand.Reader, []byte("heads"))
+	if string(cm) == string(cm2) {
+		t.Fatal("expected two commitments to the same message to differ")
+	}
+}
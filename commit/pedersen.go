@@ -0,0 +1,94 @@
+// Package commit implements commitment schemes: Pedersen commitments over
+// the curves of the ec package, which are perfectly hiding, computationally
+// binding and additively homomorphic, and hash based commitments, which are
+// simpler but carry no algebraic structure.
+package commit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+// Pedersen holds the generators of Pedersen commitments on a curve. A
+// commitment to v with blinding r is v*G + r*H.
+type Pedersen struct {
+	Curve *ec.Curve
+	// H is a second generator whose discrete logarithm relative to G is
+	// unknown, otherwise commitments would not be binding
+	H *ec.Point
+}
+
+// NewPedersen returns the Pedersen parameters of c, with H hashed to the
+// curve
+func NewPedersen(c *ec.Curve) *Pedersen {
+	return &Pedersen{Curve: c, H: c.HashToPoint("badcrypto pedersen generator", []byte(c.Name))}
+}
+
+// Commit commits to value with a fresh random blinding factor, which must
+// be kept to open the commitment
+func (p *Pedersen) Commit(rand io.Reader, value *big.Int) (commitment *ec.Point, blinding *big.Int, err error) {
+	blinding, err = p.Curve.RandomScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p.CommitWithBlinding(value, blinding), blinding, nil
+}
+
+// CommitWithBlinding returns value*G + blinding*H
+func (p *Pedersen) CommitWithBlinding(value, blinding *big.Int) *ec.Point {
+	return p.Curve.Add(p.Curve.ScalarBaseMult(value), p.Curve.ScalarMult(p.H, blinding))
+}
+
+// Open checks that commitment was made to value with blinding
+func (p *Pedersen) Open(commitment *ec.Point, value, blinding *big.Int) bool {
+	return p.CommitWithBlinding(value, blinding).Equal(commitment)
+}
+
+// Add returns a commitment to the sum of the values of a and b, which
+// opens with the sum of their blinding factors
+func (p *Pedersen) Add(a, b *ec.Point) *ec.Point {
+	return p.Curve.Add(a, b)
+}
+
+// Sub returns a commitment to the difference of the values of a and b
+func (p *Pedersen) Sub(a, b *ec.Point) *ec.Point {
+	return p.Curve.Sub(a, b)
+}
+
+// ScalarMult returns a commitment to k times the value of a, which opens
+// with k times its blinding factor
+func (p *Pedersen) ScalarMult(a *ec.Point, k *big.Int) *ec.Point {
+	return p.Curve.ScalarMult(a, k)
+}
+
+// AddScalars returns a+b modulo the group order, to combine values or
+// blinding factors alongside Add
+func (p *Pedersen) AddScalars(a, b *big.Int) *big.Int {
+	s := new(big.Int).Add(a, b)
+	return s.Mod(s, p.Curve.N)
+}
+
+// Generators returns n independent generators G_0..G_n-1, hashed to the
+// curve under label. Range proofs and vector commitments need many of them.
+func (p *Pedersen) Generators(label string, n int) []*ec.Point {
+	gens := make([]*ec.Point, n)
+	for i := range gens {
+		gens[i] = p.Curve.HashToPoint("badcrypto pedersen "+label, []byte(fmt.Sprintf("%s %d", p.Curve.Name, i)))
+	}
+	return gens
+}
+
+// CommitVector returns the sum of values[i]*gens[i] + blinding*H, a single
+// point committing to a whole vector
+func (p *Pedersen) CommitVector(gens []*ec.Point, values []*big.Int, blinding *big.Int) (*ec.Point, error) {
+	if len(values) > len(gens) {
+		return nil, errors.New("commit: not enough generators for the vector")
+	}
+	scalars := append([]*big.Int{blinding}, values...)
+	points := append([]*ec.Point{p.H}, gens[:len(values)]...)
+	return p.Curve.MultiScalarMult(scalars, points), nil
+}
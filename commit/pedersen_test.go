@@ -0,0 +1,60 @@
+package commit
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+func TestPedersen(t *testing.T) {
+	t.Parallel()
+	for _, c := range []*ec.Curve{ec.P256(), ec.Secp256k1()} {
+		p := NewPedersen(c)
+		v := big.NewInt(1337)
+		cm, r, err := p.Commit(rand.Reader, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !p.Open(cm, v, r) {
+			t.Fatalf("%s: expected commitment to open", c.Name)
+		}
+		if p.Open(cm, big.NewInt(1338), r) {
+			t.Fatalf("%s: expected commitment to not open to another value", c.Name)
+		}
+		cm2, r2, _ := p.Commit(rand.Reader, v)
+		if cm2.Equal(cm) {
+			t.Fatalf("%s: expected two commitments to the same value to differ", c.Name)
+		}
+		if !p.Open(p.Add(cm, cm2), big.NewInt(2674), p.AddScalars(r, r2)) {
+			t.Fatalf("%s: expected the sum of commitments to open to the sum", c.Name)
+		}
+		if !p.Open(p.Sub(cm, cm2), big.NewInt(0), new(big.Int).Sub(r, r2)) {
+			t.Fatalf("%s: expected the difference of commitments to open to zero", c.Name)
+		}
+		if !p.Open(p.ScalarMult(cm, big.NewInt(3)), big.NewInt(4011), new(big.Int).Mul(r, big.NewInt(3))) {
+			t.Fatalf("%s: expected 3 times the commitment to open to 3 times the value", c.Name)
+		}
+	}
+}
+
+func TestCommitVector(t *testing.T) {
+	t.Parallel()
+	p := NewPedersen(ec.P256())
+	gens := p.Generators("test", 3)
+	if gens[0].Equal(gens[1]) || gens[0].Equal(p.H) {
+		t.Fatal("expected distinct generators")
+	}
+	a := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}
+	b := []*big.Int{big.NewInt(10), big.NewInt(20), big.NewInt(30)}
+	ca, _ := p.CommitVector(gens, a, big.NewInt(5))
+	cb, _ := p.CommitVector(gens, b, big.NewInt(7))
+	sum, _ := p.CommitVector(gens, []*big.Int{big.NewInt(11), big.NewInt(22), big.NewInt(33)}, big.NewInt(12))
+	if !p.Add(ca, cb).Equal(sum) {
+		t.Fatal("expected vector commitments to be homomorphic")
+	}
+	if _, err := p.CommitVector(gens[:2], a, big.NewInt(1)); err == nil {
+		t.Fatal("expected a vector longer than the generators to be rejected")
+	}
+}
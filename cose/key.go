@@ -0,0 +1,246 @@
+// Package cose implements the COSE_Key and COSE_Sign1 structures of
+// RFC 9052 and RFC 9053 on top of the cborenc codec, which is what
+// WebAuthn and FIDO authenticators use to carry public keys and
+// signatures.
+package cose
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/encoding/cborenc"
+)
+
+// Key types from the IANA COSE Key Types registry
+const (
+	KeyTypeOKP = 1
+	KeyTypeEC2 = 2
+	KeyTypeRSA = 3
+)
+
+// Elliptic curves from the IANA COSE Elliptic Curves registry
+const (
+	CurveP256    = 1
+	CurveP384    = 2
+	CurveP521    = 3
+	CurveEd25519 = 6
+)
+
+// Algorithm is a signature algorithm from the IANA COSE Algorithms registry
+type Algorithm int64
+
+// Supported signature algorithms
+const (
+	AlgES256 Algorithm = -7
+	AlgEdDSA Algorithm = -8
+	AlgES384 Algorithm = -35
+	AlgES512 Algorithm = -36
+	AlgPS256 Algorithm = -37
+	AlgRS256 Algorithm = -257
+)
+
+// COSE_Key labels from RFC 9052 section 7.1 and RFC 9053 section 7
+const (
+	labelKty = 1
+	labelKid = 2
+	labelAlg = 3
+	labelCrv = -1 // also n for RSA keys
+	labelX   = -2 // also e for RSA keys
+	labelY   = -3
+	labelD   = -4
+)
+
+// Key is a COSE_Key holding a public key, and optionally the private
+// scalar of an EC2 or OKP key
+type Key struct {
+	Type      int64
+	ID        []byte
+	Algorithm Algorithm
+	Curve     int64
+	X, Y      []byte // x and y coordinates, or n and e for RSA keys
+	D         []byte // private scalar, never set for RSA keys
+}
+
+// NewKey builds a COSE_Key from an *ecdsa.PublicKey, ed25519.PublicKey or
+// *rsa.PublicKey. RSA keys are tagged with RS256, ECDSA keys with the
+// algorithm matching their curve, and Ed25519 keys with EdDSA.
+func NewKey(pub crypto.PublicKey) (*Key, error) {
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		crv, alg, size, err := ecParams(k.Curve)
+		if err != nil {
+			return nil, err
+		}
+		return &Key{
+			Type:      KeyTypeEC2,
+			Algorithm: alg,
+			Curve:     crv,
+			X:         k.X.FillBytes(make([]byte, size)),
+			Y:         k.Y.FillBytes(make([]byte, size)),
+		}, nil
+	case ed25519.PublicKey:
+		return &Key{Type: KeyTypeOKP, Algorithm: AlgEdDSA, Curve: CurveEd25519, X: append([]byte{}, k...)}, nil
+	case *rsa.PublicKey:
+		return &Key{Type: KeyTypeRSA, Algorithm: AlgRS256, X: k.N.Bytes(), Y: big.NewInt(int64(k.E)).Bytes()}, nil
+	}
+	return nil, fmt.Errorf("cose: unsupported public key type %T", pub)
+}
+
+func ecParams(curve elliptic.Curve) (crv int64, alg Algorithm, size int, err error) {
+	switch curve {
+	case elliptic.P256():
+		return CurveP256, AlgES256, 32, nil
+	case elliptic.P384():
+		return CurveP384, AlgES384, 48, nil
+	case elliptic.P521():
+		return CurveP521, AlgES512, 66, nil
+	}
+	return 0, 0, 0, fmt.Errorf("cose: unsupported curve %s", curve.Params().Name)
+}
+
+func curveOf(crv int64) (elliptic.Curve, error) {
+	switch crv {
+	case CurveP256:
+		return elliptic.P256(), nil
+	case CurveP384:
+		return elliptic.P384(), nil
+	case CurveP521:
+		return elliptic.P521(), nil
+	}
+	return nil, fmt.Errorf("cose: unsupported ec2 curve %d", crv)
+}
+
+// PublicKey returns the stdlib public key held by the COSE_Key, after
+// checking that EC points are on their curve
+func (k *Key) PublicKey() (crypto.PublicKey, error) {
+	switch k.Type {
+	case KeyTypeEC2:
+		curve, err := curveOf(k.Curve)
+		if err != nil {
+			return nil, err
+		}
+		x, y := new(big.Int).SetBytes(k.X), new(big.Int).SetBytes(k.Y)
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("cose: ec2 point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case KeyTypeOKP:
+		if k.Curve != CurveEd25519 || len(k.X) != ed25519.PublicKeySize {
+			return nil, errors.New("cose: only ed25519 okp keys are supported")
+		}
+		return ed25519.PublicKey(append([]byte{}, k.X...)), nil
+	case KeyTypeRSA:
+		e := new(big.Int).SetBytes(k.Y)
+		if len(k.X) == 0 || !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("cose: invalid rsa public key")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(k.X), E: int(e.Int64())}, nil
+	}
+	return nil, fmt.Errorf("cose: unsupported key type %d", k.Type)
+}
+
+// Marshal returns the deterministic CBOR encoding of the COSE_Key
+func (k *Key) Marshal() ([]byte, error) {
+	m := map[interface{}]interface{}{labelKty: k.Type}
+	if len(k.ID) > 0 {
+		m[labelKid] = k.ID
+	}
+	if k.Algorithm != 0 {
+		m[labelAlg] = int64(k.Algorithm)
+	}
+	switch k.Type {
+	case KeyTypeEC2:
+		m[labelCrv], m[labelX], m[labelY] = k.Curve, k.X, k.Y
+	case KeyTypeOKP:
+		m[labelCrv], m[labelX] = k.Curve, k.X
+	case KeyTypeRSA:
+		// RSA keys reuse the -1 and -2 labels for n and e
+		m[labelCrv], m[labelX] = k.X, k.Y
+	default:
+		return nil, fmt.Errorf("cose: unsupported key type %d", k.Type)
+	}
+	if len(k.D) > 0 {
+		m[labelD] = k.D
+	}
+	return cborenc.Marshal(m)
+}
+
+// ParseKey decodes a COSE_Key from its CBOR encoding
+func ParseKey(data []byte) (*Key, error) {
+	v, err := cborenc.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return keyFromCBOR(v)
+}
+
+func keyFromCBOR(v interface{}) (*Key, error) {
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("cose: key is not a cbor map")
+	}
+	k := new(Key)
+	if k.Type, ok = m[int64(labelKty)].(int64); !ok {
+		return nil, errors.New("cose: key is missing its kty")
+	}
+	if kid, ok := m[int64(labelKid)]; ok {
+		if k.ID, ok = kid.([]byte); !ok {
+			return nil, errors.New("cose: kid must be a byte string")
+		}
+	}
+	if alg, ok := m[int64(labelAlg)]; ok {
+		a, ok := alg.(int64)
+		if !ok {
+			return nil, errors.New("cose: alg must be an integer")
+		}
+		k.Algorithm = Algorithm(a)
+	}
+	bstr := func(label int64, required bool) ([]byte, error) {
+		v, ok := m[label]
+		if !ok {
+			if required {
+				return nil, fmt.Errorf("cose: key is missing label %d", label)
+			}
+			return nil, nil
+		}
+		b, ok := v.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("cose: label %d must be a byte string", label)
+		}
+		return b, nil
+	}
+	var err error
+	switch k.Type {
+	case KeyTypeEC2, KeyTypeOKP:
+		if k.Curve, ok = m[int64(labelCrv)].(int64); !ok {
+			return nil, errors.New("cose: key is missing its curve")
+		}
+		if k.X, err = bstr(labelX, true); err != nil {
+			return nil, err
+		}
+		if k.Type == KeyTypeEC2 {
+			if k.Y, err = bstr(labelY, true); err != nil {
+				return nil, err
+			}
+		}
+		if k.D, err = bstr(labelD, false); err != nil {
+			return nil, err
+		}
+	case KeyTypeRSA:
+		if k.X, err = bstr(labelCrv, true); err != nil {
+			return nil, err
+		}
+		if k.Y, err = bstr(labelX, true); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("cose: unsupported key type %d", k.Type)
+	}
+	return k, nil
+}
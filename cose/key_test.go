@@ -0,0 +1,92 @@
+package cose
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+func TestKeyRoundTrip(t *testing.T) {
+	t.Parallel()
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ec384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, pub := range []interface{}{&ecKey.PublicKey, &ec384Key.PublicKey, edPub, &rsaKey.PublicKey} {
+		k, err := NewKey(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded, err := k.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := ParseKey(encoded)
+		if err != nil {
+			t.Fatalf("testcase %d failed to parse key: %v", i, err)
+		}
+		pub2, err := parsed.PublicKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(pub, pub2) {
+			t.Fatalf("testcase %d public key changed after round trip", i)
+		}
+	}
+}
+
+func TestKeyEncoding(t *testing.T) {
+	t.Parallel()
+	// the P-256 key with kid "11" from the COSE examples of RFC 9052 appendix C
+	k := &Key{
+		Type:  KeyTypeEC2,
+		ID:    []byte("11"),
+		Curve: CurveP256,
+		X:     mustHex("bac5b11cad8f99f9c72b05cf4b9e26d244dc189f745228255a219a86d6a09eff"),
+		Y:     mustHex("20138bf82dc1b6d562be0fa54ab7804a3a64b6d72ccfed6b6fb6ed28bbfc117e"),
+	}
+	encoded, err := k.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// map keys are ordered 1, 2, -1, -2, -3 by the deterministic encoding
+	expected := "a501020242313120012158" + "20bac5b11cad8f99f9c72b05cf4b9e26d244dc189f745228255a219a86d6a09eff" +
+		"225820" + "20138bf82dc1b6d562be0fa54ab7804a3a64b6d72ccfed6b6fb6ed28bbfc117e"
+	if hex.EncodeToString(encoded) != expected {
+		t.Fatalf("unexpected encoding\nexp %s\ngot %x", expected, encoded)
+	}
+}
+
+func TestKeyInvalidPoint(t *testing.T) {
+	t.Parallel()
+	k := &Key{Type: KeyTypeEC2, Curve: CurveP256, X: make([]byte, 32), Y: make([]byte, 32)}
+	k.X[31], k.Y[31] = 1, 1
+	if _, err := k.PublicKey(); err == nil {
+		t.Fatal("expected an error on a point that is not on the curve")
+	}
+}
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
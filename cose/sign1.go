@@ -0,0 +1,247 @@
+package cose
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/encoding/cborenc"
+)
+
+// TagSign1 is the CBOR tag of a COSE_Sign1 message
+const TagSign1 = 18
+
+// header labels from RFC 9052 section 3.1
+const (
+	headerAlg = 1
+	headerKid = 4
+)
+
+// Sign1Message is a COSE_Sign1 structure: a payload signed by a single signer
+type Sign1Message struct {
+	Algorithm   Algorithm // taken from the protected header
+	KeyID       []byte    // taken from the unprotected header, if any
+	Protected   []byte    // serialized protected header, as signed
+	Unprotected map[interface{}]interface{}
+	Payload     []byte
+	Signature   []byte
+}
+
+// Sign1 signs payload with signer and returns the tagged COSE_Sign1
+// encoding. The algorithm goes in the protected header and kid, when
+// not empty, in the unprotected header.
+func Sign1(signer crypto.Signer, alg Algorithm, kid, payload, externalAAD []byte) ([]byte, error) {
+	protected, err := cborenc.Marshal(map[interface{}]interface{}{headerAlg: int64(alg)})
+	if err != nil {
+		return nil, err
+	}
+	tbs, err := sigStructure(protected, externalAAD, payload)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := sign(signer, alg, tbs)
+	if err != nil {
+		return nil, err
+	}
+	unprotected := map[interface{}]interface{}{}
+	if len(kid) > 0 {
+		unprotected[headerKid] = kid
+	}
+	return cborenc.Marshal(cborenc.Tag{
+		Number:  TagSign1,
+		Content: []interface{}{protected, unprotected, payload, sig},
+	})
+}
+
+// sigStructure builds the Sig_structure of RFC 9052 section 4.4 that
+// is actually signed
+func sigStructure(protected, externalAAD, payload []byte) ([]byte, error) {
+	if externalAAD == nil {
+		externalAAD = []byte{}
+	}
+	return cborenc.Marshal([]interface{}{"Signature1", protected, externalAAD, payload})
+}
+
+// ParseSign1 decodes a tagged or untagged COSE_Sign1 message
+func ParseSign1(data []byte) (*Sign1Message, error) {
+	v, err := cborenc.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	if tag, ok := v.(cborenc.Tag); ok {
+		if tag.Number != TagSign1 {
+			return nil, fmt.Errorf("cose: unexpected tag %d, expected COSE_Sign1", tag.Number)
+		}
+		v = tag.Content
+	}
+	arr, ok := v.([]interface{})
+	if !ok || len(arr) != 4 {
+		return nil, errors.New("cose: COSE_Sign1 must be an array of four items")
+	}
+	msg := new(Sign1Message)
+	if msg.Protected, ok = arr[0].([]byte); !ok {
+		return nil, errors.New("cose: protected header must be a byte string")
+	}
+	if msg.Unprotected, ok = arr[1].(map[interface{}]interface{}); !ok {
+		return nil, errors.New("cose: unprotected header must be a map")
+	}
+	if arr[2] != nil {
+		if msg.Payload, ok = arr[2].([]byte); !ok {
+			return nil, errors.New("cose: payload must be a byte string or nil")
+		}
+	}
+	if msg.Signature, ok = arr[3].([]byte); !ok {
+		return nil, errors.New("cose: signature must be a byte string")
+	}
+	if len(msg.Protected) > 0 {
+		hdr, err := cborenc.Unmarshal(msg.Protected)
+		if err != nil {
+			return nil, err
+		}
+		hdrMap, ok := hdr.(map[interface{}]interface{})
+		if !ok {
+			return nil, errors.New("cose: protected header must be a map")
+		}
+		if alg, ok := hdrMap[int64(headerAlg)].(int64); ok {
+			msg.Algorithm = Algorithm(alg)
+		}
+	}
+	if msg.Algorithm == 0 {
+		return nil, errors.New("cose: protected header has no algorithm")
+	}
+	if kid, ok := msg.Unprotected[int64(headerKid)].([]byte); ok {
+		msg.KeyID = kid
+	}
+	return msg, nil
+}
+
+// Verify checks the signature of the message with key. A detached
+// payload must be set into msg.Payload before calling Verify.
+func (msg *Sign1Message) Verify(key *Key, externalAAD []byte) error {
+	if key.Algorithm != 0 && key.Algorithm != msg.Algorithm {
+		return errors.New("cose: key algorithm does not match the message")
+	}
+	pub, err := key.PublicKey()
+	if err != nil {
+		return err
+	}
+	tbs, err := sigStructure(msg.Protected, externalAAD, msg.Payload)
+	if err != nil {
+		return err
+	}
+	return verify(pub, msg.Algorithm, tbs, msg.Signature, false)
+}
+
+func hashFor(alg Algorithm) (crypto.Hash, error) {
+	switch alg {
+	case AlgES256, AlgPS256, AlgRS256:
+		return crypto.SHA256, nil
+	case AlgES384:
+		return crypto.SHA384, nil
+	case AlgES512:
+		return crypto.SHA512, nil
+	}
+	return 0, fmt.Errorf("cose: unsupported algorithm %d", alg)
+}
+
+// sign signs tbs with the algorithm alg. ECDSA signatures are encoded
+// as the fixed size concatenation of r and s, as COSE requires.
+func sign(signer crypto.Signer, alg Algorithm, tbs []byte) ([]byte, error) {
+	if alg == AlgEdDSA {
+		edKey, ok := signer.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errors.New("cose: EdDSA requires an ed25519 key")
+		}
+		return ed25519.Sign(edKey, tbs), nil
+	}
+	h, err := hashFor(alg)
+	if err != nil {
+		return nil, err
+	}
+	hh := h.New()
+	hh.Write(tbs)
+	digest := hh.Sum(nil)
+	switch k := signer.(type) {
+	case *ecdsa.PrivateKey:
+		_, expected, size, err := ecParams(k.Curve)
+		if err != nil {
+			return nil, err
+		}
+		if expected != alg {
+			return nil, errors.New("cose: algorithm does not match the ecdsa curve")
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest)
+		if err != nil {
+			return nil, err
+		}
+		return append(r.FillBytes(make([]byte, size)), s.FillBytes(make([]byte, size))...), nil
+	case *rsa.PrivateKey:
+		if alg == AlgPS256 {
+			return rsa.SignPSS(rand.Reader, k, h, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		return rsa.SignPKCS1v15(rand.Reader, k, h, digest)
+	}
+	return nil, fmt.Errorf("cose: unsupported signer type %T", signer)
+}
+
+// verify checks sig over tbs. When ecdsaDER is set, ECDSA signatures are
+// expected in their ASN.1 form, as WebAuthn assertions use, instead of
+// the raw r||s form of COSE.
+func verify(pub crypto.PublicKey, alg Algorithm, tbs, sig []byte, ecdsaDER bool) error {
+	if alg == AlgEdDSA {
+		edKey, ok := pub.(ed25519.PublicKey)
+		if !ok {
+			return errors.New("cose: EdDSA requires an ed25519 key")
+		}
+		if !ed25519.Verify(edKey, tbs, sig) {
+			return errors.New("cose: invalid EdDSA signature")
+		}
+		return nil
+	}
+	h, err := hashFor(alg)
+	if err != nil {
+		return err
+	}
+	hh := h.New()
+	hh.Write(tbs)
+	digest := hh.Sum(nil)
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		_, expected, size, err := ecParams(k.Curve)
+		if err != nil {
+			return err
+		}
+		if expected != alg {
+			return errors.New("cose: algorithm does not match the ecdsa curve")
+		}
+		var r, s *big.Int
+		if ecdsaDER {
+			var parsed struct{ R, S *big.Int }
+			if rest, err := asn1.Unmarshal(sig, &parsed); err != nil || len(rest) > 0 {
+				return errors.New("cose: malformed der ecdsa signature")
+			}
+			r, s = parsed.R, parsed.S
+		} else {
+			if len(sig) != 2*size {
+				return errors.New("cose: invalid ecdsa signature length")
+			}
+			r, s = new(big.Int).SetBytes(sig[:size]), new(big.Int).SetBytes(sig[size:])
+		}
+		if !ecdsa.Verify(k, digest, r, s) {
+			return errors.New("cose: invalid ecdsa signature")
+		}
+		return nil
+	case *rsa.PublicKey:
+		if alg == AlgPS256 {
+			return rsa.VerifyPSS(k, h, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		return rsa.VerifyPKCS1v15(k, h, digest, sig)
+	}
+	return fmt.Errorf("cose: unsupported public key type %T", pub)
+}
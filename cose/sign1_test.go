@@ -0,0 +1,122 @@
+package cose
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"math/big"
+	"testing"
+)
+
+func TestVerifyRFCSign1Example(t *testing.T) {
+	t.Parallel()
+	// COSE_Sign1 example C.2.1 of RFC 8152, signed with the key of kid "11"
+	msg, err := ParseSign1(mustHex("d28443a10126a10442313154546869732069732074686520636f6e74656e742e58408eb33e4ca31d1c465ab05aac34cc6b23d58fef5c083106c4d25a91aef0b0117e2af9a291aa32e14ab834dc56ed2a223444547e01f11d3b0916e5a4c345cacb36"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Algorithm != AlgES256 || string(msg.KeyID) != "11" || string(msg.Payload) != "This is the content." {
+		t.Fatalf("unexpected message content: %+v", msg)
+	}
+	key := &Key{
+		Type:  KeyTypeEC2,
+		Curve: CurveP256,
+		X:     mustHex("bac5b11cad8f99f9c72b05cf4b9e26d244dc189f745228255a219a86d6a09eff"),
+		Y:     mustHex("20138bf82dc1b6d562be0fa54ab7804a3a64b6d72ccfed6b6fb6ed28bbfc117e"),
+	}
+	if err := msg.Verify(key, nil); err != nil {
+		t.Fatal(err)
+	}
+	msg.Payload = []byte("This is not the content.")
+	if err := msg.Verify(key, nil); err == nil {
+		t.Fatal("expected verification of a modified payload to fail")
+	}
+}
+
+func TestSign1RoundTrip(t *testing.T) {
+	t.Parallel()
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ec521Key, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signers := []struct {
+		key interface{}
+		alg Algorithm
+	}{
+		{ecKey, AlgES256},
+		{ec521Key, AlgES512},
+		{edKey, AlgEdDSA},
+		{rsaKey, AlgRS256},
+		{rsaKey, AlgPS256},
+	}
+	for i, s := range signers {
+		var (
+			data []byte
+			pub  interface{}
+		)
+		switch k := s.key.(type) {
+		case *ecdsa.PrivateKey:
+			data, err = Sign1(k, s.alg, []byte("kid"), []byte("bad crypto"), []byte("aad"))
+			pub = &k.PublicKey
+		case ed25519.PrivateKey:
+			data, err = Sign1(k, s.alg, nil, []byte("bad crypto"), []byte("aad"))
+			pub = k.Public()
+		case *rsa.PrivateKey:
+			data, err = Sign1(k, s.alg, nil, []byte("bad crypto"), []byte("aad"))
+			pub = &k.PublicKey
+		}
+		if err != nil {
+			t.Fatalf("testcase %d failed to sign: %v", i, err)
+		}
+		key, err := NewKey(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key.Algorithm = s.alg
+		msg, err := ParseSign1(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := msg.Verify(key, []byte("aad")); err != nil {
+			t.Fatalf("testcase %d failed to verify: %v", i, err)
+		}
+		if err := msg.Verify(key, []byte("other aad")); err == nil {
+			t.Fatalf("testcase %d verified with the wrong external aad", i)
+		}
+	}
+}
+
+func TestSign1RejectsTruncatedSignature(t *testing.T) {
+	t.Parallel()
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := Sign1(ecKey, AlgES256, nil, []byte("bad crypto"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := ParseSign1(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg.Signature = new(big.Int).SetBytes(msg.Signature).Bytes()[:63]
+	key, _ := NewKey(&ecKey.PublicKey)
+	if err := msg.Verify(key, nil); err == nil {
+		t.Fatal("expected a truncated signature to be rejected")
+	}
+}
@@ -0,0 +1,105 @@
+package cose
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+
+	"github.com/jvehent/badcrypto/encoding/cborenc"
+)
+
+// authenticator data flags from the WebAuthn specification, section 6.1
+const (
+	FlagUserPresent      = 0x01
+	FlagUserVerified     = 0x04
+	FlagAttestedCredData = 0x40
+	FlagExtensionData    = 0x80
+)
+
+// AuthenticatorData is the binary structure signed by a WebAuthn authenticator
+type AuthenticatorData struct {
+	RPIDHash     [32]byte
+	Flags        byte
+	SignCount    uint32
+	AAGUID       []byte // set when FlagAttestedCredData is present
+	CredentialID []byte
+	Credential   *Key   // the COSE_Key of the new credential, on registration
+	Extensions   []byte // raw CBOR extensions, if any
+}
+
+// ParseAuthenticatorData decodes authenticator data, including the
+// attested credential COSE_Key embedded in it on registration
+func ParseAuthenticatorData(data []byte) (*AuthenticatorData, error) {
+	if len(data) < 37 {
+		return nil, errors.New("cose: authenticator data too short")
+	}
+	ad := new(AuthenticatorData)
+	copy(ad.RPIDHash[:], data[:32])
+	ad.Flags = data[32]
+	ad.SignCount = binary.BigEndian.Uint32(data[33:37])
+	rest := data[37:]
+	if ad.Flags&FlagAttestedCredData != 0 {
+		if len(rest) < 18 {
+			return nil, errors.New("cose: attested credential data too short")
+		}
+		ad.AAGUID = rest[:16]
+		idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if len(rest) < idLen {
+			return nil, errors.New("cose: credential id too short")
+		}
+		ad.CredentialID, rest = rest[:idLen], rest[idLen:]
+		// the COSE_Key is followed by the extensions without any length prefix
+		v, remaining, err := cborenc.UnmarshalFirst(rest)
+		if err != nil {
+			return nil, err
+		}
+		if ad.Credential, err = keyFromCBOR(v); err != nil {
+			return nil, err
+		}
+		rest = remaining
+	}
+	if ad.Flags&FlagExtensionData != 0 {
+		if _, remaining, err := cborenc.UnmarshalFirst(rest); err != nil {
+			return nil, err
+		} else if len(remaining) > 0 {
+			return nil, errors.New("cose: trailing bytes after extensions")
+		}
+		ad.Extensions, rest = rest, nil
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("cose: trailing bytes in authenticator data")
+	}
+	return ad, nil
+}
+
+// VerifyAssertion checks a WebAuthn assertion signature, which is
+// computed over the authenticator data concatenated with the SHA-256 of
+// the client data JSON, using the credential key registered earlier.
+// It also checks that the assertion is bound to rpID and that the user
+// was present. Checking the challenge inside clientDataJSON and the
+// signature counter is left to the caller.
+func VerifyAssertion(key *Key, rpID string, authData, clientDataJSON, sig []byte) (*AuthenticatorData, error) {
+	ad, err := ParseAuthenticatorData(authData)
+	if err != nil {
+		return nil, err
+	}
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	if !bytes.Equal(ad.RPIDHash[:], rpIDHash[:]) {
+		return nil, errors.New("cose: assertion was made for another relying party")
+	}
+	if ad.Flags&FlagUserPresent == 0 {
+		return nil, errors.New("cose: user presence flag is not set")
+	}
+	pub, err := key.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte{}, authData...), clientDataHash[:]...)
+	if err := verify(pub, key.Algorithm, signed, sig, true); err != nil {
+		return nil, err
+	}
+	return ad, nil
+}
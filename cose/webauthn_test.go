@@ -0,0 +1,67 @@
+package cose
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+)
+
+// fakeAuthenticator builds authenticator data the way a security key
+// would, optionally embedding the attested credential key
+func fakeAuthenticator(t *testing.T, rpID string, flags byte, cred *Key) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	data := append(rpIDHash[:], flags, 0, 0, 0, 42)
+	if cred != nil {
+		data = append(data, make([]byte, 16)...) // zero aaguid
+		data = append(data, 0, 4, 0xca, 0xfe, 0xba, 0xbe)
+		encoded, err := cred.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = append(data, encoded...)
+	}
+	return data
+}
+
+func TestVerifyAssertion(t *testing.T) {
+	t.Parallel()
+	credKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coseKey, err := NewKey(&credKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// registration: the relying party extracts the credential key from authData
+	regData := fakeAuthenticator(t, "example.net", FlagUserPresent|FlagAttestedCredData, coseKey)
+	ad, err := ParseAuthenticatorData(regData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ad.SignCount != 42 || string(ad.CredentialID) != "\xca\xfe\xba\xbe" || ad.Credential == nil {
+		t.Fatalf("unexpected authenticator data: %+v", ad)
+	}
+
+	// authentication: the authenticator signs authData || sha256(clientDataJSON)
+	authData := fakeAuthenticator(t, "example.net", FlagUserPresent|FlagUserVerified, nil)
+	clientDataJSON := []byte(`{"type":"webauthn.get","challenge":"YmFkY3J5cHRv","origin":"https://example.net"}`)
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, credKey, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyAssertion(ad.Credential, "example.net", authData, clientDataJSON, sig); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyAssertion(ad.Credential, "evil.example", authData, clientDataJSON, sig); err == nil {
+		t.Fatal("expected an assertion for another relying party to be rejected")
+	}
+	if _, err := VerifyAssertion(ad.Credential, "example.net", authData, []byte(`{}`), sig); err == nil {
+		t.Fatal("expected an assertion over other client data to be rejected")
+	}
+}
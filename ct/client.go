@@ -0,0 +1,244 @@
+package ct
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jvehent/badcrypto/ecdsa"
+	"github.com/jvehent/badcrypto/merkle"
+)
+
+// maxResponseSize bounds the responses read from a log
+const maxResponseSize = 16 << 20
+
+// Log is a Certificate Transparency log
+type Log struct {
+	// URL is the base of the log API, such that URL + "ct/v1/get-sth"
+	// is the get-sth endpoint
+	URL string
+	// LogID is the SHA-256 hash of the DER public key of the log
+	LogID [32]byte
+	// PublicKey is an *ecdsa.PublicKey of this repository or an
+	// *rsa.PublicKey
+	PublicKey interface{}
+	// Client is used for requests, http.DefaultClient when nil
+	Client *http.Client
+}
+
+// NewLog returns a log at url with the given DER SubjectPublicKeyInfo
+func NewLog(url string, publicKey []byte) (*Log, error) {
+	pub, err := x509.ParsePKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	l := &Log{URL: url, LogID: sha256.Sum256(publicKey)}
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		l.PublicKey = pub
+	default:
+		if l.PublicKey, err = ecdsa.ParsePKIXPublicKey(publicKey); err != nil {
+			return nil, err
+		}
+	}
+	if !strings.HasSuffix(l.URL, "/") {
+		l.URL += "/"
+	}
+	return l, nil
+}
+
+// get calls a log endpoint and decodes its JSON response into v
+func (l *Log) get(ctx context.Context, endpoint string, params url.Values, v interface{}) error {
+	u := l.URL + "ct/v1/" + endpoint
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	client := l.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ct: %s returned %s", endpoint, resp.Status)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("ct: invalid %s response: %v", endpoint, err)
+	}
+	return nil
+}
+
+// GetSTH fetches the latest signed tree head of the log and checks its
+// signature
+func (l *Log) GetSTH(ctx context.Context) (*SignedTreeHead, error) {
+	var resp struct {
+		TreeSize          uint64 `json:"tree_size"`
+		Timestamp         uint64 `json:"timestamp"`
+		SHA256RootHash    []byte `json:"sha256_root_hash"`
+		TreeHeadSignature []byte `json:"tree_head_signature"`
+	}
+	if err := l.get(ctx, "get-sth", nil, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.SHA256RootHash) != sha256.Size {
+		return nil, errors.New("ct: invalid tree head root hash")
+	}
+	ds, rest, err := parseDigitallySigned(resp.TreeHeadSignature)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("ct: trailing data after tree head signature")
+	}
+	sth := &SignedTreeHead{
+		TreeSize:          resp.TreeSize,
+		Timestamp:         resp.Timestamp,
+		SHA256RootHash:    resp.SHA256RootHash,
+		TreeHeadSignature: ds,
+	}
+	if err := l.VerifySTH(sth); err != nil {
+		return nil, err
+	}
+	return sth, nil
+}
+
+// VerifySTH checks the signature of a tree head
+func (l *Log) VerifySTH(sth *SignedTreeHead) error {
+	return verifySignature(l.PublicKey, sth.SignedData(), sth.TreeHeadSignature)
+}
+
+// VerifySCT checks an SCT the log issued for a certificate, as delivered
+// in the TLS or OCSP extensions
+func (l *Log) VerifySCT(sct *SCT, cert *x509.Certificate) error {
+	if sct.LogID != l.LogID {
+		return errors.New("ct: SCT was issued by another log")
+	}
+	return verifySignature(l.PublicKey, sct.SignedData(X509Entry, cert.Raw, nil), sct.Signature)
+}
+
+// VerifyEmbeddedSCT checks an SCT the log issued for the precertificate
+// of cert, which is signed by issuer
+func (l *Log) VerifyEmbeddedSCT(sct *SCT, cert, issuer *x509.Certificate) error {
+	if sct.LogID != l.LogID {
+		return errors.New("ct: SCT was issued by another log")
+	}
+	tbs, err := PrecertTBS(cert)
+	if err != nil {
+		return err
+	}
+	return verifySignature(l.PublicKey, sct.SignedData(PrecertEntry, tbs, IssuerKeyHash(issuer)), sct.Signature)
+}
+
+// GetSTHConsistency fetches the proof that the tree of size first is a
+// prefix of the tree of size second
+func (l *Log) GetSTHConsistency(ctx context.Context, first, second uint64) ([][]byte, error) {
+	var resp struct {
+		Consistency [][]byte `json:"consistency"`
+	}
+	params := url.Values{}
+	params.Set("first", strconv.FormatUint(first, 10))
+	params.Set("second", strconv.FormatUint(second, 10))
+	if err := l.get(ctx, "get-sth-consistency", params, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Consistency, nil
+}
+
+// GetProofByHash fetches the index and audit path of the leaf with the
+// given hash in the tree of size treeSize
+func (l *Log) GetProofByHash(ctx context.Context, leafHash []byte, treeSize uint64) (uint64, [][]byte, error) {
+	var resp struct {
+		LeafIndex uint64   `json:"leaf_index"`
+		AuditPath [][]byte `json:"audit_path"`
+	}
+	params := url.Values{}
+	params.Set("hash", base64.StdEncoding.EncodeToString(leafHash))
+	params.Set("tree_size", strconv.FormatUint(treeSize, 10))
+	if err := l.get(ctx, "get-proof-by-hash", params, &resp); err != nil {
+		return 0, nil, err
+	}
+	return resp.LeafIndex, resp.AuditPath, nil
+}
+
+// GetEntries fetches the entries from start to end included. Logs may
+// return fewer entries than requested, but never none.
+func (l *Log) GetEntries(ctx context.Context, start, end uint64) ([]*LogEntry, error) {
+	if start > end {
+		return nil, fmt.Errorf("ct: invalid entry range %d to %d", start, end)
+	}
+	var resp struct {
+		Entries []struct {
+			LeafInput []byte `json:"leaf_input"`
+			ExtraData []byte `json:"extra_data"`
+		} `json:"entries"`
+	}
+	params := url.Values{}
+	params.Set("start", strconv.FormatUint(start, 10))
+	params.Set("end", strconv.FormatUint(end, 10))
+	if err := l.get(ctx, "get-entries", params, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Entries) == 0 || uint64(len(resp.Entries)) > end-start+1 {
+		return nil, fmt.Errorf("ct: log returned %d entries for %d to %d", len(resp.Entries), start, end)
+	}
+	entries := make([]*LogEntry, len(resp.Entries))
+	for i, raw := range resp.Entries {
+		e, err := ParseMerkleTreeLeaf(raw.LeafInput)
+		if err != nil {
+			return nil, err
+		}
+		e.Index = start + uint64(i)
+		e.ExtraData = raw.ExtraData
+		entries[i] = e
+	}
+	return entries, nil
+}
+
+// CheckInclusion fetches an inclusion proof for the leaf and verifies it
+// against the tree head, returning the index of the leaf
+func (l *Log) CheckInclusion(ctx context.Context, leafInput []byte, sth *SignedTreeHead) (uint64, error) {
+	leafHash := merkle.LeafHash(leafInput)
+	index, proof, err := l.GetProofByHash(ctx, leafHash, sth.TreeSize)
+	if err != nil {
+		return 0, err
+	}
+	if err := merkle.VerifyInclusion(index, sth.TreeSize, leafHash, proof, sth.SHA256RootHash); err != nil {
+		return 0, err
+	}
+	return index, nil
+}
+
+// CheckConsistency fetches a consistency proof between two tree heads and
+// verifies it
+func (l *Log) CheckConsistency(ctx context.Context, old, new *SignedTreeHead) error {
+	var proof [][]byte
+	if old.TreeSize > 0 && old.TreeSize < new.TreeSize {
+		var err error
+		if proof, err = l.GetSTHConsistency(ctx, old.TreeSize, new.TreeSize); err != nil {
+			return err
+		}
+	}
+	return merkle.VerifyConsistency(old.TreeSize, new.TreeSize, old.SHA256RootHash, new.SHA256RootHash, proof)
+}
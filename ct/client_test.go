@@ -0,0 +1,236 @@
+package ct
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ecdsa"
+	"github.com/jvehent/badcrypto/merkle"
+)
+
+// fakeLog serves the RFC 6962 API over an in memory tree
+type fakeLog struct {
+	t   *testing.T
+	key *ecdsa.PrivateKey
+
+	mu     sync.Mutex
+	tree   merkle.Tree
+	leaves [][]byte
+	// size is the published tree size
+	size uint64
+	// fork replaces the published root when set
+	fork []byte
+	// maxEntries caps the number of entries per get-entries response
+	maxEntries int
+}
+
+func (f *fakeLog) add(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := 0; i < n; i++ {
+		index := len(f.leaves)
+		leaf := MerkleTreeLeaf(uint64(1700000000000+index), X509Entry, []byte(fmt.Sprintf("certificate %d", index)), nil, nil)
+		f.leaves = append(f.leaves, leaf)
+		f.tree.Append(leaf)
+	}
+	f.size = f.tree.Size()
+}
+
+func (f *fakeLog) reply(w http.ResponseWriter, v interface{}) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		f.t.Error(err)
+	}
+}
+
+func (f *fakeLog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	q := r.URL.Query()
+	param := func(name string) uint64 {
+		v, _ := strconv.ParseUint(q.Get(name), 10, 64)
+		return v
+	}
+	switch r.URL.Path {
+	case "/log/ct/v1/get-sth":
+		root, _ := f.tree.RootAt(f.size)
+		if f.fork != nil {
+			root = f.fork
+		}
+		sth := &SignedTreeHead{TreeSize: f.size, Timestamp: 1700000000000 + f.size, SHA256RootHash: root}
+		f.reply(w, map[string]interface{}{
+			"tree_size":           sth.TreeSize,
+			"timestamp":           sth.Timestamp,
+			"sha256_root_hash":    sth.SHA256RootHash,
+			"tree_head_signature": sign(f.t, f.key, sth.SignedData()).Marshal(),
+		})
+	case "/log/ct/v1/get-sth-consistency":
+		proof, err := f.tree.ConsistencyProof(param("first"), param("second"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.reply(w, map[string]interface{}{"consistency": proof})
+	case "/log/ct/v1/get-proof-by-hash":
+		hash, _ := base64.StdEncoding.DecodeString(q.Get("hash"))
+		size := param("tree_size")
+		for i := uint64(0); i < size && i < f.tree.Size(); i++ {
+			if bytes.Equal(f.tree.LeafHash(i), hash) {
+				proof, _ := f.tree.InclusionProof(i, size)
+				f.reply(w, map[string]interface{}{"leaf_index": i, "audit_path": proof})
+				return
+			}
+		}
+		http.NotFound(w, r)
+	case "/log/ct/v1/get-entries":
+		start, end := param("start"), param("end")
+		var entries []map[string][]byte
+		for i := start; i <= end && i < uint64(len(f.leaves)); i++ {
+			if f.maxEntries > 0 && len(entries) == f.maxEntries {
+				break
+			}
+			entries = append(entries, map[string][]byte{"leaf_input": f.leaves[i], "extra_data": {}})
+		}
+		f.reply(w, map[string]interface{}{"entries": entries})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newFakeLog(t *testing.T, n int) (*fakeLog, *Log, func()) {
+	f := &fakeLog{t: t}
+	server := httptest.NewServer(f)
+	var l *Log
+	f.key, l = testLogKey(t, server.URL+"/log")
+	f.add(n)
+	return f, l, server.Close
+}
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+	f, l, done := newFakeLog(t, 20)
+	defer done()
+	ctx := context.Background()
+
+	sth, err := l.GetSTH(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sth.TreeSize != 20 {
+		t.Fatalf("expected a tree of 20 entries but got %d", sth.TreeSize)
+	}
+	entries, err := l.GetEntries(ctx, 5, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 5 || entries[0].Index != 5 || string(entries[4].Certificate) != "certificate 9" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	for _, e := range entries {
+		index, err := l.CheckInclusion(ctx, e.LeafInput, sth)
+		if err != nil {
+			t.Fatalf("expected entry %d to be included but got %v", e.Index, err)
+		}
+		if index != e.Index {
+			t.Fatalf("expected entry at index %d but got %d", e.Index, index)
+		}
+	}
+
+	f.add(13)
+	newSTH, err := l.GetSTH(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.CheckConsistency(ctx, sth, newSTH); err != nil {
+		t.Fatalf("expected tree heads to be consistent but got %v", err)
+	}
+	sth.TreeHeadSignature.Signature[10] ^= 1
+	if l.VerifySTH(sth) == nil {
+		t.Fatal("expected tampered tree head signature to fail")
+	}
+}
+
+func TestMonitor(t *testing.T) {
+	t.Parallel()
+	f, l, done := newFakeLog(t, 37)
+	defer done()
+	ctx := context.Background()
+
+	m, err := NewMonitor(ctx, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.BatchSize = 8
+	f.mu.Lock()
+	f.maxEntries = 5
+	f.mu.Unlock()
+	var testcases = []int{0, 1, 30, 64}
+	for i, n := range testcases {
+		f.add(n)
+		entries, err := m.Update(ctx)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if len(entries) != n {
+			t.Fatalf("testcase %d expected %d new entries but got %d", i, n, len(entries))
+		}
+		if m.STH.TreeSize != f.tree.Size() {
+			t.Fatalf("testcase %d expected monitor at %d but is at %d", i, f.tree.Size(), m.STH.TreeSize)
+		}
+	}
+
+	// a log showing a forked tree is caught, and the monitor stays put
+	f.add(3)
+	f.mu.Lock()
+	f.fork = merkle.LeafHash([]byte("fork"))
+	f.mu.Unlock()
+	before := m.STH.TreeSize
+	if _, err := m.Update(ctx); err == nil {
+		t.Fatal("expected a forked tree head to be rejected")
+	}
+	if m.STH.TreeSize != before {
+		t.Fatal("expected a failed update to leave the monitor unchanged")
+	}
+	f.mu.Lock()
+	f.fork = nil
+	f.mu.Unlock()
+	if entries, err := m.Update(ctx); err != nil || len(entries) != 3 {
+		t.Fatalf("expected the monitor to recover but got %d entries and %v", len(entries), err)
+	}
+
+	// a log rewriting an entry is caught as well
+	f.mu.Lock()
+	f.leaves = append(f.leaves, MerkleTreeLeaf(1, X509Entry, []byte("hidden"), nil, nil))
+	f.tree.Append(MerkleTreeLeaf(1, X509Entry, []byte("shown"), nil, nil))
+	f.size = f.tree.Size()
+	f.mu.Unlock()
+	if _, err := m.Update(ctx); err == nil {
+		t.Fatal("expected an entry not matching the tree to be rejected")
+	}
+}
+
+func TestEmptyLogMonitor(t *testing.T) {
+	t.Parallel()
+	f, l, done := newFakeLog(t, 0)
+	defer done()
+	ctx := context.Background()
+	m, err := NewMonitor(ctx, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.add(4)
+	entries, err := m.Update(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries but got %d", len(entries))
+	}
+}
@@ -0,0 +1,361 @@
+// Package ct is a client for the Certificate Transparency logs of RFC 6962.
+// It fetches signed tree heads and entries from a log, checks the
+// signatures of tree heads and of Signed Certificate Timestamps, and
+// verifies Merkle inclusion and consistency proofs with the merkle
+// package. ECDSA signatures are checked with the ecdsa package of this
+// repository, RSA signatures with crypto/rsa.
+//
+// Monitor follows a log over time: every Update checks that the new tree
+// head is consistent with the previous one, downloads the new entries and
+// recomputes the root from them, so that a log cannot show different
+// trees to different monitors without being caught.
+package ct
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ecdsa"
+)
+
+// TLS SignatureAndHashAlgorithm values used by logs
+const (
+	HashSHA256     = 4
+	SignatureRSA   = 1
+	SignatureECDSA = 3
+)
+
+// EntryType is the type of a log entry
+type EntryType uint16
+
+// Entry types of RFC 6962 section 3.1
+const (
+	X509Entry    EntryType = 0
+	PrecertEntry EntryType = 1
+)
+
+// signature types of RFC 6962 section 3.2
+const (
+	certificateTimestamp = 0
+	treeHash             = 1
+)
+
+// oidSCTList is the X.509 extension carrying embedded SCTs
+var oidSCTList = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+
+// DigitallySigned is a TLS digitally-signed struct
+type DigitallySigned struct {
+	HashAlgorithm      uint8
+	SignatureAlgorithm uint8
+	Signature          []byte
+}
+
+func parseDigitallySigned(b []byte) (*DigitallySigned, []byte, error) {
+	if len(b) < 4 {
+		return nil, nil, errors.New("ct: truncated signature")
+	}
+	l := int(binary.BigEndian.Uint16(b[2:4]))
+	if len(b) < 4+l {
+		return nil, nil, errors.New("ct: truncated signature")
+	}
+	return &DigitallySigned{HashAlgorithm: b[0], SignatureAlgorithm: b[1], Signature: b[4 : 4+l]}, b[4+l:], nil
+}
+
+// Marshal encodes the signature in its TLS form
+func (ds *DigitallySigned) Marshal() []byte {
+	out := []byte{ds.HashAlgorithm, ds.SignatureAlgorithm, byte(len(ds.Signature) >> 8), byte(len(ds.Signature))}
+	return append(out, ds.Signature...)
+}
+
+// verifySignature checks ds over data with a log public key
+func verifySignature(pub interface{}, data []byte, ds *DigitallySigned) error {
+	if ds.HashAlgorithm != HashSHA256 {
+		return fmt.Errorf("ct: unsupported hash algorithm %d", ds.HashAlgorithm)
+	}
+	digest := sha256.Sum256(data)
+	switch key := pub.(type) {
+	case *ecdsa.PublicKey:
+		if ds.SignatureAlgorithm != SignatureECDSA {
+			return errors.New("ct: signature algorithm does not match the log key")
+		}
+		if !ecdsa.VerifyASN1(key, digest[:], ds.Signature) {
+			return errors.New("ct: invalid ECDSA signature")
+		}
+		return nil
+	case *rsa.PublicKey:
+		if ds.SignatureAlgorithm != SignatureRSA {
+			return errors.New("ct: signature algorithm does not match the log key")
+		}
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], ds.Signature); err != nil {
+			return errors.New("ct: invalid RSA signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("ct: unsupported log key type %T", pub)
+}
+
+// SignedTreeHead is a log's signed commitment to the root of its tree
+type SignedTreeHead struct {
+	TreeSize          uint64
+	Timestamp         uint64
+	SHA256RootHash    []byte
+	TreeHeadSignature *DigitallySigned
+}
+
+// SignedData returns the TreeHeadSignature input of RFC 6962 section 3.5
+func (sth *SignedTreeHead) SignedData() []byte {
+	out := make([]byte, 18, 18+len(sth.SHA256RootHash))
+	out[0] = 0 // v1
+	out[1] = treeHash
+	binary.BigEndian.PutUint64(out[2:10], sth.Timestamp)
+	binary.BigEndian.PutUint64(out[10:18], sth.TreeSize)
+	return append(out, sth.SHA256RootHash...)
+}
+
+// SCT is a Signed Certificate Timestamp, the promise of a log to include
+// a certificate in its tree
+type SCT struct {
+	Version    uint8
+	LogID      [32]byte
+	Timestamp  uint64
+	Extensions []byte
+	Signature  *DigitallySigned
+}
+
+// ParseSCT decodes a single serialized SCT
+func ParseSCT(b []byte) (*SCT, error) {
+	if len(b) < 1+32+8+2 {
+		return nil, errors.New("ct: truncated SCT")
+	}
+	sct := &SCT{Version: b[0]}
+	if sct.Version != 0 {
+		return nil, fmt.Errorf("ct: unsupported SCT version %d", sct.Version)
+	}
+	copy(sct.LogID[:], b[1:33])
+	sct.Timestamp = binary.BigEndian.Uint64(b[33:41])
+	l := int(binary.BigEndian.Uint16(b[41:43]))
+	b = b[43:]
+	if len(b) < l {
+		return nil, errors.New("ct: truncated SCT extensions")
+	}
+	sct.Extensions, b = b[:l], b[l:]
+	ds, rest, err := parseDigitallySigned(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("ct: trailing data after SCT")
+	}
+	sct.Signature = ds
+	return sct, nil
+}
+
+// Marshal serializes the SCT
+func (sct *SCT) Marshal() []byte {
+	out := []byte{sct.Version}
+	out = append(out, sct.LogID[:]...)
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], sct.Timestamp)
+	out = append(out, ts[:]...)
+	out = append(out, byte(len(sct.Extensions)>>8), byte(len(sct.Extensions)))
+	out = append(out, sct.Extensions...)
+	return append(out, sct.Signature.Marshal()...)
+}
+
+// ParseSCTList decodes a SignedCertificateTimestampList, as found in the
+// TLS extension, the OCSP extension and the certificate extension
+func ParseSCTList(b []byte) ([]*SCT, error) {
+	if len(b) < 2 || int(binary.BigEndian.Uint16(b)) != len(b)-2 {
+		return nil, errors.New("ct: invalid SCT list length")
+	}
+	var scts []*SCT
+	for b = b[2:]; len(b) > 0; {
+		if len(b) < 2 {
+			return nil, errors.New("ct: truncated SCT list")
+		}
+		l := int(binary.BigEndian.Uint16(b))
+		if len(b) < 2+l {
+			return nil, errors.New("ct: truncated SCT list")
+		}
+		sct, err := ParseSCT(b[2 : 2+l])
+		if err != nil {
+			return nil, err
+		}
+		scts = append(scts, sct)
+		b = b[2+l:]
+	}
+	return scts, nil
+}
+
+// MarshalSCTList serializes SCTs into a SignedCertificateTimestampList
+func MarshalSCTList(scts []*SCT) []byte {
+	var body []byte
+	for _, sct := range scts {
+		b := sct.Marshal()
+		body = append(body, byte(len(b)>>8), byte(len(b)))
+		body = append(body, b...)
+	}
+	return append([]byte{byte(len(body) >> 8), byte(len(body))}, body...)
+}
+
+// EmbeddedSCTs returns the SCTs embedded in the extension of a certificate
+func EmbeddedSCTs(cert *x509.Certificate) ([]*SCT, error) {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidSCTList) {
+			continue
+		}
+		var list []byte
+		if rest, err := asn1.Unmarshal(ext.Value, &list); err != nil || len(rest) != 0 {
+			return nil, errors.New("ct: invalid SCT list extension")
+		}
+		return ParseSCTList(list)
+	}
+	return nil, nil
+}
+
+// signedEntry encodes the entry part of the SCT signature input and of
+// the Merkle tree leaves
+func signedEntry(typ EntryType, cert, issuerKeyHash []byte) []byte {
+	out := []byte{byte(typ >> 8), byte(typ)}
+	if typ == PrecertEntry {
+		out = append(out, issuerKeyHash...)
+	}
+	out = append(out, byte(len(cert)>>16), byte(len(cert)>>8), byte(len(cert)))
+	return append(out, cert...)
+}
+
+// SignedData returns the input of the SCT signature of RFC 6962 section
+// 3.2, for a certificate or a precertificate TBSCertificate
+func (sct *SCT) SignedData(typ EntryType, cert, issuerKeyHash []byte) []byte {
+	out := []byte{sct.Version, certificateTimestamp}
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], sct.Timestamp)
+	out = append(out, ts[:]...)
+	out = append(out, signedEntry(typ, cert, issuerKeyHash)...)
+	out = append(out, byte(len(sct.Extensions)>>8), byte(len(sct.Extensions)))
+	return append(out, sct.Extensions...)
+}
+
+// tbsCertificate is parsed just enough to remove an extension and
+// serialize it back
+type tbsCertificate struct {
+	Raw                asn1.RawContent
+	Version            int `asn1:"optional,explicit,default:0,tag:0"`
+	SerialNumber       *big.Int
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Issuer             asn1.RawValue
+	Validity           asn1.RawValue
+	Subject            asn1.RawValue
+	PublicKey          asn1.RawValue
+	UniqueID           asn1.BitString   `asn1:"optional,tag:1"`
+	SubjectUniqueID    asn1.BitString   `asn1:"optional,tag:2"`
+	Extensions         []pkix.Extension `asn1:"optional,explicit,tag:3"`
+}
+
+// PrecertTBS rebuilds the TBSCertificate a log signed for a certificate
+// with embedded SCTs: the certificate without its SCT list extension
+func PrecertTBS(cert *x509.Certificate) ([]byte, error) {
+	var tbs tbsCertificate
+	rest, err := asn1.Unmarshal(cert.RawTBSCertificate, &tbs)
+	if err != nil || len(rest) != 0 {
+		return nil, errors.New("ct: invalid TBSCertificate")
+	}
+	var exts []pkix.Extension
+	for _, ext := range tbs.Extensions {
+		if !ext.Id.Equal(oidSCTList) {
+			exts = append(exts, ext)
+		}
+	}
+	if len(exts) == len(tbs.Extensions) {
+		return nil, errors.New("ct: certificate has no embedded SCTs")
+	}
+	tbs.Extensions = exts
+	tbs.Raw = nil
+	return asn1.Marshal(tbs)
+}
+
+// IssuerKeyHash returns the SHA-256 hash of the public key of the issuer,
+// which precertificate entries are bound to
+func IssuerKeyHash(issuer *x509.Certificate) []byte {
+	h := sha256.Sum256(issuer.RawSubjectPublicKeyInfo)
+	return h[:]
+}
+
+// LogEntry is an entry of a log with its parsed Merkle tree leaf
+type LogEntry struct {
+	Index     uint64
+	LeafInput []byte
+	ExtraData []byte
+
+	Timestamp uint64
+	Type      EntryType
+	// Certificate is the DER certificate of X509Entry, or the
+	// TBSCertificate of PrecertEntry
+	Certificate   []byte
+	IssuerKeyHash []byte
+	Extensions    []byte
+}
+
+// ParseMerkleTreeLeaf decodes the leaf_input of a log entry
+func ParseMerkleTreeLeaf(b []byte) (*LogEntry, error) {
+	e := &LogEntry{LeafInput: b}
+	if len(b) < 2+8+2 {
+		return nil, errors.New("ct: truncated leaf")
+	}
+	if b[0] != 0 || b[1] != 0 {
+		return nil, fmt.Errorf("ct: unsupported leaf version %d or type %d", b[0], b[1])
+	}
+	e.Timestamp = binary.BigEndian.Uint64(b[2:10])
+	e.Type = EntryType(binary.BigEndian.Uint16(b[10:12]))
+	b = b[12:]
+	switch e.Type {
+	case X509Entry:
+	case PrecertEntry:
+		if len(b) < 32 {
+			return nil, errors.New("ct: truncated leaf")
+		}
+		e.IssuerKeyHash, b = b[:32], b[32:]
+	default:
+		return nil, fmt.Errorf("ct: unknown entry type %d", e.Type)
+	}
+	if len(b) < 3 {
+		return nil, errors.New("ct: truncated leaf")
+	}
+	l := int(b[0])<<16 | int(b[1])<<8 | int(b[2])
+	if len(b) < 3+l+2 {
+		return nil, errors.New("ct: truncated leaf")
+	}
+	e.Certificate, b = b[3:3+l], b[3+l:]
+	l = int(binary.BigEndian.Uint16(b))
+	if len(b) != 2+l {
+		return nil, errors.New("ct: invalid leaf extensions")
+	}
+	e.Extensions = b[2:]
+	return e, nil
+}
+
+// MerkleTreeLeaf encodes the leaf a log adds to its tree for an entry
+// with the given timestamp
+func MerkleTreeLeaf(timestamp uint64, typ EntryType, cert, issuerKeyHash, extensions []byte) []byte {
+	out := []byte{0, 0} // v1, timestamped_entry
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], timestamp)
+	out = append(out, ts[:]...)
+	out = append(out, signedEntry(typ, cert, issuerKeyHash)...)
+	out = append(out, byte(len(extensions)>>8), byte(len(extensions)))
+	return append(out, extensions...)
+}
+
+// equal256 compares two hashes
+func equal256(a, b []byte) bool {
+	return len(a) == sha256.Size && bytes.Equal(a, b)
+}
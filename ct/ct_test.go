@@ -0,0 +1,201 @@
+package ct
+
+import (
+	"bytes"
+	stdecdsa "crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/ecdsa"
+)
+
+// testLogKey returns a log signing key and the log using it
+func testLogKey(t *testing.T, url string) (*ecdsa.PrivateKey, *Log) {
+	key, err := ecdsa.GenerateKey(rand.Reader, ec.P256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := ecdsa.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewLog(url, der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, l
+}
+
+func sign(t *testing.T, key *ecdsa.PrivateKey, data []byte) *DigitallySigned {
+	digest := sha256.Sum256(data)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &DigitallySigned{HashAlgorithm: HashSHA256, SignatureAlgorithm: SignatureECDSA, Signature: sig}
+}
+
+// testCertificates returns a CA and a template of leaf certificate
+func testCertificates(t *testing.T) (*x509.Certificate, *stdecdsa.PrivateKey, *x509.Certificate, *stdecdsa.PrivateKey) {
+	caKey, err := stdecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "badcrypto test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafKey, err := stdecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "badcrypto.test"},
+		DNSNames:     []string{"badcrypto.test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	return ca, caKey, leaf, leafKey
+}
+
+func TestSCT(t *testing.T) {
+	t.Parallel()
+	key, l := testLogKey(t, "https://ct.badcrypto.test/")
+	ca, caKey, template, leafKey := testCertificates(t)
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &leafKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sct := &SCT{LogID: l.LogID, Timestamp: 1700000000000}
+	sct.Signature = sign(t, key, sct.SignedData(X509Entry, cert.Raw, nil))
+
+	scts, err := ParseSCTList(MarshalSCTList([]*SCT{sct, sct}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scts) != 2 {
+		t.Fatalf("expected 2 SCTs but got %d", len(scts))
+	}
+	if err := l.VerifySCT(scts[1], cert); err != nil {
+		t.Fatalf("expected SCT to verify but got %v", err)
+	}
+	scts[1].Timestamp++
+	if l.VerifySCT(scts[1], cert) == nil {
+		t.Fatal("expected SCT with another timestamp to fail")
+	}
+	if l.VerifySCT(scts[0], ca) == nil {
+		t.Fatal("expected SCT to fail for another certificate")
+	}
+	_, other := testLogKey(t, "https://other.badcrypto.test/")
+	if other.VerifySCT(scts[0], cert) == nil {
+		t.Fatal("expected SCT to fail for another log")
+	}
+}
+
+func TestEmbeddedSCT(t *testing.T) {
+	t.Parallel()
+	key, l := testLogKey(t, "https://ct.badcrypto.test/")
+	ca, caKey, template, leafKey := testCertificates(t)
+
+	// the precertificate is the certificate without its SCT extension
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &leafKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	precert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sct := &SCT{LogID: l.LogID, Timestamp: 1700000000000}
+	sct.Signature = sign(t, key, sct.SignedData(PrecertEntry, precert.RawTBSCertificate, IssuerKeyHash(ca)))
+
+	list, err := asn1.Marshal(MarshalSCTList([]*SCT{sct}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	template.ExtraExtensions = []pkix.Extension{{Id: oidSCTList, Value: list}}
+	der, err = x509.CreateCertificate(rand.Reader, template, ca, &leafKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbs, err := PrecertTBS(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(tbs, precert.RawTBSCertificate) {
+		t.Fatal("expected the rebuilt TBSCertificate to match the precertificate")
+	}
+	scts, err := EmbeddedSCTs(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scts) != 1 {
+		t.Fatalf("expected 1 embedded SCT but got %d", len(scts))
+	}
+	if err := l.VerifyEmbeddedSCT(scts[0], cert, ca); err != nil {
+		t.Fatalf("expected embedded SCT to verify but got %v", err)
+	}
+	if l.VerifyEmbeddedSCT(scts[0], cert, cert) == nil {
+		t.Fatal("expected embedded SCT to fail with another issuer")
+	}
+	if _, err := PrecertTBS(precert); err == nil {
+		t.Fatal("expected a certificate without SCTs to be rejected")
+	}
+}
+
+func TestMerkleTreeLeaf(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		typ       EntryType
+		cert      []byte
+		issuer    []byte
+		extension []byte
+	}{
+		{X509Entry, []byte("a certificate"), nil, nil},
+		{PrecertEntry, []byte("a tbs certificate"), bytes.Repeat([]byte{7}, 32), []byte{1, 2}},
+	}
+	for i, tc := range testcases {
+		leaf := MerkleTreeLeaf(1234, tc.typ, tc.cert, tc.issuer, tc.extension)
+		e, err := ParseMerkleTreeLeaf(leaf)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if e.Timestamp != 1234 || e.Type != tc.typ || !bytes.Equal(e.Certificate, tc.cert) ||
+			!bytes.Equal(e.IssuerKeyHash, tc.issuer) || !bytes.Equal(e.Extensions, tc.extension) {
+			t.Fatalf("testcase %d decoded to %+v", i, e)
+		}
+		if _, err := ParseMerkleTreeLeaf(leaf[:len(leaf)-1]); err == nil {
+			t.Fatalf("testcase %d expected a truncated leaf to fail", i)
+		}
+	}
+}
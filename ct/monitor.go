@@ -0,0 +1,106 @@
+package ct
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jvehent/badcrypto/merkle"
+)
+
+// DefaultBatchSize is the number of entries a Monitor requests at once
+const DefaultBatchSize = 256
+
+// Monitor follows the growth of a log. It keeps the compact form of the
+// tree at the last verified tree head, which is enough to recompute the
+// root of every later tree from the new entries alone.
+type Monitor struct {
+	Log *Log
+	// STH is the last verified tree head
+	STH *SignedTreeHead
+	// BatchSize is the number of entries requested at once,
+	// DefaultBatchSize when zero
+	BatchSize uint64
+
+	tree *merkle.CompactTree
+}
+
+// NewMonitor starts monitoring the log at its current tree head. The
+// compact tree is rebuilt from the inclusion proof of the last entry,
+// so monitoring can start on a log of any size.
+func NewMonitor(ctx context.Context, l *Log) (*Monitor, error) {
+	sth, err := l.GetSTH(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m := &Monitor{Log: l, STH: sth, tree: new(merkle.CompactTree)}
+	if sth.TreeSize == 0 {
+		if !equal256(sth.SHA256RootHash, merkle.EmptyRoot()) {
+			return nil, errors.New("ct: empty tree head with a non empty root")
+		}
+		return m, nil
+	}
+	entries, err := l.GetEntries(ctx, sth.TreeSize-1, sth.TreeSize-1)
+	if err != nil {
+		return nil, err
+	}
+	leafHash := merkle.LeafHash(entries[0].LeafInput)
+	index, proof, err := l.GetProofByHash(ctx, leafHash, sth.TreeSize)
+	if err != nil {
+		return nil, err
+	}
+	if index != sth.TreeSize-1 {
+		return nil, fmt.Errorf("ct: last entry found at index %d of a tree of size %d", index, sth.TreeSize)
+	}
+	if m.tree, err = merkle.NewCompactFromInclusion(sth.TreeSize, leafHash, proof); err != nil {
+		return nil, err
+	}
+	if !equal256(m.tree.Root(), sth.SHA256RootHash) {
+		return nil, merkle.ErrRootMismatch
+	}
+	return m, nil
+}
+
+// Update fetches the latest tree head, checks that it is consistent with
+// the previous one, and returns the entries added since. The entries are
+// only returned once the root computed from them matches the new tree
+// head, so an error leaves the monitor at its previous state.
+func (m *Monitor) Update(ctx context.Context) ([]*LogEntry, error) {
+	sth, err := m.Log.GetSTH(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if sth.TreeSize < m.STH.TreeSize {
+		return nil, fmt.Errorf("ct: log shrank from %d to %d entries", m.STH.TreeSize, sth.TreeSize)
+	}
+	if err := m.Log.CheckConsistency(ctx, m.STH, sth); err != nil {
+		return nil, err
+	}
+	batch := m.BatchSize
+	if batch == 0 {
+		batch = DefaultBatchSize
+	}
+	tree := m.tree.Clone()
+	var entries []*LogEntry
+	for next := m.STH.TreeSize; next < sth.TreeSize; {
+		end := next + batch - 1
+		if end >= sth.TreeSize {
+			end = sth.TreeSize - 1
+		}
+		got, err := m.Log.GetEntries(ctx, next, end)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range got {
+			tree.AppendHash(merkle.LeafHash(e.LeafInput))
+		}
+		entries = append(entries, got...)
+		next += uint64(len(got))
+	}
+	if !equal256(tree.Root(), sth.SHA256RootHash) {
+		return nil, merkle.ErrRootMismatch
+	}
+	m.tree = tree
+	m.STH = sth
+	return entries, nil
+}
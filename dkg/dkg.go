@@ -0,0 +1,309 @@
+// Package dkg implements Pedersen's distributed key generation over the
+// curves of the ec package: n participants end up with Shamir shares of a
+// key that no one, not even a dealer, ever held, any k of which can sign
+// with FROST or decrypt with threshold ElGamal.
+//
+// Every participant deals a random secret with Feldman VSS: it broadcasts
+// the commitments to its polynomial, with a Schnorr proof of knowledge of
+// the constant term, and sends a share privately to each other
+// participant. A participant whose share does not match the commitments
+// broadcasts a complaint, and the accused dealer answers by publishing the
+// share: everyone checks it, and disqualifies the dealer if the answer is
+// missing or invalid. The group key is the sum of the secrets of the
+// qualified dealers, and the share of a participant the sum of the shares
+// it received from them.
+//
+// The complaint round is simplified: participants are trusted to
+// broadcast the same messages to everyone, and to send their answers.
+// Gennaro, Jarecki, Krawczyk and Rabin also showed that a participant who
+// sees the commitments of the others before deciding to get disqualified
+// can bias the group key. Neither matters for Schnorr signatures, but the
+// key is not uniformly random.
+package dkg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/frost"
+	"github.com/jvehent/badcrypto/vss"
+	"github.com/jvehent/badcrypto/zk/sigma"
+)
+
+// Commitment is the first round broadcast of a participant: the Feldman
+// commitment to its polynomial, and a proof that it knows the discrete
+// logarithm of the first point, which stops it from choosing its
+// commitment to cancel the secrets of the others
+type Commitment struct {
+	Index      uint32
+	Commitment vss.Commitment
+	Proof      *sigma.Proof
+}
+
+// Complaint is broadcast by Accuser when the share Dealer sent does not
+// match the commitment of Dealer
+type Complaint struct {
+	Accuser, Dealer uint32
+}
+
+// KeyShare is the result of the key generation for a participant
+type KeyShare struct {
+	Curve *ec.Curve
+	Index uint32
+	// Secret is the share of the group secret key
+	Secret *big.Int
+	// PublicKey is the group public key
+	PublicKey *ec.Point
+	// VerificationShares holds Secret*G of every participant, the public
+	// keys that check their signature shares or partial decryptions
+	VerificationShares map[uint32]*ec.Point
+	// Qualified lists the dealers whose secrets make the group key
+	Qualified []uint32
+}
+
+// Participant is the state of one participant during the key generation
+type Participant struct {
+	curve *ec.Curve
+	index uint32
+	n, k  int
+	poly  vss.Polynomial
+	// commitments and shares are those of the other dealers, and of the
+	// participant itself
+	commitments  map[uint32]vss.Commitment
+	shares       map[uint32]*big.Int
+	disqualified map[uint32]bool
+}
+
+// proofLabel binds the proof of knowledge to the dealer, so that it
+// cannot be replayed by another participant
+func proofLabel(index uint32) string {
+	return fmt.Sprintf("dkg %d", index)
+}
+
+// NewParticipant starts the key generation for participant index out of
+// n, with threshold k: it picks a random polynomial, and returns the
+// commitment to broadcast
+func NewParticipant(rand io.Reader, c *ec.Curve, index uint32, n, k int) (*Participant, *Commitment, error) {
+	if k < 1 || n < k {
+		return nil, nil, fmt.Errorf("dkg: invalid threshold %d of %d", k, n)
+	}
+	if index < 1 || int64(index) > int64(n) {
+		return nil, nil, fmt.Errorf("dkg: index %d is not between 1 and %d", index, n)
+	}
+	secret, err := c.RandomScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	poly, err := vss.NewPolynomial(rand, c, secret, k)
+	if err != nil {
+		return nil, nil, err
+	}
+	comm := poly.Commit(c)
+	w, err := sigma.NewDLog(c, nil, comm[0]).Witness(secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	proof, err := sigma.Prove(rand, proofLabel(index), w)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := &Participant{
+		curve:        c,
+		index:        index,
+		n:            n,
+		k:            k,
+		poly:         poly,
+		commitments:  map[uint32]vss.Commitment{index: comm},
+		shares:       map[uint32]*big.Int{index: poly.Evaluate(index, c.N)},
+		disqualified: make(map[uint32]bool),
+	}
+	return p, &Commitment{Index: index, Commitment: comm, Proof: proof}, nil
+}
+
+// Index returns the index of the participant
+func (p *Participant) Index() uint32 {
+	return p.index
+}
+
+// Share returns the share for participant to, sent privately
+func (p *Participant) Share(to uint32) vss.Share {
+	return vss.Share{Index: to, Value: p.poly.Evaluate(to, p.curve.N)}
+}
+
+// ReceiveCommitment records the commitment broadcast by another dealer,
+// and disqualifies it if it is malformed or its proof invalid
+func (p *Participant) ReceiveCommitment(comm *Commitment) error {
+	if comm.Index < 1 || int64(comm.Index) > int64(p.n) || comm.Index == p.index {
+		return fmt.Errorf("dkg: commitment from unexpected participant %d", comm.Index)
+	}
+	if _, ok := p.commitments[comm.Index]; ok {
+		return fmt.Errorf("dkg: duplicate commitment from participant %d", comm.Index)
+	}
+	if len(comm.Commitment) != p.k || !sigma.Verify(proofLabel(comm.Index), sigma.NewDLog(p.curve, nil, comm.Commitment[0]), comm.Proof) {
+		p.disqualified[comm.Index] = true
+		return fmt.Errorf("dkg: invalid commitment from participant %d", comm.Index)
+	}
+	p.commitments[comm.Index] = comm.Commitment
+	return nil
+}
+
+// ReceiveShare checks the share sent by dealer against its commitment. It
+// returns a complaint to broadcast if the share is invalid, and nil
+// otherwise.
+func (p *Participant) ReceiveShare(dealer uint32, share vss.Share) *Complaint {
+	comm, ok := p.commitments[dealer]
+	if !ok || dealer == p.index {
+		return &Complaint{Accuser: p.index, Dealer: dealer}
+	}
+	if share.Index != p.index || !vss.VerifyFeldman(p.curve, comm, share) {
+		return &Complaint{Accuser: p.index, Dealer: dealer}
+	}
+	p.shares[dealer] = new(big.Int).Set(share.Value)
+	return nil
+}
+
+// Answer returns the share the participant sent to the accuser of a
+// complaint against it, which it must broadcast
+func (p *Participant) Answer(complaint *Complaint) (vss.Share, error) {
+	if complaint.Dealer != p.index {
+		return vss.Share{}, errors.New("dkg: complaint against another participant")
+	}
+	return p.Share(complaint.Accuser), nil
+}
+
+// ReceiveAnswer processes a complaint and the answer of the accused dealer,
+// nil if it did not answer. An invalid answer disqualifies the dealer, and
+// a valid one replaces the share of the accuser.
+func (p *Participant) ReceiveAnswer(complaint *Complaint, answer *vss.Share) {
+	comm, ok := p.commitments[complaint.Dealer]
+	if !ok || answer == nil || answer.Index != complaint.Accuser || !vss.VerifyFeldman(p.curve, comm, *answer) {
+		p.disqualified[complaint.Dealer] = true
+		return
+	}
+	if complaint.Accuser == p.index {
+		p.shares[complaint.Dealer] = new(big.Int).Set(answer.Value)
+	}
+}
+
+// Finish ends the key generation once every share, complaint and answer
+// has been received, and returns the key share of the participant. Every
+// honest participant computes the same qualified set and public key.
+func (p *Participant) Finish() (*KeyShare, error) {
+	c := p.curve
+	var qualified []uint32
+	for dealer := range p.commitments {
+		if !p.disqualified[dealer] {
+			qualified = append(qualified, dealer)
+		}
+	}
+	sort.Slice(qualified, func(i, j int) bool { return qualified[i] < qualified[j] })
+	if len(qualified) < p.k {
+		return nil, fmt.Errorf("dkg: only %d qualified dealers for a threshold of %d", len(qualified), p.k)
+	}
+
+	// the commitment to the sum of the polynomials is the sum of the
+	// commitments, and evaluates to the verification share of everyone
+	secret := new(big.Int)
+	group := make(vss.Commitment, p.k)
+	for j := range group {
+		group[j] = ec.Infinity()
+	}
+	for _, dealer := range qualified {
+		s, ok := p.shares[dealer]
+		if !ok {
+			return nil, fmt.Errorf("dkg: no valid share from participant %d", dealer)
+		}
+		secret.Add(secret, s)
+		for j, point := range p.commitments[dealer] {
+			group[j] = c.Add(group[j], point)
+		}
+	}
+	secret.Mod(secret, c.N)
+
+	verification := make(map[uint32]*ec.Point, p.n)
+	for i := 1; i <= p.n; i++ {
+		verification[uint32(i)] = evaluate(c, group, uint32(i))
+	}
+	if !c.ScalarBaseMult(secret).Equal(verification[p.index]) {
+		return nil, errors.New("dkg: the key share does not match the group commitment")
+	}
+	return &KeyShare{
+		Curve:              c,
+		Index:              p.index,
+		Secret:             secret,
+		PublicKey:          group[0],
+		VerificationShares: verification,
+		Qualified:          qualified,
+	}, nil
+}
+
+// evaluate returns the sum of x^j * comm[j], the commitment to the value
+// of the polynomial at x
+func evaluate(c *ec.Curve, comm vss.Commitment, x uint32) *ec.Point {
+	scalars := make([]*big.Int, len(comm))
+	xj := big.NewInt(1)
+	for j := range comm {
+		scalars[j] = new(big.Int).Set(xj)
+		xj.Mul(xj, big.NewInt(int64(x)))
+		xj.Mod(xj, c.N)
+	}
+	return c.MultiScalarMult(scalars, comm)
+}
+
+// FROST returns the key share in the form the frost package signs with
+func (ks *KeyShare) FROST() *frost.KeyShare {
+	return &frost.KeyShare{
+		Curve:             ks.Curve,
+		Index:             ks.Index,
+		Secret:            new(big.Int).Set(ks.Secret),
+		VerificationShare: ks.VerificationShares[ks.Index],
+		PublicKey:         ks.PublicKey,
+	}
+}
+
+// Generate runs the key generation among n honest participants in
+// memory, and returns their key shares in the order of their indices
+func Generate(rand io.Reader, c *ec.Curve, n, k int) ([]*KeyShare, error) {
+	participants := make([]*Participant, n)
+	commitments := make([]*Commitment, n)
+	for i := range participants {
+		p, comm, err := NewParticipant(rand, c, uint32(i+1), n, k)
+		if err != nil {
+			return nil, err
+		}
+		participants[i], commitments[i] = p, comm
+	}
+	for _, p := range participants {
+		for _, comm := range commitments {
+			if comm.Index == p.index {
+				continue
+			}
+			if err := p.ReceiveCommitment(comm); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, dealer := range participants {
+		for _, p := range participants {
+			if p == dealer {
+				continue
+			}
+			if complaint := p.ReceiveShare(dealer.index, dealer.Share(p.index)); complaint != nil {
+				return nil, fmt.Errorf("dkg: participant %d complains about %d", complaint.Accuser, complaint.Dealer)
+			}
+		}
+	}
+	shares := make([]*KeyShare, n)
+	for i, p := range participants {
+		ks, err := p.Finish()
+		if err != nil {
+			return nil, err
+		}
+		shares[i] = ks
+	}
+	return shares, nil
+}
@@ -0,0 +1,215 @@
+package dkg
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/frost"
+	"github.com/jvehent/badcrypto/vss"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		curve   *ec.Curve
+		n, k    int
+		signers []int
+	}{
+		{ec.P256(), 3, 2, []int{0, 2}},
+		{ec.Secp256k1(), 5, 3, []int{4, 1, 3}},
+		{ec.P256(), 1, 1, []int{0}},
+	}
+	for i, tc := range testcases {
+		keys, err := Generate(rand.Reader, tc.curve, tc.n, tc.k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var shares []vss.Share
+		for _, j := range tc.signers {
+			if !keys[j].PublicKey.Equal(keys[0].PublicKey) {
+				t.Fatalf("testcase %d expected participants to agree on the public key", i)
+			}
+			shares = append(shares, vss.Share{Index: keys[j].Index, Value: keys[j].Secret})
+		}
+		secret, err := vss.Recover(tc.curve, shares)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !tc.curve.ScalarBaseMult(secret).Equal(keys[0].PublicKey) {
+			t.Fatalf("testcase %d expected %d shares to recover the secret key", i, tc.k)
+		}
+		if tc.k > 1 {
+			secret, err = vss.Recover(tc.curve, shares[1:])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.curve.ScalarBaseMult(secret).Equal(keys[0].PublicKey) {
+				t.Fatalf("testcase %d expected %d shares not to recover the secret key", i, tc.k-1)
+			}
+		}
+	}
+}
+
+func TestFROST(t *testing.T) {
+	t.Parallel()
+	c := ec.Secp256k1()
+	keys, err := Generate(rand.Reader, c, 4, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signers := []*frost.KeyShare{keys[3].FROST(), keys[0].FROST(), keys[2].FROST()}
+	msg := []byte("signed with a dealerless key")
+	nonces := make([]*frost.Nonce, len(signers))
+	commitments := make([]*frost.NonceCommitment, len(signers))
+	for i, ks := range signers {
+		if nonces[i], commitments[i], err = ks.Commit(rand.Reader); err != nil {
+			t.Fatal(err)
+		}
+	}
+	shares := make([]*frost.SignatureShare, len(signers))
+	for i, ks := range signers {
+		if shares[i], err = ks.Sign(msg, nonces[i], commitments); err != nil {
+			t.Fatal(err)
+		}
+		// the verification shares of the other participants check it too
+		if !frost.VerifyShare(c, keys[1].PublicKey, keys[1].VerificationShares[ks.Index], msg, commitments, shares[i]) {
+			t.Fatalf("expected the share of participant %d to verify", ks.Index)
+		}
+	}
+	sig, err := frost.Aggregate(c, keys[0].PublicKey, msg, commitments, shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !frost.Verify(c, keys[0].PublicKey, msg, sig) {
+		t.Fatal("expected the signature to verify")
+	}
+}
+
+// run plays the protocol with a dealer that sends a bad share to
+// participant 1, and answers the complaint with answer
+func run(t *testing.T, answer func(*Participant, *Complaint) *vss.Share) []*KeyShare {
+	c := ec.P256()
+	const n, k = 4, 2
+	participants := make([]*Participant, n)
+	commitments := make([]*Commitment, n)
+	for i := range participants {
+		p, comm, err := NewParticipant(rand.Reader, c, uint32(i+1), n, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		participants[i], commitments[i] = p, comm
+	}
+	for _, p := range participants {
+		for _, comm := range commitments {
+			if comm.Index != p.Index() {
+				if err := p.ReceiveCommitment(comm); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+	var complaints []*Complaint
+	for _, dealer := range participants {
+		for _, p := range participants {
+			if p == dealer {
+				continue
+			}
+			share := dealer.Share(p.Index())
+			if dealer.Index() == 4 && p.Index() == 1 {
+				share.Value = new(big.Int).Add(share.Value, big.NewInt(1))
+			}
+			if complaint := p.ReceiveShare(dealer.Index(), share); complaint != nil {
+				complaints = append(complaints, complaint)
+			}
+		}
+	}
+	if len(complaints) != 1 || *complaints[0] != (Complaint{Accuser: 1, Dealer: 4}) {
+		t.Fatalf("expected a single complaint of 1 against 4 but got %v", complaints)
+	}
+	a := answer(participants[3], complaints[0])
+	for _, p := range participants {
+		p.ReceiveAnswer(complaints[0], a)
+	}
+	keys := make([]*KeyShare, n)
+	for i, p := range participants {
+		ks, err := p.Finish()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = ks
+	}
+	return keys
+}
+
+func TestComplaint(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		answer    func(*Participant, *Complaint) *vss.Share
+		qualified int
+	}{
+		// the dealer publishes the right share, and stays qualified
+		{func(p *Participant, c *Complaint) *vss.Share {
+			s, err := p.Answer(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return &s
+		}, 4},
+		// no answer
+		{func(p *Participant, c *Complaint) *vss.Share { return nil }, 3},
+		// the dealer repeats the bad share
+		{func(p *Participant, c *Complaint) *vss.Share {
+			s := p.Share(c.Accuser)
+			s.Value.Add(s.Value, big.NewInt(1))
+			return &s
+		}, 3},
+	}
+	for i, tc := range testcases {
+		keys := run(t, tc.answer)
+		if len(keys[0].Qualified) != tc.qualified {
+			t.Fatalf("testcase %d expected %d qualified dealers but got %v", i, tc.qualified, keys[0].Qualified)
+		}
+		shares := []vss.Share{{Index: keys[0].Index, Value: keys[0].Secret}, {Index: keys[3].Index, Value: keys[3].Secret}}
+		secret, err := vss.Recover(ec.P256(), shares)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ec.P256().ScalarBaseMult(secret).Equal(keys[2].PublicKey) {
+			t.Fatalf("testcase %d expected the shares to recover the secret key", i)
+		}
+	}
+}
+
+func TestInvalidCommitment(t *testing.T) {
+	t.Parallel()
+	c := ec.P256()
+	p1, _, err := NewParticipant(rand.Reader, c, 1, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, comm2, err := NewParticipant(rand.Reader, c, 2, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a proof made for participant 2 does not verify as participant 3
+	replayed := *comm2
+	replayed.Index = 3
+	if err := p1.ReceiveCommitment(&replayed); err == nil {
+		t.Fatal("expected a replayed proof to be rejected")
+	}
+	if err := p1.ReceiveCommitment(comm2); err != nil {
+		t.Fatal(err)
+	}
+	if err := p1.ReceiveCommitment(comm2); err == nil {
+		t.Fatal("expected a duplicate commitment to be rejected")
+	}
+	// 3 is disqualified, which leaves 1 and 2 for a threshold of 2
+	if _, err := p1.Finish(); err == nil {
+		t.Fatal("expected Finish to fail without a share from participant 2")
+	}
+	if _, _, err := NewParticipant(rand.Reader, c, 4, 3, 2); err == nil {
+		t.Fatal("expected an error for an index above n")
+	}
+}
@@ -0,0 +1,438 @@
+// Package doubleratchet implements the Double Ratchet algorithm of the
+// Signal protocol. Each message is encrypted with a fresh key from a
+// symmetric chain, and every round trip advances a Diffie-Hellman ratchet
+// that reseeds the chains, so past keys cannot be recovered from the
+// current state (forward secrecy) and a compromised state heals after
+// the next ratchet step (post-compromise security).
+//
+// It pairs with the x3dh package: the X3DH shared key is the initial root
+// key, and Bob's signed prekey is his initial ratchet key pair. Header
+// encryption, which hides the ratchet public keys and counters, is
+// optional.
+package doubleratchet
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jvehent/badcrypto/chacha20poly1305"
+	"github.com/jvehent/badcrypto/kdf"
+	"github.com/jvehent/badcrypto/x25519"
+)
+
+// DefaultMaxSkip bounds the number of message keys kept for messages
+// that did not arrive yet, in a single chain
+const DefaultMaxSkip = 1000
+
+// headerLen is the length of an encoded header: the ratchet public key
+// followed by the previous chain length and the message number
+const headerLen = x25519.Size + 8
+
+// Options configures a session
+type Options struct {
+	// Info identifies the application in the key derivations
+	Info []byte
+	// MaxSkip overrides DefaultMaxSkip when positive
+	MaxSkip int
+	// HeaderEncryption hides the message headers
+	HeaderEncryption bool
+	// Rand is the source of ratchet key pairs, crypto/rand when nil
+	Rand io.Reader
+}
+
+// Header carries the sender ratchet public key, the number of messages
+// in the previous sending chain and the number of this message
+type Header struct {
+	DH []byte
+	PN uint32
+	N  uint32
+}
+
+func (h *Header) marshal() []byte {
+	out := make([]byte, headerLen)
+	copy(out, h.DH)
+	binary.BigEndian.PutUint32(out[x25519.Size:], h.PN)
+	binary.BigEndian.PutUint32(out[x25519.Size+4:], h.N)
+	return out
+}
+
+func parseHeader(b []byte) (*Header, error) {
+	if len(b) != headerLen {
+		return nil, errors.New("doubleratchet: malformed header")
+	}
+	return &Header{
+		DH: append([]byte{}, b[:x25519.Size]...),
+		PN: binary.BigEndian.Uint32(b[x25519.Size:]),
+		N:  binary.BigEndian.Uint32(b[x25519.Size+4:]),
+	}, nil
+}
+
+// Message is an encrypted message. Header is the encoded header, or the
+// encrypted header when header encryption is enabled.
+type Message struct {
+	Header     []byte
+	Ciphertext []byte
+}
+
+type skippedKey struct {
+	// dh is the ratchet public key, or the header key with header encryption
+	dh string
+	n  uint32
+}
+
+// Session is one side of a Double Ratchet conversation
+type Session struct {
+	dhs    [2][]byte // private, public
+	dhr    []byte
+	rk     []byte
+	cks    []byte
+	ckr    []byte
+	ns, nr uint32
+	pn     uint32
+
+	// header keys, only used with header encryption
+	hks, hkr   []byte
+	nhks, nhkr []byte
+
+	skipped map[skippedKey][]byte
+	opts    Options
+}
+
+// NewInitiator starts a session for Alice, who knows the shared key from
+// X3DH and Bob's ratchet public key, so she can send right away
+func NewInitiator(sk, remotePub []byte, opts Options) (*Session, error) {
+	s := newSession(opts)
+	var err error
+	if s.dhs, err = s.generateDH(); err != nil {
+		return nil, err
+	}
+	s.dhr = append([]byte{}, remotePub...)
+	s.rk = append([]byte{}, sk...)
+	dh, err := x25519.SharedSecret(s.dhs[0], s.dhr)
+	if err != nil {
+		return nil, err
+	}
+	if s.opts.HeaderEncryption {
+		hka, nhkb, err := sharedHeaderKeys(sk, s.opts.Info)
+		if err != nil {
+			return nil, err
+		}
+		s.hks = hka
+		s.nhkr = nhkb
+	}
+	if err := s.kdfRK(dh, &s.cks, &s.nhks); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewResponder starts a session for Bob with the shared key from X3DH and
+// his ratchet key pair. He can only send after receiving a message.
+func NewResponder(sk, priv, pub []byte, opts Options) (*Session, error) {
+	s := newSession(opts)
+	s.dhs = [2][]byte{append([]byte{}, priv...), append([]byte{}, pub...)}
+	s.rk = append([]byte{}, sk...)
+	if s.opts.HeaderEncryption {
+		hka, nhkb, err := sharedHeaderKeys(sk, s.opts.Info)
+		if err != nil {
+			return nil, err
+		}
+		s.nhks = nhkb
+		s.nhkr = hka
+	}
+	return s, nil
+}
+
+func newSession(opts Options) *Session {
+	if opts.MaxSkip <= 0 {
+		opts.MaxSkip = DefaultMaxSkip
+	}
+	if opts.Rand == nil {
+		opts.Rand = rand.Reader
+	}
+	return &Session{skipped: make(map[skippedKey][]byte), opts: opts}
+}
+
+func (s *Session) generateDH() ([2][]byte, error) {
+	priv, pub, err := x25519.GenerateKey(s.opts.Rand)
+	if err != nil {
+		return [2][]byte{}, err
+	}
+	return [2][]byte{priv[:], pub[:]}, nil
+}
+
+// sharedHeaderKeys derives the initial header keys both parties agree on
+// from the X3DH shared key
+func sharedHeaderKeys(sk, info []byte) (hka, nhkb []byte, err error) {
+	out, err := kdf.HKDF(sha256.New, sk, nil, append([]byte("header keys "), info...), 64)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out[:32], out[32:], nil
+}
+
+// kdfRK is KDF_RK: HKDF keyed by the root key over a DH output, which
+// yields the next root key, a chain key and, with header encryption,
+// the next header key
+func (s *Session) kdfRK(dh []byte, ck, nhk *[]byte) error {
+	n := 64
+	if s.opts.HeaderEncryption {
+		n = 96
+	}
+	out, err := kdf.HKDF(sha256.New, dh, s.rk, s.opts.Info, n)
+	if err != nil {
+		return err
+	}
+	s.rk, *ck = out[:32], out[32:64]
+	if s.opts.HeaderEncryption {
+		*nhk = out[64:]
+	}
+	return nil
+}
+
+// kdfCK is KDF_CK: the message key is HMAC(ck, 0x01) and the next chain
+// key is HMAC(ck, 0x02)
+func kdfCK(ck []byte) (next, mk []byte) {
+	mac := hmac.New(sha256.New, ck)
+	mac.Write([]byte{0x01})
+	mk = mac.Sum(nil)
+	mac.Reset()
+	mac.Write([]byte{0x02})
+	return mac.Sum(nil), mk
+}
+
+// seal encrypts with a single use message key: the ChaCha20-Poly1305
+// key and nonce both come from HKDF over the message key
+func seal(mk, plaintext, ad []byte, info []byte) ([]byte, error) {
+	key, nonce, err := messageKeys(mk, info)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nil, nonce, plaintext, ad), nil
+}
+
+func open(mk, ciphertext, ad []byte, info []byte) ([]byte, error) {
+	key, nonce, err := messageKeys(mk, info)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Open(nil, nonce, ciphertext, ad)
+}
+
+func messageKeys(mk, info []byte) (key, nonce []byte, err error) {
+	out, err := kdf.HKDF(sha256.New, mk, make([]byte, 32), append([]byte("message keys "), info...), 44)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out[:32], out[32:], nil
+}
+
+// header keys are reused for a whole chain, so headers are encrypted
+// with a random nonce sent in front of the ciphertext
+func encryptHeader(hk, header []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(hk)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, header, nil), nil
+}
+
+func decryptHeader(hk, enc []byte) (*Header, error) {
+	if hk == nil || len(enc) < chacha20poly1305.NonceSize {
+		return nil, errors.New("doubleratchet: cannot decrypt header")
+	}
+	aead, err := chacha20poly1305.New(hk)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := aead.Open(nil, enc[:chacha20poly1305.NonceSize], enc[chacha20poly1305.NonceSize:], nil)
+	if err != nil {
+		return nil, err
+	}
+	return parseHeader(plain)
+}
+
+// Encrypt encrypts plaintext and authenticates ad along with the header.
+// The associated data from X3DH should be passed as ad.
+func (s *Session) Encrypt(plaintext, ad []byte) (*Message, error) {
+	if s.cks == nil {
+		return nil, errors.New("doubleratchet: cannot send before receiving a first message")
+	}
+	var mk []byte
+	s.cks, mk = kdfCK(s.cks)
+	header := (&Header{DH: s.dhs[1], PN: s.pn, N: s.ns}).marshal()
+	if s.opts.HeaderEncryption {
+		var err error
+		if header, err = encryptHeader(s.hks, header); err != nil {
+			return nil, err
+		}
+	}
+	s.ns++
+	ciphertext, err := seal(mk, plaintext, append(append([]byte{}, ad...), header...), s.opts.Info)
+	if err != nil {
+		return nil, err
+	}
+	return &Message{Header: header, Ciphertext: ciphertext}, nil
+}
+
+// Decrypt authenticates and decrypts a message. Messages may arrive out
+// of order, in which case the keys of the missing ones are kept. The
+// session is left unchanged when decryption fails.
+func (s *Session) Decrypt(msg *Message, ad []byte) ([]byte, error) {
+	st := s.clone()
+	plaintext, err := st.decrypt(msg, append(append([]byte{}, ad...), msg.Header...))
+	if err != nil {
+		return nil, err
+	}
+	*s = *st
+	return plaintext, nil
+}
+
+func (s *Session) decrypt(msg *Message, ad []byte) ([]byte, error) {
+	// a message from a skipped slot
+	if plaintext, ok, err := s.trySkipped(msg, ad); ok || err != nil {
+		return plaintext, err
+	}
+	var (
+		header *Header
+		err    error
+		step   bool
+	)
+	if s.opts.HeaderEncryption {
+		if header, err = decryptHeader(s.hkr, msg.Header); err != nil {
+			if header, err = decryptHeader(s.nhkr, msg.Header); err != nil {
+				return nil, errors.New("doubleratchet: failed to decrypt header")
+			}
+			step = true
+		}
+	} else {
+		if header, err = parseHeader(msg.Header); err != nil {
+			return nil, err
+		}
+		step = !bytes.Equal(header.DH, s.dhr)
+	}
+	if step {
+		if err := s.skipMessageKeys(header.PN); err != nil {
+			return nil, err
+		}
+		if err := s.dhRatchet(header); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.skipMessageKeys(header.N); err != nil {
+		return nil, err
+	}
+	var mk []byte
+	s.ckr, mk = kdfCK(s.ckr)
+	s.nr++
+	plaintext, err := open(mk, msg.Ciphertext, ad, s.opts.Info)
+	if err != nil {
+		return nil, errors.New("doubleratchet: message authentication failed")
+	}
+	return plaintext, nil
+}
+
+func (s *Session) trySkipped(msg *Message, ad []byte) ([]byte, bool, error) {
+	for slot, mk := range s.skipped {
+		var header *Header
+		if s.opts.HeaderEncryption {
+			h, err := decryptHeader([]byte(slot.dh), msg.Header)
+			if err != nil {
+				continue
+			}
+			header = h
+		} else {
+			h, err := parseHeader(msg.Header)
+			if err != nil || string(h.DH) != slot.dh {
+				continue
+			}
+			header = h
+		}
+		if header.N != slot.n {
+			continue
+		}
+		delete(s.skipped, slot)
+		plaintext, err := open(mk, msg.Ciphertext, ad, s.opts.Info)
+		if err != nil {
+			return nil, true, errors.New("doubleratchet: message authentication failed")
+		}
+		return plaintext, true, nil
+	}
+	return nil, false, nil
+}
+
+// skipMessageKeys stores the keys of the receiving chain up to message
+// number until, so late messages can still be decrypted
+func (s *Session) skipMessageKeys(until uint32) error {
+	if s.ckr == nil {
+		return nil
+	}
+	if uint64(s.nr)+uint64(s.opts.MaxSkip) < uint64(until) {
+		return fmt.Errorf("doubleratchet: too many skipped messages (%d)", until-s.nr)
+	}
+	slotKey := string(s.dhr)
+	if s.opts.HeaderEncryption {
+		slotKey = string(s.hkr)
+	}
+	for s.nr < until {
+		var mk []byte
+		s.ckr, mk = kdfCK(s.ckr)
+		s.skipped[skippedKey{dh: slotKey, n: s.nr}] = mk
+		s.nr++
+	}
+	return nil
+}
+
+// dhRatchet performs a DH ratchet step on receiving a new ratchet key:
+// it derives the receiving chain from the peer's new key, then generates
+// a new key pair and derives the sending chain
+func (s *Session) dhRatchet(header *Header) error {
+	s.pn = s.ns
+	s.ns, s.nr = 0, 0
+	if s.opts.HeaderEncryption {
+		s.hks, s.hkr = s.nhks, s.nhkr
+	}
+	s.dhr = append([]byte{}, header.DH...)
+	dh, err := x25519.SharedSecret(s.dhs[0], s.dhr)
+	if err != nil {
+		return err
+	}
+	if err := s.kdfRK(dh, &s.ckr, &s.nhkr); err != nil {
+		return err
+	}
+	if s.dhs, err = s.generateDH(); err != nil {
+		return err
+	}
+	if dh, err = x25519.SharedSecret(s.dhs[0], s.dhr); err != nil {
+		return err
+	}
+	return s.kdfRK(dh, &s.cks, &s.nhks)
+}
+
+// clone copies the session so a failed decryption can be discarded
+func (s *Session) clone() *Session {
+	st := *s
+	st.skipped = make(map[skippedKey][]byte, len(s.skipped))
+	for k, v := range s.skipped {
+		st.skipped[k] = v
+	}
+	return &st
+}
@@ -0,0 +1,185 @@
+package doubleratchet
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/jvehent/badcrypto/x3dh"
+)
+
+// newPair establishes a session between Alice and Bob with X3DH, Bob's
+// signed prekey serving as his initial ratchet key pair
+func newPair(t *testing.T, opts Options) (alice, bob *Session, ad []byte) {
+	aliceID, err := x3dh.GenerateIdentityKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobID, err := x3dh.GenerateIdentityKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks, err := x3dh.NewKeyStore(rand.Reader, bobID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := ks.Bundle()
+	sk, adA, msg, err := x3dh.Initiate(rand.Reader, aliceID, bundle, opts.Info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	skB, adB, err := ks.Respond(msg, opts.Info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(adA, adB) {
+		t.Fatal("associated data differ")
+	}
+	if alice, err = NewInitiator(sk, bundle.SignedPreKey, opts); err != nil {
+		t.Fatal(err)
+	}
+	if bob, err = NewResponder(skB, ks.SignedPreKey.Private, ks.SignedPreKey.Public, opts); err != nil {
+		t.Fatal(err)
+	}
+	return alice, bob, adA
+}
+
+func send(t *testing.T, from, to *Session, ad []byte, text string) {
+	msg, err := from.Encrypt([]byte(text), ad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := to.Decrypt(msg, ad)
+	if err != nil {
+		t.Fatalf("failed to decrypt %q: %v", text, err)
+	}
+	if string(out) != text {
+		t.Fatalf("expected %q but got %q", text, out)
+	}
+}
+
+func TestConversation(t *testing.T) {
+	t.Parallel()
+	for _, he := range []bool{false, true} {
+		alice, bob, ad := newPair(t, Options{Info: []byte("test"), HeaderEncryption: he})
+		if _, err := bob.Encrypt([]byte("too early"), ad); err == nil {
+			t.Fatal("expected bob to be unable to send first")
+		}
+		send(t, alice, bob, ad, "hello bob")
+		send(t, alice, bob, ad, "are you there?")
+		send(t, bob, alice, ad, "hi alice")
+		send(t, alice, bob, ad, "how are you")
+		send(t, bob, alice, ad, "fine")
+		send(t, bob, alice, ad, "and you?")
+	}
+}
+
+func receive(t *testing.T, s *Session, msg *Message, ad []byte, text string) {
+	out, err := s.Decrypt(msg, ad)
+	if err != nil {
+		t.Fatalf("failed to decrypt %q: %v", text, err)
+	}
+	if string(out) != text {
+		t.Fatalf("expected %q but got %q", text, out)
+	}
+}
+
+func TestOutOfOrder(t *testing.T) {
+	t.Parallel()
+	for _, he := range []bool{false, true} {
+		alice, bob, ad := newPair(t, Options{HeaderEncryption: he})
+		var msgs []*Message
+		for i := 0; i < 5; i++ {
+			msg, err := alice.Encrypt([]byte(fmt.Sprintf("message %d", i)), ad)
+			if err != nil {
+				t.Fatal(err)
+			}
+			msgs = append(msgs, msg)
+		}
+		receive(t, bob, msgs[0], ad, "message 0")
+		receive(t, bob, msgs[3], ad, "message 3")
+		// a round trip moves both sides to new ratchet keys
+		send(t, bob, alice, ad, "got 0 and 3")
+		late, err := alice.Encrypt([]byte("new chain"), ad)
+		if err != nil {
+			t.Fatal(err)
+		}
+		receive(t, bob, late, ad, "new chain")
+		// messages of the previous chain are still readable, once each
+		receive(t, bob, msgs[4], ad, "message 4")
+		receive(t, bob, msgs[1], ad, "message 1")
+		receive(t, bob, msgs[2], ad, "message 2")
+		if _, err := bob.Decrypt(msgs[2], ad); err == nil {
+			t.Fatal("expected a replayed message to fail")
+		}
+	}
+}
+
+func TestSkippedAcrossRatchet(t *testing.T) {
+	t.Parallel()
+	for _, he := range []bool{false, true} {
+		alice, bob, ad := newPair(t, Options{HeaderEncryption: he})
+		send(t, alice, bob, ad, "first")
+		send(t, bob, alice, ad, "reply")
+		// the second message of this chain is lost until the next chain
+		// starts, the PN field of the header tells bob to keep its key
+		m1, _ := alice.Encrypt([]byte("m1"), ad)
+		m2, _ := alice.Encrypt([]byte("m2"), ad)
+		receive(t, bob, m1, ad, "m1")
+		send(t, bob, alice, ad, "reply again")
+		m3, _ := alice.Encrypt([]byte("m3"), ad)
+		receive(t, bob, m3, ad, "m3")
+		receive(t, bob, m2, ad, "m2")
+	}
+}
+
+func TestTampering(t *testing.T) {
+	t.Parallel()
+	for _, he := range []bool{false, true} {
+		alice, bob, ad := newPair(t, Options{HeaderEncryption: he})
+		msg, err := alice.Encrypt([]byte("authentic"), ad)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bad := &Message{Header: msg.Header, Ciphertext: append([]byte{}, msg.Ciphertext...)}
+		bad.Ciphertext[0] ^= 1
+		if _, err := bob.Decrypt(bad, ad); err == nil {
+			t.Fatal("expected a modified ciphertext to fail")
+		}
+		if _, err := bob.Decrypt(msg, []byte("other associated data")); err == nil {
+			t.Fatal("expected different associated data to fail")
+		}
+		// failed attempts must not corrupt the session
+		receive(t, bob, msg, ad, "authentic")
+	}
+}
+
+func TestMaxSkip(t *testing.T) {
+	t.Parallel()
+	alice, bob, ad := newPair(t, Options{MaxSkip: 3})
+	for i := 0; i < 4; i++ {
+		if _, err := alice.Encrypt([]byte("lost"), ad); err != nil {
+			t.Fatal(err)
+		}
+	}
+	msg, err := alice.Encrypt([]byte("too far"), ad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bob.Decrypt(msg, ad); err == nil {
+		t.Fatal("expected a message beyond MaxSkip to be refused")
+	}
+}
+
+func TestHeaderEncryptionHidesKeys(t *testing.T) {
+	t.Parallel()
+	alice, _, ad := newPair(t, Options{HeaderEncryption: true})
+	msg, err := alice.Encrypt([]byte("hidden"), ad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(msg.Header, alice.dhs[1]) {
+		t.Fatal("expected the ratchet public key to be encrypted")
+	}
+}
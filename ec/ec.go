@@ -0,0 +1,425 @@
+// Package ec implements arithmetic on short Weierstrass curves
+// y^2 = x^3 + ax + b over prime fields, with math/big, for the protocols in
+// this repository that need a prime order group: secret sharing
+// commitments, threshold signatures, zero knowledge proofs and the like.
+//
+// Nothing here runs in constant time.
+package ec
+
+import (
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// Curve holds the domain parameters of a curve of prime order N
+type Curve struct {
+	Name   string
+	P      *big.Int // field modulus
+	N      *big.Int // order of the base point
+	A, B   *big.Int // curve coefficients
+	Gx, Gy *big.Int // base point
+}
+
+// Point is an affine point on a curve. The point at infinity has nil
+// coordinates.
+type Point struct {
+	X, Y *big.Int
+}
+
+func fromHex(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("ec: invalid hex constant " + s)
+	}
+	return n
+}
+
+var p256, secp256k1 *Curve
+
+func init() {
+	params := elliptic.P256().Params()
+	p256 = &Curve{
+		Name: "P-256",
+		P:    params.P,
+		N:    params.N,
+		A:    new(big.Int).Sub(params.P, big.NewInt(3)),
+		B:    params.B,
+		Gx:   params.Gx,
+		Gy:   params.Gy,
+	}
+	secp256k1 = &Curve{
+		Name: "secp256k1",
+		P:    fromHex("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f"),
+		N:    fromHex("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141"),
+		A:    big.NewInt(0),
+		B:    big.NewInt(7),
+		Gx:   fromHex("79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"),
+		Gy:   fromHex("483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8"),
+	}
+}
+
+// P256 returns the NIST P-256 curve
+func P256() *Curve { return p256 }
+
+// Secp256k1 returns the curve used by Bitcoin
+func Secp256k1() *Curve { return secp256k1 }
+
+// Infinity returns the identity element
+func Infinity() *Point { return &Point{} }
+
+// IsInfinity reports whether p is the identity element
+func (p *Point) IsInfinity() bool { return p.X == nil }
+
+// Equal reports whether p and q are the same point
+func (p *Point) Equal(q *Point) bool {
+	if p.IsInfinity() || q.IsInfinity() {
+		return p.IsInfinity() && q.IsInfinity()
+	}
+	return p.X.Cmp(q.X) == 0 && p.Y.Cmp(q.Y) == 0
+}
+
+// Generator returns the base point
+func (c *Curve) Generator() *Point {
+	return &Point{X: new(big.Int).Set(c.Gx), Y: new(big.Int).Set(c.Gy)}
+}
+
+// ByteLen is the length of a field element or a scalar in bytes
+func (c *Curve) ByteLen() int {
+	return (c.P.BitLen() + 7) / 8
+}
+
+// rhs computes x^3 + ax + b mod p
+func (c *Curve) rhs(x *big.Int) *big.Int {
+	r := new(big.Int).Mul(x, x)
+	r.Add(r, c.A)
+	r.Mul(r, x)
+	r.Add(r, c.B)
+	return r.Mod(r, c.P)
+}
+
+// IsOnCurve reports whether p satisfies the curve equation. The point at
+// infinity is on every curve.
+func (c *Curve) IsOnCurve(p *Point) bool {
+	if p.IsInfinity() {
+		return true
+	}
+	if p.X.Sign() < 0 || p.X.Cmp(c.P) >= 0 || p.Y.Sign() < 0 || p.Y.Cmp(c.P) >= 0 {
+		return false
+	}
+	y2 := new(big.Int).Mul(p.Y, p.Y)
+	y2.Mod(y2, c.P)
+	return y2.Cmp(c.rhs(p.X)) == 0
+}
+
+// jacobian coordinates (X, Y, Z) represent the affine point (X/Z^2, Y/Z^3)
+// and save a modular inversion on every addition
+type jacobian struct {
+	x, y, z *big.Int
+}
+
+func (c *Curve) toJacobian(p *Point) *jacobian {
+	if p.IsInfinity() {
+		return &jacobian{new(big.Int), new(big.Int), new(big.Int)}
+	}
+	return &jacobian{new(big.Int).Set(p.X), new(big.Int).Set(p.Y), big.NewInt(1)}
+}
+
+func (c *Curve) toAffine(j *jacobian) *Point {
+	if j.z.Sign() == 0 {
+		return Infinity()
+	}
+	zinv := new(big.Int).ModInverse(j.z, c.P)
+	zinv2 := new(big.Int).Mul(zinv, zinv)
+	x := new(big.Int).Mul(j.x, zinv2)
+	x.Mod(x, c.P)
+	y := new(big.Int).Mul(j.y, zinv2)
+	y.Mul(y, zinv)
+	y.Mod(y, c.P)
+	return &Point{X: x, Y: y}
+}
+
+func (c *Curve) double(j *jacobian) *jacobian {
+	if j.z.Sign() == 0 || j.y.Sign() == 0 {
+		return &jacobian{new(big.Int), new(big.Int), new(big.Int)}
+	}
+	p := c.P
+	xx := new(big.Int).Mul(j.x, j.x)
+	yy := new(big.Int).Mul(j.y, j.y)
+	yy.Mod(yy, p)
+	zz := new(big.Int).Mul(j.z, j.z)
+	zz.Mod(zz, p)
+	// s = 4*x*y^2, m = 3*x^2 + a*z^4
+	s := new(big.Int).Mul(j.x, yy)
+	s.Lsh(s, 2)
+	s.Mod(s, p)
+	m := new(big.Int).Mul(xx, big.NewInt(3))
+	if c.A.Sign() != 0 {
+		z4 := new(big.Int).Mul(zz, zz)
+		z4.Mul(z4, c.A)
+		m.Add(m, z4)
+	}
+	m.Mod(m, p)
+	x3 := new(big.Int).Mul(m, m)
+	x3.Sub(x3, new(big.Int).Lsh(s, 1))
+	x3.Mod(x3, p)
+	y4 := new(big.Int).Mul(yy, yy)
+	y3 := new(big.Int).Sub(s, x3)
+	y3.Mul(y3, m)
+	y3.Sub(y3, y4.Lsh(y4, 3))
+	y3.Mod(y3, p)
+	z3 := new(big.Int).Mul(j.y, j.z)
+	z3.Lsh(z3, 1)
+	z3.Mod(z3, p)
+	return &jacobian{x3, y3, z3}
+}
+
+func (c *Curve) add(a, b *jacobian) *jacobian {
+	if a.z.Sign() == 0 {
+		return b
+	}
+	if b.z.Sign() == 0 {
+		return a
+	}
+	p := c.P
+	z1z1 := new(big.Int).Mul(a.z, a.z)
+	z1z1.Mod(z1z1, p)
+	z2z2 := new(big.Int).Mul(b.z, b.z)
+	z2z2.Mod(z2z2, p)
+	u1 := new(big.Int).Mul(a.x, z2z2)
+	u1.Mod(u1, p)
+	u2 := new(big.Int).Mul(b.x, z1z1)
+	u2.Mod(u2, p)
+	s1 := new(big.Int).Mul(a.y, b.z)
+	s1.Mul(s1, z2z2)
+	s1.Mod(s1, p)
+	s2 := new(big.Int).Mul(b.y, a.z)
+	s2.Mul(s2, z1z1)
+	s2.Mod(s2, p)
+	if u1.Cmp(u2) == 0 {
+		if s1.Cmp(s2) == 0 {
+			return c.double(a)
+		}
+		return &jacobian{new(big.Int), new(big.Int), new(big.Int)}
+	}
+	h := new(big.Int).Sub(u2, u1)
+	r := new(big.Int).Sub(s2, s1)
+	hh := new(big.Int).Mul(h, h)
+	hh.Mod(hh, p)
+	hhh := new(big.Int).Mul(hh, h)
+	hhh.Mod(hhh, p)
+	v := new(big.Int).Mul(u1, hh)
+	x3 := new(big.Int).Mul(r, r)
+	x3.Sub(x3, hhh)
+	x3.Sub(x3, new(big.Int).Lsh(v, 1))
+	x3.Mod(x3, p)
+	y3 := new(big.Int).Sub(v, x3)
+	y3.Mul(y3, r)
+	y3.Sub(y3, s1.Mul(s1, hhh))
+	y3.Mod(y3, p)
+	z3 := new(big.Int).Mul(a.z, b.z)
+	z3.Mul(z3, h)
+	z3.Mod(z3, p)
+	return &jacobian{x3, y3, z3}
+}
+
+// Add returns p + q
+func (c *Curve) Add(p, q *Point) *Point {
+	return c.toAffine(c.add(c.toJacobian(p), c.toJacobian(q)))
+}
+
+// Double returns 2p
+func (c *Curve) Double(p *Point) *Point {
+	return c.toAffine(c.double(c.toJacobian(p)))
+}
+
+// Neg returns -p
+func (c *Curve) Neg(p *Point) *Point {
+	if p.IsInfinity() {
+		return Infinity()
+	}
+	y := new(big.Int).Sub(c.P, p.Y)
+	return &Point{X: new(big.Int).Set(p.X), Y: y.Mod(y, c.P)}
+}
+
+// Sub returns p - q
+func (c *Curve) Sub(p, q *Point) *Point {
+	return c.Add(p, c.Neg(q))
+}
+
+// ScalarMult returns k*p, with k reduced modulo the group order
+func (c *Curve) ScalarMult(p *Point, k *big.Int) *Point {
+	k = new(big.Int).Mod(k, c.N)
+	if tracing() {
+		tracef("scalarmult", "%#x * %s on %s, double and add from the top bit of k", k, p, c.Name)
+	}
+	base := c.toJacobian(p)
+	acc := &jacobian{new(big.Int), new(big.Int), new(big.Int)}
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		acc = c.double(acc)
+		if k.Bit(i) == 1 {
+			acc = c.add(acc, base)
+		}
+		if tracing() {
+			step := "double"
+			if k.Bit(i) == 1 {
+				step = "double and add"
+			}
+			tracef("scalarmult", "  bit %d is %d: %s, accumulator %s", i, k.Bit(i), step, c.toAffine(acc))
+		}
+	}
+	result := c.toAffine(acc)
+	if tracing() {
+		tracef("scalarmult", "result %s", result)
+	}
+	return result
+}
+
+// ScalarBaseMult returns k*G
+func (c *Curve) ScalarBaseMult(k *big.Int) *Point {
+	return c.ScalarMult(c.Generator(), k)
+}
+
+// MultiScalarMult returns the sum of scalars[i]*points[i], sharing the
+// doublings between all the terms
+func (c *Curve) MultiScalarMult(scalars []*big.Int, points []*Point) *Point {
+	if len(scalars) != len(points) {
+		panic("ec: mismatched multi scalar multiplication lengths")
+	}
+	ks := make([]*big.Int, len(scalars))
+	bases := make([]*jacobian, len(points))
+	maxLen := 0
+	for i := range scalars {
+		ks[i] = new(big.Int).Mod(scalars[i], c.N)
+		bases[i] = c.toJacobian(points[i])
+		if ks[i].BitLen() > maxLen {
+			maxLen = ks[i].BitLen()
+		}
+	}
+	acc := &jacobian{new(big.Int), new(big.Int), new(big.Int)}
+	for bit := maxLen - 1; bit >= 0; bit-- {
+		acc = c.double(acc)
+		for i, k := range ks {
+			if k.Bit(bit) == 1 {
+				acc = c.add(acc, bases[i])
+			}
+		}
+	}
+	return c.toAffine(acc)
+}
+
+// RandomScalar returns a uniform scalar in [1, N)
+func (c *Curve) RandomScalar(rand io.Reader) (*big.Int, error) {
+	buf := make([]byte, (c.N.BitLen()+7)/8)
+	excess := uint(len(buf)*8 - c.N.BitLen())
+	for {
+		if _, err := io.ReadFull(rand, buf); err != nil {
+			return nil, err
+		}
+		buf[0] &= 0xff >> excess
+		k := new(big.Int).SetBytes(buf)
+		if k.Sign() > 0 && k.Cmp(c.N) < 0 {
+			return k, nil
+		}
+	}
+}
+
+// HashToScalar hashes msg to a scalar modulo N, using SHA-256 in counter
+// mode to produce 16 extra bytes so the bias is negligible
+func (c *Curve) HashToScalar(domain string, msg ...[]byte) *big.Int {
+	need := (c.N.BitLen()+7)/8 + 16
+	var out []byte
+	for ctr := uint32(0); len(out) < need; ctr++ {
+		h := sha256.New()
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], ctr)
+		h.Write(b[:])
+		writeLengthPrefixed(h, []byte(domain))
+		for _, m := range msg {
+			writeLengthPrefixed(h, m)
+		}
+		out = h.Sum(out)
+	}
+	k := new(big.Int).SetBytes(out[:need])
+	return k.Mod(k, c.N)
+}
+
+func writeLengthPrefixed(w io.Writer, b []byte) {
+	var l [8]byte
+	binary.BigEndian.PutUint64(l[:], uint64(len(b)))
+	w.Write(l[:])
+	w.Write(b)
+}
+
+// HashToPoint maps msg to a point with no known discrete logarithm
+// relative to G by try-and-increment: hash to a candidate x until it is on
+// the curve, and pick y by the parity of the hash. This is not the
+// constant time hash to curve of RFC 9380.
+func (c *Curve) HashToPoint(domain string, msg []byte) *Point {
+	for ctr := uint32(0); ; ctr++ {
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], ctr)
+		h := sha256.New()
+		writeLengthPrefixed(h, []byte(domain))
+		writeLengthPrefixed(h, msg)
+		h.Write(b[:])
+		digest := h.Sum(nil)
+		x := new(big.Int).SetBytes(digest)
+		if x.Cmp(c.P) >= 0 {
+			continue
+		}
+		y := new(big.Int).ModSqrt(c.rhs(x), c.P)
+		if y == nil {
+			continue
+		}
+		if y.Bit(0) != uint(digest[0]&1) {
+			y.Sub(c.P, y)
+		}
+		return &Point{X: x, Y: y}
+	}
+}
+
+// Marshal encodes p in the SEC 1 compressed form, or as a single zero
+// byte for the point at infinity
+func (c *Curve) Marshal(p *Point) []byte {
+	if p.IsInfinity() {
+		return []byte{0}
+	}
+	out := make([]byte, 1+c.ByteLen())
+	out[0] = 2 | byte(p.Y.Bit(0))
+	p.X.FillBytes(out[1:])
+	return out
+}
+
+// Unmarshal decodes a point in the SEC 1 compressed or uncompressed form
+// and checks that it is on the curve
+func (c *Curve) Unmarshal(b []byte) (*Point, error) {
+	size := c.ByteLen()
+	switch {
+	case len(b) == 1 && b[0] == 0:
+		return Infinity(), nil
+	case len(b) == 1+size && (b[0] == 2 || b[0] == 3):
+		x := new(big.Int).SetBytes(b[1:])
+		if x.Cmp(c.P) >= 0 {
+			return nil, errors.New("ec: invalid point encoding")
+		}
+		y := new(big.Int).ModSqrt(c.rhs(x), c.P)
+		if y == nil {
+			return nil, errors.New("ec: point is not on the curve")
+		}
+		if y.Bit(0) != uint(b[0]&1) {
+			y.Sub(c.P, y)
+		}
+		return &Point{X: x, Y: y}, nil
+	case len(b) == 1+2*size && b[0] == 4:
+		p := &Point{X: new(big.Int).SetBytes(b[1 : 1+size]), Y: new(big.Int).SetBytes(b[1+size:])}
+		if !c.IsOnCurve(p) {
+			return nil, errors.New("ec: point is not on the curve")
+		}
+		return p, nil
+	}
+	return nil, errors.New("ec: invalid point encoding")
+}
@@ -0,0 +1,110 @@
+package ec
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestP256MatchesStdlib(t *testing.T) {
+	t.Parallel()
+	c := P256()
+	std := elliptic.P256()
+	for i := 0; i < 20; i++ {
+		k, _ := c.RandomScalar(rand.Reader)
+		l, _ := c.RandomScalar(rand.Reader)
+		p := c.ScalarBaseMult(k)
+		x, y := std.ScalarBaseMult(k.Bytes())
+		if p.X.Cmp(x) != 0 || p.Y.Cmp(y) != 0 {
+			t.Fatalf("testcase %d expected %x*G to match crypto/elliptic", i, k)
+		}
+		q := c.ScalarMult(p, l)
+		x, y = std.ScalarMult(x, y, l.Bytes())
+		if q.X.Cmp(x) != 0 || q.Y.Cmp(y) != 0 {
+			t.Fatalf("testcase %d expected scalar mult to match crypto/elliptic", i)
+		}
+		sum := c.Add(p, q)
+		x, y = std.Add(p.X, p.Y, q.X, q.Y)
+		if sum.X.Cmp(x) != 0 || sum.Y.Cmp(y) != 0 {
+			t.Fatalf("testcase %d expected addition to match crypto/elliptic", i)
+		}
+	}
+}
+
+func TestSecp256k1(t *testing.T) {
+	t.Parallel()
+	c := Secp256k1()
+	g := c.Generator()
+	if !c.IsOnCurve(g) {
+		t.Fatal("expected the generator to be on the curve")
+	}
+	two := c.Double(g)
+	if two.X.Cmp(fromHex("c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5")) != 0 ||
+		two.Y.Cmp(fromHex("1ae168fea63dc339a3c58419466ceaeef7f632653266d0e1236431a950cfe52a")) != 0 {
+		t.Fatalf("expected the known value of 2G but got (%x, %x)", two.X, two.Y)
+	}
+	if !c.Add(g, g).Equal(two) || !c.ScalarBaseMult(big.NewInt(2)).Equal(two) {
+		t.Fatal("expected G+G and 2*G to equal 2G")
+	}
+}
+
+func TestGroupLaws(t *testing.T) {
+	t.Parallel()
+	for _, c := range []*Curve{P256(), Secp256k1()} {
+		g := c.Generator()
+		if !c.ScalarBaseMult(c.N).IsInfinity() {
+			t.Fatalf("%s: expected N*G to be the point at infinity", c.Name)
+		}
+		minusOne := new(big.Int).Sub(c.N, big.NewInt(1))
+		if !c.ScalarBaseMult(minusOne).Equal(c.Neg(g)) {
+			t.Fatalf("%s: expected (N-1)*G to be -G", c.Name)
+		}
+		if !c.Sub(g, g).IsInfinity() || !c.Add(g, Infinity()).Equal(g) {
+			t.Fatalf("%s: expected G-G to be infinity and G+0 to be G", c.Name)
+		}
+		a, _ := c.RandomScalar(rand.Reader)
+		b, _ := c.RandomScalar(rand.Reader)
+		ab := new(big.Int).Add(a, b)
+		if !c.ScalarBaseMult(ab).Equal(c.Add(c.ScalarBaseMult(a), c.ScalarBaseMult(b))) {
+			t.Fatalf("%s: expected (a+b)G = aG + bG", c.Name)
+		}
+		h := c.HashToPoint("test", []byte("msg"))
+		if !c.IsOnCurve(h) || !c.ScalarMult(h, c.N).IsInfinity() {
+			t.Fatalf("%s: expected hashed point to be in the group", c.Name)
+		}
+		msm := c.MultiScalarMult([]*big.Int{a, b}, []*Point{g, h})
+		if !msm.Equal(c.Add(c.ScalarMult(g, a), c.ScalarMult(h, b))) {
+			t.Fatalf("%s: expected multi scalar mult to match", c.Name)
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	t.Parallel()
+	for _, c := range []*Curve{P256(), Secp256k1()} {
+		for i := 0; i < 10; i++ {
+			k, _ := c.RandomScalar(rand.Reader)
+			p := c.ScalarBaseMult(k)
+			q, err := c.Unmarshal(c.Marshal(p))
+			if err != nil || !q.Equal(p) {
+				t.Fatalf("%s testcase %d expected compressed round trip, %v", c.Name, i, err)
+			}
+			if c.Name == "P-256" {
+				q, err = c.Unmarshal(elliptic.Marshal(elliptic.P256(), p.X, p.Y))
+				if err != nil || !q.Equal(p) {
+					t.Fatalf("testcase %d expected to parse uncompressed point, %v", i, err)
+				}
+			}
+		}
+		inf, err := c.Unmarshal(c.Marshal(Infinity()))
+		if err != nil || !inf.IsInfinity() {
+			t.Fatalf("%s: expected infinity round trip", c.Name)
+		}
+		bad := append([]byte{4}, make([]byte, 2*c.ByteLen())...)
+		bad[len(bad)-1] = 1
+		if _, err := c.Unmarshal(bad); err == nil {
+			t.Fatalf("%s: expected point off the curve to be rejected", c.Name)
+		}
+	}
+}
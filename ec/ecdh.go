@@ -0,0 +1,36 @@
+package ec
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ECDH returns the x coordinate of priv*peer, the shared secret of an
+// elliptic curve Diffie-Hellman key exchange. It rejects a peer point that
+// is not on the curve or is the point at infinity, curves have a prime
+// order so there is no small subgroup to check.
+func (c *Curve) ECDH(priv *big.Int, peer *Point) ([]byte, error) {
+	if peer.IsInfinity() || !c.IsOnCurve(peer) {
+		return nil, errors.New("ec: invalid peer public key")
+	}
+	s := c.ScalarMult(peer, priv)
+	if s.IsInfinity() {
+		return nil, errors.New("ec: shared secret is the point at infinity")
+	}
+	return s.X.FillBytes(make([]byte, c.ByteLen())), nil
+}
+
+// ECDHUnchecked is ECDH without any validation of peer, deliberately
+// vulnerable for exercises. The addition formulas do not use the b
+// coefficient, so a point off the curve is computed on with the curve
+// y^2 = x^3 + ax + b' it lies on, whose order may have small factors, and
+// the shared secret then leaks priv modulo those. The point at infinity
+// gives zeroes.
+func (c *Curve) ECDHUnchecked(priv *big.Int, peer *Point) []byte {
+	out := make([]byte, c.ByteLen())
+	s := c.ScalarMult(peer, priv)
+	if s.IsInfinity() {
+		return out
+	}
+	return s.X.FillBytes(out)
+}
@@ -0,0 +1,85 @@
+package ec
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/testvectors"
+)
+
+func TestECDH(t *testing.T) {
+	t.Parallel()
+	for _, c := range []*Curve{P256(), Secp256k1()} {
+		a, _ := c.RandomScalar(rand.Reader)
+		b, _ := c.RandomScalar(rand.Reader)
+		ab, err := c.ECDH(a, c.ScalarBaseMult(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ba, err := c.ECDH(b, c.ScalarBaseMult(a))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(ab, ba) || len(ab) != 32 {
+			t.Fatalf("%s expected the same shared secret but got %x and %x", c.Name, ab, ba)
+		}
+		if !bytes.Equal(ab, c.ECDHUnchecked(a, c.ScalarBaseMult(b))) {
+			t.Fatalf("%s expected ECDHUnchecked to agree on valid points", c.Name)
+		}
+		if _, err := c.ECDH(a, Infinity()); err == nil {
+			t.Fatalf("%s expected the point at infinity to be rejected", c.Name)
+		}
+	}
+}
+
+func TestECDHUnchecked(t *testing.T) {
+	t.Parallel()
+	c := P256()
+	// (x, 0) is not on P-256, it has order 2 on y^2 = x^3 + ax + b' with
+	// b' = -x^3 - ax
+	peer := &Point{X: big.NewInt(5), Y: big.NewInt(0)}
+	if _, err := c.ECDH(big.NewInt(3), peer); err == nil {
+		t.Fatal("expected a point off the curve to be rejected")
+	}
+	zero := make([]byte, 32)
+	for _, k := range []int64{2, 3, 1000, 1001} {
+		s := c.ECDHUnchecked(big.NewInt(k), peer)
+		// the shared secret leaks the parity of the private key
+		if k%2 == 0 && !bytes.Equal(s, zero) || k%2 == 1 && new(big.Int).SetBytes(s).Int64() != 5 {
+			t.Fatalf("testcase %d unexpected shared secret %x", k, s)
+		}
+	}
+}
+
+func TestECDHWycheproof(t *testing.T) {
+	t.Parallel()
+	var f testvectors.ECDHFile
+	if err := testvectors.LoadWycheproof("testdata/ecdh_secp256r1_ecpoint_test.json", &f); err != nil {
+		t.Fatal(err)
+	}
+	c := P256()
+	err := f.ForEach(func(g *testvectors.ECDHGroup, tc *testvectors.ECDHTest) error {
+		if g.Curve != "secp256r1" {
+			return fmt.Errorf("unsupported curve %s", g.Curve)
+		}
+		var shared []byte
+		peer, err := c.Unmarshal(tc.Public)
+		if err == nil {
+			shared, err = c.ECDH(new(big.Int).SetBytes(tc.Private), peer)
+		}
+		// compressed points are acceptable and supported by Unmarshal
+		if valid := tc.Result != testvectors.Invalid; valid != (err == nil) {
+			return fmt.Errorf("%s: unexpected result %v", tc, err)
+		}
+		if err == nil && !bytes.Equal(shared, tc.Shared) {
+			return fmt.Errorf("%s: expected shared secret %x but got %x", tc, tc.Shared, shared)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,76 @@
+package ec
+
+import (
+	"crypto/elliptic"
+	"math/big"
+)
+
+// stdCurve adapts a Curve to crypto/elliptic.Curve
+type stdCurve struct {
+	c      *Curve
+	params *elliptic.CurveParams
+}
+
+// Elliptic returns c as a crypto/elliptic.Curve, for code that expects the
+// standard library types, such as crypto/ecdsa or elliptic.Marshal. The
+// point at infinity is (0, 0), as in crypto/elliptic.
+//
+// The CurveParams returned by Params have no room for the coefficient a
+// and their own methods assume a = -3: they are only correct for P-256.
+// Use the methods of the returned Curve, which go through c.
+//
+// There is no crypto/ecdh adapter: ecdh.Curve has unexported methods and
+// cannot be implemented outside of the standard library.
+func (c *Curve) Elliptic() elliptic.Curve {
+	return &stdCurve{c: c, params: &elliptic.CurveParams{
+		P:       c.P,
+		N:       c.N,
+		B:       c.B,
+		Gx:      c.Gx,
+		Gy:      c.Gy,
+		BitSize: c.P.BitLen(),
+		Name:    c.Name,
+	}}
+}
+
+// point converts crypto/elliptic coordinates, mapping (0, 0) to infinity
+func point(x, y *big.Int) *Point {
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return Infinity()
+	}
+	return &Point{X: x, Y: y}
+}
+
+// coordinates converts p to crypto/elliptic coordinates
+func coordinates(p *Point) (x, y *big.Int) {
+	if p.IsInfinity() {
+		return new(big.Int), new(big.Int)
+	}
+	return p.X, p.Y
+}
+
+func (s *stdCurve) Params() *elliptic.CurveParams {
+	return s.params
+}
+
+// IsOnCurve rejects the point at infinity, as crypto/elliptic does
+func (s *stdCurve) IsOnCurve(x, y *big.Int) bool {
+	p := point(x, y)
+	return !p.IsInfinity() && s.c.IsOnCurve(p)
+}
+
+func (s *stdCurve) Add(x1, y1, x2, y2 *big.Int) (x, y *big.Int) {
+	return coordinates(s.c.Add(point(x1, y1), point(x2, y2)))
+}
+
+func (s *stdCurve) Double(x1, y1 *big.Int) (x, y *big.Int) {
+	return coordinates(s.c.Double(point(x1, y1)))
+}
+
+func (s *stdCurve) ScalarMult(x1, y1 *big.Int, k []byte) (x, y *big.Int) {
+	return coordinates(s.c.ScalarMult(point(x1, y1), new(big.Int).SetBytes(k)))
+}
+
+func (s *stdCurve) ScalarBaseMult(k []byte) (x, y *big.Int) {
+	return coordinates(s.c.ScalarBaseMult(new(big.Int).SetBytes(k)))
+}
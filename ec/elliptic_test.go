@@ -0,0 +1,67 @@
+package ec
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+func TestElliptic(t *testing.T) {
+	t.Parallel()
+	for _, c := range []*Curve{P256(), Secp256k1()} {
+		curve := c.Elliptic()
+		if curve.Params().Name != c.Name || curve.Params().BitSize != 256 {
+			t.Fatalf("%s has unexpected params %+v", c.Name, curve.Params())
+		}
+		k, _ := c.RandomScalar(rand.Reader)
+		p := c.ScalarBaseMult(k)
+		x, y := curve.ScalarBaseMult(k.Bytes())
+		if x.Cmp(p.X) != 0 || y.Cmp(p.Y) != 0 || !curve.IsOnCurve(x, y) {
+			t.Fatalf("%s expected ScalarBaseMult to match", c.Name)
+		}
+		dx, dy := curve.Double(x, y)
+		ax, ay := curve.Add(x, y, x, y)
+		if dx.Cmp(ax) != 0 || dy.Cmp(ay) != 0 {
+			t.Fatalf("%s expected P+P to be 2P", c.Name)
+		}
+		// P + -P is infinity, written (0, 0)
+		ix, iy := curve.Add(x, y, x, new(big.Int).Sub(c.P, y))
+		if ix.Sign() != 0 || iy.Sign() != 0 || curve.IsOnCurve(ix, iy) {
+			t.Fatalf("%s expected P-P to be (0, 0) and not on the curve", c.Name)
+		}
+		if zx, zy := curve.Add(ix, iy, x, y); zx.Cmp(x) != 0 || zy.Cmp(y) != 0 {
+			t.Fatalf("%s expected infinity to be the identity", c.Name)
+		}
+		if zx, zy := curve.ScalarMult(x, y, c.N.Bytes()); zx.Sign() != 0 || zy.Sign() != 0 {
+			t.Fatalf("%s expected N*P to be infinity", c.Name)
+		}
+		if curve.IsOnCurve(x, new(big.Int).Add(y, big.NewInt(1))) {
+			t.Fatalf("%s expected a point off the curve to be rejected", c.Name)
+		}
+
+		// elliptic.Unmarshal checks points with the adapter
+		if ux, _ := elliptic.Unmarshal(curve, elliptic.Marshal(curve, x, y)); ux == nil || ux.Cmp(p.X) != 0 {
+			t.Fatalf("%s expected elliptic.Unmarshal to accept the point", c.Name)
+		}
+
+		// crypto/ecdsa only needs the interface
+		priv, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		digest := sha256.Sum256([]byte("stdlib types"))
+		r, s, err := ecdsa.Sign(rand.Reader, priv, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ecdsa.Verify(&priv.PublicKey, digest[:], r, s) {
+			t.Fatalf("%s expected crypto/ecdsa to verify its signature", c.Name)
+		}
+		if !c.IsOnCurve(&Point{X: priv.X, Y: priv.Y}) {
+			t.Fatalf("%s expected the crypto/ecdsa key to be on the curve", c.Name)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package ec
+
+import (
+	"errors"
+	"hash"
+	"math/big"
+)
+
+// ExpandMessageXMD is expand_message_xmd of RFC 9380 section 5.3.1, which
+// stretches msg into length uniform bytes under the domain separation
+// tag dst
+func ExpandMessageXMD(h func() hash.Hash, msg, dst []byte, length int) ([]byte, error) {
+	hh := h()
+	bLen, rLen := hh.Size(), hh.BlockSize()
+	ell := (length + bLen - 1) / bLen
+	if ell > 255 || length > 65535 || len(dst) > 255 {
+		return nil, errors.New("ec: invalid expand_message_xmd parameters")
+	}
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+
+	hh.Write(make([]byte, rLen))
+	hh.Write(msg)
+	hh.Write([]byte{byte(length >> 8), byte(length), 0})
+	hh.Write(dstPrime)
+	b0 := hh.Sum(nil)
+
+	hh = h()
+	hh.Write(b0)
+	hh.Write([]byte{1})
+	hh.Write(dstPrime)
+	bi := hh.Sum(nil)
+	out := append([]byte{}, bi...)
+	for i := 2; i <= ell; i++ {
+		xored := make([]byte, bLen)
+		for j := range xored {
+			xored[j] = b0[j] ^ bi[j]
+		}
+		hh = h()
+		hh.Write(xored)
+		hh.Write([]byte{byte(i)})
+		hh.Write(dstPrime)
+		bi = hh.Sum(nil)
+		out = append(out, bi...)
+	}
+	return out[:length], nil
+}
+
+// HashToField is hash_to_field of RFC 9380 section 5.2 with
+// expand_message_xmd: it returns count elements modulo modulus, each
+// reduced from L uniform bytes
+func HashToField(h func() hash.Hash, msg, dst []byte, count, L int, modulus *big.Int) ([]*big.Int, error) {
+	uniform, err := ExpandMessageXMD(h, msg, dst, count*L)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*big.Int, count)
+	for i := range out {
+		e := new(big.Int).SetBytes(uniform[i*L : (i+1)*L])
+		out[i] = e.Mod(e, modulus)
+	}
+	return out, nil
+}
+
+// sswuZ is the Z parameter of the simplified SWU map for the supported
+// curves, from RFC 9380 section 8
+func (c *Curve) sswuZ() (*big.Int, error) {
+	if c.Name == "P-256" {
+		return big.NewInt(-10), nil
+	}
+	return nil, errors.New("ec: simplified SWU is not supported on " + c.Name)
+}
+
+// HashToCurve is the random oracle encoding P256_XMD:SHA-256_SSWU_RO_ of
+// RFC 9380: hash to two field elements, map both with simplified SWU and
+// add the points. Only P-256 is supported, secp256k1 would need an isogeny.
+func (c *Curve) HashToCurve(h func() hash.Hash, msg, dst []byte) (*Point, error) {
+	if _, err := c.sswuZ(); err != nil {
+		return nil, err
+	}
+	// L = ceil((ceil(log2(p)) + 128) / 8)
+	L := (c.P.BitLen() + 128 + 7) / 8
+	u, err := HashToField(h, msg, dst, 2, L, c.P)
+	if err != nil {
+		return nil, err
+	}
+	q0, err := c.mapToCurveSSWU(u[0])
+	if err != nil {
+		return nil, err
+	}
+	q1, err := c.mapToCurveSSWU(u[1])
+	if err != nil {
+		return nil, err
+	}
+	// the cofactor of P-256 is one, there is nothing to clear
+	return c.Add(q0, q1), nil
+}
+
+// mapToCurveSSWU is the straight line simplified SWU map of RFC 9380
+// section 6.6.2
+func (c *Curve) mapToCurveSSWU(u *big.Int) (*Point, error) {
+	z, err := c.sswuZ()
+	if err != nil {
+		return nil, err
+	}
+	p := c.P
+	mod := func(x *big.Int) *big.Int { return x.Mod(x, p) }
+	u2 := mod(new(big.Int).Mul(u, u))
+	zu2 := mod(new(big.Int).Mul(z, u2))
+	// tv1 = 1 / (Z^2*u^4 + Z*u^2), with 1/0 = 0
+	tv1 := mod(new(big.Int).Add(new(big.Int).Mul(zu2, zu2), zu2))
+	if tv1.Sign() != 0 {
+		tv1.ModInverse(tv1, p)
+	}
+	ainv := new(big.Int).ModInverse(c.A, p)
+	var x1 *big.Int
+	if tv1.Sign() == 0 {
+		// x1 = B / (Z*A)
+		x1 = mod(new(big.Int).Mul(c.B, new(big.Int).ModInverse(mod(new(big.Int).Mul(z, c.A)), p)))
+	} else {
+		// x1 = (-B/A) * (1 + tv1)
+		x1 = new(big.Int).Neg(c.B)
+		x1.Mul(x1, ainv)
+		x1.Mul(x1, new(big.Int).Add(tv1, big.NewInt(1)))
+		mod(x1)
+	}
+	x, y := x1, new(big.Int).ModSqrt(c.rhs(x1), p)
+	if y == nil {
+		x = mod(new(big.Int).Mul(zu2, x1))
+		y = new(big.Int).ModSqrt(c.rhs(x), p)
+		if y == nil {
+			return nil, errors.New("ec: simplified SWU failed to find a square")
+		}
+	}
+	if u.Bit(0) != y.Bit(0) {
+		y.Sub(p, y)
+		mod(y)
+	}
+	return &Point{X: x, Y: y}, nil
+}
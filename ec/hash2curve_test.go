@@ -0,0 +1,42 @@
+package ec
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+// RFC 9380 appendix K.1
+func TestExpandMessageXMD(t *testing.T) {
+	t.Parallel()
+	dst := []byte("QUUX-V01-CS02-with-expander-SHA256-128")
+	out, err := ExpandMessageXMD(sha256.New, nil, dst, 0x20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "68a985b87eb6b46952128911f2a4412bbc302a9d759667f87f7a21d803f07235"
+	if got := hex.EncodeToString(out); got != expected {
+		t.Fatalf("expected %s but got %s", expected, got)
+	}
+}
+
+// RFC 9380 appendix J.1.1
+func TestHashToCurveP256(t *testing.T) {
+	t.Parallel()
+	c := P256()
+	p, err := c.HashToCurve(sha256.New, nil, []byte("QUUX-V01-CS02-with-P256_XMD:SHA-256_SSWU_RO_"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "2c15230b26dbc6fc9a37051158c95b79656e17a1a920b11394ca91c44247d3e4"
+	if got := hex.EncodeToString(p.X.Bytes()); got != expected {
+		t.Fatalf("expected x %s but got %s", expected, got)
+	}
+	expected = "8a7a74985cc5c776cdfe4b1f19884970453912e9d31528c060be9ab5c43e8415"
+	if got := hex.EncodeToString(p.Y.Bytes()); got != expected {
+		t.Fatalf("expected y %s but got %s", expected, got)
+	}
+	if _, err := Secp256k1().HashToCurve(sha256.New, nil, []byte("dst")); err == nil {
+		t.Fatal("expected secp256k1 to be unsupported")
+	}
+}
@@ -0,0 +1,36 @@
+package ec
+
+import (
+	"fmt"
+	"io"
+)
+
+// traceWriter receives the steps of ScalarMult, nil disables tracing
+var traceWriter io.Writer
+
+// SetTraceWriter makes ScalarMult, and so ScalarBaseMult and ECDH, write
+// each doubling and addition of its double and add loop to w with the
+// affine value of the accumulator. A nil w turns tracing off, which is the
+// default. Tracing is global and meant for single threaded examples.
+func SetTraceWriter(w io.Writer) {
+	traceWriter = w
+}
+
+// tracing returns true when steps should be written, converting the
+// accumulator to affine coordinates costs an inversion per bit
+func tracing() bool {
+	return traceWriter != nil
+}
+
+// tracef writes a step of algorithm alg
+func tracef(alg, format string, args ...interface{}) {
+	fmt.Fprintf(traceWriter, alg+": "+format+"\n", args...)
+}
+
+// String formats p as (x, y) in hexadecimal
+func (p *Point) String() string {
+	if p.IsInfinity() {
+		return "infinity"
+	}
+	return fmt.Sprintf("(%#x, %#x)", p.X, p.Y)
+}
@@ -0,0 +1,47 @@
+package ec
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// not parallel, the trace writer is global
+func TestTrace(t *testing.T) {
+	var buf bytes.Buffer
+	SetTraceWriter(&buf)
+	defer SetTraceWriter(nil)
+
+	c := P256()
+	g := c.Generator()
+	// 5 is 0b101: double and add, double, double and add
+	p := c.ScalarMult(g, big.NewInt(5))
+	sum := g
+	for i := 1; i < 5; i++ {
+		sum = c.Add(sum, g)
+	}
+	if !p.Equal(sum) {
+		t.Fatalf("expected 5G to be G+G+G+G+G")
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected 5 trace lines but got %d:\n%s", len(lines), buf.String())
+	}
+	var testcases = []string{
+		"scalarmult: 0x5 * " + g.String() + " on P-256, double and add from the top bit of k",
+		"scalarmult:   bit 2 is 1: double and add, accumulator " + g.String(),
+		"scalarmult:   bit 1 is 0: double, accumulator " + c.Add(g, g).String(),
+		"scalarmult:   bit 0 is 1: double and add, accumulator " + p.String(),
+		"scalarmult: result " + p.String(),
+	}
+	for i, want := range testcases {
+		if lines[i] != want {
+			t.Fatalf("testcase %d expected %q but got %q", i, want, lines[i])
+		}
+	}
+	if got := Infinity().String(); got != "infinity" {
+		t.Fatalf("expected the point at infinity to print as infinity but got %q", got)
+	}
+}
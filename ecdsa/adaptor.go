@@ -0,0 +1,134 @@
+package ecdsa
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/zk/sigma"
+)
+
+// adaptorLabel separates the proofs of adaptor signatures from other uses
+// of the sigma protocols
+const adaptorLabel = "ecdsa adaptor signature"
+
+// AdaptorSignature is an ECDSA signature encrypted under an adaptor point
+// T = t*G, after Aumayr, Ersoy, Erwig, Faust, Hostakova, Maffei,
+// Moreno-Sanchez and Riahi (2021). Completing it with t gives the
+// signature (r, s), and anyone holding both learns t.
+//
+// The signer picks k and sets R = k*T, whose x coordinate is r, and
+// S = k^-1 * (e + r*d), which is s*t. Unlike Schnorr, ECDSA is not linear
+// in the nonce, so the verifier cannot check R alone: the signature
+// carries K = k*G, which the usual verification equation checks against
+// S, and a proof that R and K share the discrete logarithm k relative to
+// T and G.
+type AdaptorSignature struct {
+	R, K  *ec.Point
+	S     *big.Int
+	Proof *sigma.Proof
+}
+
+// adaptorStatement is the DLEQ log_G(K) = log_T(R)
+func adaptorStatement(c *ec.Curve, t *ec.Point, sig *AdaptorSignature) *sigma.DLEQ {
+	return &sigma.DLEQ{C: c, G: c.Generator(), H: t, Y: sig.K, Z: sig.R}
+}
+
+// AdaptorSign signs hash with priv and encrypts the signature under the
+// point t
+func AdaptorSign(rand io.Reader, priv *PrivateKey, hash []byte, t *ec.Point) (*AdaptorSignature, error) {
+	c := priv.Curve
+	if t == nil || t.IsInfinity() || !c.IsOnCurve(t) {
+		return nil, errors.New("ecdsa: invalid adaptor point")
+	}
+	e := hashToInt(c, hash)
+	for {
+		k, err := nonce(rand, priv, hash)
+		if err != nil {
+			return nil, err
+		}
+		sig := &AdaptorSignature{R: c.ScalarMult(t, k), K: c.ScalarBaseMult(k)}
+		r := new(big.Int).Mod(sig.R.X, c.N)
+		if r.Sign() == 0 {
+			continue
+		}
+		// S = k^-1 * (e + r*d) mod n
+		s := new(big.Int).Mul(r, priv.D)
+		s.Add(s, e)
+		s.Mul(s, new(big.Int).ModInverse(k, c.N))
+		s.Mod(s, c.N)
+		if s.Sign() == 0 {
+			continue
+		}
+		sig.S = s
+		sig.Proof, err = sigma.Prove(rand, adaptorLabel, adaptorStatement(c, t, sig).Witness(k))
+		if err != nil {
+			return nil, err
+		}
+		return sig, nil
+	}
+}
+
+// AdaptorVerify checks that sig completes into a signature of hash by pub
+// with the discrete logarithm of t
+func AdaptorVerify(pub *PublicKey, hash []byte, t *ec.Point, sig *AdaptorSignature) bool {
+	c := pub.Curve
+	if sig == nil || sig.R == nil || sig.K == nil || sig.S == nil {
+		return false
+	}
+	for _, p := range []*ec.Point{sig.R, sig.K, t, pub.Point} {
+		if p == nil || p.IsInfinity() || !c.IsOnCurve(p) {
+			return false
+		}
+	}
+	if sig.S.Sign() <= 0 || sig.S.Cmp(c.N) >= 0 {
+		return false
+	}
+	r := new(big.Int).Mod(sig.R.X, c.N)
+	if r.Sign() == 0 {
+		return false
+	}
+	if !sigma.Verify(adaptorLabel, adaptorStatement(c, t, sig), sig.Proof) {
+		return false
+	}
+	// K = S^-1 * (e*G + r*P)
+	e := hashToInt(c, hash)
+	w := new(big.Int).ModInverse(sig.S, c.N)
+	u1 := e.Mul(e, w)
+	u1.Mod(u1, c.N)
+	u2 := w.Mul(r, w)
+	u2.Mod(u2, c.N)
+	return c.MultiScalarMult([]*big.Int{u1, u2}, []*ec.Point{c.Generator(), pub.Point}).Equal(sig.K)
+}
+
+// Complete decrypts an adaptor signature with the secret t and returns
+// the signature (r, s)
+func (sig *AdaptorSignature) Complete(c *ec.Curve, t *big.Int) (r, s *big.Int) {
+	r = new(big.Int).Mod(sig.R.X, c.N)
+	s = new(big.Int).ModInverse(t, c.N)
+	s.Mul(s, sig.S)
+	return r, s.Mod(s, c.N)
+}
+
+// Extract returns the secret t from an adaptor signature and the
+// signature (r, s) it completed into. Since (r, n-s) verifies as well as
+// (r, s), it tries both signs of t.
+func (sig *AdaptorSignature) Extract(c *ec.Curve, t *ec.Point, r, s *big.Int) (*big.Int, error) {
+	if r == nil || s == nil || s.Sign() <= 0 || s.Cmp(c.N) >= 0 ||
+		new(big.Int).Mod(sig.R.X, c.N).Cmp(r) != 0 {
+		return nil, errors.New("ecdsa: signature does not complete the adaptor signature")
+	}
+	// t = S / s
+	secret := new(big.Int).ModInverse(s, c.N)
+	secret.Mul(secret, sig.S)
+	secret.Mod(secret, c.N)
+	if c.ScalarBaseMult(secret).Equal(t) {
+		return secret, nil
+	}
+	secret.Sub(c.N, secret)
+	if c.ScalarBaseMult(secret).Equal(t) {
+		return secret, nil
+	}
+	return nil, errors.New("ecdsa: signature does not complete the adaptor signature")
+}
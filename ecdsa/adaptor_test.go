@@ -0,0 +1,59 @@
+package ecdsa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+func TestAdaptorSignature(t *testing.T) {
+	t.Parallel()
+	hash := sha256.Sum256([]byte("pay 1 coin to bob"))
+	for i, c := range []*ec.Curve{ec.P256(), ec.Secp256k1()} {
+		priv, err := GenerateKey(rand.Reader, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		secret, err := c.RandomScalar(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		point := c.ScalarBaseMult(secret)
+		adaptor, err := AdaptorSign(rand.Reader, priv, hash[:], point)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !AdaptorVerify(&priv.PublicKey, hash[:], point, adaptor) {
+			t.Fatalf("testcase %d expected the adaptor signature to verify", i)
+		}
+		if AdaptorVerify(&priv.PublicKey, hash[:], c.Generator(), adaptor) {
+			t.Fatalf("testcase %d expected the adaptor signature to fail under another point", i)
+		}
+		if AdaptorVerify(&priv.PublicKey, []byte("other"), point, adaptor) {
+			t.Fatalf("testcase %d expected the adaptor signature to fail for another hash", i)
+		}
+		r, s := adaptor.Complete(c, secret)
+		if !Verify(&priv.PublicKey, hash[:], r, s) {
+			t.Fatalf("testcase %d expected the completed signature to verify", i)
+		}
+		got, err := adaptor.Extract(c, point, r, s)
+		if err != nil || got.Cmp(secret) != 0 {
+			t.Fatalf("testcase %d expected to extract the secret but got %v", i, err)
+		}
+		// the other valid signature of the same nonce
+		got, err = adaptor.Extract(c, point, r, new(big.Int).Sub(c.N, s))
+		if err != nil || !c.ScalarBaseMult(got).Equal(point) {
+			t.Fatalf("testcase %d expected to extract the secret from (r, n-s) but got %v", i, err)
+		}
+		r2, s2, err := Sign(rand.Reader, priv, hash[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := adaptor.Extract(c, point, r2, s2); err == nil {
+			t.Fatalf("testcase %d expected an unrelated signature not to extract", i)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Package ecdsa implements the Elliptic Curve Digital Signature Algorithm
+// of FIPS 186-4 over the curves of the ec package. Signatures are
+// interoperable with crypto/ecdsa on P-256, in their raw (r, s) form as
+// well as in the ASN.1 DER form used by X.509 and TLS.
+//
+// The nonce is drawn from the random source, hedged with the private key
+// and the message so that a weak random source does not leak the key.
+// Nothing in here is constant time.
+package ecdsa
+
+import (
+	stdecdsa "crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+// PublicKey is an ECDSA verification key
+type PublicKey struct {
+	Curve *ec.Curve
+	Point *ec.Point
+}
+
+// PrivateKey is an ECDSA signing key
+type PrivateKey struct {
+	PublicKey
+	D *big.Int
+}
+
+// GenerateKey returns a new random key on c
+func GenerateKey(rand io.Reader, c *ec.Curve) (*PrivateKey, error) {
+	d, err := c.RandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{PublicKey: PublicKey{Curve: c, Point: c.ScalarBaseMult(d)}, D: d}, nil
+}
+
+// hashToInt converts a hash to an integer modulo the order of the curve,
+// keeping its leftmost bits when it is longer than the order, as specified
+// by SEC 1 section 4.1.3
+func hashToInt(c *ec.Curve, hash []byte) *big.Int {
+	orderBits := c.N.BitLen()
+	orderBytes := (orderBits + 7) / 8
+	if len(hash) > orderBytes {
+		hash = hash[:orderBytes]
+	}
+	e := new(big.Int).SetBytes(hash)
+	if excess := len(hash)*8 - orderBits; excess > 0 {
+		e.Rsh(e, uint(excess))
+	}
+	return e
+}
+
+// nonce hashes fresh randomness with the key and the message into a
+// scalar, retrying on the negligible chance it is zero
+func nonce(rand io.Reader, priv *PrivateKey, hash []byte) (*big.Int, error) {
+	c := priv.Curve
+	entropy := make([]byte, 32)
+	for {
+		if _, err := io.ReadFull(rand, entropy); err != nil {
+			return nil, err
+		}
+		k := c.HashToScalar("badcrypto ecdsa nonce", priv.D.Bytes(), entropy, hash)
+		if k.Sign() != 0 {
+			return k, nil
+		}
+	}
+}
+
+// Sign signs hash, which should be the digest of a larger message, and
+// returns the signature as the pair of integers (r, s)
+func Sign(rand io.Reader, priv *PrivateKey, hash []byte) (r, s *big.Int, err error) {
+	c := priv.Curve
+	e := hashToInt(c, hash)
+	for {
+		k, err := nonce(rand, priv, hash)
+		if err != nil {
+			return nil, nil, err
+		}
+		// r = x(k*G) mod n
+		r = new(big.Int).Mod(c.ScalarBaseMult(k).X, c.N)
+		if r.Sign() == 0 {
+			continue
+		}
+		// s = k^-1 * (e + r*d) mod n
+		s = new(big.Int).Mul(r, priv.D)
+		s.Add(s, e)
+		s.Mul(s, new(big.Int).ModInverse(k, c.N))
+		s.Mod(s, c.N)
+		if s.Sign() != 0 {
+			return r, s, nil
+		}
+	}
+}
+
+// Verify checks the signature (r, s) of hash by pub
+func Verify(pub *PublicKey, hash []byte, r, s *big.Int) bool {
+	c := pub.Curve
+	if r == nil || s == nil || r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(c.N) >= 0 || s.Cmp(c.N) >= 0 {
+		return false
+	}
+	if pub.Point == nil || pub.Point.IsInfinity() || !c.IsOnCurve(pub.Point) {
+		return false
+	}
+	e := hashToInt(c, hash)
+	w := new(big.Int).ModInverse(s, c.N)
+	u1 := e.Mul(e, w)
+	u1.Mod(u1, c.N)
+	u2 := w.Mul(r, w)
+	u2.Mod(u2, c.N)
+	p := c.MultiScalarMult([]*big.Int{u1, u2}, []*ec.Point{c.Generator(), pub.Point})
+	if p.IsInfinity() {
+		return false
+	}
+	return new(big.Int).Mod(p.X, c.N).Cmp(r) == 0
+}
+
+type signature struct {
+	R, S *big.Int
+}
+
+// SignASN1 signs hash and returns the DER encoded signature
+func SignASN1(rand io.Reader, priv *PrivateKey, hash []byte) ([]byte, error) {
+	r, s, err := Sign(rand, priv, hash)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(signature{r, s})
+}
+
+// VerifyASN1 checks the DER encoded signature sig of hash by pub
+func VerifyASN1(pub *PublicKey, hash, sig []byte) bool {
+	var parsed signature
+	rest, err := asn1.Unmarshal(sig, &parsed)
+	if err != nil || len(rest) != 0 {
+		return false
+	}
+	return Verify(pub, hash, parsed.R, parsed.S)
+}
+
+// FromStandard converts a crypto/ecdsa public key on P-256
+func FromStandard(pub *stdecdsa.PublicKey) (*PublicKey, error) {
+	if pub.Curve != elliptic.P256() {
+		return nil, errors.New("ecdsa: only P-256 keys are supported")
+	}
+	p := &ec.Point{X: new(big.Int).Set(pub.X), Y: new(big.Int).Set(pub.Y)}
+	if !ec.P256().IsOnCurve(p) {
+		return nil, errors.New("ecdsa: point is not on the curve")
+	}
+	return &PublicKey{Curve: ec.P256(), Point: p}, nil
+}
+
+// ParsePKIXPublicKey parses a DER encoded SubjectPublicKeyInfo holding a
+// P-256 ECDSA key
+func ParsePKIXPublicKey(der []byte) (*PublicKey, error) {
+	pub, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	ecpub, ok := pub.(*stdecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("ecdsa: not an ECDSA public key")
+	}
+	return FromStandard(ecpub)
+}
+
+// MarshalPKIXPublicKey encodes a P-256 key as a DER SubjectPublicKeyInfo
+func MarshalPKIXPublicKey(pub *PublicKey) ([]byte, error) {
+	if pub.Curve != ec.P256() {
+		return nil, errors.New("ecdsa: only P-256 keys are supported")
+	}
+	return x509.MarshalPKIXPublicKey(&stdecdsa.PublicKey{Curve: elliptic.P256(), X: pub.Point.X, Y: pub.Point.Y})
+}
@@ -0,0 +1,170 @@
+package ecdsa
+
+import (
+	stdecdsa "crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/testvectors"
+)
+
+func TestSignVerify(t *testing.T) {
+	t.Parallel()
+	h256 := sha256.Sum256([]byte("hello"))
+	h512 := sha512.Sum512([]byte("hello"))
+	var testcases = []struct {
+		curve *ec.Curve
+		hash  []byte
+	}{
+		{ec.P256(), h256[:]},
+		{ec.P256(), h512[:]},
+		{ec.Secp256k1(), []byte{1, 2, 3}},
+	}
+	for i, tc := range testcases {
+		priv, err := GenerateKey(rand.Reader, tc.curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, s, err := Sign(rand.Reader, priv, tc.hash)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if !Verify(&priv.PublicKey, tc.hash, r, s) {
+			t.Fatalf("testcase %d expected signature to verify", i)
+		}
+		other := append([]byte{0xff}, tc.hash[1:]...)
+		if Verify(&priv.PublicKey, other, r, s) {
+			t.Fatalf("testcase %d expected signature of another hash to fail", i)
+		}
+		if Verify(&priv.PublicKey, tc.hash, s, r) {
+			t.Fatalf("testcase %d expected swapped signature to fail", i)
+		}
+		if Verify(&priv.PublicKey, tc.hash, new(big.Int).Add(r, tc.curve.N), s) {
+			t.Fatalf("testcase %d expected r larger than the order to fail", i)
+		}
+	}
+}
+
+func TestStandardLibrary(t *testing.T) {
+	t.Parallel()
+	hash := sha256.Sum256([]byte("interop"))
+	// signed here, verified by crypto/ecdsa
+	priv, err := GenerateKey(rand.Reader, ec.P256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := SignASN1(rand.Reader, priv, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	std := &stdecdsa.PublicKey{Curve: elliptic.P256(), X: priv.Point.X, Y: priv.Point.Y}
+	if !stdecdsa.VerifyASN1(std, hash[:], sig) {
+		t.Fatal("expected crypto/ecdsa to verify the signature")
+	}
+	// signed by crypto/ecdsa, verified here
+	stdPriv, err := stdecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err = stdecdsa.SignASN1(rand.Reader, stdPriv, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := FromStandard(&stdPriv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyASN1(pub, hash[:], sig) {
+		t.Fatal("expected signature of crypto/ecdsa to verify")
+	}
+	parsed, err := ParsePKIXPublicKey(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.Point.Equal(priv.Point) {
+		t.Fatal("expected parsed key to match")
+	}
+	if VerifyASN1(pub, hash[:], append(sig, 0)) {
+		t.Fatal("expected signature with trailing data to fail")
+	}
+}
+
+// hashes maps the hash names of Wycheproof and CAVP files
+var hashes = map[string]func([]byte) []byte{
+	"SHA-256": func(m []byte) []byte { h := sha256.Sum256(m); return h[:] },
+	"SHA-384": func(m []byte) []byte { h := sha512.Sum384(m); return h[:] },
+	"SHA-512": func(m []byte) []byte { h := sha512.Sum512(m); return h[:] },
+}
+
+func TestWycheproof(t *testing.T) {
+	t.Parallel()
+	var f testvectors.ECDSAFile
+	if err := testvectors.LoadWycheproof("testdata/ecdsa_secp256r1_sha256_test.json", &f); err != nil {
+		t.Fatal(err)
+	}
+	err := f.ForEach(func(g *testvectors.ECDSAGroup, tc *testvectors.ECDSATest) error {
+		if g.PublicKey.Curve != "secp256r1" {
+			return fmt.Errorf("unsupported curve %s", g.PublicKey.Curve)
+		}
+		pub, err := ParsePKIXPublicKey(g.PublicKeyDER)
+		if err != nil {
+			return err
+		}
+		hash, ok := hashes[g.SHA]
+		if !ok {
+			return fmt.Errorf("unsupported hash %s", g.SHA)
+		}
+		if valid := VerifyASN1(pub, hash(tc.Msg), tc.Sig); valid != (tc.Result == testvectors.Valid) {
+			return fmt.Errorf("%s: signature verification returned %v", tc, valid)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCAVPSigVer(t *testing.T) {
+	t.Parallel()
+	in, err := os.Open("testdata/SigVer.rsp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	n := 0
+	err = testvectors.ReadSigVer(in, func(tc *testvectors.SigVer) error {
+		if tc.Curve != "P-256" {
+			return nil
+		}
+		hash, ok := hashes[tc.Hash]
+		if !ok {
+			return fmt.Errorf("line %d: unsupported hash %s", tc.Line, tc.Hash)
+		}
+		c := ec.P256()
+		p := &ec.Point{X: new(big.Int).SetBytes(tc.Qx), Y: new(big.Int).SetBytes(tc.Qy)}
+		pub := &PublicKey{Curve: c, Point: p}
+		r, s := new(big.Int).SetBytes(tc.R), new(big.Int).SetBytes(tc.S)
+		if valid := Verify(pub, hash(tc.Msg), r, s); valid != tc.Valid {
+			return fmt.Errorf("line %d: expected validity %v (%s) but got %v", tc.Line, tc.Valid, tc.Reason, valid)
+		}
+		n++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 45 {
+		t.Fatalf("expected 45 P-256 test cases but ran %d", n)
+	}
+}
@@ -0,0 +1,257 @@
+// Package edwards25519 implements the group of the twisted Edwards curve
+// -x^2 + y^2 = 1 + d*x^2*y^2 over GF(2^255-19) used by Ed25519, with
+// math/big and extended coordinates, along with the RFC 8032 encoding.
+//
+// Nothing here runs in constant time.
+package edwards25519
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	// p is the field modulus 2^255-19
+	p = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	// d is -121665/121666
+	d = func() *big.Int {
+		n := big.NewInt(-121665)
+		inv := new(big.Int).ModInverse(big.NewInt(121666), p)
+		n.Mul(n, inv)
+		return n.Mod(n, p)
+	}()
+	d2 = new(big.Int).Mod(new(big.Int).Lsh(d, 1), p)
+
+	// Order is the prime order of the subgroup generated by the base
+	// point, 2^252 + 27742317777372353535851937790883648493
+	Order = func() *big.Int {
+		n, _ := new(big.Int).SetString("27742317777372353535851937790883648493", 10)
+		return n.Add(n, new(big.Int).Lsh(big.NewInt(1), 252))
+	}()
+
+	baseY = func() *big.Int {
+		// y = 4/5
+		n := new(big.Int).ModInverse(big.NewInt(5), p)
+		n.Mul(n, big.NewInt(4))
+		return n.Mod(n, p)
+	}()
+)
+
+// Point is a point on the curve in extended coordinates (X:Y:Z:T) with
+// x = X/Z, y = Y/Z and x*y = T/Z
+type Point struct {
+	x, y, z, t *big.Int
+}
+
+// Identity returns the neutral element (0, 1)
+func Identity() *Point {
+	return &Point{big.NewInt(0), big.NewInt(1), big.NewInt(1), big.NewInt(0)}
+}
+
+// Basepoint returns the generator B of RFC 8032
+func Basepoint() *Point {
+	x, err := recoverX(baseY, 0)
+	if err != nil {
+		panic(err)
+	}
+	return fromAffine(x, baseY)
+}
+
+func fromAffine(x, y *big.Int) *Point {
+	t := new(big.Int).Mul(x, y)
+	return &Point{new(big.Int).Set(x), new(big.Int).Set(y), big.NewInt(1), t.Mod(t, p)}
+}
+
+// ExtendedCoordinates returns copies of the coordinates (X:Y:Z:T) of q,
+// which encodings other than RFC 8032, such as ristretto255, work from
+func (q *Point) ExtendedCoordinates() (X, Y, Z, T *big.Int) {
+	return new(big.Int).Set(q.x), new(big.Int).Set(q.y), new(big.Int).Set(q.z), new(big.Int).Set(q.t)
+}
+
+// NewPointFromExtended returns the point (X:Y:Z:T), after checking that
+// it is on the curve and that X*Y = Z*T
+func NewPointFromExtended(X, Y, Z, T *big.Int) (*Point, error) {
+	q := &Point{
+		new(big.Int).Mod(X, p), new(big.Int).Mod(Y, p),
+		new(big.Int).Mod(Z, p), new(big.Int).Mod(T, p),
+	}
+	if q.z.Sign() == 0 || mulMod(q.x, q.y).Cmp(mulMod(q.z, q.t)) != 0 {
+		return nil, errors.New("edwards25519: invalid extended coordinates")
+	}
+	// (-X^2 + Y^2) * Z^2 = Z^4 + d * X^2 * Y^2
+	x2, y2, z2 := mulMod(q.x, q.x), mulMod(q.y, q.y), mulMod(q.z, q.z)
+	lhs := mulMod(new(big.Int).Sub(y2, x2), z2)
+	rhs := new(big.Int).Add(mulMod(z2, z2), mulMod(d, mulMod(x2, y2)))
+	if lhs.Cmp(rhs.Mod(rhs, p)) != 0 {
+		return nil, errors.New("edwards25519: point is not on the curve")
+	}
+	return q, nil
+}
+
+func (q *Point) affine() (x, y *big.Int) {
+	zinv := new(big.Int).ModInverse(q.z, p)
+	x = new(big.Int).Mul(q.x, zinv)
+	y = new(big.Int).Mul(q.y, zinv)
+	return x.Mod(x, p), y.Mod(y, p)
+}
+
+func mulMod(a, b *big.Int) *big.Int {
+	r := new(big.Int).Mul(a, b)
+	return r.Mod(r, p)
+}
+
+// Add returns q + r, with the complete addition law of Hisil, Wong,
+// Carter and Dawson, so it also doubles
+func (q *Point) Add(r *Point) *Point {
+	a := mulMod(new(big.Int).Sub(q.y, q.x), new(big.Int).Sub(r.y, r.x))
+	b := mulMod(new(big.Int).Add(q.y, q.x), new(big.Int).Add(r.y, r.x))
+	c := mulMod(mulMod(q.t, d2), r.t)
+	dd := mulMod(new(big.Int).Lsh(q.z, 1), r.z)
+	e := new(big.Int).Sub(b, a)
+	f := new(big.Int).Sub(dd, c)
+	g := new(big.Int).Add(dd, c)
+	h := new(big.Int).Add(b, a)
+	return &Point{mulMod(e, f), mulMod(g, h), mulMod(f, g), mulMod(e, h)}
+}
+
+// Neg returns -q
+func (q *Point) Neg() *Point {
+	x := new(big.Int).Sub(p, q.x)
+	t := new(big.Int).Sub(p, q.t)
+	return &Point{x.Mod(x, p), new(big.Int).Set(q.y), new(big.Int).Set(q.z), t.Mod(t, p)}
+}
+
+// Sub returns q - r
+func (q *Point) Sub(r *Point) *Point {
+	return q.Add(r.Neg())
+}
+
+// ScalarMult returns k*q. The scalar is not reduced, so that points
+// outside of the prime order subgroup are handled correctly.
+func (q *Point) ScalarMult(k *big.Int) *Point {
+	if k.Sign() < 0 {
+		return q.Neg().ScalarMult(new(big.Int).Neg(k))
+	}
+	acc := Identity()
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		acc = acc.Add(acc)
+		if k.Bit(i) == 1 {
+			acc = acc.Add(q)
+		}
+	}
+	return acc
+}
+
+// ScalarBaseMult returns k*B
+func ScalarBaseMult(k *big.Int) *Point {
+	return Basepoint().ScalarMult(k)
+}
+
+// MulByCofactor returns 8*q, which maps any point to the prime order
+// subgroup
+func (q *Point) MulByCofactor() *Point {
+	r := q.Add(q)
+	r = r.Add(r)
+	return r.Add(r)
+}
+
+// Equal reports whether q and r are the same point
+func (q *Point) Equal(r *Point) bool {
+	// X1/Z1 == X2/Z2 and Y1/Z1 == Y2/Z2
+	return mulMod(q.x, r.z).Cmp(mulMod(r.x, q.z)) == 0 &&
+		mulMod(q.y, r.z).Cmp(mulMod(r.y, q.z)) == 0
+}
+
+// IsIdentity reports whether q is the neutral element
+func (q *Point) IsIdentity() bool {
+	return q.Equal(Identity())
+}
+
+// IsSmallOrder reports whether q is one of the eight points of order
+// dividing the cofactor
+func (q *Point) IsSmallOrder() bool {
+	return q.MulByCofactor().IsIdentity()
+}
+
+// Bytes encodes q as in RFC 8032 section 5.1.2: y in little endian with
+// the sign of x in the top bit
+func (q *Point) Bytes() []byte {
+	x, y := q.affine()
+	out := make([]byte, 32)
+	y.FillBytes(out)
+	reverse(out)
+	out[31] |= byte(x.Bit(0)) << 7
+	return out
+}
+
+// NewPoint decodes a point as in RFC 8032 section 5.1.3, rejecting non
+// canonical encodings of y
+func NewPoint(b []byte) (*Point, error) {
+	if len(b) != 32 {
+		return nil, errors.New("edwards25519: invalid point length")
+	}
+	buf := append([]byte{}, b...)
+	sign := uint(buf[31] >> 7)
+	buf[31] &= 0x7f
+	reverse(buf)
+	y := new(big.Int).SetBytes(buf)
+	if y.Cmp(p) >= 0 {
+		return nil, errors.New("edwards25519: non canonical point encoding")
+	}
+	x, err := recoverX(y, sign)
+	if err != nil {
+		return nil, err
+	}
+	return fromAffine(x, y), nil
+}
+
+// recoverX solves x^2 = (y^2 - 1) / (d*y^2 + 1) and picks the root of
+// the given sign
+func recoverX(y *big.Int, sign uint) (*big.Int, error) {
+	y2 := mulMod(y, y)
+	u := new(big.Int).Sub(y2, big.NewInt(1))
+	v := new(big.Int).Add(mulMod(d, y2), big.NewInt(1))
+	x2 := mulMod(u, new(big.Int).ModInverse(v, p))
+	x := new(big.Int).ModSqrt(x2, p)
+	if x == nil {
+		return nil, errors.New("edwards25519: point is not on the curve")
+	}
+	if x.Sign() == 0 && sign == 1 {
+		return nil, errors.New("edwards25519: invalid sign of x")
+	}
+	if x.Bit(0) != sign {
+		x.Sub(p, x)
+	}
+	return x, nil
+}
+
+// ScalarFromBytes interprets b as a little endian integer, without
+// reducing it
+func ScalarFromBytes(b []byte) *big.Int {
+	buf := append([]byte{}, b...)
+	reverse(buf)
+	return new(big.Int).SetBytes(buf)
+}
+
+// ScalarBytes encodes k modulo the group order in 32 little endian bytes
+func ScalarBytes(k *big.Int) []byte {
+	out := new(big.Int).Mod(k, Order).FillBytes(make([]byte, 32))
+	reverse(out)
+	return out
+}
+
+// ClampScalar applies the Ed25519 and X25519 bit clamping to the first
+// half of a hashed secret key and returns the scalar
+func ClampScalar(h []byte) *big.Int {
+	buf := append([]byte{}, h[:32]...)
+	buf[0] &= 248
+	buf[31] &= 127
+	buf[31] |= 64
+	return ScalarFromBytes(buf)
+}
+
+func reverse(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
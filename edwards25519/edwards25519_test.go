@@ -0,0 +1,105 @@
+package edwards25519
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+func TestBasepoint(t *testing.T) {
+	t.Parallel()
+	expected := "5866666666666666666666666666666666666666666666666666666666666666"
+	if got := hex.EncodeToString(Basepoint().Bytes()); got != expected {
+		t.Fatalf("expected base point %s but got %s", expected, got)
+	}
+	if !ScalarBaseMult(Order).IsIdentity() {
+		t.Fatal("expected L*B to be the identity")
+	}
+}
+
+func TestMatchesEd25519(t *testing.T) {
+	t.Parallel()
+	for i := 0; i < 10; i++ {
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h := sha512.Sum512(priv.Seed())
+		got := ScalarBaseMult(ClampScalar(h[:])).Bytes()
+		if !bytes.Equal(got, pub) {
+			t.Fatalf("testcase %d expected public key %x but got %x", i, pub, got)
+		}
+		q, err := NewPoint(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(q.Bytes(), pub) {
+			t.Fatalf("testcase %d expected the encoding to round trip", i)
+		}
+	}
+}
+
+func TestGroupLaws(t *testing.T) {
+	t.Parallel()
+	a, b := big.NewInt(123456789), big.NewInt(987654321)
+	pa, pb := ScalarBaseMult(a), ScalarBaseMult(b)
+	if !pa.Add(pb).Equal(ScalarBaseMult(new(big.Int).Add(a, b))) {
+		t.Fatal("expected aB + bB = (a+b)B")
+	}
+	if !pa.Sub(pa).IsIdentity() {
+		t.Fatal("expected aB - aB to be the identity")
+	}
+	if !pa.ScalarMult(b).Equal(pb.ScalarMult(a)) {
+		t.Fatal("expected b(aB) = a(bB)")
+	}
+}
+
+func TestSmallOrder(t *testing.T) {
+	t.Parallel()
+	// (0, -1) has order 2
+	enc := make([]byte, 32)
+	enc[0] = 0xec
+	for i := 1; i < 31; i++ {
+		enc[i] = 0xff
+	}
+	enc[31] = 0x7f
+	q, err := NewPoint(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.IsSmallOrder() || q.IsIdentity() || !q.Add(q).IsIdentity() {
+		t.Fatal("expected (0, -1) to have order two")
+	}
+	if Basepoint().IsSmallOrder() {
+		t.Fatal("expected the base point to not have small order")
+	}
+	enc[0] = 0xee
+	if _, err := NewPoint(enc); err == nil {
+		t.Fatal("expected a non canonical y to be rejected")
+	}
+}
+
+func TestExtendedCoordinates(t *testing.T) {
+	t.Parallel()
+	q := ScalarBaseMult(big.NewInt(42))
+	r, err := NewPointFromExtended(q.ExtendedCoordinates())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Equal(q) {
+		t.Fatal("expected the coordinates to round trip")
+	}
+	X, Y, Z, T := q.ExtendedCoordinates()
+	// the same point with every coordinate scaled
+	two := big.NewInt(2)
+	if _, err := NewPointFromExtended(X.Mul(X, two), Y.Mul(Y, two), Z.Mul(Z, two), T.Mul(T, two)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewPointFromExtended(X, Y.Add(Y, two), Z, T); err == nil {
+		t.Fatal("expected a point off the curve to be rejected")
+	}
+}
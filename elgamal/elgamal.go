@@ -0,0 +1,108 @@
+// Package elgamal implements ElGamal encryption over the curves of the ec
+// package, and its threshold decryption by a quorum of holders of shares
+// of the private key.
+//
+// A message is a point M. Encrypting it to Y = x*G with a random r gives
+// (r*G, M + r*Y), and the private key removes the mask with x*(r*G) =
+// r*Y. Anyone can re-randomize a ciphertext by adding an encryption of
+// the point at infinity, which a mix network uses to shuffle ciphertexts
+// unlinkably, and the sum of two ciphertexts encrypts the sum of their
+// messages. Messages are points: EncodeInt and DecodeInt map small
+// integers m to m*G, which makes the sum of ciphertexts encrypt the sum of
+// the integers, the exponential ElGamal of electronic voting.
+//
+// Ciphertexts are malleable by design, and so not secure against chosen
+// ciphertext attacks.
+package elgamal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+// PublicKey is the point Y = x*G
+type PublicKey struct {
+	Curve *ec.Curve
+	Y     *ec.Point
+}
+
+// PrivateKey is the scalar x
+type PrivateKey struct {
+	PublicKey
+	X *big.Int
+}
+
+// Ciphertext is the pair (r*G, M + r*Y)
+type Ciphertext struct {
+	C1, C2 *ec.Point
+}
+
+// GenerateKey returns a new key pair on curve c
+func GenerateKey(rand io.Reader, c *ec.Curve) (*PrivateKey, error) {
+	x, err := c.RandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{PublicKey: PublicKey{Curve: c, Y: c.ScalarBaseMult(x)}, X: x}, nil
+}
+
+// Encrypt returns an encryption of the point m
+func (pub *PublicKey) Encrypt(rand io.Reader, m *ec.Point) (*Ciphertext, error) {
+	c := pub.Curve
+	if !c.IsOnCurve(m) {
+		return nil, errors.New("elgamal: message is not a point of the curve")
+	}
+	r, err := c.RandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	return &Ciphertext{C1: c.ScalarBaseMult(r), C2: c.Add(m, c.ScalarMult(pub.Y, r))}, nil
+}
+
+// ReEncrypt returns a new encryption of the message of ct, which cannot be
+// linked to ct without the private key
+func (pub *PublicKey) ReEncrypt(rand io.Reader, ct *Ciphertext) (*Ciphertext, error) {
+	zero, err := pub.Encrypt(rand, ec.Infinity())
+	if err != nil {
+		return nil, err
+	}
+	return pub.Add(ct, zero), nil
+}
+
+// Add returns an encryption of the sum of the messages of a and b
+func (pub *PublicKey) Add(a, b *Ciphertext) *Ciphertext {
+	c := pub.Curve
+	return &Ciphertext{C1: c.Add(a.C1, b.C1), C2: c.Add(a.C2, b.C2)}
+}
+
+// Decrypt returns the message of ct, M = C2 - x*C1
+func (priv *PrivateKey) Decrypt(ct *Ciphertext) (*ec.Point, error) {
+	c := priv.Curve
+	if !c.IsOnCurve(ct.C1) || !c.IsOnCurve(ct.C2) {
+		return nil, errors.New("elgamal: ciphertext is not made of points of the curve")
+	}
+	return c.Sub(ct.C2, c.ScalarMult(ct.C1, priv.X)), nil
+}
+
+// EncodeInt returns the point m*G
+func EncodeInt(c *ec.Curve, m int) *ec.Point {
+	return c.ScalarBaseMult(big.NewInt(int64(m)))
+}
+
+// DecodeInt returns the m in [0, max] such that p = m*G, by trying them
+// all
+func DecodeInt(c *ec.Curve, p *ec.Point, max int) (int, error) {
+	q := ec.Infinity()
+	g := c.Generator()
+	for m := 0; m <= max; m++ {
+		if q.Equal(p) {
+			return m, nil
+		}
+		q = c.Add(q, g)
+	}
+	return 0, fmt.Errorf("elgamal: message is not an integer up to %d", max)
+}
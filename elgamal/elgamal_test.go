@@ -0,0 +1,92 @@
+package elgamal
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+func TestEncrypt(t *testing.T) {
+	t.Parallel()
+	for _, c := range []*ec.Curve{ec.P256(), ec.Secp256k1()} {
+		priv, err := GenerateKey(rand.Reader, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := c.HashToPoint("test", []byte("message"))
+		ct, err := priv.Encrypt(rand.Reader, m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := priv.Decrypt(ct); err != nil || !got.Equal(m) {
+			t.Fatalf("expected the message back but got %v, %v", got, err)
+		}
+		re, err := priv.ReEncrypt(rand.Reader, ct)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if re.C1.Equal(ct.C1) || re.C2.Equal(ct.C2) {
+			t.Fatal("expected the re-encryption to differ from the ciphertext")
+		}
+		if got, err := priv.Decrypt(re); err != nil || !got.Equal(m) {
+			t.Fatalf("expected the re-encryption to decrypt to the message but got %v, %v", got, err)
+		}
+		other, err := GenerateKey(rand.Reader, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := other.Decrypt(ct); got.Equal(m) {
+			t.Fatal("expected another key not to decrypt the message")
+		}
+	}
+}
+
+func TestHomomorphicSum(t *testing.T) {
+	t.Parallel()
+	c := ec.P256()
+	priv, err := GenerateKey(rand.Reader, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	votes := []int{1, 0, 1, 1, 0, 1}
+	var sum *Ciphertext
+	for _, v := range votes {
+		ct, err := priv.Encrypt(rand.Reader, EncodeInt(c, v))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sum == nil {
+			sum = ct
+		} else {
+			sum = priv.Add(sum, ct)
+		}
+	}
+	m, err := priv.Decrypt(sum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := DecodeInt(c, m, len(votes)); err != nil || n != 4 {
+		t.Fatalf("expected a tally of 4 but got %d, %v", n, err)
+	}
+	if _, err := DecodeInt(c, m, 3); err == nil {
+		t.Fatal("expected an error for a tally above the maximum")
+	}
+}
+
+func TestInvalidMessage(t *testing.T) {
+	t.Parallel()
+	c := ec.P256()
+	priv, err := GenerateKey(rand.Reader, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := c.Generator()
+	off := &ec.Point{X: g.X, Y: g.X}
+	if _, err := priv.Encrypt(rand.Reader, off); err == nil {
+		t.Fatal("expected an error for a message off the curve")
+	}
+	if _, err := priv.Decrypt(&Ciphertext{C1: off, C2: g}); err == nil {
+		t.Fatal("expected an error for a ciphertext off the curve")
+	}
+}
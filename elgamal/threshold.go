@@ -0,0 +1,98 @@
+package elgamal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/dkg"
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/vss"
+	"github.com/jvehent/badcrypto/zk/sigma"
+)
+
+// proofLabel separates the proofs of partial decryptions from other uses
+// of the sigma protocols
+const proofLabel = "elgamal partial decryption"
+
+// PartialDecryption is the share of the decryption of a ciphertext by the
+// member Index of a quorum: D = x_i*C1 and the proof that it is correct.
+//
+// Threshold decryption splits the private key x into Shamir shares x_i,
+// for instance with the dkg package so that x never exists in one place.
+// Each member of a quorum of k publishes D_i = x_i*C1, and since x is the
+// sum of lambda_i*x_i with the Lagrange coefficients lambda_i of the
+// quorum, x*C1 is the sum of lambda_i*D_i. A member that publishes a
+// wrong D_i would make the quorum decrypt garbage, so D_i comes with a
+// Chaum-Pedersen proof that log_G(Y_i) = log_C1(D_i), where Y_i = x_i*G
+// is the public verification share of the member.
+type PartialDecryption struct {
+	Index uint32
+	D     *ec.Point
+	Proof *sigma.Proof
+}
+
+// ThresholdPublicKey returns the public key of a distributed key
+func ThresholdPublicKey(ks *dkg.KeyShare) *PublicKey {
+	return &PublicKey{Curve: ks.Curve, Y: ks.PublicKey}
+}
+
+// statement is the DLEQ of a partial decryption
+func statement(c *ec.Curve, verificationShare *ec.Point, ct *Ciphertext, d *ec.Point) *sigma.DLEQ {
+	return &sigma.DLEQ{C: c, G: c.Generator(), H: ct.C1, Y: verificationShare, Z: d}
+}
+
+// PartialDecrypt returns the partial decryption of ct by the key share ks
+func PartialDecrypt(rand io.Reader, ks *dkg.KeyShare, ct *Ciphertext) (*PartialDecryption, error) {
+	c := ks.Curve
+	if !c.IsOnCurve(ct.C1) || !c.IsOnCurve(ct.C2) {
+		return nil, errors.New("elgamal: ciphertext is not made of points of the curve")
+	}
+	d := c.ScalarMult(ct.C1, ks.Secret)
+	s := statement(c, c.ScalarBaseMult(ks.Secret), ct, d)
+	proof, err := sigma.Prove(rand, proofLabel, s.Witness(ks.Secret))
+	if err != nil {
+		return nil, err
+	}
+	return &PartialDecryption{Index: ks.Index, D: d, Proof: proof}, nil
+}
+
+// VerifyPartial checks the proof of a partial decryption of ct against the
+// verification share of its member
+func VerifyPartial(c *ec.Curve, verificationShare *ec.Point, ct *Ciphertext, pd *PartialDecryption) bool {
+	if pd == nil || pd.D == nil || verificationShare == nil || !c.IsOnCurve(pd.D) {
+		return false
+	}
+	return sigma.Verify(proofLabel, statement(c, verificationShare, ct, pd.D), pd.Proof)
+}
+
+// Combine checks the partial decryptions of a quorum against the
+// verification shares of the key, and returns the message of ct. The
+// quorum must have at least the threshold number of members, which
+// Combine cannot check: fewer members decrypt to a random point.
+func Combine(c *ec.Curve, verificationShares map[uint32]*ec.Point, ct *Ciphertext, partials []*PartialDecryption) (*ec.Point, error) {
+	if len(partials) == 0 {
+		return nil, errors.New("elgamal: no partial decryptions")
+	}
+	indices := make([]uint32, len(partials))
+	for i, pd := range partials {
+		if pd == nil {
+			return nil, errors.New("elgamal: missing partial decryption")
+		}
+		if !VerifyPartial(c, verificationShares[pd.Index], ct, pd) {
+			return nil, fmt.Errorf("elgamal: invalid partial decryption from member %d", pd.Index)
+		}
+		indices[i] = pd.Index
+	}
+	scalars := make([]*big.Int, len(partials))
+	points := make([]*ec.Point, len(partials))
+	for i, pd := range partials {
+		lambda, err := vss.Lagrange(c, pd.Index, indices)
+		if err != nil {
+			return nil, err
+		}
+		scalars[i], points[i] = lambda, pd.D
+	}
+	return c.Sub(ct.C2, c.MultiScalarMult(scalars, points)), nil
+}
@@ -0,0 +1,98 @@
+package elgamal
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/jvehent/badcrypto/dkg"
+	"github.com/jvehent/badcrypto/ec"
+)
+
+func TestThresholdDecryption(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		curve  *ec.Curve
+		n, k   int
+		quorum []int
+	}{
+		{ec.P256(), 3, 2, []int{0, 2}},
+		{ec.Secp256k1(), 5, 3, []int{4, 1, 3}},
+		{ec.P256(), 4, 2, []int{0, 1, 2, 3}},
+	}
+	for i, tc := range testcases {
+		keys, err := dkg.Generate(rand.Reader, tc.curve, tc.n, tc.k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub := ThresholdPublicKey(keys[0])
+		m := tc.curve.HashToPoint("test", []byte("threshold message"))
+		ct, err := pub.Encrypt(rand.Reader, m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var partials []*PartialDecryption
+		for _, j := range tc.quorum {
+			pd, err := PartialDecrypt(rand.Reader, keys[j], ct)
+			if err != nil {
+				t.Fatal(err)
+			}
+			partials = append(partials, pd)
+		}
+		got, err := Combine(tc.curve, keys[0].VerificationShares, ct, partials)
+		if err != nil {
+			t.Fatalf("testcase %d: %v", i, err)
+		}
+		if !got.Equal(m) {
+			t.Fatalf("testcase %d expected the quorum to decrypt the message", i)
+		}
+		got, err = Combine(tc.curve, keys[0].VerificationShares, ct, partials[:tc.k-1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Equal(m) {
+			t.Fatalf("testcase %d expected fewer than %d members not to decrypt", i, tc.k)
+		}
+	}
+}
+
+func TestInvalidPartialDecryption(t *testing.T) {
+	t.Parallel()
+	c := ec.P256()
+	keys, err := dkg.Generate(rand.Reader, c, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := ThresholdPublicKey(keys[0])
+	ct, err := pub.Encrypt(rand.Reader, c.Generator())
+	if err != nil {
+		t.Fatal(err)
+	}
+	good, err := PartialDecrypt(rand.Reader, keys[0], ct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad, err := PartialDecrypt(rand.Reader, keys[1], ct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a member that shifts its share of the decryption
+	bad.D = c.Add(bad.D, c.Generator())
+	if VerifyPartial(c, keys[1].VerificationShares[2], ct, bad) {
+		t.Fatal("expected a shifted partial decryption to fail")
+	}
+	if _, err := Combine(c, keys[0].VerificationShares, ct, []*PartialDecryption{good, bad}); err == nil {
+		t.Fatal("expected Combine to reject the shifted partial decryption")
+	}
+	// a partial decryption checked against the share of another member
+	if VerifyPartial(c, keys[0].VerificationShares[3], ct, good) {
+		t.Fatal("expected a partial decryption to fail with another verification share")
+	}
+	// and against another ciphertext
+	other, err := pub.Encrypt(rand.Reader, c.Generator())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if VerifyPartial(c, keys[0].VerificationShares[1], other, good) {
+		t.Fatal("expected a partial decryption to fail for another ciphertext")
+	}
+}
@@ -0,0 +1,97 @@
+// Package base58 implements the Base58 encoding used by Bitcoin, which
+// avoids the characters 0, O, I and l, along with the Base58Check variant
+// that appends the first four bytes of a double SHA-256 as a checksum.
+package base58
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var (
+	radix = big.NewInt(58)
+	// ErrChecksum is returned when a Base58Check string has a bad checksum
+	ErrChecksum = errors.New("base58: invalid checksum")
+)
+
+var decodeMap [256]int8
+
+func init() {
+	for i := range decodeMap {
+		decodeMap[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		decodeMap[alphabet[i]] = int8(i)
+	}
+}
+
+// Encode encodes b in Base58. Each leading zero byte becomes a "1".
+func Encode(b []byte) string {
+	zeros := 0
+	for zeros < len(b) && b[zeros] == 0 {
+		zeros++
+	}
+	n := new(big.Int).SetBytes(b)
+	var out []byte
+	mod := new(big.Int)
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		out = append(out, alphabet[mod.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		out = append(out, alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// Decode decodes a Base58 string
+func Decode(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == alphabet[0] {
+		zeros++
+	}
+	n := new(big.Int)
+	for i := 0; i < len(s); i++ {
+		v := decodeMap[s[i]]
+		if v < 0 {
+			return nil, errors.New("base58: invalid character")
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(v)))
+	}
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
+
+func checksum(b []byte) []byte {
+	h := sha256.Sum256(b)
+	h = sha256.Sum256(h[:])
+	return h[:4]
+}
+
+// CheckEncode encodes b in Base58 with a four bytes checksum
+func CheckEncode(b []byte) string {
+	return Encode(append(append([]byte{}, b...), checksum(b)...))
+}
+
+// CheckDecode decodes a Base58Check string and verifies its checksum
+func CheckDecode(s string) ([]byte, error) {
+	b, err := Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 4 {
+		return nil, ErrChecksum
+	}
+	payload, sum := b[:len(b)-4], b[len(b)-4:]
+	if !bytes.Equal(checksum(payload), sum) {
+		return nil, ErrChecksum
+	}
+	return payload, nil
+}
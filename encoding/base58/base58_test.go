@@ -0,0 +1,66 @@
+package base58
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+	// from the base58 test vectors of Bitcoin Core
+	var testcases = []struct {
+		hex, encoded string
+	}{
+		{"", ""},
+		{"61", "2g"},
+		{"626262", "a3gV"},
+		{"636363", "aPEr"},
+		{"73696d706c792061206c6f6e6720737472696e67", "2cFupjhnEsSn59qHXstmK2ffpLv2"},
+		{"00eb15231dfceb60925886b67d065299925915aeb172c06647", "1NS17iag9jJgTHD1VXjvLCEnZuQ3rJDE9L"},
+		{"516b6fcd0f", "ABnLTmg"},
+		{"bf4f89001e670274dd", "3SEo3LWLoPntC"},
+		{"572e4794", "3EFU7m"},
+		{"ecac89cad93923c02321", "EJDM8drfXA6uyA"},
+		{"10c8511e", "Rt5zm"},
+		{"00000000000000000000", "1111111111"},
+	}
+	for i, tc := range testcases {
+		b, _ := hex.DecodeString(tc.hex)
+		if got := Encode(b); got != tc.encoded {
+			t.Fatalf("testcase %d expected %s but got %s", i, tc.encoded, got)
+		}
+		decoded, err := Decode(tc.encoded)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if !bytes.Equal(decoded, b) {
+			t.Fatalf("testcase %d expected %x but got %x", i, b, decoded)
+		}
+	}
+	for _, invalid := range []string{"0", "O", "I", "l", "3mJr0", "abc def"} {
+		if _, err := Decode(invalid); err == nil {
+			t.Fatalf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+	// the address of the genesis block coinbase
+	payload, _ := hex.DecodeString("0062e907b15cbf27d5425399ebf6f0fb50ebb88f18")
+	const address = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+	if got := CheckEncode(payload); got != address {
+		t.Fatalf("expected %s but got %s", address, got)
+	}
+	decoded, err := CheckDecode(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded, payload) {
+		t.Fatalf("expected %x but got %x", payload, decoded)
+	}
+	if _, err := CheckDecode("1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb"); err != ErrChecksum {
+		t.Fatalf("expected a checksum error but got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+// Package bech32 implements the Bech32 encoding of BIP 173: a human
+// readable part, the separator "1", and data in a 32 characters alphabet
+// followed by a six characters BCH checksum. Unlike BIP 173, the total
+// length is not limited to 90 characters, as age keys do not follow it.
+package bech32
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var generator = []uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+// polymod computes the BCH checksum over values
+func polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+// hrpExpand spreads the human readable part over 5 bits values for the
+// checksum: the high bits of each character, a zero, then the low bits
+func hrpExpand(hrp string) []byte {
+	out := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+func verifyChecksum(hrp string, data []byte) bool {
+	return polymod(append(hrpExpand(hrp), data...)) == 1
+}
+
+func createChecksum(hrp string, data []byte) []byte {
+	values := append(hrpExpand(hrp), data...)
+	values = append(values, 0, 0, 0, 0, 0, 0)
+	mod := polymod(values) ^ 1
+	out := make([]byte, 6)
+	for i := range out {
+		out[i] = byte(mod>>uint(5*(5-i))) & 31
+	}
+	return out
+}
+
+// convertBits regroups a slice of fromBits values into toBits values,
+// padding the last group with zeroes when pad is set
+func convertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	var (
+		acc  uint32
+		bits uint
+		out  []byte
+	)
+	maxv := byte(1<<toBits - 1)
+	for _, b := range data {
+		if b>>fromBits != 0 {
+			return nil, errors.New("bech32: invalid data range")
+		}
+		acc = acc<<fromBits | uint32(b)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte(acc>>bits)&maxv)
+		}
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(toBits-bits))&maxv)
+		}
+	} else if bits >= fromBits || byte(acc<<(toBits-bits))&maxv != 0 {
+		return nil, errors.New("bech32: invalid padding")
+	}
+	return out, nil
+}
+
+// Encode encodes data under the human readable part hrp. The result is
+// lowercase, unless hrp is uppercase in which case it is all uppercase.
+func Encode(hrp string, data []byte) (string, error) {
+	if len(hrp) < 1 {
+		return "", errors.New("bech32: empty human readable part")
+	}
+	for _, c := range hrp {
+		if c < 33 || c > 126 {
+			return "", fmt.Errorf("bech32: invalid human readable part character %q", c)
+		}
+	}
+	if strings.ToLower(hrp) != hrp && strings.ToUpper(hrp) != hrp {
+		return "", errors.New("bech32: mixed case human readable part")
+	}
+	upper := strings.ToUpper(hrp) == hrp && strings.ToLower(hrp) != hrp
+	lower := strings.ToLower(hrp)
+	values, err := convertBits(data, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	sb.WriteString(lower)
+	sb.WriteByte('1')
+	for _, v := range append(values, createChecksum(lower, values)...) {
+		sb.WriteByte(charset[v])
+	}
+	if upper {
+		return strings.ToUpper(sb.String()), nil
+	}
+	return sb.String(), nil
+}
+
+// Decode decodes a Bech32 string and returns its human readable part, in
+// lowercase, and its data
+func Decode(s string) (hrp string, data []byte, err error) {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, errors.New("bech32: mixed case string")
+	}
+	s = strings.ToLower(s)
+	pos := strings.LastIndex(s, "1")
+	if pos < 1 || pos+7 > len(s) {
+		return "", nil, errors.New("bech32: separator in invalid position")
+	}
+	hrp = s[:pos]
+	for _, c := range hrp {
+		if c < 33 || c > 126 {
+			return "", nil, fmt.Errorf("bech32: invalid human readable part character %q", c)
+		}
+	}
+	values := make([]byte, 0, len(s)-pos-1)
+	for _, c := range s[pos+1:] {
+		v := strings.IndexRune(charset, c)
+		if v < 0 {
+			return "", nil, fmt.Errorf("bech32: invalid character %q", c)
+		}
+		values = append(values, byte(v))
+	}
+	if !verifyChecksum(hrp, values) {
+		return "", nil, errors.New("bech32: invalid checksum")
+	}
+	data, err = convertBits(values[:len(values)-6], 5, 8, false)
+	if err != nil {
+		return "", nil, err
+	}
+	return hrp, data, nil
+}
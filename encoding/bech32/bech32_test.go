@@ -0,0 +1,81 @@
+package bech32
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDecodeBIP173(t *testing.T) {
+	t.Parallel()
+	// valid checksums from BIP 173
+	var testcases = []string{
+		"A12UEL5L",
+		"a12uel5l",
+		"an83characterlonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1tt5tgs",
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+		"11qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqc8247j",
+		"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w",
+		"?1ezyfcl",
+	}
+	for i, testcase := range testcases {
+		hrp, _, err := Decode(testcase)
+		if err != nil {
+			t.Fatalf("testcase %d expected %q to decode but got %v", i, testcase, err)
+		}
+		pos := strings.LastIndex(strings.ToLower(testcase), "1")
+		if hrp != strings.ToLower(testcase[:pos]) {
+			t.Fatalf("testcase %d expected hrp %q but got %q", i, testcase[:pos], hrp)
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	t.Parallel()
+	// invalid strings from BIP 173
+	var testcases = []string{
+		"pzry9x0s0muk",  // no separator
+		"1pzry9x0s0muk", // empty hrp
+		"x1b4n0q5v",     // invalid data character
+		"li1dgmt3",      // too short checksum
+		"A1G7SGD8",      // checksum calculated with uppercase hrp
+		"10a06t8",       // empty hrp
+		"1qzzfhee",      // empty hrp
+		"a12UEL5L",      // mixed case
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxx", // bad checksum
+	}
+	for i, testcase := range testcases {
+		if _, _, err := Decode(testcase); err == nil {
+			t.Fatalf("testcase %d expected %q to fail decoding", i, testcase)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		hrp  string
+		data []byte
+	}{
+		{"age", bytes.Repeat([]byte{0x42}, 32)},
+		{"AGE-SECRET-KEY-", bytes.Repeat([]byte{0xff}, 32)},
+		{"test", nil},
+		{"test", []byte{0}},
+	}
+	for i, testcase := range testcases {
+		s, err := Encode(testcase.hrp, testcase.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if testcase.hrp == strings.ToUpper(testcase.hrp) && s != strings.ToUpper(s) {
+			t.Fatalf("testcase %d expected an uppercase encoding but got %s", i, s)
+		}
+		hrp, data, err := Decode(s)
+		if err != nil {
+			t.Fatalf("testcase %d failed decoding %s: %v", i, s, err)
+		}
+		if hrp != strings.ToLower(testcase.hrp) || !bytes.Equal(data, testcase.data) {
+			t.Fatalf("testcase %d expected %s %x but got %s %x", i, testcase.hrp, testcase.data, hrp, data)
+		}
+	}
+}
@@ -0,0 +1,468 @@
+// Package cborenc is a minimal CBOR codec (RFC 8949) that always produces
+// the core deterministic encoding of section 4.2: integers and lengths use
+// their shortest form, floats use the shortest width that preserves their
+// value, map keys are sorted by their encoded bytes, and indefinite
+// lengths are never emitted.
+//
+// Decoded values use the following Go types:
+//
+//	unsigned and negative integers  int64, or uint64 above math.MaxInt64
+//	byte strings                    []byte
+//	text strings                    string
+//	arrays                          []interface{}
+//	maps                            map[interface{}]interface{}
+//	tags                            Tag
+//	false, true                     bool
+//	null, undefined                 nil
+//	floats                          float64
+package cborenc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// major types from RFC 8949 section 3.1
+const (
+	majorUnsigned = 0
+	majorNegative = 1
+	majorBytes    = 2
+	majorText     = 3
+	majorArray    = 4
+	majorMap      = 5
+	majorTag      = 6
+	majorSimple   = 7
+)
+
+// simple values and floats from RFC 8949 section 3.3
+const (
+	simpleFalse     = 20
+	simpleTrue      = 21
+	simpleNull      = 22
+	simpleUndefined = 23
+	simpleFloat16   = 25
+	simpleFloat32   = 26
+	simpleFloat64   = 27
+)
+
+// maxDepth limits the nesting of arrays, maps and tags when decoding
+const maxDepth = 64
+
+// Tag is a tagged data item, such as tag 18 that marks a COSE_Sign1
+type Tag struct {
+	Number  uint64
+	Content interface{}
+}
+
+// Marshal returns the deterministic CBOR encoding of v
+func Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encode(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeHead writes the initial byte of an item and its argument in the
+// shortest possible form
+func writeHead(buf *bytes.Buffer, major byte, arg uint64) {
+	switch {
+	case arg < 24:
+		buf.WriteByte(major<<5 | byte(arg))
+	case arg <= math.MaxUint8:
+		buf.WriteByte(major<<5 | 24)
+		buf.WriteByte(byte(arg))
+	case arg <= math.MaxUint16:
+		buf.WriteByte(major<<5 | 25)
+		buf.Write([]byte{byte(arg >> 8), byte(arg)})
+	case arg <= math.MaxUint32:
+		buf.WriteByte(major<<5 | 26)
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], uint32(arg))
+		buf.Write(b[:])
+	default:
+		buf.WriteByte(major<<5 | 27)
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], arg)
+		buf.Write(b[:])
+	}
+}
+
+func encode(buf *bytes.Buffer, v interface{}) error {
+	switch x := v.(type) {
+	case nil:
+		buf.WriteByte(majorSimple<<5 | simpleNull)
+	case bool:
+		if x {
+			buf.WriteByte(majorSimple<<5 | simpleTrue)
+		} else {
+			buf.WriteByte(majorSimple<<5 | simpleFalse)
+		}
+	case int:
+		encodeInt(buf, int64(x))
+	case int8:
+		encodeInt(buf, int64(x))
+	case int16:
+		encodeInt(buf, int64(x))
+	case int32:
+		encodeInt(buf, int64(x))
+	case int64:
+		encodeInt(buf, x)
+	case uint:
+		writeHead(buf, majorUnsigned, uint64(x))
+	case uint8:
+		writeHead(buf, majorUnsigned, uint64(x))
+	case uint16:
+		writeHead(buf, majorUnsigned, uint64(x))
+	case uint32:
+		writeHead(buf, majorUnsigned, uint64(x))
+	case uint64:
+		writeHead(buf, majorUnsigned, x)
+	case float32:
+		encodeFloat(buf, float64(x))
+	case float64:
+		encodeFloat(buf, x)
+	case []byte:
+		writeHead(buf, majorBytes, uint64(len(x)))
+		buf.Write(x)
+	case string:
+		writeHead(buf, majorText, uint64(len(x)))
+		buf.WriteString(x)
+	case Tag:
+		writeHead(buf, majorTag, x.Number)
+		return encode(buf, x.Content)
+	case []interface{}:
+		writeHead(buf, majorArray, uint64(len(x)))
+		for _, item := range x {
+			if err := encode(buf, item); err != nil {
+				return err
+			}
+		}
+	default:
+		rv := reflect.ValueOf(v)
+		switch rv.Kind() {
+		case reflect.Map:
+			return encodeMap(buf, rv)
+		case reflect.Slice, reflect.Array:
+			writeHead(buf, majorArray, uint64(rv.Len()))
+			for i := 0; i < rv.Len(); i++ {
+				if err := encode(buf, rv.Index(i).Interface()); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("cborenc: unsupported type %T", v)
+		}
+	}
+	return nil
+}
+
+func encodeInt(buf *bytes.Buffer, x int64) {
+	if x >= 0 {
+		writeHead(buf, majorUnsigned, uint64(x))
+		return
+	}
+	// negative integers are encoded as -1 - x
+	writeHead(buf, majorNegative, uint64(-(x + 1)))
+}
+
+// encodeMap writes the pairs of a map sorted by the bytewise order of
+// their encoded keys, as required by the deterministic encoding
+func encodeMap(buf *bytes.Buffer, rv reflect.Value) error {
+	type pair struct {
+		key, value []byte
+	}
+	pairs := make([]pair, 0, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		var k, v bytes.Buffer
+		if err := encode(&k, iter.Key().Interface()); err != nil {
+			return err
+		}
+		if err := encode(&v, iter.Value().Interface()); err != nil {
+			return err
+		}
+		pairs = append(pairs, pair{k.Bytes(), v.Bytes()})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return bytes.Compare(pairs[i].key, pairs[j].key) < 0
+	})
+	writeHead(buf, majorMap, uint64(len(pairs)))
+	for i, p := range pairs {
+		if i > 0 && bytes.Equal(p.key, pairs[i-1].key) {
+			return errors.New("cborenc: duplicate map key")
+		}
+		buf.Write(p.key)
+		buf.Write(p.value)
+	}
+	return nil
+}
+
+// encodeFloat writes f using the shortest of half, single or double
+// precision that represents it exactly
+func encodeFloat(buf *bytes.Buffer, f float64) {
+	if math.IsNaN(f) {
+		// the deterministic encoding of NaN is the half precision quiet NaN
+		buf.Write([]byte{majorSimple<<5 | simpleFloat16, 0x7e, 0x00})
+		return
+	}
+	if h, ok := toFloat16(f); ok {
+		buf.Write([]byte{majorSimple<<5 | simpleFloat16, byte(h >> 8), byte(h)})
+		return
+	}
+	if f32 := float32(f); float64(f32) == f {
+		var b [5]byte
+		b[0] = majorSimple<<5 | simpleFloat32
+		binary.BigEndian.PutUint32(b[1:], math.Float32bits(f32))
+		buf.Write(b[:])
+		return
+	}
+	var b [9]byte
+	b[0] = majorSimple<<5 | simpleFloat64
+	binary.BigEndian.PutUint64(b[1:], math.Float64bits(f))
+	buf.Write(b[:])
+}
+
+// toFloat16 converts f to an IEEE 754 half precision float if it can be
+// represented without any loss, including subnormals and infinities
+func toFloat16(f float64) (uint16, bool) {
+	bits := math.Float64bits(f)
+	sign := uint16(bits>>48) & 0x8000
+	exp := int(bits>>52&0x7ff) - 1023
+	mant := bits & (1<<52 - 1)
+	switch {
+	case math.IsInf(f, 0):
+		return sign | 0x7c00, true
+	case f == 0:
+		return sign, true
+	case exp >= -14 && exp <= 15:
+		// normal half: 10 bits of mantissa
+		if mant&(1<<42-1) != 0 {
+			return 0, false
+		}
+		return sign | uint16(exp+15)<<10 | uint16(mant>>42), true
+	case exp >= -24 && exp < -14:
+		// subnormal half: the implicit leading one becomes explicit
+		shift := uint(42 + (-14 - exp))
+		full := mant | 1<<52
+		if full&(1<<shift-1) != 0 {
+			return 0, false
+		}
+		return sign | uint16(full>>shift), true
+	}
+	return 0, false
+}
+
+// fromFloat16 converts a half precision float to a float64
+func fromFloat16(h uint16) float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1.0
+	}
+	exp := int(h >> 10 & 0x1f)
+	mant := float64(h & 0x3ff)
+	switch exp {
+	case 0:
+		return sign * math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			return math.Inf(int(sign))
+		}
+		return math.NaN()
+	}
+	return sign * math.Ldexp(mant+1024, exp-25)
+}
+
+// Unmarshal decodes the single CBOR item contained in data. Trailing
+// bytes after the item are an error. Non-deterministic encodings are
+// accepted, except for indefinite lengths and duplicate map keys.
+func Unmarshal(data []byte) (interface{}, error) {
+	v, rest, err := UnmarshalFirst(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("cborenc: trailing data after item")
+	}
+	return v, nil
+}
+
+// UnmarshalFirst decodes the first CBOR item of data and returns the
+// bytes that follow it, which is how WebAuthn authenticator data embeds
+// a COSE key in the middle of a binary structure.
+func UnmarshalFirst(data []byte) (v interface{}, rest []byte, err error) {
+	d := decoder{data: data}
+	v, err = d.decode(0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return v, d.data[d.off:], nil
+}
+
+// UnmarshalDeterministic decodes data like Unmarshal, but also requires
+// that data is in the core deterministic encoding, such that
+// re-encoding the result gives back the exact same bytes.
+func UnmarshalDeterministic(data []byte) (interface{}, error) {
+	v, err := Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(encoded, data) {
+		return nil, errors.New("cborenc: input is not deterministically encoded")
+	}
+	return v, nil
+}
+
+type decoder struct {
+	data []byte
+	off  int
+}
+
+func (d *decoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.off) {
+		return nil, errors.New("cborenc: unexpected end of data")
+	}
+	b := d.data[d.off : d.off+int(n)]
+	d.off += int(n)
+	return b, nil
+}
+
+// head reads the initial byte and argument of an item
+func (d *decoder) head() (major byte, info byte, arg uint64, err error) {
+	b, err := d.read(1)
+	if err != nil {
+		return
+	}
+	major, info = b[0]>>5, b[0]&0x1f
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info <= 27:
+		var buf []byte
+		buf, err = d.read(1 << (info - 24))
+		if err != nil {
+			return
+		}
+		for _, c := range buf {
+			arg = arg<<8 | uint64(c)
+		}
+	case info == 31:
+		err = errors.New("cborenc: indefinite length items are not supported")
+	default:
+		err = fmt.Errorf("cborenc: reserved additional information %d", info)
+	}
+	return
+}
+
+// hashable returns true if v can be a Go map key. A Tag is comparable as
+// a type, but hashing it panics when its content is a slice or a map.
+func hashable(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case Tag:
+		return hashable(v.Content)
+	}
+	return reflect.TypeOf(v).Comparable()
+}
+
+func (d *decoder) decode(depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, errors.New("cborenc: maximum nesting depth exceeded")
+	}
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case majorUnsigned:
+		if arg > math.MaxInt64 {
+			return arg, nil
+		}
+		return int64(arg), nil
+	case majorNegative:
+		if arg > math.MaxInt64 {
+			return nil, errors.New("cborenc: negative integer overflows int64")
+		}
+		return -1 - int64(arg), nil
+	case majorBytes:
+		b, err := d.read(arg)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{}, b...), nil
+	case majorText:
+		b, err := d.read(arg)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case majorArray:
+		if arg > uint64(len(d.data)-d.off) {
+			return nil, errors.New("cborenc: array length exceeds input")
+		}
+		arr := make([]interface{}, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			item, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, item)
+		}
+		return arr, nil
+	case majorMap:
+		if arg > uint64(len(d.data)-d.off) {
+			return nil, errors.New("cborenc: map length exceeds input")
+		}
+		m := make(map[interface{}]interface{}, arg)
+		for i := uint64(0); i < arg; i++ {
+			k, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			if !hashable(k) {
+				return nil, fmt.Errorf("cborenc: unsupported map key type %T", k)
+			}
+			v, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			if _, dup := m[k]; dup {
+				return nil, errors.New("cborenc: duplicate map key")
+			}
+			m[k] = v
+		}
+		return m, nil
+	case majorTag:
+		content, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return Tag{Number: arg, Content: content}, nil
+	}
+	// major type 7: simple values and floats
+	switch info {
+	case simpleFalse:
+		return false, nil
+	case simpleTrue:
+		return true, nil
+	case simpleNull, simpleUndefined:
+		return nil, nil
+	case simpleFloat16:
+		return fromFloat16(uint16(arg)), nil
+	case simpleFloat32:
+		return float64(math.Float32frombits(uint32(arg))), nil
+	case simpleFloat64:
+		return math.Float64frombits(arg), nil
+	}
+	return nil, fmt.Errorf("cborenc: unsupported simple value %d", arg)
+}
@@ -0,0 +1,177 @@
+package cborenc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestMarshalRFC8949Examples(t *testing.T) {
+	t.Parallel()
+	// examples from RFC 8949 appendix A, using the deterministic encoding
+	var testcases = []struct {
+		v       interface{}
+		encoded string
+	}{
+		{0, "00"},
+		{1, "01"},
+		{10, "0a"},
+		{23, "17"},
+		{24, "1818"},
+		{25, "1819"},
+		{100, "1864"},
+		{1000, "1903e8"},
+		{1000000, "1a000f4240"},
+		{int64(1000000000000), "1b000000e8d4a51000"},
+		{uint64(18446744073709551615), "1bffffffffffffffff"},
+		{-1, "20"},
+		{-10, "29"},
+		{-100, "3863"},
+		{-1000, "3903e7"},
+		{0.0, "f90000"},
+		{math.Copysign(0, -1), "f98000"},
+		{1.0, "f93c00"},
+		{1.1, "fb3ff199999999999a"},
+		{1.5, "f93e00"},
+		{65504.0, "f97bff"},
+		{100000.0, "fa47c35000"},
+		{3.4028234663852886e+38, "fa7f7fffff"},
+		{1.0e+300, "fb7e37e43c8800759c"},
+		{5.960464477539063e-8, "f90001"},
+		{0.00006103515625, "f90400"},
+		{-4.0, "f9c400"},
+		{-4.1, "fbc010666666666666"},
+		{math.Inf(1), "f97c00"},
+		{math.NaN(), "f97e00"},
+		{math.Inf(-1), "f9fc00"},
+		{false, "f4"},
+		{true, "f5"},
+		{nil, "f6"},
+		{[]byte{}, "40"},
+		{[]byte{1, 2, 3, 4}, "4401020304"},
+		{"", "60"},
+		{"a", "6161"},
+		{"IETF", "6449455446"},
+		{"ü", "62c3bc"},
+		{[]interface{}{}, "80"},
+		{[]interface{}{1, 2, 3}, "83010203"},
+		{[]interface{}{1, []interface{}{2, 3}, []int{4, 5}}, "8301820203820405"},
+		{map[int]int{}, "a0"},
+		{map[int]int{3: 4, 1: 2}, "a201020304"},
+		{map[string]interface{}{"b": []int{2, 3}, "a": 1}, "a26161016162820203"},
+		{Tag{0, "2013-03-21T20:04:00Z"}, "c074323031332d30332d32315432303a30343a30305a"},
+		// keys sort by their encoding: 10 < -1 < "z" < [100] in bytewise order
+		{map[interface{}]interface{}{"z": 1, -1: 2, 10: 3}, "a30a03200261" + "7a01"},
+	}
+	for i, testcase := range testcases {
+		encoded, err := Marshal(testcase.v)
+		if err != nil {
+			t.Fatalf("testcase %d failed to encode %v: %v", i, testcase.v, err)
+		}
+		if hex.EncodeToString(encoded) != testcase.encoded {
+			t.Fatalf("testcase %d encoded %v as %x, expected %s", i, testcase.v, encoded, testcase.encoded)
+		}
+		// decoding must give back an item that encodes the same way
+		decoded, err := UnmarshalDeterministic(encoded)
+		if err != nil {
+			t.Fatalf("testcase %d failed to decode %x: %v", i, encoded, err)
+		}
+		reencoded, _ := Marshal(decoded)
+		if !bytes.Equal(reencoded, encoded) {
+			t.Fatalf("testcase %d re-encoded as %x, expected %x", i, reencoded, encoded)
+		}
+	}
+}
+
+func TestUnmarshalTypes(t *testing.T) {
+	t.Parallel()
+	// a2            map of two pairs
+	//   01 6161     1: "a"
+	//   20 82f5f6   -1: [true, null]
+	// followed by a tag 24 wrapping a byte string, decoded separately
+	v, err := Unmarshal(mustHex("a20161612082f5f6"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[interface{}]interface{}{
+		int64(1):  "a",
+		int64(-1): []interface{}{true, nil},
+	}
+	if !reflect.DeepEqual(v, expected) {
+		t.Fatalf("decoded %#v, expected %#v", v, expected)
+	}
+	v, err = Unmarshal(mustHex("d8184101"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v, Tag{24, []byte{1}}) {
+		t.Fatalf("decoded %#v, expected tag 24", v)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	t.Parallel()
+	var testcases = []string{
+		"",           // empty input
+		"18",         // missing argument
+		"62c3",       // truncated text string
+		"5f4101ff",   // indefinite length byte string
+		"a201020103", // duplicate map key
+		"1c",         // reserved additional information
+		"0000",       // trailing data
+		"9bffffffffffffffff",
+		"3bffffffffffffffff", // negative integer that does not fit in an int64
+		"a1c98030",           // map key of a tag wrapping an array
+		"a1c9c9a030",         // map key of nested tags wrapping a map
+	}
+	for i, testcase := range testcases {
+		if _, err := Unmarshal(mustHex(testcase)); err == nil {
+			t.Fatalf("testcase %d expected an error decoding %s", i, testcase)
+		}
+	}
+	// non-shortest encodings are only rejected in deterministic mode
+	for i, testcase := range []string{"1801", "a203040102", "fa3fc00000"} {
+		if _, err := Unmarshal(mustHex(testcase)); err != nil {
+			t.Fatalf("testcase %d failed to decode %s: %v", i, testcase, err)
+		}
+		if _, err := UnmarshalDeterministic(mustHex(testcase)); err == nil {
+			t.Fatalf("testcase %d expected %s to be rejected as non deterministic", i, testcase)
+		}
+	}
+}
+
+func TestUnmarshalFirst(t *testing.T) {
+	t.Parallel()
+	v, rest, err := UnmarshalFirst(mustHex("820102deadbeef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v, []interface{}{int64(1), int64(2)}) || hex.EncodeToString(rest) != "deadbeef" {
+		t.Fatalf("unexpected item %v and rest %x", v, rest)
+	}
+}
+
+func TestFloat16RoundTrip(t *testing.T) {
+	t.Parallel()
+	// every finite half precision value must convert back and forth exactly
+	for h := 0; h < 0x10000; h++ {
+		if h&0x7c00 == 0x7c00 {
+			continue // infinities and NaNs
+		}
+		f := fromFloat16(uint16(h))
+		back, ok := toFloat16(f)
+		if !ok || back != uint16(h) {
+			t.Fatalf("half %04x converted to %v then back to %04x (ok=%t)", h, f, back, ok)
+		}
+	}
+}
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,112 @@
+package keyenc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jvehent/badcrypto/encoding/cborenc"
+)
+
+// labelType is the label of the key type in the CBOR map of every key
+const labelType = 1
+
+// MarshalCBOR returns key as a CBOR map of keys.cddl, in the
+// deterministic encoding of cborenc
+func MarshalCBOR(key interface{}) ([]byte, error) {
+	f, err := flatten(key)
+	if err != nil {
+		return nil, err
+	}
+	m := map[interface{}]interface{}{labelType: f.typ}
+	switch f.typ {
+	case typeRSAPublic, typeRSAPrivate:
+		m[2], m[3] = f.n, f.e
+		if f.typ == typeRSAPrivate {
+			primes := make([]interface{}, len(f.primes))
+			for i, p := range f.primes {
+				primes[i] = p
+			}
+			m[4], m[5] = f.d, primes
+		}
+	case typeECDSAPublic, typeECDSAPrivate:
+		m[2], m[3], m[4] = f.curve, f.x, f.y
+		if f.typ == typeECDSAPrivate {
+			m[5] = f.d
+		}
+	case typeEd25519Public, typeDHPublic:
+		m[2] = f.pub
+	case typeEd25519Private:
+		m[2] = f.priv
+	case typeDHPrivate:
+		m[2], m[3] = f.pub, f.priv
+	}
+	return cborenc.Marshal(m)
+}
+
+// UnmarshalCBOR parses a CBOR map of keys.cddl and returns the key it
+// holds, with one of the types listed in the package documentation.
+// Unknown labels are ignored.
+func UnmarshalCBOR(data []byte) (interface{}, error) {
+	v, err := cborenc.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("keyenc: cbor key is not a map")
+	}
+	typ, ok := m[int64(labelType)].(int64)
+	if !ok {
+		return nil, errors.New("keyenc: cbor key is missing its type")
+	}
+	f := &key{typ: int(typ)}
+	// bstr returns the byte string at label, which must be present
+	bstr := func(label int64) []byte {
+		b, ok := m[label].([]byte)
+		if !ok && err == nil {
+			err = fmt.Errorf("keyenc: label %d of key type %d must be a byte string", label, typ)
+		}
+		return b
+	}
+	switch f.typ {
+	case typeRSAPublic, typeRSAPrivate:
+		f.n = bstr(2)
+		e, ok := m[int64(3)].(int64)
+		if !ok || e < 0 {
+			return nil, errors.New("keyenc: rsa exponent must be an unsigned integer")
+		}
+		f.e = uint64(e)
+		if f.typ == typeRSAPrivate {
+			f.d = bstr(4)
+			primes, ok := m[int64(5)].([]interface{})
+			if !ok {
+				return nil, errors.New("keyenc: rsa primes must be an array")
+			}
+			for _, p := range primes {
+				b, ok := p.([]byte)
+				if !ok {
+					return nil, errors.New("keyenc: rsa primes must be byte strings")
+				}
+				f.primes = append(f.primes, b)
+			}
+		}
+	case typeECDSAPublic, typeECDSAPrivate:
+		if f.curve, ok = m[int64(2)].(string); !ok {
+			return nil, errors.New("keyenc: ecdsa curve must be a text string")
+		}
+		f.x, f.y = bstr(3), bstr(4)
+		if f.typ == typeECDSAPrivate {
+			f.d = bstr(5)
+		}
+	case typeEd25519Public, typeDHPublic:
+		f.pub = bstr(2)
+	case typeEd25519Private:
+		f.priv = bstr(2)
+	case typeDHPrivate:
+		f.pub, f.priv = bstr(2), bstr(3)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f.unflatten()
+}
@@ -0,0 +1,67 @@
+package keyenc
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/ecdsa"
+	"github.com/jvehent/badcrypto/encoding/cborenc"
+)
+
+func TestCBORFormat(t *testing.T) {
+	t.Parallel()
+	edKey := bytes.Repeat([]byte{0xab}, 32)
+	data, err := MarshalCBOR(ed25519.PublicKey(edKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// {1: 5, 2: h'abab...'}
+	expected := "a20105025820" + hex.EncodeToString(edKey)
+	if hex.EncodeToString(data) != expected {
+		t.Fatalf("expected %s but got %x", expected, data)
+	}
+}
+
+func TestUnmarshalCBORErrors(t *testing.T) {
+	t.Parallel()
+	priv, err := ecdsa.GenerateKey(rand.Reader, ec.P256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ecdsa.GenerateKey(rand.Reader, ec.P256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := priv.Point.X.FillBytes(make([]byte, 32))
+	y := priv.Point.Y.FillBytes(make([]byte, 32))
+	var testcases = []struct {
+		name string
+		v    interface{}
+	}{
+		{"not a map", []interface{}{1, 2}},
+		{"no type", map[interface{}]interface{}{2: []byte{1}}},
+		{"unknown type", map[interface{}]interface{}{1: 42}},
+		{"rsa negative exponent", map[interface{}]interface{}{1: 1, 2: []byte{0xc5, 0x01}, 3: -3}},
+		{"rsa primes not an array", map[interface{}]interface{}{1: 2, 2: []byte{0xc5, 0x01}, 3: 3, 4: []byte{7}, 5: []byte{3}}},
+		{"ecdsa curve as bytes", map[interface{}]interface{}{1: 3, 2: []byte("P-256"), 3: x, 4: y}},
+		{"ecdsa x as text", map[interface{}]interface{}{1: 3, 2: "P-256", 3: "x", 4: y}},
+		{"ecdsa off the curve", map[interface{}]interface{}{1: 3, 2: "P-256", 3: x, 4: x}},
+		{"ecdsa wrong scalar", map[interface{}]interface{}{1: 4, 2: "P-256", 3: x, 4: y, 5: other.D.FillBytes(make([]byte, 32))}},
+		{"ecdsa zero scalar", map[interface{}]interface{}{1: 4, 2: "P-256", 3: x, 4: y, 5: make([]byte, 32)}},
+		{"ed25519 missing key", map[interface{}]interface{}{1: 5}},
+		{"short ed25519 seed", map[interface{}]interface{}{1: 6, 2: make([]byte, 16)}},
+	}
+	for _, tc := range testcases {
+		data, err := cborenc.Marshal(tc.v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k, err := UnmarshalCBOR(data); err == nil {
+			t.Fatalf("testcase %q expected an error but got %#v", tc.name, k)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+// Package keyenc serializes keys in Protocol Buffers and in CBOR, so that
+// services exchanging keys over gRPC or CBOR based protocols share one
+// encoding instead of each inventing their own. The schemas are
+// keys.proto and keys.cddl, next to this file.
+//
+// The supported keys are:
+//
+//	RSA      *rsa.PublicKey, *rsa.PrivateKey from crypto/rsa, as generated
+//	         by the rsa package
+//	ECDSA    *ecdsa.PublicKey, *ecdsa.PrivateKey from the ecdsa package,
+//	         on the curves of the ec package
+//	Ed25519  ed25519.PublicKey, ed25519.PrivateKey from crypto/ed25519
+//	DH       noise.DHKey, an X25519 key pair or a lone public key when
+//	         Private is empty
+//
+// The protobuf wire format is written and parsed here directly, there is
+// no dependency on a protobuf runtime: MarshalProto produces a Key message
+// of keys.proto that code generated by protoc reads, and UnmarshalProto
+// reads what that code writes. COSE_Key, the CBOR key format of WebAuthn,
+// is in the cose package.
+//
+// Decoded keys are checked: points must be on their curve, private keys
+// must match their public key, and RSA keys must pass Validate.
+package keyenc
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/ecdsa"
+	"github.com/jvehent/badcrypto/noise"
+	"github.com/jvehent/badcrypto/x25519"
+)
+
+// Key types, the field numbers of the oneof of the Key message of
+// keys.proto and the values of label 1 of keys.cddl
+const (
+	typeRSAPublic      = 1
+	typeRSAPrivate     = 2
+	typeECDSAPublic    = 3
+	typeECDSAPrivate   = 4
+	typeEd25519Public  = 5
+	typeEd25519Private = 6
+	typeDHPublic       = 7
+	typeDHPrivate      = 8
+)
+
+// key is the flattened form of all supported keys, in between the Go
+// types and the encodings
+type key struct {
+	typ    int
+	n      []byte   // RSA modulus
+	e      uint64   // RSA public exponent
+	d      []byte   // RSA private exponent or ECDSA scalar
+	primes [][]byte // RSA primes
+	curve  string   // ECDSA curve name
+	x, y   []byte   // ECDSA point
+	pub    []byte   // Ed25519 or X25519 public key
+	priv   []byte   // Ed25519 seed or X25519 scalar
+}
+
+// curves are the curves ECDSA keys can be on
+var curves = []*ec.Curve{ec.P256(), ec.Secp256k1()}
+
+func curveByName(name string) (*ec.Curve, error) {
+	for _, c := range curves {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("keyenc: unknown curve %q", name)
+}
+
+// flatten converts a supported key to its flattened form
+func flatten(k interface{}) (*key, error) {
+	switch k := k.(type) {
+	case *rsa.PublicKey:
+		return &key{typ: typeRSAPublic, n: k.N.Bytes(), e: uint64(k.E)}, nil
+	case *rsa.PrivateKey:
+		f := &key{typ: typeRSAPrivate, n: k.N.Bytes(), e: uint64(k.E), d: k.D.Bytes()}
+		for _, p := range k.Primes {
+			f.primes = append(f.primes, p.Bytes())
+		}
+		return f, nil
+	case *ecdsa.PublicKey:
+		f, err := flattenPoint(k)
+		if err != nil {
+			return nil, err
+		}
+		f.typ = typeECDSAPublic
+		return f, nil
+	case *ecdsa.PrivateKey:
+		f, err := flattenPoint(&k.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		f.typ = typeECDSAPrivate
+		f.d = k.D.FillBytes(make([]byte, (k.Curve.N.BitLen()+7)/8))
+		return f, nil
+	case ed25519.PublicKey:
+		return &key{typ: typeEd25519Public, pub: append([]byte{}, k...)}, nil
+	case ed25519.PrivateKey:
+		return &key{typ: typeEd25519Private, priv: k.Seed()}, nil
+	case noise.DHKey:
+		if len(k.Private) == 0 {
+			return &key{typ: typeDHPublic, pub: append([]byte{}, k.Public...)}, nil
+		}
+		return &key{typ: typeDHPrivate, pub: append([]byte{}, k.Public...), priv: append([]byte{}, k.Private...)}, nil
+	case *noise.DHKey:
+		return flatten(*k)
+	}
+	return nil, fmt.Errorf("keyenc: unsupported key type %T", k)
+}
+
+func flattenPoint(pub *ecdsa.PublicKey) (*key, error) {
+	// a curve of the same name with other parameters would decode wrong
+	if c, err := curveByName(pub.Curve.Name); err != nil || c != pub.Curve {
+		return nil, fmt.Errorf("keyenc: unsupported curve %q", pub.Curve.Name)
+	}
+	if pub.Point.IsInfinity() {
+		return nil, errors.New("keyenc: ecdsa public key is the point at infinity")
+	}
+	size := pub.Curve.ByteLen()
+	return &key{
+		curve: pub.Curve.Name,
+		x:     pub.Point.X.FillBytes(make([]byte, size)),
+		y:     pub.Point.Y.FillBytes(make([]byte, size)),
+	}, nil
+}
+
+// unflatten returns the Go key of f, after checking it
+func (f *key) unflatten() (interface{}, error) {
+	switch f.typ {
+	case typeRSAPublic:
+		return f.rsaPublic()
+	case typeRSAPrivate:
+		pub, err := f.rsaPublic()
+		if err != nil {
+			return nil, err
+		}
+		if len(f.d) == 0 || len(f.primes) < 2 {
+			return nil, errors.New("keyenc: rsa private key needs d and two primes")
+		}
+		priv := &rsa.PrivateKey{PublicKey: *pub, D: new(big.Int).SetBytes(f.d)}
+		for _, p := range f.primes {
+			priv.Primes = append(priv.Primes, new(big.Int).SetBytes(p))
+		}
+		if err := priv.Validate(); err != nil {
+			return nil, fmt.Errorf("keyenc: invalid rsa private key: %v", err)
+		}
+		priv.Precompute()
+		return priv, nil
+	case typeECDSAPublic:
+		return f.ecdsaPublic()
+	case typeECDSAPrivate:
+		pub, err := f.ecdsaPublic()
+		if err != nil {
+			return nil, err
+		}
+		d := new(big.Int).SetBytes(f.d)
+		if d.Sign() == 0 || d.Cmp(pub.Curve.N) >= 0 {
+			return nil, errors.New("keyenc: ecdsa scalar out of range")
+		}
+		if !pub.Curve.ScalarBaseMult(d).Equal(pub.Point) {
+			return nil, errors.New("keyenc: ecdsa private key does not match its public key")
+		}
+		return &ecdsa.PrivateKey{PublicKey: *pub, D: d}, nil
+	case typeEd25519Public:
+		if len(f.pub) != ed25519.PublicKeySize {
+			return nil, errors.New("keyenc: ed25519 public key must be 32 bytes")
+		}
+		return ed25519.PublicKey(append([]byte{}, f.pub...)), nil
+	case typeEd25519Private:
+		if len(f.priv) != ed25519.SeedSize {
+			return nil, errors.New("keyenc: ed25519 seed must be 32 bytes")
+		}
+		return ed25519.NewKeyFromSeed(f.priv), nil
+	case typeDHPublic:
+		if len(f.pub) != x25519.Size {
+			return nil, errors.New("keyenc: dh public key must be 32 bytes")
+		}
+		return noise.DHKey{Public: append([]byte{}, f.pub...)}, nil
+	case typeDHPrivate:
+		if len(f.pub) != x25519.Size || len(f.priv) != x25519.Size {
+			return nil, errors.New("keyenc: dh keys must be 32 bytes")
+		}
+		var scalar [x25519.Size]byte
+		copy(scalar[:], f.priv)
+		if pub := x25519.ScalarBaseMult(scalar); !bytes.Equal(pub[:], f.pub) {
+			return nil, errors.New("keyenc: dh private key does not match its public key")
+		}
+		return noise.DHKey{Private: append([]byte{}, f.priv...), Public: append([]byte{}, f.pub...)}, nil
+	}
+	return nil, fmt.Errorf("keyenc: unknown key type %d", f.typ)
+}
+
+func (f *key) rsaPublic() (*rsa.PublicKey, error) {
+	// crypto/rsa rejects exponents below 2 or above 2^31-1
+	if len(f.n) == 0 || f.e < 2 || f.e > 1<<31-1 {
+		return nil, errors.New("keyenc: invalid rsa public key")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(f.n), E: int(f.e)}, nil
+}
+
+func (f *key) ecdsaPublic() (*ecdsa.PublicKey, error) {
+	c, err := curveByName(f.curve)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.x) != c.ByteLen() || len(f.y) != c.ByteLen() {
+		return nil, fmt.Errorf("keyenc: %s coordinates must be %d bytes", c.Name, c.ByteLen())
+	}
+	p := &ec.Point{X: new(big.Int).SetBytes(f.x), Y: new(big.Int).SetBytes(f.y)}
+	if !c.IsOnCurve(p) {
+		return nil, errors.New("keyenc: ecdsa point is not on the curve")
+	}
+	return &ecdsa.PublicKey{Curve: c, Point: p}, nil
+}
@@ -0,0 +1,98 @@
+package keyenc
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"reflect"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/ecdsa"
+	"github.com/jvehent/badcrypto/noise"
+)
+
+// testKeys returns a key of every supported type
+func testKeys(t *testing.T) []interface{} {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p256, err := ecdsa.GenerateKey(rand.Reader, ec.P256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	k1, err := ecdsa.GenerateKey(rand.Reader, ec.Secp256k1())
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dh, err := noise.GenerateKeypair(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []interface{}{
+		&rsaKey.PublicKey, rsaKey,
+		&p256.PublicKey, p256, &k1.PublicKey, k1,
+		edPub, edPriv,
+		noise.DHKey{Public: dh.Public}, dh,
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+	var encodings = []struct {
+		name      string
+		marshal   func(interface{}) ([]byte, error)
+		unmarshal func([]byte) (interface{}, error)
+	}{
+		{"protobuf", MarshalProto, UnmarshalProto},
+		{"cbor", MarshalCBOR, UnmarshalCBOR},
+	}
+	for _, enc := range encodings {
+		for i, k := range testKeys(t) {
+			data, err := enc.marshal(k)
+			if err != nil {
+				t.Fatalf("%s testcase %d failed to marshal %T: %v", enc.name, i, k, err)
+			}
+			got, err := enc.unmarshal(data)
+			if err != nil {
+				t.Fatalf("%s testcase %d failed to unmarshal %T: %v", enc.name, i, k, err)
+			}
+			if priv, ok := got.(*rsa.PrivateKey); ok {
+				// compare the precomputed values too
+				k.(*rsa.PrivateKey).Precompute()
+				got = priv
+			}
+			if !reflect.DeepEqual(got, k) {
+				t.Fatalf("%s testcase %d expected %#v but got %#v", enc.name, i, k, got)
+			}
+			again, err := enc.marshal(got)
+			if err != nil || string(again) != string(data) {
+				t.Fatalf("%s testcase %d expected the encoding to be stable", enc.name, i)
+			}
+		}
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	t.Parallel()
+	other := *ec.P256()
+	var testcases = []interface{}{
+		"not a key",
+		&ecdsa.PublicKey{Curve: ec.P256(), Point: ec.Infinity()},
+		// same name as P-256 but not the curve of the ec package
+		&ecdsa.PublicKey{Curve: &other, Point: ec.P256().Generator()},
+	}
+	for i, k := range testcases {
+		if _, err := MarshalProto(k); err == nil {
+			t.Fatalf("testcase %d expected protobuf marshaling of %T to fail", i, k)
+		}
+		if _, err := MarshalCBOR(k); err == nil {
+			t.Fatalf("testcase %d expected cbor marshaling of %T to fail", i, k)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package keyenc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// wire types of the protobuf encoding
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// MarshalProto returns key as a Key message of keys.proto. Fields are
+// written in the order of their numbers and empty ones are left out, as
+// protobuf implementations do, so equal keys give equal bytes.
+func MarshalProto(key interface{}) ([]byte, error) {
+	f, err := flatten(key)
+	if err != nil {
+		return nil, err
+	}
+	var msg []byte
+	switch f.typ {
+	case typeRSAPublic:
+		msg = f.rsaPublicProto()
+	case typeRSAPrivate:
+		msg = appendMessage(nil, 1, f.rsaPublicProto())
+		msg = appendBytes(msg, 2, f.d)
+		for _, p := range f.primes {
+			msg = appendMessage(msg, 3, p)
+		}
+	case typeECDSAPublic:
+		msg = f.ecdsaPublicProto()
+	case typeECDSAPrivate:
+		msg = appendMessage(nil, 1, f.ecdsaPublicProto())
+		msg = appendBytes(msg, 2, f.d)
+	case typeEd25519Public, typeDHPublic:
+		msg = appendBytes(nil, 1, f.pub)
+	case typeEd25519Private:
+		msg = appendBytes(nil, 1, f.priv)
+	case typeDHPrivate:
+		msg = appendMessage(nil, 1, appendBytes(nil, 1, f.pub))
+		msg = appendBytes(msg, 2, f.priv)
+	}
+	return appendMessage(nil, f.typ, msg), nil
+}
+
+func (f *key) rsaPublicProto() []byte {
+	msg := appendBytes(nil, 1, f.n)
+	if f.e != 0 {
+		msg = appendTag(msg, 2, wireVarint)
+		msg = appendVarint(msg, f.e)
+	}
+	return msg
+}
+
+func (f *key) ecdsaPublicProto() []byte {
+	msg := appendBytes(nil, 1, []byte(f.curve))
+	msg = appendBytes(msg, 2, f.x)
+	return appendBytes(msg, 3, f.y)
+}
+
+func appendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func appendTag(b []byte, num, wire int) []byte {
+	return appendVarint(b, uint64(num)<<3|uint64(wire))
+}
+
+// appendMessage writes a length delimited field, even when it is empty:
+// an empty message in a oneof still selects its case
+func appendMessage(b []byte, num int, v []byte) []byte {
+	b = appendTag(b, num, wireBytes)
+	b = appendVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// appendBytes writes a bytes or string field, unless it is empty
+func appendBytes(b []byte, num int, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	return appendMessage(b, num, v)
+}
+
+// field is a field of a protobuf message. Varint fields have their value
+// in v, length delimited fields in b.
+type field struct {
+	num, wire int
+	v         uint64
+	b         []byte
+}
+
+// parseFields splits a message into its fields. Fixed size fields are
+// returned too, for unknown fields to be skipped, but groups are refused.
+func parseFields(data []byte) ([]field, error) {
+	var fields []field
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, errors.New("keyenc: invalid protobuf field tag")
+		}
+		data = data[n:]
+		f := field{num: int(tag >> 3), wire: int(tag & 7)}
+		if tag>>3 == 0 || tag>>3 > 1<<29-1 {
+			return nil, errors.New("keyenc: invalid protobuf field number")
+		}
+		switch f.wire {
+		case wireVarint:
+			f.v, n = binary.Uvarint(data)
+			if n <= 0 {
+				return nil, errors.New("keyenc: invalid protobuf varint")
+			}
+			data = data[n:]
+		case wireBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || length > uint64(len(data)-n) {
+				return nil, errors.New("keyenc: truncated protobuf field")
+			}
+			f.b = data[n : n+int(length)]
+			data = data[n+int(length):]
+		case wireFixed64, wireFixed32:
+			size := 8
+			if f.wire == wireFixed32 {
+				size = 4
+			}
+			if len(data) < size {
+				return nil, errors.New("keyenc: truncated protobuf field")
+			}
+			data = data[size:]
+		default:
+			return nil, fmt.Errorf("keyenc: unsupported protobuf wire type %d", f.wire)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// UnmarshalProto parses a Key message of keys.proto and returns the key
+// it holds, with one of the types listed in the package documentation.
+// Unknown fields are skipped and, as in protobuf, the last occurrence of
+// a field wins.
+func UnmarshalProto(data []byte) (interface{}, error) {
+	fields, err := parseFields(data)
+	if err != nil {
+		return nil, err
+	}
+	f := new(key)
+	var msg []byte
+	for _, fd := range fields {
+		if fd.num >= typeRSAPublic && fd.num <= typeDHPrivate {
+			if fd.wire != wireBytes {
+				return nil, fmt.Errorf("keyenc: key field %d is not a message", fd.num)
+			}
+			f.typ, msg = fd.num, fd.b
+		}
+	}
+	if f.typ == 0 {
+		return nil, errors.New("keyenc: protobuf message holds no key")
+	}
+	if err := f.parseProto(f.typ, msg); err != nil {
+		return nil, err
+	}
+	return f.unflatten()
+}
+
+// parseProto sets the fields of f from msg, a message of type typ.
+// Private keys embed their public key, which is parsed into f as well.
+func (f *key) parseProto(typ int, msg []byte) error {
+	fields, err := parseFields(msg)
+	if err != nil {
+		return err
+	}
+	for _, fd := range fields {
+		wire := wireBytes
+		switch {
+		case typ == typeRSAPublic && fd.num == 1:
+			f.n = fd.b
+		case typ == typeRSAPublic && fd.num == 2:
+			wire, f.e = wireVarint, fd.v
+		case typ == typeRSAPrivate && fd.num == 1:
+			err = f.parseProto(typeRSAPublic, fd.b)
+		case typ == typeRSAPrivate && fd.num == 2, typ == typeECDSAPrivate && fd.num == 2:
+			f.d = fd.b
+		case typ == typeRSAPrivate && fd.num == 3:
+			f.primes = append(f.primes, fd.b)
+		case typ == typeECDSAPublic && fd.num == 1:
+			f.curve = string(fd.b)
+		case typ == typeECDSAPublic && fd.num == 2:
+			f.x = fd.b
+		case typ == typeECDSAPublic && fd.num == 3:
+			f.y = fd.b
+		case typ == typeECDSAPrivate && fd.num == 1:
+			err = f.parseProto(typeECDSAPublic, fd.b)
+		case typ == typeEd25519Public && fd.num == 1, typ == typeDHPublic && fd.num == 1:
+			f.pub = fd.b
+		case typ == typeEd25519Private && fd.num == 1, typ == typeDHPrivate && fd.num == 2:
+			f.priv = fd.b
+		case typ == typeDHPrivate && fd.num == 1:
+			err = f.parseProto(typeDHPublic, fd.b)
+		default:
+			// unknown field
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if fd.wire != wire {
+			return fmt.Errorf("keyenc: field %d of key type %d has wire type %d", fd.num, typ, fd.wire)
+		}
+	}
+	return nil
+}
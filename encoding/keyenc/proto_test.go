@@ -0,0 +1,83 @@
+package keyenc
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/hex"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/jvehent/badcrypto/noise"
+)
+
+func TestProtoWireFormat(t *testing.T) {
+	t.Parallel()
+	edKey := bytes.Repeat([]byte{0xab}, 32)
+	var testcases = []struct {
+		key     interface{}
+		encoded string
+	}{
+		// Key{rsa_public: {n: 0xc501, e: 65537}}
+		{&rsa.PublicKey{N: big.NewInt(0xc501), E: 65537}, "0a080a02c50110818004"},
+		// Key{ed25519_public: {key: ab...ab}}
+		{ed25519.PublicKey(edKey), "2a220a20" + hex.EncodeToString(edKey)},
+		// Key{dh_public: {key: ab...ab}}
+		{noise.DHKey{Public: edKey}, "3a220a20" + hex.EncodeToString(edKey)},
+	}
+	for i, tc := range testcases {
+		data, err := MarshalProto(tc.key)
+		if err != nil {
+			t.Fatalf("testcase %d failed: %v", i, err)
+		}
+		if hex.EncodeToString(data) != tc.encoded {
+			t.Fatalf("testcase %d expected %s but got %x", i, tc.encoded, data)
+		}
+	}
+}
+
+func TestUnmarshalProtoUnknownFields(t *testing.T) {
+	t.Parallel()
+	// RSAPublicKey{n: 0xc501, e: 65537} with unknown varint, fixed32 and
+	// fixed64 fields, inside a Key with an unknown bytes field
+	inner, _ := hex.DecodeString("0a02c501" + "7801" + "10818004" + "7d01020304" + "790102030405060708")
+	msg := appendMessage(nil, 20, []byte("future"))
+	msg = appendMessage(msg, typeRSAPublic, inner)
+	got, err := UnmarshalProto(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &rsa.PublicKey{N: big.NewInt(0xc501), E: 65537}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v but got %v", expected, got)
+	}
+}
+
+func TestUnmarshalProtoErrors(t *testing.T) {
+	t.Parallel()
+	edKey := bytes.Repeat([]byte{0xab}, 32)
+	var testcases = []struct {
+		name    string
+		encoded []byte
+	}{
+		{"empty", nil},
+		{"no key", appendMessage(nil, 20, nil)},
+		{"truncated tag", []byte{0x80}},
+		{"truncated field", []byte{0x2a, 0x22, 0x0a}},
+		{"field zero", []byte{0x02, 0x00}},
+		{"group", []byte{0x2b}},
+		{"key as varint", []byte{0x28, 0x01}},
+		{"short ed25519", appendMessage(nil, typeEd25519Public, appendBytes(nil, 1, edKey[:31]))},
+		{"ed25519 as varint", appendMessage(nil, typeEd25519Public, []byte{0x08, 0x01})},
+		{"rsa without exponent", appendMessage(nil, typeRSAPublic, appendBytes(nil, 1, []byte{0xc5, 0x01}))},
+		{"rsa private without primes", appendMessage(nil, typeRSAPrivate, appendBytes(appendMessage(nil, 1, []byte{0x0a, 0x01, 0x0f, 0x10, 0x03}), 2, []byte{7}))},
+		{"unknown curve", appendMessage(nil, typeECDSAPublic, appendBytes(nil, 1, []byte("P-192")))},
+		{"dh public mismatch", appendMessage(nil, typeDHPrivate, appendBytes(appendMessage(nil, 1, appendBytes(nil, 1, edKey)), 2, edKey))},
+	}
+	for _, tc := range testcases {
+		if k, err := UnmarshalProto(tc.encoded); err == nil {
+			t.Fatalf("testcase %q expected an error but got %#v", tc.name, k)
+		}
+	}
+}
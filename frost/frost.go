@@ -0,0 +1,280 @@
+// Package frost implements FROST, the two-round Flexible Round-Optimized
+// Schnorr Threshold signatures of Komlo and Goldberg, over the curves of
+// the ec package.
+//
+// A trusted dealer splits the signing key with Feldman VSS. To sign, each
+// participant first publishes a pair of nonce commitments, then, once the
+// commitments of the signing set are known, a signature share. Anyone can
+// aggregate the shares into an ordinary Schnorr signature (R, z) verifying
+// z*G = R + c*Y.
+//
+// The protocol follows RFC 9591 but hashes with ec.HashToScalar rather than
+// the RFC 9380 hash to field, so signatures do not match its test vectors.
+package frost
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/vss"
+)
+
+const contextString = "badcrypto FROST v1 "
+
+// KeyShare is the signing key of one participant
+type KeyShare struct {
+	Curve *ec.Curve
+	Index uint32
+	// Secret is the participant's share of the group signing key
+	Secret *big.Int
+	// VerificationShare is Secret*G, used to check signature shares
+	VerificationShare *ec.Point
+	// PublicKey is the group verification key
+	PublicKey *ec.Point
+}
+
+// Nonce is the secret pair of nonces of one signing session. It must
+// never be used twice, Sign clears it after use.
+type Nonce struct {
+	hiding, binding *big.Int
+}
+
+// NonceCommitment is the public commitment to a Nonce, sent to the other
+// signers in the first round
+type NonceCommitment struct {
+	Index   uint32
+	Hiding  *ec.Point
+	Binding *ec.Point
+}
+
+// SignatureShare is the second round output of a participant
+type SignatureShare struct {
+	Index uint32
+	Z     *big.Int
+}
+
+// Signature is a Schnorr signature
+type Signature struct {
+	R *ec.Point
+	Z *big.Int
+}
+
+// KeyGen is a trusted dealer key generation: it picks a random signing key,
+// splits it into n shares with threshold k and checks every share against
+// the VSS commitments before handing them out
+func KeyGen(rand io.Reader, c *ec.Curve, n, k int) ([]*KeyShare, *ec.Point, error) {
+	secret, err := c.RandomScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	shares, comm, err := vss.Feldman(rand, c, secret, n, k)
+	if err != nil {
+		return nil, nil, err
+	}
+	pub := comm[0]
+	keys := make([]*KeyShare, n)
+	for i, s := range shares {
+		if !vss.VerifyFeldman(c, comm, s) {
+			return nil, nil, fmt.Errorf("frost: share %d does not match its commitment", s.Index)
+		}
+		keys[i] = &KeyShare{
+			Curve:             c,
+			Index:             s.Index,
+			Secret:            s.Value,
+			VerificationShare: c.ScalarBaseMult(s.Value),
+			PublicKey:         pub,
+		}
+	}
+	return keys, pub, nil
+}
+
+// Commit runs the first round: it generates the nonces of a signing
+// session and their commitments. The nonces mix fresh randomness with the
+// secret share, in case rand is weak.
+func (ks *KeyShare) Commit(rand io.Reader) (*Nonce, *NonceCommitment, error) {
+	c := ks.Curve
+	nonce := new(Nonce)
+	for _, n := range []**big.Int{&nonce.hiding, &nonce.binding} {
+		random := make([]byte, 32)
+		if _, err := io.ReadFull(rand, random); err != nil {
+			return nil, nil, err
+		}
+		*n = c.HashToScalar(contextString+"nonce", random, ks.Secret.Bytes())
+	}
+	return nonce, &NonceCommitment{
+		Index:   ks.Index,
+		Hiding:  c.ScalarBaseMult(nonce.hiding),
+		Binding: c.ScalarBaseMult(nonce.binding),
+	}, nil
+}
+
+// sortCommitments returns a copy of the commitments ordered by index, and
+// fails on duplicates
+func sortCommitments(commitments []*NonceCommitment) ([]*NonceCommitment, error) {
+	sorted := append([]*NonceCommitment{}, commitments...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Index < sorted[j].Index })
+	for i := range sorted {
+		if sorted[i].Index == 0 {
+			return nil, errors.New("frost: commitment with a zero index")
+		}
+		if i > 0 && sorted[i].Index == sorted[i-1].Index {
+			return nil, fmt.Errorf("frost: duplicate commitment for participant %d", sorted[i].Index)
+		}
+	}
+	return sorted, nil
+}
+
+func encodeIndex(c *ec.Curve, index uint32) []byte {
+	return big.NewInt(int64(index)).FillBytes(make([]byte, c.ByteLen()))
+}
+
+// bindingFactors computes rho_i for every signer, which binds each nonce
+// pair to the message and to the whole signing set
+func bindingFactors(c *ec.Curve, pub *ec.Point, msg []byte, commitments []*NonceCommitment) map[uint32]*big.Int {
+	var encoded []byte
+	for _, cm := range commitments {
+		encoded = append(encoded, encodeIndex(c, cm.Index)...)
+		encoded = append(encoded, c.Marshal(cm.Hiding)...)
+		encoded = append(encoded, c.Marshal(cm.Binding)...)
+	}
+	msgHash := c.HashToScalar(contextString+"msg", msg).Bytes()
+	comHash := c.HashToScalar(contextString+"com", encoded).Bytes()
+	rhos := make(map[uint32]*big.Int, len(commitments))
+	for _, cm := range commitments {
+		rhos[cm.Index] = c.HashToScalar(contextString+"rho", c.Marshal(pub), msgHash, comHash, encodeIndex(c, cm.Index))
+	}
+	return rhos
+}
+
+// groupCommitment computes R, the sum of D_i + rho_i*E_i
+func groupCommitment(c *ec.Curve, commitments []*NonceCommitment, rhos map[uint32]*big.Int) *ec.Point {
+	r := ec.Infinity()
+	for _, cm := range commitments {
+		r = c.Add(r, c.Add(cm.Hiding, c.ScalarMult(cm.Binding, rhos[cm.Index])))
+	}
+	return r
+}
+
+func challenge(c *ec.Curve, r, pub *ec.Point, msg []byte) *big.Int {
+	return c.HashToScalar(contextString+"chal", c.Marshal(r), c.Marshal(pub), msg)
+}
+
+func indices(commitments []*NonceCommitment) []uint32 {
+	out := make([]uint32, len(commitments))
+	for i, cm := range commitments {
+		out[i] = cm.Index
+	}
+	return out
+}
+
+// Sign runs the second round: given the nonce from Commit and the
+// commitments of every participant of the signing set, itself included,
+// it returns the signature share z_i = d_i + e_i*rho_i + lambda_i*s_i*c
+func (ks *KeyShare) Sign(msg []byte, nonce *Nonce, commitments []*NonceCommitment) (*SignatureShare, error) {
+	if nonce == nil || nonce.hiding == nil {
+		return nil, errors.New("frost: nonce is missing or was already used")
+	}
+	c := ks.Curve
+	sorted, err := sortCommitments(commitments)
+	if err != nil {
+		return nil, err
+	}
+	var own *NonceCommitment
+	for _, cm := range sorted {
+		if cm.Index == ks.Index {
+			own = cm
+		}
+	}
+	if own == nil {
+		return nil, errors.New("frost: signer is not in the commitment list")
+	}
+	if !own.Hiding.Equal(c.ScalarBaseMult(nonce.hiding)) || !own.Binding.Equal(c.ScalarBaseMult(nonce.binding)) {
+		return nil, errors.New("frost: nonce does not match the signer's commitment")
+	}
+	rhos := bindingFactors(c, ks.PublicKey, msg, sorted)
+	r := groupCommitment(c, sorted, rhos)
+	lambda, err := vss.Lagrange(c, ks.Index, indices(sorted))
+	if err != nil {
+		return nil, err
+	}
+	ch := challenge(c, r, ks.PublicKey, msg)
+
+	z := new(big.Int).Mul(nonce.binding, rhos[ks.Index])
+	z.Add(z, nonce.hiding)
+	z.Add(z, lambda.Mul(lambda, ks.Secret).Mul(lambda, ch))
+	z.Mod(z, c.N)
+	nonce.hiding, nonce.binding = nil, nil
+	return &SignatureShare{Index: ks.Index, Z: z}, nil
+}
+
+// VerifyShare checks a signature share against the verification share of
+// its signer, so the aggregator can identify misbehaving participants
+func VerifyShare(c *ec.Curve, pub, verificationShare *ec.Point, msg []byte, commitments []*NonceCommitment, share *SignatureShare) bool {
+	sorted, err := sortCommitments(commitments)
+	if err != nil {
+		return false
+	}
+	var own *NonceCommitment
+	for _, cm := range sorted {
+		if cm.Index == share.Index {
+			own = cm
+		}
+	}
+	if own == nil {
+		return false
+	}
+	rhos := bindingFactors(c, pub, msg, sorted)
+	ch := challenge(c, groupCommitment(c, sorted, rhos), pub, msg)
+	lambda, err := vss.Lagrange(c, share.Index, indices(sorted))
+	if err != nil {
+		return false
+	}
+	expected := c.Add(own.Hiding, c.ScalarMult(own.Binding, rhos[share.Index]))
+	expected = c.Add(expected, c.ScalarMult(verificationShare, lambda.Mul(lambda, ch)))
+	return c.ScalarBaseMult(share.Z).Equal(expected)
+}
+
+// Aggregate sums the signature shares of the signing set into a Schnorr
+// signature, and checks the result
+func Aggregate(c *ec.Curve, pub *ec.Point, msg []byte, commitments []*NonceCommitment, shares []*SignatureShare) (*Signature, error) {
+	sorted, err := sortCommitments(commitments)
+	if err != nil {
+		return nil, err
+	}
+	if len(shares) != len(sorted) {
+		return nil, errors.New("frost: the number of shares does not match the signing set")
+	}
+	seen := make(map[uint32]bool)
+	z := new(big.Int)
+	for _, s := range shares {
+		if seen[s.Index] {
+			return nil, fmt.Errorf("frost: duplicate share from participant %d", s.Index)
+		}
+		seen[s.Index] = true
+		z.Add(z, s.Z)
+	}
+	for _, cm := range sorted {
+		if !seen[cm.Index] {
+			return nil, fmt.Errorf("frost: missing share from participant %d", cm.Index)
+		}
+	}
+	sig := &Signature{R: groupCommitment(c, sorted, bindingFactors(c, pub, msg, sorted)), Z: z.Mod(z, c.N)}
+	if !Verify(c, pub, msg, sig) {
+		return nil, errors.New("frost: aggregated signature is invalid")
+	}
+	return sig, nil
+}
+
+// Verify checks a Schnorr signature produced by Aggregate
+func Verify(c *ec.Curve, pub *ec.Point, msg []byte, sig *Signature) bool {
+	if sig == nil || sig.R == nil || sig.Z == nil || sig.R.IsInfinity() || !c.IsOnCurve(sig.R) ||
+		sig.Z.Sign() < 0 || sig.Z.Cmp(c.N) >= 0 {
+		return false
+	}
+	ch := challenge(c, sig.R, pub, msg)
+	return c.ScalarBaseMult(sig.Z).Equal(c.Add(sig.R, c.ScalarMult(pub, ch)))
+}
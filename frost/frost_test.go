@@ -0,0 +1,129 @@
+package frost
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+// sign runs both rounds with the given participants
+func sign(t *testing.T, signers []*KeyShare, msg []byte) ([]*NonceCommitment, []*SignatureShare) {
+	nonces := make([]*Nonce, len(signers))
+	commitments := make([]*NonceCommitment, len(signers))
+	for i, ks := range signers {
+		n, cm, err := ks.Commit(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nonces[i], commitments[i] = n, cm
+	}
+	shares := make([]*SignatureShare, len(signers))
+	for i, ks := range signers {
+		s, err := ks.Sign(msg, nonces[i], commitments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shares[i] = s
+	}
+	return commitments, shares
+}
+
+func TestThresholdSigning(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		curve   *ec.Curve
+		n, k    int
+		signers []int
+	}{
+		{ec.P256(), 3, 2, []int{0, 2}},
+		{ec.P256(), 5, 3, []int{4, 1, 3}},
+		{ec.Secp256k1(), 4, 4, []int{0, 1, 2, 3}},
+		{ec.Secp256k1(), 5, 2, []int{0, 1, 2, 3, 4}},
+	}
+	msg := []byte("threshold signed message")
+	for i, tc := range testcases {
+		keys, pub, err := KeyGen(rand.Reader, tc.curve, tc.n, tc.k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var signers []*KeyShare
+		for _, j := range tc.signers {
+			signers = append(signers, keys[j])
+		}
+		commitments, shares := sign(t, signers, msg)
+		for j, s := range shares {
+			if !VerifyShare(tc.curve, pub, signers[j].VerificationShare, msg, commitments, s) {
+				t.Fatalf("testcase %d expected share %d to verify", i, j)
+			}
+		}
+		sig, err := Aggregate(tc.curve, pub, msg, commitments, shares)
+		if err != nil {
+			t.Fatalf("testcase %d: %v", i, err)
+		}
+		if !Verify(tc.curve, pub, msg, sig) {
+			t.Fatalf("testcase %d expected signature to verify", i)
+		}
+		if Verify(tc.curve, pub, []byte("other message"), sig) {
+			t.Fatalf("testcase %d expected signature on another message to fail", i)
+		}
+	}
+}
+
+func TestMisbehavingSigner(t *testing.T) {
+	t.Parallel()
+	c := ec.P256()
+	keys, pub, err := KeyGen(rand.Reader, c, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("hello")
+	commitments, shares := sign(t, keys[:2], msg)
+	shares[1].Z = new(big.Int).Add(shares[1].Z, big.NewInt(1))
+	if VerifyShare(c, pub, keys[1].VerificationShare, msg, commitments, shares[1]) {
+		t.Fatal("expected tampered share to be identified")
+	}
+	if !VerifyShare(c, pub, keys[0].VerificationShare, msg, commitments, shares[0]) {
+		t.Fatal("expected honest share to verify")
+	}
+	if _, err := Aggregate(c, pub, msg, commitments, shares); err == nil {
+		t.Fatal("expected aggregation with a bad share to fail")
+	}
+}
+
+func TestBelowThreshold(t *testing.T) {
+	t.Parallel()
+	c := ec.P256()
+	keys, pub, err := KeyGen(rand.Reader, c, 3, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("hello")
+	commitments, shares := sign(t, keys[:2], msg)
+	if _, err := Aggregate(c, pub, msg, commitments, shares); err == nil {
+		t.Fatal("expected two of three signers to fail")
+	}
+}
+
+func TestNonceReuse(t *testing.T) {
+	t.Parallel()
+	c := ec.P256()
+	keys, _, err := KeyGen(rand.Reader, c, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n0, cm0, _ := keys[0].Commit(rand.Reader)
+	_, cm1, _ := keys[1].Commit(rand.Reader)
+	commitments := []*NonceCommitment{cm0, cm1}
+	if _, err := keys[0].Sign([]byte("first"), n0, commitments); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys[0].Sign([]byte("second"), n0, commitments); err == nil {
+		t.Fatal("expected a second use of the nonce to be refused")
+	}
+	n1, _, _ := keys[1].Commit(rand.Reader)
+	if _, err := keys[1].Sign([]byte("first"), n1, commitments); err == nil {
+		t.Fatal("expected a nonce that does not match the commitment to be refused")
+	}
+}
@@ -0,0 +1,225 @@
+// Package group abstracts the prime order groups that OPRF, OPAQUE and
+// VRFs are built on, following the prime order group API of RFC 9497
+// section 2.1, so those protocols run over P-256 or ristretto255 alike.
+//
+// A Group comes with the hash function of the ciphersuites it appears
+// in, which it uses to hash to elements and scalars: P-256 goes with
+// SHA-256 and ristretto255 with SHA-512. Scalars are *big.Int modulo
+// Order, elements are opaque values that only the group that returned
+// them can handle.
+package group
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"hash"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/ristretto255"
+)
+
+// Element is an element of a Group, *ec.Point for P-256 and
+// *ristretto255.Element for ristretto255
+type Element interface{}
+
+// Group is a prime order group with its encodings and hash functions
+type Group interface {
+	// Name identifies the group and its hash, as in the ciphersuite
+	// identifiers of RFC 9497
+	Name() string
+	// Hash is the hash function of the ciphersuite
+	Hash() func() hash.Hash
+	// Order is the prime order of the group
+	Order() *big.Int
+
+	Identity() Element
+	Generator() Element
+	Add(a, b Element) Element
+	Neg(a Element) Element
+	ScalarMult(a Element, k *big.Int) Element
+	ScalarBaseMult(k *big.Int) Element
+	Equal(a, b Element) bool
+
+	// HashToGroup hashes msg to an element with no known discrete
+	// logarithm, under the domain separation tag dst
+	HashToGroup(msg, dst []byte) (Element, error)
+	// HashToScalar hashes msg to a uniform scalar under dst
+	HashToScalar(msg, dst []byte) (*big.Int, error)
+	// RandomScalar returns a uniformly random non zero scalar
+	RandomScalar(rand io.Reader) (*big.Int, error)
+
+	// ElementLen is the length of an encoded element
+	ElementLen() int
+	Marshal(a Element) []byte
+	// Unmarshal decodes an element, rejecting non canonical encodings and
+	// the identity, as DeserializeElement of RFC 9497 does
+	Unmarshal(b []byte) (Element, error)
+	// ScalarLen is the length of an encoded scalar
+	ScalarLen() int
+	MarshalScalar(k *big.Int) []byte
+	// UnmarshalScalar decodes a scalar, rejecting values that are not
+	// reduced modulo the order
+	UnmarshalScalar(b []byte) (*big.Int, error)
+}
+
+// ErrIdentity is returned when decoding the identity element
+var ErrIdentity = errors.New("group: element is the identity")
+
+// P256SHA256 is P-256 with hash to curve P256_XMD:SHA-256_SSWU_RO_,
+// big endian scalars and compressed points
+var P256SHA256 Group = p256{ec.P256()}
+
+// Ristretto255SHA512 is ristretto255 with hashing through
+// expand_message_xmd and SHA-512, and little endian scalars
+var Ristretto255SHA512 Group = ristretto{}
+
+type p256 struct {
+	c *ec.Curve
+}
+
+func (g p256) Name() string           { return "P256-SHA256" }
+func (g p256) Hash() func() hash.Hash { return sha256.New }
+func (g p256) Order() *big.Int        { return g.c.N }
+func (g p256) Identity() Element      { return ec.Infinity() }
+func (g p256) Generator() Element     { return g.c.Generator() }
+func (g p256) ElementLen() int        { return 1 + g.c.ByteLen() }
+func (g p256) ScalarLen() int         { return g.c.ByteLen() }
+
+func (g p256) Add(a, b Element) Element {
+	return g.c.Add(a.(*ec.Point), b.(*ec.Point))
+}
+
+func (g p256) Neg(a Element) Element {
+	return g.c.Neg(a.(*ec.Point))
+}
+
+func (g p256) ScalarMult(a Element, k *big.Int) Element {
+	return g.c.ScalarMult(a.(*ec.Point), k)
+}
+
+func (g p256) ScalarBaseMult(k *big.Int) Element {
+	return g.c.ScalarBaseMult(k)
+}
+
+func (g p256) Equal(a, b Element) bool {
+	return a.(*ec.Point).Equal(b.(*ec.Point))
+}
+
+func (g p256) HashToGroup(msg, dst []byte) (Element, error) {
+	return g.c.HashToCurve(sha256.New, msg, dst)
+}
+
+// HashToScalar is hash_to_field with L = 48 bytes, 128 bits more than
+// the order, as RFC 9497 specifies for P256-SHA256
+func (g p256) HashToScalar(msg, dst []byte) (*big.Int, error) {
+	k, err := ec.HashToField(sha256.New, msg, dst, 1, 48, g.c.N)
+	if err != nil {
+		return nil, err
+	}
+	return k[0], nil
+}
+
+func (g p256) RandomScalar(rand io.Reader) (*big.Int, error) {
+	return g.c.RandomScalar(rand)
+}
+
+func (g p256) Marshal(a Element) []byte {
+	return g.c.Marshal(a.(*ec.Point))
+}
+
+func (g p256) Unmarshal(b []byte) (Element, error) {
+	if len(b) != g.ElementLen() {
+		return nil, errors.New("group: invalid P-256 element length")
+	}
+	p, err := g.c.Unmarshal(b)
+	if err != nil {
+		return nil, err
+	}
+	if p.IsInfinity() {
+		return nil, ErrIdentity
+	}
+	return p, nil
+}
+
+func (g p256) MarshalScalar(k *big.Int) []byte {
+	return new(big.Int).Mod(k, g.c.N).FillBytes(make([]byte, g.ScalarLen()))
+}
+
+func (g p256) UnmarshalScalar(b []byte) (*big.Int, error) {
+	if len(b) != g.ScalarLen() {
+		return nil, errors.New("group: invalid P-256 scalar length")
+	}
+	k := new(big.Int).SetBytes(b)
+	if k.Cmp(g.c.N) >= 0 {
+		return nil, errors.New("group: non canonical P-256 scalar")
+	}
+	return k, nil
+}
+
+type ristretto struct{}
+
+func (ristretto) Name() string           { return "ristretto255-SHA512" }
+func (ristretto) Hash() func() hash.Hash { return sha512.New }
+func (ristretto) Order() *big.Int        { return ristretto255.Order }
+func (ristretto) Identity() Element      { return ristretto255.Identity() }
+func (ristretto) Generator() Element     { return ristretto255.Generator() }
+func (ristretto) ElementLen() int        { return 32 }
+func (ristretto) ScalarLen() int         { return 32 }
+
+func (ristretto) Add(a, b Element) Element {
+	return a.(*ristretto255.Element).Add(b.(*ristretto255.Element))
+}
+
+func (ristretto) Neg(a Element) Element {
+	return a.(*ristretto255.Element).Neg()
+}
+
+func (ristretto) ScalarMult(a Element, k *big.Int) Element {
+	return a.(*ristretto255.Element).ScalarMult(k)
+}
+
+func (ristretto) ScalarBaseMult(k *big.Int) Element {
+	return ristretto255.ScalarBaseMult(k)
+}
+
+func (ristretto) Equal(a, b Element) bool {
+	return a.(*ristretto255.Element).Equal(b.(*ristretto255.Element))
+}
+
+func (ristretto) HashToGroup(msg, dst []byte) (Element, error) {
+	return ristretto255.HashToGroup(msg, dst)
+}
+
+func (ristretto) HashToScalar(msg, dst []byte) (*big.Int, error) {
+	return ristretto255.HashToScalar(msg, dst)
+}
+
+func (ristretto) RandomScalar(rand io.Reader) (*big.Int, error) {
+	return ristretto255.RandomScalar(rand)
+}
+
+func (ristretto) Marshal(a Element) []byte {
+	return a.(*ristretto255.Element).Bytes()
+}
+
+func (ristretto) Unmarshal(b []byte) (Element, error) {
+	e, err := ristretto255.NewElement(b)
+	if err != nil {
+		return nil, err
+	}
+	if e.Equal(ristretto255.Identity()) {
+		return nil, ErrIdentity
+	}
+	return e, nil
+}
+
+func (ristretto) MarshalScalar(k *big.Int) []byte {
+	return ristretto255.ScalarBytes(k)
+}
+
+func (ristretto) UnmarshalScalar(b []byte) (*big.Int, error) {
+	return ristretto255.NewScalar(b)
+}
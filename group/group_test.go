@@ -0,0 +1,110 @@
+package group
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+var groups = []Group{P256SHA256, Ristretto255SHA512}
+
+func TestGroupLaws(t *testing.T) {
+	t.Parallel()
+	for _, g := range groups {
+		a, err := g.RandomScalar(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := g.RandomScalar(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pa, pb := g.ScalarBaseMult(a), g.ScalarBaseMult(b)
+		sum := new(big.Int).Add(a, b)
+		if !g.Equal(g.Add(pa, pb), g.ScalarBaseMult(sum.Mod(sum, g.Order()))) {
+			t.Fatalf("%s: expected aG + bG = (a+b)G", g.Name())
+		}
+		if !g.Equal(g.Add(pa, g.Neg(pa)), g.Identity()) {
+			t.Fatalf("%s: expected aG - aG to be the identity", g.Name())
+		}
+		if !g.Equal(g.ScalarMult(pa, b), g.ScalarMult(pb, a)) {
+			t.Fatalf("%s: expected b(aG) = a(bG)", g.Name())
+		}
+		if !g.Equal(g.ScalarMult(g.Generator(), g.Order()), g.Identity()) {
+			t.Fatalf("%s: expected the generator to have order Order", g.Name())
+		}
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	t.Parallel()
+	for _, g := range groups {
+		k, err := g.RandomScalar(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := g.ScalarBaseMult(k)
+		enc := g.Marshal(e)
+		if len(enc) != g.ElementLen() {
+			t.Fatalf("%s: expected an element of %d bytes but got %d", g.Name(), g.ElementLen(), len(enc))
+		}
+		d, err := g.Unmarshal(enc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !g.Equal(d, e) {
+			t.Fatalf("%s: expected the element to round trip", g.Name())
+		}
+		if _, err := g.Unmarshal(g.Marshal(g.Identity())); err == nil {
+			t.Fatalf("%s: expected the identity to be rejected", g.Name())
+		}
+		if _, err := g.Unmarshal(enc[1:]); err == nil {
+			t.Fatalf("%s: expected a short element to be rejected", g.Name())
+		}
+
+		s := g.MarshalScalar(k)
+		if len(s) != g.ScalarLen() {
+			t.Fatalf("%s: expected a scalar of %d bytes but got %d", g.Name(), g.ScalarLen(), len(s))
+		}
+		got, err := g.UnmarshalScalar(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Cmp(k) != 0 {
+			t.Fatalf("%s: expected the scalar to round trip", g.Name())
+		}
+		if _, err := g.UnmarshalScalar(bytes.Repeat([]byte{0xff}, g.ScalarLen())); err == nil {
+			t.Fatalf("%s: expected an unreduced scalar to be rejected", g.Name())
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	t.Parallel()
+	dst := []byte("badcrypto group test")
+	for _, g := range groups {
+		e1, err := g.HashToGroup([]byte("a"), dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e2, err := g.HashToGroup([]byte("a"), dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e3, err := g.HashToGroup([]byte("b"), dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !g.Equal(e1, e2) || g.Equal(e1, e3) {
+			t.Fatalf("%s: expected hashing to be deterministic and input dependent", g.Name())
+		}
+		k, err := g.HashToScalar([]byte("a"), dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k.Sign() < 0 || k.Cmp(g.Order()) >= 0 {
+			t.Fatalf("%s: expected a reduced scalar", g.Name())
+		}
+	}
+}
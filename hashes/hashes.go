@@ -0,0 +1,54 @@
+// Package hashes binds the hash functions of this repository to the
+// crypto.Hash identifiers of the standard library, so that code written
+// against crypto.Hash, such as the DigestInfo selection of
+// rsa.SignPKCS1v15 or the New method of crypto.Hash itself, runs them.
+//
+// The hashes implemented here are RIPEMD-160, BLAKE2b and SHA-3, which
+// the standard library names but does not implement. SHA-2 comes from the
+// standard library, and there is no BLAKE2s in this repository: Bind is
+// there for the day there is, or for implementations found elsewhere.
+package hashes
+
+import (
+	"crypto"
+	"fmt"
+	"hash"
+
+	"github.com/jvehent/badcrypto/blake2b"
+	"github.com/jvehent/badcrypto/ripemd160"
+	"github.com/jvehent/badcrypto/sha3"
+)
+
+// Bind registers f as the implementation of h with crypto.RegisterHash,
+// after checking that it has the right digest size. It replaces the
+// standard library implementation if there is one, for the whole process.
+func Bind(h crypto.Hash, f func() hash.Hash) error {
+	if h == 0 || h >= maxHash {
+		return fmt.Errorf("hashes: unknown hash function %d", h)
+	}
+	if size := f().Size(); size != h.Size() {
+		return fmt.Errorf("hashes: %v has %d bytes digests but the implementation produces %d", h, h.Size(), size)
+	}
+	crypto.RegisterHash(h, f)
+	return nil
+}
+
+// maxHash is one past the last crypto.Hash of the standard library
+const maxHash = crypto.BLAKE2b_512 + 1
+
+// Register binds every hash function of this repository to its identifier
+func Register() {
+	for h, f := range map[crypto.Hash]func() hash.Hash{
+		crypto.RIPEMD160:   ripemd160.New,
+		crypto.BLAKE2b_256: blake2b.New256,
+		crypto.BLAKE2b_384: blake2b.New384,
+		crypto.BLAKE2b_512: blake2b.New512,
+		crypto.SHA3_256:    sha3.New256,
+		crypto.SHA3_384:    sha3.New384,
+		crypto.SHA3_512:    sha3.New512,
+	} {
+		if err := Bind(h, f); err != nil {
+			panic(err)
+		}
+	}
+}
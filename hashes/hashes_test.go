@@ -0,0 +1,70 @@
+package hashes
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ripemd160"
+)
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+	Register()
+	if !crypto.RIPEMD160.Available() {
+		t.Fatal("expected RIPEMD-160 to be available")
+	}
+	h := crypto.RIPEMD160.New()
+	h.Write([]byte("abc"))
+	if got := hex.EncodeToString(h.Sum(nil)); got != "8eb208f7e05d987a9b044a8e98c6b087f15a0bfc" {
+		t.Fatalf("expected the RIPEMD-160 of abc but got %s", got)
+	}
+	h = crypto.BLAKE2b_256.New()
+	h.Write([]byte("abc"))
+	if got := hex.EncodeToString(h.Sum(nil)); got != "bddd813c634239723171ef3fee98579b94964e3bb1cb3e427262c8c068d52319" {
+		t.Fatalf("expected the BLAKE2b-256 of abc but got %s", got)
+	}
+	h = crypto.SHA3_256.New()
+	h.Write([]byte("abc"))
+	if got := hex.EncodeToString(h.Sum(nil)); got != "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532" {
+		t.Fatalf("expected the SHA3-256 of abc but got %s", got)
+	}
+
+	// crypto/rsa picks the RIPEMD-160 DigestInfo
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := ripemd160.Sum([]byte("message"))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.RIPEMD160, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.RIPEMD160, digest[:], sig); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBind(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		h     crypto.Hash
+		f     func() hash.Hash
+		valid bool
+	}{
+		{crypto.RIPEMD160, ripemd160.New, true},
+		{crypto.SHA256, ripemd160.New, false},
+		{crypto.SHA224, sha256.New, false},
+		{0, ripemd160.New, false},
+		{crypto.Hash(200), ripemd160.New, false},
+	}
+	for i, tc := range testcases {
+		if err := Bind(tc.h, tc.f); (err == nil) != tc.valid {
+			t.Fatalf("testcase %d expected valid=%v but got %v", i, tc.valid, err)
+		}
+	}
+}
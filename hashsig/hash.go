@@ -0,0 +1,187 @@
+// Package hashsig implements signatures whose only assumption is a secure
+// hash function: WOTS+ one-time signatures, XMSS which certifies 2^h WOTS+
+// keys with a Merkle tree, and SPHINCS-lite, a small SPHINCS+.
+//
+// A WOTS+ key signs one message: signing reveals points along hash chains
+// that let anyone forge from a second signature. XMSS signs up to 2^h
+// messages with a single public key, the root of the tree, but the signer
+// must never use a leaf twice: it is stateful, and a key copied to a
+// backup or restored from a snapshot is a key that signs twice. SPHINCS+
+// removes the state by making the tree so large that leaves chosen from
+// the hash of the message collide only rarely, and by signing at the
+// leaves with FORS, a few-time scheme that tolerates those collisions. The
+// tree is a hypertree, layers of small trees each signing the roots below,
+// so that keys are generated without computing 2^64 leaves.
+//
+// All three follow the structure of SPHINCS+ with its simple tweakable
+// hash, H(pub seed || address || input), on any hash function of the
+// repository, but SPHINCS-lite uses much smaller parameters than the
+// SPHINCS+ and SLH-DSA parameter sets and is not interoperable with them.
+package hashsig
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+
+	"github.com/jvehent/badcrypto/blake2b"
+	"github.com/jvehent/badcrypto/sha3"
+)
+
+// Params selects the hash function of the schemes and the size N of the
+// hashes, keys and signature elements. Security against classical
+// attackers is 8N bits, and 4N bits against quantum ones.
+type Params struct {
+	// Hash returns a new instance of the hash function, whose output is
+	// truncated to N bytes
+	Hash func() hash.Hash
+	N    int
+}
+
+var (
+	// SHA3_256 uses SHA3-256 with N = 32
+	SHA3_256 = &Params{Hash: sha3.New256, N: 32}
+	// BLAKE2b_256 uses BLAKE2b-256 with N = 32
+	BLAKE2b_256 = &Params{Hash: blake2b.New256, N: 32}
+)
+
+var (
+	// ErrKeyUsed is returned when signing a second time with a WOTS+ key
+	ErrKeyUsed = errors.New("hashsig: one-time key already used")
+	// ErrExhausted is returned when every leaf of an XMSS key is used
+	ErrExhausted = errors.New("hashsig: every one-time key of the tree is used")
+)
+
+func (p *Params) check() error {
+	if p.N < 16 || p.N > p.Hash().Size() {
+		return errors.New("hashsig: N must be at least 16 and at most the hash size")
+	}
+	return nil
+}
+
+// types of addresses, which separate the uses of the tweakable hash
+const (
+	addrWOTS = iota
+	addrWOTSPK
+	addrTree
+	addrFORSTree
+	addrFORSRoots
+	addrWOTSPRF
+	addrFORSPRF
+)
+
+// address locates a hash in the structure of the scheme: the layer and
+// tree of the hypertree, then depending on the type the WOTS+ key, chain
+// and step, or the height and index of a tree node
+type address struct {
+	layer   uint32
+	tree    uint64
+	typ     uint32
+	keyPair uint32
+	height  uint32
+	index   uint32
+}
+
+func (a *address) bytes() []byte {
+	b := make([]byte, 32)
+	binary.BigEndian.PutUint32(b[0:], a.layer)
+	binary.BigEndian.PutUint64(b[8:], a.tree)
+	binary.BigEndian.PutUint32(b[16:], a.typ)
+	binary.BigEndian.PutUint32(b[20:], a.keyPair)
+	binary.BigEndian.PutUint32(b[24:], a.height)
+	binary.BigEndian.PutUint32(b[28:], a.index)
+	return b
+}
+
+// prefixes of the hash inputs, so that message hashing and the tweakable
+// hash never share an input
+const (
+	prefixTweak = iota
+	prefixPRFMsg
+	prefixMsg
+	prefixMsgExpand
+)
+
+func (p *Params) hash(parts ...[]byte) []byte {
+	h := p.Hash()
+	for _, x := range parts {
+		h.Write(x)
+	}
+	return h.Sum(nil)
+}
+
+// thash is the tweakable hash, H(pub seed || address || inputs)
+func (p *Params) thash(pubSeed []byte, adrs address, in ...[]byte) []byte {
+	h := p.Hash()
+	h.Write([]byte{prefixTweak})
+	h.Write(pubSeed)
+	h.Write(adrs.bytes())
+	for _, x := range in {
+		h.Write(x)
+	}
+	return h.Sum(nil)[:p.N]
+}
+
+// prf derives the secret at adrs from the secret seed
+func (p *Params) prf(pubSeed, skSeed []byte, adrs address) []byte {
+	return p.thash(pubSeed, adrs, skSeed)
+}
+
+// prfMsg derives the randomizer of a signature
+func (p *Params) prfMsg(skPRF, optRand, msg []byte) []byte {
+	return p.hash([]byte{prefixPRFMsg}, skPRF, optRand, msg)[:p.N]
+}
+
+// hashMessage returns size bytes of digest of msg, bound to the public key
+// and the randomizer r
+func (p *Params) hashMessage(r, pubSeed, root, msg []byte, size int) []byte {
+	seed := p.hash([]byte{prefixMsg}, r, pubSeed, root, msg)
+	var out []byte
+	for counter := uint32(0); len(out) < size; counter++ {
+		var c [4]byte
+		binary.BigEndian.PutUint32(c[:], counter)
+		out = append(out, p.hash([]byte{prefixMsgExpand}, seed, c[:])...)
+	}
+	return out[:size]
+}
+
+// buildTree returns every level of the Merkle tree over leaves, from the
+// leaves to the root, with node addresses of the type of adrs. base is
+// the index of the first leaf among all the trees of that type, so that
+// each node of the tree has a distinct address.
+func (p *Params) buildTree(leaves [][]byte, base uint32, pubSeed []byte, adrs address) [][][]byte {
+	levels := [][][]byte{leaves}
+	for j := uint32(1); len(levels[j-1]) > 1; j++ {
+		below := levels[j-1]
+		level := make([][]byte, len(below)/2)
+		for i := range level {
+			adrs.height, adrs.index = j, base>>j+uint32(i)
+			level[i] = p.thash(pubSeed, adrs, below[2*i], below[2*i+1])
+		}
+		levels = append(levels, level)
+	}
+	return levels
+}
+
+// authPath appends the siblings of the nodes from leaf to the root
+func authPath(out []byte, levels [][][]byte, leaf uint32) []byte {
+	for j := 0; j < len(levels)-1; j++ {
+		out = append(out, levels[j][leaf>>uint(j)^1]...)
+	}
+	return out
+}
+
+// rootFromPath returns the root reached from node, the leaf at index leaf
+// among all the trees of the type of adrs, and its authentication path
+func (p *Params) rootFromPath(node []byte, leaf uint32, path, pubSeed []byte, adrs address) []byte {
+	for j := uint32(0); j < uint32(len(path)/p.N); j++ {
+		sibling := path[int(j)*p.N : int(j+1)*p.N]
+		adrs.height, adrs.index = j+1, leaf>>(j+1)
+		if leaf>>j&1 == 0 {
+			node = p.thash(pubSeed, adrs, node, sibling)
+		} else {
+			node = p.thash(pubSeed, adrs, sibling, node)
+		}
+	}
+	return node
+}
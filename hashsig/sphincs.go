@@ -0,0 +1,188 @@
+package hashsig
+
+import (
+	"bytes"
+	"io"
+)
+
+// parameters of SPHINCS-lite: a hypertree of 4 layers of trees of height
+// 4 has 2^16 leaves, each a FORS key of 12 trees of height 8. A FORS
+// signature reveals one leaf per tree, so after the same FORS key signs
+// twice a forger still has to match 12 leaves chosen by the hash of its
+// message out of the at most 2 revealed per tree.
+const (
+	sphincsLayers     = 4
+	sphincsTreeHeight = 4
+	forsTrees         = 12
+	forsHeight        = 8
+)
+
+// sphincsDigestSize is the size of the digest of a message: one byte per
+// FORS tree for the revealed leaves, then the 16 bits of the hypertree
+// leaf that signs
+const sphincsDigestSize = forsTrees*forsHeight/8 + sphincsLayers*sphincsTreeHeight/8
+
+// SPHINCSSignatureSize returns the size of a SPHINCS-lite signature: the
+// randomizer, the FORS signature, then a WOTS+ signature and
+// authentication path per layer
+func (p *Params) SPHINCSSignatureSize() int {
+	return p.N + forsTrees*(forsHeight+1)*p.N + sphincsLayers*(p.WOTSSignatureSize()+sphincsTreeHeight*p.N)
+}
+
+// SPHINCSPrivateKey is a SPHINCS-lite key. It has no state and may be
+// copied freely.
+type SPHINCSPrivateKey struct {
+	params                       *Params
+	skSeed, skPRF, pubSeed, root []byte
+}
+
+// GenerateSPHINCSKey returns a new SPHINCS-lite key with seeds read from
+// rand. Only the top tree of the hypertree is computed.
+func GenerateSPHINCSKey(params *Params, rand io.Reader) (*SPHINCSPrivateKey, error) {
+	if err := params.check(); err != nil {
+		return nil, err
+	}
+	seeds := make([]byte, 3*params.N)
+	if _, err := io.ReadFull(rand, seeds); err != nil {
+		return nil, err
+	}
+	key := &SPHINCSPrivateKey{
+		params:  params,
+		skSeed:  seeds[:params.N],
+		skPRF:   seeds[params.N : 2*params.N],
+		pubSeed: seeds[2*params.N:],
+	}
+	top := params.wotsTree(key.skSeed, key.pubSeed, address{layer: sphincsLayers - 1}, sphincsTreeHeight)
+	key.root = top[sphincsTreeHeight][0]
+	return key, nil
+}
+
+// PublicKey returns the public key, the public seed and the root of the
+// hypertree
+func (key *SPHINCSPrivateKey) PublicKey() []byte {
+	return append(append([]byte{}, key.pubSeed...), key.root...)
+}
+
+// sphincsIndices splits a digest into the FORS leaves and the hypertree
+// leaf, as the tree of the bottom layer and the leaf in it
+func sphincsIndices(digest []byte) (fors []byte, tree uint64, leaf uint32) {
+	fors = digest[:forsTrees]
+	for _, b := range digest[forsTrees:] {
+		tree = tree<<8 | uint64(b)
+	}
+	leaf = uint32(tree & (1<<sphincsTreeHeight - 1))
+	return fors, tree >> sphincsTreeHeight, leaf
+}
+
+// Sign returns the signature of msg. The randomizer is derived from the
+// key, msg and 32 bytes read from rand, or only the key and msg if rand is
+// nil, which makes signing deterministic.
+func (key *SPHINCSPrivateKey) Sign(rand io.Reader, msg []byte) ([]byte, error) {
+	p := key.params
+	optRand := key.pubSeed
+	if rand != nil {
+		optRand = make([]byte, 32)
+		if _, err := io.ReadFull(rand, optRand); err != nil {
+			return nil, err
+		}
+	}
+	r := p.prfMsg(key.skPRF, optRand, msg)
+	digest := p.hashMessage(r, key.pubSeed, key.root, msg, sphincsDigestSize)
+	indices, tree, leaf := sphincsIndices(digest)
+
+	sig := make([]byte, 0, p.SPHINCSSignatureSize())
+	sig = append(sig, r...)
+	var node []byte
+	sig, node = p.forsSign(sig, indices, key.skSeed, key.pubSeed, address{tree: tree, keyPair: leaf})
+	// each layer signs the root of the tree below with one of its leaves
+	for layer := uint32(0); layer < sphincsLayers; layer++ {
+		adrs := address{layer: layer, tree: tree}
+		levels := p.wotsTree(key.skSeed, key.pubSeed, adrs, sphincsTreeHeight)
+		adrs.keyPair = leaf
+		sig = p.wotsSign(sig, node, key.skSeed, key.pubSeed, adrs)
+		sig = authPath(sig, levels, leaf)
+		node = levels[sphincsTreeHeight][0]
+		leaf = uint32(tree & (1<<sphincsTreeHeight - 1))
+		tree >>= sphincsTreeHeight
+	}
+	return sig, nil
+}
+
+// VerifySPHINCS tells whether sig is a valid SPHINCS-lite signature of
+// msg by pub
+func VerifySPHINCS(params *Params, pub, msg, sig []byte) bool {
+	if params.check() != nil || len(pub) != 2*params.N || len(sig) != params.SPHINCSSignatureSize() {
+		return false
+	}
+	p := params
+	pubSeed, root := pub[:p.N], pub[p.N:]
+	r := sig[:p.N]
+	sig = sig[p.N:]
+	digest := p.hashMessage(r, pubSeed, root, msg, sphincsDigestSize)
+	indices, tree, leaf := sphincsIndices(digest)
+
+	forsSize := forsTrees * (forsHeight + 1) * p.N
+	node := p.forsPublicKeyFromSig(sig[:forsSize], indices, pubSeed, address{tree: tree, keyPair: leaf})
+	sig = sig[forsSize:]
+	for layer := uint32(0); layer < sphincsLayers; layer++ {
+		wotsSize := p.WOTSSignatureSize()
+		adrs := address{layer: layer, tree: tree, keyPair: leaf}
+		node = p.wotsPublicKeyFromSig(sig[:wotsSize], node, pubSeed, adrs)
+		adrs.typ, adrs.keyPair = addrTree, 0
+		node = p.rootFromPath(node, leaf, sig[wotsSize:wotsSize+sphincsTreeHeight*p.N], pubSeed, adrs)
+		sig = sig[wotsSize+sphincsTreeHeight*p.N:]
+		leaf = uint32(tree & (1<<sphincsTreeHeight - 1))
+		tree >>= sphincsTreeHeight
+	}
+	return bytes.Equal(node, root)
+}
+
+// forsLeaf returns the secret and the leaf at index among all the leaves
+// of the FORS key at adrs
+func (p *Params) forsLeaf(skSeed, pubSeed []byte, adrs address, index uint32) (secret, leaf []byte) {
+	adrs.typ, adrs.index = addrFORSPRF, index
+	secret = p.prf(pubSeed, skSeed, adrs)
+	adrs.typ = addrFORSTree
+	return secret, p.thash(pubSeed, adrs, secret)
+}
+
+// forsSign appends the FORS signature of indices, the secret of one leaf
+// per tree and its authentication path, and returns the FORS public key,
+// the hash of the roots of the trees
+func (p *Params) forsSign(out, indices, skSeed, pubSeed []byte, adrs address) ([]byte, []byte) {
+	roots := make([][]byte, forsTrees)
+	for t, i := range indices {
+		base := uint32(t) << forsHeight
+		leaves := make([][]byte, 1<<forsHeight)
+		for j := range leaves {
+			secret, leaf := p.forsLeaf(skSeed, pubSeed, adrs, base+uint32(j))
+			if j == int(i) {
+				out = append(out, secret...)
+			}
+			leaves[j] = leaf
+		}
+		nodes := adrs
+		nodes.typ = addrFORSTree
+		levels := p.buildTree(leaves, base, pubSeed, nodes)
+		out = authPath(out, levels, uint32(i))
+		roots[t] = levels[forsHeight][0]
+	}
+	adrs.typ = addrFORSRoots
+	return out, p.thash(pubSeed, adrs, roots...)
+}
+
+// forsPublicKeyFromSig returns the FORS public key that sig leads to
+func (p *Params) forsPublicKeyFromSig(sig, indices, pubSeed []byte, adrs address) []byte {
+	roots := make([][]byte, forsTrees)
+	nodes := adrs
+	nodes.typ = addrFORSTree
+	for t, i := range indices {
+		index := uint32(t)<<forsHeight + uint32(i)
+		part := sig[t*(forsHeight+1)*p.N : (t+1)*(forsHeight+1)*p.N]
+		nodes.height, nodes.index = 0, index
+		leaf := p.thash(pubSeed, nodes, part[:p.N])
+		roots[t] = p.rootFromPath(leaf, index, part[p.N:], pubSeed, nodes)
+	}
+	adrs.typ = addrFORSRoots
+	return p.thash(pubSeed, adrs, roots...)
+}
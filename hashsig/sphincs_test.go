@@ -0,0 +1,69 @@
+package hashsig
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestSPHINCS(t *testing.T) {
+	t.Parallel()
+	for _, params := range []*Params{SHA3_256, BLAKE2b_256} {
+		key, err := GenerateSPHINCSKey(params, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub := key.PublicKey()
+		msg := []byte("stateless")
+		sig, err := key.Sign(rand.Reader, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sig) != params.SPHINCSSignatureSize() {
+			t.Fatalf("expected a signature of %d bytes but got %d", params.SPHINCSSignatureSize(), len(sig))
+		}
+		if !VerifySPHINCS(params, pub, msg, sig) {
+			t.Fatal("signature does not verify")
+		}
+		if VerifySPHINCS(params, pub, []byte("stateful"), sig) {
+			t.Fatal("signature verifies a different message")
+		}
+		// the randomizer, a FORS secret, a WOTS+ chain and the top
+		// authentication path
+		for _, i := range []int{0, params.N, len(sig) - params.SPHINCSSignatureSize()/2, len(sig) - 1} {
+			altered := append([]byte{}, sig...)
+			altered[i] ^= 1
+			if VerifySPHINCS(params, pub, msg, altered) {
+				t.Fatalf("signature altered at %d verifies", i)
+			}
+		}
+	}
+}
+
+func TestSPHINCSDeterministic(t *testing.T) {
+	t.Parallel()
+	params := BLAKE2b_256
+	key, err := GenerateSPHINCSKey(params, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("deterministic")
+	a, _ := key.Sign(nil, msg)
+	b, _ := key.Sign(nil, msg)
+	c, _ := key.Sign(rand.Reader, msg)
+	if !bytes.Equal(a, b) {
+		t.Fatal("deterministic signatures differ")
+	}
+	if bytes.Equal(a, c) {
+		t.Fatal("randomized signature equals the deterministic one")
+	}
+	for _, sig := range [][]byte{a, c} {
+		if !VerifySPHINCS(params, key.PublicKey(), msg, sig) {
+			t.Fatal("signature does not verify")
+		}
+	}
+	other, _ := GenerateSPHINCSKey(params, rand.Reader)
+	if VerifySPHINCS(params, other.PublicKey(), msg, a) {
+		t.Fatal("signature verifies with a different key")
+	}
+}
@@ -0,0 +1,145 @@
+package hashsig
+
+import (
+	"bytes"
+	"io"
+)
+
+// w is the Winternitz parameter: each chain of w - 1 hashes signs 4 bits
+const w = 16
+
+// wotsLen2 is the number of base w digits of the checksum, enough for
+// 2N (w - 1) up to N = 64
+const wotsLen2 = 3
+
+// wotsLen is the number of chains, 2N digits of the message and the
+// checksum
+func (p *Params) wotsLen() int {
+	return 2*p.N + wotsLen2
+}
+
+// WOTSSignatureSize returns the size of a WOTS+ signature
+func (p *Params) WOTSSignatureSize() int {
+	return p.wotsLen() * p.N
+}
+
+// wotsDigits returns the base w digits of msg followed by those of the
+// checksum, the sum of w - 1 - digit: moving a message digit up the chain
+// moves a checksum digit down, which a forger cannot undo
+func (p *Params) wotsDigits(msg []byte) []int {
+	digits := make([]int, 0, p.wotsLen())
+	for _, b := range msg {
+		digits = append(digits, int(b>>4), int(b&0x0f))
+	}
+	checksum := 0
+	for _, d := range digits {
+		checksum += w - 1 - d
+	}
+	for i := wotsLen2 - 1; i >= 0; i-- {
+		digits = append(digits, checksum>>(4*uint(i))&0x0f)
+	}
+	return digits
+}
+
+// chain hashes x steps times from position start of the chain at adrs
+func (p *Params) chain(x []byte, start, steps int, pubSeed []byte, adrs address) []byte {
+	adrs.typ = addrWOTS
+	for i := start; i < start+steps; i++ {
+		adrs.index = uint32(i)
+		x = p.thash(pubSeed, adrs, x)
+	}
+	return x
+}
+
+// wotsSecret returns the start of chain i of the WOTS+ key at adrs
+func (p *Params) wotsSecret(skSeed, pubSeed []byte, adrs address, i int) []byte {
+	adrs.typ, adrs.height, adrs.index = addrWOTSPRF, uint32(i), 0
+	return p.prf(pubSeed, skSeed, adrs)
+}
+
+// wotsCompress hashes the ends of the chains into the public key
+func (p *Params) wotsCompress(ends [][]byte, pubSeed []byte, adrs address) []byte {
+	adrs.typ, adrs.height, adrs.index = addrWOTSPK, 0, 0
+	return p.thash(pubSeed, adrs, ends...)
+}
+
+// wotsPublicKey returns the public key of the WOTS+ key at adrs, the hash
+// of the ends of its chains
+func (p *Params) wotsPublicKey(skSeed, pubSeed []byte, adrs address) []byte {
+	ends := make([][]byte, p.wotsLen())
+	for i := range ends {
+		adrs.height = uint32(i)
+		ends[i] = p.chain(p.wotsSecret(skSeed, pubSeed, adrs, i), 0, w-1, pubSeed, adrs)
+	}
+	return p.wotsCompress(ends, pubSeed, adrs)
+}
+
+// wotsSign appends the signature of the N bytes msg with the WOTS+ key at
+// adrs: each chain walked as many steps as its digit
+func (p *Params) wotsSign(out, msg, skSeed, pubSeed []byte, adrs address) []byte {
+	for i, d := range p.wotsDigits(msg) {
+		adrs.height = uint32(i)
+		out = append(out, p.chain(p.wotsSecret(skSeed, pubSeed, adrs, i), 0, d, pubSeed, adrs)...)
+	}
+	return out
+}
+
+// wotsPublicKeyFromSig finishes the chains of sig, which gives the public
+// key of adrs if sig is a signature of msg
+func (p *Params) wotsPublicKeyFromSig(sig, msg, pubSeed []byte, adrs address) []byte {
+	ends := make([][]byte, p.wotsLen())
+	for i, d := range p.wotsDigits(msg) {
+		adrs.height = uint32(i)
+		ends[i] = p.chain(sig[i*p.N:(i+1)*p.N], d, w-1-d, pubSeed, adrs)
+	}
+	return p.wotsCompress(ends, pubSeed, adrs)
+}
+
+// WOTSPrivateKey is a WOTS+ key, which signs a single message
+type WOTSPrivateKey struct {
+	params          *Params
+	skSeed, pubSeed []byte
+	pub             []byte
+	used            bool
+}
+
+// GenerateWOTSKey returns a new WOTS+ key with seeds read from rand
+func GenerateWOTSKey(params *Params, rand io.Reader) (*WOTSPrivateKey, error) {
+	if err := params.check(); err != nil {
+		return nil, err
+	}
+	seeds := make([]byte, 2*params.N)
+	if _, err := io.ReadFull(rand, seeds); err != nil {
+		return nil, err
+	}
+	key := &WOTSPrivateKey{params: params, skSeed: seeds[:params.N], pubSeed: seeds[params.N:]}
+	key.pub = params.wotsPublicKey(key.skSeed, key.pubSeed, address{})
+	return key, nil
+}
+
+// PublicKey returns the public key, the public seed followed by the hash
+// of the ends of the chains
+func (key *WOTSPrivateKey) PublicKey() []byte {
+	return append(append([]byte{}, key.pubSeed...), key.pub...)
+}
+
+// Sign returns the signature of msg, or ErrKeyUsed if the key has already
+// signed
+func (key *WOTSPrivateKey) Sign(msg []byte) ([]byte, error) {
+	if key.used {
+		return nil, ErrKeyUsed
+	}
+	key.used = true
+	digest := key.params.hashMessage(nil, key.pubSeed, key.pub, msg, key.params.N)
+	return key.params.wotsSign(nil, digest, key.skSeed, key.pubSeed, address{}), nil
+}
+
+// VerifyWOTS tells whether sig is a valid WOTS+ signature of msg by pub
+func VerifyWOTS(params *Params, pub, msg, sig []byte) bool {
+	if params.check() != nil || len(pub) != 2*params.N || len(sig) != params.WOTSSignatureSize() {
+		return false
+	}
+	pubSeed, root := pub[:params.N], pub[params.N:]
+	digest := params.hashMessage(nil, pubSeed, root, msg, params.N)
+	return bytes.Equal(params.wotsPublicKeyFromSig(sig, digest, pubSeed, address{}), root)
+}
@@ -0,0 +1,91 @@
+package hashsig
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestWOTS(t *testing.T) {
+	t.Parallel()
+	for _, params := range []*Params{SHA3_256, BLAKE2b_256, {Hash: BLAKE2b_256.Hash, N: 16}} {
+		key, err := GenerateWOTSKey(params, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub := key.PublicKey()
+		sig, err := key.Sign([]byte("one message"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sig) != params.WOTSSignatureSize() {
+			t.Fatalf("expected a signature of %d bytes but got %d", params.WOTSSignatureSize(), len(sig))
+		}
+		if !VerifyWOTS(params, pub, []byte("one message"), sig) {
+			t.Fatal("signature does not verify")
+		}
+		if VerifyWOTS(params, pub, []byte("two messages"), sig) {
+			t.Fatal("signature verifies a different message")
+		}
+		sig[0] ^= 1
+		if VerifyWOTS(params, pub, []byte("one message"), sig) {
+			t.Fatal("altered signature verifies")
+		}
+		if _, err := key.Sign([]byte("two messages")); err != ErrKeyUsed {
+			t.Fatalf("expected ErrKeyUsed but got %v", err)
+		}
+	}
+}
+
+func TestChain(t *testing.T) {
+	t.Parallel()
+	p := BLAKE2b_256
+	seed := make([]byte, p.N)
+	x := bytes.Repeat([]byte{1}, p.N)
+	adrs := address{keyPair: 3, height: 5}
+	end := p.chain(x, 0, w-1, seed, adrs)
+	for i := 0; i < w; i++ {
+		if !bytes.Equal(p.chain(p.chain(x, 0, i, seed, adrs), i, w-1-i, seed, adrs), end) {
+			t.Fatalf("chain split at %d does not reach the end", i)
+		}
+	}
+	if bytes.Equal(p.chain(x, 0, w-1, seed, address{keyPair: 3, height: 6}), end) {
+		t.Fatal("chains at different addresses are equal")
+	}
+}
+
+// TestChecksum checks that raising a digit of the message lowers the
+// checksum, so that a signature cannot be moved up the chains
+func TestChecksum(t *testing.T) {
+	t.Parallel()
+	p := SHA3_256
+	zero := p.wotsDigits(make([]byte, p.N))
+	high := p.wotsDigits(bytes.Repeat([]byte{0xff}, p.N))
+	if len(zero) != p.wotsLen() || len(high) != p.wotsLen() {
+		t.Fatalf("expected %d digits but got %d", p.wotsLen(), len(zero))
+	}
+	for i := 2 * p.N; i < p.wotsLen(); i++ {
+		if high[i] != 0 {
+			t.Fatalf("expected a zero checksum but got digit %d", high[i])
+		}
+	}
+	// 2N (w - 1) = 960 = 0x3c0
+	if zero[2*p.N] != 3 || zero[2*p.N+1] != 12 || zero[2*p.N+2] != 0 {
+		t.Fatalf("expected checksum 3c0 but got %x%x%x", zero[2*p.N], zero[2*p.N+1], zero[2*p.N+2])
+	}
+}
+
+func TestParams(t *testing.T) {
+	t.Parallel()
+	for _, params := range []*Params{{Hash: SHA3_256.Hash, N: 8}, {Hash: SHA3_256.Hash, N: 33}} {
+		if _, err := GenerateWOTSKey(params, rand.Reader); err == nil {
+			t.Fatalf("expected an error with N = %d", params.N)
+		}
+		if _, err := GenerateXMSSKey(params, 4, rand.Reader); err == nil {
+			t.Fatalf("expected an error with N = %d", params.N)
+		}
+		if _, err := GenerateSPHINCSKey(params, rand.Reader); err == nil {
+			t.Fatalf("expected an error with N = %d", params.N)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package hashsig
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// MaxXMSSHeight is the largest XMSS tree, 2^20 signatures. The private
+// key keeps the whole tree in memory.
+const MaxXMSSHeight = 20
+
+// XMSSSignatureSize returns the size of an XMSS signature with a tree of
+// the given height: the leaf index, the randomizer, the WOTS+ signature
+// and the authentication path
+func (p *Params) XMSSSignatureSize(height int) int {
+	return 4 + p.N + p.WOTSSignatureSize() + height*p.N
+}
+
+// XMSSPrivateKey is an XMSS key. It holds the index of the next unused
+// leaf, and must not be copied: two copies sign with the same leaves.
+type XMSSPrivateKey struct {
+	params                 *Params
+	height                 int
+	skSeed, skPRF, pubSeed []byte
+	next                   uint32
+	// tree holds the WOTS+ public keys and every node above them
+	tree [][][]byte
+}
+
+// GenerateXMSSKey returns a new XMSS key of 2^height leaves with seeds
+// read from rand. It computes every WOTS+ public key, which takes seconds
+// for a height of 10.
+func GenerateXMSSKey(params *Params, height int, rand io.Reader) (*XMSSPrivateKey, error) {
+	if err := params.check(); err != nil {
+		return nil, err
+	}
+	if height < 1 || height > MaxXMSSHeight {
+		return nil, errors.New("hashsig: invalid XMSS height")
+	}
+	seeds := make([]byte, 3*params.N)
+	if _, err := io.ReadFull(rand, seeds); err != nil {
+		return nil, err
+	}
+	key := &XMSSPrivateKey{
+		params:  params,
+		height:  height,
+		skSeed:  seeds[:params.N],
+		skPRF:   seeds[params.N : 2*params.N],
+		pubSeed: seeds[2*params.N:],
+	}
+	key.tree = params.wotsTree(key.skSeed, key.pubSeed, address{}, height)
+	return key, nil
+}
+
+// wotsTree returns the Merkle tree of the 2^height WOTS+ keys at adrs
+func (p *Params) wotsTree(skSeed, pubSeed []byte, adrs address, height int) [][][]byte {
+	leaves := make([][]byte, 1<<uint(height))
+	for i := range leaves {
+		adrs.keyPair = uint32(i)
+		leaves[i] = p.wotsPublicKey(skSeed, pubSeed, adrs)
+	}
+	adrs.typ, adrs.keyPair = addrTree, 0
+	return p.buildTree(leaves, 0, pubSeed, adrs)
+}
+
+func (key *XMSSPrivateKey) root() []byte {
+	return key.tree[key.height][0]
+}
+
+// PublicKey returns the public key: the height, the root and the public
+// seed
+func (key *XMSSPrivateKey) PublicKey() []byte {
+	pub := []byte{byte(key.height)}
+	pub = append(pub, key.root()...)
+	return append(pub, key.pubSeed...)
+}
+
+// Remaining returns the number of signatures left
+func (key *XMSSPrivateKey) Remaining() uint32 {
+	return 1<<uint(key.height) - key.next
+}
+
+// Sign returns the signature of msg with the next unused leaf, or
+// ErrExhausted once they are all used. The leaf is marked used before
+// the signature is computed: a key whose state is saved must be saved
+// before the signature is released.
+func (key *XMSSPrivateKey) Sign(msg []byte) ([]byte, error) {
+	if key.Remaining() == 0 {
+		return nil, ErrExhausted
+	}
+	leaf := key.next
+	key.next++
+
+	p := key.params
+	var index [4]byte
+	binary.BigEndian.PutUint32(index[:], leaf)
+	// the randomizer makes the digest unpredictable to an attacker
+	// looking for collisions before the signature
+	r := p.prfMsg(key.skPRF, index[:], msg)
+	digest := p.hashMessage(r, key.pubSeed, key.root(), append(index[:], msg...), p.N)
+
+	sig := make([]byte, 0, p.XMSSSignatureSize(key.height))
+	sig = append(sig, index[:]...)
+	sig = append(sig, r...)
+	sig = p.wotsSign(sig, digest, key.skSeed, key.pubSeed, address{keyPair: leaf})
+	return authPath(sig, key.tree, leaf), nil
+}
+
+// VerifyXMSS tells whether sig is a valid XMSS signature of msg by pub
+func VerifyXMSS(params *Params, pub, msg, sig []byte) bool {
+	if params.check() != nil || len(pub) != 1+2*params.N {
+		return false
+	}
+	height := int(pub[0])
+	if height < 1 || height > MaxXMSSHeight || len(sig) != params.XMSSSignatureSize(height) {
+		return false
+	}
+	root, pubSeed := pub[1:1+params.N], pub[1+params.N:]
+	leaf := binary.BigEndian.Uint32(sig)
+	if leaf>>uint(height) != 0 {
+		return false
+	}
+	r := sig[4 : 4+params.N]
+	wotsSig := sig[4+params.N : 4+params.N+params.WOTSSignatureSize()]
+	path := sig[4+params.N+params.WOTSSignatureSize():]
+
+	digest := params.hashMessage(r, pubSeed, root, append(sig[:4:4], msg...), params.N)
+	node := params.wotsPublicKeyFromSig(wotsSig, digest, pubSeed, address{keyPair: leaf})
+	return bytes.Equal(params.rootFromPath(node, leaf, path, pubSeed, address{typ: addrTree}), root)
+}
@@ -0,0 +1,65 @@
+package hashsig
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"testing"
+)
+
+func TestXMSS(t *testing.T) {
+	t.Parallel()
+	params := BLAKE2b_256
+	key, err := GenerateXMSSKey(params, 3, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := key.PublicKey()
+	var sigs [][]byte
+	for i := 0; i < 8; i++ {
+		if key.Remaining() != uint32(8-i) {
+			t.Fatalf("expected %d signatures left but got %d", 8-i, key.Remaining())
+		}
+		msg := []byte(fmt.Sprintf("message %d", i))
+		sig, err := key.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sig) != params.XMSSSignatureSize(3) {
+			t.Fatalf("expected a signature of %d bytes but got %d", params.XMSSSignatureSize(3), len(sig))
+		}
+		if binary.BigEndian.Uint32(sig) != uint32(i) {
+			t.Fatalf("expected leaf %d but got %d", i, binary.BigEndian.Uint32(sig))
+		}
+		if !VerifyXMSS(params, pub, msg, sig) {
+			t.Fatalf("signature %d does not verify", i)
+		}
+		if VerifyXMSS(params, pub, []byte("other"), sig) {
+			t.Fatalf("signature %d verifies a different message", i)
+		}
+		sigs = append(sigs, sig)
+	}
+	if _, err := key.Sign([]byte("one more")); err != ErrExhausted {
+		t.Fatalf("expected ErrExhausted but got %v", err)
+	}
+
+	// a signature moved to another leaf, or with an altered path, fails
+	moved := append([]byte{}, sigs[2]...)
+	binary.BigEndian.PutUint32(moved, 3)
+	altered := append([]byte{}, sigs[2]...)
+	altered[len(altered)-1] ^= 1
+	outside := append([]byte{}, sigs[2]...)
+	binary.BigEndian.PutUint32(outside, 10)
+	for _, sig := range [][]byte{moved, altered, outside, sigs[2][1:]} {
+		if VerifyXMSS(params, pub, []byte("message 2"), sig) {
+			t.Fatal("invalid signature verifies")
+		}
+	}
+	other, _ := GenerateXMSSKey(params, 3, rand.Reader)
+	if VerifyXMSS(params, other.PublicKey(), []byte("message 0"), sigs[0]) {
+		t.Fatal("signature verifies with a different key")
+	}
+	if _, err := GenerateXMSSKey(params, MaxXMSSHeight+1, rand.Reader); err == nil {
+		t.Fatal("expected an error with a tree too high")
+	}
+}
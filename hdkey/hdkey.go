@@ -0,0 +1,279 @@
+// Package hdkey implements the hierarchical deterministic wallets of
+// BIP 32 over secp256k1: a master key derived from a seed, private and
+// public child derivation, derivation paths such as m/44'/0'/0'/0/1, and
+// the xprv and xpub serialization.
+//
+// Normal children can be derived from the parent public key alone, which
+// lets a watch only wallet generate addresses. Hardened children, with an
+// index of HardenedOffset or more, require the parent private key. Note
+// that a leaked normal child private key and the parent xpub reveal the
+// parent private key.
+package hdkey
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/encoding/base58"
+	"github.com/jvehent/badcrypto/ripemd160"
+)
+
+// HardenedOffset is the index of the first hardened child
+const HardenedOffset uint32 = 0x80000000
+
+// serializedSize is the size of an extended key before Base58Check
+const serializedSize = 78
+
+// Version bytes of serialized keys
+var (
+	MainnetPrivate = [4]byte{0x04, 0x88, 0xad, 0xe4} // xprv
+	MainnetPublic  = [4]byte{0x04, 0x88, 0xb2, 0x1e} // xpub
+	TestnetPrivate = [4]byte{0x04, 0x35, 0x83, 0x94} // tprv
+	TestnetPublic  = [4]byte{0x04, 0x35, 0x87, 0xcf} // tpub
+)
+
+// ErrInvalidChild is returned in the rare case, probability 2^-127, where
+// a child index yields an invalid key. BIP 32 says to move on to the next
+// index.
+var ErrInvalidChild = errors.New("hdkey: child index yields an invalid key, use the next one")
+
+// Key is an extended private or public key
+type Key struct {
+	Version           [4]byte
+	Depth             uint8
+	ParentFingerprint [4]byte
+	ChildNumber       uint32
+	ChainCode         []byte
+	// Key is 0x00 followed by the private key, or the compressed public key
+	Key []byte
+}
+
+func curve() *ec.Curve {
+	return ec.Secp256k1()
+}
+
+// NewMaster derives the master key from a seed of 16 to 64 bytes, such
+// as the one of a BIP 39 mnemonic
+func NewMaster(seed []byte) (*Key, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("hdkey: seed of %d bytes is not between 16 and 64", len(seed))
+	}
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	i := mac.Sum(nil)
+	k := new(big.Int).SetBytes(i[:32])
+	if k.Sign() == 0 || k.Cmp(curve().N) >= 0 {
+		return nil, errors.New("hdkey: seed yields an invalid master key")
+	}
+	return &Key{
+		Version:   MainnetPrivate,
+		ChainCode: i[32:],
+		Key:       append([]byte{0}, i[:32]...),
+	}, nil
+}
+
+// IsPrivate returns true for extended private keys
+func (k *Key) IsPrivate() bool {
+	return k.Version == MainnetPrivate || k.Version == TestnetPrivate
+}
+
+// PrivateKey returns the private scalar of an extended private key
+func (k *Key) PrivateKey() (*big.Int, error) {
+	if !k.IsPrivate() {
+		return nil, errors.New("hdkey: not a private key")
+	}
+	return new(big.Int).SetBytes(k.Key[1:]), nil
+}
+
+// PublicKey returns the public point of the key
+func (k *Key) PublicKey() *ec.Point {
+	c := curve()
+	if k.IsPrivate() {
+		return c.ScalarBaseMult(new(big.Int).SetBytes(k.Key[1:]))
+	}
+	p, err := c.Unmarshal(k.Key)
+	if err != nil {
+		// keys are validated when parsed or derived
+		panic(err)
+	}
+	return p
+}
+
+// publicBytes returns the compressed public key
+func (k *Key) publicBytes() []byte {
+	if k.IsPrivate() {
+		return curve().Marshal(k.PublicKey())
+	}
+	return k.Key
+}
+
+// Identifier returns HASH160 of the public key, RIPEMD-160(SHA-256(K))
+func (k *Key) Identifier() []byte {
+	h := sha256.Sum256(k.publicBytes())
+	id := ripemd160.Sum(h[:])
+	return id[:]
+}
+
+// Fingerprint returns the first four bytes of the identifier
+func (k *Key) Fingerprint() [4]byte {
+	var fp [4]byte
+	copy(fp[:], k.Identifier())
+	return fp
+}
+
+// Public returns the extended public key of k
+func (k *Key) Public() *Key {
+	if !k.IsPrivate() {
+		return k
+	}
+	pub := *k
+	pub.Version = MainnetPublic
+	if k.Version == TestnetPrivate {
+		pub.Version = TestnetPublic
+	}
+	pub.Key = k.publicBytes()
+	return &pub
+}
+
+// Child derives the child key at index. Private keys derive private
+// children, public keys can only derive normal public children.
+func (k *Key) Child(index uint32) (*Key, error) {
+	if k.Depth == 255 {
+		return nil, errors.New("hdkey: maximum depth reached")
+	}
+	c := curve()
+	mac := hmac.New(sha512.New, k.ChainCode)
+	if index >= HardenedOffset {
+		if !k.IsPrivate() {
+			return nil, errors.New("hdkey: cannot derive a hardened child from a public key")
+		}
+		mac.Write(k.Key)
+	} else {
+		mac.Write(k.publicBytes())
+	}
+	var i [4]byte
+	binary.BigEndian.PutUint32(i[:], index)
+	mac.Write(i[:])
+	sum := mac.Sum(nil)
+	il := new(big.Int).SetBytes(sum[:32])
+	if il.Cmp(c.N) >= 0 {
+		return nil, ErrInvalidChild
+	}
+	child := &Key{
+		Version:           k.Version,
+		Depth:             k.Depth + 1,
+		ParentFingerprint: k.Fingerprint(),
+		ChildNumber:       index,
+		ChainCode:         sum[32:],
+	}
+	if k.IsPrivate() {
+		// k_i = parse256(IL) + k_par mod n
+		ki := il.Add(il, new(big.Int).SetBytes(k.Key[1:]))
+		ki.Mod(ki, c.N)
+		if ki.Sign() == 0 {
+			return nil, ErrInvalidChild
+		}
+		child.Key = append([]byte{0}, ki.FillBytes(make([]byte, 32))...)
+		return child, nil
+	}
+	// K_i = point(parse256(IL)) + K_par
+	p := c.Add(c.ScalarBaseMult(il), k.PublicKey())
+	if p.IsInfinity() {
+		return nil, ErrInvalidChild
+	}
+	child.Key = c.Marshal(p)
+	return child, nil
+}
+
+// Derive follows a path such as "m/0'/1/2h" from k, where a trailing ' or
+// h marks a hardened index. The path may be relative, without "m/".
+func (k *Key) Derive(path string) (*Key, error) {
+	parts := strings.Split(path, "/")
+	if parts[0] == "m" {
+		if k.Depth != 0 {
+			return nil, errors.New("hdkey: absolute path from a non master key")
+		}
+		parts = parts[1:]
+	}
+	key := k
+	for _, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("hdkey: empty element in path %q", path)
+		}
+		offset := uint32(0)
+		if strings.HasSuffix(part, "'") || strings.HasSuffix(part, "h") || strings.HasSuffix(part, "H") {
+			offset = HardenedOffset
+			part = part[:len(part)-1]
+		}
+		index, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || uint32(index) >= HardenedOffset {
+			return nil, fmt.Errorf("hdkey: invalid index %q in path %q", part, path)
+		}
+		if key, err = key.Child(uint32(index) + offset); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// String returns the Base58Check serialization of the key, such as
+// xprv... or xpub...
+func (k *Key) String() string {
+	buf := make([]byte, 0, serializedSize)
+	buf = append(buf, k.Version[:]...)
+	buf = append(buf, k.Depth)
+	buf = append(buf, k.ParentFingerprint[:]...)
+	var i [4]byte
+	binary.BigEndian.PutUint32(i[:], k.ChildNumber)
+	buf = append(buf, i[:]...)
+	buf = append(buf, k.ChainCode...)
+	buf = append(buf, k.Key...)
+	return base58.CheckEncode(buf)
+}
+
+// Parse decodes and validates a serialized extended key
+func Parse(s string) (*Key, error) {
+	buf, err := base58.CheckDecode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) != serializedSize {
+		return nil, fmt.Errorf("hdkey: serialized key of %d bytes instead of %d", len(buf), serializedSize)
+	}
+	k := &Key{Depth: buf[4], ChildNumber: binary.BigEndian.Uint32(buf[9:13])}
+	copy(k.Version[:], buf[:4])
+	copy(k.ParentFingerprint[:], buf[5:9])
+	k.ChainCode = buf[13:45]
+	k.Key = buf[45:]
+	switch k.Version {
+	case MainnetPrivate, TestnetPrivate:
+		if k.Key[0] != 0 {
+			return nil, errors.New("hdkey: invalid private key prefix")
+		}
+		d := new(big.Int).SetBytes(k.Key[1:])
+		if d.Sign() == 0 || d.Cmp(curve().N) >= 0 {
+			return nil, errors.New("hdkey: private key out of range")
+		}
+	case MainnetPublic, TestnetPublic:
+		if k.Key[0] != 2 && k.Key[0] != 3 {
+			return nil, errors.New("hdkey: invalid public key prefix")
+		}
+		if _, err := curve().Unmarshal(k.Key); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("hdkey: unknown version %x", k.Version)
+	}
+	if k.Depth == 0 && (k.ParentFingerprint != [4]byte{} || k.ChildNumber != 0) {
+		return nil, errors.New("hdkey: master key with a parent")
+	}
+	return k, nil
+}
@@ -0,0 +1,146 @@
+package hdkey
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// test vectors 1 and 2 of BIP 32
+var vectors = []struct {
+	seed  string
+	chain []struct{ path, xpub, xprv string }
+}{
+	{
+		"000102030405060708090a0b0c0d0e0f",
+		[]struct{ path, xpub, xprv string }{
+			{"m",
+				"xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8",
+				"xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi"},
+			{"m/0H",
+				"xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw",
+				"xprv9uHRZZhk6KAJC1avXpDAp4MDc3sQKNxDiPvvkX8Br5ngLNv1TxvUxt4cV1rGL5hj6KCesnDYUhd7oWgT11eZG7XnxHrnYeSvkzY7d2bhkJ7"},
+			{"m/0H/1",
+				"xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ",
+				"xprv9wTYmMFdV23N2TdNG573QoEsfRrWKQgWeibmLntzniatZvR9BmLnvSxqu53Kw1UmYPxLgboyZQaXwTCg8MSY3H2EU4pWcQDnRnrVA1xe8fs"},
+			{"m/0H/1/2H",
+				"xpub6D4BDPcP2GT577Vvch3R8wDkScZWzQzMMUm3PWbmWvVJrZwQY4VUNgqFJPMM3No2dFDFGTsxxpG5uJh7n7epu4trkrX7x7DogT5Uv6fcLW5",
+				"xprv9z4pot5VBttmtdRTWfWQmoH1taj2axGVzFqSb8C9xaxKymcFzXBDptWmT7FwuEzG3ryjH4ktypQSAewRiNMjANTtpgP4mLTj34bhnZX7UiM"},
+			{"m/0H/1/2H/2",
+				"xpub6FHa3pjLCk84BayeJxFW2SP4XRrFd1JYnxeLeU8EqN3vDfZmbqBqaGJAyiLjTAwm6ZLRQUMv1ZACTj37sR62cfN7fe5JnJ7dh8zL4fiyLHV",
+				"xprvA2JDeKCSNNZky6uBCviVfJSKyQ1mDYahRjijr5idH2WwLsEd4Hsb2Tyh8RfQMuPh7f7RtyzTtdrbdqqsunu5Mm3wDvUAKRHSC34sJ7in334"},
+			{"m/0H/1/2H/2/1000000000",
+				"xpub6H1LXWLaKsWFhvm6RVpEL9P4KfRZSW7abD2ttkWP3SSQvnyA8FSVqNTEcYFgJS2UaFcxupHiYkro49S8yGasTvXEYBVPamhGW6cFJodrTHy",
+				"xprvA41z7zogVVwxVSgdKUHDy1SKmdb533PjDz7J6N6mV6uS3ze1ai8FHa8kmHScGpWmj4WggLyQjgPie1rFSruoUihUZREPSL39UNdE3BBDu76"},
+		},
+	},
+	{
+		"fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		[]struct{ path, xpub, xprv string }{
+			{"m",
+				"xpub661MyMwAqRbcFW31YEwpkMuc5THy2PSt5bDMsktWQcFF8syAmRUapSCGu8ED9W6oDMSgv6Zz8idoc4a6mr8BDzTJY47LJhkJ8UB7WEGuduB",
+				"xprv9s21ZrQH143K31xYSDQpPDxsXRTUcvj2iNHm5NUtrGiGG5e2DtALGdso3pGz6ssrdK4PFmM8NSpSBHNqPqm55Qn3LqFtT2emdEXVYsCzC2U"},
+		},
+	},
+}
+
+func TestVectors(t *testing.T) {
+	t.Parallel()
+	for i, v := range vectors {
+		seed, _ := hex.DecodeString(v.seed)
+		master, err := NewMaster(seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j, step := range v.chain {
+			k, err := master.Derive(step.path)
+			if err != nil {
+				t.Fatalf("vector %d step %d failed with %v", i, j, err)
+			}
+			if k.String() != step.xprv {
+				t.Fatalf("vector %d step %d expected %s but got %s", i, j, step.xprv, k.String())
+			}
+			if k.Public().String() != step.xpub {
+				t.Fatalf("vector %d step %d expected %s but got %s", i, j, step.xpub, k.Public().String())
+			}
+			for _, s := range []string{step.xprv, step.xpub} {
+				parsed, err := Parse(s)
+				if err != nil {
+					t.Fatalf("vector %d step %d failed to parse with %v", i, j, err)
+				}
+				if parsed.String() != s {
+					t.Fatalf("vector %d step %d expected %s after parsing but got %s", i, j, s, parsed.String())
+				}
+			}
+		}
+	}
+}
+
+func TestPublicDerivation(t *testing.T) {
+	t.Parallel()
+	seed, _ := hex.DecodeString(vectors[0].seed)
+	master, err := NewMaster(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	account, err := master.Derive("m/44'/0'/0'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	xpub, err := Parse(account.Public().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a watch only wallet derives the same addresses from the xpub
+	for _, path := range []string{"0/0", "0/1", "1/7", "0/1000000"} {
+		priv, err := account.Derive(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub, err := xpub.Derive(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if priv.Public().String() != pub.String() {
+			t.Fatalf("expected %s from the xpub but got %s", priv.Public(), pub)
+		}
+	}
+	if _, err := xpub.Derive("0'"); err == nil {
+		t.Fatal("expected hardened derivation from an xpub to fail")
+	}
+	if _, err := xpub.PrivateKey(); err == nil {
+		t.Fatal("expected no private key in an xpub")
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	t.Parallel()
+	xprv := vectors[0].chain[0].xprv
+	var keys = []string{
+		// bad checksum
+		xprv[:len(xprv)-1] + "j",
+		// truncated
+		xprv[:50],
+		"",
+	}
+	for i, s := range keys {
+		if _, err := Parse(s); err == nil {
+			t.Fatalf("testcase %d expected %q to be rejected", i, s)
+		}
+	}
+	var paths = []string{"m/", "m//1", "m/x", "m/2147483648", "m/1'/-1", "m/0'h"}
+	seed, _ := hex.DecodeString(vectors[0].seed)
+	master, _ := NewMaster(seed)
+	for i, path := range paths {
+		if _, err := master.Derive(path); err == nil {
+			t.Fatalf("path testcase %d expected %q to be rejected", i, path)
+		}
+	}
+	child, _ := master.Derive("m/1")
+	if _, err := child.Derive("m/1"); err == nil || !strings.Contains(err.Error(), "absolute") {
+		t.Fatalf("expected an absolute path from a child to be rejected, got %v", err)
+	}
+	if _, err := NewMaster(make([]byte, 8)); err == nil {
+		t.Fatal("expected a short seed to be rejected")
+	}
+}
@@ -0,0 +1,378 @@
+// Package hpke implements Hybrid Public Key Encryption (RFC 9180) with
+// the DHKEM(X25519, HKDF-SHA256) KEM, the HKDF-SHA256 KDF, and the
+// AES-128-GCM, AES-256-GCM and ChaCha20-Poly1305 AEADs, in the base, PSK,
+// auth and auth-PSK modes.
+//
+// A sender encapsulates a shared secret to the recipient public key, the
+// key schedule turns it into an AEAD key, a base nonce and an exporter
+// secret, and a Context then seals any number of messages in order.
+package hpke
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/jvehent/badcrypto/chacha20poly1305"
+	"github.com/jvehent/badcrypto/kdf"
+	"github.com/jvehent/badcrypto/x25519"
+)
+
+// KEM identifies a key encapsulation mechanism
+type KEM uint16
+
+// KDF identifies a key derivation function
+type KDF uint16
+
+// AEAD identifies an authenticated encryption algorithm
+type AEAD uint16
+
+// Mode is the HPKE mode, which determines how the sender authenticates
+type Mode uint8
+
+// Algorithm identifiers from RFC 9180 section 7
+const (
+	DHKEM_X25519_HKDF_SHA256 KEM = 0x0020
+
+	HKDF_SHA256 KDF = 0x0001
+
+	AES_128_GCM      AEAD = 0x0001
+	AES_256_GCM      AEAD = 0x0002
+	ChaCha20Poly1305 AEAD = 0x0003
+	// ExportOnly contexts can only export secrets, not encrypt
+	ExportOnly AEAD = 0xffff
+)
+
+// Modes from RFC 9180 section 5
+const (
+	ModeBase    Mode = 0x00
+	ModePSK     Mode = 0x01
+	ModeAuth    Mode = 0x02
+	ModeAuthPSK Mode = 0x03
+)
+
+const versionLabel = "HPKE-v1"
+
+// Suite is a combination of KEM, KDF and AEAD
+type Suite struct {
+	KEM  KEM
+	KDF  KDF
+	AEAD AEAD
+}
+
+// NewSuite returns the suite for the given AEAD, with the only KEM and
+// KDF this package implements
+func NewSuite(aead AEAD) Suite {
+	return Suite{KEM: DHKEM_X25519_HKDF_SHA256, KDF: HKDF_SHA256, AEAD: aead}
+}
+
+func (s Suite) id() []byte {
+	id := []byte("HPKE")
+	id = appendU16(id, uint16(s.KEM))
+	id = appendU16(id, uint16(s.KDF))
+	return appendU16(id, uint16(s.AEAD))
+}
+
+func (s Suite) check() error {
+	if s.KEM != DHKEM_X25519_HKDF_SHA256 {
+		return errors.New("hpke: unsupported KEM")
+	}
+	if s.KDF != HKDF_SHA256 {
+		return errors.New("hpke: unsupported KDF")
+	}
+	switch s.AEAD {
+	case AES_128_GCM, AES_256_GCM, ChaCha20Poly1305, ExportOnly:
+		return nil
+	}
+	return errors.New("hpke: unsupported AEAD")
+}
+
+// keyLen and nonceLen are Nk and Nn of RFC 9180 section 7.3
+func (s Suite) keyLen() int {
+	switch s.AEAD {
+	case AES_128_GCM:
+		return 16
+	case AES_256_GCM, ChaCha20Poly1305:
+		return 32
+	}
+	return 0
+}
+
+func (s Suite) nonceLen() int {
+	if s.AEAD == ExportOnly {
+		return 0
+	}
+	return 12
+}
+
+func (s Suite) newAEAD(key []byte) (cipher.AEAD, error) {
+	switch s.AEAD {
+	case AES_128_GCM, AES_256_GCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case ChaCha20Poly1305:
+		return chacha20poly1305.New(key)
+	}
+	return nil, nil
+}
+
+func appendU16(b []byte, v uint16) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
+
+// labeledExtract and labeledExpand bind every derivation to the protocol
+// version, the suite and a label, as in RFC 9180 section 4
+func labeledExtract(suiteID, salt []byte, label string, ikm []byte) []byte {
+	labeled := append([]byte(versionLabel), suiteID...)
+	labeled = append(labeled, label...)
+	labeled = append(labeled, ikm...)
+	return kdf.HKDFExtract(sha256.New, labeled, salt)
+}
+
+func labeledExpand(suiteID, prk []byte, label string, info []byte, length int) []byte {
+	labeled := appendU16(nil, uint16(length))
+	labeled = append(labeled, versionLabel...)
+	labeled = append(labeled, suiteID...)
+	labeled = append(labeled, label...)
+	labeled = append(labeled, info...)
+	out, err := kdf.HKDFExpand(sha256.New, prk, labeled, length)
+	if err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// Context holds the state of an encryption context, on either side
+type Context struct {
+	suite          Suite
+	aead           cipher.AEAD
+	baseNonce      []byte
+	seq            uint64
+	exporterSecret []byte
+}
+
+// Sender is the context of the party that encapsulated the secret
+type Sender struct{ Context }
+
+// Receiver is the context of the recipient
+type Receiver struct{ Context }
+
+// keySchedule derives the encryption context of RFC 9180 section 5.1
+func (s Suite) keySchedule(mode Mode, sharedSecret, info, psk, pskID []byte) (*Context, error) {
+	if err := verifyPSKInputs(mode, psk, pskID); err != nil {
+		return nil, err
+	}
+	id := s.id()
+	pskIDHash := labeledExtract(id, nil, "psk_id_hash", pskID)
+	infoHash := labeledExtract(id, nil, "info_hash", info)
+	ksContext := append([]byte{byte(mode)}, pskIDHash...)
+	ksContext = append(ksContext, infoHash...)
+
+	secret := labeledExtract(id, sharedSecret, "secret", psk)
+	ctx := &Context{
+		suite:          s,
+		exporterSecret: labeledExpand(id, secret, "exp", ksContext, sha256.Size),
+	}
+	if s.AEAD != ExportOnly {
+		key := labeledExpand(id, secret, "key", ksContext, s.keyLen())
+		ctx.baseNonce = labeledExpand(id, secret, "base_nonce", ksContext, s.nonceLen())
+		aead, err := s.newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		ctx.aead = aead
+	}
+	return ctx, nil
+}
+
+func verifyPSKInputs(mode Mode, psk, pskID []byte) error {
+	gotPSK, gotPSKID := len(psk) > 0, len(pskID) > 0
+	if gotPSK != gotPSKID {
+		return errors.New("hpke: inconsistent PSK inputs")
+	}
+	if gotPSK && (mode == ModeBase || mode == ModeAuth) {
+		return errors.New("hpke: PSK input provided when not needed")
+	}
+	if !gotPSK && (mode == ModePSK || mode == ModeAuthPSK) {
+		return errors.New("hpke: missing required PSK input")
+	}
+	return nil
+}
+
+// nonce computes base_nonce XOR I2OSP(seq, Nn)
+func (c *Context) nonce() []byte {
+	nonce := append([]byte{}, c.baseNonce...)
+	var seq [8]byte
+	binary.BigEndian.PutUint64(seq[:], c.seq)
+	for i := 0; i < 8; i++ {
+		nonce[len(nonce)-8+i] ^= seq[i]
+	}
+	return nonce
+}
+
+func (c *Context) incrementSeq() error {
+	if c.seq == ^uint64(0) {
+		return errors.New("hpke: message limit reached")
+	}
+	c.seq++
+	return nil
+}
+
+// Seal encrypts plaintext and authenticates aad with the next nonce
+func (s *Sender) Seal(aad, plaintext []byte) ([]byte, error) {
+	if s.aead == nil {
+		return nil, errors.New("hpke: export only context cannot encrypt")
+	}
+	ct := s.aead.Seal(nil, s.nonce(), plaintext, aad)
+	if err := s.incrementSeq(); err != nil {
+		return nil, err
+	}
+	return ct, nil
+}
+
+// Open decrypts ciphertext and authenticates aad with the next nonce,
+// messages must therefore be opened in the order they were sealed
+func (r *Receiver) Open(aad, ciphertext []byte) ([]byte, error) {
+	if r.aead == nil {
+		return nil, errors.New("hpke: export only context cannot decrypt")
+	}
+	pt, err := r.aead.Open(nil, r.nonce(), ciphertext, aad)
+	if err != nil {
+		return nil, errors.New("hpke: message authentication failed")
+	}
+	if err := r.incrementSeq(); err != nil {
+		return nil, err
+	}
+	return pt, nil
+}
+
+// Export derives a secret of length bytes bound to exporterContext
+func (c *Context) Export(exporterContext []byte, length int) ([]byte, error) {
+	if length > 255*sha256.Size {
+		return nil, errors.New("hpke: export length too large")
+	}
+	return labeledExpand(c.suite.id(), c.exporterSecret, "sec", exporterContext, length), nil
+}
+
+// SetupBaseS encapsulates a secret to pkR and returns the encapsulated
+// key to send along with the sender context
+func (s Suite) SetupBaseS(rand io.Reader, pkR, info []byte) ([]byte, *Sender, error) {
+	return s.setupS(rand, ModeBase, pkR, info, nil, nil, nil)
+}
+
+// SetupBaseR decapsulates enc with skR and returns the receiver context
+func (s Suite) SetupBaseR(enc, skR, info []byte) (*Receiver, error) {
+	return s.setupR(ModeBase, enc, skR, info, nil, nil, nil)
+}
+
+// SetupPSKS is SetupBaseS with a pre-shared key authenticating the sender
+func (s Suite) SetupPSKS(rand io.Reader, pkR, info, psk, pskID []byte) ([]byte, *Sender, error) {
+	return s.setupS(rand, ModePSK, pkR, info, psk, pskID, nil)
+}
+
+// SetupPSKR is SetupBaseR with a pre-shared key
+func (s Suite) SetupPSKR(enc, skR, info, psk, pskID []byte) (*Receiver, error) {
+	return s.setupR(ModePSK, enc, skR, info, psk, pskID, nil)
+}
+
+// SetupAuthS is SetupBaseS where the sender authenticates with the
+// private key skS
+func (s Suite) SetupAuthS(rand io.Reader, pkR, info, skS []byte) ([]byte, *Sender, error) {
+	return s.setupS(rand, ModeAuth, pkR, info, nil, nil, skS)
+}
+
+// SetupAuthR is SetupBaseR for a sender authenticated by pkS
+func (s Suite) SetupAuthR(enc, skR, info, pkS []byte) (*Receiver, error) {
+	return s.setupR(ModeAuth, enc, skR, info, nil, nil, pkS)
+}
+
+// SetupAuthPSKS combines the PSK and auth modes
+func (s Suite) SetupAuthPSKS(rand io.Reader, pkR, info, psk, pskID, skS []byte) ([]byte, *Sender, error) {
+	return s.setupS(rand, ModeAuthPSK, pkR, info, psk, pskID, skS)
+}
+
+// SetupAuthPSKR combines the PSK and auth modes
+func (s Suite) SetupAuthPSKR(enc, skR, info, psk, pskID, pkS []byte) (*Receiver, error) {
+	return s.setupR(ModeAuthPSK, enc, skR, info, psk, pskID, pkS)
+}
+
+func (s Suite) setupS(rand io.Reader, mode Mode, pkR, info, psk, pskID, skS []byte) ([]byte, *Sender, error) {
+	skE, _, err := x25519.GenerateKey(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.setupSWithEphemeral(skE[:], mode, pkR, info, psk, pskID, skS)
+}
+
+// setupSWithEphemeral is setupS with a given ephemeral key, so the RFC
+// test vectors can be replayed
+func (s Suite) setupSWithEphemeral(skE []byte, mode Mode, pkR, info, psk, pskID, skS []byte) ([]byte, *Sender, error) {
+	if err := s.check(); err != nil {
+		return nil, nil, err
+	}
+	var (
+		sharedSecret, enc []byte
+		err               error
+	)
+	if skS != nil {
+		sharedSecret, enc, err = authEncap(skE, pkR, skS)
+	} else {
+		sharedSecret, enc, err = encap(skE, pkR)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, err := s.keySchedule(mode, sharedSecret, info, psk, pskID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return enc, &Sender{*ctx}, nil
+}
+
+func (s Suite) setupR(mode Mode, enc, skR, info, psk, pskID, pkS []byte) (*Receiver, error) {
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	var (
+		sharedSecret []byte
+		err          error
+	)
+	if pkS != nil {
+		sharedSecret, err = authDecap(enc, skR, pkS)
+	} else {
+		sharedSecret, err = decap(enc, skR)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ctx, err := s.keySchedule(mode, sharedSecret, info, psk, pskID)
+	if err != nil {
+		return nil, err
+	}
+	return &Receiver{*ctx}, nil
+}
+
+// Seal is the single-shot base mode encryption of RFC 9180 section 6.1
+func (s Suite) Seal(rand io.Reader, pkR, info, aad, plaintext []byte) (enc, ciphertext []byte, err error) {
+	enc, sender, err := s.SetupBaseS(rand, pkR, info)
+	if err != nil {
+		return nil, nil, err
+	}
+	ciphertext, err = sender.Seal(aad, plaintext)
+	return enc, ciphertext, err
+}
+
+// Open is the single-shot base mode decryption
+func (s Suite) Open(enc, skR, info, aad, ciphertext []byte) ([]byte, error) {
+	receiver, err := s.SetupBaseR(enc, skR, info)
+	if err != nil {
+		return nil, err
+	}
+	return receiver.Open(aad, ciphertext)
+}
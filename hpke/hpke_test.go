@@ -0,0 +1,384 @@
+package hpke
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+)
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// RFC 9180 appendix A.1.1, DHKEM(X25519, HKDF-SHA256), HKDF-SHA256, AES-128-GCM
+func TestBaseVector(t *testing.T) {
+	t.Parallel()
+	suite := NewSuite(AES_128_GCM)
+	info := unhex("4f6465206f6e2061204772656369616e2055726e")
+	skE, pkE, err := DeriveKeyPair(unhex("7268600d403fce431561aef583ee1613527cff655c1343f29812e66706df3234"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(skE, unhex("52c4a758a802cd8b936eceea314432798d5baf2d7e9235dc084ab1b9cfa2f736")) {
+		t.Fatalf("expected skEm but got %x", skE)
+	}
+	if !bytes.Equal(pkE, unhex("37fda3567bdbd628e88668c3c8d7e97d1d1253b6d4ea6d44c150f741f1bf4431")) {
+		t.Fatalf("expected pkEm but got %x", pkE)
+	}
+	skR, pkR, err := DeriveKeyPair(unhex("6db9df30aa07dd42ee5e8181afdb977e538f5e1fec8a06223f33f7013e525037"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(skR, unhex("4612c550263fc8ad58375df3f557aac531d26850903e55a9f23f21d8534e8ac8")) {
+		t.Fatalf("expected skRm but got %x", skR)
+	}
+	enc, sender, err := suite.setupSWithEphemeral(skE, ModeBase, pkR, info, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(enc, pkE) {
+		t.Fatalf("expected enc to be pkEm but got %x", enc)
+	}
+	if !bytes.Equal(sender.baseNonce, unhex("56d890e5accaaf011cff4b7d")) {
+		t.Fatalf("expected base nonce but got %x", sender.baseNonce)
+	}
+	if !bytes.Equal(sender.exporterSecret, unhex("45ff1c2e220db587171952c0592d5f5ebe103f1561a2614e38f2ffd47e99e3f8")) {
+		t.Fatalf("expected exporter secret but got %x", sender.exporterSecret)
+	}
+	pt := unhex("4265617574792069732074727574682c20747275746820626561757479")
+	ct, err := sender.Seal(unhex("436f756e742d30"), pt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := unhex("f938558b5d72f1a23810b4be2ab4f84331acc02fc97babc53a52ae8218a355a96d8770ac83d07bea87e13c512a")
+	if !bytes.Equal(ct, expected) {
+		t.Fatalf("expected ciphertext %x but got %x", expected, ct)
+	}
+	receiver, err := suite.SetupBaseR(enc, skR, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := receiver.Open(unhex("436f756e742d30"), ct)
+	if err != nil || !bytes.Equal(got, pt) {
+		t.Fatalf("expected plaintext back but got %x, %v", got, err)
+	}
+}
+
+// rfcVectors are the vectors of RFC 9180 appendices A.1 and A.2 for the
+// four modes, checked on the first encryption and the three exports. They
+// all use the same psk, info and first message.
+var rfcVectors = []struct {
+	name                     string
+	mode                     Mode
+	aead                     AEAD
+	ikmE, ikmR, ikmS         string
+	pkEm, pkRm, pkSm         string
+	sharedSecret, ciphertext string
+	exportEmpty, export00    string
+	exportTestContext        string
+}{
+	{
+		name: "A.1.1", mode: ModeBase, aead: AES_128_GCM,
+		ikmE:              "7268600d403fce431561aef583ee1613527cff655c1343f29812e66706df3234",
+		ikmR:              "6db9df30aa07dd42ee5e8181afdb977e538f5e1fec8a06223f33f7013e525037",
+		pkEm:              "37fda3567bdbd628e88668c3c8d7e97d1d1253b6d4ea6d44c150f741f1bf4431",
+		pkRm:              "3948cfe0ad1ddb695d780e59077195da6c56506b027329794ab02bca80815c4d",
+		sharedSecret:      "fe0e18c9f024ce43799ae393c7e8fe8fce9d218875e8227b0187c04e7d2ea1fc",
+		ciphertext:        "f938558b5d72f1a23810b4be2ab4f84331acc02fc97babc53a52ae8218a355a96d8770ac83d07bea87e13c512a",
+		exportEmpty:       "3853fe2b4035195a573ffc53856e77058e15d9ea064de3e59f4961d0095250ee",
+		export00:          "2e8f0b54673c7029649d4eb9d5e33bf1872cf76d623ff164ac185da9e88c21a5",
+		exportTestContext: "e9e43065102c3836401bed8c3c3c75ae46be1639869391d62c61f1ec7af54931",
+	},
+	{
+		name: "A.1.2", mode: ModePSK, aead: AES_128_GCM,
+		ikmE:              "78628c354e46f3e169bd231be7b2ff1c77aa302460a26dbfa15515684c00130b",
+		ikmR:              "d4a09d09f575fef425905d2ab396c1449141463f698f8efdb7accfaff8995098",
+		pkEm:              "0ad0950d9fb9588e59690b74f1237ecdf1d775cd60be2eca57af5a4b0471c91b",
+		pkRm:              "9fed7e8c17387560e92cc6462a68049657246a09bfa8ade7aefe589672016366",
+		sharedSecret:      "727699f009ffe3c076315019c69648366b69171439bd7dd0807743bde76986cd",
+		ciphertext:        "e52c6fed7f758d0cf7145689f21bc1be6ec9ea097fef4e959440012f4feb73fb611b946199e681f4cfc34db8ea",
+		exportEmpty:       "dff17af354c8b41673567db6259fd6029967b4e1aad13023c2ae5df8f4f43bf6",
+		export00:          "6a847261d8207fe596befb52928463881ab493da345b10e1dcc645e3b94e2d95",
+		exportTestContext: "8aff52b45a1be3a734bc7a41e20b4e055ad4c4d22104b0c20285a7c4302401cd",
+	},
+	{
+		name: "A.1.3", mode: ModeAuth, aead: AES_128_GCM,
+		ikmE:              "6e6d8f200ea2fb20c30b003a8b4f433d2f4ed4c2658d5bc8ce2fef718059c9f7",
+		ikmR:              "f1d4a30a4cef8d6d4e3b016e6fd3799ea057db4f345472ed302a67ce1c20cdec",
+		ikmS:              "94b020ce91d73fca4649006c7e7329a67b40c55e9e93cc907d282bbbff386f58",
+		pkEm:              "23fb952571a14a25e3d678140cd0e5eb47a0961bb18afcf85896e5453c312e76",
+		pkRm:              "1632d5c2f71c2b38d0a8fcc359355200caa8b1ffdf28618080466c909cb69b2e",
+		pkSm:              "8b0c70873dc5aecb7f9ee4e62406a397b350e57012be45cf53b7105ae731790b",
+		sharedSecret:      "2d6db4cf719dc7293fcbf3fa64690708e44e2bebc81f84608677958c0d4448a7",
+		ciphertext:        "5fd92cc9d46dbf8943e72a07e42f363ed5f721212cd90bcfd072bfd9f44e06b80fd17824947496e21b680c141b",
+		exportEmpty:       "28c70088017d70c896a8420f04702c5a321d9cbf0279fba899b59e51bac72c85",
+		export00:          "25dfc004b0892be1888c3914977aa9c9bbaf2c7471708a49e1195af48a6f29ce",
+		exportTestContext: "5a0131813abc9a522cad678eb6bafaabc43389934adb8097d23c5ff68059eb64",
+	},
+	{
+		name: "A.1.4", mode: ModeAuthPSK, aead: AES_128_GCM,
+		ikmE:              "4303619085a20ebcf18edd22782952b8a7161e1dbae6e46e143a52a96127cf84",
+		ikmR:              "4b16221f3b269a88e207270b5e1de28cb01f847841b344b8314d6a622fe5ee90",
+		ikmS:              "62f77dcf5df0dd7eac54eac9f654f426d4161ec850cc65c54f8b65d2e0b4e345",
+		pkEm:              "820818d3c23993492cc5623ab437a48a0a7ca3e9639c140fe1e33811eb844b7c",
+		pkRm:              "1d11a3cd247ae48e901939659bd4d79b6b959e1f3e7d66663fbc9412dd4e0976",
+		pkSm:              "2bfb2eb18fcad1af0e4f99142a1c474ae74e21b9425fc5c589382c69b50cc57e",
+		sharedSecret:      "f9d0e870aba28d04709b2680cb8185466c6a6ff1d6e9d1091d5bf5e10ce3a577",
+		ciphertext:        "a84c64df1e11d8fd11450039d4fe64ff0c8a99fca0bd72c2d4c3e0400bc14a40f27e45e141a24001697737533e",
+		exportEmpty:       "08f7e20644bb9b8af54ad66d2067457c5f9fcb2a23d9f6cb4445c0797b330067",
+		export00:          "52e51ff7d436557ced5265ff8b94ce69cf7583f49cdb374e6aad801fc063b010",
+		exportTestContext: "a30c20370c026bbea4dca51cb63761695132d342bae33a6a11527d3e7679436d",
+	},
+	{
+		name: "A.2.1", mode: ModeBase, aead: ChaCha20Poly1305,
+		ikmE:              "909a9b35d3dc4713a5e72a4da274b55d3d3821a37e5d099e74a647db583a904b",
+		ikmR:              "1ac01f181fdf9f352797655161c58b75c656a6cc2716dcb66372da835542e1df",
+		pkEm:              "1afa08d3dec047a643885163f1180476fa7ddb54c6a8029ea33f95796bf2ac4a",
+		pkRm:              "4310ee97d88cc1f088a5576c77ab0cf5c3ac797f3d95139c6c84b5429c59662a",
+		sharedSecret:      "0bbe78490412b4bbea4812666f7916932b828bba79942424abb65244930d69a7",
+		ciphertext:        "1c5250d8034ec2b784ba2cfd69dbdb8af406cfe3ff938e131f0def8c8b60b4db21993c62ce81883d2dd1b51a28",
+		exportEmpty:       "4bbd6243b8bb54cec311fac9df81841b6fd61f56538a775e7c80a9f40160606e",
+		export00:          "8c1df14732580e5501b00f82b10a1647b40713191b7c1240ac80e2b68808ba69",
+		exportTestContext: "5acb09211139c43b3090489a9da433e8a30ee7188ba8b0a9a1ccf0c229283e53",
+	},
+	{
+		name: "A.2.2", mode: ModePSK, aead: ChaCha20Poly1305,
+		ikmE:              "35706a0b09fb26fb45c39c2f5079c709c7cf98e43afa973f14d88ece7e29c2e3",
+		ikmR:              "26b923eade72941c8a85b09986cdfa3f1296852261adedc52d58d2930269812b",
+		pkEm:              "2261299c3f40a9afc133b969a97f05e95be2c514e54f3de26cbe5644ac735b04",
+		pkRm:              "13640af826b722fc04feaa4de2f28fbd5ecc03623b317834e7ff4120dbe73062",
+		sharedSecret:      "4be079c5e77779d0215b3f689595d59e3e9b0455d55662d1f3666ec606e50ea7",
+		ciphertext:        "4a177f9c0d6f15cfdf533fb65bf84aecdc6ab16b8b85b4cf65a370e07fc1d78d28fb073214525276f4a89608ff",
+		exportEmpty:       "813c1bfc516c99076ae0f466671f0ba5ff244a41699f7b2417e4c59d46d39f40",
+		export00:          "2745cf3d5bb65c333658732954ee7af49eb895ce77f8022873a62a13c94cb4e1",
+		exportTestContext: "ad40e3ae14f21c99bfdebc20ae14ab86f4ca2dc9a4799d200f43a25f99fa78ae",
+	},
+	{
+		name: "A.2.3", mode: ModeAuth, aead: ChaCha20Poly1305,
+		ikmE:              "938d3daa5a8904540bc24f48ae90eed3f4f7f11839560597b55e7c9598c996c0",
+		ikmR:              "64835d5ee64aa7aad57c6f2e4f758f7696617f8829e70bc9ac7a5ef95d1c756c",
+		ikmS:              "9d8f94537d5a3ddef71234c0baedfad4ca6861634d0b94c3007fed557ad17df6",
+		pkEm:              "f7674cc8cd7baa5872d1f33dbaffe3314239f6197ddf5ded1746760bfc847e0e",
+		pkRm:              "1a478716d63cb2e16786ee93004486dc151e988b34b475043d3e0175bdb01c44",
+		pkSm:              "f0f4f9e96c54aeed3f323de8534fffd7e0577e4ce269896716bcb95643c8712b",
+		sharedSecret:      "d2d67828c8bc9fa661cf15a31b3ebf1febe0cafef7abfaaca580aaf6d471e3eb",
+		ciphertext:        "ab1a13c9d4f01a87ec3440dbd756e2677bd2ecf9df0ce7ed73869b98e00c09be111cb9fdf077347aeb88e61bdf",
+		exportEmpty:       "070cffafd89b67b7f0eeb800235303a223e6ff9d1e774dce8eac585c8688c872",
+		export00:          "2852e728568d40ddb0edde284d36a4359c56558bb2fb8837cd3d92e46a3a14a8",
+		exportTestContext: "1df39dc5dd60edcbf5f9ae804e15ada66e885b28ed7929116f768369a3f950ee",
+	},
+	{
+		name: "A.2.4", mode: ModeAuthPSK, aead: ChaCha20Poly1305,
+		ikmE:              "49d6eac8c6c558c953a0a252929a818745bb08cd3d29e15f9f5db5eb2e7d4b84",
+		ikmR:              "f3304ddcf15848488271f12b75ecaf72301faabf6ad283654a14c398832eb184",
+		ikmS:              "20ade1d5203de1aadfb261c4700b6432e260d0d317be6ebbb8d7fffb1f86ad9d",
+		pkEm:              "656a2e00dc9990fd189e6e473459392df556e9a2758754a09db3f51179a3fc02",
+		pkRm:              "a5099431c35c491ec62ca91df1525d6349cb8aa170c51f9581f8627be6334851",
+		pkSm:              "3ac5bd4dd66ff9f2740bef0d6ccb66daa77bff7849d7895182b07fb74d087c45",
+		sharedSecret:      "86a6c0ed17714f11d2951747e660857a5fd7616c933ef03207808b7a7123fe67",
+		ciphertext:        "9aa52e29274fc6172e38a4461361d2342585d3aeec67fb3b721ecd63f059577c7fe886be0ede01456ebc67d597",
+		exportEmpty:       "c23ebd4e7a0ad06a5dddf779f65004ce9481069ce0f0e6dd51a04539ddcbd5cd",
+		export00:          "ed7ff5ca40a3d84561067ebc8e01702bc36cf1eb99d42a92004642b9dfaadd37",
+		exportTestContext: "d3bae066aa8da27d527d85c040f7dd6ccb60221c902ee36a82f70bcd62a60ee4",
+	},
+}
+
+func TestRFCVectors(t *testing.T) {
+	t.Parallel()
+	info := unhex("4f6465206f6e2061204772656369616e2055726e")
+	pt := unhex("4265617574792069732074727574682c20747275746820626561757479")
+	aad := unhex("436f756e742d30")
+	for i, tc := range rfcVectors {
+		var psk, pskID []byte
+		if tc.mode == ModePSK || tc.mode == ModeAuthPSK {
+			psk = unhex("0247fd33b913760fa1fa51e1892d9f307fbe65eb171e8132c2af18555a738b82")
+			pskID = unhex("456e6e796e20447572696e206172616e204d6f726961")
+		}
+		skE, pkE, err := DeriveKeyPair(unhex(tc.ikmE))
+		if err != nil {
+			t.Fatal(err)
+		}
+		skR, pkR, err := DeriveKeyPair(unhex(tc.ikmR))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var skS, pkS []byte
+		if tc.ikmS != "" {
+			if skS, pkS, err = DeriveKeyPair(unhex(tc.ikmS)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, k := range []struct{ got, expected []byte }{
+			{pkE, unhex(tc.pkEm)}, {pkR, unhex(tc.pkRm)}, {pkS, unhex(tc.pkSm)},
+		} {
+			if !bytes.Equal(k.got, k.expected) {
+				t.Fatalf("testcase %d (%s) expected public key %x but got %x", i, tc.name, k.expected, k.got)
+			}
+		}
+		var sharedSecret []byte
+		if skS != nil {
+			sharedSecret, _, err = authEncap(skE, pkR, skS)
+		} else {
+			sharedSecret, _, err = encap(skE, pkR)
+		}
+		if err != nil || !bytes.Equal(sharedSecret, unhex(tc.sharedSecret)) {
+			t.Fatalf("testcase %d (%s) expected shared secret %s but got %x, %v", i, tc.name, tc.sharedSecret, sharedSecret, err)
+		}
+		suite := NewSuite(tc.aead)
+		enc, sender, err := suite.setupSWithEphemeral(skE, tc.mode, pkR, info, psk, pskID, skS)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ct, err := sender.Seal(aad, pt)
+		if err != nil || !bytes.Equal(ct, unhex(tc.ciphertext)) {
+			t.Fatalf("testcase %d (%s) expected ciphertext %s but got %x, %v", i, tc.name, tc.ciphertext, ct, err)
+		}
+		receiver, err := suite.setupR(tc.mode, enc, skR, info, psk, pskID, pkS)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := receiver.Open(aad, ct); err != nil || !bytes.Equal(got, pt) {
+			t.Fatalf("testcase %d (%s) expected plaintext back but got %x, %v", i, tc.name, got, err)
+		}
+		for _, e := range []struct{ context, expected string }{
+			{"", tc.exportEmpty},
+			{"00", tc.export00},
+			{"54657374436f6e74657874", tc.exportTestContext},
+		} {
+			s, _ := sender.Export(unhex(e.context), 32)
+			r, _ := receiver.Export(unhex(e.context), 32)
+			if !bytes.Equal(s, unhex(e.expected)) || !bytes.Equal(r, s) {
+				t.Fatalf("testcase %d (%s) expected export %s for context %q but got %x and %x", i, tc.name, e.expected, e.context, s, r)
+			}
+		}
+	}
+}
+
+func TestModes(t *testing.T) {
+	t.Parallel()
+	skR, pkR, err := GenerateKeyPair(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	skS, pkS, err := GenerateKeyPair(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	psk, pskID := []byte("0123456789abcdef0123456789abcdef"), []byte("psk id")
+	info := []byte("modes test")
+	for _, aead := range []AEAD{AES_128_GCM, AES_256_GCM, ChaCha20Poly1305} {
+		suite := NewSuite(aead)
+		var testcases = []struct {
+			setupS func() ([]byte, *Sender, error)
+			setupR func(enc []byte) (*Receiver, error)
+		}{
+			{
+				func() ([]byte, *Sender, error) { return suite.SetupBaseS(rand.Reader, pkR, info) },
+				func(enc []byte) (*Receiver, error) { return suite.SetupBaseR(enc, skR, info) },
+			},
+			{
+				func() ([]byte, *Sender, error) { return suite.SetupPSKS(rand.Reader, pkR, info, psk, pskID) },
+				func(enc []byte) (*Receiver, error) { return suite.SetupPSKR(enc, skR, info, psk, pskID) },
+			},
+			{
+				func() ([]byte, *Sender, error) { return suite.SetupAuthS(rand.Reader, pkR, info, skS) },
+				func(enc []byte) (*Receiver, error) { return suite.SetupAuthR(enc, skR, info, pkS) },
+			},
+			{
+				func() ([]byte, *Sender, error) {
+					return suite.SetupAuthPSKS(rand.Reader, pkR, info, psk, pskID, skS)
+				},
+				func(enc []byte) (*Receiver, error) { return suite.SetupAuthPSKR(enc, skR, info, psk, pskID, pkS) },
+			},
+		}
+		for i, tc := range testcases {
+			enc, sender, err := tc.setupS()
+			if err != nil {
+				t.Fatalf("aead %d testcase %d: %v", aead, i, err)
+			}
+			receiver, err := tc.setupR(enc)
+			if err != nil {
+				t.Fatalf("aead %d testcase %d: %v", aead, i, err)
+			}
+			for j := 0; j < 3; j++ {
+				msg := []byte{byte(j), 'm', 's', 'g'}
+				ct, err := sender.Seal([]byte("aad"), msg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				pt, err := receiver.Open([]byte("aad"), ct)
+				if err != nil || !bytes.Equal(pt, msg) {
+					t.Fatalf("aead %d testcase %d expected message %d back but got %x, %v", aead, i, j, pt, err)
+				}
+			}
+			e1, _ := sender.Export([]byte("ctx"), 42)
+			e2, _ := receiver.Export([]byte("ctx"), 42)
+			if len(e1) != 42 || !bytes.Equal(e1, e2) {
+				t.Fatalf("aead %d testcase %d expected matching exports", aead, i)
+			}
+		}
+	}
+}
+
+func TestWrongInputs(t *testing.T) {
+	t.Parallel()
+	suite := NewSuite(ChaCha20Poly1305)
+	skR, pkR, _ := GenerateKeyPair(rand.Reader)
+	skS, _, _ := GenerateKeyPair(rand.Reader)
+	_, pkOther, _ := GenerateKeyPair(rand.Reader)
+	enc, ct, err := suite.Seal(rand.Reader, pkR, []byte("info"), []byte("aad"), []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := suite.Open(enc, skR, []byte("other info"), []byte("aad"), ct); err == nil {
+		t.Fatal("expected open with a different info to fail")
+	}
+	if _, err := suite.Open(enc, skR, []byte("info"), []byte("other aad"), ct); err == nil {
+		t.Fatal("expected open with a different aad to fail")
+	}
+	enc, sender, err := suite.SetupAuthS(rand.Reader, pkR, nil, skS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct, _ = sender.Seal(nil, []byte("hello"))
+	receiver, err := suite.SetupAuthR(enc, skR, nil, pkOther)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := receiver.Open(nil, ct); err == nil {
+		t.Fatal("expected open from the wrong sender to fail")
+	}
+	if _, _, err := suite.SetupPSKS(rand.Reader, pkR, nil, []byte("psk"), nil); err == nil {
+		t.Fatal("expected a psk without an id to be rejected")
+	}
+	if _, _, err := (Suite{KEM: 0x0010, KDF: HKDF_SHA256, AEAD: AES_128_GCM}).SetupBaseS(rand.Reader, pkR, nil); err == nil {
+		t.Fatal("expected an unsupported KEM to be rejected")
+	}
+}
+
+func TestExportOnly(t *testing.T) {
+	t.Parallel()
+	suite := NewSuite(ExportOnly)
+	skR, pkR, _ := GenerateKeyPair(rand.Reader)
+	enc, sender, err := suite.SetupBaseS(rand.Reader, pkR, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sender.Seal(nil, []byte("hello")); err == nil {
+		t.Fatal("expected an export only context to refuse to seal")
+	}
+	receiver, err := suite.SetupBaseR(enc, skR, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e1, _ := sender.Export(nil, 32)
+	e2, _ := receiver.Export(nil, 32)
+	if !bytes.Equal(e1, e2) {
+		t.Fatal("expected matching exports")
+	}
+}
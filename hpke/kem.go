@@ -0,0 +1,97 @@
+package hpke
+
+import (
+	"errors"
+	"io"
+
+	"github.com/jvehent/badcrypto/x25519"
+)
+
+// kemSuiteID identifies DHKEM(X25519, HKDF-SHA256) in its derivations
+var kemSuiteID = appendU16([]byte("KEM"), uint16(DHKEM_X25519_HKDF_SHA256))
+
+// GenerateKeyPair returns a random X25519 key pair for the KEM
+func GenerateKeyPair(rand io.Reader) (sk, pk []byte, err error) {
+	priv, pub, err := x25519.GenerateKey(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	return priv[:], pub[:], nil
+}
+
+// DeriveKeyPair deterministically derives a key pair from ikm, as in RFC
+// 9180 section 7.1.3
+func DeriveKeyPair(ikm []byte) (sk, pk []byte, err error) {
+	if len(ikm) < 32 {
+		return nil, nil, errors.New("hpke: ikm is too short")
+	}
+	prk := labeledExtract(kemSuiteID, nil, "dkp_prk", ikm)
+	sk = labeledExpand(kemSuiteID, prk, "sk", nil, x25519.Size)
+	var priv [x25519.Size]byte
+	copy(priv[:], sk)
+	pub := x25519.ScalarBaseMult(priv)
+	return sk, pub[:], nil
+}
+
+func publicKey(sk []byte) []byte {
+	var priv [x25519.Size]byte
+	copy(priv[:], sk)
+	pub := x25519.ScalarBaseMult(priv)
+	return pub[:]
+}
+
+// extractAndExpand turns DH outputs into the KEM shared secret
+func extractAndExpand(dh, kemContext []byte) []byte {
+	prk := labeledExtract(kemSuiteID, nil, "eae_prk", dh)
+	return labeledExpand(kemSuiteID, prk, "shared_secret", kemContext, 32)
+}
+
+func encap(skE, pkR []byte) (sharedSecret, enc []byte, err error) {
+	dh, err := x25519.SharedSecret(skE, pkR)
+	if err != nil {
+		return nil, nil, err
+	}
+	enc = publicKey(skE)
+	kemContext := append(append([]byte{}, enc...), pkR...)
+	return extractAndExpand(dh, kemContext), enc, nil
+}
+
+func decap(enc, skR []byte) ([]byte, error) {
+	dh, err := x25519.SharedSecret(skR, enc)
+	if err != nil {
+		return nil, err
+	}
+	kemContext := append(append([]byte{}, enc...), publicKey(skR)...)
+	return extractAndExpand(dh, kemContext), nil
+}
+
+// authEncap also mixes in a DH between the sender static key and the
+// recipient, which authenticates the sender
+func authEncap(skE, pkR, skS []byte) (sharedSecret, enc []byte, err error) {
+	dh1, err := x25519.SharedSecret(skE, pkR)
+	if err != nil {
+		return nil, nil, err
+	}
+	dh2, err := x25519.SharedSecret(skS, pkR)
+	if err != nil {
+		return nil, nil, err
+	}
+	enc = publicKey(skE)
+	kemContext := append(append([]byte{}, enc...), pkR...)
+	kemContext = append(kemContext, publicKey(skS)...)
+	return extractAndExpand(append(dh1, dh2...), kemContext), enc, nil
+}
+
+func authDecap(enc, skR, pkS []byte) ([]byte, error) {
+	dh1, err := x25519.SharedSecret(skR, enc)
+	if err != nil {
+		return nil, err
+	}
+	dh2, err := x25519.SharedSecret(skR, pkS)
+	if err != nil {
+		return nil, err
+	}
+	kemContext := append(append([]byte{}, enc...), publicKey(skR)...)
+	kemContext = append(kemContext, pkS...)
+	return extractAndExpand(append(dh1, dh2...), kemContext), nil
+}
@@ -0,0 +1,284 @@
+package kdf
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+	"math/bits"
+	"sync"
+
+	"github.com/jvehent/badcrypto/blake2b"
+)
+
+// Argon2 variants, the y parameter of RFC 9106
+const (
+	argon2d  = 0
+	argon2i  = 1
+	argon2id = 2
+)
+
+const (
+	argon2Version = 0x13
+	// argon2SyncPoints is the number of slices a pass is cut into, lanes
+	// only synchronize at their boundaries
+	argon2SyncPoints = 4
+	// blockWords is the size of a memory block in 64 bits words, 1 KiB
+	blockWords = 128
+)
+
+type block [blockWords]uint64
+
+// Argon2id derives a key of keyLen bytes from password and salt following
+// RFC 9106. time is the number of passes over the memory, memory its size
+// in KiB and threads the number of lanes filled in parallel. RFC 9106
+// recommends time=1, memory=2097152 (2 GiB) and threads=4, or time=3 and
+// memory=65536 (64 MiB) when memory is constrained.
+func Argon2id(password, salt []byte, time, memory, threads, keyLen int) ([]byte, error) {
+	return argon2(argon2id, password, salt, nil, nil, time, memory, threads, keyLen)
+}
+
+// argon2 computes any of the three variants, with the optional secret K
+// and associated data X of RFC 9106
+func argon2(mode int, password, salt, secret, data []byte, time, memory, threads, keyLen int) ([]byte, error) {
+	if time < 1 || threads < 1 || threads > 1<<24-1 || keyLen < 4 {
+		return nil, errors.New("kdf: argon2 needs at least one pass, one lane and a 4 bytes key")
+	}
+	if memory < 8*threads || uint64(memory) > 1<<32-1 {
+		return nil, errors.New("kdf: argon2 memory must be at least 8 KiB per lane and below 4 TiB")
+	}
+	if len(salt) < 8 {
+		return nil, errors.New("kdf: argon2 salt must be at least 8 bytes")
+	}
+
+	// H0 hashes all the parameters and inputs
+	h := blake2b.New512()
+	for _, v := range []int{threads, keyLen, memory, time, argon2Version, mode} {
+		writeUint32(h, uint32(v))
+	}
+	for _, in := range [][]byte{password, salt, secret, data} {
+		writeUint32(h, uint32(len(in)))
+		h.Write(in)
+	}
+	h0 := h.Sum(make([]byte, 0, blake2b.Size+8))
+
+	// memory is rounded down to a multiple of 4 blocks per lane
+	lanes := threads
+	laneLen := memory / (argon2SyncPoints * lanes) * argon2SyncPoints
+	b := make([]block, lanes*laneLen)
+
+	// the first two blocks of each lane come from H0
+	var buf [1024]byte
+	for l := 0; l < lanes; l++ {
+		for i := 0; i < 2; i++ {
+			in := append(h0[:blake2b.Size], 0, 0, 0, 0, 0, 0, 0, 0)
+			binary.LittleEndian.PutUint32(in[64:], uint32(i))
+			binary.LittleEndian.PutUint32(in[68:], uint32(l))
+			hashPrime(buf[:], in)
+			for k := range b[l*laneLen+i] {
+				b[l*laneLen+i][k] = binary.LittleEndian.Uint64(buf[8*k:])
+			}
+		}
+	}
+
+	segLen := laneLen / argon2SyncPoints
+	for pass := 0; pass < time; pass++ {
+		for slice := 0; slice < argon2SyncPoints; slice++ {
+			var wg sync.WaitGroup
+			for l := 0; l < lanes; l++ {
+				wg.Add(1)
+				go func(l int) {
+					defer wg.Done()
+					fillSegment(b, mode, pass, slice, l, lanes, laneLen, segLen, time)
+				}(l)
+			}
+			wg.Wait()
+		}
+	}
+
+	// the tag is derived from the last blocks of all lanes
+	var c block
+	for l := 0; l < lanes; l++ {
+		last := &b[l*laneLen+laneLen-1]
+		for k := range c {
+			c[k] ^= last[k]
+		}
+	}
+	for k, w := range c {
+		binary.LittleEndian.PutUint64(buf[8*k:], w)
+	}
+	out := make([]byte, keyLen)
+	hashPrime(out, buf[:])
+	return out, nil
+}
+
+// fillSegment computes the blocks of a slice of lane l
+func fillSegment(b []block, mode, pass, slice, l, lanes, laneLen, segLen, time int) {
+	// the first half of the first pass of Argon2id, and all of Argon2i,
+	// pick reference blocks independently of the password
+	independent := mode == argon2i || (mode == argon2id && pass == 0 && slice < argon2SyncPoints/2)
+	var in, addresses, zero block
+	if independent {
+		in[0] = uint64(pass)
+		in[1] = uint64(l)
+		in[2] = uint64(slice)
+		in[3] = uint64(len(b))
+		in[4] = uint64(time)
+		in[5] = uint64(mode)
+	}
+	nextAddresses := func() {
+		in[6]++
+		compress(&addresses, &zero, &in, false)
+		compress(&addresses, &zero, &addresses, false)
+	}
+
+	start := 0
+	if pass == 0 && slice == 0 {
+		// the first two blocks are already there
+		start = 2
+	}
+	if independent && start != 0 {
+		nextAddresses()
+	}
+	for j := start; j < segLen; j++ {
+		col := slice*segLen + j
+		cur := l*laneLen + col
+		prev := cur - 1
+		if col == 0 {
+			prev = l*laneLen + laneLen - 1
+		}
+
+		var rand uint64
+		if independent {
+			if j%blockWords == 0 {
+				nextAddresses()
+			}
+			rand = addresses[j%blockWords]
+		} else {
+			rand = b[prev][0]
+		}
+
+		// the reference lane, always the current one in the first slice
+		refLane := int(rand>>32) % lanes
+		if pass == 0 && slice == 0 {
+			refLane = l
+		}
+		// the reference area is every finished block, except the ones
+		// of the current slice of other lanes and the previous block
+		var area int
+		switch {
+		case pass == 0 && refLane == l:
+			area = col - 1
+		case pass == 0:
+			area = slice * segLen
+			if j == 0 {
+				area--
+			}
+		case refLane == l:
+			area = laneLen - segLen + j - 1
+		default:
+			area = laneLen - segLen
+			if j == 0 {
+				area--
+			}
+		}
+		// map J1 to the area with a quadratic bias toward recent blocks
+		x := (rand & 0xffffffff) * (rand & 0xffffffff) >> 32
+		y := uint64(area) * x >> 32
+		rel := uint64(area) - 1 - y
+		startPos := 0
+		if pass != 0 && slice != argon2SyncPoints-1 {
+			startPos = (slice + 1) * segLen
+		}
+		ref := refLane*laneLen + int((uint64(startPos)+rel)%uint64(laneLen))
+
+		// later passes XOR the new block into the previous one
+		compress(&b[cur], &b[prev], &b[ref], pass > 0)
+	}
+}
+
+// compress sets out to G(x, y), the compression function of RFC 9106
+// section 3.5, or XORs G(x, y) into out when xor is set
+func compress(out, x, y *block, xor bool) {
+	var r, q block
+	for i := range r {
+		r[i] = x[i] ^ y[i]
+	}
+	q = r
+	// P on the rows, then on the columns of 16 bytes registers
+	for i := 0; i < 8; i++ {
+		blamka(&q, 16*i, 16*i+1, 16*i+2, 16*i+3, 16*i+4, 16*i+5, 16*i+6, 16*i+7,
+			16*i+8, 16*i+9, 16*i+10, 16*i+11, 16*i+12, 16*i+13, 16*i+14, 16*i+15)
+	}
+	for i := 0; i < 8; i++ {
+		blamka(&q, 2*i, 2*i+1, 2*i+16, 2*i+17, 2*i+32, 2*i+33, 2*i+48, 2*i+49,
+			2*i+64, 2*i+65, 2*i+80, 2*i+81, 2*i+96, 2*i+97, 2*i+112, 2*i+113)
+	}
+	for i := range out {
+		if xor {
+			out[i] ^= q[i] ^ r[i]
+		} else {
+			out[i] = q[i] ^ r[i]
+		}
+	}
+}
+
+// blamka is the permutation P, a BLAKE2b round where additions also add
+// twice the product of the low 32 bits of their operands
+func blamka(q *block, i0, i1, i2, i3, i4, i5, i6, i7, i8, i9, i10, i11, i12, i13, i14, i15 int) {
+	gb(q, i0, i4, i8, i12)
+	gb(q, i1, i5, i9, i13)
+	gb(q, i2, i6, i10, i14)
+	gb(q, i3, i7, i11, i15)
+	gb(q, i0, i5, i10, i15)
+	gb(q, i1, i6, i11, i12)
+	gb(q, i2, i7, i8, i13)
+	gb(q, i3, i4, i9, i14)
+}
+
+func gb(q *block, a, b, c, d int) {
+	const lo = 0xffffffff
+	q[a] += q[b] + 2*(q[a]&lo)*(q[b]&lo)
+	q[d] = bits.RotateLeft64(q[d]^q[a], -32)
+	q[c] += q[d] + 2*(q[c]&lo)*(q[d]&lo)
+	q[b] = bits.RotateLeft64(q[b]^q[c], -24)
+	q[a] += q[b] + 2*(q[a]&lo)*(q[b]&lo)
+	q[d] = bits.RotateLeft64(q[d]^q[a], -16)
+	q[c] += q[d] + 2*(q[c]&lo)*(q[d]&lo)
+	q[b] = bits.RotateLeft64(q[b]^q[c], -63)
+}
+
+// hashPrime fills out with H' of RFC 9106 section 3.3, the variable
+// length hash built from chained BLAKE2b-512
+func hashPrime(out, in []byte) {
+	if len(out) <= blake2b.Size {
+		h, _ := blake2b.New(len(out), nil)
+		writeUint32(h, uint32(len(out)))
+		h.Write(in)
+		h.Sum(out[:0])
+		return
+	}
+	h := blake2b.New512()
+	writeUint32(h, uint32(len(out)))
+	h.Write(in)
+	v := h.Sum(nil)
+	// each 64 bytes hash gives 32 bytes of output and feeds the next
+	for len(out) > blake2b.Size {
+		copy(out, v[:32])
+		out = out[32:]
+		h.Reset()
+		h.Write(v)
+		if len(out) <= blake2b.Size {
+			break
+		}
+		v = h.Sum(v[:0])
+	}
+	last, _ := blake2b.New(len(out), nil)
+	last.Write(v)
+	last.Sum(out[:0])
+}
+
+func writeUint32(h hash.Hash, v uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	h.Write(b[:])
+}
@@ -0,0 +1,64 @@
+package kdf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestArgon2(t *testing.T) {
+	t.Parallel()
+	// vectors from RFC 9106 section 5, with a secret and associated data
+	password := bytes.Repeat([]byte{0x01}, 32)
+	salt := bytes.Repeat([]byte{0x02}, 16)
+	secret := bytes.Repeat([]byte{0x03}, 8)
+	data := bytes.Repeat([]byte{0x04}, 12)
+	var testcases = []struct {
+		mode int
+		tag  string
+	}{
+		{argon2d, "512b391b6f1162975371d30919734294f868e3be3984f3c1a13a4db9fabe4acb"},
+		{argon2i, "c814d9d1dc7f37aa13f0d77f2494bda1c8de6b016dd388d29952a4c4672b6ce8"},
+		{argon2id, "0d640df58d78766c08c037a34a8b53c9d01ef0452d75b65eb52520e96b01e659"},
+	}
+	for i, testcase := range testcases {
+		tag, err := argon2(testcase.mode, password, salt, secret, data, 3, 32, 4, 32)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(tag) != testcase.tag {
+			t.Fatalf("testcase %d expected %s but got %x", i, testcase.tag, tag)
+		}
+	}
+}
+
+func TestArgon2id(t *testing.T) {
+	t.Parallel()
+	// from the test suite of the reference implementation
+	key, err := Argon2id([]byte("password"), []byte("somesalt"), 2, 1<<16, 1, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "09316115d5cf24ed5a15a31a3ba326e5cf32edc24702987c02b6566f61913cf7"; hex.EncodeToString(key) != expected {
+		t.Fatalf("expected %s but got %x", expected, key)
+	}
+}
+
+func TestArgon2BadParameters(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		salt                          string
+		time, memory, threads, keyLen int
+	}{
+		{"somesalt", 0, 64, 1, 32},
+		{"somesalt", 1, 64, 0, 32},
+		{"somesalt", 1, 31, 4, 32}, // less than 8 KiB per lane
+		{"somesalt", 1, 64, 1, 3},
+		{"short", 1, 64, 1, 32},
+	}
+	for i, testcase := range testcases {
+		if _, err := Argon2id([]byte("password"), []byte(testcase.salt), testcase.time, testcase.memory, testcase.threads, testcase.keyLen); err == nil {
+			t.Fatalf("testcase %d expected an error", i)
+		}
+	}
+}
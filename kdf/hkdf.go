@@ -0,0 +1,49 @@
+package kdf
+
+import (
+	"crypto/hmac"
+	"errors"
+	"hash"
+)
+
+// HKDFExtract computes the pseudorandom key PRK = HMAC-Hash(salt, IKM) as
+// specified in RFC 5869 section 2.2. An empty salt is replaced by a
+// string of zeroes as long as the hash output.
+func HKDFExtract(h func() hash.Hash, secret, salt []byte) []byte {
+	if len(salt) == 0 {
+		salt = make([]byte, h().Size())
+	}
+	mac := hmac.New(h, salt)
+	mac.Write(secret)
+	return mac.Sum(nil)
+}
+
+// HKDFExpand expands the pseudorandom key prk into length bytes of
+// output keying material bound to info, as specified in RFC 5869
+// section 2.3.
+//
+// T(0) is empty and T(i) = HMAC-Hash(PRK, T(i-1) || info || i), the
+// output is the concatenation of T(1) ... T(N) truncated to length.
+func HKDFExpand(h func() hash.Hash, prk, info []byte, length int) ([]byte, error) {
+	mac := hmac.New(h, prk)
+	if length > 255*mac.Size() {
+		return nil, errors.New("kdf: hkdf output length too large")
+	}
+	okm := make([]byte, 0, length+mac.Size())
+	var t []byte
+	for counter := byte(1); len(okm) < length; counter++ {
+		mac.Reset()
+		mac.Write(t)
+		mac.Write(info)
+		mac.Write([]byte{counter})
+		t = mac.Sum(t[:0])
+		okm = append(okm, t...)
+	}
+	return okm[:length], nil
+}
+
+// HKDF runs the extract then expand steps of RFC 5869 and returns
+// length bytes of output keying material
+func HKDF(h func() hash.Hash, secret, salt, info []byte, length int) ([]byte, error) {
+	return HKDFExpand(h, HKDFExtract(h, secret, salt), info, length)
+}
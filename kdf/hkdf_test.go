@@ -0,0 +1,51 @@
+package kdf
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"testing"
+)
+
+func TestHKDF(t *testing.T) {
+	t.Parallel()
+	// vectors from RFC 5869 appendix A
+	var testcases = []struct {
+		h               func() hash.Hash
+		ikm, salt, info string
+		length          int
+		prk, okm        string
+	}{
+		{sha256.New, "0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b", "000102030405060708090a0b0c", "f0f1f2f3f4f5f6f7f8f9", 42,
+			"077709362c2e32df0ddc3f0dc47bba6390b6c73bb50f9c3122ec844ad7c2b3e5",
+			"3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865"},
+		{sha256.New, "0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b", "", "", 42,
+			"19ef24a32c717b167f33a91d6f648bdf96596776afdb6377ac434c1c293ccb04",
+			"8da4e775a563c18f715f802a063c5a31b8a11f5c5ee1879ec3454e5f3c738d2d9d201395faa4b61a96c8"},
+		{sha1.New, "0b0b0b0b0b0b0b0b0b0b0b", "000102030405060708090a0b0c", "f0f1f2f3f4f5f6f7f8f9", 42,
+			"9b6c18c432a7bf8f0e71c8eb88f4b30baa2ba243",
+			"085a01ea1b10f36933068b56efa5ad81a4f14b822f5b091568a9cdd4f155fda2c22e422478d305f3f896"},
+	}
+	for i, testcase := range testcases {
+		ikm, _ := hex.DecodeString(testcase.ikm)
+		salt, _ := hex.DecodeString(testcase.salt)
+		info, _ := hex.DecodeString(testcase.info)
+		prk := HKDFExtract(testcase.h, ikm, salt)
+		if hex.EncodeToString(prk) != testcase.prk {
+			t.Fatalf("testcase %d expected prk %s but got %x", i, testcase.prk, prk)
+		}
+		okm, err := HKDF(testcase.h, ikm, salt, info, testcase.length)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, _ := hex.DecodeString(testcase.okm)
+		if !bytes.Equal(okm, expected) {
+			t.Fatalf("testcase %d expected %x but got %x", i, expected, okm)
+		}
+	}
+	if _, err := HKDFExpand(sha256.New, make([]byte, 32), nil, 255*32+1); err == nil {
+		t.Fatal("expected an error for an output longer than 255 blocks")
+	}
+}
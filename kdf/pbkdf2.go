@@ -0,0 +1,49 @@
+// Package kdf implements the key derivation functions used across
+// badcrypto to turn passwords and shared secrets into keys.
+package kdf
+
+import (
+	"crypto/hmac"
+	"hash"
+)
+
+// PBKDF2 derives a key of keyLen bytes from password and salt using
+// iter iterations of HMAC based on the hash function h, as specified
+// in RFC 8018 section 5.2.
+//
+// Each block of output T_i is the XOR of U_1 ... U_iter where
+// U_1 = PRF(password, salt || INT(i)) and U_j = PRF(password, U_{j-1}).
+func PBKDF2(password, salt []byte, iter, keyLen int, h func() hash.Hash) []byte {
+	prf := hmac.New(h, password)
+	hashLen := prf.Size()
+	numBlocks := (keyLen + hashLen - 1) / hashLen
+
+	var buf [4]byte
+	dk := make([]byte, 0, numBlocks*hashLen)
+	u := make([]byte, hashLen)
+	for block := 1; block <= numBlocks; block++ {
+		// U_1 = PRF(password, salt || INT(block))
+		prf.Reset()
+		prf.Write(salt)
+		buf[0] = byte(block >> 24)
+		buf[1] = byte(block >> 16)
+		buf[2] = byte(block >> 8)
+		buf[3] = byte(block)
+		prf.Write(buf[:4])
+		dk = prf.Sum(dk)
+		t := dk[len(dk)-hashLen:]
+		copy(u, t)
+
+		// U_n = PRF(password, U_{n-1})
+		for n := 2; n <= iter; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = u[:0]
+			u = prf.Sum(u)
+			for x := range u {
+				t[x] ^= u[x]
+			}
+		}
+	}
+	return dk[:keyLen]
+}
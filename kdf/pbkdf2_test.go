@@ -0,0 +1,35 @@
+package kdf
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"testing"
+)
+
+func TestPBKDF2(t *testing.T) {
+	t.Parallel()
+	// vectors from RFC 6070 for SHA1, and computed with python's hashlib for SHA256
+	var testcases = []struct {
+		h              func() hash.Hash
+		password, salt string
+		iter, keyLen   int
+		dk             string
+	}{
+		{sha1.New, "password", "salt", 1, 20, "0c60c80f961f0e71f3a9b524af6012062fe037a6"},
+		{sha1.New, "password", "salt", 2, 20, "ea6c014dc72d6f8ccd1ed92ace1d41f0d8de8957"},
+		{sha1.New, "password", "salt", 4096, 20, "4b007901b765489abead49d926f721d065a429c1"},
+		{sha1.New, "passwordPASSWORDpassword", "saltSALTsaltSALTsaltSALTsaltSALTsalt", 4096, 25, "3d2eec4fe41c849b80c8d83662c0e44a8b291a964cf2f07038"},
+		{sha256.New, "password", "salt", 4096, 32, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+		{sha256.New, "passwordPASSWORDpassword", "saltSALTsaltSALTsaltSALTsaltSALTsalt", 4096, 40, "348c89dbcbd32b2f32d814b8116e84cf2b17347ebc1800181c4e2a1fb8dd53e1c635518c7dac47e9"},
+	}
+	for i, testcase := range testcases {
+		dk := PBKDF2([]byte(testcase.password), []byte(testcase.salt), testcase.iter, testcase.keyLen, testcase.h)
+		expected, _ := hex.DecodeString(testcase.dk)
+		if !bytes.Equal(dk, expected) {
+			t.Fatalf("testcase %d expected %x but got %x", i, expected, dk)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package kdf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestScrypt(t *testing.T) {
+	t.Parallel()
+	// vectors from RFC 7914 section 12
+	var testcases = []struct {
+		password, salt string
+		N, r, p        int
+		dk             string
+	}{
+		{"", "", 16, 1, 1, "77d6576238657b203b19ca42c18a0497f16b4844e3074ae8dfdffa3fede21442fcd0069ded0948f8326a753a0fc81f17e8d3e0fb2e0d3628cf35e20c38d18906"},
+		{"password", "NaCl", 1024, 8, 16, "fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b3731622eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640"},
+		{"pleaseletmein", "SodiumChloride", 16384, 8, 1, "7023bdcb3afd7348461c06cd81fd38ebfda8fbba904f8e3ea9b543f6545da1f2d5432955613f0fcf62d49705242a9af9e61e85dc0d651e40dfcf017b45575887"},
+	}
+	for i, testcase := range testcases {
+		dk, err := Scrypt([]byte(testcase.password), []byte(testcase.salt), testcase.N, testcase.r, testcase.p, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, _ := hex.DecodeString(testcase.dk)
+		if !bytes.Equal(dk, expected) {
+			t.Fatalf("testcase %d expected %x but got %x", i, expected, dk)
+		}
+	}
+}
+
+func TestScryptBadParameters(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		N, r, p int
+	}{
+		{0, 8, 1},
+		{1, 8, 1},
+		{1000, 8, 1}, // not a power of two
+		{1024, 0, 1},
+		{1024, 8, 0},
+		{1024, 1 << 30, 1},
+	}
+	for i, testcase := range testcases {
+		if _, err := Scrypt([]byte("p"), []byte("s"), testcase.N, testcase.r, testcase.p, 32); err == nil {
+			t.Fatalf("testcase %d expected an error for N=%d r=%d p=%d", i, testcase.N, testcase.r, testcase.p)
+		}
+	}
+}
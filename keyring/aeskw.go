@@ -0,0 +1,70 @@
+package keyring
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+// kwIV is the default initial value of RFC 3394 section 2.2.3.1, checked
+// on unwrapping as an integrity check
+var kwIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+// ErrUnwrap is returned when a wrapped key fails its integrity check, and
+// was encrypted under another key or modified
+var ErrUnwrap = errors.New("keyring: failed to unwrap key")
+
+// WrapKey wraps key, a multiple of 8 bytes of at least 16, under the AES
+// block kek with the key wrap algorithm of RFC 3394 section 2.2.1. The
+// result is 8 bytes longer than key.
+func WrapKey(kek cipher.Block, key []byte) ([]byte, error) {
+	if len(key) < 16 || len(key)%8 != 0 {
+		return nil, errors.New("keyring: keys to wrap must be a multiple of 8 bytes of at least 16")
+	}
+	n := len(key) / 8
+	out := make([]byte, 8+len(key))
+	copy(out, kwIV)
+	copy(out[8:], key)
+	var b [16]byte
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			// B = AES(K, A | R[i]), A = MSB(64, B) ^ t, R[i] = LSB(64, B)
+			copy(b[:8], out[:8])
+			copy(b[8:], out[8*i:8*i+8])
+			kek.Encrypt(b[:], b[:])
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(out[:8], binary.BigEndian.Uint64(b[:8])^t)
+			copy(out[8*i:8*i+8], b[8:])
+		}
+	}
+	return out, nil
+}
+
+// UnwrapKey reverses WrapKey and checks the integrity of the result
+func UnwrapKey(kek cipher.Block, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 24 || len(wrapped)%8 != 0 {
+		return nil, ErrUnwrap
+	}
+	n := len(wrapped)/8 - 1
+	a := make([]byte, 8)
+	copy(a, wrapped[:8])
+	key := make([]byte, len(wrapped)-8)
+	copy(key, wrapped[8:])
+	var b [16]byte
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			// B = AES-1(K, (A ^ t) | R[i]), A = MSB(64, B), R[i] = LSB(64, B)
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(b[:8], binary.BigEndian.Uint64(a)^t)
+			copy(b[8:], key[8*(i-1):8*i])
+			kek.Decrypt(b[:], b[:])
+			copy(a, b[:8])
+			copy(key[8*(i-1):8*i], b[8:])
+		}
+	}
+	if subtle.ConstantTimeCompare(a, kwIV) != 1 {
+		return nil, ErrUnwrap
+	}
+	return key, nil
+}
@@ -0,0 +1,74 @@
+package keyring
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+// test vectors of RFC 3394 section 4
+var kwTestcases = []struct {
+	kek, key, wrapped string
+}{
+	{
+		"000102030405060708090A0B0C0D0E0F",
+		"00112233445566778899AABBCCDDEEFF",
+		"1FA68B0A8112B447AEF34BD8FB5A7B829D3E862371D2CFE5",
+	},
+	{
+		"000102030405060708090A0B0C0D0E0F1011121314151617",
+		"00112233445566778899AABBCCDDEEFF",
+		"96778B25AE6CA435F92B5B97C050AED2468AB8A17AD84E5D",
+	},
+	{
+		"000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F",
+		"00112233445566778899AABBCCDDEEFF",
+		"64E8C3F9CE0F5BA263E9777905818A2A93C8191E7D6E8AE7",
+	},
+	{
+		"000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F",
+		"00112233445566778899AABBCCDDEEFF0001020304050607",
+		"A8F9BC1612C68B3FF6E6F4FBE30E71E4769C8B80A32CB8958CD5D17D6B254DA1",
+	},
+	{
+		"000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F",
+		"00112233445566778899AABBCCDDEEFF000102030405060708090A0B0C0D0E0F",
+		"28C9F404C4B810F4CBCCB35CFB87F8263F5786E2D80ED326CBC7F0E71A99F43BFB988B9B7A02DD21",
+	},
+}
+
+func TestKeyWrap(t *testing.T) {
+	t.Parallel()
+	for i, tc := range kwTestcases {
+		kek, _ := hex.DecodeString(tc.kek)
+		key, _ := hex.DecodeString(tc.key)
+		expected, _ := hex.DecodeString(tc.wrapped)
+		block, err := aes.NewCipher(kek)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wrapped, err := WrapKey(block, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(wrapped, expected) {
+			t.Fatalf("testcase %d expected %X but got %X", i, expected, wrapped)
+		}
+		unwrapped, err := UnwrapKey(block, wrapped)
+		if err != nil || !bytes.Equal(unwrapped, key) {
+			t.Fatalf("testcase %d expected to unwrap %X but got %X and %v", i, key, unwrapped, err)
+		}
+		wrapped[len(wrapped)-1] ^= 1
+		if _, err := UnwrapKey(block, wrapped); err != ErrUnwrap {
+			t.Fatalf("testcase %d expected a modified key to fail but got %v", i, err)
+		}
+	}
+	block, _ := aes.NewCipher(make([]byte, 16))
+	if _, err := WrapKey(block, make([]byte, 12)); err == nil {
+		t.Fatal("expected a 12 bytes key to be rejected")
+	}
+	if _, err := UnwrapKey(block, make([]byte, 16)); err != ErrUnwrap {
+		t.Fatalf("expected a 16 bytes wrapped key to be rejected but got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package keyring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+)
+
+// Wrapping algorithms, as written in headers
+const (
+	AlgAESKW   = "AES-KW"
+	AlgRSAOAEP = "RSA-OAEP-256"
+)
+
+// KEK is a key encryption key, or master key, which wraps data keys
+type KEK interface {
+	// ID names the key in the headers of the data it protects
+	ID() string
+	// Algorithm is AlgAESKW or AlgRSAOAEP
+	Algorithm() string
+	Wrap(dek []byte) ([]byte, error)
+	Unwrap(wrapped []byte) ([]byte, error)
+}
+
+// AESKWKey is a KEK wrapping with the AES key wrap of RFC 3394
+type AESKWKey struct {
+	id    string
+	block cipher.Block
+}
+
+// NewAESKWKey returns a KEK for a 16, 24 or 32 bytes AES key
+func NewAESKWKey(id string, key []byte) (*AESKWKey, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("keyring: %v", err)
+	}
+	return &AESKWKey{id: id, block: block}, nil
+}
+
+// GenerateAESKWKey returns a KEK for a random 256 bits AES key
+func GenerateAESKWKey(id string) (*AESKWKey, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return NewAESKWKey(id, key)
+}
+
+// ID returns the name of the key
+func (k *AESKWKey) ID() string { return k.id }
+
+// Algorithm returns AlgAESKW
+func (k *AESKWKey) Algorithm() string { return AlgAESKW }
+
+// Wrap wraps a data key
+func (k *AESKWKey) Wrap(dek []byte) ([]byte, error) { return WrapKey(k.block, dek) }
+
+// Unwrap unwraps a data key
+func (k *AESKWKey) Unwrap(wrapped []byte) ([]byte, error) { return UnwrapKey(k.block, wrapped) }
+
+// oaepLabel binds wrapped keys to this package
+var oaepLabel = []byte("badcrypto keyring")
+
+// RSAOAEPKey is a KEK wrapping with RSA-OAEP and SHA-256. Without the
+// private key it can only wrap, which lets a host encrypt data it cannot
+// read back.
+type RSAOAEPKey struct {
+	id   string
+	pub  *rsa.PublicKey
+	priv *rsa.PrivateKey
+}
+
+// NewRSAOAEPKey returns a KEK that wraps and unwraps with priv
+func NewRSAOAEPKey(id string, priv *rsa.PrivateKey) *RSAOAEPKey {
+	return &RSAOAEPKey{id: id, pub: &priv.PublicKey, priv: priv}
+}
+
+// NewRSAOAEPPublicKey returns a KEK that can only wrap
+func NewRSAOAEPPublicKey(id string, pub *rsa.PublicKey) *RSAOAEPKey {
+	return &RSAOAEPKey{id: id, pub: pub}
+}
+
+// ID returns the name of the key
+func (k *RSAOAEPKey) ID() string { return k.id }
+
+// Algorithm returns AlgRSAOAEP
+func (k *RSAOAEPKey) Algorithm() string { return AlgRSAOAEP }
+
+// Wrap encrypts a data key to the public key
+func (k *RSAOAEPKey) Wrap(dek []byte) ([]byte, error) {
+	return rsa.EncryptOAEP(sha256.New(), rand.Reader, k.pub, dek, oaepLabel)
+}
+
+// Unwrap decrypts a data key with the private key
+func (k *RSAOAEPKey) Unwrap(wrapped []byte) ([]byte, error) {
+	if k.priv == nil {
+		return nil, fmt.Errorf("keyring: %s is a public key and cannot unwrap", k.id)
+	}
+	dek, err := rsa.DecryptOAEP(sha256.New(), nil, k.priv, wrapped, oaepLabel)
+	if err != nil {
+		return nil, ErrUnwrap
+	}
+	return dek, nil
+}
@@ -0,0 +1,66 @@
+package keyring
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+)
+
+func TestKEKs(t *testing.T) {
+	t.Parallel()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aesKey, err := GenerateAESKWKey("aes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var testcases = []struct {
+		kek KEK
+		alg string
+		// size of a wrapped 32 bytes key
+		size int
+	}{
+		{aesKey, AlgAESKW, 40},
+		{NewRSAOAEPKey("rsa", rsaKey), AlgRSAOAEP, 256},
+	}
+	dek := make([]byte, DataKeySize)
+	rand.Read(dek)
+	for i, tc := range testcases {
+		if tc.kek.Algorithm() != tc.alg {
+			t.Fatalf("testcase %d expected algorithm %s but got %s", i, tc.alg, tc.kek.Algorithm())
+		}
+		wrapped, err := tc.kek.Wrap(dek)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(wrapped) != tc.size {
+			t.Fatalf("testcase %d expected %d bytes but got %d", i, tc.size, len(wrapped))
+		}
+		unwrapped, err := tc.kek.Unwrap(wrapped)
+		if err != nil || !bytes.Equal(unwrapped, dek) {
+			t.Fatalf("testcase %d expected to unwrap the data key but got %x and %v", i, unwrapped, err)
+		}
+		wrapped[3] ^= 1
+		if _, err := tc.kek.Unwrap(wrapped); err != ErrUnwrap {
+			t.Fatalf("testcase %d expected a modified key to fail but got %v", i, err)
+		}
+	}
+
+	pub := NewRSAOAEPPublicKey("rsa", &rsaKey.PublicKey)
+	wrapped, err := pub.Wrap(dek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pub.Unwrap(wrapped); err == nil {
+		t.Fatal("expected a public key to be unable to unwrap")
+	}
+	if unwrapped, err := NewRSAOAEPKey("rsa", rsaKey).Unwrap(wrapped); err != nil || !bytes.Equal(unwrapped, dek) {
+		t.Fatalf("expected the private key to unwrap but got %v", err)
+	}
+	if _, err := NewAESKWKey("short", make([]byte, 10)); err == nil {
+		t.Fatal("expected a 10 bytes AES key to be rejected")
+	}
+}
@@ -0,0 +1,252 @@
+// Package keyring implements envelope encryption: every message is
+// encrypted under a fresh random data encryption key (DEK), and the DEK is
+// wrapped under a long lived key encryption key (KEK), or master key, with
+// the AES key wrap of RFC 3394 or with RSA-OAEP. The wrapped DEK travels in
+// a header in front of the ciphertext.
+//
+// Master keys live in a Keyring. The primary key wraps new data keys, and
+// the others only unwrap existing ones. Rotating the master key adds a new
+// primary, then Rewrap moves each message to it by unwrapping and
+// rewrapping its DEK, without touching the encrypted body. Once no message
+// refers to the old key, it can be removed.
+//
+// The header is not authenticated by the body encryption, otherwise a
+// rewrap would have to encrypt the body again. Nothing is lost: a
+// modified wrapped key fails to unwrap, or unwraps to another DEK which
+// fails to decrypt the body.
+package keyring
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/jvehent/badcrypto/chacha20poly1305"
+)
+
+// DataKeySize is the size of data keys, for ChaCha20-Poly1305
+const DataKeySize = chacha20poly1305.KeySize
+
+// magic starts every header, and changes with the format
+var magic = []byte("BKR1")
+
+// Header carries a wrapped data key and names the KEK that wrapped it. It
+// is encoded as
+//
+//	"BKR1" || u8 len || KeyID || u8 len || Algorithm || u16 len || WrappedKey
+type Header struct {
+	KeyID      string
+	Algorithm  string
+	WrappedKey []byte
+}
+
+// MarshalBinary encodes the header
+func (h *Header) MarshalBinary() ([]byte, error) {
+	if len(h.KeyID) > 255 || len(h.Algorithm) > 255 || len(h.WrappedKey) > 65535 {
+		return nil, errors.New("keyring: header field too long")
+	}
+	out := append([]byte{}, magic...)
+	out = append(out, byte(len(h.KeyID)))
+	out = append(out, h.KeyID...)
+	out = append(out, byte(len(h.Algorithm)))
+	out = append(out, h.Algorithm...)
+	out = append(out, byte(len(h.WrappedKey)>>8), byte(len(h.WrappedKey)))
+	return append(out, h.WrappedKey...), nil
+}
+
+var errHeader = errors.New("keyring: invalid header")
+
+// ParseHeader decodes the header at the start of data and returns it with
+// the bytes that follow it
+func ParseHeader(data []byte) (*Header, []byte, error) {
+	if len(data) < len(magic) || string(data[:len(magic)]) != string(magic) {
+		return nil, nil, errHeader
+	}
+	data = data[len(magic):]
+	field := func(size int) ([]byte, error) {
+		if len(data) < size {
+			return nil, errHeader
+		}
+		n := int(data[0])
+		if size == 2 {
+			n = int(binary.BigEndian.Uint16(data))
+		}
+		if len(data) < size+n {
+			return nil, errHeader
+		}
+		f := data[size : size+n]
+		data = data[size+n:]
+		return f, nil
+	}
+	id, err := field(1)
+	if err != nil {
+		return nil, nil, err
+	}
+	alg, err := field(1)
+	if err != nil {
+		return nil, nil, err
+	}
+	wrapped, err := field(2)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &Header{KeyID: string(id), Algorithm: string(alg), WrappedKey: append([]byte{}, wrapped...)}, data, nil
+}
+
+// Keyring holds master keys by ID
+type Keyring struct {
+	keys    map[string]KEK
+	primary KEK
+}
+
+// New returns a keyring whose primary key is primary
+func New(primary KEK) *Keyring {
+	return &Keyring{keys: map[string]KEK{primary.ID(): primary}, primary: primary}
+}
+
+// Primary returns the key that wraps new data keys
+func (kr *Keyring) Primary() KEK {
+	return kr.primary
+}
+
+// Add adds a key that unwraps data keys
+func (kr *Keyring) Add(k KEK) error {
+	if _, ok := kr.keys[k.ID()]; ok {
+		return fmt.Errorf("keyring: duplicate key %q", k.ID())
+	}
+	kr.keys[k.ID()] = k
+	return nil
+}
+
+// Rotate adds k and makes it the primary key. The previous primary key
+// stays in the keyring to unwrap existing data keys until it is removed.
+func (kr *Keyring) Rotate(k KEK) error {
+	if err := kr.Add(k); err != nil {
+		return err
+	}
+	kr.primary = k
+	return nil
+}
+
+// Remove removes a key that is not the primary one. Data keys it wrapped
+// cannot be unwrapped anymore.
+func (kr *Keyring) Remove(id string) error {
+	if id == kr.primary.ID() {
+		return errors.New("keyring: cannot remove the primary key")
+	}
+	if _, ok := kr.keys[id]; !ok {
+		return fmt.Errorf("keyring: unknown key %q", id)
+	}
+	delete(kr.keys, id)
+	return nil
+}
+
+// GenerateDataKey returns a random data key and its header, wrapped under
+// the primary key. The caller encrypts with the data key, stores the
+// header next to the ciphertext and forgets the data key.
+func (kr *Keyring) GenerateDataKey() ([]byte, *Header, error) {
+	dek := make([]byte, DataKeySize)
+	if _, err := rand.Read(dek); err != nil {
+		return nil, nil, err
+	}
+	h, err := kr.wrap(dek)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dek, h, nil
+}
+
+func (kr *Keyring) wrap(dek []byte) (*Header, error) {
+	wrapped, err := kr.primary.Wrap(dek)
+	if err != nil {
+		return nil, err
+	}
+	return &Header{KeyID: kr.primary.ID(), Algorithm: kr.primary.Algorithm(), WrappedKey: wrapped}, nil
+}
+
+// UnwrapDataKey returns the data key of a header
+func (kr *Keyring) UnwrapDataKey(h *Header) ([]byte, error) {
+	k, ok := kr.keys[h.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("keyring: unknown key %q", h.KeyID)
+	}
+	if h.Algorithm != k.Algorithm() {
+		return nil, fmt.Errorf("keyring: key %q is for %s, not %s", h.KeyID, k.Algorithm(), h.Algorithm)
+	}
+	return k.Unwrap(h.WrappedKey)
+}
+
+// Encrypt encrypts plaintext under a new data key. The result is the
+// header followed by a random nonce and the ChaCha20-Poly1305 ciphertext,
+// which authenticates additionalData.
+func (kr *Keyring) Encrypt(plaintext, additionalData []byte) ([]byte, error) {
+	dek, h, err := kr.GenerateDataKey()
+	if err != nil {
+		return nil, err
+	}
+	out, err := h.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.New(dek)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plaintext, additionalData), nil
+}
+
+// Decrypt decrypts the output of Encrypt with any key of the keyring
+func (kr *Keyring) Decrypt(ciphertext, additionalData []byte) ([]byte, error) {
+	h, body, err := ParseHeader(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	dek, err := kr.UnwrapDataKey(h)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.New(dek)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) < aead.NonceSize() {
+		return nil, errors.New("keyring: ciphertext too short")
+	}
+	plaintext, err := aead.Open(nil, body[:aead.NonceSize()], body[aead.NonceSize():], additionalData)
+	if err != nil {
+		return nil, errors.New("keyring: failed to decrypt and authenticate")
+	}
+	return plaintext, nil
+}
+
+// Rewrap returns ciphertext with its data key wrapped under the primary
+// key, keeping the encrypted body as is. Data already wrapped under the
+// primary key is returned unchanged.
+func (kr *Keyring) Rewrap(ciphertext []byte) ([]byte, error) {
+	h, body, err := ParseHeader(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if h.KeyID == kr.primary.ID() {
+		return append([]byte{}, ciphertext...), nil
+	}
+	dek, err := kr.UnwrapDataKey(h)
+	if err != nil {
+		return nil, err
+	}
+	nh, err := kr.wrap(dek)
+	if err != nil {
+		return nil, err
+	}
+	out, err := nh.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return append(out, body...), nil
+}
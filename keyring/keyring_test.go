@@ -0,0 +1,119 @@
+package keyring
+
+import (
+	"bytes"
+	"testing"
+)
+
+func newKey(t *testing.T, id string) KEK {
+	k, err := GenerateAESKWKey(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	t.Parallel()
+	kr := New(newKey(t, "master"))
+	plaintext := []byte("attack at dawn")
+	ct, err := kr.Encrypt(plaintext, []byte("ad"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, _, err := ParseHeader(ct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.KeyID != "master" || h.Algorithm != AlgAESKW || len(h.WrappedKey) != DataKeySize+8 {
+		t.Fatalf("unexpected header %+v", h)
+	}
+	pt, err := kr.Decrypt(ct, []byte("ad"))
+	if err != nil || !bytes.Equal(pt, plaintext) {
+		t.Fatalf("expected %q but got %q and %v", plaintext, pt, err)
+	}
+
+	// the same plaintext gets another data key every time
+	ct2, _ := kr.Encrypt(plaintext, []byte("ad"))
+	h2, _, _ := ParseHeader(ct2)
+	if bytes.Equal(h.WrappedKey, h2.WrappedKey) {
+		t.Fatal("expected a fresh data key per message")
+	}
+	// a wrapped key from another message unwraps but fails the body
+	swapped := append(mustMarshal(t, h2), ct[len(mustMarshal(t, h)):]...)
+	other := New(newKey(t, "master"))
+	var testcases = []struct {
+		name       string
+		kr         *Keyring
+		ciphertext []byte
+		ad         []byte
+	}{
+		{"other additional data", kr, ct, []byte("da")},
+		{"swapped data key", kr, swapped, []byte("ad")},
+		{"modified body", kr, append(ct[:len(ct)-1:len(ct)-1], ct[len(ct)-1]^1), []byte("ad")},
+		{"truncated header", kr, ct[:10], []byte("ad")},
+		{"no magic", kr, ct[4:], []byte("ad")},
+		{"other master key with the same ID", other, ct, []byte("ad")},
+	}
+	for _, tc := range testcases {
+		if _, err := tc.kr.Decrypt(tc.ciphertext, tc.ad); err == nil {
+			t.Fatalf("%s expected to fail", tc.name)
+		}
+	}
+}
+
+func mustMarshal(t *testing.T, h *Header) []byte {
+	b, err := h.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestRotation(t *testing.T) {
+	t.Parallel()
+	kr := New(newKey(t, "2023"))
+	old, err := kr.Encrypt([]byte("old secret"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := kr.Rotate(newKey(t, "2024")); err != nil {
+		t.Fatal(err)
+	}
+	if err := kr.Rotate(newKey(t, "2024")); err == nil {
+		t.Fatal("expected a duplicate key to be rejected")
+	}
+	if kr.Primary().ID() != "2024" {
+		t.Fatalf("expected the new key to be primary but got %s", kr.Primary().ID())
+	}
+	// the old key still unwraps until the data is rewrapped
+	if pt, err := kr.Decrypt(old, nil); err != nil || string(pt) != "old secret" {
+		t.Fatalf("expected the old data to decrypt but got %q and %v", pt, err)
+	}
+	rewrapped, err := kr.Rewrap(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oh, oldBody, _ := ParseHeader(old)
+	nh, newBody, _ := ParseHeader(rewrapped)
+	if oh.KeyID != "2023" || nh.KeyID != "2024" || !bytes.Equal(oldBody, newBody) {
+		t.Fatalf("expected only the header to change from %s to 2024 but got %s", oh.KeyID, nh.KeyID)
+	}
+	again, err := kr.Rewrap(rewrapped)
+	if err != nil || !bytes.Equal(again, rewrapped) {
+		t.Fatalf("expected rewrapping under the primary key to change nothing but got %v", err)
+	}
+
+	if err := kr.Remove("2024"); err == nil {
+		t.Fatal("expected the primary key not to be removable")
+	}
+	if err := kr.Remove("2023"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kr.Decrypt(old, nil); err == nil {
+		t.Fatal("expected data under a removed key to fail")
+	}
+	if pt, err := kr.Decrypt(rewrapped, nil); err != nil || string(pt) != "old secret" {
+		t.Fatalf("expected the rewrapped data to decrypt but got %q and %v", pt, err)
+	}
+}
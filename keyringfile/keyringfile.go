@@ -0,0 +1,373 @@
+// Package keyringfile stores named private keys in a file encrypted under
+// a passphrase. Keys have versions: rotating a key adds a new version
+// generated like the current one, and keeps the older versions to decrypt
+// or verify what they were used for.
+//
+// A file is a header followed by the encrypted keys:
+//
+//	"BKRF" || u8 version || u32 time || u32 memory || u8 threads ||
+//	salt (16 bytes) || nonce (24 bytes) || ciphertext
+//
+// The file key is derived from the passphrase and the salt with Argon2id,
+// using the time, memory and threads parameters of the header, and the
+// keys are encrypted with XChaCha20-Poly1305 with the header as associated
+// data. Integers are big-endian. Once decrypted, the payload is
+//
+//	u32 count || count * (u8 len || name || u32 version ||
+//	                      i64 creation time || u32 len || key)
+//
+// where the creation time is in seconds since the Unix epoch and the key
+// is encoded with keyenc.MarshalProto. Every save draws a new salt and
+// nonce.
+package keyringfile
+
+import (
+	"crypto"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/jvehent/badcrypto/chacha20poly1305"
+	"github.com/jvehent/badcrypto/encoding/keyenc"
+	"github.com/jvehent/badcrypto/kdf"
+)
+
+// Version is the version of the file format written by Marshal
+const Version = 1
+
+const saltSize = 16
+
+// magic starts every keyring file
+var magic = []byte("BKRF")
+
+// headerSize is the size of the header, up to the ciphertext
+var headerSize = len(magic) + 1 + 4 + 4 + 1 + saltSize + chacha20poly1305.NonceSizeX
+
+var (
+	// ErrPassphrase is returned when a file does not decrypt, because the
+	// passphrase is wrong or the file was modified
+	ErrPassphrase = errors.New("keyringfile: wrong passphrase or corrupted file")
+	// ErrNotFound is returned for a name or version that has no key
+	ErrNotFound = errors.New("keyringfile: key not found")
+	// ErrExists is returned when adding a key under a name already taken
+	ErrExists = errors.New("keyringfile: key already exists")
+)
+
+// Params are the Argon2id parameters deriving the file key from the
+// passphrase. Memory is in KiB.
+type Params struct {
+	Time, Memory, Threads int
+}
+
+// DefaultParams are the parameters RFC 9106 recommends when memory is
+// constrained, 64 MiB and three passes
+var DefaultParams = Params{Time: 3, Memory: 64 * 1024, Threads: 4}
+
+// maxParams bounds the parameters of the files Unmarshal accepts, so that
+// opening a crafted file cannot exhaust the memory: 2 GiB is the first
+// recommendation of RFC 9106
+var maxParams = Params{Time: 16, Memory: 2 * 1024 * 1024, Threads: 255}
+
+func (p Params) check() error {
+	if p.Time < 1 || p.Time > maxParams.Time || p.Threads < 1 || p.Threads > maxParams.Threads ||
+		p.Memory < 8*p.Threads || p.Memory > maxParams.Memory {
+		return fmt.Errorf("keyringfile: unsupported argon2id parameters %+v", p)
+	}
+	return nil
+}
+
+// Entry is a version of a named key
+type Entry struct {
+	Name    string
+	Version int
+	Created time.Time
+	// Key is a private key of one of the types of keyenc
+	Key crypto.PrivateKey
+}
+
+// Type returns the type of the key of e
+func (e *Entry) Type() KeyType {
+	t, _ := typeOf(e.Key)
+	return t
+}
+
+// Keyring holds the keys of a keyring file, in memory
+type Keyring struct {
+	// Params are used by Marshal, and set by Unmarshal to those of the file
+	Params Params
+	// entries are sorted by name, then version
+	entries []*Entry
+}
+
+// New returns an empty keyring using DefaultParams
+func New() *Keyring {
+	return &Keyring{Params: DefaultParams}
+}
+
+// nameRE keeps names printable and short
+var nameRE = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]{0,63}$`)
+
+// Add stores key as version 1 of a new name
+func (kr *Keyring) Add(name string, key crypto.PrivateKey) (*Entry, error) {
+	if !nameRE.MatchString(name) {
+		return nil, fmt.Errorf("keyringfile: invalid name %q", name)
+	}
+	if _, err := typeOf(key); err != nil {
+		return nil, err
+	}
+	if _, err := kr.Export(name, 0); err == nil {
+		return nil, ErrExists
+	}
+	return kr.insert(&Entry{Name: name, Version: 1, Created: now(), Key: key}), nil
+}
+
+// Generate creates a key of type t and stores it as version 1 of a new
+// name
+func (kr *Keyring) Generate(name string, t KeyType) (*Entry, error) {
+	key, err := generate(t)
+	if err != nil {
+		return nil, err
+	}
+	return kr.Add(name, key)
+}
+
+// Rotate generates a new version of the key called name, of the same type
+// as its latest version
+func (kr *Keyring) Rotate(name string) (*Entry, error) {
+	latest, err := kr.Export(name, 0)
+	if err != nil {
+		return nil, err
+	}
+	key, err := generate(latest.Type())
+	if err != nil {
+		return nil, err
+	}
+	return kr.insert(&Entry{Name: name, Version: latest.Version + 1, Created: now(), Key: key}), nil
+}
+
+// List returns the latest version of every key, sorted by name
+func (kr *Keyring) List() []*Entry {
+	var latest []*Entry
+	for i, e := range kr.entries {
+		if i == len(kr.entries)-1 || kr.entries[i+1].Name != e.Name {
+			latest = append(latest, e)
+		}
+	}
+	return latest
+}
+
+// Export returns a version of the key called name, or its latest version
+// when version is 0
+func (kr *Keyring) Export(name string, version int) (*Entry, error) {
+	var found *Entry
+	for _, e := range kr.entries {
+		if e.Name == name && (version == 0 || e.Version == version) {
+			found = e
+		}
+	}
+	if found == nil {
+		return nil, ErrNotFound
+	}
+	return found, nil
+}
+
+func (kr *Keyring) insert(e *Entry) *Entry {
+	kr.entries = append(kr.entries, e)
+	sort.SliceStable(kr.entries, func(i, j int) bool {
+		a, b := kr.entries[i], kr.entries[j]
+		return a.Name < b.Name || (a.Name == b.Name && a.Version < b.Version)
+	})
+	return e
+}
+
+// now returns the creation time of new keys, to the second as stored
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+// fileKey derives the encryption key of a file
+func fileKey(passphrase, salt []byte, p Params) ([]byte, error) {
+	return kdf.Argon2id(passphrase, salt, p.Time, p.Memory, p.Threads, chacha20poly1305.KeySize)
+}
+
+// Marshal encrypts the keyring under passphrase
+func (kr *Keyring) Marshal(passphrase []byte) ([]byte, error) {
+	if err := kr.Params.check(); err != nil {
+		return nil, err
+	}
+	header := make([]byte, 0, headerSize)
+	header = append(header, magic...)
+	header = append(header, Version)
+	header = appendUint32(header, uint32(kr.Params.Time))
+	header = appendUint32(header, uint32(kr.Params.Memory))
+	header = append(header, byte(kr.Params.Threads))
+	salt := header[len(header) : len(header)+saltSize+chacha20poly1305.NonceSizeX]
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	header = header[:headerSize]
+	nonce := header[headerSize-chacha20poly1305.NonceSizeX:]
+
+	payload := appendUint32(nil, uint32(len(kr.entries)))
+	for _, e := range kr.entries {
+		key, err := keyenc.MarshalProto(e.Key)
+		if err != nil {
+			return nil, err
+		}
+		payload = append(payload, byte(len(e.Name)))
+		payload = append(payload, e.Name...)
+		payload = appendUint32(payload, uint32(e.Version))
+		payload = appendUint32(payload, uint32(uint64(e.Created.Unix())>>32))
+		payload = appendUint32(payload, uint32(e.Created.Unix()))
+		payload = appendUint32(payload, uint32(len(key)))
+		payload = append(payload, key...)
+	}
+
+	key, err := fileKey(passphrase, salt[:saltSize], kr.Params)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(header, nonce, payload, header), nil
+}
+
+// Unmarshal decrypts a keyring file with passphrase
+func Unmarshal(data, passphrase []byte) (*Keyring, error) {
+	if len(data) < headerSize+chacha20poly1305.Overhead || string(data[:len(magic)]) != string(magic) {
+		return nil, errors.New("keyringfile: not a keyring file")
+	}
+	if v := data[len(magic)]; v != Version {
+		return nil, fmt.Errorf("keyringfile: unsupported version %d", v)
+	}
+	header, ciphertext := data[:headerSize], data[headerSize:]
+	p := header[len(magic)+1:]
+	kr := &Keyring{Params: Params{
+		Time:    int(binary.BigEndian.Uint32(p)),
+		Memory:  int(binary.BigEndian.Uint32(p[4:])),
+		Threads: int(p[8]),
+	}}
+	if err := kr.Params.check(); err != nil {
+		return nil, err
+	}
+	salt := p[9 : 9+saltSize]
+	nonce := p[9+saltSize:]
+
+	key, err := fileKey(passphrase, salt, kr.Params)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := aead.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		return nil, ErrPassphrase
+	}
+	if err := kr.parsePayload(payload); err != nil {
+		return nil, err
+	}
+	return kr, nil
+}
+
+var errPayload = errors.New("keyringfile: invalid payload")
+
+func (kr *Keyring) parsePayload(payload []byte) error {
+	next := func(n int) ([]byte, error) {
+		if len(payload) < n {
+			return nil, errPayload
+		}
+		b := payload[:n]
+		payload = payload[n:]
+		return b, nil
+	}
+	b, err := next(4)
+	if err != nil {
+		return err
+	}
+	for count := binary.BigEndian.Uint32(b); count > 0; count-- {
+		b, err := next(1)
+		if err != nil {
+			return err
+		}
+		name, err := next(int(b[0]))
+		if err != nil {
+			return err
+		}
+		b, err = next(16)
+		if err != nil {
+			return err
+		}
+		version := int(binary.BigEndian.Uint32(b))
+		created := time.Unix(int64(binary.BigEndian.Uint64(b[4:])), 0).UTC()
+		key, err := next(int(binary.BigEndian.Uint32(b[12:])))
+		if err != nil {
+			return err
+		}
+		k, err := keyenc.UnmarshalProto(key)
+		if err != nil {
+			return err
+		}
+		if _, err := typeOf(k); err != nil {
+			return err
+		}
+		if !nameRE.MatchString(string(name)) || version < 1 {
+			return errPayload
+		}
+		if _, err := kr.Export(string(name), version); err == nil {
+			return fmt.Errorf("keyringfile: version %d of %s appears twice", version, name)
+		}
+		kr.insert(&Entry{Name: string(name), Version: version, Created: created, Key: k})
+	}
+	if len(payload) != 0 {
+		return errPayload
+	}
+	return nil
+}
+
+// Load reads and decrypts the keyring file at path
+func Load(path string, passphrase []byte) (*Keyring, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Unmarshal(data, passphrase)
+}
+
+// Save encrypts the keyring to path, readable by its owner only. The file
+// is written next to path then renamed over it, so that a failed save
+// leaves the previous file intact.
+func (kr *Keyring) Save(path string, passphrase []byte) error {
+	data, err := kr.Marshal(passphrase)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	// TempFile already creates the file with mode 0600
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
@@ -0,0 +1,200 @@
+package keyringfile
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jvehent/badcrypto/encoding/keyenc"
+)
+
+// testParams keep the tests fast, they are far too weak for real files
+var testParams = Params{Time: 1, Memory: 64, Threads: 2}
+
+var passphrase = []byte("correct horse battery staple")
+
+func newTestKeyring(t *testing.T) *Keyring {
+	kr := New()
+	kr.Params = testParams
+	for _, typ := range []KeyType{ECDSAP256, ECDSASecp256k1, Ed25519, X25519} {
+		if _, err := kr.Generate(string(typ), typ); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return kr
+}
+
+// sameKey compares keys through their encoding
+func sameKey(t *testing.T, a, b interface{}) bool {
+	ea, err := keyenc.MarshalProto(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eb, err := keyenc.MarshalProto(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.Equal(ea, eb)
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+	kr := newTestKeyring(t)
+	if _, err := kr.Rotate("ed25519"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := kr.Marshal(passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Unmarshal(data, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Params != testParams {
+		t.Fatalf("expected params %+v but got %+v", testParams, got.Params)
+	}
+	if len(got.entries) != len(kr.entries) {
+		t.Fatalf("expected %d entries but got %d", len(kr.entries), len(got.entries))
+	}
+	for i, e := range kr.entries {
+		g := got.entries[i]
+		if g.Name != e.Name || g.Version != e.Version || !g.Created.Equal(e.Created) || !sameKey(t, g.Key, e.Key) {
+			t.Fatalf("entry %d expected %s v%d but got %s v%d", i, e.Name, e.Version, g.Name, g.Version)
+		}
+	}
+	// a new salt and nonce every time
+	again, _ := kr.Marshal(passphrase)
+	if bytes.Equal(data, again) {
+		t.Fatal("expected two marshalings to differ")
+	}
+}
+
+func TestRotate(t *testing.T) {
+	t.Parallel()
+	kr := newTestKeyring(t)
+	v1, _ := kr.Export("x25519", 0)
+	v2, err := kr.Rotate("x25519")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v2.Version != 2 || v2.Type() != X25519 || sameKey(t, v1.Key, v2.Key) {
+		t.Fatalf("expected a new x25519 key as version 2 but got %s version %d", v2.Type(), v2.Version)
+	}
+	if latest, _ := kr.Export("x25519", 0); latest != v2 {
+		t.Fatal("expected export to return the latest version")
+	}
+	if old, _ := kr.Export("x25519", 1); old != v1 {
+		t.Fatal("expected version 1 to be kept")
+	}
+	if _, err := kr.Export("x25519", 3); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound for a missing version but got %v", err)
+	}
+	if _, err := kr.Rotate("missing"); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound rotating a missing key but got %v", err)
+	}
+	list := kr.List()
+	if len(list) != 4 {
+		t.Fatalf("expected 4 keys listed but got %d", len(list))
+	}
+	for i, name := range []string{"ecdsa-p256", "ecdsa-secp256k1", "ed25519", "x25519"} {
+		if list[i].Name != name {
+			t.Fatalf("testcase %d expected %s but got %s", i, name, list[i].Name)
+		}
+	}
+	if list[3] != v2 {
+		t.Fatal("expected list to return the latest version")
+	}
+}
+
+func TestAdd(t *testing.T) {
+	t.Parallel()
+	kr := New()
+	_, priv, _ := ed25519.GenerateKey(nil)
+	if _, err := kr.Add("signing", priv); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kr.Add("signing", priv); err != ErrExists {
+		t.Fatalf("expected ErrExists but got %v", err)
+	}
+	if _, err := kr.Add("../signing", priv); err == nil {
+		t.Fatal("expected an invalid name to be rejected")
+	}
+	if _, err := kr.Add("public", priv.Public()); err == nil {
+		t.Fatal("expected a public key to be rejected")
+	}
+	pub, err := Public(priv)
+	if err != nil || !bytes.Equal(pub.(ed25519.PublicKey), priv.Public().(ed25519.PublicKey)) {
+		t.Fatalf("expected the public key of the entry but got %v", err)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	t.Parallel()
+	kr := newTestKeyring(t)
+	data, err := kr.Marshal(passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Unmarshal(data, []byte("wrong")); err != ErrPassphrase {
+		t.Fatalf("expected ErrPassphrase but got %v", err)
+	}
+	// the header is authenticated
+	modified := append([]byte{}, data...)
+	modified[len(magic)+1+4+4]++
+	if _, err := Unmarshal(modified, passphrase); err != ErrPassphrase {
+		t.Fatalf("expected a modified header to be rejected but got %v", err)
+	}
+	modified = append([]byte{}, data...)
+	modified[len(modified)-1] ^= 1
+	if _, err := Unmarshal(modified, passphrase); err != ErrPassphrase {
+		t.Fatalf("expected a modified ciphertext to be rejected but got %v", err)
+	}
+	// parameters out of bounds are refused before deriving any key
+	modified = append([]byte{}, data...)
+	modified[len(magic)+1+4] = 0xff
+	if _, err := Unmarshal(modified, passphrase); err == nil || err == ErrPassphrase {
+		t.Fatalf("expected oversized parameters to be refused but got %v", err)
+	}
+	modified = append([]byte{}, data...)
+	modified[len(magic)] = 2
+	if _, err := Unmarshal(modified, passphrase); err == nil {
+		t.Fatal("expected an unknown version to be refused")
+	}
+	if _, err := Unmarshal(data[:headerSize], passphrase); err == nil {
+		t.Fatal("expected a truncated file to be refused")
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "keys.bkr")
+	kr := newTestKeyring(t)
+	if err := kr.Save(path, passphrase); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Fatalf("expected mode 0600 but got %v", fi.Mode().Perm())
+	}
+	got, err := Load(path, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.List()) != 4 {
+		t.Fatalf("expected 4 keys but got %d", len(got.List()))
+	}
+	// saving again replaces the file and leaves no temporary file behind
+	if err := got.Save(path, passphrase); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "*"))
+	if len(files) != 1 {
+		t.Fatalf("expected only the keyring in the directory but got %q", files)
+	}
+}
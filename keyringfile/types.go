@@ -0,0 +1,89 @@
+package keyringfile
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	stdrsa "crypto/rsa"
+	"fmt"
+
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/ecdsa"
+	"github.com/jvehent/badcrypto/noise"
+	"github.com/jvehent/badcrypto/rsa"
+)
+
+// KeyType names a kind of key a keyring generates
+type KeyType string
+
+// Key types of Generate and Rotate
+const (
+	RSA2048        KeyType = "rsa-2048"
+	RSA3072        KeyType = "rsa-3072"
+	RSA4096        KeyType = "rsa-4096"
+	ECDSAP256      KeyType = "ecdsa-p256"
+	ECDSASecp256k1 KeyType = "ecdsa-secp256k1"
+	Ed25519        KeyType = "ed25519"
+	X25519         KeyType = "x25519"
+)
+
+// KeyTypes lists the types Generate supports
+var KeyTypes = []KeyType{RSA2048, RSA3072, RSA4096, ECDSAP256, ECDSASecp256k1, Ed25519, X25519}
+
+var rsaBits = map[KeyType]int{RSA2048: 2048, RSA3072: 3072, RSA4096: 4096}
+
+func generate(t KeyType) (crypto.PrivateKey, error) {
+	switch t {
+	case RSA2048, RSA3072, RSA4096:
+		return rsa.GenerateKeyParallel(rand.Reader, rsaBits[t], 0)
+	case ECDSAP256:
+		return ecdsa.GenerateKey(rand.Reader, ec.P256())
+	case ECDSASecp256k1:
+		return ecdsa.GenerateKey(rand.Reader, ec.Secp256k1())
+	case Ed25519:
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		return priv, err
+	case X25519:
+		return noise.GenerateKeypair(rand.Reader)
+	}
+	return nil, fmt.Errorf("keyringfile: cannot generate keys of type %q", t)
+}
+
+// typeOf returns the type of a private key. Imported RSA keys of other
+// sizes are stored, but cannot be rotated.
+func typeOf(key crypto.PrivateKey) (KeyType, error) {
+	switch k := key.(type) {
+	case *stdrsa.PrivateKey:
+		return KeyType(fmt.Sprintf("rsa-%d", k.N.BitLen())), nil
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case ec.P256():
+			return ECDSAP256, nil
+		case ec.Secp256k1():
+			return ECDSASecp256k1, nil
+		}
+	case ed25519.PrivateKey:
+		return Ed25519, nil
+	case noise.DHKey:
+		if len(k.Private) != 0 {
+			return X25519, nil
+		}
+	}
+	return "", fmt.Errorf("keyringfile: %T is not a supported private key", key)
+}
+
+// Public returns the public half of a key of the keyring, in a type
+// keyenc encodes
+func Public(key crypto.PrivateKey) (crypto.PublicKey, error) {
+	switch k := key.(type) {
+	case *stdrsa.PrivateKey:
+		return &k.PublicKey, nil
+	case *ecdsa.PrivateKey:
+		return &k.PublicKey, nil
+	case ed25519.PrivateKey:
+		return k.Public(), nil
+	case noise.DHKey:
+		return noise.DHKey{Public: k.Public}, nil
+	}
+	return nil, fmt.Errorf("keyringfile: %T is not a supported private key", key)
+}
@@ -0,0 +1,163 @@
+package keystore
+
+import (
+	"crypto"
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jvehent/badcrypto/pkcs8"
+)
+
+// fileExt ends the name of every key file
+const fileExt = ".pem"
+
+// FileStore is a KeyStore keeping each key in a directory, as an
+// ENCRYPTED PRIVATE KEY PEM file named after its label. Keys are
+// encrypted with scrypt and AES-256-CBC, so that
+//
+//	openssl pkey -in dir/label.pem
+//
+// reads them with the passphrase. Every operation decrypts the key file,
+// which costs a scrypt derivation: hold on to handles rather than asking
+// the store again for each signature.
+type FileStore struct {
+	dir        string
+	passphrase []byte
+	opts       pkcs8.Options
+}
+
+// NewFileStore returns a store of the keys in dir, encrypted under
+// passphrase. The directory is created if it does not exist.
+func NewFileStore(dir string, passphrase []byte) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	opts := pkcs8.DefaultOptions
+	opts.KDF = pkcs8.Scrypt
+	return &FileStore{dir: dir, passphrase: append([]byte{}, passphrase...), opts: opts}, nil
+}
+
+func (s *FileStore) path(label string) string {
+	return filepath.Join(s.dir, label+fileExt)
+}
+
+// Generate creates a key and returns its public key
+func (s *FileStore) Generate(label string, t KeyType) (crypto.PublicKey, error) {
+	key, err := generate(rand.Reader, t)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.write(label, key); err != nil {
+		return nil, err
+	}
+	return key.Public(), nil
+}
+
+// Import encrypts a private key into the store
+func (s *FileStore) Import(label string, priv crypto.PrivateKey) error {
+	key, err := checkKey(priv)
+	if err != nil {
+		return err
+	}
+	return s.write(label, key)
+}
+
+func (s *FileStore) write(label string, key crypto.Signer) error {
+	if err := checkLabel(label); err != nil {
+		return err
+	}
+	data, err := pkcs8.EncodePEM(key, s.passphrase, &s.opts)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path(label), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		return ErrExists
+	} else if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(s.path(label))
+		return err
+	}
+	return f.Close()
+}
+
+// read decrypts the key of label
+func (s *FileStore) read(label string) (crypto.Signer, error) {
+	if err := checkLabel(label); err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(s.path(label))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	priv, err := pkcs8.DecodePEM(data, s.passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return checkKey(priv)
+}
+
+// Labels lists the keys in lexical order
+func (s *FileStore) Labels() ([]string, error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var labels []string
+	for _, f := range files {
+		label := strings.TrimSuffix(f.Name(), fileExt)
+		if f.Mode().IsRegular() && label != f.Name() && checkLabel(label) == nil {
+			labels = append(labels, label)
+		}
+	}
+	sort.Strings(labels)
+	return labels, nil
+}
+
+// PublicKey returns the public key of a key
+func (s *FileStore) PublicKey(label string) (crypto.PublicKey, error) {
+	key, err := s.read(label)
+	if err != nil {
+		return nil, err
+	}
+	return key.Public(), nil
+}
+
+// Signer decrypts a key and returns a handle signing with it
+func (s *FileStore) Signer(label string) (Signer, error) {
+	key, err := s.read(label)
+	if err != nil {
+		return nil, err
+	}
+	return &handle{label: label, key: key}, nil
+}
+
+// Decrypter decrypts an RSA key and returns a handle decrypting with it
+func (s *FileStore) Decrypter(label string) (Decrypter, error) {
+	key, err := s.read(label)
+	if err != nil {
+		return nil, err
+	}
+	return newDecrypter(label, key)
+}
+
+// Delete removes the file of a key
+func (s *FileStore) Delete(label string) error {
+	if err := checkLabel(label); err != nil {
+		return err
+	}
+	err := os.Remove(s.path(label))
+	if os.IsNotExist(err) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,154 @@
+// Package keystore defines how code uses private keys it does not hold, in
+// the spirit of PKCS#11: keys are created or imported into a KeyStore
+// under a label, and then only reached through Signer and Decrypter
+// handles that sign and decrypt without ever returning the private key.
+// TLS, JWT or command line code written against these interfaces can
+// later be pointed at an HSM or a cloud KMS by implementing KeyStore.
+//
+// Two stores are provided. MemoryStore keeps keys in memory, for tests
+// and short lived processes. FileStore keeps each key in a directory as a
+// passphrase encrypted PKCS#8 file, which OpenSSL can also read.
+package keystore
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+)
+
+// KeyType selects the algorithm of a generated key
+type KeyType string
+
+// Supported key types
+const (
+	ECDSAP256 KeyType = "ecdsa-p256"
+	ECDSAP384 KeyType = "ecdsa-p384"
+	Ed25519   KeyType = "ed25519"
+	RSA2048   KeyType = "rsa-2048"
+	RSA3072   KeyType = "rsa-3072"
+)
+
+var (
+	// ErrNotFound is returned for a label that names no key
+	ErrNotFound = errors.New("keystore: key not found")
+	// ErrExists is returned when a label is already taken
+	ErrExists = errors.New("keystore: key already exists")
+	// ErrUnsupported is returned when a key cannot do what is asked,
+	// such as decrypting with an ECDSA key
+	ErrUnsupported = errors.New("keystore: operation not supported by key")
+)
+
+// Signer signs with a key of the store. Sign follows crypto.Signer: the
+// caller hashes the message, except for Ed25519 keys.
+type Signer interface {
+	crypto.Signer
+	Label() string
+}
+
+// Decrypter decrypts with an RSA key of the store, following
+// crypto.Decrypter: opts is a *rsa.OAEPOptions or a
+// *rsa.PKCS1v15DecryptOptions
+type Decrypter interface {
+	crypto.Decrypter
+	Label() string
+}
+
+// KeyStore holds private keys by label
+type KeyStore interface {
+	// Generate creates a key and returns its public key
+	Generate(label string, t KeyType) (crypto.PublicKey, error)
+	// Import adds an *ecdsa.PrivateKey, ed25519.PrivateKey or
+	// *rsa.PrivateKey
+	Import(label string, priv crypto.PrivateKey) error
+	// Labels lists the keys in lexical order
+	Labels() ([]string, error)
+	PublicKey(label string) (crypto.PublicKey, error)
+	Signer(label string) (Signer, error)
+	Decrypter(label string) (Decrypter, error)
+	Delete(label string) error
+}
+
+// labelRE keeps labels usable as file names
+var labelRE = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]{0,63}$`)
+
+func checkLabel(label string) error {
+	if !labelRE.MatchString(label) {
+		return fmt.Errorf("keystore: invalid label %q", label)
+	}
+	return nil
+}
+
+// generate returns a new private key of type t
+func generate(random io.Reader, t KeyType) (crypto.Signer, error) {
+	switch t {
+	case ECDSAP256:
+		return ecdsa.GenerateKey(elliptic.P256(), random)
+	case ECDSAP384:
+		return ecdsa.GenerateKey(elliptic.P384(), random)
+	case Ed25519:
+		_, priv, err := ed25519.GenerateKey(random)
+		return priv, err
+	case RSA2048:
+		return rsa.GenerateKey(random, 2048)
+	case RSA3072:
+		return rsa.GenerateKey(random, 3072)
+	}
+	return nil, fmt.Errorf("keystore: unknown key type %q", t)
+}
+
+// checkKey returns priv as a crypto.Signer if it is a supported type
+func checkKey(priv crypto.PrivateKey) (crypto.Signer, error) {
+	switch k := priv.(type) {
+	case *ecdsa.PrivateKey:
+		return k, nil
+	case ed25519.PrivateKey:
+		return k, nil
+	case *rsa.PrivateKey:
+		return k, nil
+	}
+	return nil, fmt.Errorf("keystore: unsupported key type %T", priv)
+}
+
+// handle exposes a private key through Signer and Decrypter only, so
+// callers cannot type assert their way to the key
+type handle struct {
+	label string
+	key   crypto.Signer
+}
+
+func (h *handle) Label() string { return h.label }
+
+func (h *handle) Public() crypto.PublicKey { return h.key.Public() }
+
+func (h *handle) Sign(random io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	return h.key.Sign(random, digest, opts)
+}
+
+func (h *handle) Decrypt(random io.Reader, ciphertext []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	d, ok := h.key.(crypto.Decrypter)
+	if !ok {
+		return nil, ErrUnsupported
+	}
+	if random == nil {
+		random = rand.Reader
+	}
+	return d.Decrypt(random, ciphertext, opts)
+}
+
+// newDecrypter returns a Decrypter handle for RSA keys only
+func newDecrypter(label string, key crypto.Signer) (Decrypter, error) {
+	if _, ok := key.(*rsa.PrivateKey); !ok {
+		return nil, ErrUnsupported
+	}
+	return &handle{label: label, key: key}, nil
+}
@@ -0,0 +1,119 @@
+package keystore
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"reflect"
+	"testing"
+)
+
+var (
+	_ KeyStore = (*MemoryStore)(nil)
+	_ KeyStore = (*FileStore)(nil)
+)
+
+// testKeyStore checks the behavior every KeyStore must have
+func testKeyStore(t *testing.T, ks KeyStore) {
+	digest := sha256.Sum256([]byte("message"))
+	var testcases = []struct {
+		label   string
+		kt      KeyType
+		opts    crypto.SignerOpts
+		msg     []byte
+		decrypt bool
+	}{
+		{"p256", ECDSAP256, crypto.SHA256, digest[:], false},
+		{"ed", Ed25519, crypto.Hash(0), []byte("message"), false},
+		{"rsa", RSA2048, crypto.SHA256, digest[:], true},
+	}
+	for i, tc := range testcases {
+		pub, err := ks.Generate(tc.label, tc.kt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored, err := ks.PublicKey(tc.label); err != nil || !reflect.DeepEqual(stored, pub) {
+			t.Fatalf("testcase %d expected the stored public key to match but got %v", i, err)
+		}
+		s, err := ks.Signer(tc.label)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Label() != tc.label {
+			t.Fatalf("testcase %d expected label %s but got %s", i, tc.label, s.Label())
+		}
+		sig, err := s.Sign(nil, tc.msg, tc.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var valid bool
+		switch k := pub.(type) {
+		case *ecdsa.PublicKey:
+			valid = ecdsa.VerifyASN1(k, tc.msg, sig)
+		case ed25519.PublicKey:
+			valid = ed25519.Verify(k, tc.msg, sig)
+		case *rsa.PublicKey:
+			valid = rsa.VerifyPKCS1v15(k, crypto.SHA256, tc.msg, sig) == nil
+		}
+		if !valid {
+			t.Fatalf("testcase %d expected a valid signature", i)
+		}
+		d, err := ks.Decrypter(tc.label)
+		if !tc.decrypt {
+			if err != ErrUnsupported {
+				t.Fatalf("testcase %d expected %v but got %v", i, ErrUnsupported, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		ct, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, pub.(*rsa.PublicKey), []byte("secret"), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pt, err := d.Decrypt(nil, ct, &rsa.OAEPOptions{Hash: crypto.SHA256})
+		if err != nil || string(pt) != "secret" {
+			t.Fatalf("testcase %d expected to decrypt but got %q and %v", i, pt, err)
+		}
+	}
+
+	imported, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err := ks.Import("imported", imported); err != nil {
+		t.Fatal(err)
+	}
+	if pub, _ := ks.PublicKey("imported"); !reflect.DeepEqual(pub, &imported.PublicKey) {
+		t.Fatal("expected the imported public key")
+	}
+	if err := ks.Import("imported", imported); err != ErrExists {
+		t.Fatalf("expected %v but got %v", ErrExists, err)
+	}
+	if err := ks.Import("x", "not a key"); err == nil {
+		t.Fatal("expected an invalid key to be rejected")
+	}
+	labels, err := ks.Labels()
+	if err != nil || !reflect.DeepEqual(labels, []string{"ed", "imported", "p256", "rsa"}) {
+		t.Fatalf("expected four sorted labels but got %v and %v", labels, err)
+	}
+	if err := ks.Delete("p256"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.Signer("p256"); err != ErrNotFound {
+		t.Fatalf("expected %v but got %v", ErrNotFound, err)
+	}
+	if err := ks.Delete("p256"); err != ErrNotFound {
+		t.Fatalf("expected %v but got %v", ErrNotFound, err)
+	}
+	for _, label := range []string{"", "../escape", ".hidden", "a/b"} {
+		if _, err := ks.Generate(label, Ed25519); err == nil {
+			t.Fatalf("expected label %q to be rejected", label)
+		}
+	}
+	if _, err := ks.Generate("dsa", KeyType("dsa")); err == nil {
+		t.Fatal("expected an unknown key type to be rejected")
+	}
+}
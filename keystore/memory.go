@@ -0,0 +1,114 @@
+package keystore
+
+import (
+	"crypto"
+	"crypto/rand"
+	"sort"
+	"sync"
+)
+
+// MemoryStore is a KeyStore holding keys in memory. It is safe for
+// concurrent use.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	keys map[string]crypto.Signer
+}
+
+// NewMemoryStore returns an empty store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{keys: make(map[string]crypto.Signer)}
+}
+
+// Generate creates a key and returns its public key
+func (s *MemoryStore) Generate(label string, t KeyType) (crypto.PublicKey, error) {
+	key, err := generate(rand.Reader, t)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.add(label, key); err != nil {
+		return nil, err
+	}
+	return key.Public(), nil
+}
+
+// Import adds a private key. The store keeps priv itself, not a copy.
+func (s *MemoryStore) Import(label string, priv crypto.PrivateKey) error {
+	key, err := checkKey(priv)
+	if err != nil {
+		return err
+	}
+	return s.add(label, key)
+}
+
+func (s *MemoryStore) add(label string, key crypto.Signer) error {
+	if err := checkLabel(label); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.keys[label]; ok {
+		return ErrExists
+	}
+	s.keys[label] = key
+	return nil
+}
+
+func (s *MemoryStore) get(label string) (crypto.Signer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok := s.keys[label]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return key, nil
+}
+
+// Labels lists the keys in lexical order
+func (s *MemoryStore) Labels() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	labels := make([]string, 0, len(s.keys))
+	for label := range s.keys {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels, nil
+}
+
+// PublicKey returns the public key of a key
+func (s *MemoryStore) PublicKey(label string) (crypto.PublicKey, error) {
+	key, err := s.get(label)
+	if err != nil {
+		return nil, err
+	}
+	return key.Public(), nil
+}
+
+// Signer returns a handle signing with a key
+func (s *MemoryStore) Signer(label string) (Signer, error) {
+	key, err := s.get(label)
+	if err != nil {
+		return nil, err
+	}
+	return &handle{label: label, key: key}, nil
+}
+
+// Decrypter returns a handle decrypting with an RSA key
+func (s *MemoryStore) Decrypter(label string) (Decrypter, error) {
+	key, err := s.get(label)
+	if err != nil {
+		return nil, err
+	}
+	return newDecrypter(label, key)
+}
+
+// Delete removes a key. Handles obtained before keep working.
+func (s *MemoryStore) Delete(label string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.keys[label]; !ok {
+		return ErrNotFound
+	}
+	delete(s.keys, label)
+	return nil
+}
@@ -0,0 +1,8 @@
+package keystore
+
+import "testing"
+
+func TestMemoryStore(t *testing.T) {
+	t.Parallel()
+	testKeyStore(t, NewMemoryStore())
+}
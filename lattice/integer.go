@@ -0,0 +1,117 @@
+package lattice
+
+import (
+	"math/big"
+
+	"github.com/jvehent/badcrypto/bignum"
+)
+
+// integer is a signed integer made of a sign and a bignum magnitude, since
+// bignum.Int only holds natural numbers. Its methods return new integers
+// and leave their receiver and arguments untouched.
+type integer struct {
+	negative bool
+	abs      *bignum.Int
+}
+
+// makeInteger returns the integer of magnitude abs, which is never
+// negative when abs is zero
+func makeInteger(negative bool, abs *bignum.Int) integer {
+	return integer{negative: negative && abs.Compare(bignum.ZeroValue) != 0, abs: abs}
+}
+
+func newInteger(v int64) integer {
+	return fromBig(big.NewInt(v))
+}
+
+func fromBig(x *big.Int) integer {
+	abs := new(bignum.Int)
+	abs.SetBytes(x.Bytes())
+	return makeInteger(x.Sign() < 0, abs)
+}
+
+func (x integer) toBig() *big.Int {
+	r := new(big.Int).SetBytes(x.abs.Bytes())
+	if x.negative {
+		r.Neg(r)
+	}
+	return r
+}
+
+func (x integer) sign() int {
+	switch {
+	case x.abs.Compare(bignum.ZeroValue) == 0:
+		return 0
+	case x.negative:
+		return -1
+	}
+	return 1
+}
+
+func (x integer) add(y integer) integer {
+	abs := new(bignum.Int)
+	if x.negative == y.negative {
+		abs.Set(x.abs)
+		abs.Add(y.abs)
+		return makeInteger(x.negative, abs)
+	}
+	// opposite signs, the larger magnitude gives its sign
+	if x.abs.Compare(y.abs) < 0 {
+		x, y = y, x
+	}
+	abs.Set(x.abs)
+	abs.Sub(y.abs)
+	return makeInteger(x.negative, abs)
+}
+
+func (x integer) sub(y integer) integer {
+	return x.add(integer{negative: !y.negative, abs: y.abs})
+}
+
+func (x integer) mul(y integer) integer {
+	abs := new(bignum.Int)
+	abs.Set(x.abs)
+	abs.Mul(y.abs)
+	return makeInteger(x.negative != y.negative, abs)
+}
+
+// quo returns x / y rounded towards zero. The divisions of the reduction
+// are exact, which makes the rounding moot.
+func (x integer) quo(y integer) integer {
+	abs := new(bignum.Int)
+	abs.Set(x.abs)
+	abs.KnuthDiv(y.abs)
+	return makeInteger(x.negative != y.negative, abs)
+}
+
+// floorDiv returns floor(x / y) for a positive y
+func (x integer) floorDiv(y integer) integer {
+	abs := new(bignum.Int)
+	abs.Set(x.abs)
+	rem := abs.KnuthDiv(y.abs)
+	if x.negative && rem.Compare(bignum.ZeroValue) != 0 {
+		abs.Increment()
+	}
+	return makeInteger(x.negative, abs)
+}
+
+func (x integer) lsh(n uint) integer {
+	abs := new(bignum.Int)
+	abs.Set(x.abs)
+	abs.Lsh(n)
+	return makeInteger(x.negative, abs)
+}
+
+// cmp compares x and y, and returns -1, 0 or 1
+func (x integer) cmp(y integer) int {
+	return x.sub(y).sign()
+}
+
+// dot returns the inner product of u and v
+func dot(u, v []integer) integer {
+	sum := newInteger(0)
+	for i := range u {
+		sum = sum.add(u[i].mul(v[i]))
+	}
+	return sum
+}
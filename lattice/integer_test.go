@@ -0,0 +1,54 @@
+package lattice
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+type result struct {
+	name          string
+	got, expected *big.Int
+}
+
+// TestInteger checks the signed arithmetic against math/big on operands
+// of both signs
+func TestInteger(t *testing.T) {
+	t.Parallel()
+	var ops []*big.Int
+	for _, bits := range []uint{0, 1, 63, 64, 65, 300} {
+		max := new(big.Int).Lsh(big.NewInt(1), bits)
+		r, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ops = append(ops, r, new(big.Int).Neg(r), max, new(big.Int).Neg(max))
+	}
+	for _, a := range ops {
+		for _, b := range ops {
+			x, y := fromBig(a), fromBig(b)
+			var testcases = []result{
+				{"+", x.add(y).toBig(), new(big.Int).Add(a, b)},
+				{"-", x.sub(y).toBig(), new(big.Int).Sub(a, b)},
+				{"*", x.mul(y).toBig(), new(big.Int).Mul(a, b)},
+				{"<<", x.lsh(3).toBig(), new(big.Int).Lsh(a, 3)},
+				{"cmp", big.NewInt(int64(x.cmp(y))), big.NewInt(int64(a.Cmp(b)))},
+			}
+			if b.Sign() != 0 {
+				testcases = append(testcases, result{"quo", x.quo(y).toBig(), new(big.Int).Quo(a, b)})
+			}
+			if b.Sign() > 0 {
+				// Div rounds towards minus infinity for a positive divisor
+				testcases = append(testcases, result{"floor", x.floorDiv(y).toBig(), new(big.Int).Div(a, b)})
+			}
+			for _, tc := range testcases {
+				if tc.got.Cmp(tc.expected) != 0 {
+					t.Fatalf("expected %s %s %s = %s but got %s", a, tc.name, b, tc.expected, tc.got)
+				}
+			}
+		}
+	}
+	if fromBig(big.NewInt(0)).sign() != 0 || newInteger(-5).sign() != -1 || newInteger(5).sign() != 1 {
+		t.Fatal("expected the signs of 0, -5 and 5 to be 0, -1 and 1")
+	}
+}
@@ -0,0 +1,264 @@
+// Package lattice implements the LLL lattice basis reduction of Lenstra,
+// Lenstra and Lovasz (1982), the tool behind Coppersmith's small roots,
+// the low density knapsack attacks and the recovery of ECDSA keys from
+// biased nonces.
+//
+// A lattice is the set of integer combinations of the vectors of a basis.
+// LLL turns any basis into one of short and nearly orthogonal vectors in
+// polynomial time, the first of which is at most 2^((d-1)/2) times longer
+// than the shortest vector of the lattice in dimension d, and often much
+// closer to it in practice. An attack only has to build a lattice where
+// the secret is an unusually short vector.
+//
+// The reduction uses the integral version of the algorithm given by Cohen
+// in A Course in Computational Algebraic Number Theory (algorithm 2.6.7).
+// The Gram-Schmidt coefficients are rationals with a known denominator, so
+// only their numerators are kept, and all the arithmetic is exact on
+// integers, with neither floating point errors nor the cost of rationals.
+// Those integers are bignum magnitudes with a sign, bases come in and go
+// out as math/big integers.
+package lattice
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrDependent is returned when the vectors of a basis are linearly
+// dependent
+var ErrDependent = errors.New("lattice: the basis vectors are linearly dependent")
+
+// DefaultDelta is the usual Lovasz constant 3/4. Values closer to 1 give
+// shorter vectors at the cost of more iterations, Coppersmith and HNP
+// attacks often use 0.99.
+var DefaultDelta = big.NewRat(3, 4)
+
+// Dot returns the inner product of u and v
+func Dot(u, v []*big.Int) *big.Int {
+	sum, t := new(big.Int), new(big.Int)
+	for i := range u {
+		sum.Add(sum, t.Mul(u[i], v[i]))
+	}
+	return sum
+}
+
+// Copy returns a deep copy of basis
+func Copy(basis [][]*big.Int) [][]*big.Int {
+	c := make([][]*big.Int, len(basis))
+	for i, v := range basis {
+		c[i] = make([]*big.Int, len(v))
+		for j, x := range v {
+			c[i][j] = new(big.Int).Set(x)
+		}
+	}
+	return c
+}
+
+// check verifies that basis is not empty and all its vectors have the same
+// size
+func check(basis [][]*big.Int) error {
+	if len(basis) == 0 || len(basis[0]) == 0 {
+		return errors.New("lattice: empty basis")
+	}
+	for _, v := range basis {
+		if len(v) != len(basis[0]) {
+			return errors.New("lattice: basis vectors of different sizes")
+		}
+	}
+	return nil
+}
+
+// LLL returns an LLL reduced basis of the lattice spanned by basis, which
+// is left untouched. delta must be in (1/4, 1], DefaultDelta is a good
+// default.
+//
+// The result is size reduced, |mu_ij| <= 1/2 for j < i, and satisfies the
+// Lovasz condition |b*_k|^2 >= (delta - mu_k,k-1^2) |b*_k-1|^2, where b*
+// is the Gram-Schmidt orthogonalization of the basis b and mu are the
+// coefficients of the projections.
+func LLL(basis [][]*big.Int, delta *big.Rat) ([][]*big.Int, error) {
+	if err := check(basis); err != nil {
+		return nil, err
+	}
+	if delta.Cmp(big.NewRat(1, 4)) <= 0 || delta.Cmp(big.NewRat(1, 1)) > 0 {
+		return nil, errors.New("lattice: delta must be in (1/4, 1]")
+	}
+	n := len(basis)
+	b := make([][]integer, n)
+	for i, v := range basis {
+		b[i] = make([]integer, len(v))
+		for j, x := range v {
+			b[i][j] = fromBig(x)
+		}
+	}
+	// d[i+1] is the Gram determinant of the first i+1 vectors, the
+	// product of |b*_j|^2 for j <= i, and d[0] = 1. lambda[i][j] =
+	// d[j+1] * mu_ij is an integer.
+	d := make([]integer, n+1)
+	d[0] = newInteger(1)
+	lambda := make([][]integer, n)
+	for i := range lambda {
+		lambda[i] = make([]integer, n)
+		for j := range lambda[i] {
+			lambda[i][j] = newInteger(0)
+		}
+	}
+	p, q := fromBig(delta.Num()), fromBig(delta.Denom())
+
+	// red size reduces b[k] against b[l]
+	red := func(k, l int) {
+		// 2 |lambda_kl| > d_l+1
+		if lambda[k][l].lsh(1).abs.Compare(d[l+1].abs) <= 0 {
+			return
+		}
+		// r = round(lambda_kl / d_l+1) = floor((2 lambda_kl + d_l+1) / (2 d_l+1))
+		r := lambda[k][l].lsh(1).add(d[l+1]).floorDiv(d[l+1].lsh(1))
+		for i := range b[k] {
+			b[k][i] = b[k][i].sub(r.mul(b[l][i]))
+		}
+		lambda[k][l] = lambda[k][l].sub(r.mul(d[l+1]))
+		for i := 0; i < l; i++ {
+			lambda[k][i] = lambda[k][i].sub(r.mul(lambda[l][i]))
+		}
+	}
+
+	// swap exchanges b[k] and b[k-1] and updates the coefficients
+	swap := func(k, kmax int) {
+		b[k], b[k-1] = b[k-1], b[k]
+		for j := 0; j < k-1; j++ {
+			lambda[k][j], lambda[k-1][j] = lambda[k-1][j], lambda[k][j]
+		}
+		l := lambda[k][k-1]
+		// B = (d_k-1 d_k+1 + lambda^2) / d_k, the new d_k
+		nb := d[k-1].mul(d[k+1]).add(l.mul(l)).quo(d[k])
+		for i := k + 1; i <= kmax; i++ {
+			old := lambda[i][k]
+			// lambda_ik = (d_k+1 lambda_i,k-1 - lambda old) / d_k
+			lambda[i][k] = d[k+1].mul(lambda[i][k-1]).sub(l.mul(old)).quo(d[k])
+			// lambda_i,k-1 = (B old + lambda lambda_ik) / d_k+1
+			lambda[i][k-1] = nb.mul(old).add(l.mul(lambda[i][k])).quo(d[k+1])
+		}
+		d[k] = nb
+	}
+
+	d[1] = dot(b[0], b[0])
+	if d[1].sign() == 0 {
+		return nil, ErrDependent
+	}
+	k, kmax := 1, 0
+	for k < n {
+		if k > kmax {
+			// incremental Gram-Schmidt of b[k]
+			kmax = k
+			for j := 0; j <= k; j++ {
+				v := dot(b[k], b[j])
+				for i := 0; i < j; i++ {
+					// v = (d_i+1 v - lambda_ki lambda_ji) / d_i
+					v = v.mul(d[i+1]).sub(lambda[k][i].mul(lambda[j][i])).quo(d[i])
+				}
+				if j < k {
+					lambda[k][j] = v
+				} else {
+					if v.sign() == 0 {
+						return nil, ErrDependent
+					}
+					d[k+1] = v
+				}
+			}
+		}
+		red(k, k-1)
+		// Lovasz condition, q d_k+1 d_k-1 < p d_k^2 - q lambda_k,k-1^2
+		// swaps the two vectors
+		left := d[k+1].mul(d[k-1]).mul(q)
+		right := d[k].mul(d[k]).mul(p).sub(lambda[k][k-1].mul(lambda[k][k-1]).mul(q))
+		if left.cmp(right) < 0 {
+			swap(k, kmax)
+			if k > 1 {
+				k--
+			}
+			continue
+		}
+		for l := k - 2; l >= 0; l-- {
+			red(k, l)
+		}
+		k++
+	}
+	reduced := make([][]*big.Int, n)
+	for i, v := range b {
+		reduced[i] = make([]*big.Int, len(v))
+		for j, x := range v {
+			reduced[i][j] = x.toBig()
+		}
+	}
+	return reduced, nil
+}
+
+// GramSchmidt returns the Gram-Schmidt orthogonalization b* of basis and
+// the coefficients mu such that b_i = b*_i + sum of mu_ij b*_j for j < i,
+// with rationals. It is much slower than LLL and only meant to check its
+// results.
+func GramSchmidt(basis [][]*big.Int) (orthogonal [][]*big.Rat, mu [][]*big.Rat) {
+	n := len(basis)
+	orthogonal = make([][]*big.Rat, n)
+	mu = make([][]*big.Rat, n)
+	norms := make([]*big.Rat, n)
+	for i := range basis {
+		orthogonal[i] = make([]*big.Rat, len(basis[i]))
+		for k, x := range basis[i] {
+			orthogonal[i][k] = new(big.Rat).SetInt(x)
+		}
+		mu[i] = make([]*big.Rat, i)
+		for j := 0; j < i; j++ {
+			// mu_ij = <b_i, b*_j> / <b*_j, b*_j>
+			dot := new(big.Rat)
+			for k, x := range basis[i] {
+				dot.Add(dot, new(big.Rat).Mul(new(big.Rat).SetInt(x), orthogonal[j][k]))
+			}
+			if norms[j].Sign() == 0 {
+				mu[i][j] = new(big.Rat)
+				continue
+			}
+			mu[i][j] = dot.Quo(dot, norms[j])
+			for k := range orthogonal[i] {
+				orthogonal[i][k].Sub(orthogonal[i][k], new(big.Rat).Mul(mu[i][j], orthogonal[j][k]))
+			}
+		}
+		norms[i] = new(big.Rat)
+		for _, x := range orthogonal[i] {
+			norms[i].Add(norms[i], new(big.Rat).Mul(x, x))
+		}
+	}
+	return
+}
+
+// IsReduced returns true if basis is LLL reduced for delta, checked with
+// exact rationals
+func IsReduced(basis [][]*big.Int, delta *big.Rat) bool {
+	orthogonal, mu := GramSchmidt(basis)
+	half := big.NewRat(1, 2)
+	norm := func(v []*big.Rat) *big.Rat {
+		s := new(big.Rat)
+		for _, x := range v {
+			s.Add(s, new(big.Rat).Mul(x, x))
+		}
+		return s
+	}
+	for i := range basis {
+		for j := 0; j < i; j++ {
+			if new(big.Rat).Abs(mu[i][j]).Cmp(half) > 0 {
+				return false
+			}
+		}
+		if i == 0 {
+			continue
+		}
+		// |b*_i|^2 >= (delta - mu_i,i-1^2) |b*_i-1|^2
+		m := new(big.Rat).Mul(mu[i][i-1], mu[i][i-1])
+		bound := new(big.Rat).Sub(delta, m)
+		bound.Mul(bound, norm(orthogonal[i-1]))
+		if norm(orthogonal[i]).Cmp(bound) < 0 {
+			return false
+		}
+	}
+	return true
+}
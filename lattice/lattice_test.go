@@ -0,0 +1,216 @@
+package lattice
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func newBasis(rows ...[]int64) [][]*big.Int {
+	basis := make([][]*big.Int, len(rows))
+	for i, row := range rows {
+		for _, x := range row {
+			basis[i] = append(basis[i], big.NewInt(x))
+		}
+	}
+	return basis
+}
+
+func equal(a, b [][]*big.Int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		for j := range a[i] {
+			if a[i][j].Cmp(b[i][j]) != 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// det returns the determinant of a square basis with fraction free
+// Gaussian elimination (Bareiss)
+func det(basis [][]*big.Int) *big.Int {
+	m := Copy(basis)
+	n := len(m)
+	sign, prev := 1, big.NewInt(1)
+	for k := 0; k < n-1; k++ {
+		if m[k][k].Sign() == 0 {
+			pivot := -1
+			for i := k + 1; i < n; i++ {
+				if m[i][k].Sign() != 0 {
+					pivot = i
+					break
+				}
+			}
+			if pivot < 0 {
+				return new(big.Int)
+			}
+			m[k], m[pivot] = m[pivot], m[k]
+			sign = -sign
+		}
+		for i := k + 1; i < n; i++ {
+			for j := k + 1; j < n; j++ {
+				x := new(big.Int).Mul(m[i][j], m[k][k])
+				x.Sub(x, new(big.Int).Mul(m[i][k], m[k][j]))
+				m[i][j] = x.Quo(x, prev)
+			}
+		}
+		prev = m[k][k]
+	}
+	return new(big.Int).Mul(m[n-1][n-1], big.NewInt(int64(sign)))
+}
+
+func TestLLL(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		basis, reduced [][]*big.Int
+	}{
+		// the example of wikipedia
+		{newBasis([]int64{1, 1, 1}, []int64{-1, 0, 2}, []int64{3, 5, 6}), newBasis([]int64{0, 1, 0}, []int64{1, 0, 1}, []int64{-1, 0, 2})},
+		{newBasis([]int64{1, 0}, []int64{0, 1}), newBasis([]int64{1, 0}, []int64{0, 1})},
+		{newBasis([]int64{201, 37}, []int64{1648, 297}), newBasis([]int64{1, 32}, []int64{40, 1})},
+	}
+	for i, tc := range testcases {
+		reduced, err := LLL(tc.basis, DefaultDelta)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if !equal(reduced, tc.reduced) {
+			t.Fatalf("testcase %d expected %v but got %v", i, tc.reduced, reduced)
+		}
+	}
+}
+
+func TestLLLRandoms(t *testing.T) {
+	t.Parallel()
+	for i, dim := range []int{2, 5, 10, 20} {
+		for _, delta := range []*big.Rat{DefaultDelta, big.NewRat(99, 100), big.NewRat(1, 1)} {
+			basis := make([][]*big.Int, dim)
+			for j := range basis {
+				basis[j] = make([]*big.Int, dim)
+				for k := range basis[j] {
+					x, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 100))
+					if err != nil {
+						t.Fatal(err)
+					}
+					basis[j][k] = x.Sub(x, new(big.Int).Lsh(big.NewInt(1), 99))
+				}
+			}
+			original := Copy(basis)
+			reduced, err := LLL(basis, delta)
+			if err != nil {
+				t.Fatalf("testcase %d failed with %v", i, err)
+			}
+			if !equal(basis, original) {
+				t.Fatalf("testcase %d modified its input", i)
+			}
+			if !IsReduced(reduced, delta) {
+				t.Fatalf("testcase %d is not reduced for delta %s", i, delta)
+			}
+			// the reduced basis spans the same lattice, with the same
+			// volume
+			d, rd := det(basis), det(reduced)
+			if d.CmpAbs(rd) != 0 {
+				t.Fatalf("testcase %d changed the determinant from %s to %s", i, d, rd)
+			}
+		}
+	}
+}
+
+func TestLLLErrors(t *testing.T) {
+	t.Parallel()
+	if _, err := LLL(newBasis([]int64{1, 2}, []int64{2, 4}), DefaultDelta); err != ErrDependent {
+		t.Fatalf("expected dependent vectors to fail but got %v", err)
+	}
+	if _, err := LLL(newBasis([]int64{0, 0}, []int64{2, 4}), DefaultDelta); err != ErrDependent {
+		t.Fatalf("expected a zero vector to fail but got %v", err)
+	}
+	if _, err := LLL(nil, DefaultDelta); err == nil {
+		t.Fatal("expected an empty basis to fail")
+	}
+	if _, err := LLL(newBasis([]int64{1, 2}, []int64{2}), DefaultDelta); err == nil {
+		t.Fatal("expected vectors of different sizes to fail")
+	}
+	if _, err := LLL(newBasis([]int64{1}), big.NewRat(1, 4)); err == nil {
+		t.Fatal("expected delta 1/4 to fail")
+	}
+}
+
+// TestKnapsack solves a low density subset sum problem with the lattice of
+// Lagarias and Odlyzko, as used to break the Merkle-Hellman cryptosystem
+func TestKnapsack(t *testing.T) {
+	t.Parallel()
+	const n = 16
+	weights := make([]*big.Int, n)
+	for i := range weights {
+		w, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 100))
+		if err != nil {
+			t.Fatal(err)
+		}
+		weights[i] = w
+	}
+	secret := []int64{1, 0, 1, 1, 0, 0, 1, 0, 1, 1, 1, 0, 0, 1, 0, 1}
+	sum := new(big.Int)
+	for i, bit := range secret {
+		if bit == 1 {
+			sum.Add(sum, weights[i])
+		}
+	}
+	// the rows are (2 e_i, N w_i) and (1, ..., 1, N s), the secret gives
+	// the short vector (2x_i - 1, 0) of norm sqrt(n)
+	scale := big.NewInt(1 << 20)
+	basis := make([][]*big.Int, n+1)
+	for i := range basis {
+		basis[i] = make([]*big.Int, n+1)
+		for j := range basis[i] {
+			basis[i][j] = new(big.Int)
+		}
+		if i < n {
+			basis[i][i].SetInt64(2)
+			basis[i][n].Mul(weights[i], scale)
+		} else {
+			for j := 0; j < n; j++ {
+				basis[i][j].SetInt64(1)
+			}
+			basis[i][n].Mul(sum, scale)
+		}
+	}
+	reduced, err := LLL(basis, big.NewRat(99, 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range reduced {
+		if v[n].Sign() != 0 {
+			continue
+		}
+		for _, sign := range []int64{1, -1} {
+			found := true
+			for i, bit := range secret {
+				if v[i].Int64()*sign != 2*bit-1 {
+					found = false
+				}
+			}
+			if found {
+				return
+			}
+		}
+	}
+	t.Fatalf("the secret was not found in %v", reduced)
+}
+
+func BenchmarkLLL40(b *testing.B) {
+	basis := make([][]*big.Int, 40)
+	for j := range basis {
+		basis[j] = make([]*big.Int, 40)
+		for k := range basis[j] {
+			basis[j][k], _ = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		LLL(basis, big.NewRat(99, 100))
+	}
+}
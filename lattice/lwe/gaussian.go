@@ -0,0 +1,80 @@
+package lwe
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// tailCut is where the Gaussian is cut, in standard deviations: values
+// beyond 12 sigma have a probability below 2^-100
+const tailCut = 12
+
+// maxSigma keeps the cumulative table of a Gaussian to a few megabytes
+const maxSigma = 1 << 16
+
+// Gaussian samples the discrete Gaussian distribution over the integers,
+// where x has a probability proportional to exp(-x^2 / 2 sigma^2). Papers
+// often give the width s = sqrt(2 pi) sigma instead of sigma.
+//
+// Sampling uses a cumulative distribution table, as FrodoKEM does: |x| is
+// the number of entries of the table below a uniform 63 bits integer,
+// and one more bit is its sign. Every sample reads the whole table, so
+// its time does not depend on the value drawn.
+type Gaussian struct {
+	sigma float64
+	// cdt[i] is 2^63 P(|x| <= i)
+	cdt []uint64
+}
+
+// NewGaussian returns a sampler of the discrete Gaussian of standard
+// deviation sigma
+func NewGaussian(sigma float64) (*Gaussian, error) {
+	if !(sigma > 0) || sigma > maxSigma {
+		return nil, errors.New("lwe: sigma must be in (0, 65536]")
+	}
+	bound := int(math.Ceil(tailCut * sigma))
+	weights := make([]float64, bound+1)
+	total := 0.0
+	for i := range weights {
+		weights[i] = math.Exp(-float64(i*i) / (2 * sigma * sigma))
+		if i > 0 {
+			// both x and -x
+			weights[i] *= 2
+		}
+		total += weights[i]
+	}
+	g := &Gaussian{sigma: sigma, cdt: make([]uint64, bound+1)}
+	sum := 0.0
+	for i, w := range weights {
+		sum += w
+		g.cdt[i] = uint64(math.Min(sum/total*(1<<63), 1<<63))
+	}
+	g.cdt[bound] = 1 << 63
+	return g, nil
+}
+
+// Sigma returns the standard deviation of the sampler
+func (g *Gaussian) Sigma() float64 {
+	return g.sigma
+}
+
+// Sample returns an integer drawn from the distribution, using 8 bytes of
+// random
+func (g *Gaussian) Sample(random io.Reader) (int, error) {
+	var buf [8]byte
+	if _, err := io.ReadFull(random, buf[:]); err != nil {
+		return 0, err
+	}
+	r := binary.BigEndian.Uint64(buf[:])
+	u := r &^ (1 << 63)
+	x := 0
+	for _, c := range g.cdt {
+		// 1 while c <= u, without a branch
+		x += int(1 ^ ((u - c) >> 63))
+	}
+	// the sign bit turns x into -x, 0 stays 0
+	sign := int(r >> 63)
+	return x - 2*sign*x, nil
+}
@@ -0,0 +1,65 @@
+package lwe
+
+import (
+	"crypto/rand"
+	"math"
+	"testing"
+)
+
+func TestGaussian(t *testing.T) {
+	t.Parallel()
+	var testcases = []float64{0.5, 3.2, 40}
+	for i, sigma := range testcases {
+		g, err := NewGaussian(sigma)
+		if err != nil {
+			t.Fatal(err)
+		}
+		const samples = 20000
+		sum, squares := 0.0, 0.0
+		for j := 0; j < samples; j++ {
+			x, err := g.Sample(rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(float64(x)) > tailCut*sigma+1 {
+				t.Fatalf("testcase %d sample %d is beyond the tail", i, x)
+			}
+			sum += float64(x)
+			squares += float64(x * x)
+		}
+		// the mean of 20000 samples is within 5 sigma / sqrt(20000), the
+		// variance within 10%; a narrow discrete Gaussian is a bit wider
+		// than sigma
+		mean := sum / samples
+		if math.Abs(mean) > 5*sigma/math.Sqrt(samples) {
+			t.Fatalf("testcase %d expected a mean close to 0 but got %f", i, mean)
+		}
+		variance := squares/samples - mean*mean
+		if sigma >= 1 && math.Abs(variance/(sigma*sigma)-1) > 0.1 {
+			t.Fatalf("testcase %d expected a variance close to %f but got %f", i, sigma*sigma, variance)
+		}
+	}
+}
+
+func TestGaussianTable(t *testing.T) {
+	t.Parallel()
+	g, err := NewGaussian(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// P(x = 0) = 1 / sum of exp(-x^2/2), about 0.3989
+	p0 := float64(g.cdt[0]) / (1 << 63)
+	if math.Abs(p0-0.398942) > 1e-4 {
+		t.Fatalf("expected P(0) = 0.398942 but got %f", p0)
+	}
+	for i := 1; i < len(g.cdt); i++ {
+		if g.cdt[i] < g.cdt[i-1] {
+			t.Fatalf("table decreases at %d", i)
+		}
+	}
+	for _, sigma := range []float64{0, -1, math.NaN(), 1 << 20} {
+		if _, err := NewGaussian(sigma); err == nil {
+			t.Fatalf("expected sigma %f to be rejected", sigma)
+		}
+	}
+}
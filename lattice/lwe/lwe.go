@@ -0,0 +1,174 @@
+// Package lwe implements Regev's public key encryption from the learning
+// with errors problem (2005), the ancestor of the lattice schemes standing
+// in for RSA and elliptic curves against quantum computers, such as
+// ML-KEM (Kyber).
+//
+// LWE is linear algebra made hard by noise: given a random matrix A and
+// b = As + e mod q, find s. Without the small error e, Gaussian
+// elimination gives s from n equations; with it, the best known
+// algorithms, classical or quantum, are lattice reductions whose cost
+// grows exponentially with n. The public key is (A, b) itself, and a bit
+// is encrypted by summing a random subset of its rows: (u, v) = (sum of
+// the a_i, sum of the b_i + bit q/2). v - <u, s> is then the bit times q/2
+// plus a sum of errors, which stays below q/4 and rounds away.
+//
+// This is the textbook scheme with the parameters of Regev's paper: keys
+// of megabytes and a ciphertext per bit, which Kyber brings down to a
+// kilobyte with structured matrices and error correcting encodings.
+package lwe
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// PublicKey is the matrix A and the vector b = As + e mod q
+type PublicKey struct {
+	Params
+	A [][]uint32
+	B []uint32
+}
+
+// PrivateKey is the secret s
+type PrivateKey struct {
+	PublicKey
+	S []uint32
+}
+
+// Ciphertext encrypts a single bit, v - <u, s> is close to 0 for a 0 and
+// to q/2 for a 1
+type Ciphertext struct {
+	U []uint32
+	V uint32
+}
+
+// GenerateKey returns a key of the given parameters, with a uniform
+// secret and Gaussian errors
+func GenerateKey(random io.Reader, params Params) (*PrivateKey, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	g, err := NewGaussian(params.Sigma)
+	if err != nil {
+		return nil, err
+	}
+	q := uint64(params.Q)
+	priv := &PrivateKey{PublicKey: PublicKey{Params: params}}
+	if priv.S, err = uniform(random, params.Q, params.N); err != nil {
+		return nil, err
+	}
+	priv.A = make([][]uint32, params.M)
+	priv.B = make([]uint32, params.M)
+	for i := range priv.A {
+		if priv.A[i], err = uniform(random, params.Q, params.N); err != nil {
+			return nil, err
+		}
+		e, err := g.Sample(random)
+		if err != nil {
+			return nil, err
+		}
+		b := reduce(int64(e), params.Q)
+		for j, a := range priv.A[i] {
+			b = (b + uint64(a)*uint64(priv.S[j])) % q
+		}
+		priv.B[i] = uint32(b)
+	}
+	return priv, nil
+}
+
+// EncryptBit encrypts the lowest bit of bit
+func EncryptBit(random io.Reader, pub *PublicKey, bit uint) (*Ciphertext, error) {
+	// the rows of the random subset, one bit each
+	subset := make([]byte, (pub.M+7)/8)
+	if _, err := io.ReadFull(random, subset); err != nil {
+		return nil, err
+	}
+	// sums of at most maxM values below 2^31 fit in 64 bits, they are
+	// reduced once at the end
+	q := uint64(pub.Q)
+	u := make([]uint64, pub.N)
+	v := uint64(bit&1) * (q / 2)
+	for i, row := range pub.A {
+		if subset[i/8]>>(uint(i)%8)&1 == 0 {
+			continue
+		}
+		for j, a := range row {
+			u[j] += uint64(a)
+		}
+		v += uint64(pub.B[i])
+	}
+	c := &Ciphertext{U: make([]uint32, pub.N), V: uint32(v % q)}
+	for j := range u {
+		c.U[j] = uint32(u[j] % q)
+	}
+	return c, nil
+}
+
+// DecryptBit returns the bit encrypted in c, 0 or 1
+func (priv *PrivateKey) DecryptBit(c *Ciphertext) uint {
+	q := uint64(priv.Q)
+	d := uint64(c.V)
+	for j, u := range c.U {
+		d = (d + q - uint64(u)*uint64(priv.S[j])%q) % q
+	}
+	// 1 if d is closer to q/2 than to 0
+	if d > q/4 && d < q-q/4 {
+		return 1
+	}
+	return 0
+}
+
+// Encrypt encrypts msg one bit at a time, the most significant bit of each
+// byte first
+func Encrypt(random io.Reader, pub *PublicKey, msg []byte) ([]*Ciphertext, error) {
+	cs := make([]*Ciphertext, 0, 8*len(msg))
+	for _, b := range msg {
+		for i := 7; i >= 0; i-- {
+			c, err := EncryptBit(random, pub, uint(b>>uint(i)))
+			if err != nil {
+				return nil, err
+			}
+			cs = append(cs, c)
+		}
+	}
+	return cs, nil
+}
+
+// Decrypt returns the message encrypted by Encrypt
+func (priv *PrivateKey) Decrypt(cs []*Ciphertext) ([]byte, error) {
+	if len(cs)%8 != 0 {
+		return nil, errors.New("lwe: ciphertexts are not whole bytes")
+	}
+	msg := make([]byte, len(cs)/8)
+	for i, c := range cs {
+		if len(c.U) != priv.N || c.V >= priv.Q {
+			return nil, errors.New("lwe: malformed ciphertext")
+		}
+		msg[i/8] |= byte(priv.DecryptBit(c)) << uint(7-i%8)
+	}
+	return msg, nil
+}
+
+// uniform returns n integers uniform modulo q
+func uniform(random io.Reader, q uint32, n int) ([]uint32, error) {
+	// 64 bits reduced modulo q < 2^31 have a negligible bias
+	buf := make([]byte, 8*n)
+	if _, err := io.ReadFull(random, buf); err != nil {
+		return nil, err
+	}
+	v := make([]uint32, n)
+	for i := range v {
+		v[i] = uint32(binary.BigEndian.Uint64(buf[8*i:]) % uint64(q))
+	}
+	return v, nil
+}
+
+// reduce returns x mod q in [0, q)
+func reduce(x int64, q uint32) uint64 {
+	r := x % int64(q)
+	if r < 0 {
+		r += int64(q)
+	}
+	return uint64(r)
+}
@@ -0,0 +1,83 @@
+package lwe
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestEncrypt(t *testing.T) {
+	t.Parallel()
+	var testcases = []int{16, 64}
+	for i, n := range testcases {
+		params, err := NewParams(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		priv, err := GenerateKey(rand.Reader, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := []byte("learning with errors")
+		cs, err := Encrypt(rand.Reader, &priv.PublicKey, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := priv.Decrypt(cs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, msg) {
+			t.Fatalf("testcase %d expected %q but got %q", i, msg, got)
+		}
+		if _, err := priv.Decrypt(cs[1:]); err == nil {
+			t.Fatalf("testcase %d expected a partial byte to be rejected", i)
+		}
+	}
+}
+
+func TestKeyIsLWE(t *testing.T) {
+	t.Parallel()
+	params, _ := NewParams(32)
+	priv, err := GenerateKey(rand.Reader, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// b - As is small, all within the tail of the Gaussian
+	q := int64(params.Q)
+	for i, row := range priv.A {
+		e := int64(priv.B[i])
+		for j, a := range row {
+			e = (e - int64(a)*int64(priv.S[j])) % q
+		}
+		e = (e + q) % q
+		if e > q/2 {
+			e -= q
+		}
+		if float64(e) > tailCut*params.Sigma+1 || float64(-e) > tailCut*params.Sigma+1 {
+			t.Fatalf("row %d has an error of %d", i, e)
+		}
+	}
+}
+
+func TestNoise(t *testing.T) {
+	t.Parallel()
+	// errors as large as q/4 make decryption a coin toss
+	params, _ := NewParams(16)
+	params.Sigma = float64(params.Q)
+	priv, err := GenerateKey(rand.Reader, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrong := 0
+	for i := 0; i < 200; i++ {
+		c, err := EncryptBit(rand.Reader, &priv.PublicKey, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wrong += int(1 - priv.DecryptBit(c))
+	}
+	if wrong < 50 || wrong > 150 {
+		t.Fatalf("expected about 100 wrong bits but got %d", wrong)
+	}
+}
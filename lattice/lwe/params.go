@@ -0,0 +1,88 @@
+package lwe
+
+import (
+	"errors"
+	"math"
+	"math/big"
+)
+
+// maxM bounds the number of samples of a key, and so the sums of
+// EncryptBit below 2^55
+const maxM = 1 << 24
+
+// Params are the parameters of LWE
+type Params struct {
+	// N is the dimension of the secret, the security parameter
+	N int
+	// M is the number of samples in the public key
+	M int
+	// Q is the modulus, below 2^31
+	Q uint32
+	// Sigma is the standard deviation of the errors
+	Sigma float64
+}
+
+// NewParams returns the parameters of Regev's paper for the dimension n:
+// Q the smallest prime above n^2, M = 1.1 (n+1) log2(Q), and errors of
+// width alpha Q with alpha = 1 / (sqrt(n) log2(n)^2). Regev proved that
+// solving LWE with them is as hard as approximating short vectors in
+// lattices of dimension n within a factor of about n^1.5, for a quantum
+// computer.
+func NewParams(n int) (Params, error) {
+	if n < 4 || n > 1<<15 {
+		return Params{}, errors.New("lwe: dimension must be in [4, 32768]")
+	}
+	q := new(big.Int).Mul(big.NewInt(int64(n)), big.NewInt(int64(n)))
+	for !q.ProbablyPrime(20) {
+		q.Add(q, big.NewInt(1))
+	}
+	logQ := math.Log2(float64(q.Int64()))
+	logN := math.Log2(float64(n))
+	alpha := 1 / (math.Sqrt(float64(n)) * logN * logN)
+	return Params{
+		N:     n,
+		M:     int(math.Ceil(1.1 * float64(n+1) * logQ)),
+		Q:     uint32(q.Int64()),
+		Sigma: alpha * float64(q.Int64()) / math.Sqrt(2*math.Pi),
+	}, nil
+}
+
+// Validate returns an error if the parameters are out of the range this
+// package handles
+func (p Params) Validate() error {
+	switch {
+	case p.N < 1 || p.N > maxM:
+		return errors.New("lwe: dimension out of range")
+	case p.M < 1 || p.M > maxM:
+		return errors.New("lwe: number of samples out of range")
+	case p.Q < 4 || p.Q >= 1<<31:
+		return errors.New("lwe: modulus must be in [4, 2^31)")
+	case !(p.Sigma > 0) || p.Sigma > maxSigma:
+		return errors.New("lwe: sigma out of range")
+	}
+	return nil
+}
+
+// FailureProbability returns the probability that a bit decrypts wrong.
+// The error of a ciphertext is the sum of the errors of about M/2 rows,
+// a Gaussian of standard deviation sigma sqrt(M/2), and decryption fails
+// when it exceeds Q/4.
+func (p Params) FailureProbability() float64 {
+	return math.Erfc(float64(p.Q) / (4 * p.Sigma * math.Sqrt(float64(p.M))))
+}
+
+// PublicKeySize returns the size in bytes of A and b, packed with
+// log2(Q) bits per coefficient
+func (p Params) PublicKeySize() int {
+	return (p.M*(p.N+1)*p.bits() + 7) / 8
+}
+
+// CiphertextSize returns the size in bytes of the ciphertext of one bit
+func (p Params) CiphertextSize() int {
+	return ((p.N+1)*p.bits() + 7) / 8
+}
+
+// bits returns the number of bits of a coefficient
+func (p Params) bits() int {
+	return int(math.Ceil(math.Log2(float64(p.Q))))
+}
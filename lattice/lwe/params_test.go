@@ -0,0 +1,60 @@
+package lwe
+
+import (
+	"testing"
+)
+
+func TestNewParams(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		n       int
+		q       uint32
+		maxFail float64
+	}{
+		{16, 257, 1e-4},
+		{128, 16411, 1e-20},
+		{256, 65537, 1e-30},
+	}
+	for i, tc := range testcases {
+		p, err := NewParams(tc.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		if p.Q != tc.q {
+			t.Fatalf("testcase %d expected q = %d but got %d", i, tc.q, p.Q)
+		}
+		if f := p.FailureProbability(); f > tc.maxFail {
+			t.Fatalf("testcase %d expected a failure probability below %g but got %g", i, tc.maxFail, f)
+		}
+	}
+	if _, err := NewParams(2); err == nil {
+		t.Fatal("expected dimension 2 to be rejected")
+	}
+}
+
+func TestSizes(t *testing.T) {
+	t.Parallel()
+	p := Params{N: 256, M: 4096, Q: 65537, Sigma: 4}
+	// 17 bits per coefficient
+	if s := p.CiphertextSize(); s != (257*17+7)/8 {
+		t.Fatalf("expected a ciphertext of %d bytes but got %d", (257*17+7)/8, s)
+	}
+	if s := p.PublicKeySize(); s != 4096*257*17/8 {
+		t.Fatalf("expected a public key of %d bytes but got %d", 4096*257*17/8, s)
+	}
+	var invalid = []Params{
+		{N: 0, M: 10, Q: 257, Sigma: 1},
+		{N: 10, M: 0, Q: 257, Sigma: 1},
+		{N: 10, M: 10, Q: 2, Sigma: 1},
+		{N: 10, M: 10, Q: 1 << 31, Sigma: 1},
+		{N: 10, M: 10, Q: 257, Sigma: 0},
+	}
+	for i, p := range invalid {
+		if p.Validate() == nil {
+			t.Fatalf("testcase %d expected an error", i)
+		}
+	}
+}
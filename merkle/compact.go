@@ -0,0 +1,94 @@
+package merkle
+
+import "fmt"
+
+// CompactTree tracks the root of an append only tree while keeping only
+// the roots of its perfect subtrees, one per bit set in the tree size.
+// It is what a monitor needs to check that the entries it downloads
+// produce the tree heads a log publishes.
+type CompactTree struct {
+	size uint64
+	// roots of the perfect subtrees, from the largest on the left to
+	// the smallest on the right
+	roots [][]byte
+}
+
+// NewCompactFromInclusion rebuilds the compact form of a tree of size
+// leaves from the hash of its last leaf and the inclusion proof of that
+// leaf: the audit path of the rightmost leaf holds exactly the roots of
+// the perfect subtrees on its left. The caller must check the Root of the
+// result against a trusted tree head.
+func NewCompactFromInclusion(size uint64, lastLeafHash []byte, proof [][]byte) (*CompactTree, error) {
+	if size == 0 {
+		return nil, fmt.Errorf("merkle: empty tree has no last leaf")
+	}
+	// the lowest set bit of size is the height of the perfect subtree
+	// containing the last leaf, whose siblings come first in the proof
+	height := 0
+	for size>>uint(height)&1 == 0 {
+		height++
+	}
+	if len(proof) < height {
+		return nil, ErrProofSize
+	}
+	r := lastLeafHash
+	for _, p := range proof[:height] {
+		r = NodeHash(p, r)
+	}
+	rest := proof[height:]
+	ones := 0
+	for s := size >> uint(height+1); s != 0; s >>= 1 {
+		if s&1 == 1 {
+			ones++
+		}
+	}
+	if len(rest) != ones {
+		return nil, ErrProofSize
+	}
+	ct := &CompactTree{size: size}
+	for i := len(rest) - 1; i >= 0; i-- {
+		ct.roots = append(ct.roots, rest[i])
+	}
+	ct.roots = append(ct.roots, r)
+	return ct, nil
+}
+
+// Size returns the number of leaves in the tree
+func (ct *CompactTree) Size() uint64 {
+	return ct.size
+}
+
+// Clone returns an independent copy of the tree
+func (ct *CompactTree) Clone() *CompactTree {
+	return &CompactTree{size: ct.size, roots: append([][]byte(nil), ct.roots...)}
+}
+
+// AppendHash adds a leaf by its hash
+func (ct *CompactTree) AppendHash(leafHash []byte) {
+	ct.roots = append(ct.roots, leafHash)
+	// every trailing one bit of the old size is a subtree of the same
+	// height as the new one, merge them
+	for s := ct.size; s&1 == 1; s >>= 1 {
+		n := len(ct.roots)
+		ct.roots[n-2] = NodeHash(ct.roots[n-2], ct.roots[n-1])
+		ct.roots = ct.roots[:n-1]
+	}
+	ct.size++
+}
+
+// Append adds a leaf with the given data
+func (ct *CompactTree) Append(data []byte) {
+	ct.AppendHash(LeafHash(data))
+}
+
+// Root returns the root hash of the tree
+func (ct *CompactTree) Root() []byte {
+	if len(ct.roots) == 0 {
+		return EmptyRoot()
+	}
+	r := ct.roots[len(ct.roots)-1]
+	for i := len(ct.roots) - 2; i >= 0; i-- {
+		r = NodeHash(ct.roots[i], r)
+	}
+	return r
+}
@@ -0,0 +1,260 @@
+// Package merkle implements the Merkle hash trees of RFC 6962 and RFC 9162
+// used by Certificate Transparency: leaf and node hashing with distinct
+// prefixes, proof generation over an in memory tree, and verification of
+// inclusion and consistency proofs.
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// HashSize is the size of the tree hashes
+const HashSize = sha256.Size
+
+var (
+	// ErrRootMismatch is returned when a proof does not lead to the
+	// expected root hash
+	ErrRootMismatch = errors.New("merkle: calculated root does not match")
+	// ErrProofSize is returned when a proof has the wrong number of hashes
+	ErrProofSize = errors.New("merkle: wrong proof size")
+)
+
+// EmptyRoot returns the root hash of the empty tree, the hash of an empty
+// string
+func EmptyRoot() []byte {
+	h := sha256.Sum256(nil)
+	return h[:]
+}
+
+// LeafHash returns the hash of a leaf, SHA-256(0x00 || data)
+func LeafHash(data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// NodeHash returns the hash of an interior node, SHA-256(0x01 || left || right)
+func NodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{1})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// Tree is an append only tree that keeps every leaf hash in memory to
+// serve proofs for any of its past sizes
+type Tree struct {
+	leaves [][]byte
+}
+
+// Append adds a leaf with the given data and returns its index
+func (t *Tree) Append(data []byte) uint64 {
+	return t.AppendHash(LeafHash(data))
+}
+
+// AppendHash adds a leaf by its hash and returns its index
+func (t *Tree) AppendHash(leafHash []byte) uint64 {
+	t.leaves = append(t.leaves, leafHash)
+	return uint64(len(t.leaves) - 1)
+}
+
+// Size returns the number of leaves in the tree
+func (t *Tree) Size() uint64 {
+	return uint64(len(t.leaves))
+}
+
+// LeafHash returns the hash of the leaf at index
+func (t *Tree) LeafHash(index uint64) []byte {
+	return t.leaves[index]
+}
+
+// Root returns the current root hash
+func (t *Tree) Root() []byte {
+	return subtreeHash(t.leaves)
+}
+
+// RootAt returns the root hash the tree had when it held size leaves
+func (t *Tree) RootAt(size uint64) ([]byte, error) {
+	if size > t.Size() {
+		return nil, fmt.Errorf("merkle: size %d is larger than the tree", size)
+	}
+	return subtreeHash(t.leaves[:size]), nil
+}
+
+// InclusionProof returns the audit path of the leaf at index in the tree
+// of the given size, from the leaf up
+func (t *Tree) InclusionProof(index, size uint64) ([][]byte, error) {
+	if size > t.Size() || index >= size {
+		return nil, fmt.Errorf("merkle: no leaf %d in a tree of size %d", index, size)
+	}
+	return path(index, t.leaves[:size]), nil
+}
+
+// ConsistencyProof returns the proof that the tree of size first is a
+// prefix of the tree of size second
+func (t *Tree) ConsistencyProof(first, second uint64) ([][]byte, error) {
+	if first > second || second > t.Size() {
+		return nil, fmt.Errorf("merkle: invalid consistency range %d to %d", first, second)
+	}
+	if first == 0 || first == second {
+		return nil, nil
+	}
+	return subproof(first, t.leaves[:second], true), nil
+}
+
+// split returns the largest power of two smaller than n
+func split(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+// subtreeHash is MTH of RFC 6962 section 2.1
+func subtreeHash(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		return EmptyRoot()
+	case 1:
+		return leaves[0]
+	}
+	k := split(len(leaves))
+	return NodeHash(subtreeHash(leaves[:k]), subtreeHash(leaves[k:]))
+}
+
+// path is PATH of RFC 6962 section 2.1.1
+func path(m uint64, leaves [][]byte) [][]byte {
+	if len(leaves) <= 1 {
+		return nil
+	}
+	k := split(len(leaves))
+	if m < uint64(k) {
+		return append(path(m, leaves[:k]), subtreeHash(leaves[k:]))
+	}
+	return append(path(m-uint64(k), leaves[k:]), subtreeHash(leaves[:k]))
+}
+
+// subproof is SUBPROOF of RFC 6962 section 2.1.2
+func subproof(m uint64, leaves [][]byte, complete bool) [][]byte {
+	n := uint64(len(leaves))
+	if m == n {
+		if complete {
+			return nil
+		}
+		return [][]byte{subtreeHash(leaves)}
+	}
+	k := uint64(split(len(leaves)))
+	if m <= k {
+		return append(subproof(m, leaves[:k], complete), subtreeHash(leaves[k:]))
+	}
+	return append(subproof(m-k, leaves[k:], false), subtreeHash(leaves[:k]))
+}
+
+// RootFromInclusionProof computes the root hash implied by an inclusion
+// proof, following RFC 9162 section 2.1.3.2
+func RootFromInclusionProof(index, size uint64, leafHash []byte, proof [][]byte) ([]byte, error) {
+	if index >= size {
+		return nil, fmt.Errorf("merkle: index %d is beyond tree size %d", index, size)
+	}
+	fn, sn := index, size-1
+	r := leafHash
+	for _, p := range proof {
+		if sn == 0 {
+			return nil, ErrProofSize
+		}
+		if fn&1 == 1 || fn == sn {
+			r = NodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = NodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return nil, ErrProofSize
+	}
+	return r, nil
+}
+
+// VerifyInclusion checks that the leaf with the given hash is at index
+// in the tree of the given size and root hash
+func VerifyInclusion(index, size uint64, leafHash []byte, proof [][]byte, root []byte) error {
+	r, err := RootFromInclusionProof(index, size, leafHash, proof)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(r, root) {
+		return ErrRootMismatch
+	}
+	return nil
+}
+
+// VerifyConsistency checks that the tree of size first and root hash
+// firstRoot is a prefix of the tree of size second and root hash
+// secondRoot, following RFC 9162 section 2.1.4.2
+func VerifyConsistency(first, second uint64, firstRoot, secondRoot []byte, proof [][]byte) error {
+	switch {
+	case first > second:
+		return fmt.Errorf("merkle: tree shrank from %d to %d", first, second)
+	case first == second:
+		if len(proof) != 0 {
+			return ErrProofSize
+		}
+		if !bytes.Equal(firstRoot, secondRoot) {
+			return ErrRootMismatch
+		}
+		return nil
+	case first == 0:
+		// the empty tree is a prefix of every tree
+		if len(proof) != 0 {
+			return ErrProofSize
+		}
+		return nil
+	case len(proof) == 0:
+		return ErrProofSize
+	}
+	if first&(first-1) == 0 {
+		// the first tree is a complete subtree, its root starts the proof
+		proof = append([][]byte{firstRoot}, proof...)
+	}
+	fn, sn := first-1, second-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return ErrProofSize
+		}
+		if fn&1 == 1 || fn == sn {
+			fr = NodeHash(c, fr)
+			sr = NodeHash(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = NodeHash(sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return ErrProofSize
+	}
+	if !bytes.Equal(fr, firstRoot) || !bytes.Equal(sr, secondRoot) {
+		return ErrRootMismatch
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// leaves and roots of the reference test tree of the certificate
+// transparency project
+var referenceLeaves = []string{"", "00", "10", "2021", "3031", "40414243", "5051525354555657", "606162636465666768696a6b6c6d6e6f"}
+
+var referenceRoots = []string{
+	"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+	"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+	"aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77",
+	"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+	"4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4",
+	"76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef",
+	"ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c",
+	"5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+}
+
+func referenceTree(t *testing.T) *Tree {
+	tree := new(Tree)
+	for _, l := range referenceLeaves {
+		data, err := hex.DecodeString(l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tree.Append(data)
+	}
+	return tree
+}
+
+func TestRoots(t *testing.T) {
+	t.Parallel()
+	tree := referenceTree(t)
+	if root, _ := tree.RootAt(0); !bytes.Equal(root, EmptyRoot()) {
+		t.Fatalf("expected empty root but got %x", root)
+	}
+	ct := new(CompactTree)
+	for i, expected := range referenceRoots {
+		root, err := tree.RootAt(uint64(i + 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(root) != expected {
+			t.Fatalf("testcase %d expected root %s but got %x", i, expected, root)
+		}
+		ct.AppendHash(tree.LeafHash(uint64(i)))
+		if hex.EncodeToString(ct.Root()) != expected {
+			t.Fatalf("testcase %d expected compact root %s but got %x", i, expected, ct.Root())
+		}
+	}
+}
+
+func bigTree(n int) *Tree {
+	tree := new(Tree)
+	for i := 0; i < n; i++ {
+		tree.Append([]byte{byte(i), byte(i >> 8), 0x42})
+	}
+	return tree
+}
+
+func TestInclusion(t *testing.T) {
+	t.Parallel()
+	tree := bigTree(70)
+	for size := uint64(1); size <= tree.Size(); size++ {
+		root, _ := tree.RootAt(size)
+		for index := uint64(0); index < size; index++ {
+			proof, err := tree.InclusionProof(index, size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			leaf := tree.LeafHash(index)
+			if err := VerifyInclusion(index, size, leaf, proof, root); err != nil {
+				t.Fatalf("expected proof of %d in %d to verify but got %v", index, size, err)
+			}
+			if VerifyInclusion(index, size, tree.LeafHash((index+1)%tree.Size()), proof, root) == nil {
+				t.Fatalf("expected proof of %d in %d to fail for another leaf", index, size)
+			}
+			if len(proof) > 0 {
+				if VerifyInclusion(index, size, leaf, proof[:len(proof)-1], root) == nil {
+					t.Fatalf("expected truncated proof of %d in %d to fail", index, size)
+				}
+			}
+		}
+	}
+}
+
+func TestConsistency(t *testing.T) {
+	t.Parallel()
+	tree := bigTree(40)
+	for second := uint64(0); second <= tree.Size(); second++ {
+		secondRoot, _ := tree.RootAt(second)
+		for first := uint64(0); first <= second; first++ {
+			firstRoot, _ := tree.RootAt(first)
+			proof, err := tree.ConsistencyProof(first, second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := VerifyConsistency(first, second, firstRoot, secondRoot, proof); err != nil {
+				t.Fatalf("expected consistency from %d to %d to verify but got %v", first, second, err)
+			}
+			if first == 0 || first == second {
+				continue
+			}
+			if VerifyConsistency(first, second, secondRoot, secondRoot, proof) == nil {
+				t.Fatalf("expected consistency from %d to %d to fail with a wrong first root", first, second)
+			}
+			if VerifyConsistency(first, second, firstRoot, firstRoot, proof) == nil {
+				t.Fatalf("expected consistency from %d to %d to fail with a wrong second root", first, second)
+			}
+			if VerifyConsistency(first-1, second, firstRoot, secondRoot, proof) == nil {
+				t.Fatalf("expected consistency from %d to %d to fail with a wrong size", first, second)
+			}
+		}
+	}
+}
+
+func TestCompactFromInclusion(t *testing.T) {
+	t.Parallel()
+	tree := bigTree(50)
+	for size := uint64(1); size < tree.Size(); size++ {
+		proof, err := tree.InclusionProof(size-1, size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ct, err := NewCompactFromInclusion(size, tree.LeafHash(size-1), proof)
+		if err != nil {
+			t.Fatalf("size %d failed with %v", size, err)
+		}
+		root, _ := tree.RootAt(size)
+		if !bytes.Equal(ct.Root(), root) {
+			t.Fatalf("size %d expected root %x but got %x", size, root, ct.Root())
+		}
+		// the rebuilt tree keeps growing like the full one
+		for i := size; i < tree.Size(); i++ {
+			ct.AppendHash(tree.LeafHash(i))
+		}
+		if !bytes.Equal(ct.Root(), tree.Root()) {
+			t.Fatalf("size %d expected grown root %x but got %x", size, tree.Root(), ct.Root())
+		}
+	}
+}
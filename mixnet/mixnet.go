@@ -0,0 +1,139 @@
+// Package mixnet implements a re-encryption mix network of ElGamal
+// ciphertexts, with a non interactive proof that each mix server shuffled
+// correctly, for toy electronic voting.
+//
+// Voters encrypt their ballots to a key shared by trustees, for instance
+// with the dkg package. Each mix server in turn re-encrypts every
+// ciphertext and permutes them: the output decrypts to the same ballots,
+// but cannot be linked to the input without the key. As long as one
+// server keeps its permutation secret, no one can tell who cast which
+// ballot, and a quorum of trustees decrypts the last output.
+//
+// A server could also drop or replace ballots, so it publishes a proof of
+// shuffle. The proof is that of Terelius and Wikstrom (2010), as
+// specified in pseudo code by Haenni, Locher, Koenig and Dubuis (2017),
+// on elliptic curves and with Fiat-Shamir challenges: the server commits
+// to its permutation matrix with Pedersen commitments to independent
+// generators H_i, and proves that the committed matrix is a permutation,
+// because its rows sum to one and its product with a random vector u
+// permutes u, and that the output is the input multiplied by that
+// matrix and re-encrypted. This is the lite version: it proves the
+// shuffle of a single list, without the offline precomputation of
+// Verificatum, and hashes to the curve with try-and-increment.
+package mixnet
+
+import (
+	cryptorand "crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/elgamal"
+)
+
+// Shuffle re-encrypts cts with pub, permutes them at random, and returns
+// the shuffled ciphertexts with the proof of shuffle
+func Shuffle(rand io.Reader, pub *elgamal.PublicKey, cts []*elgamal.Ciphertext) ([]*elgamal.Ciphertext, *Proof, error) {
+	if len(cts) == 0 {
+		return nil, nil, errors.New("mixnet: nothing to shuffle")
+	}
+	c := pub.Curve
+	for _, ct := range cts {
+		if ct == nil || !c.IsOnCurve(ct.C1) || !c.IsOnCurve(ct.C2) {
+			return nil, nil, errors.New("mixnet: ciphertext is not made of points of the curve")
+		}
+	}
+	perm, err := permutation(rand, len(cts))
+	if err != nil {
+		return nil, nil, err
+	}
+	out := make([]*elgamal.Ciphertext, len(cts))
+	randomness := make([]*big.Int, len(cts))
+	for i, j := range perm {
+		r, err := c.RandomScalar(rand)
+		if err != nil {
+			return nil, nil, err
+		}
+		out[i] = reEncrypt(pub, cts[j], r)
+		randomness[i] = r
+	}
+	proof, err := prove(rand, pub, cts, out, perm, randomness)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, proof, nil
+}
+
+// reEncrypt adds the encryption of the point at infinity with randomness r
+// to ct
+func reEncrypt(pub *elgamal.PublicKey, ct *elgamal.Ciphertext, r *big.Int) *elgamal.Ciphertext {
+	c := pub.Curve
+	return &elgamal.Ciphertext{
+		C1: c.Add(ct.C1, c.ScalarBaseMult(r)),
+		C2: c.Add(ct.C2, c.ScalarMult(pub.Y, r)),
+	}
+}
+
+// permutation returns a uniformly random permutation of [0, n) with a
+// Fisher-Yates shuffle, perm[i] being the input that goes to output i
+func permutation(rand io.Reader, n int) ([]int, error) {
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	for i := n - 1; i > 0; i-- {
+		j, err := cryptorand.Int(rand, big.NewInt(int64(i+1)))
+		if err != nil {
+			return nil, err
+		}
+		perm[i], perm[j.Int64()] = perm[j.Int64()], perm[i]
+	}
+	return perm, nil
+}
+
+// Mix runs cts through a cascade of servers, each shuffling the output
+// of the previous one, and returns the output of every server with its
+// proof. It simulates in memory what would be servers run by distinct
+// parties.
+func Mix(rand io.Reader, pub *elgamal.PublicKey, cts []*elgamal.Ciphertext, servers int) ([][]*elgamal.Ciphertext, []*Proof, error) {
+	outputs := make([][]*elgamal.Ciphertext, servers)
+	proofs := make([]*Proof, servers)
+	in := cts
+	for s := 0; s < servers; s++ {
+		out, proof, err := Shuffle(rand, pub, in)
+		if err != nil {
+			return nil, nil, err
+		}
+		outputs[s], proofs[s] = out, proof
+		in = out
+	}
+	return outputs, proofs, nil
+}
+
+// VerifyMix checks the proof of every server of a cascade that started
+// from cts
+func VerifyMix(pub *elgamal.PublicKey, cts []*elgamal.Ciphertext, outputs [][]*elgamal.Ciphertext, proofs []*Proof) bool {
+	if len(outputs) == 0 || len(outputs) != len(proofs) {
+		return false
+	}
+	in := cts
+	for s, out := range outputs {
+		if !Verify(pub, in, out, proofs[s]) {
+			return false
+		}
+		in = out
+	}
+	return true
+}
+
+// generators returns H and H_1..H_n, points whose discrete logarithms
+// relative to G and to each other are unknown
+func generators(c *ec.Curve, n int) (*ec.Point, []*ec.Point) {
+	h := c.HashToPoint("badcrypto mixnet generator", nil)
+	hs := make([]*ec.Point, n)
+	for i := range hs {
+		hs[i] = c.HashToPoint("badcrypto mixnet generator", big.NewInt(int64(i+1)).Bytes())
+	}
+	return h, hs
+}
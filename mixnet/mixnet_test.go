@@ -0,0 +1,169 @@
+package mixnet
+
+import (
+	"crypto/rand"
+	"sort"
+	"testing"
+
+	"github.com/jvehent/badcrypto/dkg"
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/elgamal"
+)
+
+func encrypt(t *testing.T, pub *elgamal.PublicKey, votes []int) []*elgamal.Ciphertext {
+	cts := make([]*elgamal.Ciphertext, len(votes))
+	for i, v := range votes {
+		ct, err := pub.Encrypt(rand.Reader, elgamal.EncodeInt(pub.Curve, v))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cts[i] = ct
+	}
+	return cts
+}
+
+func TestShuffle(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		curve *ec.Curve
+		votes []int
+	}{
+		{ec.P256(), []int{7}},
+		{ec.P256(), []int{1, 2}},
+		{ec.Secp256k1(), []int{3, 1, 4, 1, 5}},
+	}
+	for i, tc := range testcases {
+		priv, err := elgamal.GenerateKey(rand.Reader, tc.curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+		in := encrypt(t, &priv.PublicKey, tc.votes)
+		out, proof, err := Shuffle(rand.Reader, &priv.PublicKey, in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(&priv.PublicKey, in, out, proof) {
+			t.Fatalf("testcase %d expected the proof of shuffle to verify", i)
+		}
+		var got []int
+		for _, ct := range out {
+			for _, old := range in {
+				if ct.C1.Equal(old.C1) {
+					t.Fatalf("testcase %d expected every ciphertext to be re-encrypted", i)
+				}
+			}
+			m, err := priv.Decrypt(ct)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := elgamal.DecodeInt(tc.curve, m, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, v)
+		}
+		want := append([]int{}, tc.votes...)
+		sort.Ints(want)
+		sort.Ints(got)
+		for j := range want {
+			if got[j] != want[j] {
+				t.Fatalf("testcase %d expected the votes %v but got %v", i, want, got)
+			}
+		}
+	}
+}
+
+func TestVote(t *testing.T) {
+	t.Parallel()
+	c := ec.P256()
+	trustees, err := dkg.Generate(rand.Reader, c, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := elgamal.ThresholdPublicKey(trustees[0])
+	// each voter picks one of three candidates
+	votes := []int{0, 2, 1, 2, 2, 0}
+	ballots := encrypt(t, pub, votes)
+	outputs, proofs, err := Mix(rand.Reader, pub, ballots, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyMix(pub, ballots, outputs, proofs) {
+		t.Fatal("expected the proofs of the cascade to verify")
+	}
+	tally := make([]int, 3)
+	for _, ct := range outputs[len(outputs)-1] {
+		var partials []*elgamal.PartialDecryption
+		for _, trustee := range trustees[1:] {
+			pd, err := elgamal.PartialDecrypt(rand.Reader, trustee, ct)
+			if err != nil {
+				t.Fatal(err)
+			}
+			partials = append(partials, pd)
+		}
+		m, err := elgamal.Combine(c, trustees[0].VerificationShares, ct, partials)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := elgamal.DecodeInt(c, m, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tally[v]++
+	}
+	if tally[0] != 2 || tally[1] != 1 || tally[2] != 3 {
+		t.Fatalf("expected a tally of [2 1 3] but got %v", tally)
+	}
+	// a cascade checked from other ballots
+	if VerifyMix(pub, outputs[0], outputs, proofs) {
+		t.Fatal("expected the cascade to fail from other ballots")
+	}
+}
+
+func TestInvalidShuffle(t *testing.T) {
+	t.Parallel()
+	c := ec.P256()
+	priv, err := elgamal.GenerateKey(rand.Reader, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := &priv.PublicKey
+	in := encrypt(t, pub, []int{1, 0, 1, 1})
+	out, proof, err := Shuffle(rand.Reader, pub, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a server that replaces a ballot with its own
+	forged := append([]*elgamal.Ciphertext{}, out...)
+	forged[2] = encrypt(t, pub, []int{0})[0]
+	if Verify(pub, in, forged, proof) {
+		t.Fatal("expected a replaced ballot to fail")
+	}
+	// or that shifts the vote of a ballot
+	shifted, err := pub.Encrypt(rand.Reader, c.Generator())
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged[2] = pub.Add(out[2], shifted)
+	if Verify(pub, in, forged, proof) {
+		t.Fatal("expected a shifted ballot to fail")
+	}
+	// or that drops a ballot
+	if Verify(pub, in, out[1:], proof) {
+		t.Fatal("expected a dropped ballot to fail")
+	}
+	// a proof checked against other inputs
+	other := encrypt(t, pub, []int{1, 0, 1, 1})
+	if Verify(pub, other, out, proof) {
+		t.Fatal("expected the proof to fail for other inputs")
+	}
+	// a proof with a tampered response
+	proof.SPerm[0].Add(proof.SPerm[0], proof.SPerm[1]).Mod(proof.SPerm[0], c.N)
+	if Verify(pub, in, out, proof) {
+		t.Fatal("expected a tampered proof to fail")
+	}
+	if _, _, err := Shuffle(rand.Reader, pub, nil); err == nil {
+		t.Fatal("expected an error for an empty list")
+	}
+}
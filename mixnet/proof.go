@@ -0,0 +1,298 @@
+package mixnet
+
+import (
+	"encoding/binary"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/elgamal"
+)
+
+// Proof is a proof of shuffle of n ciphertexts. Commitments are the
+// commitments c_j = r_j*G + H_i to the permutation matrix, one per input
+// j that goes to output i, and Chain the commitments ĉ_i that link the
+// permuted challenges. T and S are the commitments and responses of the
+// sigma protocol that proves the relations between them, made non
+// interactive by hashing the statement and the commitments.
+type Proof struct {
+	Commitments []*ec.Point
+	Chain       []*ec.Point
+	T1, T2, T3  *ec.Point
+	T41, T42    *ec.Point
+	TChain      []*ec.Point
+	S1, S2      *big.Int
+	S3, S4      *big.Int
+	SChain      []*big.Int
+	SPerm       []*big.Int
+}
+
+// statement serializes what a proof of shuffle is about, to bind its
+// challenges to the key and to both lists of ciphertexts
+func statement(pub *elgamal.PublicKey, in, out []*elgamal.Ciphertext) [][]byte {
+	c := pub.Curve
+	msg := [][]byte{c.Marshal(pub.Y)}
+	for _, cts := range [][]*elgamal.Ciphertext{in, out} {
+		for _, ct := range cts {
+			msg = append(msg, c.Marshal(ct.C1), c.Marshal(ct.C2))
+		}
+	}
+	return msg
+}
+
+// challenges returns the vector u, one challenge per input, that the
+// verifier would pick once the permutation is committed
+func challenges(c *ec.Curve, msg [][]byte, commitments []*ec.Point) []*big.Int {
+	for _, p := range commitments {
+		msg = append(msg, c.Marshal(p))
+	}
+	u := make([]*big.Int, len(commitments))
+	for j := range u {
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], uint32(j))
+		u[j] = c.HashToScalar("badcrypto mixnet challenge u", append(msg, b[:])...)
+	}
+	return u
+}
+
+// challenge returns the challenge of the sigma protocol
+func challenge(c *ec.Curve, msg [][]byte, p *Proof) *big.Int {
+	points := append(append([]*ec.Point{}, p.Commitments...), p.Chain...)
+	points = append(points, p.T1, p.T2, p.T3, p.T41, p.T42)
+	points = append(points, p.TChain...)
+	for _, pt := range points {
+		msg = append(msg, c.Marshal(pt))
+	}
+	return c.HashToScalar("badcrypto mixnet challenge", msg...)
+}
+
+// prove returns the proof that out[i] is in[perm[i]] re-encrypted with
+// randomness[i]
+func prove(rand io.Reader, pub *elgamal.PublicKey, in, out []*elgamal.Ciphertext, perm []int, randomness []*big.Int) (*Proof, error) {
+	c := pub.Curve
+	n := len(in)
+	h, hs := generators(c, n)
+	msg := statement(pub, in, out)
+	mod := func(x *big.Int) *big.Int { return x.Mod(x, c.N) }
+	mul := func(a, b *big.Int) *big.Int { return mod(new(big.Int).Mul(a, b)) }
+	random := func() ([]*big.Int, error) {
+		s := make([]*big.Int, n)
+		for i := range s {
+			var err error
+			if s[i], err = c.RandomScalar(rand); err != nil {
+				return nil, err
+			}
+		}
+		return s, nil
+	}
+
+	// commit to the permutation matrix: the column of input j has a one
+	// on the row of its output i
+	r, err := random()
+	if err != nil {
+		return nil, err
+	}
+	p := &Proof{Commitments: make([]*ec.Point, n)}
+	for i, j := range perm {
+		p.Commitments[j] = c.Add(c.ScalarBaseMult(r[j]), hs[i])
+	}
+	u := challenges(c, msg, p.Commitments)
+	permuted := make([]*big.Int, n)
+	for i, j := range perm {
+		permuted[i] = u[j]
+	}
+
+	// chain the permuted challenges: ĉ_i = r̂_i*G + u'_i*ĉ_{i-1}, so that
+	// ĉ_n commits to their product
+	rChain, err := random()
+	if err != nil {
+		return nil, err
+	}
+	p.Chain = make([]*ec.Point, n)
+	prev := h
+	for i := range p.Chain {
+		p.Chain[i] = c.Add(c.ScalarBaseMult(rChain[i]), c.ScalarMult(prev, permuted[i]))
+		prev = p.Chain[i]
+	}
+
+	// the secrets behind the four relations
+	rBar, rHat, rTilde, rPrime := new(big.Int), new(big.Int), new(big.Int), new(big.Int)
+	v := big.NewInt(1)
+	for i := n - 1; i >= 0; i-- {
+		rHat = mod(rHat.Add(rHat, mul(rChain[i], v)))
+		v = mul(v, permuted[i])
+	}
+	for j := range r {
+		rBar = mod(rBar.Add(rBar, r[j]))
+		rTilde = mod(rTilde.Add(rTilde, mul(u[j], r[j])))
+	}
+	for i := range randomness {
+		rPrime = mod(rPrime.Add(rPrime, mul(permuted[i], randomness[i])))
+	}
+
+	// commitments of the sigma protocol
+	w, err := random()
+	if err != nil {
+		return nil, err
+	}
+	wChain, err := random()
+	if err != nil {
+		return nil, err
+	}
+	w1, err := c.RandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	w2, err := c.RandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	w3, err := c.RandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	w4, err := c.RandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	c1s, c2s := components(out)
+	p.T1 = c.ScalarBaseMult(w1)
+	p.T2 = c.ScalarBaseMult(w2)
+	p.T3 = c.Add(c.ScalarBaseMult(w3), c.MultiScalarMult(w, hs))
+	p.T41 = c.Sub(c.MultiScalarMult(w, c1s), c.ScalarBaseMult(w4))
+	p.T42 = c.Sub(c.MultiScalarMult(w, c2s), c.ScalarMult(pub.Y, w4))
+	p.TChain = make([]*ec.Point, n)
+	prev = h
+	for i := range p.TChain {
+		p.TChain[i] = c.Add(c.ScalarBaseMult(wChain[i]), c.ScalarMult(prev, w[i]))
+		prev = p.Chain[i]
+	}
+
+	// responses
+	e := challenge(c, msg, p)
+	respond := func(w, x *big.Int) *big.Int {
+		return mod(new(big.Int).Add(w, mul(e, x)))
+	}
+	p.S1 = respond(w1, rBar)
+	p.S2 = respond(w2, rHat)
+	p.S3 = respond(w3, rTilde)
+	p.S4 = respond(w4, rPrime)
+	p.SChain = make([]*big.Int, n)
+	p.SPerm = make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		p.SChain[i] = respond(wChain[i], rChain[i])
+		p.SPerm[i] = respond(w[i], permuted[i])
+	}
+	return p, nil
+}
+
+// components returns the first and second points of the ciphertexts
+func components(cts []*elgamal.Ciphertext) ([]*ec.Point, []*ec.Point) {
+	c1s := make([]*ec.Point, len(cts))
+	c2s := make([]*ec.Point, len(cts))
+	for i, ct := range cts {
+		c1s[i], c2s[i] = ct.C1, ct.C2
+	}
+	return c1s, c2s
+}
+
+// valid reports whether all the points of the proof and of the
+// ciphertexts are on the curve, the scalars reduced, and the lengths
+// consistent
+func (p *Proof) valid(c *ec.Curve, n int, cts ...[]*elgamal.Ciphertext) bool {
+	if len(p.Commitments) != n || len(p.Chain) != n || len(p.TChain) != n ||
+		len(p.SChain) != n || len(p.SPerm) != n {
+		return false
+	}
+	points := append(append([]*ec.Point{}, p.Commitments...), p.Chain...)
+	points = append(points, p.T1, p.T2, p.T3, p.T41, p.T42)
+	points = append(points, p.TChain...)
+	for _, list := range cts {
+		if len(list) != n {
+			return false
+		}
+		for _, ct := range list {
+			if ct == nil {
+				return false
+			}
+			points = append(points, ct.C1, ct.C2)
+		}
+	}
+	for _, pt := range points {
+		if pt == nil || !c.IsOnCurve(pt) {
+			return false
+		}
+	}
+	scalars := append(append([]*big.Int{p.S1, p.S2, p.S3, p.S4}, p.SChain...), p.SPerm...)
+	for _, s := range scalars {
+		if s == nil || s.Sign() < 0 || s.Cmp(c.N) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Verify checks the proof that out is a shuffle of in re-encrypted with
+// pub
+func Verify(pub *elgamal.PublicKey, in, out []*elgamal.Ciphertext, p *Proof) bool {
+	c := pub.Curve
+	n := len(in)
+	if p == nil || n == 0 || !p.valid(c, n, in, out) {
+		return false
+	}
+	h, hs := generators(c, n)
+	msg := statement(pub, in, out)
+	u := challenges(c, msg, p.Commitments)
+	e := challenge(c, msg, p)
+
+	// the columns of the matrix sum to one: the sum of the commitments
+	// opens to the sum of the generators
+	sum := ec.Infinity()
+	for j := range p.Commitments {
+		sum = c.Add(sum, c.Sub(p.Commitments[j], hs[j]))
+	}
+	if !c.ScalarBaseMult(p.S1).Equal(c.Add(p.T1, c.ScalarMult(sum, e))) {
+		return false
+	}
+
+	// the chain ends on the product of the challenges, which the
+	// permuted challenges share
+	prod := big.NewInt(1)
+	for _, uj := range u {
+		prod.Mul(prod, uj).Mod(prod, c.N)
+	}
+	last := c.Sub(p.Chain[n-1], c.ScalarMult(h, prod))
+	if !c.ScalarBaseMult(p.S2).Equal(c.Add(p.T2, c.ScalarMult(last, e))) {
+		return false
+	}
+
+	// the matrix maps u to the permuted challenges
+	left := c.Add(c.ScalarBaseMult(p.S3), c.MultiScalarMult(p.SPerm, hs))
+	if !left.Equal(c.Add(p.T3, c.ScalarMult(c.MultiScalarMult(u, p.Commitments), e))) {
+		return false
+	}
+
+	// the output is the input, weighted by the permuted challenges and
+	// re-encrypted
+	in1, in2 := components(in)
+	out1, out2 := components(out)
+	left = c.Sub(c.MultiScalarMult(p.SPerm, out1), c.ScalarBaseMult(p.S4))
+	if !left.Equal(c.Add(p.T41, c.ScalarMult(c.MultiScalarMult(u, in1), e))) {
+		return false
+	}
+	left = c.Sub(c.MultiScalarMult(p.SPerm, out2), c.ScalarMult(pub.Y, p.S4))
+	if !left.Equal(c.Add(p.T42, c.ScalarMult(c.MultiScalarMult(u, in2), e))) {
+		return false
+	}
+
+	// each link of the chain multiplies by the same permuted challenge
+	prev := h
+	for i := 0; i < n; i++ {
+		left = c.Add(c.ScalarBaseMult(p.SChain[i]), c.ScalarMult(prev, p.SPerm[i]))
+		if !left.Equal(c.Add(p.TChain[i], c.ScalarMult(p.Chain[i], e))) {
+			return false
+		}
+		prev = p.Chain[i]
+	}
+	return true
+}
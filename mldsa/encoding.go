@@ -0,0 +1,101 @@
+package mldsa
+
+// packBits appends the values of f, bits each, least significant bit
+// first, FIPS 204 algorithm 16
+func packBits(out []byte, f *[n]uint32, bits uint) []byte {
+	var acc uint64
+	var held uint
+	for _, c := range f {
+		acc |= uint64(c) << held
+		held += bits
+		for held >= 8 {
+			out = append(out, byte(acc))
+			acc >>= 8
+			held -= 8
+		}
+	}
+	return out
+}
+
+// unpackBits reads 32 bits bytes into values of bits each, FIPS 204
+// algorithm 18
+func unpackBits(b []byte, bits uint) [n]uint32 {
+	var f [n]uint32
+	var acc uint64
+	var held uint
+	i := 0
+	for _, x := range b[:32*bits] {
+		acc |= uint64(x) << held
+		held += 8
+		for held >= bits {
+			f[i] = uint32(acc & (1<<bits - 1))
+			acc >>= bits
+			held -= bits
+			i++
+		}
+	}
+	return f
+}
+
+// bitPack appends the coefficients of f, in [-a, b], as b - f with bits
+// bits each, FIPS 204 algorithm 17
+func bitPack(out []byte, f *poly, b int32, bits uint) []byte {
+	var v [n]uint32
+	for i, c := range f {
+		v[i] = uint32(b - centered(c))
+	}
+	return packBits(out, &v, bits)
+}
+
+// bitUnpack is the inverse of bitPack, FIPS 204 algorithm 19. Values
+// above a + b, which bitPack never produces, come out of range.
+func bitUnpack(buf []byte, bits uint, b int32) poly {
+	var f poly
+	for i, v := range unpackBits(buf, bits) {
+		f[i] = fromInt(b - int32(v))
+	}
+	return f
+}
+
+// hintPack appends the positions of the hints of each polynomial, then
+// the running count at the end of each, FIPS 204 algorithm 20
+func hintPack(out []byte, h *[k][n]bool) []byte {
+	y := make([]byte, omega+k)
+	index := 0
+	for i := range h {
+		for j, set := range h[i] {
+			if set {
+				y[index] = byte(j)
+				index++
+			}
+		}
+		y[omega+i] = byte(index)
+	}
+	return append(out, y...)
+}
+
+// hintUnpack decodes hints, rejecting any encoding but the one hintPack
+// produces: increasing positions and zero padding, so that a signature
+// has a single valid encoding, FIPS 204 algorithm 21
+func hintUnpack(y []byte) (h [k][n]bool, ok bool) {
+	index := 0
+	for i := 0; i < k; i++ {
+		end := int(y[omega+i])
+		if end < index || end > omega {
+			return h, false
+		}
+		first := index
+		for ; index < end; index++ {
+			if index > first && y[index-1] >= y[index] {
+				return h, false
+			}
+			h[i][y[index]] = true
+		}
+	}
+	for ; index < omega; index++ {
+		if y[index] != 0 {
+			return h, false
+		}
+	}
+	return h, true
+}
@@ -0,0 +1,201 @@
+package mldsa
+
+// q is the prime modulus 2^23 - 2^13 + 1, n the degree of the
+// polynomials, d the number of bits dropped from t
+const (
+	q = 8380417
+	n = 256
+	d = 13
+)
+
+// poly is a polynomial of Zq[X]/(X^256 + 1), coefficients in [0, q)
+type poly [n]uint32
+
+// nttPoly is a polynomial in the NTT domain, its values at the 256 roots
+// of X^256 + 1
+type nttPoly [n]uint32
+
+// zetas[i] is 1753^BitRev8(i) mod q, 1753 being a primitive 512th root of
+// unity
+var zetas = roots()
+
+func roots() (zetas [n]uint32) {
+	for i := range zetas {
+		r := 0
+		for b := 0; b < 8; b++ {
+			r |= (i >> uint(b) & 1) << uint(7-b)
+		}
+		x := uint32(1)
+		for j := 0; j < r; j++ {
+			x = mul(x, 1753)
+		}
+		zetas[i] = x
+	}
+	return
+}
+
+func add(a, b uint32) uint32 {
+	return (a + b) % q
+}
+
+func sub(a, b uint32) uint32 {
+	return (a + q - b) % q
+}
+
+func mul(a, b uint32) uint32 {
+	return uint32(uint64(a) * uint64(b) % q)
+}
+
+// fromInt returns x mod q
+func fromInt(x int32) uint32 {
+	r := x % q
+	if r < 0 {
+		r += q
+	}
+	return uint32(r)
+}
+
+// centered returns x as an integer in (-q/2, q/2]
+func centered(x uint32) int32 {
+	if x > (q-1)/2 {
+		return int32(x) - q
+	}
+	return int32(x)
+}
+
+// ntt returns the NTT of f, FIPS 204 algorithm 41
+func ntt(f poly) nttPoly {
+	m := 0
+	for length := 128; length >= 1; length /= 2 {
+		for start := 0; start < n; start += 2 * length {
+			m++
+			z := zetas[m]
+			for j := start; j < start+length; j++ {
+				t := mul(z, f[j+length])
+				f[j+length] = sub(f[j], t)
+				f[j] = add(f[j], t)
+			}
+		}
+	}
+	return nttPoly(f)
+}
+
+// inverseNTT returns the polynomial of f, FIPS 204 algorithm 42
+func inverseNTT(f nttPoly) poly {
+	m := n
+	for length := 1; length < n; length *= 2 {
+		for start := 0; start < n; start += 2 * length {
+			m--
+			z := q - zetas[m]
+			for j := start; j < start+length; j++ {
+				t := f[j]
+				f[j] = add(t, f[j+length])
+				f[j+length] = mul(z, sub(t, f[j+length]))
+			}
+		}
+	}
+	// 8347681 = 256^-1 mod q
+	for i := range f {
+		f[i] = mul(f[i], 8347681)
+	}
+	return poly(f)
+}
+
+func nttMul(f, g nttPoly) nttPoly {
+	for i := range f {
+		f[i] = mul(f[i], g[i])
+	}
+	return f
+}
+
+func nttAdd(f, g nttPoly) nttPoly {
+	for i := range f {
+		f[i] = add(f[i], g[i])
+	}
+	return f
+}
+
+func polyAdd(f, g poly) poly {
+	for i := range f {
+		f[i] = add(f[i], g[i])
+	}
+	return f
+}
+
+func polySub(f, g poly) poly {
+	for i := range f {
+		f[i] = sub(f[i], g[i])
+	}
+	return f
+}
+
+// norm returns the infinity norm of f, the largest absolute value of its
+// centered coefficients
+func norm(f *poly) int32 {
+	max := int32(0)
+	for _, c := range f {
+		x := centered(c)
+		if x < 0 {
+			x = -x
+		}
+		if x > max {
+			max = x
+		}
+	}
+	return max
+}
+
+// power2Round splits r into r1 2^d + r0 with r0 in (-2^(d-1), 2^(d-1)],
+// FIPS 204 algorithm 35
+func power2Round(r uint32) (r1 uint32, r0 int32) {
+	r0 = int32(r & (1<<d - 1))
+	if r0 > 1<<(d-1) {
+		r0 -= 1 << d
+	}
+	return uint32((int32(r) - r0) >> d), r0
+}
+
+// decompose splits r into r1 2 gamma2 + r0 with r0 in (-gamma2, gamma2],
+// except at the top where q - 1 wraps to 0 and r0 - 1, FIPS 204
+// algorithm 36
+func decompose(r uint32) (r1 uint32, r0 int32) {
+	r0 = int32(r % (2 * gamma2))
+	if r0 > gamma2 {
+		r0 -= 2 * gamma2
+	}
+	if int32(r)-r0 == q-1 {
+		return 0, r0 - 1
+	}
+	return uint32((int32(r) - r0) / (2 * gamma2)), r0
+}
+
+func highBits(r uint32) uint32 {
+	r1, _ := decompose(r)
+	return r1
+}
+
+func lowBits(r uint32) int32 {
+	_, r0 := decompose(r)
+	return r0
+}
+
+// makeHint tells whether adding z to r changes its high bits, FIPS 204
+// algorithm 39
+func makeHint(z, r uint32) bool {
+	return highBits(r) != highBits(add(r, z))
+}
+
+// useHint returns the high bits of r + z from r and the hint of z, FIPS
+// 204 algorithm 40: a hint moves the high bits one step in the direction
+// of the low bits
+func useHint(h bool, r uint32) uint32 {
+	const m = (q - 1) / (2 * gamma2)
+	r1, r0 := decompose(r)
+	switch {
+	case h && r0 > 0:
+		return (r1 + 1) % m
+	case h:
+		return (r1 + m - 1) % m
+	}
+	return r1
+}
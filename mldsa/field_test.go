@@ -0,0 +1,116 @@
+package mldsa
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func randomPoly(r *rand.Rand) (f poly) {
+	for i := range f {
+		f[i] = uint32(r.Intn(q))
+	}
+	return
+}
+
+// TestNTT compares the product through the NTT with the schoolbook
+// product in Zq[X]/(X^256 + 1)
+func TestNTT(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 10; i++ {
+		f, g := randomPoly(r), randomPoly(r)
+		if inverseNTT(ntt(f)) != f {
+			t.Fatalf("testcase %d: inverse NTT does not undo the NTT", i)
+		}
+		var expected poly
+		for a := 0; a < n; a++ {
+			for b := 0; b < n; b++ {
+				p := mul(f[a], g[b])
+				if a+b < n {
+					expected[a+b] = add(expected[a+b], p)
+				} else {
+					expected[a+b-n] = sub(expected[a+b-n], p)
+				}
+			}
+		}
+		if got := inverseNTT(nttMul(ntt(f), ntt(g))); got != expected {
+			t.Fatalf("testcase %d: NTT product differs from the schoolbook product", i)
+		}
+	}
+}
+
+func TestRounding(t *testing.T) {
+	t.Parallel()
+	for x := uint32(0); x < q; x++ {
+		r1, r0 := power2Round(x)
+		if r0 <= -(1<<(d-1)) || r0 > 1<<(d-1) || add(r1<<d, fromInt(r0)) != x {
+			t.Fatalf("power2Round(%d) gave %d, %d", x, r1, r0)
+		}
+		h1, h0 := decompose(x)
+		if h1 >= 16 || h0 < -gamma2 || h0 > gamma2 || add(h1*2*gamma2, fromInt(h0)) != x {
+			t.Fatalf("decompose(%d) gave %d, %d", x, h1, h0)
+		}
+		if useHint(false, x) != h1 {
+			t.Fatalf("useHint(false, %d) is not the high bits", x)
+		}
+	}
+}
+
+// TestHints checks that the hint of any small z recovers the high bits of
+// r + z from r
+func TestHints(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100000; i++ {
+		x := uint32(r.Intn(q))
+		z := fromInt(int32(r.Intn(2*gamma2+1) - gamma2))
+		if i%2 == 0 {
+			// the wrap around at q - 1
+			x = q - 1 - uint32(r.Intn(2*gamma2))
+		}
+		if got, expected := useHint(makeHint(z, x), x), highBits(add(x, z)); got != expected {
+			t.Fatalf("testcase %d expected high bits %d of %d + %d but got %d", i, expected, x, z, got)
+		}
+	}
+}
+
+func TestPacking(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(1))
+	var f poly
+	for i := range f {
+		f[i] = fromInt(int32(r.Intn(2*gamma1)) - gamma1 + 1)
+	}
+	b := bitPack(nil, &f, gamma1, gamma1Bits)
+	if len(b) != 32*gamma1Bits || bitUnpack(b, gamma1Bits, gamma1) != f {
+		t.Fatal("bitUnpack does not undo bitPack")
+	}
+
+	var h [k][n]bool
+	for i := 0; i < omega; i++ {
+		h[r.Intn(k)][r.Intn(n)] = true
+	}
+	y := hintPack(nil, &h)
+	got, ok := hintUnpack(y)
+	if !ok || got != h {
+		t.Fatal("hintUnpack does not undo hintPack")
+	}
+	// positions must increase and the padding must be zero
+	var two [k][n]bool
+	two[0][3], two[0][7] = true, true
+	y = hintPack(nil, &two)
+	y[0], y[1] = y[1], y[0]
+	if _, ok := hintUnpack(y); ok {
+		t.Fatal("hints out of order accepted")
+	}
+	y = hintPack(nil, &two)
+	y[omega-1] = 1
+	if _, ok := hintUnpack(y); ok {
+		t.Fatal("hints with non zero padding accepted")
+	}
+	y = hintPack(nil, &two)
+	y[omega] = omega + 1
+	if _, ok := hintUnpack(y); ok {
+		t.Fatal("hint count above omega accepted")
+	}
+}
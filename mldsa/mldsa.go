@@ -0,0 +1,483 @@
+// Package mldsa implements ML-DSA-65, the module lattice digital signature
+// algorithm of FIPS 204, derived from CRYSTALS-Dilithium.
+//
+// ML-DSA works in the same ring as ML-KEM, Zq[X]/(X^256 + 1) with q =
+// 8380417. The private key is a pair of vectors s1, s2 with coefficients in
+// [-4, 4], the public key is t = A s1 + s2 with only its high bits t1
+// published. Signing is a Fiat-Shamir proof of knowledge of s1: commit to
+// the high bits w1 of A y for a random y, hash them with the message into
+// a challenge c with 49 coefficients of 1 or -1, answer z = y + c s1. The
+// answer would leak s1 through its distribution, so signatures whose z,
+// or whose low bits of A y - c s2, come near the bounds are rejected and
+// signing starts over with a new y, about 5 times on average. The
+// verifier recomputes w1 from A z - c t1 2^13, which misses the low bits
+// of t and c s2: the signature carries one hint bit per coefficient where
+// that carry changes the high bits, at most 55 of them.
+//
+// This implementation is written to follow the pseudocode of FIPS 204 and
+// is not constant time.
+package mldsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/subtle"
+	"errors"
+	"io"
+
+	"github.com/jvehent/badcrypto/sha3"
+)
+
+const (
+	// SeedSize is the size of the seed a key pair is derived from
+	SeedSize = 32
+	// PublicKeySize is the size of an encoded public key
+	PublicKeySize = 32 + k*32*10
+	// PrivateKeySize is the size of an encoded private key, in the
+	// expanded format of FIPS 204
+	PrivateKeySize = 32 + 32 + 64 + (l+k)*32*4 + k*32*d
+	// SignatureSize is the size of a signature
+	SignatureSize = lambda/4 + l*32*gamma1Bits + omega + k
+)
+
+// parameters of ML-DSA-65
+const (
+	k          = 6
+	l          = 5
+	eta        = 4
+	tau        = 49
+	lambda     = 192
+	gamma1     = 1 << 19
+	gamma1Bits = 20
+	gamma2     = (q - 1) / 32
+	beta       = tau * eta
+	omega      = 55
+)
+
+// PublicKey is a public key of ML-DSA-65
+type PublicKey struct {
+	rho [32]byte
+	t1  [k]poly
+	// t1d is t1 2^d in the NTT domain, a is expanded from rho and tr is
+	// the hash of the encoded key, all computed once
+	t1d [k]nttPoly
+	a   [k][l]nttPoly
+	tr  [64]byte
+}
+
+// PrivateKey is a private key of ML-DSA-65
+type PrivateKey struct {
+	pub PublicKey
+	key [32]byte
+	s1  [l]poly
+	s2  [k]poly
+	t0  [k]poly
+	// s1, s2 and t0 in the NTT domain
+	s1Hat [l]nttPoly
+	s2Hat [k]nttPoly
+	t0Hat [k]nttPoly
+	// seed is unknown for keys parsed from the expanded format
+	seed []byte
+}
+
+// Options are the signing options of ML-DSA. Context, at most 255 bytes,
+// separates the signatures of different applications with the same key.
+type Options struct {
+	Context string
+}
+
+// HashFunc returns 0: ML-DSA signs the message itself, not a digest
+func (opts *Options) HashFunc() crypto.Hash {
+	return 0
+}
+
+// GenerateKey returns a new private key, from a seed of 32 bytes read
+// from rand
+func GenerateKey(rand io.Reader) (*PrivateKey, error) {
+	seed := make([]byte, SeedSize)
+	if _, err := io.ReadFull(rand, seed); err != nil {
+		return nil, err
+	}
+	return NewKeyFromSeed(seed)
+}
+
+// NewKeyFromSeed derives a private key from its 32 bytes seed, FIPS 204
+// algorithm 6
+func NewKeyFromSeed(seed []byte) (*PrivateKey, error) {
+	if len(seed) != SeedSize {
+		return nil, errors.New("mldsa: invalid seed length")
+	}
+	// (rho, rho', K) = H(seed || k || l), the k and l bytes separate the
+	// parameter sets
+	h := sha3.NewShake256()
+	h.Write(seed)
+	h.Write([]byte{k, l})
+	var rhoPrime [64]byte
+	priv := &PrivateKey{seed: append([]byte{}, seed...)}
+	h.Read(priv.pub.rho[:])
+	h.Read(rhoPrime[:])
+	h.Read(priv.key[:])
+	priv.pub.a = expandA(priv.pub.rho[:])
+	priv.s1, priv.s2 = expandS(rhoPrime[:])
+	priv.expand()
+	for i := range priv.t0 {
+		t := priv.publicT(i)
+		for j, c := range t {
+			t1, t0 := power2Round(c)
+			priv.pub.t1[i][j], priv.t0[i][j] = t1, fromInt(t0)
+		}
+	}
+	priv.pub.expand()
+	priv.t0Hat = nttVector(priv.t0)
+	return priv, nil
+}
+
+// publicT returns the polynomial i of t = A s1 + s2
+func (priv *PrivateKey) publicT(i int) poly {
+	var sum nttPoly
+	for j := range priv.s1Hat {
+		sum = nttAdd(sum, nttMul(priv.pub.a[i][j], priv.s1Hat[j]))
+	}
+	return polyAdd(inverseNTT(sum), priv.s2[i])
+}
+
+// expand computes the NTT of s1 and s2
+func (priv *PrivateKey) expand() {
+	for i := range priv.s1 {
+		priv.s1Hat[i] = ntt(priv.s1[i])
+	}
+	for i := range priv.s2 {
+		priv.s2Hat[i] = ntt(priv.s2[i])
+	}
+}
+
+func nttVector(v [k]poly) (hat [k]nttPoly) {
+	for i := range v {
+		hat[i] = ntt(v[i])
+	}
+	return
+}
+
+// expand computes t1 2^d and tr from rho and t1
+func (pub *PublicKey) expand() {
+	for i := range pub.t1 {
+		var t poly
+		for j, c := range pub.t1[i] {
+			t[j] = c << d
+		}
+		pub.t1d[i] = ntt(t)
+	}
+	h := sha3.NewShake256()
+	h.Write(pub.Bytes())
+	h.Read(pub.tr[:])
+}
+
+// ParsePrivateKey decodes a private key in the expanded format of FIPS
+// 204, rho || K || tr || s1 || s2 || t0, after checking that it is
+// consistent: t0 and tr must be those of A s1 + s2
+func ParsePrivateKey(b []byte) (*PrivateKey, error) {
+	if len(b) != PrivateKeySize {
+		return nil, errors.New("mldsa: invalid private key length")
+	}
+	priv := &PrivateKey{}
+	copy(priv.pub.rho[:], b[:32])
+	copy(priv.key[:], b[32:64])
+	tr := b[64:128]
+	b = b[128:]
+	for i := 0; i < l+k; i++ {
+		f := unpackBits(b[128*i:], 4)
+		for j, v := range f {
+			if v > 2*eta {
+				return nil, errors.New("mldsa: invalid private key")
+			}
+			f[j] = fromInt(eta - int32(v))
+		}
+		if i < l {
+			priv.s1[i] = poly(f)
+		} else {
+			priv.s2[i-l] = poly(f)
+		}
+	}
+	b = b[128*(l+k):]
+	for i := range priv.t0 {
+		priv.t0[i] = bitUnpack(b[32*d*i:], d, 1<<(d-1))
+	}
+	priv.pub.a = expandA(priv.pub.rho[:])
+	priv.expand()
+	for i := range priv.t0 {
+		t := priv.publicT(i)
+		for j, c := range t {
+			t1, t0 := power2Round(c)
+			if fromInt(t0) != priv.t0[i][j] {
+				return nil, errors.New("mldsa: inconsistent private key")
+			}
+			priv.pub.t1[i][j] = t1
+		}
+	}
+	priv.pub.expand()
+	if subtle.ConstantTimeCompare(tr, priv.pub.tr[:]) != 1 {
+		return nil, errors.New("mldsa: inconsistent private key")
+	}
+	priv.t0Hat = nttVector(priv.t0)
+	return priv, nil
+}
+
+// Seed returns the 32 bytes seed of the key, or nil if it was parsed from
+// the expanded format
+func (priv *PrivateKey) Seed() []byte {
+	if priv.seed == nil {
+		return nil
+	}
+	return append([]byte{}, priv.seed...)
+}
+
+// Bytes returns the key in the expanded format of FIPS 204, algorithm 24
+func (priv *PrivateKey) Bytes() []byte {
+	b := make([]byte, 0, PrivateKeySize)
+	b = append(b, priv.pub.rho[:]...)
+	b = append(b, priv.key[:]...)
+	b = append(b, priv.pub.tr[:]...)
+	for i := range priv.s1 {
+		b = bitPack(b, &priv.s1[i], eta, 4)
+	}
+	for i := range priv.s2 {
+		b = bitPack(b, &priv.s2[i], eta, 4)
+	}
+	for i := range priv.t0 {
+		b = bitPack(b, &priv.t0[i], 1<<(d-1), d)
+	}
+	return b
+}
+
+// PublicKey returns the public key of priv
+func (priv *PrivateKey) PublicKey() *PublicKey {
+	pub := priv.pub
+	return &pub
+}
+
+// Public returns the public key of priv, for crypto.Signer
+func (priv *PrivateKey) Public() crypto.PublicKey {
+	return priv.PublicKey()
+}
+
+// ParsePublicKey decodes a public key, rho || t1
+func ParsePublicKey(b []byte) (*PublicKey, error) {
+	if len(b) != PublicKeySize {
+		return nil, errors.New("mldsa: invalid public key length")
+	}
+	pub := &PublicKey{}
+	copy(pub.rho[:], b)
+	for i := range pub.t1 {
+		pub.t1[i] = poly(unpackBits(b[32+320*i:], 10))
+	}
+	pub.a = expandA(pub.rho[:])
+	pub.expand()
+	return pub, nil
+}
+
+// Bytes returns the encoding of pub, FIPS 204 algorithm 22
+func (pub *PublicKey) Bytes() []byte {
+	b := make([]byte, 0, PublicKeySize)
+	b = append(b, pub.rho[:]...)
+	for i := range pub.t1 {
+		b = packBits(b, (*[n]uint32)(&pub.t1[i]), 10)
+	}
+	return b
+}
+
+// Equal tells whether pub and x are the same key
+func (pub *PublicKey) Equal(x crypto.PublicKey) bool {
+	other, ok := x.(*PublicKey)
+	if !ok {
+		return false
+	}
+	return bytes.Equal(pub.Bytes(), other.Bytes())
+}
+
+// Sign returns the signature of message. opts may be nil or an *Options
+// carrying a context, and must not ask for a hash: the message is signed
+// as is. Signing is hedged with 32 bytes read from rand, or deterministic
+// if rand is nil.
+func (priv *PrivateKey) Sign(rand io.Reader, message []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if opts != nil && opts.HashFunc() != 0 {
+		return nil, errors.New("mldsa: cannot sign a digest, only the message itself")
+	}
+	var context string
+	if o, ok := opts.(*Options); ok {
+		context = o.Context
+	}
+	mu, err := priv.pub.mu(message, context)
+	if err != nil {
+		return nil, err
+	}
+	var rnd [32]byte
+	if rand != nil {
+		if _, err := io.ReadFull(rand, rnd[:]); err != nil {
+			return nil, err
+		}
+	}
+	return priv.sign(mu, rnd[:]), nil
+}
+
+// Verify tells whether sig is a valid signature of message by pub, under
+// the context of opts, which may be nil for no context
+func Verify(pub *PublicKey, message, sig []byte, opts *Options) bool {
+	var context string
+	if opts != nil {
+		context = opts.Context
+	}
+	mu, err := pub.mu(message, context)
+	if err != nil {
+		return false
+	}
+	return pub.verify(mu, sig)
+}
+
+// mu returns the message representative H(tr || M'), where M' = 0 ||
+// len(context) || context || message is the input of pure ML-DSA, FIPS
+// 204 algorithms 2 and 3
+func (pub *PublicKey) mu(message []byte, context string) ([]byte, error) {
+	if len(context) > 255 {
+		return nil, errors.New("mldsa: context longer than 255 bytes")
+	}
+	h := sha3.NewShake256()
+	h.Write(pub.tr[:])
+	h.Write([]byte{0, byte(len(context))})
+	h.Write([]byte(context))
+	h.Write(message)
+	mu := make([]byte, 64)
+	h.Read(mu)
+	return mu, nil
+}
+
+// sign returns the signature of the message representative mu with the
+// randomness rnd, FIPS 204 algorithm 7
+func (priv *PrivateKey) sign(mu, rnd []byte) []byte {
+	h := sha3.NewShake256()
+	h.Write(priv.key[:])
+	h.Write(rnd)
+	h.Write(mu)
+	rhoPrime := make([]byte, 64)
+	h.Read(rhoPrime)
+
+	for kappa := 0; ; kappa += l {
+		y := expandMask(rhoPrime, kappa)
+		var yHat [l]nttPoly
+		for i := range y {
+			yHat[i] = ntt(y[i])
+		}
+		// commitment w = A y and its high bits w1
+		var w, w1 [k]poly
+		for i := range w {
+			var sum nttPoly
+			for j := range yHat {
+				sum = nttAdd(sum, nttMul(priv.pub.a[i][j], yHat[j]))
+			}
+			w[i] = inverseNTT(sum)
+			for j, c := range w[i] {
+				w1[i][j] = highBits(c)
+			}
+		}
+		ctilde := challengeHash(mu, &w1)
+		cHat := ntt(sampleInBall(ctilde))
+
+		var z [l]poly
+		rejected := false
+		for i := range z {
+			z[i] = polyAdd(y[i], inverseNTT(nttMul(cHat, priv.s1Hat[i])))
+			if norm(&z[i]) >= gamma1-beta {
+				rejected = true
+			}
+		}
+		if rejected {
+			continue
+		}
+		var hint [k][n]bool
+		hints := 0
+		for i := range w {
+			// r0 = LowBits(w - c s2) must stay clear of the rounding
+			// boundaries for the verifier to find the same w1
+			r := polySub(w[i], inverseNTT(nttMul(cHat, priv.s2Hat[i])))
+			ct0 := inverseNTT(nttMul(cHat, priv.t0Hat[i]))
+			if norm(&ct0) >= gamma2 {
+				rejected = true
+				break
+			}
+			for j, c := range r {
+				r0 := lowBits(c)
+				if r0 >= gamma2-beta || r0 <= -(gamma2-beta) {
+					rejected = true
+					break
+				}
+				// the verifier computes w - c s2 + c t0
+				if makeHint(q-ct0[j], add(c, ct0[j])) {
+					hint[i][j] = true
+					hints++
+				}
+			}
+			if rejected {
+				break
+			}
+		}
+		if rejected || hints > omega {
+			continue
+		}
+
+		sig := make([]byte, 0, SignatureSize)
+		sig = append(sig, ctilde...)
+		for i := range z {
+			sig = bitPack(sig, &z[i], gamma1, gamma1Bits)
+		}
+		return hintPack(sig, &hint)
+	}
+}
+
+// verify tells whether sig is a valid signature of mu, FIPS 204
+// algorithm 8
+func (pub *PublicKey) verify(mu, sig []byte) bool {
+	if len(sig) != SignatureSize {
+		return false
+	}
+	ctilde := sig[:lambda/4]
+	var zHat [l]nttPoly
+	for i := range zHat {
+		z := bitUnpack(sig[lambda/4+32*gamma1Bits*i:], gamma1Bits, gamma1)
+		if norm(&z) >= gamma1-beta {
+			return false
+		}
+		zHat[i] = ntt(z)
+	}
+	hint, ok := hintUnpack(sig[lambda/4+l*32*gamma1Bits:])
+	if !ok {
+		return false
+	}
+	cHat := ntt(sampleInBall(ctilde))
+	// w' = A z - c t1 2^d = w - c s2 + c t0, whose high bits, corrected
+	// by the hints, are w1
+	var w1 [k]poly
+	for i := range w1 {
+		var sum nttPoly
+		for j := range zHat {
+			sum = nttAdd(sum, nttMul(pub.a[i][j], zHat[j]))
+		}
+		w := polySub(inverseNTT(sum), inverseNTT(nttMul(cHat, pub.t1d[i])))
+		for j, c := range w {
+			w1[i][j] = useHint(hint[i][j], c)
+		}
+	}
+	return subtle.ConstantTimeCompare(ctilde, challengeHash(mu, &w1)) == 1
+}
+
+// challengeHash returns c~ = H(mu || w1Encode(w1)), FIPS 204 algorithm 28
+func challengeHash(mu []byte, w1 *[k]poly) []byte {
+	h := sha3.NewShake256()
+	h.Write(mu)
+	var b []byte
+	for i := range w1 {
+		b = packBits(b[:0], (*[n]uint32)(&w1[i]), 4)
+		h.Write(b)
+	}
+	ctilde := make([]byte, lambda/4)
+	h.Read(ctilde)
+	return ctilde
+}
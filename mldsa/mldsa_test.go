@@ -0,0 +1,214 @@
+package mldsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/jvehent/badcrypto/sha3"
+)
+
+var _ crypto.Signer = (*PrivateKey)(nil)
+
+// TestAccumulated derives 100 keys from a SHAKE128 stream of seeds, signs
+// the empty message deterministically with each and checks the hash of
+// all public keys and signatures, the accumulated vectors of
+// https://c2sp.org/CCTV/ML-DSA
+func TestAccumulated(t *testing.T) {
+	t.Parallel()
+	s := sha3.NewShake128()
+	o := sha3.NewShake128()
+	seed := make([]byte, SeedSize)
+	for i := 0; i < 100; i++ {
+		s.Read(seed)
+		priv, err := NewKeyFromSeed(seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pk := priv.PublicKey().Bytes()
+		o.Write(pk)
+		sig, err := priv.Sign(nil, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		o.Write(sig)
+		pub, err := ParsePublicKey(pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(pub, nil, sig, nil) {
+			t.Fatalf("vector %d: signature does not verify", i)
+		}
+	}
+	sum := make([]byte, 32)
+	o.Read(sum)
+	expected := "8358a1843220194417cadbc2651295cd8fc65125b5a5c1a239a16dc8b57ca199"
+	if hex.EncodeToString(sum) != expected {
+		t.Fatalf("expected %s but got %x", expected, sum)
+	}
+}
+
+// the ML-DSA-65 Sign_internal known answers of the ACVP, each hitting
+// every rejection path of the signing loop: SHA-256 of pk || sk, the
+// input M' of mu = H(tr || M') and SHA-256 of the deterministic signature
+var rejectionKATs = []struct {
+	seed, keyHash, msg, sigHash string
+}{
+	{
+		"464756A985E5DF03739D95DD309C1ED9C5B04254CC294E7E7EB9B9365EE15117",
+		"AE95EA0DAA80199E7B4A74EB5A1B1DC6C3805BD01D2FA78D7C4FBA8C255AA13D",
+		"491101BBA044DE6E44A63796C33CDA051BB05A60725B87AF4BA9DB940C03AC09",
+		"8E08EA0C8DB941685B9905A73B0B57BAD3500B1F73490480B24375B41230CC04",
+	},
+	{
+		"235A48DB4CA7916B884F424A8586EFD517E87C64AECEC0FCE9A3CC212BA1522E",
+		"1AC58A909DB4D7BC2473AB5E24AF768279C76F86A82D448258E24EEA4EA6B713",
+		"F8CE85CB2EC474FFBF5A3FFAE029CE6F4526B8D597655067F97F438B81071E9B",
+		"AE9531A01738615B6D33C77B3FF618A86E101FDC4C8504681F0EDFA64511AD63",
+	},
+	{
+		"E13131B705A760305FEFFEBFE99082E2691A444BBEFCC3EDF67D909886200207",
+		"B422093F95CC489C52F4FA2B8973A2FDDD44426D1D04D1AAEEFC8715D417181F",
+		"CD365512C7E61BBAA130800B37F3BB46AAF1BEEF3742EA8A9010A6DD4576ED0B",
+		"3C55E604DECA7B89A99305D7A391C35F66A17C1923F467675EC951C0948D21C9",
+	},
+	{
+		"0A4793E040A4BC0D0F37643D12C1EA1F10648724609936C76E0EC83E37209E92",
+		"622D26D536D4D66CD94956B33A74E2E830ED265D25C34FF7C3E5243403146ADF",
+		"6D9C7A795E48D80A892CBF4D4558429787277E3806EB5D0BCE1640EEBBBF9AEC",
+		"3B141110B9F56540B2D49AACDE6399974A4EAC40621E367E68D4504F294DB21B",
+	},
+	{
+		"F865B889E5022D54BABC81CA67E7EB39F1AC42F92CF5295C3DA5C9667DB1B924",
+		"45BC8EDD1A620C46E973E346844270721824D97888BC174281852D98B7E8F4A3",
+		"047AFAADBE020ED2D766DA85317DEDE80BE550545F0B21E3F555A990F8004258",
+		"56308A3578360C41356BA9C97D3240E01767FA76BBBA9FD0CC6CFA9ADD088DB9",
+	},
+}
+
+func TestRejectionKATs(t *testing.T) {
+	t.Parallel()
+	for i, tc := range rejectionKATs {
+		seed, _ := hex.DecodeString(tc.seed)
+		priv, err := NewKeyFromSeed(seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pk, sk := priv.PublicKey().Bytes(), priv.Bytes()
+		keyHash := sha256.Sum256(append(append([]byte{}, pk...), sk...))
+		if hex.EncodeToString(keyHash[:]) != lower(tc.keyHash) {
+			t.Fatalf("testcase %d expected key hash %s but got %x", i, tc.keyHash, keyHash)
+		}
+		parsed, err := ParsePrivateKey(sk)
+		if err != nil {
+			t.Fatalf("testcase %d: %v", i, err)
+		}
+		if !bytes.Equal(parsed.Bytes(), sk) || parsed.Seed() != nil {
+			t.Fatalf("testcase %d: parsed private key differs", i)
+		}
+		msg, _ := hex.DecodeString(tc.msg)
+		h := sha3.NewShake256()
+		h.Write(priv.pub.tr[:])
+		h.Write(msg)
+		mu := make([]byte, 64)
+		h.Read(mu)
+		sig := parsed.sign(mu, make([]byte, 32))
+		sigHash := sha256.Sum256(sig)
+		if hex.EncodeToString(sigHash[:]) != lower(tc.sigHash) {
+			t.Fatalf("testcase %d expected signature hash %s but got %x", i, tc.sigHash, sigHash)
+		}
+		if !priv.pub.verify(mu, sig) {
+			t.Fatalf("testcase %d: signature does not verify", i)
+		}
+		if priv.pub.verify(make([]byte, 64), sig) {
+			t.Fatalf("testcase %d: signature verifies a different message", i)
+		}
+	}
+}
+
+func lower(s string) string {
+	b, _ := hex.DecodeString(s)
+	return hex.EncodeToString(b)
+}
+
+func TestSignVerify(t *testing.T) {
+	t.Parallel()
+	priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := priv.PublicKey()
+	msg := []byte("ML-DSA-65")
+	opts := &Options{Context: "test"}
+	sig, err := priv.Sign(rand.Reader, msg, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig) != SignatureSize {
+		t.Fatalf("expected a signature of %d bytes but got %d", SignatureSize, len(sig))
+	}
+	if !Verify(pub, msg, sig, opts) {
+		t.Fatal("signature does not verify")
+	}
+	other, _ := priv.Sign(rand.Reader, msg, opts)
+	if bytes.Equal(sig, other) {
+		t.Fatal("hedged signatures of the same message are equal")
+	}
+	if Verify(pub, msg, sig, nil) || Verify(pub, msg, sig, &Options{Context: "tesT"}) {
+		t.Fatal("signature verifies under a different context")
+	}
+	if Verify(pub, []byte("ML-DSA-44"), sig, opts) {
+		t.Fatal("signature verifies a different message")
+	}
+	for _, i := range []int{0, lambda / 4, SignatureSize - 1} {
+		bad := append([]byte{}, sig...)
+		bad[i] ^= 1
+		if Verify(pub, msg, bad, opts) {
+			t.Fatalf("signature with byte %d flipped verifies", i)
+		}
+	}
+	if Verify(pub, msg, sig[:SignatureSize-1], opts) {
+		t.Fatal("truncated signature verifies")
+	}
+	if !pub.Equal(priv.Public()) {
+		t.Fatal("public keys differ")
+	}
+	other2, _ := GenerateKey(rand.Reader)
+	if pub.Equal(other2.Public()) || Verify(other2.PublicKey(), msg, sig, opts) {
+		t.Fatal("signature verifies with a different key")
+	}
+}
+
+func TestSignErrors(t *testing.T) {
+	t.Parallel()
+	priv, err := NewKeyFromSeed(make([]byte, SeedSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := priv.Sign(nil, []byte("digest"), crypto.SHA256); err == nil {
+		t.Fatal("expected an error signing a digest")
+	}
+	long := &Options{Context: string(make([]byte, 256))}
+	if _, err := priv.Sign(nil, nil, long); err == nil {
+		t.Fatal("expected an error with a context of 256 bytes")
+	}
+	if _, err := NewKeyFromSeed(make([]byte, SeedSize-1)); err == nil {
+		t.Fatal("expected an error with a short seed")
+	}
+	if _, err := ParsePublicKey(make([]byte, PublicKeySize+1)); err == nil {
+		t.Fatal("expected an error with a long public key")
+	}
+	sk := priv.Bytes()
+	sk[len(sk)-1] ^= 1
+	if _, err := ParsePrivateKey(sk); err == nil {
+		t.Fatal("expected an error with an inconsistent t0")
+	}
+	sk = priv.Bytes()
+	sk[128] = 0xff
+	if _, err := ParsePrivateKey(sk); err == nil {
+		t.Fatal("expected an error with s1 out of range")
+	}
+}
@@ -0,0 +1,101 @@
+package mldsa
+
+import "github.com/jvehent/badcrypto/sha3"
+
+// expandA samples the matrix A from rho, entry r, s from the SHAKE128
+// stream of rho || s || r, FIPS 204 algorithms 30 and 32: 23 bits out of
+// each 3 bytes, kept if they are below q
+func expandA(rho []byte) (a [k][l]nttPoly) {
+	var buf [168]byte
+	for r := 0; r < k; r++ {
+		for s := 0; s < l; s++ {
+			g := sha3.NewShake128()
+			g.Write(rho)
+			g.Write([]byte{byte(s), byte(r)})
+			j := 0
+			for j < n {
+				g.Read(buf[:])
+				for c := 0; c+3 <= len(buf) && j < n; c += 3 {
+					z := uint32(buf[c]) | uint32(buf[c+1])<<8 | uint32(buf[c+2]&0x7f)<<16
+					if z < q {
+						a[r][s][j] = z
+						j++
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
+// expandS samples the secrets s1 and s2 with coefficients in [-eta, eta]
+// from rho', FIPS 204 algorithms 31 and 33: half bytes below 9 are kept
+// as eta minus their value
+func expandS(rho []byte) (s1 [l]poly, s2 [k]poly) {
+	for r := 0; r < l+k; r++ {
+		h := sha3.NewShake256()
+		h.Write(rho)
+		h.Write([]byte{byte(r), byte(r >> 8)})
+		var f poly
+		var b [1]byte
+		j := 0
+		for j < n {
+			h.Read(b[:])
+			for _, half := range []byte{b[0] & 0x0f, b[0] >> 4} {
+				if half < 2*eta+1 && j < n {
+					f[j] = fromInt(eta - int32(half))
+					j++
+				}
+			}
+		}
+		if r < l {
+			s1[r] = f
+		} else {
+			s2[r-l] = f
+		}
+	}
+	return
+}
+
+// expandMask returns the masking vector y, coefficients in
+// (-gamma1, gamma1], from rho” and the counter kappa, FIPS 204
+// algorithm 34
+func expandMask(rho []byte, kappa int) (y [l]poly) {
+	buf := make([]byte, 32*gamma1Bits)
+	for r := 0; r < l; r++ {
+		h := sha3.NewShake256()
+		h.Write(rho)
+		h.Write([]byte{byte(kappa + r), byte((kappa + r) >> 8)})
+		h.Read(buf)
+		y[r] = bitUnpack(buf, gamma1Bits, gamma1)
+	}
+	return
+}
+
+// sampleInBall returns the challenge c, a polynomial with tau
+// coefficients of 1 or -1 and all others 0, from the SHAKE256 stream of
+// c~, FIPS 204 algorithm 29: a Fisher-Yates shuffle of the non zero
+// coefficients into place, the signs taken from the first 8 bytes
+func sampleInBall(ctilde []byte) poly {
+	h := sha3.NewShake256()
+	h.Write(ctilde)
+	var signs [8]byte
+	h.Read(signs[:])
+	var c poly
+	var b [1]byte
+	for i := n - tau; i < n; i++ {
+		h.Read(b[:])
+		for int(b[0]) > i {
+			h.Read(b[:])
+		}
+		j := int(b[0])
+		c[i] = c[j]
+		bit := i + tau - n
+		if signs[bit/8]>>uint(bit%8)&1 == 1 {
+			c[j] = q - 1
+		} else {
+			c[j] = 1
+		}
+	}
+	return c
+}
@@ -0,0 +1,173 @@
+package mlkem
+
+// q is the prime modulus, 13 * 2^8 + 1, n the degree of the polynomials
+const (
+	q = 3329
+	n = 256
+)
+
+// poly is a polynomial of Zq[X]/(X^256 + 1), coefficients in [0, q)
+type poly [n]uint16
+
+// nttPoly is a polynomial in the NTT domain: 128 polynomials of degree 1,
+// the polynomial modulo X^2 - zeta^(2 BitRev7(i) + 1)
+type nttPoly [n]uint16
+
+// zetas[i] is 17^BitRev7(i) mod q, 17 being a primitive 256th root of
+// unity, and gammas[i] is 17^(2 BitRev7(i) + 1)
+var zetas, gammas = roots()
+
+func roots() (zetas, gammas [128]uint16) {
+	for i := range zetas {
+		r := 0
+		for b := 0; b < 7; b++ {
+			r |= (i >> uint(b) & 1) << uint(6-b)
+		}
+		zetas[i] = power(17, r)
+		gammas[i] = power(17, 2*r+1)
+	}
+	return
+}
+
+// power returns x^e mod q
+func power(x uint16, e int) uint16 {
+	r := uint32(1)
+	for i := 0; i < e; i++ {
+		r = r * uint32(x) % q
+	}
+	return uint16(r)
+}
+
+func add(a, b uint16) uint16 {
+	return uint16((uint32(a) + uint32(b)) % q)
+}
+
+func sub(a, b uint16) uint16 {
+	return uint16((uint32(a) + q - uint32(b)) % q)
+}
+
+func mul(a, b uint16) uint16 {
+	return uint16(uint32(a) * uint32(b) % q)
+}
+
+// ntt returns the NTT of f, FIPS 203 algorithm 9: seven layers of
+// butterflies, down to the 128 residues modulo X^2 - gamma
+func ntt(f poly) nttPoly {
+	k := 1
+	for length := 128; length >= 2; length /= 2 {
+		for start := 0; start < n; start += 2 * length {
+			zeta := zetas[k]
+			k++
+			for j := start; j < start+length; j++ {
+				t := mul(zeta, f[j+length])
+				f[j+length] = sub(f[j], t)
+				f[j] = add(f[j], t)
+			}
+		}
+	}
+	return nttPoly(f)
+}
+
+// inverseNTT returns the polynomial of f, FIPS 203 algorithm 10
+func inverseNTT(f nttPoly) poly {
+	k := 127
+	for length := 2; length <= 128; length *= 2 {
+		for start := 0; start < n; start += 2 * length {
+			zeta := zetas[k]
+			k--
+			for j := start; j < start+length; j++ {
+				t := f[j]
+				f[j] = add(t, f[j+length])
+				f[j+length] = mul(zeta, sub(f[j+length], t))
+			}
+		}
+	}
+	// 3303 = 128^-1 mod q
+	for i := range f {
+		f[i] = mul(f[i], 3303)
+	}
+	return poly(f)
+}
+
+// nttMul returns f * g in the NTT domain, FIPS 203 algorithm 11: the
+// products of the degree 1 residues modulo X^2 - gamma
+func nttMul(f, g nttPoly) nttPoly {
+	var h nttPoly
+	for i := 0; i < n/2; i++ {
+		a0, a1 := f[2*i], f[2*i+1]
+		b0, b1 := g[2*i], g[2*i+1]
+		h[2*i] = add(mul(a0, b0), mul(mul(a1, b1), gammas[i]))
+		h[2*i+1] = add(mul(a0, b1), mul(a1, b0))
+	}
+	return h
+}
+
+func polyAdd(f, g poly) poly {
+	for i := range f {
+		f[i] = add(f[i], g[i])
+	}
+	return f
+}
+
+func polySub(f, g poly) poly {
+	for i := range f {
+		f[i] = sub(f[i], g[i])
+	}
+	return f
+}
+
+func nttAdd(f, g nttPoly) nttPoly {
+	for i := range f {
+		f[i] = add(f[i], g[i])
+	}
+	return f
+}
+
+// compress maps x in [0, q) to round(2^d x / q) mod 2^d, FIPS 203
+// section 4.2.1. q is odd, so there are no ties.
+func compress(x uint16, d uint) uint16 {
+	return uint16(((uint32(x)<<d + q/2) / q) & (1<<d - 1))
+}
+
+// decompress maps y in [0, 2^d) to round(q y / 2^d)
+func decompress(y uint16, d uint) uint16 {
+	return uint16((uint32(y)*q + 1<<(d-1)) >> d)
+}
+
+// byteEncode appends the coefficients of f, d bits each, least
+// significant bit first, FIPS 203 algorithm 5
+func byteEncode(b []byte, f *[n]uint16, d uint) []byte {
+	var acc uint32
+	var bits uint
+	for _, c := range f {
+		acc |= uint32(c) << bits
+		bits += d
+		for bits >= 8 {
+			b = append(b, byte(acc))
+			acc >>= 8
+			bits -= 8
+		}
+	}
+	return b
+}
+
+// byteDecode reads 32 d bytes into coefficients of d bits, FIPS 203
+// algorithm 6. For d = 12 the values are not reduced, the caller checks
+// them against q.
+func byteDecode(b []byte, d uint) [n]uint16 {
+	var f [n]uint16
+	var acc uint32
+	var bits uint
+	i := 0
+	for _, x := range b[:32*d] {
+		acc |= uint32(x) << bits
+		bits += 8
+		for bits >= d {
+			f[i] = uint16(acc & (1<<d - 1))
+			acc >>= d
+			bits -= d
+			i++
+		}
+	}
+	return f
+}
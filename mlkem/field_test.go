@@ -0,0 +1,102 @@
+package mlkem
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func randomPoly(r *rand.Rand) poly {
+	var f poly
+	for i := range f {
+		f[i] = uint16(r.Intn(q))
+	}
+	return f
+}
+
+// schoolbook returns f * g modulo X^256 + 1, where X^256 wraps to -1
+func schoolbook(f, g poly) poly {
+	var h [2 * n]uint32
+	for i := range f {
+		for j := range g {
+			h[i+j] = (h[i+j] + uint32(f[i])*uint32(g[j])) % q
+		}
+	}
+	var r poly
+	for i := range r {
+		r[i] = sub(uint16(h[i]), uint16(h[i+n]))
+	}
+	return r
+}
+
+func TestNTT(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		f, g := randomPoly(r), randomPoly(r)
+		if inverseNTT(ntt(f)) != f {
+			t.Fatalf("testcase %d NTT does not invert", i)
+		}
+		if got := inverseNTT(nttMul(ntt(f), ntt(g))); got != schoolbook(f, g) {
+			t.Fatalf("testcase %d NTT product differs from the schoolbook one", i)
+		}
+	}
+	if zetas[1] != 1729 || gammas[0] != 17 {
+		t.Fatalf("expected zeta 1729 and gamma 17 but got %d and %d", zetas[1], gammas[0])
+	}
+}
+
+func TestCompress(t *testing.T) {
+	t.Parallel()
+	for _, d := range []uint{1, 4, 10, 11} {
+		// decompressing then compressing is the identity, and the other
+		// way round is off by at most round(q / 2^(d+1))
+		bound := (q + 1<<(d+1) - 1) >> (d + 1)
+		for x := uint16(0); x < q; x++ {
+			y := decompress(compress(x, d), d)
+			diff := int(x) - int(y)
+			if diff < 0 {
+				diff = -diff
+			}
+			if diff > q/2 {
+				diff = q - diff
+			}
+			if diff > int(bound) {
+				t.Fatalf("d = %d: %d came back as %d", d, x, y)
+			}
+		}
+		for y := uint16(0); y < 1<<d; y++ {
+			if got := compress(decompress(y, d), d); got != y {
+				t.Fatalf("d = %d: expected %d but got %d", d, y, got)
+			}
+		}
+	}
+}
+
+func TestByteEncode(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(2))
+	for _, d := range []uint{1, 4, 10, 12} {
+		var f [n]uint16
+		for i := range f {
+			f[i] = uint16(r.Intn(1 << d))
+		}
+		b := byteEncode(nil, &f, d)
+		if len(b) != 32*int(d) {
+			t.Fatalf("d = %d: expected %d bytes but got %d", d, 32*d, len(b))
+		}
+		if byteDecode(b, d) != f {
+			t.Fatalf("d = %d: decoding does not invert encoding", d)
+		}
+	}
+}
+
+func TestSampleCBD(t *testing.T) {
+	t.Parallel()
+	// coefficients are in [-eta, eta]
+	f := samplePolyCBD(make([]byte, 32), 0, eta1)
+	for i, c := range f {
+		if c > eta1 && c < q-eta1 {
+			t.Fatalf("coefficient %d is %d", i, c)
+		}
+	}
+}
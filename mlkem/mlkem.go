@@ -0,0 +1,200 @@
+// Package mlkem implements ML-KEM-768, the module lattice key
+// encapsulation mechanism of FIPS 203, derived from CRYSTALS-Kyber.
+//
+// ML-KEM is LWE over polynomials: the secret s and the errors are vectors
+// of 3 polynomials of Zq[X]/(X^256 + 1) with small coefficients, q =
+// 3329, and the public key is t = A s + e. The structure of the ring makes
+// the keys a kilobyte instead of the megabytes of plain LWE, and the
+// number theoretic transform multiplies polynomials in quasi-linear time.
+// K-PKE encrypts a 32 bytes message with it, and the Fujisaki-Okamoto
+// transform turns that into a KEM secure against chosen ciphertexts: the
+// encryption randomness is derived from the message, decapsulation
+// encrypts again what it decrypted and returns a pseudorandom key derived
+// from the secret z when the ciphertexts differ, the implicit rejection.
+//
+// This implementation is written to follow the pseudocode of FIPS 203
+// and is not constant time.
+package mlkem
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io"
+
+	"github.com/jvehent/badcrypto/sha3"
+)
+
+const (
+	// SeedSize is the size of the seed d || z of a decapsulation key
+	SeedSize = 64
+	// SharedKeySize is the size of the shared key
+	SharedKeySize = 32
+	// EncapsulationKeySize is the size of an encoded encapsulation key
+	EncapsulationKeySize = k*encodedPolySize + 32
+	// DecapsulationKeySize is the size of an encoded decapsulation key,
+	// in the expanded format of FIPS 203
+	DecapsulationKeySize = k*encodedPolySize + EncapsulationKeySize + 32 + 32
+	// CiphertextSize is the size of a ciphertext
+	CiphertextSize = 32*du*k + 32*dv
+)
+
+// EncapsulationKey is the public key of ML-KEM-768
+type EncapsulationKey struct {
+	pke pkeEncryptionKey
+	// h is H(ek), which binds the shared key to the recipient
+	h [32]byte
+}
+
+// DecapsulationKey is the private key of ML-KEM-768
+type DecapsulationKey struct {
+	ek EncapsulationKey
+	s  [k]nttPoly
+	z  [32]byte
+	// seed is d || z, unknown for keys parsed from the expanded format
+	seed []byte
+}
+
+// GenerateKey returns a new decapsulation key, from a seed of 64 bytes
+// read from rand
+func GenerateKey(rand io.Reader) (*DecapsulationKey, error) {
+	seed := make([]byte, SeedSize)
+	if _, err := io.ReadFull(rand, seed); err != nil {
+		return nil, err
+	}
+	return NewDecapsulationKey(seed)
+}
+
+// NewDecapsulationKey derives a decapsulation key from its 64 bytes seed
+// d || z, FIPS 203 algorithm 16
+func NewDecapsulationKey(seed []byte) (*DecapsulationKey, error) {
+	if len(seed) != SeedSize {
+		return nil, errors.New("mlkem: invalid seed length")
+	}
+	pke, s := pkeKeyGen(seed[:32])
+	dk := &DecapsulationKey{s: s, seed: append([]byte{}, seed...)}
+	dk.ek.pke = *pke
+	dk.ek.h = sha3.Sum256(pke.bytes())
+	copy(dk.z[:], seed[32:])
+	return dk, nil
+}
+
+// ParseDecapsulationKey decodes a decapsulation key in the expanded
+// format of FIPS 203, dk_pke || ek || H(ek) || z, after checking that the
+// encapsulation key is valid and matches its hash
+func ParseDecapsulationKey(b []byte) (*DecapsulationKey, error) {
+	if len(b) != DecapsulationKeySize {
+		return nil, errors.New("mlkem: invalid decapsulation key length")
+	}
+	ekBytes := b[k*encodedPolySize : k*encodedPolySize+EncapsulationKeySize]
+	ek, err := NewEncapsulationKey(ekBytes)
+	if err != nil {
+		return nil, err
+	}
+	h := b[k*encodedPolySize+EncapsulationKeySize:]
+	if subtle.ConstantTimeCompare(h[:32], ek.h[:]) != 1 {
+		return nil, errors.New("mlkem: decapsulation key hash mismatch")
+	}
+	dk := &DecapsulationKey{ek: *ek}
+	for i := range dk.s {
+		dk.s[i] = nttPoly(byteDecode(b[encodedPolySize*i:], 12))
+		for _, c := range dk.s[i] {
+			if c >= q {
+				return nil, errors.New("mlkem: invalid decapsulation key")
+			}
+		}
+	}
+	copy(dk.z[:], h[32:])
+	return dk, nil
+}
+
+// Seed returns the 64 bytes seed of the key, or nil if it was parsed from
+// the expanded format
+func (dk *DecapsulationKey) Seed() []byte {
+	if dk.seed == nil {
+		return nil
+	}
+	return append([]byte{}, dk.seed...)
+}
+
+// Bytes returns the key in the expanded format of FIPS 203
+func (dk *DecapsulationKey) Bytes() []byte {
+	b := make([]byte, 0, DecapsulationKeySize)
+	for i := range dk.s {
+		b = byteEncode(b, (*[n]uint16)(&dk.s[i]), 12)
+	}
+	b = append(b, dk.ek.pke.bytes()...)
+	b = append(b, dk.ek.h[:]...)
+	return append(b, dk.z[:]...)
+}
+
+// EncapsulationKey returns the public key of dk
+func (dk *DecapsulationKey) EncapsulationKey() *EncapsulationKey {
+	ek := dk.ek
+	return &ek
+}
+
+// NewEncapsulationKey decodes an encapsulation key, with the modulus check
+// of FIPS 203: every coefficient of t must be below q
+func NewEncapsulationKey(b []byte) (*EncapsulationKey, error) {
+	if len(b) != EncapsulationKeySize {
+		return nil, errors.New("mlkem: invalid encapsulation key length")
+	}
+	ek := &EncapsulationKey{}
+	for i := range ek.pke.t {
+		ek.pke.t[i] = nttPoly(byteDecode(b[encodedPolySize*i:], 12))
+		for _, c := range ek.pke.t[i] {
+			if c >= q {
+				return nil, errors.New("mlkem: invalid encapsulation key")
+			}
+		}
+	}
+	copy(ek.pke.rho[:], b[k*encodedPolySize:])
+	ek.pke.expandA()
+	ek.h = sha3.Sum256(b)
+	return ek, nil
+}
+
+// Bytes returns the encoding of ek
+func (ek *EncapsulationKey) Bytes() []byte {
+	return ek.pke.bytes()
+}
+
+// Encapsulate returns a shared key and the ciphertext that carries it to
+// the holder of the decapsulation key
+func (ek *EncapsulationKey) Encapsulate(rand io.Reader) (sharedKey, ciphertext []byte, err error) {
+	var m [32]byte
+	if _, err := io.ReadFull(rand, m[:]); err != nil {
+		return nil, nil, err
+	}
+	sharedKey, ciphertext = ek.encapsulate(m[:])
+	return sharedKey, ciphertext, nil
+}
+
+// encapsulate is FIPS 203 algorithm 17: (K, r) = G(m || H(ek)) and the
+// ciphertext encrypts m with r
+func (ek *EncapsulationKey) encapsulate(m []byte) (sharedKey, ciphertext []byte) {
+	g := sha3.Sum512(append(append([]byte{}, m...), ek.h[:]...))
+	return g[:32], ek.pke.encrypt(m, g[32:])
+}
+
+// Decapsulate returns the shared key of ciphertext. A ciphertext that was
+// not produced by Encapsulate gives a pseudorandom key rather than an
+// error, so that an attacker learns nothing from it.
+func (dk *DecapsulationKey) Decapsulate(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) != CiphertextSize {
+		return nil, errors.New("mlkem: invalid ciphertext length")
+	}
+	// FIPS 203 algorithm 18
+	m := pkeDecrypt(&dk.s, ciphertext)
+	g := sha3.Sum512(append(m, dk.ek.h[:]...))
+	key := g[:32]
+	rejection := make([]byte, SharedKeySize)
+	j := sha3.NewShake256()
+	j.Write(dk.z[:])
+	j.Write(ciphertext)
+	j.Read(rejection)
+	c := dk.ek.pke.encrypt(m[:32], g[32:])
+	equal := subtle.ConstantTimeCompare(c, ciphertext)
+	subtle.ConstantTimeCopy(1-equal, key, rejection)
+	return key, nil
+}
@@ -0,0 +1,161 @@
+package mlkem
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/jvehent/badcrypto/sha3"
+)
+
+func sequence(start byte, n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = start + byte(i)
+	}
+	return b
+}
+
+func TestKnownAnswer(t *testing.T) {
+	t.Parallel()
+	// the self test of the Go FIPS 140 module: d = 01..20, z = 21..40 and
+	// m = 41..60
+	dk, err := NewDecapsulationKey(sequence(1, SeedSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, c := dk.EncapsulationKey().encapsulate(sequence(0x41, 32))
+	expected := "5501fc523b745f41762a188de44a59b920f430146204ee4e793732396df7aa48"
+	if hex.EncodeToString(key) != expected {
+		t.Fatalf("expected %s but got %x", expected, key)
+	}
+	got, err := dk.Decapsulate(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(got) != expected {
+		t.Fatalf("expected %s but got %x", expected, got)
+	}
+}
+
+// TestAccumulated runs the accumulated vectors of the C2SP CCTV project,
+// generated with the FIPS 203 reference: keys, encapsulations and the
+// implicit rejection of random ciphertexts, all hashed together
+func TestAccumulated(t *testing.T) {
+	t.Parallel()
+	count, expected := 10000, "8a518cc63da366322a8e7a818c7a0d63483cb3528d34a4cf42f35d5ad73f22fc"
+	if testing.Short() {
+		count, expected = 100, "1114b1b6699ed191734fa339376afa7e285c9e6acf6ff0177d346696ce564415"
+	}
+	s := sha3.NewShake128()
+	o := sha3.NewShake128()
+	seed := make([]byte, SeedSize)
+	m := make([]byte, 32)
+	random := make([]byte, CiphertextSize)
+	for i := 0; i < count; i++ {
+		s.Read(seed)
+		dk, err := NewDecapsulationKey(seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ek := dk.EncapsulationKey()
+		o.Write(ek.Bytes())
+		s.Read(m)
+		key, c := ek.encapsulate(m)
+		o.Write(c)
+		o.Write(key)
+		got, err := dk.Decapsulate(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, key) {
+			t.Fatalf("vector %d expected %x but got %x", i, key, got)
+		}
+		s.Read(random)
+		rejected, err := dk.Decapsulate(random)
+		if err != nil {
+			t.Fatal(err)
+		}
+		o.Write(rejected)
+	}
+	if got := hex.EncodeToString(o.Sum(nil)); got != expected {
+		t.Fatalf("expected %s but got %s", expected, got)
+	}
+}
+
+func TestEncapsulate(t *testing.T) {
+	t.Parallel()
+	dk, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ek, err := NewEncapsulationKey(dk.EncapsulationKey().Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, c, err := ek.Encapsulate(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != SharedKeySize || len(c) != CiphertextSize {
+		t.Fatalf("expected sizes %d and %d but got %d and %d", SharedKeySize, CiphertextSize, len(key), len(c))
+	}
+	// the expanded format decapsulates the same
+	parsed, err := ParseDecapsulationKey(dk.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Seed() != nil || !bytes.Equal(dk.Seed(), dk.seed) {
+		t.Fatal("expected the seed of generated keys only")
+	}
+	for _, d := range []*DecapsulationKey{dk, parsed} {
+		got, err := d.Decapsulate(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, key) {
+			t.Fatalf("expected %x but got %x", key, got)
+		}
+	}
+	// a modified ciphertext gives the implicit rejection key J(z || c)
+	c[0] ^= 1
+	got, err := dk.Decapsulate(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rejection := make([]byte, 32)
+	sha3.ShakeSum256(rejection, append(append([]byte{}, dk.z[:]...), c...))
+	if !bytes.Equal(got, rejection) {
+		t.Fatalf("expected the rejection key %x but got %x", rejection, got)
+	}
+}
+
+func TestInvalidKeys(t *testing.T) {
+	t.Parallel()
+	dk, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ekBytes := dk.EncapsulationKey().Bytes()
+	// a coefficient of 4095 fails the modulus check
+	bad := append([]byte{}, ekBytes...)
+	bad[0], bad[1] = 0xff, bad[1]|0x0f
+	if _, err := NewEncapsulationKey(bad); err == nil {
+		t.Fatal("expected a coefficient above q to be rejected")
+	}
+	if _, err := NewEncapsulationKey(ekBytes[1:]); err == nil {
+		t.Fatal("expected a short key to be rejected")
+	}
+	dkBytes := dk.Bytes()
+	dkBytes[DecapsulationKeySize-33] ^= 1
+	if _, err := ParseDecapsulationKey(dkBytes); err == nil {
+		t.Fatal("expected a wrong hash to be rejected")
+	}
+	if _, err := NewDecapsulationKey(make([]byte, 32)); err == nil {
+		t.Fatal("expected a short seed to be rejected")
+	}
+	if _, err := dk.Decapsulate(make([]byte, CiphertextSize-1)); err == nil {
+		t.Fatal("expected a short ciphertext to be rejected")
+	}
+}
@@ -0,0 +1,138 @@
+package mlkem
+
+import "github.com/jvehent/badcrypto/sha3"
+
+// parameters of ML-KEM-768
+const (
+	k    = 3
+	eta1 = 2
+	eta2 = 2
+	du   = 10
+	dv   = 4
+)
+
+// encodedPolySize is the size of a polynomial with 12 bits coefficients
+const encodedPolySize = 384
+
+// pkeEncryptionKey is the K-PKE encryption key: t = A s + e in the NTT
+// domain, and the seed rho of the matrix A
+type pkeEncryptionKey struct {
+	t   [k]nttPoly
+	rho [32]byte
+	// a is expanded from rho once
+	a [k][k]nttPoly
+}
+
+// expandA samples the matrix A from rho, FIPS 203 algorithm 13 line 6
+func (ek *pkeEncryptionKey) expandA() {
+	for i := 0; i < k; i++ {
+		for j := 0; j < k; j++ {
+			ek.a[i][j] = sampleNTT(ek.rho[:], byte(j), byte(i))
+		}
+	}
+}
+
+// bytes returns the encoding of t followed by rho
+func (ek *pkeEncryptionKey) bytes() []byte {
+	b := make([]byte, 0, k*encodedPolySize+32)
+	for i := range ek.t {
+		b = byteEncode(b, (*[n]uint16)(&ek.t[i]), 12)
+	}
+	return append(b, ek.rho[:]...)
+}
+
+// pkeKeyGen returns the K-PKE keys derived from the seed d, FIPS 203
+// algorithm 13
+func pkeKeyGen(d []byte) (*pkeEncryptionKey, [k]nttPoly) {
+	// (rho, sigma) = G(d || k), the k byte separates the parameter sets
+	g := sha3.Sum512(append(append([]byte{}, d...), k))
+	ek := &pkeEncryptionKey{}
+	copy(ek.rho[:], g[:32])
+	sigma := g[32:]
+	ek.expandA()
+	var s, e [k]nttPoly
+	var counter byte
+	for i := range s {
+		s[i] = ntt(samplePolyCBD(sigma, counter, eta1))
+		counter++
+	}
+	for i := range e {
+		e[i] = ntt(samplePolyCBD(sigma, counter, eta1))
+		counter++
+	}
+	for i := range ek.t {
+		ek.t[i] = e[i]
+		for j := range s {
+			ek.t[i] = nttAdd(ek.t[i], nttMul(ek.a[i][j], s[j]))
+		}
+	}
+	return ek, s
+}
+
+// encrypt returns the K-PKE encryption of the 32 bytes m with the
+// randomness r, FIPS 203 algorithm 14
+func (ek *pkeEncryptionKey) encrypt(m, r []byte) []byte {
+	var y [k]nttPoly
+	var e1 [k]poly
+	var counter byte
+	for i := range y {
+		y[i] = ntt(samplePolyCBD(r, counter, eta1))
+		counter++
+	}
+	for i := range e1 {
+		e1[i] = samplePolyCBD(r, counter, eta2)
+		counter++
+	}
+	e2 := samplePolyCBD(r, counter, eta2)
+
+	c := make([]byte, 0, CiphertextSize)
+	// u = A^T y + e1, compressed to du bits
+	for i := 0; i < k; i++ {
+		var sum nttPoly
+		for j := 0; j < k; j++ {
+			sum = nttAdd(sum, nttMul(ek.a[j][i], y[j]))
+		}
+		u := polyAdd(inverseNTT(sum), e1[i])
+		for x := range u {
+			u[x] = compress(u[x], du)
+		}
+		c = byteEncode(c, (*[n]uint16)(&u), du)
+	}
+	// v = t^T y + e2 + mu, with each bit of m as 0 or q/2
+	var sum nttPoly
+	for j := 0; j < k; j++ {
+		sum = nttAdd(sum, nttMul(ek.t[j], y[j]))
+	}
+	mu := poly(byteDecode(m, 1))
+	for x := range mu {
+		mu[x] = decompress(mu[x], 1)
+	}
+	v := polyAdd(polyAdd(inverseNTT(sum), e2), mu)
+	for x := range v {
+		v[x] = compress(v[x], dv)
+	}
+	return byteEncode(c, (*[n]uint16)(&v), dv)
+}
+
+// pkeDecrypt returns the message of the ciphertext c, FIPS 203
+// algorithm 15: w = v - s^T u is m times q/2 plus a small error, and
+// rounds to m
+func pkeDecrypt(s *[k]nttPoly, c []byte) []byte {
+	var sum nttPoly
+	for i := 0; i < k; i++ {
+		u := poly(byteDecode(c[32*du*i:], du))
+		for x := range u {
+			u[x] = decompress(u[x], du)
+		}
+		sum = nttAdd(sum, nttMul(s[i], ntt(u)))
+	}
+	v := poly(byteDecode(c[32*du*k:], dv))
+	for x := range v {
+		v[x] = decompress(v[x], dv)
+	}
+	w := polySub(v, inverseNTT(sum))
+	for x := range w {
+		w[x] = compress(w[x], 1)
+	}
+	return byteEncode(nil, (*[n]uint16)(&w), 1)
+}
@@ -0,0 +1,55 @@
+package mlkem
+
+import "github.com/jvehent/badcrypto/sha3"
+
+// sampleNTT returns a uniform polynomial in the NTT domain from the
+// SHAKE128 stream of rho || j || i, FIPS 203 algorithm 7: each 3 bytes
+// give two 12 bits candidates, kept if they are below q
+func sampleNTT(rho []byte, j, i byte) nttPoly {
+	xof := sha3.NewShake128()
+	xof.Write(rho)
+	xof.Write([]byte{j, i})
+	var a nttPoly
+	var buf [168]byte
+	k := 0
+	for k < n {
+		xof.Read(buf[:])
+		for c := 0; c+3 <= len(buf) && k < n; c += 3 {
+			d1 := uint16(buf[c]) | uint16(buf[c+1]&0x0f)<<8
+			d2 := uint16(buf[c+1])>>4 | uint16(buf[c+2])<<4
+			if d1 < q {
+				a[k] = d1
+				k++
+			}
+			if d2 < q && k < n {
+				a[k] = d2
+				k++
+			}
+		}
+	}
+	return a
+}
+
+// samplePolyCBD returns a polynomial with coefficients from the centered
+// binomial distribution of parameter eta, the difference of two sums of
+// eta bits, from the SHAKE256 stream of s || b, FIPS 203 algorithm 8
+func samplePolyCBD(s []byte, b byte, eta int) poly {
+	buf := make([]byte, 64*eta)
+	h := sha3.NewShake256()
+	h.Write(s)
+	h.Write([]byte{b})
+	h.Read(buf)
+	bit := func(i int) uint16 {
+		return uint16(buf[i/8] >> uint(i%8) & 1)
+	}
+	var f poly
+	for i := range f {
+		var x, y uint16
+		for j := 0; j < eta; j++ {
+			x += bit(2*i*eta + j)
+			y += bit(2*i*eta + eta + j)
+		}
+		f[i] = sub(x, y)
+	}
+	return f
+}
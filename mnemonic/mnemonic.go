@@ -0,0 +1,147 @@
+// Package mnemonic implements the BIP 39 mnemonic sentences that encode
+// wallet entropy as a list of English words, and the derivation of a
+// BIP 32 seed from such a sentence and an optional passphrase.
+//
+// Each word encodes 11 bits. The entropy, 128 to 256 bits, is followed by
+// the first entropy/32 bits of its SHA-256 as a checksum, which catches
+// most typos but is no integrity protection. The seed is PBKDF2 of the
+// sentence itself, not of the entropy, so any passphrase gives a valid
+// wallet and a wrong one cannot be detected.
+package mnemonic
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/jvehent/badcrypto/hdkey"
+	"github.com/jvehent/badcrypto/kdf"
+)
+
+const (
+	// MinEntropyBits and MaxEntropyBits bound the entropy size, which must
+	// be a multiple of 32 bits
+	MinEntropyBits = 128
+	MaxEntropyBits = 256
+	// SeedSize is the size of the seed returned by Seed
+	SeedSize = 64
+	// seedIterations is the PBKDF2 iteration count fixed by BIP 39
+	seedIterations = 2048
+)
+
+// ErrChecksum is returned when the checksum bits of a mnemonic are wrong
+var ErrChecksum = errors.New("mnemonic: invalid checksum")
+
+func checkEntropySize(bits int) error {
+	if bits < MinEntropyBits || bits > MaxEntropyBits || bits%32 != 0 {
+		return fmt.Errorf("mnemonic: entropy of %d bits is not a multiple of 32 between %d and %d", bits, MinEntropyBits, MaxEntropyBits)
+	}
+	return nil
+}
+
+// NewEntropy reads bits of entropy from rand
+func NewEntropy(rand io.Reader, bits int) ([]byte, error) {
+	if err := checkEntropySize(bits); err != nil {
+		return nil, err
+	}
+	entropy := make([]byte, bits/8)
+	if _, err := io.ReadFull(rand, entropy); err != nil {
+		return nil, err
+	}
+	return entropy, nil
+}
+
+// Generate returns a new mnemonic of bits of entropy, 12 words for 128
+// bits up to 24 words for 256 bits
+func Generate(rand io.Reader, bits int) (string, error) {
+	entropy, err := NewEntropy(rand, bits)
+	if err != nil {
+		return "", err
+	}
+	return FromEntropy(entropy)
+}
+
+// FromEntropy encodes entropy as a mnemonic
+func FromEntropy(entropy []byte) (string, error) {
+	if err := checkEntropySize(8 * len(entropy)); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(entropy)
+	// the checksum is at most 8 bits, one byte of the hash is enough
+	data := append(append([]byte{}, entropy...), sum[0])
+	n := (8*len(entropy) + 8*len(entropy)/32) / 11
+	words := make([]string, n)
+	for i := range words {
+		words[i] = wordlist[bitsAt(data, 11*i)]
+	}
+	return strings.Join(words, " "), nil
+}
+
+// bitsAt returns the 11 bits of data starting at bit offset, big endian
+func bitsAt(data []byte, offset int) int {
+	v := 0
+	for i := offset; i < offset+11; i++ {
+		v = v<<1 | int(data[i/8]>>(7-uint(i%8))&1)
+	}
+	return v
+}
+
+// ToEntropy decodes a mnemonic back to its entropy and verifies its
+// checksum. Words must be lowercase and separated by whitespace.
+func ToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words)%3 != 0 || len(words) < 12 || len(words) > 24 {
+		return nil, fmt.Errorf("mnemonic: %d words is not a multiple of 3 between 12 and 24", len(words))
+	}
+	// 11 bits per word, rounded up so the checksum fits
+	data := make([]byte, (11*len(words)+7)/8)
+	for i, w := range words {
+		index := sort.SearchStrings(wordlist[:], w)
+		if index == len(wordlist) || wordlist[index] != w {
+			return nil, fmt.Errorf("mnemonic: unknown word %q at position %d", w, i+1)
+		}
+		for j := 0; j < 11; j++ {
+			if index>>(10-uint(j))&1 == 1 {
+				bit := 11*i + j
+				data[bit/8] |= 1 << (7 - uint(bit%8))
+			}
+		}
+	}
+	checksumBits := len(words) / 3
+	entropy := data[:4*checksumBits]
+	sum := sha256.Sum256(entropy)
+	checksum := data[4*checksumBits] >> (8 - uint(checksumBits))
+	if checksum != sum[0]>>(8-uint(checksumBits)) {
+		return nil, ErrChecksum
+	}
+	return entropy, nil
+}
+
+// Validate returns an error if mnemonic has an unknown word, the wrong
+// number of words or a bad checksum
+func Validate(mnemonic string) error {
+	_, err := ToEntropy(mnemonic)
+	return err
+}
+
+// Seed derives the 64 bytes BIP 32 seed of a mnemonic and passphrase with
+// PBKDF2-HMAC-SHA512. BIP 39 does not validate the mnemonic here, call
+// Validate first. Both strings should be NFKD normalized, which is a no-op
+// for ASCII but is left to the caller for other passphrases.
+func Seed(mnemonic, passphrase string) []byte {
+	sentence := strings.Join(strings.Fields(mnemonic), " ")
+	return kdf.PBKDF2([]byte(sentence), []byte("mnemonic"+passphrase), seedIterations, SeedSize, sha512.New)
+}
+
+// NewMasterKey validates mnemonic and returns the BIP 32 master key of its
+// seed
+func NewMasterKey(mnemonic, passphrase string) (*hdkey.Key, error) {
+	if err := Validate(mnemonic); err != nil {
+		return nil, err
+	}
+	return hdkey.NewMaster(Seed(mnemonic, passphrase))
+}
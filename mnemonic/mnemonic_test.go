@@ -0,0 +1,156 @@
+package mnemonic
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestWordlist(t *testing.T) {
+	t.Parallel()
+	prefixes := make(map[string]bool)
+	for i, w := range wordlist {
+		if i > 0 && wordlist[i-1] >= w {
+			t.Fatalf("word %d %q is not sorted after %q", i, w, wordlist[i-1])
+		}
+		prefix := w
+		if len(prefix) > 4 {
+			prefix = prefix[:4]
+		}
+		if prefixes[prefix] {
+			t.Fatalf("word %d %q has a duplicate prefix", i, w)
+		}
+		prefixes[prefix] = true
+	}
+}
+
+// test vectors of the BIP 39 reference implementation, all with the
+// passphrase TREZOR
+var vectors = []struct {
+	entropy, mnemonic, seed string
+}{
+	{
+		"00000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+	},
+	{
+		"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+		"legal winner thank year wave sausage worth useful legal winner thank yellow",
+		"2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+	},
+	{
+		"80808080808080808080808080808080",
+		"letter advice cage absurd amount doctor acoustic avoid letter advice cage above",
+		"d71de856f81a8acc65e6fc851a38d4d7ec216fd0796d0a6827a3ad6ed5511a30fa280f12eb2e47ed2ac03b5c462a0358d18d69fe4f985ec81778c1b370b652a8",
+	},
+	{
+		"ffffffffffffffffffffffffffffffff",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+		"ac27495480225222079d7be181583751e86f571027b0497b5b5d11218e0a8a13332572917f0f8e5a589620c6f15b11c61dee327651a14c34e18231052e48c069",
+	},
+	{
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art",
+		"bda85446c68413707090a52022edd26a1c9462295029f2e60cd7c4f2bbd3097170af7a4d73245cafa9c3cca8d561a7c3de6f5d4a10be8ed2a5e608d68f92fcc8",
+	},
+	{
+		"9e885d952ad362caeb4efe34a8e91bd2",
+		"ozone drill grab fiber curtain grace pudding thank cruise elder eight picnic",
+		"274ddc525802f7c828d8ef7ddbcdc5304e87ac3535913611fbbfa986d0c9e5476c91689f9c8a54fd55bd38606aa6a8595ad213d4c9c9f9aca3fb217069a41028",
+	},
+	{
+		"f585c11aec520db57dd353c69554b21a89b20fb0650966fa0a9d6f74fd989d8f",
+		"void come effort suffer camp survey warrior heavy shoot primary clutch crush open amazing screen patrol group space point ten exist slush involve unfold",
+		"01f5bced59dec48e362f2c45b5de68b9fd6c92c6634f44d6d40aab69056506f0e35524a518034ddc1192e1dacd32c1ed3eaa3c3b131c88ed8e7e54c49a5d0998",
+	},
+}
+
+func TestVectors(t *testing.T) {
+	t.Parallel()
+	for i, v := range vectors {
+		entropy, _ := hex.DecodeString(v.entropy)
+		m, err := FromEntropy(entropy)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if m != v.mnemonic {
+			t.Fatalf("testcase %d expected %q but got %q", i, v.mnemonic, m)
+		}
+		back, err := ToEntropy(m)
+		if err != nil {
+			t.Fatalf("testcase %d failed to decode with %v", i, err)
+		}
+		if hex.EncodeToString(back) != v.entropy {
+			t.Fatalf("testcase %d expected entropy %s but got %x", i, v.entropy, back)
+		}
+		seed := hex.EncodeToString(Seed(m, "TREZOR"))
+		if seed != v.seed {
+			t.Fatalf("testcase %d expected seed %s but got %s", i, v.seed, seed)
+		}
+	}
+}
+
+func TestNewMasterKey(t *testing.T) {
+	t.Parallel()
+	k, err := NewMasterKey(vectors[0].mnemonic, "TREZOR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "xprv9s21ZrQH143K3h3fDYiay8mocZ3afhfULfb5GX8kCBdno77K4HiA15Tg23wpbeF1pLfs1c5SPmYHrEpTuuRhxMwvKDwqdKiGJS9XFKzUsAF"
+	if k.String() != expected {
+		t.Fatalf("expected %s but got %s", expected, k.String())
+	}
+	if _, err := NewMasterKey("abandon abandon abandon", ""); err == nil {
+		t.Fatal("expected a short mnemonic to be rejected")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		mnemonic string
+		valid    bool
+	}{
+		{vectors[1].mnemonic, true},
+		{"  legal winner thank year wave sausage\tworth useful legal winner thank yellow\n", true},
+		// last word changed, the checksum no longer matches
+		{"legal winner thank year wave sausage worth useful legal winner thank year", false},
+		{"legal winner thank year wave sausage worth useful legal winner thank", false},
+		{"legal winner thank year wave sausage worth useful legal winner thank yellowish", false},
+		{"Legal winner thank year wave sausage worth useful legal winner thank yellow", false},
+		{strings.Repeat("abandon ", 15) + "abandon", false},
+		{"", false},
+	}
+	for i, tc := range testcases {
+		err := Validate(tc.mnemonic)
+		if (err == nil) != tc.valid {
+			t.Fatalf("testcase %d expected validity %v but got %v", i, tc.valid, err)
+		}
+	}
+	if Validate(strings.Repeat("abandon ", 11)+"abandon") != ErrChecksum {
+		t.Fatal("expected a checksum error")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+	for bits := MinEntropyBits; bits <= MaxEntropyBits; bits += 32 {
+		m, err := Generate(rand.Reader, bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(strings.Fields(m)); n != bits*3/32 {
+			t.Fatalf("expected %d words for %d bits but got %d", bits*3/32, bits, n)
+		}
+		if err := Validate(m); err != nil {
+			t.Fatalf("generated mnemonic %q is invalid: %v", m, err)
+		}
+	}
+	for _, bits := range []int{96, 136, 288} {
+		if _, err := Generate(rand.Reader, bits); err == nil {
+			t.Fatalf("expected %d bits to be rejected", bits)
+		}
+	}
+}
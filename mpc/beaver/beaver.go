@@ -0,0 +1,213 @@
+// Package beaver implements additive secret sharing over a prime field,
+// and the multiplication of shared values with Beaver triples, between
+// parties simulated by goroutines that talk over channels.
+//
+// A value x is shared among n parties as n random field elements that sum
+// to x: any n-1 of them are uniformly random and reveal nothing. Sums, and
+// products by public constants, are computed by each party on its own
+// shares. Multiplying two shared values needs interaction, and a triple
+// (a, b, c = ab) of shared random values prepared in advance, here by a
+// trusted dealer. To multiply x by y, the parties open d = x - a and e =
+// y - b, which a and b mask perfectly, and each computes its share of
+//
+//	xy = (d + a)(e + b) = de + d b + e a + c
+//
+// where de is public and added by a single party, and d b, e a and c are
+// linear in the shares. A triple must only be used once: two products
+// opened with the same a reveal the difference of their x.
+//
+// The protocol only resists semi honest parties, that follow it but try
+// to learn more from what they see. A malicious party can shift the result
+// by lying about its share of d or e, which the SPDZ protocol detects with
+// MACs on the shares.
+package beaver
+
+import (
+	cryptorand "crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// Prime is the Mersenne prime 2^127 - 1, a default field for the shares
+var Prime = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1))
+
+// Share returns n additive shares of secret modulo p: n-1 random field
+// elements, and the secret minus their sum
+func Share(rand io.Reader, p, secret *big.Int, n int) ([]*big.Int, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("beaver: invalid number of shares %d", n)
+	}
+	shares := make([]*big.Int, n)
+	last := new(big.Int).Mod(secret, p)
+	for i := 1; i < n; i++ {
+		s, err := cryptorand.Int(rand, p)
+		if err != nil {
+			return nil, err
+		}
+		shares[i] = s
+		last.Sub(last, s)
+	}
+	shares[0] = last.Mod(last, p)
+	return shares, nil
+}
+
+// Reconstruct returns the secret of shares, their sum modulo p
+func Reconstruct(p *big.Int, shares []*big.Int) *big.Int {
+	sum := new(big.Int)
+	for _, s := range shares {
+		sum.Add(sum, s)
+	}
+	return sum.Mod(sum, p)
+}
+
+// Triple is the share of a party of a Beaver triple: shares of random a
+// and b, and of their product c
+type Triple struct {
+	A, B, C *big.Int
+}
+
+// NewTriple plays the trusted dealer and returns the shares of a random
+// triple for n parties, triples[i] going to party i
+func NewTriple(rand io.Reader, p *big.Int, n int) ([]Triple, error) {
+	a, err := cryptorand.Int(rand, p)
+	if err != nil {
+		return nil, err
+	}
+	b, err := cryptorand.Int(rand, p)
+	if err != nil {
+		return nil, err
+	}
+	c := new(big.Int).Mul(a, b)
+	var shares [3][]*big.Int
+	for i, x := range []*big.Int{a, b, c} {
+		if shares[i], err = Share(rand, p, x, n); err != nil {
+			return nil, err
+		}
+	}
+	triples := make([]Triple, n)
+	for i := range triples {
+		triples[i] = Triple{A: shares[0][i], B: shares[1][i], C: shares[2][i]}
+	}
+	return triples, nil
+}
+
+// Party is one of the parties of a computation. Its methods take and
+// return its shares, and those that open values must be called by every
+// party, in the same order, from its own goroutine.
+type Party struct {
+	// ID is the index of the party, from 0
+	ID int
+	p  *big.Int
+	// send[j] and recv[j] are the channels to and from party j, nil for
+	// the party itself
+	send []chan<- []*big.Int
+	recv []<-chan []*big.Int
+}
+
+// NewParties returns n parties computing modulo the prime p, connected
+// to each other by channels
+func NewParties(n int, p *big.Int) ([]*Party, error) {
+	if n < 2 {
+		return nil, fmt.Errorf("beaver: a computation needs at least 2 parties, not %d", n)
+	}
+	parties := make([]*Party, n)
+	for i := range parties {
+		parties[i] = &Party{
+			ID:   i,
+			p:    p,
+			send: make([]chan<- []*big.Int, n),
+			recv: make([]<-chan []*big.Int, n),
+		}
+	}
+	for i := range parties {
+		for j := range parties {
+			if i == j {
+				continue
+			}
+			// a party sends before it receives, and may get one opening
+			// ahead of a peer that has not read the previous one yet
+			c := make(chan []*big.Int, 2)
+			parties[i].send[j] = c
+			parties[j].recv[i] = c
+		}
+	}
+	return parties, nil
+}
+
+// Add returns the share of x + y
+func (party *Party) Add(x, y *big.Int) *big.Int {
+	z := new(big.Int).Add(x, y)
+	return z.Mod(z, party.p)
+}
+
+// Sub returns the share of x - y
+func (party *Party) Sub(x, y *big.Int) *big.Int {
+	z := new(big.Int).Sub(x, y)
+	return z.Mod(z, party.p)
+}
+
+// AddConstant returns the share of x + c for a public c, which only party
+// 0 adds
+func (party *Party) AddConstant(x, c *big.Int) *big.Int {
+	if party.ID != 0 {
+		return new(big.Int).Set(x)
+	}
+	return party.Add(x, c)
+}
+
+// MulConstant returns the share of c * x for a public c
+func (party *Party) MulConstant(x, c *big.Int) *big.Int {
+	z := new(big.Int).Mul(x, c)
+	return z.Mod(z, party.p)
+}
+
+// Open sends the shares of the party to every other party, and returns
+// the values they reconstruct
+func (party *Party) Open(shares ...*big.Int) ([]*big.Int, error) {
+	for _, c := range party.send {
+		if c != nil {
+			c <- shares
+		}
+	}
+	values := make([]*big.Int, len(shares))
+	for i, s := range shares {
+		values[i] = new(big.Int).Set(s)
+	}
+	for j, c := range party.recv {
+		if c == nil {
+			continue
+		}
+		peer := <-c
+		if len(peer) != len(shares) {
+			return nil, fmt.Errorf("beaver: party %d opened %d values instead of %d", j, len(peer), len(shares))
+		}
+		for i, s := range peer {
+			values[i].Add(values[i], s)
+		}
+	}
+	for _, v := range values {
+		v.Mod(v, party.p)
+	}
+	return values, nil
+}
+
+// Mul returns the share of x * y, consuming the triple t which must not
+// be used again
+func (party *Party) Mul(x, y *big.Int, t Triple) (*big.Int, error) {
+	if t.A == nil || t.B == nil || t.C == nil {
+		return nil, errors.New("beaver: incomplete triple")
+	}
+	opened, err := party.Open(party.Sub(x, t.A), party.Sub(y, t.B))
+	if err != nil {
+		return nil, err
+	}
+	d, e := opened[0], opened[1]
+	// c + d b + e a, and d e once
+	z := new(big.Int).Set(t.C)
+	z.Add(z, new(big.Int).Mul(d, t.B))
+	z.Add(z, new(big.Int).Mul(e, t.A))
+	z.Mod(z, party.p)
+	return party.AddConstant(z, new(big.Int).Mul(d, e)), nil
+}
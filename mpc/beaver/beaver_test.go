@@ -0,0 +1,179 @@
+package beaver
+
+import (
+	"crypto/rand"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+func TestShare(t *testing.T) {
+	t.Parallel()
+	secret := big.NewInt(424242)
+	for n := 1; n <= 5; n++ {
+		shares, err := Share(rand.Reader, Prime, secret, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(shares) != n {
+			t.Fatalf("expected %d shares but got %d", n, len(shares))
+		}
+		if s := Reconstruct(Prime, shares); s.Cmp(secret) != 0 {
+			t.Fatalf("expected %d parties to reconstruct %d but got %d", n, secret, s)
+		}
+		if n > 1 && Reconstruct(Prime, shares[1:]).Cmp(secret) == 0 {
+			t.Fatalf("expected %d shares out of %d not to reveal the secret", n-1, n)
+		}
+	}
+	if _, err := Share(rand.Reader, Prime, secret, 0); err == nil {
+		t.Fatal("expected an error for zero shares")
+	}
+}
+
+// run calls f in a goroutine per party and returns the values it opens
+func run(t *testing.T, parties []*Party, f func(party *Party) (*big.Int, error)) []*big.Int {
+	results := make([]*big.Int, len(parties))
+	errs := make([]error, len(parties))
+	var wg sync.WaitGroup
+	for i, party := range parties {
+		wg.Add(1)
+		go func(i int, party *Party) {
+			defer wg.Done()
+			results[i], errs[i] = f(party)
+		}(i, party)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return results
+}
+
+func TestMul(t *testing.T) {
+	t.Parallel()
+	p := big.NewInt(1000003)
+	for n := 2; n <= 3; n++ {
+		parties, err := NewParties(n, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// (x * y + 5) * z with x = 1234, y = 5678 and z = 91011
+		var inputs [3][]*big.Int
+		for i, v := range []int64{1234, 5678, 91011} {
+			if inputs[i], err = Share(rand.Reader, p, big.NewInt(v), n); err != nil {
+				t.Fatal(err)
+			}
+		}
+		var triples [2][]Triple
+		for i := range triples {
+			if triples[i], err = NewTriple(rand.Reader, p, n); err != nil {
+				t.Fatal(err)
+			}
+		}
+		results := run(t, parties, func(party *Party) (*big.Int, error) {
+			i := party.ID
+			xy, err := party.Mul(inputs[0][i], inputs[1][i], triples[0][i])
+			if err != nil {
+				return nil, err
+			}
+			xy = party.AddConstant(xy, big.NewInt(5))
+			r, err := party.Mul(xy, inputs[2][i], triples[1][i])
+			if err != nil {
+				return nil, err
+			}
+			opened, err := party.Open(r)
+			if err != nil {
+				return nil, err
+			}
+			return opened[0], nil
+		})
+		expected := big.NewInt(1234*5678 + 5)
+		expected.Mul(expected, big.NewInt(91011))
+		expected.Mod(expected, p)
+		for i, r := range results {
+			if r.Cmp(expected) != 0 {
+				t.Fatalf("party %d of %d expected %d but got %d", i, n, expected, r)
+			}
+		}
+	}
+}
+
+func TestLinear(t *testing.T) {
+	t.Parallel()
+	parties, err := NewParties(3, Prime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := Share(rand.Reader, Prime, big.NewInt(100), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := Share(rand.Reader, Prime, big.NewInt(30), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 3 * (x - y) + 7 - x
+	results := run(t, parties, func(party *Party) (*big.Int, error) {
+		i := party.ID
+		z := party.MulConstant(party.Sub(x[i], y[i]), big.NewInt(3))
+		z = party.Sub(party.AddConstant(z, big.NewInt(7)), x[i])
+		opened, err := party.Open(z, party.Add(x[i], y[i]))
+		if err != nil {
+			return nil, err
+		}
+		return opened[0].Add(opened[0], opened[1]), nil
+	})
+	for i, r := range results {
+		if r.Int64() != 117+130 {
+			t.Fatalf("party %d expected %d but got %d", i, 117+130, r)
+		}
+	}
+}
+
+// TestTripleReuse shows why a triple must only be used once: the openings
+// of x - a and x' - a differ by x - x'
+func TestTripleReuse(t *testing.T) {
+	t.Parallel()
+	parties, err := NewParties(2, Prime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	triple, err := NewTriple(rand.Reader, Prime, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, _ := Share(rand.Reader, Prime, big.NewInt(1000), 2)
+	x2, _ := Share(rand.Reader, Prime, big.NewInt(1337), 2)
+	results := run(t, parties, func(party *Party) (*big.Int, error) {
+		i := party.ID
+		d, err := party.Open(party.Sub(x[i], triple[i].A))
+		if err != nil {
+			return nil, err
+		}
+		d2, err := party.Open(party.Sub(x2[i], triple[i].A))
+		if err != nil {
+			return nil, err
+		}
+		diff := new(big.Int).Sub(d2[0], d[0])
+		return diff.Mod(diff, Prime), nil
+	})
+	if results[0].Int64() != 337 {
+		t.Fatalf("expected the openings to differ by 337 but got %d", results[0])
+	}
+}
+
+func TestNewParties(t *testing.T) {
+	t.Parallel()
+	if _, err := NewParties(1, Prime); err == nil {
+		t.Fatal("expected an error for a single party")
+	}
+	parties, err := NewParties(2, Prime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parties[0].Mul(big.NewInt(1), big.NewInt(2), Triple{}); err == nil {
+		t.Fatal("expected an error for an empty triple")
+	}
+}
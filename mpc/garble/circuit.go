@@ -0,0 +1,181 @@
+package garble
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Op is the boolean function of a gate
+type Op uint8
+
+// Supported gates. XOR and INV are free, AND costs two ciphertexts.
+const (
+	XOR Op = iota
+	AND
+	INV
+)
+
+// Gate reads the wires In and writes the wire Out. INV only reads In[0].
+type Gate struct {
+	Op  Op
+	In  [2]int
+	Out int
+}
+
+// Circuit is a boolean circuit whose gates are in topological order
+type Circuit struct {
+	NumWires        int
+	GarblerInputs   []int
+	EvaluatorInputs []int
+	Outputs         []int
+	Gates           []Gate
+}
+
+// ParseBristol reads a circuit in the Bristol format: a header with the
+// number of gates and wires, a line with the number of input bits of each
+// party and of output bits, then one gate per line such as "2 1 0 1 2 AND".
+// Garbler inputs come first, then evaluator inputs, and the outputs are
+// the last wires.
+func ParseBristol(r io.Reader) (*Circuit, error) {
+	var fields [][]string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if f := strings.Fields(scanner.Text()); len(f) > 0 {
+			fields = append(fields, f)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(fields) < 2 || len(fields[0]) != 2 || len(fields[1]) != 3 {
+		return nil, errors.New("garble: invalid bristol header")
+	}
+	header, err := atois(append(fields[0], fields[1]...))
+	if err != nil {
+		return nil, err
+	}
+	numGates, numWires, n1, n2, n3 := header[0], header[1], header[2], header[3], header[4]
+	if len(fields)-2 != numGates {
+		return nil, fmt.Errorf("garble: expected %d gates but found %d", numGates, len(fields)-2)
+	}
+	if n1+n2 > numWires || n3 > numWires {
+		return nil, errors.New("garble: more inputs or outputs than wires")
+	}
+	c := &Circuit{NumWires: numWires}
+	for i := 0; i < n1; i++ {
+		c.GarblerInputs = append(c.GarblerInputs, i)
+	}
+	for i := n1; i < n1+n2; i++ {
+		c.EvaluatorInputs = append(c.EvaluatorInputs, i)
+	}
+	for i := numWires - n3; i < numWires; i++ {
+		c.Outputs = append(c.Outputs, i)
+	}
+	for i, f := range fields[2:] {
+		g, err := parseGate(f)
+		if err != nil {
+			return nil, fmt.Errorf("garble: gate %d: %v", i, err)
+		}
+		c.Gates = append(c.Gates, g)
+	}
+	return c, c.validate()
+}
+
+func atois(fields []string) ([]int, error) {
+	out := make([]int, len(fields))
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("garble: invalid number %q", f)
+		}
+		out[i] = n
+	}
+	return out, nil
+}
+
+func parseGate(f []string) (Gate, error) {
+	if len(f) < 4 {
+		return Gate{}, errors.New("truncated gate")
+	}
+	nums, err := atois(f[:len(f)-1])
+	if err != nil {
+		return Gate{}, err
+	}
+	op := f[len(f)-1]
+	switch {
+	case (op == "XOR" || op == "AND") && len(nums) == 5 && nums[0] == 2 && nums[1] == 1:
+		g := Gate{Op: XOR, In: [2]int{nums[2], nums[3]}, Out: nums[4]}
+		if op == "AND" {
+			g.Op = AND
+		}
+		return g, nil
+	case op == "INV" && len(nums) == 4 && nums[0] == 1 && nums[1] == 1:
+		return Gate{Op: INV, In: [2]int{nums[2], 0}, Out: nums[3]}, nil
+	}
+	return Gate{}, fmt.Errorf("unsupported gate %q", strings.Join(f, " "))
+}
+
+// validate checks that every gate only reads wires that were set before
+func (c *Circuit) validate() error {
+	set := make([]bool, c.NumWires)
+	for _, w := range append(append([]int{}, c.GarblerInputs...), c.EvaluatorInputs...) {
+		if w < 0 || w >= c.NumWires {
+			return fmt.Errorf("garble: input wire %d out of range", w)
+		}
+		set[w] = true
+	}
+	for i, g := range c.Gates {
+		ins := g.In[:]
+		if g.Op == INV {
+			ins = g.In[:1]
+		}
+		for _, w := range ins {
+			if w < 0 || w >= c.NumWires || !set[w] {
+				return fmt.Errorf("garble: gate %d reads unset wire %d", i, w)
+			}
+		}
+		if g.Out < 0 || g.Out >= c.NumWires {
+			return fmt.Errorf("garble: gate %d writes wire %d out of range", i, g.Out)
+		}
+		set[g.Out] = true
+	}
+	for _, w := range c.Outputs {
+		if w < 0 || w >= c.NumWires || !set[w] {
+			return fmt.Errorf("garble: output wire %d is never set", w)
+		}
+	}
+	return nil
+}
+
+// Eval computes the circuit in the clear, as a reference
+func (c *Circuit) Eval(garbler, evaluator []bool) ([]bool, error) {
+	if len(garbler) != len(c.GarblerInputs) || len(evaluator) != len(c.EvaluatorInputs) {
+		return nil, errors.New("garble: wrong number of input bits")
+	}
+	wires := make([]bool, c.NumWires)
+	for i, w := range c.GarblerInputs {
+		wires[w] = garbler[i]
+	}
+	for i, w := range c.EvaluatorInputs {
+		wires[w] = evaluator[i]
+	}
+	for _, g := range c.Gates {
+		switch g.Op {
+		case XOR:
+			wires[g.Out] = wires[g.In[0]] != wires[g.In[1]]
+		case AND:
+			wires[g.Out] = wires[g.In[0]] && wires[g.In[1]]
+		case INV:
+			wires[g.Out] = !wires[g.In[0]]
+		}
+	}
+	out := make([]bool, len(c.Outputs))
+	for i, w := range c.Outputs {
+		out[i] = wires[w]
+	}
+	return out, nil
+}
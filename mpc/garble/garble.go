@@ -0,0 +1,210 @@
+// Package garble implements Yao's garbled circuits for two party
+// computation of boolean circuits, with the free-XOR, point-and-permute
+// and half-gates optimizations: XOR and INV gates cost nothing and AND
+// gates two ciphertexts.
+//
+// The garbler picks two labels per wire, one for each bit, and encrypts
+// the gates. It sends the garbled circuit and the labels of its own inputs,
+// the evaluator obtains the labels of its inputs through oblivious transfer
+// (see the ot package), evaluates the circuit on labels, and decodes the
+// outputs. Security only holds against semi honest parties.
+package garble
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// LabelSize is the size of wire labels, the security parameter in bytes
+const LabelSize = 16
+
+type label [LabelSize]byte
+
+func (l label) xor(m label) label {
+	for i := range l {
+		l[i] ^= m[i]
+	}
+	return l
+}
+
+// lsb is the permute bit of the label
+func (l label) lsb() bool {
+	return l[0]&1 == 1
+}
+
+// hash is the random oracle of the half gates, tweaked by the gate
+func hash(l label, tweak uint64) label {
+	var t [8]byte
+	binary.BigEndian.PutUint64(t[:], tweak)
+	h := sha256.New()
+	h.Write(t[:])
+	h.Write(l[:])
+	var out label
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+// GarbledCircuit is what the garbler sends to the evaluator
+type GarbledCircuit struct {
+	// Tables holds the two half gate ciphertexts of every AND gate, in
+	// order
+	Tables [][2][LabelSize]byte
+	// Decoding holds the permute bit of the zero label of each output
+	Decoding []bool
+}
+
+// Garbler holds the secret labels of a garbling
+type Garbler struct {
+	circuit *Circuit
+	delta   label
+	zeros   []label
+}
+
+// Garble garbles the circuit. The Garbler keeps the secrets to encode the
+// inputs, the GarbledCircuit goes to the evaluator.
+func Garble(rand io.Reader, c *Circuit) (*Garbler, *GarbledCircuit, error) {
+	g := &Garbler{circuit: c, zeros: make([]label, c.NumWires)}
+	// the global offset has its permute bit set, so the two labels of a
+	// wire always have different permute bits
+	if _, err := io.ReadFull(rand, g.delta[:]); err != nil {
+		return nil, nil, err
+	}
+	g.delta[0] |= 1
+	for _, w := range append(append([]int{}, c.GarblerInputs...), c.EvaluatorInputs...) {
+		if _, err := io.ReadFull(rand, g.zeros[w][:]); err != nil {
+			return nil, nil, err
+		}
+	}
+	gc := new(GarbledCircuit)
+	for i, gate := range c.Gates {
+		a := g.zeros[gate.In[0]]
+		switch gate.Op {
+		case XOR:
+			g.zeros[gate.Out] = a.xor(g.zeros[gate.In[1]])
+		case INV:
+			g.zeros[gate.Out] = a.xor(g.delta)
+		case AND:
+			b := g.zeros[gate.In[1]]
+			j0, j1 := uint64(2*i), uint64(2*i+1)
+			pa, pb := a.lsb(), b.lsb()
+			// generator half gate, the garbler knows pb
+			tg := hash(a, j0).xor(hash(a.xor(g.delta), j0))
+			if pb {
+				tg = tg.xor(g.delta)
+			}
+			wg := hash(a, j0)
+			if pa {
+				wg = wg.xor(tg)
+			}
+			// evaluator half gate, the evaluator knows b
+			te := hash(b, j1).xor(hash(b.xor(g.delta), j1)).xor(a)
+			we := hash(b, j1)
+			if pb {
+				we = we.xor(te.xor(a))
+			}
+			g.zeros[gate.Out] = wg.xor(we)
+			gc.Tables = append(gc.Tables, [2][LabelSize]byte{tg, te})
+		}
+	}
+	for _, w := range c.Outputs {
+		gc.Decoding = append(gc.Decoding, g.zeros[w].lsb())
+	}
+	return g, gc, nil
+}
+
+func (g *Garbler) encode(wire int, bit bool) []byte {
+	l := g.zeros[wire]
+	if bit {
+		l = l.xor(g.delta)
+	}
+	return append([]byte{}, l[:]...)
+}
+
+// GarblerLabels encodes the garbler inputs, the labels reveal nothing
+// about the bits to the evaluator
+func (g *Garbler) GarblerLabels(bits []bool) ([][]byte, error) {
+	if len(bits) != len(g.circuit.GarblerInputs) {
+		return nil, errors.New("garble: wrong number of garbler input bits")
+	}
+	out := make([][]byte, len(bits))
+	for i, w := range g.circuit.GarblerInputs {
+		out[i] = g.encode(w, bits[i])
+	}
+	return out, nil
+}
+
+// EvaluatorLabelPairs returns the zero and one labels of every evaluator
+// input, the messages of the oblivious transfers
+func (g *Garbler) EvaluatorLabelPairs() [][2][]byte {
+	out := make([][2][]byte, len(g.circuit.EvaluatorInputs))
+	for i, w := range g.circuit.EvaluatorInputs {
+		out[i] = [2][]byte{g.encode(w, false), g.encode(w, true)}
+	}
+	return out
+}
+
+func toLabel(b []byte) (label, error) {
+	var l label
+	if len(b) != LabelSize {
+		return l, errors.New("garble: invalid label size")
+	}
+	copy(l[:], b)
+	return l, nil
+}
+
+// Evaluate runs the garbled circuit on one label per input wire and
+// decodes the outputs
+func Evaluate(c *Circuit, gc *GarbledCircuit, garblerLabels, evaluatorLabels [][]byte) ([]bool, error) {
+	if len(garblerLabels) != len(c.GarblerInputs) || len(evaluatorLabels) != len(c.EvaluatorInputs) {
+		return nil, errors.New("garble: wrong number of input labels")
+	}
+	if len(gc.Decoding) != len(c.Outputs) {
+		return nil, errors.New("garble: garbled circuit does not match the circuit")
+	}
+	wires := make([]label, c.NumWires)
+	var err error
+	for i, w := range c.GarblerInputs {
+		if wires[w], err = toLabel(garblerLabels[i]); err != nil {
+			return nil, err
+		}
+	}
+	for i, w := range c.EvaluatorInputs {
+		if wires[w], err = toLabel(evaluatorLabels[i]); err != nil {
+			return nil, err
+		}
+	}
+	table := 0
+	for i, gate := range c.Gates {
+		a := wires[gate.In[0]]
+		switch gate.Op {
+		case XOR:
+			wires[gate.Out] = a.xor(wires[gate.In[1]])
+		case INV:
+			// the garbler flipped the meaning of the label
+			wires[gate.Out] = a
+		case AND:
+			if table >= len(gc.Tables) {
+				return nil, errors.New("garble: missing garbled tables")
+			}
+			b := wires[gate.In[1]]
+			tg, te := label(gc.Tables[table][0]), label(gc.Tables[table][1])
+			table++
+			wg := hash(a, uint64(2*i))
+			if a.lsb() {
+				wg = wg.xor(tg)
+			}
+			we := hash(b, uint64(2*i+1))
+			if b.lsb() {
+				we = we.xor(te.xor(a))
+			}
+			wires[gate.Out] = wg.xor(we)
+		}
+	}
+	out := make([]bool, len(c.Outputs))
+	for i, w := range c.Outputs {
+		out[i] = wires[w].lsb() != gc.Decoding[i]
+	}
+	return out, nil
+}
@@ -0,0 +1,211 @@
+package garble
+
+import (
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/ot"
+)
+
+// adder builds a ripple carry adder of two n bits numbers, garbler input
+// first, with n+1 output bits
+func adder(n int) *Circuit {
+	c := &Circuit{NumWires: 2 * n}
+	for i := 0; i < n; i++ {
+		c.GarblerInputs = append(c.GarblerInputs, i)
+		c.EvaluatorInputs = append(c.EvaluatorInputs, n+i)
+	}
+	wire := func() int { c.NumWires++; return c.NumWires - 1 }
+	gate := func(op Op, a, b int) int {
+		out := wire()
+		c.Gates = append(c.Gates, Gate{Op: op, In: [2]int{a, b}, Out: out})
+		return out
+	}
+	carry := -1
+	for i := 0; i < n; i++ {
+		a, b := i, n+i
+		axb := gate(XOR, a, b)
+		if carry < 0 {
+			c.Outputs = append(c.Outputs, axb)
+			carry = gate(AND, a, b)
+			continue
+		}
+		c.Outputs = append(c.Outputs, gate(XOR, axb, carry))
+		// carry = (a and b) xor (carry and (a xor b))
+		carry = gate(XOR, gate(AND, a, b), gate(AND, carry, axb))
+	}
+	c.Outputs = append(c.Outputs, carry)
+	return c
+}
+
+func toBits(x uint64, n int) []bool {
+	bits := make([]bool, n)
+	for i := range bits {
+		bits[i] = x>>uint(i)&1 == 1
+	}
+	return bits
+}
+
+func fromBits(bits []bool) uint64 {
+	var x uint64
+	for i, b := range bits {
+		if b {
+			x |= 1 << uint(i)
+		}
+	}
+	return x
+}
+
+func TestAdder(t *testing.T) {
+	t.Parallel()
+	c := adder(16)
+	var testcases = []struct{ a, b uint64 }{
+		{0, 0}, {1, 1}, {12345, 54321}, {0xffff, 0xffff}, {0x8000, 0x7fff},
+	}
+	for i, tc := range testcases {
+		g, gc, err := Garble(rand.Reader, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		glabels, err := g.GarblerLabels(toBits(tc.a, 16))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var elabels [][]byte
+		for j, pair := range g.EvaluatorLabelPairs() {
+			if toBits(tc.b, 16)[j] {
+				elabels = append(elabels, pair[1])
+			} else {
+				elabels = append(elabels, pair[0])
+			}
+		}
+		out, err := Evaluate(c, gc, glabels, elabels)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fromBits(out); got != tc.a+tc.b {
+			t.Fatalf("testcase %d expected %d but got %d", i, tc.a+tc.b, got)
+		}
+		clear, _ := c.Eval(toBits(tc.a, 16), toBits(tc.b, 16))
+		if fromBits(clear) != tc.a+tc.b {
+			t.Fatalf("testcase %d expected the clear evaluation to match", i)
+		}
+	}
+}
+
+// a garbled millionaires' problem: is a > b for 4 bits numbers, written
+// in Bristol format. The output is the carry of a + not(b), computed bit
+// by bit as c = c xor ((a xor c) and not(b xor c)).
+const greaterThan = `17 25
+4 4 1
+
+1 1 4 8 INV
+2 1 0 8 9 AND
+2 1 1 9 10 XOR
+2 1 5 9 11 XOR
+1 1 11 12 INV
+2 1 10 12 13 AND
+2 1 13 9 14 XOR
+2 1 2 14 15 XOR
+2 1 6 14 16 XOR
+1 1 16 17 INV
+2 1 15 17 18 AND
+2 1 18 14 19 XOR
+2 1 3 19 20 XOR
+2 1 7 19 21 XOR
+1 1 21 22 INV
+2 1 20 22 23 AND
+2 1 23 19 24 XOR
+`
+
+func TestBristol(t *testing.T) {
+	t.Parallel()
+	c, err := ParseBristol(strings.NewReader(greaterThan))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.GarblerInputs) != 4 || len(c.EvaluatorInputs) != 4 || len(c.Outputs) != 1 {
+		t.Fatal("expected 4 bits inputs and a single output")
+	}
+	for a := uint64(0); a < 16; a++ {
+		for b := uint64(0); b < 16; b++ {
+			clear, err := c.Eval(toBits(a, 4), toBits(b, 4))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if clear[0] != (a > b) {
+				t.Fatalf("expected %d > %d to be %v", a, b, a > b)
+			}
+			g, gc, _ := Garble(rand.Reader, c)
+			glabels, _ := g.GarblerLabels(toBits(a, 4))
+			var elabels [][]byte
+			for j, pair := range g.EvaluatorLabelPairs() {
+				if toBits(b, 4)[j] {
+					elabels = append(elabels, pair[1])
+				} else {
+					elabels = append(elabels, pair[0])
+				}
+			}
+			out, err := Evaluate(c, gc, glabels, elabels)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out[0] != (a > b) {
+				t.Fatalf("expected garbled evaluation of %d > %d to be %v", a, b, a > b)
+			}
+		}
+	}
+	for _, bad := range []string{
+		"1 3\n1 1 1\n2 1 0 1 2 NAND\n",
+		"1 3\n1 1 1\n2 1 0 2 2 AND\n",
+		"2 3\n1 1 1\n2 1 0 1 2 AND\n",
+	} {
+		if _, err := ParseBristol(strings.NewReader(bad)); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+// TestWithOT transfers the evaluator labels with oblivious transfer, so
+// the garbler never learns the evaluator input
+func TestWithOT(t *testing.T) {
+	t.Parallel()
+	c := adder(8)
+	a, b := uint64(200), uint64(99)
+	g, gc, err := Garble(rand.Reader, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	curve := ec.P256()
+	sender, err := ot.NewSender(rand.Reader, curve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	choices := toBits(b, 8)
+	B, rkeys, err := ot.Choose(rand.Reader, curve, sender.A, choices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	skeys, err := sender.Keys(B)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cts, err := ot.Transfer(skeys, g.EvaluatorLabelPairs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	elabels, err := ot.Receive(rkeys, choices, cts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	glabels, _ := g.GarblerLabels(toBits(a, 8))
+	out, err := Evaluate(c, gc, glabels, elabels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fromBits(out); got != a+b {
+		t.Fatalf("expected %d but got %d", a+b, got)
+	}
+}
@@ -0,0 +1,217 @@
+// Package mss implements the Merkle signature scheme: a Merkle tree, from
+// package merkle, over the public keys of many Winternitz one-time keys,
+// from package otsig. The root is the public key, and a signature is a
+// one-time signature with the one-time public key and its inclusion proof.
+//
+// Each one-time key must sign only once, so the private key is stateful:
+// it holds the index of the next unused key, and that index must reach
+// stable storage before a signature leaves the signer. A crash between
+// signing and saving, a restored backup or two machines sharing the key
+// all sign twice with the same one-time key, which lets anyone forge.
+// The state is the only part of the key that changes, and is small: 8
+// bytes that State returns and Restore loads, refusing to go backwards.
+// The one-time keys are derived from a seed, and the tree is rebuilt when
+// the key is parsed.
+//
+// Package hashsig has XMSS, the same construction with WOTS+, and
+// SPHINCS-lite which needs no state at all.
+package mss
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/jvehent/badcrypto/merkle"
+	"github.com/jvehent/badcrypto/otsig"
+	"github.com/jvehent/badcrypto/sha3"
+)
+
+// MaxSize is the largest number of one-time keys of a key
+const MaxSize = 1 << 20
+
+// SeedSize is the size of the seed the one-time keys are derived from
+const SeedSize = 32
+
+var (
+	// ErrExhausted is returned when every one-time key has signed
+	ErrExhausted = errors.New("mss: every one-time key is used")
+	// ErrRollback is returned when restoring a state older than the
+	// current one, which would sign again with used one-time keys
+	ErrRollback = errors.New("mss: state would reuse one-time keys")
+)
+
+// PrivateKey is a Merkle signature scheme key. It must not be copied, and
+// its State must be saved after each signature.
+type PrivateKey struct {
+	w    int
+	seed []byte
+	next uint64
+	tree *merkle.Tree
+	root []byte
+}
+
+// GenerateKey returns a new key of size one-time Winternitz keys with w
+// bits per chain, from a seed read from rand
+func GenerateKey(rand io.Reader, size, w int) (*PrivateKey, error) {
+	seed := make([]byte, SeedSize)
+	if _, err := io.ReadFull(rand, seed); err != nil {
+		return nil, err
+	}
+	return newKey(seed, uint64(size), w)
+}
+
+func newKey(seed []byte, size uint64, w int) (*PrivateKey, error) {
+	if size < 1 || size > MaxSize {
+		return nil, errors.New("mss: invalid number of one-time keys")
+	}
+	key := &PrivateKey{w: w, seed: seed, tree: new(merkle.Tree)}
+	for i := uint64(0); i < size; i++ {
+		ots, err := key.oneTimeKey(i)
+		if err != nil {
+			return nil, err
+		}
+		key.tree.Append(ots.PublicKey())
+	}
+	key.root = key.tree.Root()
+	return key, nil
+}
+
+// oneTimeKey derives the one-time key at index from the SHAKE256 stream
+// of the seed and the index
+func (key *PrivateKey) oneTimeKey(index uint64) (*otsig.WinternitzPrivateKey, error) {
+	var i [8]byte
+	binary.BigEndian.PutUint64(i[:], index)
+	h := sha3.NewShake256()
+	h.Write(key.seed)
+	h.Write(i[:])
+	return otsig.GenerateWinternitzKey(h, key.w)
+}
+
+// PublicKey returns the public key: the number of one-time keys, w and
+// the root of the tree
+func (key *PrivateKey) PublicKey() []byte {
+	pub := make([]byte, 9, 9+sha256.Size)
+	binary.BigEndian.PutUint64(pub, key.tree.Size())
+	pub[8] = byte(key.w)
+	return append(pub, key.root...)
+}
+
+// Remaining returns the number of signatures left
+func (key *PrivateKey) Remaining() uint64 {
+	return key.tree.Size() - key.next
+}
+
+// State returns the index of the next unused one-time key, the part of
+// the key that changes with each signature
+func (key *PrivateKey) State() []byte {
+	state := make([]byte, 8)
+	binary.BigEndian.PutUint64(state, key.next)
+	return state
+}
+
+// Restore loads a state saved by State. It returns ErrRollback if the
+// state is older than the current one, and leaves the key unchanged.
+func (key *PrivateKey) Restore(state []byte) error {
+	if len(state) != 8 {
+		return errors.New("mss: invalid state")
+	}
+	next := binary.BigEndian.Uint64(state)
+	if next > key.tree.Size() {
+		return errors.New("mss: state beyond the last one-time key")
+	}
+	if next < key.next {
+		return ErrRollback
+	}
+	key.next = next
+	return nil
+}
+
+// MarshalBinary encodes the whole key: the number of one-time keys, w,
+// the seed and the state
+func (key *PrivateKey) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 9, 9+SeedSize+8)
+	binary.BigEndian.PutUint64(b, key.tree.Size())
+	b[8] = byte(key.w)
+	b = append(b, key.seed...)
+	return append(b, key.State()...), nil
+}
+
+// ParsePrivateKey decodes a key encoded by MarshalBinary, which rebuilds
+// its tree
+func ParsePrivateKey(b []byte) (*PrivateKey, error) {
+	if len(b) != 9+SeedSize+8 {
+		return nil, errors.New("mss: invalid private key length")
+	}
+	key, err := newKey(append([]byte{}, b[9:9+SeedSize]...), binary.BigEndian.Uint64(b), int(b[8]))
+	if err != nil {
+		return nil, err
+	}
+	if err := key.Restore(b[9+SeedSize:]); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Sign returns the signature of msg with the next unused one-time key,
+// or ErrExhausted once they are all used: the index, the one-time public
+// key and signature, and the inclusion proof of the public key. The
+// state advances before the one-time key signs, the caller saves it
+// before releasing the signature.
+func (key *PrivateKey) Sign(msg []byte) ([]byte, error) {
+	if key.Remaining() == 0 {
+		return nil, ErrExhausted
+	}
+	index := key.next
+	key.next++
+
+	ots, err := key.oneTimeKey(index)
+	if err != nil {
+		return nil, err
+	}
+	otsSig, err := ots.Sign(msg)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := key.tree.InclusionProof(index, key.tree.Size())
+	if err != nil {
+		return nil, err
+	}
+	sig := make([]byte, 9)
+	binary.BigEndian.PutUint64(sig, index)
+	sig[8] = byte(len(proof))
+	sig = append(sig, ots.PublicKey()...)
+	sig = append(sig, otsSig...)
+	for _, p := range proof {
+		sig = append(sig, p...)
+	}
+	return sig, nil
+}
+
+// Verify tells whether sig is a valid signature of msg by pub
+func Verify(pub, msg, sig []byte) bool {
+	if len(pub) != 9+sha256.Size || len(sig) < 9 {
+		return false
+	}
+	size, w, root := binary.BigEndian.Uint64(pub), int(pub[8]), pub[9:]
+	sizes, err := otsig.WinternitzSizes(w)
+	if err != nil {
+		return false
+	}
+	index, proofLen := binary.BigEndian.Uint64(sig), int(sig[8])
+	if len(sig) != 9+sizes.PublicKey+sizes.Signature+proofLen*sha256.Size {
+		return false
+	}
+	otsPub := sig[9 : 9+sizes.PublicKey]
+	otsSig := sig[9+sizes.PublicKey : 9+sizes.PublicKey+sizes.Signature]
+	if !otsig.VerifyWinternitz(w, otsPub, msg, otsSig) {
+		return false
+	}
+	proof := make([][]byte, proofLen)
+	for i := range proof {
+		start := 9 + sizes.PublicKey + sizes.Signature + i*sha256.Size
+		proof[i] = sig[start : start+sha256.Size]
+	}
+	return merkle.VerifyInclusion(index, size, merkle.LeafHash(otsPub), proof, root) == nil
+}
@@ -0,0 +1,112 @@
+package mss
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"testing"
+)
+
+func TestSignVerify(t *testing.T) {
+	t.Parallel()
+	// 5 is not a power of two, the proofs have different lengths
+	key, err := GenerateKey(rand.Reader, 5, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := key.PublicKey()
+	var sigs [][]byte
+	for i := 0; i < 5; i++ {
+		msg := []byte(fmt.Sprintf("message %d", i))
+		sig, err := key.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(pub, msg, sig) {
+			t.Fatalf("signature %d does not verify", i)
+		}
+		if Verify(pub, []byte("other"), sig) {
+			t.Fatalf("signature %d verifies a different message", i)
+		}
+		if key.Remaining() != uint64(4-i) {
+			t.Fatalf("expected %d signatures left but got %d", 4-i, key.Remaining())
+		}
+		sigs = append(sigs, sig)
+	}
+	if _, err := key.Sign([]byte("one more")); err != ErrExhausted {
+		t.Fatalf("expected ErrExhausted but got %v", err)
+	}
+	// another index, an altered proof or a different key fail
+	moved := append([]byte{}, sigs[1]...)
+	moved[7] = 2
+	altered := append([]byte{}, sigs[1]...)
+	altered[len(altered)-1] ^= 1
+	for _, sig := range [][]byte{moved, altered, sigs[1][:len(sigs[1])-1]} {
+		if Verify(pub, []byte("message 1"), sig) {
+			t.Fatal("invalid signature verifies")
+		}
+	}
+	other, _ := GenerateKey(rand.Reader, 5, 4)
+	if Verify(other.PublicKey(), []byte("message 1"), sigs[1]) {
+		t.Fatal("signature verifies with a different key")
+	}
+}
+
+func TestState(t *testing.T) {
+	t.Parallel()
+	key, err := GenerateKey(rand.Reader, 4, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := key.State()
+	encoded, _ := key.MarshalBinary()
+	first, _ := key.Sign([]byte("first"))
+
+	// a backup taken before the signature signs with the same one-time
+	// key: Restore refuses it, a parsed copy does not know better
+	if err := key.Restore(saved); err != ErrRollback {
+		t.Fatalf("expected ErrRollback but got %v", err)
+	}
+	copied, err := ParsePrivateKey(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(copied.PublicKey(), key.PublicKey()) {
+		t.Fatal("parsed key has a different public key")
+	}
+	reused, _ := copied.Sign([]byte("second"))
+	if !bytes.Equal(first[:9], reused[:9]) {
+		t.Fatal("expected the copy to sign with the same one-time key")
+	}
+
+	if err := copied.Restore(key.State()); err != nil {
+		t.Fatal(err)
+	}
+	encoded, _ = key.MarshalBinary()
+	restored, err := ParsePrivateKey(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Remaining() != 3 {
+		t.Fatalf("expected 3 signatures left but got %d", restored.Remaining())
+	}
+	sig, _ := restored.Sign([]byte("third"))
+	if sig[7] != 1 || !Verify(key.PublicKey(), []byte("third"), sig) {
+		t.Fatal("restored key does not sign with the next one-time key")
+	}
+	if err := restored.Restore([]byte{0, 0, 0, 0, 0, 0, 0, 5}); err == nil {
+		t.Fatal("expected an error with a state beyond the last key")
+	}
+}
+
+func TestInvalidKeys(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct{ size, w int }{{0, 4}, {MaxSize + 1, 4}, {4, 3}} {
+		if _, err := GenerateKey(rand.Reader, tc.size, tc.w); err == nil {
+			t.Fatalf("expected an error with %d keys and w = %d", tc.size, tc.w)
+		}
+	}
+	if _, err := ParsePrivateKey(make([]byte, 10)); err == nil {
+		t.Fatal("expected an error with a short key")
+	}
+}
@@ -0,0 +1,496 @@
+// Package musig2 implements MuSig2, the two-round Schnorr multi-signature
+// of Nick, Ruffing and Seurin, as specified by BIP 327 over secp256k1.
+//
+// Each signer holds an ordinary key pair. KeyAgg combines the public keys
+// of a group into one x-only BIP 340 key, weighting each key with a hash
+// of the whole list so that no signer can pick its key to cancel the
+// others. To sign, each signer first publishes two nonce points. Once all
+// of them are known and aggregated, each signer sends a partial signature,
+// and the partial signatures add up to a BIP 340 signature that verifies
+// under the aggregate key, indistinguishable from a single signer's.
+//
+// Two nonces rather than one are what allows the first round to happen
+// before the message is known, and sessions to run concurrently without
+// the ROS attack on two-round Schnorr multi-signatures: the nonce of the
+// session is R1 + b*R2, with b a hash of all the nonces and the message.
+//
+// Keys, nonces and signatures are byte strings in the encodings of the
+// BIP, so that its test vectors apply directly. The secret nonce must
+// never be used twice, Sign clears it. Nothing in here is constant time.
+package musig2
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+var curve = ec.Secp256k1()
+
+// taggedHash is the BIP 340 hash SHA256(SHA256(tag) || SHA256(tag) || msg)
+func taggedHash(tag string, msg ...[]byte) []byte {
+	t := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(t[:])
+	h.Write(t[:])
+	for _, m := range msg {
+		h.Write(m)
+	}
+	return h.Sum(nil)
+}
+
+// hashToScalar returns a tagged hash as an integer modulo N
+func hashToScalar(tag string, msg ...[]byte) *big.Int {
+	e := new(big.Int).SetBytes(taggedHash(tag, msg...))
+	return e.Mod(e, curve.N)
+}
+
+// scalarBytes encodes a scalar on 32 bytes
+func scalarBytes(k *big.Int) []byte {
+	return k.FillBytes(make([]byte, 32))
+}
+
+// xBytes encodes the x coordinate of p on 32 bytes
+func xBytes(p *ec.Point) []byte {
+	return p.X.FillBytes(make([]byte, 32))
+}
+
+// hasEvenY reports whether the y coordinate of p is even
+func hasEvenY(p *ec.Point) bool {
+	return p.Y.Bit(0) == 0
+}
+
+// parsePoint decodes a point in the 33 bytes compressed form
+func parsePoint(b []byte) (*ec.Point, error) {
+	if len(b) != 33 || (b[0] != 2 && b[0] != 3) {
+		return nil, errors.New("musig2: invalid point encoding")
+	}
+	return curve.Unmarshal(b)
+}
+
+// parsePointExt decodes a compressed point, or 33 zero bytes for the point
+// at infinity
+func parsePointExt(b []byte) (*ec.Point, error) {
+	if bytes.Equal(b, make([]byte, 33)) {
+		return ec.Infinity(), nil
+	}
+	return parsePoint(b)
+}
+
+// pointBytesExt encodes p in the compressed form, or as 33 zero bytes for
+// the point at infinity
+func pointBytesExt(p *ec.Point) []byte {
+	if p.IsInfinity() {
+		return make([]byte, 33)
+	}
+	return curve.Marshal(p)
+}
+
+// parseScalar decodes 32 bytes as a scalar, which must be below N
+func parseScalar(b []byte) (*big.Int, error) {
+	if len(b) != 32 {
+		return nil, errors.New("musig2: invalid scalar length")
+	}
+	k := new(big.Int).SetBytes(b)
+	if k.Cmp(curve.N) >= 0 {
+		return nil, errors.New("musig2: scalar out of range")
+	}
+	return k, nil
+}
+
+// InvalidContributionError blames the signer whose public key ("pubkey"),
+// public nonce ("pubnonce") or partial signature ("psig") is invalid, so
+// that the others can exclude it. Signer is -1 for an invalid aggregate
+// nonce ("aggnonce"), which is the fault of whoever aggregated it.
+type InvalidContributionError struct {
+	Signer  int
+	Contrib string
+}
+
+func (e *InvalidContributionError) Error() string {
+	if e.Signer < 0 {
+		return fmt.Sprintf("musig2: invalid %s", e.Contrib)
+	}
+	return fmt.Sprintf("musig2: invalid %s from signer %d", e.Contrib, e.Signer)
+}
+
+// PublicKey returns the compressed public key of the 32 bytes secret key
+// sk
+func PublicKey(sk []byte) ([]byte, error) {
+	d, err := parseScalar(sk)
+	if err != nil || d.Sign() == 0 {
+		return nil, errors.New("musig2: invalid secret key")
+	}
+	return curve.Marshal(curve.ScalarBaseMult(d)), nil
+}
+
+// KeySort returns the public keys sorted in lexicographic order, which
+// signers may agree on to aggregate their keys independently of the order
+// they learnt them in
+func KeySort(pubkeys [][]byte) [][]byte {
+	sorted := append([][]byte{}, pubkeys...)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i], sorted[j]) < 0 })
+	return sorted
+}
+
+// KeyAggContext is the aggregate of a list of public keys and of the
+// tweaks applied to it. Q is the aggregate key, gacc and tacc keep track
+// of the negations and additions of the tweaks so that signers can adjust
+// their shares.
+type KeyAggContext struct {
+	Q          *ec.Point
+	gacc, tacc *big.Int
+}
+
+// keyAggList returns the hash of the whole list of keys
+func keyAggList(pubkeys [][]byte) []byte {
+	return taggedHash("KeyAgg list", bytes.Join(pubkeys, nil))
+}
+
+// secondKey returns the first key that differs from the first one, or nil
+func secondKey(pubkeys [][]byte) []byte {
+	for _, pk := range pubkeys[1:] {
+		if !bytes.Equal(pk, pubkeys[0]) {
+			return pk
+		}
+	}
+	return nil
+}
+
+// keyAggCoeff returns the weight of pk in the aggregate of pubkeys. The
+// second distinct key gets a weight of one, which saves a multiplication
+// and is still secure.
+func keyAggCoeff(pubkeys [][]byte, pk []byte) *big.Int {
+	if second := secondKey(pubkeys); second != nil && bytes.Equal(pk, second) {
+		return big.NewInt(1)
+	}
+	return hashToScalar("KeyAgg coefficient", keyAggList(pubkeys), pk)
+}
+
+// KeyAgg aggregates the compressed public keys into the key Q, the sum of
+// a_i*P_i where a_i is the coefficient of each key
+func KeyAgg(pubkeys [][]byte) (*KeyAggContext, error) {
+	if len(pubkeys) == 0 {
+		return nil, errors.New("musig2: no public keys")
+	}
+	points := make([]*ec.Point, len(pubkeys))
+	coeffs := make([]*big.Int, len(pubkeys))
+	for i, pk := range pubkeys {
+		p, err := parsePoint(pk)
+		if err != nil {
+			return nil, &InvalidContributionError{Signer: i, Contrib: "pubkey"}
+		}
+		points[i], coeffs[i] = p, keyAggCoeff(pubkeys, pk)
+	}
+	q := curve.MultiScalarMult(coeffs, points)
+	if q.IsInfinity() {
+		return nil, errors.New("musig2: aggregate key is the point at infinity")
+	}
+	return &KeyAggContext{Q: q, gacc: big.NewInt(1), tacc: new(big.Int)}, nil
+}
+
+// PublicKey returns the aggregate key in the compressed form
+func (ctx *KeyAggContext) PublicKey() []byte {
+	return curve.Marshal(ctx.Q)
+}
+
+// XOnlyPublicKey returns the aggregate key as the 32 bytes x-only BIP 340
+// key that verifies the signatures of the group
+func (ctx *KeyAggContext) XOnlyPublicKey() []byte {
+	return xBytes(ctx.Q)
+}
+
+// Tweak is a scalar added to the aggregate key. A plain tweak adds t*G to
+// Q, as in BIP 32 derivation. An x-only tweak adds t*G to the point of
+// even y with the x coordinate of Q, as in Taproot commitments.
+type Tweak struct {
+	Value []byte
+	XOnly bool
+}
+
+// ApplyTweak returns the context of the aggregate key tweaked by t
+func (ctx *KeyAggContext) ApplyTweak(t Tweak) (*KeyAggContext, error) {
+	tweak, err := parseScalar(t.Value)
+	if err != nil {
+		return nil, errors.New("musig2: invalid tweak")
+	}
+	g := big.NewInt(1)
+	if t.XOnly && !hasEvenY(ctx.Q) {
+		g.Sub(curve.N, g)
+	}
+	q := curve.Add(curve.ScalarMult(ctx.Q, g), curve.ScalarBaseMult(tweak))
+	if q.IsInfinity() {
+		return nil, errors.New("musig2: tweaked key is the point at infinity")
+	}
+	gacc := new(big.Int).Mul(g, ctx.gacc)
+	gacc.Mod(gacc, curve.N)
+	tacc := new(big.Int).Mul(g, ctx.tacc)
+	tacc.Add(tacc, tweak)
+	tacc.Mod(tacc, curve.N)
+	return &KeyAggContext{Q: q, gacc: gacc, tacc: tacc}, nil
+}
+
+// SecretNonce is the secret pair of nonces of a signer in one session. It
+// must never be used twice, Sign clears it after use.
+type SecretNonce struct {
+	k1, k2 *big.Int
+	pk     []byte
+}
+
+// NonceGen returns a fresh pair of nonces for the signer of the public
+// key pk, and the 66 bytes public nonce to send to the other signers. The
+// secret key sk, the aggregate x-only key aggpk, the message msg and
+// extra may be nil, but when known they protect against a bad random
+// source.
+func NonceGen(rand io.Reader, sk, pk, aggpk, msg, extra []byte) (*SecretNonce, []byte, error) {
+	if len(pk) != 33 {
+		return nil, nil, errors.New("musig2: invalid public key")
+	}
+	r := make([]byte, 32)
+	if _, err := io.ReadFull(rand, r); err != nil {
+		return nil, nil, err
+	}
+	if sk != nil {
+		if len(sk) != 32 {
+			return nil, nil, errors.New("musig2: invalid secret key")
+		}
+		aux := taggedHash("MuSig/aux", r)
+		for i := range r {
+			r[i] = sk[i] ^ aux[i]
+		}
+	}
+	var in []byte
+	in = append(in, r...)
+	in = append(in, byte(len(pk)))
+	in = append(in, pk...)
+	in = append(in, byte(len(aggpk)))
+	in = append(in, aggpk...)
+	if msg == nil {
+		in = append(in, 0)
+	} else {
+		var l [8]byte
+		binary.BigEndian.PutUint64(l[:], uint64(len(msg)))
+		in = append(in, 1)
+		in = append(in, l[:]...)
+		in = append(in, msg...)
+	}
+	var l [4]byte
+	binary.BigEndian.PutUint32(l[:], uint32(len(extra)))
+	in = append(in, l[:]...)
+	in = append(in, extra...)
+	k1 := hashToScalar("MuSig/nonce", in, []byte{0})
+	k2 := hashToScalar("MuSig/nonce", in, []byte{1})
+	if k1.Sign() == 0 || k2.Sign() == 0 {
+		return nil, nil, errors.New("musig2: zero nonce")
+	}
+	pubnonce := append(curve.Marshal(curve.ScalarBaseMult(k1)), curve.Marshal(curve.ScalarBaseMult(k2))...)
+	return &SecretNonce{k1: k1, k2: k2, pk: append([]byte(nil), pk...)}, pubnonce, nil
+}
+
+// NonceAgg adds up the public nonces of the signers into the 66 bytes
+// aggregate nonce, which a coordinator may compute for everyone
+func NonceAgg(pubnonces [][]byte) ([]byte, error) {
+	r1, r2 := ec.Infinity(), ec.Infinity()
+	for i, pn := range pubnonces {
+		if len(pn) != 66 {
+			return nil, &InvalidContributionError{Signer: i, Contrib: "pubnonce"}
+		}
+		p1, err := parsePoint(pn[:33])
+		if err != nil {
+			return nil, &InvalidContributionError{Signer: i, Contrib: "pubnonce"}
+		}
+		p2, err := parsePoint(pn[33:])
+		if err != nil {
+			return nil, &InvalidContributionError{Signer: i, Contrib: "pubnonce"}
+		}
+		r1, r2 = curve.Add(r1, p1), curve.Add(r2, p2)
+	}
+	return append(pointBytesExt(r1), pointBytesExt(r2)...), nil
+}
+
+// Session holds what the signers of a message derive from the aggregate
+// nonce: the nonce coefficient b, the final nonce R = R1 + b*R2 and the
+// BIP 340 challenge e
+type Session struct {
+	keys    *KeyAggContext
+	pubkeys [][]byte
+	msg     []byte
+	b, e    *big.Int
+	r       *ec.Point
+}
+
+// NewSession starts the second round for the aggregate nonce, the public
+// keys of the signers in the order of KeyAgg, the tweaks to apply to the
+// aggregate key and the message
+func NewSession(aggnonce []byte, pubkeys [][]byte, tweaks []Tweak, msg []byte) (*Session, error) {
+	keys, err := KeyAgg(pubkeys)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tweaks {
+		if keys, err = keys.ApplyTweak(t); err != nil {
+			return nil, err
+		}
+	}
+	if len(aggnonce) != 66 {
+		return nil, &InvalidContributionError{Signer: -1, Contrib: "aggnonce"}
+	}
+	r1, err := parsePointExt(aggnonce[:33])
+	if err != nil {
+		return nil, &InvalidContributionError{Signer: -1, Contrib: "aggnonce"}
+	}
+	r2, err := parsePointExt(aggnonce[33:])
+	if err != nil {
+		return nil, &InvalidContributionError{Signer: -1, Contrib: "aggnonce"}
+	}
+	s := &Session{keys: keys, pubkeys: pubkeys, msg: msg}
+	s.b = hashToScalar("MuSig/noncecoef", aggnonce, xBytes(keys.Q), msg)
+	s.r = curve.Add(r1, curve.ScalarMult(r2, s.b))
+	if s.r.IsInfinity() {
+		// only a malicious signer can cause this, and it cannot gain
+		// anything from it
+		s.r = curve.Generator()
+	}
+	s.e = hashToScalar("BIP0340/challenge", xBytes(s.r), xBytes(keys.Q), msg)
+	return s, nil
+}
+
+// Keys returns the context of the tweaked aggregate key of the session
+func (s *Session) Keys() *KeyAggContext {
+	return s.keys
+}
+
+// hasKey reports whether pk is one of the keys of the session
+func (s *Session) hasKey(pk []byte) bool {
+	for _, k := range s.pubkeys {
+		if bytes.Equal(k, pk) {
+			return true
+		}
+	}
+	return false
+}
+
+// g returns 1 if the aggregate key has an even y, -1 otherwise, since
+// BIP 340 verifies against the point of even y
+func (s *Session) g() *big.Int {
+	if hasEvenY(s.keys.Q) {
+		return big.NewInt(1)
+	}
+	return new(big.Int).Sub(curve.N, big.NewInt(1))
+}
+
+// Sign returns the 32 bytes partial signature of the signer with the
+// secret key sk, and clears its secret nonce
+func (s *Session) Sign(nonce *SecretNonce, sk []byte) ([]byte, error) {
+	if nonce == nil || nonce.k1 == nil {
+		return nil, errors.New("musig2: secret nonce already used")
+	}
+	k1, k2 := nonce.k1, nonce.k2
+	nonce.k1, nonce.k2 = nil, nil
+	// a zeroed nonce is what a signer that stores and clears its secret
+	// nonces would find when asked to sign twice
+	if k1.Sign() == 0 || k1.Cmp(curve.N) >= 0 || k2.Sign() == 0 || k2.Cmp(curve.N) >= 0 {
+		return nil, errors.New("musig2: secret nonce out of range")
+	}
+	d, err := parseScalar(sk)
+	if err != nil || d.Sign() == 0 {
+		return nil, errors.New("musig2: invalid secret key")
+	}
+	pk := curve.Marshal(curve.ScalarBaseMult(d))
+	if !bytes.Equal(pk, nonce.pk) {
+		return nil, errors.New("musig2: secret nonce was generated for another key")
+	}
+	if !s.hasKey(pk) {
+		return nil, errors.New("musig2: signer key is not part of the session")
+	}
+	if !hasEvenY(s.r) {
+		k1 = new(big.Int).Sub(curve.N, k1)
+		k2 = new(big.Int).Sub(curve.N, k2)
+	}
+	// s = k1 + b*k2 + e*a*d, with d negated along with the key
+	a := keyAggCoeff(s.pubkeys, pk)
+	d.Mul(d, s.g()).Mul(d, s.keys.gacc)
+	sig := new(big.Int).Mul(s.e, a)
+	sig.Mul(sig, d)
+	sig.Add(sig, k1)
+	sig.Add(sig, new(big.Int).Mul(s.b, k2))
+	sig.Mod(sig, curve.N)
+	return scalarBytes(sig), nil
+}
+
+// PartialSigVerify checks the partial signature of the signer with the
+// public key pk and the public nonce pubnonce, so that a failed
+// aggregation can blame the signer that cheated
+func (s *Session) PartialSigVerify(psig, pubnonce, pk []byte) bool {
+	sig, err := parseScalar(psig)
+	if err != nil || len(pubnonce) != 66 || !s.hasKey(pk) {
+		return false
+	}
+	r1, err := parsePoint(pubnonce[:33])
+	if err != nil {
+		return false
+	}
+	r2, err := parsePoint(pubnonce[33:])
+	if err != nil {
+		return false
+	}
+	p, err := parsePoint(pk)
+	if err != nil {
+		return false
+	}
+	re := curve.Add(r1, curve.ScalarMult(r2, s.b))
+	if !hasEvenY(s.r) {
+		re = curve.Neg(re)
+	}
+	// s*G = Re + e*a*g*gacc*P
+	k := new(big.Int).Mul(s.e, keyAggCoeff(s.pubkeys, pk))
+	k.Mul(k, s.g()).Mul(k, s.keys.gacc)
+	return curve.ScalarBaseMult(sig).Equal(curve.Add(re, curve.ScalarMult(p, k)))
+}
+
+// Aggregate adds up the partial signatures into the 64 bytes BIP 340
+// signature of the message under the aggregate key
+func (s *Session) Aggregate(psigs [][]byte) ([]byte, error) {
+	sum := new(big.Int).Mul(s.e, s.g())
+	sum.Mul(sum, s.keys.tacc)
+	for i, psig := range psigs {
+		k, err := parseScalar(psig)
+		if err != nil {
+			return nil, &InvalidContributionError{Signer: i, Contrib: "psig"}
+		}
+		sum.Add(sum, k)
+	}
+	sum.Mod(sum, curve.N)
+	return append(xBytes(s.r), scalarBytes(sum)...), nil
+}
+
+// Verify checks the 64 bytes BIP 340 signature of msg by the x-only
+// public key pk
+func Verify(pk, msg, sig []byte) bool {
+	if len(pk) != 32 || len(sig) != 64 {
+		return false
+	}
+	p, err := curve.Unmarshal(append([]byte{2}, pk...))
+	if err != nil {
+		return false
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	if r.Cmp(curve.P) >= 0 {
+		return false
+	}
+	s, err := parseScalar(sig[32:])
+	if err != nil {
+		return false
+	}
+	e := hashToScalar("BIP0340/challenge", sig[:32], pk, msg)
+	// R = s*G - e*P
+	rp := curve.Sub(curve.ScalarBaseMult(s), curve.ScalarMult(p, e))
+	return !rp.IsInfinity() && hasEvenY(rp) && rp.X.Cmp(r) == 0
+}
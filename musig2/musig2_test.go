@@ -0,0 +1,554 @@
+package musig2
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/testvectors"
+)
+
+// The testdata files hold the BIP 327 test vectors in their original
+// layout
+
+func loadVectors(t *testing.T, path string, v interface{}) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func pick(all []testvectors.HexBytes, indices []int) [][]byte {
+	out := make([][]byte, len(indices))
+	for i, j := range indices {
+		out[i] = all[j]
+	}
+	return out
+}
+
+// vectorError is the error a test case expects: an invalid contribution
+// that blames a signer, or the aggregator when signer is null, or any
+// other invalid value
+type vectorError struct {
+	Type    string `json:"type"`
+	Signer  *int   `json:"signer"`
+	Contrib string `json:"contrib"`
+}
+
+func (ve vectorError) String() string {
+	if ve.Type != "invalid_contribution" {
+		return "an invalid value"
+	}
+	if ve.Signer == nil {
+		return "an invalid " + ve.Contrib
+	}
+	return fmt.Sprintf("an invalid %s from signer %d", ve.Contrib, *ve.Signer)
+}
+
+// matches reports whether err is the expected error
+func (ve vectorError) matches(err error) bool {
+	if err == nil {
+		return false
+	}
+	ice, ok := err.(*InvalidContributionError)
+	if ve.Type != "invalid_contribution" {
+		return !ok
+	}
+	signer := -1
+	if ve.Signer != nil {
+		signer = *ve.Signer
+	}
+	return ok && ice.Signer == signer && ice.Contrib == ve.Contrib
+}
+
+func tweaks(all []testvectors.HexBytes, indices []int, xonly []bool) []Tweak {
+	out := make([]Tweak, len(indices))
+	for i, j := range indices {
+		out[i] = Tweak{Value: all[j], XOnly: xonly[i]}
+	}
+	return out
+}
+
+// secretNonce decodes the 97 bytes k1 || k2 || pk secret nonce of the BIP
+func secretNonce(b []byte) *SecretNonce {
+	return &SecretNonce{
+		k1: new(big.Int).SetBytes(b[:32]),
+		k2: new(big.Int).SetBytes(b[32:64]),
+		pk: b[64:],
+	}
+}
+
+// opt returns the value of a nullable field, nil if it is null
+func opt(h *testvectors.HexBytes) []byte {
+	if h == nil {
+		return nil
+	}
+	return *h
+}
+
+func TestKeyAggVectors(t *testing.T) {
+	t.Parallel()
+	var v struct {
+		Pubkeys []testvectors.HexBytes `json:"pubkeys"`
+		Tweaks  []testvectors.HexBytes `json:"tweaks"`
+		Valid   []struct {
+			KeyIndices []int                `json:"key_indices"`
+			Expected   testvectors.HexBytes `json:"expected"`
+		} `json:"valid_test_cases"`
+		Errors []struct {
+			KeyIndices   []int       `json:"key_indices"`
+			TweakIndices []int       `json:"tweak_indices"`
+			IsXonly      []bool      `json:"is_xonly"`
+			Error        vectorError `json:"error"`
+			Comment      string      `json:"comment"`
+		} `json:"error_test_cases"`
+	}
+	loadVectors(t, "testdata/key_agg_vectors.json", &v)
+	for i, tc := range v.Valid {
+		ctx, err := KeyAgg(pick(v.Pubkeys, tc.KeyIndices))
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if !bytes.Equal(ctx.XOnlyPublicKey(), tc.Expected) {
+			t.Fatalf("testcase %d expected aggregate key %X but got %X", i, []byte(tc.Expected), ctx.XOnlyPublicKey())
+		}
+	}
+	for i, tc := range v.Errors {
+		ctx, err := KeyAgg(pick(v.Pubkeys, tc.KeyIndices))
+		for _, tw := range tweaks(v.Tweaks, tc.TweakIndices, tc.IsXonly) {
+			if err != nil {
+				break
+			}
+			ctx, err = ctx.ApplyTweak(tw)
+		}
+		if !tc.Error.matches(err) {
+			t.Fatalf("error testcase %d expected %s but got %v: %s", i, tc.Error, err, tc.Comment)
+		}
+	}
+}
+
+func TestNonceGenVectors(t *testing.T) {
+	t.Parallel()
+	var v struct {
+		Cases []struct {
+			Rand             testvectors.HexBytes  `json:"rand_"`
+			Sk               *testvectors.HexBytes `json:"sk"`
+			Pk               testvectors.HexBytes  `json:"pk"`
+			Aggpk            *testvectors.HexBytes `json:"aggpk"`
+			Msg              *testvectors.HexBytes `json:"msg"`
+			ExtraIn          *testvectors.HexBytes `json:"extra_in"`
+			ExpectedSecnonce testvectors.HexBytes  `json:"expected_secnonce"`
+			ExpectedPubnonce testvectors.HexBytes  `json:"expected_pubnonce"`
+		} `json:"test_cases"`
+	}
+	loadVectors(t, "testdata/nonce_gen_vectors.json", &v)
+	for i, tc := range v.Cases {
+		nonce, pubnonce, err := NonceGen(bytes.NewReader(tc.Rand), opt(tc.Sk), tc.Pk, opt(tc.Aggpk), opt(tc.Msg), opt(tc.ExtraIn))
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		secnonce := append(append(scalarBytes(nonce.k1), scalarBytes(nonce.k2)...), nonce.pk...)
+		if !bytes.Equal(secnonce, tc.ExpectedSecnonce) {
+			t.Fatalf("testcase %d expected secret nonce %X but got %X", i, []byte(tc.ExpectedSecnonce), secnonce)
+		}
+		if !bytes.Equal(pubnonce, tc.ExpectedPubnonce) {
+			t.Fatalf("testcase %d expected public nonce %X but got %X", i, []byte(tc.ExpectedPubnonce), pubnonce)
+		}
+	}
+}
+
+func TestNonceAggVectors(t *testing.T) {
+	t.Parallel()
+	var v struct {
+		Pnonces []testvectors.HexBytes `json:"pnonces"`
+		Valid   []struct {
+			PnonceIndices []int                `json:"pnonce_indices"`
+			Expected      testvectors.HexBytes `json:"expected"`
+		} `json:"valid_test_cases"`
+		Errors []struct {
+			PnonceIndices []int       `json:"pnonce_indices"`
+			Error         vectorError `json:"error"`
+			Comment       string      `json:"comment"`
+		} `json:"error_test_cases"`
+	}
+	loadVectors(t, "testdata/nonce_agg_vectors.json", &v)
+	for i, tc := range v.Valid {
+		aggnonce, err := NonceAgg(pick(v.Pnonces, tc.PnonceIndices))
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if !bytes.Equal(aggnonce, tc.Expected) {
+			t.Fatalf("testcase %d expected aggregate nonce %X but got %X", i, []byte(tc.Expected), aggnonce)
+		}
+	}
+	for i, tc := range v.Errors {
+		_, err := NonceAgg(pick(v.Pnonces, tc.PnonceIndices))
+		if !tc.Error.matches(err) {
+			t.Fatalf("error testcase %d expected %s but got %v: %s", i, tc.Error, err, tc.Comment)
+		}
+	}
+}
+
+func TestSignVectors(t *testing.T) {
+	t.Parallel()
+	var v struct {
+		Sk        testvectors.HexBytes   `json:"sk"`
+		Pubkeys   []testvectors.HexBytes `json:"pubkeys"`
+		Secnonces []testvectors.HexBytes `json:"secnonces"`
+		Pnonces   []testvectors.HexBytes `json:"pnonces"`
+		Aggnonces []testvectors.HexBytes `json:"aggnonces"`
+		Msgs      []testvectors.HexBytes `json:"msgs"`
+		Valid     []struct {
+			KeyIndices    []int                `json:"key_indices"`
+			NonceIndices  []int                `json:"nonce_indices"`
+			AggnonceIndex int                  `json:"aggnonce_index"`
+			MsgIndex      int                  `json:"msg_index"`
+			SignerIndex   int                  `json:"signer_index"`
+			Expected      testvectors.HexBytes `json:"expected"`
+		} `json:"valid_test_cases"`
+		SignErrors []struct {
+			KeyIndices    []int       `json:"key_indices"`
+			AggnonceIndex int         `json:"aggnonce_index"`
+			MsgIndex      int         `json:"msg_index"`
+			SecnonceIndex int         `json:"secnonce_index"`
+			Error         vectorError `json:"error"`
+			Comment       string      `json:"comment"`
+		} `json:"sign_error_test_cases"`
+		VerifyFails []struct {
+			Sig          testvectors.HexBytes `json:"sig"`
+			KeyIndices   []int                `json:"key_indices"`
+			NonceIndices []int                `json:"nonce_indices"`
+			MsgIndex     int                  `json:"msg_index"`
+			SignerIndex  int                  `json:"signer_index"`
+			Comment      string               `json:"comment"`
+		} `json:"verify_fail_test_cases"`
+		VerifyErrors []struct {
+			Sig          testvectors.HexBytes `json:"sig"`
+			KeyIndices   []int                `json:"key_indices"`
+			NonceIndices []int                `json:"nonce_indices"`
+			MsgIndex     int                  `json:"msg_index"`
+			SignerIndex  int                  `json:"signer_index"`
+			Error        vectorError          `json:"error"`
+			Comment      string               `json:"comment"`
+		} `json:"verify_error_test_cases"`
+	}
+	loadVectors(t, "testdata/sign_verify_vectors.json", &v)
+	for i, tc := range v.Valid {
+		aggnonce, err := NonceAgg(pick(v.Pnonces, tc.NonceIndices))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(aggnonce, v.Aggnonces[tc.AggnonceIndex]) {
+			t.Fatalf("testcase %d expected aggregate nonce %X but got %X", i, []byte(v.Aggnonces[tc.AggnonceIndex]), aggnonce)
+		}
+		pubkeys := pick(v.Pubkeys, tc.KeyIndices)
+		s, err := NewSession(aggnonce, pubkeys, nil, v.Msgs[tc.MsgIndex])
+		if err != nil {
+			t.Fatal(err)
+		}
+		nonce := secretNonce(v.Secnonces[0])
+		psig, err := s.Sign(nonce, v.Sk)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if !bytes.Equal(psig, tc.Expected) {
+			t.Fatalf("testcase %d expected partial signature %X but got %X", i, []byte(tc.Expected), psig)
+		}
+		pnonce := v.Pnonces[tc.NonceIndices[tc.SignerIndex]]
+		if !s.PartialSigVerify(psig, pnonce, pubkeys[tc.SignerIndex]) {
+			t.Fatalf("testcase %d expected the partial signature to verify", i)
+		}
+		if _, err := s.Sign(nonce, v.Sk); err == nil {
+			t.Fatalf("testcase %d expected a used secret nonce to be rejected", i)
+		}
+	}
+	for i, tc := range v.SignErrors {
+		s, err := NewSession(v.Aggnonces[tc.AggnonceIndex], pick(v.Pubkeys, tc.KeyIndices), nil, v.Msgs[tc.MsgIndex])
+		if err == nil {
+			_, err = s.Sign(secretNonce(v.Secnonces[tc.SecnonceIndex]), v.Sk)
+		}
+		if !tc.Error.matches(err) {
+			t.Fatalf("sign error testcase %d expected %s but got %v: %s", i, tc.Error, err, tc.Comment)
+		}
+	}
+	for i, tc := range v.VerifyFails {
+		aggnonce, err := NonceAgg(pick(v.Pnonces, tc.NonceIndices))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubkeys := pick(v.Pubkeys, tc.KeyIndices)
+		s, err := NewSession(aggnonce, pubkeys, nil, v.Msgs[tc.MsgIndex])
+		if err != nil {
+			t.Fatal(err)
+		}
+		pnonce := v.Pnonces[tc.NonceIndices[tc.SignerIndex]]
+		if s.PartialSigVerify(tc.Sig, pnonce, pubkeys[tc.SignerIndex]) {
+			t.Fatalf("verify fail testcase %d expected the partial signature to fail: %s", i, tc.Comment)
+		}
+	}
+	// the nonces and keys are checked on aggregation, before a partial
+	// signature can be verified
+	for i, tc := range v.VerifyErrors {
+		aggnonce, err := NonceAgg(pick(v.Pnonces, tc.NonceIndices))
+		if err == nil {
+			_, err = NewSession(aggnonce, pick(v.Pubkeys, tc.KeyIndices), nil, v.Msgs[tc.MsgIndex])
+		}
+		if !tc.Error.matches(err) {
+			t.Fatalf("verify error testcase %d expected %s but got %v: %s", i, tc.Error, err, tc.Comment)
+		}
+	}
+}
+
+func TestTweakVectors(t *testing.T) {
+	t.Parallel()
+	var v struct {
+		Sk       testvectors.HexBytes   `json:"sk"`
+		Pubkeys  []testvectors.HexBytes `json:"pubkeys"`
+		Secnonce testvectors.HexBytes   `json:"secnonce"`
+		Pnonces  []testvectors.HexBytes `json:"pnonces"`
+		Aggnonce testvectors.HexBytes   `json:"aggnonce"`
+		Tweaks   []testvectors.HexBytes `json:"tweaks"`
+		Msg      testvectors.HexBytes   `json:"msg"`
+		Valid    []struct {
+			KeyIndices   []int                `json:"key_indices"`
+			NonceIndices []int                `json:"nonce_indices"`
+			TweakIndices []int                `json:"tweak_indices"`
+			IsXonly      []bool               `json:"is_xonly"`
+			SignerIndex  int                  `json:"signer_index"`
+			Expected     testvectors.HexBytes `json:"expected"`
+			Comment      string               `json:"comment"`
+		} `json:"valid_test_cases"`
+		Errors []struct {
+			KeyIndices   []int       `json:"key_indices"`
+			TweakIndices []int       `json:"tweak_indices"`
+			IsXonly      []bool      `json:"is_xonly"`
+			Error        vectorError `json:"error"`
+			Comment      string      `json:"comment"`
+		} `json:"error_test_cases"`
+	}
+	loadVectors(t, "testdata/tweak_vectors.json", &v)
+	for i, tc := range v.Valid {
+		aggnonce, err := NonceAgg(pick(v.Pnonces, tc.NonceIndices))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(aggnonce, v.Aggnonce) {
+			t.Fatalf("testcase %d expected aggregate nonce %X but got %X", i, []byte(v.Aggnonce), aggnonce)
+		}
+		pubkeys := pick(v.Pubkeys, tc.KeyIndices)
+		s, err := NewSession(aggnonce, pubkeys, tweaks(v.Tweaks, tc.TweakIndices, tc.IsXonly), v.Msg)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		psig, err := s.Sign(secretNonce(v.Secnonce), v.Sk)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if !bytes.Equal(psig, tc.Expected) {
+			t.Fatalf("testcase %d expected partial signature %X but got %X: %s", i, []byte(tc.Expected), psig, tc.Comment)
+		}
+		pnonce := v.Pnonces[tc.NonceIndices[tc.SignerIndex]]
+		if !s.PartialSigVerify(psig, pnonce, pubkeys[tc.SignerIndex]) {
+			t.Fatalf("testcase %d expected the partial signature to verify", i)
+		}
+	}
+	for i, tc := range v.Errors {
+		_, err := NewSession(v.Aggnonce, pick(v.Pubkeys, tc.KeyIndices), tweaks(v.Tweaks, tc.TweakIndices, tc.IsXonly), v.Msg)
+		if !tc.Error.matches(err) {
+			t.Fatalf("error testcase %d expected %s but got %v: %s", i, tc.Error, err, tc.Comment)
+		}
+	}
+}
+
+func TestSigAggVectors(t *testing.T) {
+	t.Parallel()
+	type sigAggCase struct {
+		Aggnonce     testvectors.HexBytes `json:"aggnonce"`
+		NonceIndices []int                `json:"nonce_indices"`
+		KeyIndices   []int                `json:"key_indices"`
+		TweakIndices []int                `json:"tweak_indices"`
+		IsXonly      []bool               `json:"is_xonly"`
+		PsigIndices  []int                `json:"psig_indices"`
+		Expected     testvectors.HexBytes `json:"expected"`
+		Error        vectorError          `json:"error"`
+		Comment      string               `json:"comment"`
+	}
+	var v struct {
+		Pubkeys []testvectors.HexBytes `json:"pubkeys"`
+		Pnonces []testvectors.HexBytes `json:"pnonces"`
+		Tweaks  []testvectors.HexBytes `json:"tweaks"`
+		Psigs   []testvectors.HexBytes `json:"psigs"`
+		Msg     testvectors.HexBytes   `json:"msg"`
+		Valid   []sigAggCase           `json:"valid_test_cases"`
+		Errors  []sigAggCase           `json:"error_test_cases"`
+	}
+	loadVectors(t, "testdata/sig_agg_vectors.json", &v)
+	session := func(tc sigAggCase) *Session {
+		aggnonce, err := NonceAgg(pick(v.Pnonces, tc.NonceIndices))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(aggnonce, tc.Aggnonce) {
+			t.Fatalf("expected aggregate nonce %X but got %X", []byte(tc.Aggnonce), aggnonce)
+		}
+		s, err := NewSession(aggnonce, pick(v.Pubkeys, tc.KeyIndices), tweaks(v.Tweaks, tc.TweakIndices, tc.IsXonly), v.Msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	for i, tc := range v.Valid {
+		s := session(tc)
+		sig, err := s.Aggregate(pick(v.Psigs, tc.PsigIndices))
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if !bytes.Equal(sig, tc.Expected) {
+			t.Fatalf("testcase %d expected signature %X but got %X", i, []byte(tc.Expected), sig)
+		}
+		if !Verify(s.Keys().XOnlyPublicKey(), v.Msg, sig) {
+			t.Fatalf("testcase %d expected the signature to verify under the aggregate key", i)
+		}
+	}
+	for i, tc := range v.Errors {
+		_, err := session(tc).Aggregate(pick(v.Psigs, tc.PsigIndices))
+		if !tc.Error.matches(err) {
+			t.Fatalf("error testcase %d expected %s but got %v: %s", i, tc.Error, err, tc.Comment)
+		}
+	}
+}
+
+func TestBIP340Verify(t *testing.T) {
+	t.Parallel()
+	// test vectors 0 and 1 of BIP 340
+	var testcases = []struct {
+		pk, msg, sig string
+	}{
+		{
+			"F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9",
+			"0000000000000000000000000000000000000000000000000000000000000000",
+			"E907831F80848D1069A5371B402410364BDF1C5F8307B0084C55F1CE2DCA821525F66A4A85EA8B71E482A74F382D2CE5EBEEE8FDB2172F477DF4900D310536C0",
+		},
+		{
+			"DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+			"243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+			"6896BD60EEAE296DB48A229FF71DFE071BDE413E6D43F917DC8DCF8C78DE33418906D11AC976ABCCB20B091292BFF4EA897EFCB639EA871CFA95F6DE339E4B0A",
+		},
+	}
+	for i, tc := range testcases {
+		pk, _ := hex.DecodeString(tc.pk)
+		msg, _ := hex.DecodeString(tc.msg)
+		sig, _ := hex.DecodeString(tc.sig)
+		if !Verify(pk, msg, sig) {
+			t.Fatalf("testcase %d expected signature to verify", i)
+		}
+		sig[63] ^= 1
+		if Verify(pk, msg, sig) {
+			t.Fatalf("testcase %d expected a modified signature to fail", i)
+		}
+	}
+}
+
+func TestSign(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		signers int
+		tweaks  []Tweak
+	}{
+		{1, nil},
+		{2, nil},
+		{3, []Tweak{{Value: bytes.Repeat([]byte{7}, 32), XOnly: true}}},
+		{4, []Tweak{
+			{Value: bytes.Repeat([]byte{1}, 32)},
+			{Value: bytes.Repeat([]byte{2}, 32), XOnly: true},
+		}},
+	}
+	msg := []byte("MuSig2 in badcrypto")
+	for i, tc := range testcases {
+		sks := make([][]byte, tc.signers)
+		pks := make([][]byte, tc.signers)
+		for j := range sks {
+			k, err := curve.RandomScalar(rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sks[j] = scalarBytes(k)
+			if pks[j], err = PublicKey(sks[j]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// the secret keys follow the sorted public keys
+		byKey := make(map[string][]byte)
+		for j := range sks {
+			byKey[string(pks[j])] = sks[j]
+		}
+		pks = KeySort(pks)
+		for j := range sks {
+			sks[j] = byKey[string(pks[j])]
+		}
+		keys, err := KeyAgg(pks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tw := range tc.tweaks {
+			if keys, err = keys.ApplyTweak(tw); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// first round, before the message is known
+		nonces := make([]*SecretNonce, tc.signers)
+		pubnonces := make([][]byte, tc.signers)
+		for j := range nonces {
+			if nonces[j], pubnonces[j], err = NonceGen(rand.Reader, sks[j], pks[j], nil, nil, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		aggnonce, err := NonceAgg(pubnonces)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// second round
+		s, err := NewSession(aggnonce, pks, tc.tweaks, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		psigs := make([][]byte, tc.signers)
+		for j := range psigs {
+			if psigs[j], err = s.Sign(nonces[j], sks[j]); err != nil {
+				t.Fatal(err)
+			}
+			if !s.PartialSigVerify(psigs[j], pubnonces[j], pks[j]) {
+				t.Fatalf("testcase %d expected partial signature %d to verify", i, j)
+			}
+		}
+		sig, err := s.Aggregate(psigs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(keys.XOnlyPublicKey(), msg, sig) {
+			t.Fatalf("testcase %d expected the signature to verify under the aggregate key", i)
+		}
+		if !bytes.Equal(s.Keys().XOnlyPublicKey(), keys.XOnlyPublicKey()) {
+			t.Fatalf("testcase %d expected the session to tweak the key the same way", i)
+		}
+		if Verify(keys.XOnlyPublicKey(), []byte("other"), sig) {
+			t.Fatalf("testcase %d expected the signature of another message to fail", i)
+		}
+		if tc.signers > 1 {
+			if s.PartialSigVerify(psigs[0], pubnonces[1], pks[0]) {
+				t.Fatalf("testcase %d expected a partial signature to fail with another nonce", i)
+			}
+			if _, err := s.Sign(nonces[0], sks[0]); err == nil {
+				t.Fatalf("testcase %d expected a used secret nonce to be rejected", i)
+			}
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package noise
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+)
+
+// Token is a step of a handshake message pattern
+type Token int
+
+// Tokens of section 7.1
+const (
+	TokenE Token = iota
+	TokenS
+	TokenEE
+	TokenES
+	TokenSE
+	TokenSS
+)
+
+// HandshakePattern describes the pre-messages and messages of a handshake
+type HandshakePattern struct {
+	Name                 string
+	InitiatorPreMessages []Token
+	ResponderPreMessages []Token
+	Messages             [][]Token
+}
+
+// Interactive patterns from section 7.5
+var (
+	// HandshakeXX transmits both static keys during the handshake
+	HandshakeXX = HandshakePattern{
+		Name: "XX",
+		Messages: [][]Token{
+			{TokenE},
+			{TokenE, TokenEE, TokenS, TokenES},
+			{TokenS, TokenSE},
+		},
+	}
+	// HandshakeNK has no initiator static key and a responder static
+	// key known in advance
+	HandshakeNK = HandshakePattern{
+		Name:                 "NK",
+		ResponderPreMessages: []Token{TokenS},
+		Messages: [][]Token{
+			{TokenE, TokenES},
+			{TokenE, TokenEE},
+		},
+	}
+	// HandshakeIK sends the initiator static key immediately to a
+	// responder whose static key is known in advance
+	HandshakeIK = HandshakePattern{
+		Name:                 "IK",
+		ResponderPreMessages: []Token{TokenS},
+		Messages: [][]Token{
+			{TokenE, TokenES, TokenS, TokenSS},
+			{TokenE, TokenEE, TokenSE},
+		},
+	}
+)
+
+// Config holds the parameters of a handshake
+type Config struct {
+	Pattern   HandshakePattern
+	Initiator bool
+	Prologue  []byte
+
+	// StaticKeypair is the local static key, when the pattern uses one
+	StaticKeypair DHKey
+	// EphemeralKeypair is normally generated, it is only set to replay
+	// test vectors
+	EphemeralKeypair DHKey
+	// PeerStatic is the remote static key, when known in advance
+	PeerStatic []byte
+	// PeerEphemeral is the remote ephemeral key, when known in advance
+	PeerEphemeral []byte
+
+	// Random is the source of ephemeral keys, crypto/rand when nil
+	Random io.Reader
+}
+
+// HandshakeState drives a handshake by processing message patterns
+type HandshakeState struct {
+	ss          SymmetricState
+	s, e        DHKey
+	rs, re      []byte
+	initiator   bool
+	shouldWrite bool
+	messages    [][]Token
+	random      io.Reader
+}
+
+// ProtocolName returns the full name of the protocol run with pattern
+func ProtocolName(pattern HandshakePattern) string {
+	return "Noise_" + pattern.Name + "_25519_ChaChaPoly_SHA256"
+}
+
+// NewHandshakeState implements Initialize() of section 5.3: it hashes the
+// protocol name, the prologue and the public keys of the pre-messages
+func NewHandshakeState(c Config) (*HandshakeState, error) {
+	hs := &HandshakeState{
+		s:           c.StaticKeypair,
+		e:           c.EphemeralKeypair,
+		rs:          c.PeerStatic,
+		re:          c.PeerEphemeral,
+		initiator:   c.Initiator,
+		shouldWrite: c.Initiator,
+		messages:    c.Pattern.Messages,
+		random:      c.Random,
+	}
+	if hs.random == nil {
+		hs.random = rand.Reader
+	}
+	hs.ss.InitializeSymmetric([]byte(ProtocolName(c.Pattern)))
+	hs.ss.MixHash(c.Prologue)
+
+	// the pre-messages are hashed in order, initiator first
+	for _, side := range []struct {
+		local  bool
+		tokens []Token
+	}{
+		{c.Initiator, c.Pattern.InitiatorPreMessages},
+		{!c.Initiator, c.Pattern.ResponderPreMessages},
+	} {
+		for _, token := range side.tokens {
+			var key []byte
+			switch {
+			case token == TokenS && side.local:
+				key = hs.s.Public
+			case token == TokenS:
+				key = hs.rs
+			case token == TokenE && side.local:
+				key = hs.e.Public
+			case token == TokenE:
+				key = hs.re
+			default:
+				return nil, errors.New("noise: invalid pre-message token")
+			}
+			if len(key) != DHLen {
+				return nil, errors.New("noise: missing key for pre-message")
+			}
+			hs.ss.MixHash(key)
+		}
+	}
+	return hs, nil
+}
+
+// WriteMessage processes the next message pattern, appends the handshake
+// message carrying payload to out and returns it. After the last message
+// the two transport CipherStates are also returned, the first one is for
+// messages sent by the initiator.
+func (hs *HandshakeState) WriteMessage(out, payload []byte) ([]byte, *CipherState, *CipherState, error) {
+	if len(hs.messages) == 0 {
+		return nil, nil, nil, errors.New("noise: handshake is already complete")
+	}
+	if !hs.shouldWrite {
+		return nil, nil, nil, errors.New("noise: unexpected call to WriteMessage, a message must be read first")
+	}
+	for _, token := range hs.messages[0] {
+		var err error
+		switch token {
+		case TokenE:
+			if len(hs.e.Private) == 0 {
+				if hs.e, err = GenerateKeypair(hs.random); err != nil {
+					return nil, nil, nil, err
+				}
+			}
+			out = append(out, hs.e.Public...)
+			hs.ss.MixHash(hs.e.Public)
+		case TokenS:
+			if len(hs.s.Public) != DHLen {
+				return nil, nil, nil, errors.New("noise: missing local static key")
+			}
+			ciphertext, err := hs.ss.EncryptAndHash(hs.s.Public)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			out = append(out, ciphertext...)
+		default:
+			if err = hs.mixDH(token); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+	}
+	ciphertext, err := hs.ss.EncryptAndHash(payload)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	out = append(out, ciphertext...)
+	if len(out) > MaxMessageLen {
+		return nil, nil, nil, errors.New("noise: message is too long")
+	}
+	return hs.next(out)
+}
+
+// ReadMessage processes the next message pattern with a message received
+// from the peer, and appends the decrypted payload to out. After the last
+// message the two transport CipherStates are also returned.
+func (hs *HandshakeState) ReadMessage(out, message []byte) ([]byte, *CipherState, *CipherState, error) {
+	if len(hs.messages) == 0 {
+		return nil, nil, nil, errors.New("noise: handshake is already complete")
+	}
+	if hs.shouldWrite {
+		return nil, nil, nil, errors.New("noise: unexpected call to ReadMessage, a message must be written first")
+	}
+	if len(message) > MaxMessageLen {
+		return nil, nil, nil, errors.New("noise: message is too long")
+	}
+	for _, token := range hs.messages[0] {
+		switch token {
+		case TokenE:
+			if len(message) < DHLen {
+				return nil, nil, nil, ErrShortMessage
+			}
+			hs.re = append([]byte{}, message[:DHLen]...)
+			message = message[DHLen:]
+			hs.ss.MixHash(hs.re)
+		case TokenS:
+			n := DHLen
+			if hs.ss.cs.HasKey() {
+				n += 16
+			}
+			if len(message) < n {
+				return nil, nil, nil, ErrShortMessage
+			}
+			rs, err := hs.ss.DecryptAndHash(message[:n])
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			hs.rs = rs
+			message = message[n:]
+		default:
+			if err := hs.mixDH(token); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+	}
+	payload, err := hs.ss.DecryptAndHash(message)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return hs.next(append(out, payload...))
+}
+
+// mixDH performs the DH of a ee, es, se or ss token and mixes its output
+// into the chaining key. The first letter names the initiator's key.
+func (hs *HandshakeState) mixDH(token Token) error {
+	var (
+		local  DHKey
+		remote []byte
+	)
+	switch token {
+	case TokenEE:
+		local, remote = hs.e, hs.re
+	case TokenSS:
+		local, remote = hs.s, hs.rs
+	case TokenES:
+		if hs.initiator {
+			local, remote = hs.e, hs.rs
+		} else {
+			local, remote = hs.s, hs.re
+		}
+	case TokenSE:
+		if hs.initiator {
+			local, remote = hs.s, hs.re
+		} else {
+			local, remote = hs.e, hs.rs
+		}
+	default:
+		return errors.New("noise: invalid token")
+	}
+	if len(local.Private) == 0 || len(remote) != DHLen {
+		return errors.New("noise: missing key for DH")
+	}
+	shared, err := dh(local, remote)
+	if err != nil {
+		return err
+	}
+	hs.ss.MixKey(shared)
+	return nil
+}
+
+// next moves to the following message pattern and splits the symmetric
+// state once the handshake is over
+func (hs *HandshakeState) next(out []byte) ([]byte, *CipherState, *CipherState, error) {
+	hs.messages = hs.messages[1:]
+	hs.shouldWrite = !hs.shouldWrite
+	if len(hs.messages) > 0 {
+		return out, nil, nil, nil
+	}
+	c1, c2 := hs.ss.Split()
+	return out, c1, c2, nil
+}
+
+// PeerStatic returns the static public key of the peer, once known
+func (hs *HandshakeState) PeerStatic() []byte {
+	return hs.rs
+}
+
+// ChannelBinding returns the handshake hash, which uniquely identifies
+// the session once the handshake is complete
+func (hs *HandshakeState) ChannelBinding() []byte {
+	return hs.ss.GetHandshakeHash()
+}
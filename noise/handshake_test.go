@@ -0,0 +1,310 @@
+package noise
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jvehent/badcrypto/testvectors"
+)
+
+func mustKeypair(t *testing.T) DHKey {
+	key, err := GenerateKeypair(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// runHandshake exchanges all the messages of a handshake between the
+// initiator and the responder and returns their transport cipher states
+func runHandshake(t *testing.T, initiator, responder *HandshakeState, messages int) (ic1, ic2, rc1, rc2 *CipherState) {
+	writer, reader := initiator, responder
+	for i := 0; i < messages; i++ {
+		payload := []byte{byte(i), 'p', 'a', 'y'}
+		msg, wc1, wc2, err := writer.WriteMessage(nil, payload)
+		if err != nil {
+			t.Fatalf("message %d write failed: %v", i, err)
+		}
+		got, c1, c2, err := reader.ReadMessage(nil, msg)
+		if err != nil {
+			t.Fatalf("message %d read failed: %v", i, err)
+		}
+		if !bytes.Equal(got, payload) {
+			t.Fatalf("message %d expected payload %x but got %x", i, payload, got)
+		}
+		if writer == initiator {
+			ic1, ic2, rc1, rc2 = wc1, wc2, c1, c2
+		} else {
+			ic1, ic2, rc1, rc2 = c1, c2, wc1, wc2
+		}
+		writer, reader = reader, writer
+	}
+	return
+}
+
+func TestHandshakePatterns(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		pattern HandshakePattern
+		// which static keys each side knows before the handshake
+		initiatorStatic, responderStaticKnown bool
+	}{
+		{HandshakeXX, true, false},
+		{HandshakeNK, false, true},
+		{HandshakeIK, true, true},
+	}
+	for i, testcase := range testcases {
+		iStatic, rStatic := mustKeypair(t), mustKeypair(t)
+		icfg := Config{Pattern: testcase.pattern, Initiator: true, Prologue: []byte("badcrypto")}
+		rcfg := Config{Pattern: testcase.pattern, Prologue: []byte("badcrypto"), StaticKeypair: rStatic}
+		if testcase.initiatorStatic {
+			icfg.StaticKeypair = iStatic
+		}
+		if testcase.responderStaticKnown {
+			icfg.PeerStatic = rStatic.Public
+		}
+		initiator, err := NewHandshakeState(icfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		responder, err := NewHandshakeState(rcfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ic1, ic2, rc1, rc2 := runHandshake(t, initiator, responder, len(testcase.pattern.Messages))
+		if ic1 == nil || ic2 == nil || rc1 == nil || rc2 == nil {
+			t.Fatalf("testcase %d handshake did not produce cipher states", i)
+		}
+		if !bytes.Equal(initiator.ChannelBinding(), responder.ChannelBinding()) {
+			t.Fatalf("testcase %d handshake hashes differ", i)
+		}
+		if !bytes.Equal(initiator.PeerStatic(), rStatic.Public) {
+			t.Fatalf("testcase %d initiator does not know the responder static key", i)
+		}
+		if testcase.initiatorStatic && !bytes.Equal(responder.PeerStatic(), iStatic.Public) {
+			t.Fatalf("testcase %d responder does not know the initiator static key", i)
+		}
+		// transport messages in both directions
+		for j := 0; j < 3; j++ {
+			ct, err := ic1.EncryptWithAd(nil, []byte("to responder"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			pt, err := rc1.DecryptWithAd(nil, ct)
+			if err != nil || string(pt) != "to responder" {
+				t.Fatalf("testcase %d transport message %d failed: %v", i, j, err)
+			}
+			ct, err = rc2.EncryptWithAd(nil, []byte("to initiator"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			pt, err = ic2.DecryptWithAd(nil, ct)
+			if err != nil || string(pt) != "to initiator" {
+				t.Fatalf("testcase %d transport message %d failed: %v", i, j, err)
+			}
+		}
+	}
+}
+
+func TestHandshakeWrongPeerStatic(t *testing.T) {
+	t.Parallel()
+	initiator, err := NewHandshakeState(Config{
+		Pattern:    HandshakeNK,
+		Initiator:  true,
+		PeerStatic: mustKeypair(t).Public,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	responder, err := NewHandshakeState(Config{Pattern: HandshakeNK, StaticKeypair: mustKeypair(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, _, _, err := initiator.WriteMessage(nil, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := responder.ReadMessage(nil, msg); err == nil {
+		t.Fatal("expected the responder to fail decrypting a message for another static key")
+	}
+}
+
+func TestHandshakePrologueMismatch(t *testing.T) {
+	t.Parallel()
+	initiator, _ := NewHandshakeState(Config{Pattern: HandshakeXX, Initiator: true, Prologue: []byte("a"), StaticKeypair: mustKeypair(t)})
+	responder, _ := NewHandshakeState(Config{Pattern: HandshakeXX, Prologue: []byte("b"), StaticKeypair: mustKeypair(t)})
+	msg, _, _, err := initiator.WriteMessage(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the first XX message is not encrypted, the mismatch shows up in the second
+	if _, _, _, err := responder.ReadMessage(nil, msg); err != nil {
+		t.Fatal(err)
+	}
+	msg, _, _, err = responder.WriteMessage(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := initiator.ReadMessage(nil, msg); err == nil {
+		t.Fatal("expected a prologue mismatch to fail the handshake")
+	}
+}
+
+func TestHandshakeOutOfTurn(t *testing.T) {
+	t.Parallel()
+	responder, err := NewHandshakeState(Config{Pattern: HandshakeXX, StaticKeypair: mustKeypair(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := responder.WriteMessage(nil, nil); err == nil {
+		t.Fatal("expected the responder to refuse writing the first message")
+	}
+}
+
+func TestVectors(t *testing.T) {
+	t.Parallel()
+	f, err := os.Open("testdata/vectors.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	patterns := map[string]HandshakePattern{"XX": HandshakeXX, "NK": HandshakeNK, "IK": HandshakeIK}
+	count := 0
+	err = testvectors.ReadRSP(f, func(rec *testvectors.Record) error {
+		name := rec.Get("handshake")
+		pattern, ok := patterns[strings.TrimPrefix(strings.TrimSuffix(name, "_25519_ChaChaPoly_SHA256"), "Noise_")]
+		if !ok || ProtocolName(pattern) != name {
+			return fmt.Errorf("line %d: unsupported protocol %s", rec.Line, name)
+		}
+		icfg := Config{Pattern: pattern, Initiator: true}
+		rcfg := Config{Pattern: pattern}
+		for _, side := range []struct {
+			cfg  *Config
+			seed string
+		}{{&icfg, "gen_init_ephemeral"}, {&rcfg, "gen_resp_ephemeral"}} {
+			var err error
+			if side.cfg.Prologue, err = optionalHex(rec, "prologue"); err != nil {
+				return err
+			}
+			seed, err := rec.Hex(side.seed)
+			if err != nil {
+				return err
+			}
+			side.cfg.Random = bytes.NewReader(seed)
+		}
+		if _, ok := rec.Fields["init_static"]; ok {
+			key, err := vectorKeypair(rec, "init_static")
+			if err != nil {
+				return err
+			}
+			icfg.StaticKeypair = key
+		}
+		if _, ok := rec.Fields["resp_static"]; ok {
+			key, err := vectorKeypair(rec, "resp_static")
+			if err != nil {
+				return err
+			}
+			rcfg.StaticKeypair = key
+			if len(pattern.ResponderPreMessages) > 0 {
+				icfg.PeerStatic = key.Public
+			}
+		}
+		initiator, err := NewHandshakeState(icfg)
+		if err != nil {
+			return err
+		}
+		responder, err := NewHandshakeState(rcfg)
+		if err != nil {
+			return err
+		}
+		// the handshake messages, then transport messages alternating
+		// from the initiator
+		writer, reader := initiator, responder
+		var ic1, ic2, rc1, rc2 *CipherState
+		for i := 0; ; i++ {
+			if _, ok := rec.Fields[fmt.Sprintf("msg_%d_payload", i)]; !ok {
+				break
+			}
+			payload, err := optionalHex(rec, fmt.Sprintf("msg_%d_payload", i))
+			if err != nil {
+				return err
+			}
+			expected, err := rec.Hex(fmt.Sprintf("msg_%d_ciphertext", i))
+			if err != nil {
+				return err
+			}
+			var msg, got []byte
+			if i < len(pattern.Messages) {
+				var wc1, wc2, c1, c2 *CipherState
+				if msg, wc1, wc2, err = writer.WriteMessage(nil, payload); err != nil {
+					return fmt.Errorf("line %d: message %d: %v", rec.Line, i, err)
+				}
+				if got, c1, c2, err = reader.ReadMessage(nil, expected); err != nil {
+					return fmt.Errorf("line %d: message %d: %v", rec.Line, i, err)
+				}
+				if writer == initiator {
+					ic1, ic2, rc1, rc2 = wc1, wc2, c1, c2
+				} else {
+					ic1, ic2, rc1, rc2 = c1, c2, wc1, wc2
+				}
+				writer, reader = reader, writer
+			} else {
+				send, receive := ic1, rc1
+				if (i-len(pattern.Messages))%2 == 1 {
+					send, receive = rc2, ic2
+				}
+				if msg, err = send.EncryptWithAd(nil, payload); err != nil {
+					return fmt.Errorf("line %d: message %d: %v", rec.Line, i, err)
+				}
+				if got, err = receive.DecryptWithAd(nil, expected); err != nil {
+					return fmt.Errorf("line %d: message %d: %v", rec.Line, i, err)
+				}
+			}
+			if !bytes.Equal(msg, expected) {
+				return fmt.Errorf("line %d: message %d expected %x but got %x", rec.Line, i, expected, msg)
+			}
+			if !bytes.Equal(got, payload) {
+				return fmt.Errorf("line %d: message %d expected payload %x but got %x", rec.Line, i, payload, got)
+			}
+			if i == len(pattern.Messages)-1 {
+				hash, err := rec.Hex("handshake_hash")
+				if err != nil {
+					return err
+				}
+				if !bytes.Equal(initiator.ChannelBinding(), hash) || !bytes.Equal(responder.ChannelBinding(), hash) {
+					return fmt.Errorf("line %d: expected handshake hash %x but got %x and %x",
+						rec.Line, hash, initiator.ChannelBinding(), responder.ChannelBinding())
+				}
+			}
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count == 0 {
+		t.Fatal("no test vector found")
+	}
+}
+
+// optionalHex decodes a field that may be missing or empty
+func optionalHex(rec *testvectors.Record, key string) ([]byte, error) {
+	if rec.Get(key) == "" {
+		return nil, nil
+	}
+	return rec.Hex(key)
+}
+
+// vectorKeypair derives the key pair whose private key is the field key
+func vectorKeypair(rec *testvectors.Record, key string) (DHKey, error) {
+	seed, err := rec.Hex(key)
+	if err != nil {
+		return DHKey{}, err
+	}
+	return GenerateKeypair(bytes.NewReader(seed))
+}
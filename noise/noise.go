@@ -0,0 +1,232 @@
+// Package noise implements the Noise Protocol Framework (revision 34)
+// with the 25519 DH function, the ChaChaPoly cipher and the SHA256 hash,
+// and the XX, NK and IK handshake patterns. The CipherState,
+// SymmetricState and HandshakeState objects follow section 5 of the
+// specification so the code reads side by side with it.
+package noise
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/jvehent/badcrypto/chacha20poly1305"
+	"github.com/jvehent/badcrypto/kdf"
+	"github.com/jvehent/badcrypto/x25519"
+)
+
+const (
+	// DHLen is the length of public keys and DH outputs
+	DHLen = x25519.Size
+	// HashLen is the output length of SHA-256
+	HashLen = sha256.Size
+	// MaxMessageLen is the largest Noise message
+	MaxMessageLen = 65535
+	// maxNonce is reserved and never used to encrypt
+	maxNonce = ^uint64(0)
+)
+
+var (
+	// ErrShortMessage is returned when a message is too short to be valid
+	ErrShortMessage = errors.New("noise: message is too short")
+	// ErrMaxNonce is returned when a CipherState has exhausted its nonces
+	ErrMaxNonce = errors.New("noise: cipher state nonce exhausted")
+)
+
+// DHKey is an X25519 key pair
+type DHKey struct {
+	Private []byte
+	Public  []byte
+}
+
+// GenerateKeypair returns a new X25519 key pair read from rand
+func GenerateKeypair(rand io.Reader) (DHKey, error) {
+	priv, pub, err := x25519.GenerateKey(rand)
+	if err != nil {
+		return DHKey{}, err
+	}
+	return DHKey{Private: priv[:], Public: pub[:]}, nil
+}
+
+func dh(key DHKey, pub []byte) ([]byte, error) {
+	return x25519.SharedSecret(key.Private, pub)
+}
+
+// CipherState holds the key k and nonce n used to encrypt messages
+type CipherState struct {
+	k      [32]byte
+	hasKey bool
+	n      uint64
+}
+
+// InitializeKey sets the key and resets the nonce to zero
+func (cs *CipherState) InitializeKey(key []byte) {
+	copy(cs.k[:], key)
+	cs.hasKey = true
+	cs.n = 0
+}
+
+// HasKey indicates if a key was set
+func (cs *CipherState) HasKey() bool {
+	return cs.hasKey
+}
+
+// SetNonce sets the nonce, used with out of order transport messages
+func (cs *CipherState) SetNonce(n uint64) {
+	cs.n = n
+}
+
+// nonce encodes n as 32 bits of zeroes followed by a little endian
+// 64 bits integer, as ChaChaPoly requires
+func nonce(n uint64) []byte {
+	out := make([]byte, chacha20poly1305.NonceSize)
+	binary.LittleEndian.PutUint64(out[4:], n)
+	return out
+}
+
+// EncryptWithAd encrypts plaintext with the associated data ad and
+// increments the nonce. Without a key, the plaintext is returned as is.
+func (cs *CipherState) EncryptWithAd(ad, plaintext []byte) ([]byte, error) {
+	if !cs.hasKey {
+		return append([]byte{}, plaintext...), nil
+	}
+	if cs.n == maxNonce {
+		return nil, ErrMaxNonce
+	}
+	aead, err := chacha20poly1305.New(cs.k[:])
+	if err != nil {
+		return nil, err
+	}
+	out := aead.Seal(nil, nonce(cs.n), plaintext, ad)
+	cs.n++
+	return out, nil
+}
+
+// DecryptWithAd decrypts ciphertext with the associated data ad. The
+// nonce is only incremented when authentication succeeds.
+func (cs *CipherState) DecryptWithAd(ad, ciphertext []byte) ([]byte, error) {
+	if !cs.hasKey {
+		return append([]byte{}, ciphertext...), nil
+	}
+	if cs.n == maxNonce {
+		return nil, ErrMaxNonce
+	}
+	aead, err := chacha20poly1305.New(cs.k[:])
+	if err != nil {
+		return nil, err
+	}
+	out, err := aead.Open(nil, nonce(cs.n), ciphertext, ad)
+	if err != nil {
+		return nil, err
+	}
+	cs.n++
+	return out, nil
+}
+
+// Rekey replaces the key with the first 32 bytes of the encryption of 32
+// zero bytes under the maximum nonce, per section 11.3
+func (cs *CipherState) Rekey() {
+	aead, err := chacha20poly1305.New(cs.k[:])
+	if err != nil {
+		panic(err)
+	}
+	out := aead.Seal(nil, nonce(maxNonce), make([]byte, 32), nil)
+	copy(cs.k[:], out[:32])
+}
+
+// SymmetricState holds the chaining key ck and the handshake hash h
+type SymmetricState struct {
+	cs CipherState
+	ck []byte
+	h  []byte
+}
+
+// InitializeSymmetric sets h to the protocol name, padded or hashed to
+// HashLen bytes, and ck to h
+func (ss *SymmetricState) InitializeSymmetric(protocolName []byte) {
+	if len(protocolName) <= HashLen {
+		ss.h = make([]byte, HashLen)
+		copy(ss.h, protocolName)
+	} else {
+		sum := sha256.Sum256(protocolName)
+		ss.h = sum[:]
+	}
+	ss.ck = append([]byte{}, ss.h...)
+	ss.cs = CipherState{}
+}
+
+// hkdf is the HKDF function of section 4.3, which is RFC 5869 with the
+// chaining key as salt, an empty info and two or three outputs
+func hkdf(ck, ikm []byte, outputs int) [][]byte {
+	okm, err := kdf.HKDF(sha256.New, ikm, ck, nil, outputs*HashLen)
+	if err != nil {
+		panic(err)
+	}
+	out := make([][]byte, outputs)
+	for i := range out {
+		out[i] = okm[i*HashLen : (i+1)*HashLen]
+	}
+	return out
+}
+
+// MixKey mixes ikm into the chaining key and sets a new cipher key
+func (ss *SymmetricState) MixKey(ikm []byte) {
+	out := hkdf(ss.ck, ikm, 2)
+	ss.ck = out[0]
+	ss.cs.InitializeKey(out[1])
+}
+
+// MixHash sets h = HASH(h || data)
+func (ss *SymmetricState) MixHash(data []byte) {
+	hash := sha256.New()
+	hash.Write(ss.h)
+	hash.Write(data)
+	ss.h = hash.Sum(nil)
+}
+
+// MixKeyAndHash mixes ikm into both the chaining key and the handshake
+// hash, it is used for pre-shared keys
+func (ss *SymmetricState) MixKeyAndHash(ikm []byte) {
+	out := hkdf(ss.ck, ikm, 3)
+	ss.ck = out[0]
+	ss.MixHash(out[1])
+	ss.cs.InitializeKey(out[2])
+}
+
+// GetHandshakeHash returns h, which can serve as a channel binding
+func (ss *SymmetricState) GetHandshakeHash() []byte {
+	return append([]byte{}, ss.h...)
+}
+
+// EncryptAndHash encrypts plaintext with h as associated data, then
+// mixes the ciphertext into h
+func (ss *SymmetricState) EncryptAndHash(plaintext []byte) ([]byte, error) {
+	ciphertext, err := ss.cs.EncryptWithAd(ss.h, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	ss.MixHash(ciphertext)
+	return ciphertext, nil
+}
+
+// DecryptAndHash decrypts ciphertext with h as associated data, then
+// mixes the ciphertext into h
+func (ss *SymmetricState) DecryptAndHash(ciphertext []byte) ([]byte, error) {
+	plaintext, err := ss.cs.DecryptWithAd(ss.h, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	ss.MixHash(ciphertext)
+	return plaintext, nil
+}
+
+// Split returns the pair of CipherStates used for transport messages,
+// the first one encrypts from initiator to responder
+func (ss *SymmetricState) Split() (*CipherState, *CipherState) {
+	out := hkdf(ss.ck, nil, 2)
+	c1, c2 := new(CipherState), new(CipherState)
+	c1.InitializeKey(out[0])
+	c2.InitializeKey(out[1])
+	return c1, c2
+}
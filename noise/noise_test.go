@@ -0,0 +1,67 @@
+package noise
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCipherStateNonce(t *testing.T) {
+	t.Parallel()
+	var sender, receiver CipherState
+	key := bytes.Repeat([]byte{1}, 32)
+	sender.InitializeKey(key)
+	receiver.InitializeKey(key)
+	var messages [][]byte
+	for i := 0; i < 3; i++ {
+		ct, err := sender.EncryptWithAd([]byte("ad"), []byte("message"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, ct)
+	}
+	if bytes.Equal(messages[0], messages[1]) {
+		t.Fatal("expected the nonce to change between messages")
+	}
+	// messages must be read in order unless the nonce is set explicitly
+	if _, err := receiver.DecryptWithAd([]byte("ad"), messages[1]); err == nil {
+		t.Fatal("expected an out of order message to fail")
+	}
+	receiver.SetNonce(1)
+	if _, err := receiver.DecryptWithAd([]byte("ad"), messages[1]); err != nil {
+		t.Fatal(err)
+	}
+	sender.SetNonce(maxNonce)
+	if _, err := sender.EncryptWithAd(nil, nil); err != ErrMaxNonce {
+		t.Fatalf("expected ErrMaxNonce but got %v", err)
+	}
+}
+
+func TestCipherStateRekey(t *testing.T) {
+	t.Parallel()
+	var a, b CipherState
+	key := bytes.Repeat([]byte{2}, 32)
+	a.InitializeKey(key)
+	b.InitializeKey(key)
+	a.Rekey()
+	ct, err := a.EncryptWithAd(nil, []byte("after rekey"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.DecryptWithAd(nil, ct); err == nil {
+		t.Fatal("expected the old key to fail after a rekey")
+	}
+	b.Rekey()
+	pt, err := b.DecryptWithAd(nil, ct)
+	if err != nil || string(pt) != "after rekey" {
+		t.Fatalf("decryption after rekey failed: %v", err)
+	}
+}
+
+func TestCipherStateNoKey(t *testing.T) {
+	t.Parallel()
+	var cs CipherState
+	out, err := cs.EncryptWithAd(nil, []byte("clear"))
+	if err != nil || string(out) != "clear" {
+		t.Fatal("expected a CipherState without a key to return the plaintext")
+	}
+}
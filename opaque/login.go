@@ -0,0 +1,251 @@
+package opaque
+
+import (
+	"crypto/hmac"
+	"errors"
+
+	"github.com/jvehent/badcrypto/oprf"
+)
+
+// KE1 is the first login message, from the client
+type KE1 struct {
+	BlindedMessage       []byte
+	ClientNonce          []byte
+	ClientPublicKeyshare []byte
+}
+
+func (m *KE1) bytes() []byte {
+	return concat(m.BlindedMessage, m.ClientNonce, m.ClientPublicKeyshare)
+}
+
+// KE2 is the server response: the credential response, which lets the
+// client recover its envelope, and the server half of the 3DH exchange
+type KE2 struct {
+	EvaluatedMessage     []byte
+	MaskingNonce         []byte
+	MaskedResponse       []byte
+	ServerNonce          []byte
+	ServerPublicKeyshare []byte
+	ServerMAC            []byte
+}
+
+func (m *KE2) credentialResponse() []byte {
+	return concat(m.EvaluatedMessage, m.MaskingNonce, m.MaskedResponse)
+}
+
+// KE3 is the last login message, which authenticates the client
+type KE3 struct {
+	ClientMAC []byte
+}
+
+// LoginState is kept by the client between KE1 and KE2
+type LoginState struct {
+	password  []byte
+	blind     *oprf.Blind
+	ke1       *KE1
+	ephemeral *keyPair
+}
+
+// ServerLoginState is kept by the server between KE2 and KE3
+type ServerLoginState struct {
+	expectedClientMAC []byte
+	sessionKey        []byte
+}
+
+// LoginInit starts a login with the password
+func (c *Client) LoginInit(password []byte) (*KE1, *LoginState, error) {
+	blind, blinded, err := c.suite().Blind(c.rand(), password)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce, err := randomBytes(c.rand(), nn)
+	if err != nil {
+		return nil, nil, err
+	}
+	ephemeral, err := c.generateKeyPair()
+	if err != nil {
+		return nil, nil, err
+	}
+	ke1 := &KE1{BlindedMessage: blinded, ClientNonce: nonce, ClientPublicKeyshare: ephemeral.public}
+	return ke1, &LoginState{password: append([]byte{}, password...), blind: blind, ke1: ke1, ephemeral: ephemeral}, nil
+}
+
+// fakeRecord stands in for unknown clients, so the response does not
+// reveal whether an account exists
+func (s *Server) fakeRecord() (*RegistrationRecord, error) {
+	kp, err := s.generateKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	maskingKey, err := randomBytes(s.rand(), s.nh())
+	if err != nil {
+		return nil, err
+	}
+	return &RegistrationRecord{ClientPublicKey: kp.public, MaskingKey: maskingKey, Envelope: make([]byte, s.envelopeLen())}, nil
+}
+
+// LoginInit answers KE1 for the client with the given record and
+// credential identifier. A nil record answers with a fake one, and the
+// login then fails at LoginFinish like a wrong password would.
+func (s *Server) LoginInit(record *RegistrationRecord, credentialID, clientIdentity []byte, ke1 *KE1) (*KE2, *ServerLoginState, error) {
+	var err error
+	if record == nil {
+		if record, err = s.fakeRecord(); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(ke1.ClientNonce) != nn || len(record.Envelope) != s.envelopeLen() {
+		return nil, nil, errors.New("opaque: malformed message")
+	}
+	key, err := s.oprfKey(credentialID)
+	if err != nil {
+		return nil, nil, err
+	}
+	evaluated, err := key.BlindEvaluate(ke1.BlindedMessage)
+	if err != nil {
+		return nil, nil, err
+	}
+	maskingNonce, err := randomBytes(s.rand(), nn)
+	if err != nil {
+		return nil, nil, err
+	}
+	pad := s.expand(record.MaskingKey, concat(maskingNonce, []byte("CredentialResponsePad")), s.npk()+s.envelopeLen())
+	ke2 := &KE2{
+		EvaluatedMessage: evaluated,
+		MaskingNonce:     maskingNonce,
+		MaskedResponse:   xor(pad, concat(s.PublicKey(), record.Envelope)),
+	}
+	if ke2.ServerNonce, err = randomBytes(s.rand(), nn); err != nil {
+		return nil, nil, err
+	}
+	ephemeral, err := s.generateKeyPair()
+	if err != nil {
+		return nil, nil, err
+	}
+	ke2.ServerPublicKeyshare = ephemeral.public
+
+	if clientIdentity == nil {
+		clientIdentity = record.ClientPublicKey
+	}
+	serverIdentity := s.Identity
+	if serverIdentity == nil {
+		serverIdentity = s.PublicKey()
+	}
+	// 3DH: ephemeral-ephemeral, static-ephemeral and ephemeral-static
+	dh1, err := s.dh(ephemeral.private, ke1.ClientPublicKeyshare)
+	if err != nil {
+		return nil, nil, err
+	}
+	dh2, err := s.dh(s.keyPair.private, ke1.ClientPublicKeyshare)
+	if err != nil {
+		return nil, nil, err
+	}
+	dh3, err := s.dh(ephemeral.private, record.ClientPublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	preamble := s.preamble(clientIdentity, ke1, serverIdentity, ke2)
+	km2, km3, sessionKey := s.deriveKeys(concat(dh1, dh2, dh3), preamble)
+	ke2.ServerMAC = s.mac(km2, s.hash(preamble))
+	clientHash := s.hash(concat(preamble, ke2.ServerMAC))
+	return ke2, &ServerLoginState{expectedClientMAC: s.mac(km3, clientHash), sessionKey: sessionKey}, nil
+}
+
+// LoginFinish authenticates the client and returns the session key
+func (s *Server) LoginFinish(state *ServerLoginState, ke3 *KE3) ([]byte, error) {
+	if !hmac.Equal(state.expectedClientMAC, ke3.ClientMAC) {
+		return nil, ErrAuthentication
+	}
+	return state.sessionKey, nil
+}
+
+// LoginFinish recovers the client credentials from KE2, authenticates
+// the server and returns KE3 along with the session and export keys
+func (c *Client) LoginFinish(state *LoginState, ke2 *KE2) (*KE3, []byte, []byte, error) {
+	npk := c.npk()
+	if len(ke2.MaskingNonce) != nn || len(ke2.MaskedResponse) != npk+c.envelopeLen() || len(ke2.ServerNonce) != nn {
+		return nil, nil, nil, errors.New("opaque: malformed message")
+	}
+	oprfOutput, err := c.suite().Finalize(state.password, state.blind, ke2.EvaluatedMessage)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	rp, err := c.randomizedPassword(oprfOutput)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	maskingKey := c.expand(rp, []byte("MaskingKey"), c.nh())
+	pad := c.expand(maskingKey, concat(ke2.MaskingNonce, []byte("CredentialResponsePad")), npk+c.envelopeLen())
+	unmasked := xor(pad, ke2.MaskedResponse)
+	serverPublicKey, envelope := unmasked[:npk], unmasked[npk:]
+	kp, exportKey, err := c.recoverEnvelope(rp, serverPublicKey, envelope, c.ServerIdentity, c.Identity)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	clientIdentity := c.Identity
+	if clientIdentity == nil {
+		clientIdentity = kp.public
+	}
+	serverIdentity := c.ServerIdentity
+	if serverIdentity == nil {
+		serverIdentity = serverPublicKey
+	}
+	dh1, err := c.dh(state.ephemeral.private, ke2.ServerPublicKeyshare)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	dh2, err := c.dh(state.ephemeral.private, serverPublicKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	dh3, err := c.dh(kp.private, ke2.ServerPublicKeyshare)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	preamble := c.preamble(clientIdentity, state.ke1, serverIdentity, ke2)
+	km2, km3, sessionKey := c.deriveKeys(concat(dh1, dh2, dh3), preamble)
+	if !hmac.Equal(ke2.ServerMAC, c.mac(km2, c.hash(preamble))) {
+		return nil, nil, nil, ErrAuthentication
+	}
+	clientHash := c.hash(concat(preamble, ke2.ServerMAC))
+	return &KE3{ClientMAC: c.mac(km3, clientHash)}, sessionKey, exportKey, nil
+}
+
+// preamble is the transcript of RFC 9807 section 6.4.2, everything but
+// the server MAC
+func (c *Config) preamble(clientIdentity []byte, ke1 *KE1, serverIdentity []byte, ke2 *KE2) []byte {
+	return concat(
+		[]byte("OPAQUEv1-"),
+		lengthPrefixed(c.Context),
+		lengthPrefixed(clientIdentity),
+		ke1.bytes(),
+		lengthPrefixed(serverIdentity),
+		ke2.credentialResponse(),
+		ke2.ServerNonce,
+		ke2.ServerPublicKeyshare,
+	)
+}
+
+// expandLabel is Expand-Label of RFC 9807 section 6.4.2
+func (c *Config) expandLabel(secret []byte, label string, context []byte, length int) []byte {
+	full := "OPAQUE-" + label
+	info := concat(
+		[]byte{byte(length >> 8), byte(length)},
+		[]byte{byte(len(full))}, []byte(full),
+		[]byte{byte(len(context))}, context,
+	)
+	return c.expand(secret, info, length)
+}
+
+// deriveKeys returns the server and client MAC keys and the session key
+func (c *Config) deriveKeys(ikm, preamble []byte) (km2, km3, sessionKey []byte) {
+	nh := c.nh()
+	prk := c.extract(nil, ikm)
+	preambleHash := c.hash(preamble)
+	handshakeSecret := c.expandLabel(prk, "HandshakeSecret", preambleHash, nh)
+	sessionKey = c.expandLabel(prk, "SessionKey", preambleHash, nh)
+	km2 = c.expandLabel(handshakeSecret, "ServerMAC", nil, nh)
+	km3 = c.expandLabel(handshakeSecret, "ClientMAC", nil, nh)
+	return km2, km3, sessionKey
+}
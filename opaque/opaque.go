@@ -0,0 +1,251 @@
+// Package opaque implements the OPAQUE augmented password authenticated
+// key exchange of RFC 9807, with the OPRF, HKDF, HMAC and 3DH key exchange
+// all over one group of the group package and its hash: P-256 and SHA-256
+// by default, or ristretto255 and SHA-512.
+//
+// The server never sees the password, not even at registration: it only
+// stores an envelope the client can open after an OPRF evaluation keyed by
+// the server, so an attacker who steals the database still has to run a
+// dictionary attack against each record, through the key stretching
+// function.
+package opaque
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/group"
+	"github.com/jvehent/badcrypto/kdf"
+	"github.com/jvehent/badcrypto/oprf"
+)
+
+// sizes from RFC 9807 section 4, the others depend on the group
+const (
+	nn    = 32 // nonces, Nn
+	nseed = 32 // key derivation seeds, Nseed
+)
+
+// ErrEnvelopeRecovery is returned to the client when the password is
+// wrong or the server response was tampered with
+var ErrEnvelopeRecovery = errors.New("opaque: envelope recovery failed")
+
+// ErrAuthentication is returned when a key exchange MAC does not verify
+var ErrAuthentication = errors.New("opaque: authentication failed")
+
+// Config holds the parameters both parties must agree on
+type Config struct {
+	// Group is the group of the OPRF and the key exchange, whose hash
+	// also serves for HKDF, HMAC and the transcript. It is
+	// group.P256SHA256 when nil.
+	Group group.Group
+	// Context is bound into the key exchange transcript
+	Context []byte
+	// Stretch is the key stretching function applied to the OPRF output,
+	// the identity when nil. It should be a memory hard function such as
+	// ScryptStretch.
+	Stretch func(oprfOutput []byte) ([]byte, error)
+	// Rand is the source of nonces and ephemeral keys, crypto/rand when
+	// nil
+	Rand io.Reader
+}
+
+func (c *Config) rand() io.Reader {
+	if c.Rand == nil {
+		return rand.Reader
+	}
+	return c.Rand
+}
+
+func (c *Config) group() group.Group {
+	if c.Group == nil {
+		return group.P256SHA256
+	}
+	return c.Group
+}
+
+func (c *Config) suite() *oprf.Suite {
+	return oprf.NewSuite(c.group())
+}
+
+// nh is the size of the hash and MAC outputs, Nh, Nm and Nx
+func (c *Config) nh() int {
+	return c.group().Hash()().Size()
+}
+
+// npk is the size of the public keys and OPRF elements, Npk and Noe
+func (c *Config) npk() int {
+	return c.group().ElementLen()
+}
+
+func (c *Config) envelopeLen() int {
+	return nn + c.nh()
+}
+
+func (c *Config) stretch(oprfOutput []byte) ([]byte, error) {
+	if c.Stretch == nil {
+		return oprfOutput, nil
+	}
+	return c.Stretch(oprfOutput)
+}
+
+// ScryptStretch stretches with scrypt and the parameters recommended by
+// RFC 9807: N=32768, r=8, p=1 and an empty salt
+func ScryptStretch(oprfOutput []byte) ([]byte, error) {
+	return kdf.Scrypt(oprfOutput, nil, 32768, 8, 1, len(oprfOutput))
+}
+
+// RegistrationRecord is what the server stores for each client
+type RegistrationRecord struct {
+	ClientPublicKey []byte
+	MaskingKey      []byte
+	Envelope        []byte
+}
+
+func randomBytes(r io.Reader, n int) ([]byte, error) {
+	b := make([]byte, n)
+	_, err := io.ReadFull(r, b)
+	return b, err
+}
+
+func (c *Config) expand(prk []byte, info []byte, length int) []byte {
+	out, err := kdf.HKDFExpand(c.group().Hash(), prk, info, length)
+	if err != nil {
+		panic(err)
+	}
+	return out
+}
+
+func (c *Config) extract(salt, ikm []byte) []byte {
+	return kdf.HKDFExtract(c.group().Hash(), ikm, salt)
+}
+
+func (c *Config) mac(key []byte, msgs ...[]byte) []byte {
+	m := hmac.New(c.group().Hash(), key)
+	for _, msg := range msgs {
+		m.Write(msg)
+	}
+	return m.Sum(nil)
+}
+
+func (c *Config) hash(msg []byte) []byte {
+	h := c.group().Hash()()
+	h.Write(msg)
+	return h.Sum(nil)
+}
+
+func concat(parts ...[]byte) []byte {
+	var out []byte
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+// lengthPrefixed encodes b with a two bytes length
+func lengthPrefixed(b []byte) []byte {
+	return append([]byte{byte(len(b) >> 8), byte(len(b))}, b...)
+}
+
+func xor(a, b []byte) []byte {
+	out := make([]byte, len(a))
+	for i := range a {
+		out[i] = a[i] ^ b[i]
+	}
+	return out
+}
+
+// keyPair is a Diffie-Hellman key pair in the group of the configuration
+type keyPair struct {
+	private *big.Int
+	public  []byte
+}
+
+// deriveKeyPair is DeriveDiffieHellmanKeyPair of RFC 9807 section 6.4.1
+func (c *Config) deriveKeyPair(seed []byte) (*keyPair, error) {
+	k, err := c.suite().DeriveKeyPair(seed, []byte("OPAQUE-DeriveDiffieHellmanKeyPair"))
+	if err != nil {
+		return nil, err
+	}
+	private, err := c.group().UnmarshalScalar(k.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return &keyPair{private: private, public: k.Public}, nil
+}
+
+func (c *Config) generateKeyPair() (*keyPair, error) {
+	seed, err := randomBytes(c.rand(), nseed)
+	if err != nil {
+		return nil, err
+	}
+	return c.deriveKeyPair(seed)
+}
+
+// dh multiplies the serialized public key by the private scalar
+func (c *Config) dh(private *big.Int, public []byte) ([]byte, error) {
+	g := c.group()
+	p, err := g.Unmarshal(public)
+	if err != nil {
+		return nil, errors.New("opaque: invalid public key")
+	}
+	return g.Marshal(g.ScalarMult(p, private)), nil
+}
+
+// cleartextCredentials binds the public keys and identities into the
+// envelope tag. Identities default to the public keys.
+func cleartextCredentials(serverPublicKey, clientPublicKey, serverIdentity, clientIdentity []byte) []byte {
+	if serverIdentity == nil {
+		serverIdentity = serverPublicKey
+	}
+	if clientIdentity == nil {
+		clientIdentity = clientPublicKey
+	}
+	return concat(serverPublicKey, lengthPrefixed(serverIdentity), lengthPrefixed(clientIdentity))
+}
+
+// randomizedPassword hardens the OPRF output with the stretching function
+func (c *Config) randomizedPassword(oprfOutput []byte) ([]byte, error) {
+	stretched, err := c.stretch(oprfOutput)
+	if err != nil {
+		return nil, err
+	}
+	return c.extract(nil, concat(oprfOutput, stretched)), nil
+}
+
+// store creates the envelope of RFC 9807 section 4.1.2. The client
+// private key is not stored, it is derived again from the randomized
+// password and the envelope nonce.
+func (c *Config) store(randomizedPassword, serverPublicKey, serverIdentity, clientIdentity []byte) (envelope, clientPublicKey, maskingKey, exportKey []byte, err error) {
+	nonce, err := randomBytes(c.rand(), nn)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	maskingKey = c.expand(randomizedPassword, []byte("MaskingKey"), c.nh())
+	authKey := c.expand(randomizedPassword, concat(nonce, []byte("AuthKey")), c.nh())
+	exportKey = c.expand(randomizedPassword, concat(nonce, []byte("ExportKey")), c.nh())
+	kp, err := c.deriveKeyPair(c.expand(randomizedPassword, concat(nonce, []byte("PrivateKey")), nseed))
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	tag := c.mac(authKey, nonce, cleartextCredentials(serverPublicKey, kp.public, serverIdentity, clientIdentity))
+	return concat(nonce, tag), kp.public, maskingKey, exportKey, nil
+}
+
+// recover opens an envelope and returns the client key pair
+func (c *Config) recoverEnvelope(randomizedPassword, serverPublicKey, envelope, serverIdentity, clientIdentity []byte) (*keyPair, []byte, error) {
+	nonce, tag := envelope[:nn], envelope[nn:]
+	authKey := c.expand(randomizedPassword, concat(nonce, []byte("AuthKey")), c.nh())
+	exportKey := c.expand(randomizedPassword, concat(nonce, []byte("ExportKey")), c.nh())
+	kp, err := c.deriveKeyPair(c.expand(randomizedPassword, concat(nonce, []byte("PrivateKey")), nseed))
+	if err != nil {
+		return nil, nil, err
+	}
+	expected := c.mac(authKey, nonce, cleartextCredentials(serverPublicKey, kp.public, serverIdentity, clientIdentity))
+	if !hmac.Equal(tag, expected) {
+		return nil, nil, ErrEnvelopeRecovery
+	}
+	return kp, exportKey, nil
+}
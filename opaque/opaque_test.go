@@ -0,0 +1,157 @@
+package opaque
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jvehent/badcrypto/group"
+)
+
+// register runs the registration flow and returns the record and the
+// export key
+func register(t *testing.T, c *Client, s *Server, password, credentialID []byte) (*RegistrationRecord, []byte) {
+	req, state, err := c.RegistrationInit(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := s.RegistrationResponse(req, credentialID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, exportKey, err := c.RegistrationFinalize(state, resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return record, exportKey
+}
+
+// login runs the login flow, and returns the keys of both sides and the
+// first error
+func login(c *Client, s *Server, record *RegistrationRecord, password, credentialID []byte) (clientKey, serverKey, exportKey []byte, err error) {
+	ke1, cstate, err := c.LoginInit(password)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ke2, sstate, err := s.LoginInit(record, credentialID, c.Identity, ke1)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ke3, clientKey, exportKey, err := c.LoginFinish(cstate, ke2)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	serverKey, err = s.LoginFinish(sstate, ke3)
+	return clientKey, serverKey, exportKey, err
+}
+
+func TestRegistrationAndLogin(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		cfg                Config
+		clientID, serverID []byte
+	}{
+		{Config{}, nil, nil},
+		{Config{Context: []byte("badcrypto demo")}, []byte("alice"), []byte("login.example.com")},
+		{Config{Stretch: ScryptStretch}, nil, []byte("login.example.com")},
+		{Config{Group: group.Ristretto255SHA512}, nil, nil},
+		{Config{Group: group.Ristretto255SHA512, Context: []byte("badcrypto demo")}, []byte("alice"), nil},
+	}
+	for i, tc := range testcases {
+		s, err := NewServer(tc.cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Identity = tc.serverID
+		c := &Client{Config: tc.cfg, Identity: tc.clientID, ServerIdentity: tc.serverID}
+		password := []byte("correct horse battery staple")
+		record, regExportKey := register(t, c, s, password, []byte("alice"))
+
+		clientKey, serverKey, exportKey, err := login(c, s, record, password, []byte("alice"))
+		if err != nil {
+			t.Fatalf("testcase %d: %v", i, err)
+		}
+		if !bytes.Equal(clientKey, serverKey) || len(clientKey) != c.nh() {
+			t.Fatalf("testcase %d expected matching session keys", i)
+		}
+		if !bytes.Equal(exportKey, regExportKey) {
+			t.Fatalf("testcase %d expected the export key to be stable", i)
+		}
+		if bytes.Contains(record.Envelope, password) || bytes.Contains(record.MaskingKey, password) {
+			t.Fatalf("testcase %d expected the record to not contain the password", i)
+		}
+	}
+}
+
+func TestWrongPassword(t *testing.T) {
+	t.Parallel()
+	s, err := NewServer(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Client{}
+	record, _ := register(t, c, s, []byte("hunter2"), []byte("bob"))
+	if _, _, _, err := login(c, s, record, []byte("hunter3"), []byte("bob")); err != ErrEnvelopeRecovery {
+		t.Fatalf("expected envelope recovery error but got %v", err)
+	}
+	// the same record under another credential identifier is keyed
+	// differently by the OPRF
+	if _, _, _, err := login(c, s, record, []byte("hunter2"), []byte("mallory")); err != ErrEnvelopeRecovery {
+		t.Fatalf("expected envelope recovery error but got %v", err)
+	}
+	if _, _, _, err := login(c, s, nil, []byte("hunter2"), []byte("nobody")); err != ErrEnvelopeRecovery {
+		t.Fatalf("expected unknown users to fail like a wrong password but got %v", err)
+	}
+}
+
+func TestTampering(t *testing.T) {
+	t.Parallel()
+	s, err := NewServer(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Client{}
+	password := []byte("hunter2")
+	record, _ := register(t, c, s, password, []byte("bob"))
+
+	ke1, cstate, _ := c.LoginInit(password)
+	ke2, sstate, err := s.LoginInit(record, []byte("bob"), nil, ke1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ke2.ServerMAC[0] ^= 1
+	if _, _, _, err := c.LoginFinish(cstate, ke2); err != ErrAuthentication {
+		t.Fatalf("expected a bad server MAC to fail but got %v", err)
+	}
+	ke2.ServerMAC[0] ^= 1
+	ke3, _, _, err := c.LoginFinish(cstate, ke2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ke3.ClientMAC[0] ^= 1
+	if _, err := s.LoginFinish(sstate, ke3); err != ErrAuthentication {
+		t.Fatalf("expected a bad client MAC to fail but got %v", err)
+	}
+
+	// an impostor server with another key cannot answer for the record
+	impostor, _ := NewServer(Config{})
+	impostor.oprfSeed = s.oprfSeed
+	if _, _, _, err := login(c, impostor, record, password, []byte("bob")); err != ErrEnvelopeRecovery {
+		t.Fatalf("expected an impostor server to fail but got %v", err)
+	}
+}
+
+func TestGroupMismatch(t *testing.T) {
+	t.Parallel()
+	s, err := NewServer(Config{Group: group.Ristretto255SHA512})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Client{}
+	req, _, err := c.RegistrationInit([]byte("password"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RegistrationResponse(req, []byte("alice")); err == nil {
+		t.Fatal("expected a P-256 element to be rejected by a ristretto255 server")
+	}
+}
@@ -0,0 +1,118 @@
+package opaque
+
+import (
+	"errors"
+
+	"github.com/jvehent/badcrypto/oprf"
+)
+
+// RegistrationRequest is sent by the client to start a registration
+type RegistrationRequest struct {
+	BlindedMessage []byte
+}
+
+// RegistrationResponse carries the OPRF evaluation of the password
+type RegistrationResponse struct {
+	EvaluatedMessage []byte
+	ServerPublicKey  []byte
+}
+
+// RegistrationState is kept by the client between the two steps of a
+// registration
+type RegistrationState struct {
+	password []byte
+	blind    *oprf.Blind
+}
+
+// Client is the party that knows the password
+type Client struct {
+	Config
+	// Identity and ServerIdentity default to the public keys when nil
+	Identity       []byte
+	ServerIdentity []byte
+}
+
+// Server holds the long term key and the OPRF seed shared by every
+// client record
+type Server struct {
+	Config
+	// Identity defaults to the public key when nil
+	Identity []byte
+
+	keyPair  *keyPair
+	oprfSeed []byte
+}
+
+// NewServer generates a server key pair and OPRF seed
+func NewServer(cfg Config) (*Server, error) {
+	s := &Server{Config: cfg}
+	var err error
+	if s.keyPair, err = s.generateKeyPair(); err != nil {
+		return nil, err
+	}
+	if s.oprfSeed, err = randomBytes(s.rand(), s.nh()); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// PublicKey returns the serialized long term public key of the server
+func (s *Server) PublicKey() []byte {
+	return s.keyPair.public
+}
+
+// oprfKey derives the OPRF key of a client from the server seed, so the
+// server does not store one key per client
+func (s *Server) oprfKey(credentialID []byte) (*oprf.PrivateKey, error) {
+	seed := s.expand(s.oprfSeed, concat(credentialID, []byte("OprfKey")), nseed)
+	return s.suite().DeriveKeyPair(seed, []byte("OPAQUE-DeriveKeyPair"))
+}
+
+// RegistrationInit blinds the password
+func (c *Client) RegistrationInit(password []byte) (*RegistrationRequest, *RegistrationState, error) {
+	blind, blinded, err := c.suite().Blind(c.rand(), password)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &RegistrationRequest{BlindedMessage: blinded},
+		&RegistrationState{password: append([]byte{}, password...), blind: blind}, nil
+}
+
+// RegistrationResponse evaluates the blinded password with the OPRF key
+// of credentialID, which uniquely identifies the client account
+func (s *Server) RegistrationResponse(req *RegistrationRequest, credentialID []byte) (*RegistrationResponse, error) {
+	key, err := s.oprfKey(credentialID)
+	if err != nil {
+		return nil, err
+	}
+	evaluated, err := key.BlindEvaluate(req.BlindedMessage)
+	if err != nil {
+		return nil, err
+	}
+	return &RegistrationResponse{EvaluatedMessage: evaluated, ServerPublicKey: s.PublicKey()}, nil
+}
+
+// RegistrationFinalize builds the record to upload to the server, and
+// returns the export key, a secret only the client can derive again
+func (c *Client) RegistrationFinalize(state *RegistrationState, resp *RegistrationResponse) (*RegistrationRecord, []byte, error) {
+	if len(resp.ServerPublicKey) != c.npk() {
+		return nil, nil, errors.New("opaque: invalid server public key")
+	}
+	oprfOutput, err := c.suite().Finalize(state.password, state.blind, resp.EvaluatedMessage)
+	if err != nil {
+		return nil, nil, err
+	}
+	rp, err := c.randomizedPassword(oprfOutput)
+	if err != nil {
+		return nil, nil, err
+	}
+	envelope, clientPublicKey, maskingKey, exportKey, err := c.store(rp, resp.ServerPublicKey, c.ServerIdentity, c.Identity)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &RegistrationRecord{
+		ClientPublicKey: clientPublicKey,
+		MaskingKey:      maskingKey,
+		Envelope:        envelope,
+	}, exportKey, nil
+}
@@ -0,0 +1,147 @@
+package openpgplite
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Armor block types defined in RFC 4880 section 6.2
+const (
+	PublicKeyBlock  = "PGP PUBLIC KEY BLOCK"
+	PrivateKeyBlock = "PGP PRIVATE KEY BLOCK"
+	SignatureBlock  = "PGP SIGNATURE"
+	MessageBlock    = "PGP MESSAGE"
+)
+
+// Block is a decoded ASCII armor block
+type Block struct {
+	Type    string            // the text after BEGIN, such as "PGP SIGNATURE"
+	Headers map[string]string // optional armor headers, such as Version or Comment
+	Body    []byte            // the binary packets carried by the block
+}
+
+const (
+	crc24Init = 0xB704CE
+	crc24Poly = 0x1864CFB
+)
+
+// crc24 computes the checksum appended to the end of an armored block,
+// as described in RFC 4880 section 6.1. It is a straight translation of
+// the C code given in the RFC.
+func crc24(data []byte) uint32 {
+	crc := uint32(crc24Init)
+	for _, b := range data {
+		crc ^= uint32(b) << 16
+		for i := 0; i < 8; i++ {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= crc24Poly
+			}
+		}
+	}
+	return crc & 0xFFFFFF
+}
+
+// Encode writes data to w as an ASCII armored block of the given type
+func Encode(w io.Writer, blockType string, headers map[string]string, data []byte) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "-----BEGIN %s-----\n", blockType)
+	// headers are sorted to keep the output deterministic
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&buf, "%s: %s\n", k, headers[k])
+	}
+	buf.WriteString("\n")
+	b64 := base64.StdEncoding.EncodeToString(data)
+	for len(b64) > 64 {
+		buf.WriteString(b64[:64] + "\n")
+		b64 = b64[64:]
+	}
+	if len(b64) > 0 {
+		buf.WriteString(b64 + "\n")
+	}
+	crc := crc24(data)
+	buf.WriteString("=" + base64.StdEncoding.EncodeToString([]byte{byte(crc >> 16), byte(crc >> 8), byte(crc)}) + "\n")
+	fmt.Fprintf(&buf, "-----END %s-----\n", blockType)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Decode reads the first ASCII armored block found in r. Any text before
+// the BEGIN line is skipped. The CRC24 checksum is verified when present.
+func Decode(r io.Reader) (*Block, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	b := &Block{Headers: make(map[string]string)}
+	// find the start of the block
+	for {
+		if !scanner.Scan() {
+			return nil, errors.New("openpgplite: no armored block found")
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "-----BEGIN ") && strings.HasSuffix(line, "-----") {
+			b.Type = strings.TrimSuffix(strings.TrimPrefix(line, "-----BEGIN "), "-----")
+			break
+		}
+	}
+	// headers end with an empty line
+	for {
+		if !scanner.Scan() {
+			return nil, errors.New("openpgplite: truncated armor headers")
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			break
+		}
+		kv := strings.SplitN(line, ": ", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("openpgplite: malformed armor header %q", line)
+		}
+		b.Headers[kv[0]] = kv[1]
+	}
+	var (
+		b64      strings.Builder
+		checksum string
+		ended    bool
+	)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "-----END "+b.Type+"-----" {
+			ended = true
+			break
+		}
+		if strings.HasPrefix(line, "=") && len(line) == 5 {
+			checksum = line[1:]
+			continue
+		}
+		b64.WriteString(line)
+	}
+	if !ended {
+		return nil, errors.New("openpgplite: missing armor END line")
+	}
+	body, err := base64.StdEncoding.DecodeString(b64.String())
+	if err != nil {
+		return nil, fmt.Errorf("openpgplite: invalid armor body: %v", err)
+	}
+	if checksum != "" {
+		crc, err := base64.StdEncoding.DecodeString(checksum)
+		if err != nil || len(crc) != 3 {
+			return nil, errors.New("openpgplite: invalid armor checksum")
+		}
+		if uint32(crc[0])<<16|uint32(crc[1])<<8|uint32(crc[2]) != crc24(body) {
+			return nil, errors.New("openpgplite: armor checksum mismatch")
+		}
+	}
+	b.Body = body
+	return b, nil
+}
@@ -0,0 +1,59 @@
+package openpgplite
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCRC24(t *testing.T) {
+	t.Parallel()
+	// the checksum of an empty input is the initialization value
+	if crc24(nil) != crc24Init {
+		t.Fatalf("expected crc24 of empty input to be %x but got %x", crc24Init, crc24(nil))
+	}
+	// "123456789" is the usual check input of CRC algorithms
+	if crc24([]byte("123456789")) != 0x21CF02 {
+		t.Fatalf("expected crc24 check value 21cf02 but got %x", crc24([]byte("123456789")))
+	}
+}
+
+func TestArmorRoundTrip(t *testing.T) {
+	t.Parallel()
+	var testcases = [][]byte{
+		{},
+		{0x01},
+		bytes.Repeat([]byte{0xD3, 0x4D, 0xB3, 0x3F}, 100),
+	}
+	for i, data := range testcases {
+		var buf bytes.Buffer
+		err := Encode(&buf, SignatureBlock, map[string]string{"Comment": "bad crypto"}, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block, err := Decode(strings.NewReader("some leading text\n" + buf.String()))
+		if err != nil {
+			t.Fatalf("testcase %d failed to decode: %v", i, err)
+		}
+		if block.Type != SignatureBlock || block.Headers["Comment"] != "bad crypto" {
+			t.Fatalf("testcase %d decoded wrong type or headers: %+v", i, block)
+		}
+		if !bytes.Equal(block.Body, data) {
+			t.Fatalf("testcase %d body mismatch\nexp %x\ngot %x", i, data, block.Body)
+		}
+	}
+}
+
+func TestArmorBadChecksum(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	if err := Encode(&buf, MessageBlock, nil, []byte("bad crypto")); err != nil {
+		t.Fatal(err)
+	}
+	// flip a character of the base64 body, which sits on the third line
+	lines := strings.Split(buf.String(), "\n")
+	lines[2] = "A" + lines[2][1:]
+	if _, err := Decode(strings.NewReader(strings.Join(lines, "\n"))); err == nil {
+		t.Fatal("expected checksum mismatch error but got none")
+	}
+}
@@ -0,0 +1,324 @@
+package openpgplite
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"time"
+)
+
+// PublicKeyAlgorithm identifies the algorithm of a key, from RFC 4880 section 9.1
+type PublicKeyAlgorithm uint8
+
+// Supported public key algorithms. EdDSA uses the legacy algorithm
+// identifier 22 that GnuPG emits for ed25519 keys.
+const (
+	PubKeyAlgoRSA     PublicKeyAlgorithm = 1
+	PubKeyAlgoRSASign PublicKeyAlgorithm = 3
+	PubKeyAlgoECDSA   PublicKeyAlgorithm = 19
+	PubKeyAlgoEdDSA   PublicKeyAlgorithm = 22
+	keyPacketVersion                     = 4
+	fingerprintPrefix                    = 0x99
+)
+
+var (
+	oidP256    = []byte{0x2A, 0x86, 0x48, 0xCE, 0x3D, 0x03, 0x01, 0x07}
+	oidEd25519 = []byte{0x2B, 0x06, 0x01, 0x04, 0x01, 0xDA, 0x47, 0x0F, 0x01}
+)
+
+// PublicKey is a version 4 public key or public subkey packet
+type PublicKey struct {
+	CreationTime time.Time
+	PubKeyAlgo   PublicKeyAlgorithm
+	PublicKey    crypto.PublicKey // *rsa.PublicKey, *ecdsa.PublicKey or ed25519.PublicKey
+	Fingerprint  [20]byte
+	KeyID        uint64
+	IsSubkey     bool
+}
+
+// NewPublicKey wraps a stdlib public key into a PublicKey packet
+func NewPublicKey(creationTime time.Time, pub crypto.PublicKey) (*PublicKey, error) {
+	pk := &PublicKey{CreationTime: time.Unix(creationTime.Unix(), 0), PublicKey: pub}
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		pk.PubKeyAlgo = PubKeyAlgoRSA
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, errors.New("openpgplite: only P-256 ecdsa keys are supported")
+		}
+		pk.PubKeyAlgo = PubKeyAlgoECDSA
+	case ed25519.PublicKey:
+		pk.PubKeyAlgo = PubKeyAlgoEdDSA
+	default:
+		return nil, fmt.Errorf("openpgplite: unsupported public key type %T", pub)
+	}
+	pk.setFingerprint()
+	return pk, nil
+}
+
+func parsePublicKey(r io.Reader) (*PublicKey, error) {
+	var hdr [6]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, err
+	}
+	if hdr[0] != keyPacketVersion {
+		return nil, fmt.Errorf("openpgplite: unsupported key packet version %d", hdr[0])
+	}
+	pk := &PublicKey{
+		CreationTime: time.Unix(int64(binary.BigEndian.Uint32(hdr[1:5])), 0),
+		PubKeyAlgo:   PublicKeyAlgorithm(hdr[5]),
+	}
+	switch pk.PubKeyAlgo {
+	case PubKeyAlgoRSA, PubKeyAlgoRSASign:
+		n, err := readMPI(r)
+		if err != nil {
+			return nil, err
+		}
+		e, err := readMPI(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(e) > 4 {
+			return nil, errors.New("openpgplite: rsa public exponent too large")
+		}
+		pk.PublicKey = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	case PubKeyAlgoECDSA, PubKeyAlgoEdDSA:
+		oid, err := readOID(r)
+		if err != nil {
+			return nil, err
+		}
+		point, err := readMPI(r)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case pk.PubKeyAlgo == PubKeyAlgoECDSA && bytes.Equal(oid, oidP256):
+			x, y := elliptic.Unmarshal(elliptic.P256(), point)
+			if x == nil {
+				return nil, errors.New("openpgplite: invalid P-256 point")
+			}
+			pk.PublicKey = &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		case pk.PubKeyAlgo == PubKeyAlgoEdDSA && bytes.Equal(oid, oidEd25519):
+			// ed25519 points are prefixed with 0x40 to mark their native encoding
+			if len(point) != 1+ed25519.PublicKeySize || point[0] != 0x40 {
+				return nil, errors.New("openpgplite: invalid ed25519 point")
+			}
+			pk.PublicKey = ed25519.PublicKey(point[1:])
+		default:
+			return nil, fmt.Errorf("openpgplite: unsupported curve oid %x", oid)
+		}
+	default:
+		return nil, fmt.Errorf("openpgplite: unsupported public key algorithm %d", pk.PubKeyAlgo)
+	}
+	pk.setFingerprint()
+	return pk, nil
+}
+
+func readOID(r io.Reader) ([]byte, error) {
+	var l [1]byte
+	if _, err := io.ReadFull(r, l[:]); err != nil {
+		return nil, err
+	}
+	if l[0] == 0 || l[0] == 0xFF {
+		return nil, errors.New("openpgplite: invalid curve oid length")
+	}
+	oid := make([]byte, l[0])
+	_, err := io.ReadFull(r, oid)
+	return oid, err
+}
+
+// body returns the serialized content of the key packet, without header
+func (pk *PublicKey) body() []byte {
+	buf := []byte{keyPacketVersion, 0, 0, 0, 0, byte(pk.PubKeyAlgo)}
+	binary.BigEndian.PutUint32(buf[1:5], uint32(pk.CreationTime.Unix()))
+	switch k := pk.PublicKey.(type) {
+	case *rsa.PublicKey:
+		buf = append(buf, encodeMPI(k.N.Bytes())...)
+		buf = append(buf, encodeMPI(big.NewInt(int64(k.E)).Bytes())...)
+	case *ecdsa.PublicKey:
+		buf = append(buf, byte(len(oidP256)))
+		buf = append(buf, oidP256...)
+		buf = append(buf, encodeMPI(elliptic.Marshal(k.Curve, k.X, k.Y))...)
+	case ed25519.PublicKey:
+		buf = append(buf, byte(len(oidEd25519)))
+		buf = append(buf, oidEd25519...)
+		buf = append(buf, encodeMPI(append([]byte{0x40}, k...))...)
+	}
+	return buf
+}
+
+// setFingerprint computes the v4 fingerprint, which is the SHA1 of the
+// key packet body prefixed with 0x99 and its two bytes length, and the
+// key ID, which is the lower 64 bits of the fingerprint.
+func (pk *PublicKey) setFingerprint() {
+	body := pk.body()
+	h := sha1.New()
+	h.Write([]byte{fingerprintPrefix, byte(len(body) >> 8), byte(len(body))})
+	h.Write(body)
+	copy(pk.Fingerprint[:], h.Sum(nil))
+	pk.KeyID = binary.BigEndian.Uint64(pk.Fingerprint[12:20])
+}
+
+// KeyIDString returns the key ID in its usual hexadecimal form
+func (pk *PublicKey) KeyIDString() string {
+	return fmt.Sprintf("%016X", pk.KeyID)
+}
+
+// Serialize writes the public key packet to w
+func (pk *PublicKey) Serialize(w io.Writer) error {
+	tag := TagPublicKey
+	if pk.IsSubkey {
+		tag = TagPublicSubkey
+	}
+	return writePacket(w, tag, pk.body())
+}
+
+// PrivateKey is a version 4 secret key packet. Only unencrypted secret
+// keys can be used for signing. Encrypted ones are kept as opaque bytes
+// so they can be written back unchanged.
+type PrivateKey struct {
+	PublicKey
+	Encrypted  bool
+	PrivateKey crypto.Signer // *rsa.PrivateKey, *ecdsa.PrivateKey or ed25519.PrivateKey
+	encrypted  []byte        // s2k and encrypted key material of protected keys
+}
+
+// NewPrivateKey wraps a stdlib signer into an unencrypted PrivateKey packet
+func NewPrivateKey(creationTime time.Time, priv crypto.Signer) (*PrivateKey, error) {
+	pk, err := NewPublicKey(creationTime, priv.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{PublicKey: *pk, PrivateKey: priv}, nil
+}
+
+func parsePrivateKey(contents []byte, isSubkey bool) (*PrivateKey, error) {
+	r := bytes.NewReader(contents)
+	pub, err := parsePublicKey(r)
+	if err != nil {
+		return nil, err
+	}
+	pub.IsSubkey = isSubkey
+	priv := &PrivateKey{PublicKey: *pub}
+	s2kUsage, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	rest, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if s2kUsage != 0 {
+		priv.Encrypted = true
+		priv.encrypted = append([]byte{s2kUsage}, rest...)
+		return priv, nil
+	}
+	if len(rest) < 2 {
+		return nil, errors.New("openpgplite: secret key packet too short")
+	}
+	material, checksum := rest[:len(rest)-2], binary.BigEndian.Uint16(rest[len(rest)-2:])
+	if secretChecksum(material) != checksum {
+		return nil, errors.New("openpgplite: secret key checksum mismatch")
+	}
+	mr := bytes.NewReader(material)
+	switch k := pub.PublicKey.(type) {
+	case *rsa.PublicKey:
+		var mpis [4][]byte // d, p, q, u
+		for i := range mpis {
+			if mpis[i], err = readMPI(mr); err != nil {
+				return nil, err
+			}
+		}
+		rsaPriv := &rsa.PrivateKey{
+			PublicKey: *k,
+			D:         new(big.Int).SetBytes(mpis[0]),
+			Primes:    []*big.Int{new(big.Int).SetBytes(mpis[1]), new(big.Int).SetBytes(mpis[2])},
+		}
+		if err := rsaPriv.Validate(); err != nil {
+			return nil, err
+		}
+		rsaPriv.Precompute()
+		priv.PrivateKey = rsaPriv
+	case *ecdsa.PublicKey:
+		d, err := readMPI(mr)
+		if err != nil {
+			return nil, err
+		}
+		priv.PrivateKey = &ecdsa.PrivateKey{PublicKey: *k, D: new(big.Int).SetBytes(d)}
+	case ed25519.PublicKey:
+		seed, err := readMPI(mr)
+		if err != nil {
+			return nil, err
+		}
+		if seed, err = padLeft(seed, ed25519.SeedSize); err != nil {
+			return nil, err
+		}
+		edPriv := ed25519.NewKeyFromSeed(seed)
+		if !bytes.Equal(edPriv.Public().(ed25519.PublicKey), k) {
+			return nil, errors.New("openpgplite: ed25519 secret does not match public key")
+		}
+		priv.PrivateKey = edPriv
+	}
+	return priv, nil
+}
+
+// secretChecksum is the sum of all bytes of the secret key material, mod 65536
+func secretChecksum(material []byte) (sum uint16) {
+	for _, b := range material {
+		sum += uint16(b)
+	}
+	return
+}
+
+// Serialize writes the secret key packet to w
+func (priv *PrivateKey) Serialize(w io.Writer) error {
+	body := priv.PublicKey.body()
+	if priv.Encrypted {
+		body = append(body, priv.encrypted...)
+	} else {
+		var material []byte
+		switch k := priv.PrivateKey.(type) {
+		case *rsa.PrivateKey:
+			if len(k.Primes) != 2 {
+				return errors.New("openpgplite: multi-prime rsa keys are not supported")
+			}
+			// OpenPGP wants p < q and u = p^-1 mod q
+			p, q := k.Primes[0], k.Primes[1]
+			if p.Cmp(q) > 0 {
+				p, q = q, p
+			}
+			u := new(big.Int).ModInverse(p, q)
+			for _, v := range []*big.Int{k.D, p, q, u} {
+				material = append(material, encodeMPI(v.Bytes())...)
+			}
+		case *ecdsa.PrivateKey:
+			material = encodeMPI(k.D.Bytes())
+		case ed25519.PrivateKey:
+			material = encodeMPI(k.Seed())
+		default:
+			return fmt.Errorf("openpgplite: unsupported private key type %T", priv.PrivateKey)
+		}
+		body = append(body, 0)
+		body = append(body, material...)
+		sum := secretChecksum(material)
+		body = append(body, byte(sum>>8), byte(sum))
+	}
+	tag := TagSecretKey
+	if priv.IsSubkey {
+		tag = TagSecretSubkey
+	}
+	return writePacket(w, tag, body)
+}
@@ -0,0 +1,101 @@
+package openpgplite
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestReadGnuPGKeys(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		file        string
+		fingerprint string
+		algo        PublicKeyAlgorithm
+	}{
+		{"testdata/ed25519.pub.asc", "6D4CF5CEBBC7D4B439215EA55FC592115248D827", PubKeyAlgoEdDSA},
+		{"testdata/rsa.pub.asc", "39001082CB340B1A3D4240CA77E1847225EC5DC0", PubKeyAlgoRSA},
+	}
+	for i, testcase := range testcases {
+		fd, err := os.Open(testcase.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys, err := ReadKeyRing(fd)
+		fd.Close()
+		if err != nil {
+			t.Fatalf("testcase %d failed to read keyring: %v", i, err)
+		}
+		if keys[0].PubKeyAlgo != testcase.algo {
+			t.Fatalf("testcase %d expected algorithm %d but got %d", i, testcase.algo, keys[0].PubKeyAlgo)
+		}
+		fp := fmtFingerprint(keys[0].Fingerprint)
+		if fp != testcase.fingerprint {
+			t.Fatalf("testcase %d expected fingerprint %s but got %s", i, testcase.fingerprint, fp)
+		}
+		if keys[0].KeyIDString() != testcase.fingerprint[24:] {
+			t.Fatalf("testcase %d expected key id %s but got %s", i, testcase.fingerprint[24:], keys[0].KeyIDString())
+		}
+	}
+}
+
+func fmtFingerprint(fp [20]byte) string {
+	const hex = "0123456789ABCDEF"
+	var out []byte
+	for _, b := range fp {
+		out = append(out, hex[b>>4], hex[b&0xf])
+	}
+	return string(out)
+}
+
+func TestPrivateKeyRoundTrip(t *testing.T) {
+	t.Parallel()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, signer := range []crypto.Signer{rsaKey, ecKey, edKey} {
+		priv, err := NewPrivateKey(time.Now(), signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := priv.Serialize(&buf); err != nil {
+			t.Fatal(err)
+		}
+		p, err := ReadPacket(&buf)
+		if err != nil {
+			t.Fatalf("testcase %d failed to parse secret key: %v", i, err)
+		}
+		priv2, ok := p.(*PrivateKey)
+		if !ok {
+			t.Fatalf("testcase %d expected a private key but got %T", i, p)
+		}
+		if priv2.Fingerprint != priv.Fingerprint {
+			t.Fatalf("testcase %d fingerprint changed after round trip", i)
+		}
+		// a signature made with the parsed key must verify with the original one
+		sig, err := DetachSign(priv2, bytes.NewReader([]byte("bad crypto")), crypto.SHA256, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := priv.VerifySignature(bytes.NewReader([]byte("bad crypto")), sig); err != nil {
+			t.Fatalf("testcase %d signature failed to verify: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+// Package openpgplite parses and emits a minimal subset of the RFC 4880
+// OpenPGP packets: enough to load a key, verify a detached signature over
+// a file, and produce one in return. It does not decrypt messages.
+package openpgplite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"time"
+)
+
+// PacketTag identifies the type of an OpenPGP packet
+type PacketTag uint8
+
+// Packet tags from RFC 4880 section 4.3
+const (
+	TagSignature              PacketTag = 2
+	TagSecretKey              PacketTag = 5
+	TagPublicKey              PacketTag = 6
+	TagSecretSubkey           PacketTag = 7
+	TagSymmetricallyEncrypted PacketTag = 9
+	TagLiteralData            PacketTag = 11
+	TagUserID                 PacketTag = 13
+	TagPublicSubkey           PacketTag = 14
+	TagSymEncryptedIntegrity  PacketTag = 18
+)
+
+// Packet is implemented by every packet type this package knows about
+type Packet interface {
+	// Serialize writes the packet, header included, to w
+	Serialize(w io.Writer) error
+}
+
+// readHeader reads a packet header in either the old or the new format and
+// returns the tag along with a reader limited to the packet body. Partial
+// body lengths are reassembled in memory, since this is a lite package.
+func readHeader(r io.Reader) (tag PacketTag, body io.Reader, err error) {
+	var hdr [1]byte
+	if _, err = io.ReadFull(r, hdr[:]); err != nil {
+		return
+	}
+	if hdr[0]&0x80 == 0 {
+		err = errors.New("openpgplite: packet header does not have its high bit set")
+		return
+	}
+	if hdr[0]&0x40 == 0 {
+		// old format: tag in bits 5-2, length type in bits 1-0
+		tag = PacketTag((hdr[0] & 0x3f) >> 2)
+		var length int64
+		switch hdr[0] & 3 {
+		case 0:
+			var l [1]byte
+			_, err = io.ReadFull(r, l[:])
+			length = int64(l[0])
+		case 1:
+			var l [2]byte
+			_, err = io.ReadFull(r, l[:])
+			length = int64(binary.BigEndian.Uint16(l[:]))
+		case 2:
+			var l [4]byte
+			_, err = io.ReadFull(r, l[:])
+			length = int64(binary.BigEndian.Uint32(l[:]))
+		default:
+			// indeterminate length, the packet extends to the end of the input
+			body = r
+			return
+		}
+		body = io.LimitReader(r, length)
+		return
+	}
+	// new format
+	tag = PacketTag(hdr[0] & 0x3f)
+	var contents []byte
+	for {
+		length, partial, lerr := readNewLength(r)
+		if lerr != nil {
+			err = lerr
+			return
+		}
+		if !partial {
+			if contents == nil {
+				body = io.LimitReader(r, length)
+				return
+			}
+			chunk := make([]byte, length)
+			if _, err = io.ReadFull(r, chunk); err != nil {
+				return
+			}
+			body = bytes.NewReader(append(contents, chunk...))
+			return
+		}
+		chunk := make([]byte, length)
+		if _, err = io.ReadFull(r, chunk); err != nil {
+			return
+		}
+		contents = append(contents, chunk...)
+	}
+}
+
+// readNewLength decodes a new format body length, and indicates if it
+// was a partial length, meaning more chunks follow.
+func readNewLength(r io.Reader) (length int64, partial bool, err error) {
+	var b [4]byte
+	if _, err = io.ReadFull(r, b[:1]); err != nil {
+		return
+	}
+	switch {
+	case b[0] < 192:
+		length = int64(b[0])
+	case b[0] < 224:
+		if _, err = io.ReadFull(r, b[1:2]); err != nil {
+			return
+		}
+		length = (int64(b[0])-192)<<8 + int64(b[1]) + 192
+	case b[0] < 255:
+		length = int64(1) << (b[0] & 0x1f)
+		partial = true
+	default:
+		if _, err = io.ReadFull(r, b[:4]); err != nil {
+			return
+		}
+		length = int64(binary.BigEndian.Uint32(b[:4]))
+	}
+	return
+}
+
+// writeHeader writes a new format packet header with a definite length
+func writeHeader(w io.Writer, tag PacketTag, length int) error {
+	hdr := []byte{0xC0 | byte(tag)}
+	switch {
+	case length < 192:
+		hdr = append(hdr, byte(length))
+	case length < 8384:
+		length -= 192
+		hdr = append(hdr, byte(length>>8)+192, byte(length))
+	default:
+		hdr = append(hdr, 0xFF, byte(length>>24), byte(length>>16), byte(length>>8), byte(length))
+	}
+	_, err := w.Write(hdr)
+	return err
+}
+
+// writePacket frames body with a header for tag and writes it to w
+func writePacket(w io.Writer, tag PacketTag, body []byte) error {
+	if err := writeHeader(w, tag, len(body)); err != nil {
+		return err
+	}
+	_, err := w.Write(body)
+	return err
+}
+
+// ReadPacket reads the next packet from r. Known packets are returned as
+// their concrete types, others as an *OpaquePacket. io.EOF is returned
+// when no more packets are available.
+func ReadPacket(r io.Reader) (Packet, error) {
+	tag, body, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	contents, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case TagPublicKey, TagPublicSubkey:
+		pk, err := parsePublicKey(bytes.NewReader(contents))
+		if err != nil {
+			return nil, err
+		}
+		pk.IsSubkey = tag == TagPublicSubkey
+		return pk, nil
+	case TagSecretKey, TagSecretSubkey:
+		return parsePrivateKey(contents, tag == TagSecretSubkey)
+	case TagSignature:
+		return parseSignature(contents)
+	case TagLiteralData:
+		return parseLiteralData(contents)
+	case TagUserID:
+		return &UserID{ID: string(contents)}, nil
+	case TagSymmetricallyEncrypted, TagSymEncryptedIntegrity:
+		return parseSymmetricallyEncrypted(tag, contents)
+	}
+	return &OpaquePacket{Tag: tag, Contents: contents}, nil
+}
+
+// ReadPackets reads all the packets contained in buf
+func ReadPackets(buf []byte) (packets []Packet, err error) {
+	r := bytes.NewReader(buf)
+	for {
+		p, err := ReadPacket(r)
+		if err == io.EOF {
+			return packets, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		packets = append(packets, p)
+	}
+}
+
+// OpaquePacket holds the raw contents of a packet this package does not parse
+type OpaquePacket struct {
+	Tag      PacketTag
+	Contents []byte
+}
+
+// Serialize writes the opaque packet back unchanged
+func (op *OpaquePacket) Serialize(w io.Writer) error {
+	return writePacket(w, op.Tag, op.Contents)
+}
+
+// UserID is a packet containing the name and email of a key holder
+type UserID struct {
+	ID string
+}
+
+// Serialize writes the user id packet to w
+func (uid *UserID) Serialize(w io.Writer) error {
+	return writePacket(w, TagUserID, []byte(uid.ID))
+}
+
+// LiteralData is a packet that carries the plaintext of a message
+type LiteralData struct {
+	IsBinary bool
+	FileName string
+	Time     time.Time
+	Body     []byte
+}
+
+func parseLiteralData(contents []byte) (*LiteralData, error) {
+	if len(contents) < 6 || len(contents) < 6+int(contents[1]) {
+		return nil, errors.New("openpgplite: literal data packet too short")
+	}
+	ld := new(LiteralData)
+	ld.IsBinary = contents[0] == 'b'
+	nameLen := int(contents[1])
+	ld.FileName = string(contents[2 : 2+nameLen])
+	ld.Time = time.Unix(int64(binary.BigEndian.Uint32(contents[2+nameLen:6+nameLen])), 0)
+	ld.Body = contents[6+nameLen:]
+	return ld, nil
+}
+
+// Serialize writes the literal data packet to w
+func (ld *LiteralData) Serialize(w io.Writer) error {
+	if len(ld.FileName) > 255 {
+		return errors.New("openpgplite: literal data file name too long")
+	}
+	format := byte('t')
+	if ld.IsBinary {
+		format = 'b'
+	}
+	body := []byte{format, byte(len(ld.FileName))}
+	body = append(body, ld.FileName...)
+	var ts [4]byte
+	binary.BigEndian.PutUint32(ts[:], uint32(ld.Time.Unix()))
+	body = append(body, ts[:]...)
+	body = append(body, ld.Body...)
+	return writePacket(w, TagLiteralData, body)
+}
+
+// SymmetricallyEncrypted holds the ciphertext of a Symmetrically Encrypted
+// Data packet (tag 9), or of a Symmetrically Encrypted Integrity Protected
+// Data packet (tag 18) when MDC is set. The ciphertext is not decrypted.
+type SymmetricallyEncrypted struct {
+	MDC        bool   // true for tag 18 packets
+	Version    byte   // only set for tag 18 packets, always 1
+	Ciphertext []byte // raw OpenPGP CFB ciphertext
+}
+
+func parseSymmetricallyEncrypted(tag PacketTag, contents []byte) (*SymmetricallyEncrypted, error) {
+	se := &SymmetricallyEncrypted{MDC: tag == TagSymEncryptedIntegrity}
+	if se.MDC {
+		if len(contents) < 1 || contents[0] != 1 {
+			return nil, errors.New("openpgplite: unsupported integrity protected packet version")
+		}
+		se.Version = contents[0]
+		contents = contents[1:]
+	}
+	se.Ciphertext = contents
+	return se, nil
+}
+
+// Serialize writes the encrypted data packet to w
+func (se *SymmetricallyEncrypted) Serialize(w io.Writer) error {
+	if !se.MDC {
+		return writePacket(w, TagSymmetricallyEncrypted, se.Ciphertext)
+	}
+	return writePacket(w, TagSymEncryptedIntegrity, append([]byte{1}, se.Ciphertext...))
+}
+
+// readMPI reads a multiprecision integer, which is a two bytes
+// bit count followed by the big endian value
+func readMPI(r io.Reader) ([]byte, error) {
+	var l [2]byte
+	if _, err := io.ReadFull(r, l[:]); err != nil {
+		return nil, err
+	}
+	bits := int(binary.BigEndian.Uint16(l[:]))
+	buf := make([]byte, (bits+7)/8)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// encodeMPI encodes buf as a multiprecision integer, stripping leading zeroes
+func encodeMPI(buf []byte) []byte {
+	for len(buf) > 0 && buf[0] == 0 {
+		buf = buf[1:]
+	}
+	bits := 0
+	if len(buf) > 0 {
+		bits = (len(buf)-1)*8 + new(big.Int).SetBytes(buf[:1]).BitLen()
+	}
+	return append([]byte{byte(bits >> 8), byte(bits)}, buf...)
+}
+
+// padLeft returns buf prepended with zeroes up to size bytes
+func padLeft(buf []byte, size int) ([]byte, error) {
+	if len(buf) > size {
+		return nil, fmt.Errorf("openpgplite: value of %d bytes does not fit in %d", len(buf), size)
+	}
+	out := make([]byte, size)
+	copy(out[size-len(buf):], buf)
+	return out, nil
+}
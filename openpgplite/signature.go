@@ -0,0 +1,393 @@
+package openpgplite
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"time"
+
+	// register the hash functions the signatures can use
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// Signature types from RFC 4880 section 5.2.1
+const (
+	SigTypeBinary byte = 0x00
+	SigTypeText   byte = 0x01
+)
+
+// signature subpacket types from RFC 4880 section 5.2.3.1
+const (
+	subpacketCreationTime      = 2
+	subpacketIssuer            = 16
+	subpacketIssuerFingerprint = 33
+)
+
+const signatureVersion = 4
+
+// hashIDs maps the OpenPGP hash algorithm identifiers to Go hashes
+var hashIDs = map[byte]crypto.Hash{
+	2:  crypto.SHA1,
+	8:  crypto.SHA256,
+	9:  crypto.SHA384,
+	10: crypto.SHA512,
+	11: crypto.SHA224,
+}
+
+func hashToID(h crypto.Hash) (byte, bool) {
+	for id, hash := range hashIDs {
+		if hash == h {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// Signature is a version 4 signature packet
+type Signature struct {
+	SigType      byte
+	PubKeyAlgo   PublicKeyAlgorithm
+	Hash         crypto.Hash
+	CreationTime time.Time
+	IssuerKeyID  uint64 // zero if the signature does not name its issuer
+
+	hashed   []byte   // hashed subpackets, kept raw because they are signed
+	unhashed []byte   // unhashed subpackets
+	hashTag  [2]byte  // first two bytes of the signed digest
+	mpis     [][]byte // one mpi for RSA, r and s for ECDSA and EdDSA
+}
+
+func parseSignature(contents []byte) (*Signature, error) {
+	r := bytes.NewReader(contents)
+	var hdr [6]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, err
+	}
+	if hdr[0] != signatureVersion {
+		return nil, fmt.Errorf("openpgplite: unsupported signature version %d", hdr[0])
+	}
+	sig := &Signature{SigType: hdr[1], PubKeyAlgo: PublicKeyAlgorithm(hdr[2])}
+	var ok bool
+	if sig.Hash, ok = hashIDs[hdr[3]]; !ok {
+		return nil, fmt.Errorf("openpgplite: unsupported hash algorithm %d", hdr[3])
+	}
+	sig.hashed = make([]byte, binary.BigEndian.Uint16(hdr[4:6]))
+	if _, err := io.ReadFull(r, sig.hashed); err != nil {
+		return nil, err
+	}
+	var l [2]byte
+	if _, err := io.ReadFull(r, l[:]); err != nil {
+		return nil, err
+	}
+	sig.unhashed = make([]byte, binary.BigEndian.Uint16(l[:]))
+	if _, err := io.ReadFull(r, sig.unhashed); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(r, sig.hashTag[:]); err != nil {
+		return nil, err
+	}
+	count := 2
+	switch sig.PubKeyAlgo {
+	case PubKeyAlgoRSA, PubKeyAlgoRSASign:
+		count = 1
+	case PubKeyAlgoECDSA, PubKeyAlgoEdDSA:
+	default:
+		return nil, fmt.Errorf("openpgplite: unsupported signature algorithm %d", sig.PubKeyAlgo)
+	}
+	for i := 0; i < count; i++ {
+		mpi, err := readMPI(r)
+		if err != nil {
+			return nil, err
+		}
+		sig.mpis = append(sig.mpis, mpi)
+	}
+	if err := sig.parseSubpackets(sig.hashed, true); err != nil {
+		return nil, err
+	}
+	if err := sig.parseSubpackets(sig.unhashed, false); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// parseSubpackets extracts the creation time and issuer from a subpacket
+// area. The creation time is only trusted when it is in the hashed area.
+func (sig *Signature) parseSubpackets(area []byte, hashed bool) error {
+	for len(area) > 0 {
+		var length int
+		switch {
+		case area[0] < 192:
+			length, area = int(area[0]), area[1:]
+		case area[0] < 255:
+			if len(area) < 2 {
+				return errors.New("openpgplite: truncated subpacket length")
+			}
+			length, area = (int(area[0])-192)<<8+int(area[1])+192, area[2:]
+		default:
+			if len(area) < 5 {
+				return errors.New("openpgplite: truncated subpacket length")
+			}
+			length, area = int(binary.BigEndian.Uint32(area[1:5])), area[5:]
+		}
+		if length < 1 || length > len(area) {
+			return errors.New("openpgplite: invalid subpacket length")
+		}
+		typ, data := area[0]&0x7f, area[1:length]
+		critical := area[0]&0x80 != 0
+		area = area[length:]
+		switch typ {
+		case subpacketCreationTime:
+			if !hashed || len(data) != 4 {
+				return errors.New("openpgplite: invalid signature creation time")
+			}
+			sig.CreationTime = time.Unix(int64(binary.BigEndian.Uint32(data)), 0)
+		case subpacketIssuer:
+			if len(data) != 8 {
+				return errors.New("openpgplite: invalid issuer subpacket")
+			}
+			sig.IssuerKeyID = binary.BigEndian.Uint64(data)
+		case subpacketIssuerFingerprint:
+			if len(data) == 21 && data[0] == keyPacketVersion && sig.IssuerKeyID == 0 {
+				sig.IssuerKeyID = binary.BigEndian.Uint64(data[13:21])
+			}
+		default:
+			if critical && hashed {
+				return fmt.Errorf("openpgplite: unsupported critical subpacket %d", typ)
+			}
+		}
+	}
+	return nil
+}
+
+// digest hashes the signed data followed by the v4 signature trailer
+func (sig *Signature) digest(signed io.Reader) ([]byte, error) {
+	if !sig.Hash.Available() {
+		return nil, fmt.Errorf("openpgplite: hash %v is not available", sig.Hash)
+	}
+	h := sig.Hash.New()
+	switch sig.SigType {
+	case SigTypeBinary:
+		if _, err := io.Copy(h, signed); err != nil {
+			return nil, err
+		}
+	case SigTypeText:
+		// text signatures are computed over lines ending with CRLF
+		data, err := ioutil.ReadAll(signed)
+		if err != nil {
+			return nil, err
+		}
+		data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+		h.Write(bytes.ReplaceAll(data, []byte("\n"), []byte("\r\n")))
+	default:
+		return nil, fmt.Errorf("openpgplite: unsupported signature type 0x%02x", sig.SigType)
+	}
+	hashID, _ := hashToID(sig.Hash)
+	prefix := []byte{signatureVersion, sig.SigType, byte(sig.PubKeyAlgo), hashID,
+		byte(len(sig.hashed) >> 8), byte(len(sig.hashed))}
+	prefix = append(prefix, sig.hashed...)
+	h.Write(prefix)
+	trailer := []byte{signatureVersion, 0xFF, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(trailer[2:], uint32(len(prefix)))
+	h.Write(trailer)
+	return h.Sum(nil), nil
+}
+
+// VerifySignature checks that sig is a valid signature made by pk over
+// the data read from signed
+func (pk *PublicKey) VerifySignature(signed io.Reader, sig *Signature) error {
+	if sig.PubKeyAlgo != pk.PubKeyAlgo {
+		return errors.New("openpgplite: signature and key algorithms do not match")
+	}
+	digest, err := sig.digest(signed)
+	if err != nil {
+		return err
+	}
+	if digest[0] != sig.hashTag[0] || digest[1] != sig.hashTag[1] {
+		return errors.New("openpgplite: signature hash tag mismatch")
+	}
+	switch k := pk.PublicKey.(type) {
+	case *rsa.PublicKey:
+		s, err := padLeft(sig.mpis[0], k.Size())
+		if err != nil {
+			return err
+		}
+		return rsa.VerifyPKCS1v15(k, sig.Hash, digest, s)
+	case *ecdsa.PublicKey:
+		r, s := new(big.Int).SetBytes(sig.mpis[0]), new(big.Int).SetBytes(sig.mpis[1])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return errors.New("openpgplite: invalid ecdsa signature")
+		}
+	case ed25519.PublicKey:
+		r, err := padLeft(sig.mpis[0], 32)
+		if err != nil {
+			return err
+		}
+		s, err := padLeft(sig.mpis[1], 32)
+		if err != nil {
+			return err
+		}
+		if !ed25519.Verify(k, digest, append(r, s...)) {
+			return errors.New("openpgplite: invalid eddsa signature")
+		}
+	default:
+		return fmt.Errorf("openpgplite: unsupported public key type %T", pk.PublicKey)
+	}
+	return nil
+}
+
+// DetachSign produces a binary signature by priv over the data read from message
+func DetachSign(priv *PrivateKey, message io.Reader, hash crypto.Hash, now time.Time) (*Signature, error) {
+	if priv.Encrypted {
+		return nil, errors.New("openpgplite: private key is encrypted")
+	}
+	if _, ok := hashToID(hash); !ok {
+		return nil, fmt.Errorf("openpgplite: unsupported hash %v", hash)
+	}
+	sig := &Signature{
+		SigType:      SigTypeBinary,
+		PubKeyAlgo:   priv.PubKeyAlgo,
+		Hash:         hash,
+		CreationTime: time.Unix(now.Unix(), 0),
+		IssuerKeyID:  priv.KeyID,
+	}
+	sig.hashed = []byte{5, subpacketCreationTime, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(sig.hashed[2:], uint32(sig.CreationTime.Unix()))
+	sig.hashed = append(sig.hashed, 22, subpacketIssuerFingerprint, keyPacketVersion)
+	sig.hashed = append(sig.hashed, priv.Fingerprint[:]...)
+	sig.unhashed = []byte{9, subpacketIssuer, 0, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint64(sig.unhashed[2:], priv.KeyID)
+
+	digest, err := sig.digest(message)
+	if err != nil {
+		return nil, err
+	}
+	copy(sig.hashTag[:], digest[:2])
+	switch k := priv.PrivateKey.(type) {
+	case *rsa.PrivateKey:
+		s, err := rsa.SignPKCS1v15(rand.Reader, k, hash, digest)
+		if err != nil {
+			return nil, err
+		}
+		sig.mpis = [][]byte{s}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest)
+		if err != nil {
+			return nil, err
+		}
+		sig.mpis = [][]byte{r.Bytes(), s.Bytes()}
+	case ed25519.PrivateKey:
+		rs := ed25519.Sign(k, digest)
+		sig.mpis = [][]byte{rs[:32], rs[32:]}
+	default:
+		return nil, fmt.Errorf("openpgplite: unsupported private key type %T", priv.PrivateKey)
+	}
+	return sig, nil
+}
+
+// Serialize writes the signature packet to w
+func (sig *Signature) Serialize(w io.Writer) error {
+	hashID, ok := hashToID(sig.Hash)
+	if !ok {
+		return fmt.Errorf("openpgplite: unsupported hash %v", sig.Hash)
+	}
+	body := []byte{signatureVersion, sig.SigType, byte(sig.PubKeyAlgo), hashID,
+		byte(len(sig.hashed) >> 8), byte(len(sig.hashed))}
+	body = append(body, sig.hashed...)
+	body = append(body, byte(len(sig.unhashed)>>8), byte(len(sig.unhashed)))
+	body = append(body, sig.unhashed...)
+	body = append(body, sig.hashTag[:]...)
+	for _, mpi := range sig.mpis {
+		body = append(body, encodeMPI(mpi)...)
+	}
+	return writePacket(w, TagSignature, body)
+}
+
+// ArmoredDetachSign signs message with priv using SHA-256 and writes
+// the armored signature to w
+func ArmoredDetachSign(w io.Writer, priv *PrivateKey, message io.Reader) error {
+	sig, err := DetachSign(priv, message, crypto.SHA256, time.Now())
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := sig.Serialize(&buf); err != nil {
+		return err
+	}
+	return Encode(w, SignatureBlock, nil, buf.Bytes())
+}
+
+// ReadKeyRing reads all the public keys, including subkeys, contained in
+// an armored or binary keyring. Secret keys contribute their public part.
+func ReadKeyRing(r io.Reader) ([]*PublicKey, error) {
+	packets, err := readMaybeArmored(r)
+	if err != nil {
+		return nil, err
+	}
+	var keys []*PublicKey
+	for _, p := range packets {
+		switch k := p.(type) {
+		case *PublicKey:
+			keys = append(keys, k)
+		case *PrivateKey:
+			keys = append(keys, &k.PublicKey)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("openpgplite: no key found in keyring")
+	}
+	return keys, nil
+}
+
+// CheckDetachedSignature verifies the armored or binary signature read
+// from signature over the data read from signed, using the key from
+// keyring that matches the signature issuer. It returns the signing key.
+func CheckDetachedSignature(keyring []*PublicKey, signed, signature io.Reader) (*PublicKey, error) {
+	packets, err := readMaybeArmored(signature)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range packets {
+		sig, ok := p.(*Signature)
+		if !ok {
+			continue
+		}
+		for _, pk := range keyring {
+			if sig.IssuerKeyID != 0 && sig.IssuerKeyID != pk.KeyID {
+				continue
+			}
+			if err := pk.VerifySignature(signed, sig); err != nil {
+				return nil, err
+			}
+			return pk, nil
+		}
+		return nil, fmt.Errorf("openpgplite: no key matches signature issuer %016X", sig.IssuerKeyID)
+	}
+	return nil, errors.New("openpgplite: no signature packet found")
+}
+
+// readMaybeArmored reads packets from r, removing the armor first if present
+func readMaybeArmored(r io.Reader) ([]Packet, error) {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Contains(buf, []byte("-----BEGIN PGP ")) {
+		block, err := Decode(bytes.NewReader(buf))
+		if err != nil {
+			return nil, err
+		}
+		buf = block.Body
+	}
+	return ReadPackets(buf)
+}
@@ -0,0 +1,158 @@
+package openpgplite
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyGnuPGSignatures(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		keyring, signature string
+	}{
+		{"testdata/ed25519.pub.asc", "testdata/message.txt.ed25519.asc"},
+		{"testdata/rsa.pub.asc", "testdata/message.txt.rsa.asc"},
+	}
+	message, err := ioutil.ReadFile("testdata/message.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, testcase := range testcases {
+		krd, err := os.Open(testcase.keyring)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys, err := ReadKeyRing(krd)
+		krd.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := ioutil.ReadFile(testcase.signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signer, err := CheckDetachedSignature(keys, bytes.NewReader(message), bytes.NewReader(sig))
+		if err != nil {
+			t.Fatalf("testcase %d failed to verify signature: %v", i, err)
+		}
+		if signer.KeyID != keys[0].KeyID {
+			t.Fatalf("testcase %d verified with unexpected key %s", i, signer.KeyIDString())
+		}
+		// a modified message must not verify
+		tampered := append([]byte("not "), message...)
+		_, err = CheckDetachedSignature(keys, bytes.NewReader(tampered), bytes.NewReader(sig))
+		if err == nil {
+			t.Fatalf("testcase %d verified a tampered message", i)
+		}
+	}
+}
+
+func TestArmoredDetachSign(t *testing.T) {
+	t.Parallel()
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := NewPrivateKey(time.Now(), edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var armored bytes.Buffer
+	if err := ArmoredDetachSign(&armored, priv, strings.NewReader("bad crypto")); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(armored.String(), "-----BEGIN PGP SIGNATURE-----") {
+		t.Fatalf("unexpected armor output:\n%s", armored.String())
+	}
+	signer, err := CheckDetachedSignature([]*PublicKey{&priv.PublicKey}, strings.NewReader("bad crypto"), &armored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer.Fingerprint != priv.Fingerprint {
+		t.Fatal("signature verified with the wrong key")
+	}
+}
+
+func TestSignatureRoundTrip(t *testing.T) {
+	t.Parallel()
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := NewPrivateKey(time.Unix(1600000000, 0), edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := DetachSign(priv, strings.NewReader("bad crypto"), crypto.SHA512, time.Unix(1600000001, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := sig.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	p, err := ReadPacket(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig2 := p.(*Signature)
+	if sig2.IssuerKeyID != priv.KeyID || sig2.Hash != crypto.SHA512 || sig2.CreationTime.Unix() != 1600000001 {
+		t.Fatalf("parsed signature does not match: %+v", sig2)
+	}
+	if err := priv.VerifySignature(strings.NewReader("bad crypto"), sig2); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLiteralAndEncryptedPackets(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	ld := &LiteralData{IsBinary: true, FileName: "bad.txt", Time: time.Unix(1600000000, 0), Body: []byte("bad crypto")}
+	if err := ld.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	se := &SymmetricallyEncrypted{MDC: true, Ciphertext: bytes.Repeat([]byte{0xab}, 300)}
+	if err := se.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	packets, err := ReadPackets(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packets) != 2 {
+		t.Fatalf("expected 2 packets but got %d", len(packets))
+	}
+	ld2 := packets[0].(*LiteralData)
+	if ld2.FileName != "bad.txt" || !bytes.Equal(ld2.Body, ld.Body) || !ld2.Time.Equal(ld.Time) {
+		t.Fatalf("literal data mismatch: %+v", ld2)
+	}
+	se2 := packets[1].(*SymmetricallyEncrypted)
+	if !se2.MDC || se2.Version != 1 || !bytes.Equal(se2.Ciphertext, se.Ciphertext) {
+		t.Fatalf("encrypted data mismatch: %+v", se2)
+	}
+}
+
+func TestPartialBodyLengths(t *testing.T) {
+	t.Parallel()
+	// a literal data packet split in a 512 bytes partial chunk followed by
+	// a final 7 bytes chunk, as produced by streaming implementations
+	body := append([]byte{'b', 0, 0, 0, 0, 0}, bytes.Repeat([]byte{'x'}, 513)...)
+	raw := []byte{0xC0 | byte(TagLiteralData), 224 + 9}
+	raw = append(raw, body[:512]...)
+	raw = append(raw, byte(len(body)-512))
+	raw = append(raw, body[512:]...)
+	p, err := ReadPacket(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.(*LiteralData).Body) != 513 {
+		t.Fatalf("expected a 513 bytes body but got %d", len(p.(*LiteralData).Body))
+	}
+}
@@ -0,0 +1,176 @@
+// Package oprf implements the base mode of the oblivious pseudorandom
+// function of RFC 9497. A client blinds its input, the server evaluates
+// the blinded element with its secret key, and the client unblinds the
+// result: the client learns F(key, input) without learning the key, and
+// the server learns nothing about the input.
+//
+// A suite runs over any prime order group of the group package. The
+// P256-SHA256 and ristretto255-SHA512 suites of the RFC are predefined.
+package oprf
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/group"
+)
+
+const modeOPRF = 0x00
+
+// Suite is an OPRF ciphersuite
+type Suite struct {
+	group   group.Group
+	context []byte
+}
+
+// P256SHA256 is the P256-SHA256 suite of RFC 9497 section 4.3
+var P256SHA256 = NewSuite(group.P256SHA256)
+
+// Ristretto255SHA512 is the ristretto255-SHA512 suite of RFC 9497
+// section 4.1
+var Ristretto255SHA512 = NewSuite(group.Ristretto255SHA512)
+
+// NewSuite returns the suite over g, whose name and hash make the
+// identifier of the suite
+func NewSuite(g group.Group) *Suite {
+	context := append([]byte("OPRFV1-"), modeOPRF)
+	context = append(context, '-')
+	context = append(context, g.Name()...)
+	return &Suite{group: g, context: context}
+}
+
+// Group returns the group of the suite
+func (s *Suite) Group() group.Group {
+	return s.group
+}
+
+// PrivateKey is the server key
+type PrivateKey struct {
+	suite *Suite
+	k     *big.Int
+	// Public is the serialized public key k*G
+	Public []byte
+}
+
+// Bytes returns the serialized secret scalar
+func (k *PrivateKey) Bytes() []byte {
+	return k.suite.group.MarshalScalar(k.k)
+}
+
+func (s *Suite) newPrivateKey(k *big.Int) *PrivateKey {
+	g := s.group
+	return &PrivateKey{suite: s, k: k, Public: g.Marshal(g.ScalarBaseMult(k))}
+}
+
+// GenerateKey returns a random server key
+func (s *Suite) GenerateKey(rand io.Reader) (*PrivateKey, error) {
+	k, err := s.group.RandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	return s.newPrivateKey(k), nil
+}
+
+// DeriveKeyPair derives a server key from a seed and some info, as in
+// RFC 9497 section 3.2.1
+func (s *Suite) DeriveKeyPair(seed, info []byte) (*PrivateKey, error) {
+	if len(info) > 0xffff {
+		return nil, errors.New("oprf: info is too long")
+	}
+	input := append(append([]byte{}, seed...), byte(len(info)>>8), byte(len(info)))
+	input = append(input, info...)
+	dst := append([]byte("DeriveKeyPair"), s.context...)
+	for counter := 0; counter < 256; counter++ {
+		k, err := s.group.HashToScalar(append(input, byte(counter)), dst)
+		if err != nil {
+			return nil, err
+		}
+		if k.Sign() != 0 {
+			return s.newPrivateKey(k), nil
+		}
+	}
+	return nil, errors.New("oprf: failed to derive a key pair")
+}
+
+// hashToGroup hashes the input to an element, rejecting the identity
+func (s *Suite) hashToGroup(input []byte) (group.Element, error) {
+	g := s.group
+	p, err := g.HashToGroup(input, append([]byte("HashToGroup-"), s.context...))
+	if err != nil {
+		return nil, err
+	}
+	if g.Equal(p, g.Identity()) {
+		return nil, errors.New("oprf: input hashes to the identity")
+	}
+	return p, nil
+}
+
+// Blind is the client state between Blind and Finalize
+type Blind struct {
+	r *big.Int
+}
+
+// Blind hashes the input to the group and multiplies it by a random
+// scalar, and returns the state and the blinded element to send
+func (s *Suite) Blind(rand io.Reader, input []byte) (*Blind, []byte, error) {
+	r, err := s.group.RandomScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.blind(r, input)
+}
+
+func (s *Suite) blind(r *big.Int, input []byte) (*Blind, []byte, error) {
+	p, err := s.hashToGroup(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &Blind{r: r}, s.group.Marshal(s.group.ScalarMult(p, r)), nil
+}
+
+// BlindEvaluate multiplies a blinded element by the server key
+func (k *PrivateKey) BlindEvaluate(blinded []byte) ([]byte, error) {
+	g := k.suite.group
+	p, err := g.Unmarshal(blinded)
+	if err != nil {
+		return nil, err
+	}
+	return g.Marshal(g.ScalarMult(p, k.k)), nil
+}
+
+// Finalize unblinds the evaluated element and hashes it with the input
+// into the PRF output
+func (s *Suite) Finalize(input []byte, blind *Blind, evaluated []byte) ([]byte, error) {
+	g := s.group
+	p, err := g.Unmarshal(evaluated)
+	if err != nil {
+		return nil, err
+	}
+	rinv := new(big.Int).ModInverse(blind.r, g.Order())
+	return s.finalHash(input, g.Marshal(g.ScalarMult(p, rinv)))
+}
+
+// Evaluate computes the PRF output directly, for a server that knows the
+// input
+func (k *PrivateKey) Evaluate(input []byte) ([]byte, error) {
+	s := k.suite
+	p, err := s.hashToGroup(input)
+	if err != nil {
+		return nil, err
+	}
+	return s.finalHash(input, s.group.Marshal(s.group.ScalarMult(p, k.k)))
+}
+
+func (s *Suite) finalHash(input, element []byte) ([]byte, error) {
+	if len(input) > 0xffff {
+		return nil, errors.New("oprf: input is too long")
+	}
+	h := s.group.Hash()()
+	h.Write([]byte{byte(len(input) >> 8), byte(len(input))})
+	h.Write(input)
+	h.Write([]byte{byte(len(element) >> 8), byte(len(element))})
+	h.Write(element)
+	h.Write([]byte("Finalize"))
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,134 @@
+package oprf
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+)
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// RFC 9497 appendices A.1.1 and A.3.1, OPRF mode of ristretto255-SHA512
+// and P256-SHA256
+func TestVectors(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		suite                                    *Suite
+		skSm                                     string
+		input, blind, blinded, evaluated, output string
+	}{
+		{
+			Ristretto255SHA512,
+			"5ebcea5ee37023ccb9fc2d2019f9d7737be85591ae8652ffa9ef0f4d37063b0e",
+			"00",
+			"64d37aed22a27f5191de1c1d69fadb899d8862b58eb4220029e036ec4c1f6706",
+			"609a0ae68c15a3cf6903766461307e5c8bb2f95e7e6550e1ffa2dc99e412803c",
+			"7ec6578ae5120958eb2db1745758ff379e77cb64fe77b0b2d8cc917ea0869c7e",
+			"527759c3d9366f277d8c6020418d96bb393ba2afb20ff90df23fb7708264e2f3ab9135e3bd69955851de4b1f9fe8a0973396719b7912ba9ee8aa7d0b5e24bcf6",
+		},
+		{
+			Ristretto255SHA512,
+			"5ebcea5ee37023ccb9fc2d2019f9d7737be85591ae8652ffa9ef0f4d37063b0e",
+			"5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a",
+			"64d37aed22a27f5191de1c1d69fadb899d8862b58eb4220029e036ec4c1f6706",
+			"da27ef466870f5f15296299850aa088629945a17d1f5b7f5ff043f76b3c06418",
+			"b4cbf5a4f1eeda5a63ce7b77c7d23f461db3fcab0dd28e4e17cecb5c90d02c25",
+			"f4a74c9c592497375e796aa837e907b1a045d34306a749db9f34221f7e750cb4f2a6413a6bf6fa5e19ba6348eb673934a722a7ede2e7621306d18951e7cf2c73",
+		},
+		{
+			P256SHA256,
+			"159749d750713afe245d2d39ccfaae8381c53ce92d098a9375ee70739c7ac0bf",
+			"00",
+			"3338fa65ec36e0290022b48eb562889d89dbfa691d1cde91517fa222ed7ad364",
+			"03723a1e5c09b8b9c18d1dcbca29e8007e95f14f4732d9346d490ffc195110368d",
+			"030de02ffec47a1fd53efcdd1c6faf5bdc270912b8749e783c7ca75bb412958832",
+			"a0b34de5fa4c5b6da07e72af73cc507cceeb48981b97b7285fc375345fe495dd",
+		},
+	}
+	for i, tc := range testcases {
+		key, err := tc.suite.DeriveKeyPair(bytes.Repeat([]byte{0xa3}, 32), []byte("test key"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(key.Bytes()); got != tc.skSm {
+			t.Fatalf("testcase %d expected skSm %s but got %s", i, tc.skSm, got)
+		}
+		r, err := tc.suite.Group().UnmarshalScalar(unhex(tc.blind))
+		if err != nil {
+			t.Fatal(err)
+		}
+		input := unhex(tc.input)
+		blind, blinded, err := tc.suite.blind(r, input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(blinded); got != tc.blinded {
+			t.Fatalf("testcase %d expected blinded element %s but got %s", i, tc.blinded, got)
+		}
+		evaluated, err := key.BlindEvaluate(blinded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(evaluated); got != tc.evaluated {
+			t.Fatalf("testcase %d expected evaluated element %s but got %s", i, tc.evaluated, got)
+		}
+		output, err := tc.suite.Finalize(input, blind, evaluated)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(output); got != tc.output {
+			t.Fatalf("testcase %d expected output %s but got %s", i, tc.output, got)
+		}
+	}
+}
+
+func TestProtocol(t *testing.T) {
+	t.Parallel()
+	for _, suite := range []*Suite{P256SHA256, Ristretto255SHA512} {
+		key, err := suite.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		input := []byte("alice@example.com")
+		blind, blinded, err := suite.Blind(rand.Reader, input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		evaluated, err := key.BlindEvaluate(blinded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		output, err := suite.Finalize(input, blind, evaluated)
+		if err != nil {
+			t.Fatal(err)
+		}
+		direct, err := key.Evaluate(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(output, direct) {
+			t.Fatal("expected the oblivious and direct evaluations to match")
+		}
+		_, blinded2, _ := suite.Blind(rand.Reader, input)
+		if bytes.Equal(blinded, blinded2) {
+			t.Fatal("expected two blindings of the same input to differ")
+		}
+		other, _ := suite.GenerateKey(rand.Reader)
+		evaluated, _ = other.BlindEvaluate(blinded)
+		output, _ = suite.Finalize(input, blind, evaluated)
+		if bytes.Equal(output, direct) {
+			t.Fatal("expected another key to give another output")
+		}
+		g := suite.Group()
+		if _, err := key.BlindEvaluate(g.Marshal(g.Identity())); err == nil {
+			t.Fatal("expected the identity to be rejected")
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package ot
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+// Kappa is the number of base OTs, the computational security parameter
+// of the extension
+const Kappa = 128
+
+// ExtensionReceiver is the receiver of extended OTs. It acts as the
+// sender of the base OTs.
+type ExtensionReceiver struct {
+	base  *Sender
+	seeds [][2][]byte
+	batch uint64
+}
+
+// ExtensionSender is the sender of extended OTs. It acts as the receiver
+// of the base OTs with a random secret s.
+type ExtensionSender struct {
+	s     []bool
+	seeds [][]byte
+	batch uint64
+}
+
+// NewExtensionReceiver starts the setup, and returns the base OT sender
+// message A for the extension sender
+func NewExtensionReceiver(rand io.Reader, c *ec.Curve) (*ExtensionReceiver, []byte, error) {
+	base, err := NewSender(rand, c)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &ExtensionReceiver{base: base}, base.A, nil
+}
+
+// NewExtensionSender answers A with its Kappa base OT choices, and returns
+// the messages B for the extension receiver
+func NewExtensionSender(rand io.Reader, c *ec.Curve, A []byte) (*ExtensionSender, [][]byte, error) {
+	bits := make([]byte, Kappa/8)
+	if _, err := io.ReadFull(rand, bits); err != nil {
+		return nil, nil, err
+	}
+	s := make([]bool, Kappa)
+	for i := range s {
+		s[i] = getBit(bits, i)
+	}
+	B, seeds, err := Choose(rand, c, A, s)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &ExtensionSender{s: s, seeds: seeds}, B, nil
+}
+
+// Setup completes the base OTs with the messages of the extension sender
+func (r *ExtensionReceiver) Setup(B [][]byte) error {
+	if len(B) != Kappa {
+		return fmt.Errorf("ot: expected %d base OT messages", Kappa)
+	}
+	seeds, err := r.base.Keys(B)
+	if err != nil {
+		return err
+	}
+	r.seeds = seeds
+	return nil
+}
+
+func getBit(b []byte, i int) bool {
+	return b[i/8]>>(uint(i)%8)&1 == 1
+}
+
+func setBit(b []byte, i int) {
+	b[i/8] |= 1 << (uint(i) % 8)
+}
+
+// column expands a base OT seed into m bits for the given batch, so each
+// batch uses fresh columns
+func column(seed []byte, batch uint64, m int) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], batch)
+	return prg(append(append([]byte{}, seed...), b[:]...), "badcrypto iknp column", (m+7)/8)
+}
+
+// rowKey hashes row i of the matrix into an OT key. The hash breaks the
+// correlation between the two keys of the sender, which differ by s.
+func rowKey(batch uint64, i int, row []byte) []byte {
+	h := sha256.New()
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], batch)
+	binary.BigEndian.PutUint64(b[8:], uint64(i))
+	h.Write([]byte("badcrypto iknp row"))
+	h.Write(b[:])
+	h.Write(row)
+	return h.Sum(nil)
+}
+
+// transpose turns Kappa columns of m bits into m rows of Kappa bits
+func transpose(cols [][]byte, m int) [][]byte {
+	rows := make([][]byte, m)
+	for i := range rows {
+		rows[i] = make([]byte, Kappa/8)
+		for j := 0; j < Kappa; j++ {
+			if getBit(cols[j], i) {
+				setBit(rows[i], j)
+			}
+		}
+	}
+	return rows
+}
+
+// Extend produces len(choices) OTs. It returns the matrix U to send to
+// the extension sender, and the key of each chosen side.
+func (r *ExtensionReceiver) Extend(choices []bool) (U [][]byte, keys [][]byte, err error) {
+	if r.seeds == nil {
+		return nil, nil, errors.New("ot: extension receiver is not set up")
+	}
+	m := len(choices)
+	rbits := make([]byte, (m+7)/8)
+	for i, c := range choices {
+		if c {
+			setBit(rbits, i)
+		}
+	}
+	// column j of T is G(k_j^0), and u_j = t_j ^ G(k_j^1) ^ r
+	t := make([][]byte, Kappa)
+	U = make([][]byte, Kappa)
+	for j := 0; j < Kappa; j++ {
+		t[j] = column(r.seeds[j][0], r.batch, m)
+		U[j] = xorBytes(xorBytes(t[j], column(r.seeds[j][1], r.batch, m)), rbits)
+	}
+	rows := transpose(t, m)
+	keys = make([][]byte, m)
+	for i := range rows {
+		keys[i] = rowKey(r.batch, i, rows[i])
+	}
+	r.batch++
+	return U, keys, nil
+}
+
+// Extend answers the matrix U of the receiver for m OTs, and returns
+// the key pairs of the sender
+func (s *ExtensionSender) Extend(U [][]byte, m int) ([][2][]byte, error) {
+	if len(U) != Kappa {
+		return nil, fmt.Errorf("ot: expected %d columns", Kappa)
+	}
+	// column j of Q is G(k_j^s_j) ^ s_j*u_j = t_j ^ s_j*r, so row i is
+	// t_i ^ r_i*s
+	q := make([][]byte, Kappa)
+	for j := 0; j < Kappa; j++ {
+		if len(U[j]) != (m+7)/8 {
+			return nil, errors.New("ot: column length does not match")
+		}
+		q[j] = column(s.seeds[j], s.batch, m)
+		if s.s[j] {
+			q[j] = xorBytes(q[j], U[j])
+		}
+	}
+	sbits := make([]byte, Kappa/8)
+	for j, b := range s.s {
+		if b {
+			setBit(sbits, j)
+		}
+	}
+	rows := transpose(q, m)
+	keys := make([][2][]byte, m)
+	for i := range rows {
+		keys[i][0] = rowKey(s.batch, i, rows[i])
+		keys[i][1] = rowKey(s.batch, i, xorBytes(rows[i], sbits))
+	}
+	s.batch++
+	return keys, nil
+}
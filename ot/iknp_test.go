@@ -0,0 +1,59 @@
+package ot
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+func TestExtension(t *testing.T) {
+	t.Parallel()
+	c := ec.P256()
+	receiver, A, err := NewExtensionReceiver(rand.Reader, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender, B, err := NewExtensionSender(rand.Reader, c, A)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := receiver.Setup(B); err != nil {
+		t.Fatal(err)
+	}
+	// two batches, to check that the columns are not reused
+	var previous [][]byte
+	for batch, m := range []int{1000, 37} {
+		choices := randomChoices(m)
+		U, rkeys, err := receiver.Extend(choices)
+		if err != nil {
+			t.Fatal(err)
+		}
+		skeys, err := sender.Extend(U, m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, choice := range choices {
+			chosen, other := 0, 1
+			if choice {
+				chosen, other = 1, 0
+			}
+			if !bytes.Equal(rkeys[i], skeys[i][chosen]) || bytes.Equal(rkeys[i], skeys[i][other]) {
+				t.Fatalf("batch %d testcase %d expected the receiver to hold exactly the chosen key", batch, i)
+			}
+		}
+		if previous != nil && bytes.Equal(previous[0][:len(U[0])], U[0]) {
+			t.Fatalf("batch %d expected fresh columns", batch)
+		}
+		previous = U
+	}
+}
+
+func TestExtensionNotSetUp(t *testing.T) {
+	t.Parallel()
+	receiver, _, _ := NewExtensionReceiver(rand.Reader, ec.P256())
+	if _, _, err := receiver.Extend([]bool{true}); err == nil {
+		t.Fatal("expected an extension before setup to fail")
+	}
+}
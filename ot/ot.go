@@ -0,0 +1,161 @@
+// Package ot implements 1-out-of-2 oblivious transfer: a sender holds two
+// messages, a receiver learns the one it chooses, the sender does not
+// learn which, and the receiver learns nothing about the other.
+//
+// Base OTs use the "simplest OT" of Chou and Orlandi over the curves of
+// the ec package, and are expensive public key operations. The IKNP
+// extension of Ishai, Kilian, Nissim and Petrank turns Kappa base OTs into
+// any number of OTs using only hashing. Both are secure against semi
+// honest adversaries only.
+//
+// The protocols produce random OT keys, one pair per transfer for the
+// sender and one key for the receiver; Transfer and Receive then use them
+// to send actual messages.
+package ot
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+// KeySize is the size of OT keys
+const KeySize = 32
+
+// Sender runs the sender side of a batch of base OTs
+type Sender struct {
+	curve *ec.Curve
+	a     *big.Int
+	// A is the public message of the sender
+	A []byte
+}
+
+// NewSender picks the sender secret, and returns the sender along with
+// the message A to send to the receiver
+func NewSender(rand io.Reader, c *ec.Curve) (*Sender, error) {
+	a, err := c.RandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	return &Sender{curve: c, a: a, A: c.Marshal(c.ScalarBaseMult(a))}, nil
+}
+
+func hashKey(c *ec.Curve, index int, a, b []byte, p *ec.Point) []byte {
+	h := sha256.New()
+	var i [8]byte
+	binary.BigEndian.PutUint64(i[:], uint64(index))
+	h.Write([]byte("badcrypto simplest ot"))
+	h.Write(i[:])
+	h.Write(a)
+	h.Write(b)
+	h.Write(c.Marshal(p))
+	return h.Sum(nil)
+}
+
+// Choose runs the receiver side of len(choices) base OTs against the
+// sender message A. It returns the messages B to send back and the key
+// of each chosen side.
+func Choose(rand io.Reader, c *ec.Curve, A []byte, choices []bool) (B [][]byte, keys [][]byte, err error) {
+	pa, err := c.Unmarshal(A)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pa.IsInfinity() {
+		return nil, nil, errors.New("ot: sender message is the identity")
+	}
+	B = make([][]byte, len(choices))
+	keys = make([][]byte, len(choices))
+	for i, choice := range choices {
+		b, err := c.RandomScalar(rand)
+		if err != nil {
+			return nil, nil, err
+		}
+		// B = b*G for 0, A + b*G for 1, so the sender cannot tell
+		pb := c.ScalarBaseMult(b)
+		if choice {
+			pb = c.Add(pa, pb)
+		}
+		B[i] = c.Marshal(pb)
+		keys[i] = hashKey(c, i, A, B[i], c.ScalarMult(pa, b))
+	}
+	return B, keys, nil
+}
+
+// Keys returns the key pairs of the sender for the receiver messages B:
+// the receiver knows exactly one key of each pair
+func (s *Sender) Keys(B [][]byte) ([][2][]byte, error) {
+	c := s.curve
+	pa, _ := c.Unmarshal(s.A)
+	keys := make([][2][]byte, len(B))
+	for i, b := range B {
+		pb, err := c.Unmarshal(b)
+		if err != nil {
+			return nil, err
+		}
+		keys[i][0] = hashKey(c, i, s.A, b, c.ScalarMult(pb, s.a))
+		keys[i][1] = hashKey(c, i, s.A, b, c.ScalarMult(c.Sub(pb, pa), s.a))
+	}
+	return keys, nil
+}
+
+// prg expands a key into n pseudorandom bytes with SHA-256 in counter
+// mode
+func prg(key []byte, label string, n int) []byte {
+	out := make([]byte, 0, n+sha256.Size)
+	for ctr := uint32(0); len(out) < n; ctr++ {
+		h := sha256.New()
+		h.Write([]byte(label))
+		h.Write(key)
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], ctr)
+		h.Write(b[:])
+		out = h.Sum(out)
+	}
+	return out[:n]
+}
+
+func xorBytes(a, b []byte) []byte {
+	out := make([]byte, len(a))
+	for i := range a {
+		out[i] = a[i] ^ b[i]
+	}
+	return out
+}
+
+// Transfer encrypts each pair of messages under the matching pair of
+// sender keys. Both messages of a pair must have the same length.
+func Transfer(keys [][2][]byte, messages [][2][]byte) ([][2][]byte, error) {
+	if len(keys) != len(messages) {
+		return nil, errors.New("ot: not as many keys as messages")
+	}
+	out := make([][2][]byte, len(messages))
+	for i, m := range messages {
+		if len(m[0]) != len(m[1]) {
+			return nil, errors.New("ot: messages of a pair must have the same length")
+		}
+		for j := 0; j < 2; j++ {
+			out[i][j] = xorBytes(m[j], prg(keys[i][j], "badcrypto ot transfer", len(m[j])))
+		}
+	}
+	return out, nil
+}
+
+// Receive decrypts the chosen message of each pair
+func Receive(keys [][]byte, choices []bool, ciphertexts [][2][]byte) ([][]byte, error) {
+	if len(keys) != len(ciphertexts) || len(choices) != len(ciphertexts) {
+		return nil, errors.New("ot: not as many keys as ciphertexts")
+	}
+	out := make([][]byte, len(ciphertexts))
+	for i, ct := range ciphertexts {
+		c := ct[0]
+		if choices[i] {
+			c = ct[1]
+		}
+		out[i] = xorBytes(c, prg(keys[i], "badcrypto ot transfer", len(c)))
+	}
+	return out, nil
+}
@@ -0,0 +1,88 @@
+package ot
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+func randomChoices(n int) []bool {
+	b := make([]byte, n)
+	rand.Read(b)
+	choices := make([]bool, n)
+	for i := range choices {
+		choices[i] = b[i]&1 == 1
+	}
+	return choices
+}
+
+func TestBaseOT(t *testing.T) {
+	t.Parallel()
+	for _, c := range []*ec.Curve{ec.P256(), ec.Secp256k1()} {
+		sender, err := NewSender(rand.Reader, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		choices := randomChoices(16)
+		B, rkeys, err := Choose(rand.Reader, c, sender.A, choices)
+		if err != nil {
+			t.Fatal(err)
+		}
+		skeys, err := sender.Keys(B)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, choice := range choices {
+			chosen, other := 0, 1
+			if choice {
+				chosen, other = 1, 0
+			}
+			if !bytes.Equal(rkeys[i], skeys[i][chosen]) || bytes.Equal(rkeys[i], skeys[i][other]) {
+				t.Fatalf("%s testcase %d expected the receiver to hold exactly the chosen key", c.Name, i)
+			}
+			if len(rkeys[i]) != KeySize {
+				t.Fatalf("%s testcase %d expected a key of %d bytes", c.Name, i, KeySize)
+			}
+		}
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	t.Parallel()
+	c := ec.P256()
+	sender, _ := NewSender(rand.Reader, c)
+	choices := []bool{false, true, true, false}
+	B, rkeys, _ := Choose(rand.Reader, c, sender.A, choices)
+	skeys, _ := sender.Keys(B)
+	messages := make([][2][]byte, len(choices))
+	for i := range messages {
+		messages[i] = [2][]byte{[]byte(fmt.Sprintf("zero %d", i)), []byte(fmt.Sprintf("one  %d", i))}
+	}
+	cts, err := Transfer(skeys, messages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Receive(rkeys, choices, cts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, choice := range choices {
+		expected := messages[i][0]
+		if choice {
+			expected = messages[i][1]
+		}
+		if !bytes.Equal(got[i], expected) {
+			t.Fatalf("testcase %d expected %q but got %q", i, expected, got[i])
+		}
+	}
+	// flipping the choice after the fact yields garbage
+	flipped, _ := Receive(rkeys, []bool{true, false, false, true}, cts)
+	for i := range flipped {
+		if bytes.Equal(flipped[i], messages[i][0]) || bytes.Equal(flipped[i], messages[i][1]) {
+			t.Fatalf("testcase %d expected the other message to stay hidden", i)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+// Package otp implements the HMAC-based one-time passwords of RFC 4226
+// (HOTP) and their time-based variant of RFC 6238 (TOTP), along with the
+// otpauth:// URIs authenticator apps import keys from.
+//
+// A code is the HMAC of a moving factor, a counter for HOTP or the number
+// of periods elapsed since the epoch for TOTP, dynamically truncated to 31
+// bits and reduced to a number of decimal digits.
+package otp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Algorithm is the hash function of the HMAC
+type Algorithm int
+
+// Algorithms allowed by RFC 6238
+const (
+	SHA1 Algorithm = iota
+	SHA256
+	SHA512
+)
+
+func (a Algorithm) String() string {
+	switch a {
+	case SHA1:
+		return "SHA1"
+	case SHA256:
+		return "SHA256"
+	case SHA512:
+		return "SHA512"
+	}
+	return fmt.Sprintf("Algorithm(%d)", int(a))
+}
+
+func (a Algorithm) hash() (func() hash.Hash, error) {
+	switch a {
+	case SHA1:
+		return sha1.New, nil
+	case SHA256:
+		return sha256.New, nil
+	case SHA512:
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("otp: unknown algorithm %d", int(a))
+}
+
+// Type is the kind of one-time password a key produces
+type Type string
+
+// Key types as written in otpauth URIs
+const (
+	TypeHOTP Type = "hotp"
+	TypeTOTP Type = "totp"
+)
+
+// Defaults used by GenerateKey and for parameters missing from URIs
+const (
+	DefaultDigits     = 6
+	DefaultPeriod     = 30
+	DefaultSecretSize = 20
+)
+
+var (
+	powers = []uint32{1, 10, 100, 1000, 10000, 100000, 1000000, 10000000, 100000000, 1000000000}
+	b32    = base32.StdEncoding.WithPadding(base32.NoPadding)
+)
+
+// HOTP returns the code of the given number of digits, from 1 to 9, for
+// counter
+func HOTP(secret []byte, counter uint64, digits int, alg Algorithm) (string, error) {
+	if digits < 1 || digits > 9 {
+		return "", fmt.Errorf("otp: invalid number of digits %d", digits)
+	}
+	h, err := alg.hash()
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(h, secret)
+	var c [8]byte
+	binary.BigEndian.PutUint64(c[:], counter)
+	mac.Write(c[:])
+	sum := mac.Sum(nil)
+	// dynamic truncation of RFC 4226 section 5.3
+	offset := sum[len(sum)-1] & 0xf
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", digits, code%powers[digits]), nil
+}
+
+// Key holds the parameters of a one-time password generator
+type Key struct {
+	Type      Type
+	Issuer    string
+	Account   string
+	Secret    []byte
+	Algorithm Algorithm
+	Digits    int
+	// Period is the time step of TOTP keys in seconds
+	Period uint64
+	// Counter is the next counter of HOTP keys
+	Counter uint64
+}
+
+// GenerateKey returns a new TOTP key with a random secret and the default
+// parameters, which every authenticator app supports
+func GenerateKey(rand io.Reader, issuer, account string) (*Key, error) {
+	k := &Key{
+		Type:      TypeTOTP,
+		Issuer:    issuer,
+		Account:   account,
+		Secret:    make([]byte, DefaultSecretSize),
+		Algorithm: SHA1,
+		Digits:    DefaultDigits,
+		Period:    DefaultPeriod,
+	}
+	if _, err := io.ReadFull(rand, k.Secret); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// step returns the TOTP time step containing t
+func (k *Key) step(t time.Time) uint64 {
+	period := k.Period
+	if period == 0 {
+		period = DefaultPeriod
+	}
+	return uint64(t.Unix()) / period
+}
+
+// TOTP returns the code of a TOTP key at time t
+func (k *Key) TOTP(t time.Time) (string, error) {
+	return HOTP(k.Secret, k.step(t), k.Digits, k.Algorithm)
+}
+
+// ValidateTOTP checks code against the time steps within skew steps of t,
+// to accept clocks that drift and codes typed at the end of a period. It
+// returns the matching time step, which callers should store to refuse a
+// second use of the same code.
+func (k *Key) ValidateTOTP(code string, t time.Time, skew uint64) (uint64, bool) {
+	now := k.step(t)
+	start := uint64(0)
+	if now > skew {
+		start = now - skew
+	}
+	for s := start; s <= now+skew; s++ {
+		if k.match(code, s) {
+			return s, true
+		}
+	}
+	return 0, false
+}
+
+// ValidateHOTP checks code against the counters from Counter to Counter
+// plus lookahead, and on success moves Counter past the matching one to
+// resynchronize with the token
+func (k *Key) ValidateHOTP(code string, lookahead uint64) bool {
+	for c := k.Counter; c <= k.Counter+lookahead; c++ {
+		if k.match(code, c) {
+			k.Counter = c + 1
+			return true
+		}
+	}
+	return false
+}
+
+func (k *Key) match(code string, counter uint64) bool {
+	expected, err := HOTP(k.Secret, counter, k.Digits, k.Algorithm)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1
+}
+
+// URI returns the otpauth:// URI of the key, usually shown as a QR code
+func (k *Key) URI() string {
+	label := url.PathEscape(k.Account)
+	if k.Issuer != "" {
+		label = url.PathEscape(k.Issuer) + ":" + label
+	}
+	v := url.Values{}
+	v.Set("secret", b32.EncodeToString(k.Secret))
+	if k.Issuer != "" {
+		v.Set("issuer", k.Issuer)
+	}
+	v.Set("algorithm", k.Algorithm.String())
+	v.Set("digits", strconv.Itoa(k.Digits))
+	if k.Type == TypeHOTP {
+		v.Set("counter", strconv.FormatUint(k.Counter, 10))
+	} else {
+		v.Set("period", strconv.FormatUint(k.Period, 10))
+	}
+	return "otpauth://" + string(k.Type) + "/" + label + "?" + v.Encode()
+}
+
+// ParseURI decodes an otpauth:// URI
+func ParseURI(uri string) (*Key, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "otpauth" {
+		return nil, errors.New("otp: not an otpauth URI")
+	}
+	k := &Key{Type: Type(strings.ToLower(u.Host)), Digits: DefaultDigits, Period: DefaultPeriod}
+	if k.Type != TypeHOTP && k.Type != TypeTOTP {
+		return nil, fmt.Errorf("otp: unknown key type %q", u.Host)
+	}
+	label := strings.TrimPrefix(u.Path, "/")
+	if i := strings.Index(label, ":"); i >= 0 {
+		k.Issuer, k.Account = label[:i], strings.TrimLeft(label[i+1:], " ")
+	} else {
+		k.Account = label
+	}
+	q := u.Query()
+	if issuer := q.Get("issuer"); issuer != "" {
+		if k.Issuer != "" && k.Issuer != issuer {
+			return nil, errors.New("otp: issuer parameter does not match the label")
+		}
+		k.Issuer = issuer
+	}
+	secret := strings.ToUpper(strings.TrimRight(q.Get("secret"), "="))
+	if k.Secret, err = b32.DecodeString(secret); err != nil || len(k.Secret) == 0 {
+		return nil, errors.New("otp: invalid secret")
+	}
+	switch strings.ToUpper(q.Get("algorithm")) {
+	case "", "SHA1":
+		k.Algorithm = SHA1
+	case "SHA256":
+		k.Algorithm = SHA256
+	case "SHA512":
+		k.Algorithm = SHA512
+	default:
+		return nil, fmt.Errorf("otp: unknown algorithm %q", q.Get("algorithm"))
+	}
+	if d := q.Get("digits"); d != "" {
+		if k.Digits, err = strconv.Atoi(d); err != nil || k.Digits < 1 || k.Digits > 9 {
+			return nil, fmt.Errorf("otp: invalid digits %q", d)
+		}
+	}
+	if p := q.Get("period"); p != "" {
+		if k.Period, err = strconv.ParseUint(p, 10, 64); err != nil || k.Period == 0 {
+			return nil, fmt.Errorf("otp: invalid period %q", p)
+		}
+	}
+	if k.Type == TypeHOTP {
+		c := q.Get("counter")
+		if c == "" {
+			return nil, errors.New("otp: hotp URI without a counter")
+		}
+		if k.Counter, err = strconv.ParseUint(c, 10, 64); err != nil {
+			return nil, fmt.Errorf("otp: invalid counter %q", c)
+		}
+	}
+	return k, nil
+}
@@ -0,0 +1,184 @@
+package otp
+
+import (
+	"crypto/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHOTP(t *testing.T) {
+	t.Parallel()
+	// RFC 4226 appendix D
+	secret := []byte("12345678901234567890")
+	var testcases = []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for i, expected := range testcases {
+		code, err := HOTP(secret, uint64(i), 6, SHA1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != expected {
+			t.Fatalf("testcase %d expected %s but got %s", i, expected, code)
+		}
+	}
+	if _, err := HOTP(secret, 0, 10, SHA1); err == nil {
+		t.Fatal("expected 10 digits to be rejected")
+	}
+}
+
+func TestTOTP(t *testing.T) {
+	t.Parallel()
+	// RFC 6238 appendix B
+	secrets := map[Algorithm][]byte{
+		SHA1:   []byte("12345678901234567890"),
+		SHA256: []byte("12345678901234567890123456789012"),
+		SHA512: []byte("1234567890123456789012345678901234567890123456789012345678901234"),
+	}
+	var testcases = []struct {
+		time     int64
+		alg      Algorithm
+		expected string
+	}{
+		{59, SHA1, "94287082"},
+		{59, SHA256, "46119246"},
+		{59, SHA512, "90693936"},
+		{1111111109, SHA1, "07081804"},
+		{1111111109, SHA256, "68084774"},
+		{1111111109, SHA512, "25091201"},
+		{1111111111, SHA1, "14050471"},
+		{1111111111, SHA256, "67062674"},
+		{1111111111, SHA512, "99943326"},
+		{1234567890, SHA1, "89005924"},
+		{1234567890, SHA256, "91819424"},
+		{1234567890, SHA512, "93441116"},
+		{2000000000, SHA1, "69279037"},
+		{2000000000, SHA256, "90698825"},
+		{2000000000, SHA512, "38618901"},
+		{20000000000, SHA1, "65353130"},
+		{20000000000, SHA256, "77737706"},
+		{20000000000, SHA512, "47863826"},
+	}
+	for i, tc := range testcases {
+		k := &Key{Type: TypeTOTP, Secret: secrets[tc.alg], Algorithm: tc.alg, Digits: 8, Period: 30}
+		code, err := k.TOTP(time.Unix(tc.time, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != tc.expected {
+			t.Fatalf("testcase %d expected %s but got %s", i, tc.expected, code)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	t.Parallel()
+	k, err := GenerateKey(rand.Reader, "badcrypto", "alice@example.net")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	code, err := k.TOTP(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var testcases = []struct {
+		offset time.Duration
+		skew   uint64
+		valid  bool
+	}{
+		{0, 0, true},
+		{30 * time.Second, 0, false},
+		{30 * time.Second, 1, true},
+		{-30 * time.Second, 1, true},
+		{90 * time.Second, 1, false},
+		{90 * time.Second, 3, true},
+	}
+	for i, tc := range testcases {
+		step, ok := k.ValidateTOTP(code, now.Add(tc.offset), tc.skew)
+		if ok != tc.valid {
+			t.Fatalf("testcase %d expected validity %v but got %v", i, tc.valid, ok)
+		}
+		if ok && step != uint64(now.Unix())/30 {
+			t.Fatalf("testcase %d expected step %d but got %d", i, now.Unix()/30, step)
+		}
+	}
+	if _, ok := k.ValidateTOTP("12345", now, 1); ok {
+		t.Fatal("expected a short code to be rejected")
+	}
+}
+
+func TestValidateHOTP(t *testing.T) {
+	t.Parallel()
+	k := &Key{Type: TypeHOTP, Secret: []byte("12345678901234567890"), Digits: 6}
+	if !k.ValidateHOTP("755224", 0) || k.Counter != 1 {
+		t.Fatalf("expected first code to validate and the counter to move to 1, got %d", k.Counter)
+	}
+	if k.ValidateHOTP("755224", 5) {
+		t.Fatal("expected a used code to be rejected")
+	}
+	// the token was pressed a few times without validating
+	if k.ValidateHOTP("338314", 2) {
+		t.Fatal("expected a code beyond the look ahead window to be rejected")
+	}
+	if !k.ValidateHOTP("338314", 3) || k.Counter != 5 {
+		t.Fatalf("expected resynchronization to counter 5, got %d", k.Counter)
+	}
+}
+
+func TestURI(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		uri string
+		key Key
+	}{
+		{
+			"otpauth://totp/Example:alice@google.com?secret=JBSWY3DPEHPK3PXP&issuer=Example",
+			Key{Type: TypeTOTP, Issuer: "Example", Account: "alice@google.com", Secret: []byte("Hello!\xde\xad\xbe\xef"), Algorithm: SHA1, Digits: 6, Period: 30},
+		},
+		{
+			"otpauth://hotp/ACME%20Co:john%20doe?secret=jbswy3dpehpk3pxp&algorithm=SHA512&digits=8&counter=42",
+			Key{Type: TypeHOTP, Issuer: "ACME Co", Account: "john doe", Secret: []byte("Hello!\xde\xad\xbe\xef"), Algorithm: SHA512, Digits: 8, Period: 30, Counter: 42},
+		},
+		{
+			"otpauth://totp/bob?secret=JBSWY3DPEHPK3PXP&period=60&algorithm=SHA256",
+			Key{Type: TypeTOTP, Account: "bob", Secret: []byte("Hello!\xde\xad\xbe\xef"), Algorithm: SHA256, Digits: 6, Period: 60},
+		},
+	}
+	for i, tc := range testcases {
+		k, err := ParseURI(tc.uri)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if k.Type != tc.key.Type || k.Issuer != tc.key.Issuer || k.Account != tc.key.Account || string(k.Secret) != string(tc.key.Secret) ||
+			k.Algorithm != tc.key.Algorithm || k.Digits != tc.key.Digits || k.Period != tc.key.Period || k.Counter != tc.key.Counter {
+			t.Fatalf("testcase %d expected %+v but got %+v", i, tc.key, k)
+		}
+		// a round trip through URI gives the same key
+		again, err := ParseURI(k.URI())
+		if err != nil {
+			t.Fatalf("testcase %d failed to parse %s with %v", i, k.URI(), err)
+		}
+		if again.URI() != k.URI() {
+			t.Fatalf("testcase %d expected %s but got %s", i, k.URI(), again.URI())
+		}
+	}
+	var invalid = []string{
+		"https://totp/alice?secret=JBSWY3DPEHPK3PXP",
+		"otpauth://motp/alice?secret=JBSWY3DPEHPK3PXP",
+		"otpauth://totp/alice?secret=not+base32!",
+		"otpauth://totp/alice",
+		"otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&algorithm=MD5",
+		"otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP&digits=12",
+		"otpauth://hotp/alice?secret=JBSWY3DPEHPK3PXP",
+		"otpauth://totp/Foo:alice?secret=JBSWY3DPEHPK3PXP&issuer=Bar",
+	}
+	for i, uri := range invalid {
+		if _, err := ParseURI(uri); err == nil {
+			t.Fatalf("invalid testcase %d expected an error but got none", i)
+		}
+	}
+	k, _ := GenerateKey(rand.Reader, "bad crypto", "alice")
+	if !strings.HasPrefix(k.URI(), "otpauth://totp/bad%20crypto:alice?") {
+		t.Fatalf("unexpected URI %s", k.URI())
+	}
+}
@@ -0,0 +1,85 @@
+package otsig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+)
+
+// lamportBits is the number of bits signed, those of a SHA-256 digest
+const lamportBits = 8 * sha256.Size
+
+// LamportSizes returns the sizes of Lamport keys and signatures: 512
+// secrets and hashes, 256 of the secrets revealed
+func LamportSizes() Sizes {
+	return Sizes{
+		PrivateKey: 2 * lamportBits * sha256.Size,
+		PublicKey:  2 * lamportBits * sha256.Size,
+		Signature:  lamportBits * sha256.Size,
+	}
+}
+
+// LamportPrivateKey is a Lamport key, which signs a single message
+type LamportPrivateKey struct {
+	// secrets[2i+b] is revealed when bit i of the digest is b
+	secrets []byte
+	used    bool
+}
+
+// GenerateLamportKey returns a new Lamport key with secrets read from rand
+func GenerateLamportKey(rand io.Reader) (*LamportPrivateKey, error) {
+	secrets := make([]byte, LamportSizes().PrivateKey)
+	if _, err := io.ReadFull(rand, secrets); err != nil {
+		return nil, err
+	}
+	return &LamportPrivateKey{secrets: secrets}, nil
+}
+
+// PublicKey returns the hashes of the secrets
+func (key *LamportPrivateKey) PublicKey() []byte {
+	pub := make([]byte, 0, LamportSizes().PublicKey)
+	for i := 0; i < len(key.secrets); i += sha256.Size {
+		pub = append(pub, hash(key.secrets[i:i+sha256.Size])...)
+	}
+	return pub
+}
+
+// lamportIndex returns the index of the secret that bit i of digest
+// reveals
+func lamportIndex(digest []byte, i int) int {
+	bit := int(digest[i/8] >> uint(7-i%8) & 1)
+	return 2*i + bit
+}
+
+// Sign returns the signature of msg, or ErrKeyUsed if the key has already
+// signed
+func (key *LamportPrivateKey) Sign(msg []byte) ([]byte, error) {
+	if key.used {
+		return nil, ErrKeyUsed
+	}
+	key.used = true
+	digest := hash(msg)
+	sig := make([]byte, 0, LamportSizes().Signature)
+	for i := 0; i < lamportBits; i++ {
+		j := lamportIndex(digest, i) * sha256.Size
+		sig = append(sig, key.secrets[j:j+sha256.Size]...)
+	}
+	return sig, nil
+}
+
+// VerifyLamport tells whether sig is a valid Lamport signature of msg by
+// pub
+func VerifyLamport(pub, msg, sig []byte) bool {
+	sizes := LamportSizes()
+	if len(pub) != sizes.PublicKey || len(sig) != sizes.Signature {
+		return false
+	}
+	digest := hash(msg)
+	for i := 0; i < lamportBits; i++ {
+		j := lamportIndex(digest, i) * sha256.Size
+		if !bytes.Equal(hash(sig[i*sha256.Size:(i+1)*sha256.Size]), pub[j:j+sha256.Size]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package otsig
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestLamport(t *testing.T) {
+	t.Parallel()
+	key, err := GenerateLamportKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := key.PublicKey()
+	sizes := LamportSizes()
+	if len(pub) != sizes.PublicKey || sizes.PublicKey != 16384 || sizes.Signature != 8192 {
+		t.Fatalf("unexpected sizes %+v", sizes)
+	}
+	sig, err := key.Sign([]byte("once"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig) != sizes.Signature {
+		t.Fatalf("expected a signature of %d bytes but got %d", sizes.Signature, len(sig))
+	}
+	if !VerifyLamport(pub, []byte("once"), sig) {
+		t.Fatal("signature does not verify")
+	}
+	if VerifyLamport(pub, []byte("twice"), sig) {
+		t.Fatal("signature verifies a different message")
+	}
+	sig[len(sig)-1] ^= 1
+	if VerifyLamport(pub, []byte("once"), sig) {
+		t.Fatal("altered signature verifies")
+	}
+	if _, err := key.Sign([]byte("twice")); err != ErrKeyUsed {
+		t.Fatalf("expected ErrKeyUsed but got %v", err)
+	}
+}
+
+// TestLamportReuse shows what a second signature gives away: both secrets
+// of about half of the pairs
+func TestLamportReuse(t *testing.T) {
+	t.Parallel()
+	key, err := GenerateLamportKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _ := key.Sign([]byte("first"))
+	key.used = false
+	second, _ := key.Sign([]byte("second"))
+	a, b := hash([]byte("first")), hash([]byte("second"))
+	both := 0
+	for i := 0; i < lamportBits; i++ {
+		if lamportIndex(a, i) != lamportIndex(b, i) {
+			both++
+			continue
+		}
+		if string(first[i*sha256.Size:(i+1)*sha256.Size]) != string(second[i*sha256.Size:(i+1)*sha256.Size]) {
+			t.Fatalf("bit %d is equal but the revealed secrets differ", i)
+		}
+	}
+	if both < 64 || both > 192 {
+		t.Fatalf("expected about 128 pairs fully revealed but got %d", both)
+	}
+}
@@ -0,0 +1,39 @@
+// Package otsig implements the Lamport and Winternitz one-time signatures
+// on SHA-256, in their plain textbook forms.
+//
+// A Lamport key is 256 pairs of random secrets, published as their
+// hashes. Signing the SHA-256 digest of a message reveals, for each bit,
+// the secret of the pair that matches it. Two signatures reveal both
+// secrets of every pair where the digests differ, about half of them,
+// and anyone can then sign many other messages.
+//
+// Winternitz trades hashing for size: each secret is the start of a chain
+// of 2^w - 1 hashes and signs w bits of the digest, the signature being
+// the point of the chain that many steps in. A verifier hashes on to the
+// end of the chain. Since anyone can hash further, a checksum of the
+// digits, which goes down when they go up, is signed along with them.
+//
+// The WOTS+ of package hashsig adds a seed and addresses to each hash so
+// that the security rests on weaker properties of the hash function, and
+// compresses the public key to a single hash.
+package otsig
+
+import (
+	"crypto/sha256"
+	"errors"
+)
+
+// ErrKeyUsed is returned when signing a second time with a one-time key
+var ErrKeyUsed = errors.New("otsig: one-time key already used")
+
+// Sizes are the sizes in bytes of the keys and signatures of a scheme
+type Sizes struct {
+	PrivateKey int
+	PublicKey  int
+	Signature  int
+}
+
+func hash(x []byte) []byte {
+	h := sha256.Sum256(x)
+	return h[:]
+}
@@ -0,0 +1,127 @@
+package otsig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"math/bits"
+)
+
+// winternitz returns the number of chains of the digest and of the
+// checksum for w bits per chain
+func winternitz(w int) (len1, len2 int) {
+	len1 = 8 * sha256.Size / w
+	// the checksum is at most len1 (2^w - 1)
+	max := len1 * (1<<uint(w) - 1)
+	len2 = (bits.Len(uint(max)) + w - 1) / w
+	return len1, len2
+}
+
+func checkW(w int) error {
+	switch w {
+	case 1, 2, 4, 8:
+		return nil
+	}
+	return errors.New("otsig: w must be 1, 2, 4 or 8")
+}
+
+// WinternitzSizes returns the sizes of Winternitz keys and signatures with
+// w bits signed per chain: one hash per chain for each of them. Chains
+// are 2^w - 1 hashes long, so doubling w halves the sizes but squares the
+// work.
+func WinternitzSizes(w int) (Sizes, error) {
+	if err := checkW(w); err != nil {
+		return Sizes{}, err
+	}
+	len1, len2 := winternitz(w)
+	size := (len1 + len2) * sha256.Size
+	return Sizes{PrivateKey: size, PublicKey: size, Signature: size}, nil
+}
+
+// winternitzDigits returns the base 2^w digits of the digest of msg,
+// followed by those of their checksum
+func winternitzDigits(w int, msg []byte) []int {
+	len1, len2 := winternitz(w)
+	digest := hash(msg)
+	digits := make([]int, 0, len1+len2)
+	checksum := 0
+	for i := 0; i < len1; i++ {
+		bit := i * w
+		d := int(digest[bit/8]>>uint(8-w-bit%8)) & (1<<uint(w) - 1)
+		digits = append(digits, d)
+		checksum += 1<<uint(w) - 1 - d
+	}
+	for i := len2 - 1; i >= 0; i-- {
+		digits = append(digits, checksum>>uint(i*w)&(1<<uint(w)-1))
+	}
+	return digits
+}
+
+// chain hashes x steps times
+func chain(x []byte, steps int) []byte {
+	for i := 0; i < steps; i++ {
+		x = hash(x)
+	}
+	return x
+}
+
+// WinternitzPrivateKey is a Winternitz key, which signs a single message
+type WinternitzPrivateKey struct {
+	w       int
+	secrets []byte
+	used    bool
+}
+
+// GenerateWinternitzKey returns a new Winternitz key signing w bits per
+// chain, with secrets read from rand
+func GenerateWinternitzKey(rand io.Reader, w int) (*WinternitzPrivateKey, error) {
+	sizes, err := WinternitzSizes(w)
+	if err != nil {
+		return nil, err
+	}
+	secrets := make([]byte, sizes.PrivateKey)
+	if _, err := io.ReadFull(rand, secrets); err != nil {
+		return nil, err
+	}
+	return &WinternitzPrivateKey{w: w, secrets: secrets}, nil
+}
+
+// PublicKey returns the ends of the chains
+func (key *WinternitzPrivateKey) PublicKey() []byte {
+	pub := make([]byte, 0, len(key.secrets))
+	for i := 0; i < len(key.secrets); i += sha256.Size {
+		pub = append(pub, chain(key.secrets[i:i+sha256.Size], 1<<uint(key.w)-1)...)
+	}
+	return pub
+}
+
+// Sign returns the signature of msg, or ErrKeyUsed if the key has already
+// signed
+func (key *WinternitzPrivateKey) Sign(msg []byte) ([]byte, error) {
+	if key.used {
+		return nil, ErrKeyUsed
+	}
+	key.used = true
+	sig := make([]byte, 0, len(key.secrets))
+	for i, d := range winternitzDigits(key.w, msg) {
+		sig = append(sig, chain(key.secrets[i*sha256.Size:(i+1)*sha256.Size], d)...)
+	}
+	return sig, nil
+}
+
+// VerifyWinternitz tells whether sig is a valid Winternitz signature of
+// msg by pub, with w bits per chain
+func VerifyWinternitz(w int, pub, msg, sig []byte) bool {
+	sizes, err := WinternitzSizes(w)
+	if err != nil || len(pub) != sizes.PublicKey || len(sig) != sizes.Signature {
+		return false
+	}
+	for i, d := range winternitzDigits(w, msg) {
+		end := chain(sig[i*sha256.Size:(i+1)*sha256.Size], 1<<uint(w)-1-d)
+		if !bytes.Equal(end, pub[i*sha256.Size:(i+1)*sha256.Size]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package otsig
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+)
+
+var winternitzSizes = []struct {
+	w, size int
+}{
+	{1, (256 + 9) * 32},
+	{2, (128 + 5) * 32},
+	{4, (64 + 3) * 32},
+	{8, (32 + 2) * 32},
+}
+
+func TestWinternitz(t *testing.T) {
+	t.Parallel()
+	for i, tc := range winternitzSizes {
+		sizes, err := WinternitzSizes(tc.w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sizes.Signature != tc.size || sizes.PublicKey != tc.size {
+			t.Fatalf("testcase %d expected signatures of %d bytes but got %+v", i, tc.size, sizes)
+		}
+		key, err := GenerateWinternitzKey(rand.Reader, tc.w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub := key.PublicKey()
+		sig, err := key.Sign([]byte("once"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sig) != tc.size || len(pub) != tc.size {
+			t.Fatalf("testcase %d expected %d bytes but got %d and %d", i, tc.size, len(pub), len(sig))
+		}
+		if !VerifyWinternitz(tc.w, pub, []byte("once"), sig) {
+			t.Fatalf("testcase %d: signature does not verify", i)
+		}
+		if VerifyWinternitz(tc.w, pub, []byte("twice"), sig) {
+			t.Fatalf("testcase %d: signature verifies a different message", i)
+		}
+		if _, err := key.Sign([]byte("twice")); err != ErrKeyUsed {
+			t.Fatalf("testcase %d expected ErrKeyUsed but got %v", i, err)
+		}
+	}
+	if _, err := GenerateWinternitzKey(rand.Reader, 3); err == nil {
+		t.Fatal("expected an error with w = 3")
+	}
+}
+
+// TestWinternitzChecksum moves the first chain of a signature one step
+// forward, which anyone can do. It signs a digest with a higher first
+// digit, but the checksum no longer matches.
+func TestWinternitzChecksum(t *testing.T) {
+	t.Parallel()
+	key, err := GenerateWinternitzKey(rand.Reader, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := key.PublicKey()
+	msg := []byte("checksum")
+	sig, _ := key.Sign(msg)
+	digits := winternitzDigits(4, msg)
+	copy(sig, hash(sig[:sha256.Size]))
+	for i, d := range digits[:64] {
+		end := chain(sig[i*sha256.Size:(i+1)*sha256.Size], 15-d)
+		if i == 0 {
+			end = chain(sig[:sha256.Size], 15-d-1)
+		}
+		if string(end) != string(pub[i*sha256.Size:(i+1)*sha256.Size]) {
+			t.Fatalf("chain %d of the forged digest does not verify", i)
+		}
+	}
+	if VerifyWinternitz(4, pub, msg, sig) {
+		t.Fatal("forged signature verifies")
+	}
+}
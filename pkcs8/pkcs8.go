@@ -0,0 +1,411 @@
+// Package pkcs8 reads and writes passphrase encrypted PKCS#8 private keys,
+// using the PBES2 scheme of RFC 8018 with a PBKDF2 or scrypt key derivation
+// and AES in CBC or GCM mode. Keys encrypted with PBKDF2 or scrypt and
+// AES-CBC load in OpenSSL with `openssl pkey -in key.pem`.
+package pkcs8
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/jvehent/badcrypto/kdf"
+)
+
+// PEMType is the type of the PEM block carrying an encrypted key
+const PEMType = "ENCRYPTED PRIVATE KEY"
+
+var (
+	oidPBES2  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidScrypt = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11591, 4, 11}
+
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+
+	oidAES128CBC = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidAES128GCM = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 6}
+	oidAES256GCM = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 46}
+)
+
+// KDF selects the password based key derivation function
+type KDF int
+
+// Supported key derivation functions
+const (
+	PBKDF2 KDF = iota
+	Scrypt
+)
+
+// Cipher selects the encryption scheme applied to the private key
+type Cipher int
+
+// Supported ciphers. OpenSSL does not accept AEAD ciphers in PBES2,
+// so the GCM variants only round trip with this package.
+const (
+	AES256CBC Cipher = iota
+	AES128CBC
+	AES192CBC
+	AES128GCM
+	AES256GCM
+)
+
+// Options controls how a private key gets encrypted. The zero value is
+// not valid, use DefaultOptions as a starting point.
+type Options struct {
+	KDF        KDF
+	Cipher     Cipher
+	Iterations int // PBKDF2 iteration count
+	ScryptN    int // scrypt cost parameters
+	ScryptR    int
+	ScryptP    int
+}
+
+// DefaultOptions matches what `openssl pkcs8 -topk8 -v2 aes-256-cbc` produces
+var DefaultOptions = Options{
+	KDF:        PBKDF2,
+	Cipher:     AES256CBC,
+	Iterations: 2048,
+	ScryptN:    16384,
+	ScryptR:    8,
+	ScryptP:    1,
+}
+
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+type scryptParams struct {
+	Salt                     []byte
+	CostParameter            int
+	BlockSize                int
+	ParallelizationParameter int
+	KeyLength                int `asn1:"optional"`
+}
+
+type gcmParams struct {
+	Nonce  []byte
+	ICVLen int `asn1:"default:12"`
+}
+
+// info returns the oid and key length of a cipher
+func (c Cipher) info() (oid asn1.ObjectIdentifier, keyLen int, err error) {
+	switch c {
+	case AES128CBC:
+		return oidAES128CBC, 16, nil
+	case AES192CBC:
+		return oidAES192CBC, 24, nil
+	case AES256CBC:
+		return oidAES256CBC, 32, nil
+	case AES128GCM:
+		return oidAES128GCM, 16, nil
+	case AES256GCM:
+		return oidAES256GCM, 32, nil
+	}
+	return nil, 0, fmt.Errorf("pkcs8: unknown cipher %d", c)
+}
+
+// MarshalEncryptedPrivateKey serializes priv, which can be any key type
+// supported by x509.MarshalPKCS8PrivateKey, into a DER encoded
+// EncryptedPrivateKeyInfo protected by passphrase.
+func MarshalEncryptedPrivateKey(priv interface{}, passphrase []byte, opts *Options) ([]byte, error) {
+	if opts == nil {
+		opts = &DefaultOptions
+	}
+	plaintext, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	cipherOID, keyLen, err := opts.Cipher.info()
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	var (
+		key    []byte
+		kdfAlg pkix.AlgorithmIdentifier
+		params []byte
+	)
+	switch opts.KDF {
+	case PBKDF2:
+		if opts.Iterations < 1 {
+			return nil, errors.New("pkcs8: pbkdf2 iteration count must be positive")
+		}
+		key = kdf.PBKDF2(passphrase, salt, opts.Iterations, keyLen, sha256.New)
+		params, err = asn1.Marshal(pbkdf2Params{
+			Salt:           salt,
+			IterationCount: opts.Iterations,
+			PRF:            pkix.AlgorithmIdentifier{Algorithm: oidHMACWithSHA256, Parameters: asn1.NullRawValue},
+		})
+		kdfAlg.Algorithm = oidPBKDF2
+	case Scrypt:
+		key, err = kdf.Scrypt(passphrase, salt, opts.ScryptN, opts.ScryptR, opts.ScryptP, keyLen)
+		if err != nil {
+			return nil, err
+		}
+		params, err = asn1.Marshal(scryptParams{
+			Salt:                     salt,
+			CostParameter:            opts.ScryptN,
+			BlockSize:                opts.ScryptR,
+			ParallelizationParameter: opts.ScryptP,
+		})
+		kdfAlg.Algorithm = oidScrypt
+	default:
+		return nil, fmt.Errorf("pkcs8: unknown key derivation function %d", opts.KDF)
+	}
+	if err != nil {
+		return nil, err
+	}
+	kdfAlg.Parameters = asn1.RawValue{FullBytes: params}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		ciphertext []byte
+		encParams  []byte
+	)
+	switch opts.Cipher {
+	case AES128GCM, AES256GCM:
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, err
+		}
+		ciphertext = aead.Seal(nil, nonce, plaintext, nil)
+		encParams, err = asn1.Marshal(gcmParams{Nonce: nonce, ICVLen: aead.Overhead()})
+		if err != nil {
+			return nil, err
+		}
+	default:
+		iv := make([]byte, aes.BlockSize)
+		if _, err := rand.Read(iv); err != nil {
+			return nil, err
+		}
+		ciphertext = pad(plaintext, aes.BlockSize)
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, ciphertext)
+		encParams, err = asn1.Marshal(iv)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pbes2, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: kdfAlg,
+		EncryptionScheme:  pkix.AlgorithmIdentifier{Algorithm: cipherOID, Parameters: asn1.RawValue{FullBytes: encParams}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		Algorithm:     pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: pbes2}},
+		EncryptedData: ciphertext,
+	})
+}
+
+// ParseEncryptedPrivateKey decrypts a DER encoded EncryptedPrivateKeyInfo
+// with passphrase and returns the parsed private key
+func ParseEncryptedPrivateKey(der, passphrase []byte) (interface{}, error) {
+	var info encryptedPrivateKeyInfo
+	if rest, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, err
+	} else if len(rest) > 0 {
+		return nil, errors.New("pkcs8: trailing data after encrypted private key")
+	}
+	if !info.Algorithm.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("pkcs8: unsupported encryption algorithm %s, only PBES2 is supported", info.Algorithm.Algorithm)
+	}
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, err
+	}
+
+	var (
+		keyLen  int
+		isGCM   bool
+		encAlgo = params.EncryptionScheme.Algorithm
+	)
+	switch {
+	case encAlgo.Equal(oidAES128CBC):
+		keyLen = 16
+	case encAlgo.Equal(oidAES192CBC):
+		keyLen = 24
+	case encAlgo.Equal(oidAES256CBC):
+		keyLen = 32
+	case encAlgo.Equal(oidAES128GCM):
+		keyLen, isGCM = 16, true
+	case encAlgo.Equal(oidAES256GCM):
+		keyLen, isGCM = 32, true
+	default:
+		return nil, fmt.Errorf("pkcs8: unsupported encryption scheme %s", encAlgo)
+	}
+
+	key, err := deriveKey(params.KeyDerivationFunc, passphrase, keyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var plaintext []byte
+	if isGCM {
+		var gp gcmParams
+		if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &gp); err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCMWithNonceSize(block, len(gp.Nonce))
+		if err != nil {
+			return nil, err
+		}
+		if gp.ICVLen != aead.Overhead() {
+			return nil, errors.New("pkcs8: unsupported gcm tag length")
+		}
+		plaintext, err = aead.Open(nil, gp.Nonce, info.EncryptedData, nil)
+		if err != nil {
+			return nil, errors.New("pkcs8: decryption failed, wrong passphrase?")
+		}
+	} else {
+		var iv []byte
+		if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+			return nil, err
+		}
+		if len(iv) != aes.BlockSize || len(info.EncryptedData)%aes.BlockSize != 0 || len(info.EncryptedData) == 0 {
+			return nil, errors.New("pkcs8: invalid cbc parameters")
+		}
+		plaintext = make([]byte, len(info.EncryptedData))
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, info.EncryptedData)
+		if plaintext, err = unpad(plaintext, aes.BlockSize); err != nil {
+			return nil, err
+		}
+	}
+	return x509.ParsePKCS8PrivateKey(plaintext)
+}
+
+// deriveKey runs the key derivation function described by alg
+func deriveKey(alg pkix.AlgorithmIdentifier, passphrase []byte, keyLen int) ([]byte, error) {
+	switch {
+	case alg.Algorithm.Equal(oidPBKDF2):
+		var p pbkdf2Params
+		if _, err := asn1.Unmarshal(alg.Parameters.FullBytes, &p); err != nil {
+			return nil, err
+		}
+		if p.KeyLength != 0 && p.KeyLength != keyLen {
+			return nil, errors.New("pkcs8: pbkdf2 key length does not match the cipher")
+		}
+		var h func() hash.Hash
+		switch {
+		case len(p.PRF.Algorithm) == 0, p.PRF.Algorithm.Equal(oidHMACWithSHA1):
+			// hmacWithSHA1 is the default prf when none is specified
+			h = sha1.New
+		case p.PRF.Algorithm.Equal(oidHMACWithSHA256):
+			h = sha256.New
+		case p.PRF.Algorithm.Equal(oidHMACWithSHA512):
+			h = sha512.New
+		default:
+			return nil, fmt.Errorf("pkcs8: unsupported pbkdf2 prf %s", p.PRF.Algorithm)
+		}
+		if p.IterationCount < 1 {
+			return nil, errors.New("pkcs8: invalid pbkdf2 iteration count")
+		}
+		return kdf.PBKDF2(passphrase, p.Salt, p.IterationCount, keyLen, h), nil
+	case alg.Algorithm.Equal(oidScrypt):
+		var p scryptParams
+		if _, err := asn1.Unmarshal(alg.Parameters.FullBytes, &p); err != nil {
+			return nil, err
+		}
+		if p.KeyLength != 0 && p.KeyLength != keyLen {
+			return nil, errors.New("pkcs8: scrypt key length does not match the cipher")
+		}
+		return kdf.Scrypt(passphrase, p.Salt, p.CostParameter, p.BlockSize, p.ParallelizationParameter, keyLen)
+	}
+	return nil, fmt.Errorf("pkcs8: unsupported key derivation function %s", alg.Algorithm)
+}
+
+// EncodePEM encrypts priv and wraps it into an ENCRYPTED PRIVATE KEY pem block
+func EncodePEM(priv interface{}, passphrase []byte, opts *Options) ([]byte, error) {
+	der, err := MarshalEncryptedPrivateKey(priv, passphrase, opts)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: PEMType, Bytes: der}), nil
+}
+
+// DecodePEM parses the first ENCRYPTED PRIVATE KEY pem block of data
+func DecodePEM(data, passphrase []byte) (interface{}, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("pkcs8: no encrypted private key pem block found")
+		}
+		if block.Type == PEMType {
+			return ParseEncryptedPrivateKey(block.Bytes, passphrase)
+		}
+	}
+}
+
+// pad applies PKCS#7 padding to buf
+func pad(buf []byte, blockSize int) []byte {
+	n := blockSize - len(buf)%blockSize
+	out := make([]byte, len(buf), len(buf)+n)
+	copy(out, buf)
+	for i := 0; i < n; i++ {
+		out = append(out, byte(n))
+	}
+	return out
+}
+
+// unpad removes PKCS#7 padding from buf. A bad padding almost always
+// means the passphrase was wrong.
+func unpad(buf []byte, blockSize int) ([]byte, error) {
+	n := int(buf[len(buf)-1])
+	if n == 0 || n > blockSize || n > len(buf) {
+		return nil, errors.New("pkcs8: decryption failed, wrong passphrase?")
+	}
+	padding := buf[len(buf)-n:]
+	expected := make([]byte, n)
+	for i := range expected {
+		expected[i] = byte(n)
+	}
+	if subtle.ConstantTimeCompare(padding, expected) != 1 {
+		return nil, errors.New("pkcs8: decryption failed, wrong passphrase?")
+	}
+	return buf[:len(buf)-n], nil
+}
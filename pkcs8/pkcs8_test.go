@@ -0,0 +1,125 @@
+package pkcs8
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var testcases = []Options{
+		DefaultOptions,
+		{KDF: PBKDF2, Cipher: AES128CBC, Iterations: 1000},
+		{KDF: PBKDF2, Cipher: AES192CBC, Iterations: 1000},
+		{KDF: PBKDF2, Cipher: AES256GCM, Iterations: 1000},
+		{KDF: Scrypt, Cipher: AES256CBC, ScryptN: 1024, ScryptR: 8, ScryptP: 1},
+		{KDF: Scrypt, Cipher: AES128GCM, ScryptN: 1024, ScryptR: 8, ScryptP: 1},
+	}
+	for i, opts := range testcases {
+		for _, key := range []interface{}{rsaKey, ecKey, edKey} {
+			opts := opts
+			data, err := EncodePEM(key, []byte("badcrypto"), &opts)
+			if err != nil {
+				t.Fatalf("testcase %d failed to encode %T: %v", i, key, err)
+			}
+			parsed, err := DecodePEM(data, []byte("badcrypto"))
+			if err != nil {
+				t.Fatalf("testcase %d failed to decode %T: %v", i, key, err)
+			}
+			if !reflect.DeepEqual(publicOf(parsed), publicOf(key)) {
+				t.Fatalf("testcase %d decoded key %T does not match", i, key)
+			}
+			if _, err := DecodePEM(data, []byte("goodcrypto")); err == nil {
+				t.Fatalf("testcase %d decoded %T with the wrong passphrase", i, key)
+			}
+		}
+	}
+}
+
+func publicOf(key interface{}) interface{} {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return k.PublicKey
+	case *ecdsa.PrivateKey:
+		return k.PublicKey
+	case ed25519.PrivateKey:
+		return k.Public()
+	}
+	return nil
+}
+
+func TestOpenSSLKeys(t *testing.T) {
+	t.Parallel()
+	// keys encrypted with openssl pkcs8 -topk8, using the passphrase "badcrypto"
+	var testcases = []struct {
+		key, pub string
+	}{
+		{"testdata/ec-pbkdf2-aes256cbc.pem", "testdata/ec.pub.pem"},
+		{"testdata/ec-scrypt-aes256cbc.pem", "testdata/ec.pub.pem"},
+		{"testdata/ed25519-pbkdf2sha1-aes128cbc.pem", "testdata/ed25519.pub.pem"},
+	}
+	for i, testcase := range testcases {
+		data, err := ioutil.ReadFile(testcase.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := DecodePEM(data, []byte("badcrypto"))
+		if err != nil {
+			t.Fatalf("testcase %d failed to decode %s: %v", i, testcase.key, err)
+		}
+		pubData, err := ioutil.ReadFile(testcase.pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block, _ := pem.Decode(pubData)
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := pub
+		if ecPub, ok := pub.(*ecdsa.PublicKey); ok {
+			expected = *ecPub
+		}
+		if !reflect.DeepEqual(publicOf(key), expected) {
+			t.Fatalf("testcase %d key from %s does not match its public key", i, testcase.key)
+		}
+	}
+}
+
+func TestPadding(t *testing.T) {
+	t.Parallel()
+	for n := 0; n < 40; n++ {
+		buf := make([]byte, n)
+		padded := pad(buf, 16)
+		if len(padded)%16 != 0 || len(padded) <= n {
+			t.Fatalf("bad padded length %d for input of %d bytes", len(padded), n)
+		}
+		unpadded, err := unpad(padded, 16)
+		if err != nil || len(unpadded) != n {
+			t.Fatalf("failed to unpad input of %d bytes: %v", n, err)
+		}
+	}
+	if _, err := unpad([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 3}, 16); err == nil {
+		t.Fatal("expected an error on invalid padding")
+	}
+}
@@ -0,0 +1,272 @@
+// Package poly implements polynomials with coefficients in Z/nZ, for a
+// modulus n that may be composite such as an RSA modulus.
+//
+// Z/nZ is only a field when n is prime. Euclidean division, and so the
+// GCD, needs to invert the leading coefficient of the divisor, which
+// fails when it shares a factor with n. That never happens by chance with
+// an RSA modulus, and when it does the factor is returned in a
+// FactorError, which is even better than the GCD.
+package poly
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Poly is a polynomial whose i-th element is the coefficient of x^i. The
+// zero polynomial is empty. Polynomials returned by a Ring are reduced
+// modulo n and have no leading zero coefficient.
+type Poly []*big.Int
+
+// Degree returns the degree of p, -1 for the zero polynomial
+func (p Poly) Degree() int {
+	return len(p) - 1
+}
+
+// String formats p such as 3x^2 + x + 5
+func (p Poly) String() string {
+	if len(p) == 0 {
+		return "0"
+	}
+	var terms []string
+	for i := len(p) - 1; i >= 0; i-- {
+		c := p[i]
+		if c.Sign() == 0 {
+			continue
+		}
+		coeff := c.String()
+		if c.Cmp(one) == 0 && i > 0 {
+			coeff = ""
+		}
+		switch i {
+		case 0:
+			terms = append(terms, coeff)
+		case 1:
+			terms = append(terms, coeff+"x")
+		default:
+			terms = append(terms, fmt.Sprintf("%sx^%d", coeff, i))
+		}
+	}
+	return strings.Join(terms, " + ")
+}
+
+// FactorError is returned when a leading coefficient is not invertible
+// modulo n, Factor is then a non trivial factor of n
+type FactorError struct {
+	Factor *big.Int
+}
+
+func (e *FactorError) Error() string {
+	return fmt.Sprintf("poly: coefficient not invertible, the modulus has a factor %s", e.Factor)
+}
+
+var one = big.NewInt(1)
+
+// Ring is the ring of polynomials over Z/NZ
+type Ring struct {
+	N *big.Int
+}
+
+// New returns the polynomial with coefficients coeffs, from x^0 up,
+// reduced modulo n
+func (r Ring) New(coeffs ...*big.Int) Poly {
+	p := make(Poly, len(coeffs))
+	for i, c := range coeffs {
+		p[i] = new(big.Int).Mod(c, r.N)
+	}
+	return p.norm()
+}
+
+// NewInt64 is New with small coefficients
+func (r Ring) NewInt64(coeffs ...int64) Poly {
+	p := make(Poly, len(coeffs))
+	for i, c := range coeffs {
+		p[i] = big.NewInt(c)
+	}
+	return r.New(p...)
+}
+
+// norm drops the leading zero coefficients
+func (p Poly) norm() Poly {
+	for len(p) > 0 && p[len(p)-1].Sign() == 0 {
+		p = p[:len(p)-1]
+	}
+	return p
+}
+
+// Equal returns true if p and q have the same coefficients
+func (p Poly) Equal(q Poly) bool {
+	p, q = p.norm(), q.norm()
+	if len(p) != len(q) {
+		return false
+	}
+	for i := range p {
+		if p[i].Cmp(q[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Add returns a + b
+func (r Ring) Add(a, b Poly) Poly {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	sum := make(Poly, len(a))
+	for i := range a {
+		sum[i] = new(big.Int).Set(a[i])
+		if i < len(b) {
+			sum[i].Add(sum[i], b[i])
+			sum[i].Mod(sum[i], r.N)
+		}
+	}
+	return sum.norm()
+}
+
+// Sub returns a - b
+func (r Ring) Sub(a, b Poly) Poly {
+	return r.Add(a, r.Neg(b))
+}
+
+// Neg returns -a
+func (r Ring) Neg(a Poly) Poly {
+	neg := make(Poly, len(a))
+	for i, c := range a {
+		neg[i] = new(big.Int).Neg(c)
+		neg[i].Mod(neg[i], r.N)
+	}
+	return neg.norm()
+}
+
+// Scale returns c * a
+func (r Ring) Scale(a Poly, c *big.Int) Poly {
+	scaled := make(Poly, len(a))
+	for i := range a {
+		scaled[i] = new(big.Int).Mul(a[i], c)
+		scaled[i].Mod(scaled[i], r.N)
+	}
+	return scaled.norm()
+}
+
+// Mul returns a * b, with the schoolbook algorithm
+func (r Ring) Mul(a, b Poly) Poly {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	product := make(Poly, len(a)+len(b)-1)
+	for i := range product {
+		product[i] = new(big.Int)
+	}
+	t := new(big.Int)
+	for i := range a {
+		for j := range b {
+			product[i+j].Add(product[i+j], t.Mul(a[i], b[j]))
+		}
+	}
+	for _, c := range product {
+		c.Mod(c, r.N)
+	}
+	return product.norm()
+}
+
+// Pow returns a^e by square and multiply
+func (r Ring) Pow(a Poly, e int) Poly {
+	if e < 0 {
+		panic("poly: negative exponent")
+	}
+	result := r.NewInt64(1)
+	for ; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			result = r.Mul(result, a)
+		}
+		a = r.Mul(a, a)
+	}
+	return result
+}
+
+// Eval returns a(x) mod n using Horner's method
+func (r Ring) Eval(a Poly, x *big.Int) *big.Int {
+	y := new(big.Int)
+	for i := len(a) - 1; i >= 0; i-- {
+		y.Mul(y, x)
+		y.Add(y, a[i])
+		y.Mod(y, r.N)
+	}
+	return y
+}
+
+// inverse returns c^-1 mod n, or a FactorError
+func (r Ring) inverse(c *big.Int) (*big.Int, error) {
+	inv := new(big.Int).ModInverse(c, r.N)
+	if inv == nil {
+		return nil, &FactorError{Factor: new(big.Int).GCD(nil, nil, c, r.N)}
+	}
+	return inv, nil
+}
+
+// DivMod returns the quotient and remainder of the division of a by b,
+// such that a = q*b + rem with deg(rem) < deg(b)
+func (r Ring) DivMod(a, b Poly) (q, rem Poly, err error) {
+	a, b = a.norm(), b.norm()
+	if len(b) == 0 {
+		return nil, nil, errors.New("poly: division by the zero polynomial")
+	}
+	inv, err := r.inverse(b[len(b)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	rem = r.New(a...)
+	if len(rem) < len(b) {
+		return nil, rem, nil
+	}
+	q = make(Poly, len(rem)-len(b)+1)
+	t := new(big.Int)
+	for len(rem) >= len(b) {
+		// cancel the leading term of rem with c*x^shift*b
+		shift := len(rem) - len(b)
+		c := new(big.Int).Mul(rem[len(rem)-1], inv)
+		c.Mod(c, r.N)
+		q[shift] = c
+		for i := range b {
+			rem[shift+i].Sub(rem[shift+i], t.Mul(c, b[i]))
+			rem[shift+i].Mod(rem[shift+i], r.N)
+		}
+		rem = rem.norm()
+	}
+	for i := range q {
+		if q[i] == nil {
+			q[i] = new(big.Int)
+		}
+	}
+	return q.norm(), rem, nil
+}
+
+// Monic returns a divided by its leading coefficient
+func (r Ring) Monic(a Poly) (Poly, error) {
+	a = a.norm()
+	if len(a) == 0 {
+		return nil, nil
+	}
+	inv, err := r.inverse(a[len(a)-1])
+	if err != nil {
+		return nil, err
+	}
+	return r.Scale(a, inv), nil
+}
+
+// GCD returns the monic greatest common divisor of a and b with Euclid's
+// algorithm. The GCD of two zero polynomials is zero.
+func (r Ring) GCD(a, b Poly) (Poly, error) {
+	a, b = r.New(a...), r.New(b...)
+	for len(b) > 0 {
+		_, rem, err := r.DivMod(a, b)
+		if err != nil {
+			return nil, err
+		}
+		a, b = b, rem
+	}
+	return r.Monic(a)
+}
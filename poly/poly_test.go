@@ -0,0 +1,139 @@
+package poly
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestString(t *testing.T) {
+	t.Parallel()
+	r := Ring{N: big.NewInt(101)}
+	var testcases = []struct {
+		p        Poly
+		expected string
+	}{
+		{r.NewInt64(), "0"},
+		{r.NewInt64(0, 0), "0"},
+		{r.NewInt64(5), "5"},
+		{r.NewInt64(5, 1, 3), "3x^2 + x + 5"},
+		{r.NewInt64(-1, 0, 0, 1), "x^3 + 100"},
+	}
+	for i, tc := range testcases {
+		if s := tc.p.String(); s != tc.expected {
+			t.Fatalf("testcase %d expected %q but got %q", i, tc.expected, s)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	t.Parallel()
+	r := Ring{N: big.NewInt(101)}
+	a := r.NewInt64(1, 2, 3)
+	b := r.NewInt64(100, 1)
+	var testcases = []struct {
+		got, expected Poly
+	}{
+		{r.Add(a, b), r.NewInt64(0, 3, 3)},
+		{r.Sub(a, a), nil},
+		{r.Sub(b, a), r.NewInt64(99, -1, -3)},
+		{r.Mul(a, b), r.NewInt64(-1, -1, -1, 3)},
+		{r.Mul(a, nil), nil},
+		{r.Scale(a, big.NewInt(34)), r.NewInt64(34, 68, 1)},
+		{r.Pow(b, 3), r.NewInt64(-1, 3, -3, 1)},
+		{r.Pow(a, 0), r.NewInt64(1)},
+	}
+	for i, tc := range testcases {
+		if !tc.got.Equal(tc.expected) {
+			t.Fatalf("testcase %d expected %s but got %s", i, tc.expected, tc.got)
+		}
+	}
+	if y := r.Eval(a, big.NewInt(10)); y.Int64() != 321%101 {
+		t.Fatalf("expected a(10) = %d but got %s", 321%101, y)
+	}
+}
+
+func TestDivMod(t *testing.T) {
+	t.Parallel()
+	n, _ := new(big.Int).SetString("c2b5bf2e9ad4d2b4c1d4fd0e2d1c4a3a0e8b2d0e9d39d7b3f2a6a3e1e0d3b5c7", 16)
+	r := Ring{N: n}
+	for i := 0; i < 20; i++ {
+		a := randomPoly(t, r, 1+i%7)
+		b := randomPoly(t, r, 1+i%4)
+		q, rem, err := r.DivMod(a, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rem.Degree() >= b.Degree() {
+			t.Fatalf("testcase %d remainder %s of degree not lower than %s", i, rem, b)
+		}
+		if !r.Add(r.Mul(q, b), rem).Equal(a) {
+			t.Fatalf("testcase %d expected %s = (%s) * (%s) + %s", i, a, q, b, rem)
+		}
+	}
+	if _, _, err := r.DivMod(r.NewInt64(1, 1), nil); err == nil {
+		t.Fatal("expected a division by zero to fail")
+	}
+}
+
+func randomPoly(t *testing.T, r Ring, degree int) Poly {
+	p := make(Poly, degree+1)
+	for i := range p {
+		c, err := rand.Int(rand.Reader, r.N)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p[i] = c
+	}
+	p[degree].SetInt64(1 + int64(degree))
+	return r.New(p...)
+}
+
+func TestGCD(t *testing.T) {
+	t.Parallel()
+	r := Ring{N: big.NewInt(101)}
+	// (x - 1)(x - 2) and (x - 1)(x - 3)
+	g, err := r.GCD(r.Mul(r.NewInt64(-1, 1), r.NewInt64(-2, 1)), r.Mul(r.NewInt64(-1, 1), r.NewInt64(-3, 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.Equal(r.NewInt64(-1, 1)) {
+		t.Fatalf("expected x - 1 but got %s", g)
+	}
+	// coprime polynomials, the gcd is 1
+	g, err = r.GCD(r.NewInt64(1, 0, 1), r.NewInt64(3, 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.Equal(r.NewInt64(1)) {
+		t.Fatalf("expected 1 but got %s", g)
+	}
+	// the gcd is monic even when the inputs are not
+	g, err = r.GCD(r.Scale(r.NewInt64(4, 0, 1), big.NewInt(7)), r.NewInt64(0, 0, 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.Equal(r.NewInt64(1)) {
+		t.Fatalf("expected 1 but got %s", g)
+	}
+	g, err = r.GCD(nil, r.NewInt64(6, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.Equal(r.NewInt64(2, 1)) {
+		t.Fatalf("expected x + 2 but got %s", g)
+	}
+}
+
+func TestFactorError(t *testing.T) {
+	t.Parallel()
+	r := Ring{N: big.NewInt(15)}
+	_, err := r.GCD(r.NewInt64(1, 0, 1), r.NewInt64(1, 3))
+	fe, ok := err.(*FactorError)
+	if !ok {
+		t.Fatalf("expected a FactorError but got %v", err)
+	}
+	if fe.Factor.Int64() != 3 {
+		t.Fatalf("expected the factor 3 but got %s", fe.Factor)
+	}
+}
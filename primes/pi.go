@@ -0,0 +1,126 @@
+package primes
+
+import "math"
+
+// li2 is li(2), the offset between the logarithmic integral li and Li
+const li2 = 1.045163780117492784844588889194613136522615578151
+
+// Pi returns π(x), the number of primes lower than or equal to x, by
+// counting them with a segmented sieve. It keeps the primes up to the
+// square root of x in memory and takes time linear in x.
+func Pi(x uint64) int {
+	if x < 2 {
+		return 0
+	}
+	base := Below(int(isqrt(x)))
+	const segment = 1 << 16
+	composite := make([]bool, segment)
+	count := 0
+	for lo := uint64(0); lo <= x; lo += segment {
+		width := uint64(segment)
+		if x-lo+1 < width {
+			width = x - lo + 1
+		}
+		for i := range composite {
+			composite[i] = false
+		}
+		for i := lo; i < 2 && i-lo < width; i++ {
+			composite[i-lo] = true
+		}
+		for _, p := range base {
+			q := uint64(p)
+			start := (q - lo%q) % q
+			if lo <= q {
+				start = q*q - lo
+			}
+			for j := start; j < width; j += q {
+				composite[j] = true
+			}
+		}
+		for _, c := range composite[:width] {
+			if !c {
+				count++
+			}
+		}
+		if lo > x-segment {
+			// lo would wrap around for x close to 2^64
+			break
+		}
+	}
+	return count
+}
+
+// isqrt returns the largest r such that r^2 <= x
+func isqrt(x uint64) uint64 {
+	r := uint64(math.Sqrt(float64(x)))
+	for r*r > x {
+		r--
+	}
+	for (r+1)*(r+1) <= x && (r+1)*(r+1) > r*r {
+		r++
+	}
+	return r
+}
+
+// Li returns the offset logarithmic integral Li(x), the integral of
+// 1/ln(t) from 2 to x, which approximates π(x) far better than x/ln(x):
+// it is off by about the square root of x. It is computed from
+// Ramanujan's series for li(x), which converges quickly for any x > 1.
+func Li(x float64) float64 {
+	if x <= 2 {
+		return 0
+	}
+	const gamma = 0.57721566490153286060651209008240243104215933593992
+	l := math.Log(x)
+	sum, term, inner := 0.0, 1.0, 0.0
+	for n := 1; n < 5000; n++ {
+		// term is (-1)^(n-1) ln(x)^n / (n! 2^(n-1))
+		if n == 1 {
+			term = l
+		} else {
+			term *= -l / float64(n) / 2
+		}
+		if (n-1)%2 == 0 {
+			inner += 1 / float64(n)
+		}
+		delta := term * inner
+		sum += delta
+		if math.Abs(delta) < 1e-17*math.Abs(sum) {
+			break
+		}
+	}
+	return gamma + math.Log(l) + math.Sqrt(x)*sum - li2
+}
+
+// Approx returns x/ln(x), the estimate of π(x) of the prime number
+// theorem: around x, one number in ln(x) is prime
+func Approx(x float64) float64 {
+	if x <= 1 {
+		return 0
+	}
+	return x / math.Log(x)
+}
+
+// Bounds returns a lower and an upper bound of π(x), the sharpest known
+// explicit ones that hold at x: those of Dusart (2010) above 599 and
+// 355991, and otherwise those of Rosser and Schoenfeld (1962),
+//
+//	x/ln(x) < π(x)  for x >= 17    and    π(x) < 1.25506 x/ln(x)  for x > 1
+func Bounds(x float64) (lower, upper float64) {
+	if x < 2 {
+		return 0, 0
+	}
+	l := math.Log(x)
+	switch {
+	case x >= 599:
+		lower = x / l * (1 + 1/l)
+	case x >= 17:
+		lower = x / l
+	}
+	if x >= 355991 {
+		upper = x / l * (1 + 1/l + 2.51/(l*l))
+	} else {
+		upper = 1.25506 * x / l
+	}
+	return lower, upper
+}
@@ -0,0 +1,64 @@
+package primes
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPi(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		x        uint64
+		expected int
+	}{
+		{0, 0},
+		{1, 0},
+		{2, 1},
+		{10, 4},
+		{100, 25},
+		{1000, 168},
+		{65535, 6542},
+		{65536, 6542},
+		{65537, 6543},
+		{1000000, 78498},
+		{10000000, 664579},
+	}
+	for i, tc := range testcases {
+		if got := Pi(tc.x); got != tc.expected {
+			t.Fatalf("testcase %d expected π(%d) = %d but got %d", i, tc.x, tc.expected, got)
+		}
+	}
+}
+
+func TestLi(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		x, expected float64
+	}{
+		{2, 0},
+		{10, 5.1204},
+		{1e6, 78626.504},
+		{1e9, 50849233.9},
+		{1e18, 24739954309690414},
+	}
+	for i, tc := range testcases {
+		if got := Li(tc.x); math.Abs(got-tc.expected) > 1e-5*tc.expected+1e-4 {
+			t.Fatalf("testcase %d expected Li(%g) = %g but got %g", i, tc.x, tc.expected, got)
+		}
+	}
+}
+
+func TestBounds(t *testing.T) {
+	t.Parallel()
+	for _, x := range []uint64{2, 16, 17, 100, 598, 599, 1000, 100000, 355990, 355991, 1000000, 10000000} {
+		pi := float64(Pi(x))
+		lower, upper := Bounds(float64(x))
+		if lower > pi || pi > upper {
+			t.Fatalf("expected %g <= π(%d) = %g <= %g", lower, x, pi, upper)
+		}
+		// Li is closer than x/ln(x)
+		if x >= 1000 && math.Abs(Li(float64(x))-pi) > math.Abs(Approx(float64(x))-pi) {
+			t.Fatalf("expected Li(%d) to be closer to π than x/ln(x)", x)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Package primes finds primes with the sieve of Eratosthenes and estimates
+// how many there are.
+//
+// Below returns the primes up to a bound, Sieve the primes in consecutive
+// windows starting at any offset, however large, and Pi counts primes
+// exactly with a segmented sieve. Li and Bounds give the approximations of
+// the prime number theorem, which size the searches of key generation and
+// the smoothness bounds of factoring and discrete logarithm attacks.
+package primes
+
+import (
+	"errors"
+	"math/big"
+)
+
+// baseBound bounds the primes a Sieve removes the multiples of. Windows
+// above baseBound^2 keep composites with no smaller factor, which are
+// weeded out by ProbablyPrime.
+const baseBound = 1 << 20
+
+// probablyPrimeRounds is the number of Miller-Rabin rounds used on the
+// candidates left by the sieve above baseBound^2
+const probablyPrimeRounds = 20
+
+// Below returns the primes lower than or equal to n
+func Below(n int) []int {
+	if n < 2 {
+		return nil
+	}
+	composite := make([]bool, n+1)
+	var primes []int
+	for i := 2; i <= n; i++ {
+		if composite[i] {
+			continue
+		}
+		primes = append(primes, i)
+		for j := i * i; j <= n; j += i {
+			composite[j] = true
+		}
+	}
+	return primes
+}
+
+// Sieve returns the primes of consecutive windows of a fixed width, the
+// sieve of Eratosthenes cut into segments: each window is an array where
+// the multiples of the primes up to its square root are crossed out. The
+// window can start anywhere, so the primes next to a 2048 bits number
+// cost the same as those next to a thousand, apart from the final test
+// of the numbers the sieve could not rule out.
+type Sieve struct {
+	next      *big.Int
+	width     int
+	base      []int
+	composite []bool
+}
+
+// NewSieve returns a sieve whose first window starts at start
+func NewSieve(start *big.Int, width int) (*Sieve, error) {
+	if start.Sign() < 0 || width < 1 {
+		return nil, errors.New("primes: sieve needs a positive start and width")
+	}
+	return &Sieve{
+		next:      new(big.Int).Set(start),
+		width:     width,
+		base:      Below(baseBound),
+		composite: make([]bool, width),
+	}, nil
+}
+
+// Next returns the primes in the next window, in increasing order, and
+// moves the sieve past it
+func (s *Sieve) Next() []*big.Int {
+	lo := s.next
+	hi := new(big.Int).Add(lo, big.NewInt(int64(s.width)))
+	for i := range s.composite {
+		s.composite[i] = false
+	}
+	// 0 and 1 are not prime
+	for i := int64(0); lo.IsInt64() && lo.Int64()+i < 2 && i < int64(s.width); i++ {
+		s.composite[i] = true
+	}
+
+	sqrt := new(big.Int).Sqrt(new(big.Int).Sub(hi, big.NewInt(1)))
+	exact := false
+	r, p := new(big.Int), new(big.Int)
+	for _, prime := range s.base {
+		if sqrt.IsUint64() && uint64(prime) > sqrt.Uint64() {
+			exact = true
+			break
+		}
+		// the first multiple of prime in the window, not prime itself
+		var start uint64
+		if lo.IsUint64() && lo.Uint64() <= uint64(prime) {
+			start = uint64(prime)*uint64(prime) - lo.Uint64()
+		} else {
+			p.SetInt64(int64(prime))
+			start = (uint64(prime) - r.Mod(lo, p).Uint64()) % uint64(prime)
+		}
+		for j := start; j < uint64(s.width); j += uint64(prime) {
+			s.composite[j] = true
+		}
+	}
+
+	var primes []*big.Int
+	for i, c := range s.composite {
+		if c {
+			continue
+		}
+		n := new(big.Int).Add(lo, big.NewInt(int64(i)))
+		if exact || n.ProbablyPrime(probablyPrimeRounds) {
+			primes = append(primes, n)
+		}
+	}
+	s.next = hi
+	return primes
+}
@@ -0,0 +1,95 @@
+package primes
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestBelow(t *testing.T) {
+	t.Parallel()
+	expected := []int{2, 3, 5, 7, 11, 13, 17, 19, 23, 29}
+	got := Below(29)
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v but got %v", expected, got)
+	}
+	for i := range got {
+		if got[i] != expected[i] {
+			t.Fatalf("expected %v but got %v", expected, got)
+		}
+	}
+	if len(Below(1)) != 0 || len(Below(2)) != 1 {
+		t.Fatal("expected no prime below 1 and one below 2")
+	}
+}
+
+func TestSieve(t *testing.T) {
+	t.Parallel()
+	// windows from zero match the simple sieve, across several widths
+	expected := Below(100000)
+	for _, width := range []int{1, 7, 1000, 4096} {
+		s, err := NewSieve(new(big.Int), width)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []*big.Int
+		for len(got) < len(expected) {
+			got = append(got, s.Next()...)
+		}
+		for i, p := range expected {
+			if got[i].Int64() != int64(p) {
+				t.Fatalf("width %d expected prime %d to be %d but got %s", width, i, p, got[i])
+			}
+		}
+	}
+}
+
+func TestSieveLargeOffsets(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		start *big.Int
+		width int
+	}{
+		// where the base primes cover the square root of the window
+		{big.NewInt(1<<40 - 3000), 1000},
+		// around 2^64 and 2^127, where they do not
+		{new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(2000)), 1000},
+		{new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1000)), 500},
+	}
+	for i, tc := range testcases {
+		s, err := NewSieve(tc.start, tc.width)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// two windows, compared with testing every number
+		got := append(s.Next(), s.Next()...)
+		var expected []*big.Int
+		for n := new(big.Int).Set(tc.start); len(expected) <= len(got); n.Add(n, big.NewInt(1)) {
+			if n.Cmp(new(big.Int).Add(tc.start, big.NewInt(int64(2*tc.width)))) >= 0 {
+				break
+			}
+			if n.ProbablyPrime(20) {
+				expected = append(expected, new(big.Int).Set(n))
+			}
+		}
+		if len(got) != len(expected) {
+			t.Fatalf("testcase %d expected %d primes but got %d", i, len(expected), len(got))
+		}
+		for k := range got {
+			if got[k].Cmp(expected[k]) != 0 {
+				t.Fatalf("testcase %d expected %s but got %s", i, expected[k], got[k])
+			}
+		}
+	}
+	// 2^127 - 1 is a Mersenne prime, the last number of the window
+	s, _ := NewSieve(new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(100)), 100)
+	primes := s.Next()
+	if last := primes[len(primes)-1]; last.Cmp(new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1))) != 0 {
+		t.Fatalf("expected 2^127 - 1 to be found but got %s", last)
+	}
+	if _, err := NewSieve(big.NewInt(-1), 10); err == nil {
+		t.Fatal("expected a negative start to be refused")
+	}
+	if _, err := NewSieve(big.NewInt(1), 0); err == nil {
+		t.Fatal("expected an empty window to be refused")
+	}
+}
@@ -0,0 +1,200 @@
+// Package psi implements a two-party private set intersection based on
+// Diffie-Hellman over P-256: each party learns which of its items are also
+// held by the other party, and nothing else about the other set but its
+// size.
+//
+// Items are hashed to the curve with the RFC 9380 random oracle encoding,
+// then masked with a secret scalar of each party. Since exponentiation
+// commutes, H(x)^ab = H(y)^ba if and only if x = y. Both parties run the
+// same symmetric protocol:
+//
+//  1. send its own items masked with its secret a, in shuffled order
+//  2. mask the points of the peer with a, and send them back in the
+//     order received
+//  3. receive its own points masked by the peer, and compare them to
+//     the doubly masked points of the peer
+//
+// The protocol is only secure against semi honest peers: a malicious peer
+// can lie about step 2 and make the intersection wrong.
+package psi
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+// MaxSetSize is the largest set a peer is allowed to send
+const MaxSetSize = 1 << 20
+
+// dst is the domain separation tag of the hash to curve
+var dst = []byte("badcrypto-psi-V01-P256_XMD:SHA-256_SSWU_RO_")
+
+// Intersect runs the private set intersection of local with the set of
+// the peer at the other end of conn, which must call Intersect as well.
+// It returns the items of local also held by the peer, without
+// duplicates and in the order they appear in local.
+func Intersect(local [][]byte, conn net.Conn) ([][]byte, error) {
+	return intersect(rand.Reader, local, conn)
+}
+
+func intersect(rand io.Reader, local [][]byte, conn io.ReadWriter) ([][]byte, error) {
+	c := ec.P256()
+	items := dedupe(local)
+	if len(items) > MaxSetSize {
+		return nil, fmt.Errorf("psi: set of %d items is larger than %d", len(items), MaxSetSize)
+	}
+	a, err := c.RandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	perm, err := shuffle(rand, len(items))
+	if err != nil {
+		return nil, err
+	}
+	masked := make([][]byte, len(items))
+	for i, index := range perm {
+		p, err := c.HashToCurve(sha256.New, items[index], dst)
+		if err != nil {
+			return nil, err
+		}
+		masked[i] = c.Marshal(c.ScalarMult(p, a))
+	}
+	peer, err := exchange(conn, c, masked)
+	if err != nil {
+		return nil, err
+	}
+	doubled := make([][]byte, len(peer))
+	peerSet := make(map[string]bool, len(peer))
+	for i, b := range peer {
+		p, err := c.Unmarshal(b)
+		if err != nil {
+			return nil, err
+		}
+		if p.IsInfinity() {
+			return nil, errors.New("psi: peer sent the point at infinity")
+		}
+		doubled[i] = c.Marshal(c.ScalarMult(p, a))
+		peerSet[string(doubled[i])] = true
+	}
+	mine, err := exchange(conn, c, doubled)
+	if err != nil {
+		return nil, err
+	}
+	if len(mine) != len(masked) {
+		return nil, fmt.Errorf("psi: peer returned %d points instead of %d", len(mine), len(masked))
+	}
+	found := make([]bool, len(items))
+	for i, b := range mine {
+		found[perm[i]] = peerSet[string(b)]
+	}
+	var out [][]byte
+	for i, item := range items {
+		if found[i] {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+// dedupe returns the distinct items of set in their order of appearance
+func dedupe(set [][]byte) [][]byte {
+	seen := make(map[string]bool, len(set))
+	var out [][]byte
+	for _, item := range set {
+		if seen[string(item)] {
+			continue
+		}
+		seen[string(item)] = true
+		out = append(out, item)
+	}
+	return out
+}
+
+// shuffle returns a uniformly random permutation of [0, n) using a
+// Fisher-Yates shuffle
+func shuffle(rand io.Reader, n int) ([]int, error) {
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	for i := n - 1; i > 0; i-- {
+		j, err := randInt(rand, i+1)
+		if err != nil {
+			return nil, err
+		}
+		perm[i], perm[j] = perm[j], perm[i]
+	}
+	return perm, nil
+}
+
+// randInt returns a uniform integer in [0, max) by rejection sampling
+func randInt(rand io.Reader, max int) (int, error) {
+	bound := big.NewInt(int64(max))
+	buf := make([]byte, (bound.BitLen()+7)/8+1)
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(8*len(buf)))
+	limit.Sub(limit, new(big.Int).Mod(limit, bound))
+	for {
+		if _, err := io.ReadFull(rand, buf); err != nil {
+			return 0, err
+		}
+		v := new(big.Int).SetBytes(buf)
+		if v.Cmp(limit) < 0 {
+			return int(v.Mod(v, bound).Int64()), nil
+		}
+	}
+}
+
+// exchange sends points to the peer while reading the points it sends,
+// so that both parties can write first without deadlocking on
+// unbuffered connections
+func exchange(conn io.ReadWriter, c *ec.Curve, points [][]byte) ([][]byte, error) {
+	size := 1 + c.ByteLen()
+	errc := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 4, 4+len(points)*size)
+		binary.BigEndian.PutUint32(buf, uint32(len(points)))
+		for _, p := range points {
+			buf = append(buf, p...)
+		}
+		_, err := conn.Write(buf)
+		errc <- err
+	}()
+	peer, err := readPoints(conn, size)
+	if err != nil {
+		// the writer may be stuck on a peer that stopped reading, errc
+		// is buffered so it exits whenever the write returns
+		return nil, err
+	}
+	if err := <-errc; err != nil {
+		return nil, err
+	}
+	return peer, nil
+}
+
+// readPoints reads a count prefixed list of compressed points
+func readPoints(r io.Reader, size int) ([][]byte, error) {
+	var l [4]byte
+	if _, err := io.ReadFull(r, l[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(l[:])
+	if n > MaxSetSize {
+		return nil, fmt.Errorf("psi: peer set of %d items is larger than %d", n, MaxSetSize)
+	}
+	points := make([][]byte, n)
+	for i := range points {
+		points[i] = make([]byte, size)
+		if _, err := io.ReadFull(r, points[i]); err != nil {
+			return nil, err
+		}
+	}
+	return points, nil
+}
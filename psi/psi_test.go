@@ -0,0 +1,110 @@
+package psi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+)
+
+func toItems(s ...string) [][]byte {
+	var out [][]byte
+	for _, v := range s {
+		out = append(out, []byte(v))
+	}
+	return out
+}
+
+func TestIntersect(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		alice, bob, expected []string
+	}{
+		{
+			[]string{"alice@example.net", "bob@example.net", "carol@example.net", "dave@example.net"},
+			[]string{"erin@example.net", "carol@example.net", "alice@example.net"},
+			[]string{"alice@example.net", "carol@example.net"},
+		},
+		{[]string{"a", "b"}, []string{"c", "d"}, nil},
+		{[]string{"a", "a", "b"}, []string{"b", "a", "b"}, []string{"a", "b"}},
+		{nil, []string{"a"}, nil},
+		{nil, nil, nil},
+	}
+	for i, tc := range testcases {
+		c1, c2 := net.Pipe()
+		type result struct {
+			out [][]byte
+			err error
+		}
+		done := make(chan result)
+		go func() {
+			out, err := Intersect(toItems(tc.bob...), c2)
+			done <- result{out, err}
+		}()
+		alice, err := Intersect(toItems(tc.alice...), c1)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		bob := <-done
+		if bob.err != nil {
+			t.Fatalf("testcase %d failed on the peer with %v", i, bob.err)
+		}
+		c1.Close()
+		c2.Close()
+		expected := toItems(tc.expected...)
+		if len(alice) != len(expected) {
+			t.Fatalf("testcase %d expected %q but got %q", i, expected, alice)
+		}
+		for j := range alice {
+			if !bytes.Equal(alice[j], expected[j]) {
+				t.Fatalf("testcase %d expected %q but got %q", i, expected, alice)
+			}
+		}
+		// the peer learns the same intersection, in its own order
+		if len(bob.out) != len(expected) {
+			t.Fatalf("testcase %d expected the peer to find %d items but got %q", i, len(expected), bob.out)
+		}
+	}
+}
+
+func TestInvalidPeer(t *testing.T) {
+	t.Parallel()
+	var testcases = [][]byte{
+		// a point that does not decode
+		append([]byte{0, 0, 0, 1}, make([]byte, 33)...),
+		// a set larger than MaxSetSize
+		{0xff, 0xff, 0xff, 0xff},
+		// a truncated set
+		{0, 0, 0, 2, 2},
+	}
+	for i, tc := range testcases {
+		c1, c2 := net.Pipe()
+		go func(msg []byte) {
+			var l [4]byte
+			c2.Read(l[:])
+			buf := make([]byte, 33*binary.BigEndian.Uint32(l[:]))
+			c2.Read(buf)
+			c2.Write(msg)
+			c2.Close()
+		}(tc)
+		if _, err := Intersect(toItems("a"), c1); err == nil {
+			t.Fatalf("testcase %d expected an error but got none", i)
+		}
+		c1.Close()
+	}
+}
+
+func TestShuffle(t *testing.T) {
+	t.Parallel()
+	perm, err := shuffle(bytes.NewReader(bytes.Repeat([]byte{0x5a, 0xa5, 0x3c}, 100)), 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[int]bool)
+	for _, v := range perm {
+		if v < 0 || v >= 50 || seen[v] {
+			t.Fatalf("expected a permutation but got %v", perm)
+		}
+		seen[v] = true
+	}
+}
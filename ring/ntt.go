@@ -0,0 +1,151 @@
+package ring
+
+// nttTables holds the roots of the transform of a ring. omega is a
+// primitive n-th root of unity modulo q, and the negacyclic ring also
+// needs psi, a square root of omega and so a primitive 2n-th root: x^n + 1
+// vanishes at the odd powers of psi.
+type nttTables struct {
+	// omegas[i] is omega^i and omegasInv[i] omega^-i
+	omegas, omegasInv []int64
+	// psis[i] is psi^i and psisInv[i] psi^-i, nil in the cyclic ring
+	psis, psisInv []int64
+	// nInv is n^-1 mod q
+	nInv int64
+}
+
+// powMod returns x^e mod m by square and multiply
+func powMod(x, e, m int64) int64 {
+	result := int64(1)
+	x %= m
+	for ; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			result = result * x % m
+		}
+		x = x * x % m
+	}
+	return result
+}
+
+// powers returns x^0 up to x^(n-1) mod q
+func powers(x int64, n int, q int64) []int64 {
+	p := make([]int64, n)
+	p[0] = 1
+	for i := 1; i < n; i++ {
+		p[i] = p[i-1] * x % q
+	}
+	return p
+}
+
+// newNTTTables returns the tables of the ring, or nil if it has no NTT:
+// n must be a power of two and q a prime with a primitive n-th, or 2n-th,
+// root of unity, which exists when that order divides q - 1
+func newNTTTables(n int, q int64, negacyclic bool) *nttTables {
+	order := int64(n)
+	if negacyclic {
+		order *= 2
+	}
+	if n&(n-1) != 0 || q < 2 || smallestFactor(q) != q || (q-1)%order != 0 {
+		return nil
+	}
+	// g^((q-1)/order) has an order that divides the power of two order,
+	// and it is exactly order unless its power order/2 is 1
+	root := int64(1)
+	for g := int64(2); order > 1 && g < q; g++ {
+		root = powMod(g, (q-1)/order, q)
+		if powMod(root, order/2, q) == q-1 {
+			break
+		}
+	}
+	t := &nttTables{nInv: powMod(int64(n), q-2, q)}
+	omega := root
+	if negacyclic {
+		omega = root * root % q
+		t.psis = powers(root, n, q)
+		t.psisInv = powers(powMod(root, q-2, q), n, q)
+	}
+	t.omegas = powers(omega, n, q)
+	t.omegasInv = powers(powMod(omega, q-2, q), n, q)
+	return t
+}
+
+// NTT returns the evaluations of a at the n roots of the modulus
+// polynomial: a(omega^i) in the cyclic ring, and a(psi omega^i) =
+// a(psi^(2i+1)) in the negacyclic one. Multiplying coefficient i of a by
+// psi^i first turns the negacyclic transform into a cyclic one. NTT
+// panics if r has no NTT.
+func (r *Ring) NTT(a Poly) Poly {
+	r.check(a)
+	if r.ntt == nil {
+		panic("ring: " + r.String() + " has no NTT")
+	}
+	f := make(Poly, r.n)
+	copy(f, a)
+	if r.negacyclic {
+		for i := range f {
+			f[i] = f[i] * r.ntt.psis[i] % r.q
+		}
+	}
+	r.transform(f, r.ntt.omegas)
+	return f
+}
+
+// InverseNTT returns the polynomial whose NTT is f: the transform with the
+// inverse roots, divided by n, then the coefficients divided by psi^i in
+// the negacyclic ring
+func (r *Ring) InverseNTT(f Poly) Poly {
+	r.check(f)
+	if r.ntt == nil {
+		panic("ring: " + r.String() + " has no NTT")
+	}
+	a := make(Poly, r.n)
+	copy(a, f)
+	r.transform(a, r.ntt.omegasInv)
+	for i := range a {
+		a[i] = a[i] * r.ntt.nInv % r.q
+		if r.negacyclic {
+			a[i] = a[i] * r.ntt.psisInv[i] % r.q
+		}
+	}
+	return a
+}
+
+// MulNTT returns the product of two polynomials in the NTT domain, the
+// products of their evaluations
+func (r *Ring) MulNTT(f, g Poly) Poly {
+	r.check(f, g)
+	h := make(Poly, r.n)
+	for i := range h {
+		h[i] = f[i] * g[i] % r.q
+	}
+	return h
+}
+
+// transform replaces f with its evaluations at the powers of the root of
+// the table roots, with the iterative Cooley-Tukey algorithm: the
+// coefficients are put in bit reversed order, then each of the log n
+// layers combines the transforms of the even and odd coefficients of
+// blocks twice as large with the butterfly (u + w v, u - w v)
+func (r *Ring) transform(f Poly, roots []int64) {
+	n := len(f)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			f[i], f[j] = f[j], f[i]
+		}
+	}
+	for length := 2; length <= n; length <<= 1 {
+		step := n / length
+		for start := 0; start < n; start += length {
+			for k := 0; k < length/2; k++ {
+				w := roots[k*step]
+				u, v := f[start+k], f[start+k+length/2]*w%r.q
+				f[start+k] = (u + v) % r.q
+				f[start+k+length/2] = (u - v + r.q) % r.q
+			}
+		}
+	}
+}
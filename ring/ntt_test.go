@@ -0,0 +1,101 @@
+package ring
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func randomPoly(rng *rand.Rand, r *Ring) Poly {
+	a := r.Zero()
+	for i := range a {
+		a[i] = rng.Int63n(r.Q())
+	}
+	return a
+}
+
+// eval returns a(x) mod q with Horner's method
+func eval(a Poly, x, q int64) int64 {
+	y := int64(0)
+	for i := len(a) - 1; i >= 0; i-- {
+		y = (y*x + a[i]) % q
+	}
+	return y
+}
+
+func TestNTTEvaluates(t *testing.T) {
+	t.Parallel()
+	rng := rand.New(rand.NewSource(1))
+	for _, negacyclic := range []bool{false, true} {
+		newRing := NewCyclic
+		if negacyclic {
+			newRing = NewNegacyclic
+		}
+		r, _ := newRing(16, 97)
+		a := randomPoly(rng, r)
+		f := r.NTT(a)
+		for i := range f {
+			// the roots of x^16 - 1 are omega^i, those of x^16 + 1 psi^(2i+1)
+			root := r.ntt.omegas[1]
+			e := int64(i)
+			if negacyclic {
+				root, e = r.ntt.psis[1], int64(2*i+1)
+			}
+			x := powMod(root, e, r.Q())
+			if y := eval(a, x, r.Q()); f[i] != y {
+				t.Fatalf("negacyclic=%t expected a(%d) = %d but got %d", negacyclic, x, y, f[i])
+			}
+			if x16 := powMod(x, 16, r.Q()); negacyclic && x16 != r.Q()-1 || !negacyclic && x16 != 1 {
+				t.Fatalf("negacyclic=%t expected %d to be a root of the modulus but got x^16 = %d", negacyclic, x, x16)
+			}
+		}
+	}
+}
+
+func TestNTTMul(t *testing.T) {
+	t.Parallel()
+	rng := rand.New(rand.NewSource(1))
+	for i, tc := range []struct {
+		n          int
+		q          int64
+		negacyclic bool
+	}{
+		{1, 5, false},
+		{2, 5, true},
+		{256, 7681, false},
+		{256, 7681, true},
+		{256, 8380417, true},
+		{1024, 12289, true},
+	} {
+		newRing := NewCyclic
+		if tc.negacyclic {
+			newRing = NewNegacyclic
+		}
+		r, _ := newRing(tc.n, tc.q)
+		if !r.HasNTT() {
+			t.Fatalf("testcase %d expected %s to have an NTT", i, r)
+		}
+		a, b := randomPoly(rng, r), randomPoly(rng, r)
+		if p := r.InverseNTT(r.NTT(a)); !p.Equal(a) {
+			t.Fatalf("testcase %d NTT does not invert", i)
+		}
+		if p := r.Mul(a, b); !p.Equal(r.MulSchoolbook(a, b)) {
+			t.Fatalf("testcase %d NTT product differs from the schoolbook one", i)
+		}
+	}
+}
+
+func BenchmarkMul(b *testing.B) {
+	r, _ := NewNegacyclic(1024, 12289)
+	rng := rand.New(rand.NewSource(1))
+	x, y := randomPoly(rng, r), randomPoly(rng, r)
+	b.Run("ntt", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			r.Mul(x, y)
+		}
+	})
+	b.Run("schoolbook", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			r.MulSchoolbook(x, y)
+		}
+	})
+}
@@ -0,0 +1,405 @@
+// Package ring implements arithmetic in the polynomial rings of lattice
+// cryptography: the convolution ring Z[x]/(x^n - 1) of NTRU, where x^n
+// wraps around to 1, and the negacyclic ring Zq[x]/(x^n + 1) of Kyber,
+// Dilithium and Falcon, where x^n wraps around to -1.
+//
+// Multiplying two polynomials with the schoolbook method takes n^2
+// coefficient products. When q is a prime such that q - 1 is a multiple
+// of n, or 2n in the negacyclic ring, and n is a power of two, the number
+// theoretic transform evaluates a polynomial at the n roots of x^n - 1 or
+// x^n + 1 in n log n operations, like the FFT does over the complex
+// numbers. A product is then n products of evaluations, and the inverse
+// transform interpolates it back into a polynomial. Mul uses the NTT
+// whenever the ring has one.
+//
+// Coefficients are int64 and the modulus is below 2^31, so that products
+// never overflow. Without a modulus, in Z[x]/(x^n - 1), nothing prevents
+// large coefficients from overflowing.
+package ring
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxModulus bounds the modulus so that the product of two coefficients
+// fits in an int64
+const MaxModulus = 1 << 31
+
+// Ring is Z[x]/(x^n - 1), Zq[x]/(x^n - 1) or Zq[x]/(x^n + 1)
+type Ring struct {
+	n          int
+	q          int64
+	negacyclic bool
+	// ntt is nil when the ring has no number theoretic transform
+	ntt *nttTables
+}
+
+// Poly is a polynomial of a ring, whose i-th element is the coefficient of
+// x^i. It has exactly n coefficients, in [0, q) when the ring has a
+// modulus.
+type Poly []int64
+
+// NewCyclic returns the convolution ring Zq[x]/(x^n - 1), or Z[x]/(x^n - 1)
+// if q is 0
+func NewCyclic(n int, q int64) (*Ring, error) {
+	if q == 1 || q < 0 {
+		return nil, errors.New("ring: the modulus must be 0 or at least 2")
+	}
+	return newRing(n, q, false)
+}
+
+// NewNegacyclic returns the ring Zq[x]/(x^n + 1)
+func NewNegacyclic(n int, q int64) (*Ring, error) {
+	if q < 2 {
+		return nil, errors.New("ring: the modulus must be at least 2")
+	}
+	return newRing(n, q, true)
+}
+
+func newRing(n int, q int64, negacyclic bool) (*Ring, error) {
+	if n < 1 {
+		return nil, errors.New("ring: the degree must be positive")
+	}
+	if q >= MaxModulus {
+		return nil, fmt.Errorf("ring: the modulus must be below %d", int64(MaxModulus))
+	}
+	r := &Ring{n: n, q: q, negacyclic: negacyclic}
+	r.ntt = newNTTTables(n, q, negacyclic)
+	return r, nil
+}
+
+// N returns the degree n of the modulus polynomial, and the number of
+// coefficients of the polynomials of r
+func (r *Ring) N() int {
+	return r.n
+}
+
+// Q returns the modulus of the coefficients, 0 in Z[x]/(x^n - 1)
+func (r *Ring) Q() int64 {
+	return r.q
+}
+
+// Negacyclic returns true if x^n is -1 in r, and false if it is 1
+func (r *Ring) Negacyclic() bool {
+	return r.negacyclic
+}
+
+// HasNTT returns true if r has a number theoretic transform
+func (r *Ring) HasNTT() bool {
+	return r.ntt != nil
+}
+
+// String describes r such as Z_3329[x]/(x^256 + 1)
+func (r *Ring) String() string {
+	z, sign := "Z", "-"
+	if r.q != 0 {
+		z = fmt.Sprintf("Z_%d", r.q)
+	}
+	if r.negacyclic {
+		sign = "+"
+	}
+	return fmt.Sprintf("%s[x]/(x^%d %s 1)", z, r.n, sign)
+}
+
+// reduce returns c mod q in [0, q), or c if r has no modulus
+func (r *Ring) reduce(c int64) int64 {
+	if r.q == 0 {
+		return c
+	}
+	c %= r.q
+	if c < 0 {
+		c += r.q
+	}
+	return c
+}
+
+// New returns the polynomial with coefficients coeffs, from x^0 up. The
+// coefficients are reduced modulo q, and those of x^n and above wrap
+// around to x^0, negated in the negacyclic ring.
+func (r *Ring) New(coeffs ...int64) Poly {
+	p := make(Poly, r.n)
+	for i, c := range coeffs {
+		if r.negacyclic && i/r.n%2 == 1 {
+			c = -c
+		}
+		p[i%r.n] = r.reduce(p[i%r.n] + r.reduce(c))
+	}
+	return p
+}
+
+// Zero returns the zero polynomial
+func (r *Ring) Zero() Poly {
+	return make(Poly, r.n)
+}
+
+// One returns the polynomial 1
+func (r *Ring) One() Poly {
+	return r.New(1)
+}
+
+// check panics if a does not have n coefficients, as indexing it would
+// either panic or silently ignore coefficients
+func (r *Ring) check(polys ...Poly) {
+	for _, a := range polys {
+		if len(a) != r.n {
+			panic(fmt.Sprintf("ring: polynomial of %d coefficients in %s", len(a), r))
+		}
+	}
+}
+
+// Equal returns true if a and b have the same coefficients
+func (a Poly) Equal(b Poly) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats a such as 3x^2 + x + 5
+func (a Poly) String() string {
+	var terms []string
+	for i := len(a) - 1; i >= 0; i-- {
+		c := a[i]
+		if c == 0 {
+			continue
+		}
+		coeff := fmt.Sprint(c)
+		if i > 0 && (c == 1 || c == -1) {
+			coeff = coeff[:len(coeff)-1]
+		}
+		switch i {
+		case 0:
+			terms = append(terms, coeff)
+		case 1:
+			terms = append(terms, coeff+"x")
+		default:
+			terms = append(terms, fmt.Sprintf("%sx^%d", coeff, i))
+		}
+	}
+	if len(terms) == 0 {
+		return "0"
+	}
+	return strings.Join(terms, " + ")
+}
+
+// Add returns a + b
+func (r *Ring) Add(a, b Poly) Poly {
+	r.check(a, b)
+	sum := make(Poly, r.n)
+	for i := range sum {
+		sum[i] = r.reduce(a[i] + b[i])
+	}
+	return sum
+}
+
+// Sub returns a - b
+func (r *Ring) Sub(a, b Poly) Poly {
+	r.check(a, b)
+	diff := make(Poly, r.n)
+	for i := range diff {
+		diff[i] = r.reduce(a[i] - b[i])
+	}
+	return diff
+}
+
+// Neg returns -a
+func (r *Ring) Neg(a Poly) Poly {
+	return r.Sub(r.Zero(), a)
+}
+
+// Scale returns c * a
+func (r *Ring) Scale(a Poly, c int64) Poly {
+	r.check(a)
+	c = r.reduce(c)
+	scaled := make(Poly, r.n)
+	for i := range scaled {
+		scaled[i] = r.reduce(a[i] * c)
+	}
+	return scaled
+}
+
+// Mul returns a * b, with the NTT if r has one and the schoolbook method
+// otherwise
+func (r *Ring) Mul(a, b Poly) Poly {
+	if r.ntt != nil {
+		return r.InverseNTT(r.MulNTT(r.NTT(a), r.NTT(b)))
+	}
+	return r.MulSchoolbook(a, b)
+}
+
+// MulSchoolbook returns a * b with the n^2 products of the coefficients of
+// a and b: the product of x^i and x^j lands at x^(i+j-n) once i+j reaches
+// n, negated in the negacyclic ring
+func (r *Ring) MulSchoolbook(a, b Poly) Poly {
+	r.check(a, b)
+	product := make(Poly, r.n)
+	for i := range a {
+		if a[i] == 0 {
+			continue
+		}
+		for j := range b {
+			c := a[i] * b[j]
+			k := i + j
+			if k >= r.n {
+				k -= r.n
+				if r.negacyclic {
+					c = -c
+				}
+			}
+			product[k] = r.reduce(product[k] + r.reduce(c))
+		}
+	}
+	return product
+}
+
+// Centered returns the coefficients of a in (-q/2, q/2], the smallest
+// integers congruent to them, as NTRU decryption needs before reducing
+// modulo another modulus
+func (r *Ring) Centered(a Poly) Poly {
+	r.check(a)
+	centered := make(Poly, r.n)
+	for i, c := range a {
+		c = r.reduce(c)
+		if r.q != 0 && c > r.q/2 {
+			c -= r.q
+		}
+		centered[i] = c
+	}
+	return centered
+}
+
+// ErrNotInvertible is returned when a polynomial has no inverse in a ring
+var ErrNotInvertible = errors.New("ring: the polynomial is not invertible")
+
+// Inverse returns a^-1, for a ring whose modulus is a prime p or a power
+// of a prime such as the 2048 of NTRU. The inverse modulo p comes from the
+// extended Euclidean algorithm in Zp[x], and Newton's iteration b(2 - ab)
+// lifts it modulo p^2, p^4 and so on up to q.
+func (r *Ring) Inverse(a Poly) (Poly, error) {
+	r.check(a)
+	p := smallestFactor(r.q)
+	pk := p
+	for pk < r.q {
+		pk *= p
+	}
+	if r.q < 2 || pk != r.q {
+		return nil, errors.New("ring: inverses need a modulus that is a prime power")
+	}
+
+	// the modulus polynomial x^n - 1 or x^n + 1, modulo p
+	m := make([]int64, r.n+1)
+	m[0], m[r.n] = p-1, 1
+	if r.negacyclic {
+		m[0] = 1
+	}
+	f := make([]int64, r.n)
+	for i, c := range a {
+		f[i] = c % p
+	}
+	inv, err := invertModP(f, m, p)
+	if err != nil {
+		return nil, err
+	}
+	b := r.New(inv...)
+	two := r.New(2)
+	for k := p; k < r.q; k *= k {
+		b = r.Mul(b, r.Sub(two, r.Mul(a, b)))
+	}
+	return b, nil
+}
+
+// smallestFactor returns the smallest prime factor of q by trial division
+func smallestFactor(q int64) int64 {
+	for p := int64(2); p*p <= q; p++ {
+		if q%p == 0 {
+			return p
+		}
+	}
+	return q
+}
+
+// trim drops the leading zero coefficients of a
+func trim(a []int64) []int64 {
+	for len(a) > 0 && a[len(a)-1] == 0 {
+		a = a[:len(a)-1]
+	}
+	return a
+}
+
+// invModP returns c^-1 mod p by Fermat's little theorem
+func invModP(c, p int64) int64 {
+	return powMod(c, p-2, p)
+}
+
+// invertModP returns the inverse of f modulo m and the prime p. The
+// extended Euclidean algorithm keeps u*f = r0 and v*f = r1 modulo m while
+// it reduces the remainders, and the last non zero remainder is a
+// constant only if f and m are coprime.
+func invertModP(f, m []int64, p int64) ([]int64, error) {
+	r0, r1 := trim(append([]int64{}, m...)), trim(f)
+	u, v := []int64{}, []int64{1}
+	for len(r1) > 0 {
+		q, rem := divModP(r0, r1, p)
+		r0, r1 = r1, rem
+		u, v = v, subModP(u, mulModP(q, v, p), p)
+	}
+	if len(r0) != 1 {
+		return nil, ErrNotInvertible
+	}
+	c := invModP(r0[0], p)
+	for i := range u {
+		u[i] = u[i] * c % p
+	}
+	return u, nil
+}
+
+// divModP returns the quotient and remainder of a by b modulo p
+func divModP(a, b []int64, p int64) (q, rem []int64) {
+	rem = append([]int64{}, a...)
+	inv := invModP(b[len(b)-1], p)
+	if len(rem) >= len(b) {
+		q = make([]int64, len(rem)-len(b)+1)
+	}
+	for len(rem) >= len(b) {
+		shift := len(rem) - len(b)
+		c := rem[len(rem)-1] * inv % p
+		q[shift] = c
+		for i := range b {
+			rem[shift+i] = ((rem[shift+i]-c*b[i])%p + p) % p
+		}
+		rem = trim(rem)
+	}
+	return q, rem
+}
+
+func mulModP(a, b []int64, p int64) []int64 {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	product := make([]int64, len(a)+len(b)-1)
+	for i := range a {
+		for j := range b {
+			product[i+j] = (product[i+j] + a[i]*b[j]) % p
+		}
+	}
+	return trim(product)
+}
+
+func subModP(a, b []int64, p int64) []int64 {
+	diff := make([]int64, len(a))
+	copy(diff, a)
+	for len(diff) < len(b) {
+		diff = append(diff, 0)
+	}
+	for i := range b {
+		diff[i] = ((diff[i]-b[i])%p + p) % p
+	}
+	return trim(diff)
+}
@@ -0,0 +1,191 @@
+package ring
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestString(t *testing.T) {
+	t.Parallel()
+	cyclic, _ := NewCyclic(5, 0)
+	negacyclic, _ := NewNegacyclic(256, 3329)
+	if s := cyclic.String(); s != "Z[x]/(x^5 - 1)" {
+		t.Fatalf("expected Z[x]/(x^5 - 1) but got %q", s)
+	}
+	if s := negacyclic.String(); s != "Z_3329[x]/(x^256 + 1)" {
+		t.Fatalf("expected Z_3329[x]/(x^256 + 1) but got %q", s)
+	}
+	var testcases = []struct {
+		p        Poly
+		expected string
+	}{
+		{cyclic.Zero(), "0"},
+		{cyclic.New(5), "5"},
+		{cyclic.New(5, 1, 3), "3x^2 + x + 5"},
+		{cyclic.New(-1, 0, 0, -1), "-x^3 + -1"},
+	}
+	for i, tc := range testcases {
+		if s := tc.p.String(); s != tc.expected {
+			t.Fatalf("testcase %d expected %q but got %q", i, tc.expected, s)
+		}
+	}
+}
+
+func TestNewRing(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		n          int
+		q          int64
+		negacyclic bool
+		valid      bool
+		ntt        bool
+	}{
+		{256, 0, false, true, false},
+		{509, 2048, false, true, false},
+		{256, 7681, false, true, true},
+		{256, 7681, true, true, true},
+		{256, 3329, true, true, false},
+		{256, 8380417, true, true, true},
+		{1024, 12289, true, true, true},
+		{1, 5, false, true, true},
+		{0, 7681, true, false, false},
+		{256, 0, true, false, false},
+		{256, 1, false, false, false},
+		{256, MaxModulus, true, false, false},
+	}
+	for i, tc := range testcases {
+		newRing := NewCyclic
+		if tc.negacyclic {
+			newRing = NewNegacyclic
+		}
+		r, err := newRing(tc.n, tc.q)
+		if (err == nil) != tc.valid {
+			t.Fatalf("testcase %d expected valid=%t but got error %v", i, tc.valid, err)
+		}
+		if err == nil && r.HasNTT() != tc.ntt {
+			t.Fatalf("testcase %d expected ntt=%t but got %t", i, tc.ntt, r.HasNTT())
+		}
+	}
+}
+
+func TestWrapAround(t *testing.T) {
+	t.Parallel()
+	cyclic, _ := NewCyclic(4, 17)
+	negacyclic, _ := NewNegacyclic(4, 17)
+	x, x3 := cyclic.New(0, 1), cyclic.New(0, 0, 0, 1)
+	if p := cyclic.Mul(x3, x); !p.Equal(cyclic.One()) {
+		t.Fatalf("expected x^4 = 1 but got %s", p)
+	}
+	if p := negacyclic.Mul(x3, x); !p.Equal(negacyclic.New(-1)) {
+		t.Fatalf("expected x^4 = -1 but got %s", p)
+	}
+	// New folds the coefficients of x^4 and above
+	if p := cyclic.New(1, 2, 3, 4, 5, 6); !p.Equal(cyclic.New(6, 8, 3, 4)) {
+		t.Fatalf("expected 4x^3 + 3x^2 + 8x + 6 but got %s", p)
+	}
+	if p := negacyclic.New(1, 2, 3, 4, 5, 6, 0, 0, 7); !p.Equal(negacyclic.New(3, -4, 3, 4)) {
+		t.Fatalf("expected 4x^3 + 3x^2 + 13x + 3 but got %s", p)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	t.Parallel()
+	r, _ := NewCyclic(3, 0)
+	a, b := r.New(1, 2, 3), r.New(-4, 5, 6)
+	if p := r.Add(a, b); !p.Equal(r.New(-3, 7, 9)) {
+		t.Fatalf("expected 9x^2 + 7x + -3 but got %s", p)
+	}
+	if p := r.Sub(a, b); !p.Equal(r.New(5, -3, -3)) {
+		t.Fatalf("expected -3x^2 + -3x + 5 but got %s", p)
+	}
+	if p := r.Neg(a); !p.Equal(r.New(-1, -2, -3)) {
+		t.Fatalf("expected -3x^2 + -2x + -1 but got %s", p)
+	}
+	if p := r.Scale(a, 3); !p.Equal(r.New(3, 6, 9)) {
+		t.Fatalf("expected 9x^2 + 6x + 3 but got %s", p)
+	}
+	// (1 + 2x + 3x^2)(-4 + 5x + 6x^2) = -4 - 3x + 4x^2 + 27x^3 + 18x^4
+	if p := r.Mul(a, b); !p.Equal(r.New(-4+27, -3+18, 4)) {
+		t.Fatalf("expected 4x^2 + 15x + 23 but got %s", p)
+	}
+}
+
+func TestCentered(t *testing.T) {
+	t.Parallel()
+	r, _ := NewCyclic(4, 32)
+	if p := r.Centered(r.New(0, 16, 17, 31)); !p.Equal(Poly{0, 16, -15, -1}) {
+		t.Fatalf("expected [0 16 -15 -1] but got %v", p)
+	}
+}
+
+// TestNTRUInverse uses the example of the NTRU book of Hoffstein, Pipher
+// and Silverman: N = 11, q = 32 and p = 3
+func TestNTRUInverse(t *testing.T) {
+	t.Parallel()
+	rq, _ := NewCyclic(11, 32)
+	rp, _ := NewCyclic(11, 3)
+	f := []int64{-1, 1, 1, 0, -1, 0, 1, 0, 0, 1, -1}
+	fq, err := rq.Inverse(rq.New(f...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := rq.New(5, 9, 6, 16, 4, 15, 16, 22, 20, 18, 30); !fq.Equal(expected) {
+		t.Fatalf("expected f^-1 mod 32 = %s but got %s", expected, fq)
+	}
+	fp, err := rp.Inverse(rp.New(f...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := rp.New(1, 2, 0, 2, 2, 1, 0, 2, 1, 2, 0); !fp.Equal(expected) {
+		t.Fatalf("expected f^-1 mod 3 = %s but got %s", expected, fp)
+	}
+}
+
+func TestInverse(t *testing.T) {
+	t.Parallel()
+	rng := rand.New(rand.NewSource(1))
+	for i, tc := range []struct {
+		n          int
+		q          int64
+		negacyclic bool
+	}{
+		{16, 2, false},
+		{17, 7, false},
+		{64, 7681, true},
+		{64, 3329, true},
+		{101, 2048, false},
+		{32, 81, true},
+	} {
+		newRing := NewCyclic
+		if tc.negacyclic {
+			newRing = NewNegacyclic
+		}
+		r, _ := newRing(tc.n, tc.q)
+		for j := 0; j < 5; j++ {
+			a := r.Zero()
+			for k := range a {
+				a[k] = rng.Int63n(tc.q)
+			}
+			inv, err := r.Inverse(a)
+			if err == ErrNotInvertible {
+				continue
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p := r.Mul(a, inv); !p.Equal(r.One()) {
+				t.Fatalf("testcase %d expected a * a^-1 = 1 but got %s", i, p)
+			}
+		}
+	}
+
+	// x^n - 1 is a multiple of x - 1
+	r, _ := NewCyclic(11, 3)
+	if _, err := r.Inverse(r.New(-1, 1)); err != ErrNotInvertible {
+		t.Fatalf("expected ErrNotInvertible but got %v", err)
+	}
+	r, _ = NewCyclic(11, 6)
+	if _, err := r.Inverse(r.One()); err == nil {
+		t.Fatal("expected an error for a modulus that is not a prime power")
+	}
+}
@@ -0,0 +1,188 @@
+// Package ringsig implements ring signatures over the curves of the ec
+// package: a signer proves that it holds the private key of one of the
+// public keys of a ring without revealing which one.
+//
+// Sign produces linkable signatures (LSAG, Liu, Wei and Wong 2004, in the
+// form used by CryptoNote). They carry a key image I = x*Hp(P) which only
+// depends on the signing key, so two signatures by the same key, even on
+// different rings, can be detected with Linked. SignUnlinkable produces
+// the plain SAG variant without a key image.
+package ringsig
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+const (
+	sagDomain      = "badcrypto SAG v1"
+	lsagDomain     = "badcrypto LSAG v1"
+	keyImageDomain = "badcrypto LSAG key image v1"
+)
+
+// PrivateKey is a ring member signing key
+type PrivateKey struct {
+	Curve  *ec.Curve
+	D      *big.Int
+	Public *ec.Point
+}
+
+// GenerateKey returns a new random signing key on c
+func GenerateKey(rand io.Reader, c *ec.Curve) (*PrivateKey, error) {
+	d, err := c.RandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{Curve: c, D: d, Public: c.ScalarBaseMult(d)}, nil
+}
+
+// KeyImage returns x*Hp(P), the tag that links all the signatures of the key
+func (k *PrivateKey) KeyImage() *ec.Point {
+	return k.Curve.ScalarMult(hashPoint(k.Curve, k.Public), k.D)
+}
+
+// Signature is a ring signature: the challenge of the first ring member
+// and one response per member. KeyImage is nil for unlinkable signatures.
+type Signature struct {
+	C0       *big.Int
+	S        []*big.Int
+	KeyImage *ec.Point
+}
+
+func hashPoint(c *ec.Curve, p *ec.Point) *ec.Point {
+	return c.HashToPoint(keyImageDomain, c.Marshal(p))
+}
+
+// ringPrefix encodes the ring and the key image, if any, which every
+// challenge commits to
+func ringPrefix(c *ec.Curve, ring []*ec.Point, keyImage *ec.Point) []byte {
+	var out []byte
+	for _, p := range ring {
+		out = append(out, c.Marshal(p)...)
+	}
+	if keyImage != nil {
+		out = append(out, c.Marshal(keyImage)...)
+	}
+	return out
+}
+
+// challenge hashes the commitments of one ring member into the challenge
+// of the next one. R is nil for unlinkable signatures.
+func challenge(c *ec.Curve, prefix, msg []byte, L, R *ec.Point) *big.Int {
+	if R == nil {
+		return c.HashToScalar(sagDomain, prefix, msg, c.Marshal(L))
+	}
+	return c.HashToScalar(lsagDomain, prefix, msg, c.Marshal(L), c.Marshal(R))
+}
+
+// Sign produces a linkable ring signature of msg by key, whose public key
+// must be one of the members of ring
+func Sign(rand io.Reader, key *PrivateKey, ring []*ec.Point, msg []byte) (*Signature, error) {
+	return sign(rand, key, ring, msg, true)
+}
+
+// SignUnlinkable produces a ring signature of msg by key that carries no
+// key image and cannot be linked to other signatures by the same key
+func SignUnlinkable(rand io.Reader, key *PrivateKey, ring []*ec.Point, msg []byte) (*Signature, error) {
+	return sign(rand, key, ring, msg, false)
+}
+
+func sign(rand io.Reader, key *PrivateKey, ring []*ec.Point, msg []byte, linkable bool) (*Signature, error) {
+	c := key.Curve
+	n := len(ring)
+	pi := -1
+	for i, p := range ring {
+		if p.Equal(key.Public) {
+			pi = i
+			break
+		}
+	}
+	if pi < 0 {
+		return nil, errors.New("ringsig: the signing key is not a member of the ring")
+	}
+	sig := &Signature{S: make([]*big.Int, n)}
+	var hp *ec.Point
+	if linkable {
+		hp = hashPoint(c, key.Public)
+		sig.KeyImage = c.ScalarMult(hp, key.D)
+	}
+	prefix := ringPrefix(c, ring, sig.KeyImage)
+
+	alpha, err := c.RandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	var R *ec.Point
+	if linkable {
+		R = c.ScalarMult(hp, alpha)
+	}
+	challenges := make([]*big.Int, n)
+	challenges[(pi+1)%n] = challenge(c, prefix, msg, c.ScalarBaseMult(alpha), R)
+	for j := 1; j < n; j++ {
+		i := (pi + j) % n
+		s, err := c.RandomScalar(rand)
+		if err != nil {
+			return nil, err
+		}
+		sig.S[i] = s
+		L, R := commitments(c, ring[i], sig.KeyImage, s, challenges[i])
+		challenges[(i+1)%n] = challenge(c, prefix, msg, L, R)
+	}
+	// close the ring: s = alpha - c*x
+	s := new(big.Int).Mul(challenges[pi], key.D)
+	s.Sub(alpha, s)
+	sig.S[pi] = s.Mod(s, c.N)
+	sig.C0 = challenges[0]
+	return sig, nil
+}
+
+// commitments recomputes L = s*G + c*P and, for linkable signatures,
+// R = s*Hp(P) + c*I
+func commitments(c *ec.Curve, p, keyImage *ec.Point, s, ch *big.Int) (L, R *ec.Point) {
+	L = c.MultiScalarMult([]*big.Int{s, ch}, []*ec.Point{c.Generator(), p})
+	if keyImage != nil {
+		R = c.MultiScalarMult([]*big.Int{s, ch}, []*ec.Point{hashPoint(c, p), keyImage})
+	}
+	return
+}
+
+// Verify checks that sig is a valid signature of msg by a member of ring
+func Verify(c *ec.Curve, ring []*ec.Point, msg []byte, sig *Signature) bool {
+	if len(ring) == 0 || len(sig.S) != len(ring) || !validScalar(c, sig.C0) {
+		return false
+	}
+	for _, p := range ring {
+		if p == nil || p.IsInfinity() || !c.IsOnCurve(p) {
+			return false
+		}
+	}
+	if sig.KeyImage != nil && (sig.KeyImage.IsInfinity() || !c.IsOnCurve(sig.KeyImage)) {
+		return false
+	}
+	prefix := ringPrefix(c, ring, sig.KeyImage)
+	ch := sig.C0
+	for i, p := range ring {
+		if !validScalar(c, sig.S[i]) {
+			return false
+		}
+		L, R := commitments(c, p, sig.KeyImage, sig.S[i], ch)
+		ch = challenge(c, prefix, msg, L, R)
+	}
+	return ch.Cmp(sig.C0) == 0
+}
+
+func validScalar(c *ec.Curve, s *big.Int) bool {
+	return s != nil && s.Sign() >= 0 && s.Cmp(c.N) < 0
+}
+
+// Linked returns true if a and b are linkable signatures produced by the
+// same key. Both signatures should be verified first.
+func Linked(a, b *Signature) bool {
+	if a.KeyImage == nil || b.KeyImage == nil {
+		return false
+	}
+	return a.KeyImage.Equal(b.KeyImage)
+}
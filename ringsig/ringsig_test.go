@@ -0,0 +1,134 @@
+package ringsig
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+func makeRing(t *testing.T, c *ec.Curve, n int) ([]*PrivateKey, []*ec.Point) {
+	keys := make([]*PrivateKey, n)
+	ring := make([]*ec.Point, n)
+	for i := range keys {
+		k, err := GenerateKey(rand.Reader, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = k
+		ring[i] = k.Public
+	}
+	return keys, ring
+}
+
+func TestSignVerify(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		curve    *ec.Curve
+		size     int
+		linkable bool
+	}{
+		{ec.P256(), 1, true},
+		{ec.P256(), 5, true},
+		{ec.P256(), 5, false},
+		{ec.Secp256k1(), 4, true},
+		{ec.Secp256k1(), 3, false},
+	}
+	msg := []byte("the meeting is at noon")
+	for i, tc := range testcases {
+		keys, ring := makeRing(t, tc.curve, tc.size)
+		for j, k := range keys {
+			sign := Sign
+			if !tc.linkable {
+				sign = SignUnlinkable
+			}
+			sig, err := sign(rand.Reader, k, ring, msg)
+			if err != nil {
+				t.Fatalf("testcase %d signer %d failed with %v", i, j, err)
+			}
+			if !Verify(tc.curve, ring, msg, sig) {
+				t.Fatalf("testcase %d expected signature of signer %d to verify", i, j)
+			}
+			if tc.linkable != (sig.KeyImage != nil) {
+				t.Fatalf("testcase %d expected key image presence to be %v", i, tc.linkable)
+			}
+			if Verify(tc.curve, ring, []byte("the meeting is at one"), sig) {
+				t.Fatalf("testcase %d expected signature on another message to fail", i)
+			}
+			sig.S[0] = new(big.Int).Add(sig.S[0], big.NewInt(1))
+			if Verify(tc.curve, ring, msg, sig) {
+				t.Fatalf("testcase %d expected tampered signature to fail", i)
+			}
+		}
+	}
+}
+
+func TestWrongRing(t *testing.T) {
+	t.Parallel()
+	c := ec.P256()
+	keys, ring := makeRing(t, c, 3)
+	outsider, err := GenerateKey(rand.Reader, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Sign(rand.Reader, outsider, ring, []byte("hi")); err == nil {
+		t.Fatal("expected signing by a non member to fail")
+	}
+	sig, err := Sign(rand.Reader, keys[1], ring, []byte("hi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := []*ec.Point{ring[0], ring[1], outsider.Public}
+	if Verify(c, other, []byte("hi"), sig) {
+		t.Fatal("expected signature to fail on another ring")
+	}
+	if Verify(c, ring[:2], []byte("hi"), sig) {
+		t.Fatal("expected signature to fail on a truncated ring")
+	}
+}
+
+func TestLinked(t *testing.T) {
+	t.Parallel()
+	c := ec.Secp256k1()
+	keys, ring := makeRing(t, c, 4)
+	_, ring2 := makeRing(t, c, 3)
+	ring2 = append(ring2, keys[2].Public)
+
+	a, err := Sign(rand.Reader, keys[2], ring, []byte("vote for a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Sign(rand.Reader, keys[2], ring2, []byte("vote for b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := Sign(rand.Reader, keys[0], ring, []byte("vote for a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(c, ring2, []byte("vote for b"), b) {
+		t.Fatal("expected signature on the second ring to verify")
+	}
+	if !Linked(a, b) {
+		t.Fatal("expected two signatures by the same key to be linked")
+	}
+	if Linked(a, d) {
+		t.Fatal("expected signatures by different keys not to be linked")
+	}
+	if !a.KeyImage.Equal(keys[2].KeyImage()) {
+		t.Fatal("expected key image to match the key")
+	}
+	u, err := SignUnlinkable(rand.Reader, keys[2], ring, []byte("vote for a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Linked(a, u) {
+		t.Fatal("expected unlinkable signature not to be linked")
+	}
+	// swapping in another key image breaks the signature
+	a.KeyImage = d.KeyImage
+	if Verify(c, ring, []byte("vote for a"), a) {
+		t.Fatal("expected signature with a forged key image to fail")
+	}
+}
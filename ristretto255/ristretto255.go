@@ -0,0 +1,310 @@
+// Package ristretto255 implements the prime order group of RFC 9496 on
+// top of the edwards25519 package.
+//
+// The Edwards curve has eight times as many points as its prime order
+// subgroup, and protocols that forget about the cofactor end up with
+// malleable signatures or keys of small order. Ristretto quotients the
+// curve by its small subgroup instead: the element is a class of four
+// points, and the encoding picks one canonical representative, so every
+// 32 bytes string decodes to at most one element of the prime order
+// group and every element has exactly one encoding.
+//
+// Nothing here runs in constant time.
+package ristretto255
+
+import (
+	"crypto/sha512"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ec"
+	"github.com/jvehent/badcrypto/edwards25519"
+)
+
+var (
+	// p is the field modulus 2^255-19
+	p = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	// the constants of RFC 9496 section 4.1, whose signs matter
+	d                = fromDecimal("37095705934669439343138083508754565189542113879843219016388785533085940283555")
+	sqrtM1           = fromDecimal("19681161376707505956807079304988542015446066515923890162744021073123829784752")
+	sqrtADMinusOne   = fromDecimal("25063068953384623474111414158702152701244531502492656460079210482610430750235")
+	invsqrtAMinusD   = fromDecimal("54469307008909316920995813868745141605393597292927456921205312896311721017578")
+	oneMinusDSquared = fromDecimal("1159843021668779879193775521855586647937357759715417654439879720876111806838")
+	dMinusOneSquared = fromDecimal("40440834346308536858101042469323190826248399146238708352240133220865137265952")
+
+	// Order is the prime order of the group, the order of the base point
+	// of edwards25519
+	Order = edwards25519.Order
+)
+
+func fromDecimal(s string) *big.Int {
+	n, _ := new(big.Int).SetString(s, 10)
+	return n
+}
+
+func mulMod(a, b *big.Int) *big.Int {
+	r := new(big.Int).Mul(a, b)
+	return r.Mod(r, p)
+}
+
+func addMod(a, b *big.Int) *big.Int {
+	r := new(big.Int).Add(a, b)
+	return r.Mod(r, p)
+}
+
+func subMod(a, b *big.Int) *big.Int {
+	r := new(big.Int).Sub(a, b)
+	return r.Mod(r, p)
+}
+
+func negMod(a *big.Int) *big.Int {
+	return subMod(big.NewInt(0), a)
+}
+
+// isNegative reports whether x, reduced modulo p, is odd
+func isNegative(x *big.Int) bool {
+	return new(big.Int).Mod(x, p).Bit(0) == 1
+}
+
+// abs returns the non negative one of x and -x
+func abs(x *big.Int) *big.Int {
+	if isNegative(x) {
+		return negMod(x)
+	}
+	return new(big.Int).Mod(x, p)
+}
+
+// sqrtRatioM1 is SQRT_RATIO_M1 of RFC 9496 section 4.2: it returns
+// whether u/v is a square, and the non negative sqrt(u/v) when it is,
+// sqrt(i*u/v) otherwise
+func sqrtRatioM1(u, v *big.Int) (bool, *big.Int) {
+	v3 := mulMod(mulMod(v, v), v)
+	v7 := mulMod(mulMod(v3, v3), v)
+	// (p-5)/8
+	e := new(big.Int).Rsh(new(big.Int).Sub(p, big.NewInt(5)), 3)
+	r := mulMod(mulMod(u, v3), new(big.Int).Exp(mulMod(u, v7), e, p))
+	check := mulMod(v, mulMod(r, r))
+	correctSign := check.Cmp(new(big.Int).Mod(u, p)) == 0
+	flippedSign := check.Cmp(negMod(u)) == 0
+	flippedSignI := check.Cmp(negMod(mulMod(u, sqrtM1))) == 0
+	if flippedSign || flippedSignI {
+		r = mulMod(r, sqrtM1)
+	}
+	return correctSign || flippedSign, abs(r)
+}
+
+// Element is an element of the group. The zero value is not valid, use
+// Identity or the other constructors.
+type Element struct {
+	q *edwards25519.Point
+}
+
+// Identity returns the neutral element
+func Identity() *Element {
+	return &Element{edwards25519.Identity()}
+}
+
+// Generator returns the base point of RFC 9496, the class of the
+// edwards25519 base point
+func Generator() *Element {
+	return &Element{edwards25519.Basepoint()}
+}
+
+// Add returns e + f
+func (e *Element) Add(f *Element) *Element {
+	return &Element{e.q.Add(f.q)}
+}
+
+// Sub returns e - f
+func (e *Element) Sub(f *Element) *Element {
+	return &Element{e.q.Sub(f.q)}
+}
+
+// Neg returns -e
+func (e *Element) Neg() *Element {
+	return &Element{e.q.Neg()}
+}
+
+// ScalarMult returns k*e
+func (e *Element) ScalarMult(k *big.Int) *Element {
+	return &Element{e.q.ScalarMult(new(big.Int).Mod(k, Order))}
+}
+
+// ScalarBaseMult returns k times the generator
+func ScalarBaseMult(k *big.Int) *Element {
+	return Generator().ScalarMult(k)
+}
+
+// Equal reports whether e and f are the same element, that is whether
+// their points differ by a point of the small subgroup, as in RFC 9496
+// section 4.3.3
+func (e *Element) Equal(f *Element) bool {
+	x1, y1, _, _ := e.q.ExtendedCoordinates()
+	x2, y2, _, _ := f.q.ExtendedCoordinates()
+	return mulMod(x1, y2).Cmp(mulMod(y1, x2)) == 0 ||
+		mulMod(y1, y2).Cmp(mulMod(x1, x2)) == 0
+}
+
+// Bytes encodes e as in RFC 9496 section 4.3.2, in 32 bytes
+func (e *Element) Bytes() []byte {
+	x0, y0, z0, t0 := e.q.ExtendedCoordinates()
+	u1 := mulMod(addMod(z0, y0), subMod(z0, y0))
+	u2 := mulMod(x0, y0)
+	_, invsqrt := sqrtRatioM1(big.NewInt(1), mulMod(u1, mulMod(u2, u2)))
+	den1 := mulMod(invsqrt, u1)
+	den2 := mulMod(invsqrt, u2)
+	zInv := mulMod(mulMod(den1, den2), t0)
+	x, y, denInv := x0, y0, den2
+	// pick the representative of the class with a non negative x*y
+	if isNegative(mulMod(t0, zInv)) {
+		x, y = mulMod(y0, sqrtM1), mulMod(x0, sqrtM1)
+		denInv = mulMod(den1, invsqrtAMinusD)
+	}
+	if isNegative(mulMod(x, zInv)) {
+		y = negMod(y)
+	}
+	s := abs(mulMod(denInv, subMod(z0, y)))
+	out := s.FillBytes(make([]byte, 32))
+	reverse(out)
+	return out
+}
+
+// NewElement decodes an element as in RFC 9496 section 4.3.1, rejecting
+// every encoding that Bytes would not produce
+func NewElement(b []byte) (*Element, error) {
+	if len(b) != 32 {
+		return nil, errors.New("ristretto255: invalid element length")
+	}
+	buf := append([]byte{}, b...)
+	reverse(buf)
+	s := new(big.Int).SetBytes(buf)
+	if s.Cmp(p) >= 0 || isNegative(s) {
+		return nil, errors.New("ristretto255: non canonical element encoding")
+	}
+	ss := mulMod(s, s)
+	u1 := subMod(big.NewInt(1), ss)
+	u2 := addMod(big.NewInt(1), ss)
+	u2Squared := mulMod(u2, u2)
+	// v = -(d * u1^2) - u2^2
+	v := subMod(negMod(mulMod(d, mulMod(u1, u1))), u2Squared)
+	wasSquare, invsqrt := sqrtRatioM1(big.NewInt(1), mulMod(v, u2Squared))
+	denX := mulMod(invsqrt, u2)
+	denY := mulMod(mulMod(invsqrt, denX), v)
+	x := abs(mulMod(mulMod(big.NewInt(2), s), denX))
+	y := mulMod(u1, denY)
+	t := mulMod(x, y)
+	if !wasSquare || isNegative(t) || y.Sign() == 0 {
+		return nil, errors.New("ristretto255: invalid element encoding")
+	}
+	q, err := edwards25519.NewPointFromExtended(x, y, big.NewInt(1), t)
+	if err != nil {
+		return nil, err
+	}
+	return &Element{q}, nil
+}
+
+// mapToPoint is the Elligator map MAP of RFC 9496 section 4.3.4, from a
+// field element to a point
+func mapToPoint(t *big.Int) *edwards25519.Point {
+	r := mulMod(sqrtM1, mulMod(t, t))
+	u := mulMod(addMod(r, big.NewInt(1)), oneMinusDSquared)
+	// v = (-1 - r*d) * (r + d)
+	v := mulMod(subMod(big.NewInt(-1), mulMod(r, d)), addMod(r, d))
+	wasSquare, s := sqrtRatioM1(u, v)
+	c := big.NewInt(-1)
+	if !wasSquare {
+		s = negMod(abs(mulMod(s, t)))
+		c = r
+	}
+	// N = c * (r - 1) * (d - 1)^2 - v
+	n := subMod(mulMod(mulMod(c, subMod(r, big.NewInt(1))), dMinusOneSquared), v)
+	w0 := mulMod(big.NewInt(2), mulMod(s, v))
+	w1 := mulMod(n, sqrtADMinusOne)
+	ss := mulMod(s, s)
+	w2 := subMod(big.NewInt(1), ss)
+	w3 := addMod(big.NewInt(1), ss)
+	q, err := edwards25519.NewPointFromExtended(mulMod(w0, w3), mulMod(w2, w1), mulMod(w1, w3), mulMod(w0, w2))
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// FromUniformBytes maps 64 uniformly random bytes to an element, as in
+// RFC 9496 section 4.3.4. The distribution of the result is uniform, and
+// nobody knows its discrete logarithm.
+func FromUniformBytes(b []byte) (*Element, error) {
+	if len(b) != 64 {
+		return nil, errors.New("ristretto255: uniform input must be 64 bytes")
+	}
+	return &Element{mapToPoint(fieldElement(b[:32])).Add(mapToPoint(fieldElement(b[32:])))}, nil
+}
+
+// fieldElement reads 32 little endian bytes, ignoring the top bit
+func fieldElement(b []byte) *big.Int {
+	buf := append([]byte{}, b...)
+	buf[31] &= 0x7f
+	reverse(buf)
+	return new(big.Int).Mod(new(big.Int).SetBytes(buf), p)
+}
+
+// HashToGroup hashes msg to an element, with expand_message_xmd and
+// SHA-512 under the domain separation tag dst, as the ristretto255-SHA512
+// suites of RFC 9497 and RFC 9380 do
+func HashToGroup(msg, dst []byte) (*Element, error) {
+	uniform, err := ec.ExpandMessageXMD(sha512.New, msg, dst, 64)
+	if err != nil {
+		return nil, err
+	}
+	return FromUniformBytes(uniform)
+}
+
+// HashToScalar hashes msg to a scalar, reducing 64 bytes of
+// expand_message_xmd with SHA-512 modulo the order
+func HashToScalar(msg, dst []byte) (*big.Int, error) {
+	uniform, err := ec.ExpandMessageXMD(sha512.New, msg, dst, 64)
+	if err != nil {
+		return nil, err
+	}
+	k := edwards25519.ScalarFromBytes(uniform)
+	return k.Mod(k, Order), nil
+}
+
+// RandomScalar returns a uniformly random non zero scalar
+func RandomScalar(rand io.Reader) (*big.Int, error) {
+	buf := make([]byte, 64)
+	for {
+		if _, err := io.ReadFull(rand, buf); err != nil {
+			return nil, err
+		}
+		k := edwards25519.ScalarFromBytes(buf)
+		if k.Mod(k, Order).Sign() != 0 {
+			return k, nil
+		}
+	}
+}
+
+// ScalarBytes encodes k modulo the order in 32 little endian bytes
+func ScalarBytes(k *big.Int) []byte {
+	return edwards25519.ScalarBytes(k)
+}
+
+// NewScalar decodes 32 little endian bytes, rejecting values that are not
+// reduced modulo the order
+func NewScalar(b []byte) (*big.Int, error) {
+	if len(b) != 32 {
+		return nil, errors.New("ristretto255: invalid scalar length")
+	}
+	k := edwards25519.ScalarFromBytes(b)
+	if k.Cmp(Order) >= 0 {
+		return nil, errors.New("ristretto255: non canonical scalar encoding")
+	}
+	return k, nil
+}
+
+func reverse(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
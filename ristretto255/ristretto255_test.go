@@ -0,0 +1,201 @@
+package ristretto255
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/edwards25519"
+)
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestConstants(t *testing.T) {
+	t.Parallel()
+	minusOne := big.NewInt(-1)
+	if mulMod(sqrtM1, sqrtM1).Cmp(negMod(big.NewInt(1))) != 0 {
+		t.Fatal("expected sqrtM1^2 = -1")
+	}
+	// a*d - 1 and 1/(a-d) with a = -1
+	if mulMod(sqrtADMinusOne, sqrtADMinusOne).Cmp(subMod(negMod(d), big.NewInt(1))) != 0 {
+		t.Fatal("expected sqrtADMinusOne^2 = -d - 1")
+	}
+	if mulMod(mulMod(invsqrtAMinusD, invsqrtAMinusD), subMod(minusOne, d)).Cmp(big.NewInt(1)) != 0 {
+		t.Fatal("expected invsqrtAMinusD^2 * (-1 - d) = 1")
+	}
+	if oneMinusDSquared.Cmp(subMod(big.NewInt(1), mulMod(d, d))) != 0 {
+		t.Fatal("expected oneMinusDSquared = 1 - d^2")
+	}
+	dm1 := subMod(d, big.NewInt(1))
+	if dMinusOneSquared.Cmp(mulMod(dm1, dm1)) != 0 {
+		t.Fatal("expected dMinusOneSquared = (d - 1)^2")
+	}
+}
+
+// RFC 9496 appendix A.1, the multiples of the generator from 0 to 15
+var multiples = []string{
+	"0000000000000000000000000000000000000000000000000000000000000000",
+	"e2f2ae0a6abc4e71a884a961c500515f58e30b6aa582dd8db6a65945e08d2d76",
+	"6a493210f7499cd17fecb510ae0cea23a110e8d5b901f8acadd3095c73a3b919",
+	"94741f5d5d52755ece4f23f044ee27d5d1ea1e2bd196b462166b16152a9d0259",
+	"da80862773358b466ffadfe0b3293ab3d9fd53c5ea6c955358f568322daf6a57",
+	"e882b131016b52c1d3337080187cf768423efccbb517bb495ab812c4160ff44e",
+	"f64746d3c92b13050ed8d80236a7f0007c3b3f962f5ba793d19a601ebb1df403",
+	"44f53520926ec81fbd5a387845beb7df85a96a24ece18738bdcfa6a7822a176d",
+	"903293d8f2287ebe10e2374dc1a53e0bc887e592699f02d077d5263cdd55601c",
+	"02622ace8f7303a31cafc63f8fc48fdc16e1c8c8d234b2f0d6685282a9076031",
+	"20706fd788b2720a1ed2a5dad4952b01f413bcf0e7564de8cdc816689e2db95f",
+	"bce83f8ba5dd2fa572864c24ba1810f9522bc6004afe95877ac73241cafdab42",
+	"e4549ee16b9aa03099ca208c67adafcafa4c3f3e4e5303de6026e3ca8ff84460",
+	"aa52e000df2e16f55fb1032fc33bc42742dad6bd5a8fc0be0167436c5948501f",
+	"46376b80f409b29dc2b5f6f0c52591990896e5716f41477cd30085ab7f10301e",
+	"e0c418f7c8d9c4cdd7395b93ea124f3ad99021bb681dfc3302a9d99a2e53e64e",
+}
+
+func TestMultiples(t *testing.T) {
+	t.Parallel()
+	acc := Identity()
+	for i, expected := range multiples {
+		if got := hex.EncodeToString(acc.Bytes()); got != expected {
+			t.Fatalf("testcase %d expected %s but got %s", i, expected, got)
+		}
+		e, err := NewElement(unhex(expected))
+		if err != nil {
+			t.Fatalf("testcase %d failed to decode: %v", i, err)
+		}
+		if !e.Equal(acc) || !bytes.Equal(e.Bytes(), acc.Bytes()) {
+			t.Fatalf("testcase %d expected the encoding to round trip", i)
+		}
+		if !ScalarBaseMult(big.NewInt(int64(i))).Equal(acc) {
+			t.Fatalf("testcase %d expected the multiple to match the sum", i)
+		}
+		acc = acc.Add(Generator())
+	}
+}
+
+// RFC 9496 appendix A.2, encodings that must be rejected
+func TestInvalidEncodings(t *testing.T) {
+	t.Parallel()
+	var testcases = []string{
+		// non canonical field elements
+		"00ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+		"f3ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+		"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+		// negative field elements
+		"0100000000000000000000000000000000000000000000000000000000000000",
+		"01ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+		"ed57ffd8c914fb201471d1c3d245ce3c746fcbe63a3679d51b6a516ebebe0e20",
+		// non square x^2
+		"26948d35ca62e643e26a83177332e6b6afeb9d08e4268b650f1f5bbd8d81d371",
+		"4eac077a713c57b4f4397629a4145982c661f48044dd3f96427d40b147d9742f",
+		// negative x*y
+		"3eb858e78f5a7254d8c9731174a94f76755fd3941c0ac93735c07ba14579630e",
+		// s = -1, which causes y = 0
+		"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+	}
+	for i, tc := range testcases {
+		if _, err := NewElement(unhex(tc)); err == nil {
+			t.Fatalf("testcase %d expected %s to be rejected", i, tc)
+		}
+	}
+	if _, err := NewElement(make([]byte, 31)); err == nil {
+		t.Fatal("expected a short encoding to be rejected")
+	}
+}
+
+func TestGroupLaws(t *testing.T) {
+	t.Parallel()
+	a, b := big.NewInt(123456789), big.NewInt(987654321)
+	pa, pb := ScalarBaseMult(a), ScalarBaseMult(b)
+	if !pa.Add(pb).Equal(ScalarBaseMult(new(big.Int).Add(a, b))) {
+		t.Fatal("expected aG + bG = (a+b)G")
+	}
+	if !pa.Sub(pa).Equal(Identity()) || !pa.Add(pa.Neg()).Equal(Identity()) {
+		t.Fatal("expected aG - aG to be the identity")
+	}
+	if !pa.ScalarMult(b).Equal(pb.ScalarMult(a)) {
+		t.Fatal("expected b(aG) = a(bG)")
+	}
+	if !Generator().ScalarMult(Order).Equal(Identity()) {
+		t.Fatal("expected the generator to have order Order")
+	}
+}
+
+// Points of the same class differ by a point of the small subgroup, and
+// must be equal and share their encoding
+func TestTorsionClasses(t *testing.T) {
+	t.Parallel()
+	// (0, -1) has order 2
+	enc := make([]byte, 32)
+	enc[0] = 0xec
+	for i := 1; i < 31; i++ {
+		enc[i] = 0xff
+	}
+	enc[31] = 0x7f
+	torsion, err := edwards25519.NewPoint(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := ScalarBaseMult(big.NewInt(7))
+	shifted := &Element{e.q.Add(torsion)}
+	if shifted.q.Equal(e.q) {
+		t.Fatal("expected the points to differ")
+	}
+	if !shifted.Equal(e) || !bytes.Equal(shifted.Bytes(), e.Bytes()) {
+		t.Fatal("expected points of the same class to be equal")
+	}
+}
+
+func TestFromUniformBytes(t *testing.T) {
+	t.Parallel()
+	b := make([]byte, 64)
+	rand.Read(b)
+	e, err := FromUniformBytes(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := NewElement(e.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Equal(e) {
+		t.Fatal("expected the mapped element to round trip")
+	}
+	if _, err := FromUniformBytes(b[:32]); err == nil {
+		t.Fatal("expected a short input to be rejected")
+	}
+}
+
+func TestScalars(t *testing.T) {
+	t.Parallel()
+	k, err := RandomScalar(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := NewScalar(ScalarBytes(k))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Cmp(k) != 0 {
+		t.Fatalf("expected %x but got %x", k, got)
+	}
+	if _, err := NewScalar(ScalarBytes(Order)); err != nil {
+		t.Fatal("expected the order reduced to zero to decode")
+	}
+	b := make([]byte, 32)
+	for i := range b {
+		b[i] = 0xff
+	}
+	if _, err := NewScalar(b); err == nil {
+		t.Fatal("expected an unreduced scalar to be rejected")
+	}
+}
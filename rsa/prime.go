@@ -0,0 +1,96 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+	"runtime"
+	"sync"
+
+	"github.com/jvehent/badcrypto/bignum"
+)
+
+// GeneratePrime returns a random prime of exactly bits bits, with its two
+// top bits set so that the product of two of them has 2*bits bits.
+//
+// workers goroutines, or GOMAXPROCS of them if workers is 0 or less, each
+// draw a random start from random in turn and sieve their way up from it
+// with bignum.SearchPrime. They share a done channel: the first prime
+// found closes it and stops the others. A 2048 bits prime takes a few
+// hundred candidates, so the search scales with the number of cores; with
+// more than one worker, which start wins, and so the result for a given
+// random, is not deterministic.
+func GeneratePrime(random io.Reader, bits, workers int) (*big.Int, error) {
+	if bits < 3 {
+		return nil, errors.New("rsa: prime size must be at least 3 bits")
+	}
+	if random == nil {
+		random = rand.Reader
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	var (
+		mu     sync.Mutex // serializes reads of random
+		once   sync.Once
+		done   = make(chan struct{})
+		result *big.Int
+		err    error
+		wg     sync.WaitGroup
+	)
+	// finish records the outcome of the first worker to stop and cancels
+	// the others
+	finish := func(p *big.Int, e error) {
+		once.Do(func() {
+			result, err = p, e
+			close(done)
+		})
+	}
+	buf := make([]byte, (bits+7)/8)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				mu.Lock()
+				start, e := candidate(random, buf, bits)
+				mu.Unlock()
+				if e != nil {
+					finish(nil, e)
+					return
+				}
+				// nil when done was closed, or when the search ran past
+				// bits bits, which a new start fixes
+				if p := bignum.SearchPrime(start, done); p != nil {
+					finish(new(big.Int).SetBytes(p.Bytes()), nil)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return result, err
+}
+
+// candidate reads an odd number of bits bits with its two top bits set,
+// using buf to hold the random bytes
+func candidate(random io.Reader, buf []byte, bits int) (*bignum.Int, error) {
+	if _, err := io.ReadFull(random, buf); err != nil {
+		return nil, err
+	}
+	// clear the bits above the size, then set the top two and the lowest
+	extra := uint(len(buf)*8 - bits)
+	buf[0] &= byte(0xff >> extra)
+	c := new(bignum.Int)
+	c.SetBytes(buf)
+	c.SetBit(bits-1, 1)
+	c.SetBit(bits-2, 1)
+	c.SetBit(0, 1)
+	return c, nil
+}
@@ -0,0 +1,58 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestGeneratePrime(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		bits, workers int
+	}{
+		{3, 1},
+		{16, 4},
+		{61, 0},
+		{512, 1},
+		{512, 8},
+		{1024, 0},
+	}
+	for i, tc := range testcases {
+		p, err := GeneratePrime(rand.Reader, tc.bits, tc.workers)
+		if err != nil {
+			t.Fatalf("testcase %d failed: %v", i, err)
+		}
+		if p.BitLen() != tc.bits {
+			t.Fatalf("testcase %d expected %d bits but got %d", i, tc.bits, p.BitLen())
+		}
+		if tc.bits > 2 && p.Bit(tc.bits-2) != 1 {
+			t.Fatalf("testcase %d expected the second top bit to be set", i)
+		}
+		if !p.ProbablyPrime(primeRounds) {
+			t.Fatalf("testcase %d returned composite %s", i, p)
+		}
+	}
+}
+
+func TestGeneratePrimeErrors(t *testing.T) {
+	t.Parallel()
+	if _, err := GeneratePrime(rand.Reader, 2, 1); err == nil {
+		t.Fatal("expected a 2 bits prime to be rejected")
+	}
+	// a failing reader stops every worker with its error
+	if _, err := GeneratePrime(bytes.NewReader(nil), 512, 4); err == nil {
+		t.Fatal("expected an error from an exhausted reader")
+	}
+}
+
+func benchmarkGeneratePrime(b *testing.B, workers int) {
+	for i := 0; i < b.N; i++ {
+		if _, err := GeneratePrime(rand.Reader, 2048, workers); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGeneratePrime2048Sequential(b *testing.B) { benchmarkGeneratePrime(b, 1) }
+func BenchmarkGeneratePrime2048Parallel(b *testing.B)   { benchmarkGeneratePrime(b, 0) }
@@ -0,0 +1,73 @@
+// Package rsa generates RSA keys with a prime search spread across all the
+// cores of the machine. For 4096 bits keys, where crypto/rsa spends
+// seconds testing candidates one after the other, GenerateKeyParallel is
+// several times faster. GenerateKeyStrong makes keys of strong primes for
+// profiles derived from ANSI X9.31. Keys are returned as crypto/rsa keys
+// and are used with that package.
+package rsa
+
+import (
+	"crypto/rsa"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// E is the public exponent of generated keys
+const E = 65537
+
+// GenerateKeyParallel returns an RSA key of bits bits with the public
+// exponent E. Each of its two primes is found by GeneratePrime with
+// workers goroutines, or GOMAXPROCS if workers is 0 or less.
+func GenerateKeyParallel(random io.Reader, bits, workers int) (*rsa.PrivateKey, error) {
+	if bits < 64 {
+		return nil, errors.New("rsa: key too small")
+	}
+	return generateKey(bits, func(size int) (*big.Int, error) {
+		return GeneratePrime(random, size, workers)
+	})
+}
+
+// generateKey returns a key of bits bits made of two primes returned by
+// generate, which is called again until they suit an exponent of E
+func generateKey(bits int, generate func(size int) (*big.Int, error)) (*rsa.PrivateKey, error) {
+	e := big.NewInt(E)
+	one := big.NewInt(1)
+	var primes [2]*big.Int
+	for i := range primes {
+		size := bits / 2
+		if i == 0 {
+			size = bits - size
+		}
+		for {
+			p, err := generate(size)
+			if err != nil {
+				return nil, err
+			}
+			// e must be invertible modulo p - 1, and the primes distinct
+			pm1 := new(big.Int).Sub(p, one)
+			if new(big.Int).GCD(nil, nil, e, pm1).Cmp(one) != 0 {
+				continue
+			}
+			if i == 1 && p.Cmp(primes[0]) == 0 {
+				continue
+			}
+			primes[i] = p
+			break
+		}
+	}
+	p, q := primes[0], primes[1]
+	// the two top bits of both primes are set, so n has exactly bits bits
+	n := new(big.Int).Mul(p, q)
+	phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+	priv := &rsa.PrivateKey{
+		PublicKey: rsa.PublicKey{N: n, E: E},
+		D:         new(big.Int).ModInverse(e, phi),
+		Primes:    []*big.Int{p, q},
+	}
+	priv.Precompute()
+	if err := priv.Validate(); err != nil {
+		return nil, err
+	}
+	return priv, nil
+}
@@ -0,0 +1,43 @@
+package rsa
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestGenerateKeyParallel(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		bits, workers int
+	}{
+		{512, 1},
+		{1023, 4},
+		{2048, 0},
+	}
+	digest := sha256.Sum256([]byte("parallel prime search"))
+	for i, tc := range testcases {
+		priv, err := GenerateKeyParallel(rand.Reader, tc.bits, tc.workers)
+		if err != nil {
+			t.Fatalf("testcase %d failed: %v", i, err)
+		}
+		if priv.N.BitLen() != tc.bits {
+			t.Fatalf("testcase %d expected a %d bits modulus but got %d", i, tc.bits, priv.N.BitLen())
+		}
+		if priv.E != E {
+			t.Fatalf("testcase %d expected exponent %d but got %d", i, E, priv.E)
+		}
+		sig, err := rsa.SignPKCS1v15(rand.Reader, priv, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("testcase %d failed to sign: %v", i, err)
+		}
+		if err := rsa.VerifyPKCS1v15(&priv.PublicKey, crypto.SHA256, digest[:], sig); err != nil {
+			t.Fatalf("testcase %d failed to verify: %v", i, err)
+		}
+	}
+	if _, err := GenerateKeyParallel(rand.Reader, 32, 1); err == nil {
+		t.Fatal("expected a 32 bits key to be rejected")
+	}
+}
@@ -0,0 +1,112 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// auxiliaryBits returns the size of the auxiliary primes of a strong
+// prime of bits bits, the minimums of FIPS 186-4 table B.1 for moduli of
+// 1024, 2048 and 3072 bits plus one
+func auxiliaryBits(bits int) int {
+	switch {
+	case bits <= 512:
+		return 101
+	case bits <= 1024:
+		return 141
+	}
+	return 171
+}
+
+// GenerateStrongPrime returns a strong prime of exactly bits bits, with
+// its two top bits set: p - 1 has a prime factor p1 and p + 1 a prime
+// factor p2, both of more than 100 bits, as ANSI X9.31 requires. Such
+// primes were meant to resist Pollard's p - 1 and Williams' p + 1
+// factoring methods, which random primes of today's sizes resist as
+// well, and are only needed to follow profiles that still ask for them.
+//
+// p1 and p2 are found by GeneratePrime with workers goroutines. p is then
+// searched, as in Gordon's algorithm, among the numbers congruent to 1
+// modulo 2*p1 and to -1 modulo p2, which the Chinese remainder theorem
+// gives as R + k*2*p1*p2.
+func GenerateStrongPrime(random io.Reader, bits, workers int) (*big.Int, error) {
+	p, _, _, err := strongPrime(random, bits, workers)
+	return p, err
+}
+
+// primeRounds is the number of Miller-Rabin rounds, on top of the
+// Baillie-PSW test of ProbablyPrime, that a candidate of strongPrime must
+// pass. Unlike GeneratePrime, which bignum.SearchPrime tests, the strong
+// prime search works on big.Int.
+const primeRounds = 20
+
+// strongPrime returns a strong prime p with the auxiliary primes p1 and p2
+// dividing p - 1 and p + 1
+func strongPrime(random io.Reader, bits, workers int) (p, p1, p2 *big.Int, err error) {
+	aux := auxiliaryBits(bits)
+	if bits < 2*aux+10 {
+		return nil, nil, nil, errors.New("rsa: strong prime size must be at least 212 bits")
+	}
+	if random == nil {
+		random = rand.Reader
+	}
+	if p1, err = GeneratePrime(random, aux, workers); err != nil {
+		return nil, nil, nil, err
+	}
+	if p2, err = GeneratePrime(random, aux, workers); err != nil {
+		return nil, nil, nil, err
+	}
+	// R = (p2^-1 mod 2p1) * p2 - ((2p1)^-1 mod p2) * 2p1 mod 2p1p2
+	twoP1 := new(big.Int).Lsh(p1, 1)
+	step := new(big.Int).Mul(twoP1, p2)
+	r := new(big.Int).ModInverse(p2, twoP1)
+	r.Mul(r, p2)
+	s := new(big.Int).ModInverse(twoP1, p2)
+	s.Mul(s, twoP1)
+	r.Sub(r, s).Mod(r, step)
+
+	buf := make([]byte, (bits+7)/8)
+	for {
+		c, err := candidate(random, buf, bits)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		x := new(big.Int).SetBytes(c.Bytes())
+		// the first number above x that is R modulo 2p1p2, then its
+		// successors for as long as they keep bits bits
+		y := new(big.Int).Sub(r, x)
+		y.Mod(y, step).Add(y, x)
+		for ; y.BitLen() == bits; y.Add(y, step) {
+			if y.ProbablyPrime(primeRounds) {
+				return y, p1, p2, nil
+			}
+		}
+	}
+}
+
+// GenerateKeyStrong returns an RSA key of bits bits with the public
+// exponent E made of two strong primes, see GenerateStrongPrime, for
+// compliance profiles derived from ANSI X9.31. As X9.31 requires, the
+// primes differ in their top 100 bits. bits must be at least 1024.
+func GenerateKeyStrong(random io.Reader, bits, workers int) (*rsa.PrivateKey, error) {
+	if bits < 1024 {
+		return nil, errors.New("rsa: strong keys must be at least 1024 bits")
+	}
+	// |p - q| > 2^(bits/2 - 100)
+	minDiff := new(big.Int).Lsh(big.NewInt(1), uint(bits/2-100))
+	for {
+		priv, err := generateKey(bits, func(size int) (*big.Int, error) {
+			return GenerateStrongPrime(random, size, workers)
+		})
+		if err != nil {
+			return nil, err
+		}
+		diff := new(big.Int).Sub(priv.Primes[0], priv.Primes[1])
+		if diff.Abs(diff).Cmp(minDiff) > 0 {
+			return priv, nil
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+func TestStrongPrime(t *testing.T) {
+	t.Parallel()
+	one := big.NewInt(1)
+	for i, bits := range []int{212, 512, 1024} {
+		p, p1, p2, err := strongPrime(rand.Reader, bits, 0)
+		if err != nil {
+			t.Fatalf("testcase %d failed: %v", i, err)
+		}
+		if p.BitLen() != bits || p.Bit(bits-2) != 1 {
+			t.Fatalf("testcase %d expected %d bits with the top two set but got %x", i, bits, p)
+		}
+		if !p.ProbablyPrime(primeRounds) || !p1.ProbablyPrime(primeRounds) || !p2.ProbablyPrime(primeRounds) {
+			t.Fatalf("testcase %d returned a composite", i)
+		}
+		if p1.BitLen() <= 100 || p2.BitLen() <= 100 {
+			t.Fatalf("testcase %d expected auxiliary primes of more than 100 bits", i)
+		}
+		pm1 := new(big.Int).Sub(p, one)
+		pp1 := new(big.Int).Add(p, one)
+		if new(big.Int).Mod(pm1, p1).Sign() != 0 || new(big.Int).Mod(pp1, p2).Sign() != 0 {
+			t.Fatalf("testcase %d expected p1 | p - 1 and p2 | p + 1", i)
+		}
+	}
+}
+
+func TestGenerateKeyStrong(t *testing.T) {
+	t.Parallel()
+	priv, err := GenerateKeyStrong(rand.Reader, 1024, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if priv.N.BitLen() != 1024 || priv.E != E {
+		t.Fatalf("expected a 1024 bits key with exponent %d but got %d bits and %d", E, priv.N.BitLen(), priv.E)
+	}
+	digest := sha256.Sum256([]byte("strong primes"))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, priv, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rsa.VerifyPKCS1v15(&priv.PublicKey, crypto.SHA256, digest[:], sig); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GenerateKeyStrong(rand.Reader, 512, 0); err == nil {
+		t.Fatal("expected a 512 bits strong key to be rejected")
+	}
+	if _, err := GenerateStrongPrime(rand.Reader, 200, 0); err == nil {
+		t.Fatal("expected a 200 bits strong prime to be rejected")
+	}
+	if _, err := GenerateStrongPrime(bytes.NewReader(nil), 512, 0); err == nil {
+		t.Fatal("expected an error from an exhausted reader")
+	}
+}
@@ -0,0 +1,82 @@
+package schnorr
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+// AdaptorSignature is a signature encrypted under an adaptor point T = t*G:
+// it verifies as a promise that the signer's signature of the message is
+// one addition of t away, and once that signature is published anyone
+// holding the adaptor signature learns t. This is what makes atomic swaps
+// work: the same t completes a signature on each side, so the party that
+// claims one hands the other the key to its own.
+//
+// The nonce point R = k*G + T is the nonce of the completed signature, and
+// S = k + e*x with e = H(R, P, m) misses t.
+type AdaptorSignature struct {
+	R *ec.Point
+	S *big.Int
+}
+
+// AdaptorSign returns the signature of msg by priv encrypted under the
+// point t
+func AdaptorSign(rand io.Reader, priv *PrivateKey, msg []byte, t *ec.Point) (*AdaptorSignature, error) {
+	c := priv.Curve
+	if t == nil || t.IsInfinity() || !c.IsOnCurve(t) {
+		return nil, errors.New("schnorr: invalid adaptor point")
+	}
+	for {
+		k, err := nonce(rand, priv, msg)
+		if err != nil {
+			return nil, err
+		}
+		r := c.Add(c.ScalarBaseMult(k), t)
+		if r.IsInfinity() {
+			continue
+		}
+		e := challenge(c, r, priv.Point, msg)
+		return &AdaptorSignature{R: r, S: respond(c, k, e, priv.D)}, nil
+	}
+}
+
+// AdaptorVerify checks that sig completes into a signature of msg by pub
+// with the discrete logarithm of t: S*G = R - T + e*P
+func AdaptorVerify(pub *PublicKey, msg []byte, t *ec.Point, sig *AdaptorSignature) bool {
+	c := pub.Curve
+	if sig == nil || sig.R == nil || sig.S == nil || sig.R.IsInfinity() || !c.IsOnCurve(sig.R) ||
+		sig.S.Sign() < 0 || sig.S.Cmp(c.N) >= 0 {
+		return false
+	}
+	if t == nil || t.IsInfinity() || !c.IsOnCurve(t) {
+		return false
+	}
+	if pub.Point == nil || pub.Point.IsInfinity() || !c.IsOnCurve(pub.Point) {
+		return false
+	}
+	e := challenge(c, sig.R, pub.Point, msg)
+	return c.ScalarBaseMult(sig.S).Equal(c.Add(c.Sub(sig.R, t), c.ScalarMult(pub.Point, e)))
+}
+
+// Complete decrypts an adaptor signature with the secret t
+func (sig *AdaptorSignature) Complete(c *ec.Curve, t *big.Int) *Signature {
+	s := new(big.Int).Add(sig.S, t)
+	return &Signature{R: sig.R, S: s.Mod(s, c.N)}
+}
+
+// Extract returns the secret t from an adaptor signature and the signature
+// it completed into, or an error if the signature is not its completion
+func (sig *AdaptorSignature) Extract(c *ec.Curve, t *ec.Point, complete *Signature) (*big.Int, error) {
+	if complete == nil || complete.S == nil || complete.R == nil || !complete.R.Equal(sig.R) {
+		return nil, errors.New("schnorr: signature does not complete the adaptor signature")
+	}
+	secret := new(big.Int).Sub(complete.S, sig.S)
+	secret.Mod(secret, c.N)
+	if !c.ScalarBaseMult(secret).Equal(t) {
+		return nil, errors.New("schnorr: signature does not complete the adaptor signature")
+	}
+	return secret, nil
+}
@@ -0,0 +1,58 @@
+package schnorr
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+func TestAdaptorSignature(t *testing.T) {
+	t.Parallel()
+	for i, c := range []*ec.Curve{ec.P256(), ec.Secp256k1()} {
+		priv, err := GenerateKey(rand.Reader, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		secret, err := c.RandomScalar(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		point := c.ScalarBaseMult(secret)
+		msg := []byte("pay 1 coin to bob")
+		adaptor, err := AdaptorSign(rand.Reader, priv, msg, point)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !AdaptorVerify(&priv.PublicKey, msg, point, adaptor) {
+			t.Fatalf("testcase %d expected the adaptor signature to verify", i)
+		}
+		if AdaptorVerify(&priv.PublicKey, msg, c.Generator(), adaptor) {
+			t.Fatalf("testcase %d expected the adaptor signature to fail under another point", i)
+		}
+		if AdaptorVerify(&priv.PublicKey, []byte("other"), point, adaptor) {
+			t.Fatalf("testcase %d expected the adaptor signature to fail for another message", i)
+		}
+		if Verify(&priv.PublicKey, msg, &Signature{R: adaptor.R, S: adaptor.S}) {
+			t.Fatalf("testcase %d expected the adaptor signature not to be a signature", i)
+		}
+		sig := adaptor.Complete(c, secret)
+		if !Verify(&priv.PublicKey, msg, sig) {
+			t.Fatalf("testcase %d expected the completed signature to verify", i)
+		}
+		got, err := adaptor.Extract(c, point, sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Cmp(secret) != 0 {
+			t.Fatalf("testcase %d expected to extract the secret", i)
+		}
+		other, err := Sign(rand.Reader, priv, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := adaptor.Extract(c, point, other); err == nil {
+			t.Fatalf("testcase %d expected an unrelated signature not to extract", i)
+		}
+	}
+}
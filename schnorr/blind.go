@@ -0,0 +1,251 @@
+package schnorr
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+// MaxConcurrentSessions is the largest number of sessions a plain blind
+// signer keeps open at once, for which the birthday attack costs about
+// 2^85 operations
+const MaxConcurrentSessions = 4
+
+// MaxConcurrentClauseSessions is the largest number of sessions a clause
+// blind signer keeps open at once
+const MaxConcurrentClauseSessions = 256
+
+// deriveTweak returns H(P, info)
+func deriveTweak(pub *PublicKey, info []byte) *big.Int {
+	c := pub.Curve
+	return c.HashToScalar("badcrypto schnorr partially blind", c.Marshal(pub.Point), info)
+}
+
+// Derive returns the key that signs partially blind signatures for the
+// public information info. An empty info returns the key itself.
+func (pub *PublicKey) Derive(info []byte) *PublicKey {
+	if len(info) == 0 {
+		return pub
+	}
+	c := pub.Curve
+	return &PublicKey{Curve: c, Point: c.Add(pub.Point, c.ScalarBaseMult(deriveTweak(pub, info)))}
+}
+
+// Derive returns the private key of PublicKey.Derive
+func (priv *PrivateKey) Derive(info []byte) *PrivateKey {
+	if len(info) == 0 {
+		return priv
+	}
+	d := new(big.Int).Add(priv.D, deriveTweak(&priv.PublicKey, info))
+	d.Mod(d, priv.Curve.N)
+	c := priv.Curve
+	return &PrivateKey{PublicKey: PublicKey{Curve: c, Point: c.ScalarBaseMult(d)}, D: d}
+}
+
+// SignerCommitment is the first message of a session, from the signer:
+// one nonce point R, or two for the clause variant
+type SignerCommitment struct {
+	Session uint64
+	Info    []byte
+	R       []*ec.Point
+}
+
+// BlindRequest is the second message of a session, from the user: the
+// blinded challenges, one per point of the commitment
+type BlindRequest struct {
+	Session uint64
+	E       []*big.Int
+}
+
+// BlindResponse is the last message of a session, from the signer: the
+// answer S to the challenge of the clause it picked
+type BlindResponse struct {
+	Session uint64
+	Clause  int
+	S       *big.Int
+}
+
+// session is the secret state of an open session
+type session struct {
+	info   []byte
+	nonces []*big.Int
+}
+
+// BlindSigner runs the signer side of blind signing sessions, and limits
+// how many of them are open at once. It is safe for concurrent use.
+type BlindSigner struct {
+	priv    *PrivateKey
+	clauses int
+	limit   int
+
+	mu   sync.Mutex
+	next uint64
+	open map[uint64]*session
+}
+
+// NewBlindSigner returns a signer of plain blind signatures that keeps at
+// most concurrent sessions open, between 1 and MaxConcurrentSessions. One
+// is the only setting with a security proof.
+func NewBlindSigner(priv *PrivateKey, concurrent int) (*BlindSigner, error) {
+	if concurrent < 1 || concurrent > MaxConcurrentSessions {
+		return nil, fmt.Errorf("schnorr: concurrent sessions must be between 1 and %d", MaxConcurrentSessions)
+	}
+	return &BlindSigner{priv: priv, clauses: 1, limit: concurrent, open: make(map[uint64]*session)}, nil
+}
+
+// NewClauseBlindSigner returns a signer of clause blind signatures that
+// keeps at most concurrent sessions open, between 1 and
+// MaxConcurrentClauseSessions
+func NewClauseBlindSigner(priv *PrivateKey, concurrent int) (*BlindSigner, error) {
+	if concurrent < 1 || concurrent > MaxConcurrentClauseSessions {
+		return nil, fmt.Errorf("schnorr: concurrent sessions must be between 1 and %d", MaxConcurrentClauseSessions)
+	}
+	return &BlindSigner{priv: priv, clauses: 2, limit: concurrent, open: make(map[uint64]*session)}, nil
+}
+
+// Commit opens a session for a signature with the public information
+// info, empty for a fully blind signature. It fails when the signer
+// already has as many sessions open as its limit, until Respond or Abort
+// closes one.
+func (s *BlindSigner) Commit(rand io.Reader, info []byte) (*SignerCommitment, error) {
+	c := s.priv.Curve
+	sess := &session{info: append([]byte(nil), info...), nonces: make([]*big.Int, s.clauses)}
+	points := make([]*ec.Point, s.clauses)
+	for i := range sess.nonces {
+		k, err := c.RandomScalar(rand)
+		if err != nil {
+			return nil, err
+		}
+		sess.nonces[i], points[i] = k, c.ScalarBaseMult(k)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.open) >= s.limit {
+		return nil, errors.New("schnorr: too many concurrent blind signing sessions")
+	}
+	id := s.next
+	s.next++
+	s.open[id] = sess
+	return &SignerCommitment{Session: id, Info: sess.info, R: points}, nil
+}
+
+// Abort closes a session without answering it
+func (s *BlindSigner) Abort(id uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.open, id)
+}
+
+// Respond answers the challenges of a session and closes it, so that its
+// nonces are never used twice. The clause signer answers one of its two
+// challenges picked at random.
+func (s *BlindSigner) Respond(rand io.Reader, req *BlindRequest) (*BlindResponse, error) {
+	s.mu.Lock()
+	sess, ok := s.open[req.Session]
+	delete(s.open, req.Session)
+	s.mu.Unlock()
+	if !ok {
+		return nil, errors.New("schnorr: unknown or closed blind signing session")
+	}
+	c := s.priv.Curve
+	if len(req.E) != len(sess.nonces) {
+		return nil, errors.New("schnorr: wrong number of challenges")
+	}
+	for _, e := range req.E {
+		if e == nil || e.Sign() < 0 || e.Cmp(c.N) >= 0 {
+			return nil, errors.New("schnorr: invalid challenge")
+		}
+	}
+	clause := 0
+	if len(sess.nonces) > 1 {
+		b, err := randomClause(rand, len(sess.nonces))
+		if err != nil {
+			return nil, err
+		}
+		clause = b
+	}
+	priv := s.priv.Derive(sess.info)
+	return &BlindResponse{
+		Session: req.Session,
+		Clause:  clause,
+		S:       respond(c, sess.nonces[clause], req.E[clause], priv.D),
+	}, nil
+}
+
+func randomClause(r io.Reader, n int) (int, error) {
+	b, err := rand.Int(r, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(b.Int64()), nil
+}
+
+// Unblinder is the secret state of the user in a session
+type Unblinder struct {
+	pub     *PublicKey
+	session uint64
+	points  []*ec.Point
+	blinded []*ec.Point
+	e       []*big.Int
+	alpha   []*big.Int
+}
+
+// Blind prepares the request for a signature of msg under pub, from the
+// commitment of the signer. The user must check that the public
+// information of the commitment is the one it expects.
+func Blind(rand io.Reader, pub *PublicKey, msg []byte, commitment *SignerCommitment) (*BlindRequest, *Unblinder, error) {
+	c := pub.Curve
+	if len(commitment.R) == 0 || len(commitment.R) > 2 {
+		return nil, nil, errors.New("schnorr: invalid number of nonce points")
+	}
+	key := pub.Derive(commitment.Info)
+	u := &Unblinder{pub: key, session: commitment.Session}
+	req := &BlindRequest{Session: commitment.Session}
+	for _, r := range commitment.R {
+		if r == nil || r.IsInfinity() || !c.IsOnCurve(r) {
+			return nil, nil, errors.New("schnorr: invalid nonce point")
+		}
+		alpha, err := c.RandomScalar(rand)
+		if err != nil {
+			return nil, nil, err
+		}
+		beta, err := c.RandomScalar(rand)
+		if err != nil {
+			return nil, nil, err
+		}
+		// R' = R + a*G + b*P, e = H(R', P, m) + b
+		blinded := c.Add(r, c.MultiScalarMult([]*big.Int{alpha, beta}, []*ec.Point{c.Generator(), key.Point}))
+		e := challenge(c, blinded, key.Point, msg)
+		e.Add(e, beta).Mod(e, c.N)
+		u.points = append(u.points, r)
+		u.blinded = append(u.blinded, blinded)
+		u.e = append(u.e, e)
+		u.alpha = append(u.alpha, alpha)
+		req.E = append(req.E, e)
+	}
+	return req, u, nil
+}
+
+// Unblind checks the answer of the signer and returns the signature of the
+// message. Under partially blind signing, the signature verifies with the
+// key derived for the public information.
+func (u *Unblinder) Unblind(resp *BlindResponse) (*Signature, error) {
+	c := u.pub.Curve
+	if resp.Session != u.session || resp.Clause < 0 || resp.Clause >= len(u.points) {
+		return nil, errors.New("schnorr: response does not match the session")
+	}
+	if resp.S == nil || resp.S.Sign() < 0 || resp.S.Cmp(c.N) >= 0 {
+		return nil, errors.New("schnorr: invalid response")
+	}
+	i := resp.Clause
+	if !c.ScalarBaseMult(resp.S).Equal(c.Add(u.points[i], c.ScalarMult(u.pub.Point, u.e[i]))) {
+		return nil, errors.New("schnorr: invalid response")
+	}
+	s := new(big.Int).Add(resp.S, u.alpha[i])
+	return &Signature{R: u.blinded[i], S: s.Mod(s, c.N)}, nil
+}
@@ -0,0 +1,130 @@
+package schnorr
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+func TestBlindSign(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		curve  *ec.Curve
+		clause bool
+		info   []byte
+	}{
+		{ec.P256(), false, nil},
+		{ec.Secp256k1(), false, nil},
+		{ec.P256(), true, nil},
+		{ec.P256(), false, []byte("expires 2027-01-01")},
+		{ec.Secp256k1(), true, []byte("expires 2027-01-01")},
+	}
+	for i, tc := range testcases {
+		priv, err := GenerateKey(rand.Reader, tc.curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signer, err := NewBlindSigner(priv, 1)
+		if tc.clause {
+			signer, err = NewClauseBlindSigner(priv, 1)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := []byte("token serial 42")
+		commitment, err := signer.Commit(rand.Reader, tc.info)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, u, err := Blind(rand.Reader, &priv.PublicKey, msg, commitment)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := signer.Respond(rand.Reader, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := u.Unblind(resp)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		pub := priv.PublicKey.Derive(tc.info)
+		if !Verify(pub, msg, sig) {
+			t.Fatalf("testcase %d expected blind signature to verify", i)
+		}
+		for _, r := range commitment.R {
+			if sig.R.Equal(r) {
+				t.Fatalf("testcase %d expected the nonce point to be blinded", i)
+			}
+		}
+		if resp.S.Cmp(sig.S) == 0 {
+			t.Fatalf("testcase %d expected the response to be blinded", i)
+		}
+		if tc.info != nil {
+			if Verify(&priv.PublicKey, msg, sig) {
+				t.Fatalf("testcase %d expected partially blind signature to fail without its information", i)
+			}
+			if Verify(priv.PublicKey.Derive([]byte("expires 2099-01-01")), msg, sig) {
+				t.Fatalf("testcase %d expected partially blind signature to fail with other information", i)
+			}
+		}
+	}
+}
+
+func TestBlindSessionLimits(t *testing.T) {
+	t.Parallel()
+	c := ec.P256()
+	priv, err := GenerateKey(rand.Reader, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewBlindSigner(priv, 0); err == nil {
+		t.Fatal("expected an error for no concurrent session")
+	}
+	if _, err := NewBlindSigner(priv, MaxConcurrentSessions+1); err == nil {
+		t.Fatal("expected an error above the concurrent session limit")
+	}
+	if _, err := NewClauseBlindSigner(priv, MaxConcurrentClauseSessions+1); err == nil {
+		t.Fatal("expected an error above the clause concurrent session limit")
+	}
+	signer, err := NewBlindSigner(priv, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := signer.Commit(rand.Reader, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := signer.Commit(rand.Reader, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signer.Commit(rand.Reader, nil); err == nil {
+		t.Fatal("expected a third concurrent session to fail")
+	}
+	signer.Abort(second.Session)
+	if _, err := signer.Commit(rand.Reader, nil); err != nil {
+		t.Fatalf("expected a session to open after an abort but got %v", err)
+	}
+	req, u, err := Blind(rand.Reader, &priv.PublicKey, []byte("msg"), first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := signer.Respond(rand.Reader, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the nonce of a session is never used twice
+	if _, err := signer.Respond(rand.Reader, req); err == nil {
+		t.Fatal("expected a second answer in the same session to fail")
+	}
+	if _, err := signer.Respond(rand.Reader, &BlindRequest{Session: second.Session, E: req.E}); err == nil {
+		t.Fatal("expected an answer in an aborted session to fail")
+	}
+	// a signer that answers garbage
+	resp.S.Add(resp.S, resp.S).Mod(resp.S, c.N)
+	if _, err := u.Unblind(resp); err == nil {
+		t.Fatal("expected an invalid response to fail")
+	}
+}
@@ -0,0 +1,153 @@
+// Package schnorr implements Schnorr signatures over the curves of the ec
+// package, and blind signatures built on them.
+//
+// A signature of m by the key P = x*G is a point R = k*G for a random
+// nonce k and the scalar s = k + e*x, where e = H(R, P, m). It verifies
+// s*G = R + e*P. Hashing the public key with R, key prefixing, keeps
+// signatures from moving between related keys, which the partially blind
+// signatures of this package rely on. The hash is ec.HashToScalar, so
+// signatures are not compatible with BIP 340 or Ed25519.
+//
+// Blind signatures let a signer, say a token issuer, sign a message it
+// never sees: the signature it later meets cannot be linked to the
+// session that produced it.
+//
+// In a session, the signer sends R = k*G. The user picks blinding
+// factors a and b, computes R' = R + a*G + b*P and e' = H(R', P, m), and
+// sends e = e' + b. The signer answers s = k + e*x, and the user outputs
+// the signature (R', s + a) of m, which is an ordinary Schnorr signature.
+//
+// Blind Schnorr signatures are only secure for sessions that run one
+// after the other. With l sessions open at once, a user can pick the
+// challenges so that l answers yield l+1 signatures: the ROS attack of
+// Benhamouda, Lepoint, Loss, Orru and Raykova (2021) is polynomial once
+// l exceeds the bit length of N, and the generalized birthday attack of
+// Wagner costs about 2^(256/(1+log2 l)) below that. A BlindSigner
+// therefore refuses to open more than a fixed number of sessions at once.
+//
+// The clause variant of Fuchsbauer, Plouviez and Seurin (2020) runs two
+// sessions in parallel and lets the signer answer only one of them, picked
+// at random after the challenges come in, which defeats the ROS attack.
+// It costs a second point and challenge per session, and allows more
+// concurrent sessions.
+//
+// A partially blind signature also covers public information agreed on by
+// both parties, such as an expiry date, which the signer sees. It is a
+// blind signature under the key P + H(P, info)*G.
+//
+// Nothing in here is constant time.
+package schnorr
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+// PublicKey is a Schnorr verification key
+type PublicKey struct {
+	Curve *ec.Curve
+	Point *ec.Point
+}
+
+// PrivateKey is a Schnorr signing key
+type PrivateKey struct {
+	PublicKey
+	D *big.Int
+}
+
+// Signature is the pair (R, s)
+type Signature struct {
+	R *ec.Point
+	S *big.Int
+}
+
+// GenerateKey returns a new random key on c
+func GenerateKey(rand io.Reader, c *ec.Curve) (*PrivateKey, error) {
+	d, err := c.RandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{PublicKey: PublicKey{Curve: c, Point: c.ScalarBaseMult(d)}, D: d}, nil
+}
+
+// challenge returns e = H(R, P, m)
+func challenge(c *ec.Curve, r, pub *ec.Point, msg []byte) *big.Int {
+	return c.HashToScalar("badcrypto schnorr challenge", c.Marshal(r), c.Marshal(pub), msg)
+}
+
+// nonce hashes fresh randomness with the key and the message into a
+// scalar, retrying on the negligible chance it is zero
+func nonce(rand io.Reader, priv *PrivateKey, msg []byte) (*big.Int, error) {
+	c := priv.Curve
+	entropy := make([]byte, 32)
+	for {
+		if _, err := io.ReadFull(rand, entropy); err != nil {
+			return nil, err
+		}
+		k := c.HashToScalar("badcrypto schnorr nonce", priv.D.Bytes(), entropy, msg)
+		if k.Sign() != 0 {
+			return k, nil
+		}
+	}
+}
+
+// Sign signs msg with priv
+func Sign(rand io.Reader, priv *PrivateKey, msg []byte) (*Signature, error) {
+	c := priv.Curve
+	k, err := nonce(rand, priv, msg)
+	if err != nil {
+		return nil, err
+	}
+	r := c.ScalarBaseMult(k)
+	e := challenge(c, r, priv.Point, msg)
+	return &Signature{R: r, S: respond(c, k, e, priv.D)}, nil
+}
+
+// respond returns s = k + e*x mod N
+func respond(c *ec.Curve, k, e, x *big.Int) *big.Int {
+	s := new(big.Int).Mul(e, x)
+	s.Add(s, k)
+	return s.Mod(s, c.N)
+}
+
+// Verify checks the signature of msg by pub
+func Verify(pub *PublicKey, msg []byte, sig *Signature) bool {
+	c := pub.Curve
+	if sig == nil || sig.R == nil || sig.S == nil || sig.R.IsInfinity() || !c.IsOnCurve(sig.R) ||
+		sig.S.Sign() < 0 || sig.S.Cmp(c.N) >= 0 {
+		return false
+	}
+	if pub.Point == nil || pub.Point.IsInfinity() || !c.IsOnCurve(pub.Point) {
+		return false
+	}
+	e := challenge(c, sig.R, pub.Point, msg)
+	return c.ScalarBaseMult(sig.S).Equal(c.Add(sig.R, c.ScalarMult(pub.Point, e)))
+}
+
+// Marshal encodes sig as the compressed point R followed by s
+func (sig *Signature) Marshal(c *ec.Curve) []byte {
+	out := c.Marshal(sig.R)
+	s := make([]byte, c.ByteLen())
+	sig.S.FillBytes(s)
+	return append(out, s...)
+}
+
+// ParseSignature decodes a signature encoded by Marshal
+func ParseSignature(c *ec.Curve, b []byte) (*Signature, error) {
+	size := c.ByteLen()
+	if len(b) != 1+2*size {
+		return nil, errors.New("schnorr: invalid signature length")
+	}
+	r, err := c.Unmarshal(b[:1+size])
+	if err != nil {
+		return nil, err
+	}
+	s := new(big.Int).SetBytes(b[1+size:])
+	if s.Cmp(c.N) >= 0 {
+		return nil, errors.New("schnorr: invalid signature scalar")
+	}
+	return &Signature{R: r, S: s}, nil
+}
@@ -0,0 +1,50 @@
+package schnorr
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+func TestSignVerify(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		curve *ec.Curve
+		msg   []byte
+	}{
+		{ec.P256(), []byte("hello")},
+		{ec.Secp256k1(), []byte("hello")},
+		{ec.P256(), nil},
+	}
+	for i, tc := range testcases {
+		priv, err := GenerateKey(rand.Reader, tc.curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := Sign(rand.Reader, priv, tc.msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(&priv.PublicKey, tc.msg, sig) {
+			t.Fatalf("testcase %d expected signature to verify", i)
+		}
+		if Verify(&priv.PublicKey, []byte("other"), sig) {
+			t.Fatalf("testcase %d expected signature of another message to fail", i)
+		}
+		other, err := GenerateKey(rand.Reader, tc.curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if Verify(&other.PublicKey, tc.msg, sig) {
+			t.Fatalf("testcase %d expected signature to fail with another key", i)
+		}
+		parsed, err := ParseSignature(tc.curve, sig.Marshal(tc.curve))
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if !Verify(&priv.PublicKey, tc.msg, parsed) {
+			t.Fatalf("testcase %d expected parsed signature to verify", i)
+		}
+	}
+}
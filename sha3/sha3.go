@@ -0,0 +1,210 @@
+// Package sha3 implements the SHA-3 hash functions and the SHAKE
+// extendable output functions of FIPS 202, on the Keccak-f[1600]
+// permutation.
+//
+// Keccak is a sponge: the state of 1600 bits is split into the rate,
+// which absorbs the input and squeezes out the output a block at a time,
+// and the capacity, which is never exposed and sets the security level.
+// Unlike SHA-2, the output of the sponge is not its whole state, so there
+// is no length extension, and SHAKE128 and SHAKE256 read as many bytes as
+// needed from the same construction. ML-KEM and ML-DSA use all four.
+package sha3
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+const (
+	// Size256 is the size of a SHA3-256 digest in bytes
+	Size256 = 32
+	// Size384 is the size of a SHA3-384 digest in bytes
+	Size384 = 48
+	// Size512 is the size of a SHA3-512 digest in bytes
+	Size512 = 64
+)
+
+// domain separation suffixes, with the first bit of the padding
+const (
+	dsSHA3  = 0x06
+	dsSHAKE = 0x1f
+)
+
+// roundConstants are the iota constants of the 24 rounds
+var roundConstants = [24]uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808a, 0x8000000080008000,
+	0x000000000000808b, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008a, 0x0000000000000088, 0x0000000080008009, 0x000000008000000a,
+	0x000000008000808b, 0x800000000000008b, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800a, 0x800000008000000a,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+// rotations are the rho offsets and piLanes the pi destinations, in the
+// order the combined rho and pi step walks the lanes
+var (
+	rotations = [24]int{1, 3, 6, 10, 15, 21, 28, 36, 45, 55, 2, 14, 27, 41, 56, 8, 25, 43, 62, 18, 39, 61, 20, 44}
+	piLanes   = [24]int{10, 7, 11, 17, 18, 3, 5, 16, 8, 21, 24, 4, 15, 23, 19, 13, 12, 2, 20, 14, 22, 9, 6, 1}
+)
+
+// keccakF applies the Keccak-f[1600] permutation to the state, lane x, y
+// being a[x+5y]
+func keccakF(a *[25]uint64) {
+	var c [5]uint64
+	for _, rc := range roundConstants {
+		// theta: each column is mixed with its two neighbours
+		for x := 0; x < 5; x++ {
+			c[x] = a[x] ^ a[x+5] ^ a[x+10] ^ a[x+15] ^ a[x+20]
+		}
+		for x := 0; x < 5; x++ {
+			d := c[(x+4)%5] ^ bits.RotateLeft64(c[(x+1)%5], 1)
+			for y := 0; y < 25; y += 5 {
+				a[x+y] ^= d
+			}
+		}
+		// rho and pi: lanes are rotated and moved
+		t := a[1]
+		for i, dst := range piLanes {
+			t, a[dst] = a[dst], bits.RotateLeft64(t, rotations[i])
+		}
+		// chi: the only non linear step, along rows
+		for y := 0; y < 25; y += 5 {
+			copy(c[:], a[y:y+5])
+			for x := 0; x < 5; x++ {
+				a[x+y] = c[x] ^ (^c[(x+1)%5] & c[(x+2)%5])
+			}
+		}
+		// iota
+		a[0] ^= rc
+	}
+}
+
+// state is a Keccak sponge
+type state struct {
+	a    [25]uint64
+	buf  [200]byte
+	n    int // bytes in buf, absorbed or left to squeeze
+	rate int
+	size int // digest size, 0 for SHAKE
+	ds   byte
+	// squeezing is set once the padding is absorbed
+	squeezing bool
+}
+
+func newState(rate, size int, ds byte) *state {
+	return &state{rate: rate, size: size, ds: ds}
+}
+
+// New256 returns a hash.Hash computing SHA3-256
+func New256() hash.Hash {
+	return newState(136, Size256, dsSHA3)
+}
+
+// New384 returns a hash.Hash computing SHA3-384
+func New384() hash.Hash {
+	return newState(104, Size384, dsSHA3)
+}
+
+// New512 returns a hash.Hash computing SHA3-512
+func New512() hash.Hash {
+	return newState(72, Size512, dsSHA3)
+}
+
+// Sum256 returns the SHA3-256 digest of data
+func Sum256(data []byte) [Size256]byte {
+	d := New256()
+	d.Write(data)
+	var out [Size256]byte
+	d.Sum(out[:0])
+	return out
+}
+
+// Sum512 returns the SHA3-512 digest of data
+func Sum512(data []byte) [Size512]byte {
+	d := New512()
+	d.Write(data)
+	var out [Size512]byte
+	d.Sum(out[:0])
+	return out
+}
+
+func (s *state) Size() int { return s.size }
+
+func (s *state) BlockSize() int { return s.rate }
+
+func (s *state) Reset() {
+	*s = state{rate: s.rate, size: s.size, ds: s.ds}
+}
+
+// absorb xors the buffer into the rate of the state and permutes it
+func (s *state) absorb() {
+	for i := 0; i < s.rate/8; i++ {
+		s.a[i] ^= binary.LittleEndian.Uint64(s.buf[8*i:])
+	}
+	keccakF(&s.a)
+}
+
+func (s *state) Write(p []byte) (int, error) {
+	if s.squeezing {
+		panic("sha3: write after read")
+	}
+	written := len(p)
+	for len(p) > 0 {
+		c := copy(s.buf[s.n:s.rate], p)
+		s.n += c
+		p = p[c:]
+		if s.n == s.rate {
+			s.absorb()
+			s.n = 0
+		}
+	}
+	return written, nil
+}
+
+// pad absorbs the last block, with the domain separation bits and the
+// final bit of the padding, and moves the sponge to squeezing
+func (s *state) pad() {
+	for i := s.n; i < s.rate; i++ {
+		s.buf[i] = 0
+	}
+	s.buf[s.n] ^= s.ds
+	s.buf[s.rate-1] ^= 0x80
+	s.absorb()
+	s.squeezing = true
+	s.squeezeBlock()
+}
+
+// squeezeBlock copies the rate of the state to the buffer
+func (s *state) squeezeBlock() {
+	for i := 0; i < s.rate/8; i++ {
+		binary.LittleEndian.PutUint64(s.buf[8*i:], s.a[i])
+	}
+	s.n = s.rate
+}
+
+// Read squeezes output out of the sponge, any amount at a time
+func (s *state) Read(out []byte) (int, error) {
+	if !s.squeezing {
+		s.pad()
+	}
+	read := len(out)
+	for len(out) > 0 {
+		if s.n == 0 {
+			keccakF(&s.a)
+			s.squeezeBlock()
+		}
+		c := copy(out, s.buf[s.rate-s.n:s.rate])
+		s.n -= c
+		out = out[c:]
+	}
+	return read, nil
+}
+
+func (s *state) Sum(in []byte) []byte {
+	// work on a copy, so the caller can keep writing
+	s0 := *s
+	out := make([]byte, s.size)
+	s0.Read(out)
+	return append(in, out...)
+}
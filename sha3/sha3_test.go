@@ -0,0 +1,129 @@
+package sha3
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestVectors(t *testing.T) {
+	t.Parallel()
+	// 200 bytes spans more than one block at every rate
+	var testcases = []struct {
+		input                  string
+		sha256, sha384, sha512 string
+	}{
+		{
+			"",
+			"a7ffc6f8bf1ed76651c14756a061d662f580ff4de43b49fa82d80a4b80f8434a",
+			"0c63a75b845e4f7d01107d852e4c2485c51a50aaaa94fc61995e71bbee983a2ac3713831264adb47fb6bd1e058d5f004",
+			"a69f73cca23a9ac5c8b567dc185a756e97c982164fe25859e0d1dcc1475c80a615b2123af1f5f94c11e3e9402c3ac558f500199d95b6d3e301758586281dcd26",
+		},
+		{
+			"abc",
+			"3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532",
+			"ec01498288516fc926459f58e2c6ad8df9b473cb0fc08c2596da7cf0e49be4b298d88cea927ac7f539f1edf228376d25",
+			"b751850b1a57168a5693cd924b6b096e08f621827444f70d884f5d0240d2712e10e116e9192af3c91a7ec57647e3934057340b4cf408d5a56592f8274eec53f0",
+		},
+		{
+			strings.Repeat("a", 200),
+			"cce34485baf2bf2aca99b94833892a4f52896d3d153f7b840cc4f9fe695f1387",
+			"f97756776c1874724c94a8008f7f155553b4bf00fbf8fbeac246624ad59c258a3c0977d9f2543d7cbd75b9ac8fdc0d40",
+			"eae6c85c6904f11075de9f9d5e1064371d000510fa3d2d79d40cf9be34892fb01859d0a0234e138bcb0ad5c84f6c0dca226a414b0c9a2897cb695f5185fe36ec",
+		},
+	}
+	for i, tc := range testcases {
+		sum256 := Sum256([]byte(tc.input))
+		if hex.EncodeToString(sum256[:]) != tc.sha256 {
+			t.Fatalf("testcase %d expected %s but got %x", i, tc.sha256, sum256)
+		}
+		h := New384()
+		h.Write([]byte(tc.input))
+		if sum := hex.EncodeToString(h.Sum(nil)); sum != tc.sha384 {
+			t.Fatalf("testcase %d expected %s but got %s", i, tc.sha384, sum)
+		}
+		sum512 := Sum512([]byte(tc.input))
+		if hex.EncodeToString(sum512[:]) != tc.sha512 {
+			t.Fatalf("testcase %d expected %s but got %x", i, tc.sha512, sum512)
+		}
+	}
+}
+
+func TestShake(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		input              string
+		shake128, shake256 string
+	}{
+		{
+			"",
+			"7f9c2ba4e88f827d616045507605853ed73b8093f6efbc88eb1a6eacfa66ef26",
+			"46b9dd2b0ba88d13233b3feb743eeb243fcd52ea62b81b82b50c27646ed5762fd75dc4ddd8c0f200cb05019d67b592f6fc821c49479ab48640292eacb3b7c4be",
+		},
+		{
+			"abc",
+			"5881092dd818bf5cf8a3ddb793fbcba74097d5c526a6d35f97b83351940f2cc8",
+			"483366601360a8771c6863080cc4114d8db44530f8f1e1ee4f94ea37e78b5739d5a15bef186a5386c75744c0527e1faa9f8726e462a12a4feb06bd8801e751e4",
+		},
+		{
+			strings.Repeat("a", 200),
+			"70ac9b97e891be583e08929ce4cce50d346b05f9597356d6af94d4643d2af3b6",
+			"e49647491c9d12d125a2f75826c96f6307d2fabebcbb9fb1616d76b09499380e8bcf60f72750879140e73fb7453a979b69d25efa8de613462f108ce7f2f1d7c5",
+		},
+	}
+	for i, tc := range testcases {
+		out := make([]byte, 32)
+		ShakeSum128(out, []byte(tc.input))
+		if hex.EncodeToString(out) != tc.shake128 {
+			t.Fatalf("testcase %d expected %s but got %x", i, tc.shake128, out)
+		}
+		out = make([]byte, 64)
+		ShakeSum256(out, []byte(tc.input))
+		if hex.EncodeToString(out) != tc.shake256 {
+			t.Fatalf("testcase %d expected %s but got %x", i, tc.shake256, out)
+		}
+	}
+}
+
+func TestIncremental(t *testing.T) {
+	t.Parallel()
+	data := bytes.Repeat([]byte("keccak"), 100)
+	// writes of any size give the same digest
+	whole := Sum256(data)
+	h := New256()
+	for i := 0; i < len(data); i += 7 {
+		end := i + 7
+		if end > len(data) {
+			end = len(data)
+		}
+		h.Write(data[i:end])
+	}
+	if !bytes.Equal(h.Sum(nil), whole[:]) {
+		t.Fatal("incremental writes gave a different digest")
+	}
+	// and reads of any size the same output, across many blocks
+	long := make([]byte, 1000)
+	ShakeSum128(long, data)
+	x := NewShake128()
+	x.Write(data)
+	for i := 0; i < len(long); i += 13 {
+		end := i + 13
+		if end > len(long) {
+			end = len(long)
+		}
+		part := make([]byte, end-i)
+		x.Read(part)
+		if !bytes.Equal(part, long[i:end]) {
+			t.Fatalf("read at %d differs", i)
+		}
+	}
+	// Sum does not change the state
+	h.Reset()
+	h.Write(data[:10])
+	h.Sum(nil)
+	h.Write(data[10:])
+	if !bytes.Equal(h.Sum(nil), whole[:]) {
+		t.Fatal("Sum changed the state")
+	}
+}
@@ -0,0 +1,37 @@
+package sha3
+
+import (
+	"hash"
+	"io"
+)
+
+// ShakeHash is an extendable output function: once written, it can be
+// read from indefinitely. Writing after reading panics.
+type ShakeHash interface {
+	hash.Hash
+	io.Reader
+}
+
+// NewShake128 returns SHAKE128, whose Sum appends 32 bytes
+func NewShake128() ShakeHash {
+	return newState(168, 32, dsSHAKE)
+}
+
+// NewShake256 returns SHAKE256, whose Sum appends 64 bytes
+func NewShake256() ShakeHash {
+	return newState(136, 64, dsSHAKE)
+}
+
+// ShakeSum128 writes len(out) bytes of the SHAKE128 output of data to out
+func ShakeSum128(out, data []byte) {
+	h := NewShake128()
+	h.Write(data)
+	h.Read(out)
+}
+
+// ShakeSum256 writes len(out) bytes of the SHAKE256 output of data to out
+func ShakeSum256(out, data []byte) {
+	h := NewShake256()
+	h.Write(data)
+	h.Read(out)
+}
@@ -0,0 +1,263 @@
+// Package shamir implements Shamir's secret sharing: a secret is hidden in
+// the constant term of a random polynomial of degree k-1, each of the n
+// shares is a point on that polynomial, and any k of them recover the
+// secret by Lagrange interpolation at zero while k-1 reveal nothing.
+//
+// The polynomials live either in GF(2^8), where every byte of the secret is
+// shared independently, or in the integers modulo a prime chosen by the
+// caller, where the whole secret is a single field element.
+package shamir
+
+import (
+	cryptorand "crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// Field selects the arithmetic of the sharing polynomials. The zero value
+// is GF(2^8). Setting Prime shares the secret modulo that prime instead.
+type Field struct {
+	Prime *big.Int
+}
+
+// Prime521 is the Mersenne prime 2^521-1, large enough to share a 64 bytes
+// secret in a single field element
+var Prime521 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 521), big.NewInt(1))
+
+// Share is one point (X, P(X)) of the sharing polynomial. In GF(2^8), Y
+// holds one evaluation per byte of the secret. In a prime field, Y is the
+// big endian evaluation padded to the size of the prime.
+type Share struct {
+	X uint16
+	Y []byte
+}
+
+// Bytes serializes the share as X in two big endian bytes followed by Y
+func (s Share) Bytes() []byte {
+	out := make([]byte, 2, 2+len(s.Y))
+	binary.BigEndian.PutUint16(out, s.X)
+	return append(out, s.Y...)
+}
+
+// ParseShare is the inverse of Share.Bytes
+func ParseShare(b []byte) (Share, error) {
+	if len(b) < 3 {
+		return Share{}, errors.New("shamir: share is too short")
+	}
+	s := Share{X: binary.BigEndian.Uint16(b), Y: append([]byte{}, b[2:]...)}
+	if s.X == 0 {
+		return Share{}, errors.New("shamir: share has a zero x coordinate")
+	}
+	return s, nil
+}
+
+// Split shares secret over GF(2^8)
+func Split(rand io.Reader, secret []byte, n, k int) ([]Share, error) {
+	return Field{}.Split(rand, secret, n, k)
+}
+
+// Combine recovers a secret shared over GF(2^8)
+func Combine(shares []Share) ([]byte, error) {
+	return Field{}.Combine(shares)
+}
+
+// Split returns n shares of secret, any k of which recover it
+func (f Field) Split(rand io.Reader, secret []byte, n, k int) ([]Share, error) {
+	if k < 1 || n < k {
+		return nil, fmt.Errorf("shamir: invalid threshold %d of %d", k, n)
+	}
+	if len(secret) == 0 {
+		return nil, errors.New("shamir: empty secret")
+	}
+	if f.Prime == nil {
+		return splitGF256(rand, secret, n, k)
+	}
+	return f.splitPrime(rand, secret, n, k)
+}
+
+// Combine interpolates the shares at zero and returns the secret. It cannot
+// tell if fewer than k shares were given, the result is then garbage.
+func (f Field) Combine(shares []Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("shamir: no shares")
+	}
+	seen := make(map[uint16]bool)
+	for _, s := range shares {
+		if s.X == 0 {
+			return nil, errors.New("shamir: share has a zero x coordinate")
+		}
+		if seen[s.X] {
+			return nil, fmt.Errorf("shamir: duplicate share %d", s.X)
+		}
+		seen[s.X] = true
+		if len(s.Y) != len(shares[0].Y) {
+			return nil, errors.New("shamir: shares have different lengths")
+		}
+	}
+	if f.Prime == nil {
+		return combineGF256(shares)
+	}
+	return f.combinePrime(shares)
+}
+
+func splitGF256(rand io.Reader, secret []byte, n, k int) ([]Share, error) {
+	if n > 255 {
+		return nil, errors.New("shamir: GF(2^8) supports at most 255 shares")
+	}
+	shares := make([]Share, n)
+	for i := range shares {
+		shares[i] = Share{X: uint16(i + 1), Y: make([]byte, len(secret))}
+	}
+	coeffs := make([]byte, k)
+	for j, b := range secret {
+		coeffs[0] = b
+		if _, err := io.ReadFull(rand, coeffs[1:]); err != nil {
+			return nil, err
+		}
+		for i := range shares {
+			shares[i].Y[j] = evalGF256(coeffs, byte(shares[i].X))
+		}
+	}
+	return shares, nil
+}
+
+func combineGF256(shares []Share) ([]byte, error) {
+	for _, s := range shares {
+		if s.X > 255 {
+			return nil, errors.New("shamir: share x coordinate out of GF(2^8)")
+		}
+	}
+	// the lagrange basis polynomials at zero do not depend on the byte
+	basis := make([]byte, len(shares))
+	for i, si := range shares {
+		num, den := byte(1), byte(1)
+		for j, sj := range shares {
+			if i == j {
+				continue
+			}
+			num = gfMul(num, byte(sj.X))
+			den = gfMul(den, byte(sj.X)^byte(si.X))
+		}
+		basis[i] = gfMul(num, gfInv(den))
+	}
+	secret := make([]byte, len(shares[0].Y))
+	for j := range secret {
+		for i, s := range shares {
+			secret[j] ^= gfMul(s.Y[j], basis[i])
+		}
+	}
+	return secret, nil
+}
+
+// evalGF256 evaluates the polynomial with coefficients coeffs at x using
+// Horner's method
+func evalGF256(coeffs []byte, x byte) byte {
+	var y byte
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		y = gfMul(y, x) ^ coeffs[i]
+	}
+	return y
+}
+
+// gfMul multiplies in GF(2^8) modulo the AES polynomial x^8+x^4+x^3+x+1
+func gfMul(a, b byte) byte {
+	var p byte
+	for b != 0 {
+		if b&1 != 0 {
+			p ^= a
+		}
+		carry := a & 0x80
+		a <<= 1
+		if carry != 0 {
+			a ^= 0x1b
+		}
+		b >>= 1
+	}
+	return p
+}
+
+// gfInv returns a^254, which is the inverse of a non zero a
+func gfInv(a byte) byte {
+	r := byte(1)
+	for i := 0; i < 254; i++ {
+		r = gfMul(r, a)
+	}
+	return r
+}
+
+// size is the length of the big endian encoding of field elements
+func (f Field) size() int {
+	return (f.Prime.BitLen() + 7) / 8
+}
+
+// splitPrime encodes the secret as 0x01 || secret before sharing it, so
+// leading zero bytes survive the round trip
+func (f Field) splitPrime(rand io.Reader, secret []byte, n, k int) ([]Share, error) {
+	if f.Prime.Sign() <= 0 || !f.Prime.ProbablyPrime(20) {
+		return nil, errors.New("shamir: field modulus is not a prime")
+	}
+	s := new(big.Int).SetBytes(append([]byte{1}, secret...))
+	if s.Cmp(f.Prime) >= 0 {
+		return nil, errors.New("shamir: secret is too large for the field")
+	}
+	if n > 0xffff || big.NewInt(int64(n)).Cmp(f.Prime) >= 0 {
+		return nil, errors.New("shamir: too many shares for the field")
+	}
+	coeffs := make([]*big.Int, k)
+	coeffs[0] = s
+	for i := 1; i < k; i++ {
+		c, err := cryptorand.Int(rand, f.Prime)
+		if err != nil {
+			return nil, err
+		}
+		coeffs[i] = c
+	}
+	shares := make([]Share, n)
+	for i := range shares {
+		x := big.NewInt(int64(i + 1))
+		y := new(big.Int)
+		for j := k - 1; j >= 0; j-- {
+			y.Mul(y, x)
+			y.Add(y, coeffs[j])
+			y.Mod(y, f.Prime)
+		}
+		shares[i] = Share{X: uint16(i + 1), Y: y.FillBytes(make([]byte, f.size()))}
+	}
+	return shares, nil
+}
+
+func (f Field) combinePrime(shares []Share) ([]byte, error) {
+	if len(shares[0].Y) != f.size() {
+		return nil, errors.New("shamir: share length does not match the field")
+	}
+	secret := new(big.Int)
+	for i, si := range shares {
+		num, den := big.NewInt(1), big.NewInt(1)
+		xi := big.NewInt(int64(si.X))
+		for j, sj := range shares {
+			if i == j {
+				continue
+			}
+			xj := big.NewInt(int64(sj.X))
+			num.Mul(num, xj)
+			den.Mul(den, new(big.Int).Sub(xj, xi))
+		}
+		den.Mod(den, f.Prime)
+		if den.ModInverse(den, f.Prime) == nil {
+			return nil, errors.New("shamir: shares are not invertible in the field")
+		}
+		term := new(big.Int).SetBytes(si.Y)
+		term.Mul(term, num)
+		term.Mul(term, den)
+		secret.Add(secret, term)
+	}
+	secret.Mod(secret, f.Prime)
+	b := secret.Bytes()
+	if len(b) == 0 || b[0] != 1 {
+		return nil, errors.New("shamir: recovered secret is malformed, not enough shares?")
+	}
+	return b[1:], nil
+}
@@ -0,0 +1,118 @@
+package shamir
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestGFMul(t *testing.T) {
+	t.Parallel()
+	// FIPS 197 section 4.2
+	if got := gfMul(0x57, 0x83); got != 0xc1 {
+		t.Fatalf("expected 0x57*0x83 = 0xc1 but got %#x", got)
+	}
+	for a := 1; a < 256; a++ {
+		if gfMul(byte(a), gfInv(byte(a))) != 1 {
+			t.Fatalf("expected %#x times its inverse to be 1", a)
+		}
+	}
+}
+
+// subsets returns all the subsets of size k of shares
+func subsets(shares []Share, k int) [][]Share {
+	if k == 0 {
+		return [][]Share{nil}
+	}
+	if len(shares) < k {
+		return nil
+	}
+	var out [][]Share
+	for _, rest := range subsets(shares[1:], k-1) {
+		out = append(out, append([]Share{shares[0]}, rest...))
+	}
+	return append(out, subsets(shares[1:], k)...)
+}
+
+func TestSplitCombine(t *testing.T) {
+	t.Parallel()
+	secret := []byte("\x00\x00the quick brown fox jumps over the lazy dog")
+	var testcases = []struct {
+		field Field
+		n, k  int
+	}{
+		{Field{}, 1, 1},
+		{Field{}, 5, 3},
+		{Field{}, 6, 6},
+		{Field{Prime: Prime521}, 5, 3},
+		{Field{Prime: Prime521}, 4, 2},
+	}
+	for i, tc := range testcases {
+		shares, err := tc.field.Split(rand.Reader, secret, tc.n, tc.k)
+		if err != nil {
+			t.Fatalf("testcase %d: %v", i, err)
+		}
+		if len(shares) != tc.n {
+			t.Fatalf("testcase %d expected %d shares but got %d", i, tc.n, len(shares))
+		}
+		for _, subset := range subsets(shares, tc.k) {
+			got, err := tc.field.Combine(subset)
+			if err != nil {
+				t.Fatalf("testcase %d: %v", i, err)
+			}
+			if !bytes.Equal(got, secret) {
+				t.Fatalf("testcase %d expected secret %q but got %q", i, secret, got)
+			}
+		}
+		if tc.k > 1 {
+			got, _ := tc.field.Combine(shares[:tc.k-1])
+			if bytes.Equal(got, secret) {
+				t.Fatalf("testcase %d expected %d shares to not recover the secret", i, tc.k-1)
+			}
+		}
+	}
+}
+
+func TestSerialization(t *testing.T) {
+	t.Parallel()
+	shares, err := Split(rand.Reader, []byte("secret"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed []Share
+	for _, s := range shares[1:] {
+		p, err := ParseShare(s.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed = append(parsed, p)
+	}
+	got, err := Combine(parsed)
+	if err != nil || string(got) != "secret" {
+		t.Fatalf("expected secret back but got %q, %v", got, err)
+	}
+	if _, err := ParseShare([]byte{0, 0, 1}); err == nil {
+		t.Fatal("expected a share at x=0 to be rejected")
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	t.Parallel()
+	if _, err := Split(rand.Reader, []byte("s"), 2, 3); err == nil {
+		t.Fatal("expected a threshold above n to be rejected")
+	}
+	if _, err := Split(rand.Reader, []byte("s"), 256, 2); err == nil {
+		t.Fatal("expected 256 shares in GF(2^8) to be rejected")
+	}
+	if _, err := (Field{Prime: big.NewInt(15)}).Split(rand.Reader, []byte("s"), 2, 2); err == nil {
+		t.Fatal("expected a composite modulus to be rejected")
+	}
+	if _, err := (Field{Prime: big.NewInt(257)}).Split(rand.Reader, []byte("s"), 2, 2); err == nil {
+		t.Fatal("expected a secret larger than the field to be rejected")
+	}
+	shares, _ := Split(rand.Reader, []byte("s"), 3, 2)
+	if _, err := Combine([]Share{shares[0], shares[0]}); err == nil {
+		t.Fatal("expected duplicate shares to be rejected")
+	}
+}
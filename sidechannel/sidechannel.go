@@ -0,0 +1,208 @@
+// Package sidechannel tests whether the running time of a function depends
+// on its input, with the fixed versus random methodology of dudect
+// (Reparaz, Balasch and Verbauwhede, 2017).
+//
+// The target runs on inputs of two classes, a fixed one and random ones,
+// chosen at random for each measurement so that noise such as frequency
+// scaling and other processes affects both classes alike. Welch's t-test
+// then tells whether the two timing distributions have different means.
+// Timings have a long tail of interrupts and preemptions, so the test is
+// also repeated on the samples below a few percentiles, which often
+// reveals leaks the tail hides. An absolute t above 4.5 is the usual
+// evidence of a leak, and above 10 there is no doubt. A passing test
+// only shows that no leak was found with this machine, compiler and
+// number of measurements, not that none exists.
+package sidechannel
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// Fixed and Random are the classes of inputs passed to a Target
+	Fixed  = 0
+	Random = 1
+)
+
+// defaults of Config
+const (
+	defaultMeasurements = 10000
+	defaultThreshold    = 4.5
+)
+
+// percentiles below which the measurements are tested again, the first
+// crops the tail the most
+var percentiles = []float64{0.5, 0.75, 0.9, 0.95, 0.99}
+
+// Target prepares a call of the operation under test on an input of class
+// Fixed or Random, and returns it. Measure prepares all the calls before
+// timing any of them, only the returned functions are timed.
+type Target func(class int) func()
+
+// Config tunes Measure. The zero value uses the defaults.
+type Config struct {
+	// Measurements is the number of timings, 10000 by default. The first
+	// tenth warms up caches and branch predictors and is discarded.
+	Measurements int
+	// Repeat is the number of calls timed together, 1 by default, which
+	// helps for operations close to the resolution of the clock
+	Repeat int
+	// Threshold is the absolute t value above which Result.Leaks reports
+	// a leak, 4.5 by default
+	Threshold float64
+}
+
+// Test is one Welch's t-test
+type Test struct {
+	// Percentile of the measurements kept, 1 for all of them
+	Percentile float64
+	// Samples is the number of measurements kept
+	Samples int
+	// T is the t statistic, positive when the fixed class is slower
+	T float64
+}
+
+// Result holds the statistics of Measure
+type Result struct {
+	Tests     []Test
+	Threshold float64
+	// max is the index of the test with the largest absolute t
+	max int
+}
+
+// T returns the t statistic with the largest absolute value of the tests
+func (r *Result) T() float64 {
+	return r.Tests[r.max].T
+}
+
+// Leaks reports whether a test found a timing difference between the
+// classes
+func (r *Result) Leaks() bool {
+	return math.Abs(r.T()) > r.Threshold
+}
+
+// String returns a verdict
+func (r *Result) String() string {
+	t := r.Tests[r.max]
+	verdict := "no leak found"
+	switch {
+	case math.Abs(t.T) > 10:
+		verdict = "definitely not constant time"
+	case r.Leaks():
+		verdict = "probably not constant time"
+	}
+	return fmt.Sprintf("max |t| = %.2f with %d samples below percentile %g: %s", math.Abs(t.T), t.Samples, t.Percentile, verdict)
+}
+
+// sample is the timing of one measurement
+type sample struct {
+	class    int
+	duration float64
+}
+
+// Measure times target on random classes of inputs, and runs Welch's
+// t-test on the timings. random chooses the classes, crypto/rand when
+// nil.
+func Measure(random io.Reader, target Target, cfg Config) (*Result, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	if cfg.Measurements == 0 {
+		cfg.Measurements = defaultMeasurements
+	}
+	if cfg.Repeat == 0 {
+		cfg.Repeat = 1
+	}
+	if cfg.Threshold == 0 {
+		cfg.Threshold = defaultThreshold
+	}
+	if cfg.Measurements < 100 || cfg.Repeat < 0 || cfg.Threshold < 0 {
+		return nil, errors.New("sidechannel: invalid configuration")
+	}
+	classes := make([]byte, cfg.Measurements)
+	if _, err := io.ReadFull(random, classes); err != nil {
+		return nil, err
+	}
+	// prepare all the inputs first, so that whatever it takes does not
+	// affect the measurements of one class more than the other
+	calls := make([]func(), len(classes))
+	for i, c := range classes {
+		calls[i] = target(int(c & 1))
+	}
+	warmup := cfg.Measurements / 10
+	samples := make([]sample, 0, cfg.Measurements-warmup)
+	for i, f := range calls {
+		start := time.Now()
+		for j := 0; j < cfg.Repeat; j++ {
+			f()
+		}
+		d := time.Since(start)
+		if i >= warmup {
+			samples = append(samples, sample{int(classes[i] & 1), float64(d)})
+		}
+	}
+	return analyze(samples, cfg.Threshold)
+}
+
+// analyze runs the t-tests on all the samples and on those below each
+// percentile
+func analyze(samples []sample, threshold float64) (*Result, error) {
+	sorted := make([]float64, len(samples))
+	for i, s := range samples {
+		sorted[i] = s.duration
+	}
+	sort.Float64s(sorted)
+	r := &Result{Threshold: threshold}
+	for _, p := range append(percentiles, 1) {
+		cutoff := sorted[int(p*float64(len(sorted)-1))]
+		var w welch
+		for _, s := range samples {
+			if s.duration <= cutoff {
+				w.push(s.class, s.duration)
+			}
+		}
+		if w.n[Fixed] < 2 || w.n[Random] < 2 {
+			if p < 1 {
+				// one class is mostly above the percentile, the test
+				// on all the measurements will tell
+				continue
+			}
+			return nil, errors.New("sidechannel: not enough measurements of each class")
+		}
+		r.Tests = append(r.Tests, Test{Percentile: p, Samples: int(w.n[0] + w.n[1]), T: w.t()})
+		if math.Abs(r.Tests[len(r.Tests)-1].T) > math.Abs(r.Tests[r.max].T) {
+			r.max = len(r.Tests) - 1
+		}
+	}
+	return r, nil
+}
+
+// welch accumulates the means and variances of the two classes with
+// Welford's online algorithm
+type welch struct {
+	n, mean, m2 [2]float64
+}
+
+func (w *welch) push(class int, x float64) {
+	w.n[class]++
+	delta := x - w.mean[class]
+	w.mean[class] += delta / w.n[class]
+	w.m2[class] += delta * (x - w.mean[class])
+}
+
+// t returns Welch's t statistic
+func (w *welch) t() float64 {
+	v0 := w.m2[0] / (w.n[0] - 1)
+	v1 := w.m2[1] / (w.n[1] - 1)
+	den := math.Sqrt(v0/w.n[0] + v1/w.n[1])
+	if den == 0 {
+		return 0
+	}
+	return (w.mean[0] - w.mean[1]) / den
+}
@@ -0,0 +1,109 @@
+package sidechannel
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+func TestWelch(t *testing.T) {
+	t.Parallel()
+	var w welch
+	for _, x := range []float64{1, 2, 3, 4} {
+		w.push(Fixed, x)
+	}
+	for _, x := range []float64{2, 4, 6, 8, 10} {
+		w.push(Random, x)
+	}
+	// means 2.5 and 6, variances 5/3 and 10
+	expected := -3.5 / math.Sqrt(5.0/3/4+10.0/5)
+	if math.Abs(w.t()-expected) > 1e-12 {
+		t.Fatalf("expected %f but got %f", expected, w.t())
+	}
+}
+
+// compareTarget compares a secret with an equal input for the fixed class,
+// and with a random one, which differs from the first byte, for the other.
+// Both are fresh copies, or the cache would make the fixed one faster.
+func compareTarget(compare func(a, b []byte) bool) Target {
+	secret := make([]byte, 4096)
+	rand.Read(secret)
+	return func(class int) func() {
+		in := make([]byte, len(secret))
+		copy(in, secret)
+		if class == Random {
+			rand.Read(in)
+			in[0] = ^secret[0]
+		}
+		return func() {
+			compare(secret, in)
+		}
+	}
+}
+
+func TestVariableTime(t *testing.T) {
+	var testcases = []struct {
+		name   string
+		target Target
+		cfg    Config
+	}{
+		{"bytes.Equal", compareTarget(bytes.Equal), Config{Measurements: 5000}},
+		{
+			// the double and add loop runs once for the scalar 1
+			"ec.ScalarBaseMult",
+			func(class int) func() {
+				c := ec.Secp256k1()
+				k := big.NewInt(1)
+				if class == Random {
+					k, _ = c.RandomScalar(rand.Reader)
+				}
+				return func() {
+					c.ScalarBaseMult(k)
+				}
+			},
+			Config{Measurements: 200},
+		},
+	}
+	for _, tc := range testcases {
+		r, err := Measure(rand.Reader, tc.target, tc.cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !r.Leaks() {
+			t.Fatalf("%s expected a leak but got %s", tc.name, r)
+		}
+	}
+}
+
+func TestConstantTime(t *testing.T) {
+	ctCompare := func(a, b []byte) bool {
+		return subtle.ConstantTimeCompare(a, b) == 1
+	}
+	r, err := Measure(rand.Reader, compareTarget(ctCompare), Config{Measurements: 5000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Leaks() {
+		t.Fatalf("expected no leak but got %s", r)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	t.Parallel()
+	noop := func(class int) func() { return func() {} }
+	if _, err := Measure(rand.Reader, noop, Config{Measurements: 10}); err == nil {
+		t.Fatal("expected too few measurements to be rejected")
+	}
+	if _, err := Measure(bytes.NewReader(nil), noop, Config{}); err == nil {
+		t.Fatal("expected an error from an empty random source")
+	}
+	// all of one class
+	if _, err := Measure(bytes.NewReader(make([]byte, 1000)), noop, Config{Measurements: 1000}); err == nil {
+		t.Fatal("expected a single class to be rejected")
+	}
+}
@@ -0,0 +1,249 @@
+// Package stream encrypts data of any length with an AEAD in constant
+// memory, using the STREAM construction of Hoang, Reyhanitabar, Rogaway
+// and Vizár (Online Authenticated-Encryption and its Nonce-Reuse
+// Misuse-Resistance, 2015).
+//
+// The plaintext is cut in segments of SegmentSize bytes that are sealed
+// independently, so a reader can release each segment as soon as it is
+// authenticated. Each nonce holds the index of its segment and a flag set
+// only on the last segment: segments cannot be reordered, dropped or
+// moved between streams, and a stream cannot be truncated at a segment
+// boundary because the segment before the cut was not sealed as the last
+// one.
+//
+// The stream starts with a random salt from which the segment key is
+// derived with HKDF, so the same key can encrypt many streams without
+// ever reusing a nonce. The format is
+//
+//	salt (16 bytes) || segment 0 || ... || last segment
+//
+// where every sealed segment but the last one holds SegmentSize bytes of
+// plaintext, and the last one between 0 and SegmentSize. Only an empty
+// stream has an empty last segment.
+package stream
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jvehent/badcrypto/kdf"
+)
+
+const (
+	// SegmentSize is the size of the plaintext of a segment
+	SegmentSize = 64 * 1024
+	// SaltSize is the size of the salt that starts a stream
+	SaltSize = 16
+	// minNonceSize leaves room for a 64 bits counter and the flag
+	minNonceSize = 9
+	lastSegment  = 0x01
+)
+
+var (
+	// ErrAuthentication is returned when a segment was modified, moved,
+	// or encrypted under another key, and when segments were removed from
+	// the end of the stream
+	ErrAuthentication = errors.New("stream: failed to authenticate segment")
+	// ErrTruncated is returned when the stream is too short to hold a
+	// salt and a segment
+	ErrTruncated = errors.New("stream: truncated stream")
+)
+
+// AEAD returns a cipher.AEAD for a key, as chacha20poly1305.New does
+type AEAD func(key []byte) (cipher.AEAD, error)
+
+// segmentAEAD derives the segment key of a stream from key and salt
+func segmentAEAD(newAEAD AEAD, key, salt []byte) (cipher.AEAD, error) {
+	sk, err := kdf.HKDF(sha256.New, key, salt, []byte("badcrypto stream segment key"), len(key))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(sk)
+	if err != nil {
+		return nil, err
+	}
+	if aead.NonceSize() < minNonceSize {
+		return nil, fmt.Errorf("stream: nonces of %d bytes are too short, %d are needed", aead.NonceSize(), minNonceSize)
+	}
+	return aead, nil
+}
+
+// nonce is the big endian index of the segment followed by the last
+// segment flag
+func nonce(size int, index uint64, last bool) []byte {
+	n := make([]byte, size)
+	for i := 0; i < 8; i++ {
+		n[size-2-i] = byte(index >> (8 * i))
+	}
+	if last {
+		n[size-1] = lastSegment
+	}
+	return n
+}
+
+// sealWriter encrypts the plaintext written to it. A segment is only
+// sealed once more data arrives or the writer is closed, because the last
+// segment must be flagged as such.
+type sealWriter struct {
+	aead   cipher.AEAD
+	dst    io.Writer
+	buf    []byte
+	index  uint64
+	closed bool
+	err    error
+}
+
+// NewSealWriter writes a random salt to w and returns a writer that
+// encrypts under key the data written to it into w. The last segment is
+// only written by Close, without which the stream does not decrypt.
+func NewSealWriter(newAEAD AEAD, key []byte, w io.Writer) (io.WriteCloser, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	aead, err := segmentAEAD(newAEAD, key, salt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(salt); err != nil {
+		return nil, err
+	}
+	return &sealWriter{aead: aead, dst: w, buf: make([]byte, 0, SegmentSize)}, nil
+}
+
+func (w *sealWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("stream: write on closed writer")
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+	written := 0
+	for len(p) > 0 {
+		if len(w.buf) == SegmentSize {
+			if w.err = w.seal(false); w.err != nil {
+				return written, w.err
+			}
+		}
+		n := copy(w.buf[len(w.buf):SegmentSize], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (w *sealWriter) seal(last bool) error {
+	out := w.aead.Seal(nil, nonce(w.aead.NonceSize(), w.index, last), w.buf, nil)
+	if _, err := w.dst.Write(out); err != nil {
+		return err
+	}
+	w.index++
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// Close seals the last segment. It does not close the destination.
+func (w *sealWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.err != nil {
+		return w.err
+	}
+	return w.seal(true)
+}
+
+// openReader decrypts one segment at a time
+type openReader struct {
+	newAEAD AEAD
+	key     []byte
+	aead    cipher.AEAD
+	src     io.Reader
+	buf     []byte
+	enc     []byte
+	index   uint64
+	done    bool
+	err     error
+}
+
+// NewOpenReader returns a reader that decrypts under key the stream read
+// from r. Each segment is returned only once authenticated, so data read
+// before an error is genuine, but the stream is only complete when Read
+// returns io.EOF.
+func NewOpenReader(newAEAD AEAD, key []byte, r io.Reader) (io.Reader, error) {
+	// fail early on a bad key or AEAD, before reading anything
+	if _, err := segmentAEAD(newAEAD, key, make([]byte, SaltSize)); err != nil {
+		return nil, err
+	}
+	return &openReader{newAEAD: newAEAD, key: append([]byte{}, key...), src: r}, nil
+}
+
+func (r *openReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			return 0, io.EOF
+		}
+		r.err = r.open()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// open reads and authenticates the next segment into buf
+func (r *openReader) open() error {
+	if r.aead == nil {
+		salt := make([]byte, SaltSize)
+		if _, err := io.ReadFull(r.src, salt); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return ErrTruncated
+			}
+			return err
+		}
+		aead, err := segmentAEAD(r.newAEAD, r.key, salt)
+		if err != nil {
+			return err
+		}
+		r.aead = aead
+		// one extra byte tells whether a full segment is the last one
+		r.enc = make([]byte, 0, SegmentSize+aead.Overhead()+1)
+	}
+	sealed := SegmentSize + r.aead.Overhead()
+	n, err := io.ReadFull(r.src, r.enc[len(r.enc):sealed+1])
+	r.enc = r.enc[:len(r.enc)+n]
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return err
+	}
+	last := len(r.enc) <= sealed
+	segment := r.enc
+	if !last {
+		segment = r.enc[:sealed]
+	}
+	if len(segment) < r.aead.Overhead() {
+		return ErrTruncated
+	}
+	out, err := r.aead.Open(nil, nonce(r.aead.NonceSize(), r.index, last), segment, nil)
+	if err != nil {
+		return ErrAuthentication
+	}
+	if last && len(out) == 0 && r.index > 0 {
+		return errors.New("stream: empty last segment")
+	}
+	r.index++
+	if last {
+		r.done = true
+	} else {
+		r.enc = append(r.enc[:0], r.enc[sealed:]...)
+	}
+	r.buf = out
+	return nil
+}
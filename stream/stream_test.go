@@ -0,0 +1,153 @@
+package stream
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/jvehent/badcrypto/chacha20poly1305"
+)
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func seal(t *testing.T, newAEAD AEAD, key, plaintext []byte) []byte {
+	var buf bytes.Buffer
+	w, err := NewSealWriter(newAEAD, key, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// odd sized writes cross segment boundaries
+	for p := plaintext; len(p) > 0; {
+		n := 1000
+		if n > len(p) {
+			n = len(p)
+		}
+		if _, err := w.Write(p[:n]); err != nil {
+			t.Fatal(err)
+		}
+		p = p[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func open(newAEAD AEAD, key, ciphertext []byte) ([]byte, error) {
+	r, err := NewOpenReader(newAEAD, key, bytes.NewReader(ciphertext))
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		newAEAD AEAD
+		size    int
+	}{
+		{chacha20poly1305.New, 0},
+		{chacha20poly1305.New, 1},
+		{chacha20poly1305.New, SegmentSize + 1},
+		{newGCM, SegmentSize - 1},
+		{newGCM, SegmentSize},
+		{newGCM, 2 * SegmentSize},
+		{newGCM, 3*SegmentSize + 12345},
+	}
+	key := make([]byte, 32)
+	rand.Read(key)
+	for i, tc := range testcases {
+		plaintext := make([]byte, tc.size)
+		rand.Read(plaintext)
+		ct := seal(t, tc.newAEAD, key, plaintext)
+		segments := (tc.size + SegmentSize - 1) / SegmentSize
+		if segments == 0 {
+			segments = 1
+		}
+		if len(ct) != SaltSize+tc.size+16*segments {
+			t.Fatalf("testcase %d expected %d segments but got %d bytes", i, segments, len(ct))
+		}
+		pt, err := open(tc.newAEAD, key, ct)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if !bytes.Equal(pt, plaintext) {
+			t.Fatalf("testcase %d decrypted to another plaintext", i)
+		}
+	}
+}
+
+func TestTampering(t *testing.T) {
+	t.Parallel()
+	key := make([]byte, 16)
+	rand.Read(key)
+	plaintext := make([]byte, 3*SegmentSize+100)
+	ct := seal(t, newGCM, key, plaintext)
+	sealed := SegmentSize + 16
+	segment := func(i int) []byte {
+		return ct[SaltSize+i*sealed : SaltSize+(i+1)*sealed]
+	}
+	cat := func(parts ...[]byte) []byte {
+		return bytes.Join(parts, nil)
+	}
+	flipped := append([]byte{}, ct...)
+	flipped[SaltSize+sealed+5] ^= 1
+	otherKey := make([]byte, 16)
+	var testcases = []struct {
+		name       string
+		ciphertext []byte
+		key        []byte
+		err        error
+	}{
+		{"flipped bit", flipped, key, ErrAuthentication},
+		{"swapped segments", cat(ct[:SaltSize], segment(1), segment(0), ct[SaltSize+2*sealed:]), key, ErrAuthentication},
+		{"cut at a segment boundary", ct[:SaltSize+3*sealed], key, ErrAuthentication},
+		{"cut inside a segment", ct[:len(ct)-1], key, ErrAuthentication},
+		{"appended data", cat(ct, []byte{0}), key, ErrAuthentication},
+		{"other salt", cat(make([]byte, SaltSize), ct[SaltSize:]), key, ErrAuthentication},
+		{"other key", ct, otherKey, ErrAuthentication},
+		{"salt only", ct[:SaltSize], key, ErrTruncated},
+		{"short salt", ct[:3], key, ErrTruncated},
+	}
+	for _, tc := range testcases {
+		if _, err := open(newGCM, tc.key, tc.ciphertext); err != tc.err {
+			t.Fatalf("%s expected %v but got %v", tc.name, tc.err, err)
+		}
+	}
+
+	// the segments before the damage are released
+	r, _ := NewOpenReader(newGCM, key, bytes.NewReader(flipped))
+	n, err := io.Copy(ioutil.Discard, r)
+	if n != SegmentSize || err != ErrAuthentication {
+		t.Fatalf("expected one segment then %v but got %d bytes and %v", ErrAuthentication, n, err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+	if _, err := NewSealWriter(chacha20poly1305.New, make([]byte, 16), ioutil.Discard); err == nil {
+		t.Fatal("expected a 16 bytes key to fail with chacha20poly1305")
+	}
+	if _, err := NewOpenReader(chacha20poly1305.New, make([]byte, 16), bytes.NewReader(nil)); err == nil {
+		t.Fatal("expected a 16 bytes key to fail with chacha20poly1305")
+	}
+	w, err := NewSealWriter(newGCM, make([]byte, 16), ioutil.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	if _, err := w.Write([]byte("late")); err == nil {
+		t.Fatal("expected a write after close to fail")
+	}
+}
@@ -0,0 +1,149 @@
+package testvectors
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Record is a test case of a CAVP response file: the "key = value" lines
+// between two blank lines, under the [...] headers that precede it
+type Record struct {
+	// Header holds the contents of the brackets of the current headers,
+	// such as "P-256,SHA-256" or "Keylen = 128"
+	Header []string
+	Fields map[string]string
+	// Line is the line number of the first field, for error messages
+	Line int
+}
+
+// Get returns the value of key, or an empty string
+func (r *Record) Get(key string) string {
+	return r.Fields[key]
+}
+
+// Hex decodes the value of key as hex
+func (r *Record) Hex(key string) ([]byte, error) {
+	v, ok := r.Fields[key]
+	if !ok {
+		return nil, fmt.Errorf("testvectors: line %d: missing %s", r.Line, key)
+	}
+	b, err := hex.DecodeString(v)
+	if err != nil {
+		return nil, fmt.Errorf("testvectors: line %d: invalid hex in %s: %v", r.Line, key, err)
+	}
+	return b, nil
+}
+
+// ReadRSP calls fn on every record of a CAVP response file, in order.
+// Comments start with #.
+func ReadRSP(r io.Reader, fn func(*Record) error) error {
+	scanner := bufio.NewScanner(r)
+	// messages of the long message tests exceed the default line size
+	scanner.Buffer(make([]byte, 64*1024), 1<<24)
+	var header []string
+	inHeader := false
+	var rec *Record
+	flush := func() error {
+		if rec == nil {
+			return nil
+		}
+		err := fn(rec)
+		rec = nil
+		return err
+	}
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "#"):
+		case line == "":
+			if err := flush(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "["):
+			if !strings.HasSuffix(line, "]") {
+				return fmt.Errorf("testvectors: line %d: unterminated header %q", n, line)
+			}
+			if err := flush(); err != nil {
+				return err
+			}
+			// consecutive headers accumulate, a header after a record
+			// starts a new list
+			if !inHeader {
+				header = nil
+			}
+			header = append(header, strings.TrimSpace(line[1:len(line)-1]))
+			inHeader = true
+		default:
+			i := strings.Index(line, "=")
+			if i < 0 {
+				return fmt.Errorf("testvectors: line %d: expected key = value but got %q", n, line)
+			}
+			if rec == nil {
+				rec = &Record{Header: header, Fields: make(map[string]string), Line: n}
+			}
+			key := strings.TrimSpace(line[:i])
+			if _, dup := rec.Fields[key]; dup {
+				return fmt.Errorf("testvectors: line %d: duplicate %s", n, key)
+			}
+			rec.Fields[key] = strings.TrimSpace(line[i+1:])
+			inHeader = false
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// SigVer is a test case of the CAVP ECDSA signature verification files
+type SigVer struct {
+	// Curve and Hash come from the [P-256,SHA-256] header
+	Curve, Hash string
+	Msg         []byte
+	Qx, Qy      []byte
+	R, S        []byte
+	Valid       bool
+	// Reason is why an invalid signature fails, such as "3 - S changed"
+	Reason string
+	Line   int
+}
+
+// ReadSigVer calls fn on every test case of a SigVer.rsp file
+func ReadSigVer(r io.Reader, fn func(*SigVer) error) error {
+	return ReadRSP(r, func(rec *Record) error {
+		if len(rec.Header) != 1 {
+			return fmt.Errorf("testvectors: line %d: expected a single [curve,hash] header", rec.Line)
+		}
+		params := strings.Split(rec.Header[0], ",")
+		if len(params) != 2 {
+			return fmt.Errorf("testvectors: line %d: invalid header %q", rec.Line, rec.Header[0])
+		}
+		sv := &SigVer{Curve: params[0], Hash: params[1], Line: rec.Line}
+		for _, f := range []struct {
+			key string
+			dst *[]byte
+		}{{"Msg", &sv.Msg}, {"Qx", &sv.Qx}, {"Qy", &sv.Qy}, {"R", &sv.R}, {"S", &sv.S}} {
+			b, err := rec.Hex(f.key)
+			if err != nil {
+				return err
+			}
+			*f.dst = b
+		}
+		// Result = P (0 ) or Result = F (3 - S changed)
+		result := rec.Get("Result")
+		switch {
+		case strings.HasPrefix(result, "P"):
+			sv.Valid = true
+		case strings.HasPrefix(result, "F"):
+		default:
+			return fmt.Errorf("testvectors: line %d: invalid result %q", rec.Line, result)
+		}
+		if i, j := strings.Index(result, "("), strings.LastIndex(result, ")"); i >= 0 && j > i {
+			sv.Reason = strings.TrimSpace(result[i+1 : j])
+		}
+		return fn(sv)
+	})
+}
@@ -0,0 +1,104 @@
+package testvectors
+
+import (
+	"strings"
+	"testing"
+)
+
+const sigVerRSP = `#  CAVS 11.0
+#  "SigVer" information
+
+[P-256,SHA-256]
+
+Msg = 616263
+Qx = 01
+Qy = 02
+R = 03
+S = 04
+Result = P (0 )
+
+Msg = 
+Qx = 01
+Qy = 02
+R = 03
+S = 05
+Result = F (3 - S changed)
+`
+
+func TestReadSigVer(t *testing.T) {
+	t.Parallel()
+	var tests []*SigVer
+	err := ReadSigVer(strings.NewReader(sigVerRSP), func(sv *SigVer) error {
+		tests = append(tests, sv)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tests) != 2 {
+		t.Fatalf("expected 2 tests but got %d", len(tests))
+	}
+	if tc := tests[0]; tc.Curve != "P-256" || tc.Hash != "SHA-256" || string(tc.Msg) != "abc" || !tc.Valid || tc.Reason != "0" || tc.Line != 6 {
+		t.Fatalf("unexpected first test %+v", tc)
+	}
+	if tc := tests[1]; len(tc.Msg) != 0 || tc.S[0] != 5 || tc.Valid || tc.Reason != "3 - S changed" {
+		t.Fatalf("unexpected second test %+v", tc)
+	}
+	var invalid = []string{
+		strings.Replace(sigVerRSP, "Result = P", "Result = X", 1),
+		strings.Replace(sigVerRSP, "Qx = 01", "Qx = 1", 1),
+		strings.Replace(sigVerRSP, "[P-256,SHA-256]", "[P-256]", 1),
+		strings.Replace(sigVerRSP, "R = 03", "R", 1),
+	}
+	for i, data := range invalid {
+		if err := ReadSigVer(strings.NewReader(data), func(*SigVer) error { return nil }); err == nil {
+			t.Fatalf("invalid testcase %d expected an error but got none", i)
+		}
+	}
+}
+
+func TestReadRSPHeaders(t *testing.T) {
+	t.Parallel()
+	// the layout of the AES-GCM response files
+	data := `[Keylen = 128]
+[IVlen = 96]
+
+Count = 0
+Key = 00
+
+Count = 1
+Key = 01
+[Keylen = 256]
+# a comment between headers
+[IVlen = 1024]
+
+Count = 0
+Key = 02`
+	var records []*Record
+	err := ReadRSP(strings.NewReader(data), func(r *Record) error {
+		records = append(records, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected = []struct {
+		header, count, key string
+	}{
+		{"Keylen = 128|IVlen = 96", "0", "00"},
+		{"Keylen = 128|IVlen = 96", "1", "01"},
+		{"Keylen = 256|IVlen = 1024", "0", "02"},
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("expected %d records but got %d", len(expected), len(records))
+	}
+	for i, e := range expected {
+		r := records[i]
+		if strings.Join(r.Header, "|") != e.header || r.Get("Count") != e.count || r.Get("Key") != e.key {
+			t.Fatalf("record %d expected %+v but got %+v", i, e, r)
+		}
+	}
+	if _, err := records[0].Hex("IV"); err == nil {
+		t.Fatal("expected a missing field to be an error")
+	}
+}
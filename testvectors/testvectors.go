@@ -0,0 +1,51 @@
+// Package testvectors parses known answer tests from Project Wycheproof
+// JSON files and NIST CAVP .rsp response files, so the implementations of
+// this repository can be checked against the edge cases other people
+// thought of rather than only the round trips their authors wrote.
+//
+// The parsers cover the Wycheproof AEAD, ECDSA and ECDH (EC point)
+// schemas and the CAVP key = value format, with typed records for ECDSA
+// SigVer. Each file type has a ForEach iterator that stops at the first
+// error returned by its callback. Files are read from the testdata
+// directory of the package under test, the go 1.15 module cannot embed
+// them.
+package testvectors
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// Result is the expected outcome of a Wycheproof test
+type Result string
+
+// Wycheproof results. An acceptable test may pass or fail, its flags
+// tell why it is borderline.
+const (
+	Valid      Result = "valid"
+	Invalid    Result = "invalid"
+	Acceptable Result = "acceptable"
+)
+
+// HexBytes is a byte string encoded as hex in JSON
+type HexBytes []byte
+
+// UnmarshalJSON decodes a hex string
+func (h *HexBytes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("testvectors: invalid hex %q: %v", s, err)
+	}
+	*h = b
+	return nil
+}
+
+// MarshalJSON encodes h as a hex string
+func (h HexBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(h))
+}
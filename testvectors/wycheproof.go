@@ -0,0 +1,238 @@
+package testvectors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Note explains a flag of a Wycheproof file
+type Note struct {
+	BugType     string   `json:"bugType"`
+	Description string   `json:"description"`
+	Effect      string   `json:"effect"`
+	CVEs        []string `json:"cves"`
+	Links       []string `json:"links"`
+}
+
+// Header holds the fields common to every Wycheproof file
+type Header struct {
+	Algorithm     string          `json:"algorithm"`
+	Schema        string          `json:"schema"`
+	NumberOfTests int             `json:"numberOfTests"`
+	Header        []string        `json:"header"`
+	Notes         map[string]Note `json:"notes"`
+}
+
+// Test holds the fields common to every Wycheproof test
+type Test struct {
+	TcID    int      `json:"tcId"`
+	Comment string   `json:"comment"`
+	Flags   []string `json:"flags"`
+	Result  Result   `json:"result"`
+}
+
+// HasFlag returns true if the test carries flag
+func (t *Test) HasFlag(flag string) bool {
+	for _, f := range t.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// String identifies the test in failure messages
+func (t *Test) String() string {
+	return fmt.Sprintf("tcId %d (%s, %s)", t.TcID, t.Result, t.Comment)
+}
+
+// AEADFile is a Wycheproof file of the aead_test_schema_v1.json schema
+type AEADFile struct {
+	Header
+	TestGroups []*AEADGroup `json:"testGroups"`
+}
+
+// AEADGroup shares key, nonce and tag sizes between tests, in bits
+type AEADGroup struct {
+	Type    string      `json:"type"`
+	KeySize int         `json:"keySize"`
+	IVSize  int         `json:"ivSize"`
+	TagSize int         `json:"tagSize"`
+	Tests   []*AEADTest `json:"tests"`
+}
+
+// AEADTest is an encryption of Msg into Ct || Tag
+type AEADTest struct {
+	Test
+	Key HexBytes `json:"key"`
+	IV  HexBytes `json:"iv"`
+	AAD HexBytes `json:"aad"`
+	Msg HexBytes `json:"msg"`
+	Ct  HexBytes `json:"ct"`
+	Tag HexBytes `json:"tag"`
+}
+
+// ForEach calls fn on every test of the file
+func (f *AEADFile) ForEach(fn func(*AEADGroup, *AEADTest) error) error {
+	for _, g := range f.TestGroups {
+		for _, t := range g.Tests {
+			if err := fn(g, t); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ECPublicKey is the public key of an ECDSA group
+type ECPublicKey struct {
+	Type         string   `json:"type"`
+	Curve        string   `json:"curve"`
+	KeySize      int      `json:"keySize"`
+	Uncompressed HexBytes `json:"uncompressed"`
+	Wx           HexBytes `json:"wx"`
+	Wy           HexBytes `json:"wy"`
+}
+
+// ECDSAFile is a Wycheproof file of the ecdsa_verify_schema_v1.json
+// schema, with DER encoded signatures
+type ECDSAFile struct {
+	Header
+	TestGroups []*ECDSAGroup `json:"testGroups"`
+}
+
+// ECDSAGroup shares a public key and a hash function between tests
+type ECDSAGroup struct {
+	Type         string       `json:"type"`
+	PublicKey    ECPublicKey  `json:"publicKey"`
+	PublicKeyDER HexBytes     `json:"publicKeyDer"`
+	SHA          string       `json:"sha"`
+	Tests        []*ECDSATest `json:"tests"`
+}
+
+// ECDSATest is a signature Sig of Msg, to be hashed with the group hash
+type ECDSATest struct {
+	Test
+	Msg HexBytes `json:"msg"`
+	Sig HexBytes `json:"sig"`
+}
+
+// ForEach calls fn on every test of the file
+func (f *ECDSAFile) ForEach(fn func(*ECDSAGroup, *ECDSATest) error) error {
+	for _, g := range f.TestGroups {
+		for _, t := range g.Tests {
+			if err := fn(g, t); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ECDHFile is a Wycheproof file of the ecdh_ecpoint_test_schema_v1.json
+// schema, where public keys are encoded points rather than SPKI
+type ECDHFile struct {
+	Header
+	TestGroups []*ECDHGroup `json:"testGroups"`
+}
+
+// ECDHGroup shares a curve between tests
+type ECDHGroup struct {
+	Type     string      `json:"type"`
+	Curve    string      `json:"curve"`
+	Encoding string      `json:"encoding"`
+	Tests    []*ECDHTest `json:"tests"`
+}
+
+// ECDHTest is the shared secret of a private scalar and a peer point.
+// Private may carry a leading zero byte, as a two's complement integer.
+type ECDHTest struct {
+	Test
+	Public  HexBytes `json:"public"`
+	Private HexBytes `json:"private"`
+	Shared  HexBytes `json:"shared"`
+}
+
+// ForEach calls fn on every test of the file
+func (f *ECDHFile) ForEach(fn func(*ECDHGroup, *ECDHTest) error) error {
+	for _, g := range f.TestGroups {
+		for _, t := range g.Tests {
+			if err := fn(g, t); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// file is implemented by the Wycheproof file types
+type file interface {
+	header() *Header
+	schema() string
+	count() int
+}
+
+func (f *AEADFile) header() *Header { return &f.Header }
+
+func (f *AEADFile) schema() string { return "aead_test_schema_v1.json" }
+
+func (f *AEADFile) count() (n int) {
+	for _, g := range f.TestGroups {
+		n += len(g.Tests)
+	}
+	return n
+}
+
+func (f *ECDSAFile) header() *Header { return &f.Header }
+
+func (f *ECDSAFile) schema() string { return "ecdsa_verify_schema_v1.json" }
+
+func (f *ECDSAFile) count() (n int) {
+	for _, g := range f.TestGroups {
+		n += len(g.Tests)
+	}
+	return n
+}
+
+func (f *ECDHFile) header() *Header { return &f.Header }
+
+func (f *ECDHFile) schema() string { return "ecdh_ecpoint_test_schema_v1.json" }
+
+func (f *ECDHFile) count() (n int) {
+	for _, g := range f.TestGroups {
+		n += len(g.Tests)
+	}
+	return n
+}
+
+// ParseWycheproof decodes a Wycheproof file into f, one of *AEADFile,
+// *ECDSAFile or *ECDHFile, and checks its schema and test count
+func ParseWycheproof(r io.Reader, f interface{}) error {
+	wf, ok := f.(file)
+	if !ok {
+		return fmt.Errorf("testvectors: unsupported file type %T", f)
+	}
+	if err := json.NewDecoder(r).Decode(f); err != nil {
+		return fmt.Errorf("testvectors: %v", err)
+	}
+	h := wf.header()
+	if h.Schema != wf.schema() {
+		return fmt.Errorf("testvectors: schema %q instead of %q", h.Schema, wf.schema())
+	}
+	if n := wf.count(); n != h.NumberOfTests {
+		return fmt.Errorf("testvectors: file declares %d tests but has %d", h.NumberOfTests, n)
+	}
+	return nil
+}
+
+// LoadWycheproof parses the Wycheproof file at path into f
+func LoadWycheproof(path string, f interface{}) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	return ParseWycheproof(in, f)
+}
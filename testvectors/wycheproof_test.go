@@ -0,0 +1,64 @@
+package testvectors
+
+import (
+	"strings"
+	"testing"
+)
+
+const aeadJSON = `{
+  "algorithm": "CHACHA20-POLY1305",
+  "schema": "aead_test_schema_v1.json",
+  "numberOfTests": 2,
+  "notes": {"ModifiedTag": {"bugType": "AUTH_BYPASS", "description": "The tag was modified."}},
+  "testGroups": [
+    {"type": "AeadTest", "keySize": 256, "ivSize": 96, "tagSize": 128, "tests": [
+      {"tcId": 1, "comment": "", "flags": [], "key": "00ff", "iv": "", "aad": "", "msg": "61", "ct": "62", "tag": "63", "result": "valid"}
+    ]},
+    {"type": "AeadTest", "keySize": 256, "ivSize": 64, "tagSize": 128, "tests": [
+      {"tcId": 2, "comment": "bad tag", "flags": ["ModifiedTag"], "key": "", "iv": "", "aad": "", "msg": "", "ct": "", "tag": "", "result": "invalid"}
+    ]}
+  ]
+}`
+
+func TestParseWycheproof(t *testing.T) {
+	t.Parallel()
+	var f AEADFile
+	if err := ParseWycheproof(strings.NewReader(aeadJSON), &f); err != nil {
+		t.Fatal(err)
+	}
+	if f.Algorithm != "CHACHA20-POLY1305" || f.Notes["ModifiedTag"].BugType != "AUTH_BYPASS" {
+		t.Fatalf("unexpected header %+v", f.Header)
+	}
+	var ids []int
+	err := f.ForEach(func(g *AEADGroup, tc *AEADTest) error {
+		ids = append(ids, tc.TcID)
+		if tc.TcID == 1 && (string(tc.Key) != "\x00\xff" || string(tc.Msg) != "a" || tc.Result != Valid || g.IVSize != 96) {
+			t.Fatalf("unexpected test %+v in group %+v", tc, g)
+		}
+		if tc.TcID == 2 && (!tc.HasFlag("ModifiedTag") || tc.HasFlag("Ktv") || tc.Result != Invalid) {
+			t.Fatalf("unexpected test %+v", tc)
+		}
+		return nil
+	})
+	if err != nil || len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Fatalf("expected to iterate over tests 1 and 2 but got %v and %v", ids, err)
+	}
+
+	var testcases = []struct {
+		data string
+		file interface{}
+	}{
+		// the schema does not match the type
+		{aeadJSON, &ECDSAFile{}},
+		// a test went missing
+		{strings.Replace(aeadJSON, `"numberOfTests": 2`, `"numberOfTests": 3`, 1), &AEADFile{}},
+		{strings.Replace(aeadJSON, `"00ff"`, `"00f"`, 1), &AEADFile{}},
+		{aeadJSON[:100], &AEADFile{}},
+		{aeadJSON, &f.Header},
+	}
+	for i, tc := range testcases {
+		if err := ParseWycheproof(strings.NewReader(tc.data), tc.file); err == nil {
+			t.Fatalf("testcase %d expected an error but got none", i)
+		}
+	}
+}
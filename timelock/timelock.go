@@ -0,0 +1,189 @@
+// Package timelock implements the time-lock puzzles of Rivest, Shamir and
+// Wagner: a message is encrypted under a key that can only be recovered by
+// computing a^(2^T) mod N, which takes T sequential modular squarings for
+// anyone who does not know the factorization of N. The creator of the
+// puzzle knows phi(N) and reduces the exponent to get there instantly.
+//
+// Squarings cannot be parallelized, so T sets a lower bound on the wall
+// clock time needed to open the puzzle, as a function of the speed of the
+// fastest squaring hardware available to the solver. Calibrate gives a
+// rough T for a duration on the local machine.
+package timelock
+
+import (
+	"crypto/cipher"
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/jvehent/badcrypto/bignum"
+	"github.com/jvehent/badcrypto/chacha20poly1305"
+	"github.com/jvehent/badcrypto/kdf"
+)
+
+// MinBits is the smallest modulus size accepted by New
+const MinBits = 512
+
+var (
+	one = big.NewInt(1)
+	two = big.NewInt(2)
+)
+
+// Puzzle is a message locked for T sequential squarings modulo N
+type Puzzle struct {
+	N, A       *big.Int
+	T          uint64
+	Nonce      []byte
+	Ciphertext []byte
+}
+
+// New locks msg in a puzzle requiring t squarings modulo a fresh RSA
+// modulus of the given size in bits
+func New(rand io.Reader, msg []byte, t uint64, bits int) (*Puzzle, error) {
+	if bits < MinBits {
+		return nil, fmt.Errorf("timelock: modulus of %d bits is smaller than %d", bits, MinBits)
+	}
+	var p, q *big.Int
+	for {
+		var err error
+		if p, err = cryptorand.Prime(rand, bits/2); err != nil {
+			return nil, err
+		}
+		if q, err = cryptorand.Prime(rand, bits-bits/2); err != nil {
+			return nil, err
+		}
+		if p.Cmp(q) != 0 {
+			break
+		}
+	}
+	n := new(big.Int).Mul(p, q)
+	phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+
+	// the base only needs to be a unit, which a random value almost
+	// always is
+	var a *big.Int
+	for {
+		var err error
+		if a, err = cryptorand.Int(rand, n); err != nil {
+			return nil, err
+		}
+		if a.Cmp(one) > 0 && new(big.Int).GCD(nil, nil, a, n).Cmp(one) == 0 {
+			break
+		}
+	}
+	// shortcut: a^(2^t) = a^(2^t mod phi(N)) mod N
+	e := new(big.Int).Exp(two, new(big.Int).SetUint64(t), phi)
+	b := new(big.Int).Exp(a, e, n)
+
+	puzzle := &Puzzle{N: n, A: a, T: t, Nonce: make([]byte, chacha20poly1305.NonceSize)}
+	if _, err := io.ReadFull(rand, puzzle.Nonce); err != nil {
+		return nil, err
+	}
+	aead, err := puzzle.aead(b)
+	if err != nil {
+		return nil, err
+	}
+	puzzle.Ciphertext = aead.Seal(nil, puzzle.Nonce, msg, puzzle.additionalData())
+	return puzzle, nil
+}
+
+// Solve performs the T sequential squarings and decrypts the message
+func (p *Puzzle) Solve() ([]byte, error) {
+	if p.N == nil || p.A == nil || p.N.Cmp(two) <= 0 {
+		return nil, errors.New("timelock: invalid puzzle")
+	}
+	b := SequentialSquare(p.A, p.N, p.T)
+	aead, err := p.aead(b)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := aead.Open(nil, p.Nonce, p.Ciphertext, p.additionalData())
+	if err != nil {
+		return nil, errors.New("timelock: puzzle does not decrypt")
+	}
+	return msg, nil
+}
+
+// SequentialSquare returns x^(2^t) mod n by squaring t times. Odd
+// moduli, as RSA moduli are, are squared in the Montgomery representation
+// of bignum, where a squaring needs no division. Other moduli are squared
+// with bignum and reduced with Barrett.
+func SequentialSquare(x, n *big.Int, t uint64) *big.Int {
+	bn := toInt(n)
+	y := toInt(new(big.Int).Mod(x, n))
+	if m, err := bignum.NewMontgomeryContext(bn); err == nil {
+		y = m.ToMontgomery(y)
+		for i := uint64(0); i < t; i++ {
+			y = m.MontgomeryMul(y, y)
+		}
+		return toBig(m.FromMontgomery(y))
+	}
+	b, err := bignum.NewBarrettContext(bn)
+	if err != nil {
+		panic("timelock: zero modulus")
+	}
+	for i := uint64(0); i < t; i++ {
+		sq := new(bignum.Int)
+		sq.Set(y)
+		sq.Mul(y)
+		b.Reduce(sq)
+		y = sq
+	}
+	return toBig(y)
+}
+
+func toInt(x *big.Int) *bignum.Int {
+	y := new(bignum.Int)
+	y.SetBytes(x.Bytes())
+	return y
+}
+
+func toBig(x *bignum.Int) *big.Int {
+	return new(big.Int).SetBytes(x.Bytes())
+}
+
+// Calibrate estimates the number of squarings modulo a bits sized modulus
+// this machine performs in d, by timing a short run
+func Calibrate(bits int, d time.Duration) uint64 {
+	n := new(big.Int).Lsh(one, uint(bits))
+	n.Sub(n, big.NewInt(1))
+	x := new(big.Int).Rsh(n, 1)
+	const sample = 1 << 12
+	start := time.Now()
+	SequentialSquare(x, n, sample)
+	elapsed := time.Since(start)
+	if elapsed <= 0 {
+		elapsed = 1
+	}
+	return uint64(float64(sample) * float64(d) / float64(elapsed))
+}
+
+// aead derives the message key from the solution b
+func (p *Puzzle) aead(b *big.Int) (cipher.AEAD, error) {
+	secret := b.FillBytes(make([]byte, (p.N.BitLen()+7)/8))
+	key, err := kdf.HKDF(sha256.New, secret, nil, []byte("badcrypto timelock v1"), chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	return chacha20poly1305.New(key)
+}
+
+// additionalData binds the ciphertext to the parameters of the puzzle
+func (p *Puzzle) additionalData() []byte {
+	var t [8]byte
+	binary.BigEndian.PutUint64(t[:], p.T)
+	ad := append([]byte{}, t[:]...)
+	for _, v := range []*big.Int{p.N, p.A} {
+		var l [4]byte
+		buf := v.Bytes()
+		binary.BigEndian.PutUint32(l[:], uint32(len(buf)))
+		ad = append(ad, l[:]...)
+		ad = append(ad, buf...)
+	}
+	return ad
+}
@@ -0,0 +1,82 @@
+package timelock
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestPuzzle(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		msg  []byte
+		t    uint64
+		bits int
+	}{
+		{[]byte("open me in the future"), 1000, 512},
+		{[]byte(""), 0, 512},
+		{bytes.Repeat([]byte{0x42}, 300), 5000, 1024},
+	}
+	for i, tc := range testcases {
+		p, err := New(rand.Reader, tc.msg, tc.t, tc.bits)
+		if err != nil {
+			t.Fatalf("testcase %d failed with %v", i, err)
+		}
+		if p.N.BitLen() != tc.bits {
+			t.Fatalf("testcase %d expected a modulus of %d bits but got %d", i, tc.bits, p.N.BitLen())
+		}
+		msg, err := p.Solve()
+		if err != nil {
+			t.Fatalf("testcase %d failed to solve with %v", i, err)
+		}
+		if !bytes.Equal(msg, tc.msg) {
+			t.Fatalf("testcase %d expected %q but got %q", i, tc.msg, msg)
+		}
+		// a puzzle with fewer squarings does not open
+		p.T++
+		if _, err := p.Solve(); err == nil {
+			t.Fatalf("testcase %d expected a tampered puzzle to fail", i)
+		}
+	}
+}
+
+func TestSequentialSquare(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		x, n int64
+		t    uint64
+	}{
+		{3, 1000003, 0},
+		{3, 1000003, 1},
+		{12345, 1000003, 20},
+		{7, 11 * 13, 100},
+		// even moduli have no Montgomery representation
+		{12345, 1 << 20, 20},
+		{7, 1000, 100},
+		{5, 1, 3},
+	}
+	for i, tc := range testcases {
+		x, n := big.NewInt(tc.x), big.NewInt(tc.n)
+		e := new(big.Int).Lsh(big.NewInt(1), uint(tc.t))
+		expected := new(big.Int).Exp(x, e, n)
+		if got := SequentialSquare(x, n, tc.t); got.Cmp(expected) != 0 {
+			t.Fatalf("testcase %d expected %s but got %s", i, expected, got)
+		}
+	}
+}
+
+func TestSmallModulus(t *testing.T) {
+	t.Parallel()
+	if _, err := New(rand.Reader, []byte("hi"), 10, 256); err == nil {
+		t.Fatal("expected a 256 bits modulus to be rejected")
+	}
+}
+
+func TestCalibrate(t *testing.T) {
+	t.Parallel()
+	if Calibrate(1024, time.Second) == 0 {
+		t.Fatal("expected a non zero number of squarings per second")
+	}
+}
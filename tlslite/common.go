@@ -0,0 +1,265 @@
+// Package tlslite implements just enough of TLS 1.3 (RFC 8446) to fetch a
+// page from a real HTTPS server using the primitives of badcrypto: an
+// X25519 key share, the HKDF key schedule, AES-GCM or ChaCha20-Poly1305
+// record protection and verification of the server certificate chain.
+//
+// It also has a minimal TLS 1.2 server (RFC 5246) with RSA key transport
+// and ECDHE-RSA key exchanges, which can be configured with deliberate
+// weaknesses to give the attack modules a realistic target.
+//
+// It does not support resumption, early data, HelloRetryRequest or client
+// certificates, and is not meant to protect anything.
+package tlslite
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Protocol versions
+const (
+	VersionTLS12 uint16 = 0x0303
+	VersionTLS13 uint16 = 0x0304
+)
+
+// TLS 1.3 cipher suites from RFC 8446 appendix B.4
+const (
+	TLS_AES_128_GCM_SHA256       uint16 = 0x1301
+	TLS_AES_256_GCM_SHA384       uint16 = 0x1302
+	TLS_CHACHA20_POLY1305_SHA256 uint16 = 0x1303
+)
+
+// TLS 1.2 cipher suites supported by the server, from RFC 5288 and RFC 5289
+const (
+	TLS_RSA_WITH_AES_128_GCM_SHA256       uint16 = 0x009c
+	TLS_RSA_WITH_AES_256_GCM_SHA384       uint16 = 0x009d
+	TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 uint16 = 0xc02f
+	TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384 uint16 = 0xc030
+)
+
+// scsvRenegotiation signals support for secure renegotiation in the
+// list of cipher suites, as per RFC 5746
+const scsvRenegotiation uint16 = 0x00ff
+
+// maxPlaintext is the largest record payload allowed by the protocol
+const maxPlaintext = 16384
+
+// record content types
+const (
+	recordTypeChangeCipherSpec uint8 = 20
+	recordTypeAlert            uint8 = 21
+	recordTypeHandshake        uint8 = 22
+	recordTypeApplicationData  uint8 = 23
+)
+
+// handshake message types
+const (
+	typeClientHello         uint8 = 1
+	typeServerHello         uint8 = 2
+	typeNewSessionTicket    uint8 = 4
+	typeEncryptedExtensions uint8 = 8
+	typeCertificate         uint8 = 11
+	typeServerKeyExchange   uint8 = 12
+	typeCertificateRequest  uint8 = 13
+	typeServerHelloDone     uint8 = 14
+	typeCertificateVerify   uint8 = 15
+	typeClientKeyExchange   uint8 = 16
+	typeFinished            uint8 = 20
+	typeKeyUpdate           uint8 = 24
+)
+
+// extension types
+const (
+	extensionServerName          uint16 = 0
+	extensionSupportedGroups     uint16 = 10
+	extensionSignatureAlgorithms uint16 = 13
+	extensionALPN                uint16 = 16
+	extensionSupportedVersions   uint16 = 43
+	extensionKeyShare            uint16 = 51
+	extensionRenegotiationInfo   uint16 = 0xff01
+)
+
+// groupX25519 is the only key exchange group offered
+const groupX25519 uint16 = 29
+
+// signature schemes
+const (
+	pkcs1WithSHA1          uint16 = 0x0201
+	pkcs1WithSHA256        uint16 = 0x0401
+	pkcs1WithSHA384        uint16 = 0x0501
+	pkcs1WithSHA512        uint16 = 0x0601
+	ecdsaWithP256AndSHA256 uint16 = 0x0403
+	ecdsaWithP384AndSHA384 uint16 = 0x0503
+	ecdsaWithP521AndSHA512 uint16 = 0x0603
+	pssWithSHA256          uint16 = 0x0804
+	pssWithSHA384          uint16 = 0x0805
+	pssWithSHA512          uint16 = 0x0806
+	ed25519Scheme          uint16 = 0x0807
+)
+
+// supportedSignatureSchemes is advertised in the ClientHello. The PKCS#1
+// v1.5 schemes are only listed so servers accept them in certificate
+// chains, they are refused in CertificateVerify.
+var supportedSignatureSchemes = []uint16{
+	ecdsaWithP256AndSHA256,
+	ecdsaWithP384AndSHA384,
+	ecdsaWithP521AndSHA512,
+	pssWithSHA256,
+	pssWithSHA384,
+	pssWithSHA512,
+	ed25519Scheme,
+	pkcs1WithSHA256,
+	pkcs1WithSHA384,
+	pkcs1WithSHA512,
+}
+
+// Config configures a TLS connection
+type Config struct {
+	// RootCAs are the roots used to verify the server certificate chain.
+	// The system pool is used when nil.
+	RootCAs *x509.CertPool
+
+	// ServerName is sent in the SNI extension and checked against the
+	// server certificate
+	ServerName string
+
+	// InsecureSkipVerify disables verification of the certificate chain
+	// and host name. The CertificateVerify signature is still checked.
+	InsecureSkipVerify bool
+
+	// CipherSuites lists the suites offered, in order of preference.
+	// All the supported suites are offered when empty.
+	CipherSuites []uint16
+
+	// NextProtos lists the ALPN protocols offered
+	NextProtos []string
+
+	// Rand is the source of randomness, crypto/rand when nil
+	Rand io.Reader
+
+	// Time returns the current time, time.Now when nil
+	Time func() time.Time
+
+	// Certificates holds the certificate chain presented by a server.
+	// Only the first one is used and it must have an RSA key.
+	Certificates []Certificate
+
+	// Weaknesses are the flaws deliberately enabled on a server
+	Weaknesses Weaknesses
+}
+
+// Certificate is a certificate chain, leaf first, and its private key
+type Certificate struct {
+	Certificate [][]byte
+	PrivateKey  crypto.Signer
+}
+
+// PKCS1Oracle controls how a TLS 1.2 server reacts to an RSA encrypted
+// premaster secret that does not decrypt to a valid PKCS#1 v1.5 message
+type PKCS1Oracle int
+
+const (
+	// PKCS1Implicit continues the handshake with a random premaster
+	// secret as required by RFC 5246 section 7.4.7.1, so the handshake
+	// fails at the Finished message whatever the padding was
+	PKCS1Implicit PKCS1Oracle = iota
+	// PKCS1OracleConformant sends a decrypt_error alert as soon as the
+	// decrypted message does not start with 0x00 0x02, which is the
+	// strong oracle of Bleichenbacher's attack
+	PKCS1OracleConformant
+	// PKCS1OracleStrict sends a decrypt_error alert unless the message
+	// starts with 0x00 0x02, has at least eight non zero padding bytes,
+	// a zero separator and a 48 bytes premaster secret with the right
+	// version, which is a weaker oracle that needs more queries
+	PKCS1OracleStrict
+)
+
+// Weaknesses lists the flaws a TLS 1.2 server can be configured with.
+// The zero value is a server that behaves as the RFCs require.
+type Weaknesses struct {
+	// PKCS1Oracle selects the behavior of the RSA key exchange on
+	// malformed premaster secrets
+	PKCS1Oracle PKCS1Oracle
+
+	// StaticECDHEKey, when set, is the X25519 private key used by every
+	// ECDHE handshake instead of a fresh ephemeral key
+	StaticECDHEKey []byte
+}
+
+func (c *Config) rand() io.Reader {
+	if c.Rand == nil {
+		return rand.Reader
+	}
+	return c.Rand
+}
+
+func (c *Config) time() time.Time {
+	if c.Time == nil {
+		return time.Now()
+	}
+	return c.Time()
+}
+
+func (c *Config) cipherSuites() []uint16 {
+	if len(c.CipherSuites) == 0 {
+		return []uint16{TLS_AES_128_GCM_SHA256, TLS_CHACHA20_POLY1305_SHA256, TLS_AES_256_GCM_SHA384}
+	}
+	return c.CipherSuites
+}
+
+// ConnectionState describes a connection after its handshake
+type ConnectionState struct {
+	Version            uint16
+	CipherSuite        uint16
+	ServerName         string
+	NegotiatedProtocol string
+	PeerCertificates   []*x509.Certificate
+}
+
+// alert is a TLS alert description, used as an error
+type alert uint8
+
+const (
+	alertCloseNotify           alert = 0
+	alertUnexpectedMessage     alert = 10
+	alertBadRecordMAC          alert = 20
+	alertRecordOverflow        alert = 22
+	alertHandshakeFailure      alert = 40
+	alertBadCertificate        alert = 42
+	alertIllegalParameter      alert = 47
+	alertDecodeError           alert = 50
+	alertDecryptError          alert = 51
+	alertProtocolVersion       alert = 70
+	alertInternalError         alert = 80
+	alertMissingExtension      alert = 109
+	alertUnsupportedExtension  alert = 110
+	alertNoApplicationProtocol alert = 120
+)
+
+var alertText = map[alert]string{
+	alertCloseNotify:           "close notify",
+	alertUnexpectedMessage:     "unexpected message",
+	alertBadRecordMAC:          "bad record MAC",
+	alertRecordOverflow:        "record overflow",
+	alertHandshakeFailure:      "handshake failure",
+	alertBadCertificate:        "bad certificate",
+	alertIllegalParameter:      "illegal parameter",
+	alertDecodeError:           "error decoding message",
+	alertDecryptError:          "error decrypting message",
+	alertProtocolVersion:       "protocol version not supported",
+	alertInternalError:         "internal error",
+	alertMissingExtension:      "missing extension",
+	alertUnsupportedExtension:  "unsupported extension",
+	alertNoApplicationProtocol: "no application protocol",
+}
+
+func (a alert) Error() string {
+	if s, ok := alertText[a]; ok {
+		return "tlslite: " + s
+	}
+	return fmt.Sprintf("tlslite: alert(%d)", uint8(a))
+}
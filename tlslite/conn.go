@@ -0,0 +1,428 @@
+package tlslite
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// Conn is a TLS connection over an underlying net.Conn
+type Conn struct {
+	conn     net.Conn
+	config   *Config
+	isClient bool
+
+	handshakeMutex    sync.Mutex
+	handshakeComplete bool
+	handshakeErr      error
+
+	vers               uint16
+	cipherSuite        uint16
+	serverName         string
+	negotiatedProtocol string
+	peerCertificates   []*x509.Certificate
+
+	// suite and traffic secrets are kept to process KeyUpdate messages
+	suite      *cipherSuite13
+	inSecret   []byte
+	outSecret  []byte
+	transcript hash.Hash
+
+	inMutex sync.Mutex
+	in      halfConn
+	hand    []byte // pending handshake bytes
+	input   []byte // pending application data
+	readErr error
+
+	outMutex sync.Mutex
+	out      halfConn
+	closed   bool
+}
+
+// Client returns a new client side TLS connection over conn. The config
+// must set ServerName or InsecureSkipVerify.
+func Client(conn net.Conn, config *Config) *Conn {
+	if config == nil {
+		config = new(Config)
+	}
+	return &Conn{conn: conn, config: config, isClient: true}
+}
+
+// Server returns a new server side TLS 1.2 connection over conn. The
+// config must hold a certificate with an RSA key.
+func Server(conn net.Conn, config *Config) *Conn {
+	return &Conn{conn: conn, config: config}
+}
+
+// Dial connects to addr over network and performs a TLS handshake. The
+// server name defaults to the host part of addr.
+func Dial(network, addr string, config *Config) (*Conn, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	rawConn, err := net.Dial(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	cfg := Config{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = host
+	}
+	c := Client(rawConn, &cfg)
+	if err := c.Handshake(); err != nil {
+		rawConn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Handshake runs the handshake if it has not run yet
+func (c *Conn) Handshake() error {
+	c.handshakeMutex.Lock()
+	defer c.handshakeMutex.Unlock()
+	if c.handshakeComplete || c.handshakeErr != nil {
+		return c.handshakeErr
+	}
+	c.inMutex.Lock()
+	defer c.inMutex.Unlock()
+	if c.isClient {
+		c.handshakeErr = c.clientHandshake()
+	} else {
+		c.handshakeErr = c.serverHandshake()
+	}
+	if c.handshakeErr != nil {
+		if a, ok := c.handshakeErr.(alert); ok {
+			c.sendAlert(a)
+		} else {
+			c.sendAlert(alertHandshakeFailure)
+		}
+		return c.handshakeErr
+	}
+	c.handshakeComplete = true
+	return nil
+}
+
+// ConnectionState returns details about a completed handshake
+func (c *Conn) ConnectionState() ConnectionState {
+	c.handshakeMutex.Lock()
+	defer c.handshakeMutex.Unlock()
+	return ConnectionState{
+		Version:            c.vers,
+		CipherSuite:        c.cipherSuite,
+		ServerName:         c.serverName,
+		NegotiatedProtocol: c.negotiatedProtocol,
+		PeerCertificates:   c.peerCertificates,
+	}
+}
+
+// writeRecord protects data with the current write keys and sends it in
+// as many records as needed. The caller holds outMutex.
+func (c *Conn) writeRecord(typ uint8, data []byte) error {
+	for first := true; first || len(data) > 0; first = false {
+		n := len(data)
+		if n > maxPlaintext {
+			n = maxPlaintext
+		}
+		outType, body := typ, data[:n]
+		if c.out.cipher != nil {
+			outType, body = c.out.cipher.seal(c.out.seq, typ, data[:n])
+			c.out.seq++
+		}
+		// the legacy version of the very first ClientHello record is 1.0
+		vers := VersionTLS12
+		if c.vers == 0 {
+			vers = 0x0301
+		}
+		record := []byte{outType, byte(vers >> 8), byte(vers)}
+		record = appendVec16(record, body)
+		if _, err := c.conn.Write(record); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// readRecord reads the next record and removes its protection. TLS 1.3
+// compatibility ChangeCipherSpec records are skipped. The caller holds
+// inMutex.
+func (c *Conn) readRecord() (uint8, []byte, error) {
+	for {
+		hdr := make([]byte, 5)
+		if _, err := io.ReadFull(c.conn, hdr); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, nil, err
+		}
+		typ := hdr[0]
+		length := int(hdr[3])<<8 | int(hdr[4])
+		if typ < recordTypeChangeCipherSpec || typ > recordTypeApplicationData {
+			return 0, nil, fmt.Errorf("tlslite: unknown record type %d, is this a TLS server?", typ)
+		}
+		if length > maxPlaintext+256 {
+			return 0, nil, alertRecordOverflow
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(c.conn, body); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, nil, err
+		}
+		if typ == recordTypeChangeCipherSpec && c.vers == VersionTLS13 {
+			if length != 1 || body[0] != 1 {
+				return 0, nil, alertUnexpectedMessage
+			}
+			continue
+		}
+		if c.in.cipher == nil {
+			return typ, body, nil
+		}
+		typ, data, err := c.in.cipher.open(c.in.seq, hdr, body)
+		if err != nil {
+			return 0, nil, err
+		}
+		c.in.seq++
+		if len(data) > maxPlaintext {
+			return 0, nil, alertRecordOverflow
+		}
+		return typ, data, nil
+	}
+}
+
+// readHandshake returns the next handshake message, header included.
+// The caller holds inMutex.
+func (c *Conn) readHandshake() ([]byte, error) {
+	for len(c.hand) < 4 || len(c.hand) < 4+(int(c.hand[1])<<16|int(c.hand[2])<<8|int(c.hand[3])) {
+		typ, data, err := c.readRecord()
+		if err != nil {
+			return nil, err
+		}
+		switch typ {
+		case recordTypeHandshake:
+			c.hand = append(c.hand, data...)
+		case recordTypeAlert:
+			return nil, alertFromRecord(data)
+		default:
+			return nil, alertUnexpectedMessage
+		}
+	}
+	n := 4 + (int(c.hand[1])<<16 | int(c.hand[2])<<8 | int(c.hand[3]))
+	msg := c.hand[:n]
+	c.hand = c.hand[n:]
+	return msg, nil
+}
+
+// readHandshakeType reads the next handshake message and checks its type
+func (c *Conn) readHandshakeType(typ uint8) ([]byte, error) {
+	msg, err := c.readHandshake()
+	if err != nil {
+		return nil, err
+	}
+	if msg[0] != typ {
+		return nil, alertUnexpectedMessage
+	}
+	return msg, nil
+}
+
+// writeHandshake sends a handshake message and adds it to the transcript
+func (c *Conn) writeHandshake(msg []byte) error {
+	c.outMutex.Lock()
+	defer c.outMutex.Unlock()
+	if c.transcript != nil {
+		c.transcript.Write(msg)
+	}
+	return c.writeRecord(recordTypeHandshake, msg)
+}
+
+func alertFromRecord(data []byte) error {
+	if len(data) != 2 {
+		return alertDecodeError
+	}
+	if alert(data[1]) == alertCloseNotify {
+		return io.EOF
+	}
+	return fmt.Errorf("tlslite: received alert: %v", alert(data[1]))
+}
+
+// sendAlert sends a fatal alert, or a warning for close_notify
+func (c *Conn) sendAlert(a alert) error {
+	c.outMutex.Lock()
+	defer c.outMutex.Unlock()
+	level := byte(2)
+	if a == alertCloseNotify {
+		level = 1
+	}
+	return c.writeRecord(recordTypeAlert, []byte{level, byte(a)})
+}
+
+// Read reads application data, processing post-handshake messages
+func (c *Conn) Read(b []byte) (int, error) {
+	if err := c.Handshake(); err != nil {
+		return 0, err
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+	c.inMutex.Lock()
+	defer c.inMutex.Unlock()
+	for len(c.input) == 0 {
+		if c.readErr != nil {
+			return 0, c.readErr
+		}
+		c.readErr = c.readApplicationData()
+	}
+	n := copy(b, c.input)
+	c.input = c.input[n:]
+	return n, nil
+}
+
+// readApplicationData reads one record into c.input
+func (c *Conn) readApplicationData() error {
+	typ, data, err := c.readRecord()
+	if err != nil {
+		return err
+	}
+	switch typ {
+	case recordTypeApplicationData:
+		c.input = append(c.input, data...)
+		return nil
+	case recordTypeAlert:
+		return alertFromRecord(data)
+	case recordTypeHandshake:
+		c.hand = append(c.hand, data...)
+		for len(c.hand) >= 4 && len(c.hand) >= 4+(int(c.hand[1])<<16|int(c.hand[2])<<8|int(c.hand[3])) {
+			msg, err := c.readHandshake()
+			if err != nil {
+				return err
+			}
+			if err := c.handlePostHandshake(msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return alertUnexpectedMessage
+}
+
+// handlePostHandshake processes messages a TLS 1.3 server may send after
+// the handshake. Session tickets are ignored since there is no resumption.
+func (c *Conn) handlePostHandshake(msg []byte) error {
+	if c.vers != VersionTLS13 {
+		return alertUnexpectedMessage
+	}
+	switch msg[0] {
+	case typeNewSessionTicket:
+		return nil
+	case typeKeyUpdate:
+		var ku keyUpdateMsg
+		if err := ku.unmarshal(msg); err != nil {
+			return err
+		}
+		c.inSecret = c.suite.nextTrafficSecret(c.inSecret)
+		in, err := newAEAD13(c.suite, c.inSecret)
+		if err != nil {
+			return err
+		}
+		c.in.setCipher(in)
+		if !ku.updateRequested {
+			return nil
+		}
+		c.outMutex.Lock()
+		defer c.outMutex.Unlock()
+		if err := c.writeRecord(recordTypeHandshake, (&keyUpdateMsg{}).marshal()); err != nil {
+			return err
+		}
+		c.outSecret = c.suite.nextTrafficSecret(c.outSecret)
+		out, err := newAEAD13(c.suite, c.outSecret)
+		if err != nil {
+			return err
+		}
+		c.out.setCipher(out)
+		return nil
+	}
+	return alertUnexpectedMessage
+}
+
+// Write sends b as application data
+func (c *Conn) Write(b []byte) (int, error) {
+	if err := c.Handshake(); err != nil {
+		return 0, err
+	}
+	c.outMutex.Lock()
+	defer c.outMutex.Unlock()
+	if c.closed {
+		return 0, errors.New("tlslite: use of closed connection")
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+	if err := c.writeRecord(recordTypeApplicationData, b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Close sends a close_notify alert and closes the underlying connection
+func (c *Conn) Close() error {
+	var alertErr error
+	c.handshakeMutex.Lock()
+	complete := c.handshakeComplete
+	c.handshakeMutex.Unlock()
+	if complete {
+		alertErr = c.sendAlert(alertCloseNotify)
+	}
+	c.outMutex.Lock()
+	c.closed = true
+	c.outMutex.Unlock()
+	if err := c.conn.Close(); err != nil {
+		return err
+	}
+	return alertErr
+}
+
+// LocalAddr returns the local network address
+func (c *Conn) LocalAddr() net.Addr { return c.conn.LocalAddr() }
+
+// RemoteAddr returns the remote network address
+func (c *Conn) RemoteAddr() net.Addr { return c.conn.RemoteAddr() }
+
+// SetDeadline sets the read and write deadlines of the underlying connection
+func (c *Conn) SetDeadline(t time.Time) error { return c.conn.SetDeadline(t) }
+
+// SetReadDeadline sets the read deadline of the underlying connection
+func (c *Conn) SetReadDeadline(t time.Time) error { return c.conn.SetReadDeadline(t) }
+
+// SetWriteDeadline sets the write deadline of the underlying connection
+func (c *Conn) SetWriteDeadline(t time.Time) error { return c.conn.SetWriteDeadline(t) }
+
+type listener struct {
+	net.Listener
+	config *Config
+}
+
+// Accept waits for the next connection and wraps it in a server Conn.
+// The handshake runs on the first Read or Write.
+func (l *listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return Server(conn, l.config), nil
+}
+
+// NewListener returns a listener that accepts TLS 1.2 connections from
+// inner, served with config
+func NewListener(inner net.Listener, config *Config) net.Listener {
+	return &listener{Listener: inner, config: config}
+}
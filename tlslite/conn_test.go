@@ -0,0 +1,243 @@
+package tlslite
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testCertificate returns a self signed certificate for "badcrypto.test"
+// with a key of the given type, and a pool that trusts it
+func testCertificate(t *testing.T, keyType string) (tls.Certificate, *x509.CertPool) {
+	var (
+		priv crypto.Signer
+		err  error
+	)
+	switch keyType {
+	case "rsa":
+		priv, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "ecdsa":
+		priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "ed25519":
+		_, priv, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "badcrypto.test"},
+		DNSNames:              []string{"badcrypto.test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, priv.Public(), priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: priv}, pool
+}
+
+// tlsServer accepts a single connection on a local port, wraps it in a
+// crypto/tls server and passes it to handler. It returns a connection to
+// that server.
+func tlsServer(t *testing.T, config *tls.Config, handler func(*tls.Conn)) net.Conn {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		defer l.Close()
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		srv := tls.Server(conn, config)
+		defer srv.Close()
+		handler(srv)
+	}()
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+// echoServer runs a crypto/tls server that echoes everything it reads
+func echoServer(t *testing.T, config *tls.Config) net.Conn {
+	return tlsServer(t, config, func(srv *tls.Conn) {
+		io.Copy(srv, srv)
+	})
+}
+
+func TestHandshake(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		keyType string
+		suite   uint16
+	}{
+		{"ecdsa", TLS_AES_128_GCM_SHA256},
+		{"ecdsa", TLS_AES_256_GCM_SHA384},
+		{"ecdsa", TLS_CHACHA20_POLY1305_SHA256},
+		{"rsa", TLS_AES_128_GCM_SHA256},
+		{"rsa", TLS_CHACHA20_POLY1305_SHA256},
+		{"ed25519", TLS_AES_256_GCM_SHA384},
+	}
+	for i, testcase := range testcases {
+		cert, pool := testCertificate(t, testcase.keyType)
+		conn := echoServer(t, &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS13,
+			NextProtos:   []string{"echo"},
+		})
+		c := Client(conn, &Config{
+			RootCAs:      pool,
+			ServerName:   "badcrypto.test",
+			CipherSuites: []uint16{testcase.suite},
+			NextProtos:   []string{"echo"},
+		})
+		if err := c.Handshake(); err != nil {
+			t.Fatalf("testcase %d handshake failed: %v", i, err)
+		}
+		state := c.ConnectionState()
+		if state.Version != VersionTLS13 || state.CipherSuite != testcase.suite || state.NegotiatedProtocol != "echo" {
+			t.Fatalf("testcase %d unexpected connection state %+v", i, state)
+		}
+		// larger than a record to exercise fragmentation
+		msg := make([]byte, 3*maxPlaintext/2)
+		rand.Read(msg)
+		go c.Write(msg)
+		echo := make([]byte, len(msg))
+		if _, err := io.ReadFull(c, echo); err != nil {
+			t.Fatalf("testcase %d read failed: %v", i, err)
+		}
+		if string(echo) != string(msg) {
+			t.Fatalf("testcase %d echo does not match", i)
+		}
+		c.Close()
+	}
+}
+
+func TestHandshakeBadCertificate(t *testing.T) {
+	t.Parallel()
+	cert, _ := testCertificate(t, "ecdsa")
+	_, otherPool := testCertificate(t, "ecdsa")
+	config := &tls.Config{Certificates: []tls.Certificate{cert}}
+
+	c := Client(echoServer(t, config), &Config{RootCAs: otherPool, ServerName: "badcrypto.test"})
+	if err := c.Handshake(); err == nil {
+		t.Fatal("expected handshake to fail with an untrusted certificate")
+	}
+	c.Close()
+
+	_, pool := testCertificate(t, "ecdsa")
+	c = Client(echoServer(t, config), &Config{RootCAs: pool, ServerName: "wrong.test"})
+	if err := c.Handshake(); err == nil {
+		t.Fatal("expected handshake to fail with the wrong server name")
+	}
+	c.Close()
+
+	c = Client(echoServer(t, config), &Config{InsecureSkipVerify: true})
+	if err := c.Handshake(); err != nil {
+		t.Fatalf("expected handshake to succeed without verification: %v", err)
+	}
+	c.Close()
+}
+
+func TestKeyUpdate(t *testing.T) {
+	t.Parallel()
+	cert, pool := testCertificate(t, "ecdsa")
+	done := make(chan error, 1)
+	conn := tlsServer(t, &tls.Config{Certificates: []tls.Certificate{cert}}, func(srv *tls.Conn) {
+		// the server must follow the client's KeyUpdate to read the
+		// message sent after it, and answer with its own KeyUpdate
+		buf := make([]byte, 10)
+		if _, err := io.ReadFull(srv, buf); err != nil {
+			done <- err
+			return
+		}
+		_, err := srv.Write(buf)
+		done <- err
+	})
+	c := Client(conn, &Config{RootCAs: pool, ServerName: "badcrypto.test"})
+	defer c.Close()
+	if _, err := c.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	// rotate the client keys and request an update from the server,
+	// which the client must process before reading the echo
+	c.outMutex.Lock()
+	if err := c.writeRecord(recordTypeHandshake, (&keyUpdateMsg{updateRequested: true}).marshal()); err != nil {
+		t.Fatal(err)
+	}
+	c.outSecret = c.suite.nextTrafficSecret(c.outSecret)
+	out, err := newAEAD13(c.suite, c.outSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.out.setCipher(out)
+	c.outMutex.Unlock()
+	if _, err := c.Write([]byte("world")); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 10)
+	if _, err := io.ReadFull(c, buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "helloworld" {
+		t.Fatalf("expected helloworld but got %q", buf)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGet(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello from " + r.URL.Path))
+	}))
+	srv.TLS = &tls.Config{MinVersion: tls.VersionTLS13}
+	srv.StartTLS()
+	defer srv.Close()
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	resp, err := Get(srv.URL+"/badcrypto", &Config{RootCAs: pool})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || string(body) != "hello from /badcrypto" {
+		t.Fatalf("unexpected response %s: %q", resp.Status, body)
+	}
+}
@@ -0,0 +1,304 @@
+package tlslite
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+
+	"github.com/jvehent/badcrypto/x25519"
+)
+
+// clientHandshake runs the TLS 1.3 handshake of RFC 8446 section 2 as a
+// client. The caller holds inMutex.
+func (c *Conn) clientHandshake() error {
+	config := c.config
+	if config.ServerName == "" && !config.InsecureSkipVerify {
+		return errors.New("tlslite: either ServerName or InsecureSkipVerify must be set")
+	}
+	priv, pub, err := x25519.GenerateKey(config.rand())
+	if err != nil {
+		return err
+	}
+	hello := &clientHelloMsg{
+		vers:              VersionTLS12,
+		random:            make([]byte, 32),
+		sessionID:         make([]byte, 32),
+		supportedGroups:   []uint16{groupX25519},
+		signatureSchemes:  supportedSignatureSchemes,
+		supportedVersions: []uint16{VersionTLS13},
+		keyShares:         []keyShare{{group: groupX25519, data: pub[:]}},
+		alpnProtocols:     config.NextProtos,
+	}
+	for _, id := range config.cipherSuites() {
+		if cipherSuite13ByID(id) != nil {
+			hello.cipherSuites = append(hello.cipherSuites, id)
+		}
+	}
+	if len(hello.cipherSuites) == 0 {
+		return errors.New("tlslite: no supported cipher suite in config")
+	}
+	// SNI does not carry IP addresses
+	if net.ParseIP(config.ServerName) == nil {
+		hello.serverName = config.ServerName
+	}
+	if _, err := io.ReadFull(config.rand(), hello.random); err != nil {
+		return err
+	}
+	// a non empty legacy session id is part of the middlebox compatibility mode
+	if _, err := io.ReadFull(config.rand(), hello.sessionID); err != nil {
+		return err
+	}
+	helloBytes := hello.marshal()
+	if err := c.writeHandshake(helloBytes); err != nil {
+		return err
+	}
+
+	msg, err := c.readHandshakeType(typeServerHello)
+	if err != nil {
+		return err
+	}
+	var sh serverHelloMsg
+	if err := sh.unmarshal(msg); err != nil {
+		return err
+	}
+	if bytes.Equal(sh.random, helloRetryRequestRandom) {
+		return errors.New("tlslite: server sent a HelloRetryRequest, which is not supported")
+	}
+	if sh.supportedVersion != VersionTLS13 {
+		return alertProtocolVersion
+	}
+	if !bytes.Equal(sh.sessionID, hello.sessionID) || sh.compression != 0 {
+		return alertIllegalParameter
+	}
+	suite := cipherSuite13ByID(sh.cipherSuite)
+	if suite == nil || !offered(hello.cipherSuites, sh.cipherSuite) {
+		return alertIllegalParameter
+	}
+	if sh.serverShare.group != groupX25519 {
+		return alertIllegalParameter
+	}
+	c.vers = VersionTLS13
+	c.cipherSuite = suite.id
+	c.serverName = config.ServerName
+	c.suite = suite
+	c.transcript = suite.hash.New()
+	c.transcript.Write(helloBytes)
+	c.transcript.Write(msg)
+
+	sharedKey, err := x25519.SharedSecret(priv[:], sh.serverShare.data)
+	if err != nil {
+		return alertIllegalParameter
+	}
+	earlySecret := suite.extract(nil, nil)
+	handshakeSecret := suite.extract(sharedKey, suite.deriveSecret(earlySecret, "derived", nil))
+	clientSecret := suite.deriveSecret(handshakeSecret, "c hs traffic", c.transcript)
+	serverSecret := suite.deriveSecret(handshakeSecret, "s hs traffic", c.transcript)
+	in, err := newAEAD13(suite, serverSecret)
+	if err != nil {
+		return err
+	}
+	c.in.setCipher(in)
+
+	msg, err = c.readHandshakeType(typeEncryptedExtensions)
+	if err != nil {
+		return err
+	}
+	var ee encryptedExtensionsMsg
+	if err := ee.unmarshal(msg); err != nil {
+		return err
+	}
+	if ee.alpnProtocol != "" && !offeredProto(config.NextProtos, ee.alpnProtocol) {
+		return alertUnsupportedExtension
+	}
+	c.negotiatedProtocol = ee.alpnProtocol
+	c.transcript.Write(msg)
+
+	msg, err = c.readHandshake()
+	if err != nil {
+		return err
+	}
+	if msg[0] == typeCertificateRequest {
+		return errors.New("tlslite: server requested a client certificate, which is not supported")
+	}
+	if msg[0] != typeCertificate {
+		return alertUnexpectedMessage
+	}
+	var cm certificateMsg
+	if err := cm.unmarshal(msg); err != nil {
+		return err
+	}
+	if err := c.verifyServerCertificate(cm.certificates); err != nil {
+		return err
+	}
+	c.transcript.Write(msg)
+
+	msg, err = c.readHandshakeType(typeCertificateVerify)
+	if err != nil {
+		return err
+	}
+	var cv certificateVerifyMsg
+	if err := cv.unmarshal(msg); err != nil {
+		return err
+	}
+	signed := signedMessage(serverSignatureContext, c.transcript)
+	if err := verifySignature(c.peerCertificates[0].PublicKey, cv.signatureScheme, signed, cv.signature); err != nil {
+		return err
+	}
+	c.transcript.Write(msg)
+
+	msg, err = c.readHandshakeType(typeFinished)
+	if err != nil {
+		return err
+	}
+	expected := suite.finishedHash(serverSecret, c.transcript)
+	if !hmac.Equal(expected, msg[4:]) {
+		return alertDecryptError
+	}
+	c.transcript.Write(msg)
+
+	masterSecret := suite.extract(nil, suite.deriveSecret(handshakeSecret, "derived", nil))
+	c.inSecret = suite.deriveSecret(masterSecret, "s ap traffic", c.transcript)
+	c.outSecret = suite.deriveSecret(masterSecret, "c ap traffic", c.transcript)
+
+	c.outMutex.Lock()
+	// a dummy ChangeCipherSpec keeps middleboxes happy
+	err = c.writeRecord(recordTypeChangeCipherSpec, []byte{1})
+	c.outMutex.Unlock()
+	if err != nil {
+		return err
+	}
+	out, err := newAEAD13(suite, clientSecret)
+	if err != nil {
+		return err
+	}
+	c.out.setCipher(out)
+	finished := handshakeMessage(typeFinished, suite.finishedHash(clientSecret, c.transcript))
+	if err := c.writeHandshake(finished); err != nil {
+		return err
+	}
+
+	if in, err = newAEAD13(suite, c.inSecret); err != nil {
+		return err
+	}
+	c.in.setCipher(in)
+	if out, err = newAEAD13(suite, c.outSecret); err != nil {
+		return err
+	}
+	c.outMutex.Lock()
+	c.out.setCipher(out)
+	c.outMutex.Unlock()
+	return nil
+}
+
+func offered(suites []uint16, id uint16) bool {
+	for _, s := range suites {
+		if s == id {
+			return true
+		}
+	}
+	return false
+}
+
+func offeredProto(protos []string, proto string) bool {
+	for _, p := range protos {
+		if p == proto {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyServerCertificate parses the certificate chain sent by the server
+// and, unless InsecureSkipVerify is set, verifies it against RootCAs
+func (c *Conn) verifyServerCertificate(certificates [][]byte) error {
+	if len(certificates) == 0 {
+		return alertDecodeError
+	}
+	certs := make([]*x509.Certificate, len(certificates))
+	for i, der := range certificates {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return fmt.Errorf("tlslite: failed to parse server certificate: %v", err)
+		}
+		certs[i] = cert
+	}
+	if !c.config.InsecureSkipVerify {
+		opts := x509.VerifyOptions{
+			Roots:         c.config.RootCAs,
+			CurrentTime:   c.config.time(),
+			DNSName:       c.config.ServerName,
+			Intermediates: x509.NewCertPool(),
+		}
+		for _, cert := range certs[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		if _, err := certs[0].Verify(opts); err != nil {
+			return fmt.Errorf("tlslite: failed to verify server certificate: %v", err)
+		}
+	}
+	switch certs[0].PublicKey.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+	default:
+		return fmt.Errorf("tlslite: unsupported server public key of type %T", certs[0].PublicKey)
+	}
+	c.peerCertificates = certs
+	return nil
+}
+
+const serverSignatureContext = "TLS 1.3, server CertificateVerify\x00"
+
+// signedMessage builds the content covered by a CertificateVerify
+// signature: 64 spaces, a context string and the transcript hash
+func signedMessage(context string, transcript hash.Hash) []byte {
+	out := bytes.Repeat([]byte{0x20}, 64)
+	out = append(out, context...)
+	return transcript.Sum(out)
+}
+
+// verifySignature checks a CertificateVerify signature made with scheme.
+// PKCS#1 v1.5 is not allowed in TLS 1.3 handshake signatures.
+func verifySignature(pub crypto.PublicKey, scheme uint16, signed, sig []byte) error {
+	var h crypto.Hash
+	switch scheme {
+	case ecdsaWithP256AndSHA256, pssWithSHA256:
+		h = crypto.SHA256
+	case ecdsaWithP384AndSHA384, pssWithSHA384:
+		h = crypto.SHA384
+	case ecdsaWithP521AndSHA512, pssWithSHA512:
+		h = crypto.SHA512
+	case ed25519Scheme:
+		key, ok := pub.(ed25519.PublicKey)
+		if !ok || !ed25519.Verify(key, signed, sig) {
+			return alertDecryptError
+		}
+		return nil
+	default:
+		return alertIllegalParameter
+	}
+	digest := h.New()
+	digest.Write(signed)
+	hashed := digest.Sum(nil)
+	switch key := pub.(type) {
+	case *ecdsa.PublicKey:
+		if scheme&0xff != 0x03 || !ecdsa.VerifyASN1(key, hashed, sig) {
+			return alertDecryptError
+		}
+	case *rsa.PublicKey:
+		opts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}
+		if scheme>>8 != 0x08 || rsa.VerifyPSS(key, h, hashed, sig, opts) != nil {
+			return alertDecryptError
+		}
+	default:
+		return alertDecryptError
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+package tlslite
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/x25519"
+)
+
+// serverSignatureSchemes are the schemes the server can sign its ECDHE
+// parameters with, in order of preference
+var serverSignatureSchemes = []uint16{
+	pkcs1WithSHA256,
+	pssWithSHA256,
+	pkcs1WithSHA384,
+	pssWithSHA384,
+	pkcs1WithSHA512,
+	pssWithSHA512,
+}
+
+// serverHandshake runs a full TLS 1.2 handshake of RFC 5246 section 7.3
+// as a server. The caller holds inMutex.
+func (c *Conn) serverHandshake() error {
+	config := c.config
+	if len(config.Certificates) == 0 {
+		return errors.New("tlslite: no certificate in server config")
+	}
+	cert := config.Certificates[0]
+	rsaPub, ok := cert.PrivateKey.Public().(*rsa.PublicKey)
+	if !ok {
+		return errors.New("tlslite: the TLS 1.2 server only supports RSA keys")
+	}
+
+	msg, err := c.readHandshakeType(typeClientHello)
+	if err != nil {
+		return err
+	}
+	var ch clientHelloMsg
+	if err := ch.unmarshal(msg); err != nil {
+		return err
+	}
+	if ch.vers < VersionTLS12 {
+		return alertProtocolVersion
+	}
+	suite := c.pickCipherSuite12(&ch)
+	if suite == nil {
+		return alertHandshakeFailure
+	}
+	c.vers = VersionTLS12
+	c.cipherSuite = suite.id
+	c.serverName = ch.serverName
+	c.transcript = suite.hash.New()
+	c.transcript.Write(msg)
+
+	hello := &serverHelloMsg{
+		vers:                VersionTLS12,
+		random:              make([]byte, 32),
+		cipherSuite:         suite.id,
+		secureRenegotiation: ch.secureRenegotiation,
+	}
+	if _, err := io.ReadFull(config.rand(), hello.random); err != nil {
+		return err
+	}
+	if err := c.writeHandshake(hello.marshal()); err != nil {
+		return err
+	}
+	var certs []byte
+	for _, der := range cert.Certificate {
+		certs = appendVec24(certs, der)
+	}
+	if err := c.writeHandshake(handshakeMessage(typeCertificate, appendVec24(nil, certs))); err != nil {
+		return err
+	}
+
+	var ecdhePriv []byte
+	if suite.ecdhe {
+		var skx []byte
+		ecdhePriv, skx, err = c.serverKeyExchange(&ch, hello.random, cert.PrivateKey)
+		if err != nil {
+			return err
+		}
+		if err := c.writeHandshake(skx); err != nil {
+			return err
+		}
+	}
+	if err := c.writeHandshake(handshakeMessage(typeServerHelloDone, nil)); err != nil {
+		return err
+	}
+
+	msg, err = c.readHandshakeType(typeClientKeyExchange)
+	if err != nil {
+		return err
+	}
+	p := &parser{b: msg[4:]}
+	var preMasterSecret []byte
+	if suite.ecdhe {
+		peer := p.vec8()
+		if !p.empty() {
+			return errDecode
+		}
+		if preMasterSecret, err = x25519.SharedSecret(ecdhePriv, peer); err != nil {
+			return alertIllegalParameter
+		}
+	} else {
+		ciphertext := p.vec16()
+		if !p.empty() {
+			return errDecode
+		}
+		if preMasterSecret, err = c.decryptPreMasterSecret(cert.PrivateKey, rsaPub, ciphertext, ch.vers); err != nil {
+			return err
+		}
+	}
+	c.transcript.Write(msg)
+
+	masterSecret := suite.masterSecret(preMasterSecret, ch.random, hello.random)
+	clientKey, serverKey, clientIV, serverIV := suite.keys(masterSecret, ch.random, hello.random)
+
+	if err := c.readChangeCipherSpec(); err != nil {
+		return err
+	}
+	in, err := newAEAD12(clientKey, clientIV)
+	if err != nil {
+		return err
+	}
+	c.in.setCipher(in)
+	msg, err = c.readHandshakeType(typeFinished)
+	if err != nil {
+		return err
+	}
+	expected := suite.finishedHash(masterSecret, "client finished", c.transcript)
+	if !hmac.Equal(expected, msg[4:]) {
+		return alertDecryptError
+	}
+	c.transcript.Write(msg)
+
+	c.outMutex.Lock()
+	err = c.writeRecord(recordTypeChangeCipherSpec, []byte{1})
+	c.outMutex.Unlock()
+	if err != nil {
+		return err
+	}
+	out, err := newAEAD12(serverKey, serverIV)
+	if err != nil {
+		return err
+	}
+	c.outMutex.Lock()
+	c.out.setCipher(out)
+	c.outMutex.Unlock()
+	finished := suite.finishedHash(masterSecret, "server finished", c.transcript)
+	return c.writeHandshake(handshakeMessage(typeFinished, finished))
+}
+
+// pickCipherSuite12 selects the first suite of the server preference
+// list that the client offered. ECDHE suites require X25519.
+func (c *Conn) pickCipherSuite12(ch *clientHelloMsg) *cipherSuite12 {
+	x25519Supported := false
+	for _, g := range ch.supportedGroups {
+		if g == groupX25519 {
+			x25519Supported = true
+		}
+	}
+	preferences := c.config.CipherSuites
+	if len(preferences) == 0 {
+		for _, s := range cipherSuites12 {
+			preferences = append(preferences, s.id)
+		}
+	}
+	for _, id := range preferences {
+		suite := cipherSuite12ByID(id)
+		if suite == nil || !offered(ch.cipherSuites, id) {
+			continue
+		}
+		if suite.ecdhe && !x25519Supported {
+			continue
+		}
+		return suite
+	}
+	return nil
+}
+
+// serverKeyExchange generates the server X25519 share, unless a static
+// key is configured, and returns it with the ServerKeyExchange message
+// carrying the share signed along with the hello randoms
+func (c *Conn) serverKeyExchange(ch *clientHelloMsg, serverRandom []byte, key crypto.Signer) ([]byte, []byte, error) {
+	var priv [x25519.Size]byte
+	if static := c.config.Weaknesses.StaticECDHEKey; static != nil {
+		if len(static) != x25519.Size {
+			return nil, nil, errors.New("tlslite: static ECDHE key must be 32 bytes")
+		}
+		copy(priv[:], static)
+	} else if _, err := io.ReadFull(c.config.rand(), priv[:]); err != nil {
+		return nil, nil, err
+	}
+	pub := x25519.ScalarBaseMult(priv)
+	// named_curve, the group and the public point
+	params := []byte{3}
+	params = appendU16(params, groupX25519)
+	params = appendVec8(params, pub[:])
+
+	// clients that do not send signature_algorithms imply PKCS#1 v1.5 with SHA-1
+	scheme := pkcs1WithSHA1
+	if len(ch.signatureSchemes) > 0 {
+		scheme = 0
+		for _, s := range serverSignatureSchemes {
+			if offered(ch.signatureSchemes, s) {
+				scheme = s
+				break
+			}
+		}
+		if scheme == 0 {
+			return nil, nil, alertHandshakeFailure
+		}
+	}
+	var h crypto.Hash
+	switch scheme {
+	case pkcs1WithSHA1:
+		h = crypto.SHA1
+	case pkcs1WithSHA256, pssWithSHA256:
+		h = crypto.SHA256
+	case pkcs1WithSHA384, pssWithSHA384:
+		h = crypto.SHA384
+	default:
+		h = crypto.SHA512
+	}
+	var opts crypto.SignerOpts = h
+	if scheme>>8 == 0x08 {
+		opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: h}
+	}
+	digest := h.New()
+	digest.Write(ch.random)
+	digest.Write(serverRandom)
+	digest.Write(params)
+	sig, err := key.Sign(c.config.rand(), digest.Sum(nil), opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	body := appendU16(params, scheme)
+	body = appendVec16(body, sig)
+	return priv[:], handshakeMessage(typeServerKeyExchange, body), nil
+}
+
+// decryptPreMasterSecret recovers the premaster secret of an RSA key
+// exchange. Depending on the configured oracle, a malformed message is
+// either replaced by a random premaster secret as RFC 5246 section
+// 7.4.7.1 requires, or reported with a decrypt_error alert.
+func (c *Conn) decryptPreMasterSecret(key crypto.Signer, pub *rsa.PublicKey, ciphertext []byte, clientVersion uint16) ([]byte, error) {
+	priv, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("tlslite: the RSA key exchange needs an *rsa.PrivateKey")
+	}
+	random := make([]byte, masterSecretLength)
+	if _, err := io.ReadFull(c.config.rand(), random); err != nil {
+		return nil, err
+	}
+	k := (pub.N.BitLen() + 7) / 8
+	if len(ciphertext) != k {
+		if c.config.Weaknesses.PKCS1Oracle == PKCS1Implicit {
+			return random, nil
+		}
+		return nil, alertDecryptError
+	}
+	// textbook RSA decryption, m = c^d mod n, left padded to k bytes
+	m := new(big.Int).Exp(new(big.Int).SetBytes(ciphertext), priv.D, pub.N)
+	em := m.FillBytes(make([]byte, k))
+
+	conformant := em[0] == 0 && em[1] == 2
+	if c.config.Weaknesses.PKCS1Oracle == PKCS1OracleConformant && !conformant {
+		return nil, alertDecryptError
+	}
+	// the separator must follow at least eight non zero padding bytes
+	sep := 0
+	for i := 2; i < len(em); i++ {
+		if em[i] == 0 {
+			sep = i
+			break
+		}
+	}
+	valid := conformant && sep >= 10 && len(em)-sep-1 == masterSecretLength
+	if valid {
+		preMaster := em[sep+1:]
+		valid = subtle.ConstantTimeCompare(preMaster[:2], []byte{byte(clientVersion >> 8), byte(clientVersion)}) == 1
+	}
+	switch {
+	case valid:
+		return em[sep+1:], nil
+	case c.config.Weaknesses.PKCS1Oracle == PKCS1OracleStrict:
+		return nil, alertDecryptError
+	default:
+		// a conformant oracle only reveals the first two bytes, what
+		// follows is hidden behind the random premaster secret
+		return random, nil
+	}
+}
+
+// readChangeCipherSpec reads the ChangeCipherSpec record that precedes
+// the peer Finished message in TLS 1.2
+func (c *Conn) readChangeCipherSpec() error {
+	if len(c.hand) > 0 {
+		return alertUnexpectedMessage
+	}
+	typ, data, err := c.readRecord()
+	if err != nil {
+		return err
+	}
+	if typ == recordTypeAlert {
+		return alertFromRecord(data)
+	}
+	if typ != recordTypeChangeCipherSpec || len(data) != 1 || data[0] != 1 {
+		return alertUnexpectedMessage
+	}
+	return nil
+}
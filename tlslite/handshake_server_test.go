@@ -0,0 +1,175 @@
+package tlslite
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+)
+
+func TestPRF12(t *testing.T) {
+	t.Parallel()
+	// TLS 1.2 PRF vector for SHA-256 published on the IETF TLS list
+	secret, _ := hex.DecodeString("9bbe436ba940f017b17652849a71db35")
+	seed, _ := hex.DecodeString("a0ba9f936cda311827a6f796ffd5198c")
+	expected := "e3f229ba727be17b8d122620557cd453c2aab21d07c3d495329b52d4e61edb5a" +
+		"6b301791e90d35c9c9a46b4e14baf9af0fa022f7077def17abfd3797c0564bab" +
+		"4fbc91666e9def9b97fce34f796789baa48082d122ee42c5a72e5a5110fff701" +
+		"87347b66"
+	out := prf12(sha256.New, secret, "test label", seed, 100)
+	if hex.EncodeToString(out) != expected {
+		t.Fatalf("unexpected prf output\nexp %s\ngot %x", expected, out)
+	}
+}
+
+// serverConfig returns a TLS 1.2 server config with an RSA certificate
+// for "badcrypto.test", and the matching crypto/tls client config
+func serverConfig(t *testing.T) (*Config, *tls.Config) {
+	cert, pool := testCertificate(t, "rsa")
+	config := &Config{Certificates: []Certificate{{
+		Certificate: cert.Certificate,
+		PrivateKey:  cert.PrivateKey.(*rsa.PrivateKey),
+	}}}
+	return config, &tls.Config{RootCAs: pool, ServerName: "badcrypto.test", MaxVersion: tls.VersionTLS12}
+}
+
+// echoListener serves a single echo connection with config
+func echoListener(t *testing.T, config *Config) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tl := NewListener(l, config)
+	go func() {
+		defer tl.Close()
+		conn, err := tl.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.Copy(conn, conn)
+	}()
+	return l.Addr().String()
+}
+
+func TestServerHandshake(t *testing.T) {
+	t.Parallel()
+	var testcases = []uint16{
+		TLS_RSA_WITH_AES_128_GCM_SHA256,
+		TLS_RSA_WITH_AES_256_GCM_SHA384,
+		TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+		TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	}
+	for i, suite := range testcases {
+		config, clientConfig := serverConfig(t)
+		clientConfig.CipherSuites = []uint16{suite}
+		conn, err := tls.Dial("tcp", echoListener(t, config), clientConfig)
+		if err != nil {
+			t.Fatalf("testcase %d handshake failed: %v", i, err)
+		}
+		state := conn.ConnectionState()
+		if state.Version != tls.VersionTLS12 || state.CipherSuite != suite {
+			t.Fatalf("testcase %d negotiated version %x and suite %x", i, state.Version, state.CipherSuite)
+		}
+		msg := make([]byte, 3*maxPlaintext/2)
+		rand.Read(msg)
+		go conn.Write(msg)
+		echo := make([]byte, len(msg))
+		if _, err := io.ReadFull(conn, echo); err != nil {
+			t.Fatalf("testcase %d read failed: %v", i, err)
+		}
+		if !bytes.Equal(echo, msg) {
+			t.Fatalf("testcase %d echo does not match", i)
+		}
+		conn.Close()
+	}
+}
+
+func TestServerStaticECDHEKey(t *testing.T) {
+	t.Parallel()
+	config, _ := serverConfig(t)
+	config.Weaknesses.StaticECDHEKey = bytes.Repeat([]byte{0x42}, 32)
+	ch := &clientHelloMsg{random: make([]byte, 32), signatureSchemes: []uint16{pkcs1WithSHA256}}
+	var shares [][]byte
+	for i := 0; i < 2; i++ {
+		c := Server(nil, config)
+		_, skx, err := c.serverKeyExchange(ch, make([]byte, 32), config.Certificates[0].PrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// skip the handshake header, curve type and group
+		p := &parser{b: skx[7:]}
+		shares = append(shares, p.vec8())
+	}
+	if !bytes.Equal(shares[0], shares[1]) {
+		t.Fatal("expected the static ECDHE key to be reused")
+	}
+}
+
+func TestPKCS1Oracle(t *testing.T) {
+	t.Parallel()
+	config, _ := serverConfig(t)
+	priv := config.Certificates[0].PrivateKey.(*rsa.PrivateKey)
+	preMaster := make([]byte, 48)
+	rand.Read(preMaster)
+	preMaster[0], preMaster[1] = 3, 3
+
+	valid, err := rsa.EncryptPKCS1v15(rand.Reader, &priv.PublicKey, preMaster)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// conformant padding, but the message is too short to be a premaster
+	short, err := rsa.EncryptPKCS1v15(rand.Reader, &priv.PublicKey, preMaster[:20])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a message starting with 0x00 0x03 is not conformant
+	em := make([]byte, priv.Size())
+	em[1] = 3
+	m := new(big.Int).SetBytes(em)
+	bad := new(big.Int).Exp(m, big.NewInt(int64(priv.E)), priv.N).FillBytes(make([]byte, priv.Size()))
+
+	var testcases = []struct {
+		oracle      PKCS1Oracle
+		ciphertext  []byte
+		expectAlert bool
+	}{
+		{PKCS1Implicit, valid, false},
+		{PKCS1Implicit, short, false},
+		{PKCS1Implicit, bad, false},
+		{PKCS1OracleConformant, valid, false},
+		{PKCS1OracleConformant, short, false},
+		{PKCS1OracleConformant, bad, true},
+		{PKCS1OracleStrict, valid, false},
+		{PKCS1OracleStrict, short, true},
+		{PKCS1OracleStrict, bad, true},
+	}
+	for i, testcase := range testcases {
+		cfg := *config
+		cfg.Weaknesses.PKCS1Oracle = testcase.oracle
+		c := Server(nil, &cfg)
+		out, err := c.decryptPreMasterSecret(priv, &priv.PublicKey, testcase.ciphertext, VersionTLS12)
+		if testcase.expectAlert {
+			if err != alertDecryptError {
+				t.Fatalf("testcase %d expected a decrypt_error alert but got %v", i, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("testcase %d unexpected error %v", i, err)
+		}
+		if len(out) != 48 {
+			t.Fatalf("testcase %d expected a 48 bytes premaster secret but got %d", i, len(out))
+		}
+		isValid := bytes.Equal(testcase.ciphertext, valid)
+		if bytes.Equal(out, preMaster) != isValid {
+			t.Fatalf("testcase %d premaster secret does not match expectations", i)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package tlslite
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// Get fetches rawurl over a tlslite connection with a bare HTTP/1.1
+// request and returns the response. The connection is closed when the
+// response body is closed.
+func Get(rawurl string, config *Config) (*http.Response, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "https" {
+		return nil, fmt.Errorf("tlslite: unsupported scheme %q", u.Scheme)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "443")
+	}
+	cfg := Config{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = u.Hostname()
+	}
+	if len(cfg.NextProtos) == 0 {
+		cfg.NextProtos = []string{"http/1.1"}
+	}
+	conn, err := Dial("tcp", addr, &cfg)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("GET", rawurl, nil)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "badcrypto-tlslite")
+	req.Close = true
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body = &bodyCloser{ReadCloser: resp.Body, conn: conn}
+	return resp, nil
+}
+
+// bodyCloser closes the TLS connection along with the response body
+type bodyCloser struct {
+	io.ReadCloser
+	conn *Conn
+}
+
+func (b *bodyCloser) Close() error {
+	err := b.ReadCloser.Close()
+	b.conn.Close()
+	return err
+}
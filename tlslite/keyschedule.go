@@ -0,0 +1,97 @@
+package tlslite
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	_ "crypto/sha256" // registers SHA-256 for crypto.SHA256.New
+	_ "crypto/sha512" // registers SHA-384 for crypto.SHA384.New
+	"hash"
+
+	"github.com/jvehent/badcrypto/chacha20poly1305"
+	"github.com/jvehent/badcrypto/kdf"
+)
+
+// cipherSuite13 holds the AEAD and hash of a TLS 1.3 cipher suite
+type cipherSuite13 struct {
+	id     uint16
+	keyLen int
+	aead   func(key []byte) (cipher.AEAD, error)
+	hash   crypto.Hash
+}
+
+var cipherSuites13 = []*cipherSuite13{
+	{TLS_AES_128_GCM_SHA256, 16, aeadAESGCM, crypto.SHA256},
+	{TLS_CHACHA20_POLY1305_SHA256, 32, chacha20poly1305.New, crypto.SHA256},
+	{TLS_AES_256_GCM_SHA384, 32, aeadAESGCM, crypto.SHA384},
+}
+
+func cipherSuite13ByID(id uint16) *cipherSuite13 {
+	for _, s := range cipherSuites13 {
+		if s.id == id {
+			return s
+		}
+	}
+	return nil
+}
+
+func aeadAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// expandLabel implements HKDF-Expand-Label from RFC 8446 section 7.1
+func (s *cipherSuite13) expandLabel(secret []byte, label string, context []byte, length int) []byte {
+	info := appendU16(nil, uint16(length))
+	info = appendVec8(info, []byte("tls13 "+label))
+	info = appendVec8(info, context)
+	out, err := kdf.HKDFExpand(s.hash.New, secret, info, length)
+	if err != nil {
+		// lengths are fixed by the protocol and always fit
+		panic(err)
+	}
+	return out
+}
+
+// deriveSecret implements Derive-Secret from RFC 8446 section 7.1. A nil
+// transcript stands for the hash of an empty message list.
+func (s *cipherSuite13) deriveSecret(secret []byte, label string, transcript hash.Hash) []byte {
+	if transcript == nil {
+		transcript = s.hash.New()
+	}
+	return s.expandLabel(secret, label, transcript.Sum(nil), s.hash.Size())
+}
+
+// extract is HKDF-Extract with the current secret as salt. A nil input
+// secret is replaced by a string of zeroes, as in the key schedule.
+func (s *cipherSuite13) extract(newSecret, currentSecret []byte) []byte {
+	if newSecret == nil {
+		newSecret = make([]byte, s.hash.Size())
+	}
+	return kdf.HKDFExtract(s.hash.New, newSecret, currentSecret)
+}
+
+// nextTrafficSecret derives the secret that follows a KeyUpdate
+func (s *cipherSuite13) nextTrafficSecret(secret []byte) []byte {
+	return s.expandLabel(secret, "traffic upd", nil, s.hash.Size())
+}
+
+// trafficKey derives the record protection key and iv of a traffic secret
+func (s *cipherSuite13) trafficKey(secret []byte) (key, iv []byte) {
+	key = s.expandLabel(secret, "key", nil, s.keyLen)
+	iv = s.expandLabel(secret, "iv", nil, 12)
+	return
+}
+
+// finishedHash computes the verify_data of a Finished message, which is
+// an HMAC of the transcript keyed with a key derived from baseKey
+func (s *cipherSuite13) finishedHash(baseKey []byte, transcript hash.Hash) []byte {
+	finishedKey := s.expandLabel(baseKey, "finished", nil, s.hash.Size())
+	mac := hmac.New(s.hash.New, finishedKey)
+	mac.Write(transcript.Sum(nil))
+	return mac.Sum(nil)
+}
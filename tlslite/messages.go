@@ -0,0 +1,340 @@
+package tlslite
+
+import "errors"
+
+var errDecode = errors.New("tlslite: malformed handshake message")
+
+// helloRetryRequestRandom is the special ServerHello random value that
+// identifies a HelloRetryRequest, from RFC 8446 section 4.1.3
+var helloRetryRequestRandom = []byte{
+	0xcf, 0x21, 0xad, 0x74, 0xe5, 0x9a, 0x61, 0x11,
+	0xbe, 0x1d, 0x8c, 0x02, 0x1e, 0x65, 0xb8, 0x91,
+	0xc2, 0xa2, 0x11, 0x16, 0x7a, 0xbb, 0x8c, 0x5e,
+	0x07, 0x9e, 0x09, 0xe2, 0xc8, 0xa8, 0x33, 0x9c,
+}
+
+type keyShare struct {
+	group uint16
+	data  []byte
+}
+
+type clientHelloMsg struct {
+	vers                uint16
+	random              []byte
+	sessionID           []byte
+	cipherSuites        []uint16
+	serverName          string
+	supportedGroups     []uint16
+	signatureSchemes    []uint16
+	supportedVersions   []uint16
+	keyShares           []keyShare
+	alpnProtocols       []string
+	secureRenegotiation bool
+}
+
+func (m *clientHelloMsg) marshal() []byte {
+	body := appendU16(nil, m.vers)
+	body = append(body, m.random...)
+	body = appendVec8(body, m.sessionID)
+	var suites []byte
+	for _, s := range m.cipherSuites {
+		suites = appendU16(suites, s)
+	}
+	body = appendVec16(body, suites)
+	// only the null compression method
+	body = appendVec8(body, []byte{0})
+
+	var exts []byte
+	if m.serverName != "" {
+		name := appendVec16([]byte{0}, []byte(m.serverName))
+		exts = appendU16(exts, extensionServerName)
+		exts = appendVec16(exts, appendVec16(nil, name))
+	}
+	if len(m.supportedGroups) > 0 {
+		var groups []byte
+		for _, g := range m.supportedGroups {
+			groups = appendU16(groups, g)
+		}
+		exts = appendU16(exts, extensionSupportedGroups)
+		exts = appendVec16(exts, appendVec16(nil, groups))
+	}
+	if len(m.signatureSchemes) > 0 {
+		var schemes []byte
+		for _, s := range m.signatureSchemes {
+			schemes = appendU16(schemes, s)
+		}
+		exts = appendU16(exts, extensionSignatureAlgorithms)
+		exts = appendVec16(exts, appendVec16(nil, schemes))
+	}
+	if len(m.alpnProtocols) > 0 {
+		var protos []byte
+		for _, p := range m.alpnProtocols {
+			protos = appendVec8(protos, []byte(p))
+		}
+		exts = appendU16(exts, extensionALPN)
+		exts = appendVec16(exts, appendVec16(nil, protos))
+	}
+	if len(m.supportedVersions) > 0 {
+		var versions []byte
+		for _, v := range m.supportedVersions {
+			versions = appendU16(versions, v)
+		}
+		exts = appendU16(exts, extensionSupportedVersions)
+		exts = appendVec16(exts, appendVec8(nil, versions))
+	}
+	if len(m.keyShares) > 0 {
+		var shares []byte
+		for _, ks := range m.keyShares {
+			shares = appendU16(shares, ks.group)
+			shares = appendVec16(shares, ks.data)
+		}
+		exts = appendU16(exts, extensionKeyShare)
+		exts = appendVec16(exts, appendVec16(nil, shares))
+	}
+	body = appendVec16(body, exts)
+	return handshakeMessage(typeClientHello, body)
+}
+
+// unmarshal parses a ClientHello message, header included. Only the
+// extensions used by the TLS 1.2 server are decoded.
+func (m *clientHelloMsg) unmarshal(msg []byte) error {
+	p := &parser{b: msg[4:]}
+	m.vers = p.u16()
+	m.random = p.read(32)
+	m.sessionID = p.vec8()
+	suites := &parser{b: p.vec16()}
+	compressions := p.vec8()
+	if p.bad || len(suites.b)%2 != 0 {
+		return errDecode
+	}
+	for len(suites.b) > 0 {
+		s := suites.u16()
+		if s == scsvRenegotiation {
+			m.secureRenegotiation = true
+		}
+		m.cipherSuites = append(m.cipherSuites, s)
+	}
+	nullCompression := false
+	for _, c := range compressions {
+		if c == 0 {
+			nullCompression = true
+		}
+	}
+	if !nullCompression {
+		return alertIllegalParameter
+	}
+	if len(p.b) == 0 {
+		return nil
+	}
+	exts := &parser{b: p.vec16()}
+	if !p.empty() {
+		return errDecode
+	}
+	for len(exts.b) > 0 {
+		typ := exts.u16()
+		ext := &parser{b: exts.vec16()}
+		switch typ {
+		case extensionServerName:
+			names := &parser{b: ext.vec16()}
+			for len(names.b) > 0 {
+				nameType := names.u8()
+				name := names.vec16()
+				if nameType == 0 {
+					m.serverName = string(name)
+				}
+			}
+			if names.bad {
+				return errDecode
+			}
+		case extensionSupportedGroups:
+			groups := &parser{b: ext.vec16()}
+			for len(groups.b) > 0 {
+				m.supportedGroups = append(m.supportedGroups, groups.u16())
+			}
+			if groups.bad {
+				return errDecode
+			}
+		case extensionSignatureAlgorithms:
+			schemes := &parser{b: ext.vec16()}
+			for len(schemes.b) > 0 {
+				m.signatureSchemes = append(m.signatureSchemes, schemes.u16())
+			}
+			if schemes.bad {
+				return errDecode
+			}
+		case extensionRenegotiationInfo:
+			// only initial handshakes are supported, so the
+			// renegotiated connection must be empty
+			if info := ext.vec8(); len(info) != 0 {
+				return alertHandshakeFailure
+			}
+			m.secureRenegotiation = true
+		default:
+			continue
+		}
+		if !ext.empty() {
+			return errDecode
+		}
+	}
+	if exts.bad {
+		return errDecode
+	}
+	return nil
+}
+
+type serverHelloMsg struct {
+	vers             uint16
+	random           []byte
+	sessionID        []byte
+	cipherSuite      uint16
+	compression      uint8
+	supportedVersion uint16
+	serverShare      keyShare
+
+	secureRenegotiation bool
+}
+
+// marshal encodes a TLS 1.2 ServerHello
+func (m *serverHelloMsg) marshal() []byte {
+	body := appendU16(nil, m.vers)
+	body = append(body, m.random...)
+	body = appendVec8(body, m.sessionID)
+	body = appendU16(body, m.cipherSuite)
+	body = append(body, m.compression)
+	if m.secureRenegotiation {
+		var exts []byte
+		exts = appendU16(exts, extensionRenegotiationInfo)
+		exts = appendVec16(exts, appendVec8(nil, nil))
+		body = appendVec16(body, exts)
+	}
+	return handshakeMessage(typeServerHello, body)
+}
+
+// unmarshal parses a ServerHello message, header included
+func (m *serverHelloMsg) unmarshal(msg []byte) error {
+	p := &parser{b: msg[4:]}
+	m.vers = p.u16()
+	m.random = p.read(32)
+	m.sessionID = p.vec8()
+	m.cipherSuite = p.u16()
+	m.compression = p.u8()
+	if p.bad {
+		return errDecode
+	}
+	if len(p.b) == 0 {
+		// a TLS 1.2 server may omit extensions altogether
+		return nil
+	}
+	exts := &parser{b: p.vec16()}
+	if !p.empty() {
+		return errDecode
+	}
+	for len(exts.b) > 0 {
+		typ := exts.u16()
+		ext := &parser{b: exts.vec16()}
+		switch typ {
+		case extensionSupportedVersions:
+			m.supportedVersion = ext.u16()
+		case extensionKeyShare:
+			m.serverShare.group = ext.u16()
+			m.serverShare.data = ext.vec16()
+		default:
+			continue
+		}
+		if !ext.empty() {
+			return errDecode
+		}
+	}
+	if exts.bad {
+		return errDecode
+	}
+	return nil
+}
+
+type encryptedExtensionsMsg struct {
+	alpnProtocol string
+}
+
+func (m *encryptedExtensionsMsg) unmarshal(msg []byte) error {
+	p := &parser{b: msg[4:]}
+	exts := &parser{b: p.vec16()}
+	if !p.empty() {
+		return errDecode
+	}
+	for len(exts.b) > 0 {
+		typ := exts.u16()
+		ext := &parser{b: exts.vec16()}
+		if typ != extensionALPN {
+			continue
+		}
+		protos := &parser{b: ext.vec16()}
+		m.alpnProtocol = string(protos.vec8())
+		if !ext.empty() || !protos.empty() || m.alpnProtocol == "" {
+			return errDecode
+		}
+	}
+	if exts.bad {
+		return errDecode
+	}
+	return nil
+}
+
+type certificateMsg struct {
+	certificates [][]byte
+}
+
+func (m *certificateMsg) unmarshal(msg []byte) error {
+	p := &parser{b: msg[4:]}
+	if context := p.vec8(); len(context) != 0 {
+		return errDecode
+	}
+	list := &parser{b: p.vec24()}
+	if !p.empty() {
+		return errDecode
+	}
+	for len(list.b) > 0 {
+		cert := list.vec24()
+		// per certificate extensions such as OCSP staples are ignored
+		list.vec16()
+		if list.bad || len(cert) == 0 {
+			return errDecode
+		}
+		m.certificates = append(m.certificates, cert)
+	}
+	return nil
+}
+
+type certificateVerifyMsg struct {
+	signatureScheme uint16
+	signature       []byte
+}
+
+func (m *certificateVerifyMsg) unmarshal(msg []byte) error {
+	p := &parser{b: msg[4:]}
+	m.signatureScheme = p.u16()
+	m.signature = p.vec16()
+	if !p.empty() {
+		return errDecode
+	}
+	return nil
+}
+
+type keyUpdateMsg struct {
+	updateRequested bool
+}
+
+func (m *keyUpdateMsg) marshal() []byte {
+	var b byte
+	if m.updateRequested {
+		b = 1
+	}
+	return handshakeMessage(typeKeyUpdate, []byte{b})
+}
+
+func (m *keyUpdateMsg) unmarshal(msg []byte) error {
+	if len(msg) != 5 || msg[4] > 1 {
+		return errDecode
+	}
+	m.updateRequested = msg[4] == 1
+	return nil
+}
@@ -0,0 +1,84 @@
+package tlslite
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"hash"
+)
+
+const (
+	masterSecretLength   = 48
+	finishedVerifyLength = 12
+)
+
+// cipherSuite12 describes a TLS 1.2 suite: its key exchange, AES key
+// length and the hash used by the PRF
+type cipherSuite12 struct {
+	id     uint16
+	ecdhe  bool
+	keyLen int
+	hash   crypto.Hash
+}
+
+var cipherSuites12 = []*cipherSuite12{
+	{TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, true, 16, crypto.SHA256},
+	{TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384, true, 32, crypto.SHA384},
+	{TLS_RSA_WITH_AES_128_GCM_SHA256, false, 16, crypto.SHA256},
+	{TLS_RSA_WITH_AES_256_GCM_SHA384, false, 32, crypto.SHA384},
+}
+
+func cipherSuite12ByID(id uint16) *cipherSuite12 {
+	for _, s := range cipherSuites12 {
+		if s.id == id {
+			return s
+		}
+	}
+	return nil
+}
+
+// prf12 is the TLS 1.2 pseudorandom function of RFC 5246 section 5,
+// P_hash(secret, label || seed) truncated to length bytes, where
+//
+//	A(0) = label || seed, A(i) = HMAC_hash(secret, A(i-1))
+//	P_hash = HMAC_hash(secret, A(1) || label || seed) ||
+//	         HMAC_hash(secret, A(2) || label || seed) || ...
+func prf12(h func() hash.Hash, secret []byte, label string, seed []byte, length int) []byte {
+	labelAndSeed := append([]byte(label), seed...)
+	mac := hmac.New(h, secret)
+	mac.Write(labelAndSeed)
+	a := mac.Sum(nil)
+	out := make([]byte, 0, length+mac.Size())
+	for len(out) < length {
+		mac.Reset()
+		mac.Write(a)
+		mac.Write(labelAndSeed)
+		out = mac.Sum(out)
+		mac.Reset()
+		mac.Write(a)
+		a = mac.Sum(a[:0])
+	}
+	return out[:length]
+}
+
+// masterSecret derives the 48 bytes master secret from the premaster
+// secret and the hello randoms
+func (s *cipherSuite12) masterSecret(preMasterSecret, clientRandom, serverRandom []byte) []byte {
+	seed := append(append([]byte{}, clientRandom...), serverRandom...)
+	return prf12(s.hash.New, preMasterSecret, "master secret", seed, masterSecretLength)
+}
+
+// keys expands the master secret into the write keys and the four bytes
+// implicit part of the GCM nonces of RFC 5288 section 3
+func (s *cipherSuite12) keys(masterSecret, clientRandom, serverRandom []byte) (clientKey, serverKey, clientIV, serverIV []byte) {
+	seed := append(append([]byte{}, serverRandom...), clientRandom...)
+	block := prf12(s.hash.New, masterSecret, "key expansion", seed, 2*s.keyLen+2*4)
+	clientKey, block = block[:s.keyLen], block[s.keyLen:]
+	serverKey, block = block[:s.keyLen], block[s.keyLen:]
+	clientIV, serverIV = block[:4], block[4:8]
+	return
+}
+
+// finishedHash computes the verify_data of a TLS 1.2 Finished message
+func (s *cipherSuite12) finishedHash(masterSecret []byte, label string, transcript hash.Hash) []byte {
+	return prf12(s.hash.New, masterSecret, label, transcript.Sum(nil), finishedVerifyLength)
+}
@@ -0,0 +1,128 @@
+package tlslite
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+)
+
+// recordCipher protects the payload of records once keys are established
+type recordCipher interface {
+	// seal protects payload of content type typ with sequence number seq,
+	// and returns the content type to put in the record header along
+	// with the record body
+	seal(seq uint64, typ uint8, payload []byte) (uint8, []byte)
+	// open removes the protection of a record whose header is hdr and
+	// returns its real content type and payload
+	open(seq uint64, hdr, body []byte) (uint8, []byte, error)
+}
+
+// halfConn is one direction of a connection: its keys and sequence number
+type halfConn struct {
+	cipher recordCipher
+	seq    uint64
+}
+
+// setCipher switches to new keys, which resets the sequence number
+func (hc *halfConn) setCipher(c recordCipher) {
+	hc.cipher = c
+	hc.seq = 0
+}
+
+// aead13 is the TLS 1.3 record protection of RFC 8446 section 5.2: the
+// nonce is the static iv XORed with the sequence number, the additional
+// data is the record header and the real content type is encrypted
+// after the payload
+type aead13 struct {
+	aead cipher.AEAD
+	iv   []byte
+}
+
+func newAEAD13(suite *cipherSuite13, secret []byte) (*aead13, error) {
+	key, iv := suite.trafficKey(secret)
+	aead, err := suite.aead(key)
+	if err != nil {
+		return nil, err
+	}
+	return &aead13{aead: aead, iv: iv}, nil
+}
+
+func (a *aead13) nonce(seq uint64) []byte {
+	nonce := make([]byte, len(a.iv))
+	copy(nonce, a.iv)
+	for i := 0; i < 8; i++ {
+		nonce[len(nonce)-1-i] ^= byte(seq >> (8 * i))
+	}
+	return nonce
+}
+
+func (a *aead13) seal(seq uint64, typ uint8, payload []byte) (uint8, []byte) {
+	inner := append(append([]byte{}, payload...), typ)
+	hdr := []byte{recordTypeApplicationData, 0x03, 0x03, 0, 0}
+	binary.BigEndian.PutUint16(hdr[3:], uint16(len(inner)+a.aead.Overhead()))
+	return recordTypeApplicationData, a.aead.Seal(nil, a.nonce(seq), inner, hdr)
+}
+
+func (a *aead13) open(seq uint64, hdr, body []byte) (uint8, []byte, error) {
+	if hdr[0] != recordTypeApplicationData {
+		return 0, nil, alertUnexpectedMessage
+	}
+	inner, err := a.aead.Open(nil, a.nonce(seq), body, hdr)
+	if err != nil {
+		return 0, nil, alertBadRecordMAC
+	}
+	// strip the zero padding, the content type is the last non zero byte
+	i := len(inner) - 1
+	for i >= 0 && inner[i] == 0 {
+		i--
+	}
+	if i < 0 {
+		return 0, nil, alertUnexpectedMessage
+	}
+	return inner[i], inner[:i], nil
+}
+
+// aead12 is the TLS 1.2 AES-GCM record protection of RFC 5288: the nonce
+// is a four bytes implicit salt followed by an explicit part sent with
+// each record, set to the sequence number, and the additional data is
+// the sequence number followed by the record header
+type aead12 struct {
+	aead cipher.AEAD
+	salt []byte
+}
+
+func newAEAD12(key, salt []byte) (*aead12, error) {
+	aead, err := aeadAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &aead12{aead: aead, salt: salt}, nil
+}
+
+func additionalData12(seq uint64, typ uint8, length int) []byte {
+	ad := make([]byte, 13)
+	binary.BigEndian.PutUint64(ad, seq)
+	ad[8] = typ
+	binary.BigEndian.PutUint16(ad[9:], VersionTLS12)
+	binary.BigEndian.PutUint16(ad[11:], uint16(length))
+	return ad
+}
+
+func (a *aead12) seal(seq uint64, typ uint8, payload []byte) (uint8, []byte) {
+	explicit := make([]byte, 8)
+	binary.BigEndian.PutUint64(explicit, seq)
+	nonce := append(append([]byte{}, a.salt...), explicit...)
+	return typ, a.aead.Seal(explicit, nonce, payload, additionalData12(seq, typ, len(payload)))
+}
+
+func (a *aead12) open(seq uint64, hdr, body []byte) (uint8, []byte, error) {
+	if len(body) < 8+a.aead.Overhead() {
+		return 0, nil, alertBadRecordMAC
+	}
+	nonce := append(append([]byte{}, a.salt...), body[:8]...)
+	ad := additionalData12(seq, hdr[0], len(body)-8-a.aead.Overhead())
+	payload, err := a.aead.Open(nil, nonce, body[8:], ad)
+	if err != nil {
+		return 0, nil, alertBadRecordMAC
+	}
+	return hdr[0], payload, nil
+}
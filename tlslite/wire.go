@@ -0,0 +1,83 @@
+package tlslite
+
+// appendU16 appends a big endian uint16 to b
+func appendU16(b []byte, v uint16) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
+
+// appendU24 appends a big endian 24 bits integer to b
+func appendU24(b []byte, v int) []byte {
+	return append(b, byte(v>>16), byte(v>>8), byte(v))
+}
+
+// appendVec8 appends data prefixed by its one byte length
+func appendVec8(b, data []byte) []byte {
+	return append(append(b, byte(len(data))), data...)
+}
+
+// appendVec16 appends data prefixed by its two bytes length
+func appendVec16(b, data []byte) []byte {
+	return append(appendU16(b, uint16(len(data))), data...)
+}
+
+// appendVec24 appends data prefixed by its three bytes length
+func appendVec24(b, data []byte) []byte {
+	return append(appendU24(b, len(data)), data...)
+}
+
+// handshakeMessage frames body with a handshake header of type typ
+func handshakeMessage(typ uint8, body []byte) []byte {
+	return append(appendU24([]byte{typ}, len(body)), body...)
+}
+
+// parser reads the fixed and variable length fields of TLS structures.
+// Reads past the end of the input set the bad flag and return zero
+// values, so callers only check for errors once at the end.
+type parser struct {
+	b   []byte
+	bad bool
+}
+
+func (p *parser) read(n int) []byte {
+	if p.bad || len(p.b) < n {
+		p.bad = true
+		p.b = nil
+		return nil
+	}
+	out := p.b[:n]
+	p.b = p.b[n:]
+	return out
+}
+
+func (p *parser) u8() uint8 {
+	b := p.read(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (p *parser) u16() uint16 {
+	b := p.read(2)
+	if b == nil {
+		return 0
+	}
+	return uint16(b[0])<<8 | uint16(b[1])
+}
+
+func (p *parser) u24() int {
+	b := p.read(3)
+	if b == nil {
+		return 0
+	}
+	return int(b[0])<<16 | int(b[1])<<8 | int(b[2])
+}
+
+func (p *parser) vec8() []byte  { return p.read(int(p.u8())) }
+func (p *parser) vec16() []byte { return p.read(int(p.u16())) }
+func (p *parser) vec24() []byte { return p.read(p.u24()) }
+
+// empty indicates that all the input was consumed without error
+func (p *parser) empty() bool {
+	return !p.bad && len(p.b) == 0
+}
@@ -0,0 +1,158 @@
+// Package vdf implements the verifiable delay function of Wesolowski in
+// an RSA group: evaluating it takes T sequential squarings, and comes with
+// a short proof that anyone can check with two small exponentiations.
+//
+// Evaluation computes y = x^(2^T) where x is the input hashed into the
+// group. The proof is pi = x^floor(2^T / l) for a 128 bits prime l derived
+// from x and y, and verification checks pi^l * x^(2^T mod l) = y, which
+// costs about 128 + 128 squarings whatever T is.
+//
+// The order of the group must be unknown to the evaluator, since knowing
+// it lets anyone shortcut the squarings as timelock puzzle creators do.
+// NewModulus generates a modulus and forgets its factors, which makes
+// whoever runs it a trusted party. Elements are taken modulo +-1 to remove
+// the known element of order two.
+package vdf
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/timelock"
+)
+
+// PrimeBits is the size of the Fiat-Shamir challenge prime l
+const PrimeBits = 128
+
+var one = big.NewInt(1)
+
+// VDF holds the public parameters of the function
+type VDF struct {
+	// N is an RSA modulus of unknown factorization
+	N *big.Int
+	// T is the number of sequential squarings
+	T uint64
+}
+
+// NewModulus returns the product of two random primes of bits/2 bits,
+// whose factors are immediately discarded
+func NewModulus(r io.Reader, bits int) (*big.Int, error) {
+	if bits < 512 {
+		return nil, errors.New("vdf: modulus must be at least 512 bits")
+	}
+	for {
+		p, err := rand.Prime(r, bits/2)
+		if err != nil {
+			return nil, err
+		}
+		q, err := rand.Prime(r, bits-bits/2)
+		if err != nil {
+			return nil, err
+		}
+		if p.Cmp(q) != 0 {
+			return new(big.Int).Mul(p, q), nil
+		}
+	}
+}
+
+// Eval computes the output y of the function on input, and the proof pi
+// that y is correct. It takes about 2*T squarings.
+func (v *VDF) Eval(input []byte) (y, pi *big.Int) {
+	x := v.hashToGroup(input)
+	y = v.normalize(timelock.SequentialSquare(x, v.N, v.T))
+	l := v.hashToPrime(x, y)
+
+	// long division of 2^T by l one bit at a time: pi accumulates
+	// x^floor(2^i / l) while r tracks 2^i mod l
+	pi = big.NewInt(1)
+	r := big.NewInt(1)
+	for i := uint64(0); i < v.T; i++ {
+		r.Lsh(r, 1)
+		pi.Mul(pi, pi)
+		if r.Cmp(l) >= 0 {
+			r.Sub(r, l)
+			pi.Mul(pi, x)
+		}
+		pi.Mod(pi, v.N)
+	}
+	return y, v.normalize(pi)
+}
+
+// Verify checks that y is the output of the function on input using the
+// proof pi
+func (v *VDF) Verify(input []byte, y, pi *big.Int) bool {
+	if !v.canonical(y) || !v.canonical(pi) {
+		return false
+	}
+	x := v.hashToGroup(input)
+	l := v.hashToPrime(x, y)
+	r := new(big.Int).Exp(big.NewInt(2), new(big.Int).SetUint64(v.T), l)
+	lhs := new(big.Int).Exp(pi, l, v.N)
+	lhs.Mul(lhs, new(big.Int).Exp(x, r, v.N))
+	lhs.Mod(lhs, v.N)
+	return v.normalize(lhs).Cmp(y) == 0
+}
+
+// normalize maps a to the representative of {a, -a} in [0, N/2]
+func (v *VDF) normalize(a *big.Int) *big.Int {
+	neg := new(big.Int).Sub(v.N, a)
+	if neg.Cmp(a) < 0 {
+		return neg
+	}
+	return a
+}
+
+// canonical reports if a is a normalized unit of the group
+func (v *VDF) canonical(a *big.Int) bool {
+	if a == nil || a.Sign() <= 0 || a.Cmp(v.N) >= 0 {
+		return false
+	}
+	if new(big.Int).GCD(nil, nil, a, v.N).Cmp(one) != 0 {
+		return false
+	}
+	return v.normalize(a).Cmp(a) == 0
+}
+
+// hashToGroup expands input with SHA-256 in counter mode to 128 bits more
+// than the modulus and reduces it
+func (v *VDF) hashToGroup(input []byte) *big.Int {
+	size := (v.N.BitLen()+7)/8 + 16
+	var buf []byte
+	for counter := uint32(0); len(buf) < size; counter++ {
+		h := sha256.New()
+		h.Write([]byte("badcrypto vdf input"))
+		var c [4]byte
+		binary.BigEndian.PutUint32(c[:], counter)
+		h.Write(c[:])
+		h.Write(v.N.Bytes())
+		h.Write(input)
+		buf = h.Sum(buf)
+	}
+	x := new(big.Int).SetBytes(buf[:size])
+	return v.normalize(x.Mod(x, v.N))
+}
+
+// hashToPrime derives the challenge prime from the statement
+func (v *VDF) hashToPrime(x, y *big.Int) *big.Int {
+	size := (v.N.BitLen() + 7) / 8
+	for counter := uint32(0); ; counter++ {
+		h := sha256.New()
+		h.Write([]byte("badcrypto vdf prime"))
+		var buf [12]byte
+		binary.BigEndian.PutUint32(buf[:4], counter)
+		binary.BigEndian.PutUint64(buf[4:], v.T)
+		h.Write(buf[:])
+		h.Write(x.FillBytes(make([]byte, size)))
+		h.Write(y.FillBytes(make([]byte, size)))
+		digest := h.Sum(nil)[:PrimeBits/8]
+		digest[0] |= 0x80
+		l := new(big.Int).SetBytes(digest)
+		if l.ProbablyPrime(20) {
+			return l
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package vdf
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/timelock"
+)
+
+func TestEvalVerify(t *testing.T) {
+	t.Parallel()
+	n, err := NewModulus(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var testcases = []struct {
+		input []byte
+		t     uint64
+	}{
+		{[]byte("block 1234"), 1},
+		{[]byte("block 1234"), 1000},
+		{[]byte(""), 3000},
+	}
+	for i, tc := range testcases {
+		v := &VDF{N: n, T: tc.t}
+		y, pi := v.Eval(tc.input)
+		if !v.Verify(tc.input, y, pi) {
+			t.Fatalf("testcase %d expected proof to verify", i)
+		}
+		// y is the plain sequential squaring, up to the sign
+		x := v.hashToGroup(tc.input)
+		if expected := v.normalize(timelock.SequentialSquare(x, n, tc.t)); y.Cmp(expected) != 0 {
+			t.Fatalf("testcase %d expected output %x but got %x", i, expected, y)
+		}
+		if v.Verify([]byte("another input"), y, pi) {
+			t.Fatalf("testcase %d expected proof to fail on another input", i)
+		}
+		bad := new(big.Int).Mul(y, big.NewInt(4))
+		if v.Verify(tc.input, v.normalize(bad.Mod(bad, n)), pi) {
+			t.Fatalf("testcase %d expected proof to fail on another output", i)
+		}
+		if v.Verify(tc.input, y, new(big.Int).Sub(n, pi)) {
+			t.Fatalf("testcase %d expected non canonical proof to fail", i)
+		}
+		longer := &VDF{N: n, T: tc.t + 1}
+		if longer.Verify(tc.input, y, pi) {
+			t.Fatalf("testcase %d expected proof to fail with another delay", i)
+		}
+	}
+}
+
+func TestSmallModulus(t *testing.T) {
+	t.Parallel()
+	if _, err := NewModulus(rand.Reader, 256); err == nil {
+		t.Fatal("expected a 256 bits modulus to be rejected")
+	}
+}
@@ -0,0 +1,64 @@
+package viz
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/jvehent/badcrypto/merkle"
+)
+
+// hashNode returns a node showing the first bytes of hash h
+func hashNode(op string, h []byte, children ...*Node) *Node {
+	n := NewNode(op, hex.EncodeToString(h), children...)
+	n.Detail = n.Value
+	if len(h) > 4 {
+		n.Value = hex.EncodeToString(h[:4]) + "..."
+	}
+	return n
+}
+
+// MerkleProof records the verification of the inclusion proof of the leaf
+// at index in a tree of size leaves, as done by
+// merkle.RootFromInclusionProof: each hash of the proof is combined with
+// the running hash on its left or its right, up to the root. The leaf and
+// the hashes computed by the verifier are highlighted, the other nodes are
+// the ones the proof provides.
+func MerkleProof(index, size uint64, leafHash []byte, proof [][]byte) (*Tree, error) {
+	root, err := merkle.RootFromInclusionProof(index, size, leafHash, proof)
+	if err != nil {
+		return nil, err
+	}
+	node := hashNode(fmt.Sprintf("leaf %d", index), leafHash)
+	node.Highlight = true
+	r := leafHash
+	fn, sn := index, size-1
+	for i, p := range proof {
+		sibling := hashNode(fmt.Sprintf("proof[%d]", i), p)
+		if fn&1 == 1 || fn == sn {
+			r = merkle.NodeHash(p, r)
+			node = hashNode("hash", r, sibling, node)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = merkle.NodeHash(r, p)
+			node = hashNode("hash", r, node, sibling)
+		}
+		node.Highlight = true
+		fn >>= 1
+		sn >>= 1
+	}
+	if !bytes.Equal(r, root) {
+		return nil, fmt.Errorf("viz: recorded root %x but merkle computes %x", r, root)
+	}
+	node.Op = "root"
+	return &Tree{
+		Title: fmt.Sprintf("inclusion of leaf %d in a tree of %d leaves", index, size),
+		Notes: []string{
+			fmt.Sprintf("The proof holds %d hashes. The verifier combines each with the hash it computed so far, on the left or on the right depending on the position of the leaf, and compares the result with the root of the signed tree head.", len(proof)),
+		},
+		Root: node,
+	}, nil
+}
@@ -0,0 +1,43 @@
+package viz
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/jvehent/badcrypto/merkle"
+)
+
+func TestMerkleProof(t *testing.T) {
+	t.Parallel()
+	tree := new(merkle.Tree)
+	for i := 0; i < 11; i++ {
+		tree.Append([]byte{byte(i)})
+	}
+	for index := uint64(0); index < tree.Size(); index++ {
+		proof, err := tree.InclusionProof(index, tree.Size())
+		if err != nil {
+			t.Fatal(err)
+		}
+		vt, err := MerkleProof(index, tree.Size(), tree.LeafHash(index), proof)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if vt.Root.Detail != hex.EncodeToString(tree.Root()) || vt.Root.Op != "root" {
+			t.Fatalf("leaf %d expected root %x but got %s %s", index, tree.Root(), vt.Root.Op, vt.Root.Detail)
+		}
+		// the leaf and one computed hash per proof hash, plus the proof
+		highlighted, nodes := 0, vt.Nodes()
+		for _, n := range nodes {
+			if n.Highlight {
+				highlighted++
+			}
+		}
+		if highlighted != len(proof)+1 || len(nodes) != 2*len(proof)+1 {
+			t.Fatalf("leaf %d expected %d highlighted nodes out of %d but got %d out of %d", index, len(proof)+1, 2*len(proof)+1, highlighted, len(nodes))
+		}
+	}
+	proof, _ := tree.InclusionProof(3, tree.Size())
+	if _, err := MerkleProof(3, tree.Size(), tree.LeafHash(3), proof[1:]); err == nil {
+		t.Fatal("expected a truncated proof to fail")
+	}
+}
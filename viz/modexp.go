@@ -0,0 +1,49 @@
+package viz
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ModExp computes base^exp mod m by left to right square and multiply and
+// records its steps: one squaring per bit of the exponent after the first,
+// followed by a multiplication by the base when the bit is set. The
+// multiplications are highlighted, their pattern spells the exponent,
+// which is what a power trace of a naive implementation shows.
+func ModExp(base, exp, m *big.Int) (*big.Int, *Tree, error) {
+	if m.Sign() <= 0 {
+		return nil, nil, errors.New("viz: modulus must be positive")
+	}
+	if exp.Sign() < 0 {
+		return nil, nil, errors.New("viz: exponent must not be negative")
+	}
+	b := new(big.Int).Mod(base, m)
+	t := &Tree{Title: fmt.Sprintf("%v^%v mod %v", base, exp, m)}
+	if exp.Sign() == 0 {
+		one := new(big.Int).Mod(big.NewInt(1), m)
+		t.Root = NewNode("one", one.String())
+		return one, t, nil
+	}
+	baseNode := NewNode("base", b.String())
+	acc := new(big.Int).Set(b)
+	node := baseNode
+	squares, mults := 0, 0
+	for i := exp.BitLen() - 2; i >= 0; i-- {
+		acc.Mul(acc, acc).Mod(acc, m)
+		node = NewNode(fmt.Sprintf("square (bit %d)", i), acc.String(), node)
+		squares++
+		if exp.Bit(i) == 1 {
+			acc.Mul(acc, b).Mod(acc, m)
+			node = NewNode(fmt.Sprintf("multiply (bit %d)", i), acc.String(), node, baseNode)
+			node.Highlight = true
+			mults++
+		}
+	}
+	t.Root = node
+	t.Notes = []string{
+		fmt.Sprintf("The exponent is %b in binary: %d squarings and %d multiplications.", exp, squares, mults),
+		"The highlighted multiplications happen only for the bits set to one, so their timing or power consumption reveals the exponent.",
+	}
+	return acc, t, nil
+}
@@ -0,0 +1,50 @@
+package viz
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestModExp(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		base, exp, m int64
+		// squarings and multiplications
+		squares, mults int
+	}{
+		{3, 0, 7, 0, 0},
+		{3, 1, 7, 0, 0},
+		{3, 5, 7, 2, 1},
+		{2, 0xff, 1000003, 7, 7},
+		{12345, 0x100, 65537, 8, 0},
+		{5, 13, 1, 3, 2},
+	}
+	for i, tc := range testcases {
+		r, tree, err := ModExp(big.NewInt(tc.base), big.NewInt(tc.exp), big.NewInt(tc.m))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := new(big.Int).Exp(big.NewInt(tc.base), big.NewInt(tc.exp), big.NewInt(tc.m))
+		if r.Cmp(expected) != 0 || tree.Root.Value != expected.String() {
+			t.Fatalf("testcase %d expected %v but got %v with root %s", i, expected, r, tree.Root.Value)
+		}
+		squares, mults := 0, 0
+		for _, n := range tree.Nodes() {
+			switch {
+			case n.Highlight:
+				mults++
+			case len(n.Children) == 1:
+				squares++
+			}
+		}
+		if squares != tc.squares || mults != tc.mults {
+			t.Fatalf("testcase %d expected %d squarings and %d multiplications but got %d and %d", i, tc.squares, tc.mults, squares, mults)
+		}
+	}
+	if _, _, err := ModExp(big.NewInt(2), big.NewInt(-1), big.NewInt(7)); err == nil {
+		t.Fatal("expected a negative exponent to fail")
+	}
+	if _, _, err := ModExp(big.NewInt(2), big.NewInt(3), big.NewInt(0)); err == nil {
+		t.Fatal("expected a zero modulus to fail")
+	}
+}
@@ -0,0 +1,174 @@
+// Package viz records the operation tree of a computation and renders it
+// as a Graphviz DOT graph or as a standalone HTML page, for slides and
+// course notes.
+//
+// A Node is one operation with the nodes it consumed as children. An
+// operand can feed several operations, the base of a modular
+// exponentiation is multiplied in at every set bit of the exponent, so the
+// tree is really a directed acyclic graph: DOT draws shared nodes once
+// with several edges, HTML expands them at their first use and shows a
+// reference afterwards. Nodes can be highlighted to mark what a reader
+// should follow, such as the nodes computed along a Merkle audit path.
+//
+// Graphs are rendered with dot, as in
+//
+//	dot -Tsvg modexp.dot > modexp.svg
+package viz
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// Node is an operation and its result
+type Node struct {
+	// Op names the operation, such as "square" or "hash"
+	Op string
+	// Value is the short form of the result shown in the graph, and
+	// Detail its full form, shown when hovering the node
+	Value, Detail string
+	Highlight     bool
+	// Children are the operands, in order
+	Children []*Node
+}
+
+// NewNode returns the node of operation op with the given operands
+func NewNode(op, value string, children ...*Node) *Node {
+	return &Node{Op: op, Value: value, Children: children}
+}
+
+// Tree is a recorded computation whose result is Root
+type Tree struct {
+	Title string
+	// Notes are paragraphs explaining the graph, written under it
+	Notes []string
+	Root  *Node
+}
+
+// walk calls fn once on every node reachable from n, parents first
+func walk(n *Node, seen map[*Node]bool, fn func(*Node)) {
+	if seen[n] {
+		return
+	}
+	seen[n] = true
+	fn(n)
+	for _, c := range n.Children {
+		walk(c, seen, fn)
+	}
+}
+
+// Nodes returns the distinct nodes of the tree, parents first
+func (t *Tree) Nodes() []*Node {
+	var nodes []*Node
+	if t.Root != nil {
+		walk(t.Root, make(map[*Node]bool), func(n *Node) { nodes = append(nodes, n) })
+	}
+	return nodes
+}
+
+// dotQuote returns s as a DOT string, where \n is a line break
+func dotQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+	return `"` + s + `"`
+}
+
+// label returns the text of a node, the operation over its value
+func (n *Node) label() string {
+	if n.Value == "" {
+		return n.Op
+	}
+	return n.Op + "\n" + n.Value
+}
+
+// DOT writes the tree as a Graphviz digraph. Edges go from operands to
+// results, with the root at the top.
+func (t *Tree) DOT(w io.Writer) error {
+	var b strings.Builder
+	nodes := t.Nodes()
+	ids := make(map[*Node]int, len(nodes))
+	for i, n := range nodes {
+		ids[n] = i
+	}
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(t.Title))
+	fmt.Fprintf(&b, "\tlabel=%s;\n\tlabelloc=t;\n\trankdir=BT;\n", dotQuote(t.Title))
+	b.WriteString("\tnode [shape=box, fontname=\"monospace\"];\n")
+	for i, n := range nodes {
+		fmt.Fprintf(&b, "\tn%d [label=%s", i, dotQuote(n.label()))
+		if n.Detail != "" {
+			fmt.Fprintf(&b, ", tooltip=%s", dotQuote(n.Detail))
+		}
+		if n.Highlight {
+			b.WriteString(", style=filled, fillcolor=\"#ffd966\"")
+		}
+		b.WriteString("];\n")
+	}
+	for i, n := range nodes {
+		for _, c := range n.Children {
+			fmt.Fprintf(&b, "\tn%d -> n%d;\n", ids[c], i)
+		}
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// htmlNode is a node as laid out in the page, Ref marks a node already
+// expanded above
+type htmlNode struct {
+	*Node
+	Ref      bool
+	Children []*htmlNode
+}
+
+func layout(n *Node, seen map[*Node]bool) *htmlNode {
+	h := &htmlNode{Node: n, Ref: seen[n]}
+	if h.Ref {
+		return h
+	}
+	seen[n] = true
+	for _, c := range n.Children {
+		h.Children = append(h.Children, layout(c, seen))
+	}
+	return h
+}
+
+var page = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+ul.tree, ul.tree ul { list-style: none; padding-left: 1.5em; border-left: 1px solid #999; }
+ul.tree { border-left: none; padding-left: 0; }
+.node { display: inline-block; margin: 0.2em 0; padding: 0.2em 0.5em; border: 1px solid #666; font-family: monospace; }
+.op { font-weight: bold; }
+.highlight { background: #ffd966; }
+.ref { border-style: dashed; color: #666; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{with .Root}}<ul class="tree">{{template "node" .}}</ul>{{end}}
+{{range .Notes}}<p>{{.}}</p>
+{{end}}</body>
+</html>
+{{define "node"}}<li><span class="node{{if .Highlight}} highlight{{end}}{{if .Ref}} ref{{end}}"{{with .Detail}} title="{{.}}"{{end}}><span class="op">{{.Op}}</span>{{with .Value}} {{.}}{{end}}{{if .Ref}} (above){{end}}</span>
+{{- if .Children}}<ul>{{range .Children}}{{template "node" .}}{{end}}</ul>{{end}}</li>
+{{end}}`))
+
+// HTML writes the tree as a standalone page, with the root at the top and
+// the operands of each node nested under it
+func (t *Tree) HTML(w io.Writer) error {
+	data := struct {
+		Title string
+		Notes []string
+		Root  *htmlNode
+	}{Title: t.Title, Notes: t.Notes}
+	if t.Root != nil {
+		data.Root = layout(t.Root, make(map[*Node]bool))
+	}
+	return page.Execute(w, data)
+}
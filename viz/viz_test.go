@@ -0,0 +1,84 @@
+package viz
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// x*(x+1) with x shared by both operations
+func sharedTree() *Tree {
+	x := NewNode("x", "3")
+	sum := NewNode("add", "4", x, NewNode("one", "1"))
+	root := NewNode("multiply", "12", x, sum)
+	root.Highlight = true
+	root.Detail = `"twelve"`
+	return &Tree{Title: "x(x+1)", Notes: []string{"x < y"}, Root: root}
+}
+
+func TestDOT(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	if err := sharedTree().DOT(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := `digraph "x(x+1)" {
+	label="x(x+1)";
+	labelloc=t;
+	rankdir=BT;
+	node [shape=box, fontname="monospace"];
+	n0 [label="multiply\n12", tooltip="\"twelve\"", style=filled, fillcolor="#ffd966"];
+	n1 [label="x\n3"];
+	n2 [label="add\n4"];
+	n3 [label="one\n1"];
+	n1 -> n0;
+	n2 -> n0;
+	n1 -> n2;
+	n3 -> n2;
+}
+`
+	if buf.String() != expected {
+		t.Fatalf("expected\n%s\nbut got\n%s", expected, buf.String())
+	}
+}
+
+func TestHTML(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	if err := sharedTree().HTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+	var testcases = []struct {
+		want  string
+		count int
+	}{
+		{"<title>x(x&#43;1)</title>", 1},
+		{`<span class="node highlight" title="&#34;twelve&#34;"><span class="op">multiply</span> 12</span>`, 1},
+		// x is expanded once, then referenced from the addition
+		{`<span class="op">x</span> 3</span>`, 1},
+		{`<span class="node ref"><span class="op">x</span> 3 (above)</span>`, 1},
+		{"<p>x &lt; y</p>", 1},
+		{"<li>", 5},
+	}
+	for i, tc := range testcases {
+		if n := strings.Count(page, tc.want); n != tc.count {
+			t.Fatalf("testcase %d expected %q %d times but got %d in\n%s", i, tc.want, tc.count, n, page)
+		}
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	t.Parallel()
+	tree := &Tree{Title: "empty"}
+	var buf bytes.Buffer
+	if err := tree.DOT(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "n0") {
+		t.Fatalf("expected no node but got %s", buf.String())
+	}
+	if err := tree.HTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+}
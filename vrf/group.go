@@ -0,0 +1,171 @@
+package vrf
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/group"
+)
+
+// Suite is the ECVRF construction over a prime order group of the group
+// package. It hashes the public key and the input to an element H, sets
+// Gamma = x*H, and proves that Gamma and the public key share their
+// discrete logarithm. Group.HashToGroup and Group.HashToScalar stand in
+// for the encode_to_curve, nonce and challenge of RFC 9381, so the outputs
+// are not those of any suite of the RFC, even over P-256.
+type Suite struct {
+	group   group.Group
+	context []byte
+}
+
+// NewSuite returns the VRF over g
+func NewSuite(g group.Group) *Suite {
+	return &Suite{group: g, context: []byte("badcrypto ECVRF-" + g.Name())}
+}
+
+// PrivateKey is a VRF key of a Suite
+type PrivateKey struct {
+	suite *Suite
+	x     *big.Int
+	// Public is the serialized public key x*G
+	Public []byte
+}
+
+func (s *Suite) newPrivateKey(x *big.Int) *PrivateKey {
+	g := s.group
+	return &PrivateKey{suite: s, x: x, Public: g.Marshal(g.ScalarBaseMult(x))}
+}
+
+// GenerateKey returns a random key
+func (s *Suite) GenerateKey(rand io.Reader) (*PrivateKey, error) {
+	x, err := s.group.RandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	return s.newPrivateKey(x), nil
+}
+
+// NewPrivateKey decodes a key serialized by Bytes
+func (s *Suite) NewPrivateKey(b []byte) (*PrivateKey, error) {
+	x, err := s.group.UnmarshalScalar(b)
+	if err != nil {
+		return nil, err
+	}
+	if x.Sign() == 0 {
+		return nil, errors.New("vrf: private key is zero")
+	}
+	return s.newPrivateKey(x), nil
+}
+
+// Bytes returns the serialized secret scalar
+func (k *PrivateKey) Bytes() []byte {
+	return k.suite.group.MarshalScalar(k.x)
+}
+
+// ProofSize is the size of an encoded proof: Gamma, c and s
+func (s *Suite) ProofSize() int {
+	return s.group.ElementLen() + 2*s.group.ScalarLen()
+}
+
+func (s *Suite) dst(label string) []byte {
+	return append([]byte(label+"-"), s.context...)
+}
+
+// Prove returns the proof pi for alpha
+func (k *PrivateKey) Prove(alpha []byte) ([]byte, error) {
+	s := k.suite
+	g := s.group
+	h, err := g.HashToGroup(append(append([]byte{}, k.Public...), alpha...), s.dst("EncodeToGroup"))
+	if err != nil {
+		return nil, err
+	}
+	gamma := g.ScalarMult(h, k.x)
+	// a deterministic nonce, as in RFC 9381, from the key and H
+	nonce, err := g.HashToScalar(append(k.Bytes(), g.Marshal(h)...), s.dst("Nonce"))
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.challenge(k.Public, h, gamma, g.ScalarBaseMult(nonce), g.ScalarMult(h, nonce))
+	if err != nil {
+		return nil, err
+	}
+	sc := new(big.Int).Mul(c, k.x)
+	sc.Add(sc, nonce)
+	sc.Mod(sc, g.Order())
+	pi := append(g.Marshal(gamma), g.MarshalScalar(c)...)
+	return append(pi, g.MarshalScalar(sc)...), nil
+}
+
+// ProofToHash returns the VRF output of a proof. It does not verify the
+// proof, Verify does and returns the same output.
+func (s *Suite) ProofToHash(pi []byte) ([]byte, error) {
+	gamma, _, _, err := s.decodeProof(pi)
+	if err != nil {
+		return nil, err
+	}
+	return s.proofToHash(gamma), nil
+}
+
+func (s *Suite) proofToHash(gamma group.Element) []byte {
+	h := s.group.Hash()()
+	h.Write(s.dst("Output"))
+	h.Write(s.group.Marshal(gamma))
+	return h.Sum(nil)
+}
+
+// Verify checks pi for alpha under the public key, and returns the VRF
+// output when it is valid
+func (s *Suite) Verify(pub, alpha, pi []byte) ([]byte, error) {
+	g := s.group
+	y, err := g.Unmarshal(pub)
+	if err != nil {
+		return nil, err
+	}
+	gamma, c, sc, err := s.decodeProof(pi)
+	if err != nil {
+		return nil, err
+	}
+	h, err := g.HashToGroup(append(append([]byte{}, pub...), alpha...), s.dst("EncodeToGroup"))
+	if err != nil {
+		return nil, err
+	}
+	// U = s*G - c*Y and V = s*H - c*Gamma
+	u := g.Add(g.ScalarBaseMult(sc), g.Neg(g.ScalarMult(y, c)))
+	v := g.Add(g.ScalarMult(h, sc), g.Neg(g.ScalarMult(gamma, c)))
+	expected, err := s.challenge(pub, h, gamma, u, v)
+	if err != nil {
+		return nil, err
+	}
+	if expected.Cmp(c) != 0 {
+		return nil, errors.New("vrf: invalid proof")
+	}
+	return s.proofToHash(gamma), nil
+}
+
+func (s *Suite) decodeProof(pi []byte) (gamma group.Element, c, sc *big.Int, err error) {
+	g := s.group
+	if len(pi) != s.ProofSize() {
+		return nil, nil, nil, errors.New("vrf: invalid proof size")
+	}
+	n := g.ElementLen()
+	if gamma, err = g.Unmarshal(pi[:n]); err != nil {
+		return nil, nil, nil, err
+	}
+	if c, err = g.UnmarshalScalar(pi[n : n+g.ScalarLen()]); err != nil {
+		return nil, nil, nil, err
+	}
+	if sc, err = g.UnmarshalScalar(pi[n+g.ScalarLen():]); err != nil {
+		return nil, nil, nil, err
+	}
+	return gamma, c, sc, nil
+}
+
+// challenge hashes the public key and the points of the proof to a scalar
+func (s *Suite) challenge(pub []byte, points ...group.Element) (*big.Int, error) {
+	msg := append([]byte{}, pub...)
+	for _, p := range points {
+		msg = append(msg, s.group.Marshal(p)...)
+	}
+	return s.group.HashToScalar(msg, s.dst("Challenge"))
+}
@@ -0,0 +1,60 @@
+package vrf
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/jvehent/badcrypto/group"
+)
+
+func TestSuite(t *testing.T) {
+	t.Parallel()
+	for _, g := range []group.Group{group.P256SHA256, group.Ristretto255SHA512} {
+		s := NewSuite(g)
+		priv, err := s.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		alpha := []byte("round 42")
+		pi, err := priv.Prove(alpha)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pi) != s.ProofSize() {
+			t.Fatalf("%s: expected a proof of %d bytes but got %d", g.Name(), s.ProofSize(), len(pi))
+		}
+		beta, err := s.Verify(priv.Public, alpha, pi)
+		if err != nil {
+			t.Fatal(err)
+		}
+		unverified, _ := s.ProofToHash(pi)
+		if !bytes.Equal(beta, unverified) || len(beta) != g.Hash()().Size() {
+			t.Fatalf("%s: expected Verify and ProofToHash to return the same output", g.Name())
+		}
+		loaded, err := s.NewPrivateKey(priv.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pi2, _ := loaded.Prove(alpha); !bytes.Equal(pi, pi2) {
+			t.Fatalf("%s: expected proofs to be deterministic", g.Name())
+		}
+		if _, err := s.Verify(priv.Public, []byte("round 43"), pi); err == nil {
+			t.Fatalf("%s: expected proof for another input to fail", g.Name())
+		}
+		other, _ := s.GenerateKey(rand.Reader)
+		if _, err := s.Verify(other.Public, alpha, pi); err == nil {
+			t.Fatalf("%s: expected proof under another key to fail", g.Name())
+		}
+		for _, i := range []int{0, g.ElementLen(), len(pi) - 1} {
+			tampered := append([]byte{}, pi...)
+			tampered[i] ^= 1
+			if _, err := s.Verify(priv.Public, alpha, tampered); err == nil {
+				t.Fatalf("%s: expected proof tampered at byte %d to fail", g.Name(), i)
+			}
+		}
+		if _, err := s.Verify(g.Marshal(g.Identity()), alpha, pi); err == nil {
+			t.Fatalf("%s: expected the identity as public key to be rejected", g.Name())
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Package vrf implements the ECVRF-EDWARDS25519-SHA512-TAI verifiable
+// random function of RFC 9381. The holder of a secret key computes, for
+// any input, a pseudorandom output and a proof that anyone with the public
+// key can check, so the output can be neither predicted nor forged, which
+// is what lotteries and leader elections need.
+//
+// Prove and Verify take Ed25519 keys. Suite runs the same construction
+// over any prime order group of the group package, such as ristretto255,
+// where no cofactor needs clearing.
+package vrf
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"errors"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/edwards25519"
+)
+
+const (
+	suiteString = 0x03
+	// ProofSize is the size of an encoded proof: Gamma, c and s
+	ProofSize = 32 + challengeLen + 32
+	// OutputSize is the size of the VRF output beta
+	OutputSize = sha512.Size
+
+	challengeLen = 16
+)
+
+// Prove returns the proof pi for alpha under the secret key
+func Prove(priv ed25519.PrivateKey, alpha []byte) ([]byte, error) {
+	if len(priv) != ed25519.PrivateKeySize {
+		return nil, errors.New("vrf: invalid private key size")
+	}
+	hashed := sha512.Sum512(priv.Seed())
+	x := edwards25519.ClampScalar(hashed[:32])
+	pk := edwards25519.ScalarBaseMult(x).Bytes()
+
+	h, err := encodeToCurve(pk, alpha)
+	if err != nil {
+		return nil, err
+	}
+	hString := h.Bytes()
+	gamma := h.ScalarMult(x)
+
+	// nonce generation as in RFC 8032, section 5.4.2.2 of RFC 9381
+	kh := sha512.New()
+	kh.Write(hashed[32:])
+	kh.Write(hString)
+	k := edwards25519.ScalarFromBytes(kh.Sum(nil))
+	k.Mod(k, edwards25519.Order)
+
+	c := challenge(pk, hString, gamma, edwards25519.ScalarBaseMult(k), h.ScalarMult(k))
+	s := new(big.Int).Mul(c, x)
+	s.Add(s, k)
+	s.Mod(s, edwards25519.Order)
+
+	pi := append(gamma.Bytes(), edwards25519.ScalarBytes(c)[:challengeLen]...)
+	return append(pi, edwards25519.ScalarBytes(s)...), nil
+}
+
+// ProofToHash returns the VRF output beta of a proof. It does not verify
+// the proof, Verify does and returns the same output.
+func ProofToHash(pi []byte) ([]byte, error) {
+	gamma, _, _, err := decodeProof(pi)
+	if err != nil {
+		return nil, err
+	}
+	return proofToHash(gamma), nil
+}
+
+func proofToHash(gamma *edwards25519.Point) []byte {
+	h := sha512.New()
+	h.Write([]byte{suiteString, 0x03})
+	h.Write(gamma.MulByCofactor().Bytes())
+	h.Write([]byte{0x00})
+	return h.Sum(nil)
+}
+
+// Verify checks pi for alpha under the public key, and returns the VRF
+// output when it is valid
+func Verify(pub ed25519.PublicKey, alpha, pi []byte) ([]byte, error) {
+	y, err := edwards25519.NewPoint(pub)
+	if err != nil {
+		return nil, err
+	}
+	// a small order key would let a malicious holder pick many
+	// outputs for one input
+	if y.IsSmallOrder() {
+		return nil, errors.New("vrf: public key has small order")
+	}
+	gamma, c, s, err := decodeProof(pi)
+	if err != nil {
+		return nil, err
+	}
+	h, err := encodeToCurve(pub, alpha)
+	if err != nil {
+		return nil, err
+	}
+	// U = s*B - c*Y and V = s*H - c*Gamma
+	u := edwards25519.ScalarBaseMult(s).Sub(y.ScalarMult(c))
+	v := h.ScalarMult(s).Sub(gamma.ScalarMult(c))
+	if challenge(pub, h.Bytes(), gamma, u, v).Cmp(c) != 0 {
+		return nil, errors.New("vrf: invalid proof")
+	}
+	return proofToHash(gamma), nil
+}
+
+func decodeProof(pi []byte) (gamma *edwards25519.Point, c, s *big.Int, err error) {
+	if len(pi) != ProofSize {
+		return nil, nil, nil, errors.New("vrf: invalid proof size")
+	}
+	gamma, err = edwards25519.NewPoint(pi[:32])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	c = edwards25519.ScalarFromBytes(pi[32 : 32+challengeLen])
+	s = edwards25519.ScalarFromBytes(pi[32+challengeLen:])
+	if s.Cmp(edwards25519.Order) >= 0 {
+		return nil, nil, nil, errors.New("vrf: proof scalar is not reduced")
+	}
+	return gamma, c, s, nil
+}
+
+// encodeToCurve is the try and increment method of section 5.4.1.1:
+// hash the public key, alpha and a counter until the digest decodes to a
+// point, then clear the cofactor
+func encodeToCurve(pk, alpha []byte) (*edwards25519.Point, error) {
+	for ctr := 0; ctr < 256; ctr++ {
+		h := sha512.New()
+		h.Write([]byte{suiteString, 0x01})
+		h.Write(pk)
+		h.Write(alpha)
+		h.Write([]byte{byte(ctr), 0x00})
+		p, err := edwards25519.NewPoint(h.Sum(nil)[:32])
+		if err != nil {
+			continue
+		}
+		return p.MulByCofactor(), nil
+	}
+	return nil, errors.New("vrf: failed to hash to the curve")
+}
+
+// challenge hashes the points of the proof to a 128 bits scalar
+func challenge(pk, hString []byte, points ...*edwards25519.Point) *big.Int {
+	h := sha512.New()
+	h.Write([]byte{suiteString, 0x02})
+	h.Write(pk)
+	h.Write(hString)
+	for _, p := range points {
+		h.Write(p.Bytes())
+	}
+	h.Write([]byte{0x00})
+	return edwards25519.ScalarFromBytes(h.Sum(nil)[:challengeLen])
+}
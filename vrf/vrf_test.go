@@ -0,0 +1,87 @@
+package vrf
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+)
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// RFC 9381 appendix B.3, example 16
+func TestVector(t *testing.T) {
+	t.Parallel()
+	priv := ed25519.NewKeyFromSeed(unhex("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"))
+	pub := priv.Public().(ed25519.PublicKey)
+	if !bytes.Equal(pub, unhex("d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a")) {
+		t.Fatalf("unexpected public key %x", pub)
+	}
+	pi, err := Prove(priv, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := unhex("8657106690b5526245a92b003bb079ccd1a92130477671f6fc01ad16f26f723f26f8a57ccaed74ee1b190bed1f479d9727d2d0f9b005a6e456a35d4fb0daab1268a1b0db10836d9826a528ca76567805")
+	if !bytes.Equal(pi, expected) {
+		t.Fatalf("expected proof %x but got %x", expected, pi)
+	}
+	beta, err := Verify(pub, nil, pi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = unhex("90cf1df3b703cce59e2a35b925d411164068269d7b2d29f3301c03dd757876ff66b71dda49d2de59d03450451af026798e8f81cd2e333de5cdf4f3e140fdd8ae")
+	if !bytes.Equal(beta, expected) {
+		t.Fatalf("expected output %x but got %x", expected, beta)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alpha := []byte("round 42")
+	pi, err := Prove(priv, alpha)
+	if err != nil {
+		t.Fatal(err)
+	}
+	beta, err := Verify(pub, alpha, pi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unverified, _ := ProofToHash(pi)
+	if !bytes.Equal(beta, unverified) || len(beta) != OutputSize {
+		t.Fatal("expected Verify and ProofToHash to return the same output")
+	}
+	pi2, _ := Prove(priv, alpha)
+	if !bytes.Equal(pi, pi2) {
+		t.Fatal("expected proofs to be deterministic")
+	}
+	if _, err := Verify(pub, []byte("round 43"), pi); err == nil {
+		t.Fatal("expected proof for another input to fail")
+	}
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	if _, err := Verify(otherPub, alpha, pi); err == nil {
+		t.Fatal("expected proof under another key to fail")
+	}
+	for _, i := range []int{0, 40, 70} {
+		tampered := append([]byte{}, pi...)
+		tampered[i] ^= 1
+		if _, err := Verify(pub, alpha, tampered); err == nil {
+			t.Fatalf("expected proof tampered at byte %d to fail", i)
+		}
+	}
+	identity := make([]byte, 32)
+	identity[0] = 1
+	if _, err := Verify(identity, alpha, pi); err == nil {
+		t.Fatal("expected a small order public key to be rejected")
+	}
+}
@@ -0,0 +1,224 @@
+// Package vss implements verifiable secret sharing: Shamir sharing over
+// the scalar field of an elliptic curve, where the dealer also publishes
+// commitments to the coefficients of the polynomial so that every holder
+// can check their share against them.
+//
+// Feldman commitments C_j = a_j*G are perfectly binding but reveal
+// secret*G. Pedersen commitments C_j = a_j*G + b_j*H hide the secret
+// perfectly, at the cost of a second blinding polynomial.
+package vss
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/commit"
+	"github.com/jvehent/badcrypto/ec"
+)
+
+// Share is the evaluation of the sharing polynomial at Index. Blinding is
+// the evaluation of the blinding polynomial, and only set by Pedersen.
+type Share struct {
+	Index    uint32
+	Value    *big.Int
+	Blinding *big.Int
+}
+
+// Commitment holds one point per coefficient of the sharing polynomial
+type Commitment []*ec.Point
+
+// Polynomial has coefficients in the scalar field of a curve, constant
+// term first
+type Polynomial []*big.Int
+
+// NewPolynomial returns a random polynomial of degree k-1 whose constant
+// term is secret
+func NewPolynomial(rand io.Reader, c *ec.Curve, secret *big.Int, k int) (Polynomial, error) {
+	if k < 1 {
+		return nil, errors.New("vss: threshold must be at least 1")
+	}
+	p := make(Polynomial, k)
+	p[0] = new(big.Int).Mod(secret, c.N)
+	for i := 1; i < k; i++ {
+		a, err := c.RandomScalar(rand)
+		if err != nil {
+			return nil, err
+		}
+		p[i] = a
+	}
+	return p, nil
+}
+
+// Evaluate returns p(x) modulo n
+func (p Polynomial) Evaluate(x uint32, n *big.Int) *big.Int {
+	bx := big.NewInt(int64(x))
+	y := new(big.Int)
+	for i := len(p) - 1; i >= 0; i-- {
+		y.Mul(y, bx)
+		y.Add(y, p[i])
+		y.Mod(y, n)
+	}
+	return y
+}
+
+// Commit returns the Feldman commitment to the coefficients of p
+func (p Polynomial) Commit(c *ec.Curve) Commitment {
+	comm := make(Commitment, len(p))
+	for i, a := range p {
+		comm[i] = c.ScalarBaseMult(a)
+	}
+	return comm
+}
+
+// H is the second generator of Pedersen commitments, the same as the
+// commit package uses
+func H(c *ec.Curve) *ec.Point {
+	return commit.NewPedersen(c).H
+}
+
+func checkParams(c *ec.Curve, n, k int) error {
+	if k < 1 || n < k {
+		return fmt.Errorf("vss: invalid threshold %d of %d", k, n)
+	}
+	if big.NewInt(int64(n)).Cmp(c.N) >= 0 || int64(n) > 1<<31 {
+		return errors.New("vss: too many shares")
+	}
+	return nil
+}
+
+// Feldman splits secret into n shares, any k of which recover it, and
+// returns the commitments to the sharing polynomial. The first commitment
+// is secret*G.
+func Feldman(rand io.Reader, c *ec.Curve, secret *big.Int, n, k int) ([]Share, Commitment, error) {
+	if err := checkParams(c, n, k); err != nil {
+		return nil, nil, err
+	}
+	poly, err := NewPolynomial(rand, c, secret, k)
+	if err != nil {
+		return nil, nil, err
+	}
+	shares := make([]Share, n)
+	for i := range shares {
+		x := uint32(i + 1)
+		shares[i] = Share{Index: x, Value: poly.Evaluate(x, c.N)}
+	}
+	return shares, poly.Commit(c), nil
+}
+
+// Pedersen splits secret like Feldman, with commitments that hide it
+func Pedersen(rand io.Reader, c *ec.Curve, secret *big.Int, n, k int) ([]Share, Commitment, error) {
+	if err := checkParams(c, n, k); err != nil {
+		return nil, nil, err
+	}
+	poly, err := NewPolynomial(rand, c, secret, k)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := c.RandomScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	blinding, err := NewPolynomial(rand, c, r, k)
+	if err != nil {
+		return nil, nil, err
+	}
+	h := H(c)
+	comm := make(Commitment, k)
+	for j := range comm {
+		comm[j] = c.Add(c.ScalarBaseMult(poly[j]), c.ScalarMult(h, blinding[j]))
+	}
+	shares := make([]Share, n)
+	for i := range shares {
+		x := uint32(i + 1)
+		shares[i] = Share{Index: x, Value: poly.Evaluate(x, c.N), Blinding: blinding.Evaluate(x, c.N)}
+	}
+	return shares, comm, nil
+}
+
+// evaluate computes the sum of index^j * C_j, the commitment to the share
+// at index
+func (comm Commitment) evaluate(c *ec.Curve, index uint32) *ec.Point {
+	x := big.NewInt(int64(index))
+	xj := big.NewInt(1)
+	scalars := make([]*big.Int, len(comm))
+	for j := range comm {
+		scalars[j] = new(big.Int).Set(xj)
+		xj.Mul(xj, x)
+		xj.Mod(xj, c.N)
+	}
+	return c.MultiScalarMult(scalars, comm)
+}
+
+// VerifyFeldman checks that share lies on the polynomial committed to
+func VerifyFeldman(c *ec.Curve, comm Commitment, share Share) bool {
+	if share.Index == 0 || share.Value == nil || len(comm) == 0 {
+		return false
+	}
+	return c.ScalarBaseMult(share.Value).Equal(comm.evaluate(c, share.Index))
+}
+
+// VerifyPedersen checks that share and its blinding lie on the
+// polynomials committed to
+func VerifyPedersen(c *ec.Curve, comm Commitment, share Share) bool {
+	if share.Index == 0 || share.Value == nil || share.Blinding == nil || len(comm) == 0 {
+		return false
+	}
+	lhs := c.Add(c.ScalarBaseMult(share.Value), c.ScalarMult(H(c), share.Blinding))
+	return lhs.Equal(comm.evaluate(c, share.Index))
+}
+
+// Lagrange returns the coefficient of the share at index when
+// interpolating the shares at indices at zero
+func Lagrange(c *ec.Curve, index uint32, indices []uint32) (*big.Int, error) {
+	num, den := big.NewInt(1), big.NewInt(1)
+	xi := big.NewInt(int64(index))
+	found := false
+	seen := make(map[uint32]bool)
+	for _, j := range indices {
+		if seen[j] {
+			return nil, fmt.Errorf("vss: duplicate share index %d", j)
+		}
+		seen[j] = true
+		if j == index {
+			found = true
+			continue
+		}
+		xj := big.NewInt(int64(j))
+		num.Mul(num, xj)
+		num.Mod(num, c.N)
+		den.Mul(den, new(big.Int).Sub(xj, xi))
+		den.Mod(den, c.N)
+	}
+	if !found {
+		return nil, fmt.Errorf("vss: index %d is not among the shares", index)
+	}
+	if den.ModInverse(den, c.N) == nil {
+		return nil, errors.New("vss: share indices collide modulo the group order")
+	}
+	return num.Mul(num, den).Mod(num, c.N), nil
+}
+
+// Recover interpolates the shares at zero and returns the secret
+func Recover(c *ec.Curve, shares []Share) (*big.Int, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("vss: no shares")
+	}
+	indices := make([]uint32, len(shares))
+	for i, s := range shares {
+		if s.Index == 0 {
+			return nil, errors.New("vss: share has a zero index")
+		}
+		indices[i] = s.Index
+	}
+	secret := new(big.Int)
+	for _, s := range shares {
+		l, err := Lagrange(c, s.Index, indices)
+		if err != nil {
+			return nil, err
+		}
+		secret.Add(secret, l.Mul(l, s.Value))
+	}
+	return secret.Mod(secret, c.N), nil
+}
@@ -0,0 +1,82 @@
+package vss
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+func TestFeldman(t *testing.T) {
+	t.Parallel()
+	c := ec.P256()
+	secret, _ := c.RandomScalar(rand.Reader)
+	shares, comm, err := Feldman(rand.Reader, c, secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !comm[0].Equal(c.ScalarBaseMult(secret)) {
+		t.Fatal("expected the first commitment to be secret*G")
+	}
+	for i, s := range shares {
+		if !VerifyFeldman(c, comm, s) {
+			t.Fatalf("share %d expected to verify", i)
+		}
+	}
+	got, err := Recover(c, []Share{shares[4], shares[0], shares[2]})
+	if err != nil || got.Cmp(secret) != 0 {
+		t.Fatalf("expected secret %x but got %x, %v", secret, got, err)
+	}
+	got, _ = Recover(c, shares[:2])
+	if got.Cmp(secret) == 0 {
+		t.Fatal("expected two shares to not recover the secret")
+	}
+	bad := Share{Index: shares[1].Index, Value: new(big.Int).Add(shares[1].Value, big.NewInt(1))}
+	if VerifyFeldman(c, comm, bad) {
+		t.Fatal("expected a tampered share to fail verification")
+	}
+	bad = Share{Index: shares[2].Index, Value: shares[1].Value}
+	if VerifyFeldman(c, comm, bad) {
+		t.Fatal("expected a share at the wrong index to fail verification")
+	}
+}
+
+func TestPedersen(t *testing.T) {
+	t.Parallel()
+	c := ec.Secp256k1()
+	secret := big.NewInt(42)
+	shares, comm, err := Pedersen(rand.Reader, c, secret, 4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comm[0].Equal(c.ScalarBaseMult(secret)) {
+		t.Fatal("expected the pedersen commitment to hide secret*G")
+	}
+	for i, s := range shares {
+		if !VerifyPedersen(c, comm, s) {
+			t.Fatalf("share %d expected to verify", i)
+		}
+	}
+	got, err := Recover(c, shares[2:])
+	if err != nil || got.Cmp(secret) != 0 {
+		t.Fatalf("expected secret 42 but got %v, %v", got, err)
+	}
+	bad := shares[0]
+	bad.Blinding = new(big.Int).Add(bad.Blinding, big.NewInt(1))
+	if VerifyPedersen(c, comm, bad) {
+		t.Fatal("expected a tampered blinding to fail verification")
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	t.Parallel()
+	c := ec.P256()
+	if _, _, err := Feldman(rand.Reader, c, big.NewInt(1), 2, 3); err == nil {
+		t.Fatal("expected a threshold above n to be rejected")
+	}
+	shares, _, _ := Feldman(rand.Reader, c, big.NewInt(1), 3, 2)
+	if _, err := Recover(c, []Share{shares[0], shares[0]}); err == nil {
+		t.Fatal("expected duplicate shares to be rejected")
+	}
+}
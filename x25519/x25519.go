@@ -0,0 +1,180 @@
+// Package x25519 implements the X25519 Diffie-Hellman function of
+// RFC 7748 with math/big and a textbook Montgomery ladder. It is
+// written for readability and is not constant time.
+package x25519
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// Size is the length of scalars, public keys and shared secrets
+const Size = 32
+
+var (
+	// p = 2^255 - 19
+	p = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	// a24 = (486662 - 2) / 4
+	a24 = big.NewInt(121665)
+
+	// Basepoint is the u-coordinate of the generator, u = 9
+	Basepoint = [Size]byte{9}
+)
+
+// decodeScalar clamps a 32 bytes scalar: the three lowest bits are
+// cleared so the scalar is a multiple of the cofactor 8, the highest
+// bit is cleared and bit 254 is set
+func decodeScalar(k [Size]byte) *big.Int {
+	k[0] &= 248
+	k[31] &= 127
+	k[31] |= 64
+	return new(big.Int).SetBytes(reverse(k[:]))
+}
+
+// decodeU reads a little endian u-coordinate, masking the unused top bit
+func decodeU(u [Size]byte) *big.Int {
+	u[31] &= 127
+	x := new(big.Int).SetBytes(reverse(u[:]))
+	return x.Mod(x, p)
+}
+
+func encodeU(x *big.Int) (out [Size]byte) {
+	b := x.FillBytes(make([]byte, Size))
+	copy(out[:], reverse(b))
+	return
+}
+
+func reverse(in []byte) []byte {
+	out := make([]byte, len(in))
+	for i := range in {
+		out[len(in)-1-i] = in[i]
+	}
+	return out
+}
+
+// ScalarMult computes scalar * point on curve25519, using the Montgomery
+// ladder of RFC 7748 section 5. An error is returned if the result is
+// the all-zero value, which happens when point has a small order.
+func ScalarMult(scalar, point [Size]byte) ([Size]byte, error) {
+	k := decodeScalar(scalar)
+	u := decodeU(point)
+
+	x1 := new(big.Int).Set(u)
+	x2, z2 := big.NewInt(1), big.NewInt(0)
+	x3, z3 := new(big.Int).Set(u), big.NewInt(1)
+	swap := uint(0)
+
+	a, aa, b, bb, e, c, d, da, cb := new(big.Int), new(big.Int), new(big.Int), new(big.Int),
+		new(big.Int), new(big.Int), new(big.Int), new(big.Int), new(big.Int)
+	for t := 254; t >= 0; t-- {
+		kt := k.Bit(t)
+		swap ^= kt
+		if swap == 1 {
+			x2, x3 = x3, x2
+			z2, z3 = z3, z2
+		}
+		swap = kt
+
+		a.Add(x2, z2)
+		aa.Mul(a, a).Mod(aa, p)
+		b.Sub(x2, z2)
+		bb.Mul(b, b).Mod(bb, p)
+		e.Sub(aa, bb)
+		c.Add(x3, z3)
+		d.Sub(x3, z3)
+		da.Mul(d, a).Mod(da, p)
+		cb.Mul(c, b).Mod(cb, p)
+
+		// x3 = (DA + CB)^2, z3 = x1 * (DA - CB)^2
+		x3.Add(da, cb)
+		x3.Mul(x3, x3).Mod(x3, p)
+		z3.Sub(da, cb)
+		z3.Mul(z3, z3)
+		z3.Mul(z3, x1).Mod(z3, p)
+		// x2 = AA * BB, z2 = E * (AA + a24 * E)
+		x2.Mul(aa, bb).Mod(x2, p)
+		z2.Mul(a24, e)
+		z2.Add(z2, aa)
+		z2.Mul(z2, e).Mod(z2, p)
+	}
+	if swap == 1 {
+		x2, z2 = x3, z3
+	}
+	// x2 / z2, with z2^-1 = z2^(p-2)
+	inv := new(big.Int).Exp(z2, new(big.Int).Sub(p, big.NewInt(2)), p)
+	out := encodeU(new(big.Int).Mod(new(big.Int).Mul(x2, inv), p))
+
+	var zero [Size]byte
+	if subtle.ConstantTimeCompare(out[:], zero[:]) == 1 {
+		return out, errors.New("x25519: low order point produced an all-zero shared secret")
+	}
+	return out, nil
+}
+
+// ScalarBaseMult computes the public key of scalar
+func ScalarBaseMult(scalar [Size]byte) [Size]byte {
+	out, _ := ScalarMult(scalar, Basepoint)
+	return out
+}
+
+// GenerateKey reads a random private scalar from rand and returns it
+// along with its public key
+func GenerateKey(rand io.Reader) (priv, pub [Size]byte, err error) {
+	if _, err = io.ReadFull(rand, priv[:]); err != nil {
+		return
+	}
+	pub = ScalarBaseMult(priv)
+	return
+}
+
+// SharedSecret is a convenience wrapper around ScalarMult that takes
+// byte slices, as found in protocol messages
+func SharedSecret(priv, peerPub []byte) ([]byte, error) {
+	if len(priv) != Size || len(peerPub) != Size {
+		return nil, errors.New("x25519: keys must be 32 bytes long")
+	}
+	var k, u [Size]byte
+	copy(k[:], priv)
+	copy(u[:], peerPub)
+	out, err := ScalarMult(k, u)
+	if err != nil {
+		return nil, err
+	}
+	return out[:], nil
+}
+
+// FromEd25519PrivateKey returns the X25519 scalar matching an Ed25519
+// private key: the first half of the SHA-512 of its seed, which is the
+// scalar Ed25519 itself signs with
+func FromEd25519PrivateKey(priv ed25519.PrivateKey) [Size]byte {
+	h := sha512.Sum512(priv.Seed())
+	var out [Size]byte
+	copy(out[:], h[:Size])
+	return out
+}
+
+// FromEd25519PublicKey maps an Ed25519 public key to the u-coordinate of
+// the birationally equivalent Montgomery point, u = (1 + y) / (1 - y)
+func FromEd25519PublicKey(pub ed25519.PublicKey) ([Size]byte, error) {
+	var out [Size]byte
+	if len(pub) != ed25519.PublicKeySize {
+		return out, errors.New("x25519: bad ed25519 public key length")
+	}
+	var enc [Size]byte
+	copy(enc[:], pub)
+	// the top bit holds the sign of x, which the u-coordinate ignores
+	y := decodeU(enc)
+	one := big.NewInt(1)
+	den := new(big.Int).Sub(one, y)
+	den.Mod(den, p)
+	if den.Sign() == 0 {
+		return out, errors.New("x25519: ed25519 public key is the identity")
+	}
+	u := new(big.Int).Add(one, y)
+	u.Mul(u, new(big.Int).ModInverse(den, p))
+	return encodeU(u.Mod(u, p)), nil
+}
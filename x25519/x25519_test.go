@@ -0,0 +1,106 @@
+package x25519
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+)
+
+func TestScalarMultRFC7748(t *testing.T) {
+	t.Parallel()
+	// test vectors from RFC 7748 section 5.2
+	var testcases = []struct {
+		scalar, u, out string
+	}{
+		{
+			"a546e36bf0527c9d3b16154b82465edd62144c0ac1fc5a18506a2244ba449ac4",
+			"e6db6867583030db3594c1a424b15f7c726624ec26b3353b10a903a6d0ab1c4c",
+			"c3da55379de9c6908e94ea4df28d084f32eccf03491c71f754b4075577a28552",
+		},
+		{
+			"4b66e9d4d1b4673c5ad22691957d6af5c11b6421e0ea01d42ca4169e7918ba0d",
+			"e5210f12786811d3f4b7959d0538ae2c31dbe7106fc03c3efc4cd549c715a493",
+			"95cbde9476e8907d7aade45cb4b873f88b595a68799fa152e6f8f7647aac7957",
+		},
+	}
+	for i, testcase := range testcases {
+		var k, u [Size]byte
+		copy(k[:], mustHex(testcase.scalar))
+		copy(u[:], mustHex(testcase.u))
+		out, err := ScalarMult(k, u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(out[:]) != testcase.out {
+			t.Fatalf("testcase %d expected %s but got %x", i, testcase.out, out)
+		}
+	}
+}
+
+func TestDiffieHellmanRFC7748(t *testing.T) {
+	t.Parallel()
+	// alice and bob from RFC 7748 section 6.1
+	var alicePriv, bobPriv [Size]byte
+	copy(alicePriv[:], mustHex("77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a"))
+	copy(bobPriv[:], mustHex("5dab087e624a8a4b79e17f8b83800ee66f3bb1292618b6fd1c2f8b27ff88e0eb"))
+	alicePub := ScalarBaseMult(alicePriv)
+	bobPub := ScalarBaseMult(bobPriv)
+	if hex.EncodeToString(alicePub[:]) != "8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a" {
+		t.Fatalf("unexpected alice public key %x", alicePub)
+	}
+	if hex.EncodeToString(bobPub[:]) != "de9edb7d7b7dc1b4d35b61c2ece435373f8343c85b78674dadfc7e146f882b4f" {
+		t.Fatalf("unexpected bob public key %x", bobPub)
+	}
+	k1, err := ScalarMult(alicePriv, bobPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k2, err := ScalarMult(bobPriv, alicePub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k1 != k2 || hex.EncodeToString(k1[:]) != "4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742" {
+		t.Fatalf("unexpected shared secrets %x and %x", k1, k2)
+	}
+}
+
+func TestLowOrderPoint(t *testing.T) {
+	t.Parallel()
+	priv, _, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// u = 0 and u = 1 have order 1 and 4, and always give an all-zero output
+	for _, u := range [][Size]byte{{0}, {1}} {
+		if _, err := ScalarMult(priv, u); err == nil {
+			t.Fatalf("expected an error multiplying by the low order point %x", u)
+		}
+	}
+}
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestFromEd25519(t *testing.T) {
+	t.Parallel()
+	for i := 0; i < 5; i++ {
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scalar := FromEd25519PrivateKey(priv)
+		u, err := FromEd25519PublicKey(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := ScalarBaseMult(scalar); expected != u {
+			t.Fatalf("testcase %d expected public key %x but got %x", i, expected, u)
+		}
+	}
+}
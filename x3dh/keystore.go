@@ -0,0 +1,128 @@
+package x3dh
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/jvehent/badcrypto/x25519"
+)
+
+// KeyStore holds Bob's private keys: his identity, his current signed
+// prekey and the one-time prekeys that were not used yet. It plays the
+// role of both Bob's device and the server distributing his bundles.
+type KeyStore struct {
+	Identity     *IdentityKey
+	SignedPreKey *SignedPreKey
+
+	mu             sync.Mutex
+	oneTimePreKeys map[uint32]*PreKey
+	published      []uint32
+	nextID         uint32
+	rand           io.Reader
+}
+
+// NewKeyStore creates a signed prekey and count one-time prekeys for
+// identity
+func NewKeyStore(rand io.Reader, identity *IdentityKey, count int) (*KeyStore, error) {
+	spk, err := identity.NewSignedPreKey(rand, 1)
+	if err != nil {
+		return nil, err
+	}
+	ks := &KeyStore{
+		Identity:       identity,
+		SignedPreKey:   spk,
+		oneTimePreKeys: make(map[uint32]*PreKey),
+		nextID:         1,
+		rand:           rand,
+	}
+	if err := ks.AddOneTimePreKeys(count); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// AddOneTimePreKeys generates and publishes count more one-time prekeys
+func (ks *KeyStore) AddOneTimePreKeys(count int) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	for i := 0; i < count; i++ {
+		pk, err := GeneratePreKey(ks.rand, ks.nextID)
+		if err != nil {
+			return err
+		}
+		ks.oneTimePreKeys[pk.ID] = pk
+		ks.published = append(ks.published, pk.ID)
+		ks.nextID++
+	}
+	return nil
+}
+
+// Bundle returns a prekey bundle for a new initiator. Each one-time
+// prekey is handed out at most once, when none are left the bundle
+// does not have one.
+func (ks *KeyStore) Bundle() *Bundle {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	b := &Bundle{
+		IdentityKey:           ks.Identity.Public(),
+		SignedPreKeyID:        ks.SignedPreKey.ID,
+		SignedPreKey:          ks.SignedPreKey.Public,
+		SignedPreKeySignature: ks.SignedPreKey.Signature,
+	}
+	if len(ks.published) > 0 {
+		id := ks.published[0]
+		ks.published = ks.published[1:]
+		b.OneTimePreKeyID = id
+		b.OneTimePreKey = ks.oneTimePreKeys[id].Public
+	}
+	return b
+}
+
+// Respond runs Bob's side of X3DH with Alice's initial message and
+// returns the shared key and associated data. The one-time prekey used
+// is deleted, so a replayed initial message fails.
+func (ks *KeyStore) Respond(msg *InitialMessage, info []byte) (sk, ad []byte, err error) {
+	if msg.SignedPreKeyID != ks.SignedPreKey.ID {
+		return nil, nil, fmt.Errorf("x3dh: unknown signed prekey %d", msg.SignedPreKeyID)
+	}
+	ikA, err := dhPublic(msg.IdentityKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	spk := ks.SignedPreKey.Private
+	var dhs [][]byte
+	for _, pair := range [][2][]byte{
+		{spk, ikA},
+		{ks.Identity.dhPrivate(), msg.EphemeralKey},
+		{spk, msg.EphemeralKey},
+	} {
+		dh, err := x25519.SharedSecret(pair[0], pair[1])
+		if err != nil {
+			return nil, nil, err
+		}
+		dhs = append(dhs, dh)
+	}
+	if msg.HasOneTimePreKey {
+		ks.mu.Lock()
+		opk, ok := ks.oneTimePreKeys[msg.OneTimePreKeyID]
+		delete(ks.oneTimePreKeys, msg.OneTimePreKeyID)
+		ks.mu.Unlock()
+		if !ok {
+			return nil, nil, errors.New("x3dh: unknown or already used one-time prekey")
+		}
+		dh4, err := x25519.SharedSecret(opk.Private, msg.EphemeralKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		dhs = append(dhs, dh4)
+	}
+	if sk, err = deriveKey(dhs, info); err != nil {
+		return nil, nil, err
+	}
+	if ad, err = associatedData(msg.IdentityKey, ks.Identity.Public()); err != nil {
+		return nil, nil, err
+	}
+	return sk, ad, nil
+}
@@ -0,0 +1,199 @@
+// Package x3dh implements the Extended Triple Diffie-Hellman key agreement
+// of the Signal protocol, which lets Alice establish a shared secret with
+// Bob while he is offline, from a bundle of prekeys he published.
+//
+// Identity keys are Ed25519 keys: they sign the signed prekey and are
+// converted to X25519 for the Diffie-Hellman computations, instead of
+// using XEdDSA as Signal does. The shared secret is
+//
+//	DH1 = DH(IK_A, SPK_B)   DH2 = DH(EK_A, IK_B)
+//	DH3 = DH(EK_A, SPK_B)   DH4 = DH(EK_A, OPK_B)
+//	SK  = HKDF(F || DH1 || DH2 || DH3 || DH4)
+//
+// where DH4 is only present when Bob had a one-time prekey available.
+package x3dh
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"github.com/jvehent/badcrypto/kdf"
+	"github.com/jvehent/badcrypto/x25519"
+)
+
+// SharedKeySize is the length of the secret agreed on
+const SharedKeySize = 32
+
+// curveX25519 prefixes encoded public keys, as in Signal
+const curveX25519 = 0x05
+
+// IdentityKey is the long term key of a party
+type IdentityKey struct {
+	priv ed25519.PrivateKey
+}
+
+// GenerateIdentityKey returns a new random identity key
+func GenerateIdentityKey(rand io.Reader) (*IdentityKey, error) {
+	_, priv, err := ed25519.GenerateKey(rand)
+	if err != nil {
+		return nil, err
+	}
+	return &IdentityKey{priv: priv}, nil
+}
+
+// Public returns the Ed25519 public identity key
+func (ik *IdentityKey) Public() ed25519.PublicKey {
+	return ik.priv.Public().(ed25519.PublicKey)
+}
+
+func (ik *IdentityKey) dhPrivate() []byte {
+	k := x25519.FromEd25519PrivateKey(ik.priv)
+	return k[:]
+}
+
+func dhPublic(pub ed25519.PublicKey) ([]byte, error) {
+	u, err := x25519.FromEd25519PublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	return u[:], nil
+}
+
+// encode returns the encoding of a public X25519 key used in signatures
+// and associated data: a curve type byte followed by the u-coordinate
+func encode(pub []byte) []byte {
+	return append([]byte{curveX25519}, pub...)
+}
+
+// PreKey is an X25519 key pair identified by a number
+type PreKey struct {
+	ID      uint32
+	Private []byte
+	Public  []byte
+}
+
+// GeneratePreKey returns a new random prekey with the given identifier
+func GeneratePreKey(rand io.Reader, id uint32) (*PreKey, error) {
+	priv, pub, err := x25519.GenerateKey(rand)
+	if err != nil {
+		return nil, err
+	}
+	return &PreKey{ID: id, Private: priv[:], Public: pub[:]}, nil
+}
+
+// SignedPreKey is a medium term prekey signed by the identity key
+type SignedPreKey struct {
+	PreKey
+	Signature []byte
+}
+
+// NewSignedPreKey generates a prekey and signs its encoding with ik
+func (ik *IdentityKey) NewSignedPreKey(rand io.Reader, id uint32) (*SignedPreKey, error) {
+	pk, err := GeneratePreKey(rand, id)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedPreKey{PreKey: *pk, Signature: ed25519.Sign(ik.priv, encode(pk.Public))}, nil
+}
+
+// Bundle is what Bob publishes for Alice to start a session with him
+type Bundle struct {
+	IdentityKey           ed25519.PublicKey
+	SignedPreKeyID        uint32
+	SignedPreKey          []byte
+	SignedPreKeySignature []byte
+	// OneTimePreKey is nil when the server ran out of them
+	OneTimePreKeyID uint32
+	OneTimePreKey   []byte
+}
+
+// InitialMessage is sent by Alice so Bob can compute the same secret
+type InitialMessage struct {
+	IdentityKey     ed25519.PublicKey
+	EphemeralKey    []byte
+	SignedPreKeyID  uint32
+	OneTimePreKeyID uint32
+	// HasOneTimePreKey indicates that OneTimePreKeyID was used
+	HasOneTimePreKey bool
+}
+
+// deriveKey computes SK from the concatenated DH outputs. F is 32 0xFF
+// bytes, so the input never collides with an XEdDSA private key, the
+// salt is zero and info identifies the application.
+func deriveKey(dhs [][]byte, info []byte) ([]byte, error) {
+	ikm := bytes.Repeat([]byte{0xff}, 32)
+	for _, dh := range dhs {
+		ikm = append(ikm, dh...)
+	}
+	return kdf.HKDF(sha256.New, ikm, make([]byte, sha256.Size), info, SharedKeySize)
+}
+
+// associatedData returns AD = Encode(IK_A) || Encode(IK_B), which the
+// first messages of the session must authenticate
+func associatedData(ikA, ikB ed25519.PublicKey) ([]byte, error) {
+	a, err := dhPublic(ikA)
+	if err != nil {
+		return nil, err
+	}
+	b, err := dhPublic(ikB)
+	if err != nil {
+		return nil, err
+	}
+	return append(encode(a), encode(b)...), nil
+}
+
+// Initiate runs Alice's side of X3DH against Bob's bundle. It verifies
+// the signed prekey, then returns the shared key, the associated data
+// and the message to send to Bob.
+func Initiate(rand io.Reader, alice *IdentityKey, bundle *Bundle, info []byte) (sk, ad []byte, msg *InitialMessage, err error) {
+	if len(bundle.IdentityKey) != ed25519.PublicKeySize || len(bundle.SignedPreKey) != x25519.Size {
+		return nil, nil, nil, errors.New("x3dh: malformed bundle")
+	}
+	if !ed25519.Verify(bundle.IdentityKey, encode(bundle.SignedPreKey), bundle.SignedPreKeySignature) {
+		return nil, nil, nil, errors.New("x3dh: invalid signed prekey signature")
+	}
+	ikB, err := dhPublic(bundle.IdentityKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ek, err := GeneratePreKey(rand, 0)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var dhs [][]byte
+	for _, pair := range [][2][]byte{
+		{alice.dhPrivate(), bundle.SignedPreKey},
+		{ek.Private, ikB},
+		{ek.Private, bundle.SignedPreKey},
+	} {
+		dh, err := x25519.SharedSecret(pair[0], pair[1])
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		dhs = append(dhs, dh)
+	}
+	msg = &InitialMessage{
+		IdentityKey:    alice.Public(),
+		EphemeralKey:   ek.Public,
+		SignedPreKeyID: bundle.SignedPreKeyID,
+	}
+	if bundle.OneTimePreKey != nil {
+		dh4, err := x25519.SharedSecret(ek.Private, bundle.OneTimePreKey)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		dhs = append(dhs, dh4)
+		msg.OneTimePreKeyID = bundle.OneTimePreKeyID
+		msg.HasOneTimePreKey = true
+	}
+	if sk, err = deriveKey(dhs, info); err != nil {
+		return nil, nil, nil, err
+	}
+	if ad, err = associatedData(alice.Public(), bundle.IdentityKey); err != nil {
+		return nil, nil, nil, err
+	}
+	return sk, ad, msg, nil
+}
@@ -0,0 +1,100 @@
+package x3dh
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+var info = []byte("badcrypto x3dh test")
+
+func setup(t *testing.T, oneTimeKeys int) (*IdentityKey, *KeyStore) {
+	alice, err := GenerateIdentityKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := GenerateIdentityKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks, err := NewKeyStore(rand.Reader, bob, oneTimeKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return alice, ks
+}
+
+func TestAgreement(t *testing.T) {
+	t.Parallel()
+	alice, bob := setup(t, 1)
+	// the first bundle has a one-time prekey, the second one does not
+	for i, expectOneTime := range []bool{true, false} {
+		bundle := bob.Bundle()
+		if (bundle.OneTimePreKey != nil) != expectOneTime {
+			t.Fatalf("testcase %d unexpected one-time prekey in bundle", i)
+		}
+		skA, adA, msg, err := Initiate(rand.Reader, alice, bundle, info)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.HasOneTimePreKey != expectOneTime {
+			t.Fatalf("testcase %d unexpected one-time prekey in message", i)
+		}
+		skB, adB, err := bob.Respond(msg, info)
+		if err != nil {
+			t.Fatalf("testcase %d: %v", i, err)
+		}
+		if !bytes.Equal(skA, skB) || len(skA) != SharedKeySize {
+			t.Fatalf("testcase %d shared keys differ", i)
+		}
+		if !bytes.Equal(adA, adB) || len(adA) != 66 {
+			t.Fatalf("testcase %d associated data differ", i)
+		}
+	}
+}
+
+func TestOneTimePreKeyReuse(t *testing.T) {
+	t.Parallel()
+	alice, bob := setup(t, 1)
+	_, _, msg, err := Initiate(rand.Reader, alice, bob.Bundle(), info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := bob.Respond(msg, info); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := bob.Respond(msg, info); err == nil {
+		t.Fatal("expected a replayed initial message to fail")
+	}
+}
+
+func TestBadSignature(t *testing.T) {
+	t.Parallel()
+	alice, bob := setup(t, 0)
+	bundle := bob.Bundle()
+	// a server substituting its own signed prekey
+	mitm, err := GeneratePreKey(rand.Reader, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle.SignedPreKey = mitm.Public
+	if _, _, _, err := Initiate(rand.Reader, alice, bundle, info); err == nil {
+		t.Fatal("expected a substituted signed prekey to be rejected")
+	}
+}
+
+func TestInfoMismatch(t *testing.T) {
+	t.Parallel()
+	alice, bob := setup(t, 0)
+	skA, _, msg, err := Initiate(rand.Reader, alice, bob.Bundle(), info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	skB, _, err := bob.Respond(msg, []byte("another application"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(skA, skB) {
+		t.Fatal("expected keys to be bound to the application info")
+	}
+}
@@ -0,0 +1,307 @@
+// Package bulletproofs implements the range proofs of Bünz, Bootle, Boneh,
+// Poelstra, Wuille and Maxwell: a proof that a Pedersen commitment
+// V = v*G + gamma*H opens to a value v in [0, 2^n), of size logarithmic in
+// n thanks to the inner product argument. There is no trusted setup, the
+// generators are hashed to the curve.
+//
+// This is the single value protocol of section 4.1 of the paper, made non
+// interactive with Fiat-Shamir, without aggregation or batch verification.
+package bulletproofs
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/commit"
+	"github.com/jvehent/badcrypto/ec"
+)
+
+// Params holds the generators of range proofs over n bits
+type Params struct {
+	Pedersen *commit.Pedersen
+	N        int
+	G, H     []*ec.Point
+	// U is the generator of the inner product in the inner product
+	// argument
+	U *ec.Point
+}
+
+// Proof is a range proof. L and R hold log2(n) points each.
+type Proof struct {
+	A, S, T1, T2 *ec.Point
+	TauX, Mu, T  *big.Int
+	L, R         []*ec.Point
+	// a and b are the last vectors of the inner product argument,
+	// folded down to a single scalar
+	IPA, IPB *big.Int
+}
+
+// NewParams returns the parameters for proofs over n bits, where n is a
+// power of two no larger than 64
+func NewParams(c *ec.Curve, n int) (*Params, error) {
+	if n < 1 || n > 64 || n&(n-1) != 0 {
+		return nil, errors.New("bulletproofs: the bit size must be a power of two up to 64")
+	}
+	ped := commit.NewPedersen(c)
+	return &Params{
+		Pedersen: ped,
+		N:        n,
+		G:        ped.Generators("bulletproofs G", n),
+		H:        ped.Generators("bulletproofs H", n),
+		U:        c.HashToPoint("badcrypto bulletproofs U", []byte(c.Name)),
+	}, nil
+}
+
+func (p *Params) randomVector(rand io.Reader) ([]*big.Int, error) {
+	out := make([]*big.Int, p.N)
+	for i := range out {
+		r, err := p.Pedersen.Curve.RandomScalar(rand)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = r
+	}
+	return out, nil
+}
+
+// Prove commits to v with blinding gamma and proves it lies in [0, 2^n).
+// It returns the proof and the commitment V it is about.
+func (p *Params) Prove(rand io.Reader, v, gamma *big.Int) (*Proof, *ec.Point, error) {
+	c := p.Pedersen.Curve
+	q := c.N
+	if v.Sign() < 0 || v.BitLen() > p.N {
+		return nil, nil, errors.New("bulletproofs: value out of range")
+	}
+	V := p.Pedersen.CommitWithBlinding(v, gamma)
+
+	// aL holds the bits of v, aR = aL - 1 so that aL o aR = 0
+	aL := make([]*big.Int, p.N)
+	aR := make([]*big.Int, p.N)
+	for i := range aL {
+		aL[i] = big.NewInt(int64(v.Bit(i)))
+		aR[i] = new(big.Int).Sub(aL[i], big.NewInt(1))
+		aR[i].Mod(aR[i], q)
+	}
+	alpha, err := c.RandomScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	rho, err := c.RandomScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	sL, err := p.randomVector(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	sR, err := p.randomVector(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	proof := &Proof{
+		A: p.vectorCommit(alpha, aL, aR),
+		S: p.vectorCommit(rho, sL, sR),
+	}
+
+	t := newTranscript(c, "range proof")
+	t.appendPoints(V, proof.A, proof.S)
+	y := t.challenge()
+	z := t.challenge()
+
+	// l(X) = l0 + l1*X and r(X) = r0 + r1*X
+	yn := powers(y, p.N, q)
+	twon := powers(big.NewInt(2), p.N, q)
+	z2 := new(big.Int).Mul(z, z)
+	z2.Mod(z2, q)
+	negZ := new(big.Int).Sub(q, z)
+	l0 := addScalar(aL, negZ, q)
+	l1 := sL
+	r0 := addVectors(hadamard(yn, addScalar(aR, z, q), q), scale(twon, z2, q), q)
+	r1 := hadamard(yn, sR, q)
+
+	// t(X) = <l(X), r(X)> = t0 + t1*X + t2*X^2
+	t1 := new(big.Int).Add(innerProduct(l0, r1, q), innerProduct(l1, r0, q))
+	t1.Mod(t1, q)
+	t2 := innerProduct(l1, r1, q)
+	tau1, err := c.RandomScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	tau2, err := c.RandomScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	proof.T1 = p.Pedersen.CommitWithBlinding(t1, tau1)
+	proof.T2 = p.Pedersen.CommitWithBlinding(t2, tau2)
+	t.appendPoints(proof.T1, proof.T2)
+	x := t.challenge()
+
+	l := addVectors(l0, scale(l1, x, q), q)
+	r := addVectors(r0, scale(r1, x, q), q)
+	proof.T = innerProduct(l, r, q)
+	x2 := new(big.Int).Mul(x, x)
+	proof.TauX = new(big.Int).Mul(tau2, x2)
+	proof.TauX.Add(proof.TauX, new(big.Int).Mul(tau1, x))
+	proof.TauX.Add(proof.TauX, new(big.Int).Mul(z2, gamma))
+	proof.TauX.Mod(proof.TauX, q)
+	proof.Mu = new(big.Int).Mul(rho, x)
+	proof.Mu.Add(proof.Mu, alpha)
+	proof.Mu.Mod(proof.Mu, q)
+	t.appendScalars(proof.TauX, proof.Mu, proof.T)
+
+	w := t.challenge()
+	u := c.ScalarMult(p.U, w)
+	proof.L, proof.R, proof.IPA, proof.IPB = p.proveInnerProduct(t, p.G, p.primeH(y), u, l, r)
+	return proof, V, nil
+}
+
+// vectorCommit returns blinding*H + <a, G> + <b, H_vec>
+func (p *Params) vectorCommit(blinding *big.Int, a, b []*big.Int) *ec.Point {
+	scalars := append(append([]*big.Int{blinding}, a...), b...)
+	points := append(append([]*ec.Point{p.Pedersen.H}, p.G...), p.H...)
+	return p.Pedersen.Curve.MultiScalarMult(scalars, points)
+}
+
+// primeH returns H'_i = y^-i * H_i, which turns the commitment to r into
+// one to r o y^-n
+func (p *Params) primeH(y *big.Int) []*ec.Point {
+	c := p.Pedersen.Curve
+	yinv := new(big.Int).ModInverse(y, c.N)
+	exps := powers(yinv, p.N, c.N)
+	out := make([]*ec.Point, p.N)
+	for i := range out {
+		out[i] = c.ScalarMult(p.H[i], exps[i])
+	}
+	return out
+}
+
+// proveInnerProduct runs protocol 2 of the paper, halving the vectors at
+// every round, for P = <a, G> + <b, H> + <a, b>*u
+func (p *Params) proveInnerProduct(t *transcript, G, H []*ec.Point, u *ec.Point, a, b []*big.Int) (L, R []*ec.Point, ipa, ipb *big.Int) {
+	c := p.Pedersen.Curve
+	q := c.N
+	for len(a) > 1 {
+		half := len(a) / 2
+		aLo, aHi := a[:half], a[half:]
+		bLo, bHi := b[:half], b[half:]
+		GLo, GHi := G[:half], G[half:]
+		HLo, HHi := H[:half], H[half:]
+		cL := innerProduct(aLo, bHi, q)
+		cR := innerProduct(aHi, bLo, q)
+		left := c.MultiScalarMult(
+			append(append(append([]*big.Int{}, aLo...), bHi...), cL),
+			append(append(append([]*ec.Point{}, GHi...), HLo...), u))
+		right := c.MultiScalarMult(
+			append(append(append([]*big.Int{}, aHi...), bLo...), cR),
+			append(append(append([]*ec.Point{}, GLo...), HHi...), u))
+		L = append(L, left)
+		R = append(R, right)
+		t.appendPoints(left, right)
+		x := t.challenge()
+		xinv := new(big.Int).ModInverse(x, q)
+		G = foldPoints(c, GLo, GHi, xinv, x)
+		H = foldPoints(c, HLo, HHi, x, xinv)
+		a = foldScalars(aLo, aHi, x, xinv, q)
+		b = foldScalars(bLo, bHi, xinv, x, q)
+	}
+	return L, R, a[0], b[0]
+}
+
+// Verify checks that proof shows the value committed in V is in [0, 2^n)
+func (p *Params) Verify(V *ec.Point, proof *Proof) bool {
+	c := p.Pedersen.Curve
+	q := c.N
+	if proof == nil || !p.wellFormed(proof) || !c.IsOnCurve(V) {
+		return false
+	}
+	t := newTranscript(c, "range proof")
+	t.appendPoints(V, proof.A, proof.S)
+	y := t.challenge()
+	z := t.challenge()
+	t.appendPoints(proof.T1, proof.T2)
+	x := t.challenge()
+	t.appendScalars(proof.TauX, proof.Mu, proof.T)
+	w := t.challenge()
+
+	// t*G + taux*H = z^2*V + delta(y,z)*G + x*T1 + x^2*T2
+	yn := powers(y, p.N, q)
+	twon := powers(big.NewInt(2), p.N, q)
+	z2 := new(big.Int).Mul(z, z)
+	z2.Mod(z2, q)
+	z3 := new(big.Int).Mul(z2, z)
+	z3.Mod(z3, q)
+	delta := new(big.Int).Sub(z, z2)
+	delta.Mul(delta, sum(yn, q))
+	delta.Sub(delta, new(big.Int).Mul(z3, sum(twon, q)))
+	delta.Mod(delta, q)
+	x2 := new(big.Int).Mul(x, x)
+	x2.Mod(x2, q)
+	lhs := p.Pedersen.CommitWithBlinding(proof.T, proof.TauX)
+	rhs := c.MultiScalarMult(
+		[]*big.Int{z2, delta, x, x2},
+		[]*ec.Point{V, c.Generator(), proof.T1, proof.T2})
+	if !lhs.Equal(rhs) {
+		return false
+	}
+
+	// P = A + x*S - z*<1, G> + <z*y^n + z^2*2^n, H'> should commit to l
+	// and r with blinding mu, add <l, r>*u and check the inner product
+	H := p.primeH(y)
+	hExps := addVectors(scale(yn, z, q), scale(twon, z2, q), q)
+	negZ := new(big.Int).Sub(q, z)
+	scalars := []*big.Int{big.NewInt(1), x, new(big.Int).Sub(q, proof.Mu), proof.T}
+	u := c.ScalarMult(p.U, w)
+	points := []*ec.Point{proof.A, proof.S, p.Pedersen.H, u}
+	for i := 0; i < p.N; i++ {
+		scalars = append(scalars, negZ, hExps[i])
+		points = append(points, p.G[i], H[i])
+	}
+	P := c.MultiScalarMult(scalars, points)
+	return p.verifyInnerProduct(t, p.G, H, u, P, proof)
+}
+
+// verifyInnerProduct replays the folding of the generators and of P, and
+// checks the final P = a*G + b*H + a*b*u
+func (p *Params) verifyInnerProduct(t *transcript, G, H []*ec.Point, u, P *ec.Point, proof *Proof) bool {
+	c := p.Pedersen.Curve
+	q := c.N
+	for i := range proof.L {
+		t.appendPoints(proof.L[i], proof.R[i])
+		x := t.challenge()
+		xinv := new(big.Int).ModInverse(x, q)
+		x2 := new(big.Int).Mul(x, x)
+		xinv2 := new(big.Int).Mul(xinv, xinv)
+		P = c.MultiScalarMult([]*big.Int{x2, big.NewInt(1), xinv2}, []*ec.Point{proof.L[i], P, proof.R[i]})
+		half := len(G) / 2
+		G = foldPoints(c, G[:half], G[half:], xinv, x)
+		H = foldPoints(c, H[:half], H[half:], x, xinv)
+	}
+	ab := new(big.Int).Mul(proof.IPA, proof.IPB)
+	expected := c.MultiScalarMult([]*big.Int{proof.IPA, proof.IPB, ab}, []*ec.Point{G[0], H[0], u})
+	return P.Equal(expected)
+}
+
+// wellFormed checks the sizes and ranges of the proof elements
+func (p *Params) wellFormed(proof *Proof) bool {
+	c := p.Pedersen.Curve
+	rounds := 0
+	for n := p.N; n > 1; n /= 2 {
+		rounds++
+	}
+	if len(proof.L) != rounds || len(proof.R) != rounds {
+		return false
+	}
+	points := append([]*ec.Point{proof.A, proof.S, proof.T1, proof.T2}, proof.L...)
+	for _, pt := range append(points, proof.R...) {
+		if pt == nil || !c.IsOnCurve(pt) {
+			return false
+		}
+	}
+	for _, s := range []*big.Int{proof.TauX, proof.Mu, proof.T, proof.IPA, proof.IPB} {
+		if s == nil || s.Sign() < 0 || s.Cmp(c.N) >= 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package bulletproofs
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+func TestRangeProof(t *testing.T) {
+	t.Parallel()
+	var testcases = []struct {
+		curve *ec.Curve
+		n     int
+		v     uint64
+	}{
+		{ec.P256(), 8, 0},
+		{ec.P256(), 8, 255},
+		{ec.Secp256k1(), 16, 12345},
+		{ec.P256(), 32, 0xdeadbeef},
+		{ec.Secp256k1(), 1, 1},
+	}
+	for i, tc := range testcases {
+		params, err := NewParams(tc.curve, tc.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gamma, _ := tc.curve.RandomScalar(rand.Reader)
+		proof, V, err := params.Prove(rand.Reader, new(big.Int).SetUint64(tc.v), gamma)
+		if err != nil {
+			t.Fatalf("testcase %d: %v", i, err)
+		}
+		if !params.Pedersen.Open(V, new(big.Int).SetUint64(tc.v), gamma) {
+			t.Fatalf("testcase %d expected V to commit to the value", i)
+		}
+		if !params.Verify(V, proof) {
+			t.Fatalf("testcase %d expected proof to verify", i)
+		}
+		other, _, _ := params.Pedersen.Commit(rand.Reader, new(big.Int).SetUint64(tc.v))
+		if params.Verify(other, proof) {
+			t.Fatalf("testcase %d expected proof for another commitment to fail", i)
+		}
+	}
+}
+
+func TestOutOfRange(t *testing.T) {
+	t.Parallel()
+	c := ec.P256()
+	params, err := NewParams(c, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := params.Prove(rand.Reader, big.NewInt(256), big.NewInt(1)); err == nil {
+		t.Fatal("expected the prover to refuse a value out of range")
+	}
+	// a cheating prover commits to 256 but proves the range for 0, the
+	// commitments do not match so the first check fails
+	gamma := big.NewInt(99)
+	proof, _, _ := params.Prove(rand.Reader, big.NewInt(0), gamma)
+	V := params.Pedersen.CommitWithBlinding(big.NewInt(256), gamma)
+	if params.Verify(V, proof) {
+		t.Fatal("expected the proof to not transfer to an out of range value")
+	}
+}
+
+func TestTampering(t *testing.T) {
+	t.Parallel()
+	c := ec.Secp256k1()
+	params, _ := NewParams(c, 16)
+	proof, V, err := params.Prove(rand.Reader, big.NewInt(1000), big.NewInt(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof.IPA = new(big.Int).Add(proof.IPA, big.NewInt(1))
+	if params.Verify(V, proof) {
+		t.Fatal("expected a tampered inner product argument to fail")
+	}
+	proof.IPA.Sub(proof.IPA, big.NewInt(1))
+	proof.L = proof.L[1:]
+	if params.Verify(V, proof) {
+		t.Fatal("expected a truncated proof to fail")
+	}
+	if _, err := NewParams(c, 12); err == nil {
+		t.Fatal("expected a bit size that is not a power of two to be rejected")
+	}
+}
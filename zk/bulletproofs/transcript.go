@@ -0,0 +1,41 @@
+package bulletproofs
+
+import (
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+// transcript accumulates the messages of the prover so every Fiat-Shamir
+// challenge depends on everything sent before it
+type transcript struct {
+	c        *ec.Curve
+	messages [][]byte
+}
+
+func newTranscript(c *ec.Curve, label string) *transcript {
+	return &transcript{c: c, messages: [][]byte{[]byte(label)}}
+}
+
+func (t *transcript) appendPoints(points ...*ec.Point) {
+	for _, p := range points {
+		t.messages = append(t.messages, t.c.Marshal(p))
+	}
+}
+
+func (t *transcript) appendScalars(scalars ...*big.Int) {
+	for _, s := range scalars {
+		t.messages = append(t.messages, s.FillBytes(make([]byte, t.c.ByteLen())))
+	}
+}
+
+// challenge returns a non zero scalar and appends it to the transcript
+func (t *transcript) challenge() *big.Int {
+	for {
+		ch := t.c.HashToScalar("badcrypto bulletproofs", t.messages...)
+		t.appendScalars(ch)
+		if ch.Sign() != 0 {
+			return ch
+		}
+	}
+}
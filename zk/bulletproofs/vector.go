@@ -0,0 +1,87 @@
+package bulletproofs
+
+import (
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+// scalar vector helpers, all results are reduced modulo the group order
+
+func innerProduct(a, b []*big.Int, n *big.Int) *big.Int {
+	sum := new(big.Int)
+	for i := range a {
+		sum.Add(sum, new(big.Int).Mul(a[i], b[i]))
+	}
+	return sum.Mod(sum, n)
+}
+
+func hadamard(a, b []*big.Int, n *big.Int) []*big.Int {
+	out := make([]*big.Int, len(a))
+	for i := range a {
+		out[i] = new(big.Int).Mul(a[i], b[i])
+		out[i].Mod(out[i], n)
+	}
+	return out
+}
+
+func addVectors(a, b []*big.Int, n *big.Int) []*big.Int {
+	out := make([]*big.Int, len(a))
+	for i := range a {
+		out[i] = new(big.Int).Add(a[i], b[i])
+		out[i].Mod(out[i], n)
+	}
+	return out
+}
+
+func addScalar(a []*big.Int, k, n *big.Int) []*big.Int {
+	out := make([]*big.Int, len(a))
+	for i := range a {
+		out[i] = new(big.Int).Add(a[i], k)
+		out[i].Mod(out[i], n)
+	}
+	return out
+}
+
+func scale(a []*big.Int, k, n *big.Int) []*big.Int {
+	out := make([]*big.Int, len(a))
+	for i := range a {
+		out[i] = new(big.Int).Mul(a[i], k)
+		out[i].Mod(out[i], n)
+	}
+	return out
+}
+
+// powers returns 1, k, k^2, ..., k^(length-1)
+func powers(k *big.Int, length int, n *big.Int) []*big.Int {
+	out := make([]*big.Int, length)
+	acc := big.NewInt(1)
+	for i := range out {
+		out[i] = new(big.Int).Set(acc)
+		acc.Mul(acc, k)
+		acc.Mod(acc, n)
+	}
+	return out
+}
+
+func sum(a []*big.Int, n *big.Int) *big.Int {
+	s := new(big.Int)
+	for _, x := range a {
+		s.Add(s, x)
+	}
+	return s.Mod(s, n)
+}
+
+// foldPoints returns x*lo[i] + y*hi[i]
+func foldPoints(c *ec.Curve, lo, hi []*ec.Point, x, y *big.Int) []*ec.Point {
+	out := make([]*ec.Point, len(lo))
+	for i := range lo {
+		out[i] = c.MultiScalarMult([]*big.Int{x, y}, []*ec.Point{lo[i], hi[i]})
+	}
+	return out
+}
+
+// foldScalars returns x*lo[i] + y*hi[i]
+func foldScalars(lo, hi []*big.Int, x, y, n *big.Int) []*big.Int {
+	return addVectors(scale(lo, x, n), scale(hi, y, n), n)
+}
@@ -0,0 +1,123 @@
+package sigma
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+// Representation states knowledge of x_1..x_n such that
+// Y = x_1*G_1 + ... + x_n*G_n
+type Representation struct {
+	C          *ec.Curve
+	Generators []*ec.Point
+	Y          *ec.Point
+}
+
+// NewDLog states knowledge of x such that Y = x*G. A nil G means the base
+// point of the curve, making this Schnorr's identification protocol.
+func NewDLog(c *ec.Curve, g, y *ec.Point) *Representation {
+	if g == nil {
+		g = c.Generator()
+	}
+	return &Representation{C: c, Generators: []*ec.Point{g}, Y: y}
+}
+
+// Curve returns the group of the statement
+func (s *Representation) Curve() *ec.Curve { return s.C }
+
+// Encode serializes the statement
+func (s *Representation) Encode() []byte {
+	return encodePoints(s.C, "representation", append(append([]*ec.Point{}, s.Generators...), s.Y)...)
+}
+
+// Check verifies sum(s_i*G_i) = T + c*Y
+func (s *Representation) Check(commitment []*ec.Point, challenge *big.Int, response []*big.Int) bool {
+	if len(commitment) != 1 || len(response) != len(s.Generators) {
+		return false
+	}
+	lhs := s.C.MultiScalarMult(response, s.Generators)
+	return lhs.Equal(s.C.Add(commitment[0], s.C.ScalarMult(s.Y, challenge)))
+}
+
+// Witness returns the prover side of the statement for the secrets xs
+func (s *Representation) Witness(xs ...*big.Int) (Witness, error) {
+	if len(xs) != len(s.Generators) {
+		return nil, errWitnessLength
+	}
+	return &representationWitness{s: s, xs: xs}, nil
+}
+
+type representationWitness struct {
+	s  *Representation
+	xs []*big.Int
+}
+
+func (w *representationWitness) Statement() Statement { return w.s }
+
+func (w *representationWitness) Commit(rand io.Reader) ([]*ec.Point, []*big.Int, error) {
+	rs, err := randomScalars(rand, w.s.C, len(w.xs))
+	if err != nil {
+		return nil, nil, err
+	}
+	return []*ec.Point{w.s.C.MultiScalarMult(rs, w.s.Generators)}, rs, nil
+}
+
+func (w *representationWitness) Respond(state []*big.Int, challenge *big.Int) []*big.Int {
+	out := make([]*big.Int, len(w.xs))
+	for i := range out {
+		out[i] = respond(w.s.C, state[i], challenge, w.xs[i])
+	}
+	return out
+}
+
+// DLEQ states knowledge of x such that Y = x*G and Z = x*H, that is that
+// (G, H, Y, Z) is a Diffie-Hellman tuple, as in Chaum and Pedersen
+type DLEQ struct {
+	C    *ec.Curve
+	G, H *ec.Point
+	Y, Z *ec.Point
+}
+
+// Curve returns the group of the statement
+func (s *DLEQ) Curve() *ec.Curve { return s.C }
+
+// Encode serializes the statement
+func (s *DLEQ) Encode() []byte {
+	return encodePoints(s.C, "dleq", s.G, s.H, s.Y, s.Z)
+}
+
+// Check verifies s*G = T1 + c*Y and s*H = T2 + c*Z
+func (s *DLEQ) Check(commitment []*ec.Point, challenge *big.Int, response []*big.Int) bool {
+	if len(commitment) != 2 || len(response) != 1 {
+		return false
+	}
+	c := s.C
+	return c.ScalarMult(s.G, response[0]).Equal(c.Add(commitment[0], c.ScalarMult(s.Y, challenge))) &&
+		c.ScalarMult(s.H, response[0]).Equal(c.Add(commitment[1], c.ScalarMult(s.Z, challenge)))
+}
+
+// Witness returns the prover side of the statement for the secret x
+func (s *DLEQ) Witness(x *big.Int) Witness {
+	return &dleqWitness{s: s, x: x}
+}
+
+type dleqWitness struct {
+	s *DLEQ
+	x *big.Int
+}
+
+func (w *dleqWitness) Statement() Statement { return w.s }
+
+func (w *dleqWitness) Commit(rand io.Reader) ([]*ec.Point, []*big.Int, error) {
+	r, err := w.s.C.RandomScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []*ec.Point{w.s.C.ScalarMult(w.s.G, r), w.s.C.ScalarMult(w.s.H, r)}, []*big.Int{r}, nil
+}
+
+func (w *dleqWitness) Respond(state []*big.Int, challenge *big.Int) []*big.Int {
+	return []*big.Int{respond(w.s.C, state[0], challenge, w.x)}
+}
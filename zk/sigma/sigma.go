@@ -0,0 +1,122 @@
+// Package sigma implements sigma protocols, three move public coin proofs
+// of knowledge over the curves of the ec package: the prover commits, the
+// verifier sends a random challenge, the prover responds. The Fiat-Shamir
+// transform replaces the verifier with a hash of the statement and the
+// commitment, which makes the proof non interactive.
+//
+// Concrete proofs are provided for knowledge of a discrete logarithm, of
+// a representation over several generators, and for equality of discrete
+// logarithms, which shows a Diffie-Hellman tuple is well formed.
+package sigma
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/jvehent/badcrypto/ec"
+)
+
+// Statement is the public claim a proof is about
+type Statement interface {
+	// Curve returns the group the statement lives in
+	Curve() *ec.Curve
+	// Encode serializes the statement so Fiat-Shamir binds the
+	// challenge to it
+	Encode() []byte
+	// Check runs the verifier's final test on a transcript
+	Check(commitment []*ec.Point, challenge *big.Int, response []*big.Int) bool
+}
+
+// Witness is the prover's secret knowledge for a Statement
+type Witness interface {
+	Statement() Statement
+	// Commit returns the first message, along with the secret state
+	// needed to respond
+	Commit(rand io.Reader) (commitment []*ec.Point, state []*big.Int, err error)
+	// Respond returns the third message for challenge
+	Respond(state []*big.Int, challenge *big.Int) []*big.Int
+}
+
+// Proof is a non interactive transcript
+type Proof struct {
+	Commitment []*ec.Point
+	Response   []*big.Int
+}
+
+// Challenge draws a verifier challenge, for the interactive protocol
+func Challenge(rand io.Reader, c *ec.Curve) (*big.Int, error) {
+	return c.RandomScalar(rand)
+}
+
+// FiatShamir derives the challenge from the label, the statement and the
+// commitment. Leaving any of them out of the hash lets a prover pick the
+// commitment after the challenge and forge proofs.
+func FiatShamir(label string, s Statement, commitment []*ec.Point) *big.Int {
+	c := s.Curve()
+	msg := [][]byte{s.Encode()}
+	for _, p := range commitment {
+		msg = append(msg, c.Marshal(p))
+	}
+	return c.HashToScalar("badcrypto sigma "+label, msg...)
+}
+
+// Prove produces a non interactive proof for the witness. The label
+// separates proofs made for different purposes.
+func Prove(rand io.Reader, label string, w Witness) (*Proof, error) {
+	commitment, state, err := w.Commit(rand)
+	if err != nil {
+		return nil, err
+	}
+	challenge := FiatShamir(label, w.Statement(), commitment)
+	return &Proof{Commitment: commitment, Response: w.Respond(state, challenge)}, nil
+}
+
+// Verify checks a non interactive proof of s under label
+func Verify(label string, s Statement, proof *Proof) bool {
+	if proof == nil {
+		return false
+	}
+	c := s.Curve()
+	for _, p := range proof.Commitment {
+		if p == nil || !c.IsOnCurve(p) {
+			return false
+		}
+	}
+	for _, r := range proof.Response {
+		if r == nil || r.Sign() < 0 || r.Cmp(c.N) >= 0 {
+			return false
+		}
+	}
+	return s.Check(proof.Commitment, FiatShamir(label, s, proof.Commitment), proof.Response)
+}
+
+// randomScalars returns n random scalars
+func randomScalars(rand io.Reader, c *ec.Curve, n int) ([]*big.Int, error) {
+	out := make([]*big.Int, n)
+	for i := range out {
+		r, err := c.RandomScalar(rand)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = r
+	}
+	return out, nil
+}
+
+// respond computes r + challenge*x modulo N
+func respond(c *ec.Curve, r, challenge, x *big.Int) *big.Int {
+	s := new(big.Int).Mul(challenge, x)
+	s.Add(s, r)
+	return s.Mod(s, c.N)
+}
+
+func encodePoints(c *ec.Curve, tag string, points ...*ec.Point) []byte {
+	out := []byte(tag)
+	for _, p := range points {
+		out = append(out, c.Marshal(p)...)
+	}
+	return out
+}
+
+var errWitnessLength = errors.New("sigma: witness does not match the statement")
@@ -0,0 +1,114 @@
+package sigma
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/jvehent/badcrypto/commit"
+	"github.com/jvehent/badcrypto/ec"
+)
+
+func TestDLog(t *testing.T) {
+	t.Parallel()
+	c := ec.P256()
+	x, _ := c.RandomScalar(rand.Reader)
+	s := NewDLog(c, nil, c.ScalarBaseMult(x))
+	w, err := s.Witness(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := Prove(rand.Reader, "test", w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify("test", s, proof) {
+		t.Fatal("expected proof to verify")
+	}
+	if Verify("other", s, proof) {
+		t.Fatal("expected proof under another label to fail")
+	}
+	other := NewDLog(c, nil, c.ScalarBaseMult(big.NewInt(2)))
+	if Verify("test", other, proof) {
+		t.Fatal("expected proof for another statement to fail")
+	}
+	bad, _ := s.Witness(new(big.Int).Add(x, big.NewInt(1)))
+	proof, _ = Prove(rand.Reader, "test", bad)
+	if Verify("test", s, proof) {
+		t.Fatal("expected proof with the wrong witness to fail")
+	}
+}
+
+func TestInteractive(t *testing.T) {
+	t.Parallel()
+	c := ec.Secp256k1()
+	x, _ := c.RandomScalar(rand.Reader)
+	s := NewDLog(c, nil, c.ScalarBaseMult(x))
+	w, _ := s.Witness(x)
+	commitment, state, err := w.Commit(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c1, _ := Challenge(rand.Reader, c)
+	r1 := w.Respond(state, c1)
+	if !s.Check(commitment, c1, r1) {
+		t.Fatal("expected interactive transcript to verify")
+	}
+	// answering two challenges for one commitment leaks the witness,
+	// which is what makes the protocol a proof of knowledge
+	c2, _ := Challenge(rand.Reader, c)
+	r2 := w.Respond(state, c2)
+	num := new(big.Int).Sub(r1[0], r2[0])
+	den := new(big.Int).Sub(c1, c2)
+	den.ModInverse(den.Mod(den, c.N), c.N)
+	extracted := num.Mul(num, den).Mod(num, c.N)
+	if extracted.Cmp(x) != 0 {
+		t.Fatal("expected the extractor to recover the witness")
+	}
+}
+
+func TestRepresentation(t *testing.T) {
+	t.Parallel()
+	c := ec.P256()
+	p := commit.NewPedersen(c)
+	v, r := big.NewInt(42), big.NewInt(7)
+	s := &Representation{C: c, Generators: []*ec.Point{c.Generator(), p.H}, Y: p.CommitWithBlinding(v, r)}
+	w, err := s.Witness(v, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := Prove(rand.Reader, "opening", w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify("opening", s, proof) {
+		t.Fatal("expected proof of opening to verify")
+	}
+	if _, err := s.Witness(v); err == nil {
+		t.Fatal("expected a short witness to be rejected")
+	}
+	proof.Response = proof.Response[:1]
+	if Verify("opening", s, proof) {
+		t.Fatal("expected a truncated proof to fail")
+	}
+}
+
+func TestDLEQ(t *testing.T) {
+	t.Parallel()
+	c := ec.P256()
+	x, _ := c.RandomScalar(rand.Reader)
+	h := c.HashToPoint("test", []byte("h"))
+	s := &DLEQ{C: c, G: c.Generator(), H: h, Y: c.ScalarBaseMult(x), Z: c.ScalarMult(h, x)}
+	proof, err := Prove(rand.Reader, "dleq", s.Witness(x))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify("dleq", s, proof) {
+		t.Fatal("expected DLEQ proof to verify")
+	}
+	notDH := &DLEQ{C: c, G: s.G, H: h, Y: s.Y, Z: c.ScalarMult(h, new(big.Int).Add(x, big.NewInt(1)))}
+	proof, _ = Prove(rand.Reader, "dleq", notDH.Witness(x))
+	if Verify("dleq", notDH, proof) {
+		t.Fatal("expected a proof for a non DH tuple to fail")
+	}
+}